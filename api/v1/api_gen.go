@@ -530,6 +530,7 @@ type ApiEndpointStatus struct {
 	ErrorMessage               string      `json:"error_message"`
 	ModelDownloadCompletedHash interface{} `json:"model_download_completed_hash"`
 	Resources                  interface{} `json:"resources"`
+	ModelDownloadProgress      interface{} `json:"model_download_progress"`
 }
 
 type ApiImageRegistrySpec struct {
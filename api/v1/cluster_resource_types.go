@@ -146,6 +146,9 @@ type DeviceResource struct {
 	Order       *int                `json:"order,omitempty"`
 	Allocatable *DeviceResourcePool `json:"allocatable,omitempty"`
 	Available   *DeviceResourcePool `json:"available,omitempty"`
+	// NVLinkDomain groups the devices of a node that are connected through NVLink.
+	// Empty means the GPU topology of the device is unknown.
+	NVLinkDomain string `json:"nvlink_domain,omitempty"`
 }
 
 type DeviceResourcePool struct {
@@ -276,6 +279,35 @@ func (c *ClusterResources) GetProductModels(acceleratorType string) []string {
 	return models
 }
 
+// LargestNVLinkDomain returns the number of healthy devices in the largest NVLink domain
+// of any node, or 0 if the cluster does not report GPU topology.
+func (c *ClusterResources) LargestNVLinkDomain() int {
+	if c == nil {
+		return 0
+	}
+
+	largest := 0
+
+	for _, node := range c.NodeResources {
+		if node == nil {
+			continue
+		}
+
+		domains := map[string]int{}
+
+		for _, device := range node.Devices {
+			if device == nil || !device.Health || device.NVLinkDomain == "" {
+				continue
+			}
+
+			domains[device.NVLinkDomain]++
+			largest = max(largest, domains[device.NVLinkDomain])
+		}
+	}
+
+	return largest
+}
+
 func (c *ClusterResources) String() string {
 	if c == nil {
 		return "ClusterResources: <nil>"
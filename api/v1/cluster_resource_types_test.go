@@ -276,3 +276,49 @@ func TestClusterResources_ProductVirtualizationSerialization(t *testing.T) {
 		t.Fatalf("Expected product memory metadata 15360, got %f", deserialized.AcceleratorMetadata[AcceleratorTypeNVIDIAGPU].Products["Tesla-T4"].MemoryTotalMiB)
 	}
 }
+
+func TestClusterResources_LargestNVLinkDomain(t *testing.T) {
+	device := func(domain string, healthy bool) *DeviceResource {
+		return &DeviceResource{NVLinkDomain: domain, Health: healthy}
+	}
+
+	tests := []struct {
+		name      string
+		resources *ClusterResources
+		want      int
+	}{
+		{
+			name: "nil resources",
+			want: 0,
+		},
+		{
+			name: "no topology reported",
+			resources: &ClusterResources{
+				NodeResources: map[string]*NodeResourceStatus{
+					"node-1": {Devices: []*DeviceResource{device("", true), device("", true)}},
+				},
+			},
+			want: 0,
+		},
+		{
+			name: "largest domain across nodes, unhealthy devices excluded",
+			resources: &ClusterResources{
+				NodeResources: map[string]*NodeResourceStatus{
+					"node-1": {Devices: []*DeviceResource{device("0", true), device("0", true), device("1", true)}},
+					"node-2": {Devices: []*DeviceResource{
+						device("0", true), device("0", true), device("0", true), device("0", false),
+					}},
+				},
+			},
+			want: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.resources.LargestNVLinkDomain(); got != tt.want {
+				t.Errorf("LargestNVLinkDomain() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package v1
 import (
 	"strconv"
 	"strings"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 
@@ -30,6 +31,18 @@ const StaticNodeClusterFlowVersionGate = "v1.0.1"
 // from version selection and available-version responses.
 const MinimumSelectableClusterVersionGate = StaticNodeClusterFlowVersionGate
 
+// MinClusterReconcileIntervalSeconds is the smallest per-cluster reconcile interval accepted,
+// so that a single cluster cannot hammer its dashboard.
+const MinClusterReconcileIntervalSeconds = 5
+
+// DefaultNodeProvisionParallelism is the number of SSH cluster worker nodes provisioned
+// concurrently when the cluster config does not set it.
+const DefaultNodeProvisionParallelism = 8
+
+// DefaultImageGCIntervalSeconds is the minimum time between two image collections on a
+// static cluster node when the image GC config does not set it.
+const DefaultImageGCIntervalSeconds = 24 * 60 * 60
+
 type Cluster struct {
 	ID         int            `json:"id,omitempty"`
 	APIVersion string         `json:"api_version,omitempty"`
@@ -47,6 +60,40 @@ type ClusterSpec struct {
 	AcceleratorVirtualization *AcceleratorVirtualizationSpec `json:"accelerator_virtualization,omitempty" yaml:"accelerator_virtualization,omitempty"`
 	// the neutree serving version, if not specified, the default version will be used
 	Version string `json:"version"`
+	// ReconcileIntervalSeconds overrides the controller sync interval for this cluster.
+	// If not specified, the cluster is reconciled at the global interval.
+	ReconcileIntervalSeconds *int `json:"reconcile_interval_seconds,omitempty" yaml:"reconcile_interval_seconds,omitempty"`
+	// Maintenance pauses the reconcile of the cluster, e.g. while its nodes are serviced out of band.
+	Maintenance *ClusterMaintenanceSpec `json:"maintenance,omitempty" yaml:"maintenance,omitempty"`
+}
+
+// ClusterMaintenanceSpec configures when the controller leaves the cluster untouched.
+// The status of the cluster stays visible, but it is not reconciled while Paused is set or
+// during one of the Windows.
+type ClusterMaintenanceSpec struct {
+	Paused  bool                       `json:"paused,omitempty" yaml:"paused,omitempty"`
+	Windows []ClusterMaintenanceWindow `json:"windows,omitempty" yaml:"windows,omitempty"`
+}
+
+// ClusterMaintenanceWindow is a time range, Start included and End excluded, in RFC 3339 format.
+type ClusterMaintenanceWindow struct {
+	Start string `json:"start" yaml:"start"`
+	End   string `json:"end" yaml:"end"`
+}
+
+// Parse returns the start and end time of the window.
+func (w ClusterMaintenanceWindow) Parse() (time.Time, time.Time, error) {
+	start, err := time.Parse(time.RFC3339, w.Start)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	end, err := time.Parse(time.RFC3339, w.End)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	return start, end, nil
 }
 
 type ClusterUpgradeStrategy struct {
@@ -68,6 +115,43 @@ func (s *ClusterSpec) AcceleratorVirtualizationEnabled() bool {
 	return s != nil && s.AcceleratorVirtualization != nil && s.AcceleratorVirtualization.Enabled
 }
 
+// ReconcileInterval returns the reconcile interval override of the cluster, or 0 when the
+// global interval applies. Overrides below the minimum are raised to the minimum.
+func (s *ClusterSpec) ReconcileInterval() time.Duration {
+	if s == nil || s.ReconcileIntervalSeconds == nil {
+		return 0
+	}
+
+	seconds := max(*s.ReconcileIntervalSeconds, MinClusterReconcileIntervalSeconds)
+
+	return time.Duration(seconds) * time.Second
+}
+
+// InMaintenance reports whether the reconcile of the cluster is paused at now.
+// Windows that cannot be parsed are ignored.
+func (s *ClusterSpec) InMaintenance(now time.Time) bool {
+	if s == nil || s.Maintenance == nil {
+		return false
+	}
+
+	if s.Maintenance.Paused {
+		return true
+	}
+
+	for _, window := range s.Maintenance.Windows {
+		start, end, err := window.Parse()
+		if err != nil {
+			continue
+		}
+
+		if !now.Before(start) && now.Before(end) {
+			return true
+		}
+	}
+
+	return false
+}
+
 type ClusterConfig struct {
 	SSHConfig        *RaySSHProvisionClusterConfig `json:"ssh_config,omitempty" yaml:"ssh_config,omitempty"`
 	KubernetesConfig *KubernetesClusterConfig      `json:"kubernetes_config,omitempty" yaml:"kubernetes_config,omitempty"`
@@ -82,6 +166,9 @@ type ClusterConfig struct {
 	// In addition, other data may be cached, which depends on the corresponding model registry download implementation,
 	// so it is not recommended to share a storage with the local model registry.
 	ModelCaches []ModelCache `json:"model_caches,omitempty" yaml:"model_caches,omitempty"`
+	// ResourcePresets are named resource profiles, e.g. small, medium, large and xlarge,
+	// that endpoints deployed to the cluster can reference instead of spelling out resources.
+	ResourcePresets map[string]ResourceSpec `json:"resource_presets,omitempty" yaml:"resource_presets,omitempty"`
 }
 
 type ClusterMetricsConfig struct {
@@ -119,11 +206,173 @@ func (c *ClusterConfig) AcceleratorExporterMode() ClusterAcceleratorExporterMode
 type RaySSHProvisionClusterConfig struct {
 	Provider Provider `json:"provider,omitempty" yaml:"provider,omitempty"`
 	Auth     Auth     `json:"auth,omitempty" yaml:"auth,omitempty"`
+	// AcceleratorRuntime adjusts the accelerator runtime config provided by the accelerator
+	// plugin before it is applied to the cluster containers, e.g. for host-specific device mounts.
+	AcceleratorRuntime *AcceleratorRuntimeOverride `json:"accelerator_runtime,omitempty" yaml:"accelerator_runtime,omitempty"`
+	// NodeProvisionParallelism bounds how many worker nodes are started or stopped concurrently.
+	// If not specified, DefaultNodeProvisionParallelism is used.
+	NodeProvisionParallelism *int `json:"node_provision_parallelism,omitempty" yaml:"node_provision_parallelism,omitempty"`
+	// Adopt takes over a Ray cluster already running on the nodes instead of provisioning it.
+	// Initialization then only verifies the running cluster and fails if none is found.
+	Adopt bool `json:"adopt,omitempty" yaml:"adopt,omitempty"`
+	// ImageGC periodically removes dangling and unused neutree images from the cluster nodes.
+	// If not specified, images are never removed.
+	ImageGC *ImageGCConfig `json:"image_gc,omitempty" yaml:"image_gc,omitempty"`
+	// DashboardOutage is how the endpoints of the cluster are reported while the Ray dashboard
+	// is unreachable, e.g. during a head node restart: one of fail, unknown or last_known.
+	// If not specified, DashboardOutageFail is used.
+	DashboardOutage DashboardOutagePolicy `json:"dashboard_outage,omitempty" yaml:"dashboard_outage,omitempty"`
+}
+
+type DashboardOutagePolicy string
+
+const (
+	// DashboardOutageFail fails the endpoints whose status or deployment cannot be checked.
+	DashboardOutageFail DashboardOutagePolicy = "fail"
+	// DashboardOutageUnknown marks the endpoints Unknown, keeping the rest of their last
+	// known status, until the dashboard is back.
+	DashboardOutageUnknown DashboardOutagePolicy = "unknown"
+	// DashboardOutageLastKnown keeps the last known status of the endpoints until the
+	// dashboard is back.
+	DashboardOutageLastKnown DashboardOutagePolicy = "last_known"
+)
+
+// DashboardOutagePolicy returns how endpoints are reported while the Ray dashboard is unreachable.
+func (c *RaySSHProvisionClusterConfig) DashboardOutagePolicy() DashboardOutagePolicy {
+	if c == nil || c.DashboardOutage == "" {
+		return DashboardOutageFail
+	}
+
+	return c.DashboardOutage
+}
+
+// ImageGCConfig configures the image garbage collection of static cluster nodes. Images
+// referenced by a container or by the node warm-up are always kept.
+type ImageGCConfig struct {
+	// IntervalSeconds is the minimum time between two collections on a node.
+	// If not specified, DefaultImageGCIntervalSeconds is used.
+	IntervalSeconds *int `json:"interval_seconds,omitempty" yaml:"interval_seconds,omitempty"`
+	// DryRun only reports the images that would be removed.
+	DryRun bool `json:"dry_run,omitempty" yaml:"dry_run,omitempty"`
+}
+
+// Interval returns the minimum time between two image collections on a node.
+func (c *ImageGCConfig) Interval() time.Duration {
+	if c == nil || c.IntervalSeconds == nil || *c.IntervalSeconds < 1 {
+		return DefaultImageGCIntervalSeconds * time.Second
+	}
+
+	return time.Duration(*c.IntervalSeconds) * time.Second
+}
+
+// NodeProvisionWorkers returns the number of worker nodes provisioned concurrently.
+func (c *RaySSHProvisionClusterConfig) NodeProvisionWorkers() int {
+	if c == nil || c.NodeProvisionParallelism == nil || *c.NodeProvisionParallelism < 1 {
+		return DefaultNodeProvisionParallelism
+	}
+
+	return *c.NodeProvisionParallelism
+}
+
+// AcceleratorRuntimeOverride is merged into the plugin-provided RuntimeConfig.
+// Plugin values are preserved unless explicitly overridden.
+type AcceleratorRuntimeOverride struct {
+	// Options are docker run options appended to the plugin options. An option replaces
+	// the plugin options using the same flag, e.g. "--gpus device=0" replaces "--gpus all",
+	// unless the flag can be given several times, e.g. --device, -v or -e.
+	Options []string `json:"options,omitempty" yaml:"options,omitempty"`
+	// Env is merged into the plugin env, overriding variables with the same name.
+	Env map[string]string `json:"env,omitempty" yaml:"env,omitempty"`
 }
 
 type KubernetesClusterConfig struct {
 	Kubeconfig string     `json:"kubeconfig,omitempty" yaml:"kubeconfig,omitempty" api:"-"`
 	Router     RouterSpec `json:"router,omitempty" yaml:"router,omitempty"`
+	// ServiceMesh joins the endpoint pods of the cluster to the service mesh installed in it.
+	// If not specified, endpoint pods get no sidecar.
+	ServiceMesh *ServiceMeshConfig `json:"service_mesh,omitempty" yaml:"service_mesh,omitempty"`
+	// FinishedJobTTLSeconds is how long one-shot Jobs created by neutree in the cluster are kept
+	// after they finish. If not specified, DefaultFinishedJobTTLSeconds is used.
+	FinishedJobTTLSeconds *int32 `json:"finished_job_ttl_seconds,omitempty" yaml:"finished_job_ttl_seconds,omitempty"`
+	// EndpointIngress configures the Ingresses serving endpoints on custom domains.
+	EndpointIngress *EndpointIngressConfig `json:"endpoint_ingress,omitempty" yaml:"endpoint_ingress,omitempty"`
+	// Tolerations are added to every endpoint replica of the cluster, e.g. to schedule on
+	// nodes tainted for dedicated workloads.
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty" yaml:"tolerations,omitempty"`
+	// DisableAcceleratorTolerations stops endpoint replicas requesting accelerators from
+	// tolerating the standard taint of their accelerator type, e.g. nvidia.com/gpu.
+	DisableAcceleratorTolerations bool `json:"disable_accelerator_tolerations,omitempty" yaml:"disable_accelerator_tolerations,omitempty"`
+	// Overcommit requests less CPU and memory than the limits of the endpoint replicas, so more
+	// endpoints are packed on the nodes of e.g. dev clusters. Accelerators are never overcommitted.
+	Overcommit *OvercommitConfig `json:"overcommit,omitempty" yaml:"overcommit,omitempty"`
+	// DisableImagePullSecretRestart stops the pods failing to pull their images from being
+	// restarted when the credential of the image registry rotates, they then retry the pull
+	// with the refreshed image pull secret on their own back-off.
+	DisableImagePullSecretRestart bool `json:"disable_image_pull_secret_restart,omitempty" yaml:"disable_image_pull_secret_restart,omitempty"`
+	// GPUDevicePlugin is the NVIDIA device plugin mode of the cluster, it decides the resource
+	// name endpoint replicas request their GPUs with, and the GPUs of the nodes are read under.
+	// If not specified, GPUDevicePluginDefault is used for the requests, and the GPUs are read
+	// under the resource name implied by the sharing strategy the nodes are labeled with.
+	GPUDevicePlugin GPUDevicePlugin `json:"gpu_device_plugin,omitempty" yaml:"gpu_device_plugin,omitempty"`
+}
+
+// GPUDevicePlugin is the mode the NVIDIA device plugin of a cluster exposes its GPUs in.
+type GPUDevicePlugin string
+
+const (
+	// GPUDevicePluginDefault exposes every GPU once as nvidia.com/gpu.
+	GPUDevicePluginDefault GPUDevicePlugin = "default"
+	// GPUDevicePluginTimeSlicing shares the GPUs in time slices, exposed as nvidia.com/gpu.shared.
+	GPUDevicePluginTimeSlicing GPUDevicePlugin = "time-slicing"
+	// GPUDevicePluginMPS shares the GPUs through MPS, exposed as nvidia.com/gpu.shared.
+	GPUDevicePluginMPS GPUDevicePlugin = "mps"
+
+	// GPUDevicePluginLabelKey carries the GPUDevicePlugin of the cluster config to the resource
+	// parsers of its nodes, overriding the resource name implied by the sharing strategy the
+	// nodes report.
+	GPUDevicePluginLabelKey = "neutree.ai/gpu-device-plugin"
+)
+
+// OvercommitConfig holds the ratios of the limits of the endpoint replicas to their requests,
+// e.g. a ratio of 2 requests half the limit. A ratio of 1 or less does not overcommit.
+type OvercommitConfig struct {
+	CPURatio    float64 `json:"cpu_ratio,omitempty" yaml:"cpu_ratio,omitempty"`
+	MemoryRatio float64 `json:"memory_ratio,omitempty" yaml:"memory_ratio,omitempty"`
+}
+
+type EndpointIngressConfig struct {
+	// ClassName is the ingress class of the endpoint Ingresses, the cluster default class if empty.
+	ClassName string `json:"class_name,omitempty" yaml:"class_name,omitempty"`
+	// CertManagerIssuer is the cert-manager ClusterIssuer provisioning the certificates of
+	// endpoint domains. If not specified, endpoints use their own TLS secret.
+	CertManagerIssuer string `json:"cert_manager_issuer,omitempty" yaml:"cert_manager_issuer,omitempty"`
+	// GatewayURL is the URL the cluster reaches the neutree gateway proxy at, e.g.
+	// http://kong-proxy.neutree.svc:80. The Ingresses of endpoint domains forward to it, so the
+	// requests are authenticated and accounted for by the gateway. Required by endpoint domains.
+	GatewayURL string `json:"gateway_url,omitempty" yaml:"gateway_url,omitempty"`
+}
+
+const DefaultFinishedJobTTLSeconds int32 = 3600
+
+// GetFinishedJobTTLSeconds returns how long finished neutree Jobs are kept in the cluster.
+func (c *KubernetesClusterConfig) GetFinishedJobTTLSeconds() int32 {
+	if c == nil || c.FinishedJobTTLSeconds == nil || *c.FinishedJobTTLSeconds < 0 {
+		return DefaultFinishedJobTTLSeconds
+	}
+
+	return *c.FinishedJobTTLSeconds
+}
+
+type ServiceMeshType string
+
+const (
+	ServiceMeshTypeIstio   ServiceMeshType = "istio"
+	ServiceMeshTypeLinkerd ServiceMeshType = "linkerd"
+)
+
+type ServiceMeshConfig struct {
+	// Type is the service mesh installed in the cluster, currently support istio, linkerd.
+	Type ServiceMeshType `json:"type,omitempty" yaml:"type,omitempty"`
 }
 
 type RouterSpec struct {
@@ -164,6 +413,57 @@ type ModelCache struct {
 	// PVC specifies the PersistentVolumeClaimSpec for the model cache storage.
 	// Only Kubernetes type cluster support PVC.
 	PVC *corev1.PersistentVolumeClaimSpec `json:"pvc,omitempty" yaml:"pvc,omitempty"`
+
+	// Eviction deletes cached models no endpoint uses once the cache grows over its threshold.
+	// Only NFS and PVC model caches of Kubernetes type cluster support eviction.
+	Eviction *ModelCacheEviction `json:"eviction,omitempty" yaml:"eviction,omitempty"`
+
+	// RegistryPaths caches the models of a model registry type under a sub-directory of the
+	// cache, e.g. {"hugging-face": "hf"} caches them at <cache>/hf/<model>/<version>. Models of
+	// the other registry types are cached at <cache>/<model>/<version>.
+	RegistryPaths map[ModelRegistryType]string `json:"registry_paths,omitempty" yaml:"registry_paths,omitempty"`
+}
+
+type ModelCacheEvictionPolicy string
+
+const (
+	// ModelCacheEvictionPolicyLRU evicts every unused model idle for longer than MaxIdle,
+	// least recently used first, once the cache is over MaxSize, or whatever its size without
+	// MaxSize.
+	ModelCacheEvictionPolicyLRU ModelCacheEvictionPolicy = "lru"
+	// ModelCacheEvictionPolicySizeCapped evicts the least recently used unused models until
+	// the cache fits in MaxSize again. It evicts nothing without MaxSize.
+	ModelCacheEvictionPolicySizeCapped ModelCacheEvictionPolicy = "size_capped"
+)
+
+const (
+	DefaultModelCacheEvictionMaxIdle         = "168h"
+	DefaultModelCacheEvictionIntervalSeconds = 600
+)
+
+// ModelCacheEviction configures the eviction of cached models. Models of endpoints which are
+// not deleted are never evicted, including the endpoints of the other clusters mounting the
+// same NFS export.
+type ModelCacheEviction struct {
+	Policy ModelCacheEvictionPolicy `json:"policy,omitempty" yaml:"policy,omitempty"`
+	// MaxSize is the cache size eviction starts at, e.g. "500Gi".
+	// If not specified, the cache size is unlimited.
+	MaxSize string `json:"max_size,omitempty" yaml:"max_size,omitempty"`
+	// MaxIdle is how long the lru policy keeps an unused model, e.g. "72h".
+	// If not specified, DefaultModelCacheEvictionMaxIdle is used.
+	MaxIdle string `json:"max_idle,omitempty" yaml:"max_idle,omitempty"`
+	// IntervalSeconds is how often the cache is checked.
+	// If not specified, DefaultModelCacheEvictionIntervalSeconds is used.
+	IntervalSeconds *int32 `json:"interval_seconds,omitempty" yaml:"interval_seconds,omitempty"`
+}
+
+// GetIntervalSeconds returns how often the model cache eviction runs.
+func (e *ModelCacheEviction) GetIntervalSeconds() int32 {
+	if e == nil || e.IntervalSeconds == nil || *e.IntervalSeconds <= 0 {
+		return DefaultModelCacheEvictionIntervalSeconds
+	}
+
+	return *e.IntervalSeconds
 }
 
 type ClusterStatus struct {
@@ -198,6 +498,13 @@ type ClusterStatus struct {
 	// Used to detect spec changes and trigger the Updating phase.
 	ObservedSpecHash string `json:"observed_spec_hash,omitempty"`
 
+	// ReconcilePaused is set while the reconcile of the cluster is paused by its maintenance spec.
+	ReconcilePaused bool `json:"reconcile_paused,omitempty"`
+
+	// ArchiveTimestamp is when the cluster was archived. Archived clusters are left out of the
+	// cluster lists and no longer reconciled, their resources are left as they are.
+	ArchiveTimestamp string `json:"archive_timestamp,omitempty"`
+
 	ComponentStatus map[string]*ComponentStatus `json:"component_status,omitempty"`
 }
 
@@ -222,6 +529,8 @@ type NodeProvision struct {
 	LastProvisionTime string `json:"last_provision_time,omitempty"`
 	Status            string `json:"status,omitempty"`
 	IsHead            bool   `json:"is_head,omitempty"`
+	// LastError is the error of the last failed provision attempt of the node.
+	LastError string `json:"last_error,omitempty"`
 }
 
 func (c Cluster) Key() string {
@@ -236,6 +545,11 @@ func (c Cluster) Key() string {
 	return c.Metadata.Workspace + "-" + "clsuter" + "-" + strconv.Itoa(c.ID) + "-" + c.Metadata.Name
 }
 
+// IsArchived reports whether the cluster was archived.
+func (c Cluster) IsArchived() bool {
+	return c.Status != nil && c.Status.ArchiveTimestamp != ""
+}
+
 func (c Cluster) IsInitialized() bool {
 	if c.Status == nil {
 		return false
@@ -279,6 +593,9 @@ const (
 	ClusterPhaseUpdating     ClusterPhase = "Updating"
 	ClusterPhaseUpgrading    ClusterPhase = "Upgrading"
 	ClusterPhaseDeleting     ClusterPhase = "Deleting"
+	// ClusterPhaseArchived is a cluster taken out of use but kept for audit and recovery,
+	// see ClusterStatus.ArchiveTimestamp.
+	ClusterPhaseArchived ClusterPhase = "Archived"
 )
 
 // Image name constants for Neutree components.
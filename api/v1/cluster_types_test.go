@@ -3,6 +3,7 @@ package v1
 import (
 	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -55,3 +56,62 @@ func TestClusterAcceleratorVirtualizationDisabledWhenMissing(t *testing.T) {
 	spec.AcceleratorVirtualization = &AcceleratorVirtualizationSpec{}
 	assert.False(t, spec.AcceleratorVirtualizationEnabled())
 }
+
+func TestClusterSpecInMaintenance(t *testing.T) {
+	now := time.Date(2026, 1, 10, 3, 0, 0, 0, time.UTC)
+	windows := func(ranges ...[2]string) *ClusterMaintenanceSpec {
+		spec := &ClusterMaintenanceSpec{}
+		for _, r := range ranges {
+			spec.Windows = append(spec.Windows, ClusterMaintenanceWindow{Start: r[0], End: r[1]})
+		}
+
+		return spec
+	}
+
+	tests := []struct {
+		name        string
+		maintenance *ClusterMaintenanceSpec
+		expected    bool
+	}{
+		{name: "no maintenance"},
+		{name: "paused", maintenance: &ClusterMaintenanceSpec{Paused: true}, expected: true},
+		{
+			name:        "within a window",
+			maintenance: windows([2]string{"2026-01-10T02:00:00Z", "2026-01-10T04:00:00Z"}),
+			expected:    true,
+		},
+		{
+			name:        "window in another time zone",
+			maintenance: windows([2]string{"2026-01-10T03:30:00+01:00", "2026-01-10T05:00:00+01:00"}),
+			expected:    true,
+		},
+		{
+			name:        "window start is included",
+			maintenance: windows([2]string{"2026-01-10T03:00:00Z", "2026-01-10T04:00:00Z"}),
+			expected:    true,
+		},
+		{
+			name:        "window end is excluded",
+			maintenance: windows([2]string{"2026-01-10T02:00:00Z", "2026-01-10T03:00:00Z"}),
+		},
+		{
+			name:        "outside of the windows",
+			maintenance: windows([2]string{"2026-01-09T02:00:00Z", "2026-01-09T04:00:00Z"}, [2]string{"2026-01-11T02:00:00Z", "2026-01-11T04:00:00Z"}),
+		},
+		{
+			name:        "invalid window is ignored",
+			maintenance: windows([2]string{"tonight", "2026-01-10T04:00:00Z"}, [2]string{"2026-01-10T02:00:00Z", "2026-01-10T04:00:00Z"}),
+			expected:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := &ClusterSpec{Maintenance: tt.maintenance}
+			assert.Equal(t, tt.expected, spec.InMaintenance(now))
+		})
+	}
+
+	var nilSpec *ClusterSpec
+	assert.False(t, nilSpec.InMaintenance(now))
+}
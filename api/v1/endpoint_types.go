@@ -2,6 +2,7 @@ package v1
 
 import (
 	"strconv"
+	"strings"
 
 	"github.com/neutree-ai/neutree/pkg/scheme"
 )
@@ -17,7 +18,14 @@ type ModelSpec struct {
 	// participates in deployment composition — same advisory nature as the
 	// hardware-verified annotation. The model catalog card / show page renders
 	// it per variant. Optional and forward-compatible; legacy specs omit it.
+	// The only deployment use is estimating the engine startup window from the
+	// parameter count and quantization, which falls back to a fixed window.
 	Info *ModelInfo `json:"info,omitempty"`
+	// Weight is the share of the requests not pinning a version that a version of a model
+	// served by a multi-model endpoint receives, e.g. to shift traffic to a canary version.
+	// It only changes the routing of the endpoint, the serve applications are not redeployed.
+	// If no version of the model has a weight, the requests go to its first version.
+	Weight *int `json:"weight,omitempty"`
 }
 
 // ModelInfo is display-only metadata describing the model checkpoint a variant
@@ -34,6 +42,9 @@ type ModelInfo struct {
 type EndpointEngineSpec struct {
 	Engine  string `json:"engine,omitempty"`
 	Version string `json:"version,omitempty"`
+	// PinImageDigest deploys the engine image by the digest its tag resolved to when the
+	// endpoint was first deployed, so rebuilding a tag does not change running endpoints.
+	PinImageDigest bool `json:"pin_image_digest,omitempty"`
 }
 
 type ResourceSpec struct {
@@ -41,6 +52,9 @@ type ResourceSpec struct {
 	GPU         *string           `json:"gpu,omitempty"`
 	Accelerator map[string]string `json:"accelerator,omitempty"`
 	Memory      *string           `json:"memory,omitempty"`
+	// Preset names a resource preset of the deploy cluster the resources are expanded from.
+	// Values set explicitly alongside the preset override the preset ones.
+	Preset string `json:"preset,omitempty"`
 }
 
 type ReplicaSpec struct {
@@ -56,6 +70,203 @@ type EndpointSpec struct {
 	DeploymentOptions map[string]any      `json:"deployment_options,omitempty"`
 	Variables         map[string]any      `json:"variables,omitempty"`
 	Env               map[string]string   `json:"env,omitempty"`
+	// Models lists additional models served by the endpoint besides Model. When set, the
+	// endpoint runs in multi-model mode: each served model is deployed as its own serve
+	// application, and the endpoint route dispatches requests by the request's model name.
+	// Models without a registry use the registry of Model.
+	Models []*ModelSpec `json:"models,omitempty"`
+	// CORS enables cross-origin requests to the endpoint route, e.g. from browser-based apps.
+	// If not specified, no CORS headers are returned.
+	CORS *EndpointCORSSpec `json:"cors,omitempty"`
+	// ValidateRequests validates OpenAI-compatible inference requests at the gateway and
+	// rejects malformed ones with field-level errors before they reach the engine.
+	ValidateRequests bool `json:"validate_requests,omitempty"`
+	// PriorityClassName is the Kubernetes priority class of the endpoint replicas, so
+	// endpoints with a higher priority can preempt lower priority ones on contended clusters.
+	// It must name an existing priority class. Ignored on ssh clusters.
+	PriorityClassName string `json:"priority_class_name,omitempty"`
+	// RequestLogging configures how request and response bodies of the endpoint are
+	// captured in AI traces. If not specified, the bodies of every request are captured.
+	RequestLogging *EndpointRequestLoggingSpec `json:"request_logging,omitempty"`
+	// Domain serves the endpoint over HTTPS on a custom domain through an Ingress of its
+	// Kubernetes cluster, forwarding to the gateway like the endpoint route. Ignored on ssh clusters.
+	Domain *EndpointDomainSpec `json:"domain,omitempty"`
+	// EngineAuth passes an engine-side credential to engines which enforce their own API
+	// key. The neutree API key is still checked before a request is forwarded.
+	EngineAuth *EndpointEngineAuthSpec `json:"engine_auth,omitempty"`
+	// Capabilities tags the endpoint with what its model can do, e.g. "chat" or "code".
+	// Requests to the capability route of a tag are balanced over the running endpoints of
+	// the workspace carrying it.
+	Capabilities []string `json:"capabilities,omitempty"`
+	// MaxOngoingRequests bounds the requests a replica of the endpoint handles at once, Ray
+	// serve queues the requests above it. It applies regardless of the concurrency limits
+	// the engine enforces itself. Ignored on kubernetes clusters.
+	MaxOngoingRequests *int `json:"max_ongoing_requests,omitempty"`
+	// DefaultSamplingParams sets the sampling parameters of the requests omitting them, e.g. a
+	// lower temperature for a coding model. Parameters sent by the client take precedence.
+	// Engines with server-side defaults (vLLM) apply them, the gateway does otherwise.
+	DefaultSamplingParams *SamplingParams `json:"default_sampling_params,omitempty"`
+	// Canary rolls an update of the endpoint out as a canary: the new version serves a share
+	// of the requests next to the running one until it is promoted. If not specified, updates
+	// replace the running version in place. Ignored on kubernetes clusters.
+	Canary *CanarySpec `json:"canary,omitempty"`
+	// UpgradeStrategy selects how the running replicas are replaced when the endpoint is
+	// updated, e.g. to a new engine version, trading the resources used during the update
+	// against the availability of the endpoint. If not specified, the replicas are rolled.
+	UpgradeStrategy *EndpointUpgradeStrategy `json:"upgrade_strategy,omitempty"`
+	// Failover sends the requests of the endpoint to a standby endpoint on another cluster
+	// while every replica of the endpoint is unhealthy, and back once one recovers. If not
+	// specified, such requests are rejected with 503. Ignored on multi-model endpoints.
+	Failover *EndpointFailoverSpec `json:"failover,omitempty"`
+}
+
+// EndpointFailoverSpec configures the standby endpoint taking over the requests of an endpoint.
+type EndpointFailoverSpec struct {
+	// Endpoint is the standby endpoint, in the same workspace and deployed on another cluster.
+	// It must serve the same model as the endpoint, with the same engine_auth.
+	Endpoint string `json:"endpoint"`
+}
+
+// EndpointUpgradeStrategy configures how the updates of an endpoint are rolled out.
+type EndpointUpgradeStrategy struct {
+	Type EndpointUpgradeStrategyType `json:"type,omitempty"`
+}
+
+type EndpointUpgradeStrategyType string
+
+const (
+	// EndpointUpgradeStrategyTypeRolling replaces the replicas a few at a time, the endpoint
+	// keeps serving with fewer replicas and no extra resources.
+	EndpointUpgradeStrategyTypeRolling EndpointUpgradeStrategyType = "Rolling"
+	// EndpointUpgradeStrategyTypeRecreate stops every replica before starting the new ones,
+	// the endpoint is unavailable during the update but never runs both versions.
+	EndpointUpgradeStrategyTypeRecreate EndpointUpgradeStrategyType = "Recreate"
+	// EndpointUpgradeStrategyTypeBlueGreen starts the new replicas next to the running ones
+	// and switches the requests to them once they are ready, which needs the resources of
+	// both versions during the update. It is only supported on Ray clusters.
+	EndpointUpgradeStrategyTypeBlueGreen EndpointUpgradeStrategyType = "BlueGreen"
+)
+
+// GetUpgradeStrategyType returns the upgrade strategy of the endpoint, Rolling if not specified.
+func (s *EndpointSpec) GetUpgradeStrategyType() EndpointUpgradeStrategyType {
+	if s == nil || s.UpgradeStrategy == nil || s.UpgradeStrategy.Type == "" {
+		return EndpointUpgradeStrategyTypeRolling
+	}
+
+	return s.UpgradeStrategy.Type
+}
+
+// CanarySpec configures the canary rollout of endpoint updates.
+type CanarySpec struct {
+	// Weight is the percentage of the requests sent to the canary, from 1 to 100.
+	Weight int `json:"weight"`
+	// DurationSeconds is how long the canary serves its share of the requests before it
+	// replaces the running version. 0 promotes it as soon as it is running.
+	DurationSeconds int `json:"duration_seconds,omitempty"`
+}
+
+// SamplingParams holds the sampling parameters of completion requests.
+type SamplingParams struct {
+	Temperature *float64 `json:"temperature,omitempty"`
+	TopP        *float64 `json:"top_p,omitempty"`
+	MaxTokens   *int     `json:"max_tokens,omitempty"`
+}
+
+// EngineAppliesSamplingDefaults reports whether the engine of the endpoint applies the
+// default sampling parameters itself, in which case the gateway leaves the requests as sent.
+func (s *EndpointSpec) EngineAppliesSamplingDefaults() bool {
+	return s != nil && s.Engine != nil && s.Engine.Engine == EngineNameVLLM
+}
+
+type EngineAuthMode string
+
+const (
+	// EngineAuthModeInject sends the credential of the secret to the engine.
+	EngineAuthModeInject EngineAuthMode = "inject"
+	// EngineAuthModeForward sends the X-Engine-Authorization header of the client to the engine.
+	EngineAuthModeForward EngineAuthMode = "forward"
+)
+
+// EndpointEngineAuthSpec configures the Authorization header sent to the engine.
+type EndpointEngineAuthSpec struct {
+	Mode EngineAuthMode `json:"mode,omitempty"`
+	// SecretRef is the secret holding the engine credential in the cluster namespace.
+	// It is required by the inject mode.
+	SecretRef *SecretKeyReference `json:"secret_ref,omitempty"`
+}
+
+// SecretKeyReference references a key of a Kubernetes secret.
+type SecretKeyReference struct {
+	Name string `json:"name"`
+	Key  string `json:"key"`
+}
+
+// EndpointDomainSpec configures the custom domain of an endpoint and its TLS certificate.
+// The certificate is provisioned by cert-manager when an issuer is configured on the endpoint
+// or its cluster and cert-manager is available, the TLS secret is used otherwise.
+type EndpointDomainSpec struct {
+	// Host is the custom domain of the endpoint, e.g. chat.example.com.
+	Host string `json:"host,omitempty"`
+	// CertManagerIssuer is the cert-manager ClusterIssuer provisioning the certificate of
+	// the domain. If not specified, the issuer of the cluster endpoint ingress is used.
+	CertManagerIssuer string `json:"cert_manager_issuer,omitempty"`
+	// TLSSecretName is the secret holding the certificate of the domain in the cluster
+	// namespace. It is provisioned by cert-manager if an issuer is used, else it must exist.
+	TLSSecretName string `json:"tls_secret_name,omitempty"`
+}
+
+// EndpointCORSSpec configures the CORS responses of an endpoint route.
+type EndpointCORSSpec struct {
+	// AllowedOrigins lists the origins allowed to call the endpoint, "*" allows any origin.
+	AllowedOrigins []string `json:"allowed_origins,omitempty"`
+	// AllowedMethods lists the HTTP methods allowed in cross-origin requests.
+	// If not specified, all methods are allowed.
+	AllowedMethods []string `json:"allowed_methods,omitempty"`
+	// AllowedHeaders lists the request headers allowed in cross-origin requests.
+	// If not specified, the headers requested by the preflight request are allowed.
+	AllowedHeaders []string `json:"allowed_headers,omitempty"`
+}
+
+// EndpointRequestLoggingSpec configures error-triggered sampling of request bodies.
+// Bodies of requests that fail with a 5xx status, including gateway timeouts, are always
+// captured; trace metadata is kept for every request regardless of sampling.
+type EndpointRequestLoggingSpec struct {
+	// SuccessSampleRate is the fraction of successful requests, between 0 and 1, whose
+	// bodies are captured. If not specified, the bodies of all requests are captured.
+	SuccessSampleRate *float64 `json:"success_sample_rate,omitempty"`
+}
+
+// RequestLogSuccessSampleRate returns the fraction of successful requests whose bodies
+// are captured in AI traces, defaulting to 1.
+func (s *EndpointSpec) RequestLogSuccessSampleRate() float64 {
+	if s == nil || s.RequestLogging == nil || s.RequestLogging.SuccessSampleRate == nil {
+		return 1
+	}
+
+	return *s.RequestLogging.SuccessSampleRate
+}
+
+// IsMultiModel reports whether the endpoint serves more than one model.
+func (s *EndpointSpec) IsMultiModel() bool {
+	return s != nil && len(s.Models) > 0
+}
+
+// ServedModels returns all models served by the endpoint, starting with Model.
+func (s *EndpointSpec) ServedModels() []*ModelSpec {
+	if s == nil || s.Model == nil {
+		return nil
+	}
+
+	models := make([]*ModelSpec, 0, len(s.Models)+1)
+	models = append(models, s.Model)
+	models = append(models, s.Models...)
+
+	return models
+}
+
+// PinsEngineImageDigest reports whether the engine image is deployed by digest.
+func (s *EndpointSpec) PinsEngineImageDigest() bool {
+	return s != nil && s.Engine != nil && s.Engine.PinImageDigest
 }
 
 type EndpointPhase string
@@ -69,8 +280,18 @@ const (
 	EndpointPhaseDEPLOYING        EndpointPhase = "Deploying"
 	EndpointPhaseMODELDOWNLOADING EndpointPhase = "ModelDownloading"
 	EndpointPhaseDELETING         EndpointPhase = "Deleting"
+	// EndpointPhaseDEGRADED is an endpoint that still serves requests while part of its
+	// workload is unhealthy, e.g. some of its pods are crash looping.
+	EndpointPhaseDEGRADED EndpointPhase = "Degraded"
+	// EndpointPhaseUNKNOWN is an endpoint whose status cannot be checked, e.g. while the Ray
+	// dashboard of its cluster is unreachable. The rest of its last known status is kept.
+	EndpointPhaseUNKNOWN EndpointPhase = "Unknown"
 )
 
+// EndpointWorkloadFailedMessagePrefix prefixes the error message of failed endpoints whose
+// workload has no healthy replica left, as opposed to endpoints failing to be synced.
+const EndpointWorkloadFailedMessagePrefix = "Endpoint failed: "
+
 type EndpointStatus struct {
 	Phase              EndpointPhase `json:"phase,omitempty"`
 	ServiceURL         string        `json:"service_url,omitempty"`
@@ -81,6 +302,70 @@ type EndpointStatus struct {
 	// proof that the current node or replica has completed downloading.
 	ModelDownloadCompletedHash *string                 `json:"model_download_completed_hash,omitempty"`
 	Resources                  *EndpointResourceStatus `json:"resources,omitempty"`
+	// ModelDownloadProgress is reported while the endpoint is in ModelDownloading phase
+	// and cleared once the download completes.
+	ModelDownloadProgress *ModelDownloadProgress `json:"model_download_progress,omitempty"`
+	// EngineImageDigest is the engine image digest the endpoint is pinned to, set only when
+	// spec.engine.pin_image_digest is enabled. Clearing it picks up the current image of the tag.
+	EngineImageDigest string `json:"engine_image_digest,omitempty"`
+	// FailureCleanupSpecHash is the hash of the endpoint spec whose failed deployment was cleaned
+	// up by deployment_options.failure_cleanup. The endpoint is not redeployed until its spec changes.
+	FailureCleanupSpecHash string `json:"failure_cleanup_spec_hash,omitempty"`
+	// NoHealthyReplicasSince is when the endpoint last started to report no healthy replica, the
+	// failed deployment is only cleaned up once its replicas had the startup window to recover.
+	NoHealthyReplicasSince string `json:"no_healthy_replicas_since,omitempty"`
+	// Canary is the status of the canary of an update rolled out by spec.canary, while the
+	// other fields report the version still serving the rest of the requests.
+	Canary *EndpointCanaryStatus `json:"canary,omitempty"`
+	// Replicas reports the health of each replica of the endpoint, e.g. to tell which replica
+	// of a partially unhealthy endpoint is crash looping.
+	Replicas []ReplicaStatus `json:"replicas,omitempty"`
+}
+
+// ReplicaState is the state of a replica of an endpoint, named after the Ray Serve replica
+// states. Ray Serve replicas may report other states of theirs, e.g. UPDATING.
+type ReplicaState string
+
+const (
+	ReplicaStateRunning    ReplicaState = "RUNNING"
+	ReplicaStateStarting   ReplicaState = "STARTING"
+	ReplicaStateRecovering ReplicaState = "RECOVERING"
+	ReplicaStateStopping   ReplicaState = "STOPPING"
+)
+
+// ReplicaStatus is the health of a replica of an endpoint.
+type ReplicaStatus struct {
+	// ReplicaID is the Ray Serve replica id, or the pod name on kubernetes.
+	ReplicaID string `json:"replica_id"`
+	// Deployment is the Ray Serve deployment of the replica, empty on kubernetes.
+	Deployment string       `json:"deployment,omitempty"`
+	State      ReplicaState `json:"state"`
+	// ErrorMessage is the last error of a replica that is not running.
+	ErrorMessage string `json:"error_message,omitempty"`
+}
+
+// EndpointCanaryStatus is the status of the canary of an endpoint update.
+type EndpointCanaryStatus struct {
+	Phase        EndpointPhase `json:"phase,omitempty"`
+	ErrorMessage string        `json:"error_message,omitempty"`
+	// StartedAt is when the canary was deployed, it is promoted spec.canary.duration_seconds later.
+	StartedAt string `json:"started_at,omitempty"`
+}
+
+// HasNoHealthyReplicas reports whether the orchestrator reported every replica of the
+// endpoint unhealthy. Such endpoints are taken out of routing until a replica recovers.
+func (s *EndpointStatus) HasNoHealthyReplicas() bool {
+	return s != nil && s.Phase == EndpointPhaseFAILED &&
+		strings.HasPrefix(s.ErrorMessage, EndpointWorkloadFailedMessagePrefix)
+}
+
+// ModelDownloadProgress describes how much of the model has been downloaded by a replica.
+type ModelDownloadProgress struct {
+	DownloadedBytes int64 `json:"downloaded_bytes"`
+	// TotalBytes is 0 when the downloader cannot determine the total size up front.
+	TotalBytes int64 `json:"total_bytes,omitempty"`
+	// Percent is only set when TotalBytes is known.
+	Percent *float64 `json:"percent,omitempty"`
 }
 
 type EndpointResourceStatus struct {
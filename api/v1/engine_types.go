@@ -54,6 +54,28 @@ func KnownModelTasks() []string {
 	return tasks
 }
 
+// defaultRequestTimeoutSeconds applies to tasks without a request timeout of their own.
+const defaultRequestTimeoutSeconds = 300
+
+// taskRequestTimeoutSeconds holds the default request timeout per model task: a generation
+// may legitimately take minutes, while a slow embedding or rerank request indicates a real
+// problem.
+var taskRequestTimeoutSeconds = map[string]float64{
+	TextGenerationModelTask: 600,
+	TextEmbeddingModelTask:  30,
+	TextRerankModelTask:     30,
+}
+
+// DefaultRequestTimeoutSeconds returns the default request timeout of an endpoint serving
+// the model task.
+func DefaultRequestTimeoutSeconds(task string) float64 {
+	if seconds, ok := taskRequestTimeoutSeconds[task]; ok {
+		return seconds
+	}
+
+	return defaultRequestTimeoutSeconds
+}
+
 // EngineVersion represents a specific version of an engine with its configuration schema,
 // deployment templates, and supported accelerators.
 //
@@ -157,6 +179,17 @@ type EngineVersion struct {
 	//    "text-embedding",
 	//  },
 	SupportedTasks []string `json:"supported_tasks,omitempty" yaml:"supported_tasks,omitempty"`
+
+	// HealthCheckPaths maps a task to the HTTP path probed for engine readiness, for engines whose
+	// readiness signal differs between tasks. The "default" key applies to tasks without their own
+	// entry; when neither matches, the deploy template's built-in path is used.
+	//
+	// Example:
+	//  HealthCheckPaths: map[string]string{
+	//    "default":        "/health",
+	//    "text-embedding": "/v1/models",
+	//  },
+	HealthCheckPaths map[string]string `json:"health_check_paths,omitempty" yaml:"health_check_paths,omitempty"`
 }
 
 // EngineImage describes the container image information for a specific accelerator type
@@ -439,6 +472,19 @@ func (img *EngineImage) GetFullImagePath() (imageName string, tag string) {
 	return img.ImageName, img.Tag
 }
 
+// DefaultHealthCheckPathKey is the HealthCheckPaths key applied to tasks without their own entry.
+const DefaultHealthCheckPathKey = "default"
+
+// GetHealthCheckPath returns the readiness probe path declared for a task, falling back to the
+// "default" entry. It returns an empty string when the engine version declares neither.
+func (ev *EngineVersion) GetHealthCheckPath(task string) string {
+	if path := ev.HealthCheckPaths[task]; path != "" {
+		return path
+	}
+
+	return ev.HealthCheckPaths[DefaultHealthCheckPathKey]
+}
+
 // GetDeployTemplate retrieves the deployment template for a specific cluster type and mode.
 // It automatically handles Base64 decoding.
 // The template is stored as Base64-encoded string to avoid JSON escaping issues.
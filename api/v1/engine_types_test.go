@@ -391,6 +391,46 @@ func TestEngineVersion_SupportsAccelerator(t *testing.T) {
 	}
 }
 
+func TestEngineVersion_GetHealthCheckPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		paths    map[string]string
+		task     string
+		expected string
+	}{
+		{
+			name:     "task specific path",
+			paths:    map[string]string{DefaultHealthCheckPathKey: "/health", TextEmbeddingModelTask: "/v1/models"},
+			task:     TextEmbeddingModelTask,
+			expected: "/v1/models",
+		},
+		{
+			name:     "falls back to default path",
+			paths:    map[string]string{DefaultHealthCheckPathKey: "/health", TextEmbeddingModelTask: "/v1/models"},
+			task:     TextGenerationModelTask,
+			expected: "/health",
+		},
+		{
+			name:     "no matching path",
+			paths:    map[string]string{TextEmbeddingModelTask: "/v1/models"},
+			task:     TextRerankModelTask,
+			expected: "",
+		},
+		{
+			name:     "no paths declared",
+			task:     TextGenerationModelTask,
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ev := &EngineVersion{HealthCheckPaths: tt.paths}
+			assert.Equal(t, tt.expected, ev.GetHealthCheckPath(tt.task))
+		})
+	}
+}
+
 func TestIsKnownModelTask(t *testing.T) {
 	tests := []struct {
 		task string
@@ -428,3 +468,10 @@ func TestKnownModelTasks(t *testing.T) {
 		assert.True(t, IsKnownModelTask(task), "KnownModelTasks must list a known task: %q", task)
 	}
 }
+
+func TestDefaultRequestTimeoutSeconds(t *testing.T) {
+	assert.Equal(t, float64(600), DefaultRequestTimeoutSeconds(TextGenerationModelTask))
+	assert.Equal(t, float64(30), DefaultRequestTimeoutSeconds(TextEmbeddingModelTask))
+	assert.Equal(t, float64(30), DefaultRequestTimeoutSeconds(TextRerankModelTask))
+	assert.Equal(t, float64(defaultRequestTimeoutSeconds), DefaultRequestTimeoutSeconds("image-generation"))
+}
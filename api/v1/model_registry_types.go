@@ -48,6 +48,10 @@ type ModelRegistryStatus struct {
 	ErrorMessage       string             `json:"error_message,omitempty"`
 	LastTransitionTime string             `json:"last_transition_time,omitempty"`
 	Phase              ModelRegistryPhase `json:"phase,omitempty"`
+
+	// ObservedSpecHash is the SHA256 hash of the registry type and URL last seen connected.
+	// Used to detect registry migrations and redeploy the endpoints using the registry.
+	ObservedSpecHash string `json:"observed_spec_hash,omitempty"`
 }
 
 type ModelRegistry struct {
@@ -1,6 +1,10 @@
 package v1
 
-import "strconv"
+import (
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+)
 
 // RayResourceSpec represents Ray resource specification
 type RayResourceSpec struct {
@@ -17,6 +21,8 @@ type KubernetesResourceSpec struct {
 	NodeSelector map[string]string `json:"nodeSelector,omitempty" yaml:"nodeSelector,omitempty"`
 	Annotations  map[string]string `json:"annotations,omitempty" yaml:"annotations,omitempty"`
 	Env          map[string]string `json:"env,omitempty" yaml:"env,omitempty"`
+	// Tolerations let the pods schedule on nodes tainted for the accelerator.
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty" yaml:"tolerations,omitempty"`
 }
 
 // Accelerator map reserved key constants
@@ -33,6 +33,8 @@ type StaticNodeClusterSpec struct {
 	Nodes []StaticNodeClusterNodeSpec `json:"nodes,omitempty" mergekey:"ip"`
 	// UpgradeStrategy controls how the static cluster rolls from the observed version to Version.
 	UpgradeStrategy *ClusterUpgradeStrategy `json:"upgrade_strategy,omitempty"`
+	// ImageGC enables the image garbage collection of the cluster nodes.
+	ImageGC *ImageGCConfig `json:"image_gc,omitempty"`
 }
 
 type StaticNodeClusterNodeSpec struct {
@@ -102,6 +104,14 @@ type StaticNodeSpec struct {
 	Warm *WarmSpec `json:"warm,omitempty"`
 	// Components is the desired set of containers and config files on this node.
 	Components []NodeComponentSpec `json:"components,omitempty"`
+	// ImageGC enables the image garbage collection of this node.
+	ImageGC *StaticNodeImageGCSpec `json:"image_gc,omitempty"`
+}
+
+type StaticNodeImageGCSpec struct {
+	ImageGCConfig
+	// ImagePrefix is the registry prefix of the neutree images collected on the node.
+	ImagePrefix string `json:"image_prefix,omitempty"`
 }
 
 type StaticNodeRole string
@@ -122,6 +132,8 @@ type StaticNodeStatus struct {
 	Warm *WarmStatus `json:"warm,omitempty"`
 	// Components records observed state for each desired or stale component.
 	Components []NodeComponentStatus `json:"components,omitempty"`
+	// ImageGC records the last image garbage collection on this node.
+	ImageGC *ImageGCStatus `json:"image_gc,omitempty"`
 	// LastTransitionTime records when this status last changed phase.
 	LastTransitionTime string `json:"last_transition_time,omitempty"`
 	// ErrorMessage summarizes the blocking node-level error.
@@ -153,6 +165,9 @@ type StaticNodeAcceleratorDeviceStatus struct {
 	MemoryMiB int64 `json:"memory_mib,omitempty"`
 	// Healthy reports whether the plugin considers this device usable.
 	Healthy bool `json:"healthy,omitempty"`
+	// NVLinkDomain groups the devices of the node that are connected through NVLink,
+	// reported by plugins that detect GPU topology.
+	NVLinkDomain string `json:"nvlink_domain,omitempty"`
 }
 
 type StaticNodeAllocationStatus struct {
@@ -329,6 +344,17 @@ type WarmImageSpec struct {
 	Required bool `json:"required,omitempty"`
 }
 
+type ImageGCStatus struct {
+	// LastRunTime records when images were last collected on the node.
+	LastRunTime string `json:"last_run_time,omitempty"`
+	// DryRun reports whether the last collection only listed the images.
+	DryRun bool `json:"dry_run,omitempty"`
+	// Images lists the images removed, or that would be removed on a dry run.
+	Images []string `json:"images,omitempty"`
+	// Message is a human-readable detail of images that failed to be removed.
+	Message string `json:"message,omitempty"`
+}
+
 type WarmStatus struct {
 	// Ready reports whether all required warm-up items are ready.
 	Ready bool `json:"ready,omitempty"`
@@ -0,0 +1,120 @@
+"""Per-model request metrics recorded by the Controller deployments.
+
+Every request is labeled with the endpoint it was sent to and the model named in
+its ``model`` field, so multi-model endpoints, which serve each model as its own
+application, can be broken down per model and summed back per endpoint::
+
+    neutree_model_requests_total{workspace, endpoint, model, route, status}
+    neutree_model_request_latency_seconds{workspace, endpoint, model, route}
+    neutree_model_requests_running{workspace, endpoint, model}
+
+Latency of a streaming request is measured until its stream ends. Ray exports
+the metrics with its ``ray_`` prefix.
+"""
+
+import functools
+import os
+import time
+from collections import defaultdict
+from typing import Any, AsyncIterator, Awaitable, Callable, Dict, Optional
+
+ENDPOINT_NAME_ENV = "NEUTREE_ENDPOINT_NAME"
+ENDPOINT_WORKSPACE_ENV = "NEUTREE_ENDPOINT_WORKSPACE"
+
+UNKNOWN_MODEL = "unknown"
+
+LATENCY_BOUNDARIES = [0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 20, 30, 60, 120, 300]
+
+
+class ModelRequestMetrics:
+    def __init__(self, metrics_module: Any = None):
+        if metrics_module is None:
+            from ray.util import metrics as metrics_module
+
+        self.workspace = os.environ.get(ENDPOINT_WORKSPACE_ENV, "")
+        self.endpoint = os.environ.get(ENDPOINT_NAME_ENV, "")
+
+        self.requests = metrics_module.Counter(
+            "neutree_model_requests_total",
+            description="Requests served per endpoint and requested model.",
+            tag_keys=("workspace", "endpoint", "model", "route", "status"),
+        )
+        self.latency = metrics_module.Histogram(
+            "neutree_model_request_latency_seconds",
+            description="Request latency per endpoint and requested model.",
+            boundaries=LATENCY_BOUNDARIES,
+            tag_keys=("workspace", "endpoint", "model", "route"),
+        )
+        self.running = metrics_module.Gauge(
+            "neutree_model_requests_running",
+            description="Requests in flight per endpoint and requested model.",
+            tag_keys=("workspace", "endpoint", "model"),
+        )
+        self._running: Dict[str, int] = defaultdict(int)
+
+    def _tags(self, model: str) -> Dict[str, str]:
+        return {"workspace": self.workspace, "endpoint": self.endpoint, "model": model}
+
+    def _set_running(self, model: str, delta: int) -> None:
+        self._running[model] += delta
+        self.running.set(self._running[model], tags=self._tags(model))
+
+    def _finish(self, route: str, model: str, start: float, status: int) -> None:
+        self._set_running(model, -1)
+        tags = self._tags(model)
+        self.requests.inc(1, tags={**tags, "route": route, "status": str(status)})
+        self.latency.observe(time.monotonic() - start, tags={**tags, "route": route})
+
+    async def observe(self, route: str, request: Optional[Dict[str, Any]],
+                      call: Callable[[], Awaitable[Any]]) -> Any:
+        """Run call and record the request against the model named in the request body."""
+        model = UNKNOWN_MODEL
+        if isinstance(request, dict) and isinstance(request.get("model"), str) and request["model"]:
+            model = request["model"]
+
+        self._set_running(model, 1)
+        start = time.monotonic()
+        try:
+            response = await call()
+        except Exception:
+            self._finish(route, model, start, 500)
+            raise
+
+        status = getattr(response, "status_code", 200)
+        if hasattr(response, "body_iterator"):
+            response.body_iterator = self._observe_stream(response.body_iterator, route, model, start, status)
+            return response
+
+        self._finish(route, model, start, status)
+        return response
+
+    async def _observe_stream(self, body: AsyncIterator[Any], route: str, model: str,
+                              start: float, status: int) -> AsyncIterator[Any]:
+        try:
+            async for chunk in body:
+                yield chunk
+        except Exception:
+            status = 500
+            raise
+        finally:
+            self._finish(route, model, start, status)
+
+
+def observe_model_requests(route: str):
+    """Record the requests of a Controller route handler in self.metrics.
+
+    The request body is parsed here to get the requested model; Starlette caches
+    it, so the handler parsing it again does not read the body twice.
+    """
+    def decorator(handler):
+        @functools.wraps(handler)
+        async def wrapper(self, request):
+            try:
+                body = await request.json()
+            except Exception:
+                body = None
+            return await self.metrics.observe(route, body, lambda: handler(self, request))
+
+        return wrapper
+
+    return decorator
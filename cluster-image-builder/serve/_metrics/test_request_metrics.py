@@ -0,0 +1,179 @@
+"""Tests for serve._metrics.request_metrics."""
+
+import asyncio
+import types
+from collections import defaultdict
+
+import pytest
+
+from serve._metrics.request_metrics import ModelRequestMetrics, observe_model_requests
+
+
+class FakeRegistry:
+    """Stand-in for ray.util.metrics sharing recorded samples across metric instances."""
+
+    def __init__(self):
+        self.counters = defaultdict(float)
+        self.observations = defaultdict(list)
+        self.gauges = {}
+
+    def _key(self, name, tags):
+        return (name,) + tuple(sorted(tags.items()))
+
+    def module(self):
+        registry = self
+
+        class Counter:
+            def __init__(self, name, description="", tag_keys=()):
+                self.name = name
+
+            def inc(self, value=1.0, tags=None):
+                registry.counters[registry._key(self.name, tags)] += value
+
+        class Histogram:
+            def __init__(self, name, description="", boundaries=None, tag_keys=()):
+                self.name = name
+
+            def observe(self, value, tags=None):
+                registry.observations[registry._key(self.name, tags)].append(value)
+
+        class Gauge:
+            def __init__(self, name, description="", tag_keys=()):
+                self.name = name
+
+            def set(self, value, tags=None):
+                registry.gauges[registry._key(self.name, tags)] = value
+
+        return types.SimpleNamespace(Counter=Counter, Histogram=Histogram, Gauge=Gauge)
+
+    def requests(self, **match):
+        """Sum neutree_model_requests_total over the series matching the given labels."""
+        total = 0.0
+        for key, value in self.counters.items():
+            labels = dict(key[1:])
+            if key[0] == "neutree_model_requests_total" and all(labels.get(k) == v for k, v in match.items()):
+                total += value
+        return total
+
+    def running(self, model):
+        for key, value in self.gauges.items():
+            if key[0] == "neutree_model_requests_running" and dict(key[1:])["model"] == model:
+                return value
+        return None
+
+
+class FakeResponse:
+    def __init__(self, status_code=200):
+        self.status_code = status_code
+
+
+class FakeStreamingResponse(FakeResponse):
+    def __init__(self, chunks):
+        super().__init__()
+
+        async def body():
+            for chunk in chunks:
+                yield chunk
+
+        self.body_iterator = body()
+
+
+@pytest.fixture
+def registry(monkeypatch):
+    monkeypatch.setenv("NEUTREE_ENDPOINT_NAME", "chat")
+    monkeypatch.setenv("NEUTREE_ENDPOINT_WORKSPACE", "default")
+    return FakeRegistry()
+
+
+def observe(metrics, route, body, response):
+    async def call():
+        if isinstance(response, Exception):
+            raise response
+        return response
+
+    return asyncio.run(metrics.observe(route, body, call))
+
+
+def test_requests_are_labeled_with_endpoint_and_model(registry):
+    metrics = ModelRequestMetrics(registry.module())
+
+    observe(metrics, "/v1/chat/completions", {"model": "qwen"}, FakeResponse())
+    observe(metrics, "/v1/chat/completions", {"model": "qwen"}, FakeResponse(400))
+
+    assert registry.requests(workspace="default", endpoint="chat", model="qwen",
+                             route="/v1/chat/completions", status="200") == 1
+    assert registry.requests(model="qwen", status="400") == 1
+    latency_keys = [k for k in registry.observations if k[0] == "neutree_model_request_latency_seconds"]
+    assert [dict(k[1:])["model"] for k in latency_keys] == ["qwen"]
+    assert registry.running("qwen") == 0
+
+
+def test_requests_aggregate_across_models_of_one_endpoint(registry):
+    # A multi-model endpoint serves each model from its own application.
+    qwen = ModelRequestMetrics(registry.module())
+    llama = ModelRequestMetrics(registry.module())
+
+    observe(qwen, "/v1/chat/completions", {"model": "qwen"}, FakeResponse())
+    observe(qwen, "/v1/chat/completions", {"model": "qwen"}, FakeResponse(500))
+    observe(llama, "/v1/embeddings", {"model": "llama"}, FakeResponse())
+
+    assert registry.requests(model="qwen") == 2
+    assert registry.requests(model="llama") == 1
+    assert registry.requests(endpoint="chat") == 3
+    assert registry.requests(endpoint="chat", status="500") == 1
+
+
+def test_streaming_request_is_finished_when_stream_ends(registry):
+    metrics = ModelRequestMetrics(registry.module())
+
+    async def run():
+        async def call():
+            return FakeStreamingResponse(["a", "b"])
+
+        response = await metrics.observe("/v1/chat/completions", {"model": "qwen", "stream": True}, call)
+        assert registry.running("qwen") == 1
+        assert registry.requests(model="qwen") == 0
+
+        return [chunk async for chunk in response.body_iterator]
+
+    assert asyncio.run(run()) == ["a", "b"]
+    assert registry.running("qwen") == 0
+    assert registry.requests(model="qwen", status="200") == 1
+
+
+def test_failed_request_is_recorded_as_error(registry):
+    metrics = ModelRequestMetrics(registry.module())
+
+    with pytest.raises(RuntimeError):
+        observe(metrics, "/v1/rerank", {"model": "bge"}, RuntimeError("replica died"))
+
+    assert registry.requests(model="bge", route="/v1/rerank", status="500") == 1
+    assert registry.running("bge") == 0
+
+
+def test_request_without_model_is_labeled_unknown(registry):
+    metrics = ModelRequestMetrics(registry.module())
+
+    observe(metrics, "/v1/chat/completions", None, FakeResponse())
+    observe(metrics, "/v1/chat/completions", {"model": ""}, FakeResponse())
+
+    assert registry.requests(model="unknown") == 2
+
+
+def test_observe_model_requests_decorator(registry):
+    class Request:
+        async def json(self):
+            return {"model": "qwen"}
+
+    class Controller:
+        def __init__(self):
+            self.metrics = ModelRequestMetrics(registry.module())
+
+        @observe_model_requests("/v1/chat/completions")
+        async def chat(self, request):
+            return FakeResponse()
+
+    response = asyncio.run(Controller().chat(Request()))
+
+    assert response.status_code == 200
+    assert registry.requests(model="qwen", route="/v1/chat/completions") == 1
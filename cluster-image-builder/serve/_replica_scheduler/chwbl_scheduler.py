@@ -11,10 +11,12 @@ from ray.serve._private.request_router.common import (
 from ray.serve._private.request_router.request_router import RequestRouter
 from ray.serve._private.request_router.replica_wrapper import RunningReplica
 
+from serve._replica_scheduler.circuit_breaker import CircuitBreakerMixin
+
 logger = logging.getLogger(SERVE_LOGGER_NAME)
 
 
-class ConsistentHashReplicaScheduler(RequestRouter):
+class ConsistentHashReplicaScheduler(CircuitBreakerMixin, RequestRouter):
     """A scheduler that routes requests using consistent hashing with bounded loads.
 
     This scheduler ensures that similar payloads are routed to the same replica
@@ -43,17 +45,21 @@ class ConsistentHashReplicaScheduler(RequestRouter):
         virtual_nodes_per_replica: int = 100,
         load_factor: float = 1.25,
         max_user_messages_for_cache: int = 2,
+        circuit_failure_threshold: int = 0,
+        circuit_open_seconds: float = 30.0,
     ):
         """Initialize custom scheduler state from request_router_kwargs."""
         self._virtual_nodes = virtual_nodes_per_replica
         self._load_factor = load_factor
         self._max_user_messages_for_cache = max_user_messages_for_cache
+        self._init_circuit_breaker(circuit_failure_threshold, circuit_open_seconds)
 
         logger.info(
             f"Initialized ConsistentHashReplicaScheduler with "
             f"{virtual_nodes_per_replica} virtual nodes per replica, "
             f"load factor of {load_factor}, "
-            f"max_user_messages_for_cache={max_user_messages_for_cache}"
+            f"max_user_messages_for_cache={max_user_messages_for_cache}, "
+            f"circuit_failure_threshold={circuit_failure_threshold}"
         )
 
     def _create_load_snapshot(self) -> Dict[ReplicaID, int]:
@@ -79,6 +85,9 @@ class ConsistentHashReplicaScheduler(RequestRouter):
             logger.warning("No candidate replicas available for consistent hash scheduling")
             return [[]]
 
+        # Skip replicas whose circuit is open, they fall back to the next replica on the ring
+        candidate_replicas = self._filter_open_circuits(candidate_replicas)
+
         # Build a map of candidate replicas for quick lookup
         candidate_map = {r.replica_id: r for r in candidate_replicas}
 
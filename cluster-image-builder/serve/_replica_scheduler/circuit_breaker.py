@@ -0,0 +1,123 @@
+import logging
+import time
+from typing import Callable, Dict, Hashable, List, Optional
+
+logger = logging.getLogger("ray.serve")
+
+
+class ReplicaCircuitBreaker:
+    """Tracks replica failures and opens the circuit to replicas that repeatedly fail.
+
+    A replica failing ``failure_threshold`` times within ``open_seconds`` is taken out of
+    routing for ``open_seconds``. Afterwards the circuit is half-open: requests reach the
+    replica again, but a single failure within the next ``open_seconds`` re-opens it
+    immediately. A threshold of 0 disables circuit breaking.
+    """
+
+    def __init__(
+        self,
+        failure_threshold: int = 0,
+        open_seconds: float = 30.0,
+        clock: Callable[[], float] = time.monotonic,
+    ):
+        self._failure_threshold = failure_threshold
+        self._open_seconds = open_seconds
+        self._clock = clock
+
+        self._failures: Dict[Hashable, List[float]] = {}
+        self._opened_at: Dict[Hashable, float] = {}
+
+    @property
+    def enabled(self) -> bool:
+        return self._failure_threshold > 0
+
+    def record_failure(self, replica_id: Hashable):
+        """Record a failed request to the replica and open its circuit when needed."""
+        if not self.enabled:
+            return
+
+        now = self._clock()
+
+        opened_at = self._opened_at.get(replica_id)
+        if opened_at is not None and now - opened_at >= 2 * self._open_seconds:
+            # The replica recovered long enough ago to be considered closed again.
+            del self._opened_at[replica_id]
+            opened_at = None
+
+        if opened_at is not None and now - opened_at < self._open_seconds:
+            # Already open, late failures of in-flight requests don't extend it.
+            return
+
+        failures = [t for t in self._failures.get(replica_id, []) if now - t < self._open_seconds]
+        failures.append(now)
+
+        # A half-open replica that fails again is opened right away.
+        if len(failures) >= self._failure_threshold or opened_at is not None:
+            self._opened_at[replica_id] = now
+            self._failures.pop(replica_id, None)
+            logger.warning(
+                f"CircuitBreaker: Opened circuit to replica {replica_id} for {self._open_seconds}s "
+                f"after {len(failures)} failure(s)"
+            )
+            return
+
+        self._failures[replica_id] = failures
+
+    def is_open(self, replica_id: Hashable) -> bool:
+        opened_at = self._opened_at.get(replica_id)
+        if opened_at is None:
+            return False
+
+        return self._clock() - opened_at < self._open_seconds
+
+    def forget(self, replica_id: Hashable):
+        """Drop the state of a replica that is gone for good."""
+        self._failures.pop(replica_id, None)
+        self._opened_at.pop(replica_id, None)
+
+    def filter(self, candidates: List, key: Optional[Callable] = None) -> List:
+        """Return the candidates whose circuit is not open.
+
+        If every candidate is open, all of them are returned: routing to a possibly
+        failing replica is better than failing the request outright.
+        """
+        if not self.enabled or not self._opened_at:
+            return candidates
+
+        if key is None:
+            key = lambda replica: replica.replica_id  # noqa: E731
+
+        allowed = [c for c in candidates if not self.is_open(key(c))]
+        if not allowed:
+            logger.warning("CircuitBreaker: All candidate replicas are open, ignoring circuit state")
+            return candidates
+
+        return allowed
+
+
+class CircuitBreakerMixin:
+    """Adds per-replica circuit breaking to a Ray Serve RequestRouter.
+
+    Must be listed before the RequestRouter base class. Ray reports replicas that could
+    not serve a request (e.g. a restarting pod) through on_replica_actor_unavailable;
+    routers call _filter_open_circuits on the candidates in choose_replicas.
+    """
+
+    _circuit_breaker: ReplicaCircuitBreaker = ReplicaCircuitBreaker()
+
+    def _init_circuit_breaker(self, failure_threshold: int = 0, open_seconds: float = 30.0):
+        self._circuit_breaker = ReplicaCircuitBreaker(
+            failure_threshold=failure_threshold,
+            open_seconds=open_seconds,
+        )
+
+    def _filter_open_circuits(self, candidate_replicas: List) -> List:
+        return self._circuit_breaker.filter(candidate_replicas)
+
+    def on_replica_actor_unavailable(self, replica_id):
+        self._circuit_breaker.record_failure(replica_id)
+        super().on_replica_actor_unavailable(replica_id)
+
+    def on_replica_actor_died(self, replica_id):
+        self._circuit_breaker.forget(replica_id)
+        super().on_replica_actor_died(replica_id)
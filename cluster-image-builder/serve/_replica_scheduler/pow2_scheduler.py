@@ -0,0 +1,44 @@
+import logging
+from typing import List, Optional
+
+from ray.serve._private.constants import SERVE_LOGGER_NAME
+from ray.serve._private.request_router.common import (
+    PendingRequest,
+)
+from ray.serve._private.request_router.pow_2_router import PowerOfTwoChoicesRequestRouter
+from ray.serve._private.request_router.replica_wrapper import RunningReplica
+
+from serve._replica_scheduler.circuit_breaker import CircuitBreakerMixin
+
+logger = logging.getLogger(SERVE_LOGGER_NAME)
+
+
+class CircuitBreakingPow2ReplicaScheduler(CircuitBreakerMixin, PowerOfTwoChoicesRequestRouter):
+    """The default power-of-two-choices scheduler with per-replica circuit breaking.
+
+    Only used when circuit breaking is configured, otherwise Ray's built-in router applies.
+    """
+
+    def initialize_state(
+        self,
+        circuit_failure_threshold: int = 0,
+        circuit_open_seconds: float = 30.0,
+    ):
+        """Initialize custom scheduler state from request_router_kwargs."""
+        self._init_circuit_breaker(circuit_failure_threshold, circuit_open_seconds)
+
+        logger.info(
+            f"Initialized CircuitBreakingPow2ReplicaScheduler with "
+            f"circuit_failure_threshold={circuit_failure_threshold}, "
+            f"circuit_open_seconds={circuit_open_seconds}"
+        )
+
+    async def choose_replicas(
+        self,
+        candidate_replicas: List[RunningReplica],
+        pending_request: Optional[PendingRequest] = None,
+    ) -> List[List[RunningReplica]]:
+        return await super().choose_replicas(
+            self._filter_open_circuits(candidate_replicas),
+            pending_request,
+        )
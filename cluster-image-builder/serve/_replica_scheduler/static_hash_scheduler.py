@@ -10,10 +10,12 @@ from ray.serve._private.request_router.common import (
 from ray.serve._private.request_router.request_router import RequestRouter
 from ray.serve._private.request_router.replica_wrapper import RunningReplica
 
+from serve._replica_scheduler.circuit_breaker import CircuitBreakerMixin
+
 logger = logging.getLogger(SERVE_LOGGER_NAME)
 
 
-class StaticHashReplicaScheduler(RequestRouter):
+class StaticHashReplicaScheduler(CircuitBreakerMixin, RequestRouter):
     """A scheduler that routes requests to replicas based on payload hash.
 
     This scheduler ensures that identical payloads are always routed to the same replica.
@@ -33,6 +35,14 @@ class StaticHashReplicaScheduler(RequestRouter):
 
         logger.info("Initialized StaticHashReplicaScheduler")
 
+    def initialize_state(
+        self,
+        circuit_failure_threshold: int = 0,
+        circuit_open_seconds: float = 30.0,
+    ):
+        """Initialize custom scheduler state from request_router_kwargs."""
+        self._init_circuit_breaker(circuit_failure_threshold, circuit_open_seconds)
+
     async def choose_replicas(
         self,
         candidate_replicas: List[RunningReplica],
@@ -51,6 +61,9 @@ class StaticHashReplicaScheduler(RequestRouter):
             logger.warning("No candidate replicas available for static hash scheduling")
             return [[]]
 
+        # Skip replicas whose circuit is open
+        candidate_replicas = self._filter_open_circuits(candidate_replicas)
+
         # If no pending request, return all candidates as equal priority
         if pending_request is None:
             return [candidate_replicas]
@@ -0,0 +1,125 @@
+import unittest
+from collections import namedtuple
+
+from serve._replica_scheduler.circuit_breaker import CircuitBreakerMixin, ReplicaCircuitBreaker
+
+Replica = namedtuple("Replica", ["replica_id"])
+
+
+class FakeClock:
+    def __init__(self):
+        self.now = 0.0
+
+    def __call__(self):
+        return self.now
+
+
+class TestReplicaCircuitBreaker(unittest.TestCase):
+    def setUp(self):
+        self.clock = FakeClock()
+        self.breaker = ReplicaCircuitBreaker(failure_threshold=2, open_seconds=30, clock=self.clock)
+        self.replicas = [Replica("r1"), Replica("r2")]
+
+    def test_disabled_never_opens(self):
+        breaker = ReplicaCircuitBreaker(failure_threshold=0, clock=self.clock)
+        for _ in range(10):
+            breaker.record_failure("r1")
+
+        self.assertFalse(breaker.is_open("r1"))
+        self.assertEqual(breaker.filter(self.replicas), self.replicas)
+
+    def test_opens_after_repeated_failures(self):
+        self.breaker.record_failure("r1")
+        self.assertFalse(self.breaker.is_open("r1"))
+
+        self.clock.now = 5
+        self.breaker.record_failure("r1")
+        self.assertTrue(self.breaker.is_open("r1"))
+        self.assertEqual(self.breaker.filter(self.replicas), [Replica("r2")])
+
+    def test_failures_outside_window_do_not_open(self):
+        self.breaker.record_failure("r1")
+
+        self.clock.now = 31
+        self.breaker.record_failure("r1")
+        self.assertFalse(self.breaker.is_open("r1"))
+
+    def test_half_open_after_open_seconds(self):
+        self.breaker.record_failure("r1")
+        self.breaker.record_failure("r1")
+
+        self.clock.now = 30
+        self.assertFalse(self.breaker.is_open("r1"))
+        self.assertEqual(self.breaker.filter(self.replicas), self.replicas)
+
+        # A single failure while half-open re-opens the circuit.
+        self.clock.now = 35
+        self.breaker.record_failure("r1")
+        self.assertTrue(self.breaker.is_open("r1"))
+
+    def test_closed_after_recovery(self):
+        self.breaker.record_failure("r1")
+        self.breaker.record_failure("r1")
+
+        self.clock.now = 60
+        self.breaker.record_failure("r1")
+        self.assertFalse(self.breaker.is_open("r1"))
+
+    def test_all_open_returns_all_candidates(self):
+        for replica in self.replicas:
+            self.breaker.record_failure(replica.replica_id)
+            self.breaker.record_failure(replica.replica_id)
+
+        self.assertEqual(self.breaker.filter(self.replicas), self.replicas)
+
+    def test_forget(self):
+        self.breaker.record_failure("r1")
+        self.breaker.record_failure("r1")
+        self.breaker.forget("r1")
+
+        self.assertFalse(self.breaker.is_open("r1"))
+
+
+class FakeRouter:
+    def __init__(self):
+        self.unavailable = []
+        self.died = []
+
+    def on_replica_actor_unavailable(self, replica_id):
+        self.unavailable.append(replica_id)
+
+    def on_replica_actor_died(self, replica_id):
+        self.died.append(replica_id)
+
+
+class CircuitBreakingRouter(CircuitBreakerMixin, FakeRouter):
+    pass
+
+
+class TestCircuitBreakerMixin(unittest.TestCase):
+    def test_unavailable_replica_opens_circuit(self):
+        router = CircuitBreakingRouter()
+        router._init_circuit_breaker(failure_threshold=1, open_seconds=30)
+        replicas = [Replica("r1"), Replica("r2")]
+
+        router.on_replica_actor_unavailable("r1")
+
+        self.assertEqual(router.unavailable, ["r1"])
+        self.assertEqual(router._filter_open_circuits(replicas), [Replica("r2")])
+
+        router.on_replica_actor_died("r1")
+
+        self.assertEqual(router.died, ["r1"])
+        self.assertEqual(router._filter_open_circuits(replicas), replicas)
+
+    def test_circuit_breaking_disabled_by_default(self):
+        router = CircuitBreakingRouter()
+        replicas = [Replica("r1")]
+
+        router.on_replica_actor_unavailable("r1")
+
+        self.assertEqual(router._filter_open_circuits(replicas), replicas)
+
+
+if __name__ == "__main__":
+    unittest.main()
@@ -0,0 +1,341 @@
+"""Structured access logs of the Controller deployments.
+
+Configured through ``deployment_options.access_log`` of the endpoint, which the
+orchestrator passes to the application as environment variables::
+
+    access_log:
+      sink: stdout           # or object_store
+      url: https://logs.example.com/neutree  # object_store only
+      fields: [timestamp, api_key, model, status, latency_ms, total_tokens]
+      sample_ratio: 1
+
+Every sampled request is logged as one JSON object holding the selected fields, all of
+``FIELDS`` by default. The stdout sink writes a line per request to the Controller logs.
+The object_store sink collects the entries in the background and uploads them as
+newline-delimited JSON objects with HTTP PUT to
+``<url>/<workspace>/<endpoint>/<YYYY>/<MM>/<DD>/<time>-<id>.jsonl``. Entries are dropped
+when the upload does not keep up, access logging never holds back requests.
+
+Unlike request capture this only records metadata: the model and the stream flag are
+read from the request body and the token usage from the response, bodies are never
+logged. The API key is the ID Kong passes on for the authenticated consumer, not the
+key itself. Token counts of streamed responses are only known when the engine sends
+usage in the stream, e.g. with ``stream_options.include_usage``.
+"""
+
+import datetime
+import json
+import logging
+import os
+import queue
+import random
+import sys
+import threading
+import time
+import urllib.request
+import uuid
+from dataclasses import dataclass
+from typing import Any, Awaitable, Callable, Dict, List, Optional, Tuple
+
+logger = logging.getLogger("ray.serve")
+
+ACCESS_LOG_SINK_ENV = "NEUTREE_ACCESS_LOG_SINK"
+ACCESS_LOG_URL_ENV = "NEUTREE_ACCESS_LOG_URL"
+ACCESS_LOG_FIELDS_ENV = "NEUTREE_ACCESS_LOG_FIELDS"
+ACCESS_LOG_SAMPLE_RATIO_ENV = "NEUTREE_ACCESS_LOG_SAMPLE_RATIO"
+
+ENDPOINT_NAME_ENV = "NEUTREE_ENDPOINT_NAME"
+ENDPOINT_WORKSPACE_ENV = "NEUTREE_ENDPOINT_WORKSPACE"
+
+SINK_STDOUT = "stdout"
+SINK_OBJECT_STORE = "object_store"
+
+# Fields of an entry, in the order they are logged.
+FIELDS = (
+    "timestamp",
+    "request_id",
+    "workspace",
+    "endpoint",
+    "api_key",
+    "model",
+    "method",
+    "path",
+    "status",
+    "latency_ms",
+    "stream",
+    "prompt_tokens",
+    "completion_tokens",
+    "total_tokens",
+)
+
+# Request and response bodies larger than this are not parsed for the model or the usage.
+_MAX_PARSED_BODY_BYTES = 1024 * 1024
+
+# Header Kong's key-auth sets to the custom ID of the consumer, the ID of the API key.
+_CONSUMER_CUSTOM_ID_HEADER = b"x-consumer-custom-id"
+_CONSUMER_USERNAME_HEADER = b"x-consumer-username"
+
+_UPLOAD_BATCH_SIZE = 1000
+_UPLOAD_INTERVAL_SECONDS = 10.0
+_UPLOAD_QUEUE_SIZE = 10000
+_UPLOAD_TIMEOUT_SECONDS = 10.0
+
+Scope = Dict[str, Any]
+Message = Dict[str, Any]
+Receive = Callable[[], Awaitable[Message]]
+Send = Callable[[Message], Awaitable[None]]
+
+
+@dataclass
+class AccessLogConfig:
+    # Empty disables access logging.
+    sink: str = ""
+    url: str = ""
+    fields: Tuple[str, ...] = FIELDS
+    sample_ratio: float = 1.0
+
+    @property
+    def enabled(self) -> bool:
+        return bool(self.sink)
+
+    @classmethod
+    def from_env(cls) -> "AccessLogConfig":
+        fields = os.environ.get(ACCESS_LOG_FIELDS_ENV)
+        ratio = os.environ.get(ACCESS_LOG_SAMPLE_RATIO_ENV)
+        return cls(
+            sink=os.environ.get(ACCESS_LOG_SINK_ENV, ""),
+            url=os.environ.get(ACCESS_LOG_URL_ENV, "").rstrip("/"),
+            fields=tuple(f for f in fields.split(",") if f in FIELDS) if fields else FIELDS,
+            sample_ratio=float(ratio) if ratio else 1.0,
+        )
+
+
+@dataclass
+class AccessLogEntry:
+    timestamp: str
+    request_id: Optional[str]
+    workspace: str
+    endpoint: str
+    api_key: Optional[str]
+    model: Optional[str]
+    method: str
+    path: str
+    status: int = 0
+    latency_ms: float = 0.0
+    stream: bool = False
+    prompt_tokens: Optional[int] = None
+    completion_tokens: Optional[int] = None
+    total_tokens: Optional[int] = None
+
+    def to_dict(self, fields: Tuple[str, ...] = FIELDS) -> Dict[str, Any]:
+        return {name: getattr(self, name) for name in fields}
+
+    def set_usage(self, usage: Any) -> None:
+        if not isinstance(usage, dict):
+            return
+        for name in ("prompt_tokens", "completion_tokens", "total_tokens"):
+            if isinstance(usage.get(name), int):
+                setattr(self, name, usage[name])
+
+
+class StdoutSink:
+    """Write every entry as a JSON line to the Controller logs."""
+
+    def __init__(self, stream: Any = None):
+        self.stream = stream or sys.stdout
+
+    def write(self, entry: Dict[str, Any]) -> None:
+        self.stream.write(json.dumps(entry, separators=(",", ":")) + "\n")
+        self.stream.flush()
+
+
+class ObjectStoreSink:
+    """Upload the entries in batches to an object store from a background thread."""
+
+    def __init__(self, url: str, prefix: str):
+        self.url = url
+        self.prefix = prefix
+        self._queue: "queue.Queue[Dict[str, Any]]" = queue.Queue(maxsize=_UPLOAD_QUEUE_SIZE)
+        self._thread: Optional[threading.Thread] = None
+        self._lock = threading.Lock()
+
+    def write(self, entry: Dict[str, Any]) -> None:
+        self._ensure_started()
+        try:
+            self._queue.put_nowait(entry)
+        except queue.Full:
+            logger.debug("[AccessLog] upload queue is full, dropping entry")
+
+    def _ensure_started(self) -> None:
+        if self._thread is not None:
+            return
+        with self._lock:
+            if self._thread is None:
+                self._thread = threading.Thread(target=self._run, name="neutree-access-log-upload", daemon=True)
+                self._thread.start()
+
+    def _run(self) -> None:
+        while True:
+            batch = [self._queue.get()]
+            deadline = time.monotonic() + _UPLOAD_INTERVAL_SECONDS
+            while len(batch) < _UPLOAD_BATCH_SIZE:
+                remaining = deadline - time.monotonic()
+                if remaining <= 0:
+                    break
+                try:
+                    batch.append(self._queue.get(timeout=remaining))
+                except queue.Empty:
+                    break
+            self.upload(batch)
+
+    def object_url(self, now: Optional[datetime.datetime] = None) -> str:
+        now = now or datetime.datetime.now(datetime.timezone.utc)
+        name = f"{now:%Y/%m/%d}/{now:%H%M%S}-{uuid.uuid4().hex[:12]}.jsonl"
+        return "/".join(part for part in (self.url, self.prefix, name) if part)
+
+    def upload(self, entries: List[Dict[str, Any]]) -> None:
+        url = self.object_url()
+        request = urllib.request.Request(
+            url,
+            data="".join(json.dumps(entry, separators=(",", ":")) + "\n" for entry in entries).encode(),
+            headers={"Content-Type": "application/x-ndjson"},
+            method="PUT",
+        )
+        try:
+            with urllib.request.urlopen(request, timeout=_UPLOAD_TIMEOUT_SECONDS) as response:
+                response.read()
+        except Exception as e:
+            logger.warning(f"[AccessLog] Failed to upload {len(entries)} entries to {url}: {e}")
+
+
+def build_sink(config: AccessLogConfig, workspace: str = "", endpoint: str = "") -> Optional[Any]:
+    if config.sink == SINK_STDOUT:
+        return StdoutSink()
+    if config.sink == SINK_OBJECT_STORE and config.url:
+        return ObjectStoreSink(config.url, "/".join(part for part in (workspace, endpoint) if part))
+
+    logger.warning(f"[AccessLog] Unsupported access log sink {config.sink!r}, access logging is disabled")
+    return None
+
+
+def _header(headers: List[Tuple[bytes, bytes]], name: bytes) -> Optional[str]:
+    for key, value in headers:
+        if key.lower() == name:
+            return value.decode("latin-1")
+    return None
+
+
+def _request_id(headers: List[Tuple[bytes, bytes]]) -> Optional[str]:
+    """Request ID set by the RequestIdPlugin of the application, or sent by the client."""
+    try:
+        from starlette_context import context
+
+        if context.exists() and context.get("X-Request-ID"):
+            return str(context.get("X-Request-ID"))
+    except Exception:
+        pass
+
+    return _header(headers, b"x-request-id")
+
+
+def _json_object(body: bytes) -> Dict[str, Any]:
+    if not body or len(body) > _MAX_PARSED_BODY_BYTES:
+        return {}
+    try:
+        parsed = json.loads(body)
+    except ValueError:
+        return {}
+    return parsed if isinstance(parsed, dict) else {}
+
+
+def _stream_usage(chunk: bytes) -> Optional[Dict[str, Any]]:
+    """Usage of the server-sent events of a response chunk, the last one reported."""
+    usage = None
+    for line in chunk.split(b"\n"):
+        line = line.strip()
+        if not line.startswith(b"data:") or b'"usage"' not in line:
+            continue
+        event = _json_object(line[len(b"data:"):].strip())
+        if isinstance(event.get("usage"), dict):
+            usage = event["usage"]
+    return usage
+
+
+class AccessLogMiddleware:
+    """ASGI middleware writing an access log entry of every sampled request to the sink."""
+
+    def __init__(self, app: Callable, config: Optional[AccessLogConfig] = None, sink: Optional[Any] = None):
+        self.app = app
+        self.config = config or AccessLogConfig.from_env()
+        self.workspace = os.environ.get(ENDPOINT_WORKSPACE_ENV, "")
+        self.endpoint = os.environ.get(ENDPOINT_NAME_ENV, "")
+        self.sink = sink
+        if self.sink is None and self.config.enabled:
+            self.sink = build_sink(self.config, self.workspace, self.endpoint)
+
+    async def __call__(self, scope: Scope, receive: Receive, send: Send) -> None:
+        if scope["type"] != "http" or self.sink is None or random.random() >= self.config.sample_ratio:
+            await self.app(scope, receive, send)
+            return
+
+        headers = list(scope.get("headers") or [])
+        entry = AccessLogEntry(
+            timestamp=datetime.datetime.now(datetime.timezone.utc).isoformat(timespec="milliseconds"),
+            request_id=_request_id(headers),
+            workspace=self.workspace,
+            endpoint=self.endpoint,
+            api_key=_header(headers, _CONSUMER_CUSTOM_ID_HEADER) or _header(headers, _CONSUMER_USERNAME_HEADER),
+            model=None,
+            method=scope.get("method", ""),
+            path=scope.get("path", ""),
+        )
+
+        request_body = bytearray()
+
+        async def receive_logged() -> Message:
+            message = await receive()
+            if message["type"] == "http.request" and len(request_body) <= _MAX_PARSED_BODY_BYTES:
+                request_body.extend(message.get("body", b""))
+                if not message.get("more_body", False):
+                    request = _json_object(bytes(request_body))
+                    if isinstance(request.get("model"), str):
+                        entry.model = request["model"]
+                    entry.stream = request.get("stream") is True
+            return message
+
+        response_body = bytearray()
+        streamed = False
+
+        async def send_logged(message: Message) -> None:
+            nonlocal streamed
+            if message["type"] == "http.response.start":
+                entry.status = int(message.get("status", 200))
+                streamed = (_header(list(message.get("headers") or []), b"content-type") or "").startswith("text/event-stream")
+            elif message["type"] == "http.response.body":
+                body = message.get("body", b"")
+                if streamed:
+                    usage = _stream_usage(body)
+                    if usage is not None:
+                        entry.set_usage(usage)
+                elif len(response_body) <= _MAX_PARSED_BODY_BYTES:
+                    response_body.extend(body)
+            await send(message)
+
+        start = time.monotonic()
+        try:
+            await self.app(scope, receive_logged, send_logged)
+        except BaseException:
+            if not entry.status:
+                entry.status = 500
+            raise
+        finally:
+            entry.latency_ms = round((time.monotonic() - start) * 1000, 3)
+            if not streamed:
+                entry.set_usage(_json_object(bytes(response_body)).get("usage"))
+            self._write(entry)
+
+    def _write(self, entry: AccessLogEntry) -> None:
+        try:
+            self.sink.write(entry.to_dict(self.config.fields))
+        except Exception as e:
+            logger.warning(f"[AccessLog] Failed to write access log entry: {e}")
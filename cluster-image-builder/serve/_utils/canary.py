@@ -0,0 +1,96 @@
+"""Canary traffic split of the Controller deployments.
+
+Set by the control plane in the ``canary`` arg of the stable application of an endpoint
+while the canary of an update runs next to it::
+
+    canary:
+      application: production_chat-model_canary
+      weight: 20
+
+The Controller sends ``weight`` percent of the requests to the Backend of the canary
+application and the others to its own Backend.
+"""
+
+import random
+from dataclasses import dataclass
+from typing import Any, Callable, Dict, Optional, Tuple
+
+
+@dataclass
+class CanaryConfig:
+    application: str = ""
+    weight: int = 0
+
+    @property
+    def enabled(self) -> bool:
+        return bool(self.application) and self.weight > 0
+
+
+def parse_canary_config(args: Dict[str, Any]) -> CanaryConfig:
+    canary = args.get("canary") or {}
+
+    return CanaryConfig(
+        application=canary.get("application") or "",
+        weight=min(100, max(0, int(canary.get("weight") or 0))),
+    )
+
+
+def _get_backend_handle(application: str) -> Any:
+    from ray import serve
+
+    return serve.get_deployment_handle("Backend", app_name=application)
+
+
+class CanaryHandle:
+    """Deployment handle sending a share of its calls to the Backend of the canary application.
+
+    Options and method lookups are recorded and replayed on the handle picked for each call, so
+    ``handle.options(stream=True).generate.remote(payload)`` works as on a deployment handle.
+    """
+
+    def __init__(self, stable: Any, canary: Callable[[], Any], weight: int,
+                 pick: Callable[[], float] = random.random, path: Tuple[Tuple[str, Any], ...] = ()):
+        self._stable = stable
+        self._canary = canary
+        self._weight = weight
+        self._pick = pick
+        self._path = path
+
+    def _chain(self, step: Tuple[str, Any]) -> "CanaryHandle":
+        return CanaryHandle(self._stable, self._canary, self._weight, self._pick, self._path + (step,))
+
+    def options(self, *args: Any, **kwargs: Any) -> "CanaryHandle":
+        return self._chain(("options", (args, kwargs)))
+
+    def __getattr__(self, name: str) -> "CanaryHandle":
+        if name.startswith("_"):
+            raise AttributeError(name)
+        return self._chain(("attr", name))
+
+    def remote(self, *args: Any, **kwargs: Any) -> Any:
+        target = self._canary() if self._pick() * 100 < self._weight else self._stable
+        for kind, value in self._path:
+            if kind == "options":
+                target = target.options(*value[0], **value[1])
+            else:
+                target = getattr(target, value)
+        return target.remote(*args, **kwargs)
+
+
+def canary_handle(handle: Any, config: CanaryConfig,
+                  get_handle: Callable[[str], Any] = _get_backend_handle) -> Any:
+    """Wrap the Backend handle of a Controller while a canary takes a share of the requests."""
+    if not config.enabled:
+        return handle
+
+    canary: Optional[Any] = None
+
+    # The canary handle is looked up on the first request sent to it, the canary application
+    # may be deployed after the Controller of the stable one starts.
+    def resolve() -> Any:
+        nonlocal canary
+        if canary is None:
+            canary = get_handle(config.application)
+        return canary
+
+    return CanaryHandle(handle, resolve, config.weight)
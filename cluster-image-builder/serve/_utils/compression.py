@@ -0,0 +1,232 @@
+"""Request decompression and response compression of the Controller deployments.
+
+Configured through ``deployment_options.compression`` of the endpoint, which the
+orchestrator passes to the application as environment variables::
+
+    compression:
+      request: true        # accept gzip encoded request bodies
+      response: true       # gzip responses of clients sending Accept-Encoding: gzip
+      min_size_bytes: 1024 # smaller responses are sent as is
+
+Requests without Content-Encoding and clients not accepting gzip pass through
+unchanged. Streamed responses are compressed chunk by chunk and flushed after
+every chunk, so server-sent events reach the client as soon as they are sent.
+"""
+
+import json
+import os
+import zlib
+from dataclasses import dataclass
+from typing import Any, Awaitable, Callable, Dict, List, Optional, Tuple
+
+COMPRESSION_REQUEST_ENV = "NEUTREE_COMPRESSION_REQUEST"
+COMPRESSION_RESPONSE_ENV = "NEUTREE_COMPRESSION_RESPONSE"
+COMPRESSION_MIN_SIZE_ENV = "NEUTREE_COMPRESSION_MIN_SIZE"
+
+DEFAULT_MIN_SIZE = 1024
+
+# gzip container of zlib streams.
+_GZIP_WBITS = 16 + zlib.MAX_WBITS
+
+Scope = Dict[str, Any]
+Message = Dict[str, Any]
+Receive = Callable[[], Awaitable[Message]]
+Send = Callable[[Message], Awaitable[None]]
+
+
+@dataclass
+class CompressionConfig:
+    request: bool = False
+    response: bool = False
+    min_size: int = DEFAULT_MIN_SIZE
+
+    @classmethod
+    def from_env(cls) -> "CompressionConfig":
+        return cls(
+            request=os.environ.get(COMPRESSION_REQUEST_ENV, "").lower() == "true",
+            response=os.environ.get(COMPRESSION_RESPONSE_ENV, "").lower() == "true",
+            min_size=int(os.environ.get(COMPRESSION_MIN_SIZE_ENV) or DEFAULT_MIN_SIZE),
+        )
+
+
+def _header(headers: List[Tuple[bytes, bytes]], name: bytes) -> Optional[str]:
+    for key, value in headers:
+        if key.lower() == name:
+            return value.decode("latin-1")
+    return None
+
+
+def _without(headers: List[Tuple[bytes, bytes]], *names: bytes) -> List[Tuple[bytes, bytes]]:
+    return [(key, value) for key, value in headers if key.lower() not in names]
+
+
+def accepts_gzip(accept_encoding: Optional[str]) -> bool:
+    """Whether an Accept-Encoding header negotiates gzip, honoring q=0."""
+    if not accept_encoding:
+        return False
+
+    for coding in accept_encoding.split(","):
+        name, _, params = coding.strip().partition(";")
+        if name.strip().lower() not in ("gzip", "*"):
+            continue
+
+        q = 1.0
+        for param in params.split(";"):
+            key, _, value = param.strip().partition("=")
+            if key == "q":
+                try:
+                    q = float(value)
+                except ValueError:
+                    q = 0.0
+        return q > 0
+
+    return False
+
+
+async def _error(send: Send, status: int, message: str) -> None:
+    body = json.dumps({"error": {"message": message, "code": status}}).encode()
+    await send({
+        "type": "http.response.start",
+        "status": status,
+        "headers": [(b"content-type", b"application/json"), (b"content-length", str(len(body)).encode())],
+    })
+    await send({"type": "http.response.body", "body": body})
+
+
+class CompressionMiddleware:
+    """ASGI middleware gzip decoding request bodies and encoding responses."""
+
+    def __init__(self, app: Callable, config: Optional[CompressionConfig] = None):
+        self.app = app
+        self.config = config or CompressionConfig.from_env()
+
+    async def __call__(self, scope: Scope, receive: Receive, send: Send) -> None:
+        if scope["type"] != "http":
+            await self.app(scope, receive, send)
+            return
+
+        headers = list(scope.get("headers") or [])
+
+        encoding = (_header(headers, b"content-encoding") or "identity").strip().lower()
+        if encoding != "identity":
+            if encoding not in ("gzip", "x-gzip") or not self.config.request:
+                await _error(send, 415, f"unsupported request content encoding {encoding}")
+                return
+
+            try:
+                body = await self._decompressed_body(receive)
+            except zlib.error as e:
+                await _error(send, 400, f"invalid gzip request body: {e}")
+                return
+
+            headers = _without(headers, b"content-encoding", b"content-length")
+            headers.append((b"content-length", str(len(body)).encode()))
+            scope = {**scope, "headers": headers}
+            receive = self._replay(body, receive)
+
+        if self.config.response and accepts_gzip(_header(headers, b"accept-encoding")):
+            send = _GzipResponder(send, self.config.min_size).send
+
+        await self.app(scope, receive, send)
+
+    @staticmethod
+    async def _decompressed_body(receive: Receive) -> bytes:
+        decompressor = zlib.decompressobj(_GZIP_WBITS)
+        chunks = []
+
+        while True:
+            message = await receive()
+            if message["type"] == "http.disconnect":
+                break
+
+            chunks.append(decompressor.decompress(message.get("body", b"")))
+            if not message.get("more_body", False):
+                break
+
+        chunks.append(decompressor.flush())
+        if not decompressor.eof:
+            raise zlib.error("truncated gzip stream")
+
+        return b"".join(chunks)
+
+    @staticmethod
+    def _replay(body: bytes, receive: Receive) -> Receive:
+        sent = False
+
+        async def replay() -> Message:
+            nonlocal sent
+            if sent:
+                # the body is consumed, wait for the client to disconnect.
+                return await receive()
+
+            sent = True
+            return {"type": "http.request", "body": body, "more_body": False}
+
+        return replay
+
+
+class _GzipResponder:
+    def __init__(self, send: Send, min_size: int):
+        self._send = send
+        self._min_size = min_size
+        self._start: Optional[Message] = None
+        self._compressor = None
+        self._passthrough = False
+
+    async def send(self, message: Message) -> None:
+        if message["type"] == "http.response.start":
+            self._start = message
+            # already encoded responses are sent as is.
+            self._passthrough = _header(list(message.get("headers") or []), b"content-encoding") is not None
+            return
+
+        if message["type"] != "http.response.body":
+            await self._send(message)
+            return
+
+        if self._start is not None:
+            start, self._start = self._start, None
+            await self._first_body(start, message)
+            return
+
+        if self._compressor is None:
+            await self._send(message)
+            return
+
+        body = self._compressor.compress(message.get("body", b""))
+        if message.get("more_body", False):
+            body += self._compressor.flush(zlib.Z_SYNC_FLUSH)
+        else:
+            body += self._compressor.flush(zlib.Z_FINISH)
+
+        await self._send({**message, "body": body})
+
+    async def _first_body(self, start: Message, message: Message) -> None:
+        body = message.get("body", b"")
+        more_body = message.get("more_body", False)
+
+        if self._passthrough or (not more_body and len(body) < self._min_size):
+            await self._send(start)
+            await self._send(message)
+            return
+
+        headers = _without(list(start.get("headers") or []), b"content-length", b"content-encoding")
+        headers.append((b"content-encoding", b"gzip"))
+        vary = _header(headers, b"vary")
+        if vary is None:
+            headers.append((b"vary", b"Accept-Encoding"))
+        elif "accept-encoding" not in vary.lower():
+            headers = _without(headers, b"vary") + [(b"vary", f"{vary}, Accept-Encoding".encode())]
+
+        self._compressor = zlib.compressobj(wbits=_GZIP_WBITS)
+        compressed = self._compressor.compress(body)
+
+        if more_body:
+            # streamed responses are flushed per chunk so events are not held back.
+            compressed += self._compressor.flush(zlib.Z_SYNC_FLUSH)
+        else:
+            compressed += self._compressor.flush(zlib.Z_FINISH)
+            headers.append((b"content-length", str(len(compressed)).encode()))
+
+        await self._send({**start, "headers": headers})
+        await self._send({**message, "body": compressed})
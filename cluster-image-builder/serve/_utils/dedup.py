@@ -0,0 +1,134 @@
+"""Request deduplication by idempotency key for the Controller deployments.
+
+Configured through ``deployment_options.router.deduplication`` of the endpoint::
+
+    router:
+      deduplication:
+        ttl_seconds: 300
+        max_entries: 1000
+
+A non-streaming generation request carrying an ``Idempotency-Key`` header runs once per key,
+route and API key: a duplicate received while it runs waits for its result, a duplicate
+received within ``ttl_seconds`` after it completed gets the same result without running again.
+A request reusing the key with another body is rejected with 422 instead of getting the result
+of another request. Keys are scoped to the API key Kong authenticated the request with, so a
+client can not get the result of another client guessing its keys. Failed requests are not
+remembered, so their retries run again.
+
+Results are kept in memory per Controller replica, in a cache bounded to ``max_entries``
+dropping the oldest keys first. A duplicate routed to another Controller replica runs again.
+"""
+
+import asyncio
+import hashlib
+import math
+import time
+from collections import OrderedDict
+from dataclasses import dataclass
+from typing import Any, Awaitable, Callable, Dict, NamedTuple, Optional, Tuple, TypeVar
+
+T = TypeVar("T")
+
+IDEMPOTENCY_KEY_HEADER = "Idempotency-Key"
+# Header Kong's key-auth sets to the custom ID of the consumer, the ID of the API key.
+CONSUMER_CUSTOM_ID_HEADER = "x-consumer-custom-id"
+
+
+class IdempotencyKey(NamedTuple):
+    key: str
+    # Digest of the request body, the duplicates of a request have the same body.
+    body_digest: str
+
+
+class IdempotencyKeyMismatch(Exception):
+    """The idempotency key was used by a request with another body, answered with 422."""
+
+
+@dataclass
+class DedupConfig:
+    # 0 disables deduplication.
+    ttl_seconds: float = 0.0
+    max_entries: int = 1000
+
+    @property
+    def enabled(self) -> bool:
+        return self.ttl_seconds > 0 and self.max_entries > 0
+
+
+def parse_dedup_config(deployment_options: Dict[str, Any]) -> DedupConfig:
+    router_options = deployment_options.get("router") or {}
+    dedup_options = router_options.get("deduplication") or {}
+
+    return DedupConfig(
+        ttl_seconds=max(0.0, float(dedup_options.get("ttl_seconds") or 0)),
+        max_entries=max(0, int(dedup_options.get("max_entries") or 1000)),
+    )
+
+
+async def idempotency_key(request: Any) -> Optional[IdempotencyKey]:
+    """Return the deduplication key of an HTTP request, None when it has no idempotency key."""
+    key = request.headers.get(IDEMPOTENCY_KEY_HEADER)
+    if not key:
+        return None
+
+    consumer = request.headers.get(CONSUMER_CUSTOM_ID_HEADER, "")
+    body = await request.body()
+
+    return IdempotencyKey(f"{consumer} {request.url.path} {key}", hashlib.sha256(body).hexdigest())
+
+
+class RequestDeduplicator:
+    """Runs the calls of the same key once, see the module documentation."""
+
+    def __init__(self, config: DedupConfig, clock: Callable[[], float] = time.monotonic):
+        self.config = config
+        self._clock = clock
+        # Key -> (expiry, result future, body digest), in insertion order. Running calls never
+        # expire.
+        self._entries: "OrderedDict[str, Tuple[float, asyncio.Future, str]]" = OrderedDict()
+
+    async def run(self, idempotency: Optional[IdempotencyKey], call: Callable[[], Awaitable[T]]) -> T:
+        if not self.config.enabled or not idempotency:
+            return await call()
+
+        self._evict_expired()
+
+        key = idempotency.key
+        entry = self._entries.get(key)
+        if entry is not None:
+            if entry[2] != idempotency.body_digest:
+                raise IdempotencyKeyMismatch(
+                    f"{IDEMPOTENCY_KEY_HEADER} was used by a request with another body")
+            return await asyncio.shield(entry[1])
+
+        future = asyncio.get_running_loop().create_future()
+        self._entries[key] = (math.inf, future, idempotency.body_digest)
+        while len(self._entries) > self.config.max_entries:
+            self._entries.popitem(last=False)
+
+        try:
+            result = await call()
+        except BaseException as e:
+            self._forget(key, future)
+            if isinstance(e, asyncio.CancelledError):
+                future.cancel()
+            else:
+                future.set_exception(e)
+                # Retrieved here so asyncio does not log it when no duplicate waits for it.
+                future.exception()
+            raise
+
+        future.set_result(result)
+        if self._entries.get(key, (None, None, None))[1] is future:
+            self._entries[key] = (self._clock() + self.config.ttl_seconds, future, idempotency.body_digest)
+
+        return result
+
+    def _forget(self, key: str, future: asyncio.Future):
+        if self._entries.get(key, (None, None, None))[1] is future:
+            del self._entries[key]
+
+    def _evict_expired(self):
+        now = self._clock()
+        for key in [key for key, (expiry, _, _) in self._entries.items() if expiry <= now]:
+            del self._entries[key]
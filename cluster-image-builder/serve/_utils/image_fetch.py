@@ -0,0 +1,226 @@
+"""Fetching of the image URLs of chat requests for the Controller deployments.
+
+Configured through ``deployment_options.router.image_fetch`` of the endpoint::
+
+    router:
+      image_fetch:
+        max_bytes: 10485760
+        timeout_seconds: 10
+
+The ``image_url`` parts of the chat messages pointing at http(s) URLs are fetched by the
+router and sent to the engine as base64 data URLs, so clients do not have to embed the
+images and engines without network access can serve them. Data URLs are passed as they are.
+
+URLs resolving to an address which is not globally routable, e.g. loopback, private or
+link-local addresses, are rejected, as are redirects to them, so requests cannot make the
+router reach internal services. The router connects to the address it validated rather than
+resolving the host again.
+"""
+
+import asyncio
+import base64
+import http.client
+import ipaddress
+import socket
+import ssl
+from dataclasses import dataclass
+from typing import Any, Awaitable, Callable, Dict, List, Optional, Tuple
+from urllib.parse import urljoin, urlsplit
+
+DEFAULT_MAX_BYTES = 10 * 1024 * 1024
+DEFAULT_TIMEOUT_SECONDS = 10.0
+MAX_REDIRECTS = 3
+
+_REDIRECT_STATUSES = {301, 302, 303, 307, 308}
+
+# (status, lower-cased headers, body) of a response.
+Response = Tuple[int, Dict[str, str], bytes]
+
+
+class ImageFetchError(Exception):
+    """Raised when an image URL of a request cannot be fetched, the request is rejected."""
+
+
+@dataclass
+class ImageFetchConfig:
+    # 0 disables image fetching.
+    max_bytes: int = 0
+    timeout_seconds: float = DEFAULT_TIMEOUT_SECONDS
+
+    @property
+    def enabled(self) -> bool:
+        return self.max_bytes > 0
+
+
+def parse_image_fetch_config(deployment_options: Dict[str, Any]) -> ImageFetchConfig:
+    router_options = deployment_options.get("router") or {}
+    image_fetch_options = router_options.get("image_fetch")
+    if image_fetch_options is None:
+        return ImageFetchConfig()
+
+    return ImageFetchConfig(
+        max_bytes=int(image_fetch_options.get("max_bytes") or DEFAULT_MAX_BYTES),
+        timeout_seconds=float(image_fetch_options.get("timeout_seconds") or DEFAULT_TIMEOUT_SECONDS),
+    )
+
+
+def is_public_address(address: str) -> bool:
+    """Return whether the IP address is globally routable, i.e. outside the internal ranges."""
+    try:
+        ip = ipaddress.ip_address(address.split("%", 1)[0])
+    except ValueError:
+        return False
+
+    if isinstance(ip, ipaddress.IPv6Address) and ip.ipv4_mapped is not None:
+        ip = ip.ipv4_mapped
+
+    return ip.is_global and not ip.is_multicast
+
+
+async def _resolve(host: str, port: int) -> List[str]:
+    infos = await asyncio.get_running_loop().getaddrinfo(host, port, type=socket.SOCK_STREAM)
+    return [info[4][0] for info in infos]
+
+
+class _PinnedHTTPConnection(http.client.HTTPConnection):
+    """Connects to the validated address while sending the host of the URL."""
+
+    def __init__(self, host: str, address: str, **kwargs):
+        super().__init__(host, **kwargs)
+        self._address = address
+
+    def connect(self):
+        self.sock = socket.create_connection((self._address, self.port), self.timeout)
+
+
+class _PinnedHTTPSConnection(http.client.HTTPSConnection):
+    """Connects to the validated address while verifying the certificate of the URL host."""
+
+    def __init__(self, host: str, address: str, **kwargs):
+        super().__init__(host, context=ssl.create_default_context(), **kwargs)
+        self._address = address
+
+    def connect(self):
+        sock = socket.create_connection((self._address, self.port), self.timeout)
+        self.sock = self._context.wrap_socket(sock, server_hostname=self.host)
+
+
+def _request(url: str, address: str, config: ImageFetchConfig) -> Response:
+    parts = urlsplit(url)
+    connection_class = _PinnedHTTPSConnection if parts.scheme == "https" else _PinnedHTTPConnection
+    connection = connection_class(parts.hostname, address, port=parts.port, timeout=config.timeout_seconds)
+
+    path = parts.path or "/"
+    if parts.query:
+        path += "?" + parts.query
+
+    try:
+        connection.request("GET", path, headers={"Accept": "image/*"})
+        response = connection.getresponse()
+        headers = {name.lower(): value for name, value in response.getheaders()}
+        # One byte more than allowed tells the image is too large.
+        body = response.read(config.max_bytes + 1) if response.status == 200 else b""
+        return response.status, headers, body
+    finally:
+        connection.close()
+
+
+class ImageFetcher:
+    """Replaces the image URLs of chat requests with data URLs, see the module documentation."""
+
+    def __init__(
+        self,
+        config: ImageFetchConfig,
+        resolve: Callable[[str, int], Awaitable[List[str]]] = _resolve,
+        request: Callable[[str, str, ImageFetchConfig], Response] = _request,
+    ):
+        self.config = config
+        self._resolve = resolve
+        self._request = request
+
+    async def embed_images(self, body: Dict[str, Any]) -> Dict[str, Any]:
+        """Fetch the http(s) image URLs of the chat messages into data URLs, in place."""
+        if not self.config.enabled:
+            return body
+
+        image_urls = [image_url for image_url in _image_urls(body) if _is_remote(image_url["url"])]
+        data_urls = await asyncio.gather(*(self.fetch(image_url["url"]) for image_url in image_urls))
+
+        for image_url, data_url in zip(image_urls, data_urls):
+            image_url["url"] = data_url
+
+        return body
+
+    async def fetch(self, url: str) -> str:
+        """Fetch the image at url and return it as a base64 data URL."""
+        for _ in range(MAX_REDIRECTS + 1):
+            address = await self._public_address(url)
+
+            try:
+                status, headers, content = await asyncio.to_thread(self._request, url, address, self.config)
+            except (OSError, http.client.HTTPException) as e:
+                raise ImageFetchError(f"failed to fetch image {url}: {e}") from e
+
+            if status in _REDIRECT_STATUSES and headers.get("location"):
+                url = urljoin(url, headers["location"])
+                continue
+
+            if status != 200:
+                raise ImageFetchError(f"failed to fetch image {url}: status {status}")
+
+            content_type = headers.get("content-type", "").split(";", 1)[0].strip().lower()
+            if not content_type.startswith("image/"):
+                raise ImageFetchError(f"{url} is not an image, its content type is {content_type or 'missing'}")
+
+            if len(content) > self.config.max_bytes:
+                raise ImageFetchError(f"image {url} is larger than {self.config.max_bytes} bytes")
+
+            return f"data:{content_type};base64,{base64.b64encode(content).decode('ascii')}"
+
+        raise ImageFetchError(f"image {url} redirected more than {MAX_REDIRECTS} times")
+
+    async def _public_address(self, url: str) -> str:
+        parts = urlsplit(url)
+        if parts.scheme not in ("http", "https") or not parts.hostname:
+            raise ImageFetchError(f"unsupported image URL {url}")
+
+        port = parts.port or (443 if parts.scheme == "https" else 80)
+
+        try:
+            addresses = await self._resolve(parts.hostname, port)
+        except OSError as e:
+            raise ImageFetchError(f"failed to resolve image host {parts.hostname}: {e}") from e
+
+        # Every address is checked, the host must not resolve to an internal one at all.
+        if not addresses or not all(is_public_address(address) for address in addresses):
+            raise ImageFetchError(f"image host {parts.hostname} resolves to a non-public address")
+
+        return addresses[0]
+
+
+def _is_remote(url: Any) -> bool:
+    return isinstance(url, str) and urlsplit(url).scheme != "data"
+
+
+def _image_urls(body: Dict[str, Any]) -> List[Dict[str, Any]]:
+    """Return the image_url objects of the content parts of the chat messages."""
+    image_urls = []
+
+    for message in body.get("messages") or []:
+        content = message.get("content") if isinstance(message, dict) else None
+        if not isinstance(content, list):
+            continue
+
+        for part in content:
+            if not isinstance(part, dict) or part.get("type") != "image_url":
+                continue
+
+            image_url: Optional[Any] = part.get("image_url")
+            # Some clients send the URL itself rather than an object.
+            if isinstance(image_url, str):
+                image_url = part["image_url"] = {"url": image_url}
+
+            if isinstance(image_url, dict) and "url" in image_url:
+                image_urls.append(image_url)
+
+    return image_urls
@@ -0,0 +1,37 @@
+"""Helpers for scheduling Backend replicas into a placement group created ahead of the endpoint."""
+
+from typing import Any, Dict
+
+# ray_actor_options fields and the Ray resources they request.
+_ACTOR_RESOURCE_OPTIONS = (("num_cpus", "CPU"), ("num_gpus", "GPU"), ("memory", "memory"))
+
+
+def schedule_in_placement_group(name: str, ray_actor_options: Dict[str, Any]) -> Dict[str, Any]:
+    """Request the replica resources from the bundles of the named placement group.
+
+    Ray Serve only creates a placement group per replica. Ray exposes the resources
+    reserved by a placement group as ``<resource>_group_<placement group id>`` though,
+    so replicas requesting those are gang scheduled into the named group, and get the
+    GPUs reserved by it assigned.
+    """
+    import ray
+
+    pg_id = ray.util.get_placement_group(name).id.hex()
+
+    resources = {
+        f"{resource}_group_{pg_id}": amount
+        for resource, amount in (ray_actor_options.get("resources") or {}).items()
+        if amount
+    }
+
+    for option, resource in _ACTOR_RESOURCE_OPTIONS:
+        amount = ray_actor_options.get(option)
+        if amount:
+            resources[f"{resource}_group_{pg_id}"] = amount
+
+    ray_actor_options["num_cpus"] = 0
+    ray_actor_options["num_gpus"] = 0
+    ray_actor_options["memory"] = None
+    ray_actor_options["resources"] = resources
+
+    return ray_actor_options
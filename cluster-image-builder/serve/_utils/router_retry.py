@@ -0,0 +1,129 @@
+"""Router-level retry, timeout and circuit breaking settings for the Controller deployments.
+
+Configured through ``deployment_options.router`` of the endpoint::
+
+    router:
+      retries: 2
+      request_timeout_seconds: 600
+      circuit_breaker:
+        failure_threshold: 3
+        open_seconds: 30
+
+Retries back off exponentially with full jitter, so a failing replica set is not hit in a
+tight loop and the retries of concurrent requests spread out.
+
+Only non-streaming requests are retried and bounded by the request timeout: they are
+idempotent, while a stream may already have sent tokens to the client when its replica
+fails, and keeps sending them for as long as the generation takes.
+"""
+
+import asyncio
+import logging
+import random
+from dataclasses import dataclass
+from typing import Any, Awaitable, Callable, Dict, Optional, TypeVar
+
+logger = logging.getLogger("ray.serve")
+
+T = TypeVar("T")
+
+# Errors raised by Ray when the replica itself failed rather than the request,
+# e.g. while its pod is restarting. Matched by name so this module does not import ray.
+_TRANSIENT_REPLICA_ERRORS = {
+    "ActorDiedError",
+    "ActorUnavailableError",
+    "RayActorError",
+}
+
+
+# Backoff before the first retry, doubled for every further retry up to the maximum.
+_RETRY_BACKOFF_BASE_SECONDS = 0.1
+_RETRY_BACKOFF_MAX_SECONDS = 2.0
+
+
+class RequestTimeoutError(TimeoutError):
+    """Raised when a request is not answered within the router request timeout."""
+
+
+# Timeouts answered with 504, whether raised by the router request timeout or by the
+# backend. asyncio.TimeoutError is only an alias of TimeoutError from Python 3.11.
+TIMEOUT_ERRORS = (TimeoutError, asyncio.TimeoutError)
+
+
+def is_timeout_error(exc: BaseException) -> bool:
+    return isinstance(exc, TIMEOUT_ERRORS)
+
+
+@dataclass
+class RouterRetryConfig:
+    retries: int = 0
+    # 0 leaves requests unbounded, endpoints are created with a task-based default.
+    request_timeout_seconds: float = 0.0
+    circuit_failure_threshold: int = 0
+    circuit_open_seconds: float = 30.0
+
+    @property
+    def circuit_breaker_enabled(self) -> bool:
+        return self.circuit_failure_threshold > 0
+
+    def request_router_kwargs(self) -> Dict[str, Any]:
+        """Return the circuit breaker kwargs understood by the Neutree request routers."""
+        if not self.circuit_breaker_enabled:
+            return {}
+
+        return {
+            "circuit_failure_threshold": self.circuit_failure_threshold,
+            "circuit_open_seconds": self.circuit_open_seconds,
+        }
+
+
+def parse_router_retry_config(deployment_options: Dict[str, Any]) -> RouterRetryConfig:
+    router_options = deployment_options.get("router") or {}
+    circuit_options = router_options.get("circuit_breaker") or {}
+
+    return RouterRetryConfig(
+        retries=max(0, int(router_options.get("retries") or 0)),
+        request_timeout_seconds=max(0.0, float(router_options.get("request_timeout_seconds") or 0)),
+        circuit_failure_threshold=max(0, int(circuit_options.get("failure_threshold") or 0)),
+        circuit_open_seconds=float(circuit_options.get("open_seconds") or 30.0),
+    )
+
+
+def is_transient_replica_error(exc: BaseException) -> bool:
+    return any(cls.__name__ in _TRANSIENT_REPLICA_ERRORS for cls in type(exc).__mro__)
+
+
+async def call_with_retry(call: Callable[[], Awaitable[T]], retries: int, timeout: Optional[float] = None) -> T:
+    """Await ``call()`` and retry it up to ``retries`` times on transient replica errors.
+
+    Each attempt issues a new request through the deployment handle, so the router
+    picks a replica again and skips replicas whose circuit is open. ``timeout`` bounds
+    all attempts together, RequestTimeoutError is raised when it expires.
+    """
+    if not timeout:
+        return await _call_with_retry(call, retries)
+
+    try:
+        return await asyncio.wait_for(_call_with_retry(call, retries), timeout)
+    except asyncio.TimeoutError:
+        raise RequestTimeoutError(f"request did not complete within {timeout:g} seconds") from None
+
+
+def retry_backoff_seconds(attempt: int) -> float:
+    """Delay before the given retry, 1 for the first one: a random delay up to the exponential backoff."""
+    backoff = min(_RETRY_BACKOFF_MAX_SECONDS, _RETRY_BACKOFF_BASE_SECONDS * 2 ** (attempt - 1))
+    return random.uniform(0, backoff)
+
+
+async def _call_with_retry(call: Callable[[], Awaitable[T]], retries: int) -> T:
+    attempt = 0
+    while True:
+        try:
+            return await call()
+        except Exception as e:
+            if attempt >= retries or not is_transient_replica_error(e):
+                raise
+
+            attempt += 1
+            logger.warning(f"[Controller] Replica failed with {type(e).__name__}, retrying ({attempt}/{retries}): {e}")
+            await asyncio.sleep(retry_backoff_seconds(attempt))
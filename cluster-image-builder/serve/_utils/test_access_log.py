@@ -0,0 +1,218 @@
+"""Tests for serve._utils.access_log."""
+
+import asyncio
+import datetime
+import io
+import json
+
+import pytest
+
+from serve._utils.access_log import (
+    FIELDS,
+    AccessLogConfig,
+    AccessLogMiddleware,
+    ObjectStoreSink,
+    StdoutSink,
+    build_sink,
+)
+
+ENABLED = AccessLogConfig(sink="stdout")
+
+
+class FakeSink:
+    def __init__(self):
+        self.entries = []
+
+    def write(self, entry):
+        self.entries.append(entry)
+
+
+def json_app(status, body):
+    async def app(scope, receive, send):
+        while (await receive()).get("more_body"):
+            pass
+        await send({"type": "http.response.start", "status": status, "headers": [(b"content-type", b"application/json")]})
+        await send({"type": "http.response.body", "body": json.dumps(body).encode()})
+
+    return app
+
+
+def call(middleware, request, headers=()):
+    body = json.dumps(request).encode()
+    chunks = [body[:10], body[10:]]
+
+    async def receive():
+        chunk = chunks.pop(0)
+        return {"type": "http.request", "body": chunk, "more_body": bool(chunks)}
+
+    async def send(message):
+        pass
+
+    scope = {"type": "http", "method": "POST", "path": "/v1/chat/completions", "headers": list(headers)}
+    asyncio.run(middleware(scope, receive, send))
+
+
+def test_config_from_env(monkeypatch):
+    assert not AccessLogConfig.from_env().enabled
+
+    monkeypatch.setenv("NEUTREE_ACCESS_LOG_SINK", "object_store")
+    monkeypatch.setenv("NEUTREE_ACCESS_LOG_URL", "https://logs.example.com/neutree/")
+    monkeypatch.setenv("NEUTREE_ACCESS_LOG_FIELDS", "timestamp,model,unknown,total_tokens")
+    monkeypatch.setenv("NEUTREE_ACCESS_LOG_SAMPLE_RATIO", "0.5")
+
+    config = AccessLogConfig.from_env()
+    assert config == AccessLogConfig(
+        sink="object_store",
+        url="https://logs.example.com/neutree",
+        fields=("timestamp", "model", "total_tokens"),
+        sample_ratio=0.5,
+    )
+    assert config.enabled
+
+
+def test_logs_request_metadata(monkeypatch):
+    monkeypatch.setenv("NEUTREE_ENDPOINT_NAME", "chat")
+    monkeypatch.setenv("NEUTREE_ENDPOINT_WORKSPACE", "default")
+    sink = FakeSink()
+    response = {"id": "1", "choices": [], "usage": {"prompt_tokens": 12, "completion_tokens": 30, "total_tokens": 42}}
+    middleware = AccessLogMiddleware(json_app(200, response), ENABLED, sink)
+
+    call(middleware, {"model": "qwen", "messages": []}, [
+        (b"x-consumer-custom-id", b"api-key-id"),
+        (b"authorization", b"Bearer sk-secret"),
+        (b"x-request-id", b"req-1"),
+    ])
+
+    assert len(sink.entries) == 1
+    entry = sink.entries[0]
+    assert list(entry) == list(FIELDS)
+    assert datetime.datetime.fromisoformat(entry["timestamp"]).tzinfo is not None
+    assert entry["latency_ms"] >= 0
+    assert {k: v for k, v in entry.items() if k not in ("timestamp", "latency_ms")} == {
+        "request_id": "req-1",
+        "workspace": "default",
+        "endpoint": "chat",
+        "api_key": "api-key-id",
+        "model": "qwen",
+        "method": "POST",
+        "path": "/v1/chat/completions",
+        "status": 200,
+        "stream": False,
+        "prompt_tokens": 12,
+        "completion_tokens": 30,
+        "total_tokens": 42,
+    }
+    # The key itself is never logged.
+    assert "sk-secret" not in json.dumps(entry)
+
+
+def test_logs_usage_of_streamed_responses():
+    sink = FakeSink()
+
+    async def app(scope, receive, send):
+        while (await receive()).get("more_body"):
+            pass
+        await send({"type": "http.response.start", "status": 200, "headers": [(b"content-type", b"text/event-stream")]})
+        await send({"type": "http.response.body", "body": b'data: {"choices":[{"delta":{"content":"hi"}}]}\n\n', "more_body": True})
+        await send({"type": "http.response.body", "body": b'data: {"choices":[],"usage":{"prompt_tokens":5,"completion_tokens":1,"total_tokens":6}}\n\ndata: [DONE]\n\n'})
+
+    call(AccessLogMiddleware(app, ENABLED, sink), {"model": "qwen", "stream": True})
+
+    entry = sink.entries[0]
+    assert entry["stream"] is True
+    assert (entry["prompt_tokens"], entry["completion_tokens"], entry["total_tokens"]) == (5, 1, 6)
+
+
+def test_selected_fields_only():
+    sink = FakeSink()
+    config = AccessLogConfig(sink="stdout", fields=("model", "status"))
+
+    call(AccessLogMiddleware(json_app(400, {"message": "bad request"}), config, sink), {"model": "qwen"})
+
+    assert sink.entries == [{"model": "qwen", "status": 400}]
+
+
+def test_server_error_is_logged():
+    sink = FakeSink()
+
+    async def failing(scope, receive, send):
+        raise RuntimeError("replica failed")
+
+    with pytest.raises(RuntimeError):
+        call(AccessLogMiddleware(failing, ENABLED, sink), {"model": "qwen"})
+
+    assert sink.entries[0]["status"] == 500
+    assert sink.entries[0]["total_tokens"] is None
+
+
+def test_sampling():
+    sink = FakeSink()
+    app = json_app(200, {})
+
+    for _ in range(5):
+        call(AccessLogMiddleware(app, AccessLogConfig(sink="stdout", sample_ratio=0), sink), {"model": "qwen"})
+    assert sink.entries == []
+
+    for _ in range(5):
+        call(AccessLogMiddleware(app, AccessLogConfig(sink="stdout", sample_ratio=1), sink), {"model": "qwen"})
+    assert len(sink.entries) == 5
+
+
+def test_pass_through_when_disabled():
+    middleware = AccessLogMiddleware(json_app(200, {}), AccessLogConfig())
+
+    assert middleware.sink is None
+    call(middleware, {"model": "qwen"})
+
+
+def test_build_sink():
+    assert isinstance(build_sink(AccessLogConfig(sink="stdout")), StdoutSink)
+
+    sink = build_sink(AccessLogConfig(sink="object_store", url="https://logs.example.com/neutree"), "default", "chat")
+    assert isinstance(sink, ObjectStoreSink)
+    url = sink.object_url(datetime.datetime(2026, 3, 4, 5, 6, 7, tzinfo=datetime.timezone.utc))
+    assert url.startswith("https://logs.example.com/neutree/default/chat/2026/03/04/050607-")
+    assert url.endswith(".jsonl")
+
+    assert build_sink(AccessLogConfig(sink="object_store")) is None
+    assert build_sink(AccessLogConfig(sink="syslog")) is None
+
+
+def test_stdout_sink_writes_json_lines():
+    stream = io.StringIO()
+    sink = StdoutSink(stream)
+
+    sink.write({"model": "qwen", "status": 200})
+    sink.write({"model": "qwen", "status": 500})
+
+    assert [json.loads(line) for line in stream.getvalue().splitlines()] == [
+        {"model": "qwen", "status": 200},
+        {"model": "qwen", "status": 500},
+    ]
+
+
+def test_object_store_sink_uploads_ndjson(monkeypatch):
+    requests = []
+
+    class FakeResponse:
+        def __enter__(self):
+            return self
+
+        def __exit__(self, *args):
+            return False
+
+        def read(self):
+            return b""
+
+    def urlopen(request, timeout):
+        requests.append(request)
+        return FakeResponse()
+
+    monkeypatch.setattr("urllib.request.urlopen", urlopen)
+
+    ObjectStoreSink("https://logs.example.com", "default/chat").upload([{"status": 200}, {"status": 500}])
+
+    assert len(requests) == 1
+    assert requests[0].get_method() == "PUT"
+    assert requests[0].full_url.startswith("https://logs.example.com/default/chat/")
+    assert requests[0].data == b'{"status":200}\n{"status":500}\n'
@@ -0,0 +1,86 @@
+"""Tests for serve._utils.canary."""
+
+from serve._utils.canary import CanaryConfig, CanaryHandle, canary_handle, parse_canary_config
+
+
+class FakeMethod:
+    def __init__(self, name, calls):
+        self.name = name
+        self.calls = calls
+        self.stream = False
+
+    def remote(self, payload):
+        self.calls.append((self.name, self.stream, payload))
+        return self.name
+
+
+class FakeHandle:
+    """Deployment handle recording which Backend a call was sent to."""
+
+    def __init__(self, name, calls):
+        self.name = name
+        self.calls = calls
+        self.stream = False
+
+    def options(self, stream=False):
+        handle = FakeHandle(self.name, self.calls)
+        handle.stream = stream
+        return handle
+
+    @property
+    def generate(self):
+        method = FakeMethod(self.name, self.calls)
+        method.stream = self.stream
+        return method
+
+
+def test_parse_canary_config():
+    assert parse_canary_config({}) == CanaryConfig()
+    assert not parse_canary_config({"canary": None}).enabled
+
+    config = parse_canary_config({"canary": {"application": "production_chat-model_canary", "weight": 20}})
+    assert config == CanaryConfig(application="production_chat-model_canary", weight=20)
+    assert config.enabled
+
+    assert parse_canary_config({"canary": {"application": "a", "weight": 150}}).weight == 100
+    assert not parse_canary_config({"canary": {"application": "a", "weight": 0}}).enabled
+
+
+def test_pass_through_without_canary():
+    stable = FakeHandle("stable", [])
+    assert canary_handle(stable, CanaryConfig()) is stable
+
+
+def test_requests_are_split_by_weight():
+    calls = []
+    picks = iter([0.1, 0.5, 0.19, 0.2, 0.99])
+    handle = CanaryHandle(FakeHandle("stable", calls), lambda: FakeHandle("canary", calls), 20,
+                          pick=lambda: next(picks))
+
+    for i in range(5):
+        handle.options(stream=i % 2 == 0).generate.remote({"n": i})
+
+    assert calls == [
+        ("canary", True, {"n": 0}),
+        ("stable", False, {"n": 1}),
+        ("canary", True, {"n": 2}),
+        ("stable", False, {"n": 3}),
+        ("stable", True, {"n": 4}),
+    ]
+
+
+def test_canary_handle_is_resolved_once_on_first_use():
+    calls, lookups = [], []
+
+    def get_handle(application):
+        lookups.append(application)
+        return FakeHandle("canary", calls)
+
+    handle = canary_handle(FakeHandle("stable", calls), CanaryConfig("production_chat-model_canary", 100), get_handle)
+    assert lookups == []
+
+    handle.generate.remote({})
+    handle.options(stream=True).generate.remote({})
+
+    assert lookups == ["production_chat-model_canary"]
+    assert [call[0] for call in calls] == ["canary", "canary"]
@@ -0,0 +1,192 @@
+"""Tests for serve._utils.compression."""
+
+import asyncio
+import gzip
+import json
+import zlib
+
+import pytest
+
+from serve._utils.compression import (
+    CompressionConfig,
+    CompressionMiddleware,
+    accepts_gzip,
+)
+
+
+def json_app(payload_size=4096):
+    """App echoing the request body size and returning a large JSON response."""
+
+    async def app(scope, receive, send):
+        message = await receive()
+        body = json.dumps({"received": message["body"].decode(), "data": "x" * payload_size}).encode()
+        await send({
+            "type": "http.response.start",
+            "status": 200,
+            "headers": [(b"content-type", b"application/json"), (b"content-length", str(len(body)).encode())],
+        })
+        await send({"type": "http.response.body", "body": body})
+
+    return app
+
+
+def streaming_app(chunks):
+    async def app(scope, receive, send):
+        await send({
+            "type": "http.response.start",
+            "status": 200,
+            "headers": [(b"content-type", b"text/event-stream")],
+        })
+        for chunk in chunks:
+            await send({"type": "http.response.body", "body": chunk, "more_body": True})
+        await send({"type": "http.response.body", "body": b"", "more_body": False})
+
+    return app
+
+
+def call(middleware, body=b"", headers=()):
+    sent = []
+    received = [{"type": "http.request", "body": body, "more_body": False}]
+
+    async def receive():
+        return received.pop(0) if received else {"type": "http.disconnect"}
+
+    async def send(message):
+        sent.append(message)
+
+    scope = {"type": "http", "method": "POST", "path": "/v1/embeddings", "headers": list(headers)}
+    asyncio.run(middleware(scope, receive, send))
+
+    start = sent[0]
+    return start["status"], dict(start["headers"]), [m.get("body", b"") for m in sent[1:]]
+
+
+ENABLED = CompressionConfig(request=True, response=True, min_size=1024)
+
+
+def test_accepts_gzip():
+    assert accepts_gzip("gzip")
+    assert accepts_gzip("br, gzip;q=0.5")
+    assert accepts_gzip("*")
+    assert not accepts_gzip("gzip;q=0")
+    assert not accepts_gzip("br, deflate")
+    assert not accepts_gzip(None)
+
+
+def test_config_from_env(monkeypatch):
+    assert CompressionConfig.from_env() == CompressionConfig()
+
+    monkeypatch.setenv("NEUTREE_COMPRESSION_REQUEST", "true")
+    monkeypatch.setenv("NEUTREE_COMPRESSION_RESPONSE", "true")
+    monkeypatch.setenv("NEUTREE_COMPRESSION_MIN_SIZE", "256")
+
+    assert CompressionConfig.from_env() == CompressionConfig(request=True, response=True, min_size=256)
+
+
+def test_gzip_request_is_decompressed():
+    middleware = CompressionMiddleware(json_app(), ENABLED)
+
+    status, headers, bodies = call(middleware, gzip.compress(b"hello"), [(b"content-encoding", b"gzip")])
+
+    assert status == 200
+    assert json.loads(b"".join(bodies))["received"] == "hello"
+
+
+def test_invalid_gzip_request_is_rejected():
+    middleware = CompressionMiddleware(json_app(), ENABLED)
+
+    status, _, _ = call(middleware, b"not gzip", [(b"content-encoding", b"gzip")])
+
+    assert status == 400
+
+
+def test_gzip_request_rejected_when_disabled():
+    middleware = CompressionMiddleware(json_app(), CompressionConfig(response=True))
+
+    status, _, bodies = call(middleware, gzip.compress(b"hello"), [(b"content-encoding", b"gzip")])
+
+    assert status == 415
+    assert "unsupported request content encoding gzip" in b"".join(bodies).decode()
+
+
+def test_response_is_compressed_when_negotiated():
+    middleware = CompressionMiddleware(json_app(), ENABLED)
+
+    status, headers, bodies = call(middleware, b"hello", [(b"accept-encoding", b"gzip, deflate")])
+
+    assert status == 200
+    assert headers[b"content-encoding"] == b"gzip"
+    assert headers[b"vary"] == b"Accept-Encoding"
+    body = b"".join(bodies)
+    assert int(headers[b"content-length"]) == len(body)
+    assert json.loads(gzip.decompress(body))["data"] == "x" * 4096
+
+
+def test_pass_through_without_negotiation():
+    middleware = CompressionMiddleware(json_app(), ENABLED)
+
+    status, headers, bodies = call(middleware, b"hello")
+
+    assert status == 200
+    assert b"content-encoding" not in headers
+    assert json.loads(b"".join(bodies))["received"] == "hello"
+
+
+def test_pass_through_when_disabled():
+    middleware = CompressionMiddleware(json_app(), CompressionConfig())
+
+    status, headers, bodies = call(middleware, b"hello", [(b"accept-encoding", b"gzip")])
+
+    assert status == 200
+    assert b"content-encoding" not in headers
+    assert json.loads(b"".join(bodies))["data"] == "x" * 4096
+
+
+def test_small_response_is_not_compressed():
+    middleware = CompressionMiddleware(json_app(payload_size=10), ENABLED)
+
+    _, headers, bodies = call(middleware, b"hello", [(b"accept-encoding", b"gzip")])
+
+    assert b"content-encoding" not in headers
+    assert json.loads(b"".join(bodies))["data"] == "x" * 10
+
+
+def test_streaming_response_is_flushed_per_chunk():
+    events = [b"data: {\"token\": \"a\"}\n\n", b"data: {\"token\": \"b\"}\n\n", b"data: [DONE]\n\n"]
+    middleware = CompressionMiddleware(streaming_app(events), ENABLED)
+
+    _, headers, bodies = call(middleware, headers=[(b"accept-encoding", b"gzip")])
+
+    assert headers[b"content-encoding"] == b"gzip"
+    assert b"content-length" not in headers
+
+    # every event can be decoded as soon as its chunk arrives.
+    decompressor = zlib.decompressobj(16 + zlib.MAX_WBITS)
+    for event, chunk in zip(events, bodies):
+        assert decompressor.decompress(chunk) == event
+
+    assert decompressor.decompress(b"".join(bodies[len(events):])) == b""
+    assert decompressor.eof
+    assert gzip.decompress(b"".join(bodies)) == b"".join(events)
+
+
+def test_streaming_pass_through_without_negotiation():
+    events = [b"data: a\n\n", b"data: b\n\n"]
+    middleware = CompressionMiddleware(streaming_app(events), ENABLED)
+
+    _, headers, bodies = call(middleware)
+
+    assert b"content-encoding" not in headers
+    assert b"".join(bodies) == b"".join(events)
+
+
+@pytest.mark.parametrize("scope_type", ["lifespan", "websocket"])
+def test_non_http_scopes_pass_through(scope_type):
+    called = []
+
+    async def app(scope, receive, send):
+        called.append(scope["type"])
+
+    asyncio.run(CompressionMiddleware(app, ENABLED)({"type": scope_type}, None, None))
+
+    assert called == [scope_type]
@@ -0,0 +1,166 @@
+"""Tests for serve._utils.dedup."""
+
+import asyncio
+from types import SimpleNamespace
+
+import pytest
+
+from serve._utils.dedup import DedupConfig, IdempotencyKey, IdempotencyKeyMismatch, RequestDeduplicator, idempotency_key, parse_dedup_config
+
+KEY = IdempotencyKey("k", "body")
+
+
+class FakeClock:
+    def __init__(self):
+        self.now = 0.0
+
+    def __call__(self):
+        return self.now
+
+
+class CountingCall:
+    def __init__(self, failures=()):
+        self.failures = list(failures)
+        self.calls = 0
+
+    async def __call__(self):
+        self.calls += 1
+        if self.failures:
+            raise self.failures.pop(0)
+        return {"id": f"result-{self.calls}"}
+
+
+def fake_request(path, headers, body=b"{}"):
+    async def read_body():
+        return body
+
+    return SimpleNamespace(url=SimpleNamespace(path=path), headers=headers, body=read_body)
+
+
+def test_parse_dedup_config():
+    assert parse_dedup_config({}) == DedupConfig()
+    assert not parse_dedup_config({}).enabled
+
+    config = parse_dedup_config({"router": {"deduplication": {"ttl_seconds": 300, "max_entries": 10}}})
+    assert config == DedupConfig(ttl_seconds=300.0, max_entries=10)
+    assert config.enabled
+
+
+def test_idempotency_key():
+    def key(headers, body=b"{}"):
+        return asyncio.run(idempotency_key(fake_request("/v1/chat/completions", headers, body)))
+
+    assert key({}) is None
+
+    anonymous = key({"Idempotency-Key": "abc"})
+    assert anonymous.key == " /v1/chat/completions abc"
+
+    # The key is scoped to the API key of the consumer Kong authenticated.
+    consumer = key({"Idempotency-Key": "abc", "x-consumer-custom-id": "key-1"})
+    assert consumer.key == "key-1 /v1/chat/completions abc"
+    assert consumer.body_digest == anonymous.body_digest
+
+    assert key({"Idempotency-Key": "abc"}, b'{"n": 2}').body_digest != anonymous.body_digest
+
+
+def test_duplicate_returns_cached_result():
+    clock = FakeClock()
+    dedup = RequestDeduplicator(DedupConfig(ttl_seconds=60), clock)
+    call = CountingCall()
+
+    async def run():
+        first = await dedup.run(KEY, call)
+        clock.now = 59
+        return first, await dedup.run(KEY, call)
+
+    first, duplicate = asyncio.run(run())
+    assert first == duplicate == {"id": "result-1"}
+    assert call.calls == 1
+
+
+def test_expired_key_reruns():
+    clock = FakeClock()
+    dedup = RequestDeduplicator(DedupConfig(ttl_seconds=60), clock)
+    call = CountingCall()
+
+    async def run():
+        await dedup.run(KEY, call)
+        clock.now = 60
+        return await dedup.run(KEY, call)
+
+    assert asyncio.run(run()) == {"id": "result-2"}
+    assert call.calls == 2
+
+
+def test_concurrent_duplicate_waits_for_running_request():
+    dedup = RequestDeduplicator(DedupConfig(ttl_seconds=60))
+    calls = []
+
+    async def run():
+        started = asyncio.Event()
+
+        async def slow():
+            calls.append(1)
+            await started.wait()
+            return "done"
+
+        first = asyncio.ensure_future(dedup.run(KEY, slow))
+        duplicate = asyncio.ensure_future(dedup.run(KEY, slow))
+        await asyncio.sleep(0)
+        started.set()
+        return await first, await duplicate
+
+    assert asyncio.run(run()) == ("done", "done")
+    assert len(calls) == 1
+
+
+def test_failed_request_is_not_cached():
+    dedup = RequestDeduplicator(DedupConfig(ttl_seconds=60))
+    call = CountingCall([RuntimeError("replica failed")])
+
+    async def run():
+        with pytest.raises(RuntimeError):
+            await dedup.run(KEY, call)
+        return await dedup.run(KEY, call)
+
+    assert asyncio.run(run()) == {"id": "result-2"}
+    assert call.calls == 2
+
+
+def test_requests_without_key_or_when_disabled_always_run():
+    call = CountingCall()
+
+    async def run(dedup, key):
+        await dedup.run(key, call)
+        await dedup.run(key, call)
+
+    asyncio.run(run(RequestDeduplicator(DedupConfig(ttl_seconds=60)), None))
+    asyncio.run(run(RequestDeduplicator(DedupConfig()), KEY))
+    assert call.calls == 4
+
+
+def test_cache_is_bounded():
+    dedup = RequestDeduplicator(DedupConfig(ttl_seconds=60, max_entries=2))
+    call = CountingCall()
+
+    async def run():
+        for key in ("a", "b", "c"):
+            await dedup.run(IdempotencyKey(key, "body"), call)
+        # "a" was dropped to keep the two latest keys.
+        return await dedup.run(IdempotencyKey("a", "body"), call), await dedup.run(IdempotencyKey("c", "body"), call)
+
+    assert asyncio.run(run()) == ({"id": "result-4"}, {"id": "result-3"})
+
+
+def test_key_reused_with_another_body_is_rejected():
+    dedup = RequestDeduplicator(DedupConfig(ttl_seconds=60))
+    call = CountingCall()
+
+    async def run():
+        await dedup.run(KEY, call)
+        with pytest.raises(IdempotencyKeyMismatch):
+            await dedup.run(IdempotencyKey("k", "other body"), call)
+        return await dedup.run(KEY, call)
+
+    assert asyncio.run(run()) == {"id": "result-1"}
+    assert call.calls == 1
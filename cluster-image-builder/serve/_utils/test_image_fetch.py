@@ -0,0 +1,200 @@
+"""Tests for serve._utils.image_fetch."""
+
+import asyncio
+import base64
+
+import pytest
+
+from serve._utils.image_fetch import (
+    DEFAULT_MAX_BYTES,
+    ImageFetchConfig,
+    ImageFetchError,
+    ImageFetcher,
+    is_public_address,
+    parse_image_fetch_config,
+)
+
+PNG = b"\x89PNG\r\n\x1a\nimage"
+
+
+def fake_resolver(hosts):
+    async def resolve(host, port):
+        if host not in hosts:
+            raise OSError(f"unknown host {host}")
+        return hosts[host]
+
+    return resolve
+
+
+class FakeServer:
+    """Answers the requests by URL and records the addresses they were sent to."""
+
+    def __init__(self, responses):
+        self.responses = responses
+        self.requests = []
+
+    def __call__(self, url, address, config):
+        self.requests.append((url, address))
+        return self.responses[url]
+
+
+def chat_body(*urls):
+    return {
+        "model": "qwen-vl",
+        "messages": [
+            {"role": "system", "content": "Describe the images."},
+            {
+                "role": "user",
+                "content": [{"type": "text", "text": "What is this?"}]
+                + [{"type": "image_url", "image_url": {"url": url}} for url in urls],
+            },
+        ],
+    }
+
+
+def test_parse_image_fetch_config():
+    assert not parse_image_fetch_config({}).enabled
+    assert not parse_image_fetch_config({"router": {"retries": 1}}).enabled
+
+    config = parse_image_fetch_config({"router": {"image_fetch": {}}})
+    assert config == ImageFetchConfig(max_bytes=DEFAULT_MAX_BYTES, timeout_seconds=10.0)
+    assert config.enabled
+
+    config = parse_image_fetch_config({"router": {"image_fetch": {"max_bytes": 1024, "timeout_seconds": 5}}})
+    assert config == ImageFetchConfig(max_bytes=1024, timeout_seconds=5.0)
+
+
+def test_is_public_address():
+    assert is_public_address("93.184.216.34")
+    assert is_public_address("2606:2800:220:1:248:1893:25c8:1946")
+
+    for address in (
+        "127.0.0.1",
+        "10.0.0.5",
+        "172.16.3.4",
+        "192.168.1.1",
+        "169.254.169.254",
+        "100.64.0.1",
+        "0.0.0.0",
+        "224.0.0.1",
+        "::1",
+        "fd00::1",
+        "fe80::1%eth0",
+        "::ffff:127.0.0.1",
+        "not-an-address",
+    ):
+        assert not is_public_address(address), address
+
+
+def test_embeds_fetched_images_as_data_urls():
+    server = FakeServer({"https://images.example.com/cat.png": (200, {"content-type": "image/png"}, PNG)})
+    fetcher = ImageFetcher(
+        ImageFetchConfig(max_bytes=1024),
+        resolve=fake_resolver({"images.example.com": ["93.184.216.34"]}),
+        request=server,
+    )
+    body = chat_body("https://images.example.com/cat.png", "data:image/jpeg;base64,AAAA")
+
+    result = asyncio.run(fetcher.embed_images(body))
+
+    parts = result["messages"][1]["content"]
+    assert parts[0] == {"type": "text", "text": "What is this?"}
+    assert parts[1]["image_url"]["url"] == "data:image/png;base64," + base64.b64encode(PNG).decode()
+    # Data URLs are left as they are.
+    assert parts[2]["image_url"]["url"] == "data:image/jpeg;base64,AAAA"
+    # The request is sent to the validated address.
+    assert server.requests == [("https://images.example.com/cat.png", "93.184.216.34")]
+
+
+def test_image_url_given_as_a_string():
+    server = FakeServer({"http://images.example.com/cat.png": (200, {"content-type": "image/png; q=1"}, PNG)})
+    fetcher = ImageFetcher(
+        ImageFetchConfig(max_bytes=1024),
+        resolve=fake_resolver({"images.example.com": ["93.184.216.34"]}),
+        request=server,
+    )
+    body = {"messages": [{"role": "user", "content": [{"type": "image_url", "image_url": "http://images.example.com/cat.png"}]}]}
+
+    asyncio.run(fetcher.embed_images(body))
+
+    assert body["messages"][0]["content"][0]["image_url"]["url"].startswith("data:image/png;base64,")
+
+
+def test_disabled_fetcher_leaves_requests_as_they_are():
+    server = FakeServer({})
+    fetcher = ImageFetcher(ImageFetchConfig(), resolve=fake_resolver({}), request=server)
+    body = chat_body("http://127.0.0.1/cat.png")
+
+    assert asyncio.run(fetcher.embed_images(body)) == chat_body("http://127.0.0.1/cat.png")
+    assert server.requests == []
+
+
+@pytest.mark.parametrize(
+    "url, hosts",
+    [
+        ("http://127.0.0.1/cat.png", {"127.0.0.1": ["127.0.0.1"]}),
+        ("http://metadata.internal/latest", {"metadata.internal": ["169.254.169.254"]}),
+        ("http://kube-dns.kube-system:53/", {"kube-dns.kube-system": ["10.96.0.10"]}),
+        # Hosts resolving to a public and an internal address are rejected too.
+        ("http://mixed.example.com/cat.png", {"mixed.example.com": ["93.184.216.34", "10.0.0.1"]}),
+        ("http://[::1]/cat.png", {"::1": ["::1"]}),
+    ],
+)
+def test_rejects_internal_addresses(url, hosts):
+    server = FakeServer({})
+    fetcher = ImageFetcher(ImageFetchConfig(max_bytes=1024), resolve=fake_resolver(hosts), request=server)
+
+    with pytest.raises(ImageFetchError, match="non-public address"):
+        asyncio.run(fetcher.embed_images(chat_body(url)))
+
+    assert server.requests == []
+
+
+def test_rejects_redirects_to_internal_addresses():
+    server = FakeServer({"https://images.example.com/cat.png": (302, {"location": "http://169.254.169.254/latest"}, b"")})
+    fetcher = ImageFetcher(
+        ImageFetchConfig(max_bytes=1024),
+        resolve=fake_resolver({"images.example.com": ["93.184.216.34"], "169.254.169.254": ["169.254.169.254"]}),
+        request=server,
+    )
+
+    with pytest.raises(ImageFetchError, match="non-public address"):
+        asyncio.run(fetcher.fetch("https://images.example.com/cat.png"))
+
+    assert len(server.requests) == 1
+
+
+def test_follows_redirects_to_public_addresses():
+    server = FakeServer({
+        "https://images.example.com/cat.png": (301, {"location": "/v2/cat.png"}, b""),
+        "https://images.example.com/v2/cat.png": (200, {"content-type": "image/png"}, PNG),
+    })
+    fetcher = ImageFetcher(
+        ImageFetchConfig(max_bytes=1024),
+        resolve=fake_resolver({"images.example.com": ["93.184.216.34"]}),
+        request=server,
+    )
+
+    assert asyncio.run(fetcher.fetch("https://images.example.com/cat.png")).startswith("data:image/png;base64,")
+
+
+@pytest.mark.parametrize(
+    "url, response, message",
+    [
+        ("file:///etc/passwd", None, "unsupported image URL"),
+        ("http://images.example.com/missing.png", (404, {}, b""), "status 404"),
+        ("http://images.example.com/page.html", (200, {"content-type": "text/html"}, b"<html>"), "not an image"),
+        ("http://images.example.com/large.png", (200, {"content-type": "image/png"}, b"x" * 17), "larger than 16 bytes"),
+        ("http://unknown.example.com/cat.png", None, "failed to resolve"),
+    ],
+)
+def test_rejects_unfetchable_images(url, response, message):
+    server = FakeServer({url: response} if response else {})
+    fetcher = ImageFetcher(
+        ImageFetchConfig(max_bytes=16),
+        resolve=fake_resolver({"images.example.com": ["93.184.216.34"]}),
+        request=server,
+    )
+
+    with pytest.raises(ImageFetchError, match=message):
+        asyncio.run(fetcher.fetch(url))
@@ -0,0 +1,54 @@
+"""Tests for serve._utils.placement_group."""
+
+import sys
+from unittest.mock import MagicMock
+
+import pytest
+
+mock_ray = MagicMock()
+sys.modules.setdefault("ray", mock_ray)
+
+from serve._utils.placement_group import schedule_in_placement_group  # noqa: E402
+
+
+@pytest.fixture(autouse=True)
+def _named_placement_group():
+    mock_ray.reset_mock()
+    mock_ray.util.get_placement_group.return_value.id.hex.return_value = "abc123"
+
+
+def test_requests_replica_resources_from_the_placement_group():
+    options = schedule_in_placement_group("tp-gang", {
+        "num_cpus": 2,
+        "num_gpus": 4,
+        "memory": 64 * 1024 ** 3,
+        "resources": {"NVIDIA-L20": 4},
+    })
+
+    mock_ray.util.get_placement_group.assert_called_once_with("tp-gang")
+    assert options == {
+        "num_cpus": 0,
+        "num_gpus": 0,
+        "memory": None,
+        "resources": {
+            "CPU_group_abc123": 2,
+            "GPU_group_abc123": 4,
+            "memory_group_abc123": 64 * 1024 ** 3,
+            "NVIDIA-L20_group_abc123": 4,
+        },
+    }
+
+
+def test_skips_resources_the_replica_does_not_request():
+    options = schedule_in_placement_group("tp-gang", {"num_cpus": 1, "num_gpus": 0, "memory": None, "resources": {}})
+
+    assert options["resources"] == {"CPU_group_abc123": 1}
+
+
+def test_missing_placement_group_fails():
+    mock_ray.util.get_placement_group.side_effect = ValueError("Failed to look up placement group with name: tp-gang")
+
+    with pytest.raises(ValueError):
+        schedule_in_placement_group("tp-gang", {"num_cpus": 1})
+
+    mock_ray.util.get_placement_group.side_effect = None
@@ -0,0 +1,134 @@
+"""Tests for serve._utils.router_retry."""
+
+import asyncio
+
+import pytest
+
+from serve._utils.router_retry import (
+    RequestTimeoutError,
+    RouterRetryConfig,
+    call_with_retry,
+    is_timeout_error,
+    is_transient_replica_error,
+    parse_router_retry_config,
+    retry_backoff_seconds,
+)
+
+
+class ActorUnavailableError(Exception):
+    """Stand-in for ray.exceptions.ActorUnavailableError."""
+
+
+class FlakyCall:
+    def __init__(self, failures):
+        self.failures = list(failures)
+        self.calls = 0
+
+    async def __call__(self):
+        self.calls += 1
+        if self.failures:
+            raise self.failures.pop(0)
+        return "ok"
+
+
+class TestParseRouterRetryConfig:
+    def test_defaults(self):
+        config = parse_router_retry_config({})
+        assert config == RouterRetryConfig()
+        assert not config.circuit_breaker_enabled
+        assert config.request_router_kwargs() == {}
+
+    def test_configured(self):
+        config = parse_router_retry_config({
+            "router": {
+                "retries": 2,
+                "request_timeout_seconds": 30,
+                "circuit_breaker": {"failure_threshold": 3, "open_seconds": 10},
+            },
+        })
+        assert config.retries == 2
+        assert config.request_timeout_seconds == 30.0
+        assert config.circuit_breaker_enabled
+        assert config.request_router_kwargs() == {
+            "circuit_failure_threshold": 3,
+            "circuit_open_seconds": 10.0,
+        }
+
+
+class TestCallWithRetry:
+    def test_retries_transient_replica_error(self):
+        call = FlakyCall([ActorUnavailableError("replica restarting")])
+        assert asyncio.run(call_with_retry(call, retries=2)) == "ok"
+        assert call.calls == 2
+
+    def test_gives_up_after_retries(self):
+        call = FlakyCall([ActorUnavailableError("1"), ActorUnavailableError("2")])
+        with pytest.raises(ActorUnavailableError):
+            asyncio.run(call_with_retry(call, retries=1))
+        assert call.calls == 2
+
+    def test_no_retry_by_default(self):
+        call = FlakyCall([ActorUnavailableError("replica restarting")])
+        with pytest.raises(ActorUnavailableError):
+            asyncio.run(call_with_retry(call, retries=0))
+        assert call.calls == 1
+
+    def test_does_not_retry_request_errors(self):
+        call = FlakyCall([ValueError("bad request")])
+        with pytest.raises(ValueError):
+            asyncio.run(call_with_retry(call, retries=3))
+        assert call.calls == 1
+
+    def test_times_out_slow_request(self):
+        async def slow():
+            await asyncio.sleep(1)
+
+        with pytest.raises(RequestTimeoutError):
+            asyncio.run(call_with_retry(slow, retries=2, timeout=0.01))
+
+    def test_backs_off_between_retries(self, monkeypatch):
+        delays = []
+
+        async def sleep(delay):
+            delays.append(delay)
+
+        monkeypatch.setattr("serve._utils.router_retry.retry_backoff_seconds", lambda attempt: attempt / 10)
+        monkeypatch.setattr("asyncio.sleep", sleep)
+
+        call = FlakyCall([ActorUnavailableError("1"), ActorUnavailableError("2")])
+        assert asyncio.run(call_with_retry(call, retries=2)) == "ok"
+        assert delays == [0.1, 0.2]
+
+    def test_retries_within_timeout(self):
+        call = FlakyCall([ActorUnavailableError("1")])
+        assert asyncio.run(call_with_retry(call, retries=1, timeout=5)) == "ok"
+        assert call.calls == 2
+
+
+def test_is_transient_replica_error():
+    class RayActorError(Exception):
+        pass
+
+    class ActorDiedError(RayActorError):
+        pass
+
+    assert is_transient_replica_error(ActorDiedError())
+    assert is_transient_replica_error(ActorUnavailableError())
+    assert not is_transient_replica_error(RuntimeError())
+
+
+def test_is_timeout_error():
+    class GetTimeoutError(TimeoutError):
+        pass
+
+    assert is_timeout_error(RequestTimeoutError())
+    assert is_timeout_error(asyncio.TimeoutError())
+    assert is_timeout_error(GetTimeoutError())
+    assert not is_timeout_error(RuntimeError())
+
+
+def test_retry_backoff_seconds():
+    for attempt, backoff in [(1, 0.1), (2, 0.2), (3, 0.4), (10, 2.0)]:
+        delays = [retry_backoff_seconds(attempt) for _ in range(100)]
+        assert all(0 <= delay <= backoff for delay in delays)
+        assert len(set(delays)) > 1
@@ -0,0 +1,226 @@
+"""Tests for serve._utils.tracing."""
+
+import asyncio
+
+from serve._utils.tracing import (
+    TRACE_CONTEXT_KEY,
+    OTLPExporter,
+    TraceContext,
+    TracingConfig,
+    TracingMiddleware,
+    accepts_trace_context,
+    current_trace_context,
+    parse_traceparent,
+    trace_request,
+    traced_handle,
+)
+
+TRACE_ID = "4bf92f3577b34da6a3ce929d0e0e4736"
+PARENT_SPAN_ID = "00f067aa0ba902b7"
+TRACEPARENT = f"00-{TRACE_ID}-{PARENT_SPAN_ID}-01"
+
+ENABLED = TracingConfig(otlp_endpoint="http://otel-collector:4318")
+
+
+class FakeExporter:
+    def __init__(self):
+        self.spans = []
+
+    def export(self, span):
+        self.spans.append(span)
+
+
+class FakeMethod:
+    """Deployment handle method recording the payloads it is called with."""
+
+    def __init__(self, calls):
+        self.calls = calls
+
+    def options(self, **kwargs):
+        return self
+
+    def remote(self, payload):
+        self.calls.append(payload)
+        return payload
+
+
+class FakeHandle:
+    def __init__(self):
+        self.calls = []
+        self.generate = FakeMethod(self.calls)
+
+    def options(self, **kwargs):
+        return self
+
+
+class FakeEngine:
+    """OpenAI serving handler of an engine recording the raw requests it is given."""
+
+    def __init__(self):
+        self.raw_requests = []
+
+    async def create_chat_completion(self, request, raw_request):
+        self.raw_requests.append(raw_request)
+        return "ok"
+
+
+class Backend:
+    """Backend replica recording the trace context its engine call runs in."""
+
+    def __init__(self):
+        self.payloads = []
+        self.contexts = []
+        self.engine = FakeEngine()
+
+    @accepts_trace_context
+    async def generate(self, payload):
+        self.payloads.append(payload)
+        self.contexts.append(current_trace_context())
+        return await self.engine.create_chat_completion(payload, trace_request())
+
+
+def router_app(handle, backend):
+    """Controller app forwarding the request to the Backend through the deployment handle."""
+
+    async def app(scope, receive, send):
+        payload = handle.options(stream=False).generate.remote({"model": "m"})
+        await backend.generate(payload)
+        await send({"type": "http.response.start", "status": 200, "headers": []})
+        await send({"type": "http.response.body", "body": b"{}"})
+
+    return app
+
+
+def call(middleware, headers=()):
+    sent = []
+
+    async def receive():
+        return {"type": "http.request", "body": b"{}", "more_body": False}
+
+    async def send(message):
+        sent.append(message)
+
+    scope = {"type": "http", "method": "POST", "path": "/v1/chat/completions", "headers": list(headers)}
+    asyncio.run(middleware(scope, receive, send))
+
+    return sent[0]["status"]
+
+
+def test_parse_traceparent():
+    context = parse_traceparent(TRACEPARENT, "vendor=value")
+    assert context == TraceContext(TRACE_ID, PARENT_SPAN_ID, True, "vendor=value")
+    assert context.traceparent() == TRACEPARENT
+
+    assert parse_traceparent(f"00-{TRACE_ID}-{PARENT_SPAN_ID}-00").sampled is False
+    assert parse_traceparent(None) is None
+    assert parse_traceparent("not-a-traceparent") is None
+    assert parse_traceparent(f"00-{'0' * 32}-{PARENT_SPAN_ID}-01") is None
+    assert parse_traceparent(f"ff-{TRACE_ID}-{PARENT_SPAN_ID}-01") is None
+
+
+def test_config_from_env(monkeypatch):
+    assert TracingConfig.from_env() == TracingConfig()
+    assert not TracingConfig.from_env().enabled
+
+    monkeypatch.setenv("NEUTREE_TRACING_OTLP_ENDPOINT", "http://otel-collector:4318/")
+    monkeypatch.setenv("NEUTREE_TRACING_SAMPLE_RATIO", "0.25")
+
+    assert TracingConfig.from_env() == TracingConfig(otlp_endpoint="http://otel-collector:4318", sample_ratio=0.25)
+
+
+def test_trace_context_is_propagated_to_backend():
+    handle, backend, exporter = FakeHandle(), Backend(), FakeExporter()
+    middleware = TracingMiddleware(router_app(traced_handle(handle, ENABLED), backend), ENABLED, exporter)
+
+    assert call(middleware, [(b"traceparent", TRACEPARENT.encode()), (b"tracestate", b"vendor=value")]) == 200
+
+    # the Controller passes on the context of the routing span, the engine never sees it.
+    assert TRACE_CONTEXT_KEY in handle.calls[0]
+    assert backend.payloads == [{"model": "m"}]
+
+    context = backend.contexts[0]
+    assert context.trace_id == TRACE_ID
+    assert context.span_id == exporter.spans[0].context.span_id
+    assert context.span_id != PARENT_SPAN_ID
+    assert context.tracestate == "vendor=value"
+    assert current_trace_context() is None
+
+    # the engine is given the context of the routing span as the headers of its request.
+    raw_request = backend.engine.raw_requests[0]
+    assert raw_request.headers == {"traceparent": context.traceparent(), "tracestate": "vendor=value"}
+    assert asyncio.run(raw_request.is_disconnected()) is False
+
+
+def test_untraced_request_is_passed_to_engine_without_raw_request():
+    backend = Backend()
+
+    asyncio.run(backend.generate({"model": "m"}))
+
+    assert backend.engine.raw_requests == [None]
+
+
+def test_routing_span_is_emitted():
+    exporter = FakeExporter()
+    middleware = TracingMiddleware(router_app(traced_handle(FakeHandle(), ENABLED), Backend()), ENABLED, exporter)
+
+    call(middleware, [(b"traceparent", TRACEPARENT.encode())])
+
+    assert len(exporter.spans) == 1
+    span = exporter.spans[0]
+    assert span.name == "neutree.route POST /v1/chat/completions"
+    assert span.parent_span_id == PARENT_SPAN_ID
+    assert span.attributes["http.response.status_code"] == 200
+    assert span.end_ns >= span.start_ns
+
+    otlp = OTLPExporter("http://otel-collector:4318", {"service.name": "neutree-router"}).request_body(exporter.spans)
+    exported = otlp["resourceSpans"][0]["scopeSpans"][0]["spans"][0]
+    assert exported["traceId"] == TRACE_ID
+    assert exported["parentSpanId"] == PARENT_SPAN_ID
+    assert {"key": "http.response.status_code", "value": {"intValue": "200"}} in exported["attributes"]
+
+
+def test_new_trace_is_sampled_with_ratio():
+    exporter = FakeExporter()
+    backend = Backend()
+    config = TracingConfig(otlp_endpoint="http://otel-collector:4318", sample_ratio=0)
+    middleware = TracingMiddleware(router_app(traced_handle(FakeHandle(), config), backend), config, exporter)
+
+    call(middleware)
+
+    # the unsampled context is still propagated so the engine does not start a trace of its own.
+    assert exporter.spans == []
+    assert backend.contexts[0].sampled is False
+
+
+def test_unsampled_parent_is_not_exported():
+    exporter = FakeExporter()
+    middleware = TracingMiddleware(router_app(traced_handle(FakeHandle(), ENABLED), Backend()), ENABLED, exporter)
+
+    call(middleware, [(b"traceparent", f"00-{TRACE_ID}-{PARENT_SPAN_ID}-00".encode())])
+
+    assert exporter.spans == []
+
+
+def test_server_error_marks_span():
+    exporter = FakeExporter()
+
+    async def failing(scope, receive, send):
+        await send({"type": "http.response.start", "status": 503, "headers": []})
+        await send({"type": "http.response.body", "body": b""})
+
+    call(TracingMiddleware(failing, ENABLED, exporter))
+
+    assert exporter.spans[0].error
+    assert exporter.spans[0].to_otlp()["status"] == {"code": 2}
+
+
+def test_pass_through_when_disabled():
+    handle, backend, exporter = FakeHandle(), Backend(), FakeExporter()
+    config = TracingConfig()
+    middleware = TracingMiddleware(router_app(traced_handle(handle, config), backend), config, exporter)
+
+    assert call(middleware, [(b"traceparent", TRACEPARENT.encode())]) == 200
+
+    assert handle.calls == [{"model": "m"}]
+    assert backend.contexts == [None]
+    assert exporter.spans == []
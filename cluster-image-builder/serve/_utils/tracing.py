@@ -0,0 +1,419 @@
+"""W3C trace context propagation and routing spans of the Controller deployments.
+
+Configured through ``deployment_options.tracing`` of the endpoint, which the
+orchestrator passes to the application as environment variables::
+
+    tracing:
+      otlp_endpoint: http://otel-collector.observability:4318
+      sample_ratio: 0.1
+
+Requests carrying a ``traceparent`` header keep its trace and sampling decision,
+the others start a new trace sampled with ``sample_ratio``. The Controller records
+a span for the routing hop, child of the incoming span, and exports the sampled
+ones to ``<otlp_endpoint>/v1/traces`` as OTLP/HTTP JSON. The trace context of the
+routing span is passed on to the Backend replica serving the request through the
+payload of the deployment handle call, and from there to the engine as the
+``traceparent`` and ``tracestate`` headers of the request it is given, see
+``trace_request``, so the engine spans are children of the routing span. vLLM and
+SGLang only record them when their own tracing is configured, llama.cpp has none.
+"""
+
+import contextvars
+import functools
+import inspect
+import json
+import logging
+import os
+import queue
+import random
+import re
+import threading
+import time
+import types
+import urllib.request
+from dataclasses import dataclass, field
+from typing import Any, Awaitable, Callable, Dict, List, Optional, Tuple
+
+logger = logging.getLogger("ray.serve")
+
+TRACING_OTLP_ENDPOINT_ENV = "NEUTREE_TRACING_OTLP_ENDPOINT"
+TRACING_SAMPLE_RATIO_ENV = "NEUTREE_TRACING_SAMPLE_RATIO"
+
+ENDPOINT_NAME_ENV = "NEUTREE_ENDPOINT_NAME"
+ENDPOINT_WORKSPACE_ENV = "NEUTREE_ENDPOINT_WORKSPACE"
+
+# Payload key carrying the trace context from the Controller to the Backend, removed
+# by the Backend before the payload reaches the engine.
+TRACE_CONTEXT_KEY = "_neutree_trace_context"
+
+SERVICE_NAME = "neutree-router"
+
+_TRACEPARENT_RE = re.compile(r"^([0-9a-f]{2})-([0-9a-f]{32})-([0-9a-f]{16})-([0-9a-f]{2})$")
+
+# OTLP span kind and status codes.
+_SPAN_KIND_SERVER = 2
+_STATUS_CODE_ERROR = 2
+
+_EXPORT_BATCH_SIZE = 512
+_EXPORT_INTERVAL_SECONDS = 2.0
+_EXPORT_QUEUE_SIZE = 4096
+_EXPORT_TIMEOUT_SECONDS = 10.0
+
+Scope = Dict[str, Any]
+Message = Dict[str, Any]
+Receive = Callable[[], Awaitable[Message]]
+Send = Callable[[Message], Awaitable[None]]
+
+
+@dataclass
+class TracingConfig:
+    otlp_endpoint: str = ""
+    sample_ratio: float = 1.0
+
+    @property
+    def enabled(self) -> bool:
+        return bool(self.otlp_endpoint)
+
+    @classmethod
+    def from_env(cls) -> "TracingConfig":
+        ratio = os.environ.get(TRACING_SAMPLE_RATIO_ENV)
+        return cls(
+            otlp_endpoint=os.environ.get(TRACING_OTLP_ENDPOINT_ENV, "").rstrip("/"),
+            sample_ratio=float(ratio) if ratio else 1.0,
+        )
+
+
+@dataclass(frozen=True)
+class TraceContext:
+    trace_id: str
+    span_id: str
+    sampled: bool
+    tracestate: Optional[str] = None
+
+    def traceparent(self) -> str:
+        return f"00-{self.trace_id}-{self.span_id}-{'01' if self.sampled else '00'}"
+
+    def headers(self) -> Dict[str, str]:
+        headers = {"traceparent": self.traceparent()}
+        if self.tracestate:
+            headers["tracestate"] = self.tracestate
+        return headers
+
+
+def parse_traceparent(traceparent: Optional[str], tracestate: Optional[str] = None) -> Optional[TraceContext]:
+    """Parse a W3C traceparent header, None if it is missing or invalid."""
+    if not traceparent:
+        return None
+
+    match = _TRACEPARENT_RE.match(traceparent.strip().lower())
+    if not match:
+        return None
+
+    version, trace_id, span_id, flags = match.groups()
+    if version == "ff" or trace_id == "0" * 32 or span_id == "0" * 16:
+        return None
+
+    return TraceContext(trace_id, span_id, bool(int(flags, 16) & 0x01), tracestate or None)
+
+
+def _new_id(length: int) -> str:
+    return f"{random.getrandbits(length * 4) or 1:0{length}x}"
+
+
+_current_context: contextvars.ContextVar[Optional[TraceContext]] = contextvars.ContextVar(
+    "neutree_trace_context", default=None
+)
+
+
+def current_trace_context() -> Optional[TraceContext]:
+    """Trace context of the request being served, None if it is not traced."""
+    return _current_context.get()
+
+
+def inject_trace_context(payload: Any) -> Any:
+    """Return the payload carrying the current trace context, for the Backend."""
+    context = current_trace_context()
+    if context is None or not isinstance(payload, dict):
+        return payload
+
+    return {**payload, TRACE_CONTEXT_KEY: context.headers()}
+
+
+def extract_trace_context(payload: Any) -> Tuple[Any, Optional[TraceContext]]:
+    """Return the payload without the trace context injected by the Controller, and that context."""
+    if not isinstance(payload, dict) or TRACE_CONTEXT_KEY not in payload:
+        return payload, None
+
+    payload = dict(payload)
+    headers = payload.pop(TRACE_CONTEXT_KEY) or {}
+    return payload, parse_traceparent(headers.get("traceparent"), headers.get("tracestate"))
+
+
+def trace_headers() -> Dict[str, str]:
+    """Trace headers of the request being served, empty if it is not traced."""
+    context = current_trace_context()
+    return context.headers() if context is not None else {}
+
+
+class TraceRequest:
+    """Stand-in for the HTTP request the engines read the trace context of a call from.
+
+    The engines take the trace headers off the raw request handed to their OpenAI
+    serving handlers, which a Backend replica called through a deployment handle has
+    not got.
+    """
+
+    def __init__(self, headers: Dict[str, str]):
+        self.headers = headers
+        self.state = types.SimpleNamespace()
+
+    async def is_disconnected(self) -> bool:
+        # the Controller owns the client connection.
+        return False
+
+
+def trace_request() -> Optional[TraceRequest]:
+    """Raw request carrying the trace headers to the engine, None if the request is not traced."""
+    headers = trace_headers()
+    return TraceRequest(headers) if headers else None
+
+
+class TracedHandle:
+    """Deployment handle passing the current trace context on with the payload of its calls."""
+
+    def __init__(self, handle: Any):
+        self._handle = handle
+
+    def options(self, *args: Any, **kwargs: Any) -> "TracedHandle":
+        return TracedHandle(self._handle.options(*args, **kwargs))
+
+    def remote(self, *args: Any, **kwargs: Any) -> Any:
+        if args:
+            args = (inject_trace_context(args[0]),) + args[1:]
+        return self._handle.remote(*args, **kwargs)
+
+    def __getattr__(self, name: str) -> "TracedHandle":
+        return TracedHandle(getattr(self._handle, name))
+
+
+def traced_handle(handle: Any, config: Optional[TracingConfig] = None) -> Any:
+    """Wrap the Backend handle of a Controller when tracing is enabled."""
+    config = config or TracingConfig.from_env()
+    return TracedHandle(handle) if config.enabled else handle
+
+
+def accepts_trace_context(method: Callable) -> Callable:
+    """Decorate a Backend method taking the payload as first argument.
+
+    The trace context injected by the Controller is removed from the payload and is the
+    current trace context while the method, or the stream it returns, runs.
+    """
+    if inspect.isasyncgenfunction(method):
+        @functools.wraps(method)
+        async def stream(self, payload, *args, **kwargs):
+            payload, context = extract_trace_context(payload)
+            token = _current_context.set(context)
+            try:
+                async for item in method(self, payload, *args, **kwargs):
+                    yield item
+            finally:
+                try:
+                    _current_context.reset(token)
+                except ValueError:
+                    # the stream was closed from another context, nothing to restore there.
+                    pass
+
+        return stream
+
+    @functools.wraps(method)
+    async def call(self, payload, *args, **kwargs):
+        payload, context = extract_trace_context(payload)
+        token = _current_context.set(context)
+        try:
+            return await method(self, payload, *args, **kwargs)
+        finally:
+            _current_context.reset(token)
+
+    return call
+
+
+@dataclass
+class Span:
+    name: str
+    context: TraceContext
+    parent_span_id: Optional[str]
+    start_ns: int
+    end_ns: int = 0
+    attributes: Dict[str, Any] = field(default_factory=dict)
+    error: bool = False
+
+    def to_otlp(self) -> Dict[str, Any]:
+        span = {
+            "traceId": self.context.trace_id,
+            "spanId": self.context.span_id,
+            "name": self.name,
+            "kind": _SPAN_KIND_SERVER,
+            "startTimeUnixNano": str(self.start_ns),
+            "endTimeUnixNano": str(self.end_ns),
+            "attributes": _otlp_attributes(self.attributes),
+        }
+        if self.parent_span_id:
+            span["parentSpanId"] = self.parent_span_id
+        if self.context.tracestate:
+            span["traceState"] = self.context.tracestate
+        if self.error:
+            span["status"] = {"code": _STATUS_CODE_ERROR}
+        return span
+
+
+def _otlp_attributes(attributes: Dict[str, Any]) -> List[Dict[str, Any]]:
+    result = []
+    for key, value in attributes.items():
+        if isinstance(value, bool):
+            typed = {"boolValue": value}
+        elif isinstance(value, int):
+            typed = {"intValue": str(value)}
+        else:
+            typed = {"stringValue": str(value)}
+        result.append({"key": key, "value": typed})
+    return result
+
+
+class OTLPExporter:
+    """Export spans in batches to an OTLP/HTTP collector from a background thread.
+
+    Spans are dropped when the collector does not keep up, tracing never holds back
+    requests.
+    """
+
+    def __init__(self, endpoint: str, resource: Optional[Dict[str, Any]] = None):
+        self.url = f"{endpoint}/v1/traces"
+        self.resource = resource or {}
+        self._queue: "queue.Queue[Span]" = queue.Queue(maxsize=_EXPORT_QUEUE_SIZE)
+        self._thread: Optional[threading.Thread] = None
+        self._lock = threading.Lock()
+
+    def export(self, span: Span) -> None:
+        self._ensure_started()
+        try:
+            self._queue.put_nowait(span)
+        except queue.Full:
+            logger.debug("[Tracing] export queue is full, dropping span")
+
+    def _ensure_started(self) -> None:
+        if self._thread is not None:
+            return
+        with self._lock:
+            if self._thread is None:
+                self._thread = threading.Thread(target=self._run, name="neutree-otlp-exporter", daemon=True)
+                self._thread.start()
+
+    def _run(self) -> None:
+        while True:
+            batch = [self._queue.get()]
+            deadline = time.monotonic() + _EXPORT_INTERVAL_SECONDS
+            while len(batch) < _EXPORT_BATCH_SIZE:
+                remaining = deadline - time.monotonic()
+                if remaining <= 0:
+                    break
+                try:
+                    batch.append(self._queue.get(timeout=remaining))
+                except queue.Empty:
+                    break
+            self.send(batch)
+
+    def request_body(self, spans: List[Span]) -> Dict[str, Any]:
+        return {
+            "resourceSpans": [{
+                "resource": {"attributes": _otlp_attributes(self.resource)},
+                "scopeSpans": [{
+                    "scope": {"name": "neutree.router"},
+                    "spans": [span.to_otlp() for span in spans],
+                }],
+            }],
+        }
+
+    def send(self, spans: List[Span]) -> None:
+        request = urllib.request.Request(
+            self.url,
+            data=json.dumps(self.request_body(spans)).encode(),
+            headers={"Content-Type": "application/json"},
+            method="POST",
+        )
+        try:
+            with urllib.request.urlopen(request, timeout=_EXPORT_TIMEOUT_SECONDS) as response:
+                response.read()
+        except Exception as e:
+            logger.warning(f"[Tracing] Failed to export {len(spans)} spans to {self.url}: {e}")
+
+
+def _header(headers: List[Tuple[bytes, bytes]], name: bytes) -> Optional[str]:
+    for key, value in headers:
+        if key.lower() == name:
+            return value.decode("latin-1")
+    return None
+
+
+def _without(headers: List[Tuple[bytes, bytes]], *names: bytes) -> List[Tuple[bytes, bytes]]:
+    return [(key, value) for key, value in headers if key.lower() not in names]
+
+
+class TracingMiddleware:
+    """ASGI middleware recording the routing span of every request and propagating its context."""
+
+    def __init__(self, app: Callable, config: Optional[TracingConfig] = None, exporter: Optional[Any] = None):
+        self.app = app
+        self.config = config or TracingConfig.from_env()
+        self.exporter = exporter
+        if self.exporter is None and self.config.enabled:
+            self.exporter = OTLPExporter(self.config.otlp_endpoint, {
+                "service.name": SERVICE_NAME,
+                "neutree.endpoint.name": os.environ.get(ENDPOINT_NAME_ENV, ""),
+                "neutree.endpoint.workspace": os.environ.get(ENDPOINT_WORKSPACE_ENV, ""),
+            })
+
+    async def __call__(self, scope: Scope, receive: Receive, send: Send) -> None:
+        if scope["type"] != "http" or not self.config.enabled:
+            await self.app(scope, receive, send)
+            return
+
+        headers = list(scope.get("headers") or [])
+        parent = parse_traceparent(_header(headers, b"traceparent"), _header(headers, b"tracestate"))
+
+        if parent is not None:
+            context = TraceContext(parent.trace_id, _new_id(16), parent.sampled, parent.tracestate)
+        else:
+            context = TraceContext(_new_id(32), _new_id(16), random.random() < self.config.sample_ratio)
+
+        # the application sees the routing span as the parent of what it calls.
+        headers = _without(headers, b"traceparent", b"tracestate")
+        headers.extend((key.encode(), value.encode()) for key, value in context.headers().items())
+        scope = {**scope, "headers": headers}
+
+        path = scope.get("path", "")
+        span = Span(
+            name=f"neutree.route {scope.get('method', '')} {path}".rstrip(),
+            context=context,
+            parent_span_id=parent.span_id if parent else None,
+            start_ns=time.time_ns(),
+            attributes={"http.request.method": scope.get("method", ""), "url.path": path},
+        )
+
+        async def send_traced(message: Message) -> None:
+            if message["type"] == "http.response.start":
+                status = int(message.get("status", 200))
+                span.attributes["http.response.status_code"] = status
+                span.error = status >= 500
+            await send(message)
+
+        token = _current_context.set(context)
+        try:
+            await self.app(scope, receive, send_traced)
+        except BaseException:
+            span.error = True
+            raise
+        finally:
+            _current_context.reset(token)
+            span.end_ns = time.time_ns()
+            if context.sampled and self.exporter is not None:
+                self.exporter.export(span)
@@ -38,6 +38,15 @@ from serve._metrics.prometheus_multiproc import install_stable_prometheus_multip
 from serve._metrics.sglang_ray_bridge import PromToRayBridge
 from serve._utils import coerce_args, filter_engine_args
 from serve._utils.runtime_env import build_backend_runtime_env
+from serve._utils.placement_group import schedule_in_placement_group
+from serve._metrics.request_metrics import ModelRequestMetrics, observe_model_requests
+from serve._utils.access_log import AccessLogMiddleware
+from serve._utils.compression import CompressionMiddleware
+from serve._utils.tracing import TracingMiddleware, accepts_trace_context, trace_headers, traced_handle
+from serve._utils.canary import CanaryConfig, canary_handle, parse_canary_config
+from serve._utils.dedup import DedupConfig, IdempotencyKeyMismatch, RequestDeduplicator, idempotency_key, parse_dedup_config
+from serve._utils.image_fetch import ImageFetchConfig, ImageFetchError, ImageFetcher, parse_image_fetch_config
+from serve._utils.router_retry import TIMEOUT_ERRORS, RouterRetryConfig, call_with_retry, parse_router_retry_config
 
 logger = logging.getLogger("ray.serve")
 
@@ -54,24 +63,33 @@ SCHEDULER_CLASS_PATHS = {
     SchedulerType.CONSISTENT_HASH: "serve._replica_scheduler.chwbl_scheduler:ConsistentHashReplicaScheduler",
 }
 
+# Request router used for the default POW2 scheduler when circuit breaking is configured
+CIRCUIT_BREAKING_POW2_CLASS_PATH = "serve._replica_scheduler.pow2_scheduler:CircuitBreakingPow2ReplicaScheduler"
 
-def _build_request_router_config(scheduler_config: Dict[str, Any]) -> Optional[RequestRouterConfig]:
+
+def _build_request_router_config(
+    scheduler_config: Dict[str, Any],
+    retry_config: RouterRetryConfig,
+) -> Optional[RequestRouterConfig]:
     """Build RequestRouterConfig based on scheduler configuration.
 
     See vLLM v0.11.2 app for full documentation of supported scheduler types.
     """
     scheduler_type = scheduler_config.get("type", SchedulerType.POW2)
 
-    if scheduler_type == SchedulerType.POW2:
-        logger.info("[app_builder] Using default POW2 scheduler")
-        return None
-
     router_class_path = SCHEDULER_CLASS_PATHS.get(scheduler_type)
-    if not router_class_path:
+    if not router_class_path and scheduler_type != SchedulerType.POW2:
         logger.warning(
             f"[app_builder] Unknown scheduler type: {scheduler_type}, falling back to default POW2"
         )
-        return None
+        scheduler_type = SchedulerType.POW2
+
+    if scheduler_type == SchedulerType.POW2:
+        if not retry_config.circuit_breaker_enabled:
+            logger.info("[app_builder] Using default POW2 scheduler")
+            return None
+
+        router_class_path = CIRCUIT_BREAKING_POW2_CLASS_PATH
 
     router_kwargs: Dict[str, Any] = {}
     if scheduler_type == SchedulerType.CONSISTENT_HASH:
@@ -81,6 +99,8 @@ def _build_request_router_config(scheduler_config: Dict[str, Any]) -> Optional[R
             "max_user_messages_for_cache": scheduler_config.get("max_user_messages_for_cache", 2),
         }
 
+    router_kwargs.update(retry_config.request_router_kwargs())
+
     logger.info(
         f"[app_builder] Using custom scheduler: {scheduler_type}, "
         f"class: {router_class_path}, kwargs: {router_kwargs}"
@@ -91,6 +111,11 @@ def _build_request_router_config(scheduler_config: Dict[str, Any]) -> Optional[R
         request_router_kwargs=router_kwargs,
     )
 
+    return RequestRouterConfig(
+        request_router_class=router_class_path,
+        request_router_kwargs=router_kwargs,
+    )
+
 
 class _FakeRawRequest:
     """Minimal stand-in for FastAPI Request used when invoking SGLang's
@@ -100,9 +125,13 @@ class _FakeRawRequest:
     generation when the client disappears; passing ``None`` would raise
     ``AttributeError``. This shim returns ``False`` so generation always
     runs to completion (the Controller still owns the real client connection).
+
+    It carries the trace headers of the request, which SGLang reads off the raw
+    request into the trace context of the generation.
     """
 
-    headers: Dict[str, str] = {}
+    def __init__(self, headers: Optional[Dict[str, str]] = None):
+        self.headers = headers or {}
 
     async def is_disconnected(self) -> bool:
         return False
@@ -352,44 +381,49 @@ class Backend:
     # therefore split into a non-streaming method (returns dict / pydantic
     # dump) and a streaming method (async generator yielding SSE strings).
 
+    @accepts_trace_context
     async def chat_completion(self, payload: Dict[str, Any]) -> Any:
         from sglang.srt.entrypoints.openai.protocol import ChatCompletionRequest
         payload = {**payload, "stream": False}
         result = await self._ensure_chat().handle_request(
-            ChatCompletionRequest(**payload), _FakeRawRequest()
+            ChatCompletionRequest(**payload), _FakeRawRequest(trace_headers())
         )
         return _extract_serializable(result)
 
+    @accepts_trace_context
     async def chat_completion_stream(self, payload: Dict[str, Any]) -> AsyncGenerator[str, None]:
         from sglang.srt.entrypoints.openai.protocol import ChatCompletionRequest
         payload = {**payload, "stream": True}
         result = await self._ensure_chat().handle_request(
-            ChatCompletionRequest(**payload), _FakeRawRequest()
+            ChatCompletionRequest(**payload), _FakeRawRequest(trace_headers())
         )
         async for chunk in _iter_response_body(result):
             yield chunk
 
+    @accepts_trace_context
     async def completion(self, payload: Dict[str, Any]) -> Any:
         from sglang.srt.entrypoints.openai.protocol import CompletionRequest
         payload = {**payload, "stream": False}
         result = await self._ensure_completion().handle_request(
-            CompletionRequest(**payload), _FakeRawRequest()
+            CompletionRequest(**payload), _FakeRawRequest(trace_headers())
         )
         return _extract_serializable(result)
 
+    @accepts_trace_context
     async def completion_stream(self, payload: Dict[str, Any]) -> AsyncGenerator[str, None]:
         from sglang.srt.entrypoints.openai.protocol import CompletionRequest
         payload = {**payload, "stream": True}
         result = await self._ensure_completion().handle_request(
-            CompletionRequest(**payload), _FakeRawRequest()
+            CompletionRequest(**payload), _FakeRawRequest(trace_headers())
         )
         async for chunk in _iter_response_body(result):
             yield chunk
 
+    @accepts_trace_context
     async def embedding(self, payload: Dict[str, Any]) -> Any:
         from sglang.srt.entrypoints.openai.protocol import EmbeddingRequest
         result = await self._ensure_embedding().handle_request(
-            EmbeddingRequest(**payload), _FakeRawRequest()
+            EmbeddingRequest(**payload), _FakeRawRequest(trace_headers())
         )
         return _extract_serializable(result)
 
@@ -409,6 +443,10 @@ class Backend:
 
 
 app = FastAPI()
+# Innermost, so access logs see the decompressed requests and uncompressed responses.
+app.add_middleware(AccessLogMiddleware)
+app.add_middleware(CompressionMiddleware)
+app.add_middleware(TracingMiddleware)
 app.add_middleware(RawContextMiddleware, plugins=(RequestIdPlugin(),))
 app.add_middleware(
     CORSMiddleware,
@@ -419,25 +457,67 @@ app.add_middleware(
 )
 
 
+async def request_timeout_handler(request: Request, exc: TimeoutError):
+    return JSONResponse(
+        content={"message": str(exc), "type": "timeout"},
+        status_code=504,
+    )
+
+
+for _timeout_error in TIMEOUT_ERRORS:
+    app.add_exception_handler(_timeout_error, request_timeout_handler)
+
+
+@app.exception_handler(IdempotencyKeyMismatch)
+async def idempotency_key_mismatch_handler(request: Request, exc: IdempotencyKeyMismatch):
+    return JSONResponse(
+        content={"message": str(exc), "type": "invalid_request_error"},
+        status_code=422,
+    )
+
+
+@app.exception_handler(ImageFetchError)
+async def image_fetch_error_handler(request: Request, exc: ImageFetchError):
+    return JSONResponse(
+        content={"message": str(exc), "type": "invalid_request_error"},
+        status_code=400,
+    )
+
+
 @serve.deployment(ray_actor_options={"num_cpus": 0.1})
 @serve.ingress(app)
 class Controller:
-    def __init__(self, backend: DeploymentHandle):
-        self.backend = backend
+    def __init__(self, backend: DeploymentHandle, retries: int = 0, request_timeout: float = 0,
+                 canary_application: str = "", canary_weight: int = 0,
+                 dedup_ttl_seconds: float = 0, dedup_max_entries: int = 1000,
+                 image_fetch_max_bytes: int = 0, image_fetch_timeout_seconds: float = 10):
+        self.backend = traced_handle(canary_handle(backend, CanaryConfig(canary_application, canary_weight)))
+        # Streaming requests are never retried, see serve._utils.router_retry.
+        self.retries = retries
+        self.request_timeout = request_timeout
+        self.dedup = RequestDeduplicator(DedupConfig(dedup_ttl_seconds, dedup_max_entries))
+        self.image_fetcher = ImageFetcher(ImageFetchConfig(image_fetch_max_bytes, image_fetch_timeout_seconds))
+        self.metrics = ModelRequestMetrics()
         logger.info("[Controller] Initialized with backend handle")
 
     @app.post("/v1/chat/completions")
+    @observe_model_requests("/v1/chat/completions")
     async def chat(self, request: Request):
         payload = await request.json()
+        payload = await self.image_fetcher.embed_images(payload)
         if payload.get("stream", False):
             gen: DeploymentResponseGenerator = (
                 self.backend.options(stream=True).chat_completion_stream.remote(payload)
             )
             return StreamingResponse(content=gen, media_type="text/event-stream")
-        result = await self.backend.options(stream=False).chat_completion.remote(payload)
+        result = await self.dedup.run(
+            await idempotency_key(request),
+            lambda: call_with_retry(lambda: self.backend.options(stream=False).chat_completion.remote(payload), self.retries, self.request_timeout),
+        )
         return _to_json_response(result)
 
     @app.post("/v1/completions")
+    @observe_model_requests("/v1/completions")
     async def completions(self, request: Request):
         payload = await request.json()
         if payload.get("stream", False):
@@ -445,18 +525,22 @@ class Controller:
                 self.backend.options(stream=True).completion_stream.remote(payload)
             )
             return StreamingResponse(content=gen, media_type="text/event-stream")
-        result = await self.backend.options(stream=False).completion.remote(payload)
+        result = await self.dedup.run(
+            await idempotency_key(request),
+            lambda: call_with_retry(lambda: self.backend.options(stream=False).completion.remote(payload), self.retries, self.request_timeout),
+        )
         return _to_json_response(result)
 
     @app.post("/v1/embeddings")
+    @observe_model_requests("/v1/embeddings")
     async def embeddings(self, request: Request):
         payload = await request.json()
-        result = await self.backend.options(stream=False).embedding.remote(payload)
+        result = await call_with_retry(lambda: self.backend.options(stream=False).embedding.remote(payload), self.retries, self.request_timeout)
         return _to_json_response(result)
 
     @app.get("/v1/models")
     async def models(self, request: Request):
-        info = await self.backend.get_model_info.remote()
+        info = await call_with_retry(lambda: self.backend.get_model_info.remote(), self.retries, self.request_timeout)
         return JSONResponse(content={
             "object": "list",
             "data": [{
@@ -493,7 +577,11 @@ def app_builder(args: Dict[str, Any]) -> Application:
     controller_options = deployment_options.get("controller", {})
 
     scheduler_config = deployment_options.get("scheduler", {})
-    request_router_config = _build_request_router_config(scheduler_config)
+    retry_config = parse_router_retry_config(deployment_options)
+    canary_config = parse_canary_config(args)
+    dedup_config = parse_dedup_config(deployment_options)
+    image_fetch_config = parse_image_fetch_config(deployment_options)
+    request_router_config = _build_request_router_config(scheduler_config, retry_config)
 
     backend_deploy_options: Dict[str, Any] = {
         "max_ongoing_requests": backend_options.get("max_ongoing_requests", 100),
@@ -509,6 +597,24 @@ def app_builder(args: Dict[str, Any]) -> Application:
     if request_router_config is not None:
         backend_deploy_options["request_router_config"] = request_router_config
 
+    # Reserve a placement group per replica, see topology_aware_placement and placement_group
+    if backend_options.get("placement_group_bundles"):
+        backend_deploy_options["placement_group_bundles"] = backend_options["placement_group_bundles"]
+        backend_deploy_options["placement_group_strategy"] = backend_options.get(
+            "placement_group_strategy", "STRICT_PACK"
+        )
+
+    # Gang schedule the replicas into a placement group created ahead, see placement_group
+    if backend_options.get("placement_group_name"):
+        schedule_in_placement_group(
+            backend_options["placement_group_name"], backend_deploy_options["ray_actor_options"]
+        )
+
+    # Replica health checking, see the health_check_* deployment options
+    for key in ("health_check_period_s", "health_check_timeout_s"):
+        if backend_options.get(key) is not None:
+            backend_deploy_options[key] = backend_options[key]
+
     backend_container = args.get("backend_container")
     if backend_container:
         backend_deploy_options["ray_actor_options"]["runtime_env"] = build_backend_runtime_env(backend_container)
@@ -535,6 +641,16 @@ def app_builder(args: Dict[str, Any]) -> Application:
             "num_cpus": controller_options.get("num_cpus", 0.1),
             "num_gpus": controller_options.get("num_gpus", 0),
         },
-    ).bind(backend=backend_deployment)
+    ).bind(
+        backend=backend_deployment,
+        retries=retry_config.retries,
+        request_timeout=retry_config.request_timeout_seconds,
+        canary_application=canary_config.application,
+        canary_weight=canary_config.weight,
+        dedup_ttl_seconds=dedup_config.ttl_seconds,
+        dedup_max_entries=dedup_config.max_entries,
+        image_fetch_max_bytes=image_fetch_config.max_bytes,
+        image_fetch_timeout_seconds=image_fetch_config.timeout_seconds,
+    )
 
     return controller_deployment
@@ -34,6 +34,15 @@ from downloader import get_downloader, build_request_from_model_args, download_w
 from serve._metrics.ray_stat_logger import NeutreeRayStatLogger
 from serve._utils import build_base_model_paths, coerce_args, filter_engine_args
 from serve._utils.runtime_env import build_backend_runtime_env
+from serve._utils.placement_group import schedule_in_placement_group
+from serve._metrics.request_metrics import ModelRequestMetrics, observe_model_requests
+from serve._utils.access_log import AccessLogMiddleware
+from serve._utils.compression import CompressionMiddleware
+from serve._utils.tracing import TracingMiddleware, accepts_trace_context, trace_request, traced_handle
+from serve._utils.canary import CanaryConfig, canary_handle, parse_canary_config
+from serve._utils.dedup import DedupConfig, IdempotencyKeyMismatch, RequestDeduplicator, idempotency_key, parse_dedup_config
+from serve._utils.image_fetch import ImageFetchConfig, ImageFetchError, ImageFetcher, parse_image_fetch_config
+from serve._utils.router_retry import TIMEOUT_ERRORS, RouterRetryConfig, call_with_retry, parse_router_retry_config
 from serve._utils.vllm_task_translate import task_kwargs as _task_kwargs
 
 
@@ -49,6 +58,9 @@ SCHEDULER_CLASS_PATHS = {
     SchedulerType.CONSISTENT_HASH: "serve._replica_scheduler.chwbl_scheduler:ConsistentHashReplicaScheduler",
 }
 
+# Request router used for the default POW2 scheduler when circuit breaking is configured
+CIRCUIT_BREAKING_POW2_CLASS_PATH = "serve._replica_scheduler.pow2_scheduler:CircuitBreakingPow2ReplicaScheduler"
+
 
 @serve.deployment(ray_actor_options={"num_cpus": 1, "num_gpus": 1})
 class Backend:
@@ -274,9 +286,10 @@ class Backend:
             )
         return self.openai_serving_score
 
+    @accepts_trace_context
     async def generate(self, payload: Any):
         await self._ensure_chat()
-        result = await self.openai_serving_chat.create_chat_completion(ChatCompletionRequest(**payload), None)
+        result = await self.openai_serving_chat.create_chat_completion(ChatCompletionRequest(**payload), trace_request())
 
         is_stream = payload.get("stream") is True
 
@@ -298,6 +311,7 @@ class Backend:
 
         return result
 
+    @accepts_trace_context
     async def generate_embeddings(self, payload: Any):
         await self._ensure_embedding()
         try:
@@ -312,8 +326,9 @@ class Backend:
                     code=400,
                 )
             )
-        return await self.openai_serving_embedding.create_embedding(request, None)
+        return await self.openai_serving_embedding.create_embedding(request, trace_request())
 
+    @accepts_trace_context
     async def rerank(self, payload: Any):
         """
         Rerank documents based on their relevance to a query.
@@ -321,7 +336,7 @@ class Backend:
         """
         await self._ensure_score()
         request = RerankRequest(**payload)
-        return await self.openai_serving_score.do_rerank(request, None)
+        return await self.openai_serving_score.do_rerank(request, trace_request())
 
     async def show_available_models(self):
         models = await self._ensure_models()
@@ -329,6 +344,10 @@ class Backend:
 
 
 app = FastAPI()
+# Innermost, so access logs see the decompressed requests and uncompressed responses.
+app.add_middleware(AccessLogMiddleware)
+app.add_middleware(CompressionMiddleware)
+app.add_middleware(TracingMiddleware)
 app.add_middleware(RawContextMiddleware, plugins=(RequestIdPlugin(validate=False),))
 app.add_middleware(
     CORSMiddleware,
@@ -339,22 +358,69 @@ app.add_middleware(
 )
 
 
+async def request_timeout_handler(request: Request, exc: TimeoutError):
+    return JSONResponse(
+        content={"message": str(exc), "type": "timeout"},
+        status_code=504
+    )
+
+
+for _timeout_error in TIMEOUT_ERRORS:
+    app.add_exception_handler(_timeout_error, request_timeout_handler)
+
+
+@app.exception_handler(IdempotencyKeyMismatch)
+async def idempotency_key_mismatch_handler(request: Request, exc: IdempotencyKeyMismatch):
+    return JSONResponse(
+        content={"message": str(exc), "type": "invalid_request_error"},
+        status_code=422
+    )
+
+
+@app.exception_handler(ImageFetchError)
+async def image_fetch_error_handler(request: Request, exc: ImageFetchError):
+    return JSONResponse(
+        content={"message": str(exc), "type": "invalid_request_error"},
+        status_code=400
+    )
+
+
 @serve.deployment(ray_actor_options={"num_cpus": 0.1})
 @serve.ingress(app)
 class Controller:
-    def __init__(self, backend: DeploymentHandle):
+    def __init__(self, backend: DeploymentHandle, retries: int = 0, request_timeout: float = 0,
+                 canary_application: str = "", canary_weight: int = 0,
+                 dedup_ttl_seconds: float = 0, dedup_max_entries: int = 1000,
+                 image_fetch_max_bytes: int = 0, image_fetch_timeout_seconds: float = 10):
         """
         Controller deployment that handles HTTP routing and calls the backend.
 
         Args:
             backend: Handle to the Backend deployment
+            retries: Times a non-streaming request is retried after a transient replica failure
+            request_timeout: Seconds a non-streaming request may take, 0 leaves it unbounded
+            canary_application: Application whose Backend serves the canary share of the requests
+            canary_weight: Percentage of the requests sent to the canary, 0 sends none
+            dedup_ttl_seconds: Seconds the result of a generation request is returned to its
+                duplicates with the same idempotency key, 0 disables deduplication
+            dedup_max_entries: Idempotency keys whose result is kept
+            image_fetch_max_bytes: Largest image fetched for the image URLs of chat requests,
+                0 passes the URLs to the engine as they are
+            image_fetch_timeout_seconds: Seconds an image may take to be fetched
         """
-        self.backend = backend
+        self.backend = traced_handle(canary_handle(backend, CanaryConfig(canary_application, canary_weight)))
+        self.retries = retries
+        self.request_timeout = request_timeout
+        self.dedup = RequestDeduplicator(DedupConfig(dedup_ttl_seconds, dedup_max_entries))
+        self.image_fetcher = ImageFetcher(ImageFetchConfig(image_fetch_max_bytes, image_fetch_timeout_seconds))
+        self.metrics = ModelRequestMetrics()
         print("[Controller] Initialized with backend handle")
 
     @app.post("/v1/chat/completions")
+    @observe_model_requests("/v1/chat/completions")
     async def chat(self, request: Request):
         req_obj = await request.json()
+        req_obj = await self.image_fetcher.embed_images(req_obj)
         stream = req_obj.get("stream", False)
 
         if stream:
@@ -379,32 +445,37 @@ class Controller:
             )
         else:
             # Handle non-streaming response as before
-            result = await self.backend.options(stream=False).generate.remote(req_obj)
+            result = await self.dedup.run(
+                await idempotency_key(request),
+                lambda: call_with_retry(lambda: self.backend.options(stream=False).generate.remote(req_obj), self.retries, self.request_timeout),
+            )
             if isinstance(result, ErrorResponse):
                 return JSONResponse(content=result.model_dump(), status_code=result.error.code)
             return JSONResponse(content=result.model_dump())
 
     @app.post("/v1/embeddings")
+    @observe_model_requests("/v1/embeddings")
     async def embeddings(self, request: Request):
         """Embeddings endpoint for text-embedding models"""
         req_obj = await request.json()
-        result = await self.backend.options(stream=False).generate_embeddings.remote(req_obj)
+        result = await call_with_retry(lambda: self.backend.options(stream=False).generate_embeddings.remote(req_obj), self.retries, self.request_timeout)
         if isinstance(result, ErrorResponse):
             return JSONResponse(content=result.model_dump(), status_code=result.error.code)
         return JSONResponse(content=result.model_dump())
 
     @app.post("/v1/rerank")
+    @observe_model_requests("/v1/rerank")
     async def rerank(self, request: Request):
         """Rerank endpoint for cross-encoder/reranker models"""
         req_obj = await request.json()
-        result = await self.backend.options(stream=False).rerank.remote(req_obj)
+        result = await call_with_retry(lambda: self.backend.options(stream=False).rerank.remote(req_obj), self.retries, self.request_timeout)
         if isinstance(result, ErrorResponse):
             return JSONResponse(content=result.model_dump(), status_code=result.error.code)
         return JSONResponse(content=result.model_dump())
 
     @app.get("/v1/models")
     async def models(self, request: Request):
-        result = await self.backend.show_available_models.remote()
+        result = await call_with_retry(lambda: self.backend.show_available_models.remote(), self.retries, self.request_timeout)
         return JSONResponse(content=result.model_dump())
 
     @app.get("/health")
@@ -412,7 +483,10 @@ class Controller:
         return {"status": "ok"}
 
 
-def _build_request_router_config(scheduler_config: Dict[str, Any]) -> Optional[RequestRouterConfig]:
+def _build_request_router_config(
+    scheduler_config: Dict[str, Any],
+    retry_config: RouterRetryConfig,
+) -> Optional[RequestRouterConfig]:
     """Build RequestRouterConfig based on scheduler configuration.
 
     Args:
@@ -421,22 +495,26 @@ def _build_request_router_config(scheduler_config: Dict[str, Any]) -> Optional[R
             - virtual_nodes: Number of virtual nodes for consistent hash (default: 100)
             - load_factor: Load factor for bounded load (default: 1.25)
             - max_user_messages_for_cache: Number of user messages for cache key (default: 2)
+        retry_config: Router retry settings, its circuit breaker settings are passed to the router.
 
     Returns:
-        RequestRouterConfig if custom scheduler is specified, None for default POW2.
+        RequestRouterConfig if custom scheduler or circuit breaking is specified, None for default POW2.
     """
     scheduler_type = scheduler_config.get('type', SchedulerType.POW2)
 
-    # Use default POW2 scheduler
-    if scheduler_type == SchedulerType.POW2:
-        print(f"[app_builder] Using default POW2 scheduler")
-        return None
-
     # Get the custom router class path
     router_class_path = SCHEDULER_CLASS_PATHS.get(scheduler_type)
-    if not router_class_path:
+    if not router_class_path and scheduler_type != SchedulerType.POW2:
         print(f"[app_builder] Unknown scheduler type: {scheduler_type}, using default POW2")
-        return None
+        scheduler_type = SchedulerType.POW2
+
+    # Use default POW2 scheduler, replaced by its circuit breaking variant when configured
+    if scheduler_type == SchedulerType.POW2:
+        if not retry_config.circuit_breaker_enabled:
+            print(f"[app_builder] Using default POW2 scheduler")
+            return None
+
+        router_class_path = CIRCUIT_BREAKING_POW2_CLASS_PATH
 
     # Build kwargs for the custom router
     router_kwargs = {}
@@ -447,6 +525,8 @@ def _build_request_router_config(scheduler_config: Dict[str, Any]) -> Optional[R
             "max_user_messages_for_cache": scheduler_config.get('max_user_messages_for_cache', 2),
         }
 
+    router_kwargs.update(retry_config.request_router_kwargs())
+
     print(f"[app_builder] Using custom scheduler: {scheduler_type}, class: {router_class_path}, kwargs: {router_kwargs}")
 
     return RequestRouterConfig(
@@ -470,7 +550,11 @@ def app_builder(args: Dict[str, Any]) -> Application:
 
     # Extract scheduler configuration and build RequestRouterConfig
     scheduler_config = deployment_options.get('scheduler', {})
-    request_router_config = _build_request_router_config(scheduler_config)
+    retry_config = parse_router_retry_config(deployment_options)
+    canary_config = parse_canary_config(args)
+    dedup_config = parse_dedup_config(deployment_options)
+    image_fetch_config = parse_image_fetch_config(deployment_options)
+    request_router_config = _build_request_router_config(scheduler_config, retry_config)
 
     # Build backend deployment options
     backend_deploy_options = {
@@ -488,6 +572,20 @@ def app_builder(args: Dict[str, Any]) -> Application:
     if request_router_config is not None:
         backend_deploy_options["request_router_config"] = request_router_config
 
+    # Reserve a placement group per replica, see topology_aware_placement and placement_group
+    if backend_options.get('placement_group_bundles'):
+        backend_deploy_options["placement_group_bundles"] = backend_options['placement_group_bundles']
+        backend_deploy_options["placement_group_strategy"] = backend_options.get('placement_group_strategy', 'STRICT_PACK')
+
+    # Gang schedule the replicas into a placement group created ahead, see placement_group
+    if backend_options.get('placement_group_name'):
+        schedule_in_placement_group(backend_options['placement_group_name'], backend_deploy_options["ray_actor_options"])
+
+    # Replica health checking, see the health_check_* deployment options
+    for key in ('health_check_period_s', 'health_check_timeout_s'):
+        if backend_options.get(key) is not None:
+            backend_deploy_options[key] = backend_options[key]
+
     # Override Backend's runtime_env.container with the full config (GPU options,
     # volume mounts, NFS) so only Backend replicas require GPU nodes.
     # Ray replaces "container" per-key, so this must be self-contained.
@@ -520,6 +618,14 @@ def app_builder(args: Dict[str, Any]) -> Application:
         }
     ).bind(
         backend=backend_deployment,
+        retries=retry_config.retries,
+        request_timeout=retry_config.request_timeout_seconds,
+        canary_application=canary_config.application,
+        canary_weight=canary_config.weight,
+        dedup_ttl_seconds=dedup_config.ttl_seconds,
+        dedup_max_entries=dedup_config.max_entries,
+        image_fetch_max_bytes=image_fetch_config.max_bytes,
+        image_fetch_timeout_seconds=image_fetch_config.timeout_seconds,
     )
 
     return controller_deployment
@@ -44,6 +44,7 @@ func NewBuilder() *Builder {
 		"k8s-proxy":       ProxiesRouteFactory(proxies.RegisterKubernetesProxyRoutes),
 		"endpoint-logs":   LogsRouteFactory(logs.RegisterEndpointLogsRoutes),
 		"ai-traces":       LogsRouteFactory(logs.RegisterAITraceRoutes),
+		"endpoint-exec":   LogsRouteFactory(logs.RegisterEndpointExecRoutes),
 		"system":          SystemRouteFactory(system.RegisterSystemRoutes),
 		// Auth route (no auth required for authentication itself)
 		"auth": AuthRouteFactory(auth.RegisterAuthRoutes),
@@ -92,6 +93,7 @@ func NewBuilder() *Builder {
 		"system":        {"auth"},
 		"endpoint-logs": {"auth"},
 		"ai-traces":     {"auth"},
+		"endpoint-exec": {"auth"},
 		// PostgREST proxy routes now require auth middleware to:
 		// 1. Validate JWT tokens (pass-through to PostgREST)
 		// 2. Convert API keys to PostgREST-compatible JWT tokens
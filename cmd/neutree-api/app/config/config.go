@@ -31,8 +31,12 @@ type APIConfig struct {
 
 	// External services
 	StorageAccessURL string
+	StorageSchema    string
 	AuthEndpoint     string
 	GrafanaURL       string
 	AITraceStoreURL  string
 	Version          string
+
+	// DefaultWorkspace is the workspace endpoints are created in when none is given.
+	DefaultWorkspace string
 }
@@ -48,9 +48,11 @@ func ProxiesRouteFactory(register ProxyRegisterFunc) RouteFactory {
 		register(deps.Group, deps.Middlewares, &proxies.Dependencies{
 			Storage:          deps.Config.Storage,
 			StorageAccessURL: deps.Config.StorageAccessURL,
+			StorageSchema:    deps.Config.StorageSchema,
 			AuthEndpoint:     deps.Config.AuthEndpoint,
 			AuthConfig:       deps.Config.AuthConfig,
 			ImageService:     registry.NewImageService(),
+			DefaultWorkspace: deps.Config.DefaultWorkspace,
 		})
 
 		return nil
@@ -100,6 +102,7 @@ func CredentialsRouteFactory(register CredentialsRegisterFunc) RouteFactory {
 		register(deps.Group, deps.Middlewares, &credentials.Dependencies{
 			Storage:          deps.Config.Storage,
 			StorageAccessURL: deps.Config.StorageAccessURL,
+			StorageSchema:    deps.Config.StorageSchema,
 		})
 
 		return nil
@@ -1,7 +1,11 @@
 package options
 
 import (
+	"errors"
+
 	"github.com/spf13/pflag"
+
+	"github.com/neutree-ai/neutree/internal/routes/proxies"
 )
 
 // APIOptions holds API application configuration options
@@ -9,6 +13,8 @@ type APIOptions struct {
 	GinMode   string
 	StaticDir string
 	Version   string
+
+	DefaultWorkspace string
 }
 
 // NewAPIOptions creates new API options with default values
@@ -16,6 +22,8 @@ func NewAPIOptions() *APIOptions {
 	return &APIOptions{
 		GinMode:   "release",
 		StaticDir: "./public",
+
+		DefaultWorkspace: proxies.DefaultWorkspaceName,
 	}
 }
 
@@ -23,9 +31,15 @@ func NewAPIOptions() *APIOptions {
 func (o *APIOptions) AddFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&o.GinMode, "gin-mode", o.GinMode, "gin mode: debug, release, test")
 	fs.StringVar(&o.StaticDir, "static-dir", o.StaticDir, "directory for static files")
+	fs.StringVar(&o.DefaultWorkspace, "default-workspace", o.DefaultWorkspace,
+		"workspace endpoints are created in when none is given, provisioned on first use")
 }
 
 // Validate validates API options
 func (o *APIOptions) Validate() error {
+	if o.DefaultWorkspace == "" {
+		return errors.New("default workspace name is required")
+	}
+
 	return nil
 }
@@ -64,11 +64,28 @@ func (o *Options) Validate() error {
 // Config converts options to API configuration
 func (o *Options) Config() (*config.APIConfig, error) {
 	// Initialize storage
-	s, err := storage.New(storage.Options{
+	storageOptions := storage.Options{
 		AccessURL: o.Storage.AccessURL,
-		Scheme:    "api",
+		Scheme:    o.Storage.Schema,
 		JwtSecret: o.Storage.JwtSecret,
-	})
+		Retry: storage.RetryPolicy{
+			MaxAttempts: o.Storage.RetryMaxAttempts,
+			BaseDelay:   o.Storage.RetryBaseDelay,
+			Jitter:      o.Storage.RetryJitter,
+			Budget:      o.Storage.RetryBudget,
+		},
+		CircuitBreaker: storage.CircuitBreakerPolicy{
+			FailureThreshold: o.Storage.CircuitFailureThreshold,
+			OpenDuration:     o.Storage.CircuitOpenDuration,
+		},
+		CacheTTL: o.Storage.CacheTTL,
+	}
+
+	if err := storageOptions.Validate(); err != nil {
+		return nil, err
+	}
+
+	s, err := storage.New(storageOptions)
 	if err != nil {
 		return nil, fmt.Errorf("failed to init storage: %w", err)
 	}
@@ -107,9 +124,11 @@ func (o *Options) Config() (*config.APIConfig, error) {
 		},
 
 		StorageAccessURL: o.Storage.AccessURL,
+		StorageSchema:    o.Storage.Schema,
 		AuthEndpoint:     o.External.AuthEndpoint,
 		GrafanaURL:       grafanaExternalURL,
 		AITraceStoreURL:  o.External.AITraceStoreURL,
 		Version:          version.Get().AppVersion,
+		DefaultWorkspace: o.API.DefaultWorkspace,
 	}, nil
 }
@@ -0,0 +1,85 @@
+package cluster
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/neutree-ai/neutree/cmd/neutree-cli/app/cmd/global"
+)
+
+func NewArchiveCmd() *cobra.Command {
+	var name string
+
+	cmd := &cobra.Command{
+		Use:   "archive",
+		Short: "Archive a cluster",
+		Long: `Archive a cluster instead of deleting it.
+
+The archived cluster is kept for audit and recovery, but it is no longer listed
+or reconciled and its resources are left as they are. Clusters still referenced
+by endpoints can not be archived.
+
+Examples:
+  # Archive the cluster gpu-a
+  neutree-cli cluster archive --name gpu-a
+`,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			apiClient, err := global.NewClient()
+			if err != nil {
+				return err
+			}
+
+			if err := apiClient.Clusters.Archive(workspace, name); err != nil {
+				return fmt.Errorf("failed to archive cluster %q: %w", name, err)
+			}
+
+			fmt.Printf("Successfully archived cluster %q\n", name)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&name, "name", "n", "", "Cluster name (required)")
+	_ = cmd.MarkFlagRequired("name")
+
+	return cmd
+}
+
+func NewRestoreCmd() *cobra.Command {
+	var name string
+
+	cmd := &cobra.Command{
+		Use:   "restore",
+		Short: "Restore an archived cluster",
+		Long: `Restore an archived cluster, it is reconciled again.
+
+Examples:
+  # Restore the archived cluster gpu-a
+  neutree-cli cluster restore --name gpu-a
+`,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			apiClient, err := global.NewClient()
+			if err != nil {
+				return err
+			}
+
+			if err := apiClient.Clusters.Restore(workspace, name); err != nil {
+				return fmt.Errorf("failed to restore cluster %q: %w", name, err)
+			}
+
+			fmt.Printf("Successfully restored cluster %q\n", name)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&name, "name", "n", "", "Cluster name (required)")
+	_ = cmd.MarkFlagRequired("name")
+
+	return cmd
+}
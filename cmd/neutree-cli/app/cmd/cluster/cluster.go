@@ -0,0 +1,22 @@
+package cluster
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var workspace string
+
+func NewClusterCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cluster",
+		Short: "Management commands for clusters",
+		Long:  `These commands help you archive clusters taken out of use and restore them.`,
+	}
+
+	cmd.PersistentFlags().StringVarP(&workspace, "workspace", "w", "default", "Workspace to use")
+
+	cmd.AddCommand(NewArchiveCmd())
+	cmd.AddCommand(NewRestoreCmd())
+
+	return cmd
+}
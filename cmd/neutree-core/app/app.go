@@ -34,7 +34,7 @@ func (a *App) Run(ctx context.Context) error {
 
 	go a.config.ObsCollectConfigManager.Start(ctx)
 
-	go cron.StartCrons(ctx, a.config.Storage) //nolint:errcheck
+	go cron.StartCrons(ctx, a.config.Storage, a.config.CostRates) //nolint:errcheck
 
 	// Start all controllers
 	for name, ctrl := range a.controllers {
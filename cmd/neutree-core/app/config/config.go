@@ -1,10 +1,13 @@
 package config
 
 import (
+	"time"
+
 	"github.com/gin-gonic/gin"
 
 	"github.com/neutree-ai/neutree/internal/accelerator"
 	"github.com/neutree-ai/neutree/internal/auth"
+	"github.com/neutree-ai/neutree/internal/costaccounting"
 	"github.com/neutree-ai/neutree/internal/engine"
 	"github.com/neutree-ai/neutree/internal/gateway"
 	"github.com/neutree-ai/neutree/internal/observability/manager"
@@ -17,6 +20,10 @@ type ControllerConfig struct {
 	Workers int
 }
 
+type EndpointControllerConfig struct {
+	DrainTimeout time.Duration
+}
+
 type ClusterControllerConfig struct {
 	DefaultClusterVersion string
 	MetricsRemoteWriteURL string
@@ -44,8 +51,14 @@ type CoreConfig struct {
 	// cluster controller specific config
 	ClusterControllerConfig *ClusterControllerConfig
 
+	// endpoint controller specific config
+	EndpointControllerConfig *EndpointControllerConfig
+
 	// core server config
 	ServerConfig *ServerConfig
 
+	// hourly rates endpoint cost is accounted at
+	CostRates costaccounting.Rates
+
 	Scheme *scheme.Scheme
 }
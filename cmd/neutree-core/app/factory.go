@@ -85,6 +85,8 @@ func NewEndpointControllerFactory() ControllerFactory {
 			Storage:        opts.config.Storage,
 			Gw:             opts.config.Gateway,
 			AcceleratorMgr: opts.config.AcceleratorManager,
+			ImageService:   opts.config.ImageService,
+			DrainTimeout:   opts.config.EndpointControllerConfig.DrainTimeout,
 		})
 		if err != nil {
 			return nil, errors.Wrapf(err, "failed to create endpoint controller")
@@ -131,6 +133,7 @@ func NewRoleAssignmentControllerFactory() ControllerFactory {
 	return func(opts *ControllerOptions) (controllers.Controller, error) {
 		roleAssignmentController, err := controllers.NewRoleAssignmentController(&controllers.RoleAssignmentControllerOption{
 			Storage: opts.config.Storage,
+			Gw:      opts.config.Gateway,
 		})
 		if err != nil {
 			return nil, errors.Wrapf(err, "failed to create role assignment controller")
@@ -1,21 +1,27 @@
 package options
 
 import (
+	"time"
+
 	"github.com/spf13/pflag"
 )
 
 type ControllerOptions struct {
-	Workers int
+	Workers              int
+	EndpointDrainTimeout time.Duration
 }
 
 func NewControllerOptions() *ControllerOptions {
 	return &ControllerOptions{
-		Workers: 5,
+		Workers:              5,
+		EndpointDrainTimeout: 30 * time.Second,
 	}
 }
 
 func (o *ControllerOptions) AddFlags(fs *pflag.FlagSet) {
 	fs.IntVar(&o.Workers, "controller-workers", o.Workers, "controller workers")
+	fs.DurationVar(&o.EndpointDrainTimeout, "endpoint-drain-timeout", o.EndpointDrainTimeout,
+		"how long deleting an endpoint waits for its requests in flight to finish")
 }
 
 func (o *ControllerOptions) Validate() error {
@@ -0,0 +1,28 @@
+package options
+
+import (
+	"github.com/spf13/pflag"
+
+	"github.com/neutree-ai/neutree/internal/costaccounting"
+)
+
+type CostOptions struct {
+	Rates map[string]string
+}
+
+func NewCostOptions() *CostOptions {
+	return &CostOptions{
+		Rates: map[string]string{},
+	}
+}
+
+func (o *CostOptions) AddFlags(fs *pflag.FlagSet) {
+	fs.StringToStringVar(&o.Rates, "cost-rates", o.Rates,
+		"hourly endpoint cost rates, keyed by accelerator type (per accelerator-hour) or \""+
+			costaccounting.ReplicaRateKey+"\" (per replica-hour), e.g. nvidia_gpu=2.5,replica=0.1")
+}
+
+func (o *CostOptions) Validate() error {
+	_, err := costaccounting.ParseRates(o.Rates)
+	return err
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/neutree-ai/neutree/cmd/neutree-core/app/config"
 	"github.com/neutree-ai/neutree/internal/accelerator"
+	"github.com/neutree-ai/neutree/internal/costaccounting"
 	"github.com/neutree-ai/neutree/internal/engine"
 	"github.com/neutree-ai/neutree/internal/gateway"
 	"github.com/neutree-ai/neutree/internal/observability/manager"
@@ -26,6 +27,7 @@ type NeutreeCoreOptions struct {
 	Observability *ObservabilityOptions
 	Cluster       *ClusterOptions
 	Auth          *AuthOptions
+	Cost          *CostOptions
 }
 
 func NewOptions() *NeutreeCoreOptions {
@@ -37,6 +39,7 @@ func NewOptions() *NeutreeCoreOptions {
 		Observability: NewObservabilityOptions(),
 		Cluster:       NewClusterOptions(),
 		Auth:          NewAuthOptions(),
+		Cost:          NewCostOptions(),
 	}
 }
 
@@ -48,13 +51,22 @@ func (o *NeutreeCoreOptions) AddFlags(fs *pflag.FlagSet) {
 	o.Observability.AddFlags(fs)
 	o.Cluster.AddFlags(fs)
 	o.Auth.AddFlags(fs)
+	o.Cost.AddFlags(fs)
 }
 
 func (o *NeutreeCoreOptions) Validate() error {
+	if err := o.Storage.Validate(); err != nil {
+		return err
+	}
+
 	if err := o.Auth.Validate(); err != nil {
 		return err
 	}
 
+	if err := o.Cost.Validate(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -98,20 +110,33 @@ func (o *NeutreeCoreOptions) Config(scheme *scheme.Scheme) (*config.CoreConfig,
 
 	c.EngineRegistry = engineRegistry
 
-	s, err := storage.New(storage.Options{
+	storageOptions := storage.Options{
 		AccessURL: o.Storage.AccessURL,
-		Scheme:    "api",
+		Scheme:    o.Storage.Schema,
 		JwtSecret: o.Storage.JwtSecret,
-	})
+		Retry: storage.RetryPolicy{
+			MaxAttempts: o.Storage.RetryMaxAttempts,
+			BaseDelay:   o.Storage.RetryBaseDelay,
+			Jitter:      o.Storage.RetryJitter,
+			Budget:      o.Storage.RetryBudget,
+		},
+		CircuitBreaker: storage.CircuitBreakerPolicy{
+			FailureThreshold: o.Storage.CircuitFailureThreshold,
+			OpenDuration:     o.Storage.CircuitOpenDuration,
+		},
+		CacheTTL: o.Storage.CacheTTL,
+	}
+
+	if err = storageOptions.Validate(); err != nil {
+		return nil, err
+	}
+
+	s, err := storage.New(storageOptions)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to init storage")
 	}
 
-	objStorage, err := storage.NewObjectStorage(storage.Options{
-		AccessURL: o.Storage.AccessURL,
-		Scheme:    "api",
-		JwtSecret: o.Storage.JwtSecret,
-	}, c.Scheme)
+	objStorage, err := storage.NewObjectStorage(storageOptions, c.Scheme)
 
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to init object storage")
@@ -163,6 +188,9 @@ func (o *NeutreeCoreOptions) Config(scheme *scheme.Scheme) (*config.CoreConfig,
 	c.ControllerConfig = &config.ControllerConfig{
 		Workers: o.Controller.Workers,
 	}
+	c.EndpointControllerConfig = &config.EndpointControllerConfig{
+		DrainTimeout: o.Controller.EndpointDrainTimeout,
+	}
 	c.ClusterControllerConfig = &config.ClusterControllerConfig{
 		DefaultClusterVersion: o.Cluster.DefaultClusterVersion,
 		MetricsRemoteWriteURL: o.Observability.MetricsRemoteWriteURL,
@@ -172,6 +200,11 @@ func (o *NeutreeCoreOptions) Config(scheme *scheme.Scheme) (*config.CoreConfig,
 		Host: o.Server.Host,
 	}
 
+	c.CostRates, err = costaccounting.ParseRates(o.Cost.Rates)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse cost rates")
+	}
+
 	jwtToken, err := storage.CreateServiceToken(o.Storage.JwtSecret)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to create service token for auth client")
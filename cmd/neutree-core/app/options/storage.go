@@ -1,26 +1,94 @@
 package options
 
 import (
+	"time"
+
+	"github.com/pkg/errors"
 	"github.com/spf13/pflag"
+
+	"github.com/neutree-ai/neutree/pkg/storage"
 )
 
 type StorageOptions struct {
 	AccessURL string
+	Schema    string
 	JwtSecret string
+
+	RetryMaxAttempts int
+	RetryBaseDelay   time.Duration
+	RetryJitter      float64
+	RetryBudget      float64
+
+	CircuitFailureThreshold int
+	CircuitOpenDuration     time.Duration
+
+	CacheTTL time.Duration
 }
 
 func NewStorageOptions() *StorageOptions {
 	return &StorageOptions{
 		AccessURL: "http://postgrest:6432",
+		Schema:    storage.DefaultSchema,
 		JwtSecret: "jwt_secret",
+
+		RetryMaxAttempts: storage.DefaultRetryPolicy().MaxAttempts,
+		RetryBaseDelay:   storage.DefaultRetryPolicy().BaseDelay,
+		RetryJitter:      storage.DefaultRetryPolicy().Jitter,
+		RetryBudget:      storage.DefaultRetryPolicy().Budget,
+
+		CircuitFailureThreshold: storage.DefaultCircuitBreakerPolicy().FailureThreshold,
+		CircuitOpenDuration:     storage.DefaultCircuitBreakerPolicy().OpenDuration,
 	}
 }
 
 func (o *StorageOptions) AddFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&o.AccessURL, "storage-access-url", o.AccessURL, "postgrest url")
+	fs.StringVar(&o.Schema, "storage-schema", o.Schema, "postgrest schema holding the neutree tables")
 	fs.StringVar(&o.JwtSecret, "storage-jwt-secret", o.JwtSecret, "storage auth token")
+	fs.IntVar(&o.RetryMaxAttempts, "storage-retry-max-attempts", o.RetryMaxAttempts,
+		"times a postgrest read failing with a network error or a 5xx response is sent, 1 disables retries")
+	fs.DurationVar(&o.RetryBaseDelay, "storage-retry-base-delay", o.RetryBaseDelay,
+		"delay before the first retry of a postgrest read, doubled before each next one")
+	fs.Float64Var(&o.RetryJitter, "storage-retry-jitter", o.RetryJitter,
+		"fraction of each retry delay added at random, from 0 to 1")
+	fs.Float64Var(&o.RetryBudget, "storage-retry-budget", o.RetryBudget,
+		"postgrest read retries allowed per read, e.g. 0.2 allows one retry for every five reads, 0 does not limit retries")
+	fs.IntVar(&o.CircuitFailureThreshold, "storage-circuit-failure-threshold", o.CircuitFailureThreshold,
+		"consecutive failed postgrest requests after which requests fail fast until a probe succeeds, 0 disables the circuit breaker")
+	fs.DurationVar(&o.CircuitOpenDuration, "storage-circuit-open-duration", o.CircuitOpenDuration,
+		"how long postgrest requests fail fast before a probe is sent")
+	fs.DurationVar(&o.CacheTTL, "storage-cache-ttl", o.CacheTTL,
+		"how long endpoint and cluster lists are served from memory, 0 disables caching")
 }
 
 func (o *StorageOptions) Validate() error {
+	if o.Schema == "" {
+		return errors.New("storage schema must not be empty")
+	}
+
+	if o.RetryMaxAttempts < 1 {
+		return errors.New("storage retry max attempts must be at least 1")
+	}
+
+	if o.RetryJitter < 0 || o.RetryJitter > 1 {
+		return errors.New("storage retry jitter must be between 0 and 1")
+	}
+
+	if o.RetryBudget < 0 {
+		return errors.New("storage retry budget must not be negative")
+	}
+
+	if o.CircuitFailureThreshold < 0 {
+		return errors.New("storage circuit failure threshold must not be negative")
+	}
+
+	if o.CircuitFailureThreshold > 0 && o.CircuitOpenDuration <= 0 {
+		return errors.New("storage circuit open duration must be positive")
+	}
+
+	if o.CacheTTL < 0 {
+		return errors.New("storage cache ttl must not be negative")
+	}
+
 	return nil
 }
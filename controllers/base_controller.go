@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"context"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
@@ -18,6 +19,14 @@ type Reconciler interface {
 	Reconcile(obj interface{}) error
 }
 
+// IntervalReconciler is implemented by reconcilers whose objects may override the controller
+// sync interval. Objects with an override are requeued at their own cadence and skipped by
+// the periodic full resync.
+type IntervalReconciler interface {
+	// ReconcileInterval returns the reconcile interval of obj, or 0 to use the sync interval.
+	ReconcileInterval(obj interface{}) time.Duration
+}
+
 type BaseController struct {
 	queue                workqueue.RateLimitingInterface //nolint:staticcheck
 	workers              int
@@ -26,6 +35,10 @@ type BaseController struct {
 	afterReconcileHooks  []HookFunc
 
 	objReader ObjectReader
+
+	// scheduled tracks objects requeued at their own reconcile interval.
+	scheduledMu sync.Mutex
+	scheduled   map[string]bool
 }
 
 func (bc *BaseController) Start(ctx context.Context, r Reconciler) {
@@ -66,17 +79,23 @@ func (bc *BaseController) processNextWorkItem(r Reconciler) bool {
 	if err != nil {
 		if err == storage.ErrResourceNotFound {
 			klog.Infof("object %s not found, may have been deleted", id)
+			bc.setScheduled(id, false)
 			bc.queue.Forget(key)
 
 			return true
 		}
 
+		// The object is no longer requeued at its own interval, leave it to the periodic
+		// resync so it is retried instead of dropped.
 		klog.Errorf("failed to get object %s: %v", id, err)
+		bc.setScheduled(id, false)
 		bc.queue.Forget(key)
 
 		return true
 	}
 
+	defer bc.scheduleNextReconcile(r, id, obj)
+
 	for _, hook := range bc.beforeReconcileHooks {
 		if err := hook(obj); err != nil {
 			klog.Error(err)
@@ -107,12 +126,57 @@ func (bc *BaseController) reconcileAll() error {
 	}
 
 	for _, item := range listObj.GetItems() {
+		if bc.isScheduled(item.GetID()) {
+			continue
+		}
+
 		bc.queue.Add(item.GetID())
 	}
 
 	return nil
 }
 
+// scheduleNextReconcile requeues the object after its own reconcile interval when the
+// reconciler reports one. Objects without an override are left to the periodic resync.
+func (bc *BaseController) scheduleNextReconcile(r Reconciler, id string, obj interface{}) {
+	ir, ok := r.(IntervalReconciler)
+	if !ok {
+		return
+	}
+
+	interval := ir.ReconcileInterval(obj)
+	if interval <= 0 {
+		bc.setScheduled(id, false)
+		return
+	}
+
+	bc.setScheduled(id, true)
+	bc.queue.AddAfter(id, interval)
+}
+
+func (bc *BaseController) setScheduled(id string, scheduled bool) {
+	bc.scheduledMu.Lock()
+	defer bc.scheduledMu.Unlock()
+
+	if !scheduled {
+		delete(bc.scheduled, id)
+		return
+	}
+
+	if bc.scheduled == nil {
+		bc.scheduled = map[string]bool{}
+	}
+
+	bc.scheduled[id] = true
+}
+
+func (bc *BaseController) isScheduled(id string) bool {
+	bc.scheduledMu.Lock()
+	defer bc.scheduledMu.Unlock()
+
+	return bc.scheduled[id]
+}
+
 // FormatErrorForStatus formats an error for display in resource status.
 func FormatErrorForStatus(err error) string {
 	if err == nil {
@@ -20,6 +20,8 @@ func TestBaseController_processNextWorkItem(t *testing.T) {
 		mockReconciler func(*mocks.MockReconciler)
 		mockReader     func(*mocks.MockObjectReader)
 		expected       bool
+		// expectUnscheduled expects the object to be left to the periodic resync.
+		expectUnscheduled bool
 	}{
 		{
 			name: "successful processing",
@@ -83,7 +85,8 @@ func TestBaseController_processNextWorkItem(t *testing.T) {
 			mockReconciler: func(m *mocks.MockReconciler) {
 				// No expectations needed
 			},
-			expected: true,
+			expected:          true,
+			expectUnscheduled: true,
 		},
 		{
 			name: "Get returns not found error",
@@ -96,7 +99,8 @@ func TestBaseController_processNextWorkItem(t *testing.T) {
 			mockReconciler: func(m *mocks.MockReconciler) {
 				// No expectations needed
 			},
-			expected: true,
+			expected:          true,
+			expectUnscheduled: true,
 		},
 		{
 			name: "beforeReconcileHook returns error",
@@ -144,6 +148,7 @@ func TestBaseController_processNextWorkItem(t *testing.T) {
 				queue:     workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
 				objReader: mockReader,
 			}
+			bc.setScheduled("1", true)
 
 			tt.setupQueue(bc.queue)
 			tt.mockReconciler(mockR)
@@ -155,6 +160,10 @@ func TestBaseController_processNextWorkItem(t *testing.T) {
 			result := bc.processNextWorkItem(mockR)
 			assert.Equal(t, tt.expected, result)
 
+			if tt.expectUnscheduled {
+				assert.False(t, bc.isScheduled("1"), "a failed Get must leave the object to the periodic resync")
+			}
+
 			mockR.AssertExpectations(t)
 			mockReader.AssertExpectations(t)
 
@@ -3,6 +3,7 @@ package controllers
 import (
 	"context"
 	"strconv"
+	"time"
 
 	"github.com/pkg/errors"
 	"k8s.io/klog/v2"
@@ -13,6 +14,7 @@ import (
 	"github.com/neutree-ai/neutree/internal/gateway"
 	"github.com/neutree-ai/neutree/internal/observability/manager"
 	"github.com/neutree-ai/neutree/internal/observability/monitoring"
+	"github.com/neutree-ai/neutree/internal/orchestrator"
 	"github.com/neutree-ai/neutree/pkg/storage"
 )
 
@@ -71,6 +73,17 @@ func (c *ClusterController) Reconcile(obj interface{}) error {
 	return c.syncHandler(cl)
 }
 
+// ReconcileInterval honors the per-cluster reconcile interval override. Clusters without an
+// override are reconciled at the controller sync interval.
+func (c *ClusterController) ReconcileInterval(obj interface{}) time.Duration {
+	cl, ok := obj.(*v1.Cluster)
+	if !ok {
+		return 0
+	}
+
+	return cl.Spec.ReconcileInterval()
+}
+
 func (controller *ClusterController) sync(obj *v1.Cluster) error {
 	// set default cluster version
 	if obj.Spec.Version == "" {
@@ -90,14 +103,59 @@ func (controller *ClusterController) sync(obj *v1.Cluster) error {
 		return controller.reconcileDelete(obj)
 	}
 
+	// Archived clusters are kept as they are until they are restored or deleted.
+	if obj.IsArchived() {
+		klog.V(4).Infof("Cluster %s is archived, skipping its reconcile", obj.Metadata.WorkspaceName())
+		return nil
+	}
+
+	if obj.Spec.InMaintenance(time.Now()) {
+		return controller.pauseReconcile(obj)
+	}
+
 	return controller.reconcileNormal(obj)
 }
 
+// pauseReconcile leaves a cluster under maintenance untouched. Its last status is kept and
+// marked as paused until the reconcile resumes.
+func (controller *ClusterController) pauseReconcile(c *v1.Cluster) error {
+	if c.Status != nil && c.Status.ReconcilePaused {
+		return nil
+	}
+
+	klog.Infof("Cluster %s is under maintenance, pausing its reconcile", c.Metadata.WorkspaceName())
+
+	status := &v1.ClusterStatus{}
+	if c.Status != nil {
+		*status = *c.Status
+	}
+
+	status.ReconcilePaused = true
+
+	err := controller.storage.UpdateCluster(strconv.Itoa(c.ID), &v1.Cluster{Status: status})
+	if err != nil {
+		return errors.Wrapf(err, "failed to update cluster %s status", c.Metadata.WorkspaceName())
+	}
+
+	return nil
+}
+
 func (controller *ClusterController) reconcileNormal(c *v1.Cluster) error {
 	var reconcileErr error
 
+	dashboardURL := clusterDashboardURL(c)
+
 	defer func() {
 		controller.updateClusterStatus(c, reconcileErr)
+
+		// The access address of a cluster can change, e.g. when its head node or load
+		// balancer is re-provisioned. The gateway services of its endpoints point at the
+		// address, they are resynced once the new one is stored.
+		if dashboardURL != "" && clusterDashboardURL(c) != dashboardURL {
+			klog.Infof("Cluster %s dashboard URL changed from %s to %s, resyncing its endpoints",
+				c.Metadata.WorkspaceName(), dashboardURL, clusterDashboardURL(c))
+			controller.resyncEndpointGateways(c)
+		}
 	}()
 
 	r, err := controller.newClusterReconcile(c, controller.acceleratorManager, controller.storage, controller.metricsRemoteWriteURL)
@@ -128,6 +186,41 @@ func (controller *ClusterController) reconcileNormal(c *v1.Cluster) error {
 	return nil
 }
 
+func clusterDashboardURL(c *v1.Cluster) string {
+	if c.Status == nil {
+		return ""
+	}
+
+	return c.Status.DashboardURL
+}
+
+// resyncEndpointGateways syncs the gateway configuration of the endpoints deployed on the
+// cluster, so they are routed to its current serve address. Failures are left to the resync
+// of the endpoints.
+func (controller *ClusterController) resyncEndpointGateways(c *v1.Cluster) {
+	endpoints, err := controller.storage.ListEndpoint(storage.ListOption{
+		Filters: []storage.Filter{
+			{Column: "spec->cluster", Operator: "eq", Value: strconv.Quote(c.Metadata.Name)},
+			{Column: "metadata->workspace", Operator: "eq", Value: strconv.Quote(c.Metadata.Workspace)},
+		},
+	})
+	if err != nil {
+		klog.Errorf("failed to list endpoints of cluster %s: %v", c.Metadata.WorkspaceName(), err)
+		return
+	}
+
+	for i := range endpoints {
+		ep := &endpoints[i]
+		if ep.Metadata.DeletionTimestamp != "" || orchestrator.IsEndpointPaused(ep) {
+			continue
+		}
+
+		if err := controller.gw.SyncEndpoint(ep); err != nil {
+			klog.Errorf("failed to resync gateway of endpoint %s: %v", ep.Metadata.WorkspaceName(), err)
+		}
+	}
+}
+
 func (controller *ClusterController) syncInternalMetricsMonitor(c *v1.Cluster) error {
 	if c.Spec.Type != v1.SSHClusterType {
 		return nil
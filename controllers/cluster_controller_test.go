@@ -18,6 +18,10 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/util/workqueue"
+	testingclock "k8s.io/utils/clock/testing"
+
+	"github.com/neutree-ai/neutree/controllers/mocks"
 )
 
 func newTestClusterController(s *storagemocks.MockStorage,
@@ -228,6 +232,70 @@ func TestClusterController_Sync_PendingOrNoStatus(t *testing.T) {
 	}
 }
 
+func TestClusterController_Sync_DashboardURLChanged(t *testing.T) {
+	zero := 0
+	running := v1.Endpoint{Metadata: &v1.Metadata{Name: "chat", Workspace: "default"}, Spec: &v1.EndpointSpec{Cluster: "test"}}
+	paused := v1.Endpoint{
+		Metadata: &v1.Metadata{Name: "paused", Workspace: "default"},
+		Spec:     &v1.EndpointSpec{Cluster: "test", Replicas: v1.ReplicaSpec{Num: &zero}},
+	}
+
+	tests := []struct {
+		name         string
+		newURL       string
+		expectResync bool
+	}{
+		{
+			name:   "access address unchanged",
+			newURL: "http://10.0.0.1:8000",
+		},
+		{
+			name:         "access address changed",
+			newURL:       "http://10.0.0.2:8000",
+			expectResync: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStorage := &storagemocks.MockStorage{}
+			mockReconcile := &clustermocks.MockClusterReconcile{}
+
+			mockReconcile.On("Reconcile", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				args.Get(1).(*v1.Cluster).Status.DashboardURL = tt.newURL
+			}).Return(nil)
+			mockStorage.On("UpdateCluster", "1", mock.MatchedBy(func(obj *v1.Cluster) bool {
+				return obj.Status.DashboardURL == tt.newURL
+			})).Return(nil)
+			mockStorage.On("ListEndpoint", mock.Anything).Return([]v1.Endpoint{running, paused}, nil)
+
+			c := newTestClusterController(mockStorage, mockReconcile)
+			gw := c.gw.(*gatewaymocks.MockGateway)
+			gw.On("SyncEndpoint", mock.Anything).Return(nil)
+
+			err := c.sync(&v1.Cluster{
+				ID:       1,
+				Metadata: &v1.Metadata{Name: "test", Workspace: "default"},
+				Spec:     &v1.ClusterSpec{Type: v1.KubernetesClusterType, Version: "v1.0.1"},
+				Status:   &v1.ClusterStatus{Initialized: true, DashboardURL: "http://10.0.0.1:8000"},
+			})
+			require.NoError(t, err)
+			mockStorage.AssertCalled(t, "UpdateCluster", "1", mock.Anything)
+
+			if !tt.expectResync {
+				mockStorage.AssertNotCalled(t, "ListEndpoint", mock.Anything)
+				gw.AssertNotCalled(t, "SyncEndpoint", mock.Anything)
+
+				return
+			}
+
+			// The gateway service of the running endpoint is rebuilt from the new address.
+			gw.AssertCalled(t, "SyncEndpoint", &running)
+			gw.AssertNumberOfCalls(t, "SyncEndpoint", 1)
+		})
+	}
+}
+
 type mockObsCollectConfigManager struct {
 	mock.Mock
 }
@@ -360,6 +428,101 @@ func TestClusterController_Reconcile(t *testing.T) {
 	}
 }
 
+func TestClusterController_ReconcileInterval(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    interface{}
+		expected time.Duration
+	}{
+		{
+			name:     "default interval",
+			input:    &v1.Cluster{Spec: &v1.ClusterSpec{}},
+			expected: 0,
+		},
+		{
+			name:     "custom interval",
+			input:    &v1.Cluster{Spec: &v1.ClusterSpec{ReconcileIntervalSeconds: intPtr(60)}},
+			expected: time.Minute,
+		},
+		{
+			name:     "interval below minimum",
+			input:    &v1.Cluster{Spec: &v1.ClusterSpec{ReconcileIntervalSeconds: intPtr(1)}},
+			expected: v1.MinClusterReconcileIntervalSeconds * time.Second,
+		},
+		{
+			name:     "not a cluster",
+			input:    "invalid",
+			expected: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &ClusterController{}
+			assert.Equal(t, tt.expected, c.ReconcileInterval(tt.input))
+		})
+	}
+}
+
+func TestClusterController_ReconcileAtCustomInterval(t *testing.T) {
+	fakeClock := testingclock.NewFakeClock(time.Now())
+	customCluster := &v1.Cluster{
+		ID:       1,
+		Metadata: &v1.Metadata{Name: "custom"},
+		Spec:     &v1.ClusterSpec{ReconcileIntervalSeconds: intPtr(30)},
+	}
+	defaultCluster := &v1.Cluster{
+		ID:       2,
+		Metadata: &v1.Metadata{Name: "default"},
+		Spec:     &v1.ClusterSpec{},
+	}
+
+	mockReader := new(mocks.MockObjectReader)
+	mockReader.On("Get", "1").Return(customCluster, nil)
+	mockReader.On("Get", "2").Return(defaultCluster, nil)
+	mockReader.On("List").Return(&v1.ClusterList{Items: []v1.Cluster{*customCluster, *defaultCluster}}, nil)
+
+	bc := &BaseController{
+		queue: workqueue.NewRateLimitingQueueWithConfig(workqueue.DefaultControllerRateLimiter(),
+			workqueue.RateLimitingQueueConfig{Clock: fakeClock}),
+		objReader: mockReader,
+	}
+	defer bc.queue.ShutDown()
+
+	var reconciled []int
+
+	c := &ClusterController{syncHandler: func(cl *v1.Cluster) error {
+		reconciled = append(reconciled, cl.ID)
+		return nil
+	}}
+
+	// The initial resync enqueues every cluster.
+	require.NoError(t, bc.reconcileAll())
+	require.Equal(t, 2, bc.queue.Len())
+	assert.True(t, bc.processNextWorkItem(c))
+	assert.True(t, bc.processNextWorkItem(c))
+	assert.ElementsMatch(t, []int{1, 2}, reconciled)
+
+	// Later resyncs at the global interval only enqueue the cluster without an override.
+	reconciled = nil
+
+	require.NoError(t, bc.reconcileAll())
+	require.Equal(t, 1, bc.queue.Len())
+	assert.True(t, bc.processNextWorkItem(c))
+	assert.Equal(t, []int{2}, reconciled)
+
+	// The cluster with an override is requeued once its own interval elapses.
+	reconciled = nil
+
+	fakeClock.Step(29 * time.Second)
+	assert.Never(t, func() bool { return bc.queue.Len() > 0 }, 100*time.Millisecond, 10*time.Millisecond)
+
+	fakeClock.Step(time.Second)
+	assert.Eventually(t, func() bool { return bc.queue.Len() == 1 }, time.Second, 10*time.Millisecond)
+	assert.True(t, bc.processNextWorkItem(c))
+	assert.Equal(t, []int{1}, reconciled)
+}
+
 func TestClusterController_UpdateClusterStatus(t *testing.T) {
 	specV2 := &v1.ClusterSpec{
 		ImageRegistry: "test",
@@ -660,3 +823,121 @@ func TestComputeClusterSpecHash(t *testing.T) {
 		assert.Equal(t, cluster.ComputeClusterSpecHash(spec1), cluster.ComputeClusterSpecHash(spec2))
 	})
 }
+
+func TestClusterController_Sync_Maintenance(t *testing.T) {
+	now := time.Now()
+	window := func(start, end time.Time) []v1.ClusterMaintenanceWindow {
+		return []v1.ClusterMaintenanceWindow{{Start: start.Format(time.RFC3339), End: end.Format(time.RFC3339)}}
+	}
+
+	tests := []struct {
+		name            string
+		maintenance     *v1.ClusterMaintenanceSpec
+		reconcilePaused bool
+		expectReconcile bool
+		expectUpdate    bool
+	}{
+		{
+			name:         "paused flag skips reconcile",
+			maintenance:  &v1.ClusterMaintenanceSpec{Paused: true},
+			expectUpdate: true,
+		},
+		{
+			name:            "already paused cluster is left untouched",
+			maintenance:     &v1.ClusterMaintenanceSpec{Paused: true},
+			reconcilePaused: true,
+		},
+		{
+			name:         "ongoing window skips reconcile",
+			maintenance:  &v1.ClusterMaintenanceSpec{Windows: window(now.Add(-time.Hour), now.Add(time.Hour))},
+			expectUpdate: true,
+		},
+		{
+			name:            "reconcile resumes after the window",
+			maintenance:     &v1.ClusterMaintenanceSpec{Windows: window(now.Add(-2*time.Hour), now.Add(-time.Hour))},
+			reconcilePaused: true,
+			expectReconcile: true,
+			expectUpdate:    true,
+		},
+		{
+			name:            "reconcile resumes once unpaused",
+			maintenance:     &v1.ClusterMaintenanceSpec{},
+			reconcilePaused: true,
+			expectReconcile: true,
+			expectUpdate:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStorage := &storagemocks.MockStorage{}
+			mockReconcile := &clustermocks.MockClusterReconcile{}
+
+			mockReconcile.On("Reconcile", mock.Anything, mock.Anything).Return(nil)
+			mockStorage.On("UpdateCluster", "1", mock.MatchedBy(func(obj *v1.Cluster) bool {
+				if tt.expectReconcile {
+					return !obj.Status.ReconcilePaused && obj.Status.Phase == v1.ClusterPhaseRunning
+				}
+
+				// The status of the paused cluster is kept as is.
+				return obj.Status.ReconcilePaused && obj.Status.Phase == v1.ClusterPhaseRunning &&
+					obj.Status.ReadyNodes == 2
+			})).Return(nil)
+
+			c := newTestClusterController(mockStorage, mockReconcile)
+
+			err := c.sync(&v1.Cluster{
+				ID:       1,
+				Metadata: &v1.Metadata{Name: "test", Workspace: "default"},
+				Spec: &v1.ClusterSpec{
+					Type:        v1.KubernetesClusterType,
+					Version:     "v1.0.1",
+					Maintenance: tt.maintenance,
+				},
+				Status: &v1.ClusterStatus{
+					Phase:           v1.ClusterPhaseRunning,
+					Initialized:     true,
+					Version:         "v1.0.1",
+					ReadyNodes:      2,
+					ReconcilePaused: tt.reconcilePaused,
+				},
+			})
+			require.NoError(t, err)
+
+			if tt.expectReconcile {
+				mockReconcile.AssertCalled(t, "Reconcile", mock.Anything, mock.Anything)
+			} else {
+				mockReconcile.AssertNotCalled(t, "Reconcile", mock.Anything, mock.Anything)
+			}
+
+			if tt.expectUpdate {
+				mockStorage.AssertCalled(t, "UpdateCluster", "1", mock.Anything)
+			} else {
+				mockStorage.AssertNotCalled(t, "UpdateCluster", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestClusterController_Sync_Archived(t *testing.T) {
+	mockStorage := &storagemocks.MockStorage{}
+	mockReconcile := &clustermocks.MockClusterReconcile{}
+
+	c := newTestClusterController(mockStorage, mockReconcile)
+
+	err := c.sync(&v1.Cluster{
+		ID:       1,
+		Metadata: &v1.Metadata{Name: "test", Workspace: "default"},
+		Spec:     &v1.ClusterSpec{Type: v1.KubernetesClusterType, Version: "v1.0.1"},
+		Status: &v1.ClusterStatus{
+			Phase:            v1.ClusterPhaseArchived,
+			Initialized:      true,
+			Version:          "v1.0.1",
+			ArchiveTimestamp: "2026-10-01T00:00:00Z",
+		},
+	})
+	require.NoError(t, err)
+
+	mockReconcile.AssertNotCalled(t, "Reconcile", mock.Anything, mock.Anything)
+	mockStorage.AssertNotCalled(t, "UpdateCluster", mock.Anything, mock.Anything)
+}
@@ -30,7 +30,8 @@ func (r *objectReader) List() (scheme.ObjectList, error) {
 		return nil, err
 	}
 
-	err = r.storage.List(listObj, storage.ListOption{})
+	// soft-deleted objects are listed too, their reconcile finishes the deletion.
+	err = r.storage.List(listObj, storage.ListOption{IncludeDeleted: true})
 	if err != nil {
 		return nil, err
 	}
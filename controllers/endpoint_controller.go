@@ -1,8 +1,12 @@
 package controllers
 
 import (
+	"fmt"
 	"reflect"
 	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 	"k8s.io/klog/v2"
@@ -11,6 +15,7 @@ import (
 	"github.com/neutree-ai/neutree/internal/accelerator"
 	"github.com/neutree-ai/neutree/internal/gateway"
 	"github.com/neutree-ai/neutree/internal/orchestrator"
+	"github.com/neutree-ai/neutree/internal/registry"
 	"github.com/neutree-ai/neutree/pkg/storage"
 )
 
@@ -20,6 +25,23 @@ type EndpointController struct {
 
 	gw             gateway.Gateway
 	acceleratorMgr accelerator.Manager
+	imageService   registry.ImageService
+
+	// drainTimeout bounds how long deleting an endpoint waits for its requests in flight.
+	drainTimeout time.Duration
+
+	// modelTasks caches the model tasks told by the model registries, and modelTaskChecks the
+	// model task check of each endpoint by its spec hash, so an endpoint is only checked again
+	// once its spec changes.
+	modelTasks      *orchestrator.ModelTaskCache
+	modelTaskChecks sync.Map
+}
+
+// modelTaskCheck is the model task check of an endpoint at a spec hash.
+type modelTaskCheck struct {
+	specHash   string
+	validation orchestrator.ModelTaskValidation
+	reason     string
 }
 
 type EndpointControllerOption struct {
@@ -27,6 +49,11 @@ type EndpointControllerOption struct {
 
 	Gw             gateway.Gateway
 	AcceleratorMgr accelerator.Manager
+	ImageService   registry.ImageService
+
+	// DrainTimeout bounds how long deleting an endpoint waits for its requests in flight,
+	// force deletes do not wait.
+	DrainTimeout time.Duration
 }
 
 func NewEndpointController(option *EndpointControllerOption) (*EndpointController, error) {
@@ -34,6 +61,9 @@ func NewEndpointController(option *EndpointControllerOption) (*EndpointControlle
 		storage:        option.Storage,
 		gw:             option.Gw,
 		acceleratorMgr: option.AcceleratorMgr,
+		imageService:   option.ImageService,
+		drainTimeout:   option.DrainTimeout,
+		modelTasks:     &orchestrator.ModelTaskCache{},
 	}
 
 	c.syncHandler = c.sync
@@ -55,6 +85,8 @@ func (c *EndpointController) Reconcile(obj interface{}) error {
 func (c *EndpointController) sync(obj *v1.Endpoint) error {
 	var err error
 	var o orchestrator.Orchestrator
+	var waitReason string
+	var cleanedUp bool
 
 	// Handle deletion early - bypass defer block for already-deleted resources
 	if obj.Metadata != nil && obj.Metadata.DeletionTimestamp != "" {
@@ -63,6 +95,16 @@ func (c *EndpointController) sync(obj *v1.Endpoint) error {
 
 	// Defer block to handle status updates for non-deletion paths
 	defer func() {
+		if waitReason != "" {
+			c.updateWaitingStatus(obj, waitReason)
+			return
+		}
+
+		// The failed status recorded when the deployment was cleaned up is kept as is.
+		if cleanedUp {
+			return
+		}
+
 		c.updateStatusOnError(obj, err)
 	}()
 
@@ -82,6 +124,41 @@ func (c *EndpointController) sync(obj *v1.Endpoint) error {
 		return nil
 	}
 
+	if _, err = orchestrator.GetFailureCleanupPolicy(obj); err != nil {
+		return errors.Wrapf(err, "invalid deployment options of endpoint %s", obj.Metadata.WorkspaceName())
+	}
+
+	if cleanedUp = orchestrator.IsFailedEndpointCleanedUp(obj); cleanedUp {
+		klog.V(4).Infof("Endpoint %s failed and its deployment was cleaned up, waiting for a spec change",
+			obj.Metadata.WorkspaceName())
+		return nil
+	}
+
+	waitReason, err = c.dependencyWaitReason(obj)
+	if err != nil {
+		return errors.Wrapf(err, "failed to check dependencies of endpoint %s",
+			obj.Metadata.WorkspaceName())
+	}
+
+	if waitReason != "" {
+		klog.V(4).Infof("Endpoint %s deferred: %s", obj.Metadata.WorkspaceName(), waitReason)
+		return nil
+	}
+
+	waitReason, err = c.capacityWaitReason(obj)
+	if err != nil {
+		return err
+	}
+
+	if waitReason != "" {
+		klog.V(4).Infof("Endpoint %s queued: %s", obj.Metadata.WorkspaceName(), waitReason)
+		return nil
+	}
+
+	if err = c.checkModelTask(obj); err != nil {
+		return err
+	}
+
 	err = o.CreateEndpoint(obj)
 	if err != nil {
 		return errors.Wrapf(err, "failed to create or update endpoint %s",
@@ -97,6 +174,153 @@ func (c *EndpointController) sync(obj *v1.Endpoint) error {
 	return nil
 }
 
+// dependencyWaitReason returns why the endpoint has to wait before it is deployed, empty once
+// its deploy cluster is initialized and its engine is created. Waiting endpoints are kept
+// pending instead of failed, and deployed by the next resync once the dependencies are ready.
+func (c *EndpointController) dependencyWaitReason(obj *v1.Endpoint) (string, error) {
+	cluster, err := c.getCluster(obj)
+	if err != nil {
+		return "", err
+	}
+
+	if cluster.Status == nil || cluster.Status.Phase == "" ||
+		cluster.Status.Phase == v1.ClusterPhasePending || cluster.Status.Phase == v1.ClusterPhaseInitializing {
+		return fmt.Sprintf("waiting for deploy cluster %s to be initialized", cluster.Metadata.WorkspaceName()), nil
+	}
+
+	if obj.Spec.Engine == nil {
+		return "", nil
+	}
+
+	engines, err := c.storage.ListEngine(storage.ListOption{
+		Filters: []storage.Filter{
+			{
+				Column:   "metadata->name",
+				Operator: "eq",
+				Value:    strconv.Quote(obj.Spec.Engine.Engine),
+			},
+			{
+				Column:   "metadata->workspace",
+				Operator: "eq",
+				Value:    strconv.Quote(obj.Metadata.Workspace),
+			},
+		},
+	})
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get engine %s", obj.Spec.Engine.Engine)
+	}
+
+	if len(engines) == 0 || engines[0].Status == nil ||
+		engines[0].Status.Phase == "" || engines[0].Status.Phase == v1.EnginePhasePending {
+		return fmt.Sprintf("waiting for engine %s to be created", obj.Spec.Engine.Engine), nil
+	}
+
+	return "", nil
+}
+
+// capacityWaitReason applies the capacity policy of an endpoint not deployed yet. It returns
+// why a queued endpoint waits for cluster capacity, and the error failing a rejected one.
+func (c *EndpointController) capacityWaitReason(obj *v1.Endpoint) (string, error) {
+	if !awaitsDeployment(obj) {
+		return "", nil
+	}
+
+	cluster, err := c.getCluster(obj)
+	if err != nil {
+		return "", err
+	}
+
+	policy, reason, err := orchestrator.CheckEndpointCapacity(obj, cluster)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to check cluster capacity for endpoint %s",
+			obj.Metadata.WorkspaceName())
+	}
+
+	if reason != "" && policy == orchestrator.CapacityPolicyReject {
+		return "", errors.New(reason)
+	}
+
+	return reason, nil
+}
+
+// checkModelTask applies the model task validation of an endpoint not deployed yet. It fails
+// the endpoint if the model tasks it declares mismatch its model registry and the validation
+// is reject, it only logs the mismatch if the validation is warn. The check is only repeated
+// once the spec of the endpoint changes.
+func (c *EndpointController) checkModelTask(obj *v1.Endpoint) error {
+	if !awaitsDeployment(obj) {
+		return nil
+	}
+
+	specHash, err := orchestrator.ComputeEndpointSpecHash(obj)
+	if err != nil {
+		return err
+	}
+
+	check, ok := c.modelTaskChecks.Load(obj.ID)
+	if !ok || check.(modelTaskCheck).specHash != specHash {
+		validation, reason, err := orchestrator.CheckEndpointModelTask(c.storage, c.modelTasks, obj)
+		if err != nil {
+			return errors.Wrapf(err, "failed to validate model task of endpoint %s",
+				obj.Metadata.WorkspaceName())
+		}
+
+		check = modelTaskCheck{specHash: specHash, validation: validation, reason: reason}
+		c.modelTaskChecks.Store(obj.ID, check)
+	}
+
+	validation, reason := check.(modelTaskCheck).validation, check.(modelTaskCheck).reason
+
+	if reason == "" {
+		return nil
+	}
+
+	if validation == orchestrator.ModelTaskValidationReject {
+		return errors.New(reason)
+	}
+
+	klog.Warningf("Endpoint %s deployed despite %s", obj.Metadata.WorkspaceName(), reason)
+
+	return nil
+}
+
+// awaitsDeployment reports whether the endpoint is not deployed yet: it has no status, waits
+// for its dependencies or cluster capacity, or was rejected for lack of capacity or a model
+// task mismatch. Deployed endpoints hold their resources already, so their capacity is not
+// checked again.
+func awaitsDeployment(obj *v1.Endpoint) bool {
+	if obj.Status == nil {
+		return true
+	}
+
+	switch obj.Status.Phase {
+	case "", v1.EndpointPhasePENDING:
+		return true
+	case v1.EndpointPhaseFAILED:
+		return strings.Contains(obj.Status.ErrorMessage, orchestrator.InsufficientCapacityMessage) ||
+			strings.Contains(obj.Status.ErrorMessage, orchestrator.ModelTaskMismatchMessage)
+	default:
+		return false
+	}
+}
+
+// updateWaitingStatus marks the endpoint pending with what it waits for.
+func (c *EndpointController) updateWaitingStatus(obj *v1.Endpoint, reason string) {
+	status := &v1.EndpointStatus{
+		Phase:        v1.EndpointPhasePENDING,
+		ErrorMessage: reason,
+	}
+
+	if !c.shouldUpdateStatus(obj, status) {
+		return
+	}
+
+	if err := c.updateStatus(obj, status); err != nil {
+		klog.Errorf("failed to update endpoint %s status: %v",
+			obj.Metadata.WorkspaceName(), err)
+	}
+}
+
 func (c *EndpointController) handleDeletion(obj *v1.Endpoint) error {
 	var err error
 	isForceDelete := v1.IsForceDelete(obj.Metadata.Annotations)
@@ -110,6 +334,8 @@ func (c *EndpointController) handleDeletion(obj *v1.Endpoint) error {
 				obj.Metadata.WorkspaceName())
 		}
 
+		c.modelTaskChecks.Delete(obj.ID)
+
 		return nil
 	}
 
@@ -186,16 +412,75 @@ func (c *EndpointController) updateStatusOnError(obj *v1.Endpoint, err error) {
 		return
 	}
 
+	if status.HasNoHealthyReplicas() {
+		status.NoHealthyReplicasSince = noHealthyReplicasSince(obj)
+		status = c.cleanupFailedDeployment(obj, status)
+	}
+
 	// Update if status changed
 	if c.shouldUpdateStatus(obj, status) {
 		updateErr := c.updateStatus(obj, status)
 		if updateErr != nil {
 			klog.Errorf("failed to update endpoint %s status: %v",
 				obj.Metadata.WorkspaceName(), updateErr)
+
+			return
+		}
+
+		if obj.Status.HasNoHealthyReplicas() != status.HasNoHealthyReplicas() {
+			c.syncGatewayAvailability(obj, status)
 		}
 	}
 }
 
+// noHealthyReplicasSince returns since when the endpoint reports no healthy replica, which is
+// now unless it already did on the previous reconcile.
+func noHealthyReplicasSince(obj *v1.Endpoint) string {
+	if obj.Status.HasNoHealthyReplicas() && obj.Status.NoHealthyReplicasSince != "" {
+		return obj.Status.NoHealthyReplicasSince
+	}
+
+	return FormatStatusTime()
+}
+
+// cleanupFailedDeployment cleans up the workload of an endpoint without a healthy replica when
+// its deployment options ask for it, and returns the status to record. Failing cleanups are
+// retried on the next resync.
+func (c *EndpointController) cleanupFailedDeployment(obj *v1.Endpoint, status *v1.EndpointStatus) *v1.EndpointStatus {
+	o, err := c.getOrchestrator(obj)
+	if err != nil {
+		klog.Errorf("failed to get orchestrator for endpoint %s: %v", obj.Metadata.WorkspaceName(), err)
+		return status
+	}
+
+	cleaned, err := orchestrator.CleanupFailedEndpoint(o, obj, status, time.Now())
+	if err != nil {
+		klog.Errorf("failed to clean up failed deployment of endpoint %s: %v", obj.Metadata.WorkspaceName(), err)
+		return status
+	}
+
+	if cleaned == nil {
+		return status
+	}
+
+	klog.Infof("Cleaned up failed deployment of endpoint %s", obj.Metadata.WorkspaceName())
+
+	return cleaned
+}
+
+// syncGatewayAvailability syncs the gateway configuration of an endpoint whose replicas all
+// turned unhealthy or recovered, so it is taken out of routing or restored right away instead
+// of on the next resync.
+func (c *EndpointController) syncGatewayAvailability(obj *v1.Endpoint, status *v1.EndpointStatus) {
+	updated := *obj
+	updated.Status = status
+
+	if err := c.gw.SyncEndpoint(&updated); err != nil {
+		klog.Errorf("failed to sync gateway availability of endpoint %s: %v",
+			obj.Metadata.WorkspaceName(), err)
+	}
+}
+
 // getActualStatus retrieves the current status from orchestrator and gateway
 func (c *EndpointController) getActualStatus(obj *v1.Endpoint) (*v1.EndpointStatus, error) {
 	o, err := c.getOrchestrator(obj)
@@ -208,8 +493,8 @@ func (c *EndpointController) getActualStatus(obj *v1.Endpoint) (*v1.EndpointStat
 		return nil, errors.Wrapf(err, "failed to get endpoint status from orchestrator for endpoint %s", obj.Metadata.WorkspaceName())
 	}
 
-	// Get service URL for running endpoints
-	if status.Phase == v1.EndpointPhaseRUNNING {
+	// Get service URL for endpoints that serve requests
+	if status.Phase == v1.EndpointPhaseRUNNING || status.Phase == v1.EndpointPhaseDEGRADED {
 		serviceURL, err := c.gw.GetEndpointServeUrl(obj)
 		if err != nil {
 			klog.Warningf("failed to get endpoint %s service url: %v", obj.Metadata.WorkspaceName(), err)
@@ -259,6 +544,32 @@ func (c *EndpointController) shouldUpdateStatus(obj *v1.Endpoint, newStatus *v1.
 		return true
 	}
 
+	// Update if model download progress changed, including being cleared on completion.
+	if !reflect.DeepEqual(obj.Status.ModelDownloadProgress, normalizedStatus.ModelDownloadProgress) {
+		return true
+	}
+
+	// Update if the pinned engine image digest changed, including being cleared once unpinned.
+	if obj.Status.EngineImageDigest != normalizedStatus.EngineImageDigest {
+		return true
+	}
+
+	if obj.Status.FailureCleanupSpecHash != normalizedStatus.FailureCleanupSpecHash {
+		return true
+	}
+
+	if obj.Status.NoHealthyReplicasSince != normalizedStatus.NoHealthyReplicasSince {
+		return true
+	}
+
+	if !reflect.DeepEqual(obj.Status.Canary, normalizedStatus.Canary) {
+		return true
+	}
+
+	if !reflect.DeepEqual(obj.Status.Replicas, normalizedStatus.Replicas) {
+		return true
+	}
+
 	return false
 }
 
@@ -270,6 +581,8 @@ func sameOptionalString(left, right *string) bool {
 	return *left == *right
 }
 
+// cleanupEndpoint removes the workload of a deleted endpoint, draining its requests in flight
+// unless it is force deleted.
 func (c *EndpointController) cleanupEndpoint(obj *v1.Endpoint) error {
 	o, err := c.getOrchestrator(obj)
 	if err != nil {
@@ -281,7 +594,18 @@ func (c *EndpointController) cleanupEndpoint(obj *v1.Endpoint) error {
 		return errors.Wrapf(err, "failed to get orchestrator for endpoint %s", obj.Metadata.WorkspaceName())
 	}
 
-	err = o.DeleteEndpoint(obj)
+	if v1.IsForceDelete(obj.Metadata.Annotations) {
+		err = o.DeleteEndpoint(obj)
+	} else {
+		err = o.DrainEndpoint(obj, c.drainTimeout)
+	}
+
+	// The drain is checked again on the next reconcile, the endpoint stays deleting.
+	if errors.Is(err, orchestrator.ErrEndpointDraining) {
+		klog.V(4).Infof("Endpoint %s is draining its requests in flight", obj.Metadata.WorkspaceName())
+		return nil
+	}
+
 	if err != nil {
 		return errors.Wrapf(err, "failed to delete endpoint %s", obj.Metadata.WorkspaceName())
 	}
@@ -304,6 +628,7 @@ func (c *EndpointController) prepareStatusForUpdate(obj *v1.Endpoint, status *v1
 
 	c.preserveResources(obj, status)
 	c.preserveModelDownloadStatus(obj, status)
+	c.preserveEngineImageDigest(obj, status)
 }
 
 func (c *EndpointController) preserveResources(obj *v1.Endpoint, status *v1.EndpointStatus) {
@@ -330,6 +655,19 @@ func (c *EndpointController) preserveModelDownloadStatus(obj *v1.Endpoint, statu
 	status.ModelDownloadCompletedHash = obj.Status.ModelDownloadCompletedHash
 }
 
+// preserveEngineImageDigest keeps the digest the engine image is pinned to while pinning is enabled.
+func (c *EndpointController) preserveEngineImageDigest(obj *v1.Endpoint, status *v1.EndpointStatus) {
+	if obj.Status == nil || obj.Status.EngineImageDigest == "" || status.EngineImageDigest != "" {
+		return
+	}
+
+	if !obj.Spec.PinsEngineImageDigest() {
+		return
+	}
+
+	status.EngineImageDigest = obj.Status.EngineImageDigest
+}
+
 func (c *EndpointController) formatStatus(phase v1.EndpointPhase, err error) *v1.EndpointStatus {
 	newStatus := &v1.EndpointStatus{
 		LastTransitionTime: FormatStatusTime(),
@@ -340,36 +678,33 @@ func (c *EndpointController) formatStatus(phase v1.EndpointPhase, err error) *v1
 	return newStatus
 }
 
-func (c *EndpointController) getOrchestrator(obj *v1.Endpoint) (orchestrator.Orchestrator, error) {
-	cluster, err := c.storage.ListCluster(storage.ListOption{
-		Filters: []storage.Filter{
-			{
-				Column:   "metadata->name",
-				Operator: "eq",
-				Value:    strconv.Quote(obj.Spec.Cluster),
-			},
-			{
-				Column:   "metadata->workspace",
-				Operator: "eq",
-				Value:    strconv.Quote(obj.Metadata.Workspace),
-			},
-		},
-	})
+func (c *EndpointController) getCluster(obj *v1.Endpoint) (*v1.Cluster, error) {
+	cluster, err := c.storage.GetClusterByName(obj.Metadata.Workspace, obj.Spec.Cluster)
+	if errors.Is(err, storage.ErrResourceNotFound) {
+		return nil, err
+	}
+
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to get cluster %s", obj.Spec.Cluster)
 	}
 
-	if len(cluster) == 0 {
-		return nil, storage.ErrResourceNotFound
+	return cluster, nil
+}
+
+func (c *EndpointController) getOrchestrator(obj *v1.Endpoint) (orchestrator.Orchestrator, error) {
+	cluster, err := c.getCluster(obj)
+	if err != nil {
+		return nil, err
 	}
 
 	orchestrator, err := orchestrator.NewOrchestrator(orchestrator.Options{
-		Cluster:        &cluster[0],
+		Cluster:        cluster,
 		Storage:        c.storage,
 		AcceleratorMgr: c.acceleratorMgr,
+		ImageService:   c.imageService,
 	})
 	if err != nil {
-		return nil, errors.Wrapf(err, "failed to create orchestrator for cluster %s", cluster[0].Metadata.WorkspaceName())
+		return nil, errors.Wrapf(err, "failed to create orchestrator for cluster %s", cluster.Metadata.WorkspaceName())
 	}
 
 	return orchestrator, nil
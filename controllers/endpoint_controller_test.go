@@ -7,6 +7,8 @@ import (
 
 	v1 "github.com/neutree-ai/neutree/api/v1"
 	gatewaymocks "github.com/neutree-ai/neutree/internal/gateway/mocks"
+	"github.com/neutree-ai/neutree/internal/model_registry"
+	modelregistrymocks "github.com/neutree-ai/neutree/internal/model_registry/mocks"
 	"github.com/neutree-ai/neutree/internal/orchestrator"
 	orchestratormocks "github.com/neutree-ai/neutree/internal/orchestrator/mocks"
 	storagemocks "github.com/neutree-ai/neutree/pkg/storage/mocks"
@@ -101,11 +103,72 @@ func TestEndpointController_Sync_Deletion(t *testing.T) {
 	}
 }
 
+func TestEndpointController_Sync_DeletionDrainsEndpoint(t *testing.T) {
+	tests := []struct {
+		name      string
+		force     bool
+		wantCalls []string
+	}{
+		{
+			name:      "drains requests in flight before teardown",
+			wantCalls: []string{"gateway.DeleteEndpoint", "orchestrator.DrainEndpoint"},
+		},
+		{
+			name:      "force delete does not drain",
+			force:     true,
+			wantCalls: []string{"gateway.DeleteEndpoint", "orchestrator.DeleteEndpoint"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []string
+
+			ms := &storagemocks.MockStorage{}
+			ms.On("GetClusterByName", mock.Anything, mock.Anything).Return(&v1.Cluster{Metadata: &v1.Metadata{Name: "test-cluster"}}, nil)
+			ms.On("UpdateEndpoint", "1", mock.Anything).Return(nil).Maybe()
+
+			mo := &orchestratormocks.MockOrchestrator{}
+			mo.On("DrainEndpoint", mock.Anything, time.Minute).Return(nil).
+				Run(func(mock.Arguments) { calls = append(calls, "orchestrator.DrainEndpoint") }).Maybe()
+			mo.On("DeleteEndpoint", mock.Anything).Return(nil).
+				Run(func(mock.Arguments) { calls = append(calls, "orchestrator.DeleteEndpoint") }).Maybe()
+			mo.On("GetEndpointStatus", mock.Anything).Return(&v1.EndpointStatus{Phase: v1.EndpointPhaseDELETED}, nil).Maybe()
+
+			orchestrator.NewOrchestrator = func(opts orchestrator.Options) (orchestrator.Orchestrator, error) {
+				return mo, nil
+			}
+
+			gw := &gatewaymocks.MockGateway{}
+			gw.On("DeleteEndpoint", mock.Anything).Return(nil).
+				Run(func(mock.Arguments) { calls = append(calls, "gateway.DeleteEndpoint") })
+			gw.On("SyncEndpoint", mock.Anything).Return(nil).Maybe()
+
+			c, _ := NewEndpointController(&EndpointControllerOption{Storage: ms, Gw: gw, DrainTimeout: time.Minute})
+
+			obj := epDel(1, v1.EndpointPhaseDELETING)
+			if tt.force {
+				obj.Metadata.Annotations = map[string]string{v1.ForceDeleteAnnotationKey: v1.ForceDeleteAnnotationValue}
+			}
+
+			assert.NoError(t, c.sync(obj))
+			assert.Equal(t, tt.wantCalls, calls)
+		})
+	}
+}
+
 /* ---------- Create / Update ---------- */
 
 func TestEndpointController_Sync_CreateUpdate(t *testing.T) {
 	id := 1
-	cluster := v1.Cluster{Metadata: &v1.Metadata{Name: "test-cluster", Workspace: "default"}}
+	cluster := v1.Cluster{
+		Metadata: &v1.Metadata{Name: "test-cluster", Workspace: "default"},
+		Status:   &v1.ClusterStatus{Phase: v1.ClusterPhaseRunning},
+	}
+	engine := v1.Engine{
+		Metadata: &v1.Metadata{Name: "test-engine", Workspace: "default"},
+		Status:   &v1.EngineStatus{Phase: v1.EnginePhaseCreated},
+	}
 
 	okStatus := &v1.EndpointStatus{Phase: v1.EndpointPhaseRUNNING}
 
@@ -119,7 +182,8 @@ func TestEndpointController_Sync_CreateUpdate(t *testing.T) {
 			name: "create ok",
 			in:   ep(id, ""),
 			setup: func(s *storagemocks.MockStorage, o *orchestratormocks.MockOrchestrator) {
-				s.On("ListCluster", mock.Anything).Return([]v1.Cluster{cluster}, nil).Maybe()
+				s.On("GetClusterByName", mock.Anything, mock.Anything).Return(&cluster, nil).Maybe()
+				s.On("ListEngine", mock.Anything).Return([]v1.Engine{engine}, nil)
 				o.On("CreateEndpoint", mock.Anything).Return(nil)
 				o.On("GetEndpointStatus", mock.Anything).Return(okStatus, nil)
 				s.On("UpdateEndpoint", strconv.Itoa(id), mock.Anything).Return(nil)
@@ -130,7 +194,8 @@ func TestEndpointController_Sync_CreateUpdate(t *testing.T) {
 			name: "update status fail - logged but not returned",
 			in:   ep(id, ""),
 			setup: func(s *storagemocks.MockStorage, o *orchestratormocks.MockOrchestrator) {
-				s.On("ListCluster", mock.Anything).Return([]v1.Cluster{cluster}, nil).Maybe()
+				s.On("GetClusterByName", mock.Anything, mock.Anything).Return(&cluster, nil).Maybe()
+				s.On("ListEngine", mock.Anything).Return([]v1.Engine{engine}, nil)
 				o.On("CreateEndpoint", mock.Anything).Return(nil)
 				o.On("GetEndpointStatus", mock.Anything).Return(okStatus, nil)
 
@@ -143,7 +208,8 @@ func TestEndpointController_Sync_CreateUpdate(t *testing.T) {
 			name: "always create even if already running",
 			in:   ep(id, v1.EndpointPhaseRUNNING),
 			setup: func(s *storagemocks.MockStorage, o *orchestratormocks.MockOrchestrator) {
-				s.On("ListCluster", mock.Anything).Return([]v1.Cluster{cluster}, nil).Maybe()
+				s.On("GetClusterByName", mock.Anything, mock.Anything).Return(&cluster, nil).Maybe()
+				s.On("ListEngine", mock.Anything).Return([]v1.Engine{engine}, nil)
 				o.On("CreateEndpoint", mock.Anything).Return(nil)
 				o.On("GetEndpointStatus", mock.Anything).Return(okStatus, nil)
 			},
@@ -168,6 +234,405 @@ func TestEndpointController_Sync_CreateUpdate(t *testing.T) {
 	}
 }
 
+func TestEndpointController_Sync_WaitsForDependencies(t *testing.T) {
+	id := 1
+	createdEngine := v1.Engine{
+		Metadata: &v1.Metadata{Name: "test-engine", Workspace: "default"},
+		Status:   &v1.EngineStatus{Phase: v1.EnginePhaseCreated},
+	}
+
+	newCluster := func(status *v1.ClusterStatus) v1.Cluster {
+		return v1.Cluster{Metadata: &v1.Metadata{Name: "test-cluster", Workspace: "default"}, Status: status}
+	}
+
+	tests := []struct {
+		name       string
+		in         *v1.Endpoint
+		cluster    v1.Cluster
+		engines    []v1.Engine
+		wantReason string
+	}{
+		{
+			name:       "cluster without status",
+			in:         ep(id, ""),
+			cluster:    newCluster(nil),
+			wantReason: "waiting for deploy cluster default/test-cluster to be initialized",
+		},
+		{
+			name:       "initializing cluster",
+			in:         ep(id, v1.EndpointPhasePENDING),
+			cluster:    newCluster(&v1.ClusterStatus{Phase: v1.ClusterPhaseInitializing}),
+			wantReason: "waiting for deploy cluster default/test-cluster to be initialized",
+		},
+		{
+			name:       "engine not created yet",
+			in:         ep(id, ""),
+			cluster:    newCluster(&v1.ClusterStatus{Phase: v1.ClusterPhaseRunning}),
+			engines:    []v1.Engine{{Metadata: createdEngine.Metadata, Status: &v1.EngineStatus{Phase: v1.EnginePhasePending}}},
+			wantReason: "waiting for engine test-engine to be created",
+		},
+		{
+			name:       "engine not found",
+			in:         ep(id, ""),
+			cluster:    newCluster(&v1.ClusterStatus{Phase: v1.ClusterPhaseRunning}),
+			wantReason: "waiting for engine test-engine to be created",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms := &storagemocks.MockStorage{}
+			mo := &orchestratormocks.MockOrchestrator{}
+			ms.On("GetClusterByName", mock.Anything, mock.Anything).Return(&tt.cluster, nil)
+			ms.On("ListEngine", mock.Anything).Return(tt.engines, nil).Maybe()
+
+			var updated *v1.Endpoint
+			ms.On("UpdateEndpoint", strconv.Itoa(id), mock.Anything).Run(func(args mock.Arguments) {
+				updated = args.Get(1).(*v1.Endpoint)
+			}).Return(nil)
+
+			c := newTestEndpointController(ms, mo)
+			assert.NoError(t, c.sync(tt.in))
+
+			mo.AssertNotCalled(t, "CreateEndpoint", mock.Anything)
+			if assert.NotNil(t, updated) {
+				assert.Equal(t, v1.EndpointPhasePENDING, updated.Status.Phase)
+				assert.Equal(t, tt.wantReason, updated.Status.ErrorMessage)
+			}
+		})
+	}
+}
+
+func TestEndpointController_Sync_DeploysOnceClusterIsReady(t *testing.T) {
+	id := 1
+	endpoint := ep(id, "")
+	engine := v1.Engine{
+		Metadata: &v1.Metadata{Name: "test-engine", Workspace: "default"},
+		Status:   &v1.EngineStatus{Phase: v1.EnginePhaseCreated},
+	}
+	cluster := v1.Cluster{
+		Metadata: &v1.Metadata{Name: "test-cluster", Workspace: "default"},
+		Status:   &v1.ClusterStatus{Phase: v1.ClusterPhaseInitializing},
+	}
+
+	ms := &storagemocks.MockStorage{}
+	mo := &orchestratormocks.MockOrchestrator{}
+	ms.On("GetClusterByName", mock.Anything, mock.Anything).Return(func(string, string) *v1.Cluster {
+		return &cluster
+	}, nil)
+	ms.On("ListEngine", mock.Anything).Return([]v1.Engine{engine}, nil)
+	ms.On("UpdateEndpoint", strconv.Itoa(id), mock.Anything).Run(func(args mock.Arguments) {
+		endpoint.Status = args.Get(1).(*v1.Endpoint).Status
+	}).Return(nil)
+	mo.On("CreateEndpoint", mock.Anything).Return(nil)
+	mo.On("GetEndpointStatus", mock.Anything).Return(&v1.EndpointStatus{Phase: v1.EndpointPhaseDEPLOYING}, nil)
+
+	c := newTestEndpointController(ms, mo)
+
+	assert.NoError(t, c.sync(endpoint))
+	mo.AssertNotCalled(t, "CreateEndpoint", mock.Anything)
+	assert.Equal(t, v1.EndpointPhasePENDING, endpoint.Status.Phase)
+
+	// The next resync deploys the endpoint once the cluster is running.
+	cluster.Status = &v1.ClusterStatus{Phase: v1.ClusterPhaseRunning}
+
+	assert.NoError(t, c.sync(endpoint))
+	mo.AssertCalled(t, "CreateEndpoint", endpoint)
+	assert.Equal(t, v1.EndpointPhaseDEPLOYING, endpoint.Status.Phase)
+	assert.Empty(t, endpoint.Status.ErrorMessage)
+}
+
+func TestEndpointController_Sync_CapacityPolicy(t *testing.T) {
+	id := 1
+	engine := v1.Engine{
+		Metadata: &v1.Metadata{Name: "test-engine", Workspace: "default"},
+		Status:   &v1.EngineStatus{Phase: v1.EnginePhaseCreated},
+	}
+	// a running cluster with a single GPU left.
+	fullCluster := v1.Cluster{
+		Metadata: &v1.Metadata{Name: "test-cluster", Workspace: "default"},
+		Status: &v1.ClusterStatus{
+			Phase: v1.ClusterPhaseRunning,
+			ResourceInfo: &v1.ClusterResources{ResourceStatus: v1.ResourceStatus{Available: &v1.ResourceInfo{
+				CPU:    32,
+				Memory: 128,
+				AcceleratorGroups: map[v1.AcceleratorType]*v1.AcceleratorGroup{
+					v1.AcceleratorTypeNVIDIAGPU: {Quantity: 1},
+				},
+			}}},
+		},
+	}
+	newEndpoint := func(phase v1.EndpointPhase, policy string) *v1.Endpoint {
+		e := ep(id, phase)
+		e.Spec.Resources = &v1.ResourceSpec{
+			GPU:         stringPtr("4"),
+			Accelerator: map[string]string{v1.AcceleratorTypeKey: string(v1.AcceleratorTypeNVIDIAGPU)},
+		}
+		e.Spec.DeploymentOptions = map[string]interface{}{"capacity_policy": policy}
+
+		return e
+	}
+	shortage := "insufficient cluster capacity: requires 4 nvidia_gpu, 1 available"
+	rejected := newEndpoint(v1.EndpointPhaseFAILED, "reject")
+	rejected.Status.ErrorMessage = shortage
+
+	tests := []struct {
+		name        string
+		in          *v1.Endpoint
+		wantErr     string
+		wantPhase   v1.EndpointPhase
+		wantMessage string
+		wantCreate  bool
+	}{
+		{
+			name:        "reject fails the endpoint",
+			in:          newEndpoint("", "reject"),
+			wantErr:     shortage,
+			wantPhase:   v1.EndpointPhaseFAILED,
+			wantMessage: shortage,
+		},
+		{
+			name:        "rejected endpoint stays failed while the cluster is full",
+			in:          rejected,
+			wantErr:     shortage,
+			wantPhase:   v1.EndpointPhaseFAILED,
+			wantMessage: shortage,
+		},
+		{
+			name:        "queue keeps the endpoint pending",
+			in:          newEndpoint("", "queue"),
+			wantPhase:   v1.EndpointPhasePENDING,
+			wantMessage: shortage,
+		},
+		{
+			name:       "best effort deploys the endpoint",
+			in:         newEndpoint("", "best_effort"),
+			wantPhase:  v1.EndpointPhaseDEPLOYING,
+			wantCreate: true,
+		},
+		{
+			name:       "deployed endpoints are not checked again",
+			in:         newEndpoint(v1.EndpointPhaseRUNNING, "reject"),
+			wantPhase:  v1.EndpointPhaseDEPLOYING,
+			wantCreate: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms := &storagemocks.MockStorage{}
+			mo := &orchestratormocks.MockOrchestrator{}
+			ms.On("GetClusterByName", mock.Anything, mock.Anything).Return(&fullCluster, nil)
+			ms.On("ListEngine", mock.Anything).Return([]v1.Engine{engine}, nil)
+			ms.On("UpdateEndpoint", strconv.Itoa(id), mock.Anything).Run(func(args mock.Arguments) {
+				tt.in.Status = args.Get(1).(*v1.Endpoint).Status
+			}).Return(nil)
+			mo.On("CreateEndpoint", mock.Anything).Return(nil).Maybe()
+			mo.On("GetEndpointStatus", mock.Anything).Return(&v1.EndpointStatus{Phase: v1.EndpointPhaseDEPLOYING}, nil).Maybe()
+
+			c := newTestEndpointController(ms, mo)
+
+			err := c.sync(tt.in)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			if tt.wantCreate {
+				mo.AssertCalled(t, "CreateEndpoint", tt.in)
+			} else {
+				mo.AssertNotCalled(t, "CreateEndpoint", mock.Anything)
+			}
+
+			assert.Equal(t, tt.wantPhase, tt.in.Status.Phase)
+			assert.Equal(t, tt.wantMessage, tt.in.Status.ErrorMessage)
+		})
+	}
+}
+
+// taskResolvingRegistry is a model registry whose metadata tells the same task for every model.
+type taskResolvingRegistry struct {
+	*modelregistrymocks.MockModelRegistry
+	task string
+}
+
+func (r *taskResolvingRegistry) GetModelTask(_ string) (string, error) {
+	return r.task, nil
+}
+
+func TestEndpointController_Sync_ModelTaskValidation(t *testing.T) {
+	id := 1
+	cluster := v1.Cluster{
+		Metadata: &v1.Metadata{Name: "test-cluster", Workspace: "default"},
+		Status:   &v1.ClusterStatus{Phase: v1.ClusterPhaseRunning},
+	}
+	engine := v1.Engine{
+		Metadata: &v1.Metadata{Name: "test-engine", Workspace: "default"},
+		Status:   &v1.EngineStatus{Phase: v1.EnginePhaseCreated},
+	}
+	registry := v1.ModelRegistry{
+		Metadata: &v1.Metadata{Name: "test-model-registry", Workspace: "default"},
+		Spec:     &v1.ModelRegistrySpec{Type: v1.HuggingFaceModelRegistryType},
+	}
+	newEndpoint := func(phase v1.EndpointPhase, validation string) *v1.Endpoint {
+		e := ep(id, phase)
+		e.Spec.Model.Task = v1.TextGenerationModelTask
+		e.Spec.DeploymentOptions = map[string]interface{}{"model_task_validation": validation}
+
+		return e
+	}
+	mismatch := "model task mismatch: model test-model is declared as text-generation but its registry serves it as text-embedding"
+	rejected := newEndpoint(v1.EndpointPhaseFAILED, "reject")
+	rejected.Status.ErrorMessage = mismatch
+
+	tests := []struct {
+		name         string
+		in           *v1.Endpoint
+		registeredAs string
+		wantErr      string
+		wantPhase    v1.EndpointPhase
+		wantMessage  string
+		wantCreate   bool
+	}{
+		{
+			name:         "matching task deploys the endpoint",
+			in:           newEndpoint("", "reject"),
+			registeredAs: v1.TextGenerationModelTask,
+			wantPhase:    v1.EndpointPhaseDEPLOYING,
+			wantCreate:   true,
+		},
+		{
+			name:         "reject fails the endpoint",
+			in:           newEndpoint("", "reject"),
+			registeredAs: v1.TextEmbeddingModelTask,
+			wantErr:      mismatch,
+			wantPhase:    v1.EndpointPhaseFAILED,
+			wantMessage:  mismatch,
+		},
+		{
+			name:         "rejected endpoint deploys once the task matches",
+			in:           rejected,
+			registeredAs: v1.TextGenerationModelTask,
+			wantPhase:    v1.EndpointPhaseDEPLOYING,
+			wantCreate:   true,
+		},
+		{
+			name:         "warn deploys the endpoint",
+			in:           newEndpoint("", "warn"),
+			registeredAs: v1.TextEmbeddingModelTask,
+			wantPhase:    v1.EndpointPhaseDEPLOYING,
+			wantCreate:   true,
+		},
+		{
+			name:         "unknown task deploys the endpoint",
+			in:           newEndpoint("", "reject"),
+			registeredAs: "",
+			wantPhase:    v1.EndpointPhaseDEPLOYING,
+			wantCreate:   true,
+		},
+		{
+			name:         "deployed endpoints are not checked again",
+			in:           newEndpoint(v1.EndpointPhaseRUNNING, "reject"),
+			registeredAs: v1.TextEmbeddingModelTask,
+			wantPhase:    v1.EndpointPhaseDEPLOYING,
+			wantCreate:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			origNewModelRegistry := model_registry.NewModelRegistry
+			defer func() { model_registry.NewModelRegistry = origNewModelRegistry }()
+
+			model_registry.NewModelRegistry = func(_ *v1.ModelRegistry) (model_registry.ModelRegistry, error) {
+				return &taskResolvingRegistry{task: tt.registeredAs}, nil
+			}
+
+			ms := &storagemocks.MockStorage{}
+			mo := &orchestratormocks.MockOrchestrator{}
+			ms.On("GetClusterByName", mock.Anything, mock.Anything).Return(&cluster, nil)
+			ms.On("ListEngine", mock.Anything).Return([]v1.Engine{engine}, nil)
+			ms.On("ListModelRegistry", mock.Anything).Return([]v1.ModelRegistry{registry}, nil).Maybe()
+			ms.On("UpdateEndpoint", strconv.Itoa(id), mock.Anything).Run(func(args mock.Arguments) {
+				tt.in.Status = args.Get(1).(*v1.Endpoint).Status
+			}).Return(nil)
+			mo.On("CreateEndpoint", mock.Anything).Return(nil).Maybe()
+			mo.On("GetEndpointStatus", mock.Anything).Return(&v1.EndpointStatus{Phase: v1.EndpointPhaseDEPLOYING}, nil).Maybe()
+
+			c := newTestEndpointController(ms, mo)
+
+			err := c.sync(tt.in)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			if tt.wantCreate {
+				mo.AssertCalled(t, "CreateEndpoint", tt.in)
+			} else {
+				mo.AssertNotCalled(t, "CreateEndpoint", mock.Anything)
+			}
+
+			assert.Equal(t, tt.wantPhase, tt.in.Status.Phase)
+			assert.Equal(t, tt.wantMessage, tt.in.Status.ErrorMessage)
+		})
+	}
+}
+
+func TestEndpointController_Sync_ModelTaskCheckedOnSpecChange(t *testing.T) {
+	id := 1
+	cluster := v1.Cluster{
+		Metadata: &v1.Metadata{Name: "test-cluster", Workspace: "default"},
+		Status:   &v1.ClusterStatus{Phase: v1.ClusterPhaseRunning},
+	}
+	engine := v1.Engine{
+		Metadata: &v1.Metadata{Name: "test-engine", Workspace: "default"},
+		Status:   &v1.EngineStatus{Phase: v1.EnginePhaseCreated},
+	}
+	registry := v1.ModelRegistry{
+		Metadata: &v1.Metadata{Name: "test-model-registry", Workspace: "default"},
+		Spec:     &v1.ModelRegistrySpec{Type: v1.HuggingFaceModelRegistryType},
+	}
+
+	origNewModelRegistry := model_registry.NewModelRegistry
+	defer func() { model_registry.NewModelRegistry = origNewModelRegistry }()
+
+	lookups := 0
+	model_registry.NewModelRegistry = func(_ *v1.ModelRegistry) (model_registry.ModelRegistry, error) {
+		lookups++
+		return &taskResolvingRegistry{task: v1.TextEmbeddingModelTask}, nil
+	}
+
+	ms := &storagemocks.MockStorage{}
+	mo := &orchestratormocks.MockOrchestrator{}
+	ms.On("GetClusterByName", mock.Anything, mock.Anything).Return(&cluster, nil)
+	ms.On("ListEngine", mock.Anything).Return([]v1.Engine{engine}, nil)
+	ms.On("ListModelRegistry", mock.Anything).Return([]v1.ModelRegistry{registry}, nil)
+	ms.On("UpdateEndpoint", strconv.Itoa(id), mock.Anything).Return(nil)
+
+	c := newTestEndpointController(ms, mo)
+
+	e := ep(id, "")
+	e.Spec.Model.Task = v1.TextGenerationModelTask
+	e.Spec.DeploymentOptions = map[string]interface{}{"model_task_validation": "reject"}
+
+	for i := 0; i < 2; i++ {
+		e.Status = nil
+		assert.ErrorContains(t, c.sync(e), "model task mismatch")
+	}
+
+	assert.Equal(t, 1, lookups)
+
+	// A spec change checks the endpoint again.
+	e.Status = nil
+	e.Spec.Model.Version = "v2"
+	assert.ErrorContains(t, c.sync(e), "model task mismatch")
+	assert.Equal(t, 2, lookups)
+	mo.AssertNotCalled(t, "CreateEndpoint", mock.Anything)
+}
+
 /* ---------- Reconcile ---------- */
 
 func TestEndpointController_Reconcile(t *testing.T) {
@@ -240,7 +705,7 @@ func Test_UpdateStatusOnError(t *testing.T) {
 			input:    func() *v1.Endpoint { return newEndpoint() },
 			inputErr: nil,
 			mockSetup: func(s *storagemocks.MockStorage, o *orchestratormocks.MockOrchestrator) {
-				s.On("ListCluster", mock.Anything).Return([]v1.Cluster{{}}, nil)
+				s.On("GetClusterByName", mock.Anything, mock.Anything).Return(&v1.Cluster{}, nil)
 				o.On("GetEndpointStatus", mock.Anything).Return(&v1.EndpointStatus{
 					Phase: v1.EndpointPhaseRUNNING,
 				}, nil)
@@ -322,7 +787,7 @@ func Test_UpdateStatusOnError(t *testing.T) {
 			},
 			inputErr: nil,
 			mockSetup: func(s *storagemocks.MockStorage, o *orchestratormocks.MockOrchestrator) {
-				s.On("ListCluster", mock.Anything).Return([]v1.Cluster{{}}, nil)
+				s.On("GetClusterByName", mock.Anything, mock.Anything).Return(&v1.Cluster{}, nil)
 				o.On("GetEndpointStatus", mock.Anything).Return(&v1.EndpointStatus{
 					Phase: v1.EndpointPhaseFAILED,
 				}, nil)
@@ -340,7 +805,7 @@ func Test_UpdateStatusOnError(t *testing.T) {
 			},
 			inputErr: nil,
 			mockSetup: func(s *storagemocks.MockStorage, o *orchestratormocks.MockOrchestrator) {
-				s.On("ListCluster", mock.Anything).Return([]v1.Cluster{{}}, nil)
+				s.On("GetClusterByName", mock.Anything, mock.Anything).Return(&v1.Cluster{}, nil)
 				o.On("GetEndpointStatus", mock.Anything).Return(&v1.EndpointStatus{
 					Phase: v1.EndpointPhaseFAILED,
 				}, assert.AnError)
@@ -362,6 +827,134 @@ func Test_UpdateStatusOnError(t *testing.T) {
 
 }
 
+func Test_UpdateStatusOnError_SyncsGatewayAvailability(t *testing.T) {
+	failed := &v1.EndpointStatus{
+		Phase:        v1.EndpointPhaseFAILED,
+		ErrorMessage: v1.EndpointWorkloadFailedMessagePrefix + "Pod 'pod-a' Container 'vllm' in CrashLoopBackOff",
+	}
+
+	tests := []struct {
+		name         string
+		current      *v1.EndpointStatus
+		actual       *v1.EndpointStatus
+		expectSynced bool
+	}{
+		{
+			name:         "every replica turned unhealthy",
+			current:      &v1.EndpointStatus{Phase: v1.EndpointPhaseRUNNING},
+			actual:       failed,
+			expectSynced: true,
+		},
+		{
+			name:         "a replica recovered",
+			current:      failed,
+			actual:       &v1.EndpointStatus{Phase: v1.EndpointPhaseDEGRADED, ErrorMessage: "Endpoint degraded: 1/2 pods unhealthy"},
+			expectSynced: true,
+		},
+		{
+			name:    "replicas still serving",
+			current: &v1.EndpointStatus{Phase: v1.EndpointPhaseRUNNING},
+			actual:  &v1.EndpointStatus{Phase: v1.EndpointPhaseDEGRADED, ErrorMessage: "Endpoint degraded: 1/2 pods unhealthy"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStorage := &storagemocks.MockStorage{}
+			mockOrchestrator := &orchestratormocks.MockOrchestrator{}
+			mockStorage.On("GetClusterByName", mock.Anything, mock.Anything).Return(&v1.Cluster{}, nil)
+			mockStorage.On("UpdateEndpoint", "1", mock.Anything).Return(nil)
+			mockOrchestrator.On("GetEndpointStatus", mock.Anything).Return(tt.actual, nil)
+
+			c := newTestEndpointController(mockStorage, mockOrchestrator)
+			obj := ep(1, "")
+			obj.Status = tt.current
+
+			c.updateStatusOnError(obj, nil)
+
+			gw := c.gw.(*gatewaymocks.MockGateway)
+			if !tt.expectSynced {
+				gw.AssertNotCalled(t, "SyncEndpoint", mock.Anything)
+				return
+			}
+
+			gw.AssertCalled(t, "SyncEndpoint", mock.MatchedBy(func(synced *v1.Endpoint) bool {
+				return synced.Status != nil && synced.Status.Phase == tt.actual.Phase
+			}))
+		})
+	}
+}
+
+func Test_UpdateStatusOnError_CleansUpFailedDeployment(t *testing.T) {
+	mockStorage := &storagemocks.MockStorage{}
+	mockOrchestrator := &orchestratormocks.MockOrchestrator{}
+	mockStorage.On("GetClusterByName", mock.Anything, mock.Anything).Return(&v1.Cluster{}, nil)
+	mockStorage.On("UpdateEndpoint", "1", mock.Anything).Return(nil)
+	mockOrchestrator.On("GetEndpointStatus", mock.Anything).Return(&v1.EndpointStatus{
+		Phase:        v1.EndpointPhaseFAILED,
+		ErrorMessage: v1.EndpointWorkloadFailedMessagePrefix + "scheduling timed out after 600s on pod 'pod-a'",
+	}, nil)
+	mockOrchestrator.On("DeleteEndpoint", mock.Anything).Return(nil)
+
+	c := newTestEndpointController(mockStorage, mockOrchestrator)
+	obj := ep(1, v1.EndpointPhaseDEPLOYING)
+	obj.Spec.DeploymentOptions = map[string]interface{}{"failure_cleanup": "teardown"}
+
+	// The replicas just failed, they get the startup window to recover.
+	c.updateStatusOnError(obj, nil)
+
+	mockOrchestrator.AssertNotCalled(t, "DeleteEndpoint", mock.Anything)
+
+	var failed *v1.EndpointStatus
+
+	mockStorage.AssertCalled(t, "UpdateEndpoint", "1", mock.MatchedBy(func(updated *v1.Endpoint) bool {
+		failed = updated.Status
+		return updated.Status.Phase == v1.EndpointPhaseFAILED && updated.Status.NoHealthyReplicasSince != "" &&
+			updated.Status.FailureCleanupSpecHash == ""
+	}))
+
+	// Still without a healthy replica once the startup window is over.
+	failed.NoHealthyReplicasSince = time.Now().Add(-2 * time.Hour).Format(time.RFC3339Nano)
+	obj.Status = failed
+
+	c.updateStatusOnError(obj, nil)
+
+	mockOrchestrator.AssertCalled(t, "DeleteEndpoint", obj)
+	mockStorage.AssertCalled(t, "UpdateEndpoint", "1", mock.MatchedBy(func(updated *v1.Endpoint) bool {
+		return updated.Status.Phase == v1.EndpointPhaseFAILED &&
+			updated.Status.ErrorMessage == v1.EndpointWorkloadFailedMessagePrefix+
+				"scheduling timed out after 600s on pod 'pod-a'; failed deployment torn down, update the endpoint to redeploy it" &&
+			updated.Status.FailureCleanupSpecHash != "" &&
+			updated.Status.NoHealthyReplicasSince == failed.NoHealthyReplicasSince
+	}))
+}
+
+func TestEndpointController_Sync_FailedDeploymentCleanedUp(t *testing.T) {
+	obj := ep(1, "")
+	obj.Spec.DeploymentOptions = map[string]interface{}{"failure_cleanup": "scale_to_zero"}
+
+	specHash, err := orchestrator.ComputeEndpointSpecHash(obj)
+	assert.NoError(t, err)
+
+	obj.Status = &v1.EndpointStatus{
+		Phase:                  v1.EndpointPhaseFAILED,
+		ErrorMessage:           v1.EndpointWorkloadFailedMessagePrefix + "image pull timed out after 1800s on pod 'pod-a'",
+		FailureCleanupSpecHash: specHash,
+	}
+
+	ms := &storagemocks.MockStorage{}
+	mo := &orchestratormocks.MockOrchestrator{}
+	ms.On("GetClusterByName", mock.Anything, mock.Anything).Return(&v1.Cluster{}, nil)
+
+	c := newTestEndpointController(ms, mo)
+
+	// The cleaned up deployment is neither redeployed nor its failed status overwritten.
+	assert.NoError(t, c.sync(obj))
+	mo.AssertNotCalled(t, "CreateEndpoint", mock.Anything)
+	mo.AssertNotCalled(t, "GetEndpointStatus", mock.Anything)
+	ms.AssertNotCalled(t, "UpdateEndpoint", mock.Anything, mock.Anything)
+}
+
 func Test_ShouldUpdateStatus(t *testing.T) {
 	endpointResources := func(memoryMiB, coreUnits int64) *v1.EndpointResourceStatus {
 		return &v1.EndpointResourceStatus{
@@ -480,6 +1073,29 @@ func Test_ShouldUpdateStatus(t *testing.T) {
 			},
 			want: false,
 		},
+		{
+			name: "same phase, model download progress advanced",
+			oldStatus: &v1.EndpointStatus{
+				Phase:                 v1.EndpointPhaseMODELDOWNLOADING,
+				ModelDownloadProgress: &v1.ModelDownloadProgress{DownloadedBytes: 10, TotalBytes: 100},
+			},
+			newStatus: &v1.EndpointStatus{
+				Phase:                 v1.EndpointPhaseMODELDOWNLOADING,
+				ModelDownloadProgress: &v1.ModelDownloadProgress{DownloadedBytes: 50, TotalBytes: 100},
+			},
+			want: true,
+		},
+		{
+			name: "same phase, model download progress cleared",
+			oldStatus: &v1.EndpointStatus{
+				Phase:                 v1.EndpointPhaseMODELDOWNLOADING,
+				ModelDownloadProgress: &v1.ModelDownloadProgress{DownloadedBytes: 100, TotalBytes: 100},
+			},
+			newStatus: &v1.EndpointStatus{
+				Phase: v1.EndpointPhaseMODELDOWNLOADING,
+			},
+			want: true,
+		},
 		{
 			name: "same phase, same resources",
 			oldStatus: &v1.EndpointStatus{
@@ -601,6 +1217,29 @@ func Test_PrepareStatusForUpdate_PreservesOmittedModelDownloadHash(t *testing.T)
 	assert.False(t, c.shouldUpdateStatus(endpoint, &v1.EndpointStatus{Phase: v1.EndpointPhaseDEPLOYING}))
 }
 
+func Test_PrepareStatusForUpdate_EngineImageDigest(t *testing.T) {
+	c := &EndpointController{}
+
+	pinned := ep(1, v1.EndpointPhaseRUNNING)
+	pinned.Spec.Engine.PinImageDigest = true
+	pinned.Status.EngineImageDigest = "sha256:abc"
+
+	status := &v1.EndpointStatus{Phase: v1.EndpointPhaseRUNNING}
+	c.prepareStatusForUpdate(pinned, status)
+
+	assert.Equal(t, "sha256:abc", status.EngineImageDigest)
+	assert.False(t, c.shouldUpdateStatus(pinned, &v1.EndpointStatus{Phase: v1.EndpointPhaseRUNNING}))
+
+	unpinned := ep(2, v1.EndpointPhaseRUNNING)
+	unpinned.Status.EngineImageDigest = "sha256:abc"
+
+	status = &v1.EndpointStatus{Phase: v1.EndpointPhaseRUNNING}
+	c.prepareStatusForUpdate(unpinned, status)
+
+	assert.Empty(t, status.EngineImageDigest)
+	assert.True(t, c.shouldUpdateStatus(unpinned, &v1.EndpointStatus{Phase: v1.EndpointPhaseRUNNING}))
+}
+
 func Test_PrepareStatusForUpdate_UsesExplicitModelDownloadHash(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -640,3 +1279,42 @@ func Test_PrepareStatusForUpdate_UsesExplicitModelDownloadHash(t *testing.T) {
 		})
 	}
 }
+
+func TestEndpointController_Sync_DeletionWaitsForDrain(t *testing.T) {
+	ms := &storagemocks.MockStorage{}
+	ms.On("GetClusterByName", mock.Anything, mock.Anything).Return(&v1.Cluster{Metadata: &v1.Metadata{Name: "test-cluster"}}, nil)
+
+	var statuses []*v1.EndpointStatus
+
+	ms.On("UpdateEndpoint", "1", mock.Anything).Return(nil).
+		Run(func(args mock.Arguments) { statuses = append(statuses, args.Get(1).(*v1.Endpoint).Status) }).Maybe()
+
+	mo := &orchestratormocks.MockOrchestrator{}
+	mo.On("DrainEndpoint", mock.Anything, time.Minute).Return(orchestrator.ErrEndpointDraining).Once()
+	mo.On("GetEndpointStatus", mock.Anything).Return(&v1.EndpointStatus{
+		Phase:        v1.EndpointPhaseDELETING,
+		ErrorMessage: "Endpoint deleting in progress",
+	}, nil).Once()
+
+	orchestrator.NewOrchestrator = func(opts orchestrator.Options) (orchestrator.Orchestrator, error) {
+		return mo, nil
+	}
+
+	gw := &gatewaymocks.MockGateway{}
+	gw.On("DeleteEndpoint", mock.Anything).Return(nil)
+
+	c, _ := NewEndpointController(&EndpointControllerOption{Storage: ms, Gw: gw, DrainTimeout: time.Minute})
+
+	obj := epDel(1, v1.EndpointPhaseRUNNING)
+
+	// The draining endpoint stays deleting without an error, the next reconcile checks it again.
+	assert.NoError(t, c.sync(obj))
+
+	if !assert.Len(t, statuses, 1) {
+		return
+	}
+
+	assert.Equal(t, v1.EndpointPhaseDELETING, statuses[0].Phase)
+	assert.Equal(t, "Endpoint deleting in progress", statuses[0].ErrorMessage)
+	mo.AssertExpectations(t)
+}
@@ -346,6 +346,8 @@ func TestForceDelete_ImageRegistryController(t *testing.T) {
 		},
 	}
 
+	mockStorage.On("ListCluster", mock.Anything).Return([]v1.Cluster{}, nil)
+
 	// Mock updateStatus call
 	mockStorage.On("UpdateImageRegistry", "1", mock.MatchedBy(func(r *v1.ImageRegistry) bool {
 		return r.Status != nil && r.Status.Phase == v1.ImageRegistryPhaseDELETED
@@ -414,14 +416,12 @@ func TestForceDelete_EndpointController(t *testing.T) {
 				},
 			}
 
-			// Mock ListCluster for getOrchestrator (called multiple times during deletion)
-			mockStorage.On("ListCluster", mock.Anything).Return([]v1.Cluster{
-				{
-					ID: 1,
-					Metadata: &v1.Metadata{
-						Name:      "test-cluster",
-						Workspace: "test-ws",
-					},
+			// Mock GetClusterByName for getOrchestrator (called multiple times during deletion)
+			mockStorage.On("GetClusterByName", mock.Anything, mock.Anything).Return(&v1.Cluster{
+				ID: 1,
+				Metadata: &v1.Metadata{
+					Name:      "test-cluster",
+					Workspace: "test-ws",
 				},
 			}, nil).Maybe()
 
@@ -430,6 +430,7 @@ func TestForceDelete_EndpointController(t *testing.T) {
 
 			// Mock orchestrator calls during endpoint deletion
 			mockOrchestrator.On("DeleteEndpoint", obj).Return(nil).Maybe()
+			mockOrchestrator.On("DrainEndpoint", obj, mock.Anything).Return(nil).Maybe()
 			if !tt.forceDelete {
 				// In normal delete, GetEndpointStatus may return RUNNING initially
 				mockOrchestrator.On("GetEndpointStatus", obj).Return(&v1.EndpointStatus{
@@ -3,6 +3,7 @@ package controllers
 import (
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/google/go-containerregistry/pkg/authn"
 	"github.com/pkg/errors"
@@ -67,7 +68,19 @@ func (c *ImageRegistryController) sync(obj *v1.ImageRegistry) error {
 
 		klog.Infof("Deleting image registry %s", obj.Metadata.Name)
 
-		// No cleanup operations needed for image registry deletion
+		err = c.handleDependentClusters(obj)
+		if err != nil {
+			if obj.Status == nil || obj.Status.Phase != v1.ImageRegistryPhaseFAILED ||
+				obj.Status.ErrorMessage != FormatErrorForStatus(err) {
+				if updateErr := c.updateStatus(obj, v1.ImageRegistryPhaseFAILED, err); updateErr != nil {
+					klog.Errorf("failed to update image registry %s/%s status: %v",
+						obj.Metadata.Workspace, obj.Metadata.Name, updateErr)
+				}
+			}
+
+			return err
+		}
+
 		updateErr := c.updateStatus(obj, v1.ImageRegistryPhaseDELETED, nil)
 		if updateErr != nil {
 			klog.Errorf("failed to update image registry %s/%s status: %v",
@@ -110,6 +123,99 @@ func (c *ImageRegistryController) sync(obj *v1.ImageRegistry) error {
 	return nil
 }
 
+// handleDependentClusters keeps an image registry still referenced by clusters from being deleted,
+// as the API does. A force deletion proceeds instead, and the dependent clusters and their endpoints
+// are marked as failed with the registry missing, so their reconcile failures are not opaque.
+func (c *ImageRegistryController) handleDependentClusters(obj *v1.ImageRegistry) error {
+	clusters, err := c.storage.ListCluster(storage.ListOption{
+		Filters: []storage.Filter{
+			{Column: "metadata->>workspace", Operator: "eq", Value: obj.Metadata.Workspace},
+			{Column: "spec->>image_registry", Operator: "eq", Value: obj.Metadata.Name},
+		},
+		IncludeDeleted: true,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to list clusters of image registry %s", obj.Metadata.WorkspaceName())
+	}
+
+	if len(clusters) == 0 {
+		return nil
+	}
+
+	if !v1.IsForceDelete(obj.Metadata.Annotations) {
+		names := make([]string, 0, len(clusters))
+		for _, cluster := range clusters {
+			names = append(names, cluster.Metadata.Name)
+		}
+
+		return errors.Errorf("image registry %s is still referenced by cluster(s) %s, delete them first or force delete it",
+			obj.Metadata.WorkspaceName(), strings.Join(names, ", "))
+	}
+
+	klog.Warningf("Force delete: image registry %s is still referenced by %d cluster(s), marking them as failed",
+		obj.Metadata.WorkspaceName(), len(clusters))
+
+	for i := range clusters {
+		if err = c.markRegistryMissing(obj, &clusters[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *ImageRegistryController) markRegistryMissing(obj *v1.ImageRegistry, cluster *v1.Cluster) error {
+	if cluster.Metadata.DeletionTimestamp != "" {
+		return nil
+	}
+
+	message := fmt.Sprintf("image registry %s is missing, it was force deleted", obj.Metadata.Name)
+
+	// Keep the rest of the status, e.g. the ready nodes and versions.
+	status := v1.ClusterStatus{}
+	if cluster.Status != nil {
+		status = *cluster.Status
+	}
+
+	status.Phase = v1.ClusterPhaseFailed
+	status.ErrorMessage = message
+	status.LastTransitionTime = FormatStatusTime()
+
+	if err := c.storage.UpdateCluster(strconv.Itoa(cluster.ID), &v1.Cluster{Status: &status}); err != nil {
+		return errors.Wrapf(err, "failed to mark cluster %s with image registry missing", cluster.Metadata.WorkspaceName())
+	}
+
+	endpoints, err := c.storage.ListEndpoint(storage.ListOption{
+		Filters: []storage.Filter{
+			{Column: "metadata->>workspace", Operator: "eq", Value: cluster.Metadata.Workspace},
+			{Column: "spec->>cluster", Operator: "eq", Value: cluster.Metadata.Name},
+		},
+		IncludeDeleted: true,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to list endpoints of cluster %s", cluster.Metadata.WorkspaceName())
+	}
+
+	for i := range endpoints {
+		endpoint := &endpoints[i]
+
+		if endpoint.Metadata == nil || endpoint.Metadata.DeletionTimestamp != "" || endpoint.Status == nil {
+			continue
+		}
+
+		endpointStatus := *endpoint.Status
+		endpointStatus.Phase = v1.EndpointPhaseFAILED
+		endpointStatus.ErrorMessage = message
+		endpointStatus.LastTransitionTime = FormatStatusTime()
+
+		if err = c.storage.UpdateEndpoint(strconv.Itoa(endpoint.ID), &v1.Endpoint{Status: &endpointStatus}); err != nil {
+			return errors.Wrapf(err, "failed to mark endpoint %s with image registry missing", endpoint.Metadata.WorkspaceName())
+		}
+	}
+
+	return nil
+}
+
 func (c *ImageRegistryController) connectImageRegistry(imageRegistry *v1.ImageRegistry) error {
 	authConfig := authn.AuthConfig{
 		Username: imageRegistry.Spec.AuthConfig.Username,
@@ -10,6 +10,7 @@ import (
 
 	v1 "github.com/neutree-ai/neutree/api/v1"
 	registrymocks "github.com/neutree-ai/neutree/internal/registry/mocks"
+	"github.com/neutree-ai/neutree/pkg/storage"
 	storagemocks "github.com/neutree-ai/neutree/pkg/storage/mocks"
 )
 
@@ -78,6 +79,138 @@ func TestImageRegistryController_Sync_Delete(t *testing.T) {
 	}
 }
 
+func TestImageRegistryController_Sync_DeleteReferenced(t *testing.T) {
+	testImageRegistry := func(force bool) *v1.ImageRegistry {
+		obj := &v1.ImageRegistry{
+			ID: 1,
+			Metadata: &v1.Metadata{
+				Name:              "test",
+				Workspace:         "default",
+				DeletionTimestamp: time.Now().Format(time.RFC3339Nano),
+			},
+			Status: &v1.ImageRegistryStatus{Phase: v1.ImageRegistryPhaseCONNECTED},
+		}
+		if force {
+			obj.Metadata.Annotations = v1.WithForceDeleteAnnotation(nil)
+		}
+
+		return obj
+	}
+
+	dependentClusters := []v1.Cluster{
+		{
+			ID:       10,
+			Metadata: &v1.Metadata{Name: "ray-a", Workspace: "default"},
+			Status:   &v1.ClusterStatus{Phase: v1.ClusterPhaseRunning, ReadyNodes: 2},
+		},
+		{
+			ID:       11,
+			Metadata: &v1.Metadata{Name: "ray-b", Workspace: "default", DeletionTimestamp: "2025-12-29T06:09:38.917Z"},
+			Status:   &v1.ClusterStatus{Phase: v1.ClusterPhaseDeleting},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		input     *v1.ImageRegistry
+		mockSetup func(*storagemocks.MockStorage)
+		wantErr   string
+	}{
+		{
+			name:  "deletion is blocked while clusters reference the registry",
+			input: testImageRegistry(false),
+			mockSetup: func(s *storagemocks.MockStorage) {
+				s.On("ListCluster", storage.ListOption{
+					Filters: []storage.Filter{
+						{Column: "metadata->>workspace", Operator: "eq", Value: "default"},
+						{Column: "spec->>image_registry", Operator: "eq", Value: "test"},
+					},
+					IncludeDeleted: true,
+				}).Return(dependentClusters, nil)
+				s.On("UpdateImageRegistry", "1", mock.Anything).Run(func(args mock.Arguments) {
+					arg := args.Get(1).(*v1.ImageRegistry)
+					assert.Equal(t, v1.ImageRegistryPhaseFAILED, arg.Status.Phase)
+					assert.Contains(t, arg.Status.ErrorMessage, "still referenced by cluster(s) ray-a, ray-b")
+				}).Return(nil)
+			},
+			wantErr: "image registry default/test is still referenced by cluster(s) ray-a, ray-b, delete them first or force delete it",
+		},
+		{
+			name:  "force deletion marks dependent clusters and endpoints",
+			input: testImageRegistry(true),
+			mockSetup: func(s *storagemocks.MockStorage) {
+				s.On("ListCluster", mock.Anything).Return(dependentClusters, nil)
+				s.On("UpdateCluster", "10", mock.Anything).Run(func(args mock.Arguments) {
+					arg := args.Get(1).(*v1.Cluster)
+					assert.Equal(t, v1.ClusterPhaseFailed, arg.Status.Phase)
+					assert.Equal(t, "image registry test is missing, it was force deleted", arg.Status.ErrorMessage)
+					assert.Equal(t, 2, arg.Status.ReadyNodes)
+				}).Return(nil).Once()
+				s.On("ListEndpoint", storage.ListOption{
+					Filters: []storage.Filter{
+						{Column: "metadata->>workspace", Operator: "eq", Value: "default"},
+						{Column: "spec->>cluster", Operator: "eq", Value: "ray-a"},
+					},
+					IncludeDeleted: true,
+				}).Return([]v1.Endpoint{
+					{
+						ID:       20,
+						Metadata: &v1.Metadata{Name: "chat", Workspace: "default"},
+						Status:   &v1.EndpointStatus{Phase: v1.EndpointPhaseRUNNING, ServiceURL: "http://chat"},
+					},
+					{
+						ID:       21,
+						Metadata: &v1.Metadata{Name: "old", Workspace: "default", DeletionTimestamp: "2025-12-29T06:09:38.917Z"},
+						Status:   &v1.EndpointStatus{Phase: v1.EndpointPhaseDELETING},
+					},
+				}, nil).Once()
+				s.On("UpdateEndpoint", "20", mock.Anything).Run(func(args mock.Arguments) {
+					arg := args.Get(1).(*v1.Endpoint)
+					assert.Equal(t, v1.EndpointPhaseFAILED, arg.Status.Phase)
+					assert.Equal(t, "image registry test is missing, it was force deleted", arg.Status.ErrorMessage)
+					assert.Equal(t, "http://chat", arg.Status.ServiceURL)
+				}).Return(nil).Once()
+				s.On("UpdateImageRegistry", "1", mock.Anything).Run(func(args mock.Arguments) {
+					arg := args.Get(1).(*v1.ImageRegistry)
+					assert.Equal(t, v1.ImageRegistryPhaseDELETED, arg.Status.Phase)
+				}).Return(nil)
+			},
+		},
+		{
+			name:  "force deletion fails when a dependent cannot be marked",
+			input: testImageRegistry(true),
+			mockSetup: func(s *storagemocks.MockStorage) {
+				s.On("ListCluster", mock.Anything).Return(dependentClusters, nil)
+				s.On("UpdateCluster", "10", mock.Anything).Return(assert.AnError)
+				s.On("UpdateImageRegistry", "1", mock.Anything).Run(func(args mock.Arguments) {
+					arg := args.Get(1).(*v1.ImageRegistry)
+					assert.Equal(t, v1.ImageRegistryPhaseFAILED, arg.Status.Phase)
+				}).Return(nil)
+			},
+			wantErr: "failed to mark cluster default/ray-a with image registry missing",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStorage := &storagemocks.MockStorage{}
+			mockImageService := &registrymocks.MockImageService{}
+			tt.mockSetup(mockStorage)
+
+			c := newTestImageRegistryController(mockStorage, mockImageService)
+
+			err := c.sync(tt.input)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			mockStorage.AssertExpectations(t)
+		})
+	}
+}
+
 func TestImageRegistryController_Sync_PendingOrNoStatus(t *testing.T) {
 	testImageRegistry := func() *v1.ImageRegistry {
 		return &v1.ImageRegistry{
@@ -163,6 +296,7 @@ func TestImageRegistryController_Sync_PendingOrNoStatus(t *testing.T) {
 			name:  "Pending/NoStatus -> Deleted",
 			input: testImageRegistryWithDeletionTimestamp(),
 			mockSetup: func(input *v1.ImageRegistry, s *storagemocks.MockStorage, imageSvc *registrymocks.MockImageService) {
+				s.On("ListCluster", mock.Anything).Return([]v1.Cluster{}, nil)
 				s.On("UpdateImageRegistry", "1", mock.Anything).Run(func(args mock.Arguments) {
 					arg := args.Get(1).(*v1.ImageRegistry)
 					assert.Equal(t, v1.ImageRegistryPhaseDELETED, arg.Status.Phase)
@@ -273,6 +407,7 @@ func TestImageRegistryController_Sync_Conneted(t *testing.T) {
 			name:  "Connected -> Deleted",
 			input: testImageRegistryWithDeletionTimestamp(),
 			mockSetup: func(input *v1.ImageRegistry, s *storagemocks.MockStorage, imageSvc *registrymocks.MockImageService) {
+				s.On("ListCluster", mock.Anything).Return([]v1.Cluster{}, nil)
 				s.On("UpdateImageRegistry", "1", mock.Anything).Run(func(args mock.Arguments) {
 					arg := args.Get(1).(*v1.ImageRegistry)
 					assert.Equal(t, v1.ImageRegistryPhaseDELETED, arg.Status.Phase)
@@ -390,6 +525,7 @@ func TestImageRegistryController_Sync_Failed(t *testing.T) {
 			name:  "Failed -> Deleted",
 			input: testImageRegistryWithDeletionTimestamp(),
 			mockSetup: func(input *v1.ImageRegistry, s *storagemocks.MockStorage, imageSvc *registrymocks.MockImageService) {
+				s.On("ListCluster", mock.Anything).Return([]v1.Cluster{}, nil)
 				s.On("UpdateImageRegistry", "1", mock.Anything).Run(func(args mock.Arguments) {
 					arg := args.Get(1).(*v1.ImageRegistry)
 					assert.Equal(t, v1.ImageRegistryPhaseDELETED, arg.Status.Phase)
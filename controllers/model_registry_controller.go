@@ -1,6 +1,8 @@
 package controllers
 
 import (
+	"crypto/sha256"
+	"fmt"
 	"strconv"
 
 	"github.com/pkg/errors"
@@ -8,6 +10,7 @@ import (
 
 	v1 "github.com/neutree-ai/neutree/api/v1"
 	"github.com/neutree-ai/neutree/internal/model_registry"
+	"github.com/neutree-ai/neutree/internal/orchestrator"
 	"github.com/neutree-ai/neutree/pkg/storage"
 )
 
@@ -86,7 +89,7 @@ func (c *ModelRegistryController) sync(obj *v1.ModelRegistry) (err error) {
 			phase = v1.ModelRegistryPhaseFAILED
 		}
 
-		updateErr := c.updateStatus(obj, phase, deleteErr)
+		updateErr := c.updateStatus(obj, phase, deleteErr, "")
 		if updateErr != nil {
 			klog.Errorf("failed to update model registry %s/%s status: %v",
 				obj.Metadata.Workspace, obj.Metadata.Name, updateErr)
@@ -112,13 +115,23 @@ func (c *ModelRegistryController) sync(obj *v1.ModelRegistry) (err error) {
 			phase = v1.ModelRegistryPhaseFAILED
 		}
 
-		// Skip update if already in correct phase and no error change
+		observedSpecHash := ""
+		if obj.Status != nil {
+			observedSpecHash = obj.Status.ObservedSpecHash
+		}
+
+		if err == nil {
+			observedSpecHash = c.observeSpec(obj)
+		}
+
+		// Skip update if already in correct phase and no error or observed spec change
 		if obj.Status != nil && obj.Status.Phase == phase &&
-			(err != nil) == (obj.Status.ErrorMessage != "") {
+			(err != nil) == (obj.Status.ErrorMessage != "") &&
+			obj.Status.ObservedSpecHash == observedSpecHash {
 			return
 		}
 
-		updateErr := c.updateStatus(obj, phase, err)
+		updateErr := c.updateStatus(obj, phase, err, observedSpecHash)
 		if updateErr != nil {
 			klog.Errorf("failed to update model registry %s/%s status: %v",
 				obj.Metadata.Workspace, obj.Metadata.Name, updateErr)
@@ -165,11 +178,90 @@ func (c *ModelRegistryController) sync(obj *v1.ModelRegistry) (err error) {
 	return nil
 }
 
-func (c *ModelRegistryController) updateStatus(obj *v1.ModelRegistry, phase v1.ModelRegistryPhase, err error) error {
+// observeSpec returns the spec hash to record for a connected registry. When the registry type or
+// URL changed since the last observed spec, the endpoints using the registry are marked for
+// redeploy first; if that fails the previous hash is kept so the change is retried on the next sync.
+func (c *ModelRegistryController) observeSpec(obj *v1.ModelRegistry) string {
+	hash := computeModelRegistrySpecHash(obj.Spec)
+
+	previous := ""
+	if obj.Status != nil {
+		previous = obj.Status.ObservedSpecHash
+	}
+
+	// Nothing to migrate on the first observation.
+	if previous == "" || previous == hash {
+		return hash
+	}
+
+	klog.Infof("Model registry %s/%s type or URL changed, redeploying dependent endpoints",
+		obj.Metadata.Workspace, obj.Metadata.Name)
+
+	if err := c.redeployDependentEndpoints(obj); err != nil {
+		klog.Errorf("failed to redeploy endpoints of model registry %s/%s: %v",
+			obj.Metadata.Workspace, obj.Metadata.Name, err)
+
+		return previous
+	}
+
+	return hash
+}
+
+// redeployDependentEndpoints marks the active endpoints using the registry as Deploying, so their
+// next reconcile re-renders volumes and download args from the new registry and the redeploy is
+// visible until the endpoint controller reports the actual phase.
+func (c *ModelRegistryController) redeployDependentEndpoints(obj *v1.ModelRegistry) error {
+	endpoints, err := c.storage.ListEndpoint(storage.ListOption{
+		Filters: []storage.Filter{
+			{Column: "metadata->>workspace", Operator: "eq", Value: obj.Metadata.Workspace},
+			{Column: "spec->model->>registry", Operator: "eq", Value: obj.Metadata.Name},
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to list endpoints")
+	}
+
+	for i := range endpoints {
+		endpoint := &endpoints[i]
+
+		if endpoint.Metadata == nil || endpoint.Metadata.DeletionTimestamp != "" ||
+			endpoint.Status == nil || orchestrator.IsEndpointPaused(endpoint) {
+			continue
+		}
+
+		// Keep the rest of the status, e.g. a pinned engine image digest.
+		status := *endpoint.Status
+		status.Phase = v1.EndpointPhaseDEPLOYING
+		status.LastTransitionTime = FormatStatusTime()
+		status.ErrorMessage = ""
+
+		if err = c.storage.UpdateEndpoint(strconv.Itoa(endpoint.ID), &v1.Endpoint{Status: &status}); err != nil {
+			return errors.Wrapf(err, "failed to mark endpoint %s for redeploy", endpoint.Metadata.WorkspaceName())
+		}
+	}
+
+	return nil
+}
+
+// computeModelRegistrySpecHash hashes the registry fields endpoint deployments depend on.
+// Credentials are excluded, rotating them does not change mounts or download sources.
+func computeModelRegistrySpecHash(spec *v1.ModelRegistrySpec) string {
+	if spec == nil {
+		return ""
+	}
+
+	hash := sha256.Sum256([]byte(string(spec.Type) + "\n" + spec.Url))
+
+	return fmt.Sprintf("%x", hash)
+}
+
+func (c *ModelRegistryController) updateStatus(obj *v1.ModelRegistry, phase v1.ModelRegistryPhase, err error,
+	observedSpecHash string) error {
 	newStatus := &v1.ModelRegistryStatus{
 		LastTransitionTime: FormatStatusTime(),
 		Phase:              phase,
 		ErrorMessage:       FormatErrorForStatus(err),
+		ObservedSpecHash:   observedSpecHash,
 	}
 
 	return c.storage.UpdateModelRegistry(strconv.Itoa(obj.ID), &v1.ModelRegistry{Status: newStatus})
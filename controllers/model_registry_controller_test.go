@@ -10,6 +10,7 @@ import (
 	v1 "github.com/neutree-ai/neutree/api/v1"
 	"github.com/neutree-ai/neutree/internal/model_registry"
 	modelregistrymocks "github.com/neutree-ai/neutree/internal/model_registry/mocks"
+	"github.com/neutree-ai/neutree/pkg/storage"
 	storagemocks "github.com/neutree-ai/neutree/pkg/storage/mocks"
 )
 
@@ -376,6 +377,112 @@ func TestModelRegistryController_Sync_Failed(t *testing.T) {
 	}
 }
 
+func TestModelRegistryController_Sync_SpecChange(t *testing.T) {
+	oldSpec := &v1.ModelRegistrySpec{Type: v1.BentoMLModelRegistryType, Url: "nfs://old-server/models"}
+	newSpec := &v1.ModelRegistrySpec{Type: v1.BentoMLModelRegistryType, Url: "nfs://new-server/models"}
+
+	testModelRegistry := func(spec *v1.ModelRegistrySpec, observedSpecHash string) *v1.ModelRegistry {
+		return &v1.ModelRegistry{
+			ID:       1,
+			Metadata: &v1.Metadata{Name: "test", Workspace: "default"},
+			Spec:     spec,
+			Status: &v1.ModelRegistryStatus{
+				Phase:            v1.ModelRegistryPhaseCONNECTED,
+				ObservedSpecHash: observedSpecHash,
+			},
+		}
+	}
+
+	paused := 0
+	endpoints := []v1.Endpoint{
+		{
+			ID:       10,
+			Metadata: &v1.Metadata{Name: "running", Workspace: "default"},
+			Spec:     &v1.EndpointSpec{},
+			Status:   &v1.EndpointStatus{Phase: v1.EndpointPhaseRUNNING, EngineImageDigest: "sha256:abc"},
+		},
+		{
+			ID:       11,
+			Metadata: &v1.Metadata{Name: "paused", Workspace: "default"},
+			Spec:     &v1.EndpointSpec{Replicas: v1.ReplicaSpec{Num: &paused}},
+			Status:   &v1.EndpointStatus{Phase: v1.EndpointPhasePAUSED},
+		},
+		{
+			ID:       12,
+			Metadata: &v1.Metadata{Name: "deleting", Workspace: "default", DeletionTimestamp: time.Now().Format(time.RFC3339Nano)},
+			Spec:     &v1.EndpointSpec{},
+			Status:   &v1.EndpointStatus{Phase: v1.EndpointPhaseDELETING},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		input     *v1.ModelRegistry
+		mockSetup func(*storagemocks.MockStorage)
+	}{
+		{
+			name:  "URL change redeploys dependent endpoints",
+			input: testModelRegistry(newSpec, computeModelRegistrySpecHash(oldSpec)),
+			mockSetup: func(s *storagemocks.MockStorage) {
+				s.On("ListEndpoint", mock.MatchedBy(func(opt storage.ListOption) bool {
+					return len(opt.Filters) == 2 &&
+						opt.Filters[0].Column == "metadata->>workspace" && opt.Filters[0].Value == "default" &&
+						opt.Filters[1].Column == "spec->model->>registry" && opt.Filters[1].Value == "test"
+				})).Return(endpoints, nil).Once()
+				s.On("UpdateEndpoint", "10", mock.Anything).Run(func(args mock.Arguments) {
+					status := args.Get(1).(*v1.Endpoint).Status
+					assert.Equal(t, v1.EndpointPhaseDEPLOYING, status.Phase)
+					assert.Equal(t, "sha256:abc", status.EngineImageDigest)
+				}).Return(nil).Once()
+				s.On("UpdateModelRegistry", "1", mock.Anything).Run(func(args mock.Arguments) {
+					obj := args.Get(1).(*v1.ModelRegistry)
+					assert.Equal(t, v1.ModelRegistryPhaseCONNECTED, obj.Status.Phase)
+					assert.Equal(t, computeModelRegistrySpecHash(newSpec), obj.Status.ObservedSpecHash)
+				}).Return(nil).Once()
+			},
+		},
+		{
+			name:  "failed redeploy keeps the previous hash",
+			input: testModelRegistry(newSpec, computeModelRegistrySpecHash(oldSpec)),
+			mockSetup: func(s *storagemocks.MockStorage) {
+				s.On("ListEndpoint", mock.Anything).Return(nil, assert.AnError).Once()
+			},
+		},
+		{
+			name:      "unchanged spec does not redeploy",
+			input:     testModelRegistry(newSpec, computeModelRegistrySpecHash(newSpec)),
+			mockSetup: func(s *storagemocks.MockStorage) {},
+		},
+		{
+			name:  "first observation only records the hash",
+			input: testModelRegistry(newSpec, ""),
+			mockSetup: func(s *storagemocks.MockStorage) {
+				s.On("UpdateModelRegistry", "1", mock.Anything).Run(func(args mock.Arguments) {
+					obj := args.Get(1).(*v1.ModelRegistry)
+					assert.Equal(t, computeModelRegistrySpecHash(newSpec), obj.Status.ObservedSpecHash)
+				}).Return(nil).Once()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStorage := &storagemocks.MockStorage{}
+			mockModel := &modelregistrymocks.MockModelRegistry{}
+			mockModel.On("HealthyCheck").Return(nil)
+			tt.mockSetup(mockStorage)
+
+			c := newTestModelRegistryController(mockStorage, mockModel)
+			assert.NoError(t, c.sync(tt.input))
+
+			mockStorage.AssertExpectations(t)
+			mockStorage.AssertNotCalled(t, "UpdateEndpoint", "11", mock.Anything)
+			mockStorage.AssertNotCalled(t, "UpdateEndpoint", "12", mock.Anything)
+			mockModel.AssertExpectations(t)
+		})
+	}
+}
+
 func TestModelRegistryController_Reconcile(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -7,21 +7,26 @@ import (
 	"k8s.io/klog/v2"
 
 	v1 "github.com/neutree-ai/neutree/api/v1"
+	"github.com/neutree-ai/neutree/internal/gateway"
 	"github.com/neutree-ai/neutree/pkg/storage"
 )
 
 type RoleAssignmentController struct {
 	storage     storage.Storage
 	syncHandler func(roleAssignment *v1.RoleAssignment) error // Added syncHandler field
+
+	gw gateway.Gateway
 }
 
 type RoleAssignmentControllerOption struct {
 	Storage storage.Storage
+	Gw      gateway.Gateway
 }
 
 func NewRoleAssignmentController(option *RoleAssignmentControllerOption) (*RoleAssignmentController, error) {
 	c := &RoleAssignmentController{
 		storage: option.Storage,
+		gw:      option.Gw,
 	}
 
 	c.syncHandler = c.sync
@@ -73,6 +78,12 @@ func (c *RoleAssignmentController) sync(obj *v1.RoleAssignment) error {
 
 		klog.Infof("Deleting role assignment %s (ID: %d)", objName, obj.ID)
 
+		// Revoke the access granted through the assignment from the user's API keys before
+		// the assignment is marked deleted, so a failed sync is retried.
+		if err = c.syncUserAPIKeys(obj); err != nil {
+			return errors.Wrapf(err, "failed to revoke role assignment %s (ID: %d) from api keys", objName, obj.ID)
+		}
+
 		updateErr := c.updateStatus(obj, v1.RoleAssignmentPhaseDELETED, nil)
 		if updateErr != nil {
 			klog.Errorf("failed to update role assignment %s (ID: %d) status: %v", objName, obj.ID, updateErr)
@@ -102,6 +113,44 @@ func (c *RoleAssignmentController) sync(obj *v1.RoleAssignment) error {
 	return nil
 }
 
+// syncUserAPIKeys re-syncs the gateway config of the API keys owned by the user of the
+// role assignment, so inference access follows the user's current permissions without
+// waiting for the periodic API key resync. Soft-deleted role assignments no longer grant
+// permissions, hence the sync already drops the access they granted.
+func (c *RoleAssignmentController) syncUserAPIKeys(obj *v1.RoleAssignment) error {
+	if obj.Spec == nil || obj.Spec.UserID == "" {
+		return nil
+	}
+
+	apiKeys, err := c.storage.ListApiKey(storage.ListOption{
+		Filters: []storage.Filter{
+			{
+				Column:   "user_id",
+				Operator: "eq",
+				Value:    obj.Spec.UserID,
+			},
+		},
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to list api keys of user %s", obj.Spec.UserID)
+	}
+
+	for i := range apiKeys {
+		apiKey := &apiKeys[i]
+		// Keys being deleted or not yet created are handled by the api key controller.
+		if (apiKey.Metadata != nil && apiKey.Metadata.DeletionTimestamp != "") ||
+			apiKey.Status == nil || apiKey.Status.SkValue == "" {
+			continue
+		}
+
+		if err := c.gw.SyncAPIKey(apiKey); err != nil {
+			return errors.Wrapf(err, "failed to sync api key %s to gateway", apiKey.ID)
+		}
+	}
+
+	return nil
+}
+
 func (c *RoleAssignmentController) updateStatus(obj *v1.RoleAssignment, phase v1.RoleAssignmentPhase, err error) error {
 	newStatus := &v1.RoleAssignmentStatus{
 		LastTransitionTime: FormatStatusTime(),
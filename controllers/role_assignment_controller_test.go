@@ -10,14 +10,16 @@ import (
 	"github.com/stretchr/testify/mock"
 
 	v1 "github.com/neutree-ai/neutree/api/v1"
+	gatewaymocks "github.com/neutree-ai/neutree/internal/gateway/mocks"
 	"github.com/neutree-ai/neutree/pkg/storage"
 	storagemocks "github.com/neutree-ai/neutree/pkg/storage/mocks"
 )
 
-// newTestRoleAssignmentController is a helper to create a RoleAssignmentController with mocked storage for testing.
-func newTestRoleAssignmentController(storage *storagemocks.MockStorage) *RoleAssignmentController {
+// newTestRoleAssignmentController is a helper to create a RoleAssignmentController with mocked storage and gateway for testing.
+func newTestRoleAssignmentController(storage *storagemocks.MockStorage, gw *gatewaymocks.MockGateway) *RoleAssignmentController {
 	c, _ := NewRoleAssignmentController(&RoleAssignmentControllerOption{
 		Storage: storage,
+		Gw:      gw,
 	})
 
 	return c
@@ -57,13 +59,13 @@ func TestRoleAssignmentController_Sync_Deletion(t *testing.T) {
 	tests := []struct {
 		name      string
 		input     *v1.RoleAssignment
-		mockSetup func(*storagemocks.MockStorage)
+		mockSetup func(*storagemocks.MockStorage, *gatewaymocks.MockGateway)
 		wantErr   bool
 	}{
 		{
 			name:  "Deleting (Phase=DELETED) -> Deleted (DB delete success)",
 			input: testRoleAssignmentWithDeletionTimestamp(raID, v1.RoleAssignmentPhaseDELETED),
-			mockSetup: func(s *storagemocks.MockStorage) {
+			mockSetup: func(s *storagemocks.MockStorage, gw *gatewaymocks.MockGateway) {
 				s.On("DeleteRoleAssignment", raIDStr).Return(nil).Once()
 			},
 			wantErr: false,
@@ -71,7 +73,7 @@ func TestRoleAssignmentController_Sync_Deletion(t *testing.T) {
 		{
 			name:  "Deleting (Phase=DELETED) -> Error (DB delete failed)",
 			input: testRoleAssignmentWithDeletionTimestamp(raID, v1.RoleAssignmentPhaseDELETED),
-			mockSetup: func(s *storagemocks.MockStorage) {
+			mockSetup: func(s *storagemocks.MockStorage, gw *gatewaymocks.MockGateway) {
 				s.On("DeleteRoleAssignment", raIDStr).Return(assert.AnError).Once()
 			},
 			wantErr: true,
@@ -79,7 +81,7 @@ func TestRoleAssignmentController_Sync_Deletion(t *testing.T) {
 		{
 			name:  "Deleting (Phase=DELETED) -> Already Deleted (DB delete returns NotFound)",
 			input: testRoleAssignmentWithDeletionTimestamp(raID, v1.RoleAssignmentPhaseDELETED),
-			mockSetup: func(s *storagemocks.MockStorage) {
+			mockSetup: func(s *storagemocks.MockStorage, gw *gatewaymocks.MockGateway) {
 				s.On("DeleteRoleAssignment", raIDStr).Return(storage.ErrResourceNotFound).Once()
 			},
 			wantErr: false, // NotFound is not an error in this case
@@ -87,7 +89,8 @@ func TestRoleAssignmentController_Sync_Deletion(t *testing.T) {
 		{
 			name:  "Deleting (Phase=CREATED) -> Set Phase=DELETED (Update success)",
 			input: testRoleAssignmentWithDeletionTimestamp(raID, v1.RoleAssignmentPhaseCREATED),
-			mockSetup: func(s *storagemocks.MockStorage) {
+			mockSetup: func(s *storagemocks.MockStorage, gw *gatewaymocks.MockGateway) {
+				s.On("ListApiKey", mock.Anything).Return([]v1.ApiKey{}, nil).Once()
 				s.On("UpdateRoleAssignment", raIDStr, mock.MatchedBy(func(r *v1.RoleAssignment) bool {
 					return r.Status != nil && r.Status.Phase == v1.RoleAssignmentPhaseDELETED && r.Status.ErrorMessage == "" && r.Spec == nil && r.Metadata == nil // Ensure only status is updated
 				})).Return(nil).Once()
@@ -97,20 +100,64 @@ func TestRoleAssignmentController_Sync_Deletion(t *testing.T) {
 		{
 			name:  "Deleting (Phase=PENDING) -> Set Phase=DELETED (Update failed)",
 			input: testRoleAssignmentWithDeletionTimestamp(raID, v1.RoleAssignmentPhasePENDING),
-			mockSetup: func(s *storagemocks.MockStorage) {
+			mockSetup: func(s *storagemocks.MockStorage, gw *gatewaymocks.MockGateway) {
+				s.On("ListApiKey", mock.Anything).Return([]v1.ApiKey{}, nil).Once()
 				s.On("UpdateRoleAssignment", raIDStr, mock.MatchedBy(func(r *v1.RoleAssignment) bool {
 					return r.Status != nil && r.Status.Phase == v1.RoleAssignmentPhaseDELETED
 				})).Return(assert.AnError).Once()
 			},
 			wantErr: true,
 		},
+		{
+			name:  "Deleting (Phase=CREATED) -> Revoke access from the user's api keys, then set Phase=DELETED",
+			input: testRoleAssignmentWithDeletionTimestamp(raID, v1.RoleAssignmentPhaseCREATED),
+			mockSetup: func(s *storagemocks.MockStorage, gw *gatewaymocks.MockGateway) {
+				s.On("ListApiKey", mock.MatchedBy(func(option storage.ListOption) bool {
+					return len(option.Filters) == 1 &&
+						option.Filters[0].Column == "user_id" &&
+						option.Filters[0].Value == "user-1"
+				})).Return([]v1.ApiKey{
+					{ID: "key-active", Metadata: &v1.Metadata{Name: "active"}, Status: &v1.ApiKeyStatus{SkValue: "sk_active"}},
+					{ID: "key-deleting", Metadata: &v1.Metadata{Name: "deleting", DeletionTimestamp: "2026-01-01T00:00:00Z"},
+						Status: &v1.ApiKeyStatus{SkValue: "sk_deleting"}},
+					{ID: "key-pending", Metadata: &v1.Metadata{Name: "pending"}},
+				}, nil).Once()
+				gw.On("SyncAPIKey", mock.MatchedBy(func(k *v1.ApiKey) bool {
+					return k.ID == "key-active"
+				})).Return(nil).Once()
+				s.On("UpdateRoleAssignment", raIDStr, mock.MatchedBy(func(r *v1.RoleAssignment) bool {
+					return r.Status != nil && r.Status.Phase == v1.RoleAssignmentPhaseDELETED
+				})).Return(nil).Once()
+			},
+			wantErr: false,
+		},
+		{
+			name:  "Deleting (Phase=CREATED) -> Error (api key gateway sync failed, not marked DELETED)",
+			input: testRoleAssignmentWithDeletionTimestamp(raID, v1.RoleAssignmentPhaseCREATED),
+			mockSetup: func(s *storagemocks.MockStorage, gw *gatewaymocks.MockGateway) {
+				s.On("ListApiKey", mock.Anything).Return([]v1.ApiKey{
+					{ID: "key-active", Metadata: &v1.Metadata{Name: "active"}, Status: &v1.ApiKeyStatus{SkValue: "sk_active"}},
+				}, nil).Once()
+				gw.On("SyncAPIKey", mock.Anything).Return(assert.AnError).Once()
+			},
+			wantErr: true,
+		},
+		{
+			name:  "Deleting (Phase=CREATED) -> Error (list api keys failed, not marked DELETED)",
+			input: testRoleAssignmentWithDeletionTimestamp(raID, v1.RoleAssignmentPhaseCREATED),
+			mockSetup: func(s *storagemocks.MockStorage, gw *gatewaymocks.MockGateway) {
+				s.On("ListApiKey", mock.Anything).Return(nil, assert.AnError).Once()
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockStorage := &storagemocks.MockStorage{}
-			tt.mockSetup(mockStorage)
-			c := newTestRoleAssignmentController(mockStorage)
+			mockGateway := &gatewaymocks.MockGateway{}
+			tt.mockSetup(mockStorage, mockGateway)
+			c := newTestRoleAssignmentController(mockStorage, mockGateway)
 
 			err := c.sync(tt.input) // Test sync directly.
 
@@ -120,6 +167,7 @@ func TestRoleAssignmentController_Sync_Deletion(t *testing.T) {
 				assert.NoError(t, err)
 			}
 			mockStorage.AssertExpectations(t)
+			mockGateway.AssertExpectations(t)
 		})
 	}
 }
@@ -186,7 +234,7 @@ func TestRoleAssignmentController_Sync_CreateOrUpdate(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			mockStorage := &storagemocks.MockStorage{}
 			tt.mockSetup(mockStorage)
-			c := newTestRoleAssignmentController(mockStorage)
+			c := newTestRoleAssignmentController(mockStorage, &gatewaymocks.MockGateway{})
 
 			err := c.sync(tt.input) // Test sync directly.
 
@@ -283,7 +331,7 @@ func TestRoleAssignmentController_Reconcile(t *testing.T) {
 			}
 
 			// Create controller using the helper.
-			c := newTestRoleAssignmentController(mockStorage)
+			c := newTestRoleAssignmentController(mockStorage, &gatewaymocks.MockGateway{})
 
 			// Override syncHandler if the test case requires the mock.
 			if tt.useMockSync {
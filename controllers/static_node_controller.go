@@ -140,6 +140,12 @@ func (c *StaticNodeController) reconcileNormal(
 		return reconcileErr
 	}
 
+	// Image garbage collection is best effort and never blocks the node.
+	result.ImageGC, err = reconciler.ReconcileImageGC(ctx, node, dockerRuntime)
+	if err != nil {
+		klog.Warningf("Failed to collect images of static node %s: %v", node.Metadata.WorkspaceName(), err)
+	}
+
 	result.Accelerator, result.Allocations, reconcileErr = reconciler.ReconcileNodeDeviceSnapshot(
 		ctx,
 		node,
@@ -156,21 +162,22 @@ func (c *StaticNodeController) registryAuth(node *v1.StaticNode) (*staticnode.Re
 		return nil, nil
 	}
 
-	clusters, err := c.storage.ListCluster(storage.ListOption{Filters: []storage.Filter{
-		{Column: "metadata->name", Operator: "eq", Value: fmt.Sprintf(`"%s"`, node.Spec.Cluster)},
-		{Column: "metadata->workspace", Operator: "eq", Value: fmt.Sprintf(`"%s"`, node.Metadata.Workspace)},
-	}})
+	cluster, err := c.storage.GetClusterByName(node.Metadata.Workspace, node.Spec.Cluster)
+	if errors.Is(err, storage.ErrResourceNotFound) {
+		return nil, nil
+	}
+
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to list parent cluster")
+		return nil, errors.Wrap(err, "failed to get parent cluster")
 	}
 
-	if len(clusters) == 0 || clusters[0].Spec == nil || clusters[0].Spec.ImageRegistry == "" {
+	if cluster.Spec == nil || cluster.Spec.ImageRegistry == "" {
 		return nil, nil
 	}
 
 	imageRegistries, err := c.storage.ListImageRegistry(storage.ListOption{Filters: []storage.Filter{
-		{Column: "metadata->name", Operator: "eq", Value: fmt.Sprintf(`"%s"`, clusters[0].Spec.ImageRegistry)},
-		{Column: "metadata->workspace", Operator: "eq", Value: fmt.Sprintf(`"%s"`, clusters[0].Metadata.Workspace)},
+		{Column: "metadata->name", Operator: "eq", Value: fmt.Sprintf(`"%s"`, cluster.Spec.ImageRegistry)},
+		{Column: "metadata->workspace", Operator: "eq", Value: fmt.Sprintf(`"%s"`, cluster.Metadata.Workspace)},
 	}})
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to list image registry")
@@ -182,7 +189,7 @@ func (c *StaticNodeController) registryAuth(node *v1.StaticNode) (*staticnode.Re
 
 	imageRegistry := &imageRegistries[0]
 	if imageRegistry.Status == nil || imageRegistry.Status.Phase != v1.ImageRegistryPhaseCONNECTED {
-		return nil, errors.New("image registry " + clusters[0].Spec.ImageRegistry + " not ready")
+		return nil, errors.New("image registry " + cluster.Spec.ImageRegistry + " not ready")
 	}
 
 	username, password, err := util.GetImageRegistryAuthInfo(imageRegistry)
@@ -72,7 +72,8 @@ func TestFindStaticNodeUsesWorkspaceNameFilters(t *testing.T) {
 		{Column: "metadata->>name", Operator: "eq", Value: "head-0"},
 	}
 	mockStorage.On("ListStaticNode", storage.ListOption{
-		Filters: expectedFilters,
+		Filters:        expectedFilters,
+		IncludeDeleted: true,
 	}).Return([]v1.StaticNode{*controllerStaticNode()}, nil)
 
 	node, found, err := findStaticNode(mockStorage, "default", "head-0")
@@ -447,7 +448,16 @@ func newMockStaticNodeStorage(
 	t.Helper()
 
 	mockStorage := storagemocks.NewMockStorage(t)
-	mockStorage.On("ListCluster", mock.Anything).Return(clusters, nil).Maybe()
+	mockStorage.On("GetClusterByName", mock.Anything, mock.Anything).
+		Return(func(workspace, name string) (*v1.Cluster, error) {
+			for i := range clusters {
+				if clusters[i].Metadata.Workspace == workspace && clusters[i].Metadata.Name == name {
+					return &clusters[i], nil
+				}
+			}
+
+			return nil, storage.ErrResourceNotFound
+		}).Maybe()
 	mockStorage.On("ListImageRegistry", mock.Anything).Return(imageRegistries, nil).Maybe()
 	mockStorage.On("UpdateStaticNode", mock.Anything, mock.Anything).
 		Run(func(args mock.Arguments) {
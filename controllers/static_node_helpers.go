@@ -175,6 +175,7 @@ func findStaticNode(store storage.Storage, workspace, name string) (*v1.StaticNo
 			{Column: "metadata->>workspace", Operator: "eq", Value: workspace},
 			{Column: "metadata->>name", Operator: "eq", Value: name},
 		},
+		IncludeDeleted: true,
 	})
 	if err != nil {
 		return nil, false, err
@@ -182,6 +182,7 @@ func (c *WorkspaceController) createOrUpdateEngine(engine *v1.Engine) error {
 				Value:    strconv.Quote(engine.Metadata.Workspace),
 			},
 		},
+		IncludeDeleted: true,
 	})
 
 	if err != nil {
@@ -231,6 +232,7 @@ func (c *WorkspaceController) DeleteWorkspaceEngine(workspace *v1.Workspace) err
 				Value:    strconv.Quote(workspace.Metadata.Name),
 			},
 		},
+		IncludeDeleted: true,
 	})
 	if err != nil {
 		return errors.Wrapf(err, "failed to list engines for workspace %s", workspace.Metadata.Name)
@@ -0,0 +1,73 @@
+package dbtest
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+)
+
+const auditLogTestUserID = "00000000-0000-0000-0000-0000000000a1"
+
+func recordAuditLog(tx *sql.Tx, role string) (int64, error) {
+	if _, err := tx.Exec("SET LOCAL ROLE " + role); err != nil {
+		return 0, err
+	}
+
+	var id int64
+
+	err := tx.QueryRow(`SELECT api.record_audit_log('endpoint:exec', 'default', 'Endpoint', 'chat', '{}'::jsonb)`).Scan(&id)
+
+	return id, err
+}
+
+func TestRecordAuditLog_RecordsTheUserOfTheJWT(t *testing.T) {
+	db := GetTestDB(t)
+
+	var id int64
+
+	err := execWithContext(t, db, []SetContextFunc{setUserContext(auditLogTestUserID)}, func(tx *sql.Tx) error {
+		var err error
+
+		id, err = recordAuditLog(tx, "service_role")
+
+		return err
+	})
+	if err != nil {
+		t.Fatalf("failed to record audit log: %v", err)
+	}
+
+	var userID string
+	if err := db.QueryRow(`SELECT user_id FROM api.audit_logs WHERE id = $1`, id).Scan(&userID); err != nil {
+		t.Fatalf("failed to read audit log: %v", err)
+	}
+
+	if userID != auditLogTestUserID {
+		t.Errorf("expected audit log of user %s, got %s", auditLogTestUserID, userID)
+	}
+}
+
+func TestRecordAuditLog_RequiresAUser(t *testing.T) {
+	db := GetTestDB(t)
+
+	err := execWithContext(t, db, nil, func(tx *sql.Tx) error {
+		_, err := recordAuditLog(tx, "service_role")
+		return err
+	})
+	if err == nil || !strings.Contains(err.Error(), "require an authenticated user") {
+		t.Errorf("expected the audit log without user to be refused, got %v", err)
+	}
+}
+
+func TestRecordAuditLog_NotExecutableByAPIUsers(t *testing.T) {
+	db := GetTestDB(t)
+
+	for _, role := range []string{"api_user", "anonymous"} {
+		err := execWithContext(t, db, []SetContextFunc{setUserContext(auditLogTestUserID)}, func(tx *sql.Tx) error {
+			_, err := recordAuditLog(tx, role)
+			return err
+		})
+		if err == nil || !strings.Contains(err.Error(), "permission denied") {
+			t.Errorf("expected %s to be denied to record audit logs, got %v", role, err)
+		}
+	}
+}
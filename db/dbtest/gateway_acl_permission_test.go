@@ -67,3 +67,80 @@ func TestGatewayACLPermissionsResolveAndRevoke(t *testing.T) {
 
 	_, _ = db.ExecContext(ctx, `DELETE FROM api.roles WHERE id = $1`, roleID)
 }
+
+func TestGatewayACLPermissionsRevokedBySoftDeletedAssignment(t *testing.T) {
+	db := GetTestDB(t)
+	ctx := context.Background()
+
+	var userID string
+	var roleID int
+	var assignmentID int
+
+	err := execWithContext(t, db, nil, func(tx *sql.Tx) error {
+		userID = createUserWithPermissions(t, tx, "gateway-acl-revoked-user", "gateway-acl-revoked-user@example.com", []string{
+			"endpoint:read",
+		})
+
+		if err := tx.QueryRowContext(ctx, `
+			SELECT id FROM api.roles
+			WHERE (metadata).name = 'gateway-acl-revoked-user-role'
+		`).Scan(&roleID); err != nil {
+			return err
+		}
+
+		return tx.QueryRowContext(ctx, `
+			SELECT id FROM api.role_assignments
+			WHERE (spec).user_id = $1::uuid
+			AND (spec).role = 'gateway-acl-revoked-user-role'
+		`, userID).Scan(&assignmentID)
+	})
+	if err != nil {
+		t.Fatalf("failed to seed ACL permission user: %v", err)
+	}
+
+	hasPermission := func() bool {
+		t.Helper()
+
+		var allowed bool
+		err := execWithContext(t, db, []SetContextFunc{setUserContext(userID)}, func(tx *sql.Tx) error {
+			return tx.QueryRowContext(ctx, `
+				SELECT api.has_permission($1::uuid, 'endpoint:read'::api.permission_action, 'workspace-a')
+			`, userID).Scan(&allowed)
+		})
+		if err != nil {
+			t.Fatalf("failed to call has_permission: %v", err)
+		}
+
+		return allowed
+	}
+
+	if !hasPermission() {
+		t.Fatal("has_permission(endpoint:read) = false before revoking the role assignment")
+	}
+
+	// Revoking soft-deletes the assignment; the row stays until the controller removes it.
+	_, err = db.ExecContext(ctx, `
+		UPDATE api.role_assignments
+		SET metadata = ROW(
+			(metadata).name,
+			(metadata).display_name,
+			(metadata).workspace,
+			now(),
+			(metadata).creation_timestamp,
+			(metadata).update_timestamp,
+			(metadata).labels,
+			(metadata).annotations
+		)::api.metadata
+		WHERE id = $1
+	`, assignmentID)
+	if err != nil {
+		t.Fatalf("failed to soft delete role assignment: %v", err)
+	}
+
+	if hasPermission() {
+		t.Fatal("has_permission(endpoint:read) = true after revoking the role assignment")
+	}
+
+	_, _ = db.ExecContext(ctx, `DELETE FROM api.role_assignments WHERE id = $1`, assignmentID)
+	_, _ = db.ExecContext(ctx, `DELETE FROM api.roles WHERE id = $1`, roleID)
+}
@@ -0,0 +1 @@
+ALTER TYPE api.endpoint_status ADD ATTRIBUTE model_download_progress json;
@@ -0,0 +1 @@
+ALTER TYPE api.endpoint_spec ADD ATTRIBUTE models json;
@@ -0,0 +1 @@
+ALTER TYPE api.cluster_spec DROP ATTRIBUTE IF EXISTS reconcile_interval_seconds;
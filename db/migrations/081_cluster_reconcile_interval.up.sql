@@ -0,0 +1 @@
+ALTER TYPE api.cluster_spec ADD ATTRIBUTE reconcile_interval_seconds integer;
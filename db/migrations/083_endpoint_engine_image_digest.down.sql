@@ -0,0 +1,2 @@
+ALTER TYPE api.endpoint_status DROP ATTRIBUTE IF EXISTS engine_image_digest;
+ALTER TYPE api.endpoint_engine_spec DROP ATTRIBUTE IF EXISTS pin_image_digest;
@@ -0,0 +1,2 @@
+ALTER TYPE api.endpoint_engine_spec ADD ATTRIBUTE pin_image_digest BOOLEAN;
+ALTER TYPE api.endpoint_status ADD ATTRIBUTE engine_image_digest TEXT;
@@ -0,0 +1,3 @@
+DROP FUNCTION IF EXISTS api.get_endpoint_costs(DATE, DATE, TEXT, TEXT);
+DROP FUNCTION IF EXISTS api.record_endpoint_costs(JSONB);
+DROP TABLE IF EXISTS api.endpoint_costs;
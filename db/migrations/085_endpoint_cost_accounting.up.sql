@@ -0,0 +1,114 @@
+-- Endpoint cost accounting (showback/chargeback).
+--
+-- neutree-core samples running endpoints every minute and reports, per
+-- endpoint, the replica-hours and accelerator-hours consumed since the previous
+-- sample together with their cost under the configured per-accelerator-type
+-- rates. The samples are accumulated into one row per (day, workspace,
+-- endpoint) so cost can be reported for any date range.
+CREATE TABLE api.endpoint_costs (
+    usage_date DATE NOT NULL,
+    workspace TEXT NOT NULL,
+    endpoint_name TEXT NOT NULL,
+    accelerator_type TEXT,
+    replica_hours DOUBLE PRECISION NOT NULL DEFAULT 0,
+    accelerator_hours DOUBLE PRECISION NOT NULL DEFAULT 0,
+    cost DOUBLE PRECISION NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    PRIMARY KEY (usage_date, workspace, endpoint_name)
+);
+
+CREATE INDEX endpoint_costs_workspace_idx ON api.endpoint_costs(workspace);
+
+ALTER TABLE api.endpoint_costs ENABLE ROW LEVEL SECURITY;
+
+-- Written by the service role through record_endpoint_costs and read through
+-- get_endpoint_costs only.
+CREATE POLICY "No direct access to endpoint costs" ON api.endpoint_costs
+    USING (false);
+
+-- Accumulates endpoint usage samples. p_usages is a JSON array of
+-- {usage_date, workspace, endpoint_name, accelerator_type, replica_hours,
+-- accelerator_hours, cost} objects; returns the number of samples recorded.
+CREATE FUNCTION api.record_endpoint_costs(
+    p_usages JSONB
+)
+RETURNS INTEGER
+SECURITY DEFINER
+AS $$
+DECLARE
+    v_count INTEGER := 0;
+    v_usage JSONB;
+BEGIN
+    FOR v_usage IN SELECT * FROM jsonb_array_elements(p_usages)
+    LOOP
+        INSERT INTO api.endpoint_costs AS c (
+            usage_date,
+            workspace,
+            endpoint_name,
+            accelerator_type,
+            replica_hours,
+            accelerator_hours,
+            cost,
+            updated_at
+        ) VALUES (
+            (v_usage->>'usage_date')::date,
+            v_usage->>'workspace',
+            v_usage->>'endpoint_name',
+            NULLIF(v_usage->>'accelerator_type', ''),
+            COALESCE((v_usage->>'replica_hours')::double precision, 0),
+            COALESCE((v_usage->>'accelerator_hours')::double precision, 0),
+            COALESCE((v_usage->>'cost')::double precision, 0),
+            now()
+        )
+        ON CONFLICT (usage_date, workspace, endpoint_name) DO UPDATE SET
+            accelerator_type = EXCLUDED.accelerator_type,
+            replica_hours = c.replica_hours + EXCLUDED.replica_hours,
+            accelerator_hours = c.accelerator_hours + EXCLUDED.accelerator_hours,
+            cost = c.cost + EXCLUDED.cost,
+            updated_at = now();
+
+        v_count := v_count + 1;
+    END LOOP;
+
+    RETURN v_count;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Reports accumulated endpoint cost per endpoint over a date range. Rows are
+-- scoped by workspace:usage-read, the same permission as usage analytics
+-- (workspace-scoped in EE, global in CE).
+CREATE FUNCTION api.get_endpoint_costs(
+    p_start_date DATE,
+    p_end_date DATE,
+    p_workspace TEXT DEFAULT NULL,
+    p_endpoint_name TEXT DEFAULT NULL
+)
+RETURNS TABLE (
+    workspace TEXT,
+    endpoint_name TEXT,
+    accelerator_type TEXT,
+    replica_hours DOUBLE PRECISION,
+    accelerator_hours DOUBLE PRECISION,
+    cost DOUBLE PRECISION
+)
+SECURITY DEFINER
+AS $$
+BEGIN
+    RETURN QUERY
+    SELECT
+        c.workspace,
+        c.endpoint_name,
+        max(c.accelerator_type),
+        sum(c.replica_hours),
+        sum(c.accelerator_hours),
+        sum(c.cost)
+    FROM api.endpoint_costs c
+    WHERE
+        c.usage_date BETWEEN p_start_date AND p_end_date
+        AND (p_workspace IS NULL OR c.workspace = p_workspace)
+        AND (p_endpoint_name IS NULL OR c.endpoint_name = p_endpoint_name)
+        AND api.has_permission(auth.uid(), 'workspace:usage-read', c.workspace)
+    GROUP BY c.workspace, c.endpoint_name
+    ORDER BY c.workspace, c.endpoint_name;
+END;
+$$ LANGUAGE plpgsql;
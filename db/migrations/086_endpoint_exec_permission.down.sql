@@ -0,0 +1,5 @@
+DROP FUNCTION IF EXISTS api.record_audit_log(UUID, TEXT, TEXT, TEXT, TEXT, JSONB);
+DROP TABLE IF EXISTS api.audit_logs;
+
+-- PostgreSQL does not support removing enum values.
+-- The endpoint:exec value will remain.
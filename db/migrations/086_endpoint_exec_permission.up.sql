@@ -0,0 +1,64 @@
+-- Debug exec into endpoint replicas (/api/v1/endpoints/:workspace/:name/exec/...).
+--
+-- endpoint:exec opens a command runner inside a running replica, so it is kept
+-- out of the workspace-user preset: only admin holds it by default (granted in
+-- 087, since a newly added enum value cannot be referenced in the same
+-- transaction that adds it), and a debug role is created by assigning it.
+ALTER TYPE api.permission_action ADD VALUE IF NOT EXISTS 'endpoint:exec';
+
+-- Audit trail of privileged operations performed through the API server.
+CREATE TABLE api.audit_logs (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    user_id UUID NOT NULL,
+    action TEXT NOT NULL,
+    workspace TEXT,
+    resource_kind TEXT NOT NULL,
+    resource_name TEXT NOT NULL,
+    details JSONB NOT NULL DEFAULT '{}'::jsonb
+);
+
+CREATE INDEX audit_logs_created_at_idx ON api.audit_logs(created_at);
+CREATE INDEX audit_logs_workspace_idx ON api.audit_logs(workspace);
+
+ALTER TABLE api.audit_logs ENABLE ROW LEVEL SECURITY;
+
+-- Written by the API server through record_audit_log only.
+CREATE POLICY "No direct access to audit logs" ON api.audit_logs
+    USING (false);
+
+-- Appends an audit log entry and returns its id.
+CREATE FUNCTION api.record_audit_log(
+    p_user_id UUID,
+    p_action TEXT,
+    p_workspace TEXT,
+    p_resource_kind TEXT,
+    p_resource_name TEXT,
+    p_details JSONB DEFAULT '{}'::jsonb
+)
+RETURNS BIGINT
+SECURITY DEFINER
+AS $$
+DECLARE
+    v_id BIGINT;
+BEGIN
+    INSERT INTO api.audit_logs (
+        user_id,
+        action,
+        workspace,
+        resource_kind,
+        resource_name,
+        details
+    ) VALUES (
+        p_user_id,
+        p_action,
+        NULLIF(p_workspace, ''),
+        p_resource_kind,
+        p_resource_name,
+        COALESCE(p_details, '{}'::jsonb)
+    )
+    RETURNING id INTO v_id;
+
+    RETURN v_id;
+END;
+$$ LANGUAGE plpgsql;
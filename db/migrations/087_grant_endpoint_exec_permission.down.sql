@@ -0,0 +1,3 @@
+-- No-op. admin retains endpoint:exec because enum values cannot be removed and
+-- update_admin_permissions() always re-aggregates the full enum. workspace-user
+-- was never granted this permission, so there is nothing to revert here.
@@ -0,0 +1,4 @@
+-- Grant endpoint:exec (added in 086) to admin. Separate migration because newly
+-- added enum values cannot be referenced in the same transaction that adds them.
+-- workspace-user deliberately does not get it.
+SELECT api.update_admin_permissions();
@@ -0,0 +1 @@
+ALTER TYPE api.engine_version DROP ATTRIBUTE IF EXISTS health_check_paths;
@@ -0,0 +1 @@
+ALTER TYPE api.engine_version ADD ATTRIBUTE health_check_paths json;
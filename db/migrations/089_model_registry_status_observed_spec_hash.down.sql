@@ -0,0 +1 @@
+ALTER TYPE api.model_registry_status DROP ATTRIBUTE IF EXISTS observed_spec_hash;
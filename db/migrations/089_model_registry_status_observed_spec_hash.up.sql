@@ -0,0 +1 @@
+ALTER TYPE api.model_registry_status ADD ATTRIBUTE observed_spec_hash TEXT;
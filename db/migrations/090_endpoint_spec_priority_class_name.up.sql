@@ -0,0 +1 @@
+ALTER TYPE api.endpoint_spec ADD ATTRIBUTE priority_class_name TEXT;
@@ -0,0 +1 @@
+ALTER TYPE api.endpoint_spec DROP ATTRIBUTE IF EXISTS request_logging;
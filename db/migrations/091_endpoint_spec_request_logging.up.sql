@@ -0,0 +1 @@
+ALTER TYPE api.endpoint_spec ADD ATTRIBUTE request_logging json;
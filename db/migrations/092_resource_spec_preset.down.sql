@@ -0,0 +1 @@
+ALTER TYPE api.resource_spec DROP ATTRIBUTE IF EXISTS preset;
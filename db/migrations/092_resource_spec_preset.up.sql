@@ -0,0 +1 @@
+ALTER TYPE api.resource_spec ADD ATTRIBUTE preset TEXT;
@@ -0,0 +1,24 @@
+-- Role assignments pending deletion no longer grant permissions, so revoking an
+-- assignment takes effect immediately instead of once the controller removes it.
+CREATE OR REPLACE FUNCTION api.has_permission(
+    user_uuid UUID,
+    required_permission api.permission_action,
+    workspace TEXT DEFAULT NULL
+)
+RETURNS BOOLEAN AS $$
+DECLARE
+    has_perm BOOLEAN;
+BEGIN
+    SELECT EXISTS (
+        SELECT 1
+        FROM api.role_assignments ra
+        JOIN api.roles r ON (ra.spec).role = (r.metadata).name
+        WHERE (ra.spec).user_id = user_uuid
+        AND (ra.spec).global = TRUE
+        AND (ra.metadata).deletion_timestamp IS NULL
+        AND required_permission = ANY((r.spec).permissions)
+    ) INTO has_perm;
+
+    RETURN has_perm;
+END;
+$$ LANGUAGE plpgsql SECURITY DEFINER;
@@ -0,0 +1,3 @@
+ALTER TYPE api.static_node_status DROP ATTRIBUTE IF EXISTS image_gc;
+ALTER TYPE api.static_node_spec DROP ATTRIBUTE IF EXISTS image_gc;
+ALTER TYPE api.static_node_cluster_spec DROP ATTRIBUTE IF EXISTS image_gc;
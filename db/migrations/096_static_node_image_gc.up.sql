@@ -0,0 +1,3 @@
+ALTER TYPE api.static_node_cluster_spec ADD ATTRIBUTE image_gc JSONB;
+ALTER TYPE api.static_node_spec ADD ATTRIBUTE image_gc JSONB;
+ALTER TYPE api.static_node_status ADD ATTRIBUTE image_gc JSONB;
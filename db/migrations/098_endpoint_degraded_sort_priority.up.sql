@@ -0,0 +1,17 @@
+ALTER TABLE api.endpoints DROP COLUMN IF EXISTS status_sort_priority;
+
+ALTER TABLE api.endpoints ADD COLUMN status_sort_priority integer
+  GENERATED ALWAYS AS (
+    CASE (status).phase
+      WHEN 'Running'          THEN 0
+      WHEN 'Degraded'         THEN 1
+      WHEN 'Deploying'        THEN 2
+      WHEN 'ModelDownloading' THEN 3
+      WHEN 'Pending'          THEN 4
+      WHEN 'Paused'           THEN 5
+      WHEN 'Failed'           THEN 6
+      WHEN 'Deleting'         THEN 7
+      WHEN 'Deleted'          THEN 8
+      ELSE 9
+    END
+  ) STORED;
@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS validate_endpoint_max_ongoing_requests_on_endpoints ON api.endpoints;
+DROP FUNCTION IF EXISTS api.validate_endpoint_max_ongoing_requests();
+
+ALTER TYPE api.endpoint_spec DROP ATTRIBUTE IF EXISTS max_ongoing_requests;
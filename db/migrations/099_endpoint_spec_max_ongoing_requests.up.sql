@@ -0,0 +1,18 @@
+ALTER TYPE api.endpoint_spec ADD ATTRIBUTE max_ongoing_requests INTEGER;
+
+CREATE OR REPLACE FUNCTION api.validate_endpoint_max_ongoing_requests()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF (NEW.spec).max_ongoing_requests IS NOT NULL AND (NEW.spec).max_ongoing_requests <= 0 THEN
+        RAISE sqlstate 'PGRST'
+            USING message = '{"code": "10132","message": "spec.max_ongoing_requests must be positive","hint": "Provide a positive number of ongoing requests per replica"}',
+            detail = '{"status": 400, "headers": {"X-Powered-By": "Neutree"}}';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER validate_endpoint_max_ongoing_requests_on_endpoints
+    BEFORE INSERT OR UPDATE ON api.endpoints
+    FOR EACH ROW
+    EXECUTE FUNCTION api.validate_endpoint_max_ongoing_requests();
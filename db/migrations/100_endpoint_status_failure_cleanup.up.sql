@@ -0,0 +1 @@
+ALTER TYPE api.endpoint_status ADD ATTRIBUTE failure_cleanup_spec_hash TEXT;
@@ -0,0 +1 @@
+ALTER TYPE api.model_spec DROP ATTRIBUTE IF EXISTS weight;
@@ -0,0 +1,2 @@
+-- Routing weight of a version of a model served by a multi-model endpoint.
+ALTER TYPE api.model_spec ADD ATTRIBUTE weight INTEGER;
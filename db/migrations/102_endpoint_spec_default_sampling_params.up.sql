@@ -0,0 +1,2 @@
+-- Sampling parameters applied to the requests of the endpoint omitting them.
+ALTER TYPE api.endpoint_spec ADD ATTRIBUTE default_sampling_params json;
@@ -0,0 +1,2 @@
+ALTER TYPE api.endpoint_status DROP ATTRIBUTE IF EXISTS canary;
+ALTER TYPE api.endpoint_spec DROP ATTRIBUTE IF EXISTS canary;
@@ -0,0 +1,3 @@
+-- Canary rollout of the updates of the endpoint.
+ALTER TYPE api.endpoint_spec ADD ATTRIBUTE canary json;
+ALTER TYPE api.endpoint_status ADD ATTRIBUTE canary json;
@@ -0,0 +1 @@
+ALTER TYPE api.endpoint_status DROP ATTRIBUTE IF EXISTS replicas;
@@ -0,0 +1,2 @@
+-- Health of each replica of the endpoint.
+ALTER TYPE api.endpoint_status ADD ATTRIBUTE replicas json;
@@ -0,0 +1,2 @@
+ALTER TYPE api.cluster_status DROP ATTRIBUTE IF EXISTS reconcile_paused;
+ALTER TYPE api.cluster_spec DROP ATTRIBUTE IF EXISTS maintenance;
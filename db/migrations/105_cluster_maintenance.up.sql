@@ -0,0 +1,3 @@
+-- Pause the reconcile of a cluster while it is paused or in a maintenance window.
+ALTER TYPE api.cluster_spec ADD ATTRIBUTE maintenance json;
+ALTER TYPE api.cluster_status ADD ATTRIBUTE reconcile_paused boolean;
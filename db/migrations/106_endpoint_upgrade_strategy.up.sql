@@ -0,0 +1,2 @@
+-- Strategy replacing the replicas of the endpoint on updates.
+ALTER TYPE api.endpoint_spec ADD ATTRIBUTE upgrade_strategy json;
@@ -0,0 +1,2 @@
+-- Standby endpoint taking over the requests of the endpoint.
+ALTER TYPE api.endpoint_spec ADD ATTRIBUTE failover json;
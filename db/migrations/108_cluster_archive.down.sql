@@ -0,0 +1 @@
+ALTER TYPE api.cluster_status DROP ATTRIBUTE IF EXISTS archive_timestamp;
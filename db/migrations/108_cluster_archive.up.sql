@@ -0,0 +1,2 @@
+-- Archive of clusters kept for audit and recovery instead of being deleted.
+ALTER TYPE api.cluster_status ADD ATTRIBUTE archive_timestamp TEXT;
@@ -0,0 +1,28 @@
+DROP TRIGGER IF EXISTS bump_endpoints_resource_version ON api.endpoints;
+ALTER TABLE api.endpoints DROP COLUMN IF EXISTS resource_version;
+
+DROP TRIGGER IF EXISTS bump_clusters_resource_version ON api.clusters;
+ALTER TABLE api.clusters DROP COLUMN IF EXISTS resource_version;
+
+DROP TRIGGER IF EXISTS bump_engines_resource_version ON api.engines;
+ALTER TABLE api.engines DROP COLUMN IF EXISTS resource_version;
+
+DROP TRIGGER IF EXISTS bump_external_endpoints_resource_version ON api.external_endpoints;
+ALTER TABLE api.external_endpoints DROP COLUMN IF EXISTS resource_version;
+
+DROP TRIGGER IF EXISTS bump_image_registries_resource_version ON api.image_registries;
+ALTER TABLE api.image_registries DROP COLUMN IF EXISTS resource_version;
+
+DROP TRIGGER IF EXISTS bump_model_registries_resource_version ON api.model_registries;
+ALTER TABLE api.model_registries DROP COLUMN IF EXISTS resource_version;
+
+DROP TRIGGER IF EXISTS bump_model_catalogs_resource_version ON api.model_catalogs;
+ALTER TABLE api.model_catalogs DROP COLUMN IF EXISTS resource_version;
+
+DROP TRIGGER IF EXISTS bump_roles_resource_version ON api.roles;
+ALTER TABLE api.roles DROP COLUMN IF EXISTS resource_version;
+
+DROP TRIGGER IF EXISTS bump_api_keys_resource_version ON api.api_keys;
+ALTER TABLE api.api_keys DROP COLUMN IF EXISTS resource_version;
+
+DROP FUNCTION IF EXISTS bump_resource_version();
@@ -0,0 +1,76 @@
+-- Version of the spec of the resources, the If-Match precondition of their updates. Unlike the
+-- metadata update timestamp, it is not bumped by the status writes of the controllers.
+CREATE OR REPLACE FUNCTION bump_resource_version()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF to_jsonb(NEW.spec) IS DISTINCT FROM to_jsonb(OLD.spec) THEN
+        NEW.resource_version := OLD.resource_version + 1;
+    ELSE
+        NEW.resource_version := OLD.resource_version;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE api.endpoints ADD COLUMN resource_version BIGINT NOT NULL DEFAULT 1;
+
+CREATE TRIGGER bump_endpoints_resource_version
+    BEFORE UPDATE ON api.endpoints
+    FOR EACH ROW
+    EXECUTE FUNCTION bump_resource_version();
+
+ALTER TABLE api.clusters ADD COLUMN resource_version BIGINT NOT NULL DEFAULT 1;
+
+CREATE TRIGGER bump_clusters_resource_version
+    BEFORE UPDATE ON api.clusters
+    FOR EACH ROW
+    EXECUTE FUNCTION bump_resource_version();
+
+ALTER TABLE api.engines ADD COLUMN resource_version BIGINT NOT NULL DEFAULT 1;
+
+CREATE TRIGGER bump_engines_resource_version
+    BEFORE UPDATE ON api.engines
+    FOR EACH ROW
+    EXECUTE FUNCTION bump_resource_version();
+
+ALTER TABLE api.external_endpoints ADD COLUMN resource_version BIGINT NOT NULL DEFAULT 1;
+
+CREATE TRIGGER bump_external_endpoints_resource_version
+    BEFORE UPDATE ON api.external_endpoints
+    FOR EACH ROW
+    EXECUTE FUNCTION bump_resource_version();
+
+ALTER TABLE api.image_registries ADD COLUMN resource_version BIGINT NOT NULL DEFAULT 1;
+
+CREATE TRIGGER bump_image_registries_resource_version
+    BEFORE UPDATE ON api.image_registries
+    FOR EACH ROW
+    EXECUTE FUNCTION bump_resource_version();
+
+ALTER TABLE api.model_registries ADD COLUMN resource_version BIGINT NOT NULL DEFAULT 1;
+
+CREATE TRIGGER bump_model_registries_resource_version
+    BEFORE UPDATE ON api.model_registries
+    FOR EACH ROW
+    EXECUTE FUNCTION bump_resource_version();
+
+ALTER TABLE api.model_catalogs ADD COLUMN resource_version BIGINT NOT NULL DEFAULT 1;
+
+CREATE TRIGGER bump_model_catalogs_resource_version
+    BEFORE UPDATE ON api.model_catalogs
+    FOR EACH ROW
+    EXECUTE FUNCTION bump_resource_version();
+
+ALTER TABLE api.roles ADD COLUMN resource_version BIGINT NOT NULL DEFAULT 1;
+
+CREATE TRIGGER bump_roles_resource_version
+    BEFORE UPDATE ON api.roles
+    FOR EACH ROW
+    EXECUTE FUNCTION bump_resource_version();
+
+ALTER TABLE api.api_keys ADD COLUMN resource_version BIGINT NOT NULL DEFAULT 1;
+
+CREATE TRIGGER bump_api_keys_resource_version
+    BEFORE UPDATE ON api.api_keys
+    FOR EACH ROW
+    EXECUTE FUNCTION bump_resource_version();
@@ -0,0 +1,37 @@
+DROP FUNCTION IF EXISTS api.record_audit_log(TEXT, TEXT, TEXT, TEXT, JSONB);
+
+-- Appends an audit log entry and returns its id.
+CREATE FUNCTION api.record_audit_log(
+    p_user_id UUID,
+    p_action TEXT,
+    p_workspace TEXT,
+    p_resource_kind TEXT,
+    p_resource_name TEXT,
+    p_details JSONB DEFAULT '{}'::jsonb
+)
+RETURNS BIGINT
+SECURITY DEFINER
+AS $$
+DECLARE
+    v_id BIGINT;
+BEGIN
+    INSERT INTO api.audit_logs (
+        user_id,
+        action,
+        workspace,
+        resource_kind,
+        resource_name,
+        details
+    ) VALUES (
+        p_user_id,
+        p_action,
+        NULLIF(p_workspace, ''),
+        p_resource_kind,
+        p_resource_name,
+        COALESCE(p_details, '{}'::jsonb)
+    )
+    RETURNING id INTO v_id;
+
+    RETURN v_id;
+END;
+$$ LANGUAGE plpgsql;
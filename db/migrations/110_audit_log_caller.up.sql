@@ -0,0 +1,48 @@
+-- record_audit_log took the user of the entry from its caller, and being SECURITY DEFINER
+-- and executable by every role, let any API user write entries in the name of another
+-- user. The user is now the subject of the JWT of the call, and only the API server, which
+-- calls it with the service role on behalf of the user, may execute it.
+DROP FUNCTION IF EXISTS api.record_audit_log(UUID, TEXT, TEXT, TEXT, TEXT, JSONB);
+
+-- Appends an audit log entry for the user of the request and returns its id.
+CREATE FUNCTION api.record_audit_log(
+    p_action TEXT,
+    p_workspace TEXT,
+    p_resource_kind TEXT,
+    p_resource_name TEXT,
+    p_details JSONB DEFAULT '{}'::jsonb
+)
+RETURNS BIGINT
+SECURITY DEFINER
+AS $$
+DECLARE
+    v_user_id UUID := auth.uid();
+    v_id BIGINT;
+BEGIN
+    IF v_user_id IS NULL THEN
+        RAISE EXCEPTION 'audit log entries require an authenticated user';
+    END IF;
+
+    INSERT INTO api.audit_logs (
+        user_id,
+        action,
+        workspace,
+        resource_kind,
+        resource_name,
+        details
+    ) VALUES (
+        v_user_id,
+        p_action,
+        NULLIF(p_workspace, ''),
+        p_resource_kind,
+        p_resource_name,
+        COALESCE(p_details, '{}'::jsonb)
+    )
+    RETURNING id INTO v_id;
+
+    RETURN v_id;
+END;
+$$ LANGUAGE plpgsql;
+
+REVOKE EXECUTE ON FUNCTION api.record_audit_log(TEXT, TEXT, TEXT, TEXT, JSONB) FROM PUBLIC, api_user, anonymous;
+GRANT EXECUTE ON FUNCTION api.record_audit_log(TEXT, TEXT, TEXT, TEXT, JSONB) TO service_role;
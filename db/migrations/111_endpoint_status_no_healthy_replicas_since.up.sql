@@ -0,0 +1 @@
+ALTER TYPE api.endpoint_status ADD ATTRIBUTE no_healthy_replicas_since TEXT;
@@ -0,0 +1,2 @@
+DROP FUNCTION IF EXISTS api.restore_cluster(INTEGER);
+DROP FUNCTION IF EXISTS api.archive_cluster(INTEGER, TEXT);
@@ -0,0 +1,32 @@
+-- Archiving and restoring a cluster only write the archive timestamp and the phase of its
+-- status. Writing the whole status instead would overwrite a status written by the cluster
+-- controller in the meantime, and could be overwritten by it in turn. They are called by
+-- the API server with the service role once it checked the permissions of the user.
+
+-- Archives the cluster, unless it is archived already.
+CREATE FUNCTION api.archive_cluster(p_id INTEGER, p_archive_timestamp TEXT)
+RETURNS VOID
+AS $$
+    UPDATE api.clusters
+    SET status.archive_timestamp = p_archive_timestamp,
+        status.phase = 'Archived',
+        status.last_transition_time = p_archive_timestamp::timestamptz
+    WHERE id = p_id AND (status).archive_timestamp IS NULL;
+$$ LANGUAGE sql;
+
+-- Restores an archived cluster. It is pending until the cluster controller reconciles it
+-- again.
+CREATE FUNCTION api.restore_cluster(p_id INTEGER)
+RETURNS VOID
+AS $$
+    UPDATE api.clusters
+    SET status.archive_timestamp = NULL,
+        status.phase = 'Pending',
+        status.last_transition_time = now()
+    WHERE id = p_id AND (status).archive_timestamp IS NOT NULL;
+$$ LANGUAGE sql;
+
+REVOKE EXECUTE ON FUNCTION api.archive_cluster(INTEGER, TEXT) FROM PUBLIC, api_user, anonymous;
+REVOKE EXECUTE ON FUNCTION api.restore_cluster(INTEGER) FROM PUBLIC, api_user, anonymous;
+GRANT EXECUTE ON FUNCTION api.archive_cluster(INTEGER, TEXT) TO service_role;
+GRANT EXECUTE ON FUNCTION api.restore_cluster(INTEGER) TO service_role;
@@ -0,0 +1,18 @@
+ALTER TABLE api.endpoints DROP COLUMN IF EXISTS status_sort_priority;
+
+ALTER TABLE api.endpoints ADD COLUMN status_sort_priority integer
+  GENERATED ALWAYS AS (
+    CASE (status).phase
+      WHEN 'Running'          THEN 0
+      WHEN 'Degraded'         THEN 1
+      WHEN 'Deploying'        THEN 2
+      WHEN 'ModelDownloading' THEN 3
+      WHEN 'Pending'          THEN 4
+      WHEN 'Unknown'          THEN 5
+      WHEN 'Paused'           THEN 6
+      WHEN 'Failed'           THEN 7
+      WHEN 'Deleting'         THEN 8
+      WHEN 'Deleted'          THEN 9
+      ELSE 10
+    END
+  ) STORED;
@@ -35,6 +35,9 @@ local AIGatewayHandler = {
 
 local EMPTY = {}
 
+-- Header a client passes the engine-side credential in when forwarding is enabled.
+local ENGINE_AUTH_HEADER = "X-Engine-Authorization"
+
 -- cjson decodes JSON null as cjson.null (a userdata sentinel).
 -- Indexing userdata causes "attempt to index a userdata value".
 -- Use this helper wherever a decoded field might be null.
@@ -179,18 +182,343 @@ local function fail(code, msg)
     return kong.response.exit(code, msg and { error = { message = msg } } or nil)
 end
 
-local function resolve_upstream(conf, model)
+-- OpenAI-compatible request validation.
+--
+-- When validate_request is enabled on the route, request bodies are checked
+-- against the fields engines expect before they are forwarded, so a malformed
+-- request fails fast with a 400 listing every invalid field instead of a
+-- confusing engine-side error. Only well-known fields are checked; unknown
+-- fields pass through untouched since engines accept their own extensions
+-- (e.g. vLLM sampling params).
+local MESSAGE_FIELDS = {
+    { name = "role", types = { "string" }, required = true,
+      enum = { "system", "developer", "user", "assistant", "tool", "function" } },
+    { name = "content", types = { "string", "array" } },
+    { name = "name", types = { "string" } },
+    { name = "tool_call_id", types = { "string" } },
+    { name = "tool_calls", types = { "array" } },
+}
+
+local TOOL_FIELDS = {
+    { name = "type", types = { "string" }, required = true, enum = { "function" } },
+    { name = "function", types = { "object" }, required = true, fields = {
+        { name = "name", types = { "string" }, required = true, non_empty = true },
+        { name = "description", types = { "string" } },
+        { name = "parameters", types = { "object" } },
+    } },
+}
+
+local REQUEST_SCHEMAS = {
+    ["/v1/chat/completions"] = {
+        { name = "model", types = { "string" } },
+        { name = "messages", types = { "array" }, required = true, non_empty = true,
+          items = { types = { "object" }, fields = MESSAGE_FIELDS } },
+        { name = "stream", types = { "boolean" } },
+        { name = "temperature", types = { "number" }, min = 0, max = 2 },
+        { name = "top_p", types = { "number" }, min = 0, max = 1 },
+        { name = "n", types = { "integer" }, min = 1 },
+        { name = "max_tokens", types = { "integer" }, min = 1 },
+        { name = "max_completion_tokens", types = { "integer" }, min = 1 },
+        { name = "presence_penalty", types = { "number" }, min = -2, max = 2 },
+        { name = "frequency_penalty", types = { "number" }, min = -2, max = 2 },
+        { name = "seed", types = { "integer" } },
+        { name = "stop", types = { "string", "array" }, items = { types = { "string" } } },
+        { name = "tools", types = { "array" }, items = { types = { "object" }, fields = TOOL_FIELDS } },
+        { name = "tool_choice", types = { "string", "object" } },
+        { name = "response_format", types = { "object" }, fields = {
+            { name = "type", types = { "string" }, required = true },
+        } },
+    },
+    ["/v1/embeddings"] = {
+        { name = "model", types = { "string" } },
+        { name = "input", types = { "string", "array" }, required = true, non_empty = true },
+        { name = "encoding_format", types = { "string" }, enum = { "float", "base64" } },
+        { name = "dimensions", types = { "integer" }, min = 1 },
+    },
+    ["/v1/rerank"] = {
+        { name = "model", types = { "string" } },
+        { name = "query", types = { "string" }, required = true },
+        { name = "documents", types = { "array" }, required = true, non_empty = true },
+        { name = "top_n", types = { "integer" }, min = 1 },
+    },
+}
+
+-- Decoded arrays carry array_mt (see the JSON policy above); any other table
+-- is a JSON object.
+local function json_type(v)
+    if v == cjson.null then
+        return "null"
+    end
+    if type(v) == "table" then
+        if getmetatable(v) == cjson.array_mt or #v > 0 then
+            return "array"
+        end
+        return "object"
+    end
+    return type(v)
+end
+
+local function matches_type(v, expected)
+    local actual = json_type(v)
+    if expected == "integer" then
+        return actual == "number" and v == math.floor(v)
+    end
+    return actual == expected
+end
+
+local function add_field_error(errors, field, message)
+    errors[#errors + 1] = { field = field, message = message }
+end
+
+local check_object
+
+local function check_field(errors, field, value, spec)
+    if value == nil or value == cjson.null then
+        if spec.required then
+            add_field_error(errors, field, "is required")
+        end
+        return
+    end
+
+    local type_ok = false
+    for _, expected in ipairs(spec.types) do
+        if matches_type(value, expected) then
+            type_ok = true
+            break
+        end
+    end
+    if not type_ok then
+        add_field_error(errors, field, "must be of type " .. table.concat(spec.types, " or "))
+        return
+    end
+
+    if type(value) == "number" then
+        if spec.min and value < spec.min then
+            add_field_error(errors, field, "must be greater than or equal to " .. spec.min)
+        elseif spec.max and value > spec.max then
+            add_field_error(errors, field, "must be less than or equal to " .. spec.max)
+        end
+    end
+
+    if spec.enum then
+        local allowed = false
+        for _, v in ipairs(spec.enum) do
+            if value == v then
+                allowed = true
+                break
+            end
+        end
+        if not allowed then
+            add_field_error(errors, field, "must be one of " .. table.concat(spec.enum, ", "))
+        end
+    end
+
+    if spec.non_empty and (value == "" or (json_type(value) == "array" and #value == 0)) then
+        add_field_error(errors, field, "must not be empty")
+    end
+
+    if spec.items and json_type(value) == "array" then
+        for i, item in ipairs(value) do
+            check_field(errors, field .. "[" .. (i - 1) .. "]", item, spec.items)
+        end
+    end
+
+    if spec.fields and json_type(value) == "object" then
+        check_object(errors, field .. ".", value, spec.fields)
+    end
+end
+
+check_object = function(errors, prefix, object, fields)
+    for _, spec in ipairs(fields) do
+        check_field(errors, prefix .. spec.name, object[spec.name], spec)
+    end
+end
+
+-- validate_request returns the field errors of an OpenAI-compatible request
+-- body for the given route type, or nil when the body is valid or the route
+-- type has no known schema.
+local function validate_request(route_type, body)
+    local fields = REQUEST_SCHEMAS[route_type]
+    if not fields then
+        return nil
+    end
+
+    local errors = {}
+    if json_type(body) ~= "object" then
+        add_field_error(errors, "body", "must be a JSON object")
+        return errors
+    end
+
+    check_object(errors, "", body, fields)
+    if #errors == 0 then
+        return nil
+    end
+    return errors
+end
+
+local function fail_validation(errors)
+    local messages = {}
+    for i, e in ipairs(errors) do
+        messages[i] = e.field .. " " .. e.message
+    end
+    return kong.response.exit(400, {
+        error = {
+            message = "invalid request: " .. table.concat(messages, "; "),
+            type = "invalid_request_error",
+            param = errors[1].field,
+            details = json_array(errors),
+        },
+    })
+end
+
+-- Sampling parameters filled from the endpoint defaults when a completion
+-- request omits them.
+local SAMPLING_DEFAULT_FIELDS = { "temperature", "top_p", "max_tokens" }
+
+local SAMPLING_ROUTE_TYPES = {
+    ["/v1/chat/completions"] = true,
+    ["/v1/completions"] = true,
+}
+
+local function is_set(v)
+    return v ~= nil and v ~= cjson.null
+end
+
+-- apply_sampling_defaults sets the default sampling parameters a completion
+-- request omits, the values sent by the client win. max_completion_tokens
+-- supersedes max_tokens in chat requests, so it counts as sending max_tokens.
+-- Returns whether the request changed.
+local function apply_sampling_defaults(route_type, body, defaults)
+    if not SAMPLING_ROUTE_TYPES[route_type] or not is_table(body) or not is_table(defaults) then
+        return false
+    end
+
+    local changed = false
+    for _, field in ipairs(SAMPLING_DEFAULT_FIELDS) do
+        local sent = is_set(body[field])
+        if field == "max_tokens" and is_set(body.max_completion_tokens) then
+            sent = true
+        end
+        if not sent and type(defaults[field]) == "number" then
+            body[field] = defaults[field]
+            changed = true
+        end
+    end
+
+    return changed
+end
+
+-- Per-worker round-robin position of each load-balanced model.
+local balance_counters = {}
+
+-- Per-worker position of each model spread over its versions by weight.
+local weight_counters = {}
+
+-- Whether the upstream serves the model at the version a request pinned with
+-- the model-version header. Upstreams without a version serve any version.
+local function serves_model(entry, model, version)
+    if not entry.model_mapping[model] then
+        return false
+    end
+
+    if type(version) ~= "string" or version == "" or type(entry.model_version) ~= "string" then
+        return true
+    end
+
+    return entry.model_version == version
+end
+
+local function upstream_weight(entry)
+    if type(entry.weight) == "number" and entry.weight > 0 then
+        return entry.weight
+    end
+
+    return 0
+end
+
+-- Spread the requests over the versions of a model by the weights of their
+-- upstreams, e.g. to shift traffic to a canary version. Without weights the
+-- requests go to the first upstream, i.e. the primary version.
+local function pick_weighted(model, matches)
+    local total = 0
+    for _, entry in ipairs(matches) do
+        total = total + upstream_weight(entry)
+    end
+
+    if total == 0 then
+        return matches[1]
+    end
+
+    local counter = (weight_counters[model] or 0) + 1
+    weight_counters[model] = counter
+
+    local slot = (counter - 1) % total
+    for _, entry in ipairs(matches) do
+        local weight = upstream_weight(entry)
+        if slot < weight then
+            return entry
+        end
+        slot = slot - weight
+    end
+end
+
+local function resolve_upstream(conf, model, version)
     if not conf.upstreams then
         return nil
     end
 
+    if not conf.load_balance then
+        local matches = {}
+        for _, entry in ipairs(conf.upstreams) do
+            if serves_model(entry, model, version) then
+                matches[#matches + 1] = entry
+            end
+        end
+
+        if #matches == 0 then
+            return nil
+        end
+
+        if type(version) == "string" and version ~= "" then
+            return matches[1]
+        end
+
+        return pick_weighted(model, matches)
+    end
+
+    -- Spread the requests of a model over every upstream serving it, e.g. the
+    -- endpoints of a capability route.
+    local matches = {}
     for _, entry in ipairs(conf.upstreams) do
-        if entry.model_mapping[model] then
-            return entry
+        if serves_model(entry, model, version) then
+            matches[#matches + 1] = entry
         end
     end
 
-    return nil
+    if #matches == 0 then
+        return nil
+    end
+
+    local counter = (balance_counters[model] or 0) + 1
+    balance_counters[model] = counter
+
+    return matches[(counter - 1) % #matches + 1]
+end
+
+local function version_suffix(version)
+    if type(version) ~= "string" or version == "" then
+        return ""
+    end
+
+    return " version " .. version
+end
+
+-- A load-balanced route reaches several endpoints, so the endpoint enforced by
+-- the neutree-ai-access allowlist is the one the request was dispatched to.
+local function stash_upstream_endpoint(entry)
+    if type(entry.endpoint_name) == "string" and entry.endpoint_name ~= "" then
+        kong.ctx.shared.neutree_endpoint_name = entry.endpoint_name
+    end
 end
 
 local function set_upstream_target(entry)
@@ -262,14 +590,19 @@ local function maybe_return_model_list(conf, suffix)
     if is_models_path(suffix) and kong.request.get_method() == "GET" then
         kong.ctx.plugin.skip = true
         local models = json_array()
+        -- load-balanced upstreams repeat the same model.
+        local seen = {}
         for _, entry in ipairs(conf.upstreams) do
             for model_name, _ in pairs(entry.model_mapping) do
-                models[#models + 1] = {
-                    id = model_name,
-                    object = "model",
-                    created = 0,
-                    owned_by = "external-endpoint",
-                }
+                if not seen[model_name] then
+                    seen[model_name] = true
+                    models[#models + 1] = {
+                        id = model_name,
+                        object = "model",
+                        created = 0,
+                        owned_by = "external-endpoint",
+                    }
+                end
             end
         end
         kong.response.exit(200, {
@@ -282,14 +615,19 @@ local function maybe_return_model_list(conf, suffix)
     if is_anthropic_models_path(suffix) and kong.request.get_method() == "GET" then
         kong.ctx.plugin.skip = true
         local models = json_array()
+        -- load-balanced upstreams repeat the same model.
+        local seen = {}
         for _, entry in ipairs(conf.upstreams) do
             for model_name, _ in pairs(entry.model_mapping) do
-                models[#models + 1] = {
-                    id = model_name,
-                    type = "model",
-                    display_name = model_name,
-                    created_at = "2025-01-01T00:00:00Z",
-                }
+                if not seen[model_name] then
+                    seen[model_name] = true
+                    models[#models + 1] = {
+                        id = model_name,
+                        type = "model",
+                        display_name = model_name,
+                        created_at = "2025-01-01T00:00:00Z",
+                    }
+                end
             end
         end
         kong.response.exit(200, {
@@ -1210,6 +1548,24 @@ local function handle_anthropic_stream_body()
     ngx.arg[1] = table.concat(output_parts)
 end
 
+-- Engines may enforce their own API key. The neutree API key has already been
+-- checked by key-auth at this point, so the Authorization header sent to the
+-- engine is either the configured engine credential or, with forwarding, the
+-- one the client passed in X-Engine-Authorization.
+local function apply_engine_auth(conf)
+    local engine_auth = kong.request.get_header(ENGINE_AUTH_HEADER)
+    kong.service.request.clear_header(ENGINE_AUTH_HEADER)
+
+    if type(conf.engine_auth_header) == "string" and conf.engine_auth_header ~= "" then
+        kong.service.request.set_header("Authorization", conf.engine_auth_header)
+        return
+    end
+
+    if conf.forward_engine_auth == true and engine_auth and engine_auth ~= "" then
+        kong.service.request.set_header("Authorization", engine_auth)
+    end
+end
+
 function AIGatewayHandler:access(conf)
     local request_path = kong.request.get_path()
     local suffix = extract_suffix(request_path, conf.route_prefix or "")
@@ -1226,6 +1582,8 @@ function AIGatewayHandler:access(conf)
         kong.ctx.shared.neutree_endpoint_name = conf.endpoint_name
     end
 
+    apply_engine_auth(conf)
+
     if maybe_return_model_list(conf, suffix) then
         return
     end
@@ -1254,6 +1612,7 @@ function AIGatewayHandler:access(conf)
         end
 
         local openai_req = convert_request(anthropic_req)
+        apply_sampling_defaults("/v1/chat/completions", openai_req, conf.default_sampling_params)
         kong.ctx.plugin.anthropic_mode = true
         kong.ctx.plugin.request_model = anthropic_req.model
         -- Expose the client-facing model to later consumer plugins (e.g.
@@ -1265,10 +1624,12 @@ function AIGatewayHandler:access(conf)
         kong.ctx.plugin.route_type = "/v1/chat/completions"
 
         if conf.upstreams then
-            local matched_entry = resolve_upstream(conf, openai_req.model)
+            local model_version = kong.request.get_header("model-version")
+            local matched_entry = resolve_upstream(conf, openai_req.model, model_version)
             if not matched_entry then
-                return anthropic_error(400, "invalid_request_error", "No upstream configured for model: " .. tostring(openai_req.model))
+                return anthropic_error(400, "invalid_request_error", "No upstream configured for model: " .. tostring(openai_req.model) .. version_suffix(model_version))
             end
+            stash_upstream_endpoint(matched_entry)
 
             local _, route_err = set_upstream_target(matched_entry)
             if route_err then
@@ -1367,6 +1728,15 @@ function AIGatewayHandler:access(conf)
         return fail(400, "request body is not json format")
     end
 
+    if conf.validate_request then
+        local validation_errors = validate_request(route_type, ai_request)
+        if validation_errors then
+            return fail_validation(validation_errors)
+        end
+    end
+
+    local sampling_defaults_applied = apply_sampling_defaults(route_type, ai_request, conf.default_sampling_params)
+
     kong.ctx.plugin.request_model = ai_request.model
     -- Expose the client-facing model to later consumer plugins (e.g.
     -- neutree-ai-access allowlist) BEFORE the request body is rewritten for
@@ -1379,10 +1749,12 @@ function AIGatewayHandler:access(conf)
             return fail(400, "missing 'model' field in request body")
         end
 
-        local matched_entry = resolve_upstream(conf, ai_request.model)
+        local model_version = kong.request.get_header("model-version")
+        local matched_entry = resolve_upstream(conf, ai_request.model, model_version)
         if not matched_entry then
-            return fail(400, "No upstream configured for model: " .. ai_request.model)
+            return fail(400, "No upstream configured for model: " .. ai_request.model .. version_suffix(model_version))
         end
+        stash_upstream_endpoint(matched_entry)
 
         local _, route_err = set_upstream_target(matched_entry)
         if route_err then
@@ -1405,6 +1777,11 @@ function AIGatewayHandler:access(conf)
         if new_body then
             kong.service.request.set_raw_body(new_body)
         end
+    elseif sampling_defaults_applied then
+        local new_body = cjson.encode(ai_request)
+        if new_body then
+            kong.service.request.set_raw_body(new_body)
+        end
     end
 
     if not ai_request.stream then
@@ -1478,6 +1855,19 @@ function AIGatewayHandler:body_filter(conf)
     handle_openai_stream_response(ngx.arg[1], ngx.arg[2])
 end
 
+-- Whether the request/response bodies of a request go into its AI trace. Failed
+-- requests (5xx, including 504 on upstream timeouts, or no status at all) are
+-- always captured; successful ones are sampled at sample_rate.
+local function should_capture_bodies(sample_rate, status, random)
+    if sample_rate == nil or sample_rate >= 1 then
+        return true
+    end
+    if status == nil or status >= 500 then
+        return true
+    end
+    return (random or math.random)() < sample_rate
+end
+
 function AIGatewayHandler:log(conf)
     if kong.ctx.plugin.skip then
         return
@@ -1496,8 +1886,15 @@ function AIGatewayHandler:log(conf)
             response_body = body
         end
     end
-    if kong.ctx.plugin.request_body_raw ~= nil then
-        kong.log.set_serialize_value("ai.trace.request_body", kong.ctx.plugin.request_body_raw)
+    local request_body = kong.ctx.plugin.request_body_raw
+    if not should_capture_bodies(conf.trace_body_sample_rate, kong.response.get_status()) then
+        -- Keep the trace record (the log pipeline only ships records carrying a body
+        -- field) but drop the bodies of sampled-out successful requests.
+        request_body, response_body = "", ""
+        kong.log.set_serialize_value("ai.trace.body_sampled_out", true)
+    end
+    if request_body ~= nil then
+        kong.log.set_serialize_value("ai.trace.request_body", request_body)
     end
     if response_body ~= nil then
         kong.log.set_serialize_value("ai.trace.response_body", response_body)
@@ -1554,6 +1951,11 @@ AIGatewayHandler._TEST = {
     convert_response = convert_response,
     make_message_start = make_message_start,
     anthropic_usage_from_openai = anthropic_usage_from_openai,
+    validate_request = validate_request,
+    should_capture_bodies = should_capture_bodies,
+    apply_engine_auth = apply_engine_auth,
+    resolve_upstream = resolve_upstream,
+    apply_sampling_defaults = apply_sampling_defaults,
 }
 
 return AIGatewayHandler
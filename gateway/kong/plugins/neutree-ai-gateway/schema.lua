@@ -50,6 +50,31 @@ local upstream_entry = {
         default = false,
       },
     },
+    {
+      -- Version of the model the upstream serves, picked by requests pinning
+      -- it with the model-version header.
+      model_version = {
+        type = "string",
+        required = false,
+      },
+    },
+    {
+      -- Share of the requests not pinning a version the upstream receives
+      -- among the upstreams serving the model.
+      weight = {
+        type = "integer",
+        required = false,
+        between = { 0, 1000000 },
+      },
+    },
+    {
+      -- Endpoint the upstream serves, stashed for the neutree-ai-access
+      -- allowlist when the upstream is picked among several endpoints.
+      endpoint_name = {
+        type = "string",
+        required = false,
+      },
+    },
   },
 }
 
@@ -87,6 +112,64 @@ local schema = {
               required = false,
             },
           },
+          {
+            -- Validate OpenAI-compatible request bodies before forwarding them
+            -- and reject malformed ones with field-level errors.
+            validate_request = {
+              type = "boolean",
+              required = false,
+              default = false,
+            },
+          },
+          {
+            -- Sampling parameters set on the completion requests omitting them.
+            -- Parameters sent by the client take precedence.
+            default_sampling_params = {
+              type = "record",
+              required = false,
+              fields = {
+                { temperature = { type = "number", required = false, between = { 0, 2 } } },
+                { top_p = { type = "number", required = false, between = { 0, 1 } } },
+                { max_tokens = { type = "integer", required = false, gt = 0 } },
+              },
+            },
+          },
+          {
+            -- Fraction of successful requests whose bodies are captured in the AI
+            -- trace. Bodies of 5xx responses, including timeouts, are always captured.
+            trace_body_sample_rate = {
+              type = "number",
+              required = false,
+              default = 1,
+              between = { 0, 1 },
+            },
+          },
+          {
+            -- Authorization header sent to the engine of an internal endpoint
+            -- which enforces its own API key, e.g. "Bearer <engine key>".
+            engine_auth_header = {
+              type = "string",
+              required = false,
+            },
+          },
+          {
+            -- Forward the X-Engine-Authorization header of the client to the
+            -- engine as its Authorization header.
+            forward_engine_auth = {
+              type = "boolean",
+              required = false,
+              default = false,
+            },
+          },
+          {
+            -- Round-robin the requests of a model over every upstream serving
+            -- it instead of always picking the first one.
+            load_balance = {
+              type = "boolean",
+              required = false,
+              default = false,
+            },
+          },
           {
             upstreams = {
               type = "array",
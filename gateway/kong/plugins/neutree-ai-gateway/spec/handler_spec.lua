@@ -163,3 +163,249 @@ describe("make_message_start()", function()
         assert.are.equal("[]", cjson.encode(ev.message.content))
     end)
 end)
+
+describe("validate_request()", function()
+    local function validate(route_type, body)
+        return T.validate_request(route_type, cjson.decode(body))
+    end
+
+    local function fields(errors)
+        local out = {}
+        for i, e in ipairs(errors) do
+            out[i] = e.field
+        end
+        return out
+    end
+
+    it("passes a valid chat completion request", function()
+        assert.is_nil(validate("/v1/chat/completions", [[{"model":"m","stream":true,]]
+            .. [["temperature":0.7,"max_tokens":128,"stop":["X"],]]
+            .. [["messages":[{"role":"system","content":"be brief"},]]
+            .. [[{"role":"user","content":[{"type":"text","text":"hi"}]},]]
+            .. [[{"role":"assistant","content":null,"tool_calls":[]}],]]
+            .. [["tools":[{"type":"function","function":{"name":"f","parameters":{}}}],]]
+            .. [["top_k":20}]]))
+    end)
+
+    it("passes valid embedding and rerank requests", function()
+        assert.is_nil(validate("/v1/embeddings", [[{"model":"m","input":["a","b"]}]]))
+        assert.is_nil(validate("/v1/rerank", [[{"query":"q","documents":["a"],"top_n":1}]]))
+    end)
+
+    it("reports every invalid field of a chat completion request", function()
+        local errors = validate("/v1/chat/completions", [[{"model":1,"temperature":3,]]
+            .. [["max_tokens":1.5,"messages":[{"content":"hi"},{"role":"bot","content":{}}]}]])
+        assert.are.same({
+            "model",
+            "messages[0].role",
+            "messages[1].role",
+            "messages[1].content",
+            "temperature",
+            "max_tokens",
+        }, fields(errors))
+        assert.are.equal("must be of type string", errors[1].message)
+        assert.are.equal("is required", errors[2].message)
+        assert.are.equal("must be less than or equal to 2", errors[5].message)
+    end)
+
+    it("requires non-empty messages and input", function()
+        assert.are.same({ "messages" }, fields(validate("/v1/chat/completions", [[{"messages":[]}]])))
+        assert.are.same({ "messages" }, fields(validate("/v1/chat/completions", [[{"model":"m"}]])))
+        assert.are.same({ "input" }, fields(validate("/v1/embeddings", [[{"input":""}]])))
+    end)
+
+    it("rejects a body that is not a JSON object", function()
+        assert.are.same({ "body" }, fields(validate("/v1/chat/completions", [[["hi"]]])))
+    end)
+
+    it("skips route types without a schema", function()
+        assert.is_nil(validate("/v1/unknown", [[{"anything":1}]]))
+    end)
+end)
+
+describe("apply_sampling_defaults()", function()
+    local defaults = { temperature = 0.2, top_p = 0.9, max_tokens = 512 }
+
+    it("fills the sampling parameters the request omits", function()
+        local body = cjson.decode([[{"model":"m","messages":[{"role":"user","content":"hi"}]}]])
+        assert.is_true(T.apply_sampling_defaults("/v1/chat/completions", body, defaults))
+        assert.are.equal(0.2, body.temperature)
+        assert.are.equal(0.9, body.top_p)
+        assert.are.equal(512, body.max_tokens)
+    end)
+
+    it("keeps the sampling parameters sent by the client", function()
+        local body = cjson.decode([[{"model":"m","temperature":1,"max_completion_tokens":64,"top_p":null}]])
+        assert.is_true(T.apply_sampling_defaults("/v1/chat/completions", body, defaults))
+        assert.are.equal(1, body.temperature)
+        assert.are.equal(0.9, body.top_p)
+        -- max_completion_tokens supersedes max_tokens.
+        assert.is_nil(body.max_tokens)
+
+        body = cjson.decode([[{"model":"m","temperature":0,"top_p":1,"max_tokens":8}]])
+        assert.is_false(T.apply_sampling_defaults("/v1/completions", body, defaults))
+        assert.are.same({ model = "m", temperature = 0, top_p = 1, max_tokens = 8 }, body)
+    end)
+
+    it("leaves requests of other routes and endpoints without defaults", function()
+        local body = cjson.decode([[{"model":"m","input":"hi"}]])
+        assert.is_false(T.apply_sampling_defaults("/v1/embeddings", body, defaults))
+        assert.is_nil(body.temperature)
+        assert.is_false(T.apply_sampling_defaults("/v1/chat/completions", body, nil))
+        assert.is_false(T.apply_sampling_defaults("/v1/chat/completions", body, cjson.null))
+    end)
+end)
+
+describe("should_capture_bodies()", function()
+    local function sample(rate, status, n)
+        local i = 0
+        local function random()
+            i = i + 1
+            return (i - 0.5) / n
+        end
+        local captured = 0
+        for _ = 1, n do
+            if T.should_capture_bodies(rate, status, random) then
+                captured = captured + 1
+            end
+        end
+        return captured
+    end
+
+    it("captures every request without sampling", function()
+        assert.are.equal(100, sample(nil, 200, 100))
+        assert.are.equal(100, sample(1, 200, 100))
+    end)
+
+    it("always captures errored and timed out requests", function()
+        assert.are.equal(100, sample(0, 500, 100))
+        assert.are.equal(100, sample(0.05, 502, 100))
+        assert.are.equal(100, sample(0.05, 504, 100))
+        assert.are.equal(100, sample(0, nil, 100))
+    end)
+
+    it("samples requests without a 5xx status at the configured rate", function()
+        assert.are.equal(5, sample(0.05, 200, 100))
+        assert.are.equal(50, sample(0.5, 200, 100))
+        assert.are.equal(5, sample(0.05, 404, 100))
+        assert.are.equal(0, sample(0, 200, 100))
+    end)
+end)
+
+describe("apply_engine_auth()", function()
+    local saved_request, saved_service
+    local upstream_headers
+
+    local function run(conf, client_headers)
+        upstream_headers = {}
+        for k, v in pairs(client_headers or {}) do
+            upstream_headers[k] = v
+        end
+        kong.request = {
+            get_header = function(name) return (client_headers or {})[name] end,
+        }
+        kong.service = {
+            request = {
+                set_header = function(name, value) upstream_headers[name] = value end,
+                clear_header = function(name) upstream_headers[name] = nil end,
+            },
+        }
+        T.apply_engine_auth(conf)
+        return upstream_headers
+    end
+
+    before_each(function()
+        saved_request, saved_service = kong.request, kong.service
+    end)
+
+    after_each(function()
+        kong.request, kong.service = saved_request, saved_service
+    end)
+
+    it("injects the configured engine credential", function()
+        local headers = run({ engine_auth_header = "Bearer engine-key" },
+            { ["X-Engine-Authorization"] = "Bearer client-key" })
+        assert.are.equal("Bearer engine-key", headers["Authorization"])
+        assert.is_nil(headers["X-Engine-Authorization"])
+    end)
+
+    it("forwards the engine credential of the client", function()
+        local headers = run({ forward_engine_auth = true },
+            { ["X-Engine-Authorization"] = "Bearer client-key" })
+        assert.are.equal("Bearer client-key", headers["Authorization"])
+        assert.is_nil(headers["X-Engine-Authorization"])
+    end)
+
+    it("does not pass engine credentials without engine auth", function()
+        local headers = run({}, { ["X-Engine-Authorization"] = "Bearer client-key" })
+        assert.is_nil(headers["Authorization"])
+        assert.is_nil(headers["X-Engine-Authorization"])
+    end)
+end)
+
+describe("resolve_upstream()", function()
+    local function entry(host, mapping)
+        return { host = host, model_mapping = mapping }
+    end
+
+    it("picks the first upstream serving the model", function()
+        local conf = { upstreams = {
+            entry("a", { ["chat"] = "model-a" }),
+            entry("b", { ["chat"] = "model-b" }),
+        } }
+        assert.are.equal("a", T.resolve_upstream(conf, "chat").host)
+        assert.are.equal("a", T.resolve_upstream(conf, "chat").host)
+        assert.is_nil(T.resolve_upstream(conf, "embed"))
+    end)
+
+    it("round-robins over the upstreams serving the model when load balancing", function()
+        local conf = { load_balance = true, upstreams = {
+            entry("a", { ["lb-chat"] = "model-a" }),
+            entry("b", { ["lb-embed"] = "model-b" }),
+            entry("c", { ["lb-chat"] = "model-c" }),
+        } }
+        local picked = {}
+        for i = 1, 4 do
+            picked[i] = T.resolve_upstream(conf, "lb-chat").host
+        end
+        assert.are.same({ "a", "c", "a", "c" }, picked)
+        assert.are.equal("b", T.resolve_upstream(conf, "lb-embed").host)
+        assert.is_nil(T.resolve_upstream(conf, "lb-rerank"))
+    end)
+
+    it("picks the upstream of the version pinned by the request", function()
+        local v1 = entry("a", { ["chat"] = "chat" })
+        v1.model_version = "v1"
+        local v2 = entry("b", { ["chat"] = "chat" })
+        v2.model_version = "v2"
+        local conf = { upstreams = { v1, v2, entry("c", { ["embed"] = "embed" }) } }
+
+        assert.are.equal("a", T.resolve_upstream(conf, "chat").host)
+        assert.are.equal("a", T.resolve_upstream(conf, "chat", "").host)
+        assert.are.equal("b", T.resolve_upstream(conf, "chat", "v2").host)
+        assert.is_nil(T.resolve_upstream(conf, "chat", "v3"))
+        -- Upstreams without a version ignore the pin.
+        assert.are.equal("c", T.resolve_upstream(conf, "embed", "v2").host)
+    end)
+
+    it("spreads the requests not pinning a version over the versions by weight", function()
+        local v1 = entry("a", { ["weighted-chat"] = "weighted-chat" })
+        v1.model_version = "v1"
+        v1.weight = 3
+        local v2 = entry("b", { ["weighted-chat"] = "weighted-chat" })
+        v2.model_version = "v2"
+        v2.weight = 1
+        local v3 = entry("c", { ["weighted-chat"] = "weighted-chat" })
+        v3.model_version = "v3"
+        v3.weight = 0
+        local conf = { upstreams = { v1, v2, v3 } }
+
+        local picked = {}
+        for i = 1, 8 do
+            picked[i] = T.resolve_upstream(conf, "weighted-chat").host
+        end
+        assert.are.same({ "a", "a", "a", "b", "a", "a", "a", "b" }, picked)
+        -- Pinned requests ignore the weights.
+        assert.are.equal("c", T.resolve_upstream(conf, "weighted-chat", "v3").host)
+    end)
+end)
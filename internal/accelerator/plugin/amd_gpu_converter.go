@@ -81,5 +81,7 @@ func (c *AMDGPUConverter) ConvertToKubernetes(spec *v1.ResourceSpec) (*v1.Kubern
 		res.NodeSelector[c.nodeSelectorKey] = product
 	}
 
+	res.Tolerations = []corev1.Toleration{acceleratorTaintToleration(c.kubernetesResourceName)}
+
 	return res, nil
 }
@@ -5,6 +5,7 @@ import (
 
 	v1 "github.com/neutree-ai/neutree/api/v1"
 	"github.com/neutree-ai/neutree/internal/util"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/utils/pointer"
 )
 
@@ -36,6 +37,7 @@ func TestAMDGPU_ConvertToKubernetes(t *testing.T) {
 				NodeSelector: map[string]string{
 					AMDGPUKubernetesNodeSelectorKey: "AMD_Instinct_MI300X_OAM",
 				},
+				Tolerations: []corev1.Toleration{{Key: AMDGPUKubernetesResource.String(), Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule}},
 			},
 			wantErr: false,
 		},
@@ -71,6 +73,7 @@ func TestAMDGPU_ConvertToKubernetes(t *testing.T) {
 					AMDGPUKubernetesResource.String(): "3",
 				},
 				NodeSelector: map[string]string{},
+				Tolerations:  []corev1.Toleration{{Key: AMDGPUKubernetesResource.String(), Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule}},
 			},
 			wantErr: false,
 		},
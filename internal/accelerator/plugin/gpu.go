@@ -19,6 +19,7 @@ import (
 
 const (
 	NvidiaGPUKubernetesResource        corev1.ResourceName = "nvidia.com/gpu"
+	NvidiaGPUSharedKubernetesResource  corev1.ResourceName = "nvidia.com/gpu.shared"
 	NvidiaGPUMemoryResource            corev1.ResourceName = "nvidia.com/gpumem"
 	NvidiaGPUMemoryPercentageResource  corev1.ResourceName = "nvidia.com/gpumem-percentage"
 	NvidiaGPUCoreResource              corev1.ResourceName = "nvidia.com/gpucores"
@@ -83,6 +84,7 @@ DCGM_FI_DRIVER_VERSION, label, Driver Version.
 	NvidiaGPUVirtualizationLabelKey  string = "neutree.ai/nvidia-vgpu-enabled"
 	NvidiaGPUDiscoveryLabelKey       string = "nvidia.com/gpu.present"
 	NvidiaGPUDiscoveryLabelValue     string = "true"
+	NvidiaGPUSharingStrategyLabelKey string = "nvidia.com/gpu.sharing-strategy"
 	NvidiaGPUTopologyAwarePolicy     string = "topology-aware"
 	NvidiaGPUDefaultDeviceSplitCount int    = 100
 	NvidiaGPUOperatorDriverRoot      string = "/run/nvidia/driver"
@@ -23,6 +23,30 @@ func NewGPUConverter() *GPUConverter {
 	}
 }
 
+// NvidiaGPUResourceName returns the resource name the NVIDIA device plugin exposes the GPUs
+// under in the given mode. The sharing modes use the name the plugin renames shared GPUs to.
+func NvidiaGPUResourceName(devicePlugin v1.GPUDevicePlugin) (corev1.ResourceName, error) {
+	switch devicePlugin {
+	case "", v1.GPUDevicePluginDefault:
+		return NvidiaGPUKubernetesResource, nil
+	case v1.GPUDevicePluginTimeSlicing, v1.GPUDevicePluginMPS:
+		return NvidiaGPUSharedKubernetesResource, nil
+	default:
+		return "", fmt.Errorf("unknown GPU device plugin %q, must be one of %s, %s or %s", devicePlugin,
+			v1.GPUDevicePluginDefault, v1.GPUDevicePluginTimeSlicing, v1.GPUDevicePluginMPS)
+	}
+}
+
+// acceleratorTaintToleration tolerates the taint accelerator nodes commonly carry under the
+// resource name of the accelerator, e.g. nvidia.com/gpu=present:NoSchedule.
+func acceleratorTaintToleration(resourceName corev1.ResourceName) corev1.Toleration {
+	return corev1.Toleration{
+		Key:      resourceName.String(),
+		Operator: corev1.TolerationOpExists,
+		Effect:   corev1.TaintEffectNoSchedule,
+	}
+}
+
 // ConvertToRay converts to Ray resource configuration
 func (c *GPUConverter) ConvertToRay(spec *v1.ResourceSpec) (*v1.RayResourceSpec, error) {
 	if spec == nil {
@@ -86,6 +110,8 @@ func (c *GPUConverter) ConvertToKubernetes(spec *v1.ResourceSpec) (*v1.Kubernete
 		k8s.NodeSelector[c.nodeSelectorKey] = product
 	}
 
+	k8s.Tolerations = []corev1.Toleration{acceleratorTaintToleration(c.kubernetesResourceName)}
+
 	if spec.HasAcceleratorVirtualization() {
 		// Keep the public API in Neutree resource terms. The HAMi raw resource
 		// names are introduced only at the Kubernetes manifest boundary.
@@ -5,6 +5,7 @@ import (
 
 	v1 "github.com/neutree-ai/neutree/api/v1"
 	"github.com/neutree-ai/neutree/internal/util"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/utils/pointer"
 )
 
@@ -36,6 +37,7 @@ func TestNVIDIAGPU_ConvertToKubernetes(t *testing.T) {
 				NodeSelector: map[string]string{
 					NvidiaGPUKubernetesNodeSelectorKey: "Tesla-T4",
 				},
+				Tolerations: []corev1.Toleration{{Key: NvidiaGPUKubernetesResource.String(), Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule}},
 			},
 			wantErr: false,
 		},
@@ -99,6 +101,7 @@ func TestNVIDIAGPU_ConvertToKubernetes(t *testing.T) {
 					NvidiaGPUKubernetesResource.String(): "3",
 				},
 				NodeSelector: map[string]string{},
+				Tolerations:  []corev1.Toleration{{Key: NvidiaGPUKubernetesResource.String(), Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule}},
 			},
 			wantErr: false,
 		},
@@ -175,6 +178,7 @@ func TestNVIDIAGPU_ConvertToKubernetesWithHAMiMemoryMiB(t *testing.T) {
 		NodeSelector: map[string]string{
 			NvidiaGPUKubernetesNodeSelectorKey: "Tesla-T4",
 		},
+		Tolerations: []corev1.Toleration{{Key: NvidiaGPUKubernetesResource.String(), Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule}},
 		Annotations: map[string]string{},
 	}
 
@@ -218,6 +222,7 @@ func TestNVIDIAGPU_ConvertToKubernetesWithHAMiMemoryPercent(t *testing.T) {
 		NodeSelector: map[string]string{
 			NvidiaGPUKubernetesNodeSelectorKey: "Tesla-T4",
 		},
+		Tolerations: []corev1.Toleration{{Key: NvidiaGPUKubernetesResource.String(), Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule}},
 		Annotations: map[string]string{},
 	}
 
@@ -247,6 +252,33 @@ func TestNVIDIAGPU_ConvertToKubernetesRejectsInvalidHAMiVirtualization(t *testin
 	}
 }
 
+func TestNvidiaGPUResourceName(t *testing.T) {
+	tests := []struct {
+		devicePlugin v1.GPUDevicePlugin
+		expected     corev1.ResourceName
+	}{
+		{devicePlugin: "", expected: NvidiaGPUKubernetesResource},
+		{devicePlugin: v1.GPUDevicePluginDefault, expected: NvidiaGPUKubernetesResource},
+		{devicePlugin: v1.GPUDevicePluginTimeSlicing, expected: NvidiaGPUSharedKubernetesResource},
+		{devicePlugin: v1.GPUDevicePluginMPS, expected: NvidiaGPUSharedKubernetesResource},
+	}
+
+	for _, tt := range tests {
+		resourceName, err := NvidiaGPUResourceName(tt.devicePlugin)
+		if err != nil {
+			t.Fatalf("device plugin %q: unexpected error: %v", tt.devicePlugin, err)
+		}
+
+		if resourceName != tt.expected {
+			t.Errorf("device plugin %q: expected %s, got %s", tt.devicePlugin, tt.expected, resourceName)
+		}
+	}
+
+	if _, err := NvidiaGPUResourceName("mig"); err == nil {
+		t.Fatal("expected unknown device plugin error")
+	}
+}
+
 func TestNVIDIAGPU_ConvertToRay(t *testing.T) {
 	tests := []struct {
 		name             string
@@ -28,7 +28,12 @@ func parseStandardNvidiaKubernetesResources(
 		return nil, fmt.Errorf("resource or label is nil")
 	}
 
-	gpuQuantity, hasGPU := resources[NvidiaGPUKubernetesResource]
+	resourceName, err := nvidiaGPUNodeResourceName(resources, labels)
+	if err != nil {
+		return nil, err
+	}
+
+	gpuQuantity, hasGPU := resources[resourceName]
 	if !hasGPU {
 		return nil, nil
 	}
@@ -76,6 +81,31 @@ func parseStandardNvidiaKubernetesResources(
 	return resourceInfo, nil
 }
 
+// nvidiaGPUNodeResourceName returns the resource name the GPUs of a node are exposed under: the
+// one of the device plugin mode of the cluster config if it is set, or else the one implied by
+// the sharing strategy of the node. A device plugin sharing the GPUs without renaming them
+// keeps exposing nvidia.com/gpu, which is then used.
+func nvidiaGPUNodeResourceName(resources map[corev1.ResourceName]resource.Quantity, labels map[string]string) (corev1.ResourceName, error) {
+	if devicePlugin := labels[v1.GPUDevicePluginLabelKey]; devicePlugin != "" {
+		return NvidiaGPUResourceName(v1.GPUDevicePlugin(devicePlugin))
+	}
+
+	preferred, fallback := NvidiaGPUKubernetesResource, NvidiaGPUSharedKubernetesResource
+
+	switch v1.GPUDevicePlugin(labels[NvidiaGPUSharingStrategyLabelKey]) {
+	case v1.GPUDevicePluginTimeSlicing, v1.GPUDevicePluginMPS:
+		preferred, fallback = fallback, preferred
+	}
+
+	if _, ok := resources[preferred]; !ok {
+		if _, ok := resources[fallback]; ok {
+			return fallback, nil
+		}
+	}
+
+	return preferred, nil
+}
+
 func parseNodeMemoryMiB(value string) float64 {
 	if value == "" {
 		return 0
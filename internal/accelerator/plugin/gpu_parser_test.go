@@ -82,6 +82,77 @@ func TestNVIDIAGPU_ParseFromKubernetes(t *testing.T) {
 			expected:   nil,
 			wantErr:    false,
 		},
+		{
+			name: "Node sharing its GPUs under the renamed resource",
+			kubernetesResource: map[corev1.ResourceName]resource.Quantity{
+				NvidiaGPUSharedKubernetesResource: resource.MustParse("8"),
+			},
+			nodeLabels: map[string]string{
+				NvidiaGPUSharingStrategyLabelKey: "time-slicing",
+			},
+			expected: &v1.ResourceInfo{
+				AcceleratorGroups: map[v1.AcceleratorType]*v1.AcceleratorGroup{
+					v1.AcceleratorTypeNVIDIAGPU: {
+						Quantity: 8,
+					},
+				},
+			},
+		},
+		{
+			name: "Node sharing its GPUs without renaming the resource",
+			kubernetesResource: map[corev1.ResourceName]resource.Quantity{
+				NvidiaGPUKubernetesResource: resource.MustParse("8"),
+			},
+			nodeLabels: map[string]string{
+				NvidiaGPUSharingStrategyLabelKey: "mps",
+			},
+			expected: &v1.ResourceInfo{
+				AcceleratorGroups: map[v1.AcceleratorType]*v1.AcceleratorGroup{
+					v1.AcceleratorTypeNVIDIAGPU: {
+						Quantity: 8,
+					},
+				},
+			},
+		},
+		{
+			name: "Cluster device plugin overrides the sharing strategy",
+			kubernetesResource: map[corev1.ResourceName]resource.Quantity{
+				NvidiaGPUKubernetesResource:       resource.MustParse("2"),
+				NvidiaGPUSharedKubernetesResource: resource.MustParse("8"),
+			},
+			nodeLabels: map[string]string{
+				NvidiaGPUSharingStrategyLabelKey: "none",
+				v1.GPUDevicePluginLabelKey:       "time-slicing",
+			},
+			expected: &v1.ResourceInfo{
+				AcceleratorGroups: map[v1.AcceleratorType]*v1.AcceleratorGroup{
+					v1.AcceleratorTypeNVIDIAGPU: {
+						Quantity: 8,
+					},
+				},
+			},
+		},
+		{
+			name: "Cluster device plugin without the resource of its mode",
+			kubernetesResource: map[corev1.ResourceName]resource.Quantity{
+				NvidiaGPUSharedKubernetesResource: resource.MustParse("8"),
+			},
+			nodeLabels: map[string]string{
+				v1.GPUDevicePluginLabelKey: "default",
+			},
+			expected: nil,
+		},
+		{
+			name: "Unknown cluster device plugin",
+			kubernetesResource: map[corev1.ResourceName]resource.Quantity{
+				NvidiaGPUKubernetesResource: resource.MustParse("2"),
+			},
+			nodeLabels: map[string]string{
+				v1.GPUDevicePluginLabelKey: "vgpu",
+			},
+			expected: nil,
+			wantErr:  true,
+		},
 		{
 			name:               "Nil resource map",
 			kubernetesResource: nil,
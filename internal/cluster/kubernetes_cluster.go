@@ -2,6 +2,7 @@ package cluster
 
 import (
 	"context"
+	"time"
 
 	"github.com/pkg/errors"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -16,6 +17,7 @@ import (
 	"github.com/neutree-ai/neutree/internal/cluster/component/hami"
 	"github.com/neutree-ai/neutree/internal/cluster/component/metrics"
 	"github.com/neutree-ai/neutree/internal/cluster/component/router"
+	"github.com/neutree-ai/neutree/internal/deploy"
 	resourceview "github.com/neutree-ai/neutree/internal/resource"
 	"github.com/neutree-ai/neutree/internal/util"
 	"github.com/neutree-ai/neutree/pkg/storage"
@@ -100,6 +102,13 @@ func (c *NativeKubernetesClusterReconciler) reconcile(reconcileCtx *ReconcileCon
 		v1.NeutreeClusterWorkspaceLabelKey: reconcileCtx.Cluster.Metadata.Workspace,
 	}
 
+	// Compared before applying, the image pull secret is applied on every reconcile so that a
+	// rotated registry credential reaches the cluster.
+	rotated, err := imagePullSecretRotated(reconcileCtx.Ctx, reconcileCtx.ctrClient, imagePullSecret)
+	if err != nil {
+		return errors.Wrap(err, "failed to check image pull secret")
+	}
+
 	installObjs := []client.Object{ns, imagePullSecret}
 	for _, obj := range installObjs {
 		err = util.CreateOrPatch(reconcileCtx.Ctx, obj, reconcileCtx.ctrClient)
@@ -108,6 +117,15 @@ func (c *NativeKubernetesClusterReconciler) reconcile(reconcileCtx *ReconcileCon
 		}
 	}
 
+	if rotated && !reconcileCtx.kubernetesClusterConfig.DisableImagePullSecretRestart {
+		klog.Infof("Image registry credential of cluster %s changed, restarting pods failing to pull images",
+			reconcileCtx.Cluster.Metadata.WorkspaceName())
+
+		if err = restartImagePullFailedPods(reconcileCtx.Ctx, reconcileCtx.ctrClient, ns.Name); err != nil {
+			return errors.Wrap(err, "failed to restart pods failing to pull images")
+		}
+	}
+
 	reconcileFuncs := []func(*ReconcileContext) error{
 		c.reconcileComponents,
 		c.reconcileModelCache,
@@ -141,6 +159,9 @@ func (c *NativeKubernetesClusterReconciler) reconcile(reconcileCtx *ReconcileCon
 		cluster.Status.Version = cluster.GetVersion()
 	}
 
+	// Remove finished neutree Jobs on clusters without the TTL-after-finished controller (best-effort)
+	c.cleanupFinishedJobs(reconcileCtx)
+
 	// Calculate resources (best-effort)
 	resources, err := c.calculateResources(reconcileCtx)
 	if err != nil {
@@ -152,6 +173,23 @@ func (c *NativeKubernetesClusterReconciler) reconcile(reconcileCtx *ReconcileCon
 	return nil
 }
 
+// cleanupFinishedJobs deletes the finished Jobs created by neutree in the cluster namespace
+// once their TTL expired.
+func (c *NativeKubernetesClusterReconciler) cleanupFinishedJobs(reconcileCtx *ReconcileContext) {
+	ttl := time.Duration(reconcileCtx.kubernetesClusterConfig.GetFinishedJobTTLSeconds()) * time.Second
+
+	deleted, err := deploy.GarbageCollectFinishedJobs(reconcileCtx.Ctx, reconcileCtx.ctrClient,
+		reconcileCtx.clusterNamespace, ttl, time.Now())
+	if err != nil {
+		klog.Warningf("failed to clean up finished jobs of cluster %s: %v", reconcileCtx.Cluster.Metadata.WorkspaceName(), err)
+		return
+	}
+
+	if deleted > 0 {
+		reconcileCtx.logger.V(4).Info("Cleaned up finished jobs", "count", deleted)
+	}
+}
+
 func (c *NativeKubernetesClusterReconciler) reconcileComponents(reconcileCtx *ReconcileContext) error {
 	imagePrefix, err := util.GetImagePrefix(reconcileCtx.ImageRegistry)
 	if err != nil {
@@ -186,11 +224,8 @@ func (c *NativeKubernetesClusterReconciler) reconcileComponents(reconcileCtx *Re
 		}
 	}
 
-	if len(errs) > 0 {
-		return utilerrors.NewAggregate(errs)
-	}
-
-	// Update DashboardURL after successful reconcile
+	// Refresh DashboardURL on every reconcile, the access address of the router service can
+	// change (e.g. a re-provisioned load balancer) while other components fail to reconcile.
 	if endpoint, routerErr := routerComp.GetRouteEndpoint(reconcileCtx.Ctx); routerErr != nil {
 		klog.Warningf("failed to get route endpoint for cluster %s: %v", reconcileCtx.Cluster.Metadata.WorkspaceName(), routerErr)
 	} else {
@@ -201,6 +236,10 @@ func (c *NativeKubernetesClusterReconciler) reconcileComponents(reconcileCtx *Re
 		reconcileCtx.Cluster.Status.DashboardURL = endpoint
 	}
 
+	if len(errs) > 0 {
+		return utilerrors.NewAggregate(errs)
+	}
+
 	return nil
 }
 
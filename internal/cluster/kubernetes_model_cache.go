@@ -23,7 +23,12 @@ func (c *NativeKubernetesClusterReconciler) reconcileModelCache(reconcileCtx *Re
 		return errors.Wrapf(err, "failed to reconcile model cache resource")
 	}
 
-	return c.reconcileModelCacheStatus(reconcileCtx)
+	err = c.reconcileModelCacheStatus(reconcileCtx)
+	if err != nil {
+		return err
+	}
+
+	return c.reconcileModelCacheEviction(reconcileCtx)
 }
 
 func (c *NativeKubernetesClusterReconciler) reconcileModelCacheResources(reconcileCtx *ReconcileContext) error {
@@ -0,0 +1,365 @@
+package cluster
+
+import (
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v1 "github.com/neutree-ai/neutree/api/v1"
+	"github.com/neutree-ai/neutree/internal/util"
+	"github.com/neutree-ai/neutree/pkg/storage"
+)
+
+// errModelCacheExportOverlap is returned when another cluster mounts a directory of the NFS export
+// of a model cache, or the export is a directory of the export of another cluster.
+var errModelCacheExportOverlap = errors.New("nfs export overlaps the model cache of another cluster")
+
+// reconcileModelCacheEviction starts a Job evicting unused models of every model cache with an
+// eviction policy. The Job is kept for the eviction interval after it finished, so the next Job
+// runs once the finished one is garbage collected.
+func (c *NativeKubernetesClusterReconciler) reconcileModelCacheEviction(reconcileCtx *ReconcileContext) error {
+	if reconcileCtx.Cluster.Spec.Config == nil {
+		return nil
+	}
+
+	var caches []v1.ModelCache
+
+	for _, cache := range reconcileCtx.Cluster.Spec.Config.ModelCaches {
+		if cache.Eviction == nil {
+			continue
+		}
+
+		// a size capped cache without max size is never over it.
+		if cache.Eviction.Policy == v1.ModelCacheEvictionPolicySizeCapped && cache.Eviction.MaxSize == "" {
+			continue
+		}
+
+		caches = append(caches, cache)
+	}
+
+	if len(caches) == 0 {
+		return nil
+	}
+
+	protected, err := c.modelCacheProtectedModels(reconcileCtx.Cluster)
+	if err != nil {
+		return err
+	}
+
+	imagePrefix, err := util.GetImagePrefix(reconcileCtx.ImageRegistry)
+	if err != nil {
+		return errors.Wrap(err, "failed to get image prefix")
+	}
+
+	var errs []error
+
+	for _, cache := range caches {
+		cacheProtected := protected
+
+		var sharedCaches []v1.ModelCache
+
+		if cache.NFS != nil {
+			cacheProtected, sharedCaches, err = c.nfsModelCacheProtectedModels(reconcileCtx.Cluster, cache, protected)
+			if errors.Is(err, errModelCacheExportOverlap) {
+				reconcileCtx.logger.Info("Skipping model cache eviction, the models of another cluster can not be protected",
+					"cache", cache.Name, "reason", err.Error())
+				continue
+			}
+
+			if err != nil {
+				errs = append(errs, errors.Wrapf(err, "failed to protect the models of model cache %s", cache.Name))
+				continue
+			}
+		}
+
+		job, err := modelCacheEvictionJob(reconcileCtx.Cluster, reconcileCtx.clusterNamespace, imagePrefix, cache,
+			cacheProtected, sharedCaches...)
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "invalid eviction of model cache %s", cache.Name))
+			continue
+		}
+
+		err = reconcileCtx.ctrClient.Get(reconcileCtx.Ctx, client.ObjectKeyFromObject(job), &batchv1.Job{})
+		if err == nil {
+			// the previous eviction is running or waits for its garbage collection.
+			continue
+		}
+
+		if !apierrors.IsNotFound(err) {
+			errs = append(errs, errors.Wrapf(err, "failed to get model cache eviction job %s", job.Name))
+			continue
+		}
+
+		err = reconcileCtx.ctrClient.Create(reconcileCtx.Ctx, job)
+		if err != nil && !apierrors.IsAlreadyExists(err) {
+			errs = append(errs, errors.Wrapf(err, "failed to create model cache eviction job %s", job.Name))
+			continue
+		}
+
+		reconcileCtx.logger.V(4).Info("Started model cache eviction", "cache", cache.Name, "protectedModels", len(cacheProtected))
+	}
+
+	if len(errs) > 0 {
+		return utilerrors.NewAggregate(errs)
+	}
+
+	return nil
+}
+
+// modelCacheProtectedModels returns the models the endpoints of the cluster need. Every version of
+// those models is kept, as the endpoints may resolve their model version when they are redeployed.
+func (c *NativeKubernetesClusterReconciler) modelCacheProtectedModels(cluster *v1.Cluster) ([]string, error) {
+	endpoints, err := c.storage.ListEndpoint(storage.ListOption{
+		Filters: []storage.Filter{
+			{Column: "metadata->>workspace", Operator: "eq", Value: cluster.Metadata.Workspace},
+			{Column: "spec->>cluster", Operator: "eq", Value: cluster.Metadata.Name},
+		},
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list endpoints of cluster %s", cluster.Metadata.WorkspaceName())
+	}
+
+	models := map[string]struct{}{}
+
+	for _, endpoint := range endpoints {
+		if endpoint.Spec == nil || endpoint.Spec.Model == nil || endpoint.Spec.Model.Name == "" {
+			continue
+		}
+
+		if endpoint.Status != nil && endpoint.Status.Phase == v1.EndpointPhaseDELETED {
+			continue
+		}
+
+		models[endpoint.Spec.Model.Name] = struct{}{}
+	}
+
+	protected := make([]string, 0, len(models))
+	for model := range models {
+		protected = append(protected, model)
+	}
+
+	sort.Strings(protected)
+
+	return protected, nil
+}
+
+// nfsModelCacheProtectedModels adds to protected the models needed by the endpoints of the other
+// clusters mounting the NFS export of the cache, and returns their model caches of it: the
+// eviction Job sees their models as well, and must keep them. It fails with
+// errModelCacheExportOverlap when another cluster mounts the export only in part, or more of it,
+// as its models are then at other paths of the cache.
+func (c *NativeKubernetesClusterReconciler) nfsModelCacheProtectedModels(cluster *v1.Cluster, cache v1.ModelCache,
+	protected []string) ([]string, []v1.ModelCache, error) {
+	clusters, err := c.storage.ListCluster(storage.ListOption{})
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to list clusters")
+	}
+
+	exportPath := path.Clean(cache.NFS.Path)
+	models := map[string]struct{}{}
+
+	for _, model := range protected {
+		models[model] = struct{}{}
+	}
+
+	var sharedCaches []v1.ModelCache
+
+	for i := range clusters {
+		other := &clusters[i]
+		if other.Metadata == nil || other.Spec == nil || other.Spec.Config == nil ||
+			(other.Metadata.Workspace == cluster.Metadata.Workspace && other.Metadata.Name == cluster.Metadata.Name) {
+			continue
+		}
+
+		shared := false
+
+		for _, otherCache := range other.Spec.Config.ModelCaches {
+			if otherCache.NFS == nil || otherCache.NFS.Server != cache.NFS.Server {
+				continue
+			}
+
+			otherPath := path.Clean(otherCache.NFS.Path)
+			if otherPath != exportPath {
+				if pathContains(otherPath, exportPath) || pathContains(exportPath, otherPath) {
+					return nil, nil, errors.Wrapf(errModelCacheExportOverlap, "cluster %s mounts %s:%s",
+						other.Metadata.WorkspaceName(), otherCache.NFS.Server, otherPath)
+				}
+
+				continue
+			}
+
+			shared = true
+
+			sharedCaches = append(sharedCaches, otherCache)
+		}
+
+		if !shared {
+			continue
+		}
+
+		otherModels, err := c.modelCacheProtectedModels(other)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for _, model := range otherModels {
+			models[model] = struct{}{}
+		}
+	}
+
+	merged := make([]string, 0, len(models))
+	for model := range models {
+		merged = append(merged, model)
+	}
+
+	sort.Strings(merged)
+
+	return merged, sharedCaches, nil
+}
+
+// pathContains reports whether dir is a parent directory of p.
+func pathContains(dir, p string) bool {
+	return dir == "/" || strings.HasPrefix(p, dir+"/")
+}
+
+// modelCacheEvictionJob returns the Job evicting the unused models of the cache. The protected
+// models are kept under every registry path of the cache and of the shared caches mounting the
+// same storage in other clusters.
+func modelCacheEvictionJob(cluster *v1.Cluster, namespace, imagePrefix string, cache v1.ModelCache,
+	protected []string, sharedCaches ...v1.ModelCache) (*batchv1.Job, error) {
+	eviction := cache.Eviction
+
+	if eviction.Policy != v1.ModelCacheEvictionPolicyLRU && eviction.Policy != v1.ModelCacheEvictionPolicySizeCapped {
+		return nil, errors.Errorf("unsupported eviction policy %q, must be %s or %s", eviction.Policy,
+			v1.ModelCacheEvictionPolicyLRU, v1.ModelCacheEvictionPolicySizeCapped)
+	}
+
+	maxIdleRaw := eviction.MaxIdle
+	if maxIdleRaw == "" {
+		maxIdleRaw = v1.DefaultModelCacheEvictionMaxIdle
+	}
+
+	maxIdle, err := time.ParseDuration(maxIdleRaw)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid eviction max idle %q", maxIdleRaw)
+	}
+
+	var volumeSource corev1.VolumeSource
+
+	switch {
+	case cache.NFS != nil:
+		volumeSource.NFS = &corev1.NFSVolumeSource{Server: cache.NFS.Server, Path: cache.NFS.Path}
+	case cache.PVC != nil:
+		volumeSource.PersistentVolumeClaim = &corev1.PersistentVolumeClaimVolumeSource{ClaimName: util.CacheName(cache)}
+	default:
+		// a host path cache is local to every node, a single Job can not evict it.
+		return nil, errors.New("eviction is only supported by nfs and pvc model caches")
+	}
+
+	cachePath := path.Join(v1.DefaultK8sClusterModelCacheMountPath, cache.Name)
+	args := []string{
+		"-m", "neutree.downloader.eviction",
+		"--path=" + cachePath,
+		"--policy=" + string(eviction.Policy),
+	}
+
+	// the cache size is unlimited without max size.
+	if eviction.MaxSize != "" {
+		maxSize, err := resource.ParseQuantity(eviction.MaxSize)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid eviction max size %q", eviction.MaxSize)
+		}
+
+		args = append(args, "--max-size="+strconv.FormatInt(maxSize.Value(), 10))
+	}
+
+	args = append(args, "--max-idle="+strconv.FormatInt(int64(maxIdle.Seconds()), 10))
+
+	registryPaths := sortedRegistryPaths(append([]v1.ModelCache{cache}, sharedCaches...)...)
+
+	for _, model := range protected {
+		args = append(args, "--protect="+model)
+
+		// the endpoint may serve the model from any registry, which may cache it in a sub-directory.
+		for _, registryPath := range registryPaths {
+			args = append(args, "--protect="+path.Join(registryPath, model))
+		}
+	}
+
+	name := util.CacheName(cache)
+
+	return &batchv1.Job{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Job",
+			APIVersion: "batch/v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name + "-eviction",
+			Namespace: namespace,
+			Labels: map[string]string{
+				v1.NeutreeClusterLabelKey:          cluster.Metadata.Name,
+				v1.NeutreeClusterWorkspaceLabelKey: cluster.Metadata.Workspace,
+				v1.LabelManagedBy:                  v1.LabelManagedByValue,
+			},
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit:            ptr.To[int32](0),
+			TTLSecondsAfterFinished: ptr.To(eviction.GetIntervalSeconds()),
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					RestartPolicy:    corev1.RestartPolicyNever,
+					ImagePullSecrets: []corev1.LocalObjectReference{{Name: ImagePullSecretName}},
+					Containers: []corev1.Container{{
+						Name:    "eviction",
+						Image:   util.RewriteImageRef(imagePrefix, "neutree/neutree-runtime:"+cluster.GetVersion()),
+						Command: []string{"python3"},
+						Args:    args,
+						VolumeMounts: []corev1.VolumeMount{{
+							Name:      name,
+							MountPath: cachePath,
+						}},
+					}},
+					Volumes: []corev1.Volume{{
+						Name:         name,
+						VolumeSource: volumeSource,
+					}},
+				},
+			},
+		},
+	}, nil
+}
+
+// sortedRegistryPaths returns the distinct registry paths of the model caches, sorted so the
+// eviction Job does not change between reconciles.
+func sortedRegistryPaths(caches ...v1.ModelCache) []string {
+	seen := map[string]struct{}{}
+	registryPaths := []string{}
+
+	for _, cache := range caches {
+		for _, registryPath := range cache.RegistryPaths {
+			registryPath = path.Clean(registryPath)
+			if _, ok := seen[registryPath]; ok {
+				continue
+			}
+
+			seen[registryPath] = struct{}{}
+			registryPaths = append(registryPaths, registryPath)
+		}
+	}
+
+	sort.Strings(registryPaths)
+
+	return registryPaths
+}
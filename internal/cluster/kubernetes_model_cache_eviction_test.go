@@ -0,0 +1,313 @@
+package cluster
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	v1 "github.com/neutree-ai/neutree/api/v1"
+	storagemocks "github.com/neutree-ai/neutree/pkg/storage/mocks"
+)
+
+func newEvictionTestEndpoint(name, model string, phase v1.EndpointPhase) v1.Endpoint {
+	return v1.Endpoint{
+		Metadata: &v1.Metadata{Name: name, Workspace: "default"},
+		Spec: &v1.EndpointSpec{
+			Cluster: "test-cluster",
+			Model:   &v1.ModelSpec{Name: model},
+		},
+		Status: &v1.EndpointStatus{Phase: phase},
+	}
+}
+
+func newEvictionTestCluster(caches ...v1.ModelCache) *v1.Cluster {
+	return &v1.Cluster{
+		Metadata: &v1.Metadata{Name: "test-cluster", Workspace: "default"},
+		Spec: &v1.ClusterSpec{
+			Version: "v1.0.0",
+			Config:  &v1.ClusterConfig{ModelCaches: caches},
+		},
+	}
+}
+
+func TestModelCacheProtectedModels(t *testing.T) {
+	s := storagemocks.NewMockStorage(t)
+	s.On("ListEndpoint", mock.Anything).Return([]v1.Endpoint{
+		newEvictionTestEndpoint("chat", "qwen/Qwen3-8B", v1.EndpointPhaseRUNNING),
+		newEvictionTestEndpoint("chat-canary", "qwen/Qwen3-8B", v1.EndpointPhaseDEPLOYING),
+		newEvictionTestEndpoint("paused", "llama/Llama-3-8B", v1.EndpointPhasePAUSED),
+		newEvictionTestEndpoint("failed", "bge-m3", v1.EndpointPhaseFAILED),
+		newEvictionTestEndpoint("removed", "mistral/Mistral-7B", v1.EndpointPhaseDELETED),
+	}, nil).Once()
+
+	c := &NativeKubernetesClusterReconciler{storage: s}
+
+	protected, err := c.modelCacheProtectedModels(newEvictionTestCluster())
+	require.NoError(t, err)
+
+	// every endpoint which is not deleted may still need its model.
+	assert.Equal(t, []string{"bge-m3", "llama/Llama-3-8B", "qwen/Qwen3-8B"}, protected)
+}
+
+func TestModelCacheEvictionJob(t *testing.T) {
+	nfs := &corev1.NFSVolumeSource{Server: "10.0.0.1", Path: "/models"}
+
+	tests := []struct {
+		name        string
+		cache       v1.ModelCache
+		expectArgs  []string
+		expectError string
+	}{
+		{
+			name: "size capped nfs cache",
+			cache: v1.ModelCache{Name: "shared", NFS: nfs, Eviction: &v1.ModelCacheEviction{
+				Policy:  v1.ModelCacheEvictionPolicySizeCapped,
+				MaxSize: "1Gi",
+			}},
+			expectArgs: []string{
+				"-m", "neutree.downloader.eviction",
+				"--path=/models-cache/shared",
+				"--policy=size_capped",
+				"--max-size=1073741824",
+				"--max-idle=604800",
+				"--protect=qwen/Qwen3-8B",
+			},
+		},
+		{
+			name: "lru pvc cache",
+			cache: v1.ModelCache{Name: "shared", PVC: &corev1.PersistentVolumeClaimSpec{}, Eviction: &v1.ModelCacheEviction{
+				Policy:  v1.ModelCacheEvictionPolicyLRU,
+				MaxSize: "500G",
+				MaxIdle: "24h",
+			}},
+			expectArgs: []string{
+				"-m", "neutree.downloader.eviction",
+				"--path=/models-cache/shared",
+				"--policy=lru",
+				"--max-size=500000000000",
+				"--max-idle=86400",
+				"--protect=qwen/Qwen3-8B",
+			},
+		},
+		{
+			name: "cache with registry paths",
+			cache: v1.ModelCache{Name: "shared", NFS: nfs, Eviction: &v1.ModelCacheEviction{
+				Policy:  v1.ModelCacheEvictionPolicySizeCapped,
+				MaxSize: "1Gi",
+			}, RegistryPaths: map[v1.ModelRegistryType]string{
+				v1.HuggingFaceModelRegistryType: "hf",
+				v1.BentoMLModelRegistryType:     "bentoml/",
+			}},
+			expectArgs: []string{
+				"-m", "neutree.downloader.eviction",
+				"--path=/models-cache/shared",
+				"--policy=size_capped",
+				"--max-size=1073741824",
+				"--max-idle=604800",
+				"--protect=qwen/Qwen3-8B",
+				"--protect=bentoml/qwen/Qwen3-8B",
+				"--protect=hf/qwen/Qwen3-8B",
+			},
+		},
+		{
+			name: "host path cache",
+			cache: v1.ModelCache{Name: "local", HostPath: &corev1.HostPathVolumeSource{Path: "/data"}, Eviction: &v1.ModelCacheEviction{
+				Policy:  v1.ModelCacheEvictionPolicyLRU,
+				MaxSize: "1Gi",
+			}},
+			expectError: "only supported by nfs and pvc model caches",
+		},
+		{
+			name: "unsupported policy",
+			cache: v1.ModelCache{Name: "shared", NFS: nfs, Eviction: &v1.ModelCacheEviction{
+				Policy:  "fifo",
+				MaxSize: "1Gi",
+			}},
+			expectError: `unsupported eviction policy "fifo"`,
+		},
+		{
+			name: "lru cache without max size",
+			cache: v1.ModelCache{Name: "shared", NFS: nfs, Eviction: &v1.ModelCacheEviction{
+				Policy: v1.ModelCacheEvictionPolicyLRU,
+			}},
+			expectArgs: []string{
+				"-m", "neutree.downloader.eviction",
+				"--path=/models-cache/shared",
+				"--policy=lru",
+				"--max-idle=604800",
+				"--protect=qwen/Qwen3-8B",
+			},
+		},
+		{
+			name: "invalid max size",
+			cache: v1.ModelCache{Name: "shared", NFS: nfs, Eviction: &v1.ModelCacheEviction{
+				Policy:  v1.ModelCacheEvictionPolicySizeCapped,
+				MaxSize: "1 GiB",
+			}},
+			expectError: "invalid eviction max size",
+		},
+		{
+			name: "invalid max idle",
+			cache: v1.ModelCache{Name: "shared", NFS: nfs, Eviction: &v1.ModelCacheEviction{
+				Policy:  v1.ModelCacheEvictionPolicyLRU,
+				MaxSize: "1Gi",
+				MaxIdle: "7d",
+			}},
+			expectError: "invalid eviction max idle",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job, err := modelCacheEvictionJob(newEvictionTestCluster(tt.cache), "neutree-cluster", "registry.example.com/neutree",
+				tt.cache, []string{"qwen/Qwen3-8B"})
+			if tt.expectError != "" {
+				assert.ErrorContains(t, err, tt.expectError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, "models-cache-shared-eviction", job.Name)
+			assert.Equal(t, v1.LabelManagedByValue, job.Labels[v1.LabelManagedBy])
+			assert.Equal(t, ptr.To[int32](v1.DefaultModelCacheEvictionIntervalSeconds), job.Spec.TTLSecondsAfterFinished)
+
+			container := job.Spec.Template.Spec.Containers[0]
+			assert.Equal(t, "registry.example.com/neutree/neutree/neutree-runtime:v1.0.0", container.Image)
+			assert.Equal(t, tt.expectArgs, container.Args)
+			assert.Equal(t, "/models-cache/shared", container.VolumeMounts[0].MountPath)
+		})
+	}
+}
+
+func TestReconcileModelCacheEviction(t *testing.T) {
+	cache := v1.ModelCache{Name: "shared", NFS: &corev1.NFSVolumeSource{Server: "10.0.0.1", Path: "/models"},
+		Eviction: &v1.ModelCacheEviction{Policy: v1.ModelCacheEvictionPolicySizeCapped, MaxSize: "1Gi"}}
+
+	tests := []struct {
+		name     string
+		existing []client.Object
+	}{
+		{
+			name: "starts the eviction job",
+		},
+		{
+			name: "keeps the previous eviction job",
+			existing: []client.Object{&batchv1.Job{ObjectMeta: metav1.ObjectMeta{
+				Name: "models-cache-shared-eviction", Namespace: "neutree-cluster",
+			}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := storagemocks.NewMockStorage(t)
+			s.On("ListEndpoint", mock.Anything).Return([]v1.Endpoint{
+				newEvictionTestEndpoint("chat", "qwen/Qwen3-8B", v1.EndpointPhaseRUNNING),
+			}, nil).Once()
+			s.On("ListCluster", mock.Anything).Return([]v1.Cluster{*newEvictionTestCluster(cache)}, nil).Once()
+
+			ctrClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(tt.existing...).Build()
+			reconcileCtx := &ReconcileContext{
+				Ctx:     context.Background(),
+				Cluster: newEvictionTestCluster(cache),
+				ImageRegistry: &v1.ImageRegistry{
+					Spec: &v1.ImageRegistrySpec{URL: "https://registry.example.com", Repository: "neutree"},
+				},
+				ctrClient:        ctrClient,
+				clusterNamespace: "neutree-cluster",
+				logger:           klog.Background(),
+			}
+
+			c := &NativeKubernetesClusterReconciler{storage: s}
+			require.NoError(t, c.reconcileModelCacheEviction(reconcileCtx))
+
+			job := &batchv1.Job{}
+			require.NoError(t, ctrClient.Get(context.Background(),
+				client.ObjectKey{Namespace: "neutree-cluster", Name: "models-cache-shared-eviction"}, job))
+
+			if len(tt.existing) > 0 {
+				assert.Empty(t, job.Spec.Template.Spec.Containers, "the previous job should not be replaced")
+				return
+			}
+
+			assert.Contains(t, job.Spec.Template.Spec.Containers[0].Args, "--protect=qwen/Qwen3-8B")
+		})
+	}
+}
+
+func TestReconcileModelCacheEviction_NoEvictionPolicy(t *testing.T) {
+	c := &NativeKubernetesClusterReconciler{storage: storagemocks.NewMockStorage(t)}
+
+	err := c.reconcileModelCacheEviction(&ReconcileContext{
+		Cluster: newEvictionTestCluster(v1.ModelCache{Name: "shared", NFS: &corev1.NFSVolumeSource{Server: "10.0.0.1"}}),
+	})
+	assert.NoError(t, err)
+}
+
+func TestReconcileModelCacheEviction_SizeCappedWithoutMaxSize(t *testing.T) {
+	c := &NativeKubernetesClusterReconciler{storage: storagemocks.NewMockStorage(t)}
+
+	err := c.reconcileModelCacheEviction(&ReconcileContext{
+		Cluster: newEvictionTestCluster(v1.ModelCache{Name: "shared", NFS: &corev1.NFSVolumeSource{Server: "10.0.0.1"},
+			Eviction: &v1.ModelCacheEviction{Policy: v1.ModelCacheEvictionPolicySizeCapped}}),
+	})
+	assert.NoError(t, err)
+}
+
+func TestNFSModelCacheProtectedModels(t *testing.T) {
+	cache := v1.ModelCache{Name: "shared", NFS: &corev1.NFSVolumeSource{Server: "10.0.0.1", Path: "/models"}}
+	cluster := newEvictionTestCluster(cache)
+
+	newOtherCluster := func(name string, caches ...v1.ModelCache) v1.Cluster {
+		other := newEvictionTestCluster(caches...)
+		other.Metadata.Name = name
+
+		return *other
+	}
+
+	t.Run("protects the models of the clusters sharing the export", func(t *testing.T) {
+		sharedCache := v1.ModelCache{Name: "models", NFS: &corev1.NFSVolumeSource{Server: "10.0.0.1", Path: "/models/"},
+			RegistryPaths: map[v1.ModelRegistryType]string{v1.HuggingFaceModelRegistryType: "hf"}}
+
+		s := storagemocks.NewMockStorage(t)
+		s.On("ListCluster", mock.Anything).Return([]v1.Cluster{
+			*cluster,
+			newOtherCluster("sharing", sharedCache),
+			newOtherCluster("other-server", v1.ModelCache{Name: "shared", NFS: &corev1.NFSVolumeSource{Server: "10.0.0.2", Path: "/models"}}),
+			newOtherCluster("other-export", v1.ModelCache{Name: "shared", NFS: &corev1.NFSVolumeSource{Server: "10.0.0.1", Path: "/datasets"}}),
+		}, nil).Once()
+		s.On("ListEndpoint", mock.Anything).Return([]v1.Endpoint{
+			newEvictionTestEndpoint("embedding", "bge-m3", v1.EndpointPhaseRUNNING),
+		}, nil).Once()
+
+		c := &NativeKubernetesClusterReconciler{storage: s}
+
+		protected, sharedCaches, err := c.nfsModelCacheProtectedModels(cluster, cache, []string{"qwen/Qwen3-8B"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"bge-m3", "qwen/Qwen3-8B"}, protected)
+		assert.Equal(t, []v1.ModelCache{sharedCache}, sharedCaches)
+	})
+
+	t.Run("refuses an export another cluster mounts in part", func(t *testing.T) {
+		s := storagemocks.NewMockStorage(t)
+		s.On("ListCluster", mock.Anything).Return([]v1.Cluster{
+			newOtherCluster("nested", v1.ModelCache{Name: "shared", NFS: &corev1.NFSVolumeSource{Server: "10.0.0.1", Path: "/models/team-a"}}),
+		}, nil).Once()
+
+		c := &NativeKubernetesClusterReconciler{storage: s}
+
+		_, _, err := c.nfsModelCacheProtectedModels(cluster, cache, nil)
+		assert.ErrorIs(t, err, errModelCacheExportOverlap)
+	})
+}
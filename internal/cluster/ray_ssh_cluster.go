@@ -6,6 +6,8 @@ import (
 	stderrors "errors"
 	"fmt"
 	"os"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -43,6 +45,9 @@ type sshRayClusterReconciler struct {
 	executor           command.Executor
 	acceleratorManager accelerator.Manager
 	storage            storage.Storage
+	// sshConnectionReuse runs the ssh commands of a reconcile through one master
+	// connection per node, see multiplexSSH.
+	sshConnectionReuse bool
 }
 
 // logWithProcessMessage logs the process messages and updates the cluster status error message.
@@ -107,6 +112,7 @@ func (c *sshRayClusterReconciler) Reconcile(ctx context.Context, cluster *v1.Clu
 	}
 
 	defer c.cleanupConfig(reconcileCtx) //nolint:errcheck
+	defer c.multiplexSSH(reconcileCtx)()
 
 	switch {
 	case reconcileCtx.Cluster.Status == nil || !reconcileCtx.Cluster.Status.Initialized:
@@ -215,6 +221,7 @@ func (c *sshRayClusterReconciler) ReconcileDelete(ctx context.Context, cluster *
 	}
 
 	defer c.cleanupConfig(reconcileCtx) //nolint:errcheck
+	defer c.multiplexSSH(reconcileCtx)()
 
 	err = c.downCluster(reconcileCtx)
 	if err != nil {
@@ -257,6 +264,34 @@ func (c *sshRayClusterReconciler) cleanupConfig(reconcileCtx *ReconcileContext)
 	return nil
 }
 
+// multiplexSSH routes the ssh commands of the reconcile through one master connection per
+// node when connection reuse is enabled, so provisioning a node authenticates once rather
+// than for every command. The returned function closes the master connections.
+func (c *sshRayClusterReconciler) multiplexSSH(reconcileCtx *ReconcileContext) func() {
+	if !c.sshConnectionReuse {
+		return func() {}
+	}
+
+	ctx := reconcileCtx.Ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	executor, err := command.NewSSHMultiplexExecutor(ctx, c.executor)
+	if err != nil {
+		klog.Warningf("Failed to enable ssh connection reuse for cluster %s: %v", reconcileCtx.Cluster.Metadata.WorkspaceName(), err)
+		return func() {}
+	}
+
+	reconcileCtx.sshExecutor = executor
+
+	return func() {
+		if err := executor.Close(); err != nil {
+			klog.Warningf("Failed to close ssh connections of cluster %s: %v", reconcileCtx.Cluster.Metadata.WorkspaceName(), err)
+		}
+	}
+}
+
 func (c *sshRayClusterReconciler) reconcileHeadNode(reconcileCtx *ReconcileContext) error {
 	alive, headVersion, healthErr := c.checkHeadNodeHealth(reconcileCtx)
 	if healthErr != nil && !stderrors.Is(healthErr, errHeadNodeUnhealthy) {
@@ -444,7 +479,15 @@ func (c *sshRayClusterReconciler) reconcileWorkerNode(reconcileCtx *ReconcileCon
 		}
 	}
 
-	for _, nodeIp := range desiredStaticNodeIpMap {
+	// Start the workers in the order of the cluster config, and stop them sorted by IP, so
+	// the provisioning order and the progress messages are the same on every reconcile.
+	sort.Strings(nodeIpToStop)
+
+	for _, nodeIp := range desiredStaticWorkersIP {
+		if slices.Contains(nodeIpToStart, nodeIp) {
+			continue
+		}
+
 		if checkNeedStart(nodeIp) {
 			nodeIpToStart = append(nodeIpToStart, nodeIp)
 		}
@@ -455,8 +498,11 @@ func (c *sshRayClusterReconciler) reconcileWorkerNode(reconcileCtx *ReconcileCon
 			fmt.Sprintf("%d worker node(s) need recovery: %s", len(nodeIpToStart), strings.Join(nodeIpToStart, ", ")))
 	}
 
+	// Each node appears at most once in nodeIpToStart and nodeIpToStop, so every node is
+	// only operated by a single SSH session at a time; the limit bounds the sessions overall.
 	nodeOpErrors := make([]error, len(nodeIpToStart)+len(nodeIpToStop))
 	eg := &errgroup.Group{}
+	eg.SetLimit(reconcileCtx.sshClusterConfig.NodeProvisionWorkers())
 
 	for i := range nodeIpToStart {
 		ip := nodeIpToStart[i]
@@ -509,6 +555,7 @@ func (c *sshRayClusterReconciler) reconcileWorkerNode(reconcileCtx *ReconcileCon
 				LastProvisionTime: time.Now().Format(time.RFC3339),
 				Status:            v1.ProvisioningNodeProvisionStatus,
 				IsHead:            false,
+				LastError:         nodeOpErrors[i].Error(),
 			}
 		}
 	}
@@ -519,6 +566,9 @@ func (c *sshRayClusterReconciler) reconcileWorkerNode(reconcileCtx *ReconcileCon
 		}
 	}
 
+	pruneRemovedNodeProvisionStatus(staticNodeProvisionStatusMap, desiredStaticNodeIpMap, currentNodeStatusMap,
+		reconcileCtx.sshClusterConfig.Provider.HeadIP)
+
 	// update cluster status
 	staticNodeProvisionStatusContent, err := json.Marshal(staticNodeProvisionStatusMap)
 	if err != nil {
@@ -539,6 +589,29 @@ func (c *sshRayClusterReconciler) reconcileWorkerNode(reconcileCtx *ReconcileCon
 	return nil
 }
 
+// pruneRemovedNodeProvisionStatus drops the provision status of the workers removed from the
+// cluster config that Ray no longer reports alive, e.g. when stopping them failed because the
+// node is gone. Removed workers still alive are kept so stopping them is retried, and the head
+// node is never dropped.
+func pruneRemovedNodeProvisionStatus(statusMap map[string]v1.NodeProvision, desiredNodeIPs, currentNodeStates map[string]string, headIP string) {
+	for nodeIp, nodeProvision := range statusMap {
+		if nodeProvision.IsHead || nodeIp == headIP {
+			continue
+		}
+
+		if _, ok := desiredNodeIPs[nodeIp]; ok {
+			continue
+		}
+
+		if currentNodeStates[nodeIp] == v1.AliveNodeState {
+			continue
+		}
+
+		klog.Infof("Removing provision status of node %s which is no longer in the cluster", nodeIp)
+		delete(statusMap, nodeIp)
+	}
+}
+
 func (c *sshRayClusterReconciler) initialize(reconcileCtx *ReconcileContext) error {
 	if reconcileCtx.Cluster.Status == nil {
 		reconcileCtx.Cluster.Status = &v1.ClusterStatus{}
@@ -555,6 +628,10 @@ func (c *sshRayClusterReconciler) initialize(reconcileCtx *ReconcileContext) err
 		}
 	}
 
+	if reconcileCtx.sshClusterConfig.Adopt {
+		return c.adopt(reconcileCtx)
+	}
+
 	c.logWithProcessMessage(reconcileCtx, "Start to initialize cluster ",
 		fmt.Sprintf("Starting head node %s for bringing up Ray cluster ", reconcileCtx.sshClusterConfig.Provider.HeadIP))
 
@@ -575,6 +652,75 @@ func (c *sshRayClusterReconciler) initialize(reconcileCtx *ReconcileContext) err
 	return nil
 }
 
+// adopt takes over the Ray cluster already running on the cluster nodes without running any
+// up or start command. The cluster is marked initialized once checkAndUpdateStatus sees all
+// nodes ready, from then on it is reconciled like a provisioned cluster.
+func (c *sshRayClusterReconciler) adopt(reconcileCtx *ReconcileContext) error {
+	headIP := reconcileCtx.sshClusterConfig.Provider.HeadIP
+
+	c.logWithProcessMessage(reconcileCtx, "Start to adopt cluster ",
+		fmt.Sprintf("Detecting running Ray cluster on head node %s ", headIP))
+
+	alive, _, err := c.checkHeadNodeHealth(reconcileCtx)
+	if err != nil && !stderrors.Is(err, errHeadNodeUnhealthy) {
+		return errors.Wrap(err, "failed to check head node health")
+	}
+
+	if !alive {
+		reason := "head raylet is not alive"
+		if err != nil {
+			reason = err.Error()
+		}
+
+		return errors.Errorf("no running Ray cluster to adopt on head node %s: %s", headIP, reason)
+	}
+
+	if err := recordAdoptedNodeProvisionStatus(reconcileCtx); err != nil {
+		return errors.Wrap(err, "failed to record provision status of adopted nodes")
+	}
+
+	c.logWithProcessMessage(reconcileCtx, "Cluster "+reconcileCtx.Cluster.Metadata.WorkspaceName()+" adopted successfully")
+
+	return nil
+}
+
+// recordAdoptedNodeProvisionStatus records the head node and the configured workers Ray reports
+// alive as provisioned, so the worker reconcile neither starts them again nor skips stopping them
+// once they are removed from the cluster config. Configured workers not alive are left out, the
+// worker reconcile starts them like workers added to the cluster.
+func recordAdoptedNodeProvisionStatus(reconcileCtx *ReconcileContext) error {
+	headIP := reconcileCtx.sshClusterConfig.Provider.HeadIP
+
+	if err := setNodePrivisionStatus(reconcileCtx, headIP, v1.ProvisionedNodeProvisionStatus, true); err != nil {
+		return err
+	}
+
+	nodes, err := reconcileCtx.rayService.ListNodes()
+	if err != nil {
+		return errors.Wrap(err, "failed to list ray nodes")
+	}
+
+	aliveNodeIPs := map[string]bool{}
+
+	for _, node := range nodes {
+		if !node.Raylet.IsHeadNode && node.Raylet.State == v1.AliveNodeState {
+			aliveNodeIPs[node.IP] = true
+		}
+	}
+
+	for _, workerIP := range reconcileCtx.sshClusterConfig.Provider.WorkerIPs {
+		if !aliveNodeIPs[workerIP] {
+			continue
+		}
+
+		if err := setNodePrivisionStatus(reconcileCtx, workerIP, v1.ProvisionedNodeProvisionStatus, false); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (c *sshRayClusterReconciler) buildSSHCommandArgs(reconcileCtx *ReconcileContext, nodeIP string) *command_runner.CommonArgs {
 	return &command_runner.CommonArgs{
 		NodeID: nodeIP,
@@ -584,10 +730,19 @@ func (c *sshRayClusterReconciler) buildSSHCommandArgs(reconcileCtx *ReconcileCon
 			SSHPrivateKey: reconcileCtx.sshConfigGenerator.SSHKeyPath(),
 		},
 		SSHControlPath: "",
-		ProcessExecute: c.executor.Execute,
+		ProcessExecute: c.sshExecutor(reconcileCtx).Execute,
 	}
 }
 
+// sshExecutor returns the executor of the ssh commands of the reconcile.
+func (c *sshRayClusterReconciler) sshExecutor(reconcileCtx *ReconcileContext) command.Executor {
+	if reconcileCtx.sshExecutor != nil {
+		return reconcileCtx.sshExecutor
+	}
+
+	return c.executor
+}
+
 func (c *sshRayClusterReconciler) configClusterAcceleratorType(reconcileCtx *ReconcileContext) error {
 	acceleratorType, err := c.detectClusterAcceleratorType(reconcileCtx)
 	if err != nil {
@@ -5,6 +5,7 @@ import (
 	stderrors "errors"
 	"fmt"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -159,6 +160,116 @@ func TestInitializeCluster(t *testing.T) {
 	}
 }
 
+func TestInitializeCluster_Adopt(t *testing.T) {
+	aliveHead := []v1.NodeSummary{{Raylet: v1.Raylet{IsHeadNode: true, State: v1.AliveNodeState}}}
+	aliveNodes := []v1.NodeSummary{
+		{IP: "127.0.0.1", Raylet: v1.Raylet{IsHeadNode: true, State: v1.AliveNodeState}},
+		{IP: "127.0.0.2", Raylet: v1.Raylet{State: v1.AliveNodeState}},
+		{IP: "127.0.0.3", Raylet: v1.Raylet{State: "DEAD"}},
+	}
+
+	tests := []struct {
+		name               string
+		setupMock          func(dashboardSvc *dashboardmocks.MockDashboardService)
+		wantErr            string
+		wantProvisionedIPs []string
+	}{
+		{
+			name: "healthy running cluster is adopted without up or start commands",
+			setupMock: func(dashboardSvc *dashboardmocks.MockDashboardService) {
+				dashboardSvc.On("GetClusterMetadata").Return(&dashboard.ClusterMetadataResponse{}, nil).Once()
+				dashboardSvc.On("ListNodes").Return(aliveHead, nil).Once()
+				dashboardSvc.On("ListNodes").Return(aliveNodes, nil).Once()
+			},
+			wantProvisionedIPs: []string{"127.0.0.1", "127.0.0.2"},
+		},
+		{
+			name: "no cluster running on the head node",
+			setupMock: func(dashboardSvc *dashboardmocks.MockDashboardService) {
+				dashboardSvc.On("GetClusterMetadata").Return(nil, errors.New("connection refused")).Once()
+			},
+			wantErr: "no running Ray cluster to adopt on head node 127.0.0.1",
+		},
+		{
+			name: "head raylet of the running cluster is not alive",
+			setupMock: func(dashboardSvc *dashboardmocks.MockDashboardService) {
+				dashboardSvc.On("GetClusterMetadata").Return(&dashboard.ClusterMetadataResponse{}, nil).Once()
+				dashboardSvc.On("ListNodes").Return([]v1.NodeSummary{
+					{Raylet: v1.Raylet{IsHeadNode: true, State: "DEAD"}},
+				}, nil).Once()
+			},
+			wantErr: "no running Ray cluster to adopt on head node 127.0.0.1",
+		},
+		{
+			name: "listing the nodes of the running cluster fails",
+			setupMock: func(dashboardSvc *dashboardmocks.MockDashboardService) {
+				dashboardSvc.On("GetClusterMetadata").Return(&dashboard.ClusterMetadataResponse{}, nil).Once()
+				dashboardSvc.On("ListNodes").Return(nil, assert.AnError).Once()
+			},
+			wantErr: "failed to check head node health",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The executor has no expectations: any ray up/start command fails the test.
+			e := &commandmocks.MockExecutor{}
+			dashboardSvc := &dashboardmocks.MockDashboardService{}
+			storage := &storagemocks.MockStorage{}
+			// Initializing phase and process messages.
+			storage.On("UpdateCluster", mock.Anything, mock.Anything).Return(nil)
+			tt.setupMock(dashboardSvc)
+
+			cluster := &v1.Cluster{
+				Metadata: &v1.Metadata{Name: "test-ssh-ray-cluster"},
+				Spec:     &v1.ClusterSpec{Type: "ssh"},
+			}
+
+			r := &sshRayClusterReconciler{
+				storage:  storage,
+				executor: e,
+			}
+
+			err := r.initialize(&ReconcileContext{
+				Cluster: cluster,
+				sshClusterConfig: &v1.RaySSHProvisionClusterConfig{
+					Provider: v1.Provider{
+						HeadIP:    "127.0.0.1",
+						WorkerIPs: []string{"127.0.0.2", "127.0.0.3"},
+					},
+					Adopt: true,
+				},
+				sshConfigGenerator: newRaySSHLocalConfigGenerator(cluster.Metadata.Name),
+				rayService:         dashboardSvc,
+			})
+
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Equal(t, v1.ClusterPhaseInitializing, cluster.Status.Phase)
+
+			provisionStatus := map[string]v1.NodeProvision{}
+			if cluster.Status.NodeProvisionStatus != "" {
+				assert.NoError(t, json.Unmarshal([]byte(cluster.Status.NodeProvisionStatus), &provisionStatus))
+			}
+
+			assert.Len(t, provisionStatus, len(tt.wantProvisionedIPs))
+
+			for _, ip := range tt.wantProvisionedIPs {
+				assert.Equal(t, v1.ProvisionedNodeProvisionStatus, provisionStatus[ip].Status, ip)
+				assert.Equal(t, ip == "127.0.0.1", provisionStatus[ip].IsHead, ip)
+			}
+
+			storage.AssertExpectations(t)
+			e.AssertExpectations(t)
+			dashboardSvc.AssertExpectations(t)
+		})
+	}
+}
+
 func TestReconcileHeadNode(t *testing.T) {
 	defaultCluster := func() *v1.Cluster {
 		return &v1.Cluster{
@@ -964,6 +1075,222 @@ func TestReconcileWorkerNode(t *testing.T) {
 	}
 }
 
+func TestReconcileWorkerNode_BoundedParallelism(t *testing.T) {
+	workerIPs := []string{"10.0.0.1", "10.0.0.2", "10.0.0.3", "10.0.0.4", "10.0.0.5"}
+	cluster := &v1.Cluster{
+		Metadata: &v1.Metadata{Name: "test"},
+		Status: &v1.ClusterStatus{
+			Initialized:     true,
+			AcceleratorType: v1.AcceleratorTypeNVIDIAGPU.StringPtr(),
+		},
+	}
+
+	var inFlight, maxInFlight int32
+
+	dashboardSvc := &dashboardmocks.MockDashboardService{}
+	dashboardSvc.On("ListNodes").Return([]v1.NodeSummary{}, nil)
+
+	acceleratorManager := &acceleratormocks.MockManager{}
+	acceleratorManager.On("GetNodeRuntimeConfig", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Run(func(mock.Arguments) {
+			current := atomic.AddInt32(&inFlight, 1)
+			for {
+				observed := atomic.LoadInt32(&maxInFlight)
+				if current <= observed || atomic.CompareAndSwapInt32(&maxInFlight, observed, current) {
+					break
+				}
+			}
+
+			time.Sleep(50 * time.Millisecond)
+			atomic.AddInt32(&inFlight, -1)
+		}).Return(v1.RuntimeConfig{}, assert.AnError).Times(len(workerIPs))
+
+	r := &sshRayClusterReconciler{
+		acceleratorManager: acceleratorManager,
+		executor:           &commandmocks.MockExecutor{},
+	}
+
+	err := r.reconcileWorkerNode(&ReconcileContext{
+		Cluster: cluster,
+		sshClusterConfig: &v1.RaySSHProvisionClusterConfig{
+			Provider:                 v1.Provider{WorkerIPs: workerIPs},
+			NodeProvisionParallelism: pointer.Int(2),
+		},
+		sshRayClusterConfig: &v1.RayClusterConfig{},
+		rayService:          dashboardSvc,
+		sshConfigGenerator:  newRaySSHLocalConfigGenerator(cluster.Metadata.Name),
+	})
+	require.Error(t, err)
+
+	assert.Equal(t, int32(2), atomic.LoadInt32(&maxInFlight))
+	acceleratorManager.AssertExpectations(t)
+
+	var status map[string]v1.NodeProvision
+	require.NoError(t, json.Unmarshal([]byte(cluster.Status.NodeProvisionStatus), &status))
+	assert.Len(t, status, len(workerIPs))
+
+	for _, ip := range workerIPs {
+		assert.Equal(t, v1.ProvisioningNodeProvisionStatus, status[ip].Status)
+	}
+}
+
+func TestReconcileWorkerNode_ConfigOrder(t *testing.T) {
+	workerIPs := []string{"10.0.0.3", "10.0.0.1", "10.0.0.2", "10.0.0.1"}
+	cluster := &v1.Cluster{
+		Metadata: &v1.Metadata{Name: "test"},
+		Status: &v1.ClusterStatus{
+			Initialized:     true,
+			AcceleratorType: v1.AcceleratorTypeNVIDIAGPU.StringPtr(),
+		},
+	}
+
+	dashboardSvc := &dashboardmocks.MockDashboardService{}
+	dashboardSvc.On("ListNodes").Return([]v1.NodeSummary{}, nil)
+
+	var started []string
+
+	acceleratorManager := &acceleratormocks.MockManager{}
+	acceleratorManager.On("GetNodeRuntimeConfig", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			started = append(started, args.String(2))
+		}).Return(v1.RuntimeConfig{}, assert.AnError)
+
+	r := &sshRayClusterReconciler{
+		acceleratorManager: acceleratorManager,
+		executor:           &commandmocks.MockExecutor{},
+	}
+
+	err := r.reconcileWorkerNode(&ReconcileContext{
+		Cluster: cluster,
+		sshClusterConfig: &v1.RaySSHProvisionClusterConfig{
+			Provider:                 v1.Provider{WorkerIPs: workerIPs},
+			NodeProvisionParallelism: pointer.Int(1),
+		},
+		sshRayClusterConfig: &v1.RayClusterConfig{},
+		rayService:          dashboardSvc,
+		sshConfigGenerator:  newRaySSHLocalConfigGenerator(cluster.Metadata.Name),
+	})
+	require.Error(t, err)
+
+	// Duplicated IPs are started once.
+	assert.Equal(t, []string{"10.0.0.3", "10.0.0.1", "10.0.0.2"}, started)
+}
+
+func TestReconcileWorkerNode_FailureIsolatedPerNode(t *testing.T) {
+	cluster := &v1.Cluster{
+		Metadata: &v1.Metadata{Name: "test"},
+		Status: &v1.ClusterStatus{
+			Initialized:     true,
+			AcceleratorType: v1.AcceleratorTypeNVIDIAGPU.StringPtr(),
+		},
+	}
+
+	dashboardSvc := &dashboardmocks.MockDashboardService{}
+	dashboardSvc.On("ListNodes").Return([]v1.NodeSummary{}, nil)
+
+	acceleratorManager := &acceleratormocks.MockManager{}
+	acceleratorManager.On("GetNodeRuntimeConfig", mock.Anything, mock.Anything, "10.0.0.2", mock.Anything).
+		Return(v1.RuntimeConfig{}, assert.AnError).Once()
+	acceleratorManager.On("GetNodeRuntimeConfig", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(v1.RuntimeConfig{}, nil).Twice()
+
+	// With one node provisioned at a time the start commands of the healthy nodes never interleave.
+	e := &commandmocks.MockExecutor{}
+	for range 2 {
+		e.On("Execute", mock.Anything, mock.Anything, mock.Anything).Return([]byte(""), nil).Once()
+		e.On("Execute", mock.Anything, mock.Anything, mock.Anything).Return([]byte("docker"), nil).Once()
+		e.On("Execute", mock.Anything, mock.Anything, mock.Anything).Return([]byte(""), nil).Once()
+		e.On("Execute", mock.Anything, mock.Anything, mock.Anything).Return([]byte(""), nil).Once()
+		e.On("Execute", mock.Anything, mock.Anything, mock.Anything).Return([]byte(""), nil).Once()
+		e.On("Execute", mock.Anything, mock.Anything, mock.Anything).Return([]byte("true"), nil).Once()
+	}
+
+	r := &sshRayClusterReconciler{
+		acceleratorManager: acceleratorManager,
+		executor:           e,
+	}
+
+	err := r.reconcileWorkerNode(&ReconcileContext{
+		Cluster: cluster,
+		sshClusterConfig: &v1.RaySSHProvisionClusterConfig{
+			Provider:                 v1.Provider{WorkerIPs: []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"}},
+			NodeProvisionParallelism: pointer.Int(1),
+		},
+		sshRayClusterConfig: &v1.RayClusterConfig{},
+		rayService:          dashboardSvc,
+		sshConfigGenerator:  newRaySSHLocalConfigGenerator(cluster.Metadata.Name),
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "10.0.0.2")
+	assert.NotContains(t, err.Error(), "10.0.0.1")
+
+	var status map[string]v1.NodeProvision
+	require.NoError(t, json.Unmarshal([]byte(cluster.Status.NodeProvisionStatus), &status))
+
+	assert.Equal(t, v1.ProvisionedNodeProvisionStatus, status["10.0.0.1"].Status)
+	assert.Empty(t, status["10.0.0.1"].LastError)
+	assert.Equal(t, v1.ProvisionedNodeProvisionStatus, status["10.0.0.3"].Status)
+	assert.Equal(t, v1.ProvisioningNodeProvisionStatus, status["10.0.0.2"].Status)
+	assert.Contains(t, status["10.0.0.2"].LastError, assert.AnError.Error())
+
+	acceleratorManager.AssertExpectations(t)
+	e.AssertExpectations(t)
+}
+
+func TestReconcileWorkerNode_PrunesRemovedNodeStatus(t *testing.T) {
+	cluster := &v1.Cluster{
+		Metadata: &v1.Metadata{Name: "test"},
+		Status: &v1.ClusterStatus{
+			Initialized: true,
+			NodeProvisionStatus: `{"10.0.0.100":{"status":"provisioned","last_provision_time":"2025-10-21T10:46:27Z","is_head":true},` +
+				`"10.0.0.1":{"status":"provisioned","last_provision_time":"2025-10-21T10:46:27Z","is_head":false},` +
+				`"10.0.0.2":{"status":"provisioned","last_provision_time":"2025-10-21T10:46:27Z","is_head":false},` +
+				`"10.0.0.3":{"status":"provisioned","last_provision_time":"2025-10-21T10:46:27Z","is_head":false}}`,
+			AcceleratorType: v1.AcceleratorTypeNVIDIAGPU.StringPtr(),
+		},
+	}
+
+	dashboardSvc := &dashboardmocks.MockDashboardService{}
+	dashboardSvc.On("ListNodes").Return([]v1.NodeSummary{
+		{IP: "10.0.0.1", Raylet: v1.Raylet{State: v1.AliveNodeState}},
+		{IP: "10.0.0.2", Raylet: v1.Raylet{State: v1.DeadNodeState}},
+		{IP: "10.0.0.3", Raylet: v1.Raylet{State: v1.AliveNodeState}},
+	}, nil)
+
+	// Stopping both removed workers fails, e.g. because their hosts are unreachable.
+	e := &commandmocks.MockExecutor{}
+	e.On("Execute", mock.Anything, mock.Anything, mock.Anything).Return([]byte(""), assert.AnError).Twice()
+
+	r := &sshRayClusterReconciler{
+		acceleratorManager: &acceleratormocks.MockManager{},
+		executor:           e,
+	}
+
+	err := r.reconcileWorkerNode(&ReconcileContext{
+		Cluster: cluster,
+		sshClusterConfig: &v1.RaySSHProvisionClusterConfig{
+			Provider: v1.Provider{HeadIP: "10.0.0.100", WorkerIPs: []string{"10.0.0.1"}},
+		},
+		sshRayClusterConfig: &v1.RayClusterConfig{},
+		rayService:          dashboardSvc,
+		sshConfigGenerator:  newRaySSHLocalConfigGenerator(cluster.Metadata.Name),
+	})
+	require.Error(t, err)
+
+	var status map[string]v1.NodeProvision
+	require.NoError(t, json.Unmarshal([]byte(cluster.Status.NodeProvisionStatus), &status))
+
+	// The removed worker no longer alive in Ray is purged, the removed worker still alive is
+	// kept to retry stopping it, and the head and desired workers are untouched.
+	assert.Len(t, status, 3)
+	assert.True(t, status["10.0.0.100"].IsHead)
+	assert.Contains(t, status, "10.0.0.1")
+	assert.Contains(t, status, "10.0.0.3")
+	assert.NotContains(t, status, "10.0.0.2")
+
+	e.AssertExpectations(t)
+}
+
 func TestSSHRayCluster_CalculateResource(t *testing.T) {
 	tests := []struct {
 		name              string
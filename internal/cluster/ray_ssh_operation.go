@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"path"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	"golang.org/x/sync/errgroup"
@@ -12,6 +13,7 @@ import (
 
 	v1 "github.com/neutree-ai/neutree/api/v1"
 	"github.com/neutree-ai/neutree/internal/ray/dashboard"
+	"github.com/neutree-ai/neutree/internal/ray/rayserve"
 	"github.com/neutree-ai/neutree/internal/semver"
 	"github.com/neutree-ai/neutree/internal/util"
 	"github.com/neutree-ai/neutree/pkg/command_runner"
@@ -25,6 +27,10 @@ import (
 // failure up the stack.
 var errHeadNodeUnhealthy = stderrors.New("head node unhealthy")
 
+// warmReplacementTimeout bounds how long a node drain waits for the replacement replicas,
+// which may have to download their model first.
+var warmReplacementTimeout = 30 * time.Minute
+
 func (c *sshRayClusterReconciler) upCluster(reconcileCtx *ReconcileContext, restart bool) (string, error) {
 	dockerConfig, changed, err := c.buildAcceleratorDockerConfig(reconcileCtx, reconcileCtx.sshClusterConfig.Provider.HeadIP)
 	if err != nil {
@@ -133,6 +139,19 @@ func (c *sshRayClusterReconciler) drainNode(reconcileCtx *ReconcileContext, node
 	return nil
 }
 
+// warmReplaceNodeReplicas starts replacements of the endpoint replicas on the node on other nodes
+// and waits for them before the node is drained, so the endpoints keep their capacity. The node is
+// drained anyway if the replacements do not run in time, e.g. the cluster lacks resources.
+func (c *sshRayClusterReconciler) warmReplaceNodeReplicas(reconcileCtx *ReconcileContext, nodeID, nodeIP string) func() error {
+	release, err := rayserve.WarmReplaceNodeReplicas(reconcileCtx.Ctx, reconcileCtx.rayService,
+		reconcileCtx.Cluster.Metadata.WorkspaceName(), nodeID, warmReplacementTimeout)
+	if err != nil {
+		c.logWithProcessMessage(reconcileCtx, fmt.Sprintf("Draining worker node %s without replacement replicas: %v", nodeIP, err))
+	}
+
+	return release
+}
+
 func (c *sshRayClusterReconciler) getNodeByIP(reconcileCtx *ReconcileContext, nodeIP string) (*v1.NodeSummary, error) {
 	rayNodes, err := reconcileCtx.rayService.ListNodes()
 	if err != nil {
@@ -185,6 +204,13 @@ func (c *sshRayClusterReconciler) stopNode(reconcileCtx *ReconcileContext, nodeI
 		}
 
 		if node.Raylet.State == v1.AliveNodeState {
+			release := c.warmReplaceNodeReplicas(reconcileCtx, node.Raylet.NodeID, nodeIP)
+			defer func() {
+				if releaseErr := release(); releaseErr != nil {
+					klog.Warningf("failed to release replacement replicas of node %s: %v", nodeIP, releaseErr)
+				}
+			}()
+
 			// current drainNode behavior is similar to ray stop, and the ray community will optimize it later.
 			err = c.drainNode(reconcileCtx, node.Raylet.NodeID, "DRAIN_NODE_REASON_PREEMPTION", "stop node", 600)
 			if err != nil {
@@ -270,6 +296,8 @@ func (c *sshRayClusterReconciler) buildAcceleratorDockerConfig(reconcileCtx *Rec
 		return v1.Docker{}, false, errors.Wrap(err, "failed to get node runtime config")
 	}
 
+	runtimeConfig = applyAcceleratorRuntimeOverride(runtimeConfig, reconcileCtx.sshClusterConfig.AcceleratorRuntime)
+
 	changed := false
 	image := base.Image
 
@@ -305,6 +333,86 @@ func (c *sshRayClusterReconciler) buildAcceleratorDockerConfig(reconcileCtx *Rec
 	return dockerConfig, changed, nil
 }
 
+// applyAcceleratorRuntimeOverride merges the cluster-config override into the plugin runtime
+// config. Override env wins over plugin env with the same name, and override options replace
+// plugin options using the same flag unless docker run accepts the flag several times, e.g.
+// --device, those are added to the plugin options; all other plugin values are kept.
+func applyAcceleratorRuntimeOverride(rc v1.RuntimeConfig, override *v1.AcceleratorRuntimeOverride) v1.RuntimeConfig {
+	if override == nil {
+		return rc
+	}
+
+	if len(override.Env) > 0 {
+		env := make(map[string]string, len(rc.Env)+len(override.Env))
+
+		for k, v := range rc.Env {
+			env[k] = v
+		}
+
+		for k, v := range override.Env {
+			env[k] = v
+		}
+
+		rc.Env = env
+	}
+
+	if len(override.Options) > 0 {
+		overridden := map[string]bool{}
+
+		for _, option := range override.Options {
+			if flag := runOptionFlag(option); !repeatableRunOptionFlags[flag] {
+				overridden[flag] = true
+			}
+		}
+
+		options := make([]string, 0, len(rc.Options)+len(override.Options))
+
+		for _, option := range rc.Options {
+			if !overridden[runOptionFlag(option)] {
+				options = append(options, option)
+			}
+		}
+
+		rc.Options = append(options, override.Options...)
+	}
+
+	return rc
+}
+
+// repeatableRunOptionFlags are the docker run flags that can be given several times, an
+// override option using one of them is added to the plugin options instead of replacing them.
+var repeatableRunOptionFlags = map[string]bool{
+	"--device":             true,
+	"--device-cgroup-rule": true,
+	"-v":                   true,
+	"--volume":             true,
+	"--mount":              true,
+	"--tmpfs":              true,
+	"-e":                   true,
+	"--env":                true,
+	"--env-file":           true,
+	"--cap-add":            true,
+	"--cap-drop":           true,
+	"--group-add":          true,
+	"--security-opt":       true,
+	"--ulimit":             true,
+	"--sysctl":             true,
+	"--add-host":           true,
+	"-l":                   true,
+	"--label":              true,
+}
+
+// runOptionFlag returns the flag of a docker run option, e.g. "--gpus" for "--gpus all"
+// and "--device" for "--device=/dev/kfd".
+func runOptionFlag(option string) string {
+	option = strings.TrimSpace(option)
+	if i := strings.IndexAny(option, " ="); i >= 0 {
+		return option[:i]
+	}
+
+	return option
+}
+
 // setDefaultRayClusterConfig set default ray cluster config.
 func (c *sshRayClusterReconciler) generateRayClusterConfig(reconcileContext *ReconcileContext) (*v1.RayClusterConfig, error) {
 	rayClusterConfig := &v1.RayClusterConfig{}
@@ -1,10 +1,12 @@
 package cluster
 
 import (
+	"context"
 	"fmt"
 	"path"
 	"strings"
 	"testing"
+	"time"
 
 	v1 "github.com/neutree-ai/neutree/api/v1"
 	acceleratormocks "github.com/neutree-ai/neutree/internal/accelerator/mocks"
@@ -14,7 +16,9 @@ import (
 	"github.com/stretchr/testify/mock"
 	corev1 "k8s.io/api/core/v1"
 
+	"github.com/neutree-ai/neutree/internal/ray/dashboard"
 	dashboardmocks "github.com/neutree-ai/neutree/internal/ray/dashboard/mocks"
+	"github.com/neutree-ai/neutree/internal/ray/rayserve"
 	commandmocks "github.com/neutree-ai/neutree/pkg/command/mocks"
 )
 
@@ -24,6 +28,7 @@ func TestBuildAcceleratorDockerConfig(t *testing.T) {
 	tests := []struct {
 		name        string
 		input       *v1.RayClusterConfig
+		override    *v1.AcceleratorRuntimeOverride
 		want        *v1.RayClusterConfig
 		setupMock   func(*acceleratormocks.MockManager)
 		wantErr     bool
@@ -84,6 +89,136 @@ func TestBuildAcceleratorDockerConfig(t *testing.T) {
 			wantErr:     false,
 			wantChanged: false,
 		},
+		{
+			name: "merge cluster override with plugin runtime config",
+			input: &v1.RayClusterConfig{
+				Docker: v1.Docker{
+					Image:      "rayproject/ray:latest",
+					RunOptions: []string{},
+				},
+			},
+			override: &v1.AcceleratorRuntimeOverride{
+				Options: []string{"--gpus device=0", "--device=/dev/infiniband"},
+				Env: map[string]string{
+					"CUDA_VERSION": "12.4",
+					"NCCL_DEBUG":   "INFO",
+				},
+			},
+			want: &v1.RayClusterConfig{
+				Docker: v1.Docker{
+					Image: "rayproject/ray:latest-test",
+					RunOptions: []string{
+						"--shm-size=8g",
+						"--gpus device=0",
+						"--device=/dev/infiniband",
+						"-e DRIVER_VERSION=450.80.02",
+						"-e CUDA_VERSION=12.4",
+						"-e NCCL_DEBUG=INFO",
+					},
+				},
+			},
+			setupMock: func(m *acceleratormocks.MockManager) {
+				m.On("GetNodeRuntimeConfig", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(
+					v1.RuntimeConfig{
+						ImageSuffix: "test",
+						Env: map[string]string{
+							"DRIVER_VERSION": "450.80.02",
+							"CUDA_VERSION":   "11.0",
+						},
+						Options: []string{
+							"--gpus all",
+							"--shm-size=8g",
+						},
+					}, nil)
+			},
+			wantErr:     false,
+			wantChanged: true,
+		},
+		{
+			name: "add repeatable override options to plugin options using the same flag",
+			input: &v1.RayClusterConfig{
+				Docker: v1.Docker{
+					Image:      "rayproject/ray:latest",
+					RunOptions: []string{},
+				},
+			},
+			override: &v1.AcceleratorRuntimeOverride{
+				Options: []string{"--device=/dev/infiniband", "-v /data:/data", "--shm-size=16g"},
+			},
+			want: &v1.RayClusterConfig{
+				Docker: v1.Docker{
+					Image: "rayproject/ray:latest-test",
+					RunOptions: []string{
+						"--device=/dev/kfd",
+						"--device=/dev/dri",
+						"-v /opt/rocm:/opt/rocm",
+						"--device=/dev/infiniband",
+						"-v /data:/data",
+						"--shm-size=16g",
+					},
+				},
+			},
+			setupMock: func(m *acceleratormocks.MockManager) {
+				m.On("GetNodeRuntimeConfig", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(
+					v1.RuntimeConfig{
+						ImageSuffix: "test",
+						Options: []string{
+							"--device=/dev/kfd",
+							"--device=/dev/dri",
+							"-v /opt/rocm:/opt/rocm",
+							"--shm-size=8g",
+						},
+					}, nil)
+			},
+			wantErr:     false,
+			wantChanged: true,
+		},
+		{
+			name: "apply cluster override without plugin runtime config",
+			input: &v1.RayClusterConfig{
+				Docker: v1.Docker{
+					Image:      "rayproject/ray:latest",
+					RunOptions: []string{},
+				},
+			},
+			override: &v1.AcceleratorRuntimeOverride{
+				Options: []string{"--device=/dev/dri"},
+			},
+			want: &v1.RayClusterConfig{
+				Docker: v1.Docker{
+					Image:      "rayproject/ray:latest",
+					RunOptions: []string{"--device=/dev/dri"},
+				},
+			},
+			setupMock: func(m *acceleratormocks.MockManager) {
+				m.On("GetNodeRuntimeConfig", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(v1.RuntimeConfig{},
+					nil)
+			},
+			wantErr:     false,
+			wantChanged: true,
+		},
+		{
+			name: "empty cluster override, never changed",
+			input: &v1.RayClusterConfig{
+				Docker: v1.Docker{
+					Image:      "rayproject/ray:latest",
+					RunOptions: []string{},
+				},
+			},
+			override: &v1.AcceleratorRuntimeOverride{},
+			want: &v1.RayClusterConfig{
+				Docker: v1.Docker{
+					Image:      "rayproject/ray:latest",
+					RunOptions: []string{},
+				},
+			},
+			setupMock: func(m *acceleratormocks.MockManager) {
+				m.On("GetNodeRuntimeConfig", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(v1.RuntimeConfig{},
+					nil)
+			},
+			wantErr:     false,
+			wantChanged: false,
+		},
 		{
 			name: "mutate accelerator runtime config not found",
 			input: &v1.RayClusterConfig{
@@ -109,7 +244,7 @@ func TestBuildAcceleratorDockerConfig(t *testing.T) {
 			}
 
 			reconcileCtx := &ReconcileContext{
-				sshClusterConfig:    &v1.RaySSHProvisionClusterConfig{},
+				sshClusterConfig:    &v1.RaySSHProvisionClusterConfig{AcceleratorRuntime: tt.override},
 				sshRayClusterConfig: tt.input,
 				Cluster: &v1.Cluster{
 					Status: &v1.ClusterStatus{
@@ -820,6 +955,105 @@ func TestStopNode(t *testing.T) {
 	}
 }
 
+func TestStopNodeWarmReplacesReplicasBeforeDrain(t *testing.T) {
+	rayserve.WarmReplacementPollInterval = time.Millisecond
+
+	t.Cleanup(func() { rayserve.WarmReplacementPollInterval = 5 * time.Second })
+
+	var events []string
+
+	mockCmdExecutor := commandmocks.NewMockExecutor(t)
+	mockCmdExecutor.EXPECT().Execute(mock.Anything, mock.Anything, mock.Anything).RunAndReturn(
+		func(_ context.Context, _ string, args []string) ([]byte, error) {
+			cmd := strings.Join(args, " ")
+
+			switch {
+			case strings.Contains(cmd, "command -v"):
+				return []byte("docker"), nil
+			case strings.Contains(cmd, "inspect"):
+				return []byte("true"), nil
+			case strings.Contains(cmd, "drain-node"):
+				events = append(events, "drain")
+			case strings.Contains(cmd, "docker stop"):
+				events = append(events, "docker stop")
+			case strings.Contains(cmd, "ray stop"):
+				events = append(events, "ray stop")
+			}
+
+			return []byte("success"), nil
+		})
+
+	backend := func(numReplicas int, replicas ...dashboard.Replica) dashboard.RayServeApplicationStatus {
+		return dashboard.RayServeApplicationStatus{
+			DeployedAppConfig: &dashboard.RayServeApplication{
+				Name: "chat",
+				Args: map[string]interface{}{
+					"deployment_options": map[string]interface{}{
+						"backend": map[string]interface{}{"num_replicas": float64(numReplicas)},
+					},
+				},
+			},
+			Deployments: map[string]dashboard.Deployment{
+				"Backend": {Name: "Backend", Replicas: replicas},
+			},
+		}
+	}
+	replica := func(nodeID, state string) dashboard.Replica {
+		return dashboard.Replica{NodeID: nodeID, State: state}
+	}
+
+	// the replacement is starting on node-2, then running, then the drained replica is gone.
+	states := []dashboard.RayServeApplicationStatus{
+		backend(1, replica("node-1", "RUNNING")),
+		backend(2, replica("node-1", "RUNNING"), replica("node-2", "STARTING")),
+		backend(2, replica("node-1", "RUNNING"), replica("node-2", "RUNNING")),
+		backend(2, replica("node-2", "RUNNING")),
+	}
+
+	mockDashboardService := dashboardmocks.NewMockDashboardService(t)
+	mockDashboardService.EXPECT().ListNodes().Return([]v1.NodeSummary{
+		{IP: "10.0.0.1", Raylet: v1.Raylet{NodeID: "node-1", State: v1.AliveNodeState}},
+	}, nil)
+	mockDashboardService.EXPECT().GetServeApplications().RunAndReturn(func() (*dashboard.RayServeApplicationsResponse, error) {
+		state := states[0]
+		if len(states) > 1 {
+			states = states[1:]
+		}
+
+		events = append(events, fmt.Sprintf("observe %d", len(state.Deployments["Backend"].Replicas)))
+
+		return &dashboard.RayServeApplicationsResponse{
+			Applications: map[string]dashboard.RayServeApplicationStatus{"chat": state},
+		}, nil
+	})
+	mockDashboardService.EXPECT().UpdateServeApplications(mock.Anything).Run(func(req dashboard.RayServeApplicationsRequest) {
+		n, _ := rayserve.BackendNumReplicas(&req.Applications[0])
+		events = append(events, fmt.Sprintf("scale to %d", n))
+	}).Return(nil)
+
+	sshRayClusterReconciler := &sshRayClusterReconciler{
+		executor: mockCmdExecutor,
+	}
+
+	err := sshRayClusterReconciler.stopNode(&ReconcileContext{
+		Ctx:                 context.Background(),
+		Cluster:             &v1.Cluster{Metadata: &v1.Metadata{Name: "ssh", Workspace: "default"}},
+		rayService:          mockDashboardService,
+		sshRayClusterConfig: &v1.RayClusterConfig{},
+		sshClusterConfig:    &v1.RaySSHProvisionClusterConfig{},
+		sshConfigGenerator:  newRaySSHLocalConfigGenerator("test"),
+	}, "10.0.0.1", false)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"observe 1", "scale to 2",
+		// the drain waits for the replacement replica to run.
+		"observe 2", "observe 2",
+		"drain", "ray stop", "docker stop",
+		"observe 1", "scale to 1",
+	}, events)
+}
+
 func TestDownCluster(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -39,8 +39,11 @@ type ReconcileContext struct {
 	sshClusterConfig    *v1.RaySSHProvisionClusterConfig
 	sshRayClusterConfig *v1.RayClusterConfig
 	sshConfigGenerator  *raySSHLocalConfigGenerator
-	processMessages     []string
-	lock                sync.Mutex
+	// sshExecutor, when set, runs the ssh commands of the reconcile instead of the
+	// reconciler executor.
+	sshExecutor     command.Executor
+	processMessages []string
+	lock            sync.Mutex
 
 	rayService dashboard.DashboardService
 
@@ -62,6 +65,7 @@ func NewReconcile(cluster *v1.Cluster, acceleratorManager accelerator.Manager,
 			executor:           &command.OSExecutor{},
 			acceleratorManager: acceleratorManager,
 			storage:            s,
+			sshConnectionReuse: true,
 		}
 
 		useStaticFlow, err := isStaticNodeClusterFlowVersion(cluster.GetVersion())
@@ -35,6 +35,9 @@ func ComputeClusterSpecHash(spec *v1.ClusterSpec) string {
 		specCopy.Config.SSHConfig.Auth.SSHPrivateKey = ""
 	}
 
+	// Exclude maintenance - pausing the reconcile does not change the cluster
+	specCopy.Maintenance = nil
+
 	cleanJSON, err := json.Marshal(specCopy)
 	if err != nil {
 		klog.Warningf("ComputeClusterSpecHash: failed to marshal cleaned spec: %v", err)
@@ -85,6 +85,18 @@ func TestComputeClusterSpecHash_ExcludesKubeconfig(t *testing.T) {
 		"changing only Kubeconfig should not change the hash")
 }
 
+func TestComputeClusterSpecHash_ExcludesMaintenance(t *testing.T) {
+	base := &v1.ClusterSpec{Type: "ssh", Version: "v1.0.0"}
+	paused := &v1.ClusterSpec{
+		Type:        "ssh",
+		Version:     "v1.0.0",
+		Maintenance: &v1.ClusterMaintenanceSpec{Paused: true},
+	}
+
+	assert.Equal(t, ComputeClusterSpecHash(base), ComputeClusterSpecHash(paused),
+		"pausing the reconcile should not change the hash")
+}
+
 func TestComputeClusterSpecHash_SpecChangeProducesDifferentHash(t *testing.T) {
 	base := &v1.ClusterSpec{
 		Type:          "ssh",
@@ -318,6 +318,7 @@ func (r *staticRayReconciler) buildStaticCluster(c *v1.Cluster) (*v1.StaticNodeC
 			Metrics:         copyStaticClusterMetricsConfig(c.Spec.Config),
 			Nodes:           nodes,
 			UpgradeStrategy: v1.DefaultClusterUpgradeStrategy(),
+			ImageGC:         copyStaticClusterImageGCConfig(sshConfig.ImageGC),
 		},
 	}, nil
 }
@@ -337,6 +338,20 @@ func copyStaticClusterMetricsConfig(config *v1.ClusterConfig) *v1.ClusterMetrics
 	return copied
 }
 
+func copyStaticClusterImageGCConfig(config *v1.ImageGCConfig) *v1.ImageGCConfig {
+	if config == nil {
+		return nil
+	}
+
+	copied := *config
+	if config.IntervalSeconds != nil {
+		interval := *config.IntervalSeconds
+		copied.IntervalSeconds = &interval
+	}
+
+	return &copied
+}
+
 func (r *staticRayReconciler) findStaticCluster(
 	workspace string,
 	name string,
@@ -352,7 +367,7 @@ func (r *staticRayReconciler) findStaticCluster(
 		})
 	}
 
-	clusters, err := r.storage.ListStaticNodeCluster(storage.ListOption{Filters: filters})
+	clusters, err := r.storage.ListStaticNodeCluster(storage.ListOption{Filters: filters, IncludeDeleted: true})
 	if err != nil {
 		return nil, false, err
 	}
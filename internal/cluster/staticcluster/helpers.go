@@ -2,6 +2,7 @@ package staticcluster
 
 import (
 	"context"
+	"strings"
 
 	"github.com/pkg/errors"
 
@@ -82,6 +83,25 @@ func staticComponentImage(cluster *v1.StaticNodeCluster, image string) string {
 	return util.RewriteImageRef(imageRegistry, image)
 }
 
+// buildNodeImageGCSpec scopes the image garbage collection of the cluster to the images
+// of its registry prefix.
+func buildNodeImageGCSpec(cluster *v1.StaticNodeCluster) *v1.StaticNodeImageGCSpec {
+	if cluster == nil || cluster.Spec == nil || cluster.Spec.ImageGC == nil {
+		return nil
+	}
+
+	spec := &v1.StaticNodeImageGCSpec{
+		ImageGCConfig: *cluster.Spec.ImageGC,
+		ImagePrefix:   strings.TrimRight(cluster.Spec.ImageRegistry, "/"),
+	}
+	if cluster.Spec.ImageGC.IntervalSeconds != nil {
+		interval := *cluster.Spec.ImageGC.IntervalSeconds
+		spec.IntervalSeconds = &interval
+	}
+
+	return spec
+}
+
 func copyAuth(auth *v1.Auth) *v1.Auth {
 	if auth == nil {
 		return nil
@@ -102,6 +102,7 @@ func (r *Planner) buildDesiredNodePlans(
 				Role:    role,
 				SSHAuth: copyAuth(nodeSpec.SSHAuth),
 				Warm:    &v1.WarmSpec{},
+				ImageGC: buildNodeImageGCSpec(cluster),
 			},
 		}
 
@@ -743,6 +743,29 @@ func TestPlannerBuildsDiscoverySafeNodesBeforeAcceleratorStatus(t *testing.T) {
 	}
 }
 
+func TestPlannerScopesImageGCToClusterRegistry(t *testing.T) {
+	cluster := testStaticNodeCluster()
+
+	for _, node := range plannedStaticNodes(t, &Planner{}, cluster, nil) {
+		assert.Nil(t, node.Spec.ImageGC)
+	}
+
+	interval := 3600
+	cluster.Spec.ImageRegistry = "registry.example.com/neutree/"
+	cluster.Spec.ImageGC = &v1.ImageGCConfig{IntervalSeconds: &interval, DryRun: true}
+
+	nodes := plannedStaticNodes(t, &Planner{}, cluster, nil)
+
+	require.Len(t, nodes, 2)
+
+	for _, node := range nodes {
+		assert.Equal(t, &v1.StaticNodeImageGCSpec{
+			ImageGCConfig: v1.ImageGCConfig{IntervalSeconds: &interval, DryRun: true},
+			ImagePrefix:   "registry.example.com/neutree",
+		}, node.Spec.ImageGC)
+	}
+}
+
 func TestPlannerPlanWaitsForDesiredComponents(t *testing.T) {
 	cluster := testStaticNodeCluster()
 	currentNodes := []*v1.StaticNode{
@@ -15,6 +15,7 @@ func ListByCluster(
 			{Column: "metadata->>workspace", Operator: "eq", Value: workspace},
 			{Column: "spec->>cluster", Operator: "eq", Value: clusterName},
 		},
+		IncludeDeleted: true,
 	})
 	if err != nil {
 		return nil, err
@@ -0,0 +1,203 @@
+package staticnode
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	v1 "github.com/neutree-ai/neutree/api/v1"
+)
+
+const (
+	imageGCReasonListFailed   = "ImageListFailed"
+	imageGCReasonRemoveFailed = "ImageRemoveFailed"
+
+	dockerNoneValue = "<none>"
+)
+
+// localImage is an image stored on a static node.
+type localImage struct {
+	Repository string
+	Tag        string
+	ID         string
+}
+
+// ref returns the reference an image is removed by: its tag, or its ID if it has none.
+func (i localImage) ref() string {
+	if i.Repository == dockerNoneValue || i.Tag == dockerNoneValue {
+		return i.ID
+	}
+
+	return i.Repository + ":" + i.Tag
+}
+
+func (i localImage) dangling() bool {
+	return i.Repository == dockerNoneValue && i.Tag == dockerNoneValue
+}
+
+// ListImages lists the images stored on the node.
+func (r DockerRuntime) ListImages(ctx context.Context) ([]localImage, error) {
+	output, err := r.runner.Run(ctx, "docker images --no-trunc --format '{{.Repository}} {{.Tag}} {{.ID}}'")
+	if err != nil {
+		return nil, dockerError(imageGCReasonListFailed, "failed to list images", err)
+	}
+
+	var images []localImage
+
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		// skip anything but image lines, e.g. an SSH banner.
+		if len(fields) != 3 || !strings.HasPrefix(fields[2], "sha256:") {
+			continue
+		}
+
+		images = append(images, localImage{Repository: fields[0], Tag: fields[1], ID: fields[2]})
+	}
+
+	return images, nil
+}
+
+// UsedImageIDs returns the IDs of the images referenced by a container on the node,
+// running or not.
+func (r DockerRuntime) UsedImageIDs(ctx context.Context) (map[string]struct{}, error) {
+	output, err := r.runner.Run(ctx, "docker ps -aq | xargs -r docker inspect --format '{{.Image}}'")
+	if err != nil {
+		return nil, dockerError(imageGCReasonListFailed, "failed to list container images", err)
+	}
+
+	used := map[string]struct{}{}
+
+	for _, line := range strings.Split(output, "\n") {
+		if id := strings.TrimSpace(line); strings.HasPrefix(id, "sha256:") {
+			used[id] = struct{}{}
+		}
+	}
+
+	return used, nil
+}
+
+// RemoveImage removes an image reference from the node.
+func (r DockerRuntime) RemoveImage(ctx context.Context, ref string) error {
+	if _, err := r.runner.Run(ctx, "docker rmi "+shellArg(ref)); err != nil {
+		return dockerError(imageGCReasonRemoveFailed, fmt.Sprintf("failed to remove image %s", ref), err)
+	}
+
+	return nil
+}
+
+// ReconcileImageGC removes the dangling images and the unused images of the node image
+// prefix, at most once per configured interval. Images referenced by a container or by
+// the node spec are kept. It returns nil if image garbage collection is not enabled.
+func (r *Reconciler) ReconcileImageGC(
+	ctx context.Context,
+	node *v1.StaticNode,
+	dockerRuntime DockerRuntime,
+) (*v1.ImageGCStatus, error) {
+	if node == nil || node.Spec == nil || node.Spec.ImageGC == nil {
+		return nil, nil
+	}
+
+	var previous *v1.ImageGCStatus
+	if node.Status != nil {
+		previous = node.Status.ImageGC
+	}
+
+	if previous != nil && previous.DryRun == node.Spec.ImageGC.DryRun {
+		lastRun, err := time.Parse(time.RFC3339, previous.LastRunTime)
+		if err == nil && time.Since(lastRun) < node.Spec.ImageGC.Interval() {
+			return previous, nil
+		}
+	}
+
+	images, err := dockerRuntime.ListImages(ctx)
+	if err != nil {
+		return previous, err
+	}
+
+	used, err := dockerRuntime.UsedImageIDs(ctx)
+	if err != nil {
+		return previous, err
+	}
+
+	status := &v1.ImageGCStatus{
+		LastRunTime: time.Now().UTC().Format(time.RFC3339),
+		DryRun:      node.Spec.ImageGC.DryRun,
+	}
+
+	candidates := imageGCCandidates(images, used, staticNodeImageRefs(node), node.Spec.ImageGC.ImagePrefix)
+	if node.Spec.ImageGC.DryRun {
+		status.Images = candidates
+
+		return status, nil
+	}
+
+	var failed []string
+
+	for _, ref := range candidates {
+		if err := dockerRuntime.RemoveImage(ctx, ref); err != nil {
+			// the image may have been taken in use since it was listed.
+			failed = append(failed, ref)
+
+			continue
+		}
+
+		status.Images = append(status.Images, ref)
+	}
+
+	if len(failed) > 0 {
+		status.Message = "failed to remove images " + strings.Join(failed, ", ")
+	}
+
+	return status, nil
+}
+
+// imageGCCandidates returns the references of the images to remove: dangling images and
+// images of the prefix that are neither used by a container nor kept.
+func imageGCCandidates(images []localImage, used, keep map[string]struct{}, prefix string) []string {
+	var candidates []string
+
+	seen := map[string]struct{}{}
+
+	for _, image := range images {
+		if _, ok := used[image.ID]; ok {
+			continue
+		}
+
+		if !image.dangling() && (prefix == "" || !strings.HasPrefix(image.Repository, prefix+"/")) {
+			continue
+		}
+
+		ref := image.ref()
+		if _, ok := keep[ref]; ok {
+			continue
+		}
+
+		if _, ok := seen[ref]; ok {
+			continue
+		}
+
+		seen[ref] = struct{}{}
+		candidates = append(candidates, ref)
+	}
+
+	return candidates
+}
+
+// staticNodeImageRefs returns the images the node spec refers to, which are kept even if
+// no container uses them yet, e.g. images warmed up ahead of an upgrade.
+func staticNodeImageRefs(node *v1.StaticNode) map[string]struct{} {
+	refs := map[string]struct{}{}
+
+	if node.Spec.Warm != nil {
+		for _, image := range node.Spec.Warm.Images {
+			refs[image.Ref] = struct{}{}
+		}
+	}
+
+	for _, component := range node.Spec.Components {
+		refs[component.Image] = struct{}{}
+	}
+
+	return refs
+}
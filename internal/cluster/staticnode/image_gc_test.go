@@ -0,0 +1,159 @@
+package staticnode
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	v1 "github.com/neutree-ai/neutree/api/v1"
+)
+
+const (
+	testImageList = "registry.example.com/neutree/neutree-serve v1.1.0 sha256:old-serve\n" +
+		"registry.example.com/neutree/neutree-serve v1.2.0 sha256:serve\n" +
+		"registry.example.com/neutree/vllm v0.8.5 sha256:engine\n" +
+		"registry.example.com/neutree/vllm v0.7.0 sha256:old-engine\n" +
+		"registry.example.com/neutree/node-agent v1.2.0 sha256:agent\n" +
+		"<none> <none> sha256:dangling\n" +
+		"docker.io/library/postgres 16 sha256:postgres\n"
+	testUsedImageIDs = "sha256:serve\nsha256:engine\n"
+)
+
+func staticNodeWithImageGC(dryRun bool, status *v1.ImageGCStatus) *v1.StaticNode {
+	return &v1.StaticNode{
+		Metadata: &v1.Metadata{Workspace: "default", Name: "node-1"},
+		Spec: &v1.StaticNodeSpec{
+			Warm: &v1.WarmSpec{Images: []v1.WarmImageSpec{
+				{Name: "node-agent", Ref: "registry.example.com/neutree/node-agent:v1.2.0", Required: true},
+			}},
+			ImageGC: &v1.StaticNodeImageGCSpec{
+				ImageGCConfig: v1.ImageGCConfig{DryRun: dryRun},
+				ImagePrefix:   "registry.example.com/neutree",
+			},
+		},
+		Status: &v1.StaticNodeStatus{ImageGC: status},
+	}
+}
+
+func TestReconcilerReconcileImageGC(t *testing.T) {
+	listResponses := []fakeStaticNodeResponse{
+		{command: "docker images --no-trunc --format '{{.Repository}} {{.Tag}} {{.ID}}'", output: testImageList},
+		{command: "docker ps -aq | xargs -r docker inspect --format '{{.Image}}'", output: testUsedImageIDs},
+	}
+
+	tests := []struct {
+		name        string
+		node        *v1.StaticNode
+		responses   []fakeStaticNodeResponse
+		wantStatus  *v1.ImageGCStatus
+		wantImages  []string
+		wantMessage string
+		wantErr     bool
+	}{
+		{
+			name: "not enabled",
+			node: &v1.StaticNode{Spec: &v1.StaticNodeSpec{}},
+		},
+		{
+			name: "removes dangling and unused neutree images",
+			node: staticNodeWithImageGC(false, nil),
+			responses: append(append([]fakeStaticNodeResponse{}, listResponses...),
+				fakeStaticNodeResponse{command: "docker rmi 'registry.example.com/neutree/neutree-serve:v1.1.0'"},
+				fakeStaticNodeResponse{command: "docker rmi 'registry.example.com/neutree/vllm:v0.7.0'"},
+				fakeStaticNodeResponse{command: "docker rmi 'sha256:dangling'"},
+			),
+			wantImages: []string{
+				"registry.example.com/neutree/neutree-serve:v1.1.0",
+				"registry.example.com/neutree/vllm:v0.7.0",
+				"sha256:dangling",
+			},
+		},
+		{
+			name:      "dry run only lists the images",
+			node:      staticNodeWithImageGC(true, nil),
+			responses: listResponses,
+			wantImages: []string{
+				"registry.example.com/neutree/neutree-serve:v1.1.0",
+				"registry.example.com/neutree/vllm:v0.7.0",
+				"sha256:dangling",
+			},
+		},
+		{
+			name: "keeps going when an image fails to be removed",
+			node: staticNodeWithImageGC(false, nil),
+			responses: append(append([]fakeStaticNodeResponse{}, listResponses...),
+				fakeStaticNodeResponse{command: "docker rmi 'registry.example.com/neutree/neutree-serve:v1.1.0'", err: errors.New("image is in use")},
+				fakeStaticNodeResponse{command: "docker rmi 'registry.example.com/neutree/vllm:v0.7.0'"},
+				fakeStaticNodeResponse{command: "docker rmi 'sha256:dangling'"},
+			),
+			wantImages:  []string{"registry.example.com/neutree/vllm:v0.7.0", "sha256:dangling"},
+			wantMessage: "failed to remove images registry.example.com/neutree/neutree-serve:v1.1.0",
+		},
+		{
+			name: "skips collection within the interval",
+			node: staticNodeWithImageGC(false, &v1.ImageGCStatus{
+				LastRunTime: time.Now().Add(-time.Hour).UTC().Format(time.RFC3339),
+				Images:      []string{"sha256:dangling"},
+			}),
+			wantStatus: &v1.ImageGCStatus{Images: []string{"sha256:dangling"}},
+		},
+		{
+			name: "collects again after the interval",
+			node: staticNodeWithImageGC(true, &v1.ImageGCStatus{
+				LastRunTime: time.Now().Add(-25 * time.Hour).UTC().Format(time.RFC3339),
+				DryRun:      true,
+			}),
+			responses: listResponses,
+			wantImages: []string{
+				"registry.example.com/neutree/neutree-serve:v1.1.0",
+				"registry.example.com/neutree/vllm:v0.7.0",
+				"sha256:dangling",
+			},
+		},
+		{
+			name: "list failure keeps the previous status",
+			node: staticNodeWithImageGC(false, nil),
+			responses: []fakeStaticNodeResponse{
+				{command: "docker images --no-trunc --format '{{.Repository}} {{.Tag}} {{.ID}}'", err: errors.New("docker not running")},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := &fakeStaticNodeRunner{responses: tt.responses}
+			dockerRuntime := newTestDockerRuntime(t, runner, nil)
+
+			status, err := (&Reconciler{}).ReconcileImageGC(context.Background(), tt.node, dockerRuntime)
+			assert.Equal(t, len(runner.responses), runner.calls)
+
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Nil(t, status)
+
+				return
+			}
+
+			require.NoError(t, err)
+
+			switch {
+			case tt.node.Spec.ImageGC == nil:
+				assert.Nil(t, status)
+			case tt.wantStatus != nil:
+				assert.Equal(t, tt.wantStatus.Images, status.Images)
+				assert.Equal(t, tt.node.Status.ImageGC.LastRunTime, status.LastRunTime)
+			default:
+				require.NotNil(t, status)
+				assert.Equal(t, tt.wantImages, status.Images)
+				assert.Equal(t, tt.wantMessage, status.Message)
+				assert.Equal(t, tt.node.Spec.ImageGC.DryRun, status.DryRun)
+				assert.NotEmpty(t, status.LastRunTime)
+			}
+		})
+	}
+}
@@ -60,6 +60,7 @@ type ReconcileResult struct {
 	Allocations []v1.StaticNodeAllocationStatus
 	Warm        *v1.WarmStatus
 	Components  []v1.NodeComponentStatus
+	ImageGC     *v1.ImageGCStatus
 }
 
 type AcceleratorManager interface {
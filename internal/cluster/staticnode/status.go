@@ -32,6 +32,10 @@ func BuildStatus(
 		if result.Components != nil {
 			status.Components = result.Components
 		}
+
+		if result.ImageGC != nil {
+			status.ImageGC = result.ImageGC
+		}
 	}
 
 	if reconcileErr != nil {
@@ -1,12 +1,17 @@
 package cluster
 
 import (
+	"bytes"
+	"context"
 	"encoding/base64"
 	"fmt"
 
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	v1 "github.com/neutree-ai/neutree/api/v1"
 	"github.com/neutree-ai/neutree/internal/util"
@@ -74,6 +79,77 @@ func generateImagePullSecret(ns string, imageRegistry *v1.ImageRegistry) (*corev
 	}, nil
 }
 
+// imagePullSecretRotated reports whether the credential of the desired image pull secret differs
+// from the one in the cluster. A missing secret is created, not rotated.
+func imagePullSecretRotated(ctx context.Context, ctrClient client.Client, desired *corev1.Secret) (bool, error) {
+	current := &corev1.Secret{}
+
+	err := ctrClient.Get(ctx, client.ObjectKeyFromObject(desired), current)
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, errors.Wrap(err, "failed to get image pull secret")
+	}
+
+	return !bytes.Equal(current.Data[corev1.DockerConfigJsonKey], desired.Data[corev1.DockerConfigJsonKey]), nil
+}
+
+// restartImagePullFailedPods deletes the pods of the namespace failing to pull their images with
+// the image pull secret, so their controllers recreate them pulling with the rotated credential
+// right away. Running pods already have their images and are left alone, as are pods without a
+// controller, which would not be recreated.
+func restartImagePullFailedPods(ctx context.Context, ctrClient client.Client, namespace string) error {
+	pods := &corev1.PodList{}
+	if err := ctrClient.List(ctx, pods, client.InNamespace(namespace)); err != nil {
+		return errors.Wrap(err, "failed to list pods")
+	}
+
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if metav1.GetControllerOf(pod) == nil || !usesImagePullSecret(pod) || !failingImagePull(pod) {
+			continue
+		}
+
+		klog.Infof("Restarting pod %s/%s failing to pull its image", pod.Namespace, pod.Name)
+
+		if err := ctrClient.Delete(ctx, pod); client.IgnoreNotFound(err) != nil {
+			return errors.Wrapf(err, "failed to delete pod %s", pod.Name)
+		}
+	}
+
+	return nil
+}
+
+func usesImagePullSecret(pod *corev1.Pod) bool {
+	for _, ref := range pod.Spec.ImagePullSecrets {
+		if ref.Name == ImagePullSecretName {
+			return true
+		}
+	}
+
+	return false
+}
+
+func failingImagePull(pod *corev1.Pod) bool {
+	statuses := append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...),
+		pod.Status.ContainerStatuses...)
+
+	for _, status := range statuses {
+		if status.State.Waiting == nil {
+			continue
+		}
+
+		switch status.State.Waiting.Reason {
+		case "ErrImagePull", "ImagePullBackOff":
+			return true
+		}
+	}
+
+	return false
+}
+
 func getUsedImageRegistries(cluster *v1.Cluster, s storage.Storage) (*v1.ImageRegistry, error) {
 	imageRegistryFilter := []storage.Filter{
 		{
@@ -91,13 +167,13 @@ func getUsedImageRegistries(cluster *v1.Cluster, s storage.Storage) (*v1.ImageRe
 		})
 	}
 
-	imageRegistryList, err := s.ListImageRegistry(storage.ListOption{Filters: imageRegistryFilter})
+	imageRegistryList, err := s.ListImageRegistry(storage.ListOption{Filters: imageRegistryFilter, IncludeDeleted: true})
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to list image registry")
 	}
 
 	if len(imageRegistryList) == 0 {
-		return nil, storage.ErrResourceNotFound
+		return nil, errors.Wrapf(storage.ErrResourceNotFound, "image registry %s is missing", cluster.Spec.ImageRegistry)
 	}
 
 	targetImageRegistry := &imageRegistryList[0]
@@ -1,12 +1,20 @@
 package cluster
 
 import (
+	"context"
 	"testing"
 
 	v1 "github.com/neutree-ai/neutree/api/v1"
 	"github.com/neutree-ai/neutree/internal/util"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	storagemocks "github.com/neutree-ai/neutree/pkg/storage/mocks"
 )
@@ -55,6 +63,103 @@ func TestGenerateImagePullSecret(t *testing.T) {
 	}
 }
 
+func TestImagePullSecretRotation(t *testing.T) {
+	namespace := "neutree-cluster-test"
+	newRegistry := func(password string) *v1.ImageRegistry {
+		return &v1.ImageRegistry{
+			Spec: &v1.ImageRegistrySpec{
+				AuthConfig: v1.ImageRegistryAuthConfig{Username: "test-user", Password: password},
+				URL:        "https://registry.example.com",
+				Repository: "my-repo",
+			},
+		}
+	}
+	newPod := func(name string, waitingReason string, controlled bool) *corev1.Pod {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec: corev1.PodSpec{
+				ImagePullSecrets: []corev1.LocalObjectReference{{Name: ImagePullSecretName}},
+			},
+		}
+		if controlled {
+			pod.OwnerReferences = []metav1.OwnerReference{{
+				APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "router", UID: "uid", Controller: ptr.To(true),
+			}}
+		}
+		if waitingReason != "" {
+			pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+				State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: waitingReason}},
+			}}
+		}
+
+		return pod
+	}
+
+	current, err := generateImagePullSecret(namespace, newRegistry("old-password"))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name          string
+		password      string
+		expectRotated bool
+		expectPods    []string
+	}{
+		{
+			name:          "unchanged credential is a no-op",
+			password:      "old-password",
+			expectRotated: false,
+			expectPods:    []string{"failing", "orphan", "running"},
+		},
+		{
+			name:          "changed credential regenerates the secret",
+			password:      "new-password",
+			expectRotated: true,
+			expectPods:    []string{"orphan", "running"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.TODO()
+			ctrClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+				current.DeepCopy(),
+				newPod("failing", "ImagePullBackOff", true),
+				newPod("orphan", "ErrImagePull", false),
+				newPod("running", "", true),
+			).Build()
+
+			desired, err := generateImagePullSecret(namespace, newRegistry(tt.password))
+			require.NoError(t, err)
+
+			rotated, err := imagePullSecretRotated(ctx, ctrClient, desired)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectRotated, rotated)
+
+			if rotated {
+				require.NoError(t, restartImagePullFailedPods(ctx, ctrClient, namespace))
+			}
+
+			pods := &corev1.PodList{}
+			require.NoError(t, ctrClient.List(ctx, pods, client.InNamespace(namespace)))
+
+			var names []string
+			for _, pod := range pods.Items {
+				names = append(names, pod.Name)
+			}
+
+			assert.ElementsMatch(t, tt.expectPods, names)
+		})
+	}
+
+	t.Run("missing secret is created, not rotated", func(t *testing.T) {
+		ctrClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+
+		rotated, err := imagePullSecretRotated(context.TODO(), ctrClient, current)
+		require.NoError(t, err)
+		assert.False(t, rotated)
+	})
+}
+
 func TestGetUsedImageRegistry(t *testing.T) {
 	testCluster := &v1.Cluster{
 		ID: 1,
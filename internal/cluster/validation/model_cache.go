@@ -0,0 +1,52 @@
+package validation
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	v1 "github.com/neutree-ai/neutree/api/v1"
+)
+
+// ValidateModelCacheRegistryPaths checks the registry paths of the model cache map known model
+// registry types to relative paths staying inside the cache.
+func ValidateModelCacheRegistryPaths(cache v1.ModelCache) error {
+	for registryType, registryPath := range cache.RegistryPaths {
+		switch registryType {
+		case v1.HuggingFaceModelRegistryType, v1.BentoMLModelRegistryType:
+		default:
+			return fmt.Errorf("unknown model registry type %q", registryType)
+		}
+
+		cleaned := path.Clean(registryPath)
+		if registryPath == "" || path.IsAbs(registryPath) || cleaned == "." || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+			return fmt.Errorf("path of model registry type %s must be a relative path inside the cache, got %q", registryType, registryPath)
+		}
+	}
+
+	return nil
+}
+
+// ValidateModelCacheNames checks the model caches of a cluster with several of them have
+// distinct non-empty names, which endpoints select them by and their volumes are named after.
+func ValidateModelCacheNames(caches []v1.ModelCache) error {
+	if len(caches) < 2 {
+		return nil
+	}
+
+	seen := make(map[string]bool, len(caches))
+
+	for _, cache := range caches {
+		if cache.Name == "" {
+			return fmt.Errorf("name is required when the cluster has several model caches")
+		}
+
+		if seen[cache.Name] {
+			return fmt.Errorf("duplicate model cache name %q", cache.Name)
+		}
+
+		seen[cache.Name] = true
+	}
+
+	return nil
+}
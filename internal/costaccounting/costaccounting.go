@@ -0,0 +1,201 @@
+package costaccounting
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+
+	v1 "github.com/neutree-ai/neutree/api/v1"
+	"github.com/neutree-ai/neutree/pkg/storage"
+)
+
+// ReplicaRateKey is the rate key charged for every replica-hour, on top of the
+// accelerator-type rate charged for every accelerator-hour. It prices CPU-only endpoints.
+const ReplicaRateKey = "replica"
+
+// maxSampleGap caps the time attributed to a single sample, so a stalled sampler does
+// not bill the whole stall against the replicas observed when it resumes.
+const maxSampleGap = 10 * time.Minute
+
+// Rates are hourly costs keyed by accelerator type (e.g. nvidia_gpu), charged per
+// accelerator-hour, plus the optional ReplicaRateKey charged per replica-hour.
+type Rates map[string]float64
+
+// ParseRates converts the rate flag values into Rates.
+func ParseRates(raw map[string]string) (Rates, error) {
+	rates := make(Rates, len(raw))
+
+	for key, value := range raw {
+		key = strings.TrimSpace(key)
+		if key == "" {
+			return nil, errors.New("cost rate key must not be empty")
+		}
+
+		rate, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid cost rate %q for %s", value, key)
+		}
+
+		if rate < 0 {
+			return nil, errors.Errorf("cost rate for %s must not be negative, got %v", key, rate)
+		}
+
+		rates[key] = rate
+	}
+
+	return rates, nil
+}
+
+// Cost returns the cost of the given replica-hours and accelerator-hours.
+func (r Rates) Cost(acceleratorType string, replicaHours, acceleratorHours float64) float64 {
+	cost := r[ReplicaRateKey] * replicaHours
+	if acceleratorType != "" {
+		cost += r[acceleratorType] * acceleratorHours
+	}
+
+	return cost
+}
+
+// Usage is the compute an endpoint consumed between two samples and its cost.
+type Usage struct {
+	UsageDate        string  `json:"usage_date"`
+	Workspace        string  `json:"workspace"`
+	EndpointName     string  `json:"endpoint_name"`
+	AcceleratorType  string  `json:"accelerator_type,omitempty"`
+	ReplicaHours     float64 `json:"replica_hours"`
+	AcceleratorHours float64 `json:"accelerator_hours"`
+	Cost             float64 `json:"cost"`
+}
+
+// Accountant accumulates endpoint cost from periodic samples of the running replicas.
+// The time between two samples is billed against the replicas observed at the later one.
+type Accountant struct {
+	storage storage.Storage
+	rates   Rates
+
+	mu          sync.Mutex
+	lastSampled map[string]time.Time
+}
+
+func NewAccountant(s storage.Storage, rates Rates) *Accountant {
+	return &Accountant{
+		storage:     s,
+		rates:       rates,
+		lastSampled: map[string]time.Time{},
+	}
+}
+
+// Sample records the usage of every running endpoint since the previous sample.
+// The first sample of an endpoint only starts its accounting.
+func (a *Accountant) Sample(now time.Time) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	endpoints, err := a.storage.ListEndpoint(storage.ListOption{})
+	if err != nil {
+		return errors.Wrap(err, "failed to list endpoints")
+	}
+
+	var usages []Usage
+
+	seen := make(map[string]bool, len(endpoints))
+
+	for i := range endpoints {
+		endpoint := &endpoints[i]
+		key := endpoint.Key()
+		seen[key] = true
+
+		last, sampled := a.lastSampled[key]
+		a.lastSampled[key] = now
+
+		if !sampled || !now.After(last) {
+			continue
+		}
+
+		elapsed := now.Sub(last)
+		if elapsed > maxSampleGap {
+			elapsed = maxSampleGap
+		}
+
+		if usage, ok := a.usage(endpoint, now, elapsed); ok {
+			usages = append(usages, usage)
+		}
+	}
+
+	for key := range a.lastSampled {
+		if !seen[key] {
+			delete(a.lastSampled, key)
+		}
+	}
+
+	if len(usages) == 0 {
+		return nil
+	}
+
+	klog.V(4).Infof("Recording cost of %d endpoint(s)", len(usages))
+
+	err = a.storage.CallDatabaseFunction("record_endpoint_costs", map[string]interface{}{
+		"p_usages": usages,
+	}, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to record endpoint costs")
+	}
+
+	return nil
+}
+
+func (a *Accountant) usage(endpoint *v1.Endpoint, now time.Time, elapsed time.Duration) (Usage, bool) {
+	if endpoint.Metadata == nil || endpoint.Spec == nil || endpoint.Status == nil ||
+		(endpoint.Status.Phase != v1.EndpointPhaseRUNNING && endpoint.Status.Phase != v1.EndpointPhaseDEGRADED) {
+		return Usage{}, false
+	}
+
+	replicas := runningReplicas(endpoint)
+	if replicas == 0 {
+		return Usage{}, false
+	}
+
+	acceleratorType, accelerators := acceleratorsPerReplica(endpoint.Spec.Resources)
+
+	usage := Usage{
+		UsageDate:       now.UTC().Format(time.DateOnly),
+		Workspace:       endpoint.Metadata.Workspace,
+		EndpointName:    endpoint.Metadata.Name,
+		AcceleratorType: acceleratorType,
+		ReplicaHours:    float64(replicas) * elapsed.Hours(),
+	}
+	usage.AcceleratorHours = usage.ReplicaHours * accelerators
+	usage.Cost = a.rates.Cost(acceleratorType, usage.ReplicaHours, usage.AcceleratorHours)
+
+	return usage, true
+}
+
+// runningReplicas prefers the replicas reported in the endpoint status over the desired count.
+func runningReplicas(endpoint *v1.Endpoint) int {
+	if endpoint.Status.Resources != nil && len(endpoint.Status.Resources.Replicas) > 0 {
+		return len(endpoint.Status.Resources.Replicas)
+	}
+
+	if endpoint.Spec.Replicas.Num != nil {
+		return *endpoint.Spec.Replicas.Num
+	}
+
+	return 1
+}
+
+func acceleratorsPerReplica(resources *v1.ResourceSpec) (string, float64) {
+	if resources == nil || resources.GPU == nil {
+		return "", 0
+	}
+
+	count, err := strconv.ParseFloat(*resources.GPU, 64)
+	if err != nil || count <= 0 {
+		return "", 0
+	}
+
+	return resources.GetAcceleratorType(), count
+}
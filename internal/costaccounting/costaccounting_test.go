@@ -0,0 +1,213 @@
+package costaccounting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"k8s.io/utils/pointer"
+
+	v1 "github.com/neutree-ai/neutree/api/v1"
+	storagemocks "github.com/neutree-ai/neutree/pkg/storage/mocks"
+)
+
+func TestParseRates(t *testing.T) {
+	tests := []struct {
+		name      string
+		raw       map[string]string
+		expected  Rates
+		expectErr bool
+	}{
+		{
+			name:     "empty",
+			raw:      map[string]string{},
+			expected: Rates{},
+		},
+		{
+			name:     "accelerator and replica rates",
+			raw:      map[string]string{"nvidia_gpu": "2.5", ReplicaRateKey: " 0.1 "},
+			expected: Rates{"nvidia_gpu": 2.5, ReplicaRateKey: 0.1},
+		},
+		{
+			name:      "invalid rate",
+			raw:       map[string]string{"nvidia_gpu": "cheap"},
+			expectErr: true,
+		},
+		{
+			name:      "negative rate",
+			raw:       map[string]string{"nvidia_gpu": "-1"},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rates, err := ParseRates(tt.raw)
+			if tt.expectErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, rates)
+		})
+	}
+}
+
+func TestRatesCost(t *testing.T) {
+	rates := Rates{"nvidia_gpu": 2, "amd_gpu": 1.5, ReplicaRateKey: 0.1}
+
+	tests := []struct {
+		name             string
+		acceleratorType  string
+		replicaHours     float64
+		acceleratorHours float64
+		expected         float64
+	}{
+		{
+			name:             "accelerator endpoint",
+			acceleratorType:  "nvidia_gpu",
+			replicaHours:     2,
+			acceleratorHours: 8,
+			expected:         16.2,
+		},
+		{
+			name:         "cpu only endpoint",
+			replicaHours: 3,
+			expected:     0.3,
+		},
+		{
+			name:             "accelerator type without rate",
+			acceleratorType:  "intel_gpu",
+			replicaHours:     1,
+			acceleratorHours: 1,
+			expected:         0.1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.InDelta(t, tt.expected, rates.Cost(tt.acceleratorType, tt.replicaHours, tt.acceleratorHours), 1e-9)
+		})
+	}
+}
+
+func costEndpoint(name string, phase v1.EndpointPhase, replicas int, gpu string) v1.Endpoint {
+	return v1.Endpoint{
+		Metadata: &v1.Metadata{Workspace: "team", Name: name},
+		Spec: &v1.EndpointSpec{
+			Replicas: v1.ReplicaSpec{Num: pointer.Int(replicas)},
+			Resources: &v1.ResourceSpec{
+				GPU:         pointer.String(gpu),
+				Accelerator: map[string]string{v1.AcceleratorTypeKey: "nvidia_gpu"},
+			},
+		},
+		Status: &v1.EndpointStatus{Phase: phase},
+	}
+}
+
+func TestAccountantSample(t *testing.T) {
+	s := storagemocks.NewMockStorage(t)
+	a := NewAccountant(s, Rates{"nvidia_gpu": 2, ReplicaRateKey: 0.1})
+
+	endpoints := []v1.Endpoint{
+		costEndpoint("chat", v1.EndpointPhaseRUNNING, 2, "2"),
+		costEndpoint("paused", v1.EndpointPhasePAUSED, 2, "2"),
+	}
+	s.EXPECT().ListEndpoint(mock.Anything).Return(endpoints, nil)
+
+	start := time.Date(2026, 10, 15, 10, 0, 0, 0, time.UTC)
+
+	// The first sample only starts the accounting.
+	require.NoError(t, a.Sample(start))
+
+	var recorded [][]Usage
+
+	s.On("CallDatabaseFunction", "record_endpoint_costs", mock.Anything, nil).Run(func(args mock.Arguments) {
+		recorded = append(recorded, args.Get(1).(map[string]interface{})["p_usages"].([]Usage))
+	}).Return(nil)
+
+	for minutes := 10; minutes <= 60; minutes += 10 {
+		require.NoError(t, a.Sample(start.Add(time.Duration(minutes)*time.Minute)))
+	}
+
+	require.Len(t, recorded, 6)
+
+	var replicaHours, acceleratorHours, cost float64
+
+	for _, usages := range recorded {
+		require.Len(t, usages, 1)
+		assert.Equal(t, "2026-10-15", usages[0].UsageDate)
+		assert.Equal(t, "team", usages[0].Workspace)
+		assert.Equal(t, "chat", usages[0].EndpointName)
+		assert.Equal(t, "nvidia_gpu", usages[0].AcceleratorType)
+
+		replicaHours += usages[0].ReplicaHours
+		acceleratorHours += usages[0].AcceleratorHours
+		cost += usages[0].Cost
+	}
+
+	// Two replicas for one hour, each with two accelerators.
+	assert.InDelta(t, 2, replicaHours, 1e-9)
+	assert.InDelta(t, 4, acceleratorHours, 1e-9)
+	assert.InDelta(t, 8.2, cost, 1e-9)
+}
+
+func TestAccountantSample_ReplicaCount(t *testing.T) {
+	running := costEndpoint("chat", v1.EndpointPhaseRUNNING, 3, "1")
+	reported := costEndpoint("chat", v1.EndpointPhaseRUNNING, 3, "1")
+	reported.Status.Resources = &v1.EndpointResourceStatus{
+		Replicas: []v1.ReplicaDeviceAllocation{{InstanceID: "a"}},
+	}
+
+	tests := []struct {
+		name                 string
+		endpoint             v1.Endpoint
+		elapsed              time.Duration
+		expectedReplicaHours float64
+	}{
+		{
+			name:                 "desired replicas",
+			endpoint:             running,
+			elapsed:              time.Minute,
+			expectedReplicaHours: 3.0 / 60,
+		},
+		{
+			name:                 "replicas reported in status",
+			endpoint:             reported,
+			elapsed:              time.Minute,
+			expectedReplicaHours: 1.0 / 60,
+		},
+		{
+			name:                 "sample gap is capped",
+			endpoint:             running,
+			elapsed:              time.Hour,
+			expectedReplicaHours: 3 * maxSampleGap.Hours(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := storagemocks.NewMockStorage(t)
+			a := NewAccountant(s, Rates{})
+
+			s.EXPECT().ListEndpoint(mock.Anything).Return([]v1.Endpoint{tt.endpoint}, nil)
+
+			var usages []Usage
+
+			s.On("CallDatabaseFunction", "record_endpoint_costs", mock.Anything, nil).Run(func(args mock.Arguments) {
+				usages = args.Get(1).(map[string]interface{})["p_usages"].([]Usage)
+			}).Return(nil).Once()
+
+			start := time.Now()
+			require.NoError(t, a.Sample(start))
+			require.NoError(t, a.Sample(start.Add(tt.elapsed)))
+
+			require.Len(t, usages, 1)
+			assert.InDelta(t, tt.expectedReplicaHours, usages[0].ReplicaHours, 1e-9)
+			assert.Zero(t, usages[0].Cost)
+		})
+	}
+}
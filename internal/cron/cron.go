@@ -8,11 +8,12 @@ import (
 	"github.com/pkg/errors"
 	"k8s.io/klog/v2"
 
+	"github.com/neutree-ai/neutree/internal/costaccounting"
 	"github.com/neutree-ai/neutree/pkg/storage"
 )
 
 // StartCrons starts all cron jobs
-func StartCrons(ctx context.Context, storage storage.Storage) error {
+func StartCrons(ctx context.Context, storage storage.Storage, costRates costaccounting.Rates) error {
 	s, err := gocron.NewScheduler()
 	if err != nil {
 		return errors.Wrapf(err, "failed to init cron scheduler")
@@ -62,6 +63,20 @@ func StartCrons(ctx context.Context, storage storage.Storage) error {
 		return errors.Wrapf(err, "failed to add sync api key usage cron job")
 	}
 
+	costAccountant := costaccounting.NewAccountant(storage, costRates)
+
+	_, err = s.NewJob(gocron.DurationJob(time.Minute), gocron.NewTask(func() {
+		klog.V(4).Infof("Start to sample endpoint costs")
+
+		jobErr := costAccountant.Sample(time.Now())
+		if jobErr != nil {
+			klog.Errorf("Failed to sample endpoint costs: %v", jobErr)
+		}
+	}), gocron.WithSingletonMode(gocron.LimitModeWait))
+	if err != nil {
+		return errors.Wrapf(err, "failed to add sample endpoint costs cron job")
+	}
+
 	s.Start()
 
 	go func() {
@@ -0,0 +1,91 @@
+package deploy
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v1 "github.com/neutree-ai/neutree/api/v1"
+)
+
+// SetJobTTL returns a mutate setting spec.ttlSecondsAfterFinished of Jobs, so the TTL controller
+// removes them once they finish. A TTL already set by the manifest is kept.
+func SetJobTTL(ttlSeconds int32) Mutate {
+	return func(obj *unstructured.Unstructured) error {
+		if obj.GetKind() != "Job" || obj.GroupVersionKind().Group != batchv1.GroupName {
+			return nil
+		}
+
+		if _, found, _ := unstructured.NestedFieldNoCopy(obj.Object, "spec", "ttlSecondsAfterFinished"); found {
+			return nil
+		}
+
+		return unstructured.SetNestedField(obj.Object, int64(ttlSeconds), "spec", "ttlSecondsAfterFinished")
+	}
+}
+
+// GarbageCollectFinishedJobs deletes the Jobs managed by neutree in the namespace which finished
+// longer than their TTL ago, defaultTTL applies to Jobs without spec.ttlSecondsAfterFinished.
+// It covers clusters without the TTL-after-finished controller, and returns the number of
+// deleted Jobs.
+func GarbageCollectFinishedJobs(ctx context.Context, ctrlClient client.Client, namespace string,
+	defaultTTL time.Duration, now time.Time) (int, error) {
+	jobs := &batchv1.JobList{}
+	if err := ctrlClient.List(ctx, jobs, client.InNamespace(namespace),
+		client.MatchingLabels{v1.LabelManagedBy: v1.LabelManagedByValue}); err != nil {
+		return 0, errors.Wrapf(err, "failed to list jobs in namespace %s", namespace)
+	}
+
+	deleted := 0
+
+	for i := range jobs.Items {
+		job := &jobs.Items[i]
+		if job.DeletionTimestamp != nil {
+			continue
+		}
+
+		finishedAt, finished := jobFinishTime(job)
+		if !finished {
+			continue
+		}
+
+		ttl := defaultTTL
+		if job.Spec.TTLSecondsAfterFinished != nil {
+			ttl = time.Duration(*job.Spec.TTLSecondsAfterFinished) * time.Second
+		}
+
+		if now.Before(finishedAt.Add(ttl)) {
+			continue
+		}
+
+		if err := ctrlClient.Delete(ctx, job, client.PropagationPolicy("Background")); client.IgnoreNotFound(err) != nil {
+			return deleted, errors.Wrapf(err, "failed to delete job %s/%s", namespace, job.Name)
+		}
+
+		deleted++
+	}
+
+	return deleted, nil
+}
+
+// jobFinishTime returns when the Job completed or failed.
+func jobFinishTime(job *batchv1.Job) (time.Time, bool) {
+	for _, cond := range job.Status.Conditions {
+		if (cond.Type != batchv1.JobComplete && cond.Type != batchv1.JobFailed) || cond.Status != corev1.ConditionTrue {
+			continue
+		}
+
+		if cond.Type == batchv1.JobComplete && job.Status.CompletionTime != nil {
+			return job.Status.CompletionTime.Time, true
+		}
+
+		return cond.LastTransitionTime.Time, true
+	}
+
+	return time.Time{}, false
+}
@@ -0,0 +1,141 @@
+package deploy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	v1 "github.com/neutree-ai/neutree/api/v1"
+)
+
+func TestSetJobTTL(t *testing.T) {
+	tests := []struct {
+		name    string
+		obj     map[string]interface{}
+		wantTTL interface{}
+	}{
+		{
+			name: "job without ttl",
+			obj: map[string]interface{}{
+				"apiVersion": "batch/v1",
+				"kind":       "Job",
+				"metadata":   map[string]interface{}{"name": "prefetch"},
+				"spec":       map[string]interface{}{},
+			},
+			wantTTL: int64(600),
+		},
+		{
+			name: "job keeps manifest ttl",
+			obj: map[string]interface{}{
+				"apiVersion": "batch/v1",
+				"kind":       "Job",
+				"metadata":   map[string]interface{}{"name": "prefetch"},
+				"spec":       map[string]interface{}{"ttlSecondsAfterFinished": int64(60)},
+			},
+			wantTTL: int64(60),
+		},
+		{
+			name: "other kinds are untouched",
+			obj: map[string]interface{}{
+				"apiVersion": "apps/v1",
+				"kind":       "Deployment",
+				"metadata":   map[string]interface{}{"name": "engine"},
+				"spec":       map[string]interface{}{},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := &unstructured.Unstructured{Object: tt.obj}
+			require.NoError(t, SetJobTTL(600)(obj))
+
+			ttl, found, err := unstructured.NestedFieldNoCopy(obj.Object, "spec", "ttlSecondsAfterFinished")
+			require.NoError(t, err)
+
+			if tt.wantTTL == nil {
+				assert.False(t, found)
+				return
+			}
+
+			assert.Equal(t, tt.wantTTL, ttl)
+		})
+	}
+}
+
+func TestKubernetesDeployer_WithJobTTL(t *testing.T) {
+	applier := NewKubernetesDeployer(nil, "default", "resource", "component")
+	assert.Equal(t, v1.DefaultFinishedJobTTLSeconds, applier.jobTTLSeconds)
+
+	applier.WithJobTTL(120)
+	assert.Equal(t, int32(120), applier.jobTTLSeconds)
+}
+
+func TestGarbageCollectFinishedJobs(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = batchv1.AddToScheme(scheme)
+
+	now := time.Now()
+	managed := map[string]string{v1.LabelManagedBy: v1.LabelManagedByValue}
+
+	job := func(name string, labels map[string]string, ttl *int32, conditionType batchv1.JobConditionType, finishedAgo time.Duration) *batchv1.Job {
+		j := &batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "neutree", Labels: labels},
+			Spec:       batchv1.JobSpec{TTLSecondsAfterFinished: ttl},
+		}
+
+		if conditionType != "" {
+			finishedAt := metav1.NewTime(now.Add(-finishedAgo))
+			j.Status.Conditions = []batchv1.JobCondition{
+				{Type: conditionType, Status: corev1.ConditionTrue, LastTransitionTime: finishedAt},
+			}
+
+			if conditionType == batchv1.JobComplete {
+				j.Status.CompletionTime = &finishedAt
+			}
+		}
+
+		return j
+	}
+
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		job("expired-complete", managed, nil, batchv1.JobComplete, 2*time.Hour),
+		job("expired-failed", managed, nil, batchv1.JobFailed, 2*time.Hour),
+		job("recent-complete", managed, nil, batchv1.JobComplete, 10*time.Minute),
+		job("own-ttl-not-expired", managed, ptr.To(int32(86400)), batchv1.JobComplete, 2*time.Hour),
+		job("running", managed, nil, "", 0),
+		job("not-managed", map[string]string{"app": "other"}, nil, batchv1.JobComplete, 2*time.Hour),
+	).Build()
+
+	deleted, err := GarbageCollectFinishedJobs(context.Background(), fakeClient, "neutree", time.Hour, now)
+	require.NoError(t, err)
+	assert.Equal(t, 2, deleted)
+
+	for name, wantExists := range map[string]bool{
+		"expired-complete":    false,
+		"expired-failed":      false,
+		"recent-complete":     true,
+		"own-ttl-not-expired": true,
+		"running":             true,
+		"not-managed":         true,
+	} {
+		err := fakeClient.Get(context.Background(), client.ObjectKey{Namespace: "neutree", Name: name}, &batchv1.Job{})
+		if wantExists {
+			assert.NoError(t, err, name)
+		} else {
+			assert.True(t, apierrors.IsNotFound(err), name)
+		}
+	}
+}
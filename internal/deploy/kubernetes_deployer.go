@@ -8,6 +8,8 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v1 "github.com/neutree-ai/neutree/api/v1"
 )
 
 // KubernetesDeployer orchestrates the complete deployment workflow for Kubernetes resources
@@ -23,6 +25,9 @@ type KubernetesDeployer struct {
 	mutates     []Mutate
 	labels      map[string]string
 	logger      klog.Logger
+
+	// jobTTLSeconds is set as ttlSecondsAfterFinished of the applied Jobs.
+	jobTTLSeconds int32
 }
 
 // NewKubernetesDeployer creates a new KubernetesDeployer instance
@@ -36,6 +41,7 @@ func NewKubernetesDeployer(ctrlClient client.Client, namespace, resourceName, co
 		componentName: componentName,
 		configStore:   NewConfigStore(ctrlClient),
 		logger:        klog.Background(),
+		jobTTLSeconds: v1.DefaultFinishedJobTTLSeconds,
 	}
 }
 
@@ -84,6 +90,12 @@ func (a *KubernetesDeployer) WithLogger(logger klog.Logger) *KubernetesDeployer
 	return a
 }
 
+// WithJobTTL sets how long the applied Jobs are kept after they finish
+func (a *KubernetesDeployer) WithJobTTL(ttlSeconds int32) *KubernetesDeployer {
+	a.jobTTLSeconds = ttlSeconds
+	return a
+}
+
 // Apply applies the resources to Kubernetes with automatic configuration management
 // Returns the number of changed objects and any error
 func (a *KubernetesDeployer) Apply(ctx context.Context) (int, error) {
@@ -111,6 +123,8 @@ func (a *KubernetesDeployer) Apply(ctx context.Context) (int, error) {
 		manifestApply = manifestApply.WithMutate(mutate)
 	}
 
+	manifestApply = manifestApply.WithMutate(SetJobTTL(a.jobTTLSeconds))
+
 	changedCount, err := manifestApply.ApplyManifests(ctx)
 	if err != nil {
 		return 0, errors.Wrap(err, "failed to apply manifests")
@@ -0,0 +1,299 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+
+	v1 "github.com/neutree-ai/neutree/api/v1"
+)
+
+// schemalessVersionsWarned holds the engine versions without a values schema already warned
+// about, the endpoints using them are validated on every reconcile.
+var schemalessVersionsWarned sync.Map
+
+// ValidateEndpointEngineArgs validates the endpoint engine_args against the values schema of
+// the used engine version. Engine versions without a schema are only warned about, once per
+// engine version.
+func ValidateEndpointEngineArgs(endpoint *v1.Endpoint, usedEngine *v1.Engine) error {
+	if endpoint == nil || endpoint.Spec == nil || endpoint.Spec.Engine == nil || usedEngine == nil || usedEngine.Spec == nil {
+		return nil
+	}
+
+	engineArgs, ok := endpoint.Spec.Variables["engine_args"].(map[string]interface{})
+	if !ok || len(engineArgs) == 0 {
+		return nil
+	}
+
+	var schema map[string]interface{}
+
+	for _, version := range usedEngine.Spec.Versions {
+		if version != nil && version.Version == endpoint.Spec.Engine.Version {
+			schema = version.ValuesSchema
+			break
+		}
+	}
+
+	if len(schema) == 0 {
+		key := usedEngine.Metadata.WorkspaceName() + "@" + endpoint.Spec.Engine.Version
+		if _, warned := schemalessVersionsWarned.LoadOrStore(key, struct{}{}); !warned {
+			klog.Warningf("engine %s version %s has no values schema, skip validating the engine_args of its endpoints",
+				usedEngine.Metadata.WorkspaceName(), endpoint.Spec.Engine.Version)
+		}
+
+		return nil
+	}
+
+	return ValidateEngineArgs(schema, engineArgs)
+}
+
+// ValidateEngineArgs validates user supplied engine_args against the values schema of an
+// engine version. Keys may be given in flag form (--max-model-len), hyphen form or
+// underscore form. Unknown keys are rejected only when the schema sets
+// "additionalProperties": false, and come with a suggestion of the closest known flag.
+//
+// Values are checked leniently because engine args are rendered as CLI flags: numbers and
+// booleans given as strings, and objects/arrays given as JSON strings are accepted.
+func ValidateEngineArgs(schema map[string]interface{}, args map[string]interface{}) error {
+	if len(schema) == 0 || len(args) == 0 {
+		return nil
+	}
+
+	properties, _ := schema["properties"].(map[string]interface{})
+	allowUnknown := true
+
+	if additional, ok := schema["additionalProperties"].(bool); ok {
+		allowUnknown = additional
+	}
+
+	keys := make([]string, 0, len(args))
+	for key := range args {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	var problems []string
+
+	for _, key := range keys {
+		name := normalizeEngineArgKey(key)
+
+		property, ok := properties[name].(map[string]interface{})
+		if !ok {
+			if allowUnknown {
+				continue
+			}
+
+			problem := fmt.Sprintf("unknown engine arg %q", key)
+			if suggestion := suggestEngineArg(name, properties); suggestion != "" {
+				problem += fmt.Sprintf(" (did you mean %q?)", suggestion)
+			}
+
+			problems = append(problems, problem)
+
+			continue
+		}
+
+		if problem := validateEngineArgValue(key, args[key], property); problem != "" {
+			problems = append(problems, problem)
+		}
+	}
+
+	if len(problems) > 0 {
+		return errors.Errorf("invalid engine_args: %s", strings.Join(problems, "; "))
+	}
+
+	return nil
+}
+
+func normalizeEngineArgKey(key string) string {
+	return strings.ReplaceAll(strings.TrimLeft(key, "-"), "-", "_")
+}
+
+func validateEngineArgValue(key string, value interface{}, property map[string]interface{}) string {
+	// null means "use the engine default" and is skipped by the templates.
+	if value == nil {
+		return ""
+	}
+
+	types := schemaTypes(property["type"])
+	if len(types) > 0 {
+		matched := false
+
+		for _, t := range types {
+			if matchesSchemaType(t, value) {
+				matched = true
+				break
+			}
+		}
+
+		if !matched {
+			return fmt.Sprintf("engine arg %q must be of type %s, got %v", key, strings.Join(types, " or "), value)
+		}
+	}
+
+	if enum, ok := property["enum"].([]interface{}); ok && len(enum) > 0 {
+		found := false
+
+		for _, allowed := range enum {
+			if fmt.Sprint(allowed) == fmt.Sprint(value) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return fmt.Sprintf("engine arg %q must be one of %v, got %v", key, enum, value)
+		}
+	}
+
+	if n, ok := toNumber(value); ok {
+		if minimum, ok := toNumber(property["minimum"]); ok && n < minimum {
+			return fmt.Sprintf("engine arg %q must be >= %v, got %v", key, minimum, value)
+		}
+
+		if maximum, ok := toNumber(property["maximum"]); ok && n > maximum {
+			return fmt.Sprintf("engine arg %q must be <= %v, got %v", key, maximum, value)
+		}
+	}
+
+	return ""
+}
+
+func schemaTypes(raw interface{}) []string {
+	switch t := raw.(type) {
+	case string:
+		return []string{t}
+	case []interface{}:
+		types := make([]string, 0, len(t))
+
+		for _, item := range t {
+			if s, ok := item.(string); ok {
+				types = append(types, s)
+			}
+		}
+
+		return types
+	default:
+		return nil
+	}
+}
+
+func matchesSchemaType(schemaType string, value interface{}) bool {
+	switch schemaType {
+	case "integer":
+		n, ok := toNumber(value)
+		return ok && n == math.Trunc(n)
+	case "number":
+		_, ok := toNumber(value)
+		return ok
+	case "boolean":
+		switch v := value.(type) {
+		case bool:
+			return true
+		case string:
+			_, err := strconv.ParseBool(v)
+			return err == nil
+		}
+
+		return false
+	case "string":
+		switch value.(type) {
+		case map[string]interface{}, []interface{}:
+			return false
+		}
+
+		return true
+	case "array":
+		switch value.(type) {
+		case []interface{}, []string, string:
+			return true
+		}
+
+		return false
+	case "object":
+		switch v := value.(type) {
+		case map[string]interface{}:
+			return true
+		case string:
+			var parsed map[string]interface{}
+			return json.Unmarshal([]byte(v), &parsed) == nil
+		}
+
+		return false
+	default:
+		return true
+	}
+}
+
+func toNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case json.Number:
+		n, err := v.Float64()
+		return n, err == nil
+	case string:
+		n, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return n, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// suggestEngineArg returns the known engine arg closest to name, or "" if none is close enough.
+func suggestEngineArg(name string, properties map[string]interface{}) string {
+	best := ""
+	bestDistance := len(name)/3 + 1
+
+	for candidate := range properties {
+		d := levenshtein(name, candidate)
+		if d < bestDistance || (d == bestDistance && best != "" && candidate < best) {
+			best = candidate
+			bestDistance = d
+		}
+	}
+
+	return best
+}
+
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
@@ -0,0 +1,250 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	v1 "github.com/neutree-ai/neutree/api/v1"
+)
+
+func TestValidateEngineArgs(t *testing.T) {
+	schema := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"max_model_len": map[string]interface{}{"type": "integer"},
+			"gpu_memory_utilization": map[string]interface{}{
+				"type":    "number",
+				"minimum": float64(0),
+				"maximum": float64(1),
+			},
+			"dtype": map[string]interface{}{
+				"type": "string",
+				"enum": []interface{}{"auto", "float16", "bfloat16"},
+			},
+			"trust_remote_code":   map[string]interface{}{"type": "boolean"},
+			"speculative_config":  map[string]interface{}{"type": []interface{}{"string", "object"}},
+			"override_generation": map[string]interface{}{"type": "object"},
+			"served_model_name":   map[string]interface{}{"type": []interface{}{"string", "array"}},
+		},
+		"additionalProperties": false,
+	}
+
+	tests := []struct {
+		name        string
+		schema      map[string]interface{}
+		args        map[string]interface{}
+		expectError []string
+	}{
+		{
+			name: "accept valid args in any key format",
+			args: map[string]interface{}{
+				"max_model_len":            float64(4096),
+				"--gpu-memory-utilization": 0.8,
+				"dtype":                    "float16",
+				"trust-remote-code":        "true",
+				"speculative_config":       map[string]interface{}{"method": "mtp"},
+				"override_generation":      `{"temperature": 0.5}`,
+				"served_model_name":        []interface{}{"a", "b"},
+			},
+		},
+		{
+			name: "accept numbers given as strings",
+			args: map[string]interface{}{
+				"max_model_len": "4096",
+			},
+		},
+		{
+			name: "accept null values",
+			args: map[string]interface{}{
+				"max_model_len": nil,
+			},
+		},
+		{
+			name: "reject unknown flag with suggestion",
+			args: map[string]interface{}{
+				"max-model-lenght": 4096,
+			},
+			expectError: []string{`unknown engine arg "max-model-lenght"`, `did you mean "max_model_len"`},
+		},
+		{
+			name: "reject unknown flag without close match",
+			args: map[string]interface{}{
+				"completely_different": 1,
+			},
+			expectError: []string{`unknown engine arg "completely_different"`},
+		},
+		{
+			name: "reject wrong type",
+			args: map[string]interface{}{
+				"max_model_len": 40.5,
+			},
+			expectError: []string{`engine arg "max_model_len" must be of type integer`},
+		},
+		{
+			name: "reject non-boolean string",
+			args: map[string]interface{}{
+				"trust_remote_code": "maybe",
+			},
+			expectError: []string{`engine arg "trust_remote_code" must be of type boolean`},
+		},
+		{
+			name: "reject value above maximum",
+			args: map[string]interface{}{
+				"gpu_memory_utilization": 1.5,
+			},
+			expectError: []string{`engine arg "gpu_memory_utilization" must be <= 1`},
+		},
+		{
+			name: "reject value below minimum",
+			args: map[string]interface{}{
+				"gpu_memory_utilization": "-0.1",
+			},
+			expectError: []string{`engine arg "gpu_memory_utilization" must be >= 0`},
+		},
+		{
+			name: "reject value outside enum",
+			args: map[string]interface{}{
+				"dtype": "float64",
+			},
+			expectError: []string{`engine arg "dtype" must be one of`},
+		},
+		{
+			name: "report all problems",
+			args: map[string]interface{}{
+				"dtype":        "float64",
+				"max_num_seqz": 1,
+			},
+			expectError: []string{`engine arg "dtype"`, `unknown engine arg "max_num_seqz"`},
+		},
+		{
+			name: "allow unknown flags when schema allows additional properties",
+			schema: map[string]interface{}{
+				"properties": map[string]interface{}{
+					"max_model_len": map[string]interface{}{"type": "integer"},
+				},
+			},
+			args: map[string]interface{}{
+				"custom_flag": "x",
+			},
+		},
+		{
+			name:   "skip validation without schema",
+			schema: map[string]interface{}{},
+			args: map[string]interface{}{
+				"anything": "x",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := tt.schema
+			if s == nil {
+				s = schema
+			}
+
+			err := ValidateEngineArgs(s, tt.args)
+			if len(tt.expectError) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+
+			require.Error(t, err)
+
+			for _, msg := range tt.expectError {
+				assert.Contains(t, err.Error(), msg)
+			}
+		})
+	}
+}
+
+func TestValidateEngineArgs_BuiltinVLLMSchema(t *testing.T) {
+	schema, err := GetVLLMV0_17_1EngineSchema()
+	require.NoError(t, err)
+
+	assert.NoError(t, ValidateEngineArgs(schema, map[string]interface{}{
+		"max-model-len":          "4096",
+		"gpu_memory_utilization": 0.9,
+		"enable_prefix_caching":  false,
+	}))
+
+	err = ValidateEngineArgs(schema, map[string]interface{}{"max-model-lenght": 4096})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `did you mean "max_model_len"`)
+}
+
+func TestValidateEndpointEngineArgs(t *testing.T) {
+	usedEngine := &v1.Engine{
+		Metadata: &v1.Metadata{Name: "vllm"},
+		Spec: &v1.EngineSpec{
+			Versions: []*v1.EngineVersion{
+				{
+					Version: "v0.17.1",
+					ValuesSchema: map[string]interface{}{
+						"properties": map[string]interface{}{
+							"max_model_len": map[string]interface{}{"type": "integer", "minimum": float64(1)},
+						},
+						"additionalProperties": false,
+					},
+				},
+				{
+					Version: "v0.0.1",
+				},
+			},
+		},
+	}
+
+	newEndpoint := func(version string, engineArgs map[string]interface{}) *v1.Endpoint {
+		return &v1.Endpoint{
+			Metadata: &v1.Metadata{Name: "ep", Workspace: "default"},
+			Spec: &v1.EndpointSpec{
+				Engine:    &v1.EndpointEngineSpec{Engine: "vllm", Version: version},
+				Variables: map[string]interface{}{"engine_args": engineArgs},
+			},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		endpoint    *v1.Endpoint
+		expectError string
+	}{
+		{
+			name:     "valid engine args",
+			endpoint: newEndpoint("v0.17.1", map[string]interface{}{"max-model-len": float64(4096)}),
+		},
+		{
+			name:        "misspelled engine arg",
+			endpoint:    newEndpoint("v0.17.1", map[string]interface{}{"max_model_lenght": float64(4096)}),
+			expectError: `did you mean "max_model_len"`,
+		},
+		{
+			name:        "out of range engine arg",
+			endpoint:    newEndpoint("v0.17.1", map[string]interface{}{"max_model_len": float64(0)}),
+			expectError: `must be >= 1`,
+		},
+		{
+			name:     "engine version without schema only warns",
+			endpoint: newEndpoint("v0.0.1", map[string]interface{}{"max_model_lenght": float64(4096)}),
+		},
+		{
+			name:     "no engine args",
+			endpoint: newEndpoint("v0.17.1", nil),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateEndpointEngineArgs(tt.endpoint, usedEngine)
+			if tt.expectError == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expectError)
+		})
+	}
+}
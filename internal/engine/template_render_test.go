@@ -167,6 +167,100 @@ func TestBuiltInKubernetesTemplatesPreserveNumericEndpointName(t *testing.T) {
 	}
 }
 
+func TestBuiltInKubernetesTemplatesDeployByImageDigest(t *testing.T) {
+	templates := map[string]string{
+		"vllm v0.11.2":     vllmV0_11_2DeployTemplate,
+		"vllm v0.17.1":     vllmV0_17_1DeployTemplate,
+		"vllm v0.24.0":     vllmV0_24_0DeployTemplate,
+		"sglang v0.5.10":   sglangV0_5_10DeployTemplate,
+		"llama.cpp v0.3.7": llamaCppDefaultDeployTemplate,
+	}
+
+	for name, template := range templates {
+		t.Run(name, func(t *testing.T) {
+			vars := newTestVLLMVars("v0.17.1", "text-generation")
+
+			objs, err := util.RenderKubernetesManifest(template, vars)
+			require.NoError(t, err)
+			assert.Equal(t, "registry.test/neutree/engine-vllm:v0.17.1", mustExtractContainerImage(t, objs.Items, "vllm-engine"))
+
+			vars["ImageDigest"] = "sha256:abc"
+
+			objs, err = util.RenderKubernetesManifest(template, vars)
+			require.NoError(t, err)
+			assert.Equal(t, "registry.test/neutree/engine-vllm:v0.17.1@sha256:abc", mustExtractContainerImage(t, objs.Items, "vllm-engine"))
+		})
+	}
+}
+
+func TestBuiltInKubernetesTemplatesPriorityClassName(t *testing.T) {
+	templates := map[string]string{
+		"vllm v0.11.2":     vllmV0_11_2DeployTemplate,
+		"vllm v0.17.1":     vllmV0_17_1DeployTemplate,
+		"vllm v0.24.0":     vllmV0_24_0DeployTemplate,
+		"sglang v0.5.10":   sglangV0_5_10DeployTemplate,
+		"llama.cpp v0.3.7": llamaCppDefaultDeployTemplate,
+	}
+
+	for name, template := range templates {
+		t.Run(name, func(t *testing.T) {
+			vars := newTestVLLMVars("v0.17.1", "text-generation")
+
+			objs, err := util.RenderKubernetesManifest(template, vars)
+			require.NoError(t, err)
+
+			deployment := mustFindRenderedObjectByKind(t, objs.Items, "Deployment")
+			_, found, err := unstructured.NestedString(deployment.Object, "spec", "template", "spec", "priorityClassName")
+			require.NoError(t, err)
+			assert.False(t, found, "priorityClassName must be omitted when unset")
+
+			vars["PriorityClassName"] = "production"
+
+			objs, err = util.RenderKubernetesManifest(template, vars)
+			require.NoError(t, err)
+
+			deployment = mustFindRenderedObjectByKind(t, objs.Items, "Deployment")
+			assertNestedString(t, deployment.Object, "production", "spec", "template", "spec", "priorityClassName")
+		})
+	}
+}
+
+func TestBuiltInKubernetesTemplatesProbes(t *testing.T) {
+	templates := []struct {
+		name        string
+		template    string
+		defaultPath string
+	}{
+		{name: "vllm v0.11.2", template: vllmV0_11_2DeployTemplate, defaultPath: "/health"},
+		{name: "vllm v0.17.1", template: vllmV0_17_1DeployTemplate, defaultPath: "/health"},
+		{name: "vllm v0.24.0", template: vllmV0_24_0DeployTemplate, defaultPath: "/health"},
+		{name: "sglang v0.5.10", template: sglangV0_5_10DeployTemplate, defaultPath: "/health"},
+		{name: "llama.cpp v0.3.7", template: llamaCppDefaultDeployTemplate, defaultPath: "/v1/models"},
+	}
+
+	for _, tt := range templates {
+		t.Run(tt.name, func(t *testing.T) {
+			vars := newTestVLLMVars("v0.17.1", "text-embedding")
+
+			objs, err := util.RenderKubernetesManifest(tt.template, vars)
+			require.NoError(t, err)
+			assert.Equal(t, tt.defaultPath, mustExtractProbePath(t, objs.Items, "vllm-engine", "startupProbe"))
+			assert.Equal(t, tt.defaultPath, mustExtractProbePath(t, objs.Items, "vllm-engine", "readinessProbe"))
+
+			assert.Equal(t, int64(120), mustExtractProbeField(t, objs.Items, "vllm-engine", "startupProbe", "failureThreshold"))
+
+			vars["HealthCheckPath"] = "/ready"
+			vars["StartupFailureThreshold"] = 360
+
+			objs, err = util.RenderKubernetesManifest(tt.template, vars)
+			require.NoError(t, err)
+			assert.Equal(t, "/ready", mustExtractProbePath(t, objs.Items, "vllm-engine", "startupProbe"))
+			assert.Equal(t, "/ready", mustExtractProbePath(t, objs.Items, "vllm-engine", "readinessProbe"))
+			assert.Equal(t, int64(360), mustExtractProbeField(t, objs.Items, "vllm-engine", "startupProbe", "failureThreshold"))
+		})
+	}
+}
+
 func TestVLLMTemplatePreservesListEngineArgs(t *testing.T) {
 	vars := newTestVLLMVars("v0.24.0", "text-generation")
 	vars["EngineArgs"] = map[string]any{
@@ -310,6 +404,51 @@ func mustExtractContainerArgs(t *testing.T, obj map[string]any, containerName st
 
 // mustExtractContainerCommand pulls the named container's command list out of
 // a rendered unstructured Deployment object.
+func mustExtractContainerImage(t *testing.T, objs []unstructured.Unstructured, containerName string) string {
+	t.Helper()
+	deploy := mustFindRenderedObjectByKind(t, objs, "Deployment")
+	spec := requireMap(t, deploy.Object["spec"], "spec")
+	tmpl := requireMap(t, spec["template"], "spec.template")
+	pod := requireMap(t, tmpl["spec"], "spec.template.spec")
+	containers := requireSlice(t, pod["containers"], "spec.template.spec.containers")
+
+	for _, c := range containers {
+		cm := requireMap(t, c, "container")
+		if name, ok := cm["name"].(string); ok && name == containerName {
+			return mustString(t, cm["image"], "image", 0)
+		}
+	}
+	require.Failf(t, "container not found", "%s", containerName)
+
+	return ""
+}
+
+func mustExtractProbePath(t *testing.T, objs []unstructured.Unstructured, containerName, probe string) string {
+	t.Helper()
+	httpGet := requireMap(t, mustExtractProbeField(t, objs, containerName, probe, "httpGet"), probe+".httpGet")
+
+	return mustString(t, httpGet["path"], probe+".httpGet.path", 0)
+}
+
+func mustExtractProbeField(t *testing.T, objs []unstructured.Unstructured, containerName, probe, field string) any {
+	t.Helper()
+	deploy := mustFindRenderedObjectByKind(t, objs, "Deployment")
+	spec := requireMap(t, deploy.Object["spec"], "spec")
+	tmpl := requireMap(t, spec["template"], "spec.template")
+	pod := requireMap(t, tmpl["spec"], "spec.template.spec")
+	containers := requireSlice(t, pod["containers"], "spec.template.spec.containers")
+
+	for _, c := range containers {
+		cm := requireMap(t, c, "container")
+		if name, ok := cm["name"].(string); ok && name == containerName {
+			return requireMap(t, cm[probe], probe)[field]
+		}
+	}
+	require.Failf(t, "container not found", "%s", containerName)
+
+	return nil
+}
+
 func mustExtractContainerCommand(t *testing.T, obj map[string]any, containerName string) []string {
 	t.Helper()
 	spec := requireMap(t, obj["spec"], "spec")
@@ -3,13 +3,16 @@ package gateway
 import (
 	"context"
 	"fmt"
-	"strconv"
+	"net/http"
+	"slices"
 	"strings"
 
 	"github.com/kong/go-kong/kong"
 	"github.com/pkg/errors"
 	"go.openly.dev/pointy"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	v1 "github.com/neutree-ai/neutree/api/v1"
 	"github.com/neutree-ai/neutree/internal/util"
@@ -31,6 +34,10 @@ type Kong struct {
 
 	quotaAPIURL  string
 	serviceToken string
+
+	// getClusterClient returns a client of a kubernetes cluster, used to read the engine
+	// credentials of its endpoints.
+	getClusterClient func(cluster *v1.Cluster) (client.Client, error)
 }
 
 func newKong(opts GatewayOptions) (Gateway, error) {
@@ -46,6 +53,7 @@ func newKong(opts GatewayOptions) (Gateway, error) {
 		proxyUrl:          opts.ProxyUrl,
 		quotaAPIURL:       opts.QuotaAPIURL,
 		serviceToken:      opts.ServiceToken,
+		getClusterClient:  util.GetClientFromCluster,
 	}, nil
 }
 
@@ -335,7 +343,12 @@ func (k *Kong) DeleteCluster(cluster *v1.Cluster) error {
 }
 
 func (k *Kong) SyncEndpoint(ep *v1.Endpoint) error {
-	gwService, err := k.syncEndpointService(ep)
+	standby, err := k.getFailoverEndpoint(ep)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get failover endpoint of endpoint %s", ep.Metadata.Name)
+	}
+
+	gwService, err := k.syncEndpointService(ep, standby)
 	if err != nil {
 		return errors.Wrapf(err, "failed to get gateway service by endpoint %s", ep.Metadata.Name)
 	}
@@ -345,16 +358,68 @@ func (k *Kong) SyncEndpoint(ep *v1.Endpoint) error {
 		return errors.Wrapf(err, "failed to sync endpoint route %s", ep.Metadata.Name)
 	}
 
-	// sync route plugins
-	needPluginMap := make(map[string]*kong.Plugin)
+	err = k.syncEndpointRoutePlugins(ep, gwService, route, standby, "")
+	if err != nil {
+		return err
+	}
 
-	aiGatewayPlugin := k.generateAIGatewayPlugin(ep, route)
-	needPluginMap[*aiGatewayPlugin.InstanceName] = aiGatewayPlugin
+	domainRoute, err := k.syncEndpointDomainRoute(ep, gwService)
+	if err != nil {
+		return errors.Wrapf(err, "failed to sync endpoint domain route %s", ep.Metadata.Name)
+	}
 
-	aclPlugin := k.generateEndpointACLPlugin(ep, route)
-	needPluginMap[*aclPlugin.InstanceName] = aclPlugin
+	if domainRoute != nil {
+		err = k.syncEndpointRoutePlugins(ep, gwService, domainRoute, standby, endpointDomainPluginSuffix)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = k.syncCapabilityRoutes(ep.Metadata.Workspace)
+	if err != nil {
+		return errors.Wrapf(err, "failed to sync capability routes of workspace %s", ep.Metadata.Workspace)
+	}
+
+	return nil
+}
+
+// syncEndpointRoutePlugins syncs the plugins of a route of the endpoint. The plugins of the
+// domain route are suffixed, so they do not collide with the plugins of the endpoint route.
+func (k *Kong) syncEndpointRoutePlugins(ep *v1.Endpoint, gwService *kong.Service, route *kong.Route,
+	standby *v1.Endpoint, instanceSuffix string) error {
+	aiGatewayPlugin, err := k.generateAIGatewayPlugin(ep, gwService, route)
+	if err != nil {
+		return errors.Wrapf(err, "failed to generate ai gateway plugin for endpoint %s", ep.Metadata.Name)
+	}
+
+	// The domain route serves the endpoint from the root path of the domain.
+	if instanceSuffix != "" {
+		aiGatewayPlugin.Config["route_prefix"] = ""
+	}
+
+	needPlugins := []*kong.Plugin{aiGatewayPlugin, k.generateEndpointACLPlugin(ep, route)}
+
+	corsPlugin, err := k.generateEndpointCORSPlugin(ep, route)
+	if err != nil {
+		return errors.Wrapf(err, "failed to generate cors plugin for endpoint %s", ep.Metadata.Name)
+	}
+
+	if corsPlugin != nil {
+		needPlugins = append(needPlugins, corsPlugin)
+	}
+
+	// Endpoints recovering a healthy replica drop the plugin with the other stale plugins below,
+	// endpoints failed over to their standby endpoint keep serving through it.
+	if unavailablePlugin := k.generateEndpointUnavailablePlugin(ep, route); unavailablePlugin != nil && standby == nil {
+		needPlugins = append(needPlugins, unavailablePlugin)
+	}
+
+	needPluginMap := make(map[string]*kong.Plugin)
+
+	for _, plugin := range needPlugins {
+		plugin.InstanceName = pointy.String(*plugin.InstanceName + instanceSuffix)
+		needPluginMap[*plugin.InstanceName] = plugin
 
-	for _, plugin := range needPluginMap {
 		err = k.syncPlugin(plugin)
 		if err != nil {
 			return errors.Wrapf(err, "failed to sync plugin %s", *plugin.Name)
@@ -403,6 +468,12 @@ func (k *Kong) DeleteEndpoint(ep *v1.Endpoint) error {
 		return errors.Wrapf(err, "failed to delete endpoint service %s", ep.Metadata.Name)
 	}
 
+	// the endpoint is being deleted, so it is left out of the capability routes.
+	err = k.syncCapabilityRoutes(ep.Metadata.Workspace)
+	if err != nil {
+		return errors.Wrapf(err, "failed to sync capability routes of workspace %s", ep.Metadata.Workspace)
+	}
+
 	return nil
 }
 
@@ -458,8 +529,15 @@ const (
 	endpointTypeExternal = "external"
 )
 
-func (k *Kong) generateAIGatewayPlugin(ep *v1.Endpoint, curRoute *kong.Route) *kong.Plugin {
-	return &kong.Plugin{
+func (k *Kong) generateAIGatewayPlugin(
+	ep *v1.Endpoint, gwService *kong.Service, curRoute *kong.Route,
+) (*kong.Plugin, error) {
+	sampleRate := ep.Spec.RequestLogSuccessSampleRate()
+	if sampleRate < 0 || sampleRate > 1 {
+		return nil, errors.Errorf("request_logging success_sample_rate must be between 0 and 1, got %v", sampleRate)
+	}
+
+	plugin := &kong.Plugin{
 		Name:         pointy.String("neutree-ai-gateway"),
 		InstanceName: pointy.String("neutree-ai-gateway-" + util.HashString(ep.Key())),
 		Route:        curRoute,
@@ -478,8 +556,156 @@ func (k *Kong) generateAIGatewayPlugin(ep *v1.Endpoint, curRoute *kong.Route) *k
 			// for the access plugin (which is not bound to a route) to read.
 			"endpoint_type": endpointTypeInternal,
 			"endpoint_name": ep.Metadata.Name,
+			// validate_request is always set so that disabling validation clears it
+			// through syncPlugin's merge.
+			"validate_request": ep.Spec != nil && ep.Spec.ValidateRequests,
+			// trace_body_sample_rate samples the bodies captured in AI traces of
+			// successful requests; bodies of 5xx responses are always captured.
+			"trace_body_sample_rate": sampleRate,
 		},
 	}
+
+	// engine_auth_header/forward_engine_auth are always set so that removing the engine auth
+	// clears them through syncPlugin's merge.
+	engineAuthHeader, forwardEngineAuth, err := k.getEngineAuth(ep)
+	if err != nil {
+		return nil, err
+	}
+
+	plugin.Config["engine_auth_header"] = engineAuthHeader
+	plugin.Config["forward_engine_auth"] = forwardEngineAuth
+
+	// default_sampling_params is always set so that removing the defaults clears them through
+	// syncPlugin's merge.
+	plugin.Config["default_sampling_params"] = gatewaySamplingDefaults(ep)
+
+	// A multi-model endpoint serves each model as its own serve application, so the plugin
+	// dispatches on the requested model name the same way external endpoints do.
+	if ep.Spec.IsMultiModel() {
+		plugin.Config["upstreams"] = generateEndpointModelUpstreams(ep, gwService)
+	}
+
+	return plugin, nil
+}
+
+// gatewaySamplingDefaults returns the default sampling parameters the gateway sets on the
+// requests of the endpoint omitting them, or nil when there are none or the engine applies them.
+func gatewaySamplingDefaults(ep *v1.Endpoint) interface{} {
+	if ep.Spec == nil || ep.Spec.DefaultSamplingParams == nil || ep.Spec.EngineAppliesSamplingDefaults() {
+		return nil
+	}
+
+	params := ep.Spec.DefaultSamplingParams
+	defaults := map[string]interface{}{}
+
+	if params.Temperature != nil {
+		defaults["temperature"] = *params.Temperature
+	}
+
+	if params.TopP != nil {
+		defaults["top_p"] = *params.TopP
+	}
+
+	if params.MaxTokens != nil {
+		defaults["max_tokens"] = *params.MaxTokens
+	}
+
+	if len(defaults) == 0 {
+		return nil
+	}
+
+	return defaults
+}
+
+// getEngineAuth returns the Authorization header the gateway sends to the engine of the
+// endpoint, or whether the engine credential of the client is forwarded.
+func (k *Kong) getEngineAuth(ep *v1.Endpoint) (interface{}, bool, error) {
+	if ep.Spec == nil || ep.Spec.EngineAuth == nil {
+		return nil, false, nil
+	}
+
+	engineAuth := ep.Spec.EngineAuth
+
+	switch engineAuth.Mode {
+	case v1.EngineAuthModeForward:
+		return nil, true, nil
+	case v1.EngineAuthModeInject:
+	default:
+		return nil, false, errors.Errorf("unsupported engine_auth mode %q, must be %s or %s",
+			engineAuth.Mode, v1.EngineAuthModeInject, v1.EngineAuthModeForward)
+	}
+
+	ref := engineAuth.SecretRef
+	if ref == nil || ref.Name == "" || ref.Key == "" {
+		return nil, false, errors.New("engine_auth secret_ref name and key are required by the inject mode")
+	}
+
+	cluster, err := k.storage.GetClusterByName(ep.Metadata.Workspace, ep.Spec.Cluster)
+	if errors.Is(err, storage.ErrResourceNotFound) {
+		return nil, false, errors.New("cluster not found")
+	}
+
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "failed to get cluster by name %s", ep.Spec.Cluster)
+	}
+
+	if cluster.Spec == nil || cluster.Spec.Type != v1.KubernetesClusterType {
+		return nil, false, errors.New("engine_auth secret_ref is only supported on kubernetes clusters")
+	}
+
+	ctrlClient, err := k.getClusterClient(cluster)
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "failed to get client of cluster %s", cluster.Metadata.Name)
+	}
+
+	secret := &corev1.Secret{}
+
+	err = ctrlClient.Get(context.Background(), client.ObjectKey{
+		Namespace: util.ClusterNamespace(cluster),
+		Name:      ref.Name,
+	}, secret)
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "failed to get engine credential secret %s", ref.Name)
+	}
+
+	credential := strings.TrimSpace(string(secret.Data[ref.Key]))
+	if credential == "" {
+		return nil, false, errors.Errorf("engine credential secret %s has no key %s", ref.Name, ref.Key)
+	}
+
+	return "Bearer " + credential, false, nil
+}
+
+// generateEndpointModelUpstreams returns one upstream per model of a multi-model endpoint,
+// all pointing at the cluster serve address of the endpoint service.
+func generateEndpointModelUpstreams(ep *v1.Endpoint, gwService *kong.Service) []map[string]interface{} {
+	upstreams := make([]map[string]interface{}, 0, len(ep.Spec.Models)+1)
+
+	for _, model := range ep.Spec.ServedModels() {
+		upstream := map[string]interface{}{
+			"model_mapping": map[string]string{model.Name: model.Name},
+			"scheme":        *gwService.Protocol,
+			"host":          *gwService.Host,
+			"port":          *gwService.Port,
+			"path":          util.EndpointServedModelRoutePrefix(ep, model),
+			"auth_header":   nil,
+			"internal":      true,
+		}
+
+		// Requests pin a version with the model-version header, the others go to the
+		// first upstream serving the model, i.e. the primary version, or are spread by weight.
+		if model.Version != "" {
+			upstream["model_version"] = model.Version
+		}
+
+		if model.Weight != nil {
+			upstream["weight"] = *model.Weight
+		}
+
+		upstreams = append(upstreams, upstream)
+	}
+
+	return upstreams
 }
 
 func (k *Kong) generateEndpointACLPlugin(ep *v1.Endpoint, curRoute *kong.Route) *kong.Plugin {
@@ -492,19 +718,92 @@ func (k *Kong) generateExternalEndpointACLPlugin(ee *v1.ExternalEndpoint, curRou
 	return k.generateACLPlugin("neutree-acl-"+util.HashString(ee.Key()), curRoute, group)
 }
 
-func (k *Kong) generateACLPlugin(instanceName string, curRoute *kong.Route, group string) *kong.Plugin {
+func (k *Kong) generateACLPlugin(instanceName string, curRoute *kong.Route, groups ...string) *kong.Plugin {
 	return &kong.Plugin{
 		Name:         pointy.String("acl"),
 		InstanceName: pointy.String(instanceName),
 		Route:        curRoute,
 		Protocols:    []*string{pointy.String("http"), pointy.String("https")},
 		Config: map[string]interface{}{
-			"allow":              []string{group},
+			"allow":              groups,
 			"hide_groups_header": true,
 		},
 	}
 }
 
+// corsMethods are the HTTP methods accepted by the Kong cors plugin, which allows all of
+// them by default.
+var corsMethods = []string{"GET", "HEAD", "PUT", "PATCH", "POST", "DELETE", "OPTIONS", "TRACE", "CONNECT"}
+
+// generateEndpointCORSPlugin returns the cors plugin of the endpoint route, or nil when the
+// endpoint does not enable CORS. The plugin answers preflight requests itself and only adds
+// CORS headers for allowed origins.
+func (k *Kong) generateEndpointCORSPlugin(ep *v1.Endpoint, curRoute *kong.Route) (*kong.Plugin, error) {
+	if ep.Spec == nil || ep.Spec.CORS == nil || len(ep.Spec.CORS.AllowedOrigins) == 0 {
+		return nil, nil
+	}
+
+	cors := ep.Spec.CORS
+
+	for _, origin := range cors.AllowedOrigins {
+		if strings.TrimSpace(origin) == "" {
+			return nil, errors.New("cors allowed_origins must not contain empty origins")
+		}
+	}
+
+	methods := corsMethods
+	if len(cors.AllowedMethods) > 0 {
+		methods = make([]string, 0, len(cors.AllowedMethods))
+
+		for _, method := range cors.AllowedMethods {
+			method = strings.ToUpper(strings.TrimSpace(method))
+			if !slices.Contains(corsMethods, method) {
+				return nil, errors.Errorf("invalid cors allowed method %q", method)
+			}
+
+			methods = append(methods, method)
+		}
+	}
+
+	// Fields are always set so that syncPlugin's merge clears values removed from the spec;
+	// null headers make Kong echo the headers requested by the preflight request.
+	config := map[string]interface{}{
+		"origins":            cors.AllowedOrigins,
+		"methods":            methods,
+		"headers":            cors.AllowedHeaders,
+		"preflight_continue": false,
+		"credentials":        false,
+	}
+
+	return &kong.Plugin{
+		Name:         pointy.String("cors"),
+		InstanceName: pointy.String("neutree-cors-" + util.HashString(ep.Key())),
+		Route:        curRoute,
+		Protocols:    []*string{pointy.String("http"), pointy.String("https")},
+		Config:       config,
+	}, nil
+}
+
+// generateEndpointUnavailablePlugin returns the request-termination plugin answering 503 on
+// the endpoint route while the endpoint has no healthy replica, or nil when it has one.
+// Authentication and ACL plugins still run first, so only authorized clients learn about it.
+func (k *Kong) generateEndpointUnavailablePlugin(ep *v1.Endpoint, curRoute *kong.Route) *kong.Plugin {
+	if !ep.Status.HasNoHealthyReplicas() {
+		return nil
+	}
+
+	return &kong.Plugin{
+		Name:         pointy.String("request-termination"),
+		InstanceName: pointy.String("neutree-unavailable-" + util.HashString(ep.Key())),
+		Route:        curRoute,
+		Protocols:    []*string{pointy.String("http"), pointy.String("https")},
+		Config: map[string]interface{}{
+			"status_code": http.StatusServiceUnavailable,
+			"message":     fmt.Sprintf("endpoint %s has no healthy replicas", ep.Metadata.Name),
+		},
+	}
+}
+
 func (k *Kong) generateHttpLogPlugin() *kong.Plugin {
 	return &kong.Plugin{
 		Name:         pointy.String("http-log"),
@@ -590,6 +889,10 @@ func isManagedAIRoutePlugin(plugin *kong.Plugin) bool {
 		return plugin.InstanceName != nil
 	case "acl":
 		return plugin.InstanceName != nil && strings.HasPrefix(*plugin.InstanceName, "neutree-acl-")
+	case "cors":
+		return plugin.InstanceName != nil && strings.HasPrefix(*plugin.InstanceName, "neutree-cors-")
+	case "request-termination":
+		return plugin.InstanceName != nil && strings.HasPrefix(*plugin.InstanceName, "neutree-unavailable-")
 	default:
 		return false
 	}
@@ -628,8 +931,62 @@ func (k *Kong) syncEndpointRoute(ep *v1.Endpoint, gwService *kong.Service) (*kon
 	return curRoute, nil
 }
 
+// syncEndpointDomainRoute syncs the route serving the endpoint on its custom domain, which
+// the Ingress of the domain forwards the requests to. It returns nil and deletes the route
+// when the endpoint has no domain.
+func (k *Kong) syncEndpointDomainRoute(ep *v1.Endpoint, gwService *kong.Service) (*kong.Route, error) {
+	routeName := getEndpointDomainRouteName(ep)
+
+	if ep.Spec == nil || ep.Spec.Domain == nil || ep.Spec.Domain.Host == "" {
+		return nil, k.deleteRoute(routeName)
+	}
+
+	route := &kong.Route{
+		Name:      pointy.String(routeName),
+		Hosts:     []*string{pointy.String(ep.Spec.Domain.Host)},
+		Paths:     []*string{pointy.String("/")},
+		Service:   gwService,
+		Protocols: []*string{pointy.String("http"), pointy.String("https")},
+	}
+
+	curRoute, err := k.kongClient.Routes.Get(context.Background(), route.Name)
+	if err != nil && !isResourceNotFoundError(err) {
+		return nil, errors.Wrapf(err, "failed to get route by name %s", routeName)
+	}
+
+	if isResourceNotFoundError(err) {
+		curRoute, err = k.kongClient.Routes.Create(context.Background(), route)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create route by name %s", routeName)
+		}
+
+		return curRoute, nil
+	}
+
+	if len(curRoute.Hosts) != 1 || *curRoute.Hosts[0] != *route.Hosts[0] || *curRoute.Service.ID != *route.Service.ID {
+		curRoute.Hosts = route.Hosts
+		curRoute.Paths = route.Paths
+		curRoute.Service = route.Service
+
+		curRoute, err = k.kongClient.Routes.Update(context.Background(), curRoute)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to update route by name %s", routeName)
+		}
+	}
+
+	return curRoute, nil
+}
+
 func (k *Kong) deleteEndpointRoute(ep *v1.Endpoint) error {
-	routeName := "neutree-endpoint-" + util.HashString(ep.Key())
+	if err := k.deleteRoute(getEndpointDomainRouteName(ep)); err != nil {
+		return err
+	}
+
+	return k.deleteRoute("neutree-endpoint-" + util.HashString(ep.Key()))
+}
+
+// deleteRoute deletes the route with the given name, with its plugins, if it exists.
+func (k *Kong) deleteRoute(routeName string) error {
 	route, err := k.kongClient.Routes.Get(context.Background(), pointy.String(routeName))
 
 	if err != nil && !isResourceNotFoundError(err) {
@@ -648,34 +1005,70 @@ func (k *Kong) deleteEndpointRoute(ep *v1.Endpoint) error {
 	return nil
 }
 
-func (k *Kong) syncEndpointService(ep *v1.Endpoint) (*kong.Service, error) {
-	clusters, err := k.storage.ListCluster(storage.ListOption{
-		Filters: []storage.Filter{
-			{
-				Column:   "metadata->name",
-				Operator: "eq",
-				Value:    strconv.Quote(ep.Spec.Cluster),
-			},
-			{
-				Column:   "metadata->workspace",
-				Operator: "eq",
-				Value:    strconv.Quote(ep.Metadata.Workspace),
-			},
-		},
-	})
+// getFailoverEndpoint returns the standby endpoint the requests of the endpoint fail over to,
+// nil while the endpoint has a healthy replica. It is also nil when the standby endpoint can
+// not take the requests, e.g. it is not running either, so they are rejected as unavailable.
+func (k *Kong) getFailoverEndpoint(ep *v1.Endpoint) (*v1.Endpoint, error) {
+	if ep.Spec == nil || ep.Spec.Failover == nil || ep.Spec.IsMultiModel() {
+		return nil, nil
+	}
+
+	standbyName := ep.Spec.Failover.Endpoint
+	if standbyName == "" || standbyName == ep.Metadata.Name {
+		return nil, errors.Errorf("failover endpoint must name another endpoint, got %q", standbyName)
+	}
+
+	if !ep.Status.HasNoHealthyReplicas() {
+		return nil, nil
+	}
+
+	standby, err := k.storage.GetEndpointByName(ep.Metadata.Workspace, standbyName)
 	if err != nil {
-		return nil, errors.Wrapf(err, "failed to list cluster by name %s", ep.Spec.Cluster)
+		klog.Warningf("Not failing endpoint %s over: failed to get standby endpoint %s: %v",
+			ep.Metadata.WorkspaceName(), standbyName, err)
+		return nil, nil
+	}
+
+	if standby.Spec == nil || standby.Spec.Cluster == ep.Spec.Cluster {
+		klog.Warningf("Not failing endpoint %s over: standby endpoint %s is not on another cluster",
+			ep.Metadata.WorkspaceName(), standbyName)
+		return nil, nil
+	}
+
+	if !isCapabilityRoutable(standby) {
+		klog.Warningf("Not failing endpoint %s over: standby endpoint %s is not running",
+			ep.Metadata.WorkspaceName(), standbyName)
+		return nil, nil
 	}
 
-	if len(clusters) == 0 {
+	klog.Infof("Failing endpoint %s over to standby endpoint %s on cluster %s",
+		ep.Metadata.WorkspaceName(), standbyName, standby.Spec.Cluster)
+
+	return standby, nil
+}
+
+// syncEndpointService points the service of the endpoint at the endpoint on its cluster, or
+// at the standby endpoint on its own cluster while the endpoint is failed over.
+func (k *Kong) syncEndpointService(ep *v1.Endpoint, standby *v1.Endpoint) (*kong.Service, error) {
+	target := ep
+	if standby != nil {
+		target = standby
+	}
+
+	cluster, err := k.storage.GetClusterByName(ep.Metadata.Workspace, target.Spec.Cluster)
+	if errors.Is(err, storage.ErrResourceNotFound) {
 		return nil, errors.New("cluster not found")
 	}
 
-	if clusters[0].Status == nil {
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get cluster by name %s", target.Spec.Cluster)
+	}
+
+	if cluster.Status == nil {
 		return nil, errors.New("cluster is never initialized")
 	}
 
-	scheme, host, port, err := util.GetClusterServeAddress(&clusters[0])
+	scheme, host, port, err := util.GetClusterServeAddress(cluster)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to get cluster serve url")
 	}
@@ -686,7 +1079,7 @@ func (k *Kong) syncEndpointService(ep *v1.Endpoint) (*kong.Service, error) {
 		Host:        &host,
 		Port:        &port,
 		Protocol:    &scheme,
-		Path:        pointy.String(fmt.Sprintf("/%s/%s", ep.Metadata.Workspace, ep.Metadata.Name)),
+		Path:        pointy.String(fmt.Sprintf("/%s/%s", target.Metadata.Workspace, target.Metadata.Name)),
 		ReadTimeout: pointy.Int(60000 * 60),
 	}
 
@@ -766,6 +1159,13 @@ func getEndpointRouteType(ep *v1.Endpoint) string {
 	return v1.RouteTypeChatCompletions
 }
 
+// endpointDomainPluginSuffix suffixes the plugins of the endpoint domain route.
+const endpointDomainPluginSuffix = "-domain"
+
+func getEndpointDomainRouteName(ep *v1.Endpoint) string {
+	return "neutree-endpoint-domain-" + util.HashString(ep.Key())
+}
+
 func getEndpointRoutePath(ep *v1.Endpoint) string {
 	return "/workspace/" + ep.Metadata.Workspace + "/endpoint/" + ep.Metadata.Name
 }
@@ -852,57 +1252,30 @@ func (k *Kong) GetExternalEndpointServeUrl(ee *v1.ExternalEndpoint) (string, err
 // resolveEndpointRef resolves an endpoint ref (internal endpoint name) to its cluster serve address.
 // Returns scheme, host, port, path for the resolved endpoint.
 func (k *Kong) resolveEndpointRef(workspace, endpointName string) (scheme, host string, port int, path string, err error) {
-	endpoints, err := k.storage.ListEndpoint(storage.ListOption{
-		Filters: []storage.Filter{
-			{
-				Column:   "metadata->name",
-				Operator: "eq",
-				Value:    strconv.Quote(endpointName),
-			},
-			{
-				Column:   "metadata->workspace",
-				Operator: "eq",
-				Value:    strconv.Quote(workspace),
-			},
-		},
-	})
-	if err != nil {
-		return "", "", 0, "", errors.Wrapf(err, "failed to list endpoint by name %s", endpointName)
+	ep, err := k.storage.GetEndpointByName(workspace, endpointName)
+	if err != nil && !errors.Is(err, storage.ErrResourceNotFound) {
+		return "", "", 0, "", errors.Wrapf(err, "failed to get endpoint by name %s", endpointName)
 	}
 
-	if len(endpoints) == 0 {
+	// endpoints being deleted no longer serve requests.
+	if err != nil || ep.GetDeletionTimestamp() != "" {
 		return "", "", 0, "", fmt.Errorf("internal endpoint %s not found in workspace %s", endpointName, workspace)
 	}
 
-	ep := &endpoints[0]
-
-	clusters, err := k.storage.ListCluster(storage.ListOption{
-		Filters: []storage.Filter{
-			{
-				Column:   "metadata->name",
-				Operator: "eq",
-				Value:    strconv.Quote(ep.Spec.Cluster),
-			},
-			{
-				Column:   "metadata->workspace",
-				Operator: "eq",
-				Value:    strconv.Quote(workspace),
-			},
-		},
-	})
-	if err != nil {
-		return "", "", 0, "", errors.Wrapf(err, "failed to list cluster by name %s", ep.Spec.Cluster)
+	cluster, err := k.storage.GetClusterByName(workspace, ep.Spec.Cluster)
+	if errors.Is(err, storage.ErrResourceNotFound) {
+		return "", "", 0, "", fmt.Errorf("cluster %s not found for endpoint %s", ep.Spec.Cluster, endpointName)
 	}
 
-	if len(clusters) == 0 {
-		return "", "", 0, "", fmt.Errorf("cluster %s not found for endpoint %s", ep.Spec.Cluster, endpointName)
+	if err != nil {
+		return "", "", 0, "", errors.Wrapf(err, "failed to get cluster by name %s", ep.Spec.Cluster)
 	}
 
-	if clusters[0].Status == nil {
+	if cluster.Status == nil {
 		return "", "", 0, "", fmt.Errorf("cluster %s is never initialized", ep.Spec.Cluster)
 	}
 
-	scheme, host, port, err = util.GetClusterServeAddress(&clusters[0])
+	scheme, host, port, err = util.GetClusterServeAddress(cluster)
 	if err != nil {
 		return "", "", 0, "", errors.Wrapf(err, "failed to get cluster serve address")
 	}
@@ -0,0 +1,385 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/kong/go-kong/kong"
+	"github.com/pkg/errors"
+	"go.openly.dev/pointy"
+	"k8s.io/klog/v2"
+
+	v1 "github.com/neutree-ai/neutree/api/v1"
+	"github.com/neutree-ai/neutree/internal/util"
+	"github.com/neutree-ai/neutree/pkg/storage"
+)
+
+// capabilityRouteTag tags the Kong services and routes of capability routes.
+const capabilityRouteTag = "neutree-capability"
+
+// capabilityPattern restricts capability tags to what can be part of a route path.
+var capabilityPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9._-]*[a-z0-9])?$`)
+
+// syncCapabilityRoutes routes each capability tag carried by the running endpoints of the
+// workspace to those endpoints, and removes the routes of the tags none of them carries.
+func (k *Kong) syncCapabilityRoutes(workspace string) error {
+	endpoints, err := k.storage.ListEndpoint(storage.ListOption{Filters: workspaceFilters(workspace)})
+	if err != nil {
+		return errors.Wrapf(err, "failed to list endpoints in workspace %s", workspace)
+	}
+
+	groups := groupEndpointsByCapability(endpoints)
+
+	var clusters map[string]*v1.Cluster
+
+	var registries map[string]*v1.ModelRegistry
+
+	if len(groups) > 0 {
+		clusters, registries, err = k.listCapabilityDependencies(workspace)
+		if err != nil {
+			return err
+		}
+	}
+
+	desired := make(map[string]struct{}, len(groups))
+
+	for capability, eps := range groups {
+		upstreams, aclGroups := k.generateCapabilityUpstreams(capability, eps, clusters, registries)
+		if len(upstreams) == 0 {
+			continue
+		}
+
+		err = k.syncCapabilityRoute(workspace, capability, upstreams, aclGroups)
+		if err != nil {
+			return errors.Wrapf(err, "failed to sync capability route %s", capability)
+		}
+
+		desired[capabilityResourceName(workspace, capability)] = struct{}{}
+	}
+
+	return k.deleteStaleCapabilityRoutes(workspace, desired)
+}
+
+func (k *Kong) listCapabilityDependencies(workspace string) (map[string]*v1.Cluster, map[string]*v1.ModelRegistry, error) {
+	clusterList, err := k.storage.ListCluster(storage.ListOption{Filters: workspaceFilters(workspace)})
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to list clusters in workspace %s", workspace)
+	}
+
+	clusters := make(map[string]*v1.Cluster, len(clusterList))
+	for i := range clusterList {
+		clusters[clusterList[i].Metadata.Name] = &clusterList[i]
+	}
+
+	registryList, err := k.storage.ListModelRegistry(storage.ListOption{Filters: workspaceFilters(workspace)})
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to list model registries in workspace %s", workspace)
+	}
+
+	registries := make(map[string]*v1.ModelRegistry, len(registryList))
+	for i := range registryList {
+		registries[registryList[i].Metadata.Name] = &registryList[i]
+	}
+
+	return clusters, registries, nil
+}
+
+// groupEndpointsByCapability returns the running endpoints carrying each capability tag,
+// ordered by name so the generated upstreams do not change between syncs.
+func groupEndpointsByCapability(endpoints []v1.Endpoint) map[string][]*v1.Endpoint {
+	groups := map[string][]*v1.Endpoint{}
+
+	for i := range endpoints {
+		ep := &endpoints[i]
+		if !isCapabilityRoutable(ep) {
+			continue
+		}
+
+		seen := map[string]struct{}{}
+
+		for _, capability := range ep.Spec.Capabilities {
+			if !capabilityPattern.MatchString(capability) {
+				klog.Warningf("Ignoring invalid capability %q of endpoint %s", capability, ep.Metadata.WorkspaceName())
+				continue
+			}
+
+			if _, ok := seen[capability]; ok {
+				continue
+			}
+
+			seen[capability] = struct{}{}
+			groups[capability] = append(groups[capability], ep)
+		}
+	}
+
+	for _, eps := range groups {
+		sort.Slice(eps, func(i, j int) bool {
+			return eps[i].Metadata.Name < eps[j].Metadata.Name
+		})
+	}
+
+	return groups
+}
+
+// isCapabilityRoutable reports whether requests can be dispatched to the endpoint, i.e. it
+// is running, possibly degraded, and not being deleted.
+func isCapabilityRoutable(ep *v1.Endpoint) bool {
+	return ep.Metadata != nil && ep.Metadata.DeletionTimestamp == "" &&
+		ep.Spec != nil && ep.Spec.Model != nil && ep.Status != nil &&
+		(ep.Status.Phase == v1.EndpointPhaseRUNNING || ep.Status.Phase == v1.EndpointPhaseDEGRADED)
+}
+
+// generateCapabilityUpstreams returns one upstream per endpoint of the capability, all
+// serving the capability tag as their model name, and the ACL groups of those endpoints.
+// Endpoints whose upstream can not be resolved are left out of the route.
+func (k *Kong) generateCapabilityUpstreams(
+	capability string,
+	endpoints []*v1.Endpoint,
+	clusters map[string]*v1.Cluster,
+	registries map[string]*v1.ModelRegistry,
+) ([]map[string]interface{}, []string) {
+	upstreams := make([]map[string]interface{}, 0, len(endpoints))
+	aclGroups := make([]string, 0, len(endpoints))
+
+	for _, ep := range endpoints {
+		upstream, err := k.generateCapabilityUpstream(capability, ep, clusters, registries)
+		if err != nil {
+			klog.Warningf("Skipping endpoint %s of capability %s: %v", ep.Metadata.WorkspaceName(), capability, err)
+			continue
+		}
+
+		upstreams = append(upstreams, upstream)
+		aclGroups = append(aclGroups, BuildNeutreeACLGroup(ep.Metadata.Workspace, ACLResourceEndpoint, ep.Metadata.Name))
+	}
+
+	return upstreams, aclGroups
+}
+
+func (k *Kong) generateCapabilityUpstream(
+	capability string,
+	ep *v1.Endpoint,
+	clusters map[string]*v1.Cluster,
+	registries map[string]*v1.ModelRegistry,
+) (map[string]interface{}, error) {
+	cluster, ok := clusters[ep.Spec.Cluster]
+	if !ok {
+		return nil, errors.Errorf("cluster %s not found", ep.Spec.Cluster)
+	}
+
+	scheme, host, port, err := util.GetClusterServeAddress(cluster)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get serve address of cluster %s", ep.Spec.Cluster)
+	}
+
+	// the injected engine credential is sent per upstream, as the endpoints of a
+	// capability may each enforce their own.
+	authHeader, _, err := k.getEngineAuth(ep)
+	if err != nil {
+		return nil, err
+	}
+
+	path := fmt.Sprintf("/%s/%s", ep.Metadata.Workspace, ep.Metadata.Name)
+	if ep.Spec.IsMultiModel() {
+		path = util.EndpointServedModelRoutePrefix(ep, ep.Spec.Model)
+	}
+
+	return map[string]interface{}{
+		"model_mapping": map[string]string{capability: endpointServeModelName(ep, registries[ep.Spec.Model.Registry])},
+		"scheme":        scheme,
+		"host":          host,
+		"port":          port,
+		"path":          path,
+		"auth_header":   authHeader,
+		"internal":      true,
+		"endpoint_name": ep.Metadata.Name,
+	}, nil
+}
+
+// endpointServeModelName returns the name the engine serves the endpoint model under,
+// matching the serve_name the orchestrators deploy it with.
+func endpointServeModelName(ep *v1.Endpoint, registry *v1.ModelRegistry) string {
+	model := ep.Spec.Model
+	if ep.Spec.Engine != nil && ep.Spec.Engine.Engine == v1.EngineNameSGLang {
+		return model.Name
+	}
+
+	if model.Version == "" || model.Version == v1.LatestVersion ||
+		(registry != nil && registry.Spec != nil && registry.Spec.Type == v1.HuggingFaceModelRegistryType) {
+		return model.Name
+	}
+
+	return model.Name + ":" + model.Version
+}
+
+func (k *Kong) syncCapabilityRoute(workspace, capability string, upstreams []map[string]interface{}, aclGroups []string) error {
+	gwService, err := k.syncCapabilityService(workspace, capability, upstreams[0])
+	if err != nil {
+		return err
+	}
+
+	name := capabilityResourceName(workspace, capability)
+	route := &kong.Route{
+		Name:      pointy.String(name),
+		Paths:     []*string{pointy.String(getCapabilityRoutePath(workspace, capability))},
+		Service:   gwService,
+		Protocols: []*string{pointy.String("http"), pointy.String("https")},
+		Tags:      capabilityTags(workspace),
+	}
+
+	curRoute, err := k.kongClient.Routes.Get(context.Background(), route.Name)
+	if err != nil && !isResourceNotFoundError(err) {
+		return errors.Wrapf(err, "failed to get route by name %s", name)
+	}
+
+	if isResourceNotFoundError(err) {
+		curRoute, err = k.kongClient.Routes.Create(context.Background(), route)
+		if err != nil {
+			return errors.Wrapf(err, "failed to create route by name %s", name)
+		}
+	}
+
+	if *curRoute.Paths[0] != *route.Paths[0] || *curRoute.Service.ID != *route.Service.ID {
+		curRoute.Paths = route.Paths
+		curRoute.Service = route.Service
+
+		_, err = k.kongClient.Routes.Update(context.Background(), curRoute)
+		if err != nil {
+			return errors.Wrapf(err, "failed to update route by name %s", name)
+		}
+	}
+
+	plugins := []*kong.Plugin{
+		k.generateCapabilityAIGatewayPlugin(workspace, capability, upstreams, curRoute),
+		k.generateACLPlugin("neutree-acl-"+util.HashString(name), curRoute, aclGroups...),
+	}
+
+	for _, plugin := range plugins {
+		err = k.syncPlugin(plugin)
+		if err != nil {
+			return errors.Wrapf(err, "failed to sync plugin %s", *plugin.Name)
+		}
+	}
+
+	return nil
+}
+
+// syncCapabilityService syncs the service of a capability route. Requests are sent to the
+// upstream picked by the ai gateway plugin, the service only gives the route a default one.
+func (k *Kong) syncCapabilityService(workspace, capability string, upstream map[string]interface{}) (*kong.Service, error) {
+	name := capabilityResourceName(workspace, capability)
+	gwService := &kong.Service{
+		Name:        pointy.String(name),
+		Host:        pointy.String(upstream["host"].(string)),
+		Port:        pointy.Int(upstream["port"].(int)),
+		Protocol:    pointy.String(upstream["scheme"].(string)),
+		Path:        pointy.String(upstream["path"].(string)),
+		ReadTimeout: pointy.Int(60000 * 60),
+		Tags:        capabilityTags(workspace),
+	}
+
+	curGwService, err := k.kongClient.Services.Get(context.Background(), gwService.Name)
+	if err != nil && !isResourceNotFoundError(err) {
+		return nil, errors.Wrapf(err, "failed to get service by name %s", name)
+	}
+
+	if isResourceNotFoundError(err) {
+		curGwService, err = k.kongClient.Services.Create(context.Background(), gwService)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create service by name %s", name)
+		}
+	}
+
+	if *curGwService.Host != *gwService.Host || *curGwService.Port != *gwService.Port ||
+		*curGwService.Protocol != *gwService.Protocol || *curGwService.Path != *gwService.Path {
+		curGwService.Host = gwService.Host
+		curGwService.Port = gwService.Port
+		curGwService.Protocol = gwService.Protocol
+		curGwService.Path = gwService.Path
+
+		_, err = k.kongClient.Services.Update(context.Background(), curGwService)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to update service by name %s", name)
+		}
+	}
+
+	return curGwService, nil
+}
+
+func (k *Kong) generateCapabilityAIGatewayPlugin(
+	workspace, capability string, upstreams []map[string]interface{}, curRoute *kong.Route,
+) *kong.Plugin {
+	return &kong.Plugin{
+		Name:         pointy.String("neutree-ai-gateway"),
+		InstanceName: pointy.String("neutree-ai-gateway-" + util.HashString(capabilityResourceName(workspace, capability))),
+		Route:        curRoute,
+		Protocols:    []*string{pointy.String("http"), pointy.String("https")},
+		Config: map[string]interface{}{
+			"route_prefix": getCapabilityRoutePath(workspace, capability),
+			"upstreams":    upstreams,
+			"load_balance": true,
+			// the endpoint name is stashed per upstream once the request is dispatched.
+			"endpoint_type": endpointTypeInternal,
+			// the credential injected for an endpoint takes precedence over the forwarded one.
+			"forward_engine_auth": true,
+		},
+	}
+}
+
+// deleteStaleCapabilityRoutes deletes the capability routes of the workspace which are not
+// desired anymore, along with their services.
+func (k *Kong) deleteStaleCapabilityRoutes(workspace string, desired map[string]struct{}) error {
+	var routes []*kong.Route
+
+	opt := &kong.ListOpt{Size: 1000, Tags: capabilityTags(workspace), MatchAllTags: true}
+	for opt != nil {
+		var (
+			data []*kong.Route
+			err  error
+		)
+
+		data, opt, err = k.kongClient.Routes.List(context.Background(), opt)
+		if err != nil {
+			return errors.Wrapf(err, "failed to list capability routes of workspace %s", workspace)
+		}
+
+		routes = append(routes, data...)
+	}
+
+	for _, route := range routes {
+		if route.Name == nil {
+			continue
+		}
+
+		if _, ok := desired[*route.Name]; ok {
+			continue
+		}
+
+		err := k.kongClient.Routes.Delete(context.Background(), route.ID)
+		if err != nil {
+			return errors.Wrapf(err, "failed to delete route by name %s", *route.Name)
+		}
+
+		err = k.kongClient.Services.Delete(context.Background(), route.Name)
+		if err != nil && !isResourceNotFoundError(err) {
+			return errors.Wrapf(err, "failed to delete service by name %s", *route.Name)
+		}
+	}
+
+	return nil
+}
+
+func capabilityResourceName(workspace, capability string) string {
+	return "neutree-capability-" + util.HashString(workspace+"/"+capability)
+}
+
+// capabilityTags returns the Kong tags of the capability routes of a workspace. The
+// workspace is hashed as Kong tags can not hold every character.
+func capabilityTags(workspace string) []*string {
+	return []*string{pointy.String(capabilityRouteTag), pointy.String(capabilityRouteTag + "-" + util.HashString(workspace))}
+}
+
+func getCapabilityRoutePath(workspace, capability string) string {
+	return "/workspace/" + workspace + "/capability/" + capability
+}
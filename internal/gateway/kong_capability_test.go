@@ -0,0 +1,169 @@
+package gateway
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	v1 "github.com/neutree-ai/neutree/api/v1"
+)
+
+func capabilityTestEndpoint(name string, phase v1.EndpointPhase, capabilities ...string) v1.Endpoint {
+	return v1.Endpoint{
+		Metadata: &v1.Metadata{Name: name, Workspace: "workspace-a"},
+		Spec: &v1.EndpointSpec{
+			Cluster:      "cluster-a",
+			Model:        &v1.ModelSpec{Name: "llama3", Registry: "hub"},
+			Capabilities: capabilities,
+		},
+		Status: &v1.EndpointStatus{Phase: phase},
+	}
+}
+
+func capabilityEndpointNames(eps []*v1.Endpoint) []string {
+	names := make([]string, 0, len(eps))
+	for _, ep := range eps {
+		names = append(names, ep.Metadata.Name)
+	}
+
+	return names
+}
+
+func TestGroupEndpointsByCapability(t *testing.T) {
+	deleting := capabilityTestEndpoint("chat-deleting", v1.EndpointPhaseRUNNING, "chat")
+	deleting.Metadata.DeletionTimestamp = "2025-01-01T00:00:00Z"
+
+	tests := []struct {
+		name      string
+		endpoints []v1.Endpoint
+		expect    map[string][]string
+	}{
+		{
+			name: "no capabilities",
+			endpoints: []v1.Endpoint{
+				capabilityTestEndpoint("chat-a", v1.EndpointPhaseRUNNING),
+			},
+			expect: map[string][]string{},
+		},
+		{
+			name: "endpoints grouped by capability and ordered by name",
+			endpoints: []v1.Endpoint{
+				capabilityTestEndpoint("chat-b", v1.EndpointPhaseRUNNING, "chat", "code"),
+				capabilityTestEndpoint("chat-a", v1.EndpointPhaseRUNNING, "chat"),
+				capabilityTestEndpoint("embed-a", v1.EndpointPhaseRUNNING, "embedding"),
+			},
+			expect: map[string][]string{
+				"chat":      {"chat-a", "chat-b"},
+				"code":      {"chat-b"},
+				"embedding": {"embed-a"},
+			},
+		},
+		{
+			name: "only serving endpoints are routed",
+			endpoints: []v1.Endpoint{
+				capabilityTestEndpoint("chat-a", v1.EndpointPhaseRUNNING, "chat"),
+				capabilityTestEndpoint("chat-degraded", v1.EndpointPhaseDEGRADED, "chat"),
+				capabilityTestEndpoint("chat-failed", v1.EndpointPhaseFAILED, "chat"),
+				capabilityTestEndpoint("chat-paused", v1.EndpointPhasePAUSED, "chat"),
+				capabilityTestEndpoint("chat-deploying", v1.EndpointPhaseDEPLOYING, "chat"),
+				deleting,
+			},
+			expect: map[string][]string{"chat": {"chat-a", "chat-degraded"}},
+		},
+		{
+			name: "invalid and repeated capabilities are ignored",
+			endpoints: []v1.Endpoint{
+				capabilityTestEndpoint("chat-a", v1.EndpointPhaseRUNNING, "chat", "chat", "Chat", "a/b", ""),
+			},
+			expect: map[string][]string{"chat": {"chat-a"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			groups := groupEndpointsByCapability(tt.endpoints)
+
+			got := map[string][]string{}
+			for capability, eps := range groups {
+				got[capability] = capabilityEndpointNames(eps)
+			}
+
+			assert.Equal(t, tt.expect, got)
+		})
+	}
+}
+
+func TestGenerateCapabilityUpstreams(t *testing.T) {
+	clusters := map[string]*v1.Cluster{
+		"cluster-a": {
+			Metadata: &v1.Metadata{Name: "cluster-a"},
+			Spec:     &v1.ClusterSpec{Type: v1.SSHClusterType},
+			Status:   &v1.ClusterStatus{DashboardURL: "http://10.0.0.1:8265"},
+		},
+		"cluster-b": {
+			Metadata: &v1.Metadata{Name: "cluster-b"},
+			Spec:     &v1.ClusterSpec{Type: v1.SSHClusterType},
+			Status:   &v1.ClusterStatus{DashboardURL: "http://10.0.0.2:8265"},
+		},
+	}
+	registries := map[string]*v1.ModelRegistry{
+		"hub":    {Spec: &v1.ModelRegistrySpec{Type: v1.HuggingFaceModelRegistryType}},
+		"bentos": {Spec: &v1.ModelRegistrySpec{Type: v1.BentoMLModelRegistryType}},
+	}
+
+	chatA := capabilityTestEndpoint("chat-a", v1.EndpointPhaseRUNNING, "chat")
+	chatB := capabilityTestEndpoint("chat-b", v1.EndpointPhaseRUNNING, "chat")
+	chatB.Spec.Cluster = "cluster-b"
+	chatB.Spec.Model = &v1.ModelSpec{Name: "qwen", Version: "v1", Registry: "bentos"}
+	chatMulti := capabilityTestEndpoint("chat-multi", v1.EndpointPhaseRUNNING, "chat")
+	chatMulti.Spec.Models = []*v1.ModelSpec{{Name: "Qwen/Qwen3-8B"}}
+	chatOrphan := capabilityTestEndpoint("chat-orphan", v1.EndpointPhaseRUNNING, "chat")
+	chatOrphan.Spec.Cluster = "cluster-missing"
+
+	upstreams, aclGroups := (&Kong{}).generateCapabilityUpstreams("chat",
+		[]*v1.Endpoint{&chatA, &chatB, &chatMulti, &chatOrphan}, clusters, registries)
+
+	require.Len(t, upstreams, 3)
+	assert.Equal(t, []map[string]interface{}{
+		{
+			"model_mapping": map[string]string{"chat": "llama3"},
+			"scheme":        "http",
+			"host":          "10.0.0.1",
+			"port":          8000,
+			"path":          "/workspace-a/chat-a",
+			"auth_header":   nil,
+			"internal":      true,
+			"endpoint_name": "chat-a",
+		},
+		{
+			"model_mapping": map[string]string{"chat": "qwen:v1"},
+			"scheme":        "http",
+			"host":          "10.0.0.2",
+			"port":          8000,
+			"path":          "/workspace-a/chat-b",
+			"auth_header":   nil,
+			"internal":      true,
+			"endpoint_name": "chat-b",
+		},
+		{
+			"model_mapping": map[string]string{"chat": "llama3"},
+			"scheme":        "http",
+			"host":          "10.0.0.1",
+			"port":          8000,
+			"path":          "/workspace-a/chat-multi/llama3",
+			"auth_header":   nil,
+			"internal":      true,
+			"endpoint_name": "chat-multi",
+		},
+	}, upstreams)
+	assert.Equal(t, []string{
+		BuildNeutreeACLGroup("workspace-a", ACLResourceEndpoint, "chat-a"),
+		BuildNeutreeACLGroup("workspace-a", ACLResourceEndpoint, "chat-b"),
+		BuildNeutreeACLGroup("workspace-a", ACLResourceEndpoint, "chat-multi"),
+	}, aclGroups)
+
+	plugin := (&Kong{}).generateCapabilityAIGatewayPlugin("workspace-a", "chat", upstreams, nil)
+	assert.Equal(t, "/workspace/workspace-a/capability/chat", plugin.Config["route_prefix"])
+	assert.Equal(t, true, plugin.Config["load_balance"])
+}
@@ -0,0 +1,738 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kong/go-kong/kong"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.openly.dev/pointy"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	v1 "github.com/neutree-ai/neutree/api/v1"
+	"github.com/neutree-ai/neutree/internal/util"
+	"github.com/neutree-ai/neutree/pkg/storage"
+	storagemocks "github.com/neutree-ai/neutree/pkg/storage/mocks"
+)
+
+func TestGenerateAIGatewayPlugin(t *testing.T) {
+	route := &kong.Route{ID: pointy.String("route-1")}
+	gwService := &kong.Service{
+		Protocol: pointy.String("http"),
+		Host:     pointy.String("10.0.0.1"),
+		Port:     pointy.Int(8000),
+		Path:     pointy.String("/workspace-a/chat-a"),
+	}
+
+	tests := []struct {
+		name             string
+		models           []*v1.ModelSpec
+		validateRequests bool
+		requestLogging   *v1.EndpointRequestLoggingSpec
+		expectSampleRate float64
+		expectError      string
+		expectUpstreams  []map[string]interface{}
+	}{
+		{
+			name:             "single model endpoint routes through the service",
+			expectSampleRate: 1,
+		},
+		{
+			name:             "request validation enabled",
+			validateRequests: true,
+			expectSampleRate: 1,
+		},
+		{
+			name:             "request logging samples successful request bodies",
+			requestLogging:   &v1.EndpointRequestLoggingSpec{SuccessSampleRate: pointy.Float64(0.05)},
+			expectSampleRate: 0.05,
+		},
+		{
+			name:             "request logging without sample rate captures all bodies",
+			requestLogging:   &v1.EndpointRequestLoggingSpec{},
+			expectSampleRate: 1,
+		},
+		{
+			name:           "request logging rejects sample rate out of range",
+			requestLogging: &v1.EndpointRequestLoggingSpec{SuccessSampleRate: pointy.Float64(1.5)},
+			expectError:    "success_sample_rate must be between 0 and 1",
+		},
+		{
+			name:             "multi-model endpoint dispatches by model name",
+			models:           []*v1.ModelSpec{{Name: "Qwen/Qwen3-8B"}},
+			expectSampleRate: 1,
+			expectUpstreams: []map[string]interface{}{
+				{
+					"model_mapping": map[string]string{"llama3": "llama3"},
+					"scheme":        "http",
+					"host":          "10.0.0.1",
+					"port":          8000,
+					"path":          "/workspace-a/chat-a/llama3",
+					"auth_header":   nil,
+					"internal":      true,
+				},
+				{
+					"model_mapping": map[string]string{"Qwen/Qwen3-8B": "Qwen/Qwen3-8B"},
+					"scheme":        "http",
+					"host":          "10.0.0.1",
+					"port":          8000,
+					"path":          "/workspace-a/chat-a/qwen-qwen3-8b",
+					"auth_header":   nil,
+					"internal":      true,
+				},
+			},
+		},
+		{
+			name:             "multi-version endpoint pins versions by upstream",
+			models:           []*v1.ModelSpec{{Name: "llama3", Version: "v2"}},
+			expectSampleRate: 1,
+			expectUpstreams: []map[string]interface{}{
+				{
+					"model_mapping": map[string]string{"llama3": "llama3"},
+					"scheme":        "http",
+					"host":          "10.0.0.1",
+					"port":          8000,
+					"path":          "/workspace-a/chat-a/llama3",
+					"auth_header":   nil,
+					"internal":      true,
+				},
+				{
+					"model_mapping": map[string]string{"llama3": "llama3"},
+					"scheme":        "http",
+					"host":          "10.0.0.1",
+					"port":          8000,
+					"path":          "/workspace-a/chat-a/llama3-v2",
+					"auth_header":   nil,
+					"internal":      true,
+					"model_version": "v2",
+				},
+			},
+		},
+		{
+			name:             "weighted versions spread unpinned requests",
+			models:           []*v1.ModelSpec{{Name: "llama3", Version: "v2", Weight: pointy.Int(10)}},
+			expectSampleRate: 1,
+			expectUpstreams: []map[string]interface{}{
+				{
+					"model_mapping": map[string]string{"llama3": "llama3"},
+					"scheme":        "http",
+					"host":          "10.0.0.1",
+					"port":          8000,
+					"path":          "/workspace-a/chat-a/llama3",
+					"auth_header":   nil,
+					"internal":      true,
+				},
+				{
+					"model_mapping": map[string]string{"llama3": "llama3"},
+					"scheme":        "http",
+					"host":          "10.0.0.1",
+					"port":          8000,
+					"path":          "/workspace-a/chat-a/llama3-v2",
+					"auth_header":   nil,
+					"internal":      true,
+					"model_version": "v2",
+					"weight":        10,
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k := &Kong{}
+			ep := &v1.Endpoint{
+				Metadata: &v1.Metadata{Name: "chat-a", Workspace: "workspace-a"},
+				Spec: &v1.EndpointSpec{
+					Model:            &v1.ModelSpec{Name: "llama3", Task: v1.TextGenerationModelTask},
+					Models:           tt.models,
+					ValidateRequests: tt.validateRequests,
+					RequestLogging:   tt.requestLogging,
+				},
+			}
+
+			plugin, err := k.generateAIGatewayPlugin(ep, gwService, route)
+			if tt.expectError != "" {
+				require.ErrorContains(t, err, tt.expectError)
+				return
+			}
+
+			require.NoError(t, err)
+
+			assert.Equal(t, "neutree-ai-gateway", *plugin.Name)
+			assert.Equal(t, route, plugin.Route)
+			assert.Equal(t, "/workspace/workspace-a/endpoint/chat-a", plugin.Config["route_prefix"])
+			assert.Equal(t, endpointTypeInternal, plugin.Config["endpoint_type"])
+			assert.Equal(t, tt.validateRequests, plugin.Config["validate_request"])
+			assert.Equal(t, tt.expectSampleRate, plugin.Config["trace_body_sample_rate"])
+
+			if tt.expectUpstreams == nil {
+				assert.NotContains(t, plugin.Config, "upstreams")
+				return
+			}
+
+			assert.Equal(t, tt.expectUpstreams, plugin.Config["upstreams"])
+		})
+	}
+}
+
+func TestGenerateAIGatewayPluginSamplingDefaults(t *testing.T) {
+	route := &kong.Route{ID: pointy.String("route-1")}
+	gwService := &kong.Service{
+		Protocol: pointy.String("http"),
+		Host:     pointy.String("10.0.0.1"),
+		Port:     pointy.Int(8000),
+	}
+
+	tests := []struct {
+		name     string
+		engine   string
+		params   *v1.SamplingParams
+		expected interface{}
+	}{
+		{
+			name:     "no defaults",
+			engine:   v1.EngineNameLlamaCpp,
+			expected: nil,
+		},
+		{
+			name:   "applied by the gateway",
+			engine: v1.EngineNameLlamaCpp,
+			params: &v1.SamplingParams{Temperature: pointy.Float64(0.2), MaxTokens: pointy.Int(512)},
+			expected: map[string]interface{}{
+				"temperature": 0.2,
+				"max_tokens":  512,
+			},
+		},
+		{
+			name:     "applied by the engine",
+			engine:   v1.EngineNameVLLM,
+			params:   &v1.SamplingParams{Temperature: pointy.Float64(0.2)},
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k := &Kong{}
+			ep := &v1.Endpoint{
+				Metadata: &v1.Metadata{Name: "chat-a", Workspace: "workspace-a"},
+				Spec: &v1.EndpointSpec{
+					Model:                 &v1.ModelSpec{Name: "llama3", Task: v1.TextGenerationModelTask},
+					Engine:                &v1.EndpointEngineSpec{Engine: tt.engine},
+					DefaultSamplingParams: tt.params,
+				},
+			}
+
+			plugin, err := k.generateAIGatewayPlugin(ep, gwService, route)
+			require.NoError(t, err)
+
+			// The key is always set so that removing the defaults clears them from the plugin.
+			require.Contains(t, plugin.Config, "default_sampling_params")
+			assert.Equal(t, tt.expected, plugin.Config["default_sampling_params"])
+		})
+	}
+}
+
+func TestGenerateAIGatewayPluginEngineAuth(t *testing.T) {
+	route := &kong.Route{ID: pointy.String("route-1")}
+	gwService := &kong.Service{
+		Protocol: pointy.String("http"),
+		Host:     pointy.String("10.0.0.1"),
+		Port:     pointy.Int(8000),
+	}
+	cluster := v1.Cluster{
+		Metadata: &v1.Metadata{Name: "k8s-a", Workspace: "workspace-a"},
+		Spec:     &v1.ClusterSpec{Type: v1.KubernetesClusterType},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "vllm-api-key", Namespace: util.ClusterNamespace(&cluster)},
+		Data:       map[string][]byte{"api-key": []byte("engine-key\n")},
+	}
+
+	tests := []struct {
+		name          string
+		engineAuth    *v1.EndpointEngineAuthSpec
+		cluster       v1.Cluster
+		expectHeader  interface{}
+		expectForward bool
+		expectError   string
+	}{
+		{
+			name: "no engine auth",
+		},
+		{
+			name: "inject the engine credential of the secret",
+			engineAuth: &v1.EndpointEngineAuthSpec{
+				Mode:      v1.EngineAuthModeInject,
+				SecretRef: &v1.SecretKeyReference{Name: "vllm-api-key", Key: "api-key"},
+			},
+			cluster:      cluster,
+			expectHeader: "Bearer engine-key",
+		},
+		{
+			name:          "forward the engine credential of the client",
+			engineAuth:    &v1.EndpointEngineAuthSpec{Mode: v1.EngineAuthModeForward},
+			expectForward: true,
+		},
+		{
+			name:        "unsupported mode",
+			engineAuth:  &v1.EndpointEngineAuthSpec{Mode: "basic"},
+			expectError: `unsupported engine_auth mode "basic"`,
+		},
+		{
+			name:        "inject without secret ref",
+			engineAuth:  &v1.EndpointEngineAuthSpec{Mode: v1.EngineAuthModeInject},
+			expectError: "secret_ref name and key are required",
+		},
+		{
+			name: "missing secret key",
+			engineAuth: &v1.EndpointEngineAuthSpec{
+				Mode:      v1.EngineAuthModeInject,
+				SecretRef: &v1.SecretKeyReference{Name: "vllm-api-key", Key: "token"},
+			},
+			cluster:     cluster,
+			expectError: "engine credential secret vllm-api-key has no key token",
+		},
+		{
+			name: "ssh cluster",
+			engineAuth: &v1.EndpointEngineAuthSpec{
+				Mode:      v1.EngineAuthModeInject,
+				SecretRef: &v1.SecretKeyReference{Name: "vllm-api-key", Key: "api-key"},
+			},
+			cluster: v1.Cluster{
+				Metadata: &v1.Metadata{Name: "ssh-a", Workspace: "workspace-a"},
+				Spec:     &v1.ClusterSpec{Type: v1.SSHClusterType},
+			},
+			expectError: "only supported on kubernetes clusters",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := storagemocks.NewMockStorage(t)
+			if tt.cluster.Metadata != nil {
+				s.On("GetClusterByName", mock.Anything, mock.Anything).Return(&tt.cluster, nil).Once()
+			}
+
+			k := &Kong{
+				storage: s,
+				getClusterClient: func(_ *v1.Cluster) (client.Client, error) {
+					return fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(secret).Build(), nil
+				},
+			}
+			ep := &v1.Endpoint{
+				Metadata: &v1.Metadata{Name: "chat-a", Workspace: "workspace-a"},
+				Spec: &v1.EndpointSpec{
+					Cluster:    "k8s-a",
+					Model:      &v1.ModelSpec{Name: "llama3", Task: v1.TextGenerationModelTask},
+					EngineAuth: tt.engineAuth,
+				},
+			}
+
+			plugin, err := k.generateAIGatewayPlugin(ep, gwService, route)
+			if tt.expectError != "" {
+				require.ErrorContains(t, err, tt.expectError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectHeader, plugin.Config["engine_auth_header"])
+			assert.Equal(t, tt.expectForward, plugin.Config["forward_engine_auth"])
+		})
+	}
+}
+
+func TestGenerateEndpointCORSPlugin(t *testing.T) {
+	route := &kong.Route{ID: pointy.String("route-1")}
+
+	tests := []struct {
+		name         string
+		cors         *v1.EndpointCORSSpec
+		expectNil    bool
+		expectError  string
+		expectConfig kong.Configuration
+	}{
+		{
+			name:      "no cors by default",
+			expectNil: true,
+		},
+		{
+			name:      "no cors without allowed origins",
+			cors:      &v1.EndpointCORSSpec{AllowedMethods: []string{"POST"}},
+			expectNil: true,
+		},
+		{
+			name: "allowed origins with default methods and headers",
+			cors: &v1.EndpointCORSSpec{AllowedOrigins: []string{"https://app.example.com"}},
+			expectConfig: kong.Configuration{
+				"origins":            []string{"https://app.example.com"},
+				"methods":            corsMethods,
+				"headers":            []string(nil),
+				"preflight_continue": false,
+				"credentials":        false,
+			},
+		},
+		{
+			name: "explicit methods and headers",
+			cors: &v1.EndpointCORSSpec{
+				AllowedOrigins: []string{"*"},
+				AllowedMethods: []string{"post", "OPTIONS"},
+				AllowedHeaders: []string{"Authorization", "Content-Type"},
+			},
+			expectConfig: kong.Configuration{
+				"origins":            []string{"*"},
+				"methods":            []string{"POST", "OPTIONS"},
+				"headers":            []string{"Authorization", "Content-Type"},
+				"preflight_continue": false,
+				"credentials":        false,
+			},
+		},
+		{
+			name:        "reject invalid method",
+			cors:        &v1.EndpointCORSSpec{AllowedOrigins: []string{"*"}, AllowedMethods: []string{"FETCH"}},
+			expectError: `invalid cors allowed method "FETCH"`,
+		},
+		{
+			name:        "reject empty origin",
+			cors:        &v1.EndpointCORSSpec{AllowedOrigins: []string{" "}},
+			expectError: "must not contain empty origins",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k := &Kong{}
+			ep := &v1.Endpoint{
+				Metadata: &v1.Metadata{Name: "chat-a", Workspace: "workspace-a"},
+				Spec:     &v1.EndpointSpec{CORS: tt.cors},
+			}
+
+			plugin, err := k.generateEndpointCORSPlugin(ep, route)
+			if tt.expectError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectError)
+
+				return
+			}
+
+			require.NoError(t, err)
+
+			if tt.expectNil {
+				assert.Nil(t, plugin)
+				return
+			}
+
+			require.NotNil(t, plugin)
+			assert.Equal(t, "cors", *plugin.Name)
+			assert.True(t, isManagedAIRoutePlugin(plugin))
+			assert.Equal(t, route, plugin.Route)
+			assert.Equal(t, tt.expectConfig, plugin.Config)
+		})
+	}
+}
+
+func TestGenerateEndpointUnavailablePlugin(t *testing.T) {
+	route := &kong.Route{ID: pointy.String("route-1")}
+
+	tests := []struct {
+		name         string
+		status       *v1.EndpointStatus
+		expectPlugin bool
+	}{
+		{
+			name: "no status yet",
+		},
+		{
+			name:   "running endpoint",
+			status: &v1.EndpointStatus{Phase: v1.EndpointPhaseRUNNING},
+		},
+		{
+			name:   "degraded endpoint keeps serving from its healthy replicas",
+			status: &v1.EndpointStatus{Phase: v1.EndpointPhaseDEGRADED, ErrorMessage: "Endpoint degraded: 1/2 pods unhealthy"},
+		},
+		{
+			name: "every replica unhealthy",
+			status: &v1.EndpointStatus{
+				Phase:        v1.EndpointPhaseFAILED,
+				ErrorMessage: v1.EndpointWorkloadFailedMessagePrefix + "Pod 'pod-a' Container 'vllm' in CrashLoopBackOff",
+			},
+			expectPlugin: true,
+		},
+		{
+			name:   "failed to sync, the deployed replicas are left as they are",
+			status: &v1.EndpointStatus{Phase: v1.EndpointPhaseFAILED, ErrorMessage: "failed to create or update endpoint chat-a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k := &Kong{}
+			ep := &v1.Endpoint{
+				Metadata: &v1.Metadata{Name: "chat-a", Workspace: "workspace-a"},
+				Spec:     &v1.EndpointSpec{},
+				Status:   tt.status,
+			}
+
+			plugin := k.generateEndpointUnavailablePlugin(ep, route)
+			if !tt.expectPlugin {
+				assert.Nil(t, plugin)
+				return
+			}
+
+			require.NotNil(t, plugin)
+			assert.Equal(t, "request-termination", *plugin.Name)
+			assert.True(t, isManagedAIRoutePlugin(plugin))
+			assert.Equal(t, route, plugin.Route)
+			assert.Equal(t, kong.Configuration{
+				"status_code": 503,
+				"message":     "endpoint chat-a has no healthy replicas",
+			}, plugin.Config)
+		})
+	}
+}
+
+func TestGetFailoverEndpoint(t *testing.T) {
+	unhealthy := &v1.EndpointStatus{
+		Phase:        v1.EndpointPhaseFAILED,
+		ErrorMessage: v1.EndpointWorkloadFailedMessagePrefix + "Pod 'pod-a' Container 'vllm' in CrashLoopBackOff",
+	}
+	standby := func(cluster string, phase v1.EndpointPhase) *v1.Endpoint {
+		return &v1.Endpoint{
+			Metadata: &v1.Metadata{Name: "chat-b", Workspace: "workspace-a"},
+			Spec:     &v1.EndpointSpec{Cluster: cluster, Model: &v1.ModelSpec{Name: "llama3"}},
+			Status:   &v1.EndpointStatus{Phase: phase},
+		}
+	}
+
+	tests := []struct {
+		name          string
+		failover      *v1.EndpointFailoverSpec
+		status        *v1.EndpointStatus
+		standby       *v1.Endpoint
+		standbyErr    error
+		expectStandby bool
+		expectError   string
+	}{
+		{
+			name:   "no failover",
+			status: unhealthy,
+		},
+		{
+			name:     "healthy endpoint serves its own requests",
+			failover: &v1.EndpointFailoverSpec{Endpoint: "chat-b"},
+			status:   &v1.EndpointStatus{Phase: v1.EndpointPhaseRUNNING},
+		},
+		{
+			name:          "unhealthy endpoint fails over",
+			failover:      &v1.EndpointFailoverSpec{Endpoint: "chat-b"},
+			status:        unhealthy,
+			standby:       standby("cluster-b", v1.EndpointPhaseRUNNING),
+			expectStandby: true,
+		},
+		{
+			name:     "standby not running",
+			failover: &v1.EndpointFailoverSpec{Endpoint: "chat-b"},
+			status:   unhealthy,
+			standby:  standby("cluster-b", v1.EndpointPhaseFAILED),
+		},
+		{
+			name:     "standby on the same cluster",
+			failover: &v1.EndpointFailoverSpec{Endpoint: "chat-b"},
+			status:   unhealthy,
+			standby:  standby("cluster-a", v1.EndpointPhaseRUNNING),
+		},
+		{
+			name:       "standby not found",
+			failover:   &v1.EndpointFailoverSpec{Endpoint: "chat-b"},
+			status:     unhealthy,
+			standbyErr: storage.ErrResourceNotFound,
+		},
+		{
+			name:        "failover to itself",
+			failover:    &v1.EndpointFailoverSpec{Endpoint: "chat-a"},
+			status:      unhealthy,
+			expectError: `failover endpoint must name another endpoint, got "chat-a"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := storagemocks.NewMockStorage(t)
+			if tt.standby != nil || tt.standbyErr != nil {
+				s.On("GetEndpointByName", "workspace-a", "chat-b").Return(tt.standby, tt.standbyErr).Once()
+			}
+
+			k := &Kong{storage: s}
+			ep := &v1.Endpoint{
+				Metadata: &v1.Metadata{Name: "chat-a", Workspace: "workspace-a"},
+				Spec:     &v1.EndpointSpec{Cluster: "cluster-a", Failover: tt.failover},
+				Status:   tt.status,
+			}
+
+			got, err := k.getFailoverEndpoint(ep)
+			if tt.expectError != "" {
+				assert.EqualError(t, err, tt.expectError)
+				return
+			}
+
+			require.NoError(t, err)
+
+			if tt.expectStandby {
+				assert.Equal(t, tt.standby, got)
+			} else {
+				assert.Nil(t, got)
+			}
+		})
+	}
+}
+
+func TestSyncEndpointService_Failover(t *testing.T) {
+	clusters := map[string]v1.Cluster{
+		"cluster-a": {
+			Metadata: &v1.Metadata{Name: "cluster-a", Workspace: "workspace-a"},
+			Spec:     &v1.ClusterSpec{Type: v1.KubernetesClusterType},
+			Status:   &v1.ClusterStatus{DashboardURL: "http://10.0.0.1:8000"},
+		},
+		"cluster-b": {
+			Metadata: &v1.Metadata{Name: "cluster-b", Workspace: "workspace-a"},
+			Spec:     &v1.ClusterSpec{Type: v1.KubernetesClusterType},
+			Status:   &v1.ClusterStatus{DashboardURL: "http://10.0.0.2:8000"},
+		},
+	}
+
+	ep := &v1.Endpoint{
+		Metadata: &v1.Metadata{Name: "chat-a", Workspace: "workspace-a"},
+		Spec:     &v1.EndpointSpec{Cluster: "cluster-a", Failover: &v1.EndpointFailoverSpec{Endpoint: "chat-b"}},
+	}
+	standby := &v1.Endpoint{
+		Metadata: &v1.Metadata{Name: "chat-b", Workspace: "workspace-a"},
+		Spec:     &v1.EndpointSpec{Cluster: "cluster-b"},
+	}
+
+	// The service is created on the first sync and updated by the next ones.
+	var current *kong.Service
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.Method {
+		case http.MethodGet:
+			if current == nil {
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = w.Write([]byte(`{"message":"Not found"}`))
+
+				return
+			}
+		case http.MethodPost, http.MethodPatch:
+			var body kong.Service
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+
+			body.ID = pointy.String("service-1")
+			current = &body
+		default:
+			t.Fatalf("unexpected Kong request: %s %s", r.Method, r.URL.Path)
+		}
+
+		require.NoError(t, json.NewEncoder(w).Encode(current))
+	}))
+	defer server.Close()
+
+	client, err := kong.NewClient(pointy.String(server.URL), server.Client())
+	require.NoError(t, err)
+
+	s := storagemocks.NewMockStorage(t)
+	s.On("GetClusterByName", mock.Anything, mock.Anything).Return(func(_, name string) (*v1.Cluster, error) {
+		cluster := clusters[name]
+
+		return &cluster, nil
+	})
+
+	k := &Kong{kongClient: client, storage: s}
+
+	_, err = k.syncEndpointService(ep, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.1", *current.Host)
+	assert.Equal(t, "/workspace-a/chat-a", *current.Path)
+
+	// Failed over, the requests are sent to the standby endpoint on its cluster.
+	_, err = k.syncEndpointService(ep, standby)
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.2", *current.Host)
+	assert.Equal(t, "/workspace-a/chat-b", *current.Path)
+
+	// Recovered, they are sent back to the endpoint.
+	_, err = k.syncEndpointService(ep, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.1", *current.Host)
+	assert.Equal(t, "/workspace-a/chat-a", *current.Path)
+}
+
+func TestSyncEndpointDomainRoute(t *testing.T) {
+	ep := &v1.Endpoint{
+		Metadata: &v1.Metadata{Name: "chat", Workspace: "workspace-a"},
+		Spec:     &v1.EndpointSpec{Domain: &v1.EndpointDomainSpec{Host: "chat.example.com"}},
+	}
+	gwService := &kong.Service{ID: pointy.String("service-1")}
+
+	// The route is created on the first sync and deleted once the domain is removed.
+	var current *kong.Route
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.Method {
+		case http.MethodGet:
+			if current == nil {
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = w.Write([]byte(`{"message":"Not found"}`))
+
+				return
+			}
+		case http.MethodPost:
+			var body kong.Route
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+
+			body.ID = pointy.String("route-1")
+			current = &body
+		case http.MethodDelete:
+			assert.Equal(t, "/routes/route-1", r.URL.Path)
+			current = nil
+			w.WriteHeader(http.StatusNoContent)
+
+			return
+		default:
+			t.Fatalf("unexpected Kong request: %s %s", r.Method, r.URL.Path)
+		}
+
+		require.NoError(t, json.NewEncoder(w).Encode(current))
+	}))
+	defer server.Close()
+
+	client, err := kong.NewClient(pointy.String(server.URL), server.Client())
+	require.NoError(t, err)
+
+	k := &Kong{kongClient: client}
+
+	route, err := k.syncEndpointDomainRoute(ep, gwService)
+	require.NoError(t, err)
+	require.NotNil(t, route)
+	assert.Equal(t, "neutree-endpoint-domain-"+util.HashString(ep.Key()), *current.Name)
+	assert.Equal(t, []*string{pointy.String("chat.example.com")}, current.Hosts)
+	assert.Equal(t, []*string{pointy.String("/")}, current.Paths)
+	assert.Equal(t, "service-1", *current.Service.ID)
+
+	ep.Spec.Domain = nil
+
+	route, err = k.syncEndpointDomainRoute(ep, gwService)
+	require.NoError(t, err)
+	assert.Nil(t, route)
+	assert.Nil(t, current)
+}
@@ -2,6 +2,7 @@ package gateway
 
 import (
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/kong/go-kong/kong"
@@ -37,6 +38,22 @@ func TestNeutreeAIPluginsRunAfterKongACL(t *testing.T) {
 	}
 }
 
+// The engine credential replaces the Authorization header in the access phase of
+// neutree-ai-gateway, so key-auth (priority 1250) must have checked the neutree API key before.
+func TestEngineAuthAppliedAfterKeyAuth(t *testing.T) {
+	data, err := os.ReadFile("../../gateway/kong/plugins/neutree-ai-gateway/handler.lua")
+	require.NoError(t, err)
+
+	handler := string(data)
+	assert.Contains(t, handler, "PRIORITY = 900")
+
+	access := handler[strings.Index(handler, "function AIGatewayHandler:access(conf)"):]
+	assert.Contains(t, access, "apply_engine_auth(conf)")
+
+	k := &Kong{}
+	assert.Equal(t, "key-auth", *k.generateKeyAuthenticationPlugin().Name)
+}
+
 func TestIsManagedAIRoutePluginRequiresInstanceName(t *testing.T) {
 	assert.False(t, isManagedAIRoutePlugin(&kong.Plugin{
 		Name: pointy.String("neutree-ai-gateway"),
@@ -52,4 +69,20 @@ func TestIsManagedAIRoutePluginRequiresInstanceName(t *testing.T) {
 		Name:         pointy.String("acl"),
 		InstanceName: pointy.String("neutree-acl-route"),
 	}))
+	assert.False(t, isManagedAIRoutePlugin(&kong.Plugin{
+		Name:         pointy.String("cors"),
+		InstanceName: pointy.String("user-cors"),
+	}))
+	assert.True(t, isManagedAIRoutePlugin(&kong.Plugin{
+		Name:         pointy.String("cors"),
+		InstanceName: pointy.String("neutree-cors-route"),
+	}))
+	assert.False(t, isManagedAIRoutePlugin(&kong.Plugin{
+		Name:         pointy.String("request-termination"),
+		InstanceName: pointy.String("user-maintenance"),
+	}))
+	assert.True(t, isManagedAIRoutePlugin(&kong.Plugin{
+		Name:         pointy.String("request-termination"),
+		InstanceName: pointy.String("neutree-unavailable-route"),
+	}))
 }
@@ -147,6 +147,12 @@ func parseApiKey(authHeader string, config AuthConfig) (*ParsedInfo, error) {
 	}, nil
 }
 
+// ParseAPIKey verifies the signature and expiry of an API key given bare or as a Bearer
+// token, as OpenAI clients send it. It does not check the key was not revoked.
+func ParseAPIKey(authHeader string, config AuthConfig) (*ParsedInfo, error) {
+	return parseApiKey(strings.TrimPrefix(authHeader, "Bearer "), config)
+}
+
 func parseSelfContainedAPIKey(apiKey string, jwtSecret string) (*SelfContainedPayload, error) {
 	if !strings.HasPrefix(apiKey, "sk_") {
 		return nil, errors.New("invalid API key format")
@@ -24,7 +24,26 @@ func (e *DeletionError) Error() string {
 
 type DeletionValidatorFunc func(workspace, name string) error
 
-func DeletionValidation(tableName string, validatorFunc DeletionValidatorFunc) gin.HandlerFunc {
+type deletionValidationOptions struct {
+	allowForceDeletion bool
+}
+
+type DeletionValidationOption func(*deletionValidationOptions)
+
+// AllowForceDeletion lets force delete requests skip the validation, the controller of the
+// resource is then responsible for handling its dependents.
+func AllowForceDeletion() DeletionValidationOption {
+	return func(o *deletionValidationOptions) {
+		o.allowForceDeletion = true
+	}
+}
+
+func DeletionValidation(tableName string, validatorFunc DeletionValidatorFunc, opts ...DeletionValidationOption) gin.HandlerFunc {
+	options := &deletionValidationOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
 	return func(c *gin.Context) {
 		if c.Request.Method != http.MethodPatch {
 			c.Next()
@@ -52,6 +71,13 @@ func DeletionValidation(tableName string, validatorFunc DeletionValidatorFunc) g
 			return
 		}
 
+		if options.allowForceDeletion && request.IsForceDeleteRequest(bodyCtx.BodyMap) {
+			klog.Infof("Force deletion for %s: workspace=%s, name=%s, skipping validation", tableName, workspace, name)
+			c.Next()
+
+			return
+		}
+
 		klog.Infof("Validating deletion for %s: workspace=%s, name=%s", tableName, workspace, name)
 
 		if err := validatorFunc(workspace, name); err != nil {
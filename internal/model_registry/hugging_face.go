@@ -34,6 +34,7 @@ var (
 
 const (
 	listModelPath              = "/api/models"
+	modelInfoPath              = "/api/models/"
 	whoamiPath                 = "/api/whoami-v2"
 	errHuggingFaceNotSupported = "operation not supported for Hugging Face registry"
 )
@@ -183,6 +184,54 @@ func (hf *huggingFace) getModelsList(options ListOption) ([]HuggingFaceModel, er
 	return models, nil
 }
 
+// pipelineTagTasks maps the pipeline tags of Hugging Face models to the task they serve, the
+// models of other pipeline tags serve no task known to the engines. text-classification is
+// left out, it is shared by rerankers and plain classifiers and tells neither apart.
+var pipelineTagTasks = map[string]string{
+	"text-generation":      v1.TextGenerationModelTask,
+	"text2text-generation": v1.TextGenerationModelTask,
+	"image-text-to-text":   v1.TextGenerationModelTask,
+	"feature-extraction":   v1.TextEmbeddingModelTask,
+	"sentence-similarity":  v1.TextEmbeddingModelTask,
+	"text-ranking":         v1.TextRerankModelTask,
+}
+
+// GetModelTask returns the task of the model from its pipeline tag, empty if the model has no
+// pipeline tag mapping to a task.
+func (hf *huggingFace) GetModelTask(name string) (string, error) {
+	req, err := http.NewRequest("GET", hf.url+modelInfoPath+name, nil)
+	if err != nil {
+		return "", err
+	}
+
+	if hf.apiToken != "" {
+		req.Header.Set("Authorization", "Bearer "+hf.apiToken)
+	}
+
+	resp, err := hf.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to get model %s: %s", name, string(body))
+	}
+
+	var model HuggingFaceModel
+
+	if err = json.Unmarshal(body, &model); err != nil {
+		return "", err
+	}
+
+	return pipelineTagTasks[model.PipelineTag], nil
+}
+
 func (hf *huggingFace) whoami() (string, error) {
 	req, err := http.NewRequest("GET", hf.url+whoamiPath, nil)
 	if err != nil {
@@ -257,3 +257,85 @@ func TestHuggingFace_HealthyCheck(t *testing.T) {
 		})
 	}
 }
+
+func TestHuggingFace_GetModelTask(t *testing.T) {
+	tests := []struct {
+		name          string
+		statusCode    int
+		body          string
+		wantTask      string
+		wantErrString string
+	}{
+		{
+			name:       "generation model",
+			statusCode: http.StatusOK,
+			body:       `{"modelId": "Qwen/Qwen3-8B", "pipeline_tag": "text-generation"}`,
+			wantTask:   v1.TextGenerationModelTask,
+		},
+		{
+			name:       "embedding model",
+			statusCode: http.StatusOK,
+			body:       `{"modelId": "Qwen/Qwen3-8B", "pipeline_tag": "feature-extraction"}`,
+			wantTask:   v1.TextEmbeddingModelTask,
+		},
+		{
+			name:       "rerank model",
+			statusCode: http.StatusOK,
+			body:       `{"modelId": "Qwen/Qwen3-8B", "pipeline_tag": "text-ranking"}`,
+			wantTask:   v1.TextRerankModelTask,
+		},
+		{
+			name:       "classification model",
+			statusCode: http.StatusOK,
+			body:       `{"modelId": "Qwen/Qwen3-8B", "pipeline_tag": "text-classification"}`,
+		},
+		{
+			name:       "unknown pipeline tag",
+			statusCode: http.StatusOK,
+			body:       `{"modelId": "Qwen/Qwen3-8B", "pipeline_tag": "automatic-speech-recognition"}`,
+		},
+		{
+			name:       "no pipeline tag",
+			statusCode: http.StatusOK,
+			body:       `{"modelId": "Qwen/Qwen3-8B"}`,
+		},
+		{
+			name:          "model not found",
+			statusCode:    http.StatusNotFound,
+			body:          `{"error": "Repository not found"}`,
+			wantErrString: "failed to get model Qwen/Qwen3-8B",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hf := &huggingFace{
+				url:      "https://huggingface.co",
+				apiToken: "test-token",
+				client: &http.Client{
+					Transport: &MockRoundTripper{
+						RoundTripFunc: func(req *http.Request) (*http.Response, error) {
+							assert.Equal(t, "/api/models/Qwen/Qwen3-8B", req.URL.Path)
+							assert.Equal(t, "Bearer test-token", req.Header.Get("Authorization"))
+
+							return &http.Response{
+								StatusCode: tt.statusCode,
+								Body:       io.NopCloser(bytes.NewBufferString(tt.body)),
+								Header:     make(http.Header),
+							}, nil
+						},
+					},
+				},
+			}
+
+			task, err := hf.GetModelTask("Qwen/Qwen3-8B")
+			if tt.wantErrString != "" {
+				assert.ErrorContains(t, err, tt.wantErrString)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantTask, task)
+		})
+	}
+}
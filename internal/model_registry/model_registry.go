@@ -33,6 +33,13 @@ type ModelRegistry interface {
 	GetNFSVersion() (string, error)
 }
 
+// ModelTaskResolver is implemented by the model registries whose model metadata tells which
+// task a model serves.
+type ModelTaskResolver interface {
+	// GetModelTask returns the task of the model, empty if its metadata does not tell.
+	GetModelTask(name string) (string, error)
+}
+
 type NewModelRegistryFunc func(registry *v1.ModelRegistry) (ModelRegistry, error)
 
 var (
@@ -627,6 +627,10 @@ func (f *fakeRayDashboardService) GetServeApplications() (*dashboard.RayServeApp
 	return f.applications, nil
 }
 
+func (f *fakeRayDashboardService) GetServeOngoingRequests() (map[string]int, error) {
+	return nil, nil
+}
+
 func (f *fakeRayDashboardService) UpdateServeApplications(_ dashboard.RayServeApplicationsRequest) error {
 	return nil
 }
@@ -635,6 +639,10 @@ func (f *fakeRayDashboardService) GetActorLog(_, _ string, _ int) (string, error
 	return "", nil
 }
 
+func (f *fakeRayDashboardService) GetPlacementGroup(_ string) (*dashboard.PlacementGroup, error) {
+	return nil, nil
+}
+
 func (f *fakeRayDashboardService) ListActors(
 	filters []dashboard.ActorFilter,
 	_ bool,
@@ -0,0 +1,165 @@
+package devicesnapshot
+
+import (
+	"context"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	v1 "github.com/neutree-ai/neutree/api/v1"
+)
+
+// NVLinkTopologyReader returns the NVLink domain of the GPUs of the node by GPU index.
+// GPUs without an NVLink connection to another GPU have no domain.
+type NVLinkTopologyReader interface {
+	NVLinkDomains(ctx context.Context) (map[string]string, error)
+}
+
+type NVLinkTopologyReaderFunc func(ctx context.Context) (map[string]string, error)
+
+func (f NVLinkTopologyReaderFunc) NVLinkDomains(ctx context.Context) (map[string]string, error) {
+	return f(ctx)
+}
+
+type NvidiaSMINVLinkTopologyReader struct {
+	Command string
+}
+
+func (r NvidiaSMINVLinkTopologyReader) NVLinkDomains(ctx context.Context) (map[string]string, error) {
+	command := r.Command
+	if command == "" {
+		command = "nvidia-smi"
+	}
+
+	out, err := exec.CommandContext(ctx, command, "topo", "-m").Output()
+	if err != nil {
+		return nil, nil
+	}
+
+	return parseNvidiaSMITopologyMatrix(string(out)), nil
+}
+
+// ApplyNVLinkDomains sets the NVLink domain of the devices of the snapshot, matched by the
+// GPU index the accelerator exporter reports as device ID.
+func ApplyNVLinkDomains(snapshot *v1.NodeDeviceSnapshot, domains map[string]string) {
+	if snapshot == nil || len(domains) == 0 {
+		return
+	}
+
+	for i := range snapshot.Accelerator.Devices {
+		if domain, ok := domains[snapshot.Accelerator.Devices[i].ID]; ok {
+			snapshot.Accelerator.Devices[i].NVLinkDomain = domain
+		}
+	}
+}
+
+var (
+	terminalEscapePattern = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+	topologyGPUPattern    = regexp.MustCompile(`^GPU(\d+)$`)
+)
+
+// parseNvidiaSMITopologyMatrix groups the GPUs of a `nvidia-smi topo -m` matrix that are
+// connected through NVLink (NV#) into domains named after their lowest GPU index.
+func parseNvidiaSMITopologyMatrix(raw string) map[string]string {
+	var columns []string
+
+	links := map[string][]string{}
+
+	for _, line := range strings.Split(terminalEscapePattern.ReplaceAllString(raw, ""), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		if columns == nil {
+			if topologyGPUPattern.MatchString(fields[0]) {
+				columns = topologyDeviceColumns(fields)
+			}
+
+			continue
+		}
+
+		match := topologyGPUPattern.FindStringSubmatch(fields[0])
+		if match == nil {
+			if !strings.HasPrefix(fields[0], "NIC") && !strings.HasPrefix(fields[0], "mlx") {
+				break
+			}
+
+			continue
+		}
+
+		gpu := match[1]
+
+		for i, link := range fields[1:] {
+			if i >= len(columns) {
+				break
+			}
+
+			peer := topologyGPUPattern.FindStringSubmatch(columns[i])
+			if peer == nil || peer[1] == gpu || !strings.HasPrefix(link, "NV") {
+				continue
+			}
+
+			links[gpu] = append(links[gpu], peer[1])
+		}
+	}
+
+	return nvlinkDomains(links)
+}
+
+// topologyDeviceColumns returns the device columns of the matrix header, which precede the
+// CPU and NUMA affinity columns.
+func topologyDeviceColumns(header []string) []string {
+	columns := make([]string, 0, len(header))
+
+	for _, field := range header {
+		if field == "CPU" || field == "NUMA" {
+			break
+		}
+
+		columns = append(columns, field)
+	}
+
+	return columns
+}
+
+func nvlinkDomains(links map[string][]string) map[string]string {
+	gpus := make([]string, 0, len(links))
+	for gpu := range links {
+		gpus = append(gpus, gpu)
+	}
+
+	sort.Slice(gpus, func(i, j int) bool {
+		left, _ := strconv.Atoi(gpus[i])  //nolint:errcheck
+		right, _ := strconv.Atoi(gpus[j]) //nolint:errcheck
+
+		return left < right
+	})
+
+	domains := map[string]string{}
+
+	for _, gpu := range gpus {
+		if _, ok := domains[gpu]; ok || len(links[gpu]) == 0 {
+			continue
+		}
+
+		domain := "nvlink-" + gpu
+		pending := []string{gpu}
+
+		for len(pending) > 0 {
+			current := pending[0]
+			pending = pending[1:]
+
+			if _, ok := domains[current]; ok {
+				continue
+			}
+
+			domains[current] = domain
+			pending = append(pending, links[current]...)
+		}
+	}
+
+	return domains
+}
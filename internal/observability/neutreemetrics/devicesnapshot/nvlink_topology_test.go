@@ -0,0 +1,74 @@
+package devicesnapshot
+
+import (
+	"testing"
+
+	v1 "github.com/neutree-ai/neutree/api/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseNvidiaSMITopologyMatrix(t *testing.T) {
+	tests := []struct {
+		name     string
+		raw      string
+		expected map[string]string
+	}{
+		{
+			name: "fully connected GPUs share one domain",
+			raw: "\t\x1b[4mGPU0\tGPU1\tGPU2\tGPU3\tNIC0\tCPU Affinity\tNUMA Affinity\tGPU NUMA ID\x1b[0m\n" +
+				"GPU0\t X \tNV12\tNV12\tNV12\tPXB\t0-63\t0\t\tN/A\n" +
+				"GPU1\tNV12\t X \tNV12\tNV12\tPXB\t0-63\t0\t\tN/A\n" +
+				"GPU2\tNV12\tNV12\t X \tNV12\tSYS\t64-127\t1\t\tN/A\n" +
+				"GPU3\tNV12\tNV12\tNV12\t X \tSYS\t64-127\t1\t\tN/A\n" +
+				"NIC0\tPXB\tPXB\tSYS\tSYS\t X \n" +
+				"\n" +
+				"Legend:\n\n  X    = Self\n  NV#  = Connection traversing a bonded set of # NVLinks\n",
+			expected: map[string]string{"0": "nvlink-0", "1": "nvlink-0", "2": "nvlink-0", "3": "nvlink-0"},
+		},
+		{
+			name: "NVLink pairs form separate domains",
+			raw: "\tGPU0\tGPU1\tGPU2\tGPU3\tCPU Affinity\tNUMA Affinity\n" +
+				"GPU0\t X \tNV4\tSYS\tSYS\t0-31\t0\n" +
+				"GPU1\tNV4\t X \tSYS\tSYS\t0-31\t0\n" +
+				"GPU2\tSYS\tSYS\t X \tNV4\t32-63\t1\n" +
+				"GPU3\tSYS\tSYS\tNV4\t X \t32-63\t1\n",
+			expected: map[string]string{"0": "nvlink-0", "1": "nvlink-0", "2": "nvlink-2", "3": "nvlink-2"},
+		},
+		{
+			name: "PCIe-only GPUs have no domain",
+			raw: "\tGPU0\tGPU1\tCPU Affinity\tNUMA Affinity\n" +
+				"GPU0\t X \tPHB\t0-31\t0\n" +
+				"GPU1\tPHB\t X \t0-31\t0\n",
+			expected: map[string]string{},
+		},
+		{
+			name:     "no matrix",
+			raw:      "NVIDIA-SMI has failed because it couldn't communicate with the NVIDIA driver.",
+			expected: map[string]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, parseNvidiaSMITopologyMatrix(tt.raw))
+		})
+	}
+}
+
+func TestApplyNVLinkDomainsMatchesDevicesByGPUIndex(t *testing.T) {
+	snapshot := &v1.NodeDeviceSnapshot{
+		Accelerator: v1.StaticNodeAcceleratorStatus{
+			Devices: []v1.StaticNodeAcceleratorDeviceStatus{
+				{ID: "0", UUID: "GPU-a"},
+				{ID: "1", UUID: "GPU-b"},
+				{ID: "2", UUID: "GPU-c"},
+			},
+		},
+	}
+
+	ApplyNVLinkDomains(snapshot, map[string]string{"0": "nvlink-0", "1": "nvlink-0"})
+
+	assert.Equal(t, "nvlink-0", snapshot.Accelerator.Devices[0].NVLinkDomain)
+	assert.Equal(t, "nvlink-0", snapshot.Accelerator.Devices[1].NVLinkDomain)
+	assert.Empty(t, snapshot.Accelerator.Devices[2].NVLinkDomain)
+}
@@ -164,6 +164,7 @@ type kubernetesDeviceAnnotation struct {
 	MinorNumber  *int   `json:"minor_number,omitempty"`
 	MemoryMiB    int64  `json:"memory_mib,omitempty"`
 	Healthy      bool   `json:"healthy,omitempty"`
+	NVLinkDomain string `json:"nvlink_domain,omitempty"`
 }
 
 type kubernetesAllocationAnnotation struct {
@@ -199,6 +200,7 @@ func kubernetesDeviceAnnotations(devices []v1.StaticNodeAcceleratorDeviceStatus)
 			MinorNumber:  minorNumber,
 			MemoryMiB:    device.MemoryMiB,
 			Healthy:      device.Healthy,
+			NVLinkDomain: device.NVLinkDomain,
 		})
 	}
 
@@ -345,6 +345,10 @@ func (f fakeRuntimeDashboard) GetServeApplications() (*dashboard.RayServeApplica
 	return f.applications, nil
 }
 
+func (f fakeRuntimeDashboard) GetServeOngoingRequests() (map[string]int, error) {
+	return nil, nil
+}
+
 func (f fakeRuntimeDashboard) UpdateServeApplications(_ dashboard.RayServeApplicationsRequest) error {
 	return nil
 }
@@ -353,6 +357,10 @@ func (f fakeRuntimeDashboard) GetActorLog(_, _ string, _ int) (string, error) {
 	return "", nil
 }
 
+func (f fakeRuntimeDashboard) GetPlacementGroup(_ string) (*dashboard.PlacementGroup, error) {
+	return nil, nil
+}
+
 func (f fakeRuntimeDashboard) ListActors(filters []dashboard.ActorFilter, _ bool, _ int) (*dashboard.ActorsResponse, error) {
 	actorID := ""
 	for _, filter := range filters {
@@ -42,6 +42,7 @@ type Config struct {
 	RuntimeUsageProvider     runtimeusage.Provider
 	EndpointGPUUsageProvider EndpointGPUUsageProvider
 	GPUHardwareProvider      hardware.GPUHardwareInfoProvider
+	NVLinkTopologyReader     devicesnapshot.NVLinkTopologyReader
 	AllocationTimeout        time.Duration
 	KubernetesWriter         *metricskubernetes.AnnotationWriter
 	AnnotationSyncInterval   time.Duration
@@ -261,10 +262,35 @@ func (s *Server) nodeDeviceSnapshot(r *http.Request) (*v1.NodeDeviceSnapshot, er
 
 	snapshot := devicesnapshot.FromAcceleratorMetrics(acceleratorExporter.Body)
 	applyGPUHardwareInfoToSnapshot(snapshot, s.gpuHardwareInfosFromScrape(ctx, acceleratorExporter))
+	s.applyNVLinkDomains(ctx, snapshot)
 
 	return s.withAllocations(ctx, snapshot)
 }
 
+// applyNVLinkDomains sets the NVLink domains of the GPUs, which topology-aware placement
+// needs to tell the GPUs connected through NVLink apart.
+func (s *Server) applyNVLinkDomains(ctx context.Context, snapshot *v1.NodeDeviceSnapshot) {
+	if len(snapshot.Accelerator.Devices) == 0 {
+		return
+	}
+
+	reader := s.config.NVLinkTopologyReader
+	if reader == nil {
+		reader = devicesnapshot.NvidiaSMINVLinkTopologyReader{}
+	}
+
+	topologyCtx, cancel := context.WithTimeout(ctx, s.allocationTimeout())
+	defer cancel()
+
+	domains, err := reader.NVLinkDomains(topologyCtx)
+	if err != nil {
+		klog.V(2).InfoS("Failed to read the NVLink topology", "error", err)
+		return
+	}
+
+	devicesnapshot.ApplyNVLinkDomains(snapshot, domains)
+}
+
 func applyGPUHardwareInfoToSnapshot(snapshot *v1.NodeDeviceSnapshot, infos []model.GPUHardwareInfo) {
 	if snapshot == nil || len(infos) == 0 {
 		return
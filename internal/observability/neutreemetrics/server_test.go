@@ -13,6 +13,7 @@ import (
 	v1 "github.com/neutree-ai/neutree/api/v1"
 	"github.com/neutree-ai/neutree/internal/accelerator/resourceparser"
 	"github.com/neutree-ai/neutree/internal/observability/neutreemetrics/allocation"
+	"github.com/neutree-ai/neutree/internal/observability/neutreemetrics/devicesnapshot"
 	"github.com/neutree-ai/neutree/internal/observability/neutreemetrics/hardware"
 	metricskubernetes "github.com/neutree-ai/neutree/internal/observability/neutreemetrics/kubernetes"
 	"github.com/neutree-ai/neutree/internal/observability/neutreemetrics/model"
@@ -612,6 +613,32 @@ func TestServerNodeDeviceSnapshotSetsMinorNumberFromHardwareInfo(t *testing.T) {
 	assert.Equal(t, 3, *snapshot.Accelerator.Devices[0].MinorNumber)
 }
 
+func TestServerNodeDeviceSnapshotSetsNVLinkDomains(t *testing.T) {
+	acceleratorExporter := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`DCGM_FI_DEV_GPU_UTIL{gpu="0",UUID="GPU-a",modelName="H100"} 10
+DCGM_FI_DEV_GPU_UTIL{gpu="1",UUID="GPU-b",modelName="H100"} 20`))
+	}))
+	t.Cleanup(acceleratorExporter.Close)
+
+	server, err := NewServer(Config{
+		ScrapeTargetProvider: testTargetProvider("", acceleratorExporter.URL),
+		HTTPClient:           acceleratorExporter.Client(),
+		GPUHardwareProvider:  emptyGPUHardwareProvider,
+		NVLinkTopologyReader: devicesnapshot.NVLinkTopologyReaderFunc(func(context.Context) (map[string]string, error) {
+			return map[string]string{"0": "nvlink-0", "1": "nvlink-0"}, nil
+		}),
+	})
+	require.NoError(t, err)
+
+	snapshot, err := server.nodeDeviceSnapshot(nil)
+	require.NoError(t, err)
+	require.Len(t, snapshot.Accelerator.Devices, 2)
+
+	for _, device := range snapshot.Accelerator.Devices {
+		assert.Equal(t, "nvlink-0", device.NVLinkDomain, device.UUID)
+	}
+}
+
 func TestServerNodeDeviceSnapshotAllowsRequests(t *testing.T) {
 	server, err := NewServer(Config{
 		DeviceSnapshotProvider: model.DeviceSnapshotProviderFunc(func(_ *http.Request) (*v1.NodeDeviceSnapshot, error) {
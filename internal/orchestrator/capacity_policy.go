@@ -0,0 +1,133 @@
+package orchestrator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	v1 "github.com/neutree-ai/neutree/api/v1"
+)
+
+// CapacityPolicy is what happens to an endpoint its deploy cluster has no capacity for, see
+// deploymentOptionCapacityPolicy.
+type CapacityPolicy string
+
+const (
+	CapacityPolicyBestEffort CapacityPolicy = "best_effort"
+	CapacityPolicyReject     CapacityPolicy = "reject"
+	CapacityPolicyQueue      CapacityPolicy = "queue"
+)
+
+// InsufficientCapacityMessage prefixes the reason an endpoint is rejected or queued for.
+const InsufficientCapacityMessage = "insufficient cluster capacity"
+
+// getCapacityPolicy parses deployment_options.capacity_policy of the endpoint, best_effort
+// by default.
+func getCapacityPolicy(endpoint *v1.Endpoint) (CapacityPolicy, error) {
+	if endpoint.Spec == nil || endpoint.Spec.DeploymentOptions == nil || endpoint.Spec.DeploymentOptions[deploymentOptionCapacityPolicy] == nil {
+		return CapacityPolicyBestEffort, nil
+	}
+
+	policy, _ := endpoint.Spec.DeploymentOptions[deploymentOptionCapacityPolicy].(string)
+
+	switch CapacityPolicy(policy) {
+	case CapacityPolicyBestEffort, CapacityPolicyReject, CapacityPolicyQueue:
+		return CapacityPolicy(policy), nil
+	default:
+		return "", errors.Errorf("deployment_options.%s must be one of %s, %s, %s", deploymentOptionCapacityPolicy,
+			CapacityPolicyBestEffort, CapacityPolicyReject, CapacityPolicyQueue)
+	}
+}
+
+// CheckEndpointCapacity returns the capacity policy of the endpoint and, unless it is
+// best_effort, why the resources the deploy cluster last reported available can not hold
+// every replica of the endpoint. The reason is empty if they can, or if the cluster did not
+// report its resources yet. Resources are compared over the whole cluster, a replica may
+// still not fit the free resources of any single node.
+func CheckEndpointCapacity(endpoint *v1.Endpoint, cluster *v1.Cluster) (CapacityPolicy, string, error) {
+	policy, err := getCapacityPolicy(endpoint)
+	if err != nil || policy == CapacityPolicyBestEffort {
+		return policy, "", err
+	}
+
+	if cluster.Status == nil || cluster.Status.ResourceInfo == nil || cluster.Status.ResourceInfo.Available == nil {
+		return policy, "", nil
+	}
+
+	expanded, err := expandResourcePreset(endpoint, cluster)
+	if err != nil {
+		return policy, "", err
+	}
+
+	if expanded.Spec.Resources == nil || IsEndpointPaused(expanded) {
+		return policy, "", nil
+	}
+
+	replicas := 1.0
+	if expanded.Spec.Replicas.Num != nil {
+		replicas = float64(*expanded.Spec.Replicas.Num)
+	}
+
+	resources := expanded.Spec.Resources
+	available := cluster.Status.ResourceInfo.Available
+
+	var shortages []string
+
+	if required := resources.GetCPUCount() * replicas; required > available.CPU {
+		shortages = append(shortages, formatCapacityShortage("CPU", required, available.CPU))
+	}
+
+	if required := resources.GetMemoryInGB() * replicas; required > available.Memory {
+		shortages = append(shortages, formatCapacityShortage("GiB memory", required, available.Memory))
+	}
+
+	if required := resources.GetGPUCount() * replicas; required > 0 {
+		acceleratorType := resources.GetAcceleratorType()
+		if acceleratorType == "" && cluster.Status.AcceleratorType != nil {
+			acceleratorType = *cluster.Status.AcceleratorType
+		}
+
+		name := acceleratorType
+		if name == "" {
+			name = "GPU"
+		}
+
+		if free := availableAccelerators(available, acceleratorType); required > free {
+			shortages = append(shortages, formatCapacityShortage(name, required, free))
+		}
+	}
+
+	if len(shortages) == 0 {
+		return policy, "", nil
+	}
+
+	return policy, fmt.Sprintf("%s: %s", InsufficientCapacityMessage, strings.Join(shortages, ", ")), nil
+}
+
+// availableAccelerators returns the available accelerators of the type, or of every type if
+// the type is unknown.
+func availableAccelerators(available *v1.ResourceInfo, acceleratorType string) float64 {
+	if acceleratorType != "" {
+		if group := available.AcceleratorGroups[v1.AcceleratorType(acceleratorType)]; group != nil {
+			return group.Quantity
+		}
+
+		return 0
+	}
+
+	var quantity float64
+	for _, group := range available.AcceleratorGroups {
+		if group != nil {
+			quantity += group.Quantity
+		}
+	}
+
+	return quantity
+}
+
+func formatCapacityShortage(resource string, required, available float64) string {
+	return fmt.Sprintf("requires %s %s, %s available", strconv.FormatFloat(required, 'f', -1, 64), resource,
+		strconv.FormatFloat(available, 'f', -1, 64))
+}
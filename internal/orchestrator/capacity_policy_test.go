@@ -0,0 +1,143 @@
+package orchestrator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/utils/pointer"
+
+	v1 "github.com/neutree-ai/neutree/api/v1"
+)
+
+func TestCheckEndpointCapacity(t *testing.T) {
+	nvidiaGPU := string(v1.AcceleratorTypeNVIDIAGPU)
+
+	newCluster := func(available *v1.ResourceInfo) *v1.Cluster {
+		cluster := &v1.Cluster{
+			Metadata: &v1.Metadata{Workspace: "default", Name: "gpu-cluster"},
+			Spec: &v1.ClusterSpec{Config: &v1.ClusterConfig{ResourcePresets: map[string]v1.ResourceSpec{
+				"large": {CPU: pointer.String("8"), GPU: pointer.String("4")},
+			}}},
+			Status: &v1.ClusterStatus{AcceleratorType: &nvidiaGPU},
+		}
+		if available != nil {
+			cluster.Status.ResourceInfo = &v1.ClusterResources{ResourceStatus: v1.ResourceStatus{Available: available}}
+		}
+
+		return cluster
+	}
+	full := &v1.ResourceInfo{
+		CPU:    4,
+		Memory: 16,
+		AcceleratorGroups: map[v1.AcceleratorType]*v1.AcceleratorGroup{
+			v1.AcceleratorTypeNVIDIAGPU: {Quantity: 1},
+		},
+	}
+	roomy := &v1.ResourceInfo{
+		CPU:    64,
+		Memory: 256,
+		AcceleratorGroups: map[v1.AcceleratorType]*v1.AcceleratorGroup{
+			v1.AcceleratorTypeNVIDIAGPU: {Quantity: 8},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		policy       interface{}
+		resources    *v1.ResourceSpec
+		replicas     int
+		available    *v1.ResourceInfo
+		expectPolicy CapacityPolicy
+		expectReason string
+		expectError  string
+	}{
+		{
+			name:         "best effort by default",
+			resources:    &v1.ResourceSpec{GPU: pointer.String("2")},
+			replicas:     1,
+			available:    full,
+			expectPolicy: CapacityPolicyBestEffort,
+		},
+		{
+			name:         "reject on a full cluster",
+			policy:       "reject",
+			resources:    &v1.ResourceSpec{CPU: pointer.String("2"), Memory: pointer.String("8"), GPU: pointer.String("1")},
+			replicas:     2,
+			available:    full,
+			expectPolicy: CapacityPolicyReject,
+			expectReason: "insufficient cluster capacity: requires 2 nvidia_gpu, 1 available",
+		},
+		{
+			name:         "queue on a full cluster",
+			policy:       "queue",
+			resources:    &v1.ResourceSpec{CPU: pointer.String("8"), Memory: pointer.String("32")},
+			replicas:     1,
+			available:    full,
+			expectPolicy: CapacityPolicyQueue,
+			expectReason: "insufficient cluster capacity: requires 8 CPU, 4 available, requires 32 GiB memory, 16 available",
+		},
+		{
+			name:         "preset resources are expanded",
+			policy:       "queue",
+			resources:    &v1.ResourceSpec{Preset: "large"},
+			replicas:     1,
+			available:    full,
+			expectPolicy: CapacityPolicyQueue,
+			expectReason: "insufficient cluster capacity: requires 8 CPU, 4 available, requires 4 nvidia_gpu, 1 available",
+		},
+		{
+			name:         "cluster with capacity",
+			policy:       "reject",
+			resources:    &v1.ResourceSpec{CPU: pointer.String("8"), GPU: pointer.String("4")},
+			replicas:     2,
+			available:    roomy,
+			expectPolicy: CapacityPolicyReject,
+		},
+		{
+			name:         "cluster without reported resources",
+			policy:       "reject",
+			resources:    &v1.ResourceSpec{GPU: pointer.String("4")},
+			replicas:     1,
+			expectPolicy: CapacityPolicyReject,
+		},
+		{
+			name:         "paused endpoint",
+			policy:       "queue",
+			resources:    &v1.ResourceSpec{GPU: pointer.String("4")},
+			available:    full,
+			expectPolicy: CapacityPolicyQueue,
+		},
+		{
+			name:        "unknown policy",
+			policy:      "wait",
+			replicas:    1,
+			expectError: "deployment_options.capacity_policy must be one of best_effort, reject, queue",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endpoint := &v1.Endpoint{
+				Metadata: &v1.Metadata{Workspace: "default", Name: "chat"},
+				Spec: &v1.EndpointSpec{
+					Resources:         tt.resources,
+					Replicas:          v1.ReplicaSpec{Num: intPtr(tt.replicas)},
+					DeploymentOptions: map[string]interface{}{},
+				},
+			}
+			if tt.policy != nil {
+				endpoint.Spec.DeploymentOptions["capacity_policy"] = tt.policy
+			}
+
+			policy, reason, err := CheckEndpointCapacity(endpoint, newCluster(tt.available))
+			if tt.expectError != "" {
+				assert.EqualError(t, err, tt.expectError)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectPolicy, policy)
+			assert.Equal(t, tt.expectReason, reason)
+		})
+	}
+}
@@ -0,0 +1,1641 @@
+package orchestrator
+
+import (
+	"encoding/json"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+
+	v1 "github.com/neutree-ai/neutree/api/v1"
+	"github.com/neutree-ai/neutree/internal/util"
+)
+
+const (
+	// deploymentOptionModelDownloader configures the model-downloader init container.
+	// max_concurrency bounds how many replicas download the model into a shared model
+	// cache at once, the other replicas wait for the cache to be populated. With lease the
+	// replicas of a kubernetes endpoint take their download slot from coordination.k8s.io
+	// Leases instead of lock files in the model cache, which bounds the downloads across
+	// replicas and restarts even without a shared model cache.
+	// credentials_secret names a Secret of the cluster namespace whose token key holds the
+	// model registry credential, kept fresh by whatever issues short-lived credentials
+	// (signed URLs, STS tokens). A download outliving its credential re-reads the Secret
+	// and resumes, up to auth_refresh_retries times (3 by default). A download rate limited
+	// by the model registry (HTTP 429) waits as long as the registry asks in Retry-After, or
+	// backs off exponentially, up to rate_limit_retries times (5 by default). Example:
+	//
+	//	model_downloader:
+	//	  image_pull_policy: IfNotPresent
+	//	  retries: 5
+	//	  retry_backoff_seconds: 2
+	//	  max_concurrency: 2
+	//	  lease: true
+	//	  credentials_secret: registry-credentials
+	//	  auth_refresh_retries: 5
+	//	  rate_limit_retries: 10
+	deploymentOptionModelDownloader = "model_downloader"
+
+	// deploymentOptionRouter configures router-level retry, timeout and circuit breaking of
+	// Ray serve endpoints. Non-streaming requests are retried on another replica after a
+	// transient replica failure, and a replica failing failure_threshold times within
+	// open_seconds is skipped for open_seconds. request_timeout_seconds bounds a
+	// non-streaming request, requests time out with 504. Endpoints are created with the
+	// default of their model task, see v1.DefaultRequestTimeoutSeconds; endpoints created
+	// before it, or removing it, are not bounded. Streaming requests are never retried nor
+	// bounded.
+	// With deduplication, a non-streaming generation request carrying an Idempotency-Key
+	// header runs once per API key: its duplicates within ttl_seconds get the same result,
+	// kept for the latest max_entries keys (1000 by default) of each router replica. A
+	// request reusing the key with another body is rejected with 422.
+	// With image_fetch, the http(s) image URLs of chat requests are fetched by the router, up
+	// to max_bytes (10 MiB by default) within timeout_seconds (10 by default), and sent to the
+	// engine as data URLs. URLs resolving to internal addresses are rejected.
+	// Example:
+	//
+	//	router:
+	//	  retries: 2
+	//	  request_timeout_seconds: 600
+	//	  circuit_breaker:
+	//	    failure_threshold: 3
+	//	    open_seconds: 30
+	//	  deduplication:
+	//	    ttl_seconds: 300
+	//	    max_entries: 1000
+	//	  image_fetch:
+	//	    max_bytes: 10485760
+	//	    timeout_seconds: 10
+	deploymentOptionRouter = "router"
+
+	defaultRouterCircuitOpenSeconds = 30
+
+	defaultRouterDeduplicationMaxEntries = 1000
+
+	defaultRouterImageFetchMaxBytes       = 10 << 20
+	defaultRouterImageFetchTimeoutSeconds = 10
+
+	// deploymentOptionCompression enables gzip at the router of Ray serve endpoints: request
+	// bodies sent with Content-Encoding: gzip are decompressed, and responses of at least
+	// min_size_bytes are compressed for clients sending Accept-Encoding: gzip. Other requests
+	// pass through unchanged. Example:
+	//
+	//	compression:
+	//	  request: true
+	//	  response: true
+	//	  min_size_bytes: 1024
+	deploymentOptionCompression = "compression"
+
+	// deploymentOptionTracing propagates W3C trace context through the router of Ray serve
+	// endpoints and exports a span of the routing hop to an OTLP/HTTP collector. Requests
+	// carrying a traceparent header keep its trace and sampling decision, the others are
+	// sampled with sample_ratio, 1 by default. The trace context is passed on to the engine
+	// replica serving the request. Example:
+	//
+	//	tracing:
+	//	  otlp_endpoint: http://otel-collector.observability:4318
+	//	  sample_ratio: 0.1
+	deploymentOptionTracing = "tracing"
+
+	// deploymentOptionAccessLog writes a structured access log entry per request at the
+	// router of Ray serve endpoints: timestamp, API key ID, model, status, latency and token
+	// usage, among others. sink is stdout, a JSON line per request in the router logs, or
+	// object_store, newline-delimited JSON objects uploaded with HTTP PUT under url. fields
+	// selects the logged fields, all by default, and sample_ratio the share of the requests
+	// logged, 1 by default. Example:
+	//
+	//	access_log:
+	//	  sink: object_store
+	//	  url: https://logs.example.com/neutree
+	//	  fields: [timestamp, api_key, model, status, latency_ms, total_tokens]
+	//	  sample_ratio: 1
+	deploymentOptionAccessLog = "access_log"
+
+	// deploymentOptionTopologyAwarePlacement prefers placing the GPUs of a multi-GPU
+	// (e.g. tensor-parallel) replica on NVLink-connected devices of one node, on clusters
+	// reporting GPU topology. Example:
+	//
+	//	topology_aware_placement: true
+	deploymentOptionTopologyAwarePlacement = "topology_aware_placement"
+
+	// deploymentOptionStartupTimeoutSeconds overrides how long an engine replica may take to
+	// become ready before it is restarted, instead of the estimate from the model size. Example:
+	//
+	//	startup_timeout_seconds: 3600
+	deploymentOptionStartupTimeoutSeconds = "startup_timeout_seconds"
+
+	// deploymentOptionServiceMesh controls whether the endpoint pods join the service mesh
+	// configured on the kubernetes cluster, they do by default. Example:
+	//
+	//	service_mesh: false
+	deploymentOptionServiceMesh = "service_mesh"
+
+	// deploymentOptionHealthCheckPeriodSeconds and deploymentOptionHealthCheckTimeoutSeconds
+	// tune how often Ray serve health checks the engine replicas of an endpoint and how long
+	// a check may take before the replica is considered unhealthy. Example:
+	//
+	//	health_check_period_s: 5
+	//	health_check_timeout_s: 60
+	deploymentOptionHealthCheckPeriodSeconds  = "health_check_period_s"
+	deploymentOptionHealthCheckTimeoutSeconds = "health_check_timeout_s"
+
+	// deploymentOptionPhaseTimeouts bounds how long a replica of a kubernetes endpoint may wait
+	// on each deployment stage, the endpoint fails naming the stage which timed out. Stages
+	// without a timeout may take as long as they need. Example:
+	//
+	//	phase_timeouts:
+	//	  scheduling_seconds: 600
+	//	  image_pull_seconds: 1800
+	//	  model_download_seconds: 7200
+	//	  model_load_seconds: 1800
+	deploymentOptionPhaseTimeouts = "phase_timeouts"
+
+	// deploymentOptionPlacementGroup gang schedules the replicas of a Ray serve endpoint,
+	// e.g. with tensor parallelism. name schedules the replicas into a placement group created
+	// ahead of the endpoint, bundles reserve a new placement group for each replica instead,
+	// the first bundle holding the replica itself. Example:
+	//
+	//	placement_group:
+	//	  name: tp-gang
+	//
+	//	placement_group:
+	//	  bundles:
+	//	    - CPU: 1
+	//	      GPU: 4
+	//	  strategy: STRICT_PACK
+	deploymentOptionPlacementGroup = "placement_group"
+
+	// deploymentOptionCapacityPolicy decides what happens to an endpoint not deployed yet when
+	// the resources its deploy cluster last reported available can not hold every replica:
+	// best_effort deploys it anyway and lets the replicas pend, reject fails the endpoint and
+	// queue keeps it pending until the cluster has the capacity. Example:
+	//
+	//	capacity_policy: queue
+	deploymentOptionCapacityPolicy = "capacity_policy"
+
+	// deploymentOptionSharedWeights loads the model weights of a kubernetes endpoint into the
+	// shared memory of the node, so the replicas co-located on a node map the same weights
+	// instead of each holding a copy. Only engines loading weights with mmap support it, which
+	// is llama-cpp. The shared memory is a hostPath volume, so the namespace of the cluster
+	// must allow the privileged pod security level, and it is kept on the node when the
+	// endpoint is deleted. Example:
+	//
+	//	shared_weights: true
+	deploymentOptionSharedWeights = "shared_weights"
+
+	// deploymentOptionMixedPlacement splits the replicas of a kubernetes endpoint into two
+	// replica groups: on_demand_replicas replicas placed on on-demand nodes for reliability,
+	// and the remainder on cheaper spot nodes. Each group adds its node selector and
+	// tolerations to the ones of the endpoint. Example:
+	//
+	//	mixed_placement:
+	//	  on_demand_replicas: 2
+	//	  on_demand:
+	//	    node_selector:
+	//	      node-pool: on-demand
+	//	  spot:
+	//	    node_selector:
+	//	      node-pool: spot
+	//	    tolerations:
+	//	      - key: spot
+	//	        operator: Exists
+	//	        effect: NoSchedule
+	deploymentOptionMixedPlacement = "mixed_placement"
+
+	// deploymentOptionTolerations adds kubernetes tolerations to the replicas of an endpoint,
+	// e.g. to schedule them on a node pool tainted for a team. They are added to the
+	// accelerator and cluster tolerations. Example:
+	//
+	//	tolerations:
+	//	  - key: team
+	//	    operator: Equal
+	//	    value: search
+	//	    effect: NoSchedule
+	deploymentOptionTolerations = "tolerations"
+
+	// deploymentOptionSecurityContext hardens the containers of a kubernetes endpoint.
+	// read_only_root_filesystem mounts their root filesystem read-only, the paths the
+	// engines write to (tmp, home and logs) get writable emptyDir volumes, next to the model
+	// cache and shared memory volumes. Example:
+	//
+	//	security_context:
+	//	  read_only_root_filesystem: true
+	deploymentOptionSecurityContext = "security_context"
+
+	// deploymentOptionFailureCleanup cleans up the workload of an endpoint whose deployment
+	// failed, e.g. a replica timed out on a deployment stage, instead of leaving its pods
+	// pending or crashing: scale_to_zero scales it to zero replicas and teardown deletes it.
+	// Replicas get the startup window (see startup_timeout_seconds) to recover before their
+	// workload is cleaned up. The endpoint then stays failed with the reason and is
+	// redeployed once its spec changes.
+	// Example:
+	//
+	//	failure_cleanup: scale_to_zero
+	deploymentOptionFailureCleanup = "failure_cleanup"
+
+	// deploymentOptionPeerDiscovery gives every replica of a kubernetes endpoint a stable DNS
+	// name, so the replicas of disaggregated engine topologies (e.g. prefill and decode
+	// workers) can address each other. The replicas run in a StatefulSet behind a headless
+	// service, they find their peers by resolving the SRV record named in NEUTREE_PEER_SRV.
+	// Example:
+	//
+	//	peer_discovery: true
+	deploymentOptionPeerDiscovery = "peer_discovery"
+
+	// deploymentOptionModelTaskValidation decides what happens to an endpoint not deployed yet
+	// when the task declared for a model it serves differs from the task the metadata of the
+	// model registry tells: warn deploys it anyway and logs the mismatch, reject fails the
+	// endpoint and skip does not check. Models whose registry has no such metadata are not
+	// checked. Example:
+	//
+	//	model_task_validation: reject
+	deploymentOptionModelTaskValidation = "model_task_validation"
+
+	// deploymentOptionImageCanary runs a new engine image of a kubernetes endpoint in a single
+	// canary replica before rolling it out. The replicas keep the previous image until the
+	// canary is ready. A canary failing to pull or start its image, or not ready within the
+	// startup window plus 30 minutes to schedule it and pull the image, aborts the rollout
+	// until the image changes. The canary needs room for one more replica in the cluster.
+	// Example:
+	//
+	//	image_canary: true
+	deploymentOptionImageCanary = "image_canary"
+
+	// deploymentOptionLifecycle runs commands in the engine container of a kubernetes endpoint
+	// right after it starts (post_start) and before it is stopped (pre_stop), e.g. to drain the
+	// in-flight requests of a replica. Each hook is an exec command, not run in a shell.
+	// Example:
+	//
+	//	lifecycle:
+	//	  pre_stop: ["sh", "-c", "sleep 15"]
+	deploymentOptionLifecycle = "lifecycle"
+
+	// deploymentOptionTerminationGracePeriod is how long a replica of a kubernetes endpoint is
+	// given to finish its requests in flight once it is sent SIGTERM, before it is killed.
+	// The pre_stop hook of deployment_options.lifecycle runs within it. It defaults to the
+	// request timeout of the model task plus terminationGracePeriodMarginSeconds, see
+	// v1.DefaultRequestTimeoutSeconds. The default only applies to endpoints deployed or changed
+	// since, a deployed endpoint whose spec is unchanged keeps its grace period so that its
+	// replicas are not rolled. Example:
+	//
+	//	termination_grace_period_seconds: 900
+	deploymentOptionTerminationGracePeriod = "termination_grace_period_seconds"
+
+	// terminationGracePeriodMarginSeconds leaves the engine time to exit once its last request
+	// finished.
+	terminationGracePeriodMarginSeconds = 30
+
+	// deploymentOptionImageAcceleratorValidation checks, before deploying a kubernetes endpoint,
+	// that the accelerator its engine image is built for is one the deploy cluster reports,
+	// so e.g. a CUDA image is not deployed to pods that can never start on an Ascend-only
+	// cluster. It is on by default, false skips the check. Example:
+	//
+	//	image_accelerator_validation: false
+	deploymentOptionImageAcceleratorValidation = "image_accelerator_validation"
+
+	// deploymentOptionGPUDevicePlugin selects the NVIDIA device plugin mode the GPUs of a
+	// kubernetes endpoint are requested from, overriding gpu_device_plugin of the cluster:
+	// default requests nvidia.com/gpu, time-slicing and mps request nvidia.com/gpu.shared.
+	// Example:
+	//
+	//	gpu_device_plugin: time-slicing
+	deploymentOptionGPUDevicePlugin = "gpu_device_plugin"
+
+	// deploymentOptionModelConversion converts the downloaded model of a kubernetes endpoint
+	// before the engine starts, e.g. a Hugging Face model to GGUF for llama-cpp. The command
+	// runs in an init container after the model-downloader, with the model cache mounted and
+	// NEUTREE_MODEL_PATH set to the downloaded model, and writes the converted artifact to the
+	// model cache, e.g. a file of the model directory that model.file selects. It runs on
+	// every replica start, so it should skip a conversion already done. The replicas convert
+	// one at a time holding a flock of the model directory, the image needs sh and flock for
+	// it. A crashed conversion leaves its output behind, so it should write to a temporary
+	// file renamed into place once complete. The init container uses the engine image and
+	// resources unless image is set. Example:
+	//
+	//	model_conversion:
+	//	  image: ghcr.io/ggml-org/llama.cpp:full
+	//	  command: ["sh", "-c", "test -f $NEUTREE_MODEL_PATH/model.gguf || (python3 /app/convert_hf_to_gguf.py $NEUTREE_MODEL_PATH --outfile $NEUTREE_MODEL_PATH/model.gguf.tmp && mv $NEUTREE_MODEL_PATH/model.gguf.tmp $NEUTREE_MODEL_PATH/model.gguf)"]
+	deploymentOptionModelConversion = "model_conversion"
+
+	// deploymentOptionAuthSidecar injects an authenticating reverse proxy in the pods of a
+	// kubernetes endpoint, for clusters where neutree does not front the endpoint traffic.
+	// The sidecar takes over the serving port the router and the Service send requests to
+	// and forwards the valid requests to the engine, moved to another port of the pod
+	// loopback address so the other pods can only reach it through the sidecar. The probes
+	// and the metrics scraper reach the engine through the sidecar too.
+	//
+	// validate_url is the URL the API keys are validated with, the /api/v1/auth/validate
+	// route of the neutree API validates the API keys neutree issued. The sidecar runs
+	// neutree.authproxy of the neutree runtime image unless image is set. Other images are
+	// run with NEUTREE_AUTH_LISTEN_PORT, the port to serve on, NEUTREE_AUTH_UPSTREAM, the
+	// URL of the engine, NEUTREE_AUTH_VALIDATE_URL set to validate_url and
+	// NEUTREE_AUTH_PUBLIC_PATHS, the comma-separated health check and metrics paths. For each
+	// request to another path they are expected to send a GET request to validate_url with
+	// the Authorization header of the request, forward the request when validate_url answers
+	// with a 2xx status, and reject it with 401 otherwise. Example:
+	//
+	//	auth_sidecar:
+	//	  validate_url: https://neutree.example.com/api/v1/auth/validate
+	deploymentOptionAuthSidecar = "auth_sidecar"
+
+	// deploymentOptionModelCache selects by name the model cache of the cluster the model of
+	// the endpoint is downloaded to and loaded from, for clusters with several model caches,
+	// e.g. the NFS shares of different teams. If not specified, the first model cache of the
+	// cluster is used. Example:
+	//
+	//	model_cache: team-a
+	deploymentOptionModelCache = "model_cache"
+
+	modelDownloaderRetriesEnv        = "NEUTREE_DL_RETRIES"
+	modelDownloaderRetryBackoffEnv   = "NEUTREE_DL_RETRY_BACKOFF"
+	modelDownloaderMaxConcurrencyEnv = "NEUTREE_DL_MAX_CONCURRENCY"
+	modelDownloaderTokenFileEnv      = "NEUTREE_DL_TOKEN_FILE"
+	modelDownloaderAuthRefreshEnv    = "NEUTREE_DL_AUTH_REFRESH_RETRIES"
+	modelDownloaderRateLimitEnv      = "NEUTREE_DL_RATE_LIMIT_RETRIES"
+
+	compressionRequestEnv  = "NEUTREE_COMPRESSION_REQUEST"
+	compressionResponseEnv = "NEUTREE_COMPRESSION_RESPONSE"
+	compressionMinSizeEnv  = "NEUTREE_COMPRESSION_MIN_SIZE"
+
+	tracingOTLPEndpointEnv = "NEUTREE_TRACING_OTLP_ENDPOINT"
+	tracingSampleRatioEnv  = "NEUTREE_TRACING_SAMPLE_RATIO"
+
+	accessLogSinkEnv        = "NEUTREE_ACCESS_LOG_SINK"
+	accessLogURLEnv         = "NEUTREE_ACCESS_LOG_URL"
+	accessLogFieldsEnv      = "NEUTREE_ACCESS_LOG_FIELDS"
+	accessLogSampleRatioEnv = "NEUTREE_ACCESS_LOG_SAMPLE_RATIO"
+
+	modelConversionModelPathEnv = "NEUTREE_MODEL_PATH"
+
+	authSidecarListenPortEnv  = "NEUTREE_AUTH_LISTEN_PORT"
+	authSidecarUpstreamEnv    = "NEUTREE_AUTH_UPSTREAM"
+	authSidecarValidateURLEnv = "NEUTREE_AUTH_VALIDATE_URL"
+	authSidecarPublicPathsEnv = "NEUTREE_AUTH_PUBLIC_PATHS"
+)
+
+// modelDownloaderOptions holds the model-downloader settings parsed from endpoint deployment options.
+type modelDownloaderOptions struct {
+	ImagePullPolicy     corev1.PullPolicy
+	Retries             *int
+	RetryBackoffSeconds *float64
+	MaxConcurrency      *int
+	Lease               bool
+	CredentialsSecret   string
+	AuthRefreshRetries  *int
+	RateLimitRetries    *int
+}
+
+// Env returns the downloader environment variables derived from the options.
+// Only explicitly configured values are returned so the downloader defaults apply otherwise.
+func (o *modelDownloaderOptions) Env() map[string]string {
+	env := map[string]string{}
+
+	if o.Retries != nil {
+		env[modelDownloaderRetriesEnv] = strconv.Itoa(*o.Retries)
+	}
+
+	if o.RetryBackoffSeconds != nil {
+		env[modelDownloaderRetryBackoffEnv] = strconv.FormatFloat(*o.RetryBackoffSeconds, 'f', -1, 64)
+	}
+
+	if o.MaxConcurrency != nil {
+		env[modelDownloaderMaxConcurrencyEnv] = strconv.Itoa(*o.MaxConcurrency)
+	}
+
+	if o.AuthRefreshRetries != nil {
+		env[modelDownloaderAuthRefreshEnv] = strconv.Itoa(*o.AuthRefreshRetries)
+	}
+
+	if o.RateLimitRetries != nil {
+		env[modelDownloaderRateLimitEnv] = strconv.Itoa(*o.RateLimitRetries)
+	}
+
+	return env
+}
+
+// getModelDownloaderOptions parses deployment_options.model_downloader of the endpoint.
+func getModelDownloaderOptions(endpoint *v1.Endpoint) (*modelDownloaderOptions, error) {
+	opts := &modelDownloaderOptions{}
+
+	if endpoint.Spec == nil || endpoint.Spec.DeploymentOptions == nil || endpoint.Spec.DeploymentOptions[deploymentOptionModelDownloader] == nil {
+		return opts, nil
+	}
+
+	raw, ok := endpoint.Spec.DeploymentOptions[deploymentOptionModelDownloader].(map[string]interface{})
+	if !ok {
+		return nil, errors.Errorf("deployment_options.%s must be an object", deploymentOptionModelDownloader)
+	}
+
+	if v, exists := raw["image_pull_policy"]; exists && v != nil {
+		policy, ok := v.(string)
+		if !ok {
+			return nil, errors.Errorf("deployment_options.%s.image_pull_policy must be a string", deploymentOptionModelDownloader)
+		}
+
+		switch corev1.PullPolicy(policy) {
+		case corev1.PullAlways, corev1.PullIfNotPresent, corev1.PullNever:
+			opts.ImagePullPolicy = corev1.PullPolicy(policy)
+		case "":
+		default:
+			return nil, errors.Errorf("invalid deployment_options.%s.image_pull_policy %q, must be one of Always, IfNotPresent, Never",
+				deploymentOptionModelDownloader, policy)
+		}
+	}
+
+	if v, exists := raw["retries"]; exists && v != nil {
+		retries, err := toFloat64(v)
+		if err != nil || retries < 0 || retries != float64(int(retries)) {
+			return nil, errors.Errorf("deployment_options.%s.retries must be a non-negative integer", deploymentOptionModelDownloader)
+		}
+
+		r := int(retries)
+		opts.Retries = &r
+	}
+
+	if v, exists := raw["retry_backoff_seconds"]; exists && v != nil {
+		backoff, err := toFloat64(v)
+		if err != nil || backoff < 0 {
+			return nil, errors.Errorf("deployment_options.%s.retry_backoff_seconds must be a non-negative number", deploymentOptionModelDownloader)
+		}
+
+		opts.RetryBackoffSeconds = &backoff
+	}
+
+	if v, exists := raw["max_concurrency"]; exists && v != nil {
+		concurrency, err := toFloat64(v)
+		if err != nil || concurrency < 1 || concurrency != float64(int(concurrency)) {
+			return nil, errors.Errorf("deployment_options.%s.max_concurrency must be a positive integer", deploymentOptionModelDownloader)
+		}
+
+		c := int(concurrency)
+		opts.MaxConcurrency = &c
+	}
+
+	if v, exists := raw["lease"]; exists && v != nil {
+		lease, ok := v.(bool)
+		if !ok {
+			return nil, errors.Errorf("deployment_options.%s.lease must be a boolean", deploymentOptionModelDownloader)
+		}
+
+		if lease && opts.MaxConcurrency == nil {
+			return nil, errors.Errorf("deployment_options.%s.lease requires max_concurrency", deploymentOptionModelDownloader)
+		}
+
+		opts.Lease = lease
+	}
+
+	if v, exists := raw["credentials_secret"]; exists && v != nil {
+		secret, ok := v.(string)
+		if !ok || secret == "" {
+			return nil, errors.Errorf("deployment_options.%s.credentials_secret must be a non-empty string", deploymentOptionModelDownloader)
+		}
+
+		opts.CredentialsSecret = secret
+	}
+
+	if v, exists := raw["auth_refresh_retries"]; exists && v != nil {
+		retries, err := toFloat64(v)
+		if err != nil || retries < 0 || retries != float64(int(retries)) {
+			return nil, errors.Errorf("deployment_options.%s.auth_refresh_retries must be a non-negative integer", deploymentOptionModelDownloader)
+		}
+
+		if opts.CredentialsSecret == "" {
+			return nil, errors.Errorf("deployment_options.%s.auth_refresh_retries requires credentials_secret", deploymentOptionModelDownloader)
+		}
+
+		r := int(retries)
+		opts.AuthRefreshRetries = &r
+	}
+
+	if v, exists := raw["rate_limit_retries"]; exists && v != nil {
+		retries, err := toFloat64(v)
+		if err != nil || retries < 0 || retries != float64(int(retries)) {
+			return nil, errors.Errorf("deployment_options.%s.rate_limit_retries must be a non-negative integer", deploymentOptionModelDownloader)
+		}
+
+		r := int(retries)
+		opts.RateLimitRetries = &r
+	}
+
+	return opts, nil
+}
+
+// routerOptions holds the router retry settings parsed from endpoint deployment options.
+type routerOptions struct {
+	Retries int
+	// RequestTimeoutSeconds is 0 when requests are not bounded.
+	RequestTimeoutSeconds   float64
+	CircuitFailureThreshold int
+	CircuitOpenSeconds      float64
+	// DeduplicationTTLSeconds is 0 when requests are not deduplicated.
+	DeduplicationTTLSeconds float64
+	DeduplicationMaxEntries int
+	// ImageFetchMaxBytes is 0 when image URLs are passed to the engine as they are.
+	ImageFetchMaxBytes       int
+	ImageFetchTimeoutSeconds float64
+}
+
+// Args returns the normalized options passed to the serve application.
+func (o *routerOptions) Args() map[string]interface{} {
+	args := map[string]interface{}{
+		"retries": o.Retries,
+		"circuit_breaker": map[string]interface{}{
+			"failure_threshold": o.CircuitFailureThreshold,
+			"open_seconds":      o.CircuitOpenSeconds,
+		},
+	}
+
+	if o.RequestTimeoutSeconds > 0 {
+		args["request_timeout_seconds"] = o.RequestTimeoutSeconds
+	}
+
+	if o.DeduplicationTTLSeconds > 0 {
+		args["deduplication"] = map[string]interface{}{
+			"ttl_seconds": o.DeduplicationTTLSeconds,
+			"max_entries": o.DeduplicationMaxEntries,
+		}
+	}
+
+	if o.ImageFetchMaxBytes > 0 {
+		args["image_fetch"] = map[string]interface{}{
+			"max_bytes":       o.ImageFetchMaxBytes,
+			"timeout_seconds": o.ImageFetchTimeoutSeconds,
+		}
+	}
+
+	return args
+}
+
+// getRouterOptions parses deployment_options.router of the endpoint.
+// It returns nil if the endpoint does not configure the router.
+func getRouterOptions(endpoint *v1.Endpoint) (*routerOptions, error) {
+	if endpoint.Spec == nil || endpoint.Spec.DeploymentOptions == nil || endpoint.Spec.DeploymentOptions[deploymentOptionRouter] == nil {
+		return nil, nil
+	}
+
+	raw, ok := endpoint.Spec.DeploymentOptions[deploymentOptionRouter].(map[string]interface{})
+	if !ok {
+		return nil, errors.Errorf("deployment_options.%s must be an object", deploymentOptionRouter)
+	}
+
+	opts := &routerOptions{
+		CircuitOpenSeconds:      defaultRouterCircuitOpenSeconds,
+		DeduplicationMaxEntries: defaultRouterDeduplicationMaxEntries,
+	}
+
+	if v, exists := raw["retries"]; exists && v != nil {
+		retries, err := toFloat64(v)
+		if err != nil || retries < 0 || retries != float64(int(retries)) {
+			return nil, errors.Errorf("deployment_options.%s.retries must be a non-negative integer", deploymentOptionRouter)
+		}
+
+		opts.Retries = int(retries)
+	}
+
+	if v, exists := raw["request_timeout_seconds"]; exists && v != nil {
+		timeout, err := toFloat64(v)
+		if err != nil || timeout <= 0 {
+			return nil, errors.Errorf("deployment_options.%s.request_timeout_seconds must be a positive number", deploymentOptionRouter)
+		}
+
+		opts.RequestTimeoutSeconds = timeout
+	}
+
+	if err := parseRouterDeduplication(raw["deduplication"], opts); err != nil {
+		return nil, err
+	}
+
+	if err := parseRouterImageFetch(raw["image_fetch"], opts); err != nil {
+		return nil, err
+	}
+
+	if raw["circuit_breaker"] == nil {
+		return opts, nil
+	}
+
+	circuitBreaker, ok := raw["circuit_breaker"].(map[string]interface{})
+	if !ok {
+		return nil, errors.Errorf("deployment_options.%s.circuit_breaker must be an object", deploymentOptionRouter)
+	}
+
+	if v, exists := circuitBreaker["failure_threshold"]; exists && v != nil {
+		threshold, err := toFloat64(v)
+		if err != nil || threshold < 0 || threshold != float64(int(threshold)) {
+			return nil, errors.Errorf("deployment_options.%s.circuit_breaker.failure_threshold must be a non-negative integer", deploymentOptionRouter)
+		}
+
+		opts.CircuitFailureThreshold = int(threshold)
+	}
+
+	if v, exists := circuitBreaker["open_seconds"]; exists && v != nil {
+		openSeconds, err := toFloat64(v)
+		if err != nil || openSeconds <= 0 {
+			return nil, errors.Errorf("deployment_options.%s.circuit_breaker.open_seconds must be a positive number", deploymentOptionRouter)
+		}
+
+		opts.CircuitOpenSeconds = openSeconds
+	}
+
+	return opts, nil
+}
+
+// parseRouterDeduplication parses deployment_options.router.deduplication into opts.
+func parseRouterDeduplication(v interface{}, opts *routerOptions) error {
+	if v == nil {
+		return nil
+	}
+
+	deduplication, ok := v.(map[string]interface{})
+	if !ok {
+		return errors.Errorf("deployment_options.%s.deduplication must be an object", deploymentOptionRouter)
+	}
+
+	ttl, err := toFloat64(deduplication["ttl_seconds"])
+	if deduplication["ttl_seconds"] == nil || err != nil || ttl <= 0 {
+		return errors.Errorf("deployment_options.%s.deduplication.ttl_seconds must be a positive number", deploymentOptionRouter)
+	}
+
+	opts.DeduplicationTTLSeconds = ttl
+
+	if v, exists := deduplication["max_entries"]; exists && v != nil {
+		maxEntries, err := toFloat64(v)
+		if err != nil || maxEntries < 1 || maxEntries != float64(int(maxEntries)) {
+			return errors.Errorf("deployment_options.%s.deduplication.max_entries must be a positive integer", deploymentOptionRouter)
+		}
+
+		opts.DeduplicationMaxEntries = int(maxEntries)
+	}
+
+	return nil
+}
+
+// parseRouterImageFetch parses deployment_options.router.image_fetch into opts.
+func parseRouterImageFetch(v interface{}, opts *routerOptions) error {
+	if v == nil {
+		return nil
+	}
+
+	imageFetch, ok := v.(map[string]interface{})
+	if !ok {
+		return errors.Errorf("deployment_options.%s.image_fetch must be an object", deploymentOptionRouter)
+	}
+
+	opts.ImageFetchMaxBytes = defaultRouterImageFetchMaxBytes
+	opts.ImageFetchTimeoutSeconds = defaultRouterImageFetchTimeoutSeconds
+
+	if v, exists := imageFetch["max_bytes"]; exists && v != nil {
+		maxBytes, err := toFloat64(v)
+		if err != nil || maxBytes < 1 || maxBytes != float64(int(maxBytes)) {
+			return errors.Errorf("deployment_options.%s.image_fetch.max_bytes must be a positive integer", deploymentOptionRouter)
+		}
+
+		opts.ImageFetchMaxBytes = int(maxBytes)
+	}
+
+	if v, exists := imageFetch["timeout_seconds"]; exists && v != nil {
+		timeout, err := toFloat64(v)
+		if err != nil || timeout <= 0 {
+			return errors.Errorf("deployment_options.%s.image_fetch.timeout_seconds must be a positive number", deploymentOptionRouter)
+		}
+
+		opts.ImageFetchTimeoutSeconds = timeout
+	}
+
+	return nil
+}
+
+// compressionOptions holds the router gzip settings parsed from endpoint deployment options.
+type compressionOptions struct {
+	Request      bool
+	Response     bool
+	MinSizeBytes *int
+}
+
+// Env returns the environment variables configuring compression of the serve application.
+func (o *compressionOptions) Env() map[string]string {
+	env := map[string]string{
+		compressionRequestEnv:  strconv.FormatBool(o.Request),
+		compressionResponseEnv: strconv.FormatBool(o.Response),
+	}
+
+	if o.MinSizeBytes != nil {
+		env[compressionMinSizeEnv] = strconv.Itoa(*o.MinSizeBytes)
+	}
+
+	return env
+}
+
+// getCompressionOptions parses deployment_options.compression of the endpoint.
+// It returns nil if the endpoint does not configure compression.
+func getCompressionOptions(endpoint *v1.Endpoint) (*compressionOptions, error) {
+	if endpoint.Spec == nil || endpoint.Spec.DeploymentOptions == nil || endpoint.Spec.DeploymentOptions[deploymentOptionCompression] == nil {
+		return nil, nil
+	}
+
+	raw, ok := endpoint.Spec.DeploymentOptions[deploymentOptionCompression].(map[string]interface{})
+	if !ok {
+		return nil, errors.Errorf("deployment_options.%s must be an object", deploymentOptionCompression)
+	}
+
+	opts := &compressionOptions{}
+
+	for key, target := range map[string]*bool{"request": &opts.Request, "response": &opts.Response} {
+		v, exists := raw[key]
+		if !exists || v == nil {
+			continue
+		}
+
+		enabled, ok := v.(bool)
+		if !ok {
+			return nil, errors.Errorf("deployment_options.%s.%s must be a boolean", deploymentOptionCompression, key)
+		}
+
+		*target = enabled
+	}
+
+	if v, exists := raw["min_size_bytes"]; exists && v != nil {
+		size, err := toFloat64(v)
+		if err != nil || size < 0 || size != float64(int(size)) {
+			return nil, errors.Errorf("deployment_options.%s.min_size_bytes must be a non-negative integer", deploymentOptionCompression)
+		}
+
+		n := int(size)
+		opts.MinSizeBytes = &n
+	}
+
+	return opts, nil
+}
+
+// tracingOptions holds the router tracing settings parsed from endpoint deployment options.
+type tracingOptions struct {
+	OTLPEndpoint string
+	SampleRatio  *float64
+}
+
+// Env returns the environment variables configuring tracing of the serve application.
+func (o *tracingOptions) Env() map[string]string {
+	env := map[string]string{
+		tracingOTLPEndpointEnv: o.OTLPEndpoint,
+	}
+
+	if o.SampleRatio != nil {
+		env[tracingSampleRatioEnv] = strconv.FormatFloat(*o.SampleRatio, 'f', -1, 64)
+	}
+
+	return env
+}
+
+// getTracingOptions parses deployment_options.tracing of the endpoint.
+// It returns nil if the endpoint does not configure tracing.
+func getTracingOptions(endpoint *v1.Endpoint) (*tracingOptions, error) {
+	if endpoint.Spec == nil || endpoint.Spec.DeploymentOptions == nil || endpoint.Spec.DeploymentOptions[deploymentOptionTracing] == nil {
+		return nil, nil
+	}
+
+	raw, ok := endpoint.Spec.DeploymentOptions[deploymentOptionTracing].(map[string]interface{})
+	if !ok {
+		return nil, errors.Errorf("deployment_options.%s must be an object", deploymentOptionTracing)
+	}
+
+	opts := &tracingOptions{}
+
+	for key, v := range raw {
+		switch key {
+		case "otlp_endpoint":
+			endpointURL, _ := v.(string)
+
+			u, err := url.Parse(endpointURL)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return nil, errors.Errorf("deployment_options.%s.otlp_endpoint must be an http or https URL", deploymentOptionTracing)
+			}
+
+			opts.OTLPEndpoint = strings.TrimSuffix(endpointURL, "/")
+		case "sample_ratio":
+			ratio, err := toFloat64(v)
+			if err != nil || ratio < 0 || ratio > 1 {
+				return nil, errors.Errorf("deployment_options.%s.sample_ratio must be a number between 0 and 1", deploymentOptionTracing)
+			}
+
+			opts.SampleRatio = &ratio
+		default:
+			return nil, errors.Errorf("unknown deployment_options.%s.%s", deploymentOptionTracing, key)
+		}
+	}
+
+	if opts.OTLPEndpoint == "" {
+		return nil, errors.Errorf("deployment_options.%s.otlp_endpoint is required", deploymentOptionTracing)
+	}
+
+	return opts, nil
+}
+
+const (
+	accessLogSinkStdout      = "stdout"
+	accessLogSinkObjectStore = "object_store"
+)
+
+// accessLogFields are the fields of the router access log entries, see
+// serve/_utils/access_log.py.
+var accessLogFields = []string{
+	"timestamp", "request_id", "workspace", "endpoint", "api_key", "model", "method", "path",
+	"status", "latency_ms", "stream", "prompt_tokens", "completion_tokens", "total_tokens",
+}
+
+// accessLogOptions holds the router access log settings parsed from endpoint deployment options.
+type accessLogOptions struct {
+	Sink string
+	URL  string
+	// Fields is empty when all the fields are logged.
+	Fields      []string
+	SampleRatio *float64
+}
+
+// Env returns the environment variables configuring access logging of the serve application.
+func (o *accessLogOptions) Env() map[string]string {
+	env := map[string]string{
+		accessLogSinkEnv: o.Sink,
+	}
+
+	if o.URL != "" {
+		env[accessLogURLEnv] = o.URL
+	}
+
+	if len(o.Fields) > 0 {
+		env[accessLogFieldsEnv] = strings.Join(o.Fields, ",")
+	}
+
+	if o.SampleRatio != nil {
+		env[accessLogSampleRatioEnv] = strconv.FormatFloat(*o.SampleRatio, 'f', -1, 64)
+	}
+
+	return env
+}
+
+// getAccessLogOptions parses deployment_options.access_log of the endpoint.
+// It returns nil if the endpoint does not configure access logging.
+func getAccessLogOptions(endpoint *v1.Endpoint) (*accessLogOptions, error) {
+	if endpoint.Spec == nil || endpoint.Spec.DeploymentOptions == nil || endpoint.Spec.DeploymentOptions[deploymentOptionAccessLog] == nil {
+		return nil, nil
+	}
+
+	raw, ok := endpoint.Spec.DeploymentOptions[deploymentOptionAccessLog].(map[string]interface{})
+	if !ok {
+		return nil, errors.Errorf("deployment_options.%s must be an object", deploymentOptionAccessLog)
+	}
+
+	opts := &accessLogOptions{}
+
+	for key, v := range raw {
+		switch key {
+		case "sink":
+			sink, _ := v.(string)
+			if sink != accessLogSinkStdout && sink != accessLogSinkObjectStore {
+				return nil, errors.Errorf("deployment_options.%s.sink must be %s or %s",
+					deploymentOptionAccessLog, accessLogSinkStdout, accessLogSinkObjectStore)
+			}
+
+			opts.Sink = sink
+		case "url":
+			sinkURL, _ := v.(string)
+
+			u, err := url.Parse(sinkURL)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return nil, errors.Errorf("deployment_options.%s.url must be an http or https URL", deploymentOptionAccessLog)
+			}
+
+			opts.URL = strings.TrimSuffix(sinkURL, "/")
+		case "fields":
+			fields, ok := v.([]interface{})
+			if !ok || len(fields) == 0 {
+				return nil, errors.Errorf("deployment_options.%s.fields must be a non-empty list", deploymentOptionAccessLog)
+			}
+
+			for _, f := range fields {
+				name, _ := f.(string)
+				if !slices.Contains(accessLogFields, name) {
+					return nil, errors.Errorf("deployment_options.%s.fields has unknown field %v, known fields are %s",
+						deploymentOptionAccessLog, f, strings.Join(accessLogFields, ", "))
+				}
+
+				opts.Fields = append(opts.Fields, name)
+			}
+		case "sample_ratio":
+			ratio, err := toFloat64(v)
+			if err != nil || ratio < 0 || ratio > 1 {
+				return nil, errors.Errorf("deployment_options.%s.sample_ratio must be a number between 0 and 1", deploymentOptionAccessLog)
+			}
+
+			opts.SampleRatio = &ratio
+		default:
+			return nil, errors.Errorf("unknown deployment_options.%s.%s", deploymentOptionAccessLog, key)
+		}
+	}
+
+	if opts.Sink == "" {
+		return nil, errors.Errorf("deployment_options.%s.sink is required", deploymentOptionAccessLog)
+	}
+
+	if opts.Sink == accessLogSinkObjectStore && opts.URL == "" {
+		return nil, errors.Errorf("deployment_options.%s.url is required by the %s sink", deploymentOptionAccessLog, accessLogSinkObjectStore)
+	}
+
+	if opts.Sink != accessLogSinkObjectStore && opts.URL != "" {
+		return nil, errors.Errorf("deployment_options.%s.url is only used by the %s sink", deploymentOptionAccessLog, accessLogSinkObjectStore)
+	}
+
+	return opts, nil
+}
+
+// getTopologyAwarePlacement parses deployment_options.topology_aware_placement of the endpoint.
+func getTopologyAwarePlacement(endpoint *v1.Endpoint) (bool, error) {
+	if endpoint.Spec == nil || endpoint.Spec.DeploymentOptions == nil || endpoint.Spec.DeploymentOptions[deploymentOptionTopologyAwarePlacement] == nil {
+		return false, nil
+	}
+
+	enabled, ok := endpoint.Spec.DeploymentOptions[deploymentOptionTopologyAwarePlacement].(bool)
+	if !ok {
+		return false, errors.Errorf("deployment_options.%s must be a boolean", deploymentOptionTopologyAwarePlacement)
+	}
+
+	return enabled, nil
+}
+
+// getSharedWeights parses deployment_options.shared_weights of the endpoint.
+func getSharedWeights(endpoint *v1.Endpoint) (bool, error) {
+	if endpoint.Spec == nil || endpoint.Spec.DeploymentOptions == nil || endpoint.Spec.DeploymentOptions[deploymentOptionSharedWeights] == nil {
+		return false, nil
+	}
+
+	enabled, ok := endpoint.Spec.DeploymentOptions[deploymentOptionSharedWeights].(bool)
+	if !ok {
+		return false, errors.Errorf("deployment_options.%s must be a boolean", deploymentOptionSharedWeights)
+	}
+
+	return enabled, nil
+}
+
+// getPeerDiscovery parses deployment_options.peer_discovery of the endpoint.
+func getPeerDiscovery(endpoint *v1.Endpoint) (bool, error) {
+	if endpoint.Spec == nil || endpoint.Spec.DeploymentOptions == nil || endpoint.Spec.DeploymentOptions[deploymentOptionPeerDiscovery] == nil {
+		return false, nil
+	}
+
+	enabled, ok := endpoint.Spec.DeploymentOptions[deploymentOptionPeerDiscovery].(bool)
+	if !ok {
+		return false, errors.Errorf("deployment_options.%s must be a boolean", deploymentOptionPeerDiscovery)
+	}
+
+	return enabled, nil
+}
+
+// getImageCanary parses deployment_options.image_canary of the endpoint, false by default.
+func getImageCanary(endpoint *v1.Endpoint) (bool, error) {
+	if endpoint.Spec == nil || endpoint.Spec.DeploymentOptions == nil || endpoint.Spec.DeploymentOptions[deploymentOptionImageCanary] == nil {
+		return false, nil
+	}
+
+	enabled, ok := endpoint.Spec.DeploymentOptions[deploymentOptionImageCanary].(bool)
+	if !ok {
+		return false, errors.Errorf("deployment_options.%s must be a boolean", deploymentOptionImageCanary)
+	}
+
+	return enabled, nil
+}
+
+// securityContextOptions holds the container security settings parsed from endpoint deployment options.
+type securityContextOptions struct {
+	ReadOnlyRootFilesystem bool
+}
+
+// getSecurityContextOptions parses deployment_options.security_context of the endpoint.
+// It returns nil if the endpoint does not configure it.
+func getSecurityContextOptions(endpoint *v1.Endpoint) (*securityContextOptions, error) {
+	if endpoint.Spec == nil || endpoint.Spec.DeploymentOptions == nil || endpoint.Spec.DeploymentOptions[deploymentOptionSecurityContext] == nil {
+		return nil, nil
+	}
+
+	raw, ok := endpoint.Spec.DeploymentOptions[deploymentOptionSecurityContext].(map[string]interface{})
+	if !ok {
+		return nil, errors.Errorf("deployment_options.%s must be an object", deploymentOptionSecurityContext)
+	}
+
+	opts := &securityContextOptions{}
+
+	for key, v := range raw {
+		switch key {
+		case "read_only_root_filesystem":
+			enabled, ok := v.(bool)
+			if !ok {
+				return nil, errors.Errorf("deployment_options.%s.read_only_root_filesystem must be a boolean", deploymentOptionSecurityContext)
+			}
+
+			opts.ReadOnlyRootFilesystem = enabled
+		default:
+			return nil, errors.Errorf("unknown deployment_options.%s.%s", deploymentOptionSecurityContext, key)
+		}
+	}
+
+	return opts, nil
+}
+
+// getImageAcceleratorValidation parses deployment_options.image_accelerator_validation of the
+// endpoint, true by default.
+func getImageAcceleratorValidation(endpoint *v1.Endpoint) (bool, error) {
+	if endpoint.Spec == nil || endpoint.Spec.DeploymentOptions == nil || endpoint.Spec.DeploymentOptions[deploymentOptionImageAcceleratorValidation] == nil {
+		return true, nil
+	}
+
+	enabled, ok := endpoint.Spec.DeploymentOptions[deploymentOptionImageAcceleratorValidation].(bool)
+	if !ok {
+		return false, errors.Errorf("deployment_options.%s must be a boolean", deploymentOptionImageAcceleratorValidation)
+	}
+
+	return enabled, nil
+}
+
+// getGPUDevicePlugin returns deployment_options.gpu_device_plugin of the endpoint, or an empty
+// string if the endpoint does not select one.
+func getGPUDevicePlugin(endpoint *v1.Endpoint) (v1.GPUDevicePlugin, error) {
+	if endpoint.Spec == nil || endpoint.Spec.DeploymentOptions == nil || endpoint.Spec.DeploymentOptions[deploymentOptionGPUDevicePlugin] == nil {
+		return "", nil
+	}
+
+	devicePlugin, ok := endpoint.Spec.DeploymentOptions[deploymentOptionGPUDevicePlugin].(string)
+	if !ok {
+		return "", errors.Errorf("deployment_options.%s must be a string", deploymentOptionGPUDevicePlugin)
+	}
+
+	return v1.GPUDevicePlugin(devicePlugin), nil
+}
+
+// getLifecycle parses deployment_options.lifecycle of the endpoint into the lifecycle hooks of
+// the engine container. It returns nil if the endpoint does not configure any hook.
+func getLifecycle(endpoint *v1.Endpoint) (*corev1.Lifecycle, error) {
+	if endpoint.Spec == nil || endpoint.Spec.DeploymentOptions == nil || endpoint.Spec.DeploymentOptions[deploymentOptionLifecycle] == nil {
+		return nil, nil
+	}
+
+	raw, ok := endpoint.Spec.DeploymentOptions[deploymentOptionLifecycle].(map[string]interface{})
+	if !ok {
+		return nil, errors.Errorf("deployment_options.%s must be an object", deploymentOptionLifecycle)
+	}
+
+	var lifecycle corev1.Lifecycle
+
+	for key, v := range raw {
+		command, err := parseHookCommand(v)
+		if err != nil {
+			return nil, errors.Wrapf(err, "deployment_options.%s.%s", deploymentOptionLifecycle, key)
+		}
+
+		handler := &corev1.LifecycleHandler{Exec: &corev1.ExecAction{Command: command}}
+
+		switch key {
+		case "pre_stop":
+			lifecycle.PreStop = handler
+		case "post_start":
+			lifecycle.PostStart = handler
+		default:
+			return nil, errors.Errorf("unknown deployment_options.%s.%s", deploymentOptionLifecycle, key)
+		}
+	}
+
+	if lifecycle.PreStop == nil && lifecycle.PostStart == nil {
+		return nil, nil
+	}
+
+	return &lifecycle, nil
+}
+
+// getTerminationGracePeriodSeconds returns deployment_options.termination_grace_period_seconds
+// of the endpoint, or the default of its model task.
+func getTerminationGracePeriodSeconds(endpoint *v1.Endpoint) (*int64, error) {
+	if !terminationGracePeriodConfigured(endpoint) {
+		task := ""
+		if endpoint.Spec != nil && endpoint.Spec.Model != nil {
+			task = endpoint.Spec.Model.Task
+		}
+
+		gracePeriod := int64(v1.DefaultRequestTimeoutSeconds(task)) + terminationGracePeriodMarginSeconds
+
+		return &gracePeriod, nil
+	}
+
+	seconds, err := toFloat64(endpoint.Spec.DeploymentOptions[deploymentOptionTerminationGracePeriod])
+	if err != nil || seconds < 0 || seconds != float64(int64(seconds)) {
+		return nil, errors.Errorf("deployment_options.%s must be a non-negative integer", deploymentOptionTerminationGracePeriod)
+	}
+
+	gracePeriod := int64(seconds)
+
+	return &gracePeriod, nil
+}
+
+func terminationGracePeriodConfigured(endpoint *v1.Endpoint) bool {
+	return endpoint.Spec != nil && endpoint.Spec.DeploymentOptions != nil &&
+		endpoint.Spec.DeploymentOptions[deploymentOptionTerminationGracePeriod] != nil
+}
+
+// parseHookCommand parses an exec command, e.g. of a lifecycle hook, a non-empty list of
+// strings whose first element is the executable.
+func parseHookCommand(v interface{}) ([]string, error) {
+	items, ok := v.([]interface{})
+	if !ok || len(items) == 0 {
+		return nil, errors.New("must be a non-empty list of strings")
+	}
+
+	command := make([]string, 0, len(items))
+
+	for i, item := range items {
+		arg, ok := item.(string)
+		if !ok {
+			return nil, errors.Errorf("[%d] must be a string", i)
+		}
+
+		command = append(command, arg)
+	}
+
+	if strings.TrimSpace(command[0]) == "" {
+		return nil, errors.New("executable must not be empty")
+	}
+
+	return command, nil
+}
+
+// getStartupTimeoutSeconds parses deployment_options.startup_timeout_seconds of the endpoint.
+// It returns 0 if the endpoint does not override the startup timeout.
+func getStartupTimeoutSeconds(endpoint *v1.Endpoint) (int, error) {
+	if endpoint.Spec == nil || endpoint.Spec.DeploymentOptions == nil || endpoint.Spec.DeploymentOptions[deploymentOptionStartupTimeoutSeconds] == nil {
+		return 0, nil
+	}
+
+	seconds, err := toFloat64(endpoint.Spec.DeploymentOptions[deploymentOptionStartupTimeoutSeconds])
+	if err != nil || seconds <= 0 || seconds != float64(int(seconds)) {
+		return 0, errors.Errorf("deployment_options.%s must be a positive integer", deploymentOptionStartupTimeoutSeconds)
+	}
+
+	return int(seconds), nil
+}
+
+// getServiceMesh parses deployment_options.service_mesh of the endpoint.
+// It returns nil if the endpoint does not configure it.
+func getServiceMesh(endpoint *v1.Endpoint) (*bool, error) {
+	if endpoint.Spec == nil || endpoint.Spec.DeploymentOptions == nil || endpoint.Spec.DeploymentOptions[deploymentOptionServiceMesh] == nil {
+		return nil, nil
+	}
+
+	enabled, ok := endpoint.Spec.DeploymentOptions[deploymentOptionServiceMesh].(bool)
+	if !ok {
+		return nil, errors.Errorf("deployment_options.%s must be a boolean", deploymentOptionServiceMesh)
+	}
+
+	return &enabled, nil
+}
+
+// getServeHealthCheckOptions parses deployment_options.health_check_period_s and
+// deployment_options.health_check_timeout_s of the endpoint into Ray serve deployment
+// options. Only the configured options are returned so the Ray defaults apply otherwise.
+func getServeHealthCheckOptions(endpoint *v1.Endpoint) (map[string]float64, error) {
+	opts := map[string]float64{}
+	if endpoint.Spec == nil || endpoint.Spec.DeploymentOptions == nil {
+		return opts, nil
+	}
+
+	for _, key := range []string{deploymentOptionHealthCheckPeriodSeconds, deploymentOptionHealthCheckTimeoutSeconds} {
+		raw := endpoint.Spec.DeploymentOptions[key]
+		if raw == nil {
+			continue
+		}
+
+		seconds, err := toFloat64(raw)
+		if err != nil || seconds < 0 {
+			return nil, errors.Errorf("deployment_options.%s must be a non-negative number", key)
+		}
+
+		opts[key] = seconds
+	}
+
+	return opts, nil
+}
+
+// phaseTimeoutOptions maps the keys of deployment_options.phase_timeouts to the stage they bound.
+var phaseTimeoutOptions = map[string]deploymentStage{
+	"scheduling_seconds":     deploymentStageScheduling,
+	"image_pull_seconds":     deploymentStageImagePull,
+	"model_download_seconds": deploymentStageModelDownload,
+	"model_load_seconds":     deploymentStageModelLoad,
+}
+
+// getPhaseTimeouts parses deployment_options.phase_timeouts of the endpoint.
+func getPhaseTimeouts(endpoint *v1.Endpoint) (phaseTimeouts, error) {
+	timeouts := phaseTimeouts{}
+
+	if endpoint.Spec == nil || endpoint.Spec.DeploymentOptions == nil || endpoint.Spec.DeploymentOptions[deploymentOptionPhaseTimeouts] == nil {
+		return timeouts, nil
+	}
+
+	raw, ok := endpoint.Spec.DeploymentOptions[deploymentOptionPhaseTimeouts].(map[string]interface{})
+	if !ok {
+		return nil, errors.Errorf("deployment_options.%s must be an object", deploymentOptionPhaseTimeouts)
+	}
+
+	for key, v := range raw {
+		stage, ok := phaseTimeoutOptions[key]
+		if !ok {
+			return nil, errors.Errorf("unknown deployment_options.%s.%s", deploymentOptionPhaseTimeouts, key)
+		}
+
+		if v == nil {
+			continue
+		}
+
+		seconds, err := toFloat64(v)
+		if err != nil || seconds <= 0 || seconds != float64(int(seconds)) {
+			return nil, errors.Errorf("deployment_options.%s.%s must be a positive integer", deploymentOptionPhaseTimeouts, key)
+		}
+
+		timeouts[stage] = time.Duration(seconds) * time.Second
+	}
+
+	return timeouts, nil
+}
+
+// placementGroupOptions holds the placement group settings parsed from endpoint deployment options.
+type placementGroupOptions struct {
+	Name     string
+	Bundles  []map[string]float64
+	Strategy string
+}
+
+// getPlacementGroupOptions parses deployment_options.placement_group of the endpoint.
+// It returns nil if the endpoint does not configure a placement group.
+func getPlacementGroupOptions(endpoint *v1.Endpoint) (*placementGroupOptions, error) {
+	if endpoint.Spec == nil || endpoint.Spec.DeploymentOptions == nil || endpoint.Spec.DeploymentOptions[deploymentOptionPlacementGroup] == nil {
+		return nil, nil
+	}
+
+	raw, ok := endpoint.Spec.DeploymentOptions[deploymentOptionPlacementGroup].(map[string]interface{})
+	if !ok {
+		return nil, errors.Errorf("deployment_options.%s must be an object", deploymentOptionPlacementGroup)
+	}
+
+	opts := &placementGroupOptions{}
+
+	if v, ok := raw["name"]; ok && v != nil {
+		name, ok := v.(string)
+		if !ok || name == "" {
+			return nil, errors.Errorf("deployment_options.%s.name must be a non-empty string", deploymentOptionPlacementGroup)
+		}
+
+		opts.Name = name
+	}
+
+	if v, ok := raw["bundles"]; ok && v != nil {
+		bundles, ok := v.([]interface{})
+		if !ok || len(bundles) == 0 {
+			return nil, errors.Errorf("deployment_options.%s.bundles must be a non-empty list", deploymentOptionPlacementGroup)
+		}
+
+		for i, b := range bundles {
+			bundle, err := parsePlacementGroupBundle(b)
+			if err != nil {
+				return nil, errors.Wrapf(err, "deployment_options.%s.bundles[%d]", deploymentOptionPlacementGroup, i)
+			}
+
+			opts.Bundles = append(opts.Bundles, bundle)
+		}
+	}
+
+	if v, ok := raw["strategy"]; ok && v != nil {
+		strategy, ok := v.(string)
+		if !ok || !slices.Contains(rayPlacementGroupStrategies, strategy) {
+			return nil, errors.Errorf("deployment_options.%s.strategy must be one of %s", deploymentOptionPlacementGroup,
+				strings.Join(rayPlacementGroupStrategies, ", "))
+		}
+
+		opts.Strategy = strategy
+	}
+
+	switch {
+	case opts.Name != "" && len(opts.Bundles) > 0:
+		return nil, errors.Errorf("deployment_options.%s.name and bundles are mutually exclusive", deploymentOptionPlacementGroup)
+	case opts.Name == "" && len(opts.Bundles) == 0:
+		return nil, errors.Errorf("deployment_options.%s requires a name or bundles", deploymentOptionPlacementGroup)
+	case opts.Name != "" && opts.Strategy != "":
+		return nil, errors.Errorf("deployment_options.%s.strategy only applies to bundles", deploymentOptionPlacementGroup)
+	}
+
+	if len(opts.Bundles) > 0 && opts.Strategy == "" {
+		opts.Strategy = rayPlacementGroupStrategyPack
+	}
+
+	return opts, nil
+}
+
+func parsePlacementGroupBundle(v interface{}) (map[string]float64, error) {
+	raw, ok := v.(map[string]interface{})
+	if !ok || len(raw) == 0 {
+		return nil, errors.New("must be a non-empty object of resource amounts")
+	}
+
+	bundle := make(map[string]float64, len(raw))
+
+	for resource, amount := range raw {
+		value, err := toFloat64(amount)
+		if err != nil || value < 0 {
+			return nil, errors.Errorf("%s must be a non-negative number", resource)
+		}
+
+		bundle[resource] = value
+	}
+
+	return bundle, nil
+}
+
+// toFloat64 converts a JSON-decoded number to float64.
+func toFloat64(v interface{}) (float64, error) {
+	switch n := v.(type) {
+	case float64:
+		return n, nil
+	case float32:
+		return float64(n), nil
+	case int:
+		return float64(n), nil
+	case int32:
+		return float64(n), nil
+	case int64:
+		return float64(n), nil
+	default:
+		return 0, errors.Errorf("unexpected number type %T", v)
+	}
+}
+
+// replicaGroupPlacement holds where the replicas of a replica group are placed.
+type replicaGroupPlacement struct {
+	NodeSelector map[string]string   `json:"node_selector,omitempty"`
+	Tolerations  []corev1.Toleration `json:"tolerations,omitempty"`
+}
+
+// mixedPlacementOptions holds the mixed placement settings parsed from endpoint deployment options.
+type mixedPlacementOptions struct {
+	OnDemandReplicas int
+	OnDemand         replicaGroupPlacement
+	Spot             replicaGroupPlacement
+}
+
+// getMixedPlacementOptions parses deployment_options.mixed_placement of the endpoint.
+// It returns nil if the endpoint does not configure mixed placement.
+func getMixedPlacementOptions(endpoint *v1.Endpoint) (*mixedPlacementOptions, error) {
+	if endpoint.Spec == nil || endpoint.Spec.DeploymentOptions == nil || endpoint.Spec.DeploymentOptions[deploymentOptionMixedPlacement] == nil {
+		return nil, nil
+	}
+
+	raw, ok := endpoint.Spec.DeploymentOptions[deploymentOptionMixedPlacement].(map[string]interface{})
+	if !ok {
+		return nil, errors.Errorf("deployment_options.%s must be an object", deploymentOptionMixedPlacement)
+	}
+
+	opts := &mixedPlacementOptions{}
+
+	for key, v := range raw {
+		switch key {
+		case "on_demand_replicas":
+			replicas, err := toFloat64(v)
+			if err != nil || replicas < 0 || replicas != float64(int(replicas)) {
+				return nil, errors.Errorf("deployment_options.%s.on_demand_replicas must be a non-negative integer", deploymentOptionMixedPlacement)
+			}
+
+			opts.OnDemandReplicas = int(replicas)
+		case "on_demand", "spot":
+			var placement replicaGroupPlacement
+
+			if err := parseReplicaGroupPlacement(v, &placement); err != nil {
+				return nil, errors.Wrapf(err, "deployment_options.%s.%s", deploymentOptionMixedPlacement, key)
+			}
+
+			if key == "spot" {
+				opts.Spot = placement
+			} else {
+				opts.OnDemand = placement
+			}
+		default:
+			return nil, errors.Errorf("unknown deployment_options.%s.%s", deploymentOptionMixedPlacement, key)
+		}
+	}
+
+	if len(opts.Spot.NodeSelector) == 0 && len(opts.Spot.Tolerations) == 0 {
+		return nil, errors.Errorf("deployment_options.%s.spot requires a node_selector or tolerations", deploymentOptionMixedPlacement)
+	}
+
+	return opts, nil
+}
+
+func parseReplicaGroupPlacement(v interface{}, placement *replicaGroupPlacement) error {
+	if _, ok := v.(map[string]interface{}); !ok {
+		return errors.New("must be an object")
+	}
+
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal replica group placement")
+	}
+
+	if err := json.Unmarshal(raw, placement); err != nil {
+		return errors.New("must have a node_selector of strings and a list of kubernetes tolerations")
+	}
+
+	for i := range placement.Tolerations {
+		if err := validateToleration(&placement.Tolerations[i]); err != nil {
+			return errors.Wrapf(err, "tolerations[%d]", i)
+		}
+	}
+
+	return nil
+}
+
+// getTolerations parses deployment_options.tolerations of the endpoint.
+func getTolerations(endpoint *v1.Endpoint) ([]corev1.Toleration, error) {
+	if endpoint.Spec == nil || endpoint.Spec.DeploymentOptions == nil || endpoint.Spec.DeploymentOptions[deploymentOptionTolerations] == nil {
+		return nil, nil
+	}
+
+	v := endpoint.Spec.DeploymentOptions[deploymentOptionTolerations]
+	if _, ok := v.([]interface{}); !ok {
+		return nil, errors.Errorf("deployment_options.%s must be a list of kubernetes tolerations", deploymentOptionTolerations)
+	}
+
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal tolerations")
+	}
+
+	var tolerations []corev1.Toleration
+	if err := json.Unmarshal(raw, &tolerations); err != nil {
+		return nil, errors.Errorf("deployment_options.%s must be a list of kubernetes tolerations", deploymentOptionTolerations)
+	}
+
+	for i := range tolerations {
+		if err := validateToleration(&tolerations[i]); err != nil {
+			return nil, errors.Wrapf(err, "deployment_options.%s[%d]", deploymentOptionTolerations, i)
+		}
+	}
+
+	return tolerations, nil
+}
+
+// validateToleration checks the toleration the way the kubernetes API server does, so an
+// invalid one fails the endpoint instead of its Deployment.
+func validateToleration(toleration *corev1.Toleration) error {
+	switch toleration.Operator {
+	case corev1.TolerationOpEqual, "":
+		if toleration.Key == "" {
+			return errors.New("key is required unless operator is Exists")
+		}
+	case corev1.TolerationOpExists:
+		if toleration.Value != "" {
+			return errors.New("value must be empty when operator is Exists")
+		}
+	default:
+		return errors.Errorf("operator must be Equal or Exists, got %q", toleration.Operator)
+	}
+
+	switch toleration.Effect {
+	case "", corev1.TaintEffectNoSchedule, corev1.TaintEffectPreferNoSchedule, corev1.TaintEffectNoExecute:
+	default:
+		return errors.Errorf("effect must be NoSchedule, PreferNoSchedule or NoExecute, got %q", toleration.Effect)
+	}
+
+	if toleration.TolerationSeconds != nil && toleration.Effect != corev1.TaintEffectNoExecute {
+		return errors.New("tolerationSeconds requires the NoExecute effect")
+	}
+
+	return nil
+}
+
+// modelConversionOptions holds the model conversion init container settings parsed from
+// endpoint deployment options.
+type modelConversionOptions struct {
+	Image   string // empty runs the conversion in the engine image
+	Command []string
+}
+
+// getModelConversionOptions parses deployment_options.model_conversion of the endpoint. It
+// returns nil if the endpoint does not convert its model.
+func getModelConversionOptions(endpoint *v1.Endpoint) (*modelConversionOptions, error) {
+	if endpoint.Spec == nil || endpoint.Spec.DeploymentOptions == nil || endpoint.Spec.DeploymentOptions[deploymentOptionModelConversion] == nil {
+		return nil, nil
+	}
+
+	raw, ok := endpoint.Spec.DeploymentOptions[deploymentOptionModelConversion].(map[string]interface{})
+	if !ok {
+		return nil, errors.Errorf("deployment_options.%s must be an object", deploymentOptionModelConversion)
+	}
+
+	opts := &modelConversionOptions{}
+
+	for key, v := range raw {
+		switch key {
+		case "image":
+			image, ok := v.(string)
+			if !ok {
+				return nil, errors.Errorf("deployment_options.%s.image must be a string", deploymentOptionModelConversion)
+			}
+
+			opts.Image = strings.TrimSpace(image)
+		case "command":
+			command, err := parseHookCommand(v)
+			if err != nil {
+				return nil, errors.Wrapf(err, "deployment_options.%s.command", deploymentOptionModelConversion)
+			}
+
+			opts.Command = command
+		default:
+			return nil, errors.Errorf("unknown deployment_options.%s.%s", deploymentOptionModelConversion, key)
+		}
+	}
+
+	if opts.Command == nil {
+		return nil, errors.Errorf("deployment_options.%s.command is required", deploymentOptionModelConversion)
+	}
+
+	return opts, nil
+}
+
+// authSidecarOptions holds the auth sidecar settings parsed from endpoint deployment options.
+type authSidecarOptions struct {
+	Image       string // empty runs the auth proxy of the neutree runtime image
+	ValidateURL string
+}
+
+// getAuthSidecarOptions parses deployment_options.auth_sidecar of the endpoint. It returns nil
+// if the endpoint has no auth sidecar.
+func getAuthSidecarOptions(endpoint *v1.Endpoint) (*authSidecarOptions, error) {
+	if endpoint.Spec == nil || endpoint.Spec.DeploymentOptions == nil || endpoint.Spec.DeploymentOptions[deploymentOptionAuthSidecar] == nil {
+		return nil, nil
+	}
+
+	raw, ok := endpoint.Spec.DeploymentOptions[deploymentOptionAuthSidecar].(map[string]interface{})
+	if !ok {
+		return nil, errors.Errorf("deployment_options.%s must be an object", deploymentOptionAuthSidecar)
+	}
+
+	opts := &authSidecarOptions{}
+
+	for key, v := range raw {
+		value, ok := v.(string)
+
+		switch key {
+		case "image":
+			if !ok {
+				return nil, errors.Errorf("deployment_options.%s.image must be a string", deploymentOptionAuthSidecar)
+			}
+
+			opts.Image = strings.TrimSpace(value)
+		case "validate_url":
+			if !ok {
+				return nil, errors.Errorf("deployment_options.%s.validate_url must be a string", deploymentOptionAuthSidecar)
+			}
+
+			opts.ValidateURL = strings.TrimSpace(value)
+		default:
+			return nil, errors.Errorf("unknown deployment_options.%s.%s", deploymentOptionAuthSidecar, key)
+		}
+	}
+
+	if opts.ValidateURL == "" {
+		return nil, errors.Errorf("deployment_options.%s.validate_url is required", deploymentOptionAuthSidecar)
+	}
+
+	u, err := url.Parse(opts.ValidateURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, errors.Errorf("deployment_options.%s.validate_url must be an http(s) URL, got %q",
+			deploymentOptionAuthSidecar, opts.ValidateURL)
+	}
+
+	return opts, nil
+}
+
+// getModelCacheName parses deployment_options.model_cache of the endpoint and checks the
+// cluster has the model cache. It returns an empty name if the endpoint does not select one.
+func getModelCacheName(endpoint *v1.Endpoint, cluster *v1.Cluster) (string, error) {
+	if endpoint.Spec == nil || endpoint.Spec.DeploymentOptions == nil || endpoint.Spec.DeploymentOptions[deploymentOptionModelCache] == nil {
+		return "", nil
+	}
+
+	name, ok := endpoint.Spec.DeploymentOptions[deploymentOptionModelCache].(string)
+	if !ok || strings.TrimSpace(name) == "" {
+		return "", errors.Errorf("deployment_options.%s must be a non-empty string", deploymentOptionModelCache)
+	}
+
+	name = strings.TrimSpace(name)
+
+	if util.FindModelCache(cluster, name) == nil {
+		return "", errors.Errorf("deployment_options.%s: cluster %s has no model cache %s",
+			deploymentOptionModelCache, cluster.Metadata.WorkspaceName(), name)
+	}
+
+	return name, nil
+}
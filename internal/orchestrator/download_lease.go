@@ -0,0 +1,128 @@
+package orchestrator
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v1 "github.com/neutree-ai/neutree/api/v1"
+	"github.com/neutree-ai/neutree/internal/util"
+)
+
+const (
+	// modelDownloaderLeaseEnv holds the name prefix of the Leases the model-downloader takes its
+	// download slot from, the Leases are named after it and their index below max_concurrency.
+	modelDownloaderLeaseEnv = "NEUTREE_DL_LEASE"
+
+	// downloadLeaseDurationSeconds is how long a download slot stays taken without its holder
+	// renewing it, e.g. after the replica downloading the model was killed.
+	downloadLeaseDurationSeconds = 30
+)
+
+// downloadLeasePrefix returns the name prefix of the model download Leases of the endpoint.
+func downloadLeasePrefix(endpointName string) string {
+	return endpointName + "-model-download"
+}
+
+// downloadLeaseNames returns the names of the model download Leases of the endpoint, one per
+// download allowed at once.
+func downloadLeaseNames(endpointName string, maxConcurrency int) []string {
+	names := make([]string, 0, maxConcurrency)
+
+	for i := range maxConcurrency {
+		names = append(names, fmt.Sprintf("%s-%d", downloadLeasePrefix(endpointName), i))
+	}
+
+	return names
+}
+
+// downloaderServiceAccountName returns the name of the service account the replicas of the
+// endpoint take the model download Leases with.
+func downloaderServiceAccountName(endpointName string) string {
+	return endpointName + "-model-downloader"
+}
+
+// addDownloadLeaseObjects adds the model download Leases of the endpoint when its
+// model-downloader takes download slots from Leases, along with the service account the
+// replicas run as and the Role allowing it to take and release those Leases only. The Leases
+// are deployed with the endpoint so they are deleted with it, the model-downloader only
+// updates their holder.
+func addDownloadLeaseObjects(objects *unstructured.UnstructuredList, endpoint *v1.Endpoint, namespace string) error {
+	opts, err := getModelDownloaderOptions(endpoint)
+	if err != nil || !opts.Lease {
+		return err
+	}
+
+	name := endpoint.Metadata.Name
+	serviceAccountName := downloaderServiceAccountName(name)
+	leaseNames := downloadLeaseNames(name, *opts.MaxConcurrency)
+
+	deployments := 0
+
+	for i := range objects.Items {
+		if objects.Items[i].GetKind() != "Deployment" {
+			continue
+		}
+
+		if err := unstructured.SetNestedField(objects.Items[i].Object, serviceAccountName,
+			"spec", "template", "spec", "serviceAccountName"); err != nil {
+			return errors.Wrap(err, "failed to set service account of deployment")
+		}
+
+		deployments++
+	}
+
+	if deployments == 0 {
+		return errors.Errorf("deploy template of endpoint %s has no deployment to take model download leases",
+			endpoint.Metadata.WorkspaceName())
+	}
+
+	leaseObjects := []client.Object{
+		&corev1.ServiceAccount{
+			TypeMeta:   metav1.TypeMeta{APIVersion: corev1.SchemeGroupVersion.String(), Kind: "ServiceAccount"},
+			ObjectMeta: metav1.ObjectMeta{Name: serviceAccountName, Namespace: namespace},
+		},
+		&rbacv1.Role{
+			TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "Role"},
+			ObjectMeta: metav1.ObjectMeta{Name: serviceAccountName, Namespace: namespace},
+			Rules: []rbacv1.PolicyRule{{
+				APIGroups:     []string{coordinationv1.GroupName},
+				Resources:     []string{"leases"},
+				ResourceNames: leaseNames,
+				Verbs:         []string{"get", "update"},
+			}},
+		},
+		&rbacv1.RoleBinding{
+			TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "RoleBinding"},
+			ObjectMeta: metav1.ObjectMeta{Name: serviceAccountName, Namespace: namespace},
+			Subjects:   []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: serviceAccountName, Namespace: namespace}},
+			RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: serviceAccountName},
+		},
+	}
+
+	for _, leaseName := range leaseNames {
+		leaseObjects = append(leaseObjects, &coordinationv1.Lease{
+			TypeMeta:   metav1.TypeMeta{APIVersion: coordinationv1.SchemeGroupVersion.String(), Kind: "Lease"},
+			ObjectMeta: metav1.ObjectMeta{Name: leaseName, Namespace: namespace},
+			Spec:       coordinationv1.LeaseSpec{LeaseDurationSeconds: pointer.Int32(downloadLeaseDurationSeconds)},
+		})
+	}
+
+	for _, obj := range leaseObjects {
+		u, err := util.ToUnstructured(obj)
+		if err != nil {
+			return errors.Wrapf(err, "failed to build %s", obj.GetObjectKind().GroupVersionKind().Kind)
+		}
+
+		objects.Items = append(objects.Items, *u)
+	}
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package orchestrator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	v1 "github.com/neutree-ai/neutree/api/v1"
+)
+
+func TestAddDownloadLeaseObjects(t *testing.T) {
+	for _, lease := range []bool{false, true} {
+		t.Run(map[bool]string{false: "disabled", true: "enabled"}[lease], func(t *testing.T) {
+			endpoint := &v1.Endpoint{
+				Metadata: &v1.Metadata{Workspace: "default", Name: "chat"},
+				Spec: &v1.EndpointSpec{DeploymentOptions: map[string]interface{}{
+					"model_downloader": map[string]interface{}{"max_concurrency": float64(2), "lease": lease},
+				}},
+			}
+
+			data := newDeploymentManifestVariables()
+			data.NeutreeVersion = "v0.1.0"
+			data.Namespace = "neutree-cluster-a"
+			data.ImagePrefix = "registry.example.com"
+			data.ImageRepo = "myrepo"
+			data.ImageTag = "v1.0.0"
+			data.EndpointName = "chat"
+			data.ModelArgs = map[string]interface{}{
+				"name":       "qwen3-8b",
+				"task":       "text-generation",
+				"path":       "/mnt/models/qwen3-8b",
+				"serve_name": "qwen3-8b",
+			}
+			data.RoutingLogic = "roundrobin"
+			data.Replicas = 4
+
+			objs, err := buildDeploymentObjects(realEmbeddedTemplate(t, "sglang-v0.5.10"), data)
+			require.NoError(t, err)
+			require.NoError(t, addDownloadLeaseObjects(objs, endpoint, "neutree-cluster-a"))
+
+			names := map[string][]string{}
+			for _, obj := range objs.Items {
+				names[obj.GetKind()] = append(names[obj.GetKind()], obj.GetName())
+			}
+
+			if !lease {
+				assert.Equal(t, map[string][]string{"Deployment": {"chat"}}, names)
+				return
+			}
+
+			// Only as many replicas as there are Leases download at once, whatever the replicas.
+			assert.Equal(t, map[string][]string{
+				"Deployment":     {"chat"},
+				"ServiceAccount": {"chat-model-downloader"},
+				"Role":           {"chat-model-downloader"},
+				"RoleBinding":    {"chat-model-downloader"},
+				"Lease":          {"chat-model-download-0", "chat-model-download-1"},
+			}, names)
+
+			for _, obj := range objs.Items {
+				switch obj.GetKind() {
+				case "Deployment":
+					var dep appsv1.Deployment
+					require.NoError(t, runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &dep))
+					assert.Equal(t, "chat-model-downloader", dep.Spec.Template.Spec.ServiceAccountName)
+				case "Role":
+					var role rbacv1.Role
+					require.NoError(t, runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &role))
+					assert.Equal(t, []rbacv1.PolicyRule{{
+						APIGroups:     []string{"coordination.k8s.io"},
+						Resources:     []string{"leases"},
+						ResourceNames: []string{"chat-model-download-0", "chat-model-download-1"},
+						Verbs:         []string{"get", "update"},
+					}}, role.Rules)
+				case "Lease":
+					var l coordinationv1.Lease
+					require.NoError(t, runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &l))
+					assert.Equal(t, "neutree-cluster-a", l.Namespace)
+					// The holder is left to the model-downloader, re-applying the Lease keeps it.
+					assert.Nil(t, l.Spec.HolderIdentity)
+					assert.Equal(t, int32(downloadLeaseDurationSeconds), *l.Spec.LeaseDurationSeconds)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,244 @@
+package orchestrator
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v1 "github.com/neutree-ai/neutree/api/v1"
+	"github.com/neutree-ai/neutree/internal/util"
+)
+
+const certManagerClusterIssuerAnnotation = "cert-manager.io/cluster-issuer"
+
+// endpointIngressTLS is how the certificate of an endpoint domain is provided.
+type endpointIngressTLS struct {
+	// Issuer is the cert-manager ClusterIssuer provisioning the certificate, empty if the
+	// secret is provided.
+	Issuer     string
+	SecretName string
+}
+
+// endpointCertManagerIssuer returns the cert-manager ClusterIssuer of the endpoint domain,
+// the endpoint one takes precedence over the cluster one.
+func endpointCertManagerIssuer(endpoint *v1.Endpoint, ingressConfig *v1.EndpointIngressConfig) string {
+	if endpoint.Spec.Domain.CertManagerIssuer != "" {
+		return endpoint.Spec.Domain.CertManagerIssuer
+	}
+
+	if ingressConfig != nil {
+		return ingressConfig.CertManagerIssuer
+	}
+
+	return ""
+}
+
+// resolveEndpointIngressTLS picks how the certificate of the endpoint domain is provided.
+// cert-manager is used when an issuer is configured and available in the cluster, the TLS
+// secret of the endpoint is used otherwise.
+func resolveEndpointIngressTLS(endpoint *v1.Endpoint, issuer string, certManagerAvailable bool) (*endpointIngressTLS, error) {
+	domain := endpoint.Spec.Domain
+
+	if issuer != "" && certManagerAvailable {
+		secretName := domain.TLSSecretName
+		if secretName == "" {
+			secretName = endpoint.Metadata.Name + "-tls"
+		}
+
+		return &endpointIngressTLS{Issuer: issuer, SecretName: secretName}, nil
+	}
+
+	if domain.TLSSecretName != "" {
+		return &endpointIngressTLS{SecretName: domain.TLSSecretName}, nil
+	}
+
+	if issuer != "" {
+		return nil, errors.Errorf("cert-manager issuer %s is not available, domain %s of endpoint %s requires a tls_secret_name",
+			issuer, domain.Host, endpoint.Metadata.WorkspaceName())
+	}
+
+	return nil, errors.Errorf("domain %s of endpoint %s requires a cert-manager issuer or a tls_secret_name",
+		domain.Host, endpoint.Metadata.WorkspaceName())
+}
+
+// certManagerIssuerAvailable reports whether cert-manager is installed in the cluster with the
+// given ClusterIssuer.
+func certManagerIssuerAvailable(ctrlClient client.Client, issuer string) (bool, error) {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("cert-manager.io/v1")
+	obj.SetKind("ClusterIssuer")
+
+	err := ctrlClient.Get(context.Background(), client.ObjectKey{Name: issuer}, obj)
+	if err == nil {
+		return true, nil
+	}
+
+	if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+		return false, nil
+	}
+
+	return false, errors.Wrapf(err, "failed to get cert-manager cluster issuer %s", issuer)
+}
+
+// endpointGatewayServiceName is the ExternalName Service the Ingress of the endpoint domain
+// forwards to the gateway through.
+func endpointGatewayServiceName(endpoint *v1.Endpoint) string {
+	return endpoint.Metadata.Name + "-gateway"
+}
+
+// buildEndpointGatewayService returns the ExternalName Service resolving to the gateway proxy.
+// The requests on the endpoint domain go through the gateway domain route of the endpoint, so
+// they are authenticated, authorized and accounted for like the requests on the endpoint route.
+func buildEndpointGatewayService(endpoint *v1.Endpoint, namespace string,
+	ingressConfig *v1.EndpointIngressConfig) (*unstructured.Unstructured, int32, error) {
+	if ingressConfig == nil || ingressConfig.GatewayURL == "" {
+		return nil, 0, errors.Errorf("domain %s of endpoint %s requires the endpoint_ingress gateway_url of the cluster",
+			endpoint.Spec.Domain.Host, endpoint.Metadata.WorkspaceName())
+	}
+
+	gatewayURL, err := url.Parse(ingressConfig.GatewayURL)
+	if err != nil || gatewayURL.Hostname() == "" {
+		return nil, 0, errors.Errorf("invalid endpoint_ingress gateway_url %q", ingressConfig.GatewayURL)
+	}
+
+	port := int32(80)
+	if gatewayURL.Scheme == "https" {
+		port = 443
+	}
+
+	if gatewayURL.Port() != "" {
+		p, err := strconv.ParseInt(gatewayURL.Port(), 10, 32)
+		if err != nil {
+			return nil, 0, errors.Errorf("invalid endpoint_ingress gateway_url %q", ingressConfig.GatewayURL)
+		}
+
+		port = int32(p)
+	}
+
+	service := &corev1.Service{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.String(),
+			Kind:       "Service",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      endpointGatewayServiceName(endpoint),
+			Namespace: namespace,
+		},
+		Spec: corev1.ServiceSpec{
+			Type:         corev1.ServiceTypeExternalName,
+			ExternalName: gatewayURL.Hostname(),
+			Ports:        []corev1.ServicePort{{Name: "http", Port: port}},
+		},
+	}
+
+	obj, err := util.ToUnstructured(service)
+	if err != nil {
+		return nil, 0, errors.Wrapf(err, "failed to convert gateway service of endpoint %s", endpoint.Metadata.WorkspaceName())
+	}
+
+	return obj, port, nil
+}
+
+// buildEndpointIngress returns the Ingress serving the endpoint on its custom domain. It
+// forwards to the gateway, keeping the host so the gateway domain route of the endpoint matches.
+func buildEndpointIngress(endpoint *v1.Endpoint, namespace string, ingressConfig *v1.EndpointIngressConfig,
+	tls *endpointIngressTLS, gatewayPort int32) (*unstructured.Unstructured, error) {
+	pathType := networkingv1.PathTypePrefix
+	ingress := &networkingv1.Ingress{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: networkingv1.SchemeGroupVersion.String(),
+			Kind:       "Ingress",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      endpoint.Metadata.Name,
+			Namespace: namespace,
+		},
+		Spec: networkingv1.IngressSpec{
+			TLS: []networkingv1.IngressTLS{{
+				Hosts:      []string{endpoint.Spec.Domain.Host},
+				SecretName: tls.SecretName,
+			}},
+			Rules: []networkingv1.IngressRule{{
+				Host: endpoint.Spec.Domain.Host,
+				IngressRuleValue: networkingv1.IngressRuleValue{
+					HTTP: &networkingv1.HTTPIngressRuleValue{
+						Paths: []networkingv1.HTTPIngressPath{{
+							Path:     "/",
+							PathType: &pathType,
+							Backend: networkingv1.IngressBackend{
+								Service: &networkingv1.IngressServiceBackend{
+									Name: endpointGatewayServiceName(endpoint),
+									Port: networkingv1.ServiceBackendPort{Number: gatewayPort},
+								},
+							},
+						}},
+					},
+				},
+			}},
+		},
+	}
+
+	if tls.Issuer != "" {
+		ingress.Annotations = map[string]string{certManagerClusterIssuerAnnotation: tls.Issuer}
+	}
+
+	if ingressConfig != nil && ingressConfig.ClassName != "" {
+		ingress.Spec.IngressClassName = &ingressConfig.ClassName
+	}
+
+	obj, err := util.ToUnstructured(ingress)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to convert ingress of endpoint %s", endpoint.Metadata.WorkspaceName())
+	}
+
+	return obj, nil
+}
+
+// addEndpointIngress adds the Ingress of the endpoint custom domain to the deployment objects.
+func addEndpointIngress(ctx *OrchestratorContext, objs *unstructured.UnstructuredList, namespace string,
+	ingressConfig *v1.EndpointIngressConfig) error {
+	endpoint := ctx.Endpoint
+	if endpoint.Spec.Domain == nil || endpoint.Spec.Domain.Host == "" {
+		return nil
+	}
+
+	issuer := endpointCertManagerIssuer(endpoint, ingressConfig)
+
+	available := false
+	if issuer != "" {
+		var err error
+
+		available, err = certManagerIssuerAvailable(ctx.ctrClient, issuer)
+		if err != nil {
+			return err
+		}
+	}
+
+	tls, err := resolveEndpointIngressTLS(endpoint, issuer, available)
+	if err != nil {
+		return err
+	}
+
+	gatewayService, gatewayPort, err := buildEndpointGatewayService(endpoint, namespace, ingressConfig)
+	if err != nil {
+		return err
+	}
+
+	ingress, err := buildEndpointIngress(endpoint, namespace, ingressConfig, tls, gatewayPort)
+	if err != nil {
+		return err
+	}
+
+	objs.Items = append(objs.Items, *gatewayService, *ingress)
+
+	return nil
+}
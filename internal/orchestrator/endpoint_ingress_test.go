@@ -0,0 +1,166 @@
+package orchestrator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.openly.dev/pointy"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	v1 "github.com/neutree-ai/neutree/api/v1"
+)
+
+func TestBuildEndpointIngress(t *testing.T) {
+	newEndpoint := func(domain *v1.EndpointDomainSpec) *v1.Endpoint {
+		return &v1.Endpoint{
+			Metadata: &v1.Metadata{Name: "chat", Workspace: "default"},
+			Spec:     &v1.EndpointSpec{Domain: domain},
+		}
+	}
+
+	tests := []struct {
+		name                 string
+		domain               *v1.EndpointDomainSpec
+		ingressConfig        *v1.EndpointIngressConfig
+		certManagerAvailable bool
+		expectedAnnotations  map[string]string
+		expectedSecret       string
+		expectedClass        *string
+		expectErr            string
+	}{
+		{
+			name:                 "cluster issuer provisions the certificate",
+			domain:               &v1.EndpointDomainSpec{Host: "chat.example.com"},
+			ingressConfig:        &v1.EndpointIngressConfig{ClassName: "nginx", CertManagerIssuer: "letsencrypt"},
+			certManagerAvailable: true,
+			expectedAnnotations:  map[string]string{"cert-manager.io/cluster-issuer": "letsencrypt"},
+			expectedSecret:       "chat-tls",
+			expectedClass:        pointy.String("nginx"),
+		},
+		{
+			name:                 "endpoint issuer and secret take precedence",
+			domain:               &v1.EndpointDomainSpec{Host: "chat.example.com", CertManagerIssuer: "internal-ca", TLSSecretName: "chat-cert"},
+			ingressConfig:        &v1.EndpointIngressConfig{CertManagerIssuer: "letsencrypt"},
+			certManagerAvailable: true,
+			expectedAnnotations:  map[string]string{"cert-manager.io/cluster-issuer": "internal-ca"},
+			expectedSecret:       "chat-cert",
+		},
+		{
+			name:           "provided secret without issuer",
+			domain:         &v1.EndpointDomainSpec{Host: "chat.example.com", TLSSecretName: "chat-cert"},
+			expectedSecret: "chat-cert",
+		},
+		{
+			name:           "provided secret when cert-manager is not available",
+			domain:         &v1.EndpointDomainSpec{Host: "chat.example.com", TLSSecretName: "chat-cert"},
+			ingressConfig:  &v1.EndpointIngressConfig{CertManagerIssuer: "letsencrypt"},
+			expectedSecret: "chat-cert",
+		},
+		{
+			name:          "cert-manager not available without secret",
+			domain:        &v1.EndpointDomainSpec{Host: "chat.example.com"},
+			ingressConfig: &v1.EndpointIngressConfig{CertManagerIssuer: "letsencrypt"},
+			expectErr:     "cert-manager issuer letsencrypt is not available, domain chat.example.com of endpoint default/chat requires a tls_secret_name",
+		},
+		{
+			name:      "no issuer nor secret",
+			domain:    &v1.EndpointDomainSpec{Host: "chat.example.com"},
+			expectErr: "domain chat.example.com of endpoint default/chat requires a cert-manager issuer or a tls_secret_name",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endpoint := newEndpoint(tt.domain)
+
+			issuer := endpointCertManagerIssuer(endpoint, tt.ingressConfig)
+
+			tls, err := resolveEndpointIngressTLS(endpoint, issuer, tt.certManagerAvailable)
+			if tt.expectErr != "" {
+				require.EqualError(t, err, tt.expectErr)
+				return
+			}
+
+			require.NoError(t, err)
+
+			obj, err := buildEndpointIngress(endpoint, "neutree-cluster", tt.ingressConfig, tls, 8000)
+			require.NoError(t, err)
+
+			ingress := &networkingv1.Ingress{}
+			require.NoError(t, runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, ingress))
+
+			assert.Equal(t, "Ingress", obj.GetKind())
+			assert.Equal(t, "chat", ingress.Name)
+			assert.Equal(t, "neutree-cluster", ingress.Namespace)
+			assert.Equal(t, tt.expectedAnnotations, ingress.Annotations)
+			assert.Equal(t, tt.expectedClass, ingress.Spec.IngressClassName)
+
+			require.Len(t, ingress.Spec.TLS, 1)
+			assert.Equal(t, []string{"chat.example.com"}, ingress.Spec.TLS[0].Hosts)
+			assert.Equal(t, tt.expectedSecret, ingress.Spec.TLS[0].SecretName)
+
+			require.Len(t, ingress.Spec.Rules, 1)
+			assert.Equal(t, "chat.example.com", ingress.Spec.Rules[0].Host)
+			backend := ingress.Spec.Rules[0].HTTP.Paths[0].Backend.Service
+			assert.Equal(t, "chat-gateway", backend.Name)
+			assert.Equal(t, int32(8000), backend.Port.Number)
+		})
+	}
+}
+
+func TestBuildEndpointGatewayService(t *testing.T) {
+	endpoint := &v1.Endpoint{
+		Metadata: &v1.Metadata{Name: "chat", Workspace: "default"},
+		Spec:     &v1.EndpointSpec{Domain: &v1.EndpointDomainSpec{Host: "chat.example.com"}},
+	}
+
+	tests := []struct {
+		name         string
+		gatewayURL   string
+		expectedHost string
+		expectedPort int32
+		expectErr    string
+	}{
+		{
+			name:         "gateway url with port",
+			gatewayURL:   "http://kong-proxy.neutree.svc:8000",
+			expectedHost: "kong-proxy.neutree.svc",
+			expectedPort: 8000,
+		},
+		{
+			name:         "https gateway url",
+			gatewayURL:   "https://gateway.example.com",
+			expectedHost: "gateway.example.com",
+			expectedPort: 443,
+		},
+		{
+			name:      "no gateway url",
+			expectErr: "domain chat.example.com of endpoint default/chat requires the endpoint_ingress gateway_url of the cluster",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj, port, err := buildEndpointGatewayService(endpoint, "neutree-cluster",
+				&v1.EndpointIngressConfig{GatewayURL: tt.gatewayURL})
+			if tt.expectErr != "" {
+				require.EqualError(t, err, tt.expectErr)
+				return
+			}
+
+			require.NoError(t, err)
+
+			service := &corev1.Service{}
+			require.NoError(t, runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, service))
+
+			assert.Equal(t, "chat-gateway", service.Name)
+			assert.Equal(t, corev1.ServiceTypeExternalName, service.Spec.Type)
+			assert.Equal(t, tt.expectedHost, service.Spec.ExternalName)
+			assert.Equal(t, tt.expectedPort, port)
+			assert.Equal(t, tt.expectedPort, service.Spec.Ports[0].Port)
+		})
+	}
+}
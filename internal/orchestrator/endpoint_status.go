@@ -0,0 +1,232 @@
+package orchestrator
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	v1 "github.com/neutree-ai/neutree/api/v1"
+)
+
+// endpointPhaseSeverity orders the phases an endpoint status signal can report from the
+// healthiest to the most pessimistic, phases not listed are never overridden by a merge.
+var endpointPhaseSeverity = map[v1.EndpointPhase]int{
+	v1.EndpointPhaseRUNNING:          0,
+	v1.EndpointPhaseDEGRADED:         1,
+	v1.EndpointPhaseMODELDOWNLOADING: 2,
+	v1.EndpointPhaseDEPLOYING:        2,
+	v1.EndpointPhaseFAILED:           3,
+}
+
+// mergeEndpointStatus reconciles the statuses reported by several signal sources of the
+// same endpoint, e.g. the serving layer and the workload running it, into the most
+// pessimistic one, keeping the reason of the source that reported it. The first status
+// wins ties and provides the resources if the winner has none. Nil statuses are sources
+// without an opinion.
+func mergeEndpointStatus(statuses ...*v1.EndpointStatus) *v1.EndpointStatus {
+	var merged, first *v1.EndpointStatus
+
+	for _, status := range statuses {
+		if status == nil {
+			continue
+		}
+
+		if first == nil {
+			first = status
+		}
+
+		if merged == nil {
+			merged = status
+			continue
+		}
+
+		severity, ok := endpointPhaseSeverity[status.Phase]
+		mergedSeverity, mergedOK := endpointPhaseSeverity[merged.Phase]
+
+		if ok && mergedOK && severity > mergedSeverity {
+			merged = status
+		}
+	}
+
+	if merged == nil || merged == first || merged.Resources != nil || first.Resources == nil {
+		return merged
+	}
+
+	result := *merged
+	result.Resources = first.Resources
+
+	return &result
+}
+
+// podWorkloadStatus returns the status the pods of an endpoint report for themselves,
+// regardless of what the deployment or serving layer above them reports: Failed when every
+// pod is crashing, Degraded when only some are, and nil when none is.
+func podWorkloadStatus(pods []corev1.Pod) *v1.EndpointStatus {
+	var (
+		active, crashing int
+		reasons          []string
+	)
+
+	for _, pod := range pods {
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+
+		active++
+
+		if podReasons := podCrashReasons(pod); len(podReasons) > 0 {
+			crashing++
+
+			reasons = append(reasons, podReasons...)
+		}
+	}
+
+	if crashing == 0 {
+		return nil
+	}
+
+	if crashing == active {
+		return &v1.EndpointStatus{
+			Phase:        v1.EndpointPhaseFAILED,
+			ErrorMessage: v1.EndpointWorkloadFailedMessagePrefix + strings.Join(reasons, "; "),
+		}
+	}
+
+	return &v1.EndpointStatus{
+		Phase: v1.EndpointPhaseDEGRADED,
+		ErrorMessage: fmt.Sprintf("Endpoint degraded: %d/%d pods unhealthy: %s",
+			crashing, active, strings.Join(reasons, "; ")),
+	}
+}
+
+// rolloutFailureStatus returns the status of a deployment whose pods failed before it became
+// ready. Replicas of the previous rollout that are still ready keep the endpoint serving, so it
+// is only degraded, the endpoint fails once no replica is ready.
+func rolloutFailureStatus(dep *appsv1.Deployment, message string, resources *v1.EndpointResourceStatus) *v1.EndpointStatus {
+	if dep.Status.ReadyReplicas > 0 && dep.Spec.Replicas != nil {
+		return &v1.EndpointStatus{
+			Phase: v1.EndpointPhaseDEGRADED,
+			ErrorMessage: fmt.Sprintf("Endpoint degraded: %d/%d replicas ready: %s",
+				dep.Status.ReadyReplicas, *dep.Spec.Replicas, message),
+			Resources: resources,
+		}
+	}
+
+	return &v1.EndpointStatus{
+		Phase:        v1.EndpointPhaseFAILED,
+		ErrorMessage: v1.EndpointWorkloadFailedMessagePrefix + message,
+		Resources:    resources,
+	}
+}
+
+// podCrashReasons describes the containers of the pod that are crash looping or were
+// killed for running out of memory, at any restart count.
+func podCrashReasons(pod corev1.Pod) []string {
+	var reasons []string
+
+	for _, cs := range pod.Status.ContainerStatuses {
+		switch {
+		case cs.State.Waiting != nil && cs.State.Waiting.Reason == k8sContainerReasonCrashLoopBackOff:
+			reasons = append(reasons, fmt.Sprintf("Pod '%s' Container '%s' in CrashLoopBackOff (restarted %d times): %s",
+				pod.Name, cs.Name, cs.RestartCount, cs.State.Waiting.Message))
+		case cs.State.Terminated != nil && cs.State.Terminated.Reason == k8sContainerReasonOOMKilled:
+			reasons = append(reasons, fmt.Sprintf("Pod '%s' Container '%s' was killed due to OOM (Out of Memory)",
+				pod.Name, cs.Name))
+		}
+	}
+
+	return reasons
+}
+
+// podReplicaStatuses returns the status of the pods of an endpoint as its replicas, ordered
+// by pod name.
+func podReplicaStatuses(pods []corev1.Pod) []v1.ReplicaStatus {
+	replicas := make([]v1.ReplicaStatus, 0, len(pods))
+	for _, pod := range pods {
+		replicas = append(replicas, podReplicaStatus(pod))
+	}
+
+	slices.SortFunc(replicas, func(a, b v1.ReplicaStatus) int {
+		return strings.Compare(a.ReplicaID, b.ReplicaID)
+	})
+
+	return replicas
+}
+
+// podReplicaStatus maps the pod onto the Ray Serve replica states: a ready pod is running, a
+// pod whose containers failed and are restarted is recovering, and one not ready yet is
+// starting. The error message is the reason of the failing container, or of the pod not
+// being scheduled.
+func podReplicaStatus(pod corev1.Pod) v1.ReplicaStatus {
+	status := v1.ReplicaStatus{ReplicaID: pod.Name, State: v1.ReplicaStateStarting}
+
+	switch {
+	case pod.DeletionTimestamp != nil:
+		status.State = v1.ReplicaStateStopping
+		return status
+	case isPodReady(pod):
+		status.State = v1.ReplicaStateRunning
+		return status
+	}
+
+	for _, statuses := range [][]corev1.ContainerStatus{pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses} {
+		for _, cs := range statuses {
+			if message := containerErrorMessage(cs); message != "" {
+				if cs.RestartCount > 0 {
+					status.State = v1.ReplicaStateRecovering
+				}
+
+				status.ErrorMessage = message
+
+				return status
+			}
+		}
+	}
+
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodScheduled && cond.Status == corev1.ConditionFalse {
+			status.ErrorMessage = fmt.Sprintf("Pod not scheduled: %s", cond.Message)
+		}
+	}
+
+	return status
+}
+
+func isPodReady(pod corev1.Pod) bool {
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodReady {
+			return cond.Status == corev1.ConditionTrue
+		}
+	}
+
+	return false
+}
+
+// containerErrorMessage describes why the container is waiting to be restarted or was last
+// terminated with an error, empty if it did not fail.
+func containerErrorMessage(cs corev1.ContainerStatus) string {
+	if cs.State.Waiting != nil && cs.RestartCount > 0 {
+		if terminated := cs.LastTerminationState.Terminated; terminated != nil {
+			return fmt.Sprintf("Container '%s' %s (restarted %d times): last terminated with %s, exit code %d",
+				cs.Name, cs.State.Waiting.Reason, cs.RestartCount, terminated.Reason, terminated.ExitCode)
+		}
+
+		return fmt.Sprintf("Container '%s' %s (restarted %d times): %s",
+			cs.Name, cs.State.Waiting.Reason, cs.RestartCount, cs.State.Waiting.Message)
+	}
+
+	// A container is waiting while it is created, any other reason is an error, e.g. ImagePullBackOff.
+	if waiting := cs.State.Waiting; waiting != nil && waiting.Reason != "" &&
+		waiting.Reason != k8sContainerReasonPodInitializing && waiting.Reason != k8sContainerReasonContainerCreating {
+		return fmt.Sprintf("Container '%s' %s: %s", cs.Name, cs.State.Waiting.Reason, cs.State.Waiting.Message)
+	}
+
+	if terminated := cs.State.Terminated; terminated != nil && terminated.ExitCode != 0 {
+		return fmt.Sprintf("Container '%s' terminated with %s, exit code %d", cs.Name, terminated.Reason, terminated.ExitCode)
+	}
+
+	return ""
+}
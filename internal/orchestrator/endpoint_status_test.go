@@ -0,0 +1,148 @@
+package orchestrator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "github.com/neutree-ai/neutree/api/v1"
+)
+
+func TestMergeEndpointStatus(t *testing.T) {
+	resources := &v1.EndpointResourceStatus{}
+
+	running := &v1.EndpointStatus{Phase: v1.EndpointPhaseRUNNING, Resources: resources}
+	degraded := &v1.EndpointStatus{Phase: v1.EndpointPhaseDEGRADED, ErrorMessage: "Endpoint degraded: 1/2 pods unhealthy"}
+	failed := &v1.EndpointStatus{Phase: v1.EndpointPhaseFAILED, ErrorMessage: "Endpoint failed: pods crashing"}
+	deploying := &v1.EndpointStatus{Phase: v1.EndpointPhaseDEPLOYING, ErrorMessage: "Endpoint deploying in progress"}
+	paused := &v1.EndpointStatus{Phase: v1.EndpointPhasePAUSED}
+
+	tests := []struct {
+		name     string
+		statuses []*v1.EndpointStatus
+		expect   *v1.EndpointStatus
+	}{
+		{
+			name:     "no signal",
+			statuses: []*v1.EndpointStatus{nil, nil},
+		},
+		{
+			name:     "signal without an opinion",
+			statuses: []*v1.EndpointStatus{running, nil},
+			expect:   running,
+		},
+		{
+			name:     "serving healthy but pods crashing",
+			statuses: []*v1.EndpointStatus{running, degraded},
+			expect: &v1.EndpointStatus{
+				Phase:        v1.EndpointPhaseDEGRADED,
+				ErrorMessage: "Endpoint degraded: 1/2 pods unhealthy",
+				Resources:    resources,
+			},
+		},
+		{
+			name:     "most pessimistic signal wins",
+			statuses: []*v1.EndpointStatus{degraded, failed, deploying},
+			expect:   failed,
+		},
+		{
+			name:     "first signal wins ties",
+			statuses: []*v1.EndpointStatus{deploying, {Phase: v1.EndpointPhaseMODELDOWNLOADING}},
+			expect:   deploying,
+		},
+		{
+			name:     "unordered phases are kept",
+			statuses: []*v1.EndpointStatus{paused, failed},
+			expect:   paused,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expect, mergeEndpointStatus(tt.statuses...))
+		})
+	}
+}
+
+func TestPodReplicaStatuses(t *testing.T) {
+	ready := []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+	notReady := []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionFalse}}
+	now := metav1.Now()
+
+	pods := []corev1.Pod{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "ep-d"},
+			Status: corev1.PodStatus{Conditions: notReady, ContainerStatuses: []corev1.ContainerStatus{{
+				Name:         "vllm",
+				RestartCount: 3,
+				State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{
+					Reason: k8sContainerReasonCrashLoopBackOff, Message: "back-off 40s restarting failed container",
+				}},
+				LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+					Reason: k8sContainerReasonOOMKilled, ExitCode: 137,
+				}},
+			}}},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "ep-a"},
+			Status:     corev1.PodStatus{Conditions: ready},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "ep-b"},
+			Status: corev1.PodStatus{
+				Conditions: notReady,
+				InitContainerStatuses: []corev1.ContainerStatus{{
+					Name: "model-downloader",
+					State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{
+						Reason: k8sContainerReasonImagePullBackOff, Message: "image not found",
+					}},
+				}},
+				ContainerStatuses: []corev1.ContainerStatus{{
+					Name:  "vllm",
+					State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: k8sContainerReasonPodInitializing}},
+				}},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "ep-c"},
+			Status: corev1.PodStatus{Conditions: []corev1.PodCondition{{
+				Type: corev1.PodScheduled, Status: corev1.ConditionFalse, Reason: "Unschedulable",
+				Message: "0/3 nodes are available: 3 Insufficient nvidia.com/gpu.",
+			}}},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "ep-e"},
+			Status: corev1.PodStatus{Conditions: notReady, ContainerStatuses: []corev1.ContainerStatus{{
+				Name:  "vllm",
+				State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: k8sContainerReasonContainerCreating}},
+			}}},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "ep-f", DeletionTimestamp: &now},
+			Status:     corev1.PodStatus{Conditions: ready},
+		},
+	}
+
+	assert.Equal(t, []v1.ReplicaStatus{
+		{ReplicaID: "ep-a", State: v1.ReplicaStateRunning},
+		{
+			ReplicaID:    "ep-b",
+			State:        v1.ReplicaStateStarting,
+			ErrorMessage: "Container 'model-downloader' ImagePullBackOff: image not found",
+		},
+		{
+			ReplicaID:    "ep-c",
+			State:        v1.ReplicaStateStarting,
+			ErrorMessage: "Pod not scheduled: 0/3 nodes are available: 3 Insufficient nvidia.com/gpu.",
+		},
+		{
+			ReplicaID:    "ep-d",
+			State:        v1.ReplicaStateRecovering,
+			ErrorMessage: "Container 'vllm' CrashLoopBackOff (restarted 3 times): last terminated with OOMKilled, exit code 137",
+		},
+		{ReplicaID: "ep-e", State: v1.ReplicaStateStarting},
+		{ReplicaID: "ep-f", State: v1.ReplicaStateStopping},
+	}, podReplicaStatuses(pods))
+}
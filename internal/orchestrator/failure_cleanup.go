@@ -0,0 +1,106 @@
+package orchestrator
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+
+	v1 "github.com/neutree-ai/neutree/api/v1"
+)
+
+// FailureCleanupPolicy is what happens to the workload of an endpoint whose deployment
+// failed, see deploymentOptionFailureCleanup.
+type FailureCleanupPolicy string
+
+const (
+	FailureCleanupNone        FailureCleanupPolicy = "none"
+	FailureCleanupScaleToZero FailureCleanupPolicy = "scale_to_zero"
+	FailureCleanupTeardown    FailureCleanupPolicy = "teardown"
+)
+
+// GetFailureCleanupPolicy parses deployment_options.failure_cleanup of the endpoint, failed
+// deployments are left in place by default.
+func GetFailureCleanupPolicy(endpoint *v1.Endpoint) (FailureCleanupPolicy, error) {
+	if endpoint.Spec == nil || endpoint.Spec.DeploymentOptions == nil || endpoint.Spec.DeploymentOptions[deploymentOptionFailureCleanup] == nil {
+		return FailureCleanupNone, nil
+	}
+
+	policy, _ := endpoint.Spec.DeploymentOptions[deploymentOptionFailureCleanup].(string)
+
+	switch FailureCleanupPolicy(policy) {
+	case FailureCleanupNone, FailureCleanupScaleToZero, FailureCleanupTeardown:
+		return FailureCleanupPolicy(policy), nil
+	default:
+		return "", errors.Errorf("deployment_options.%s must be one of %s, %s, %s", deploymentOptionFailureCleanup,
+			FailureCleanupNone, FailureCleanupScaleToZero, FailureCleanupTeardown)
+	}
+}
+
+// CleanupFailedEndpoint cleans up the workload of an endpoint reporting no healthy replica as
+// its failure cleanup policy asks, and returns the failed status recording the cleanup. Replicas
+// restarting or still starting up may recover, so the workload is only cleaned up once the
+// endpoint reported no healthy replica for longer than its startup window. It returns nil when
+// the policy leaves failed deployments in place or the replicas may still recover.
+func CleanupFailedEndpoint(o Orchestrator, endpoint *v1.Endpoint, status *v1.EndpointStatus, now time.Time) (*v1.EndpointStatus, error) {
+	policy, err := GetFailureCleanupPolicy(endpoint)
+	if err != nil || policy == FailureCleanupNone {
+		return nil, err
+	}
+
+	recovering, err := mayStillRecover(endpoint, status, now)
+	if err != nil || recovering {
+		return nil, err
+	}
+
+	specHash, err := ComputeEndpointSpecHash(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	action := "scaled to zero"
+
+	if policy == FailureCleanupTeardown {
+		action = "torn down"
+		err = o.DeleteEndpoint(endpoint)
+	} else {
+		err = o.PauseEndpoint(endpoint)
+	}
+
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to clean up failed deployment of endpoint %s", endpoint.Metadata.WorkspaceName())
+	}
+
+	cleaned := *status
+	cleaned.ErrorMessage += "; failed deployment " + action + ", update the endpoint to redeploy it"
+	cleaned.FailureCleanupSpecHash = specHash
+
+	return &cleaned, nil
+}
+
+// mayStillRecover reports whether the endpoint reported no healthy replica for less than its
+// startup window, a status not telling since when counts as just failed.
+func mayStillRecover(endpoint *v1.Endpoint, status *v1.EndpointStatus, now time.Time) (bool, error) {
+	since, err := time.Parse(time.RFC3339Nano, status.NoHealthyReplicasSince)
+	if err != nil {
+		return true, nil
+	}
+
+	seconds, err := startupTimeoutSeconds(endpoint)
+	if err != nil {
+		return false, err
+	}
+
+	return now.Sub(since) < time.Duration(seconds)*time.Second, nil
+}
+
+// IsFailedEndpointCleanedUp reports whether the failed deployment of the endpoint was cleaned
+// up and its spec did not change since, so it must not be redeployed.
+func IsFailedEndpointCleanedUp(endpoint *v1.Endpoint) bool {
+	if endpoint.Status == nil || endpoint.Status.Phase != v1.EndpointPhaseFAILED || endpoint.Status.FailureCleanupSpecHash == "" {
+		return false
+	}
+
+	specHash, err := ComputeEndpointSpecHash(endpoint)
+
+	return err == nil && specHash == endpoint.Status.FailureCleanupSpecHash
+}
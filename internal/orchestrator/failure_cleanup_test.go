@@ -0,0 +1,168 @@
+package orchestrator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"k8s.io/utils/pointer"
+
+	v1 "github.com/neutree-ai/neutree/api/v1"
+	orchestratormocks "github.com/neutree-ai/neutree/internal/orchestrator/mocks"
+)
+
+func TestGetFailureCleanupPolicy(t *testing.T) {
+	tests := []struct {
+		name         string
+		option       interface{}
+		expectPolicy FailureCleanupPolicy
+		expectError  string
+	}{
+		{name: "not configured", expectPolicy: FailureCleanupNone},
+		{name: "scale to zero", option: "scale_to_zero", expectPolicy: FailureCleanupScaleToZero},
+		{name: "teardown", option: "teardown", expectPolicy: FailureCleanupTeardown},
+		{name: "unknown policy", option: "delete", expectError: "deployment_options.failure_cleanup must be one of none, scale_to_zero, teardown"},
+		{name: "not a string", option: true, expectError: "deployment_options.failure_cleanup must be one of none, scale_to_zero, teardown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endpoint := &v1.Endpoint{Spec: &v1.EndpointSpec{DeploymentOptions: map[string]interface{}{}}}
+			if tt.option != nil {
+				endpoint.Spec.DeploymentOptions["failure_cleanup"] = tt.option
+			}
+
+			policy, err := GetFailureCleanupPolicy(endpoint)
+			if tt.expectError != "" {
+				assert.EqualError(t, err, tt.expectError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectPolicy, policy)
+		})
+	}
+}
+
+func TestCleanupFailedEndpoint(t *testing.T) {
+	now := time.Now()
+	failed := &v1.EndpointStatus{
+		Phase:                  v1.EndpointPhaseFAILED,
+		ErrorMessage:           v1.EndpointWorkloadFailedMessagePrefix + "scheduling timed out after 600s on pod 'chat-0'",
+		NoHealthyReplicasSince: now.Add(-time.Duration(defaultStartupSeconds) * time.Second).Format(time.RFC3339Nano),
+	}
+
+	tests := []struct {
+		name          string
+		policy        string
+		expectCall    string
+		expectMessage string
+	}{
+		{
+			name: "failed deployments left in place",
+		},
+		{
+			name:          "scale to zero",
+			policy:        "scale_to_zero",
+			expectCall:    "PauseEndpoint",
+			expectMessage: failed.ErrorMessage + "; failed deployment scaled to zero, update the endpoint to redeploy it",
+		},
+		{
+			name:          "teardown",
+			policy:        "teardown",
+			expectCall:    "DeleteEndpoint",
+			expectMessage: failed.ErrorMessage + "; failed deployment torn down, update the endpoint to redeploy it",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := &orchestratormocks.MockOrchestrator{}
+			o.On("PauseEndpoint", mock.Anything).Return(nil)
+			o.On("DeleteEndpoint", mock.Anything).Return(nil)
+
+			endpoint := &v1.Endpoint{
+				Metadata: &v1.Metadata{Workspace: "default", Name: "chat"},
+				Spec:     &v1.EndpointSpec{DeploymentOptions: map[string]interface{}{}},
+			}
+			if tt.policy != "" {
+				endpoint.Spec.DeploymentOptions["failure_cleanup"] = tt.policy
+			}
+
+			status, err := CleanupFailedEndpoint(o, endpoint, failed, now)
+			require.NoError(t, err)
+
+			if tt.expectCall == "" {
+				assert.Nil(t, status)
+				o.AssertNotCalled(t, "PauseEndpoint", mock.Anything)
+				o.AssertNotCalled(t, "DeleteEndpoint", mock.Anything)
+
+				return
+			}
+
+			o.AssertCalled(t, tt.expectCall, endpoint)
+			require.NotNil(t, status)
+			assert.Equal(t, v1.EndpointPhaseFAILED, status.Phase)
+			assert.Equal(t, tt.expectMessage, status.ErrorMessage)
+			assert.True(t, status.HasNoHealthyReplicas())
+
+			// The failed endpoint is not redeployed until its spec changes.
+			endpoint.Status = status
+			assert.True(t, IsFailedEndpointCleanedUp(endpoint))
+
+			endpoint.Spec.Replicas.Num = pointer.Int(2)
+			assert.False(t, IsFailedEndpointCleanedUp(endpoint))
+		})
+	}
+}
+
+func TestCleanupFailedEndpoint_WaitsForRecovery(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name          string
+		since         string
+		startup       interface{}
+		expectCleanup bool
+	}{
+		{name: "just failed", since: now.Format(time.RFC3339Nano)},
+		{name: "unknown since when", since: ""},
+		{name: "within the startup window", since: now.Add(-10 * time.Minute).Format(time.RFC3339Nano)},
+		{name: "startup window over", since: now.Add(-30 * time.Minute).Format(time.RFC3339Nano), expectCleanup: true},
+		{name: "within the configured startup timeout", since: now.Add(-30 * time.Minute).Format(time.RFC3339Nano), startup: 3600},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := &orchestratormocks.MockOrchestrator{}
+			o.On("PauseEndpoint", mock.Anything).Return(nil)
+
+			endpoint := &v1.Endpoint{
+				Metadata: &v1.Metadata{Workspace: "default", Name: "chat"},
+				Spec:     &v1.EndpointSpec{DeploymentOptions: map[string]interface{}{"failure_cleanup": "scale_to_zero"}},
+			}
+			if tt.startup != nil {
+				endpoint.Spec.DeploymentOptions["startup_timeout_seconds"] = tt.startup
+			}
+
+			status, err := CleanupFailedEndpoint(o, endpoint, &v1.EndpointStatus{
+				Phase:                  v1.EndpointPhaseFAILED,
+				ErrorMessage:           v1.EndpointWorkloadFailedMessagePrefix + "container crash looping",
+				NoHealthyReplicasSince: tt.since,
+			}, now)
+			require.NoError(t, err)
+
+			if !tt.expectCleanup {
+				assert.Nil(t, status)
+				o.AssertNotCalled(t, "PauseEndpoint", mock.Anything)
+
+				return
+			}
+
+			require.NotNil(t, status)
+			o.AssertCalled(t, "PauseEndpoint", endpoint)
+		})
+	}
+}
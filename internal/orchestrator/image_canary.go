@@ -0,0 +1,277 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/neutree-ai/neutree/internal/util"
+)
+
+// canaryApp labels the pods of an image canary instead of inference, the router only routes
+// to inference pods so the canary serves no traffic.
+const canaryApp = "inference-canary"
+
+const (
+	// imageCanaryStartedAtAnnotation records when the canary started running its image.
+	imageCanaryStartedAtAnnotation = "neutree.ai/image-canary-started-at"
+	// imageCanaryFailureAnnotation records why the canary of its image failed. The failed
+	// canary is scaled to zero and kept, so the failure is reported until the image changes.
+	imageCanaryFailureAnnotation = "neutree.ai/image-canary-failure"
+
+	// imageCanaryPullSeconds is how long the canary may take to be scheduled and pull its
+	// image, on top of the startup window of the endpoint.
+	imageCanaryPullSeconds = 1800
+)
+
+// imageCanaryName returns the name of the Deployment running the image canary of the endpoint.
+func imageCanaryName(endpointName string) string {
+	return endpointName + "-canary"
+}
+
+// checkImageCanary runs the engine image of the rendered workload in a single canary replica
+// before it replaces the image of the existing workload. It returns whether the workload can
+// be applied: the endpoint has no canary, it is deployed the first time, its engine image is
+// unchanged or the canary of the new image is ready. A canary failing to pull or start its
+// image, or not ready in time, aborts the rollout: its pod is deleted and the replicas keep
+// running the previous image.
+func (k *kubernetesOrchestrator) checkImageCanary(ctx *OrchestratorContext, namespace string,
+	objects *unstructured.UnstructuredList, existing client.Object) (bool, error) {
+	enabled, err := getImageCanary(ctx.Endpoint)
+	if err != nil || !enabled {
+		return err == nil, err
+	}
+
+	template, err := renderedPodTemplate(objects)
+	if err != nil {
+		return false, err
+	}
+
+	if existing == nil || engineImage(existingPodTemplate(existing)) == engineImage(template) {
+		// a canary left by an image reverted before its rollout is not needed anymore
+		return true, k.deleteImageCanary(ctx, namespace)
+	}
+
+	desired := buildImageCanary(ctx.Endpoint.Metadata.Name, namespace, template, time.Now())
+	image := engineImage(template)
+
+	canary := &appsv1.Deployment{}
+	if err := ctx.ctrClient.Get(context.Background(), client.ObjectKeyFromObject(desired), canary); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return false, errors.Wrap(err, "failed to get image canary")
+		}
+
+		ctx.logger.Info("Starting image canary before rollout", "image", image)
+
+		return false, errors.Wrap(ctx.ctrClient.Create(context.Background(), desired), "failed to create image canary")
+	}
+
+	if engineImage(&canary.Spec.Template) != image {
+		ctx.logger.Info("Restarting image canary with the new image", "image", image)
+
+		canary.Annotations = maps.Clone(canary.Annotations)
+		if canary.Annotations == nil {
+			canary.Annotations = map[string]string{}
+		}
+
+		delete(canary.Annotations, imageCanaryFailureAnnotation)
+		maps.Copy(canary.Annotations, desired.Annotations)
+
+		canary.Spec.Replicas = desired.Spec.Replicas
+		canary.Spec.Template = desired.Spec.Template
+
+		return false, errors.Wrap(ctx.ctrClient.Update(context.Background(), canary), "failed to update image canary")
+	}
+
+	if reason := canary.Annotations[imageCanaryFailureAnnotation]; reason != "" {
+		return false, errors.Errorf("image canary of %s failed, rollout aborted: %s", image, reason)
+	}
+
+	if util.IsDeploymentUpdatedAndReady(canary) {
+		ctx.logger.Info("Image canary is ready, rolling out", "image", image)
+		return true, k.deleteImageCanary(ctx, namespace)
+	}
+
+	pods, err := k.listPods(ctx.ctrClient, namespace, canary.Spec.Selector.MatchLabels)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to list image canary pods")
+	}
+
+	failed, reason := checkImageCanaryPods(pods)
+	if !failed {
+		failed, reason, err = imageCanaryTimedOut(ctx, canary, time.Now())
+		if err != nil {
+			return false, err
+		}
+	}
+
+	if failed {
+		if err := failImageCanary(ctx, canary, reason); err != nil {
+			return false, err
+		}
+
+		return false, errors.Errorf("image canary of %s failed, rollout aborted: %s", image, reason)
+	}
+
+	ctx.logger.V(4).Info("Waiting for image canary before rollout", "image", image)
+
+	return false, nil
+}
+
+// imageCanaryTimedOut reports whether the canary was not ready within the startup window of
+// the endpoint plus the time to schedule it and pull its image.
+func imageCanaryTimedOut(ctx *OrchestratorContext, canary *appsv1.Deployment, now time.Time) (bool, string, error) {
+	startedAt, err := time.Parse(time.RFC3339, canary.Annotations[imageCanaryStartedAtAnnotation])
+	if err != nil {
+		startedAt = canary.CreationTimestamp.Time
+	}
+
+	startupSeconds, err := startupTimeoutSeconds(ctx.Endpoint)
+	if err != nil {
+		return false, "", err
+	}
+
+	timeout := time.Duration(startupSeconds+imageCanaryPullSeconds) * time.Second
+	if startedAt.IsZero() || now.Sub(startedAt) < timeout {
+		return false, "", nil
+	}
+
+	return true, fmt.Sprintf("not ready after %ds", int(timeout.Seconds())), nil
+}
+
+// failImageCanary records the failure of the canary and scales it to zero, which deletes its
+// pod. The canary is kept to report the failure until the image changes.
+func failImageCanary(ctx *OrchestratorContext, canary *appsv1.Deployment, reason string) error {
+	canary.Annotations = maps.Clone(canary.Annotations)
+	if canary.Annotations == nil {
+		canary.Annotations = map[string]string{}
+	}
+
+	canary.Annotations[imageCanaryFailureAnnotation] = reason
+	canary.Spec.Replicas = pointer.Int32(0)
+
+	return errors.Wrap(ctx.ctrClient.Update(context.Background(), canary), "failed to stop the failed image canary")
+}
+
+// deleteImageCanary deletes the image canary of the endpoint, if any.
+func (k *kubernetesOrchestrator) deleteImageCanary(ctx *OrchestratorContext, namespace string) error {
+	canary := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: imageCanaryName(ctx.Endpoint.Metadata.Name), Namespace: namespace}}
+
+	err := ctx.ctrClient.Delete(context.Background(), canary, client.PropagationPolicy(metav1.DeletePropagationBackground))
+	if err != nil && !apierrors.IsNotFound(err) {
+		return errors.Wrap(err, "failed to delete image canary")
+	}
+
+	return nil
+}
+
+// checkImageCanaryPods reports whether a container of the canary failed to pull or start its
+// image. An unschedulable canary waits for room in the cluster, it says nothing of the image.
+func checkImageCanaryPods(pods []corev1.Pod) (bool, string) {
+	failed := false
+
+	var reasons []string
+
+	for _, pod := range pods {
+		if f, msgs := checkContainerStatuses(pod.Name, pod.Status.InitContainerStatuses, "Init Container"); f {
+			failed = true
+
+			reasons = append(reasons, msgs...)
+		}
+
+		if f, msgs := checkContainerStatuses(pod.Name, pod.Status.ContainerStatuses, "Container"); f {
+			failed = true
+
+			reasons = append(reasons, msgs...)
+		}
+	}
+
+	return failed, strings.Join(reasons, "; ")
+}
+
+// buildImageCanary returns the single replica Deployment running the pod template as the image
+// canary of the endpoint, started at now.
+func buildImageCanary(endpointName, namespace string, template *corev1.PodTemplateSpec, now time.Time) *appsv1.Deployment {
+	podTemplate := *template.DeepCopy()
+	podTemplate.Labels = maps.Clone(podTemplate.Labels)
+
+	if podTemplate.Labels == nil {
+		podTemplate.Labels = map[string]string{}
+	}
+
+	podTemplate.Labels["app"] = canaryApp
+	podTemplate.Labels["endpoint"] = endpointName
+
+	selector := map[string]string{"app": canaryApp, "endpoint": endpointName}
+
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        imageCanaryName(endpointName),
+			Namespace:   namespace,
+			Labels:      selector,
+			Annotations: map[string]string{imageCanaryStartedAtAnnotation: now.UTC().Format(time.RFC3339)},
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: pointer.Int32(1),
+			Selector: &metav1.LabelSelector{MatchLabels: selector},
+			Template: podTemplate,
+		},
+	}
+}
+
+// renderedPodTemplate returns the pod template of the rendered workload of the endpoint.
+func renderedPodTemplate(objects *unstructured.UnstructuredList) (*corev1.PodTemplateSpec, error) {
+	for _, obj := range objects.Items {
+		switch obj.GetKind() {
+		case "Deployment":
+			var dep appsv1.Deployment
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &dep); err != nil {
+				return nil, errors.Wrap(err, "failed to parse deployment")
+			}
+
+			return &dep.Spec.Template, nil
+		case "StatefulSet":
+			var sts appsv1.StatefulSet
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &sts); err != nil {
+				return nil, errors.Wrap(err, "failed to parse statefulset")
+			}
+
+			return &sts.Spec.Template, nil
+		}
+	}
+
+	return nil, errors.New("deploy template has no workload to run an image canary of")
+}
+
+// existingPodTemplate returns the pod template of the deployed workload of the endpoint.
+func existingPodTemplate(workload client.Object) *corev1.PodTemplateSpec {
+	switch w := workload.(type) {
+	case *appsv1.Deployment:
+		return &w.Spec.Template
+	case *appsv1.StatefulSet:
+		return &w.Spec.Template
+	default:
+		return nil
+	}
+}
+
+// engineImage returns the image of the engine container, the first container of the pod.
+func engineImage(template *corev1.PodTemplateSpec) string {
+	if template == nil || len(template.Spec.Containers) == 0 {
+		return ""
+	}
+
+	return template.Spec.Containers[0].Image
+}
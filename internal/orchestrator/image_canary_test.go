@@ -0,0 +1,239 @@
+package orchestrator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/neutree-ai/neutree/internal/util"
+)
+
+func imageCanaryDeployment(t *testing.T, name, namespace, image string) *appsv1.Deployment {
+	t.Helper()
+
+	return &appsv1.Deployment{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: pointer.Int32(2),
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"endpoint": name, "app": "inference"}},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"endpoint": name, "app": "inference"}},
+				Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "vllm", Image: image}}},
+			},
+		},
+	}
+}
+
+func renderedImageCanaryObjects(t *testing.T, dep *appsv1.Deployment) *unstructured.UnstructuredList {
+	t.Helper()
+
+	obj, err := util.ToUnstructured(dep)
+	require.NoError(t, err)
+
+	return &unstructured.UnstructuredList{Items: []unstructured.Unstructured{*obj}}
+}
+
+func markImageCanaryReady(t *testing.T, c client.Client, namespace, name string) {
+	t.Helper()
+
+	canary := &appsv1.Deployment{}
+	require.NoError(t, c.Get(context.Background(), client.ObjectKey{Namespace: namespace, Name: imageCanaryName(name)}, canary))
+
+	canary.Status = appsv1.DeploymentStatus{
+		ObservedGeneration: canary.Generation,
+		Replicas:           1,
+		UpdatedReplicas:    1,
+		ReadyReplicas:      1,
+		AvailableReplicas:  1,
+		Conditions: []appsv1.DeploymentCondition{
+			{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionTrue},
+			{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionTrue},
+		},
+	}
+	require.NoError(t, c.Status().Update(context.Background(), canary))
+}
+
+func TestKubernetesOrchestrator_checkImageCanary(t *testing.T) {
+	const name = "chat"
+
+	t.Run("canary succeeds then rollout", func(t *testing.T) {
+		fakeClient := NewFakeK8sClient(t)
+		ctx := makePauseTestCtx(fakeClient, name)
+		ctx.Endpoint.Spec.DeploymentOptions = map[string]interface{}{"image_canary": true}
+		namespace := util.ClusterNamespace(ctx.Cluster)
+
+		existing := imageCanaryDeployment(t, name, namespace, "vllm:v0.11.2")
+		require.NoError(t, fakeClient.Create(context.Background(), existing))
+
+		objects := renderedImageCanaryObjects(t, imageCanaryDeployment(t, name, namespace, "vllm:v0.24.0"))
+		k := &kubernetesOrchestrator{}
+
+		rollout, err := k.checkImageCanary(ctx, namespace, objects, existing)
+		require.NoError(t, err)
+		assert.False(t, rollout)
+
+		canary := &appsv1.Deployment{}
+		require.NoError(t, fakeClient.Get(context.Background(), client.ObjectKey{Namespace: namespace, Name: imageCanaryName(name)}, canary))
+		assert.Equal(t, pointer.Int32(1), canary.Spec.Replicas)
+		assert.Equal(t, "vllm:v0.24.0", canary.Spec.Template.Spec.Containers[0].Image)
+		assert.Equal(t, canaryApp, canary.Spec.Template.Labels["app"])
+
+		// waiting for the canary to become ready
+		rollout, err = k.checkImageCanary(ctx, namespace, objects, existing)
+		require.NoError(t, err)
+		assert.False(t, rollout)
+
+		markImageCanaryReady(t, fakeClient, namespace, name)
+
+		rollout, err = k.checkImageCanary(ctx, namespace, objects, existing)
+		require.NoError(t, err)
+		assert.True(t, rollout)
+
+		err = fakeClient.Get(context.Background(), client.ObjectKey{Namespace: namespace, Name: imageCanaryName(name)}, canary)
+		assert.True(t, apierrors.IsNotFound(err))
+	})
+
+	t.Run("canary fails then abort", func(t *testing.T) {
+		fakeClient := NewFakeK8sClient(t)
+		ctx := makePauseTestCtx(fakeClient, name)
+		ctx.Endpoint.Spec.DeploymentOptions = map[string]interface{}{"image_canary": true}
+		namespace := util.ClusterNamespace(ctx.Cluster)
+
+		existing := imageCanaryDeployment(t, name, namespace, "vllm:v0.11.2")
+		require.NoError(t, fakeClient.Create(context.Background(), existing))
+
+		objects := renderedImageCanaryObjects(t, imageCanaryDeployment(t, name, namespace, "vllm:broken"))
+		k := &kubernetesOrchestrator{}
+
+		rollout, err := k.checkImageCanary(ctx, namespace, objects, existing)
+		require.NoError(t, err)
+		assert.False(t, rollout)
+
+		require.NoError(t, fakeClient.Create(context.Background(), &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      imageCanaryName(name) + "-abc",
+				Namespace: namespace,
+				Labels:    map[string]string{"endpoint": name, "app": canaryApp},
+			},
+			Status: corev1.PodStatus{
+				ContainerStatuses: []corev1.ContainerStatus{{
+					Name: "vllm",
+					State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{
+						Reason:  k8sContainerReasonImagePullBackOff,
+						Message: "manifest unknown",
+					}},
+				}},
+			},
+		}))
+
+		rollout, err = k.checkImageCanary(ctx, namespace, objects, existing)
+		require.Error(t, err)
+		assert.False(t, rollout)
+		assert.Contains(t, err.Error(), "image canary of vllm:broken failed, rollout aborted")
+		assert.Contains(t, err.Error(), "failed to pull image: manifest unknown")
+
+		// the pod of the failed canary is deleted, the canary is kept so that the failure is
+		// reported until the image changes again
+		canary := &appsv1.Deployment{}
+		require.NoError(t, fakeClient.Get(context.Background(), client.ObjectKey{Namespace: namespace, Name: imageCanaryName(name)}, canary))
+		assert.Equal(t, pointer.Int32(0), canary.Spec.Replicas)
+		assert.Contains(t, canary.Annotations[imageCanaryFailureAnnotation], "failed to pull image: manifest unknown")
+
+		rollout, err = k.checkImageCanary(ctx, namespace, objects, existing)
+		require.Error(t, err)
+		assert.False(t, rollout)
+		assert.Contains(t, err.Error(), "failed to pull image: manifest unknown")
+
+		// a new image restarts the canary
+		fixed := renderedImageCanaryObjects(t, imageCanaryDeployment(t, name, namespace, "vllm:fixed"))
+		rollout, err = k.checkImageCanary(ctx, namespace, fixed, existing)
+		require.NoError(t, err)
+		assert.False(t, rollout)
+
+		require.NoError(t, fakeClient.Get(context.Background(), client.ObjectKey{Namespace: namespace, Name: imageCanaryName(name)}, canary))
+		assert.Equal(t, pointer.Int32(1), canary.Spec.Replicas)
+		assert.NotContains(t, canary.Annotations, imageCanaryFailureAnnotation)
+
+		// reverting the image drops the canary
+		rollout, err = k.checkImageCanary(ctx, namespace, renderedImageCanaryObjects(t, existing), existing)
+		require.NoError(t, err)
+		assert.True(t, rollout)
+
+		err = fakeClient.Get(context.Background(), client.ObjectKey{Namespace: namespace, Name: imageCanaryName(name)}, canary)
+		assert.True(t, apierrors.IsNotFound(err))
+	})
+
+	t.Run("canary not ready in time then abort", func(t *testing.T) {
+		fakeClient := NewFakeK8sClient(t)
+		ctx := makePauseTestCtx(fakeClient, name)
+		ctx.Endpoint.Spec.DeploymentOptions = map[string]interface{}{"image_canary": true, "startup_timeout_seconds": 600}
+		namespace := util.ClusterNamespace(ctx.Cluster)
+
+		existing := imageCanaryDeployment(t, name, namespace, "vllm:v0.11.2")
+		require.NoError(t, fakeClient.Create(context.Background(), existing))
+
+		objects := renderedImageCanaryObjects(t, imageCanaryDeployment(t, name, namespace, "vllm:v0.24.0"))
+		k := &kubernetesOrchestrator{}
+
+		rollout, err := k.checkImageCanary(ctx, namespace, objects, existing)
+		require.NoError(t, err)
+		assert.False(t, rollout)
+
+		canary := &appsv1.Deployment{}
+		require.NoError(t, fakeClient.Get(context.Background(), client.ObjectKey{Namespace: namespace, Name: imageCanaryName(name)}, canary))
+
+		// still within the startup window and the time to pull the image
+		canary.Annotations[imageCanaryStartedAtAnnotation] = time.Now().Add(-30 * time.Minute).UTC().Format(time.RFC3339)
+		require.NoError(t, fakeClient.Update(context.Background(), canary))
+
+		rollout, err = k.checkImageCanary(ctx, namespace, objects, existing)
+		require.NoError(t, err)
+		assert.False(t, rollout)
+
+		canary.Annotations[imageCanaryStartedAtAnnotation] = time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+		require.NoError(t, fakeClient.Update(context.Background(), canary))
+
+		rollout, err = k.checkImageCanary(ctx, namespace, objects, existing)
+		assert.False(t, rollout)
+		assert.EqualError(t, err, "image canary of vllm:v0.24.0 failed, rollout aborted: not ready after 2400s")
+
+		require.NoError(t, fakeClient.Get(context.Background(), client.ObjectKey{Namespace: namespace, Name: imageCanaryName(name)}, canary))
+		assert.Equal(t, pointer.Int32(0), canary.Spec.Replicas)
+	})
+
+	t.Run("first deploy and disabled canary roll out directly", func(t *testing.T) {
+		fakeClient := NewFakeK8sClient(t)
+		ctx := makePauseTestCtx(fakeClient, name)
+		namespace := util.ClusterNamespace(ctx.Cluster)
+
+		existing := imageCanaryDeployment(t, name, namespace, "vllm:v0.11.2")
+		objects := renderedImageCanaryObjects(t, imageCanaryDeployment(t, name, namespace, "vllm:v0.24.0"))
+		k := &kubernetesOrchestrator{}
+
+		rollout, err := k.checkImageCanary(ctx, namespace, objects, existing)
+		require.NoError(t, err)
+		assert.True(t, rollout)
+
+		ctx.Endpoint.Spec.DeploymentOptions = map[string]interface{}{"image_canary": true}
+
+		rollout, err = k.checkImageCanary(ctx, namespace, objects, nil)
+		require.NoError(t, err)
+		assert.True(t, rollout)
+
+		ctx.Endpoint.Spec.DeploymentOptions = map[string]interface{}{"image_canary": "yes"}
+
+		_, err = k.checkImageCanary(ctx, namespace, objects, existing)
+		assert.EqualError(t, err, "deployment_options.image_canary must be a boolean")
+	})
+}
@@ -0,0 +1,81 @@
+package orchestrator
+
+import (
+	"strconv"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+
+	v1 "github.com/neutree-ai/neutree/api/v1"
+	"github.com/neutree-ai/neutree/internal/registry"
+	"github.com/neutree-ai/neutree/internal/util"
+	"github.com/neutree-ai/neutree/pkg/storage"
+)
+
+// resolveEngineImageDigest returns the digest the engine image of the endpoint is deployed by,
+// or an empty string when the endpoint does not pin its engine image.
+// The image tag is resolved through the image service on first deploy and the digest is recorded
+// in the endpoint status; later deploys reuse it until the status digest is cleared.
+func resolveEngineImageDigest(s storage.Storage, imageService registry.ImageService,
+	endpoint *v1.Endpoint, imageRegistry *v1.ImageRegistry, image string) (string, error) {
+	if !endpoint.Spec.PinsEngineImageDigest() {
+		return "", nil
+	}
+
+	if endpoint.Status != nil && endpoint.Status.EngineImageDigest != "" {
+		return endpoint.Status.EngineImageDigest, nil
+	}
+
+	if imageService == nil {
+		return "", errors.New("image service is required to pin the engine image digest")
+	}
+
+	auth, err := imageRegistryAuthenticator(imageRegistry)
+	if err != nil {
+		return "", err
+	}
+
+	digest, err := imageService.GetImageDigest(image, auth)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to resolve digest of engine image %s", image)
+	}
+
+	status := &v1.EndpointStatus{}
+	if endpoint.Status != nil {
+		current := *endpoint.Status
+		status = &current
+	}
+
+	status.EngineImageDigest = digest
+
+	if err = s.UpdateEndpoint(strconv.Itoa(endpoint.ID), &v1.Endpoint{Status: status}); err != nil {
+		return "", errors.Wrapf(err, "failed to record engine image digest of endpoint %s", endpoint.Metadata.WorkspaceName())
+	}
+
+	endpoint.Status = status
+
+	klog.Infof("Pinned engine image %s of endpoint %s to digest %s", image, endpoint.Metadata.WorkspaceName(), digest)
+
+	return digest, nil
+}
+
+func imageRegistryAuthenticator(imageRegistry *v1.ImageRegistry) (authn.Authenticator, error) {
+	if imageRegistry == nil {
+		return authn.Anonymous, nil
+	}
+
+	username, password, err := util.GetImageRegistryAuthInfo(imageRegistry)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get auth info of image registry %s", imageRegistry.Metadata.WorkspaceName())
+	}
+
+	if username == "" && password == "" {
+		return authn.Anonymous, nil
+	}
+
+	return authn.FromConfig(authn.AuthConfig{
+		Username: username,
+		Password: password,
+	}), nil
+}
@@ -0,0 +1,127 @@
+package orchestrator
+
+import (
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	v1 "github.com/neutree-ai/neutree/api/v1"
+	registrymocks "github.com/neutree-ai/neutree/internal/registry/mocks"
+	storagemocks "github.com/neutree-ai/neutree/pkg/storage/mocks"
+)
+
+func pinnedEndpoint(pin bool, status *v1.EndpointStatus) *v1.Endpoint {
+	return &v1.Endpoint{
+		ID:       7,
+		Metadata: &v1.Metadata{Workspace: "default", Name: "ep"},
+		Spec: &v1.EndpointSpec{
+			Engine: &v1.EndpointEngineSpec{Engine: "vllm", Version: "v0.11.2", PinImageDigest: pin},
+		},
+		Status: status,
+	}
+}
+
+func TestResolveEngineImageDigest(t *testing.T) {
+	const image = "registry.neutree.ai/neutree/vllm-cuda:v0.11.2"
+
+	t.Run("not pinned", func(t *testing.T) {
+		s := storagemocks.NewMockStorage(t)
+		imageService := registrymocks.NewMockImageService(t)
+
+		digest, err := resolveEngineImageDigest(s, imageService, pinnedEndpoint(false, nil), nil, image)
+		require.NoError(t, err)
+		assert.Empty(t, digest)
+	})
+
+	t.Run("tag resolved to digest on first deploy", func(t *testing.T) {
+		s := storagemocks.NewMockStorage(t)
+		imageService := registrymocks.NewMockImageService(t)
+		endpoint := pinnedEndpoint(true, &v1.EndpointStatus{Phase: v1.EndpointPhaseDEPLOYING})
+
+		imageService.EXPECT().GetImageDigest(image, authn.Anonymous).Return("sha256:abc", nil).Once()
+		s.EXPECT().UpdateEndpoint("7", mock.MatchedBy(func(e *v1.Endpoint) bool {
+			return e.Status.EngineImageDigest == "sha256:abc" && e.Status.Phase == v1.EndpointPhaseDEPLOYING
+		})).Return(nil).Once()
+
+		digest, err := resolveEngineImageDigest(s, imageService, endpoint, nil, image)
+		require.NoError(t, err)
+		assert.Equal(t, "sha256:abc", digest)
+		assert.Equal(t, "sha256:abc", endpoint.Status.EngineImageDigest)
+
+		// Later deploys reuse the recorded digest even if the tag was rebuilt.
+		digest, err = resolveEngineImageDigest(s, imageService, endpoint, nil, image)
+		require.NoError(t, err)
+		assert.Equal(t, "sha256:abc", digest)
+	})
+
+	t.Run("registry credentials are used", func(t *testing.T) {
+		s := storagemocks.NewMockStorage(t)
+		imageService := registrymocks.NewMockImageService(t)
+		imageRegistry := &v1.ImageRegistry{
+			Metadata: &v1.Metadata{Name: "registry"},
+			Spec: &v1.ImageRegistrySpec{
+				AuthConfig: v1.ImageRegistryAuthConfig{Username: "user", Password: "pass"},
+			},
+		}
+
+		imageService.EXPECT().GetImageDigest(image, authn.FromConfig(authn.AuthConfig{Username: "user", Password: "pass"})).
+			Return("sha256:abc", nil)
+		s.EXPECT().UpdateEndpoint("7", mock.Anything).Return(nil)
+
+		digest, err := resolveEngineImageDigest(s, imageService, pinnedEndpoint(true, nil), imageRegistry, image)
+		require.NoError(t, err)
+		assert.Equal(t, "sha256:abc", digest)
+	})
+
+	t.Run("resolve failure", func(t *testing.T) {
+		s := storagemocks.NewMockStorage(t)
+		imageService := registrymocks.NewMockImageService(t)
+
+		imageService.EXPECT().GetImageDigest(image, mock.Anything).Return("", assert.AnError)
+
+		_, err := resolveEngineImageDigest(s, imageService, pinnedEndpoint(true, nil), nil, image)
+		assert.Error(t, err)
+	})
+}
+
+func TestKubernetesOrchestrator_setDeployImageVariables_PinnedDigest(t *testing.T) {
+	s := storagemocks.NewMockStorage(t)
+	imageService := registrymocks.NewMockImageService(t)
+	k := &kubernetesOrchestrator{storage: s, imageService: imageService}
+
+	endpoint := pinnedEndpoint(true, nil)
+	endpoint.Spec.Resources = &v1.ResourceSpec{Accelerator: map[string]string{"type": "nvidia-gpu"}}
+	engine := &v1.Engine{
+		Metadata: &v1.Metadata{Name: "vllm"},
+		Spec: &v1.EngineSpec{
+			Versions: []*v1.EngineVersion{
+				{
+					Version: "v0.11.2",
+					Images: map[string]*v1.EngineImage{
+						"nvidia-gpu": {ImageName: "vllm-cuda", Tag: "v0.11.2"},
+					},
+				},
+			},
+		},
+	}
+	imageRegistry := &v1.ImageRegistry{
+		Metadata: &v1.Metadata{Name: "default"},
+		Spec: &v1.ImageRegistrySpec{
+			URL:        "https://registry.neutree.ai",
+			Repository: "neutree",
+		},
+	}
+
+	imageService.EXPECT().GetImageDigest("registry.neutree.ai/neutree/vllm-cuda:v0.11.2", authn.Anonymous).Return("sha256:abc", nil)
+	s.EXPECT().UpdateEndpoint("7", mock.Anything).Return(nil)
+
+	data := newDeploymentManifestVariables()
+	require.NoError(t, k.setDeployImageVariables(&data, endpoint, engine, imageRegistry))
+
+	assert.Equal(t, "vllm-cuda", data.ImageRepo)
+	assert.Equal(t, "v0.11.2", data.ImageTag)
+	assert.Equal(t, "sha256:abc", data.ImageDigest)
+}
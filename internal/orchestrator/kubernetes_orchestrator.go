@@ -5,7 +5,10 @@ import (
 	"crypto/sha256"
 	"encoding/json"
 	"fmt"
+	"io"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	"k8s.io/klog/v2"
@@ -13,16 +16,22 @@ import (
 	v1 "github.com/neutree-ai/neutree/api/v1"
 	"github.com/neutree-ai/neutree/internal/accelerator"
 	"github.com/neutree-ai/neutree/internal/deploy"
+	"github.com/neutree-ai/neutree/internal/engine"
+	"github.com/neutree-ai/neutree/internal/registry"
 	resourceview "github.com/neutree-ai/neutree/internal/resource"
 	"github.com/neutree-ai/neutree/internal/util"
 	"github.com/neutree-ai/neutree/pkg/storage"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -31,16 +40,27 @@ const (
 	annNeutreeVersion                = "neutree.ai/neutree-version"
 	containerFailureRestartThreshold = 5
 	modelDownloaderInitContainerName = "model-downloader"
+	modelConversionInitContainerName = "model-conversion"
+	authSidecarContainerName         = "auth-proxy"
+
+	// engineServingPort is the pod port the router and the Service send the endpoint requests
+	// to. The engine listens on it unless an auth sidecar takes it over, the engine is then
+	// moved to authSidecarEnginePort of the pod loopback address, out of reach of the other
+	// pods.
+	engineServingPort     = 8000
+	authSidecarEnginePort = 8001
+	authSidecarEngineHost = "127.0.0.1"
 )
 
 // Kubernetes does not expose these kubelet container reasons as corev1 constants.
 // Keep the standard reason strings centralized so status checks and tests share one definition.
 const (
-	k8sContainerReasonOOMKilled        = "OOMKilled"
-	k8sContainerReasonCrashLoopBackOff = "CrashLoopBackOff"
-	k8sContainerReasonImagePullBackOff = "ImagePullBackOff"
-	k8sContainerReasonErrImagePull     = "ErrImagePull"
-	k8sContainerReasonPodInitializing  = "PodInitializing"
+	k8sContainerReasonOOMKilled         = "OOMKilled"
+	k8sContainerReasonCrashLoopBackOff  = "CrashLoopBackOff"
+	k8sContainerReasonImagePullBackOff  = "ImagePullBackOff"
+	k8sContainerReasonErrImagePull      = "ErrImagePull"
+	k8sContainerReasonPodInitializing   = "PodInitializing"
+	k8sContainerReasonContainerCreating = "ContainerCreating"
 )
 
 var _ Orchestrator = &kubernetesOrchestrator{}
@@ -49,12 +69,18 @@ type kubernetesOrchestrator struct {
 	storage storage.Storage
 
 	acceleratorMgr accelerator.Manager
+	imageService   registry.ImageService
+
+	// k8sClient reads pod logs, e.g. model download progress of the init container.
+	k8sClient util.K8sClient
 }
 
 func newKubernetesOrchestrator(opts Options) *kubernetesOrchestrator {
 	return &kubernetesOrchestrator{
 		storage:        opts.Storage,
 		acceleratorMgr: opts.AcceleratorMgr,
+		imageService:   opts.ImageService,
+		k8sClient:      &util.DefaultK8sClient{},
 	}
 }
 
@@ -64,6 +90,11 @@ func (k *kubernetesOrchestrator) prepareOrchestratorContext(endpoint *v1.Endpoin
 		return nil, errors.Wrap(err, "failed to get deploy cluster")
 	}
 
+	endpoint, err = expandResourcePreset(endpoint, deployedCluster)
+	if err != nil {
+		return nil, err
+	}
+
 	imageRegistry, err := getUsedImageRegistries(deployedCluster, k.storage)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get used image registry")
@@ -105,15 +136,27 @@ func (k *kubernetesOrchestrator) validateDependencies(ctx *OrchestratorContext)
 		return errors.Errorf("deploy cluster %s is not kubernetes type", ctx.Cluster.Metadata.WorkspaceName())
 	}
 
+	if ctx.Endpoint.Spec.IsMultiModel() {
+		return errors.New("multi-model endpoints are only supported on ssh clusters")
+	}
+
 	if err := validateAcceleratorVirtualizationDependencies(ctx); err != nil {
 		return err
 	}
 
+	if err := validateEndpointAcceleratorProduct(ctx.Endpoint, ctx.Cluster); err != nil {
+		return err
+	}
+
 	// validate engine status
 	if ctx.Engine.Status == nil || ctx.Engine.Status.Phase != v1.EnginePhaseCreated {
 		return errors.Errorf("engine %s not ready", ctx.Engine.Metadata.WorkspaceName())
 	}
 
+	if err := validateImageAcceleratorDependencies(ctx); err != nil {
+		return err
+	}
+
 	// validate model registry status
 	if ctx.ModelRegistry.Status == nil || ctx.ModelRegistry.Status.Phase != v1.ModelRegistryPhaseCONNECTED {
 		return errors.Errorf("model registry %s not ready", ctx.ModelRegistry.Metadata.WorkspaceName())
@@ -124,6 +167,23 @@ func (k *kubernetesOrchestrator) validateDependencies(ctx *OrchestratorContext)
 		return errors.Errorf("image registry %s not ready", ctx.ImageRegistry.Metadata.WorkspaceName())
 	}
 
+	// validate engine args against the engine version schema
+	if err := engine.ValidateEndpointEngineArgs(ctx.Endpoint, ctx.Engine); err != nil {
+		return err
+	}
+
+	if err := validateEndpointSamplingParams(ctx.Endpoint); err != nil {
+		return err
+	}
+
+	if err := validateKubernetesEndpointUpgradeStrategy(ctx.Endpoint); err != nil {
+		return err
+	}
+
+	if err := validateSharedWeights(ctx); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -161,6 +221,89 @@ func validateAcceleratorVirtualizationDependencies(ctx *OrchestratorContext) err
 	return nil
 }
 
+// validateImageAcceleratorDependencies rejects an endpoint whose engine image is built for an
+// accelerator the deploy cluster does not report, its pods could never start. Engine versions
+// without images fall back to the image named after the engine, which is not built for a
+// known accelerator, and clusters not reporting their resources yet are not checked.
+func validateImageAcceleratorDependencies(ctx *OrchestratorContext) error {
+	enabled, err := getImageAcceleratorValidation(ctx.Endpoint)
+	if err != nil || !enabled {
+		return err
+	}
+
+	if ctx.Endpoint.Spec.Engine == nil || ctx.Endpoint.Spec.Resources == nil {
+		return nil
+	}
+
+	var engineVersion *v1.EngineVersion
+
+	for _, ev := range ctx.Engine.Spec.Versions {
+		if ev.Version == ctx.Endpoint.Spec.Engine.Version {
+			engineVersion = ev
+			break
+		}
+	}
+
+	if engineVersion == nil || len(engineVersion.Images) == 0 {
+		return nil
+	}
+
+	// the image resolved by getImageForAccelerator is the one of the requested accelerator type
+	acceleratorType := ctx.Endpoint.Spec.Resources.GetAcceleratorType()
+	if acceleratorType == "" || acceleratorType == acceleratorTypeCPU {
+		return nil
+	}
+
+	clusterTypes, reported := clusterAcceleratorTypes(ctx.Cluster)
+	if !reported || clusterTypes[acceleratorType] {
+		return nil
+	}
+
+	available := make([]string, 0, len(clusterTypes))
+	for t := range clusterTypes {
+		available = append(available, t)
+	}
+
+	sort.Strings(available)
+
+	return errors.Errorf(
+		"engine %s version %s image is built for accelerator %q, but deploy cluster %s only has accelerators %v",
+		ctx.Engine.Metadata.WorkspaceName(),
+		engineVersion.Version,
+		acceleratorType,
+		ctx.Cluster.Metadata.WorkspaceName(),
+		available,
+	)
+}
+
+// clusterAcceleratorTypes returns the accelerator types the cluster reports in its status, and
+// whether it reported its resources at all.
+func clusterAcceleratorTypes(cluster *v1.Cluster) (map[string]bool, bool) {
+	types := map[string]bool{}
+
+	if cluster.Status == nil || (cluster.Status.ResourceInfo == nil && cluster.Status.AcceleratorType == nil) {
+		return types, false
+	}
+
+	if cluster.Status.AcceleratorType != nil && *cluster.Status.AcceleratorType != "" {
+		types[*cluster.Status.AcceleratorType] = true
+	}
+
+	if resources := cluster.Status.ResourceInfo; resources != nil {
+		for _, info := range []*v1.ResourceInfo{resources.Allocatable, resources.Available} {
+			if info == nil {
+				continue
+			}
+
+			for t := range info.AcceleratorGroups {
+				types[string(t)] = true
+			}
+		}
+	}
+
+	return types, true
+}
+
 func acceleratorVirtualizationComponentStatus(cluster *v1.Cluster) *v1.ComponentStatus {
 	if cluster == nil || cluster.Status == nil || cluster.Status.ComponentStatus == nil {
 		return nil
@@ -189,8 +332,8 @@ func (k *kubernetesOrchestrator) CreateEndpoint(endpoint *v1.Endpoint) error {
 	return nil
 }
 
-// computeEndpointSpecHash computes a SHA256 hash of the endpoint spec.
-func computeEndpointSpecHash(endpoint *v1.Endpoint) (string, error) {
+// ComputeEndpointSpecHash computes a SHA256 hash of the endpoint spec.
+func ComputeEndpointSpecHash(endpoint *v1.Endpoint) (string, error) {
 	specJSON, err := json.Marshal(endpoint.Spec)
 	if err != nil {
 		return "", errors.Wrap(err, "failed to marshal endpoint spec for hashing")
@@ -201,34 +344,66 @@ func computeEndpointSpecHash(endpoint *v1.Endpoint) (string, error) {
 	return fmt.Sprintf("%x", hash), nil
 }
 
+// validatePriorityClass checks that the priority class requested by the endpoint exists in the
+// deploy cluster. Lookup failures other than not found (e.g. missing RBAC on priority classes)
+// are tolerated, the pods are then admitted or rejected by the cluster itself.
+func validatePriorityClass(ctx *OrchestratorContext) error {
+	name := ctx.Endpoint.Spec.PriorityClassName
+	if name == "" {
+		return nil
+	}
+
+	err := ctx.ctrClient.Get(context.Background(), client.ObjectKey{Name: name}, &schedulingv1.PriorityClass{})
+	if err == nil {
+		return nil
+	}
+
+	if apierrors.IsNotFound(err) {
+		return errors.Errorf("priority class %s not found in cluster %s", name, ctx.Cluster.Metadata.WorkspaceName())
+	}
+
+	ctx.logger.V(4).Info("Skip priority class validation", "priorityClass", name, "error", err.Error())
+
+	return nil
+}
+
 func (k *kubernetesOrchestrator) createEndpoint(ctx *OrchestratorContext) error {
 	namespace := util.ClusterNamespace(ctx.Cluster)
 
+	if err := validatePriorityClass(ctx); err != nil {
+		return err
+	}
+
 	renderVars, err := k.buildManifestVariables(ctx.Endpoint, ctx.Cluster, ctx.ModelRegistry, ctx.Engine, ctx.ImageRegistry)
 	if err != nil {
 		return errors.Wrapf(err, "failed to build manifest variables for endpoint %s", ctx.Endpoint.Metadata.WorkspaceName())
 	}
 
-	currentSpecHash, err := computeEndpointSpecHash(ctx.Endpoint)
+	currentSpecHash, err := ComputeEndpointSpecHash(ctx.Endpoint)
 	if err != nil {
 		return errors.Wrapf(err, "failed to compute endpoint spec hash for endpoint %s", ctx.Endpoint.Metadata.WorkspaceName())
 	}
 
 	// Preserve NeutreeVersion when only the cluster version changed (not the endpoint spec).
-	// The spec hash and NeutreeVersion are stored as annotations on the K8s Deployment.
-	existingDep := &appsv1.Deployment{}
+	// The spec hash and NeutreeVersion are stored as annotations on the K8s Deployment, or
+	// the StatefulSet of an endpoint with peer discovery.
+	existingDep := endpointWorkload(ctx.Endpoint, namespace)
+	deployed := true
+
+	if err := ctx.ctrClient.Get(context.Background(), client.ObjectKeyFromObject(existingDep), existingDep); err != nil {
+		if meta.IsNoMatchError(err) {
+			return errors.Errorf("deploy cluster %s has no LeaderWorkerSet API to run the replicas of endpoint %s spanning several nodes, install LeaderWorkerSet first",
+				ctx.Cluster.Metadata.WorkspaceName(), ctx.Endpoint.Metadata.WorkspaceName())
+		}
 
-	if err := ctx.ctrClient.Get(context.Background(), client.ObjectKey{
-		Namespace: namespace,
-		Name:      ctx.Endpoint.Metadata.Name,
-	}, existingDep); err != nil {
 		if !apierrors.IsNotFound(err) {
 			return errors.Wrapf(err, "failed to get existing deployment for endpoint %s", ctx.Endpoint.Metadata.WorkspaceName())
 		}
 		// Deployment does not exist yet (first deploy) — nothing to preserve.
-	} else if existingDep.Annotations != nil {
-		storedHash := existingDep.Annotations[annEndpointSpecHash]
-		storedVersion := existingDep.Annotations[annNeutreeVersion]
+		deployed = false
+	} else if annotations := existingDep.GetAnnotations(); annotations != nil {
+		storedHash := annotations[annEndpointSpecHash]
+		storedVersion := annotations[annNeutreeVersion]
 
 		// Preserve NeutreeVersion when:
 		// - storedHash == currentSpecHash: endpoint spec unchanged (cluster-only upgrade)
@@ -236,6 +411,12 @@ func (k *kubernetesOrchestrator) createEndpoint(ctx *OrchestratorContext) error
 		if storedVersion != "" && (storedHash == "" || storedHash == currentSpecHash) {
 			renderVars.NeutreeVersion = storedVersion
 		}
+
+		// The default termination grace period only applies to new or changed specs, an
+		// unchanged endpoint keeps the grace period its replicas run with.
+		if (storedHash == "" || storedHash == currentSpecHash) && !terminationGracePeriodConfigured(ctx.Endpoint) {
+			renderVars.TerminationGracePeriodSeconds = deployedTerminationGracePeriodSeconds(existingDep)
+		}
 	}
 
 	deployTemplate, err := k.getDeployTemplate(ctx.Endpoint, ctx.Engine)
@@ -243,11 +424,56 @@ func (k *kubernetesOrchestrator) createEndpoint(ctx *OrchestratorContext) error
 		return errors.Wrapf(err, "failed to get deploy template for endpoint %s", ctx.Endpoint.Metadata.WorkspaceName())
 	}
 
-	deploymentObjects, err := buildDeploymentObjects(deployTemplate, renderVars)
+	mixedPlacement, err := getMixedPlacementOptions(ctx.Endpoint)
+	if err != nil {
+		return errors.Wrapf(err, "invalid deployment options for endpoint %s", ctx.Endpoint.Metadata.WorkspaceName())
+	}
+
+	deploymentObjects, err := buildReplicaGroupObjects(deployTemplate, renderVars, mixedPlacement)
 	if err != nil {
 		return errors.Wrapf(err, "failed to build deployment for endpoint %s", ctx.Endpoint.Metadata.WorkspaceName())
 	}
 
+	// The cluster config was validated when preparing the orchestrator context, a missing
+	// config keeps the default Job TTL.
+	kubernetesConfig, _ := util.ParseKubernetesClusterConfig(ctx.Cluster) //nolint:errcheck
+
+	var ingressConfig *v1.EndpointIngressConfig
+	if kubernetesConfig != nil {
+		ingressConfig = kubernetesConfig.EndpointIngress
+	}
+
+	if err := addEndpointIngress(ctx, deploymentObjects, namespace, ingressConfig); err != nil {
+		return errors.Wrapf(err, "failed to build ingress for endpoint %s", ctx.Endpoint.Metadata.WorkspaceName())
+	}
+
+	if err := addDownloadLeaseObjects(deploymentObjects, ctx.Endpoint, namespace); err != nil {
+		return errors.Wrapf(err, "failed to build model download leases for endpoint %s", ctx.Endpoint.Metadata.WorkspaceName())
+	}
+
+	if err := addPeerDiscoveryObjects(deploymentObjects, ctx.Endpoint); err != nil {
+		return errors.Wrapf(err, "failed to build peer discovery for endpoint %s", ctx.Endpoint.Metadata.WorkspaceName())
+	}
+
+	if err := addMultiNodeObjects(deploymentObjects, renderVars.WorkerReplicas); err != nil {
+		return errors.Wrapf(err, "failed to build multi-node replicas for endpoint %s", ctx.Endpoint.Metadata.WorkspaceName())
+	}
+
+	var deployedWorkload client.Object
+	if deployed {
+		deployedWorkload = existingDep
+	}
+
+	rollout, err := k.checkImageCanary(ctx, namespace, deploymentObjects, deployedWorkload)
+	if err != nil {
+		return errors.Wrapf(err, "failed to check image canary for endpoint %s", ctx.Endpoint.Metadata.WorkspaceName())
+	}
+
+	if !rollout {
+		ctx.logger.Info("Waiting for the image canary before rolling out")
+		return nil
+	}
+
 	applier := deploy.NewKubernetesDeployer(
 		ctx.ctrClient,
 		namespace,
@@ -255,6 +481,7 @@ func (k *kubernetesOrchestrator) createEndpoint(ctx *OrchestratorContext) error
 		"deployment",               // componentName
 	).
 		WithNewObjects(deploymentObjects).
+		WithJobTTL(kubernetesConfig.GetFinishedJobTTLSeconds()).
 		WithLabels(map[string]string{
 			"endpoint":                         ctx.Endpoint.Metadata.Name,
 			v1.NeutreeClusterLabelKey:          ctx.Cluster.Metadata.Name,
@@ -264,7 +491,7 @@ func (k *kubernetesOrchestrator) createEndpoint(ctx *OrchestratorContext) error
 		WithMutate(func(obj *unstructured.Unstructured) error {
 			// Inject spec hash and NeutreeVersion as annotations on the Deployment
 			// so they are included in the SSA apply and managed by the field owner.
-			if obj.GetKind() == "Deployment" {
+			if obj.GetKind() == "Deployment" || obj.GetKind() == "StatefulSet" || obj.GetKind() == leaderWorkerSetKind {
 				ann := obj.GetAnnotations()
 				if ann == nil {
 					ann = make(map[string]string)
@@ -296,20 +523,19 @@ func (k *kubernetesOrchestrator) createEndpoint(ctx *OrchestratorContext) error
 	// a cluster upgrade where NeutreeVersion was preserved), this patch is the only
 	// path that writes/refreshes annotations. Annotation-only changes do not trigger
 	// a rollout.
-	dep := &appsv1.Deployment{}
-	if err := ctx.ctrClient.Get(context.Background(), client.ObjectKey{
-		Namespace: namespace,
-		Name:      ctx.Endpoint.Metadata.Name,
-	}, dep); err == nil {
-		if dep.Annotations == nil || dep.Annotations[annNeutreeVersion] == "" || dep.Annotations[annEndpointSpecHash] == "" {
-			patch := client.MergeFrom(dep.DeepCopy())
-
-			if dep.Annotations == nil {
-				dep.Annotations = make(map[string]string)
+	dep := endpointWorkload(ctx.Endpoint, namespace)
+	if err := ctx.ctrClient.Get(context.Background(), client.ObjectKeyFromObject(dep), dep); err == nil {
+		annotations := dep.GetAnnotations()
+		if annotations == nil || annotations[annNeutreeVersion] == "" || annotations[annEndpointSpecHash] == "" {
+			patch := client.MergeFrom(dep.DeepCopyObject().(client.Object))
+
+			if annotations == nil {
+				annotations = make(map[string]string)
 			}
 
-			dep.Annotations[annEndpointSpecHash] = currentSpecHash
-			dep.Annotations[annNeutreeVersion] = renderVars.NeutreeVersion
+			annotations[annEndpointSpecHash] = currentSpecHash
+			annotations[annNeutreeVersion] = renderVars.NeutreeVersion
+			dep.SetAnnotations(annotations)
 
 			if patchErr := ctx.ctrClient.Patch(context.Background(), dep, patch); patchErr != nil {
 				ctx.logger.Error(patchErr, "Failed to bootstrap deployment annotations")
@@ -320,16 +546,35 @@ func (k *kubernetesOrchestrator) createEndpoint(ctx *OrchestratorContext) error
 	return nil
 }
 
-// PauseEndpoint scales the endpoint's K8s deployment to zero replicas.
+// deployedTerminationGracePeriodSeconds returns the termination grace period of the pods of a
+// deployed workload, nil if they keep the Kubernetes default.
+func deployedTerminationGracePeriodSeconds(workload client.Object) *int64 {
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(workload)
+	if err != nil {
+		return nil
+	}
+
+	podSpec := []string{"spec", "template", "spec"}
+	if _, ok := workload.(*unstructured.Unstructured); ok {
+		podSpec = []string{"spec", "leaderWorkerTemplate", "leaderTemplate", "spec"}
+	}
+
+	seconds, found, err := unstructured.NestedInt64(obj, append(podSpec, "terminationGracePeriodSeconds")...)
+	if err != nil || !found {
+		return nil
+	}
+
+	return &seconds
+}
+
+// PauseEndpoint scales the endpoint's K8s workloads to zero replicas: its
+// Deployment, the Deployment of its spot replicas, its StatefulSet and, for
+// replicas spanning several nodes, its LeaderWorkerSet.
 //
 // Pause does not need ModelRegistry/Engine/ImageRegistry — the existing K8s
-// deployment already has the rendered manifest, so this just GETs it and
+// workloads already have the rendered manifest, so this just GETs them and
 // merge-patches spec.replicas to 0. A paused endpoint therefore converges to
 // Paused even when the model has been removed.
-//
-// TODO: like getEndpointStats, this currently assumes a single Deployment per
-// endpoint. Multi-kind deploy modes (multiple Deployments, LeaderWorkerSet)
-// will need pause + status reporting extended together.
 func (k *kubernetesOrchestrator) PauseEndpoint(endpoint *v1.Endpoint) error {
 	ctx, err := k.prepareOrchestratorContextForPauseDelete(endpoint)
 	if err != nil {
@@ -374,6 +619,15 @@ func (k *kubernetesOrchestrator) DeleteEndpoint(endpoint *v1.Endpoint) error {
 	return nil
 }
 
+// DrainEndpoint deletes the endpoint. Kubernetes already drains the pods of the endpoint as
+// they terminate: a terminating pod is removed from the Service endpoints, so it gets no new
+// requests, and the engine finishes the requests in flight within the termination grace
+// period, see deployment_options.termination_grace_period_seconds and the pre_stop hook of
+// deployment_options.lifecycle. timeout is not used.
+func (k *kubernetesOrchestrator) DrainEndpoint(endpoint *v1.Endpoint, _ time.Duration) error {
+	return k.DeleteEndpoint(endpoint)
+}
+
 // prepareOrchestratorContextForPauseDelete is the pause/delete equivalent of
 // prepareOrchestratorContext: it fetches only what those operations actually
 // need (cluster + ctrlClient) and skips ModelRegistry/Engine/ImageRegistry
@@ -415,32 +669,70 @@ func (k *kubernetesOrchestrator) validateClusterForPauseDelete(ctx *Orchestrator
 	return nil
 }
 
-// pauseEndpoint patches the endpoint's existing Deployment to spec.replicas=0.
-// Idempotent: returns nil when the deployment is already at 0 replicas or
-// when the deployment does not exist (already paused / never deployed).
+// pauseEndpoint patches the endpoint's existing workloads to spec.replicas=0.
+// Idempotent: returns nil when the workloads are already at 0 replicas or
+// when they do not exist (already paused / never deployed).
 func (k *kubernetesOrchestrator) pauseEndpoint(ctx *OrchestratorContext) error {
 	namespace := util.ClusterNamespace(ctx.Cluster)
+	objectMeta := func(name string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Namespace: namespace, Name: name}
+	}
+
+	// The spot replica group of an endpoint with mixed placement runs in a Deployment
+	// of its own, the replicas of an endpoint with peer discovery in a StatefulSet.
+	workloads := []client.Object{
+		&appsv1.Deployment{ObjectMeta: objectMeta(ctx.Endpoint.Metadata.Name)},
+		&appsv1.Deployment{ObjectMeta: objectMeta(spotDeploymentName(ctx.Endpoint.Metadata.Name))},
+		&appsv1.StatefulSet{ObjectMeta: objectMeta(ctx.Endpoint.Metadata.Name)},
+	}
+
+	// The replicas spanning several nodes run in a LeaderWorkerSet, only checked for the
+	// endpoints that have one as the API may not be installed.
+	if workers, _ := multiNodeWorkers(ctx.Endpoint); workers > 0 { //nolint:errcheck
+		workloads = append(workloads, newLeaderWorkerSet(ctx.Endpoint.Metadata.Name, namespace))
+	}
+
+	for _, workload := range workloads {
+		if err := k.pauseWorkload(ctx, workload); err != nil {
+			return err
+		}
+	}
 
-	dep := &appsv1.Deployment{}
-	if err := ctx.ctrClient.Get(context.Background(), client.ObjectKey{
-		Namespace: namespace,
-		Name:      ctx.Endpoint.Metadata.Name,
-	}, dep); err != nil {
+	return k.deleteImageCanary(ctx, namespace)
+}
+
+func (k *kubernetesOrchestrator) pauseWorkload(ctx *OrchestratorContext, workload client.Object) error {
+	name := workload.GetName()
+
+	if err := ctx.ctrClient.Get(context.Background(), client.ObjectKeyFromObject(workload), workload); err != nil {
 		if apierrors.IsNotFound(err) {
-			ctx.logger.V(4).Info("Deployment not found, treating pause as no-op")
+			ctx.logger.V(4).Info("Workload not found, treating pause as no-op", "workload", name)
 			return nil
 		}
 
-		return errors.Wrapf(err, "failed to get deployment for endpoint %s", ctx.Endpoint.Metadata.WorkspaceName())
+		return errors.Wrapf(err, "failed to get workload %s for endpoint %s", name, ctx.Endpoint.Metadata.WorkspaceName())
 	}
 
-	if dep.Spec.Replicas != nil && *dep.Spec.Replicas == 0 {
-		ctx.logger.V(4).Info("Deployment already at replicas=0, treating pause as no-op")
+	var replicas *int32
+
+	switch w := workload.(type) {
+	case *appsv1.Deployment:
+		replicas = w.Spec.Replicas
+	case *appsv1.StatefulSet:
+		replicas = w.Spec.Replicas
+	case *unstructured.Unstructured:
+		if value, found, _ := unstructured.NestedInt64(w.Object, "spec", "replicas"); found { //nolint:errcheck
+			replicas = ptr.To(int32(value))
+		}
+	}
+
+	if replicas != nil && *replicas == 0 {
+		ctx.logger.V(4).Info("Workload already at replicas=0, treating pause as no-op", "workload", name)
 		return nil
 	}
 
 	patch := client.RawPatch(types.MergePatchType, []byte(`{"spec":{"replicas":0}}`))
-	if err := ctx.ctrClient.Patch(context.Background(), dep, patch); err != nil {
+	if err := ctx.ctrClient.Patch(context.Background(), workload, patch); err != nil {
 		return errors.Wrapf(err, "failed to patch endpoint %s replicas to 0", ctx.Endpoint.Metadata.WorkspaceName())
 	}
 
@@ -455,6 +747,11 @@ func (k *kubernetesOrchestrator) deleteEndpoint(ctx *OrchestratorContext) error
 		"deployment",
 	).WithLogger(ctx.logger)
 
+	// The image canary is not managed by the deployer
+	if err := k.deleteImageCanary(ctx, util.ClusterNamespace(ctx.Cluster)); err != nil {
+		return err
+	}
+
 	// Delete all resources
 	deleteFinished, err := applier.Delete(context.Background())
 	if err != nil {
@@ -494,17 +791,12 @@ func (k *kubernetesOrchestrator) getEndpointStats(
 ) (*v1.EndpointStatus, error) {
 	var exists bool
 
-	// now we only use deployment to deploy endpoint,
-	// but in the future, we may support more deploy mode, like distribute inference or pd inference,
-	// and then, we may expand the status checking logic.
-	dep := &appsv1.Deployment{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      endpoint.Metadata.Name,
-			Namespace: namespace,
-		},
-	}
+	// The replicas run in a Deployment, or in a StatefulSet with peer discovery or a
+	// LeaderWorkerSet when they span several nodes, whose status is checked through a
+	// Deployment view of them.
+	workload := endpointWorkload(endpoint, namespace)
 
-	err := ctrlClient.Get(context.Background(), client.ObjectKeyFromObject(dep), dep)
+	err := ctrlClient.Get(context.Background(), client.ObjectKeyFromObject(workload), workload)
 	if err != nil {
 		if apierrors.IsNotFound(err) {
 			exists = false
@@ -515,6 +807,17 @@ func (k *kubernetesOrchestrator) getEndpointStats(
 		exists = true
 	}
 
+	var dep *appsv1.Deployment
+
+	switch w := workload.(type) {
+	case *appsv1.Deployment:
+		dep = w
+	case *appsv1.StatefulSet:
+		dep = statefulSetStatusView(w)
+	case *unstructured.Unstructured:
+		dep = leaderWorkerSetStatusView(w)
+	}
+
 	isDeleting := endpoint.GetDeletionTimestamp() != ""
 
 	if isDeleting {
@@ -584,26 +887,55 @@ func (k *kubernetesOrchestrator) getEndpointStats(
 		klog.Warningf("failed to build resource status for endpoint %s: %v", endpoint.Metadata.WorkspaceName(), err)
 	}
 
-	// Check if all pods are ready and updated
+	replicas := podReplicaStatuses(pods)
+
+	// Check if all pods are ready and updated. A ready deployment can still have pods
+	// crashing behind it, so the pods have the final say on how healthy it is.
 	if util.IsDeploymentUpdatedAndReady(dep) {
-		return &v1.EndpointStatus{
+		spotStatus, err := k.spotReplicaGroupStatus(ctrlClient, namespace, endpoint)
+		if err != nil {
+			return nil, err
+		}
+
+		status := *mergeEndpointStatus(&v1.EndpointStatus{
 			Phase:     v1.EndpointPhaseRUNNING,
 			Resources: resources,
-		}, nil
+		}, podWorkloadStatus(pods), spotStatus)
+		status.Replicas = replicas
+
+		return &status, nil
 	}
 
 	if hasFailed, failedMsg := k.checkPodFailures(pods); hasFailed {
-		return &v1.EndpointStatus{
-			Phase:        v1.EndpointPhaseFAILED,
-			ErrorMessage: "Endpoint failed: " + failedMsg,
-			Resources:    resources,
-		}, nil
+		status := rolloutFailureStatus(dep, failedMsg, resources)
+		status.Replicas = replicas
+
+		return status, nil
+	}
+
+	timeouts, err := getPhaseTimeouts(endpoint)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid deployment options of endpoint %s", endpoint.Metadata.WorkspaceName())
+	}
+
+	if timedOut, timeoutMsg := checkPhaseTimeouts(pods, timeouts, time.Now()); timedOut {
+		status := rolloutFailureStatus(dep, timeoutMsg, resources)
+		status.Replicas = replicas
+
+		return status, nil
 	}
 
 	if hasIncomplete, detail := hasIncompleteModelDownloaderInitContainer(pods); hasIncomplete {
+		progress, rateLimit := k.getModelDownloadProgress(cluster, namespace, pods)
+		if rateLimit != "" {
+			detail += ": " + rateLimit
+		}
+
 		return &v1.EndpointStatus{
-			Phase:        v1.EndpointPhaseMODELDOWNLOADING,
-			ErrorMessage: "Endpoint model download in progress: " + detail,
+			Phase:                 v1.EndpointPhaseMODELDOWNLOADING,
+			ErrorMessage:          "Endpoint model download in progress: " + detail,
+			ModelDownloadProgress: progress,
+			Replicas:              replicas,
 		}, nil
 	}
 
@@ -614,6 +946,7 @@ func (k *kubernetesOrchestrator) getEndpointStats(
 		Phase:        v1.EndpointPhaseDEPLOYING,
 		ErrorMessage: "Endpoint deploying in progress: " + errorMessage,
 		Resources:    resources,
+		Replicas:     replicas,
 	}, nil
 }
 
@@ -648,6 +981,56 @@ func (k *kubernetesOrchestrator) listPods(ctrlClient client.Client, namespace st
 	return podList.Items, nil
 }
 
+// getModelDownloadProgress reads the latest progress reported by a running model-downloader
+// init container, along with the rate limit of the model registry holding the download back,
+// see parseModelDownloadRateLimit. Progress is best effort, so failures are logged and nil is
+// returned.
+func (k *kubernetesOrchestrator) getModelDownloadProgress(cluster *v1.Cluster, namespace string,
+	pods []corev1.Pod) (*v1.ModelDownloadProgress, string) {
+	if k.k8sClient == nil {
+		return nil, ""
+	}
+
+	var rateLimit string
+
+	for _, pod := range pods {
+		for _, initStatus := range pod.Status.InitContainerStatuses {
+			if initStatus.Name != modelDownloaderInitContainerName || initStatus.State.Running == nil {
+				continue
+			}
+
+			tailLines := int64(modelDownloadLogTailLines)
+
+			logs, err := k.k8sClient.GetPodLogs(context.Background(), cluster, namespace, pod.Name, &corev1.PodLogOptions{
+				Container: modelDownloaderInitContainerName,
+				TailLines: &tailLines,
+			})
+			if err != nil {
+				klog.V(4).Infof("failed to get %s logs of pod %s: %v", modelDownloaderInitContainerName, pod.Name, err)
+				continue
+			}
+
+			content, err := io.ReadAll(logs)
+			logs.Close()
+
+			if err != nil {
+				klog.V(4).Infof("failed to read %s logs of pod %s: %v", modelDownloaderInitContainerName, pod.Name, err)
+				continue
+			}
+
+			if rateLimit == "" {
+				rateLimit = parseModelDownloadRateLimit(string(content))
+			}
+
+			if progress := parseModelDownloadProgress(string(content)); progress != nil {
+				return progress, rateLimit
+			}
+		}
+	}
+
+	return nil, rateLimit
+}
+
 func hasIncompleteModelDownloaderInitContainer(pods []corev1.Pod) (bool, string) {
 	for _, pod := range pods {
 		for _, initStatus := range pod.Status.InitContainerStatuses {
@@ -2,23 +2,45 @@ package orchestrator
 
 import (
 	"encoding/json"
+	"fmt"
 	"maps"
 	"math"
 	"net/url"
 	"path"
 	"path/filepath"
 	"reflect"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
 
 	v1 "github.com/neutree-ai/neutree/api/v1"
+	"github.com/neutree-ai/neutree/internal/accelerator/plugin"
 	"github.com/neutree-ai/neutree/internal/cluster"
 	"github.com/neutree-ai/neutree/internal/util"
 )
 
+// writableHomePath is the home directory of the engines when their root filesystem is read-only.
+const writableHomePath = "/var/lib/neutree/home"
+
+// writableRootPaths are the paths the engines write to, kept writable by emptyDir volumes when
+// the root filesystem of the containers is read-only.
+var writableRootPaths = []struct {
+	name string
+	path string
+}{
+	{name: "writable-tmp", path: "/tmp"},
+	{name: "writable-home", path: writableHomePath},
+	{name: "writable-logs", path: "/var/log"},
+}
+
 type DeploymentManifestVariables struct {
 	EndpointName    string
 	Namespace       string
@@ -29,6 +51,7 @@ type DeploymentManifestVariables struct {
 	ImagePrefix     string
 	ImageRepo       string
 	ImageTag        string
+	ImageDigest     string // pins the engine image to a manifest digest, empty deploys by tag
 	ImagePullSecret string
 	ModelArgs       map[string]interface{}
 	EngineArgs      map[string]interface{}
@@ -39,11 +62,61 @@ type DeploymentManifestVariables struct {
 	VolumeMounts    []corev1.VolumeMount
 	RoutingLogic    string
 	Replicas        int32
+	// WorkerReplicas is the number of worker pods of each replica next to its leader pod, 0
+	// unless the replicas span several nodes. The replicas then run in a LeaderWorkerSet, built
+	// from the rendered Deployment unless the template renders one of its own.
+	WorkerReplicas  int32
 	NodeSelector    map[string]string
+	Tolerations     []corev1.Toleration
 	NeutreeVersion  string
+	HealthCheckPath string // engine readiness probe path for the model task, empty keeps the template default
+	// StartupFailureThreshold is the engine startup probe failureThreshold, scaled to the model size.
+	StartupFailureThreshold int
+	PriorityClassName       string // pod priority class of the engine replicas, empty keeps the cluster default
+	ServiceMesh             string // service mesh the engine replicas join, empty if none
+	ReadOnlyRootFilesystem  bool   // mounts the root filesystem of the containers read-only
+	// ResourceRequests are the requests of the engine container, Resources being its limits.
+	// CPU and memory requests are scaled down by the overcommit ratios of the cluster.
+	ResourceRequests map[string]string
+	// Lifecycle holds the post_start and pre_stop hooks of the engine container, nil if none.
+	Lifecycle *corev1.Lifecycle
+	// TerminationGracePeriodSeconds is how long a stopped replica may take to exit, nil keeps
+	// the Kubernetes default.
+	TerminationGracePeriodSeconds *int64
+
+	// ModelDownloaderImagePullPolicy overrides the pull policy of the model-downloader
+	// init container only; the engine container keeps its own policy.
+	ModelDownloaderImagePullPolicy string
+	// ModelDownloaderEnv holds downloader-only settings such as retry/backoff.
+	ModelDownloaderEnv map[string]string
+	// ModelConversion is the init container converting the downloaded model before the engine
+	// starts, nil if the endpoint does not convert its model.
+	ModelConversion *corev1.Container
+	// DeploymentStrategy replaces the rolling update of the deployment templates, nil keeps it.
+	DeploymentStrategy *appsv1.DeploymentStrategy
+	// EnginePort is the port the engine listens on, 0 keeps the template default.
+	EnginePort int
+	// EngineHost is the address the engine listens on, empty keeps the template default of
+	// all the addresses of the pod.
+	EngineHost string
+	// ProbePort is the port the engine probes are sent to, 0 probes the engine port.
+	ProbePort int
+	// AuthSidecar is the container authenticating the requests before they reach the engine,
+	// nil if the endpoint has no auth sidecar. Without an image it runs the auth proxy of the
+	// neutree runtime image.
+	AuthSidecar *corev1.Container
 }
 
 func buildDeploymentObjects(deployTemplate string, renderVars DeploymentManifestVariables) (*unstructured.UnstructuredList, error) {
+	// The runtime image follows NeutreeVersion, which is only settled once the version of
+	// the deployed replicas is preserved.
+	if renderVars.AuthSidecar != nil && renderVars.AuthSidecar.Image == "" {
+		sidecar := renderVars.AuthSidecar.DeepCopy()
+		sidecar.Image = renderVars.ImagePrefix + "/neutree/neutree-runtime:" + renderVars.NeutreeVersion
+		sidecar.Command = []string{"python3", "-m", "neutree.authproxy"}
+		renderVars.AuthSidecar = sidecar
+	}
+
 	return util.RenderKubernetesManifest(deployTemplate, renderVars)
 }
 
@@ -59,9 +132,11 @@ func (k *kubernetesOrchestrator) setBasicVariables(data *DeploymentManifestVaria
 	data.Replicas = int32(*endpoint.Spec.Replicas.Num)
 	data.RoutingLogic = "roundrobin"
 	data.NeutreeVersion = deployedCluster.Spec.Version
+	data.PriorityClassName = endpoint.Spec.PriorityClassName
+	data.DeploymentStrategy = kubernetesDeploymentStrategy(endpoint)
 }
 
-// setDeployImageVariables sets the container image repository and tag for deployment
+// setDeployImageVariables sets the container image repository, tag and pinned digest for deployment
 func (k *kubernetesOrchestrator) setDeployImageVariables(data *DeploymentManifestVariables,
 	endpoint *v1.Endpoint, engine *v1.Engine, imageRegistry *v1.ImageRegistry) error {
 	imagePrefix, err := util.GetImagePrefix(imageRegistry)
@@ -81,6 +156,13 @@ func (k *kubernetesOrchestrator) setDeployImageVariables(data *DeploymentManifes
 	data.ImageRepo = imageName
 	data.ImageTag = imageTag
 
+	image := imagePrefix + "/" + imageName + ":" + imageTag
+
+	data.ImageDigest, err = resolveEngineImageDigest(k.storage, k.imageService, endpoint, imageRegistry, image)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -152,7 +234,7 @@ func setEngineTensorParallelDefault(data *DeploymentManifestVariables, endpoint
 }
 
 // setEngineArgs sets engine arguments from endpoint variables
-func (k *kubernetesOrchestrator) setEngineArgs(data *DeploymentManifestVariables, endpoint *v1.Endpoint, engine *v1.Engine) {
+func (k *kubernetesOrchestrator) setEngineArgs(data *DeploymentManifestVariables, endpoint *v1.Endpoint, engine *v1.Engine) error {
 	// Set engine-specific default arguments first
 	k.setEngineDefaultArgs(data, engine)
 
@@ -164,15 +246,53 @@ func (k *kubernetesOrchestrator) setEngineArgs(data *DeploymentManifestVariables
 	}
 
 	setDefaultSGLangEnableMetrics(engine.Metadata.Name, data.EngineArgs)
+	setDefaultSamplingParamsArg(endpoint, data.EngineArgs)
 
 	// Auto-set tensor-parallel-size after user args are merged, so we can
 	// detect user-provided values in either key format. Applies to engines
 	// that expose a TP arg (vLLM / SGLang).
 	setEngineTensorParallelDefault(data, endpoint, engine)
 
+	if err := normalizeBooleanEngineArgs(data.EngineArgs, engine.Metadata.Name); err != nil {
+		return err
+	}
+
 	// Prepare engine arg values for YAML double-quoted template rendering.
 	// Handles native maps, unescaped JSON strings, and pre-escaped strings.
 	prepareEngineArgsForTemplate(data.EngineArgs, engine.Metadata.Name)
+
+	return nil
+}
+
+// normalizeBooleanEngineArgs rejects null engine args, which would render as a flag without
+// a meaningful value. The vLLM and SGLang templates render true as a bare flag and leave
+// false out, since their store_true flags reject "--flag false": for them the "true" and
+// "false" strings in any case are turned into booleans so "True" is not passed as a value.
+// Other engines get booleans as values.
+func normalizeBooleanEngineArgs(args map[string]interface{}, engineName string) error {
+	for key, value := range args {
+		if value == nil {
+			return errors.Errorf("engine_args.%s must not be null, set it to false to leave the flag out", key)
+		}
+
+		if engineName != v1.EngineNameVLLM && engineName != v1.EngineNameSGLang {
+			continue
+		}
+
+		str, ok := value.(string)
+		if !ok {
+			continue
+		}
+
+		switch strings.ToLower(strings.TrimSpace(str)) {
+		case "true":
+			args[key] = true
+		case "false":
+			args[key] = false
+		}
+	}
+
+	return nil
 }
 
 func prepareEngineArgsForTemplate(args map[string]interface{}, engineName string) {
@@ -287,8 +407,9 @@ func escapeForYAMLDoubleQuote(s string) string {
 	return s
 }
 
-// setResourceVariables sets resource specifications and node selector
-func (k *kubernetesOrchestrator) setResourceVariables(data *DeploymentManifestVariables, endpoint *v1.Endpoint) error {
+// setResourceVariables sets resource specifications, node selector and tolerations
+func (k *kubernetesOrchestrator) setResourceVariables(data *DeploymentManifestVariables, endpoint *v1.Endpoint,
+	deployedCluster *v1.Cluster) error {
 	resourceSpec, err := convertToKubernetes(k.acceleratorMgr, endpoint.Spec.Resources)
 	if err != nil {
 		return errors.Wrapf(err, "failed to convert resources for endpoint %s", endpoint.Metadata.Name)
@@ -300,6 +421,7 @@ func (k *kubernetesOrchestrator) setResourceVariables(data *DeploymentManifestVa
 
 	if resourceSpec.Requests != nil {
 		maps.Copy(data.Resources, resourceSpec.Requests)
+		maps.Copy(data.ResourceRequests, resourceSpec.Requests)
 	}
 
 	if resourceSpec.NodeSelector != nil {
@@ -314,6 +436,286 @@ func (k *kubernetesOrchestrator) setResourceVariables(data *DeploymentManifestVa
 		maps.Copy(data.Env, resourceSpec.Env)
 	}
 
+	var kubernetesConfig *v1.KubernetesClusterConfig
+	if deployedCluster.Spec != nil && deployedCluster.Spec.Config != nil {
+		kubernetesConfig = deployedCluster.Spec.Config.KubernetesConfig
+	}
+
+	// the accelerator converters tolerate the standard taint of the accelerator nodes,
+	// clusters tainting them differently opt out and configure their own tolerations.
+	if kubernetesConfig == nil || !kubernetesConfig.DisableAcceleratorTolerations {
+		data.Tolerations = append(data.Tolerations, resourceSpec.Tolerations...)
+	}
+
+	if err = setGPUResourceName(data, endpoint, kubernetesConfig); err != nil {
+		return errors.Wrapf(err, "failed to select GPU resource name for endpoint %s", endpoint.Metadata.Name)
+	}
+
+	if kubernetesConfig != nil {
+		data.Tolerations = append(data.Tolerations, kubernetesConfig.Tolerations...)
+
+		if err = overcommitResourceRequests(data.ResourceRequests, kubernetesConfig.Overcommit); err != nil {
+			return errors.Wrapf(err, "failed to overcommit resources for endpoint %s", endpoint.Metadata.Name)
+		}
+	}
+
+	return nil
+}
+
+// setGPUResourceName requests the NVIDIA GPUs under the resource name of the device plugin
+// mode the endpoint selects, or else the one of the cluster. The toleration keeps the
+// nvidia.com/gpu key, GPU nodes are tainted the same in every mode.
+func setGPUResourceName(data *DeploymentManifestVariables, endpoint *v1.Endpoint,
+	kubernetesConfig *v1.KubernetesClusterConfig) error {
+	devicePlugin, err := getGPUDevicePlugin(endpoint)
+	if err != nil {
+		return err
+	}
+
+	if devicePlugin == "" && kubernetesConfig != nil {
+		devicePlugin = kubernetesConfig.GPUDevicePlugin
+	}
+
+	resourceName, err := plugin.NvidiaGPUResourceName(devicePlugin)
+	if err != nil {
+		return err
+	}
+
+	if resourceName == plugin.NvidiaGPUKubernetesResource {
+		return nil
+	}
+
+	for _, resources := range []map[string]string{data.Resources, data.ResourceRequests} {
+		if count, ok := resources[plugin.NvidiaGPUKubernetesResource.String()]; ok {
+			delete(resources, plugin.NvidiaGPUKubernetesResource.String())
+			resources[resourceName.String()] = count
+		}
+	}
+
+	return nil
+}
+
+// overcommitResourceRequests scales the CPU and memory requests down by the overcommit ratios.
+// Accelerator requests stay equal to their limits, kubernetes can not overcommit them.
+func overcommitResourceRequests(requests map[string]string, overcommit *v1.OvercommitConfig) error {
+	if overcommit == nil {
+		return nil
+	}
+
+	for name, ratio := range map[string]float64{
+		string(corev1.ResourceCPU):    overcommit.CPURatio,
+		string(corev1.ResourceMemory): overcommit.MemoryRatio,
+	} {
+		request, ok := requests[name]
+		if !ok || ratio <= 1 {
+			continue
+		}
+
+		quantity, err := resource.ParseQuantity(request)
+		if err != nil {
+			return errors.Wrapf(err, "invalid %s request %s", name, request)
+		}
+
+		if name == string(corev1.ResourceCPU) {
+			requests[name] = resource.NewMilliQuantity(int64(float64(quantity.MilliValue())/ratio), resource.DecimalSI).String()
+		} else {
+			requests[name] = resource.NewQuantity(int64(float64(quantity.Value())/ratio), resource.BinarySI).String()
+		}
+	}
+
+	return nil
+}
+
+// setTolerationVariables adds the tolerations of deployment_options.tolerations after the
+// accelerator and cluster ones.
+func (k *kubernetesOrchestrator) setTolerationVariables(data *DeploymentManifestVariables, endpoint *v1.Endpoint) error {
+	tolerations, err := getTolerations(endpoint)
+	if err != nil {
+		return err
+	}
+
+	data.Tolerations = append(data.Tolerations, tolerations...)
+
+	return nil
+}
+
+// setLifecycleVariables sets the post_start and pre_stop hooks the endpoint configures on its
+// engine container, and the time its replicas are given to stop.
+func (k *kubernetesOrchestrator) setLifecycleVariables(data *DeploymentManifestVariables, endpoint *v1.Endpoint) error {
+	lifecycle, err := getLifecycle(endpoint)
+	if err != nil {
+		return err
+	}
+
+	data.Lifecycle = lifecycle
+
+	data.TerminationGracePeriodSeconds, err = getTerminationGracePeriodSeconds(endpoint)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// setSecurityContextVariables mounts the root filesystem of the containers read-only when the
+// endpoint asks for it. The engines write temporary files, caches under their home directory
+// and logs, these paths get emptyDir volumes so the engines do not crash on a read-only root.
+func (k *kubernetesOrchestrator) setSecurityContextVariables(data *DeploymentManifestVariables, endpoint *v1.Endpoint) error {
+	opts, err := getSecurityContextOptions(endpoint)
+	if err != nil || opts == nil || !opts.ReadOnlyRootFilesystem {
+		return err
+	}
+
+	data.ReadOnlyRootFilesystem = true
+
+	for _, writable := range writableRootPaths {
+		data.Volumes = append(data.Volumes, corev1.Volume{
+			Name:         writable.name,
+			VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+		})
+
+		data.VolumeMounts = append(data.VolumeMounts, corev1.VolumeMount{
+			Name:      writable.name,
+			MountPath: writable.path,
+		})
+	}
+
+	// The home directory of the engine images is part of their root filesystem, HOME points
+	// the engines at a writable one instead. The endpoint env still overrides it.
+	data.Env["HOME"] = writableHomePath
+
+	return nil
+}
+
+// modelConversionLockScript runs the conversion command passed as its arguments holding an
+// exclusive lock of the model directory, so the replicas sharing the model cache, e.g. on
+// NFS, convert the model one after another and the later ones find the conversion done. The
+// lock is released when the command exits, even if it crashed. Images without flock run the
+// command without the lock.
+const modelConversionLockScript = `lock="$NEUTREE_MODEL_PATH/.neutree-conversion.lock"
+if command -v flock >/dev/null 2>&1; then
+  exec flock "$lock" "$@"
+fi
+echo "flock not found, converting the model without locking $lock" >&2
+exec "$@"`
+
+// setModelConversionVariables sets the init container running the model conversion of the
+// endpoint. It runs after the model-downloader with the env, volumes and resources of the
+// engine container, init containers running one at a time before it, so the conversion does
+// not add to the resources a replica is scheduled with. The conversion holds the lock of
+// modelConversionLockScript.
+func (k *kubernetesOrchestrator) setModelConversionVariables(data *DeploymentManifestVariables, endpoint *v1.Endpoint) error {
+	opts, err := getModelConversionOptions(endpoint)
+	if err != nil || opts == nil {
+		return err
+	}
+
+	image := opts.Image
+	if image == "" {
+		image = data.ImagePrefix + "/" + data.ImageRepo + ":" + data.ImageTag
+		if data.ImageDigest != "" {
+			image += "@" + data.ImageDigest
+		}
+	}
+
+	container := &corev1.Container{
+		Name:         modelConversionInitContainerName,
+		Image:        image,
+		Command:      []string{"sh", "-c", modelConversionLockScript, modelConversionInitContainerName},
+		Args:         opts.Command,
+		VolumeMounts: data.VolumeMounts,
+		Resources: corev1.ResourceRequirements{
+			Limits:   corev1.ResourceList{},
+			Requests: corev1.ResourceList{},
+		},
+	}
+
+	for name, value := range data.Resources {
+		quantity, err := resource.ParseQuantity(value)
+		if err != nil {
+			return errors.Wrapf(err, "invalid %s limit %s", name, value)
+		}
+
+		container.Resources.Limits[corev1.ResourceName(name)] = quantity
+	}
+
+	for name, value := range data.ResourceRequests {
+		quantity, err := resource.ParseQuantity(value)
+		if err != nil {
+			return errors.Wrapf(err, "invalid %s request %s", name, value)
+		}
+
+		container.Resources.Requests[corev1.ResourceName(name)] = quantity
+	}
+
+	env := maps.Clone(data.Env)
+	env[modelConversionModelPathEnv] = fmt.Sprintf("%v", data.ModelArgs["path"])
+
+	for _, name := range slices.Sorted(maps.Keys(env)) {
+		container.Env = append(container.Env, corev1.EnvVar{Name: name, Value: env[name]})
+	}
+
+	if data.ReadOnlyRootFilesystem {
+		container.SecurityContext = &corev1.SecurityContext{ReadOnlyRootFilesystem: ptr.To(true)}
+	}
+
+	data.ModelConversion = container
+
+	return nil
+}
+
+// setAuthSidecarVariables moves the engine off the serving port and puts the auth sidecar on
+// it, so the requests sent to the replicas through the router or the Service are
+// authenticated first. The engine only listens on the pod loopback address, so it can not be
+// reached around the sidecar, and is probed through it.
+func (k *kubernetesOrchestrator) setAuthSidecarVariables(data *DeploymentManifestVariables, endpoint *v1.Endpoint) error {
+	opts, err := getAuthSidecarOptions(endpoint)
+	if err != nil || opts == nil {
+		return err
+	}
+
+	data.EnginePort = authSidecarEnginePort
+	data.EngineHost = authSidecarEngineHost
+	data.ProbePort = engineServingPort
+
+	// The default health check paths of the deploy templates.
+	healthCheckPath := data.HealthCheckPath
+	if healthCheckPath == "" {
+		healthCheckPath = "/health"
+		if data.EngineName == v1.EngineNameLlamaCpp {
+			healthCheckPath = "/v1/models"
+		}
+	}
+
+	port := corev1.ContainerPort{ContainerPort: engineServingPort}
+	if data.ServiceMesh != "" {
+		port.Name = "http"
+	}
+
+	container := &corev1.Container{
+		Name:  authSidecarContainerName,
+		Image: opts.Image,
+		Env: []corev1.EnvVar{
+			{Name: authSidecarListenPortEnv, Value: strconv.Itoa(engineServingPort)},
+			{Name: authSidecarUpstreamEnv, Value: fmt.Sprintf("http://%s:%d", authSidecarEngineHost, authSidecarEnginePort)},
+			{Name: authSidecarValidateURLEnv, Value: opts.ValidateURL},
+			{Name: authSidecarPublicPathsEnv, Value: healthCheckPath + ",/metrics"},
+		},
+		Ports: []corev1.ContainerPort{port},
+		ReadinessProbe: &corev1.Probe{
+			ProbeHandler: corev1.ProbeHandler{
+				TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromInt32(engineServingPort)},
+			},
+			PeriodSeconds: 10,
+		},
+	}
+
+	if data.ReadOnlyRootFilesystem {
+		container.SecurityContext = &corev1.SecurityContext{ReadOnlyRootFilesystem: ptr.To(true)}
+	}
+
+	data.AuthSidecar = container
+
 	return nil
 }
 
@@ -363,19 +765,34 @@ func (k *kubernetesOrchestrator) setModelArgs(data *DeploymentManifestVariables,
 	maps.Copy(data.ModelArgs, modelArgs)
 }
 
-// setModelRegistryVariables adapts model registry specific settings
-func (k *kubernetesOrchestrator) setModelRegistryVariables(data *DeploymentManifestVariables, endpoint *v1.Endpoint,
-	deployedCluster *v1.Cluster, modelRegistry *v1.ModelRegistry) error {
-	modelCacheRelativePath := v1.DefaultModelCacheRelativePath
+// setHealthCheckPath selects the readiness probe path the engine version declares for the endpoint's task
+func (k *kubernetesOrchestrator) setHealthCheckPath(data *DeploymentManifestVariables, endpoint *v1.Endpoint, engine *v1.Engine) {
+	for _, version := range engine.Spec.Versions {
+		if version.Version == endpoint.Spec.Engine.Version {
+			data.HealthCheckPath = version.GetHealthCheckPath(endpoint.Spec.Model.Task)
+			return
+		}
+	}
+}
 
-	modelCaches, err := util.GetClusterModelCache(*deployedCluster)
+// setStartupProbeVariables sizes the engine startup probe window for the endpoint's model
+func (k *kubernetesOrchestrator) setStartupProbeVariables(data *DeploymentManifestVariables, endpoint *v1.Endpoint) error {
+	seconds, err := startupTimeoutSeconds(endpoint)
 	if err != nil {
-		return errors.Wrapf(err, "failed to get model caches")
+		return err
 	}
 
-	// TODO: Now we only use the first model cache for simplicity, In the future, we may support specific model cache.
-	if len(modelCaches) > 0 {
-		modelCacheRelativePath = modelCaches[0].Name
+	data.StartupFailureThreshold = startupFailureThreshold(seconds)
+
+	return nil
+}
+
+// setModelRegistryVariables adapts model registry specific settings
+func (k *kubernetesOrchestrator) setModelRegistryVariables(data *DeploymentManifestVariables, endpoint *v1.Endpoint,
+	deployedCluster *v1.Cluster, modelRegistry *v1.ModelRegistry) error {
+	modelCacheName, err := getModelCacheName(endpoint, deployedCluster)
+	if err != nil {
+		return err
 	}
 
 	switch modelRegistry.Spec.Type {
@@ -392,7 +809,7 @@ func (k *kubernetesOrchestrator) setModelRegistryVariables(data *DeploymentManif
 			// bentoml model registry path: <BENTOML_HOME>/models/<model_name>/<model_version>
 			// so we need to append "models" to the path
 			data.ModelArgs["registry_path"] = filepath.Join(mountPath, "models", endpoint.Spec.Model.Name, modelRealVersion)
-			data.ModelArgs["path"] = filepath.Join(v1.DefaultK8sClusterModelCacheMountPath, modelCacheRelativePath, endpoint.Spec.Model.Name, modelRealVersion)
+			data.ModelArgs["path"] = util.ModelCacheModelPath(v1.DefaultK8sClusterModelCacheMountPath, deployedCluster, modelCacheName, modelRegistry.Spec.Type, endpoint.Spec.Model.Name, modelRealVersion)
 
 			data.Volumes = append(data.Volumes, corev1.Volume{
 				Name: "bentoml-model-registry",
@@ -423,24 +840,77 @@ func (k *kubernetesOrchestrator) setModelRegistryVariables(data *DeploymentManif
 
 		data.ModelArgs["version"] = modelRealVersion
 		data.ModelArgs["registry_path"] = endpoint.Spec.Model.Name
-		data.ModelArgs["path"] = filepath.Join(v1.DefaultK8sClusterModelCacheMountPath, modelCacheRelativePath, endpoint.Spec.Model.Name, modelRealVersion)
+		data.ModelArgs["path"] = util.ModelCacheModelPath(v1.DefaultK8sClusterModelCacheMountPath, deployedCluster, modelCacheName, modelRegistry.Spec.Type, endpoint.Spec.Model.Name, modelRealVersion)
 	}
 
 	return nil
 }
 
-// addSharedMemoryVolume adds shared memory volume to the deployment
-func (k *kubernetesOrchestrator) addSharedMemoryVolume(data *DeploymentManifestVariables) {
+// setModelDownloaderVariables sets model-downloader init container settings from deployment options
+func (k *kubernetesOrchestrator) setModelDownloaderVariables(data *DeploymentManifestVariables, endpoint *v1.Endpoint) error {
+	opts, err := getModelDownloaderOptions(endpoint)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse model downloader options for endpoint %s", endpoint.Metadata.Name)
+	}
+
+	data.ModelDownloaderImagePullPolicy = string(opts.ImagePullPolicy)
+
+	for key, value := range opts.Env() {
+		// values set explicitly in endpoint env take precedence and are already rendered from data.Env
+		if _, exists := data.Env[key]; exists {
+			continue
+		}
+
+		data.ModelDownloaderEnv[key] = value
+	}
+
+	if opts.CredentialsSecret != "" {
+		k.addRegistryCredentialsVolume(data, opts.CredentialsSecret)
+	}
+
+	if opts.Lease {
+		data.ModelDownloaderEnv[modelDownloaderLeaseEnv] = downloadLeasePrefix(data.EndpointName)
+	}
+
+	return nil
+}
+
+const (
+	registryCredentialsVolumeName = "registry-credentials"
+	registryCredentialsMountPath  = "/var/run/neutree/registry-credentials"
+	registryCredentialsTokenKey   = "token"
+)
+
+// addRegistryCredentialsVolume mounts the Secret holding the model registry credential and
+// points the model-downloader at its token file. The kubelet keeps mounted Secrets in sync,
+// so the model-downloader reads a rotated credential from the same file.
+func (k *kubernetesOrchestrator) addRegistryCredentialsVolume(data *DeploymentManifestVariables, secretName string) {
 	data.Volumes = append(data.Volumes, corev1.Volume{
-		Name: "dshm",
+		Name: registryCredentialsVolumeName,
 		VolumeSource: corev1.VolumeSource{
-			EmptyDir: &corev1.EmptyDirVolumeSource{
-				// none size limit mean the sharing memory size equals node allocated memory.
-				Medium: corev1.StorageMediumMemory,
+			Secret: &corev1.SecretVolumeSource{
+				SecretName: secretName,
+				Items:      []corev1.KeyToPath{{Key: registryCredentialsTokenKey, Path: registryCredentialsTokenKey}},
 			},
 		},
 	})
 
+	data.VolumeMounts = append(data.VolumeMounts, corev1.VolumeMount{
+		Name:      registryCredentialsVolumeName,
+		MountPath: registryCredentialsMountPath,
+		ReadOnly:  true,
+	})
+
+	data.ModelDownloaderEnv[modelDownloaderTokenFileEnv] = filepath.Join(registryCredentialsMountPath, registryCredentialsTokenKey)
+}
+
+// addSharedMemoryVolume adds shared memory volume to the deployment
+func (k *kubernetesOrchestrator) addSharedMemoryVolume(data *DeploymentManifestVariables, sharedWeights bool) {
+	data.Volumes = append(data.Volumes, corev1.Volume{
+		Name:         "dshm",
+		VolumeSource: sharedMemoryVolumeSource(data, sharedWeights),
+	})
+
 	data.VolumeMounts = append(data.VolumeMounts, corev1.VolumeMount{
 		Name:      "dshm",
 		MountPath: "/dev/shm",
@@ -464,10 +934,47 @@ func (k *kubernetesOrchestrator) buildManifestVariables(endpoint *v1.Endpoint, d
 	k.setRoutingLogic(&data, endpoint)
 
 	// Set engine args
-	k.setEngineArgs(&data, endpoint, engine)
+	if err := k.setEngineArgs(&data, endpoint, engine); err != nil {
+		return DeploymentManifestVariables{}, err
+	}
 
 	// Set resource variables
-	if err := k.setResourceVariables(&data, endpoint); err != nil {
+	if err := k.setResourceVariables(&data, endpoint, deployedCluster); err != nil {
+		return DeploymentManifestVariables{}, err
+	}
+
+	// Set the tolerations of the endpoint itself, e.g. for team-dedicated node pools
+	if err := k.setTolerationVariables(&data, endpoint); err != nil {
+		return DeploymentManifestVariables{}, err
+	}
+
+	// Set topology-aware placement of multi-GPU replicas
+	if err := k.setTopologyAwarePlacement(&data, endpoint, deployedCluster); err != nil {
+		return DeploymentManifestVariables{}, err
+	}
+
+	// Set service mesh sidecar injection
+	if err := k.setServiceMeshVariables(&data, endpoint, deployedCluster); err != nil {
+		return DeploymentManifestVariables{}, err
+	}
+
+	// Set read-only root filesystem and the writable volumes it needs
+	if err := k.setSecurityContextVariables(&data, endpoint); err != nil {
+		return DeploymentManifestVariables{}, err
+	}
+
+	// Set the lifecycle hooks of the engine container and the termination grace period
+	if err := k.setLifecycleVariables(&data, endpoint); err != nil {
+		return DeploymentManifestVariables{}, err
+	}
+
+	// Set the worker pods of replicas spanning several nodes
+	if err := k.setMultiNodeVariables(&data, endpoint); err != nil {
+		return DeploymentManifestVariables{}, err
+	}
+
+	// Set the DNS names of the peer replicas
+	if err := k.setPeerDiscoveryVariables(&data, endpoint); err != nil {
 		return DeploymentManifestVariables{}, err
 	}
 
@@ -482,13 +989,47 @@ func (k *kubernetesOrchestrator) buildManifestVariables(endpoint *v1.Endpoint, d
 	// Set model args
 	k.setModelArgs(&data, endpoint, modelRegistry)
 
+	// Set task specific health check path
+	k.setHealthCheckPath(&data, endpoint, engine)
+
+	// Set startup probe window
+	if err := k.setStartupProbeVariables(&data, endpoint); err != nil {
+		return DeploymentManifestVariables{}, err
+	}
+
+	// Validate the phase timeouts the endpoint status is checked against
+	if _, err := getPhaseTimeouts(endpoint); err != nil {
+		return DeploymentManifestVariables{}, err
+	}
+
 	// Set model registry specific variables
 	if err := k.setModelRegistryVariables(&data, endpoint, deployedCluster, modelRegistry); err != nil {
 		return DeploymentManifestVariables{}, err
 	}
 
+	// Set model downloader init container variables
+	if err := k.setModelDownloaderVariables(&data, endpoint); err != nil {
+		return DeploymentManifestVariables{}, err
+	}
+
+	// Set shared weights variables
+	sharedWeights, err := k.setSharedWeightsVariables(&data, endpoint, engine)
+	if err != nil {
+		return DeploymentManifestVariables{}, err
+	}
+
 	// Add shared memory volume
-	k.addSharedMemoryVolume(&data)
+	k.addSharedMemoryVolume(&data, sharedWeights)
+
+	// Set the model conversion init container last, it mounts the volumes of the engine container
+	if err := k.setModelConversionVariables(&data, endpoint); err != nil {
+		return DeploymentManifestVariables{}, err
+	}
+
+	// Set the auth sidecar in front of the engine
+	if err := k.setAuthSidecarVariables(&data, endpoint); err != nil {
+		return DeploymentManifestVariables{}, err
+	}
 
 	return data, nil
 }
@@ -653,13 +1194,16 @@ func generateModelCacheConfig(modelCaches []v1.ModelCache) ([]corev1.Volume, []c
 
 func newDeploymentManifestVariables() DeploymentManifestVariables {
 	return DeploymentManifestVariables{
-		Resources:    make(map[string]string),
-		NodeSelector: make(map[string]string),
-		Annotations:  make(map[string]string),
-		Env:          make(map[string]string),
-		ModelArgs:    make(map[string]interface{}),
-		EngineArgs:   make(map[string]interface{}),
-		Volumes:      []corev1.Volume{},
-		VolumeMounts: []corev1.VolumeMount{},
+		Resources:        make(map[string]string),
+		ResourceRequests: make(map[string]string),
+		NodeSelector:     make(map[string]string),
+		Annotations:      make(map[string]string),
+		Env:              make(map[string]string),
+		ModelArgs:        make(map[string]interface{}),
+		EngineArgs:       make(map[string]interface{}),
+		Volumes:          []corev1.Volume{},
+		VolumeMounts:     []corev1.VolumeMount{},
+
+		ModelDownloaderEnv: make(map[string]string),
 	}
 }
@@ -3,7 +3,11 @@ package orchestrator
 import (
 	"context"
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 	"testing"
@@ -15,10 +19,16 @@ import (
 	"github.com/neutree-ai/neutree/internal/accelerator/resourceparser"
 	"github.com/neutree-ai/neutree/internal/engine"
 	"github.com/neutree-ai/neutree/internal/util"
+	utilmocks "github.com/neutree-ai/neutree/internal/util/mocks"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -672,6 +682,125 @@ func TestKubernetesOrchestratorValidateDependenciesForAcceleratorVirtualization(
 		require.NoError(t, err)
 	})
 
+	t.Run("rejects multi-model endpoint", func(t *testing.T) {
+		ctx := baseContext()
+		ctx.Endpoint.Spec.Model = &v1.ModelSpec{Name: "model-a"}
+		ctx.Endpoint.Spec.Models = []*v1.ModelSpec{{Name: "model-b"}}
+
+		err := newKubernetesOrchestrator(Options{}).validateDependencies(ctx)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "multi-model endpoints are only supported on ssh clusters")
+	})
+}
+
+func TestKubernetesOrchestratorValidateDependenciesForImageAccelerator(t *testing.T) {
+	baseContext := func() *OrchestratorContext {
+		return &OrchestratorContext{
+			Cluster: &v1.Cluster{
+				Metadata: &v1.Metadata{Name: "cluster", Workspace: "workspace"},
+				Spec:     &v1.ClusterSpec{Type: v1.KubernetesClusterType},
+				Status: &v1.ClusterStatus{
+					Phase: v1.ClusterPhaseRunning,
+					ResourceInfo: &v1.ClusterResources{
+						ResourceStatus: v1.ResourceStatus{
+							Allocatable: &v1.ResourceInfo{
+								AcceleratorGroups: map[v1.AcceleratorType]*v1.AcceleratorGroup{
+									"huawei_npu": {Quantity: 8},
+								},
+							},
+						},
+					},
+				},
+			},
+			Engine: &v1.Engine{
+				Metadata: &v1.Metadata{Name: "vllm", Workspace: "workspace"},
+				Spec: &v1.EngineSpec{
+					Versions: []*v1.EngineVersion{
+						{
+							Version: "v0.17.1",
+							Images: map[string]*v1.EngineImage{
+								"nvidia_gpu": {ImageName: "neutree/vllm-cuda", Tag: "v0.17.1"},
+								"huawei_npu": {ImageName: "neutree/vllm-ascend", Tag: "v0.17.1"},
+							},
+						},
+						{Version: "v0.11.2"},
+					},
+				},
+				Status: &v1.EngineStatus{Phase: v1.EnginePhaseCreated},
+			},
+			ModelRegistry: &v1.ModelRegistry{
+				Metadata: &v1.Metadata{Name: "model-registry", Workspace: "workspace"},
+				Status:   &v1.ModelRegistryStatus{Phase: v1.ModelRegistryPhaseCONNECTED},
+			},
+			ImageRegistry: &v1.ImageRegistry{
+				Metadata: &v1.Metadata{Name: "image-registry", Workspace: "workspace"},
+				Status:   &v1.ImageRegistryStatus{Phase: v1.ImageRegistryPhaseCONNECTED},
+			},
+			Endpoint: &v1.Endpoint{
+				Metadata: &v1.Metadata{Name: "endpoint", Workspace: "workspace"},
+				Spec: &v1.EndpointSpec{
+					Engine: &v1.EndpointEngineSpec{Engine: "vllm", Version: "v0.17.1"},
+					Resources: &v1.ResourceSpec{
+						GPU:         pointer.String("1"),
+						Accelerator: map[string]string{v1.AcceleratorTypeKey: "nvidia_gpu"},
+					},
+				},
+			},
+		}
+	}
+
+	t.Run("rejects image built for an accelerator the cluster does not have", func(t *testing.T) {
+		err := newKubernetesOrchestrator(Options{}).validateDependencies(baseContext())
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `engine workspace/vllm version v0.17.1 image is built for accelerator "nvidia_gpu"`)
+		assert.Contains(t, err.Error(), "only has accelerators [huawei_npu]")
+	})
+
+	t.Run("allows image built for an accelerator of the cluster", func(t *testing.T) {
+		ctx := baseContext()
+		ctx.Endpoint.Spec.Resources.Accelerator[v1.AcceleratorTypeKey] = "huawei_npu"
+
+		require.NoError(t, newKubernetesOrchestrator(Options{}).validateDependencies(ctx))
+	})
+
+	t.Run("allows legacy engine version without images", func(t *testing.T) {
+		ctx := baseContext()
+		ctx.Endpoint.Spec.Engine.Version = "v0.11.2"
+
+		require.NoError(t, newKubernetesOrchestrator(Options{}).validateDependencies(ctx))
+	})
+
+	t.Run("allows cpu image", func(t *testing.T) {
+		ctx := baseContext()
+		ctx.Endpoint.Spec.Resources = &v1.ResourceSpec{CPU: pointer.String("2")}
+
+		require.NoError(t, newKubernetesOrchestrator(Options{}).validateDependencies(ctx))
+	})
+
+	t.Run("allows cluster not reporting its resources yet", func(t *testing.T) {
+		ctx := baseContext()
+		ctx.Cluster.Status.ResourceInfo = nil
+
+		require.NoError(t, newKubernetesOrchestrator(Options{}).validateDependencies(ctx))
+	})
+
+	t.Run("skips the check when disabled", func(t *testing.T) {
+		ctx := baseContext()
+		ctx.Endpoint.Spec.DeploymentOptions = map[string]interface{}{"image_accelerator_validation": false}
+
+		require.NoError(t, newKubernetesOrchestrator(Options{}).validateDependencies(ctx))
+	})
+
+	t.Run("rejects invalid option", func(t *testing.T) {
+		ctx := baseContext()
+		ctx.Endpoint.Spec.DeploymentOptions = map[string]interface{}{"image_accelerator_validation": "no"}
+
+		err := newKubernetesOrchestrator(Options{}).validateDependencies(ctx)
+
+		require.EqualError(t, err, "deployment_options.image_accelerator_validation must be a boolean")
+	})
 }
 
 func validVirtualizationResourceInfo() *v1.ClusterResources {
@@ -1260,6 +1389,7 @@ func TestKubernetesOrchestrator_setBasicVariables(t *testing.T) {
 			Replicas: v1.ReplicaSpec{
 				Num: &numReplicas,
 			},
+			PriorityClassName: "production",
 		},
 	}
 
@@ -1292,6 +1422,99 @@ func TestKubernetesOrchestrator_setBasicVariables(t *testing.T) {
 	assert.NotEmpty(t, data.Namespace)
 	assert.NotEmpty(t, data.ImagePullSecret)
 	assert.Equal(t, data.NeutreeVersion, "v0.1.0")
+	assert.Equal(t, "production", data.PriorityClassName)
+}
+
+func TestValidatePriorityClass(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = schedulingv1.AddToScheme(scheme)
+
+	existing := &schedulingv1.PriorityClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "production"},
+		Value:      1000,
+	}
+
+	tests := []struct {
+		name          string
+		priorityClass string
+		getError      error
+		expectError   bool
+	}{
+		{name: "unset", priorityClass: ""},
+		{name: "existing priority class", priorityClass: "production"},
+		{name: "missing priority class", priorityClass: "batch", expectError: true},
+		{
+			name:          "lookup forbidden is tolerated",
+			priorityClass: "batch",
+			getError:      apierrors.NewForbidden(schedulingv1.Resource("priorityclasses"), "batch", errors.New("forbidden")),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ctrlClient client.Client = fake.NewClientBuilder().WithScheme(scheme).WithObjects(existing).Build()
+			if tt.getError != nil {
+				ctrlClient = &errorClient{Client: ctrlClient, getError: tt.getError}
+			}
+
+			ctx := makePauseTestCtx(ctrlClient, "chat-model")
+			ctx.Endpoint.Spec.PriorityClassName = tt.priorityClass
+
+			err := validatePriorityClass(ctx)
+			if tt.expectError {
+				assert.ErrorContains(t, err, "priority class batch not found")
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestKubernetesOrchestrator_setHealthCheckPath(t *testing.T) {
+	k := &kubernetesOrchestrator{}
+
+	engine := &v1.Engine{
+		Metadata: &v1.Metadata{Name: "vllm"},
+		Spec: &v1.EngineSpec{
+			Versions: []*v1.EngineVersion{
+				{
+					Version: "v0.5.0",
+					HealthCheckPaths: map[string]string{
+						v1.DefaultHealthCheckPathKey: "/health",
+						v1.TextEmbeddingModelTask:    "/v1/models",
+					},
+				},
+				{Version: "v0.4.0"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		version  string
+		task     string
+		expected string
+	}{
+		{name: "task specific path", version: "v0.5.0", task: v1.TextEmbeddingModelTask, expected: "/v1/models"},
+		{name: "default path", version: "v0.5.0", task: v1.TextGenerationModelTask, expected: "/health"},
+		{name: "version without health check paths", version: "v0.4.0", task: v1.TextEmbeddingModelTask, expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endpoint := &v1.Endpoint{
+				Spec: &v1.EndpointSpec{
+					Engine: &v1.EndpointEngineSpec{Engine: "vllm", Version: tt.version},
+					Model:  &v1.ModelSpec{Task: tt.task},
+				},
+			}
+
+			data := newDeploymentManifestVariables()
+			k.setHealthCheckPath(&data, endpoint, engine)
+
+			assert.Equal(t, tt.expected, data.HealthCheckPath)
+		})
+	}
 }
 
 func TestKubernetesOrchestrator_setRoutingLogic(t *testing.T) {
@@ -1733,7 +1956,7 @@ func TestKubernetesOrchestrator_setEngineArgs(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			data := newDeploymentManifestVariables()
-			k.setEngineArgs(&data, tt.endpoint, tt.engine)
+			require.NoError(t, k.setEngineArgs(&data, tt.endpoint, tt.engine))
 			assert.Equal(t, tt.expectedArgs, data.EngineArgs)
 		})
 	}
@@ -1912,6 +2135,265 @@ func TestKubernetesOrchestrator_setEnvironmentVariables(t *testing.T) {
 	}
 }
 
+func TestKubernetesOrchestrator_setTopologyAwarePlacement(t *testing.T) {
+	k := &kubernetesOrchestrator{}
+	gpu := func(domain string) *v1.DeviceResource {
+		return &v1.DeviceResource{Health: true, NVLinkDomain: domain}
+	}
+	nvlinkCluster := &v1.Cluster{
+		Status: &v1.ClusterStatus{
+			ResourceInfo: &v1.ClusterResources{
+				NodeResources: map[string]*v1.NodeResourceStatus{
+					"node-1": {Devices: []*v1.DeviceResource{gpu("0"), gpu("0"), gpu("1"), gpu("1")}},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name           string
+		gpu            string
+		options        map[string]interface{}
+		cluster        *v1.Cluster
+		wantAnnotation bool
+		wantErr        bool
+	}{
+		{
+			name:           "enabled with NVLink topology",
+			gpu:            "2",
+			options:        map[string]interface{}{"topology_aware_placement": true},
+			cluster:        nvlinkCluster,
+			wantAnnotation: true,
+		},
+		{
+			name:    "enabled without topology",
+			gpu:     "2",
+			options: map[string]interface{}{"topology_aware_placement": true},
+			cluster: &v1.Cluster{Status: &v1.ClusterStatus{}},
+		},
+		{
+			name:    "replica larger than NVLink domain",
+			gpu:     "4",
+			options: map[string]interface{}{"topology_aware_placement": true},
+			cluster: nvlinkCluster,
+		},
+		{
+			name:    "disabled",
+			gpu:     "2",
+			cluster: nvlinkCluster,
+		},
+		{
+			name:    "invalid option",
+			gpu:     "2",
+			options: map[string]interface{}{"topology_aware_placement": "true"},
+			cluster: nvlinkCluster,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endpoint := &v1.Endpoint{
+				Metadata: &v1.Metadata{Workspace: "default", Name: "tp-ep"},
+				Spec: &v1.EndpointSpec{
+					Resources:         &v1.ResourceSpec{GPU: pointer.String(tt.gpu)},
+					DeploymentOptions: tt.options,
+				},
+			}
+
+			data := newDeploymentManifestVariables()
+			err := k.setTopologyAwarePlacement(&data, endpoint, tt.cluster)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+
+			if tt.wantAnnotation {
+				assert.Equal(t, plugin.NvidiaGPUTopologyAwarePolicy, data.Annotations[hamiGPUSchedulerPolicyAnnotation])
+			} else {
+				assert.NotContains(t, data.Annotations, hamiGPUSchedulerPolicyAnnotation)
+			}
+		})
+	}
+}
+
+func TestKubernetesOrchestrator_setServiceMeshVariables(t *testing.T) {
+	k := &kubernetesOrchestrator{}
+	meshCluster := func(mesh v1.ServiceMeshType) *v1.Cluster {
+		return &v1.Cluster{
+			Metadata: &v1.Metadata{Workspace: "default", Name: "k8s"},
+			Spec: &v1.ClusterSpec{
+				Type: v1.KubernetesClusterType,
+				Config: &v1.ClusterConfig{
+					KubernetesConfig: &v1.KubernetesClusterConfig{
+						ServiceMesh: &v1.ServiceMeshConfig{Type: mesh},
+					},
+				},
+			},
+		}
+	}
+	plainCluster := &v1.Cluster{
+		Metadata: &v1.Metadata{Workspace: "default", Name: "k8s"},
+		Spec: &v1.ClusterSpec{
+			Type:   v1.KubernetesClusterType,
+			Config: &v1.ClusterConfig{KubernetesConfig: &v1.KubernetesClusterConfig{}},
+		},
+	}
+
+	tests := []struct {
+		name            string
+		options         map[string]interface{}
+		cluster         *v1.Cluster
+		wantMesh        string
+		wantAnnotations map[string]string
+		wantErr         bool
+	}{
+		{
+			name:     "istio cluster",
+			cluster:  meshCluster(v1.ServiceMeshTypeIstio),
+			wantMesh: "istio",
+			wantAnnotations: map[string]string{
+				"sidecar.istio.io/inject":                "true",
+				"sidecar.istio.io/rewriteAppHTTPProbers": "true",
+				"proxy.istio.io/config":                  `{"holdApplicationUntilProxyStarts":true}`,
+			},
+		},
+		{
+			name:     "linkerd cluster",
+			cluster:  meshCluster(v1.ServiceMeshTypeLinkerd),
+			wantMesh: "linkerd",
+			wantAnnotations: map[string]string{
+				"linkerd.io/inject":             "enabled",
+				"config.linkerd.io/proxy-await": "enabled",
+			},
+		},
+		{
+			name:    "off by default",
+			cluster: plainCluster,
+		},
+		{
+			name:    "endpoint opts out",
+			options: map[string]interface{}{"service_mesh": false},
+			cluster: meshCluster(v1.ServiceMeshTypeIstio),
+		},
+		{
+			name:    "endpoint opts in without cluster mesh",
+			options: map[string]interface{}{"service_mesh": true},
+			cluster: plainCluster,
+			wantErr: true,
+		},
+		{
+			name:    "invalid option",
+			options: map[string]interface{}{"service_mesh": "istio"},
+			cluster: meshCluster(v1.ServiceMeshTypeIstio),
+			wantErr: true,
+		},
+		{
+			name:    "unsupported mesh",
+			cluster: meshCluster("consul"),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endpoint := &v1.Endpoint{
+				Metadata: &v1.Metadata{Workspace: "default", Name: "mesh-ep"},
+				Spec:     &v1.EndpointSpec{DeploymentOptions: tt.options},
+			}
+
+			data := newDeploymentManifestVariables()
+			err := k.setServiceMeshVariables(&data, endpoint, tt.cluster)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantMesh, data.ServiceMesh)
+
+			if tt.wantAnnotations == nil {
+				assert.Empty(t, data.Annotations)
+			}
+
+			for key, value := range tt.wantAnnotations {
+				assert.Equal(t, value, data.Annotations[key], key)
+			}
+		})
+	}
+}
+
+func TestBuildDeployment_ServiceMesh(t *testing.T) {
+	data := newDeploymentManifestVariables()
+	data.NeutreeVersion = "v0.1.0"
+	data.ClusterName = "test-cluster"
+	data.Workspace = "test-workspace"
+	data.Namespace = "default"
+	data.ImagePrefix = "registry.example.com"
+	data.ImageRepo = "vllm"
+	data.ImageTag = "v0.17.1"
+	data.EngineName = "vllm"
+	data.EngineVersion = "v0.17.1"
+	data.EndpointName = "test-endpoint"
+	data.RoutingLogic = "roundrobin"
+	data.Replicas = 1
+	data.ModelArgs = map[string]interface{}{
+		"name":          "qwen",
+		"task":          "text-generation",
+		"path":          "/mnt/models/qwen",
+		"registry_type": "hugging-face",
+		"registry_path": "qwen",
+		"serve_name":    "qwen",
+	}
+
+	render := func(t *testing.T, data DeploymentManifestVariables) *appsv1.Deployment {
+		objs, err := buildDeploymentObjects(realEmbeddedTemplate(t, "vllm-v0.17.1"), data)
+		require.NoError(t, err)
+		require.Len(t, objs.Items, 1)
+
+		var deployment appsv1.Deployment
+		require.NoError(t, runtime.DefaultUnstructuredConverter.FromUnstructured(objs.Items[0].Object, &deployment))
+
+		return &deployment
+	}
+
+	deployment := render(t, data)
+	container := deployment.Spec.Template.Spec.Containers[0]
+	assert.Empty(t, deployment.Spec.Template.Annotations)
+	assert.Empty(t, container.Ports[0].Name)
+
+	k := &kubernetesOrchestrator{}
+	cluster := &v1.Cluster{
+		Metadata: &v1.Metadata{Workspace: "default", Name: "k8s"},
+		Spec: &v1.ClusterSpec{
+			Config: &v1.ClusterConfig{
+				KubernetesConfig: &v1.KubernetesClusterConfig{
+					ServiceMesh: &v1.ServiceMeshConfig{Type: v1.ServiceMeshTypeIstio},
+				},
+			},
+		},
+	}
+	endpoint := &v1.Endpoint{Metadata: &v1.Metadata{Workspace: "default", Name: "test-endpoint"}, Spec: &v1.EndpointSpec{}}
+	require.NoError(t, k.setServiceMeshVariables(&data, endpoint, cluster))
+
+	deployment = render(t, data)
+	container = deployment.Spec.Template.Spec.Containers[0]
+
+	// annotation values are rendered as strings, including JSON proxy config
+	assert.Equal(t, "true", deployment.Spec.Template.Annotations["sidecar.istio.io/inject"])
+	assert.Equal(t, `{"holdApplicationUntilProxyStarts":true}`, deployment.Spec.Template.Annotations["proxy.istio.io/config"])
+	assert.Equal(t, "true", deployment.Spec.Template.Annotations["sidecar.istio.io/rewriteAppHTTPProbers"])
+	assert.Equal(t, "80,443", deployment.Spec.Template.Annotations["traffic.sidecar.istio.io/excludeOutboundPorts"])
+
+	// the engine port is named for mesh protocol selection and probes keep targeting it
+	assert.Equal(t, "http", container.Ports[0].Name)
+	assert.Equal(t, int32(8000), container.Ports[0].ContainerPort)
+	assert.Equal(t, 8000, container.ReadinessProbe.HTTPGet.Port.IntValue())
+	assert.Equal(t, 8000, container.StartupProbe.HTTPGet.Port.IntValue())
+}
+
 func TestKubernetesOrchestrator_setModelArgs(t *testing.T) {
 	k := &kubernetesOrchestrator{}
 
@@ -2065,7 +2547,7 @@ func TestKubernetesOrchestrator_addSharedMemoryVolume(t *testing.T) {
 	k := &kubernetesOrchestrator{}
 
 	data := newDeploymentManifestVariables()
-	k.addSharedMemoryVolume(&data)
+	k.addSharedMemoryVolume(&data, false)
 
 	require.Len(t, data.Volumes, 1)
 	assert.Equal(t, "dshm", data.Volumes[0].Name)
@@ -2225,15 +2707,86 @@ func TestKubernetesOrchestrator_setModelRegistryVariables_HuggingFace(t *testing
 				},
 			},
 		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			data := &DeploymentManifestVariables{
-				Env:       map[string]string{},
-				ModelArgs: map[string]interface{}{},
-			}
-
+		{
+			name: "HuggingFace - model cache selected by the endpoint",
+			modelRegistry: &v1.ModelRegistry{
+				Metadata: &v1.Metadata{
+					Name: "hf-registry",
+				},
+				Spec: &v1.ModelRegistrySpec{
+					Type: v1.HuggingFaceModelRegistryType,
+					Url:  "https://huggingface.co/",
+				},
+			},
+			endpoint: &v1.Endpoint{
+				Spec: &v1.EndpointSpec{
+					Model:             &v1.ModelSpec{Name: "test-model"},
+					DeploymentOptions: map[string]interface{}{"model_cache": "team-b"},
+				},
+			},
+			cluster: &v1.Cluster{
+				Metadata: &v1.Metadata{Name: "test-cluster", Workspace: "default"},
+				Spec: &v1.ClusterSpec{
+					Config: &v1.ClusterConfig{
+						KubernetesConfig: &v1.KubernetesClusterConfig{},
+						ModelCaches: []v1.ModelCache{
+							{Name: "team-a", NFS: &corev1.NFSVolumeSource{Server: "10.0.0.1", Path: "/team-a"}},
+							{
+								Name:          "team-b",
+								NFS:           &corev1.NFSVolumeSource{Server: "10.0.0.2", Path: "/team-b"},
+								RegistryPaths: map[v1.ModelRegistryType]string{v1.HuggingFaceModelRegistryType: "hf"},
+							},
+						},
+					},
+				},
+			},
+			expected: &DeploymentManifestVariables{
+				ModelArgs: map[string]interface{}{
+					"registry_path": "test-model",
+					"path":          filepath.Join(v1.DefaultK8sClusterModelCacheMountPath, "team-b", "hf", "test-model"),
+					"version":       "", // Empty version for HuggingFace to use default branch
+				},
+				Env: map[string]string{
+					v1.HFEndpoint: "https://huggingface.co",
+				},
+			},
+		},
+		{
+			name: "HuggingFace - unknown model cache selected by the endpoint",
+			modelRegistry: &v1.ModelRegistry{
+				Metadata: &v1.Metadata{
+					Name: "hf-registry",
+				},
+				Spec: &v1.ModelRegistrySpec{
+					Type: v1.HuggingFaceModelRegistryType,
+					Url:  "https://huggingface.co/",
+				},
+			},
+			endpoint: &v1.Endpoint{
+				Spec: &v1.EndpointSpec{
+					Model:             &v1.ModelSpec{Name: "test-model"},
+					DeploymentOptions: map[string]interface{}{"model_cache": "team-c"},
+				},
+			},
+			cluster: &v1.Cluster{
+				Metadata: &v1.Metadata{Name: "test-cluster", Workspace: "default"},
+				Spec: &v1.ClusterSpec{
+					Config: &v1.ClusterConfig{
+						ModelCaches: []v1.ModelCache{{Name: "team-a"}},
+					},
+				},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := &DeploymentManifestVariables{
+				Env:       map[string]string{},
+				ModelArgs: map[string]interface{}{},
+			}
+
 			err := k.setModelRegistryVariables(data, tt.endpoint, tt.cluster, tt.modelRegistry)
 			if tt.wantErr {
 				assert.Error(t, err)
@@ -3019,6 +3572,35 @@ func TestKubernetesOrchestrator_getEndpointStats(t *testing.T) {
 			expectedPhase: v1.EndpointPhaseRUNNING,
 			expectError:   false,
 		},
+		{
+			name: "return Degraded for ready deployment with some pods crashing",
+			inputEndpoint: func() *v1.Endpoint {
+				return newEndpoint()
+			},
+			setupMock: func(t *testing.T) *FakeK8sClient {
+				return NewFakeK8sClient(t).
+					WithDeployment(newEndpoint().Metadata.Name, 1, 1, 1).
+					WithPods(1).
+					WithPodInCrashLoopBackOff("test-container", 1)
+			},
+			expectedPhase:  v1.EndpointPhaseDEGRADED,
+			expectErrorMsg: "Endpoint degraded: 1/2 pods unhealthy: Pod 'pod-crash' Container 'test-container' in CrashLoopBackOff",
+			expectError:    false,
+		},
+		{
+			name: "return Failed for ready deployment with every pod crashing",
+			inputEndpoint: func() *v1.Endpoint {
+				return newEndpoint()
+			},
+			setupMock: func(t *testing.T) *FakeK8sClient {
+				return NewFakeK8sClient(t).
+					WithDeployment(newEndpoint().Metadata.Name, 1, 1, 1).
+					WithPodInCrashLoopBackOff("test-container", 1)
+			},
+			expectedPhase:  v1.EndpointPhaseFAILED,
+			expectErrorMsg: k8sContainerReasonCrashLoopBackOff,
+			expectError:    false,
+		},
 		{
 			name: "return Deploying for deployment with not all replicas ready",
 			inputEndpoint: func() *v1.Endpoint {
@@ -3061,6 +3643,20 @@ func TestKubernetesOrchestrator_getEndpointStats(t *testing.T) {
 			expectErrorMsg: "failed to pull image",
 			expectError:    false,
 		},
+		{
+			name: "return Degraded for failing rollout while previous replicas are ready",
+			inputEndpoint: func() *v1.Endpoint {
+				return newEndpoint()
+			},
+			setupMock: func(t *testing.T) *FakeK8sClient {
+				return NewFakeK8sClient(t).
+					WithDeployment(newEndpoint().Metadata.Name, 2, 1, 1).
+					WithPodInImagePullBackOff("test-container")
+			},
+			expectedPhase:  v1.EndpointPhaseDEGRADED,
+			expectErrorMsg: "Endpoint degraded: 1/2 replicas ready: ",
+			expectError:    false,
+		},
 		{
 			name: "return Failed for pod with OOMKilled",
 			inputEndpoint: func() *v1.Endpoint {
@@ -3305,6 +3901,75 @@ func TestKubernetesOrchestrator_getEndpointStatsIgnoresResourceStatusError(t *te
 	assert.Nil(t, status.Resources)
 }
 
+func TestKubernetesOrchestrator_getEndpointStatsModelDownloadProgress(t *testing.T) {
+	endpoint := &v1.Endpoint{
+		Metadata: &v1.Metadata{
+			Workspace: "production",
+			Name:      "chat-model",
+		},
+		Spec: &v1.EndpointSpec{
+			Replicas: v1.ReplicaSpec{
+				Num: pointer.Int(1),
+			},
+		},
+	}
+	cluster := &v1.Cluster{Spec: &v1.ClusterSpec{}}
+
+	t.Run("progress is parsed from running model-downloader logs", func(t *testing.T) {
+		fakeClient := NewFakeK8sClient(t).
+			WithDeployment(endpoint.Metadata.Name, 1, 0, 1).
+			WithRunningInitContainer(modelDownloaderInitContainerName)
+
+		k8sClient := utilmocks.NewMockK8sClient(t)
+		k8sClient.On("GetPodLogs", mock.Anything, cluster, "test-namespace", "pod-init-running",
+			mock.MatchedBy(func(opts *corev1.PodLogOptions) bool {
+				return opts.Container == modelDownloaderInitContainerName && opts.TailLines != nil
+			})).
+			Return(io.NopCloser(strings.NewReader(
+				"NEUTREE_MODEL_DOWNLOAD_START\nNEUTREE_MODEL_DOWNLOAD_PROGRESS downloaded=512 total=1024\n")), nil)
+
+		o := &kubernetesOrchestrator{k8sClient: k8sClient}
+
+		status, err := o.getEndpointStats(fakeClient, "test-namespace", cluster, endpoint)
+		require.NoError(t, err)
+		assert.Equal(t, v1.EndpointPhaseMODELDOWNLOADING, status.Phase)
+		require.NotNil(t, status.ModelDownloadProgress)
+		assert.Equal(t, int64(512), status.ModelDownloadProgress.DownloadedBytes)
+		assert.Equal(t, int64(1024), status.ModelDownloadProgress.TotalBytes)
+		require.NotNil(t, status.ModelDownloadProgress.Percent)
+		assert.Equal(t, 50.0, *status.ModelDownloadProgress.Percent)
+	})
+
+	t.Run("log read failure leaves progress empty", func(t *testing.T) {
+		fakeClient := NewFakeK8sClient(t).
+			WithDeployment(endpoint.Metadata.Name, 1, 0, 1).
+			WithRunningInitContainer(modelDownloaderInitContainerName)
+
+		k8sClient := utilmocks.NewMockK8sClient(t)
+		k8sClient.On("GetPodLogs", mock.Anything, cluster, "test-namespace", "pod-init-running", mock.Anything).
+			Return(nil, assert.AnError)
+
+		o := &kubernetesOrchestrator{k8sClient: k8sClient}
+
+		status, err := o.getEndpointStats(fakeClient, "test-namespace", cluster, endpoint)
+		require.NoError(t, err)
+		assert.Equal(t, v1.EndpointPhaseMODELDOWNLOADING, status.Phase)
+		assert.Nil(t, status.ModelDownloadProgress)
+	})
+
+	t.Run("completion clears progress", func(t *testing.T) {
+		fakeClient := NewFakeK8sClient(t).
+			WithDeployment(endpoint.Metadata.Name, 1, 1, 1)
+
+		o := &kubernetesOrchestrator{k8sClient: utilmocks.NewMockK8sClient(t)}
+
+		status, err := o.getEndpointStats(fakeClient, "test-namespace", cluster, endpoint)
+		require.NoError(t, err)
+		assert.Equal(t, v1.EndpointPhaseRUNNING, status.Phase)
+		assert.Nil(t, status.ModelDownloadProgress)
+	})
+}
+
 // TestBuildDeployment_BooleanEngineArgs pins the boolean handling in the
 // K8s deploy templates that ship engine_args through to vLLM / SGLang CLI
 // argparse. Both engines' boolean flags are registered with
@@ -3331,6 +3996,10 @@ func TestBuildDeployment_BooleanEngineArgs(t *testing.T) {
 			name:        "vllm-v0.17.1",
 			templateKey: "vllm-v0.17.1",
 		},
+		{
+			name:        "vllm-v0.24.0",
+			templateKey: "vllm-v0.24.0",
+		},
 		{
 			name:        "sglang-v0.5.10",
 			templateKey: "sglang-v0.5.10",
@@ -3421,115 +4090,486 @@ func TestBuildDeployment_BooleanEngineArgs(t *testing.T) {
 	}
 }
 
-func TestBuildDeployment_VLLMListEngineArgs(t *testing.T) {
-	cases := []struct {
-		name        string
-		templateKey string
-	}{
-		{
-			name:        "vllm-v0.11.2",
-			templateKey: "vllm-v0.11.2",
-		},
-		{
-			name:        "vllm-v0.17.1",
-			templateKey: "vllm-v0.17.1",
-		},
+func TestNormalizeBooleanEngineArgs(t *testing.T) {
+	args := map[string]interface{}{
+		"enforce-eager":     "True",
+		"disable-log-stats": " FALSE ",
+		"trust-remote-code": true,
+		"max-model-len":     "4096",
+		"dtype":             "float16",
 	}
 
-	for _, tc := range cases {
-		t.Run(tc.name, func(t *testing.T) {
-			tmpl := realEmbeddedTemplate(t, tc.templateKey)
+	require.NoError(t, normalizeBooleanEngineArgs(args, v1.EngineNameVLLM))
+	assert.Equal(t, map[string]interface{}{
+		"enforce-eager":     true,
+		"disable-log-stats": false,
+		"trust-remote-code": true,
+		"max-model-len":     "4096",
+		"dtype":             "float16",
+	}, args)
+
+	// llama.cpp takes booleans as values, they are left as given.
+	args = map[string]interface{}{"interrupt_requests": "False"}
+	require.NoError(t, normalizeBooleanEngineArgs(args, v1.EngineNameLlamaCpp))
+	assert.Equal(t, map[string]interface{}{"interrupt_requests": "False"}, args)
+
+	err := normalizeBooleanEngineArgs(map[string]interface{}{"enforce-eager": nil}, v1.EngineNameVLLM)
+	assert.EqualError(t, err, "engine_args.enforce-eager must not be null, set it to false to leave the flag out")
+}
 
-			data := DeploymentManifestVariables{
-				NeutreeVersion:  "v0.1.0",
-				ClusterName:     "test-cluster",
-				Workspace:       "test-workspace",
-				Namespace:       "default",
-				ImagePrefix:     "registry.example.com",
-				ImageRepo:       "myrepo",
-				ImageTag:        "v1.0.0",
-				ImagePullSecret: "my-secret",
-				EngineName:      v1.EngineNameVLLM,
-				EngineVersion:   "v1.0.0",
-				EndpointName:    "test-endpoint",
-				ModelArgs: map[string]interface{}{
-					"name":          "gpt-4",
-					"task":          "text-generation",
-					"path":          "/mnt/models/gpt-4",
-					"registry_type": "bentoml",
-					"registry_path": "/mnt/registry/gpt-4-model",
-					"serve_name":    "gpt-4-serve",
-				},
-				EngineArgs: map[string]interface{}{
-					"served_model_name": []interface{}{"gpt-4", "neu-vllm-list-alias"},
-					"logits_processors": []interface{}{"a.Processor", "b.Processor"},
-				},
-				Resources: map[string]string{
-					"cpu":    "500m",
-					"memory": "1Gi",
+func TestBuildDeployment_NormalizedBooleanEngineArgs(t *testing.T) {
+	k := &kubernetesOrchestrator{}
+
+	for _, templateKey := range []string{"vllm-v0.11.2", "vllm-v0.17.1", "vllm-v0.24.0", "sglang-v0.5.10"} {
+		t.Run(templateKey, func(t *testing.T) {
+			engineName := strings.SplitN(templateKey, "-v", 2)[0]
+			endpoint := &v1.Endpoint{Spec: &v1.EndpointSpec{
+				Variables: map[string]interface{}{
+					"engine_args": map[string]interface{}{
+						"enforce-eager":     "True",
+						"disable-log-stats": "False",
+						"dtype":             "float16",
+					},
 				},
-				RoutingLogic: "roundrobin",
-				Replicas:     1,
+			}}
+
+			data := newDeploymentManifestVariables()
+			require.NoError(t, k.setEngineArgs(&data, endpoint, &v1.Engine{Metadata: &v1.Metadata{Name: engineName}}))
+
+			data.NeutreeVersion = "v0.1.0"
+			data.Namespace = "default"
+			data.ImagePrefix = "registry.example.com"
+			data.ImageRepo = "myrepo"
+			data.ImageTag = "v1.0.0"
+			data.EndpointName = "test-endpoint"
+			data.ModelArgs = map[string]interface{}{
+				"name":       "gpt-4",
+				"task":       "text-generation",
+				"path":       "/mnt/models/gpt-4",
+				"serve_name": "gpt-4",
 			}
+			data.RoutingLogic = "roundrobin"
+			data.Replicas = 1
 
-			objs, err := buildDeploymentObjects(tmpl, data)
-			require.NoError(t, err, "template render must succeed")
+			objs, err := buildDeploymentObjects(realEmbeddedTemplate(t, templateKey), data)
+			require.NoError(t, err)
 
 			tokens := extractEngineCLITokens(t, objs)
-
-			assertFlagWithValues(t, tokens, "--served_model_name", "gpt-4", "neu-vllm-list-alias")
-			assertFlagWithValues(t, tokens, "--logits_processors", "a.Processor", "b.Processor")
-			assert.NotContains(t, tokens, `["gpt-4","neu-vllm-list-alias"]`)
-			assert.NotContains(t, tokens, `["a.Processor","b.Processor"]`)
+			assertFlagWithoutValue(t, tokens, "--enforce-eager")
+			assert.NotContains(t, tokens, "--disable-log-stats")
+			assert.NotContains(t, tokens, "False")
+			assertFlagWithValue(t, tokens, "--dtype", "float16")
 		})
 	}
 }
 
-func TestBuildDeployment_SGLangJSONListEngineArgs(t *testing.T) {
-	tmpl := realEmbeddedTemplate(t, "sglang-v0.5.10")
+func TestBuildDeployment_ModelDownloaderOverrides(t *testing.T) {
+	for _, templateKey := range []string{"vllm-v0.11.2", "vllm-v0.17.1", "vllm-v0.24.0", "sglang-v0.5.10", "llama-cpp-v0.3.7"} {
+		t.Run(templateKey, func(t *testing.T) {
+			data := newDeploymentManifestVariables()
+			data.NeutreeVersion = "v0.1.0"
+			data.Namespace = "default"
+			data.ImagePrefix = "registry.example.com"
+			data.ImageRepo = "myrepo"
+			data.ImageTag = "v1.0.0"
+			data.ImagePullSecret = "my-secret"
+			data.EndpointName = "test-endpoint"
+			data.ModelArgs = map[string]interface{}{
+				"name":          "gpt-4",
+				"task":          "text-generation",
+				"path":          "/mnt/models/gpt-4",
+				"registry_type": "hugging-face",
+				"registry_path": "gpt-4",
+				"serve_name":    "gpt-4",
+			}
+			data.Env["HF_ENDPOINT"] = "https://huggingface.co"
+			data.ModelDownloaderImagePullPolicy = string(corev1.PullIfNotPresent)
+			data.ModelDownloaderEnv[modelDownloaderRetriesEnv] = "5"
+			data.RoutingLogic = "roundrobin"
+			data.Replicas = 1
+			(&kubernetesOrchestrator{}).addRegistryCredentialsVolume(&data, "registry-credentials")
+
+			objs, err := buildDeploymentObjects(realEmbeddedTemplate(t, templateKey), data)
+			require.NoError(t, err)
 
-	data := DeploymentManifestVariables{
-		NeutreeVersion:  "v0.1.0",
-		ClusterName:     "test-cluster",
-		Workspace:       "test-workspace",
-		Namespace:       "default",
-		ImagePrefix:     "registry.example.com",
-		ImageRepo:       "myrepo",
-		ImageTag:        "v1.0.0",
-		ImagePullSecret: "my-secret",
-		EngineName:      v1.EngineNameSGLang,
-		EngineVersion:   "v1.0.0",
-		EndpointName:    "test-endpoint",
-		ModelArgs: map[string]interface{}{
-			"name":          "qwen",
-			"task":          "text-generation",
-			"path":          "/mnt/models/qwen",
-			"registry_type": "bentoml",
-			"registry_path": "/mnt/registry/qwen",
-			"serve_name":    "qwen",
+			var deployment appsv1.Deployment
+
+			for _, obj := range objs.Items {
+				if obj.GetKind() == "Deployment" {
+					require.NoError(t, runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &deployment))
+				}
+			}
+
+			require.Len(t, deployment.Spec.Template.Spec.InitContainers, 1)
+			initContainer := deployment.Spec.Template.Spec.InitContainers[0]
+			assert.Equal(t, modelDownloaderInitContainerName, initContainer.Name)
+			assert.Equal(t, corev1.PullIfNotPresent, initContainer.ImagePullPolicy)
+			assert.Contains(t, initContainer.Env, corev1.EnvVar{Name: modelDownloaderRetriesEnv, Value: "5"})
+			assert.Contains(t, initContainer.Env, corev1.EnvVar{Name: "HF_ENDPOINT", Value: "https://huggingface.co"})
+			assert.Contains(t, initContainer.Env, corev1.EnvVar{Name: modelDownloaderTokenFileEnv, Value: "/var/run/neutree/registry-credentials/token"})
+			assert.Contains(t, initContainer.VolumeMounts, corev1.VolumeMount{
+				Name: "registry-credentials", MountPath: "/var/run/neutree/registry-credentials", ReadOnly: true,
+			})
+
+			// the engine container pull policy is independent of the downloader
+			for _, c := range deployment.Spec.Template.Spec.Containers {
+				assert.Empty(t, c.ImagePullPolicy, "container %s must keep its own pull policy", c.Name)
+				assert.NotContains(t, c.Env, corev1.EnvVar{Name: modelDownloaderRetriesEnv, Value: "5"})
+			}
+		})
+	}
+}
+
+func TestKubernetesOrchestrator_setModelDownloaderVariables(t *testing.T) {
+	tests := []struct {
+		name              string
+		deploymentOptions map[string]interface{}
+		env               map[string]string
+		expectedPolicy    string
+		expectedEnv       map[string]string
+		expectedVolumes   []corev1.Volume
+		expectError       bool
+	}{
+		{
+			name:        "no options",
+			expectedEnv: map[string]string{},
 		},
-		EngineArgs: map[string]interface{}{
-			"forward_hooks": []interface{}{
-				map[string]interface{}{
-					"name": "trace",
-					"path": "hooks.TraceHook",
+		{
+			name: "pull policy and retries",
+			deploymentOptions: map[string]interface{}{
+				"model_downloader": map[string]interface{}{
+					"image_pull_policy":     "Always",
+					"retries":               float64(5),
+					"retry_backoff_seconds": 1.5,
 				},
 			},
-			"remote_instance_weight_loader_send_weights_group_ports": []int{29500, 29501},
+			expectedPolicy: "Always",
+			expectedEnv: map[string]string{
+				modelDownloaderRetriesEnv:      "5",
+				modelDownloaderRetryBackoffEnv: "1.5",
+			},
 		},
-		Resources: map[string]string{
-			"cpu":    "500m",
-			"memory": "1Gi",
+		{
+			name: "endpoint env takes precedence",
+			deploymentOptions: map[string]interface{}{
+				"model_downloader": map[string]interface{}{
+					"retries": float64(5),
+				},
+			},
+			env:         map[string]string{modelDownloaderRetriesEnv: "1"},
+			expectedEnv: map[string]string{},
 		},
-		RoutingLogic: "roundrobin",
-		Replicas:     1,
-	}
-
-	prepareEngineArgsForTemplate(data.EngineArgs, v1.EngineNameSGLang)
-
-	objs, err := buildDeploymentObjects(tmpl, data)
-	require.NoError(t, err, "template render must succeed")
-
+		{
+			name: "invalid pull policy",
+			deploymentOptions: map[string]interface{}{
+				"model_downloader": map[string]interface{}{
+					"image_pull_policy": "Sometimes",
+				},
+			},
+			expectError: true,
+		},
+		{
+			name: "negative retries",
+			deploymentOptions: map[string]interface{}{
+				"model_downloader": map[string]interface{}{
+					"retries": float64(-1),
+				},
+			},
+			expectError: true,
+		},
+		{
+			name: "max concurrency",
+			deploymentOptions: map[string]interface{}{
+				"model_downloader": map[string]interface{}{
+					"max_concurrency": float64(2),
+				},
+			},
+			expectedEnv: map[string]string{
+				modelDownloaderMaxConcurrencyEnv: "2",
+			},
+		},
+		{
+			name: "max concurrency with lease",
+			deploymentOptions: map[string]interface{}{
+				"model_downloader": map[string]interface{}{
+					"max_concurrency": float64(2),
+					"lease":           true,
+				},
+			},
+			expectedEnv: map[string]string{
+				modelDownloaderMaxConcurrencyEnv: "2",
+				modelDownloaderLeaseEnv:          "test-endpoint-model-download",
+			},
+		},
+		{
+			name: "lease without max concurrency",
+			deploymentOptions: map[string]interface{}{
+				"model_downloader": map[string]interface{}{
+					"lease": true,
+				},
+			},
+			expectError: true,
+		},
+		{
+			name: "zero max concurrency",
+			deploymentOptions: map[string]interface{}{
+				"model_downloader": map[string]interface{}{
+					"max_concurrency": float64(0),
+				},
+			},
+			expectError: true,
+		},
+		{
+			name: "refreshable registry credentials",
+			deploymentOptions: map[string]interface{}{
+				"model_downloader": map[string]interface{}{
+					"credentials_secret":   "registry-credentials",
+					"auth_refresh_retries": float64(5),
+				},
+			},
+			expectedEnv: map[string]string{
+				modelDownloaderTokenFileEnv:   "/var/run/neutree/registry-credentials/token",
+				modelDownloaderAuthRefreshEnv: "5",
+			},
+			expectedVolumes: []corev1.Volume{{
+				Name: "registry-credentials",
+				VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{
+					SecretName: "registry-credentials",
+					Items:      []corev1.KeyToPath{{Key: "token", Path: "token"}},
+				}},
+			}},
+		},
+		{
+			name: "auth refresh without credentials secret",
+			deploymentOptions: map[string]interface{}{
+				"model_downloader": map[string]interface{}{
+					"auth_refresh_retries": float64(5),
+				},
+			},
+			expectError: true,
+		},
+		{
+			name: "empty credentials secret",
+			deploymentOptions: map[string]interface{}{
+				"model_downloader": map[string]interface{}{
+					"credentials_secret": "",
+				},
+			},
+			expectError: true,
+		},
+		{
+			name: "rate limit retries",
+			deploymentOptions: map[string]interface{}{
+				"model_downloader": map[string]interface{}{
+					"rate_limit_retries": float64(10),
+				},
+			},
+			expectedEnv: map[string]string{
+				modelDownloaderRateLimitEnv: "10",
+			},
+		},
+		{
+			name: "fractional rate limit retries",
+			deploymentOptions: map[string]interface{}{
+				"model_downloader": map[string]interface{}{
+					"rate_limit_retries": 1.5,
+				},
+			},
+			expectError: true,
+		},
+		{
+			name: "options not an object",
+			deploymentOptions: map[string]interface{}{
+				"model_downloader": "Always",
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k := &kubernetesOrchestrator{}
+			data := newDeploymentManifestVariables()
+			endpoint := &v1.Endpoint{
+				Metadata: &v1.Metadata{Name: "test-endpoint"},
+				Spec: &v1.EndpointSpec{
+					DeploymentOptions: tt.deploymentOptions,
+					Env:               tt.env,
+				},
+			}
+			data.EndpointName = endpoint.Metadata.Name
+			k.setEnvironmentVariables(&data, endpoint)
+
+			err := k.setModelDownloaderVariables(&data, endpoint)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedPolicy, data.ModelDownloaderImagePullPolicy)
+			assert.Equal(t, tt.expectedEnv, data.ModelDownloaderEnv)
+			assert.ElementsMatch(t, tt.expectedVolumes, data.Volumes)
+		})
+	}
+}
+
+func TestBuildDeployment_DefaultSamplingParams(t *testing.T) {
+	k := &kubernetesOrchestrator{}
+
+	for _, templateKey := range []string{"vllm-v0.11.2", "vllm-v0.17.1", "vllm-v0.24.0"} {
+		t.Run(templateKey, func(t *testing.T) {
+			endpoint := &v1.Endpoint{Spec: &v1.EndpointSpec{
+				Engine: &v1.EndpointEngineSpec{Engine: v1.EngineNameVLLM},
+				DefaultSamplingParams: &v1.SamplingParams{
+					Temperature: pointer.Float64(0.2),
+					MaxTokens:   pointer.Int(512),
+				},
+			}}
+
+			data := newDeploymentManifestVariables()
+			require.NoError(t, k.setEngineArgs(&data, endpoint, &v1.Engine{Metadata: &v1.Metadata{Name: v1.EngineNameVLLM}}))
+
+			data.NeutreeVersion = "v0.1.0"
+			data.Namespace = "default"
+			data.ImagePrefix = "registry.example.com"
+			data.ImageRepo = "myrepo"
+			data.ImageTag = "v1.0.0"
+			data.EndpointName = "test-endpoint"
+			data.ModelArgs = map[string]interface{}{
+				"name":       "gpt-4",
+				"task":       "text-generation",
+				"path":       "/mnt/models/gpt-4",
+				"serve_name": "gpt-4",
+			}
+			data.RoutingLogic = "roundrobin"
+			data.Replicas = 1
+
+			objs, err := buildDeploymentObjects(realEmbeddedTemplate(t, templateKey), data)
+			require.NoError(t, err)
+
+			tokens := extractEngineCLITokens(t, objs)
+
+			idx := indexOf(tokens, "--override_generation_config")
+			require.NotEqual(t, -1, idx, "expected --override_generation_config in tokens %v", tokens)
+			require.Less(t, idx+1, len(tokens))
+
+			var config map[string]interface{}
+			require.NoError(t, json.Unmarshal([]byte(tokens[idx+1]), &config), "value %q must be JSON", tokens[idx+1])
+			assert.Equal(t, map[string]interface{}{"temperature": 0.2, "max_new_tokens": float64(512)}, config)
+		})
+	}
+}
+
+func TestBuildDeployment_VLLMListEngineArgs(t *testing.T) {
+	cases := []struct {
+		name        string
+		templateKey string
+	}{
+		{
+			name:        "vllm-v0.11.2",
+			templateKey: "vllm-v0.11.2",
+		},
+		{
+			name:        "vllm-v0.17.1",
+			templateKey: "vllm-v0.17.1",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tmpl := realEmbeddedTemplate(t, tc.templateKey)
+
+			data := DeploymentManifestVariables{
+				NeutreeVersion:  "v0.1.0",
+				ClusterName:     "test-cluster",
+				Workspace:       "test-workspace",
+				Namespace:       "default",
+				ImagePrefix:     "registry.example.com",
+				ImageRepo:       "myrepo",
+				ImageTag:        "v1.0.0",
+				ImagePullSecret: "my-secret",
+				EngineName:      v1.EngineNameVLLM,
+				EngineVersion:   "v1.0.0",
+				EndpointName:    "test-endpoint",
+				ModelArgs: map[string]interface{}{
+					"name":          "gpt-4",
+					"task":          "text-generation",
+					"path":          "/mnt/models/gpt-4",
+					"registry_type": "bentoml",
+					"registry_path": "/mnt/registry/gpt-4-model",
+					"serve_name":    "gpt-4-serve",
+				},
+				EngineArgs: map[string]interface{}{
+					"served_model_name": []interface{}{"gpt-4", "neu-vllm-list-alias"},
+					"logits_processors": []interface{}{"a.Processor", "b.Processor"},
+				},
+				Resources: map[string]string{
+					"cpu":    "500m",
+					"memory": "1Gi",
+				},
+				RoutingLogic: "roundrobin",
+				Replicas:     1,
+			}
+
+			objs, err := buildDeploymentObjects(tmpl, data)
+			require.NoError(t, err, "template render must succeed")
+
+			tokens := extractEngineCLITokens(t, objs)
+
+			assertFlagWithValues(t, tokens, "--served_model_name", "gpt-4", "neu-vllm-list-alias")
+			assertFlagWithValues(t, tokens, "--logits_processors", "a.Processor", "b.Processor")
+			assert.NotContains(t, tokens, `["gpt-4","neu-vllm-list-alias"]`)
+			assert.NotContains(t, tokens, `["a.Processor","b.Processor"]`)
+		})
+	}
+}
+
+func TestBuildDeployment_SGLangJSONListEngineArgs(t *testing.T) {
+	tmpl := realEmbeddedTemplate(t, "sglang-v0.5.10")
+
+	data := DeploymentManifestVariables{
+		NeutreeVersion:  "v0.1.0",
+		ClusterName:     "test-cluster",
+		Workspace:       "test-workspace",
+		Namespace:       "default",
+		ImagePrefix:     "registry.example.com",
+		ImageRepo:       "myrepo",
+		ImageTag:        "v1.0.0",
+		ImagePullSecret: "my-secret",
+		EngineName:      v1.EngineNameSGLang,
+		EngineVersion:   "v1.0.0",
+		EndpointName:    "test-endpoint",
+		ModelArgs: map[string]interface{}{
+			"name":          "qwen",
+			"task":          "text-generation",
+			"path":          "/mnt/models/qwen",
+			"registry_type": "bentoml",
+			"registry_path": "/mnt/registry/qwen",
+			"serve_name":    "qwen",
+		},
+		EngineArgs: map[string]interface{}{
+			"forward_hooks": []interface{}{
+				map[string]interface{}{
+					"name": "trace",
+					"path": "hooks.TraceHook",
+				},
+			},
+			"remote_instance_weight_loader_send_weights_group_ports": []int{29500, 29501},
+		},
+		Resources: map[string]string{
+			"cpu":    "500m",
+			"memory": "1Gi",
+		},
+		RoutingLogic: "roundrobin",
+		Replicas:     1,
+	}
+
+	prepareEngineArgsForTemplate(data.EngineArgs, v1.EngineNameSGLang)
+
+	objs, err := buildDeploymentObjects(tmpl, data)
+	require.NoError(t, err, "template render must succeed")
+
 	tokens := extractEngineCLITokens(t, objs)
 
 	assertFlagWithValue(t, tokens, "--forward-hooks", `[{"name":"trace","path":"hooks.TraceHook"}]`)
@@ -3800,6 +4840,7 @@ func TestKubernetesOrchestrator_deleteEndpoint_NoConfigStore(t *testing.T) {
 }
 
 func int32Ptr(v int32) *int32 { return &v }
+func int64Ptr(v int64) *int64 { return &v }
 
 // klogTestLogger returns the default klog logger. This matches the same
 // idiom production code uses (klog.Background()); it is NOT silenced —
@@ -3809,3 +4850,1224 @@ func int32Ptr(v int32) *int32 { return &v }
 func klogTestLogger() klog.Logger {
 	return klog.Background()
 }
+
+func TestKubernetesOrchestrator_setResourceVariablesTolerations(t *testing.T) {
+	nvidiaToleration := corev1.Toleration{Key: "nvidia.com/gpu", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule}
+	amdToleration := corev1.Toleration{Key: "amd.com/gpu", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule}
+	dedicatedToleration := corev1.Toleration{Key: "dedicated", Operator: corev1.TolerationOpEqual, Value: "inference", Effect: corev1.TaintEffectNoSchedule}
+
+	tests := []struct {
+		name             string
+		acceleratorType  v1.AcceleratorType
+		gpu              string
+		kubernetesConfig *v1.KubernetesClusterConfig
+		expect           []corev1.Toleration
+	}{
+		{
+			name:            "nvidia gpu endpoint tolerates the nvidia taint",
+			acceleratorType: v1.AcceleratorTypeNVIDIAGPU,
+			gpu:             "1",
+			expect:          []corev1.Toleration{nvidiaToleration},
+		},
+		{
+			name:            "amd gpu endpoint tolerates the amd taint",
+			acceleratorType: v1.AcceleratorTypeAMDGPU,
+			gpu:             "1",
+			expect:          []corev1.Toleration{amdToleration},
+		},
+		{
+			name:            "endpoint without gpus does not tolerate the accelerator taint",
+			acceleratorType: v1.AcceleratorTypeNVIDIAGPU,
+			gpu:             "0",
+		},
+		{
+			name:             "accelerator tolerations disabled",
+			acceleratorType:  v1.AcceleratorTypeNVIDIAGPU,
+			gpu:              "1",
+			kubernetesConfig: &v1.KubernetesClusterConfig{DisableAcceleratorTolerations: true},
+		},
+		{
+			name:             "cluster tolerations are added",
+			acceleratorType:  v1.AcceleratorTypeNVIDIAGPU,
+			gpu:              "1",
+			kubernetesConfig: &v1.KubernetesClusterConfig{Tolerations: []corev1.Toleration{dedicatedToleration}},
+			expect:           []corev1.Toleration{nvidiaToleration, dedicatedToleration},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := &kubernetesOrchestrator{acceleratorMgr: accelerator.NewManager(gin.New())}
+			endpoint := &v1.Endpoint{
+				Metadata: &v1.Metadata{Name: "chat", Workspace: "default"},
+				Spec: &v1.EndpointSpec{
+					Resources: &v1.ResourceSpec{
+						GPU:         pointer.String(tt.gpu),
+						Accelerator: map[string]string{v1.AcceleratorTypeKey: string(tt.acceleratorType)},
+					},
+				},
+			}
+			cluster := &v1.Cluster{Spec: &v1.ClusterSpec{Config: &v1.ClusterConfig{KubernetesConfig: tt.kubernetesConfig}}}
+
+			data := newDeploymentManifestVariables()
+			require.NoError(t, o.setResourceVariables(&data, endpoint, cluster))
+			assert.Equal(t, tt.expect, data.Tolerations)
+		})
+	}
+}
+
+func TestKubernetesOrchestrator_setResourceVariablesOvercommit(t *testing.T) {
+	tests := []struct {
+		name           string
+		overcommit     *v1.OvercommitConfig
+		expectRequests map[string]string
+	}{
+		{
+			name:           "not overcommitted",
+			expectRequests: map[string]string{"cpu": "8", "memory": "32Gi", "nvidia.com/gpu": "2"},
+		},
+		{
+			name:           "cpu and memory overcommitted",
+			overcommit:     &v1.OvercommitConfig{CPURatio: 4, MemoryRatio: 2},
+			expectRequests: map[string]string{"cpu": "2", "memory": "16Gi", "nvidia.com/gpu": "2"},
+		},
+		{
+			name:           "fractional ratio",
+			overcommit:     &v1.OvercommitConfig{CPURatio: 1.6},
+			expectRequests: map[string]string{"cpu": "5", "memory": "32Gi", "nvidia.com/gpu": "2"},
+		},
+		{
+			name:           "ratio below one does not overcommit",
+			overcommit:     &v1.OvercommitConfig{CPURatio: 0.5, MemoryRatio: 1},
+			expectRequests: map[string]string{"cpu": "8", "memory": "32Gi", "nvidia.com/gpu": "2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := &kubernetesOrchestrator{acceleratorMgr: accelerator.NewManager(gin.New())}
+			endpoint := &v1.Endpoint{
+				Metadata: &v1.Metadata{Name: "chat", Workspace: "default"},
+				Spec: &v1.EndpointSpec{
+					Resources: &v1.ResourceSpec{
+						CPU:         pointer.String("8"),
+						Memory:      pointer.String("32"),
+						GPU:         pointer.String("2"),
+						Accelerator: map[string]string{v1.AcceleratorTypeKey: string(v1.AcceleratorTypeNVIDIAGPU)},
+					},
+				},
+			}
+			cluster := &v1.Cluster{Spec: &v1.ClusterSpec{Config: &v1.ClusterConfig{
+				KubernetesConfig: &v1.KubernetesClusterConfig{Overcommit: tt.overcommit},
+			}}}
+
+			data := newDeploymentManifestVariables()
+			require.NoError(t, o.setResourceVariables(&data, endpoint, cluster))
+			assert.Equal(t, map[string]string{"cpu": "8", "memory": "32Gi", "nvidia.com/gpu": "2"}, data.Resources)
+			assert.Equal(t, tt.expectRequests, data.ResourceRequests)
+
+			data.NeutreeVersion = "v0.1.0"
+			data.Namespace = "neutree-cluster-a"
+			data.ImagePrefix = "registry.example.com"
+			data.ImageRepo = "myrepo"
+			data.ImageTag = "v1.0.0"
+			data.EndpointName = "chat"
+			data.ModelArgs = map[string]interface{}{
+				"name":       "qwen3-8b",
+				"task":       "text-generation",
+				"path":       "/mnt/models/qwen3-8b",
+				"serve_name": "qwen3-8b",
+			}
+			data.RoutingLogic = "roundrobin"
+			data.Replicas = 1
+
+			objs, err := buildDeploymentObjects(realEmbeddedTemplate(t, "vllm-v0.24.0"), data)
+			require.NoError(t, err)
+
+			var dep appsv1.Deployment
+			require.NoError(t, runtime.DefaultUnstructuredConverter.FromUnstructured(objs.Items[0].Object, &dep))
+
+			container := dep.Spec.Template.Spec.Containers[0]
+			for name, request := range tt.expectRequests {
+				quantity := container.Resources.Requests[corev1.ResourceName(name)]
+				assert.Equal(t, request, quantity.String(), name)
+			}
+
+			gpuLimit := container.Resources.Limits["nvidia.com/gpu"]
+			assert.Equal(t, "2", gpuLimit.String())
+		})
+	}
+}
+
+func TestKubernetesOrchestrator_setResourceVariablesGPUDevicePlugin(t *testing.T) {
+	nvidiaToleration := corev1.Toleration{Key: "nvidia.com/gpu", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule}
+
+	tests := []struct {
+		name                string
+		clusterDevicePlugin v1.GPUDevicePlugin
+		endpointOption      interface{}
+		expectResourceName  string
+		expectError         string
+	}{
+		{
+			name:               "default",
+			expectResourceName: "nvidia.com/gpu",
+		},
+		{
+			name:                "cluster default plugin",
+			clusterDevicePlugin: v1.GPUDevicePluginDefault,
+			expectResourceName:  "nvidia.com/gpu",
+		},
+		{
+			name:                "cluster time-slicing plugin",
+			clusterDevicePlugin: v1.GPUDevicePluginTimeSlicing,
+			expectResourceName:  "nvidia.com/gpu.shared",
+		},
+		{
+			name:                "cluster mps plugin",
+			clusterDevicePlugin: v1.GPUDevicePluginMPS,
+			expectResourceName:  "nvidia.com/gpu.shared",
+		},
+		{
+			name:               "endpoint time-slicing plugin",
+			endpointOption:     "time-slicing",
+			expectResourceName: "nvidia.com/gpu.shared",
+		},
+		{
+			name:                "endpoint overrides the cluster plugin",
+			clusterDevicePlugin: v1.GPUDevicePluginMPS,
+			endpointOption:      "default",
+			expectResourceName:  "nvidia.com/gpu",
+		},
+		{
+			name:           "unknown plugin",
+			endpointOption: "mig",
+			expectError:    `unknown GPU device plugin "mig"`,
+		},
+		{
+			name:           "not a string",
+			endpointOption: true,
+			expectError:    "deployment_options.gpu_device_plugin must be a string",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := &kubernetesOrchestrator{acceleratorMgr: accelerator.NewManager(gin.New())}
+			endpoint := &v1.Endpoint{
+				Metadata: &v1.Metadata{Name: "chat", Workspace: "default"},
+				Spec: &v1.EndpointSpec{
+					Resources: &v1.ResourceSpec{
+						CPU:         pointer.String("8"),
+						GPU:         pointer.String("2"),
+						Accelerator: map[string]string{v1.AcceleratorTypeKey: string(v1.AcceleratorTypeNVIDIAGPU)},
+					},
+					DeploymentOptions: map[string]interface{}{},
+				},
+			}
+			if tt.endpointOption != nil {
+				endpoint.Spec.DeploymentOptions["gpu_device_plugin"] = tt.endpointOption
+			}
+			cluster := &v1.Cluster{Spec: &v1.ClusterSpec{Config: &v1.ClusterConfig{
+				KubernetesConfig: &v1.KubernetesClusterConfig{GPUDevicePlugin: tt.clusterDevicePlugin},
+			}}}
+
+			data := newDeploymentManifestVariables()
+			err := o.setResourceVariables(&data, endpoint, cluster)
+			if tt.expectError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectError)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, map[string]string{"cpu": "8", tt.expectResourceName: "2"}, data.Resources)
+			assert.Equal(t, map[string]string{"cpu": "8", tt.expectResourceName: "2"}, data.ResourceRequests)
+			assert.Equal(t, []corev1.Toleration{nvidiaToleration}, data.Tolerations)
+		})
+	}
+}
+
+func TestGetTolerations(t *testing.T) {
+	tests := []struct {
+		name        string
+		option      interface{}
+		expect      []corev1.Toleration
+		expectError string
+	}{
+		{
+			name: "not configured",
+		},
+		{
+			name: "team-dedicated node pool",
+			option: []interface{}{
+				map[string]interface{}{"key": "team", "operator": "Equal", "value": "search", "effect": "NoSchedule"},
+				map[string]interface{}{"operator": "Exists", "effect": "NoExecute", "tolerationSeconds": float64(60)},
+			},
+			expect: []corev1.Toleration{
+				{Key: "team", Operator: corev1.TolerationOpEqual, Value: "search", Effect: corev1.TaintEffectNoSchedule},
+				{Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoExecute, TolerationSeconds: pointer.Int64(60)},
+			},
+		},
+		{
+			name:        "not a list",
+			option:      map[string]interface{}{"key": "team"},
+			expectError: "deployment_options.tolerations must be a list of kubernetes tolerations",
+		},
+		{
+			name:        "key missing",
+			option:      []interface{}{map[string]interface{}{"value": "search", "effect": "NoSchedule"}},
+			expectError: "deployment_options.tolerations[0]: key is required unless operator is Exists",
+		},
+		{
+			name:        "value with exists operator",
+			option:      []interface{}{map[string]interface{}{"key": "team", "operator": "Exists", "value": "search"}},
+			expectError: "deployment_options.tolerations[0]: value must be empty when operator is Exists",
+		},
+		{
+			name:        "unknown operator",
+			option:      []interface{}{map[string]interface{}{"key": "team", "operator": "In"}},
+			expectError: `deployment_options.tolerations[0]: operator must be Equal or Exists, got "In"`,
+		},
+		{
+			name:        "unknown effect",
+			option:      []interface{}{map[string]interface{}{"key": "team", "value": "search", "effect": "NoEvict"}},
+			expectError: `deployment_options.tolerations[0]: effect must be NoSchedule, PreferNoSchedule or NoExecute, got "NoEvict"`,
+		},
+		{
+			name: "toleration seconds without NoExecute",
+			option: []interface{}{map[string]interface{}{
+				"key": "team", "value": "search", "effect": "NoSchedule", "tolerationSeconds": float64(60),
+			}},
+			expectError: "deployment_options.tolerations[0]: tolerationSeconds requires the NoExecute effect",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endpoint := &v1.Endpoint{Spec: &v1.EndpointSpec{DeploymentOptions: map[string]interface{}{}}}
+			if tt.option != nil {
+				endpoint.Spec.DeploymentOptions["tolerations"] = tt.option
+			}
+
+			tolerations, err := getTolerations(endpoint)
+			if tt.expectError != "" {
+				assert.EqualError(t, err, tt.expectError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expect, tolerations)
+		})
+	}
+}
+
+func TestBuildDeployment_EndpointTolerations(t *testing.T) {
+	o := &kubernetesOrchestrator{acceleratorMgr: accelerator.NewManager(gin.New())}
+	endpoint := &v1.Endpoint{
+		Metadata: &v1.Metadata{Name: "chat", Workspace: "default"},
+		Spec: &v1.EndpointSpec{
+			Resources: &v1.ResourceSpec{
+				GPU:         pointer.String("1"),
+				Accelerator: map[string]string{v1.AcceleratorTypeKey: string(v1.AcceleratorTypeNVIDIAGPU)},
+			},
+			DeploymentOptions: map[string]interface{}{
+				"tolerations": []interface{}{
+					map[string]interface{}{"key": "team", "operator": "Equal", "value": "search", "effect": "NoSchedule"},
+				},
+			},
+		},
+	}
+	cluster := &v1.Cluster{Spec: &v1.ClusterSpec{}}
+
+	data := newDeploymentManifestVariables()
+	data.NeutreeVersion = "v0.1.0"
+	data.Namespace = "default"
+	data.ImagePrefix = "registry.example.com"
+	data.ImageRepo = "myrepo"
+	data.ImageTag = "v1.0.0"
+	data.EndpointName = "chat"
+	data.ModelArgs = map[string]interface{}{
+		"name":       "gpt-4",
+		"task":       "text-generation",
+		"path":       "/mnt/models/gpt-4",
+		"serve_name": "gpt-4",
+	}
+	data.RoutingLogic = "roundrobin"
+	data.Replicas = 1
+
+	require.NoError(t, o.setResourceVariables(&data, endpoint, cluster))
+	require.NoError(t, o.setTolerationVariables(&data, endpoint))
+
+	objs, err := buildDeploymentObjects(realEmbeddedTemplate(t, "vllm-v0.17.1"), data)
+	require.NoError(t, err)
+
+	var deployment appsv1.Deployment
+
+	for _, obj := range objs.Items {
+		if obj.GetKind() == "Deployment" {
+			require.NoError(t, runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &deployment))
+		}
+	}
+
+	assert.Equal(t, []corev1.Toleration{
+		{Key: "nvidia.com/gpu", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+		{Key: "team", Operator: corev1.TolerationOpEqual, Value: "search", Effect: corev1.TaintEffectNoSchedule},
+	}, deployment.Spec.Template.Spec.Tolerations)
+}
+
+func TestGetSecurityContextOptions(t *testing.T) {
+	tests := []struct {
+		name        string
+		option      interface{}
+		expect      *securityContextOptions
+		expectError string
+	}{
+		{
+			name: "not configured",
+		},
+		{
+			name:   "read-only root filesystem",
+			option: map[string]interface{}{"read_only_root_filesystem": true},
+			expect: &securityContextOptions{ReadOnlyRootFilesystem: true},
+		},
+		{
+			name:        "not an object",
+			option:      true,
+			expectError: "deployment_options.security_context must be an object",
+		},
+		{
+			name:        "not a boolean",
+			option:      map[string]interface{}{"read_only_root_filesystem": "true"},
+			expectError: "deployment_options.security_context.read_only_root_filesystem must be a boolean",
+		},
+		{
+			name:        "unknown key",
+			option:      map[string]interface{}{"run_as_non_root": true},
+			expectError: "unknown deployment_options.security_context.run_as_non_root",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endpoint := &v1.Endpoint{Spec: &v1.EndpointSpec{DeploymentOptions: map[string]interface{}{}}}
+			if tt.option != nil {
+				endpoint.Spec.DeploymentOptions["security_context"] = tt.option
+			}
+
+			opts, err := getSecurityContextOptions(endpoint)
+			if tt.expectError != "" {
+				assert.EqualError(t, err, tt.expectError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expect, opts)
+		})
+	}
+}
+
+func TestBuildDeployment_ReadOnlyRootFilesystem(t *testing.T) {
+	writableMounts := []corev1.VolumeMount{
+		{Name: "writable-tmp", MountPath: "/tmp"},
+		{Name: "writable-home", MountPath: "/var/lib/neutree/home"},
+		{Name: "writable-logs", MountPath: "/var/log"},
+	}
+
+	for _, templateKey := range []string{"vllm-v0.11.2", "vllm-v0.17.1", "vllm-v0.24.0", "sglang-v0.5.10", "llama-cpp-v0.3.7"} {
+		for _, readOnly := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s/read-only=%t", templateKey, readOnly), func(t *testing.T) {
+				endpoint := &v1.Endpoint{Spec: &v1.EndpointSpec{DeploymentOptions: map[string]interface{}{}}}
+				if readOnly {
+					endpoint.Spec.DeploymentOptions["security_context"] = map[string]interface{}{"read_only_root_filesystem": true}
+				}
+
+				data := newDeploymentManifestVariables()
+				data.NeutreeVersion = "v0.1.0"
+				data.Namespace = "default"
+				data.ImagePrefix = "registry.example.com"
+				data.ImageRepo = "myrepo"
+				data.ImageTag = "v1.0.0"
+				data.EndpointName = "test-endpoint"
+				data.ModelArgs = map[string]interface{}{
+					"name":       "gpt-4",
+					"task":       "text-generation",
+					"path":       "/mnt/models/gpt-4",
+					"serve_name": "gpt-4",
+				}
+				data.RoutingLogic = "roundrobin"
+				data.Replicas = 1
+
+				require.NoError(t, (&kubernetesOrchestrator{}).setSecurityContextVariables(&data, endpoint))
+
+				objs, err := buildDeploymentObjects(realEmbeddedTemplate(t, templateKey), data)
+				require.NoError(t, err)
+
+				var deployment appsv1.Deployment
+
+				for _, obj := range objs.Items {
+					if obj.GetKind() == "Deployment" {
+						require.NoError(t, runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &deployment))
+					}
+				}
+
+				containers := append(deployment.Spec.Template.Spec.InitContainers, deployment.Spec.Template.Spec.Containers...)
+				require.Len(t, containers, 2)
+
+				for _, container := range containers {
+					if !readOnly {
+						assert.Nil(t, container.SecurityContext, container.Name)
+						assert.Empty(t, container.VolumeMounts, container.Name)
+
+						continue
+					}
+
+					require.NotNil(t, container.SecurityContext, container.Name)
+					assert.Equal(t, pointer.Bool(true), container.SecurityContext.ReadOnlyRootFilesystem, container.Name)
+					assert.Equal(t, writableMounts, container.VolumeMounts, container.Name)
+					assert.Contains(t, container.Env, corev1.EnvVar{Name: "HOME", Value: "/var/lib/neutree/home"}, container.Name)
+				}
+
+				if readOnly {
+					assert.Len(t, deployment.Spec.Template.Spec.Volumes, len(writableMounts))
+				} else {
+					assert.Empty(t, deployment.Spec.Template.Spec.Volumes)
+				}
+			})
+		}
+	}
+}
+
+func TestGetLifecycle(t *testing.T) {
+	tests := []struct {
+		name        string
+		option      interface{}
+		expect      *corev1.Lifecycle
+		expectError string
+	}{
+		{
+			name: "not configured",
+		},
+		{
+			name:   "no hook",
+			option: map[string]interface{}{},
+		},
+		{
+			name: "pre_stop and post_start",
+			option: map[string]interface{}{
+				"pre_stop":   []interface{}{"sh", "-c", "sleep 15"},
+				"post_start": []interface{}{"/bin/warmup"},
+			},
+			expect: &corev1.Lifecycle{
+				PreStop:   &corev1.LifecycleHandler{Exec: &corev1.ExecAction{Command: []string{"sh", "-c", "sleep 15"}}},
+				PostStart: &corev1.LifecycleHandler{Exec: &corev1.ExecAction{Command: []string{"/bin/warmup"}}},
+			},
+		},
+		{
+			name:        "not an object",
+			option:      []interface{}{"sleep", "15"},
+			expectError: "deployment_options.lifecycle must be an object",
+		},
+		{
+			name:        "command not a list",
+			option:      map[string]interface{}{"pre_stop": "sleep 15"},
+			expectError: "deployment_options.lifecycle.pre_stop: must be a non-empty list of strings",
+		},
+		{
+			name:        "empty command",
+			option:      map[string]interface{}{"post_start": []interface{}{}},
+			expectError: "deployment_options.lifecycle.post_start: must be a non-empty list of strings",
+		},
+		{
+			name:        "argument not a string",
+			option:      map[string]interface{}{"pre_stop": []interface{}{"sleep", 15}},
+			expectError: "deployment_options.lifecycle.pre_stop: [1] must be a string",
+		},
+		{
+			name:        "empty executable",
+			option:      map[string]interface{}{"pre_stop": []interface{}{" ", "15"}},
+			expectError: "deployment_options.lifecycle.pre_stop: executable must not be empty",
+		},
+		{
+			name:        "unknown hook",
+			option:      map[string]interface{}{"pre_start": []interface{}{"true"}},
+			expectError: "unknown deployment_options.lifecycle.pre_start",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endpoint := &v1.Endpoint{Spec: &v1.EndpointSpec{DeploymentOptions: map[string]interface{}{}}}
+			if tt.option != nil {
+				endpoint.Spec.DeploymentOptions["lifecycle"] = tt.option
+			}
+
+			lifecycle, err := getLifecycle(endpoint)
+			if tt.expectError != "" {
+				assert.EqualError(t, err, tt.expectError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expect, lifecycle)
+		})
+	}
+}
+
+func TestBuildDeployment_Lifecycle(t *testing.T) {
+	preStop := []string{"sh", "-c", "sleep 15"}
+
+	for _, templateKey := range []string{"vllm-v0.11.2", "vllm-v0.17.1", "vllm-v0.24.0", "sglang-v0.5.10", "llama-cpp-v0.3.7"} {
+		for _, configured := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s/configured=%t", templateKey, configured), func(t *testing.T) {
+				endpoint := &v1.Endpoint{Spec: &v1.EndpointSpec{DeploymentOptions: map[string]interface{}{}}}
+				if configured {
+					endpoint.Spec.DeploymentOptions["lifecycle"] = map[string]interface{}{
+						"pre_stop":   []interface{}{"sh", "-c", "sleep 15"},
+						"post_start": []interface{}{"/bin/warmup"},
+					}
+				}
+
+				data := newDeploymentManifestVariables()
+				data.NeutreeVersion = "v0.1.0"
+				data.Namespace = "default"
+				data.ImagePrefix = "registry.example.com"
+				data.ImageRepo = "myrepo"
+				data.ImageTag = "v1.0.0"
+				data.EndpointName = "test-endpoint"
+				data.ModelArgs = map[string]interface{}{
+					"name":       "gpt-4",
+					"task":       "text-generation",
+					"path":       "/mnt/models/gpt-4",
+					"serve_name": "gpt-4",
+				}
+				data.RoutingLogic = "roundrobin"
+				data.Replicas = 1
+
+				require.NoError(t, (&kubernetesOrchestrator{}).setLifecycleVariables(&data, endpoint))
+
+				objs, err := buildDeploymentObjects(realEmbeddedTemplate(t, templateKey), data)
+				require.NoError(t, err)
+
+				var deployment appsv1.Deployment
+
+				for _, obj := range objs.Items {
+					if obj.GetKind() == "Deployment" {
+						require.NoError(t, runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &deployment))
+					}
+				}
+
+				require.Len(t, deployment.Spec.Template.Spec.Containers, 1)
+				require.Len(t, deployment.Spec.Template.Spec.InitContainers, 1)
+				assert.Nil(t, deployment.Spec.Template.Spec.InitContainers[0].Lifecycle)
+
+				lifecycle := deployment.Spec.Template.Spec.Containers[0].Lifecycle
+				if !configured {
+					assert.Nil(t, lifecycle)
+					return
+				}
+
+				require.NotNil(t, lifecycle)
+				require.NotNil(t, lifecycle.PreStop)
+				require.NotNil(t, lifecycle.PostStart)
+				assert.Equal(t, preStop, lifecycle.PreStop.Exec.Command)
+				assert.Equal(t, []string{"/bin/warmup"}, lifecycle.PostStart.Exec.Command)
+			})
+		}
+	}
+}
+
+func TestGetTerminationGracePeriodSeconds(t *testing.T) {
+	tests := []struct {
+		name        string
+		task        string
+		option      interface{}
+		expect      *int64
+		expectError string
+	}{
+		{
+			name:   "text generation default",
+			task:   v1.TextGenerationModelTask,
+			expect: int64Ptr(630),
+		},
+		{
+			name:   "embedding default",
+			task:   v1.TextEmbeddingModelTask,
+			expect: int64Ptr(60),
+		},
+		{
+			name:   "unknown task default",
+			expect: int64Ptr(330),
+		},
+		{
+			name:   "configured",
+			task:   v1.TextGenerationModelTask,
+			option: 900,
+			expect: int64Ptr(900),
+		},
+		{
+			name:   "zero",
+			option: 0,
+			expect: int64Ptr(0),
+		},
+		{
+			name:        "negative",
+			option:      -1,
+			expectError: "deployment_options.termination_grace_period_seconds must be a non-negative integer",
+		},
+		{
+			name:        "fractional",
+			option:      1.5,
+			expectError: "deployment_options.termination_grace_period_seconds must be a non-negative integer",
+		},
+		{
+			name:        "not a number",
+			option:      "10m",
+			expectError: "deployment_options.termination_grace_period_seconds must be a non-negative integer",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endpoint := &v1.Endpoint{Spec: &v1.EndpointSpec{
+				Model:             &v1.ModelSpec{Task: tt.task},
+				DeploymentOptions: map[string]interface{}{},
+			}}
+			if tt.option != nil {
+				endpoint.Spec.DeploymentOptions["termination_grace_period_seconds"] = tt.option
+			}
+
+			seconds, err := getTerminationGracePeriodSeconds(endpoint)
+			if tt.expectError != "" {
+				assert.EqualError(t, err, tt.expectError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expect, seconds)
+		})
+	}
+}
+
+func TestBuildDeployment_TerminationGracePeriod(t *testing.T) {
+	for _, templateKey := range []string{"vllm-v0.11.2", "vllm-v0.17.1", "vllm-v0.24.0", "sglang-v0.5.10", "llama-cpp-v0.3.7"} {
+		for _, option := range []interface{}{nil, 900, 0} {
+			t.Run(fmt.Sprintf("%s/option=%v", templateKey, option), func(t *testing.T) {
+				endpoint := &v1.Endpoint{Spec: &v1.EndpointSpec{
+					Model:             &v1.ModelSpec{Task: v1.TextGenerationModelTask},
+					DeploymentOptions: map[string]interface{}{},
+				}}
+				if option != nil {
+					endpoint.Spec.DeploymentOptions["termination_grace_period_seconds"] = option
+				}
+
+				data := newDeploymentManifestVariables()
+				data.NeutreeVersion = "v0.1.0"
+				data.Namespace = "default"
+				data.ImagePrefix = "registry.example.com"
+				data.ImageRepo = "myrepo"
+				data.ImageTag = "v1.0.0"
+				data.EndpointName = "test-endpoint"
+				data.ModelArgs = map[string]interface{}{
+					"name":       "gpt-4",
+					"task":       "text-generation",
+					"path":       "/mnt/models/gpt-4",
+					"serve_name": "gpt-4",
+				}
+				data.RoutingLogic = "roundrobin"
+				data.Replicas = 1
+
+				require.NoError(t, (&kubernetesOrchestrator{}).setLifecycleVariables(&data, endpoint))
+
+				objs, err := buildDeploymentObjects(realEmbeddedTemplate(t, templateKey), data)
+				require.NoError(t, err)
+
+				var deployment appsv1.Deployment
+
+				for _, obj := range objs.Items {
+					if obj.GetKind() == "Deployment" {
+						require.NoError(t, runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &deployment))
+					}
+				}
+
+				expect := int64(630)
+				if option != nil {
+					expect = int64(option.(int))
+				}
+
+				require.NotNil(t, deployment.Spec.Template.Spec.TerminationGracePeriodSeconds)
+				assert.Equal(t, expect, *deployment.Spec.Template.Spec.TerminationGracePeriodSeconds)
+			})
+		}
+	}
+}
+
+func TestGetModelConversionOptions(t *testing.T) {
+	tests := []struct {
+		name        string
+		option      interface{}
+		expect      *modelConversionOptions
+		expectError string
+	}{
+		{
+			name: "not configured",
+		},
+		{
+			name:   "engine image",
+			option: map[string]interface{}{"command": []interface{}{"/bin/convert", "--outtype", "q8_0"}},
+			expect: &modelConversionOptions{Command: []string{"/bin/convert", "--outtype", "q8_0"}},
+		},
+		{
+			name: "conversion image",
+			option: map[string]interface{}{
+				"image":   "ghcr.io/ggml-org/llama.cpp:full",
+				"command": []interface{}{"/bin/convert"},
+			},
+			expect: &modelConversionOptions{Image: "ghcr.io/ggml-org/llama.cpp:full", Command: []string{"/bin/convert"}},
+		},
+		{
+			name:        "not an object",
+			option:      []interface{}{"/bin/convert"},
+			expectError: "deployment_options.model_conversion must be an object",
+		},
+		{
+			name:        "no command",
+			option:      map[string]interface{}{"image": "ghcr.io/ggml-org/llama.cpp:full"},
+			expectError: "deployment_options.model_conversion.command is required",
+		},
+		{
+			name:        "command not a list",
+			option:      map[string]interface{}{"command": "/bin/convert"},
+			expectError: "deployment_options.model_conversion.command: must be a non-empty list of strings",
+		},
+		{
+			name:        "image not a string",
+			option:      map[string]interface{}{"image": 1, "command": []interface{}{"/bin/convert"}},
+			expectError: "deployment_options.model_conversion.image must be a string",
+		},
+		{
+			name:        "unknown field",
+			option:      map[string]interface{}{"command": []interface{}{"/bin/convert"}, "args": []interface{}{"-v"}},
+			expectError: "unknown deployment_options.model_conversion.args",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endpoint := &v1.Endpoint{Spec: &v1.EndpointSpec{DeploymentOptions: map[string]interface{}{}}}
+			if tt.option != nil {
+				endpoint.Spec.DeploymentOptions["model_conversion"] = tt.option
+			}
+
+			opts, err := getModelConversionOptions(endpoint)
+			if tt.expectError != "" {
+				assert.EqualError(t, err, tt.expectError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expect, opts)
+		})
+	}
+}
+
+func TestBuildDeployment_ModelConversion(t *testing.T) {
+	for _, templateKey := range []string{"vllm-v0.11.2", "vllm-v0.17.1", "vllm-v0.24.0", "sglang-v0.5.10", "llama-cpp-v0.3.7"} {
+		for _, image := range []string{"", "ghcr.io/ggml-org/llama.cpp:full"} {
+			t.Run(fmt.Sprintf("%s/image=%q", templateKey, image), func(t *testing.T) {
+				option := map[string]interface{}{"command": []interface{}{"sh", "-c", "convert $NEUTREE_MODEL_PATH"}}
+				if image != "" {
+					option["image"] = image
+				}
+
+				endpoint := &v1.Endpoint{Spec: &v1.EndpointSpec{DeploymentOptions: map[string]interface{}{
+					"model_conversion": option,
+				}}}
+
+				data := newDeploymentManifestVariables()
+				data.NeutreeVersion = "v0.1.0"
+				data.Namespace = "default"
+				data.ImagePrefix = "registry.example.com"
+				data.ImageRepo = "myrepo"
+				data.ImageTag = "v1.0.0"
+				data.EndpointName = "test-endpoint"
+				data.ModelArgs = map[string]interface{}{
+					"name":       "gpt-4",
+					"task":       "text-generation",
+					"path":       "/mnt/models/gpt-4",
+					"serve_name": "gpt-4",
+				}
+				data.RoutingLogic = "roundrobin"
+				data.Replicas = 1
+				data.Resources = map[string]string{"cpu": "4", "memory": "16Gi", "nvidia.com/gpu": "1"}
+				data.ResourceRequests = map[string]string{"cpu": "2", "memory": "16Gi", "nvidia.com/gpu": "1"}
+				data.Env = map[string]string{"HF_HOME": "/mnt/models"}
+				data.VolumeMounts = []corev1.VolumeMount{{Name: "models-cache", MountPath: "/mnt/models"}}
+
+				require.NoError(t, (&kubernetesOrchestrator{}).setModelConversionVariables(&data, endpoint))
+
+				objs, err := buildDeploymentObjects(realEmbeddedTemplate(t, templateKey), data)
+				require.NoError(t, err)
+
+				var deployment appsv1.Deployment
+
+				for _, obj := range objs.Items {
+					if obj.GetKind() == "Deployment" {
+						require.NoError(t, runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &deployment))
+					}
+				}
+
+				// Init containers run in order, the conversion after the download and before the engine.
+				initContainers := deployment.Spec.Template.Spec.InitContainers
+				require.Len(t, initContainers, 2)
+				assert.Equal(t, "model-downloader", initContainers[0].Name)
+				assert.Equal(t, "model-conversion", initContainers[1].Name)
+				require.Len(t, deployment.Spec.Template.Spec.Containers, 1)
+
+				conversion := initContainers[1]
+				if image == "" {
+					assert.Equal(t, "registry.example.com/myrepo:v1.0.0", conversion.Image)
+				} else {
+					assert.Equal(t, image, conversion.Image)
+				}
+
+				assert.Equal(t, []string{"sh", "-c", modelConversionLockScript, "model-conversion"}, conversion.Command)
+				assert.Equal(t, []string{"sh", "-c", "convert $NEUTREE_MODEL_PATH"}, conversion.Args)
+				assert.Equal(t, []corev1.EnvVar{
+					{Name: "HF_HOME", Value: "/mnt/models"},
+					{Name: "NEUTREE_MODEL_PATH", Value: "/mnt/models/gpt-4"},
+				}, conversion.Env)
+				assert.Equal(t, data.VolumeMounts, conversion.VolumeMounts)
+				assert.Equal(t, resource.MustParse("1"), conversion.Resources.Limits["nvidia.com/gpu"])
+				assert.Equal(t, resource.MustParse("2"), conversion.Resources.Requests[corev1.ResourceCPU])
+			})
+		}
+	}
+}
+
+func TestModelConversionLockScript(t *testing.T) {
+	if _, err := exec.LookPath("flock"); err != nil {
+		t.Skip("flock is not installed")
+	}
+
+	modelPath := t.TempDir()
+
+	cmd := exec.Command("sh", "-c", modelConversionLockScript, "model-conversion",
+		"sh", "-c", `echo "$1" > "$NEUTREE_MODEL_PATH/model.gguf"`, "convert", "converted")
+	cmd.Env = append(os.Environ(), "NEUTREE_MODEL_PATH="+modelPath)
+
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+
+	converted, err := os.ReadFile(filepath.Join(modelPath, "model.gguf"))
+	require.NoError(t, err)
+	assert.Equal(t, "converted\n", string(converted))
+	assert.FileExists(t, filepath.Join(modelPath, ".neutree-conversion.lock"))
+}
+
+func TestGetAuthSidecarOptions(t *testing.T) {
+	tests := []struct {
+		name        string
+		option      interface{}
+		expect      *authSidecarOptions
+		expectError string
+	}{
+		{
+			name: "not configured",
+		},
+		{
+			name: "configured",
+			option: map[string]interface{}{
+				"image":        "registry.example.com/neutree/auth-proxy:v1",
+				"validate_url": "http://token-validator.auth.svc:8080/validate",
+			},
+			expect: &authSidecarOptions{
+				Image:       "registry.example.com/neutree/auth-proxy:v1",
+				ValidateURL: "http://token-validator.auth.svc:8080/validate",
+			},
+		},
+		{
+			name:        "not an object",
+			option:      "registry.example.com/neutree/auth-proxy:v1",
+			expectError: "deployment_options.auth_sidecar must be an object",
+		},
+		{
+			name:   "default image",
+			option: map[string]interface{}{"validate_url": "https://neutree.example.com/api/v1/auth/validate"},
+			expect: &authSidecarOptions{ValidateURL: "https://neutree.example.com/api/v1/auth/validate"},
+		},
+		{
+			name:        "no validate url",
+			option:      map[string]interface{}{"image": "registry.example.com/neutree/auth-proxy:v1"},
+			expectError: "deployment_options.auth_sidecar.validate_url is required",
+		},
+		{
+			name: "validate url not http",
+			option: map[string]interface{}{
+				"image":        "registry.example.com/neutree/auth-proxy:v1",
+				"validate_url": "token-validator.auth.svc:8080/validate",
+			},
+			expectError: `deployment_options.auth_sidecar.validate_url must be an http(s) URL, got "token-validator.auth.svc:8080/validate"`,
+		},
+		{
+			name:        "image not a string",
+			option:      map[string]interface{}{"image": 1},
+			expectError: "deployment_options.auth_sidecar.image must be a string",
+		},
+		{
+			name:        "unknown field",
+			option:      map[string]interface{}{"image": "registry.example.com/neutree/auth-proxy:v1", "port": 9000},
+			expectError: "unknown deployment_options.auth_sidecar.port",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endpoint := &v1.Endpoint{Spec: &v1.EndpointSpec{DeploymentOptions: map[string]interface{}{}}}
+			if tt.option != nil {
+				endpoint.Spec.DeploymentOptions["auth_sidecar"] = tt.option
+			}
+
+			opts, err := getAuthSidecarOptions(endpoint)
+			if tt.expectError != "" {
+				assert.EqualError(t, err, tt.expectError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expect, opts)
+		})
+	}
+}
+
+func TestBuildDeployment_AuthSidecar(t *testing.T) {
+	for _, templateKey := range []string{"vllm-v0.11.2", "vllm-v0.17.1", "vllm-v0.24.0", "sglang-v0.5.10", "llama-cpp-v0.3.7"} {
+		for _, sidecar := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s/sidecar=%v", templateKey, sidecar), func(t *testing.T) {
+				endpoint := &v1.Endpoint{Spec: &v1.EndpointSpec{DeploymentOptions: map[string]interface{}{}}}
+				if sidecar {
+					endpoint.Spec.DeploymentOptions["auth_sidecar"] = map[string]interface{}{
+						"validate_url": "https://neutree.example.com/api/v1/auth/validate",
+					}
+				}
+
+				data := newDeploymentManifestVariables()
+				data.EngineName = strings.Split(templateKey, "-v")[0]
+				data.NeutreeVersion = "v0.1.0"
+				data.Namespace = "default"
+				data.ImagePrefix = "registry.example.com"
+				data.ImageRepo = "myrepo"
+				data.ImageTag = "v1.0.0"
+				data.EndpointName = "test-endpoint"
+				data.ModelArgs = map[string]interface{}{
+					"name":       "gpt-4",
+					"task":       "text-generation",
+					"path":       "/mnt/models/gpt-4",
+					"serve_name": "gpt-4",
+				}
+				data.RoutingLogic = "roundrobin"
+				data.Replicas = 1
+				data.ServiceMesh = string(v1.ServiceMeshTypeIstio)
+
+				require.NoError(t, (&kubernetesOrchestrator{}).setAuthSidecarVariables(&data, endpoint))
+
+				objs, err := buildDeploymentObjects(realEmbeddedTemplate(t, templateKey), data)
+				require.NoError(t, err)
+
+				var deployment appsv1.Deployment
+
+				for _, obj := range objs.Items {
+					if obj.GetKind() == "Deployment" {
+						require.NoError(t, runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &deployment))
+					}
+				}
+
+				containers := deployment.Spec.Template.Spec.Containers
+				engine := containers[0]
+				engineListen := "--host 0.0.0.0 --port 8000"
+
+				if !sidecar {
+					require.Len(t, containers, 1)
+					assert.Equal(t, []corev1.ContainerPort{{ContainerPort: 8000, Name: "http"}}, engine.Ports)
+				} else {
+					// The sidecar takes over the serving port and forwards to the engine moved off
+					// it, out of reach of the other pods.
+					engineListen = "--host 127.0.0.1 --port 8001"
+
+					require.Len(t, containers, 2)
+					assert.Equal(t, []corev1.ContainerPort{{ContainerPort: 8001}}, engine.Ports)
+
+					healthCheckPath := "/health"
+					if templateKey == "llama-cpp-v0.3.7" {
+						healthCheckPath = "/v1/models"
+					}
+
+					proxy := containers[1]
+					assert.Equal(t, "auth-proxy", proxy.Name)
+					assert.Equal(t, "registry.example.com/neutree/neutree-runtime:v0.1.0", proxy.Image)
+					assert.Equal(t, []string{"python3", "-m", "neutree.authproxy"}, proxy.Command)
+					assert.Equal(t, []corev1.ContainerPort{{ContainerPort: 8000, Name: "http"}}, proxy.Ports)
+					assert.Equal(t, []corev1.EnvVar{
+						{Name: "NEUTREE_AUTH_LISTEN_PORT", Value: "8000"},
+						{Name: "NEUTREE_AUTH_UPSTREAM", Value: "http://127.0.0.1:8001"},
+						{Name: "NEUTREE_AUTH_VALIDATE_URL", Value: "https://neutree.example.com/api/v1/auth/validate"},
+						{Name: "NEUTREE_AUTH_PUBLIC_PATHS", Value: healthCheckPath + ",/metrics"},
+					}, proxy.Env)
+				}
+
+				command := strings.Join(append(engine.Command, engine.Args...), " ")
+				assert.Regexp(t, strings.ReplaceAll(engineListen, " ", `\s+`), command)
+
+				// The engine is probed on the serving port, through the sidecar if any.
+				assert.Equal(t, int32(8000), engine.StartupProbe.HTTPGet.Port.IntVal)
+				assert.Equal(t, int32(8000), engine.ReadinessProbe.HTTPGet.Port.IntVal)
+			})
+		}
+	}
+}
+
+func TestBuildDeployment_Tolerations(t *testing.T) {
+	for _, templateKey := range []string{"vllm-v0.11.2", "vllm-v0.17.1", "vllm-v0.24.0", "sglang-v0.5.10", "llama-cpp-v0.3.7"} {
+		t.Run(templateKey, func(t *testing.T) {
+			data := newDeploymentManifestVariables()
+			data.NeutreeVersion = "v0.1.0"
+			data.Namespace = "default"
+			data.ImagePrefix = "registry.example.com"
+			data.ImageRepo = "myrepo"
+			data.ImageTag = "v1.0.0"
+			data.EndpointName = "test-endpoint"
+			data.ModelArgs = map[string]interface{}{
+				"name":       "gpt-4",
+				"task":       "text-generation",
+				"path":       "/mnt/models/gpt-4",
+				"serve_name": "gpt-4",
+			}
+			data.RoutingLogic = "roundrobin"
+			data.Replicas = 1
+			data.Tolerations = []corev1.Toleration{
+				{Key: "nvidia.com/gpu", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+			}
+
+			objs, err := buildDeploymentObjects(realEmbeddedTemplate(t, templateKey), data)
+			require.NoError(t, err)
+
+			var deployment appsv1.Deployment
+
+			for _, obj := range objs.Items {
+				if obj.GetKind() == "Deployment" {
+					require.NoError(t, runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &deployment))
+				}
+			}
+
+			assert.Equal(t, data.Tolerations, deployment.Spec.Template.Spec.Tolerations)
+		})
+	}
+}
+
+func TestGetModelCacheName(t *testing.T) {
+	cluster := &v1.Cluster{
+		Metadata: &v1.Metadata{Name: "test-cluster", Workspace: "default"},
+		Spec: &v1.ClusterSpec{
+			Config: &v1.ClusterConfig{
+				ModelCaches: []v1.ModelCache{{Name: "team-a"}, {Name: "team-b"}},
+			},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		option      interface{}
+		expect      string
+		expectError string
+	}{
+		{
+			name: "not configured",
+		},
+		{
+			name:   "configured",
+			option: " team-b ",
+			expect: "team-b",
+		},
+		{
+			name:        "not a string",
+			option:      1,
+			expectError: "deployment_options.model_cache must be a non-empty string",
+		},
+		{
+			name:        "empty",
+			option:      "",
+			expectError: "deployment_options.model_cache must be a non-empty string",
+		},
+		{
+			name:        "unknown model cache",
+			option:      "team-c",
+			expectError: "deployment_options.model_cache: cluster default/test-cluster has no model cache team-c",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endpoint := &v1.Endpoint{Spec: &v1.EndpointSpec{DeploymentOptions: map[string]interface{}{}}}
+			if tt.option != nil {
+				endpoint.Spec.DeploymentOptions["model_cache"] = tt.option
+			}
+
+			name, err := getModelCacheName(endpoint, cluster)
+			if tt.expectError != "" {
+				assert.EqualError(t, err, tt.expectError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expect, name)
+		})
+	}
+}
+
+func TestDeployedTerminationGracePeriodSeconds(t *testing.T) {
+	withGracePeriod := func(seconds *int64) corev1.PodTemplateSpec {
+		return corev1.PodTemplateSpec{Spec: corev1.PodSpec{TerminationGracePeriodSeconds: seconds}}
+	}
+
+	lws := newLeaderWorkerSet("test-endpoint", "default")
+	lws.Object["spec"] = map[string]interface{}{
+		"leaderWorkerTemplate": map[string]interface{}{
+			"leaderTemplate": map[string]interface{}{
+				"spec": map[string]interface{}{"terminationGracePeriodSeconds": int64(45)},
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		workload client.Object
+		expect   *int64
+	}{
+		{
+			name:     "deployment",
+			workload: &appsv1.Deployment{Spec: appsv1.DeploymentSpec{Template: withGracePeriod(int64Ptr(30))}},
+			expect:   int64Ptr(30),
+		},
+		{
+			name:     "statefulset",
+			workload: &appsv1.StatefulSet{Spec: appsv1.StatefulSetSpec{Template: withGracePeriod(int64Ptr(900))}},
+			expect:   int64Ptr(900),
+		},
+		{
+			name:     "leaderworkerset",
+			workload: lws,
+			expect:   int64Ptr(45),
+		},
+		{
+			name:     "not set",
+			workload: &appsv1.Deployment{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expect, deployedTerminationGracePeriodSeconds(tt.workload))
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v1 "github.com/neutree-ai/neutree/api/v1"
+	"github.com/neutree-ai/neutree/internal/util"
+)
+
+const (
+	// replicaGroupLabel tells the pods of the spot replica group apart from the on-demand
+	// ones, whose Deployment keeps the selector it had before mixed placement was enabled.
+	replicaGroupLabel = "replica-group"
+	replicaGroupSpot  = "spot"
+)
+
+// spotDeploymentName returns the name of the Deployment of the spot replica group, the
+// on-demand replica group keeps the Deployment named after the endpoint.
+func spotDeploymentName(endpointName string) string {
+	return endpointName + "-spot"
+}
+
+// splitMixedPlacementReplicas returns how many of the replicas are placed on on-demand and
+// on spot nodes.
+func splitMixedPlacementReplicas(replicas int32, opts *mixedPlacementOptions) (int32, int32) {
+	onDemand := min(int32(opts.OnDemandReplicas), replicas) //nolint:gosec
+
+	return onDemand, replicas - onDemand
+}
+
+// withReplicaGroupPlacement returns the variables rendering the given number of replicas
+// placed as the replica group asks, on top of the placement of the endpoint.
+func withReplicaGroupPlacement(data DeploymentManifestVariables, replicas int32, placement replicaGroupPlacement) DeploymentManifestVariables {
+	data.Replicas = replicas
+
+	data.NodeSelector = maps.Clone(data.NodeSelector)
+	if data.NodeSelector == nil {
+		data.NodeSelector = make(map[string]string)
+	}
+
+	maps.Copy(data.NodeSelector, placement.NodeSelector)
+
+	data.Tolerations = append(slices.Clone(data.Tolerations), placement.Tolerations...)
+
+	return data
+}
+
+// buildReplicaGroupObjects renders the deploy template of an endpoint. With mixed placement
+// the template is rendered once per replica group and the Deployment of the spot group is
+// renamed and labeled apart, the other objects are shared by both groups.
+func buildReplicaGroupObjects(deployTemplate string, renderVars DeploymentManifestVariables,
+	opts *mixedPlacementOptions) (*unstructured.UnstructuredList, error) {
+	if opts == nil {
+		return buildDeploymentObjects(deployTemplate, renderVars)
+	}
+
+	onDemandReplicas, spotReplicas := splitMixedPlacementReplicas(renderVars.Replicas, opts)
+
+	objects, err := buildDeploymentObjects(deployTemplate, withReplicaGroupPlacement(renderVars, onDemandReplicas, opts.OnDemand))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build on-demand replica group")
+	}
+
+	if spotReplicas == 0 {
+		return objects, nil
+	}
+
+	spotObjects, err := buildDeploymentObjects(deployTemplate, withReplicaGroupPlacement(renderVars, spotReplicas, opts.Spot))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build spot replica group")
+	}
+
+	for _, obj := range spotObjects.Items {
+		if obj.GetKind() != "Deployment" {
+			continue
+		}
+
+		obj.SetName(spotDeploymentName(renderVars.EndpointName))
+
+		for _, fields := range [][]string{
+			{"spec", "selector", "matchLabels", replicaGroupLabel},
+			{"spec", "template", "metadata", "labels", replicaGroupLabel},
+		} {
+			if err := unstructured.SetNestedField(obj.Object, replicaGroupSpot, fields...); err != nil {
+				return nil, errors.Wrap(err, "failed to label spot replica group")
+			}
+		}
+
+		objects.Items = append(objects.Items, obj)
+	}
+
+	return objects, nil
+}
+
+// spotReplicaGroupStatus returns a degraded status while the spot replica group of an
+// endpoint with mixed placement is not ready, e.g. because spot nodes were reclaimed. The
+// endpoint keeps serving from its on-demand replicas meanwhile.
+func (k *kubernetesOrchestrator) spotReplicaGroupStatus(ctrlClient client.Client, namespace string,
+	endpoint *v1.Endpoint) (*v1.EndpointStatus, error) {
+	opts, err := getMixedPlacementOptions(endpoint)
+	if err != nil || opts == nil {
+		return nil, err
+	}
+
+	dep := &appsv1.Deployment{}
+
+	err = ctrlClient.Get(context.Background(), client.ObjectKey{Namespace: namespace, Name: spotDeploymentName(endpoint.Metadata.Name)}, dep)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+
+		return nil, errors.Wrapf(err, "failed to get spot deployment for endpoint %s", endpoint.Metadata.WorkspaceName())
+	}
+
+	if util.IsDeploymentUpdatedAndReady(dep) || dep.Spec.Replicas == nil {
+		return nil, nil
+	}
+
+	return &v1.EndpointStatus{
+		Phase: v1.EndpointPhaseDEGRADED,
+		ErrorMessage: fmt.Sprintf("Endpoint degraded: %d/%d spot replicas ready",
+			dep.Status.ReadyReplicas, *dep.Spec.Replicas),
+	}, nil
+}
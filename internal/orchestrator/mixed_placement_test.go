@@ -0,0 +1,289 @@
+package orchestrator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v1 "github.com/neutree-ai/neutree/api/v1"
+	"github.com/neutree-ai/neutree/internal/util"
+)
+
+func TestGetMixedPlacementOptions(t *testing.T) {
+	spot := map[string]interface{}{"node_selector": map[string]interface{}{"node-pool": "spot"}}
+
+	tests := []struct {
+		name        string
+		option      interface{}
+		expect      *mixedPlacementOptions
+		expectError string
+	}{
+		{
+			name: "not configured",
+		},
+		{
+			name: "on-demand and spot groups",
+			option: map[string]interface{}{
+				"on_demand_replicas": float64(2),
+				"on_demand":          map[string]interface{}{"node_selector": map[string]interface{}{"node-pool": "on-demand"}},
+				"spot": map[string]interface{}{
+					"node_selector": map[string]interface{}{"node-pool": "spot"},
+					"tolerations":   []interface{}{map[string]interface{}{"key": "spot", "operator": "Exists", "effect": "NoSchedule"}},
+				},
+			},
+			expect: &mixedPlacementOptions{
+				OnDemandReplicas: 2,
+				OnDemand:         replicaGroupPlacement{NodeSelector: map[string]string{"node-pool": "on-demand"}},
+				Spot: replicaGroupPlacement{
+					NodeSelector: map[string]string{"node-pool": "spot"},
+					Tolerations:  []corev1.Toleration{{Key: "spot", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule}},
+				},
+			},
+		},
+		{
+			name:   "all replicas on spot nodes",
+			option: map[string]interface{}{"spot": spot},
+			expect: &mixedPlacementOptions{Spot: replicaGroupPlacement{NodeSelector: map[string]string{"node-pool": "spot"}}},
+		},
+		{
+			name:        "not an object",
+			option:      "spot",
+			expectError: "deployment_options.mixed_placement must be an object",
+		},
+		{
+			name:        "negative on-demand replicas",
+			option:      map[string]interface{}{"on_demand_replicas": float64(-1), "spot": spot},
+			expectError: "deployment_options.mixed_placement.on_demand_replicas must be a non-negative integer",
+		},
+		{
+			name:        "spot group without placement",
+			option:      map[string]interface{}{"on_demand_replicas": float64(1)},
+			expectError: "deployment_options.mixed_placement.spot requires a node_selector or tolerations",
+		},
+		{
+			name:        "invalid node selector",
+			option:      map[string]interface{}{"spot": map[string]interface{}{"node_selector": "spot"}},
+			expectError: "deployment_options.mixed_placement.spot: must have a node_selector of strings and a list of kubernetes tolerations",
+		},
+		{
+			name: "invalid toleration",
+			option: map[string]interface{}{"spot": map[string]interface{}{
+				"tolerations": []interface{}{map[string]interface{}{"key": "spot", "operator": "Exists", "value": "true"}},
+			}},
+			expectError: "deployment_options.mixed_placement.spot: tolerations[0]: value must be empty when operator is Exists",
+		},
+		{
+			name:        "unknown key",
+			option:      map[string]interface{}{"spot": spot, "preemptible": true},
+			expectError: "unknown deployment_options.mixed_placement.preemptible",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endpoint := &v1.Endpoint{Spec: &v1.EndpointSpec{DeploymentOptions: map[string]interface{}{}}}
+			if tt.option != nil {
+				endpoint.Spec.DeploymentOptions["mixed_placement"] = tt.option
+			}
+
+			opts, err := getMixedPlacementOptions(endpoint)
+			if tt.expectError != "" {
+				assert.EqualError(t, err, tt.expectError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expect, opts)
+		})
+	}
+}
+
+func TestBuildReplicaGroupObjects(t *testing.T) {
+	opts := &mixedPlacementOptions{
+		OnDemandReplicas: 1,
+		OnDemand:         replicaGroupPlacement{NodeSelector: map[string]string{"node-pool": "on-demand"}},
+		Spot: replicaGroupPlacement{
+			NodeSelector: map[string]string{"node-pool": "spot"},
+			Tolerations:  []corev1.Toleration{{Key: "spot", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule}},
+		},
+	}
+
+	tests := []struct {
+		name           string
+		replicas       int32
+		opts           *mixedPlacementOptions
+		expectReplicas map[string]int32
+	}{
+		{
+			name:           "without mixed placement",
+			replicas:       3,
+			expectReplicas: map[string]int32{"test-endpoint": 3},
+		},
+		{
+			name:           "replicas split between groups",
+			replicas:       3,
+			opts:           opts,
+			expectReplicas: map[string]int32{"test-endpoint": 1, "test-endpoint-spot": 2},
+		},
+		{
+			name:           "no replicas left for spot nodes",
+			replicas:       1,
+			opts:           opts,
+			expectReplicas: map[string]int32{"test-endpoint": 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := newDeploymentManifestVariables()
+			data.NeutreeVersion = "v0.1.0"
+			data.Namespace = "neutree-cluster-a"
+			data.ImagePrefix = "registry.example.com"
+			data.ImageRepo = "myrepo"
+			data.ImageTag = "v1.0.0"
+			data.EndpointName = "test-endpoint"
+			data.ModelArgs = map[string]interface{}{
+				"name":       "qwen2-0.5b-instruct",
+				"task":       "text-generation",
+				"path":       "/models-cache/default/qwen2-0.5b-instruct/v1",
+				"file":       "*q8_0.gguf",
+				"serve_name": "qwen2-0.5b-instruct",
+			}
+			data.RoutingLogic = "roundrobin"
+			data.Replicas = tt.replicas
+			data.NodeSelector = map[string]string{"zone": "a"}
+
+			objs, err := buildReplicaGroupObjects(realEmbeddedTemplate(t, "llama-cpp-v0.3.7"), data, tt.opts)
+			require.NoError(t, err)
+
+			deployments := map[string]appsv1.Deployment{}
+
+			for _, obj := range objs.Items {
+				if obj.GetKind() != "Deployment" {
+					continue
+				}
+
+				var deployment appsv1.Deployment
+				require.NoError(t, runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &deployment))
+				deployments[deployment.Name] = deployment
+			}
+
+			require.Len(t, deployments, len(tt.expectReplicas))
+
+			for name, replicas := range tt.expectReplicas {
+				require.Contains(t, deployments, name)
+				require.NotNil(t, deployments[name].Spec.Replicas)
+				assert.Equal(t, replicas, *deployments[name].Spec.Replicas)
+			}
+
+			// The render variables of the endpoint are not changed by the replica groups.
+			assert.Equal(t, map[string]string{"zone": "a"}, data.NodeSelector)
+			assert.Empty(t, data.Tolerations)
+
+			if tt.opts == nil {
+				return
+			}
+
+			onDemand := deployments["test-endpoint"]
+			assert.Equal(t, map[string]string{"zone": "a", "node-pool": "on-demand"}, onDemand.Spec.Template.Spec.NodeSelector)
+			assert.Empty(t, onDemand.Spec.Template.Spec.Tolerations)
+			assert.NotContains(t, onDemand.Spec.Selector.MatchLabels, replicaGroupLabel)
+
+			spot, ok := deployments["test-endpoint-spot"]
+			if !ok {
+				return
+			}
+
+			assert.Equal(t, map[string]string{"zone": "a", "node-pool": "spot"}, spot.Spec.Template.Spec.NodeSelector)
+			assert.Equal(t, opts.Spot.Tolerations, spot.Spec.Template.Spec.Tolerations)
+			assert.Equal(t, "spot", spot.Spec.Selector.MatchLabels[replicaGroupLabel])
+			assert.Equal(t, "spot", spot.Spec.Template.Labels[replicaGroupLabel])
+		})
+	}
+}
+
+func TestKubernetesOrchestrator_pauseEndpoint_MixedPlacement(t *testing.T) {
+	const name = "chat-model"
+
+	fakeClient := NewFakeK8sClient(t)
+	ctx := makePauseTestCtx(fakeClient, name)
+	createTestDeployment(t, fakeClient, ctx, name, 1)
+	createTestDeployment(t, fakeClient, ctx, spotDeploymentName(name), 2)
+
+	require.NoError(t, (&kubernetesOrchestrator{}).pauseEndpoint(ctx))
+
+	for _, depName := range []string{name, spotDeploymentName(name)} {
+		dep := &appsv1.Deployment{}
+		require.NoError(t, fakeClient.Get(context.Background(),
+			client.ObjectKey{Namespace: util.ClusterNamespace(ctx.Cluster), Name: depName}, dep))
+		require.NotNil(t, dep.Spec.Replicas)
+		assert.Equal(t, int32(0), *dep.Spec.Replicas, depName)
+	}
+}
+
+func TestKubernetesOrchestrator_spotReplicaGroupStatus(t *testing.T) {
+	const namespace = "test-namespace"
+
+	tests := []struct {
+		name         string
+		mixed        bool
+		replicas     int32
+		ready        int32
+		expectStatus *v1.EndpointStatus
+	}{
+		{
+			name:     "without mixed placement",
+			replicas: 2,
+		},
+		{
+			name:  "spot group not deployed",
+			mixed: true,
+		},
+		{
+			name:     "spot group ready",
+			mixed:    true,
+			replicas: 2,
+			ready:    2,
+		},
+		{
+			name:     "spot nodes reclaimed",
+			mixed:    true,
+			replicas: 2,
+			ready:    1,
+			expectStatus: &v1.EndpointStatus{
+				Phase:        v1.EndpointPhaseDEGRADED,
+				ErrorMessage: "Endpoint degraded: 1/2 spot replicas ready",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeClient := NewFakeK8sClient(t)
+			if tt.replicas > 0 {
+				fakeClient = fakeClient.WithDeployment("chat-spot", tt.replicas, tt.ready, tt.replicas)
+			}
+
+			endpoint := &v1.Endpoint{
+				Metadata: &v1.Metadata{Workspace: "default", Name: "chat"},
+				Spec:     &v1.EndpointSpec{DeploymentOptions: map[string]interface{}{}},
+			}
+			if tt.mixed {
+				endpoint.Spec.DeploymentOptions["mixed_placement"] = map[string]interface{}{
+					"on_demand_replicas": float64(1),
+					"spot":               map[string]interface{}{"node_selector": map[string]interface{}{"node-pool": "spot"}},
+				}
+			}
+
+			status, err := (&kubernetesOrchestrator{}).spotReplicaGroupStatus(fakeClient, namespace, endpoint)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectStatus, status)
+		})
+	}
+}
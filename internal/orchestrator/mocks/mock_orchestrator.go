@@ -5,6 +5,8 @@ package mocks
 import (
 	mock "github.com/stretchr/testify/mock"
 
+	time "time"
+
 	v1 "github.com/neutree-ai/neutree/api/v1"
 )
 
@@ -113,6 +115,53 @@ func (_c *MockOrchestrator_DeleteEndpoint_Call) RunAndReturn(run func(*v1.Endpoi
 	return _c
 }
 
+// DrainEndpoint provides a mock function with given fields: endpoint, timeout
+func (_m *MockOrchestrator) DrainEndpoint(endpoint *v1.Endpoint, timeout time.Duration) error {
+	ret := _m.Called(endpoint, timeout)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DrainEndpoint")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*v1.Endpoint, time.Duration) error); ok {
+		r0 = rf(endpoint, timeout)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockOrchestrator_DrainEndpoint_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DrainEndpoint'
+type MockOrchestrator_DrainEndpoint_Call struct {
+	*mock.Call
+}
+
+// DrainEndpoint is a helper method to define mock.On call
+//   - endpoint *v1.Endpoint
+//   - timeout time.Duration
+func (_e *MockOrchestrator_Expecter) DrainEndpoint(endpoint interface{}, timeout interface{}) *MockOrchestrator_DrainEndpoint_Call {
+	return &MockOrchestrator_DrainEndpoint_Call{Call: _e.mock.On("DrainEndpoint", endpoint, timeout)}
+}
+
+func (_c *MockOrchestrator_DrainEndpoint_Call) Run(run func(endpoint *v1.Endpoint, timeout time.Duration)) *MockOrchestrator_DrainEndpoint_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(*v1.Endpoint), args[1].(time.Duration))
+	})
+	return _c
+}
+
+func (_c *MockOrchestrator_DrainEndpoint_Call) Return(_a0 error) *MockOrchestrator_DrainEndpoint_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockOrchestrator_DrainEndpoint_Call) RunAndReturn(run func(*v1.Endpoint, time.Duration) error) *MockOrchestrator_DrainEndpoint_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetEndpointStatus provides a mock function with given fields: endpoint
 func (_m *MockOrchestrator) GetEndpointStatus(endpoint *v1.Endpoint) (*v1.EndpointStatus, error) {
 	ret := _m.Called(endpoint)
@@ -0,0 +1,159 @@
+package orchestrator
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+
+	v1 "github.com/neutree-ai/neutree/api/v1"
+	"github.com/neutree-ai/neutree/internal/model_registry"
+	"github.com/neutree-ai/neutree/pkg/storage"
+)
+
+// ModelTaskValidation is what happens to an endpoint declaring a model task the model registry
+// metadata contradicts, see deploymentOptionModelTaskValidation.
+type ModelTaskValidation string
+
+const (
+	ModelTaskValidationWarn   ModelTaskValidation = "warn"
+	ModelTaskValidationReject ModelTaskValidation = "reject"
+	ModelTaskValidationSkip   ModelTaskValidation = "skip"
+)
+
+// ModelTaskMismatchMessage prefixes the reason an endpoint is warned about or rejected for.
+const ModelTaskMismatchMessage = "model task mismatch"
+
+// ModelTaskCache keeps the tasks the model registries tell for their models by model and
+// version, so the metadata of a model version is only fetched once. Failed lookups are not
+// cached, they are retried by the next check.
+type ModelTaskCache struct {
+	tasks sync.Map
+}
+
+func (c *ModelTaskCache) get(key string) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+
+	task, ok := c.tasks.Load(key)
+	if !ok {
+		return "", false
+	}
+
+	return task.(string), true
+}
+
+func (c *ModelTaskCache) set(key, task string) {
+	if c != nil {
+		c.tasks.Store(key, task)
+	}
+}
+
+// getModelTaskValidation parses deployment_options.model_task_validation of the endpoint, warn
+// by default.
+func getModelTaskValidation(endpoint *v1.Endpoint) (ModelTaskValidation, error) {
+	if endpoint.Spec == nil || endpoint.Spec.DeploymentOptions == nil || endpoint.Spec.DeploymentOptions[deploymentOptionModelTaskValidation] == nil {
+		return ModelTaskValidationWarn, nil
+	}
+
+	validation, _ := endpoint.Spec.DeploymentOptions[deploymentOptionModelTaskValidation].(string)
+
+	switch ModelTaskValidation(validation) {
+	case ModelTaskValidationWarn, ModelTaskValidationReject, ModelTaskValidationSkip:
+		return ModelTaskValidation(validation), nil
+	default:
+		return "", errors.Errorf("deployment_options.%s must be one of %s, %s, %s", deploymentOptionModelTaskValidation,
+			ModelTaskValidationWarn, ModelTaskValidationReject, ModelTaskValidationSkip)
+	}
+}
+
+// CheckEndpointModelTask returns the model task validation of the endpoint and, unless it is
+// skip, why the task declared for a model the endpoint serves differs from the task of the
+// model in its registry. The reason is empty if the tasks match, or if the registry has no
+// metadata telling the task of the model. The tasks told by the registries are kept in the
+// cache, which may be nil.
+func CheckEndpointModelTask(s storage.Storage, cache *ModelTaskCache, endpoint *v1.Endpoint) (ModelTaskValidation, string, error) {
+	validation, err := getModelTaskValidation(endpoint)
+	if err != nil || validation == ModelTaskValidationSkip {
+		return validation, "", err
+	}
+
+	registries := map[string]*v1.ModelRegistry{}
+
+	var mismatches []string
+
+	for _, model := range endpoint.Spec.ServedModels() {
+		if model == nil || model.Task == "" {
+			continue
+		}
+
+		registryName := model.Registry
+		if registryName == "" {
+			registryName = endpoint.Spec.Model.Registry
+		}
+
+		registry, ok := registries[registryName]
+		if !ok {
+			registry, err = getModelRegistry(s, endpoint.Metadata.Workspace, registryName)
+			if err != nil {
+				return validation, "", errors.Wrapf(err, "failed to get model registry %s", registryName)
+			}
+
+			registries[registryName] = registry
+		}
+
+		task := registeredModelTask(cache, registry, model)
+		if task != "" && task != model.Task {
+			mismatches = append(mismatches, fmt.Sprintf("model %s is declared as %s but its registry serves it as %s",
+				model.Name, model.Task, task))
+		}
+	}
+
+	if len(mismatches) == 0 {
+		return validation, "", nil
+	}
+
+	return validation, fmt.Sprintf("%s: %s", ModelTaskMismatchMessage, strings.Join(mismatches, ", ")), nil
+}
+
+// registeredModelTask returns the task of the model told by the metadata of its registry, empty
+// if the registry has no such metadata or it can not be read. The endpoint deploys anyway then,
+// the task is only checked when the metadata is at hand.
+func registeredModelTask(cache *ModelTaskCache, registry *v1.ModelRegistry, model *v1.ModelSpec) string {
+	if registry.Spec == nil {
+		return ""
+	}
+
+	name := model.Name
+	key := fmt.Sprintf("%s/%s@%s", registry.Metadata.WorkspaceName(), name, model.Version)
+
+	if task, ok := cache.get(key); ok {
+		return task
+	}
+
+	manager, err := model_registry.NewModelRegistry(registry)
+	if err != nil {
+		klog.Warningf("Skip task validation of model %s, failed to connect model registry %s: %v",
+			name, registry.Metadata.WorkspaceName(), err)
+		return ""
+	}
+
+	resolver, ok := manager.(model_registry.ModelTaskResolver)
+	if !ok {
+		return ""
+	}
+
+	task, err := resolver.GetModelTask(name)
+	if err != nil {
+		klog.Warningf("Skip task validation of model %s, failed to get its metadata from model registry %s: %v",
+			name, registry.Metadata.WorkspaceName(), err)
+		return ""
+	}
+
+	cache.set(key, task)
+
+	return task
+}
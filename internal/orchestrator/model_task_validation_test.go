@@ -0,0 +1,180 @@
+package orchestrator
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	v1 "github.com/neutree-ai/neutree/api/v1"
+	"github.com/neutree-ai/neutree/internal/model_registry"
+	modelregistrymocks "github.com/neutree-ai/neutree/internal/model_registry/mocks"
+	storagemocks "github.com/neutree-ai/neutree/pkg/storage/mocks"
+)
+
+// taskResolvingRegistry is a model registry whose metadata tells the task of every model.
+type taskResolvingRegistry struct {
+	*modelregistrymocks.MockModelRegistry
+	tasks map[string]string
+	err   error
+}
+
+func (r *taskResolvingRegistry) GetModelTask(name string) (string, error) {
+	return r.tasks[name], r.err
+}
+
+func TestCheckEndpointModelTask(t *testing.T) {
+	tests := []struct {
+		name             string
+		validation       interface{}
+		models           []*v1.ModelSpec
+		tasks            map[string]string
+		resolveError     error
+		noResolver       bool
+		expectValidation ModelTaskValidation
+		expectReason     string
+		expectError      string
+	}{
+		{
+			name:             "task matches",
+			tasks:            map[string]string{"Qwen/Qwen3-8B": v1.TextGenerationModelTask},
+			expectValidation: ModelTaskValidationWarn,
+		},
+		{
+			name:             "task mismatch warns by default",
+			tasks:            map[string]string{"Qwen/Qwen3-8B": v1.TextEmbeddingModelTask},
+			expectValidation: ModelTaskValidationWarn,
+			expectReason:     "model task mismatch: model Qwen/Qwen3-8B is declared as text-generation but its registry serves it as text-embedding",
+		},
+		{
+			name:             "task mismatch rejected",
+			validation:       "reject",
+			tasks:            map[string]string{"Qwen/Qwen3-8B": v1.TextEmbeddingModelTask},
+			expectValidation: ModelTaskValidationReject,
+			expectReason:     "model task mismatch: model Qwen/Qwen3-8B is declared as text-generation but its registry serves it as text-embedding",
+		},
+		{
+			name:   "task mismatch of a served model",
+			models: []*v1.ModelSpec{{Name: "BAAI/bge-reranker-v2-m3", Task: v1.TextEmbeddingModelTask}},
+			tasks: map[string]string{
+				"Qwen/Qwen3-8B":           v1.TextGenerationModelTask,
+				"BAAI/bge-reranker-v2-m3": v1.TextRerankModelTask,
+			},
+			expectValidation: ModelTaskValidationWarn,
+			expectReason:     "model task mismatch: model BAAI/bge-reranker-v2-m3 is declared as text-embedding but its registry serves it as text-rerank",
+		},
+		{
+			name:             "unknown task skipped",
+			validation:       "reject",
+			tasks:            map[string]string{},
+			expectValidation: ModelTaskValidationReject,
+		},
+		{
+			name:             "metadata unavailable skipped",
+			validation:       "reject",
+			resolveError:     errors.New("connection refused"),
+			expectValidation: ModelTaskValidationReject,
+		},
+		{
+			name:             "registry without metadata skipped",
+			validation:       "reject",
+			noResolver:       true,
+			expectValidation: ModelTaskValidationReject,
+		},
+		{
+			name:             "skip",
+			validation:       "skip",
+			tasks:            map[string]string{"Qwen/Qwen3-8B": v1.TextEmbeddingModelTask},
+			expectValidation: ModelTaskValidationSkip,
+		},
+		{
+			name:        "invalid validation",
+			validation:  "strict",
+			expectError: "deployment_options.model_task_validation must be one of warn, reject, skip",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			origNewModelRegistry := model_registry.NewModelRegistry
+			defer func() { model_registry.NewModelRegistry = origNewModelRegistry }()
+
+			model_registry.NewModelRegistry = func(_ *v1.ModelRegistry) (model_registry.ModelRegistry, error) {
+				if tt.noResolver {
+					return modelregistrymocks.NewMockModelRegistry(t), nil
+				}
+
+				return &taskResolvingRegistry{tasks: tt.tasks, err: tt.resolveError}, nil
+			}
+
+			mockStorage := storagemocks.NewMockStorage(t)
+			mockStorage.On("ListModelRegistry", mock.Anything).Return([]v1.ModelRegistry{{
+				Metadata: &v1.Metadata{Workspace: "default", Name: "hf"},
+				Spec:     &v1.ModelRegistrySpec{Type: v1.HuggingFaceModelRegistryType},
+			}}, nil).Maybe()
+
+			endpoint := &v1.Endpoint{
+				Metadata: &v1.Metadata{Workspace: "default", Name: "chat"},
+				Spec: &v1.EndpointSpec{
+					Model:  &v1.ModelSpec{Registry: "hf", Name: "Qwen/Qwen3-8B", Task: v1.TextGenerationModelTask},
+					Models: tt.models,
+				},
+			}
+			if tt.validation != nil {
+				endpoint.Spec.DeploymentOptions = map[string]interface{}{"model_task_validation": tt.validation}
+			}
+
+			validation, reason, err := CheckEndpointModelTask(mockStorage, nil, endpoint)
+			if tt.expectError != "" {
+				assert.EqualError(t, err, tt.expectError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectValidation, validation)
+			assert.Equal(t, tt.expectReason, reason)
+		})
+	}
+}
+
+func TestCheckEndpointModelTask_Cache(t *testing.T) {
+	origNewModelRegistry := model_registry.NewModelRegistry
+	defer func() { model_registry.NewModelRegistry = origNewModelRegistry }()
+
+	lookups := 0
+	model_registry.NewModelRegistry = func(_ *v1.ModelRegistry) (model_registry.ModelRegistry, error) {
+		lookups++
+		return &taskResolvingRegistry{tasks: map[string]string{"Qwen/Qwen3-8B": v1.TextEmbeddingModelTask}}, nil
+	}
+
+	mockStorage := storagemocks.NewMockStorage(t)
+	mockStorage.On("ListModelRegistry", mock.Anything).Return([]v1.ModelRegistry{{
+		Metadata: &v1.Metadata{Workspace: "default", Name: "hf"},
+		Spec:     &v1.ModelRegistrySpec{Type: v1.HuggingFaceModelRegistryType},
+	}}, nil)
+
+	endpoint := &v1.Endpoint{
+		Metadata: &v1.Metadata{Workspace: "default", Name: "chat"},
+		Spec: &v1.EndpointSpec{
+			Model: &v1.ModelSpec{Registry: "hf", Name: "Qwen/Qwen3-8B", Version: "main", Task: v1.TextGenerationModelTask},
+		},
+	}
+	cache := &ModelTaskCache{}
+	expectReason := "model task mismatch: model Qwen/Qwen3-8B is declared as text-generation but its registry serves it as text-embedding"
+
+	for i := 0; i < 2; i++ {
+		_, reason, err := CheckEndpointModelTask(mockStorage, cache, endpoint)
+		require.NoError(t, err)
+		assert.Equal(t, expectReason, reason)
+	}
+
+	assert.Equal(t, 1, lookups)
+
+	// Another version of the model is looked up again.
+	endpoint.Spec.Model.Version = "v2"
+	_, _, err := CheckEndpointModelTask(mockStorage, cache, endpoint)
+	require.NoError(t, err)
+	assert.Equal(t, 2, lookups)
+}
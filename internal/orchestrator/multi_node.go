@@ -0,0 +1,337 @@
+package orchestrator
+
+import (
+	"maps"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	v1 "github.com/neutree-ai/neutree/api/v1"
+)
+
+const (
+	leaderWorkerSetAPIVersion = "leaderworkerset.x-k8s.io/v1"
+	leaderWorkerSetKind       = "LeaderWorkerSet"
+
+	// multiNodeWorkerApp is the app label of the worker pods of a multi-node replica. The
+	// router sends the requests to the app=inference pods, the leader pods serving the engine.
+	multiNodeWorkerApp = "inference-worker"
+
+	// multiNodeLeaderScript starts the Ray head in the leader pod, waits for the worker pods of
+	// the group to join its Ray cluster and runs the engine command passed as its arguments.
+	// LeaderWorkerSet sets LWS_GROUP_SIZE to the number of pods of the group.
+	multiNodeLeaderScript = `ray start --head --port=6379 --disable-usage-stats && ` +
+		`until python3 -c 'import os, ray; ray.init(address="auto", logging_level="ERROR"); ` +
+		`exit(sum(n["Alive"] for n in ray.nodes()) < int(os.environ["LWS_GROUP_SIZE"]))'; do sleep 5; done && ` +
+		`exec "$0" "$@"`
+
+	// multiNodeWorkerScript joins the worker pod to the Ray cluster of its leader, retrying
+	// until the Ray head of the leader is started. LeaderWorkerSet sets LWS_LEADER_ADDRESS to
+	// the DNS name of the leader pod of the group.
+	multiNodeWorkerScript = `until ray start --address="$LWS_LEADER_ADDRESS:6379" --disable-usage-stats --block; ` +
+		`do sleep 5; done`
+)
+
+// multiNodeWorkers returns the number of worker pods each replica of the endpoint runs next to
+// its leader pod, 0 unless the replicas span several nodes. A vLLM replica spans several nodes
+// when the tensor-parallel-size of its engine_args exceeds resources.gpu, the GPUs of one pod
+// and so of one node: the replica then runs a pod with resources.gpu GPUs per node, joined in
+// the Ray cluster the engine distributes its workers over.
+func multiNodeWorkers(endpoint *v1.Endpoint) (int32, error) {
+	if endpoint.Spec == nil || endpoint.Spec.Engine == nil || endpoint.Spec.Engine.Engine != v1.EngineNameVLLM ||
+		endpoint.Spec.Resources == nil {
+		return 0, nil
+	}
+
+	engineArgs, _ := endpoint.Spec.Variables["engine_args"].(map[string]interface{}) //nolint:errcheck
+
+	tpKey := engineTPArgKey(v1.EngineNameVLLM)
+	value, ok := engineArgs[strings.ReplaceAll(tpKey, "_", "-")]
+
+	if ok {
+		tpKey = strings.ReplaceAll(tpKey, "_", "-")
+	} else if value, ok = engineArgs[tpKey]; !ok {
+		return 0, nil
+	}
+
+	tensorParallelSize, err := engineArgInt(value)
+	if err != nil {
+		return 0, errors.Wrapf(err, "engine_args.%s", tpKey)
+	}
+
+	gpus := endpoint.Spec.Resources.GetGPUCount()
+	if gpus < 1 || math.Trunc(gpus) != gpus || float64(tensorParallelSize) <= gpus {
+		return 0, nil
+	}
+
+	if tensorParallelSize%int(gpus) != 0 {
+		return 0, errors.Errorf("engine_args.%s %d spans several nodes, it must be a multiple of the %d GPUs per node of resources.gpu",
+			tpKey, tensorParallelSize, int(gpus))
+	}
+
+	return int32(tensorParallelSize/int(gpus)) - 1, nil
+}
+
+// engineArgInt parses an integer engine arg, given as a number or a string.
+func engineArgInt(value interface{}) (int, error) {
+	if s, ok := value.(string); ok {
+		n, err := strconv.Atoi(strings.TrimSpace(s))
+		if err != nil {
+			return 0, errors.Errorf("must be an integer, got %q", s)
+		}
+
+		return n, nil
+	}
+
+	n, err := toFloat64(value)
+	if err != nil || math.Trunc(n) != n {
+		return 0, errors.Errorf("must be an integer, got %v", value)
+	}
+
+	return int(n), nil
+}
+
+// setMultiNodeVariables sets the worker pods of the replicas spanning several nodes, and makes
+// the engine distribute its workers over the Ray cluster of their pods.
+func (k *kubernetesOrchestrator) setMultiNodeVariables(data *DeploymentManifestVariables, endpoint *v1.Endpoint) error {
+	workers, err := multiNodeWorkers(endpoint)
+	if err != nil || workers == 0 {
+		return err
+	}
+
+	// The pods of a multi-node replica run in a LeaderWorkerSet, the options running the
+	// replicas in another workload do not apply to it.
+	if enabled, err := getPeerDiscovery(endpoint); err != nil || enabled {
+		return multiNodeOptionError(err, deploymentOptionPeerDiscovery)
+	}
+
+	if mixedPlacement, err := getMixedPlacementOptions(endpoint); err != nil || mixedPlacement != nil {
+		return multiNodeOptionError(err, deploymentOptionMixedPlacement)
+	}
+
+	if enabled, err := getImageCanary(endpoint); err != nil || enabled {
+		return multiNodeOptionError(err, deploymentOptionImageCanary)
+	}
+
+	data.WorkerReplicas = workers
+
+	if data.EngineArgs["distributed-executor-backend"] == nil && data.EngineArgs["distributed_executor_backend"] == nil {
+		data.EngineArgs["distributed-executor-backend"] = "ray"
+	}
+
+	return nil
+}
+
+func multiNodeOptionError(err error, option string) error {
+	if err != nil {
+		return err
+	}
+
+	return errors.Errorf("deployment_options.%s can not be used with replicas spanning several nodes", option)
+}
+
+// newLeaderWorkerSet returns the LeaderWorkerSet object of the workload of a multi-node endpoint.
+func newLeaderWorkerSet(name, namespace string) *unstructured.Unstructured {
+	lws := &unstructured.Unstructured{}
+	lws.SetAPIVersion(leaderWorkerSetAPIVersion)
+	lws.SetKind(leaderWorkerSetKind)
+	lws.SetName(name)
+	lws.SetNamespace(namespace)
+
+	return lws
+}
+
+// addMultiNodeObjects runs the replicas of the rendered Deployment in a LeaderWorkerSet when they
+// span several nodes, one group of pods per replica. Templates rendering a LeaderWorkerSet of
+// their own from WorkerReplicas are left as they are.
+//
+// The leader pod is the pod of the Deployment: it starts the Ray head, waits for the worker pods
+// and runs the engine. The worker pods download the model like the leader and join its Ray
+// cluster, they do not serve requests. A group is recreated as a whole when one of its pods
+// restarts, the engine can not recover from a lost worker.
+func addMultiNodeObjects(objects *unstructured.UnstructuredList, workers int32) error {
+	if workers == 0 {
+		return nil
+	}
+
+	for _, obj := range objects.Items {
+		if obj.GetKind() == leaderWorkerSetKind {
+			return nil
+		}
+	}
+
+	for i, obj := range objects.Items {
+		if obj.GetKind() != "Deployment" {
+			continue
+		}
+
+		var dep appsv1.Deployment
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &dep); err != nil {
+			return errors.Wrap(err, "failed to parse deployment")
+		}
+
+		if len(dep.Spec.Template.Spec.Containers) == 0 {
+			return errors.Errorf("deployment %s has no engine container", dep.Name)
+		}
+
+		leaderTemplate, err := runtime.DefaultUnstructuredConverter.ToUnstructured(multiNodeLeaderTemplate(&dep.Spec.Template))
+		if err != nil {
+			return errors.Wrap(err, "failed to build leader template")
+		}
+
+		workerTemplate, err := runtime.DefaultUnstructuredConverter.ToUnstructured(multiNodeWorkerTemplate(&dep.Spec.Template))
+		if err != nil {
+			return errors.Wrap(err, "failed to build worker template")
+		}
+
+		replicas := int64(1)
+		if dep.Spec.Replicas != nil {
+			replicas = int64(*dep.Spec.Replicas)
+		}
+
+		lws := newLeaderWorkerSet(dep.Name, dep.Namespace)
+		lws.SetLabels(dep.Labels)
+		lws.SetAnnotations(dep.Annotations)
+		lws.Object["spec"] = map[string]interface{}{
+			"replicas":        replicas,
+			"rolloutStrategy": leaderWorkerSetRolloutStrategy(dep.Spec.Strategy),
+			"leaderWorkerTemplate": map[string]interface{}{
+				"size":           int64(workers) + 1,
+				"restartPolicy":  "RecreateGroupOnPodRestart",
+				"leaderTemplate": leaderTemplate,
+				"workerTemplate": workerTemplate,
+			},
+		}
+
+		objects.Items[i] = *lws
+
+		return nil
+	}
+
+	return errors.New("deploy template has no deployment to run the replicas spanning several nodes in")
+}
+
+// multiNodeLeaderTemplate returns the pod template of the leader pods, the engine command of the
+// rendered pod running once the Ray cluster of the group is complete.
+func multiNodeLeaderTemplate(template *corev1.PodTemplateSpec) *corev1.PodTemplateSpec {
+	leader := template.DeepCopy()
+
+	engine := &leader.Spec.Containers[0]
+	engine.Command = append([]string{"bash", "-c", multiNodeLeaderScript}, engine.Command...)
+
+	return leader
+}
+
+// multiNodeWorkerTemplate returns the pod template of the worker pods, which only run a Ray
+// worker node in the engine container. The auth sidecar and the probes of the engine are left
+// to the leader.
+func multiNodeWorkerTemplate(template *corev1.PodTemplateSpec) *corev1.PodTemplateSpec {
+	worker := template.DeepCopy()
+
+	worker.Labels = maps.Clone(worker.Labels)
+	if worker.Labels == nil {
+		worker.Labels = map[string]string{}
+	}
+
+	worker.Labels["app"] = multiNodeWorkerApp
+
+	worker.Spec.Containers = worker.Spec.Containers[:1]
+
+	engine := &worker.Spec.Containers[0]
+	engine.Command = []string{"bash", "-c", multiNodeWorkerScript}
+	engine.Args = nil
+	engine.Ports = nil
+	engine.StartupProbe = nil
+	engine.ReadinessProbe = nil
+	engine.LivenessProbe = nil
+	engine.Lifecycle = nil
+
+	return worker
+}
+
+// leaderWorkerSetRolloutStrategy returns the rolling update of the LeaderWorkerSet, replacing the
+// groups as the rendered Deployment replaces its pods. LeaderWorkerSets only roll out.
+func leaderWorkerSetRolloutStrategy(strategy appsv1.DeploymentStrategy) map[string]interface{} {
+	config := map[string]interface{}{"maxUnavailable": int64(1), "maxSurge": int64(0)}
+
+	if strategy.RollingUpdate != nil {
+		if strategy.RollingUpdate.MaxUnavailable != nil {
+			config["maxUnavailable"] = intOrStringValue(*strategy.RollingUpdate.MaxUnavailable)
+		}
+
+		if strategy.RollingUpdate.MaxSurge != nil {
+			config["maxSurge"] = intOrStringValue(*strategy.RollingUpdate.MaxSurge)
+		}
+	}
+
+	return map[string]interface{}{
+		"type":                       "RollingUpdate",
+		"rollingUpdateConfiguration": config,
+	}
+}
+
+func intOrStringValue(value intstr.IntOrString) interface{} {
+	if value.Type == intstr.String {
+		return value.StrVal
+	}
+
+	return int64(value.IntVal)
+}
+
+// leaderWorkerSetStatusView returns a Deployment mirroring the LeaderWorkerSet of a multi-node
+// endpoint, counting its groups as replicas, so its status is checked like the one of a
+// Deployment. Its selector matches the leader and the worker pods of the endpoint.
+func leaderWorkerSetStatusView(lws *unstructured.Unstructured) *appsv1.Deployment {
+	nestedInt32 := func(fields ...string) int32 {
+		value, _, _ := unstructured.NestedInt64(lws.Object, fields...) //nolint:errcheck
+		return int32(value)
+	}
+
+	replicas := nestedInt32("spec", "replicas")
+	ready := nestedInt32("status", "readyReplicas")
+
+	labels, _, _ := unstructured.NestedStringMap(lws.Object, //nolint:errcheck
+		"spec", "leaderWorkerTemplate", "leaderTemplate", "metadata", "labels")
+	delete(labels, "app")
+
+	available := appsv1.DeploymentCondition{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionTrue}
+	if ready < replicas {
+		available.Status = corev1.ConditionFalse
+		available.Reason = "MinimumReplicasUnavailable"
+	}
+
+	dep := &appsv1.Deployment{
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+		},
+		Status: appsv1.DeploymentStatus{
+			// LeaderWorkerSets do not report the generation they observed, the updated
+			// replicas tell whether the spec was rolled out.
+			ObservedGeneration: lws.GetGeneration(),
+			Replicas:           nestedInt32("status", "replicas"),
+			UpdatedReplicas:    nestedInt32("status", "updatedReplicas"),
+			ReadyReplicas:      ready,
+			AvailableReplicas:  ready,
+			Conditions: []appsv1.DeploymentCondition{
+				{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionTrue},
+				available,
+			},
+		},
+	}
+
+	dep.Name = lws.GetName()
+	dep.Namespace = lws.GetNamespace()
+	dep.Generation = lws.GetGeneration()
+	dep.Annotations = lws.GetAnnotations()
+
+	return dep
+}
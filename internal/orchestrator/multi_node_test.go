@@ -0,0 +1,273 @@
+package orchestrator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v1 "github.com/neutree-ai/neutree/api/v1"
+	"github.com/neutree-ai/neutree/internal/util"
+)
+
+func newMultiNodeEndpoint(engine, gpu string, engineArgs map[string]interface{}) *v1.Endpoint {
+	return &v1.Endpoint{
+		Metadata: &v1.Metadata{Workspace: "default", Name: "llama-70b"},
+		Spec: &v1.EndpointSpec{
+			Engine:    &v1.EndpointEngineSpec{Engine: engine, Version: "v0.24.0"},
+			Resources: &v1.ResourceSpec{GPU: pointer.String(gpu)},
+			Variables: map[string]interface{}{"engine_args": engineArgs},
+		},
+	}
+}
+
+func TestMultiNodeWorkers(t *testing.T) {
+	tests := []struct {
+		name          string
+		endpoint      *v1.Endpoint
+		expectWorkers int32
+		expectError   string
+	}{
+		{
+			name:     "tensor parallel within a node",
+			endpoint: newMultiNodeEndpoint(v1.EngineNameVLLM, "8", map[string]interface{}{"tensor-parallel-size": 8}),
+		},
+		{
+			name:     "tensor parallel not set",
+			endpoint: newMultiNodeEndpoint(v1.EngineNameVLLM, "8", map[string]interface{}{}),
+		},
+		{
+			name:          "tensor parallel over two nodes",
+			endpoint:      newMultiNodeEndpoint(v1.EngineNameVLLM, "8", map[string]interface{}{"tensor-parallel-size": 16}),
+			expectWorkers: 1,
+		},
+		{
+			name:          "underscore key given as a string",
+			endpoint:      newMultiNodeEndpoint(v1.EngineNameVLLM, "4", map[string]interface{}{"tensor_parallel_size": "16"}),
+			expectWorkers: 3,
+		},
+		{
+			name:     "other engine",
+			endpoint: newMultiNodeEndpoint(v1.EngineNameSGLang, "8", map[string]interface{}{"tensor-parallel-size": 16}),
+		},
+		{
+			name:     "without gpus",
+			endpoint: newMultiNodeEndpoint(v1.EngineNameVLLM, "0", map[string]interface{}{"tensor-parallel-size": 16}),
+		},
+		{
+			name:        "not a multiple of the gpus of a node",
+			endpoint:    newMultiNodeEndpoint(v1.EngineNameVLLM, "8", map[string]interface{}{"tensor-parallel-size": 12}),
+			expectError: "engine_args.tensor-parallel-size 12 spans several nodes, it must be a multiple of the 8 GPUs per node of resources.gpu",
+		},
+		{
+			name:        "not an integer",
+			endpoint:    newMultiNodeEndpoint(v1.EngineNameVLLM, "8", map[string]interface{}{"tensor-parallel-size": "many"}),
+			expectError: `engine_args.tensor-parallel-size: must be an integer, got "many"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workers, err := multiNodeWorkers(tt.endpoint)
+			if tt.expectError != "" {
+				assert.EqualError(t, err, tt.expectError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectWorkers, workers)
+		})
+	}
+}
+
+func TestSetMultiNodeVariables(t *testing.T) {
+	t.Run("replicas spanning several nodes", func(t *testing.T) {
+		data := newDeploymentManifestVariables()
+		data.EngineArgs["tensor-parallel-size"] = 16
+
+		endpoint := newMultiNodeEndpoint(v1.EngineNameVLLM, "8", map[string]interface{}{"tensor-parallel-size": 16})
+		require.NoError(t, (&kubernetesOrchestrator{}).setMultiNodeVariables(&data, endpoint))
+
+		assert.Equal(t, int32(1), data.WorkerReplicas)
+		assert.Equal(t, "ray", data.EngineArgs["distributed-executor-backend"])
+	})
+
+	t.Run("keeps the executor backend of the endpoint", func(t *testing.T) {
+		data := newDeploymentManifestVariables()
+		data.EngineArgs["distributed_executor_backend"] = "mp"
+
+		endpoint := newMultiNodeEndpoint(v1.EngineNameVLLM, "8", map[string]interface{}{"tensor-parallel-size": 16})
+		require.NoError(t, (&kubernetesOrchestrator{}).setMultiNodeVariables(&data, endpoint))
+
+		assert.NotContains(t, data.EngineArgs, "distributed-executor-backend")
+	})
+
+	t.Run("replicas within a node", func(t *testing.T) {
+		data := newDeploymentManifestVariables()
+
+		endpoint := newMultiNodeEndpoint(v1.EngineNameVLLM, "8", map[string]interface{}{"tensor-parallel-size": 8})
+		require.NoError(t, (&kubernetesOrchestrator{}).setMultiNodeVariables(&data, endpoint))
+
+		assert.Zero(t, data.WorkerReplicas)
+		assert.NotContains(t, data.EngineArgs, "distributed-executor-backend")
+	})
+
+	t.Run("with peer discovery", func(t *testing.T) {
+		data := newDeploymentManifestVariables()
+
+		endpoint := newMultiNodeEndpoint(v1.EngineNameVLLM, "8", map[string]interface{}{"tensor-parallel-size": 16})
+		endpoint.Spec.DeploymentOptions = map[string]interface{}{"peer_discovery": true}
+
+		err := (&kubernetesOrchestrator{}).setMultiNodeVariables(&data, endpoint)
+		assert.EqualError(t, err, "deployment_options.peer_discovery can not be used with replicas spanning several nodes")
+	})
+}
+
+func TestAddMultiNodeObjects(t *testing.T) {
+	data := newDeploymentManifestVariables()
+	data.NeutreeVersion = "v0.1.0"
+	data.Namespace = "neutree-cluster-a"
+	data.ImagePrefix = "registry.example.com"
+	data.ImageRepo = "neutree/vllm-cuda"
+	data.ImageTag = "v0.24.0"
+	data.EngineName = "vllm"
+	data.EndpointName = "llama-70b"
+	data.ModelArgs = map[string]interface{}{
+		"name":       "llama-3.1-70b",
+		"task":       "text-generation",
+		"path":       "/mnt/models/llama-3.1-70b",
+		"serve_name": "llama-3.1-70b",
+	}
+	data.RoutingLogic = "roundrobin"
+	data.Replicas = 2
+	data.Resources = map[string]string{"nvidia.com/gpu": "8"}
+	data.EngineArgs = map[string]interface{}{"tensor-parallel-size": 16}
+
+	endpoint := newMultiNodeEndpoint(v1.EngineNameVLLM, "8", map[string]interface{}{"tensor-parallel-size": 16})
+	require.NoError(t, (&kubernetesOrchestrator{}).setMultiNodeVariables(&data, endpoint))
+
+	objs, err := buildDeploymentObjects(realEmbeddedTemplate(t, "vllm-v0.24.0"), data)
+	require.NoError(t, err)
+	require.NoError(t, addMultiNodeObjects(objs, data.WorkerReplicas))
+
+	require.Len(t, objs.Items, 1)
+	lws := objs.Items[0]
+	assert.Equal(t, leaderWorkerSetAPIVersion, lws.GetAPIVersion())
+	assert.Equal(t, leaderWorkerSetKind, lws.GetKind())
+	assert.Equal(t, "llama-70b", lws.GetName())
+	assert.Equal(t, "neutree-cluster-a", lws.GetNamespace())
+
+	spec := lws.Object["spec"].(map[string]interface{})
+	assert.Equal(t, int64(2), spec["replicas"])
+
+	group := spec["leaderWorkerTemplate"].(map[string]interface{})
+	assert.Equal(t, int64(2), group["size"])
+	assert.Equal(t, "RecreateGroupOnPodRestart", group["restartPolicy"])
+
+	var leader, worker corev1.PodTemplateSpec
+	require.NoError(t, runtime.DefaultUnstructuredConverter.FromUnstructured(group["leaderTemplate"].(map[string]interface{}), &leader))
+	require.NoError(t, runtime.DefaultUnstructuredConverter.FromUnstructured(group["workerTemplate"].(map[string]interface{}), &worker))
+
+	// The leader serves the engine over the Ray cluster of the group.
+	assert.Equal(t, "inference", leader.Labels["app"])
+	engine := leader.Spec.Containers[0]
+	assert.Equal(t, []string{"bash", "-c", multiNodeLeaderScript, "vllm", "serve", "/mnt/models/llama-3.1-70b"}, engine.Command[:6])
+	assert.Contains(t, engine.Command, "--distributed-executor-backend")
+	assert.NotNil(t, engine.ReadinessProbe)
+	assert.Equal(t, "8", engine.Resources.Limits.Name("nvidia.com/gpu", "").String())
+
+	// The workers only join it, the router does not send them requests.
+	assert.Equal(t, multiNodeWorkerApp, worker.Labels["app"])
+	assert.Equal(t, "llama-70b", worker.Labels["endpoint"])
+	require.Len(t, worker.Spec.Containers, 1)
+	engine = worker.Spec.Containers[0]
+	assert.Equal(t, []string{"bash", "-c", multiNodeWorkerScript}, engine.Command)
+	assert.Nil(t, engine.ReadinessProbe)
+	assert.Nil(t, engine.StartupProbe)
+	assert.Empty(t, engine.Ports)
+	assert.Equal(t, "8", engine.Resources.Limits.Name("nvidia.com/gpu", "").String())
+	assert.Equal(t, leader.Spec.InitContainers, worker.Spec.InitContainers)
+}
+
+func TestAddMultiNodeObjects_KeepsLeaderWorkerSetOfTheTemplate(t *testing.T) {
+	lws := newLeaderWorkerSet("llama-70b", "neutree-cluster-a")
+	objs := &unstructured.UnstructuredList{Items: []unstructured.Unstructured{*lws}}
+
+	require.NoError(t, addMultiNodeObjects(objs, 1))
+	assert.Equal(t, []unstructured.Unstructured{*lws}, objs.Items)
+}
+
+func TestLeaderWorkerSetStatusView(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      map[string]interface{}
+		expectReady bool
+	}{
+		{
+			name:        "all groups updated and ready",
+			status:      map[string]interface{}{"replicas": int64(2), "updatedReplicas": int64(2), "readyReplicas": int64(2)},
+			expectReady: true,
+		},
+		{
+			name:   "group starting",
+			status: map[string]interface{}{"replicas": int64(2), "updatedReplicas": int64(2), "readyReplicas": int64(1)},
+		},
+		{
+			name:   "group not updated",
+			status: map[string]interface{}{"replicas": int64(2), "updatedReplicas": int64(1), "readyReplicas": int64(2)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lws := newLeaderWorkerSet("llama-70b", "neutree-cluster-a")
+			lws.SetGeneration(2)
+			lws.Object["spec"] = map[string]interface{}{
+				"replicas": int64(2),
+				"leaderWorkerTemplate": map[string]interface{}{
+					"leaderTemplate": map[string]interface{}{
+						"metadata": map[string]interface{}{
+							"labels": map[string]interface{}{"endpoint": "llama-70b", "app": "inference"},
+						},
+					},
+				},
+			}
+			lws.Object["status"] = tt.status
+
+			dep := leaderWorkerSetStatusView(lws)
+
+			assert.Equal(t, tt.expectReady, util.IsDeploymentUpdatedAndReady(dep))
+			// The selector matches the worker pods too.
+			assert.Equal(t, map[string]string{"endpoint": "llama-70b"}, dep.Spec.Selector.MatchLabels)
+		})
+	}
+}
+
+func TestKubernetesOrchestrator_pauseEndpoint_MultiNode(t *testing.T) {
+	const name = "llama-70b"
+
+	fakeClient := NewFakeK8sClient(t)
+	ctx := makePauseTestCtx(fakeClient, name)
+	ctx.Endpoint.Spec.Engine = &v1.EndpointEngineSpec{Engine: v1.EngineNameVLLM, Version: "v0.24.0"}
+	ctx.Endpoint.Spec.Resources = &v1.ResourceSpec{GPU: pointer.String("8")}
+	ctx.Endpoint.Spec.Variables = map[string]interface{}{"engine_args": map[string]interface{}{"tensor-parallel-size": 16}}
+
+	lws := newLeaderWorkerSet(name, util.ClusterNamespace(ctx.Cluster))
+	lws.Object["spec"] = map[string]interface{}{"replicas": int64(2)}
+	require.NoError(t, fakeClient.Create(context.Background(), lws))
+
+	require.NoError(t, (&kubernetesOrchestrator{}).pauseEndpoint(ctx))
+
+	paused := newLeaderWorkerSet(name, util.ClusterNamespace(ctx.Cluster))
+	require.NoError(t, fakeClient.Get(context.Background(), client.ObjectKeyFromObject(paused), paused))
+
+	replicas, _, err := unstructured.NestedInt64(paused.Object, "spec", "replicas")
+	require.NoError(t, err)
+	assert.Zero(t, replicas)
+}
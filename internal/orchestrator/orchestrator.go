@@ -2,13 +2,16 @@ package orchestrator
 
 import (
 	"fmt"
+	"time"
 
+	"github.com/pkg/errors"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	v1 "github.com/neutree-ai/neutree/api/v1"
 	"github.com/neutree-ai/neutree/internal/accelerator"
 	"github.com/neutree-ai/neutree/internal/ray/dashboard"
+	"github.com/neutree-ai/neutree/internal/registry"
 	"github.com/neutree-ai/neutree/pkg/storage"
 )
 
@@ -16,6 +19,10 @@ import (
 type Orchestrator interface {
 	CreateEndpoint(endpoint *v1.Endpoint) error
 	DeleteEndpoint(endpoint *v1.Endpoint) error
+	// DrainEndpoint stops the endpoint from accepting new requests, and deletes it once the
+	// requests in flight finished or timeout passed since its deletion. It returns
+	// ErrEndpointDraining while it waits, the caller calls it again later.
+	DrainEndpoint(endpoint *v1.Endpoint, timeout time.Duration) error
 	PauseEndpoint(endpoint *v1.Endpoint) error
 	GetEndpointStatus(endpoint *v1.Endpoint) (*v1.EndpointStatus, error)
 }
@@ -24,12 +31,18 @@ type Options struct {
 	Cluster        *v1.Cluster
 	Storage        storage.Storage
 	AcceleratorMgr accelerator.Manager
+	// ImageService resolves engine image digests of digest-pinned endpoints.
+	ImageService registry.ImageService
 }
 
 type NewOrchestratorFunc func(opts Options) (Orchestrator, error)
 
 var (
 	NewOrchestrator NewOrchestratorFunc = newOrchestrator
+
+	// ErrEndpointDraining is returned by DrainEndpoint while the endpoint waits for its
+	// requests in flight.
+	ErrEndpointDraining = errors.New("endpoint is draining its requests in flight")
 )
 
 func newOrchestrator(opts Options) (Orchestrator, error) {
@@ -0,0 +1,199 @@
+package orchestrator
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v1 "github.com/neutree-ai/neutree/api/v1"
+	"github.com/neutree-ai/neutree/internal/util"
+)
+
+const (
+	// peerServiceEnv holds the DNS name of the headless service of the replicas, resolving to
+	// all of them.
+	peerServiceEnv = "NEUTREE_PEER_SERVICE"
+	// peerSRVEnv holds the SRV record name of the engine port of the headless service, which
+	// resolves to the DNS name of every replica. A replica finds its own ordinal in the suffix
+	// of its hostname. The peers are resolved at runtime, so scaling the endpoint does not
+	// change the pod template and roll the replicas.
+	peerSRVEnv = "NEUTREE_PEER_SRV"
+
+	// peerServicePortName names the engine port of the headless service in its SRV record.
+	peerServicePortName = "engine"
+	// defaultPeerServicePort is the engine port of deploy templates not declaring it.
+	defaultPeerServicePort = 8000
+)
+
+// peerServiceName returns the name of the headless service giving the replicas of the endpoint
+// their DNS names.
+func peerServiceName(endpointName string) string {
+	return endpointName + "-peers"
+}
+
+// setPeerDiscoveryVariables injects the DNS names resolving to the replicas into their
+// environment when the endpoint asks for peer discovery. The endpoint env still overrides them.
+func (k *kubernetesOrchestrator) setPeerDiscoveryVariables(data *DeploymentManifestVariables, endpoint *v1.Endpoint) error {
+	enabled, err := getPeerDiscovery(endpoint)
+	if err != nil || !enabled {
+		return err
+	}
+
+	// The replicas of a replica group are named apart from the ones of the other group, they
+	// would not get consecutive ordinals.
+	mixedPlacement, err := getMixedPlacementOptions(endpoint)
+	if err != nil {
+		return err
+	}
+
+	if mixedPlacement != nil {
+		return errors.Errorf("deployment_options.%s can not be used with deployment_options.%s",
+			deploymentOptionPeerDiscovery, deploymentOptionMixedPlacement)
+	}
+
+	service := fmt.Sprintf("%s.%s.svc", peerServiceName(data.EndpointName), data.Namespace)
+	data.Env[peerServiceEnv] = service
+	data.Env[peerSRVEnv] = fmt.Sprintf("_%s._tcp.%s", peerServicePortName, service)
+
+	return nil
+}
+
+// endpointWorkload returns the object of the workload running the replicas of the endpoint, a
+// LeaderWorkerSet when they span several nodes, a StatefulSet with peer discovery and a
+// Deployment otherwise.
+func endpointWorkload(endpoint *v1.Endpoint, namespace string) client.Object {
+	objectMeta := metav1.ObjectMeta{Name: endpoint.Metadata.Name, Namespace: namespace}
+
+	if workers, _ := multiNodeWorkers(endpoint); workers > 0 { //nolint:errcheck
+		return newLeaderWorkerSet(endpoint.Metadata.Name, namespace)
+	}
+
+	// An invalid option fails the deploy of the endpoint, it keeps the workload deployed so far.
+	if enabled, _ := getPeerDiscovery(endpoint); enabled { //nolint:errcheck
+		return &appsv1.StatefulSet{ObjectMeta: objectMeta}
+	}
+
+	return &appsv1.Deployment{ObjectMeta: objectMeta}
+}
+
+// addPeerDiscoveryObjects runs the replicas of the rendered Deployment in a StatefulSet instead,
+// which names them by ordinal, and adds the headless service giving each of them a DNS name
+// and an SRV record on the engine port. Peers are resolvable before they are ready, so the
+// replicas can wait for each other to start.
+func addPeerDiscoveryObjects(objects *unstructured.UnstructuredList, endpoint *v1.Endpoint) error {
+	enabled, err := getPeerDiscovery(endpoint)
+	if err != nil || !enabled {
+		return err
+	}
+
+	for i, obj := range objects.Items {
+		if obj.GetKind() != "Deployment" {
+			continue
+		}
+
+		var dep appsv1.Deployment
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &dep); err != nil {
+			return errors.Wrap(err, "failed to parse deployment")
+		}
+
+		serviceName := peerServiceName(dep.Name)
+
+		statefulSet, err := util.ToUnstructured(&appsv1.StatefulSet{
+			TypeMeta: metav1.TypeMeta{APIVersion: appsv1.SchemeGroupVersion.String(), Kind: "StatefulSet"},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        dep.Name,
+				Namespace:   dep.Namespace,
+				Labels:      dep.Labels,
+				Annotations: dep.Annotations,
+			},
+			Spec: appsv1.StatefulSetSpec{
+				Replicas:            dep.Spec.Replicas,
+				Selector:            dep.Spec.Selector,
+				Template:            dep.Spec.Template,
+				ServiceName:         serviceName,
+				PodManagementPolicy: appsv1.ParallelPodManagement,
+				UpdateStrategy:      appsv1.StatefulSetUpdateStrategy{Type: appsv1.RollingUpdateStatefulSetStrategyType},
+			},
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to build statefulset")
+		}
+
+		service, err := util.ToUnstructured(&corev1.Service{
+			TypeMeta: metav1.TypeMeta{APIVersion: corev1.SchemeGroupVersion.String(), Kind: "Service"},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      serviceName,
+				Namespace: dep.Namespace,
+				Labels:    dep.Labels,
+			},
+			Spec: corev1.ServiceSpec{
+				ClusterIP:                corev1.ClusterIPNone,
+				Selector:                 dep.Spec.Selector.MatchLabels,
+				PublishNotReadyAddresses: true,
+				Ports: []corev1.ServicePort{{
+					Name:       peerServicePortName,
+					Protocol:   corev1.ProtocolTCP,
+					Port:       enginePort(&dep),
+					TargetPort: intstr.FromInt32(enginePort(&dep)),
+				}},
+			},
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to build peer service")
+		}
+
+		objects.Items[i] = *statefulSet
+		objects.Items = append(objects.Items, *service)
+
+		return nil
+	}
+
+	return errors.Errorf("deploy template of endpoint %s has no deployment to run with peer discovery",
+		endpoint.Metadata.WorkspaceName())
+}
+
+// enginePort returns the first port the engine container of the deployment declares.
+func enginePort(dep *appsv1.Deployment) int32 {
+	if containers := dep.Spec.Template.Spec.Containers; len(containers) > 0 && len(containers[0].Ports) > 0 {
+		return containers[0].Ports[0].ContainerPort
+	}
+
+	return defaultPeerServicePort
+}
+
+// statefulSetStatusView maps the rollout state of the StatefulSet of an endpoint onto the
+// Deployment fields the endpoint status is checked against. A StatefulSet has no progress
+// deadline, it is always progressing.
+func statefulSetStatusView(sts *appsv1.StatefulSet) *appsv1.Deployment {
+	available := appsv1.DeploymentCondition{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionTrue}
+	if sts.Spec.Replicas == nil || sts.Status.AvailableReplicas < *sts.Spec.Replicas {
+		available.Status = corev1.ConditionFalse
+		available.Reason = "MinimumReplicasUnavailable"
+	}
+
+	return &appsv1.Deployment{
+		ObjectMeta: sts.ObjectMeta,
+		Spec: appsv1.DeploymentSpec{
+			Replicas: sts.Spec.Replicas,
+			Selector: sts.Spec.Selector,
+		},
+		Status: appsv1.DeploymentStatus{
+			ObservedGeneration: sts.Status.ObservedGeneration,
+			Replicas:           sts.Status.Replicas,
+			UpdatedReplicas:    sts.Status.UpdatedReplicas,
+			ReadyReplicas:      sts.Status.ReadyReplicas,
+			AvailableReplicas:  sts.Status.AvailableReplicas,
+			Conditions: []appsv1.DeploymentCondition{
+				{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionTrue},
+				available,
+			},
+		},
+	}
+}
@@ -0,0 +1,214 @@
+package orchestrator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v1 "github.com/neutree-ai/neutree/api/v1"
+	"github.com/neutree-ai/neutree/internal/util"
+)
+
+func TestSetPeerDiscoveryVariables(t *testing.T) {
+	tests := []struct {
+		name        string
+		options     map[string]interface{}
+		expectEnv   map[string]string
+		expectError string
+	}{
+		{
+			name:      "not configured",
+			expectEnv: map[string]string{},
+		},
+		{
+			name:      "disabled",
+			options:   map[string]interface{}{"peer_discovery": false},
+			expectEnv: map[string]string{},
+		},
+		{
+			name:    "multi-replica disaggregated endpoint",
+			options: map[string]interface{}{"peer_discovery": true},
+			expectEnv: map[string]string{
+				"NEUTREE_PEER_SERVICE": "pd-chat-peers.neutree-cluster-a.svc",
+				"NEUTREE_PEER_SRV":     "_engine._tcp.pd-chat-peers.neutree-cluster-a.svc",
+			},
+		},
+		{
+			name:        "not a boolean",
+			options:     map[string]interface{}{"peer_discovery": "true"},
+			expectError: "deployment_options.peer_discovery must be a boolean",
+		},
+		{
+			name: "with mixed placement",
+			options: map[string]interface{}{
+				"peer_discovery":  true,
+				"mixed_placement": map[string]interface{}{"spot": map[string]interface{}{"node_selector": map[string]interface{}{"node-pool": "spot"}}},
+			},
+			expectError: "deployment_options.peer_discovery can not be used with deployment_options.mixed_placement",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endpoint := &v1.Endpoint{Spec: &v1.EndpointSpec{DeploymentOptions: tt.options}}
+
+			data := newDeploymentManifestVariables()
+			data.EndpointName = "pd-chat"
+			data.Namespace = "neutree-cluster-a"
+			data.Replicas = 3
+
+			err := (&kubernetesOrchestrator{}).setPeerDiscoveryVariables(&data, endpoint)
+			if tt.expectError != "" {
+				assert.EqualError(t, err, tt.expectError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectEnv, data.Env)
+		})
+	}
+}
+
+func TestAddPeerDiscoveryObjects(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		t.Run(map[bool]string{false: "disabled", true: "enabled"}[enabled], func(t *testing.T) {
+			endpoint := &v1.Endpoint{
+				Metadata: &v1.Metadata{Workspace: "default", Name: "pd-chat"},
+				Spec:     &v1.EndpointSpec{DeploymentOptions: map[string]interface{}{"peer_discovery": enabled}},
+			}
+
+			data := newDeploymentManifestVariables()
+			data.NeutreeVersion = "v0.1.0"
+			data.Namespace = "neutree-cluster-a"
+			data.ImagePrefix = "registry.example.com"
+			data.ImageRepo = "myrepo"
+			data.ImageTag = "v1.0.0"
+			data.EndpointName = "pd-chat"
+			data.ModelArgs = map[string]interface{}{
+				"name":       "qwen3-8b",
+				"task":       "text-generation",
+				"path":       "/mnt/models/qwen3-8b",
+				"serve_name": "qwen3-8b",
+			}
+			data.RoutingLogic = "roundrobin"
+			data.Replicas = 2
+
+			require.NoError(t, (&kubernetesOrchestrator{}).setPeerDiscoveryVariables(&data, endpoint))
+
+			objs, err := buildDeploymentObjects(realEmbeddedTemplate(t, "sglang-v0.5.10"), data)
+			require.NoError(t, err)
+			require.NoError(t, addPeerDiscoveryObjects(objs, endpoint))
+
+			kinds := map[string]string{}
+			for _, obj := range objs.Items {
+				kinds[obj.GetKind()] = obj.GetName()
+			}
+
+			if !enabled {
+				assert.Equal(t, map[string]string{"Deployment": "pd-chat"}, kinds)
+				return
+			}
+
+			assert.Equal(t, map[string]string{"StatefulSet": "pd-chat", "Service": "pd-chat-peers"}, kinds)
+
+			var statefulSet appsv1.StatefulSet
+
+			var service corev1.Service
+
+			for _, obj := range objs.Items {
+				switch obj.GetKind() {
+				case "StatefulSet":
+					require.NoError(t, runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &statefulSet))
+				case "Service":
+					require.NoError(t, runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &service))
+				}
+			}
+
+			assert.Equal(t, "neutree-cluster-a", statefulSet.Namespace)
+			assert.Equal(t, "pd-chat-peers", statefulSet.Spec.ServiceName)
+			assert.Equal(t, pointer.Int32(2), statefulSet.Spec.Replicas)
+			assert.Equal(t, appsv1.ParallelPodManagement, statefulSet.Spec.PodManagementPolicy)
+			assert.Subset(t, statefulSet.Spec.Template.Labels, statefulSet.Spec.Selector.MatchLabels)
+
+			require.Len(t, statefulSet.Spec.Template.Spec.Containers, 1)
+			assert.Contains(t, statefulSet.Spec.Template.Spec.Containers[0].Env, corev1.EnvVar{
+				Name:  "NEUTREE_PEER_SRV",
+				Value: "_engine._tcp.pd-chat-peers.neutree-cluster-a.svc",
+			})
+
+			assert.Equal(t, "neutree-cluster-a", service.Namespace)
+			assert.Equal(t, corev1.ClusterIPNone, service.Spec.ClusterIP)
+			assert.True(t, service.Spec.PublishNotReadyAddresses)
+			assert.Equal(t, statefulSet.Spec.Selector.MatchLabels, service.Spec.Selector)
+			assert.Equal(t, []corev1.ServicePort{{
+				Name:       "engine",
+				Protocol:   corev1.ProtocolTCP,
+				Port:       8000,
+				TargetPort: intstr.FromInt32(8000),
+			}}, service.Spec.Ports)
+		})
+	}
+}
+
+func TestStatefulSetStatusView(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      appsv1.StatefulSetStatus
+		expectReady bool
+	}{
+		{
+			name:        "all replicas updated and ready",
+			status:      appsv1.StatefulSetStatus{ObservedGeneration: 2, Replicas: 2, UpdatedReplicas: 2, ReadyReplicas: 2, AvailableReplicas: 2},
+			expectReady: true,
+		},
+		{
+			name:   "replica starting",
+			status: appsv1.StatefulSetStatus{ObservedGeneration: 2, Replicas: 2, UpdatedReplicas: 2, ReadyReplicas: 1, AvailableReplicas: 1},
+		},
+		{
+			name:   "rollout not observed",
+			status: appsv1.StatefulSetStatus{ObservedGeneration: 1, Replicas: 2, UpdatedReplicas: 2, ReadyReplicas: 2, AvailableReplicas: 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sts := &appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "pd-chat", Generation: 2},
+				Spec:       appsv1.StatefulSetSpec{Replicas: pointer.Int32(2)},
+				Status:     tt.status,
+			}
+
+			assert.Equal(t, tt.expectReady, util.IsDeploymentUpdatedAndReady(statefulSetStatusView(sts)))
+		})
+	}
+}
+
+func TestKubernetesOrchestrator_pauseEndpoint_PeerDiscovery(t *testing.T) {
+	const name = "pd-chat"
+
+	fakeClient := NewFakeK8sClient(t)
+	ctx := makePauseTestCtx(fakeClient, name)
+	ctx.Endpoint.Spec.DeploymentOptions = map[string]interface{}{"peer_discovery": true}
+
+	require.NoError(t, fakeClient.Create(context.Background(), &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: util.ClusterNamespace(ctx.Cluster)},
+		Spec:       appsv1.StatefulSetSpec{Replicas: pointer.Int32(2)},
+	}))
+
+	require.NoError(t, (&kubernetesOrchestrator{}).pauseEndpoint(ctx))
+
+	sts := &appsv1.StatefulSet{}
+	require.NoError(t, fakeClient.Get(context.Background(),
+		client.ObjectKey{Namespace: util.ClusterNamespace(ctx.Cluster), Name: name}, sts))
+	assert.Equal(t, pointer.Int32(0), sts.Spec.Replicas)
+}
@@ -0,0 +1,119 @@
+package orchestrator
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// deploymentStage is the step a replica of a deploying endpoint is waiting on.
+type deploymentStage string
+
+const (
+	deploymentStageScheduling    deploymentStage = "scheduling"
+	deploymentStageImagePull     deploymentStage = "image pull"
+	deploymentStageModelDownload deploymentStage = "model download"
+	deploymentStageModelLoad     deploymentStage = "model load"
+)
+
+// phaseTimeouts bounds how long a replica may stay in each deployment stage, stages without a
+// timeout may take as long as they need.
+type phaseTimeouts map[deploymentStage]time.Duration
+
+// podDeploymentStage returns the stage a not ready pod waits on and since when. A pod whose
+// engine turned unready after it was ready already loaded its model, it is not deploying.
+func podDeploymentStage(pod *corev1.Pod) (deploymentStage, time.Time, bool) {
+	if pod.DeletionTimestamp != nil {
+		return "", time.Time{}, false
+	}
+
+	scheduledAt := pod.CreationTimestamp.Time
+
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type != corev1.PodScheduled {
+			continue
+		}
+
+		if cond.Status != corev1.ConditionTrue {
+			return deploymentStageScheduling, pod.CreationTimestamp.Time, true
+		}
+
+		if !cond.LastTransitionTime.IsZero() {
+			scheduledAt = cond.LastTransitionTime.Time
+		}
+	}
+
+	// the containers of a stage start once the previous stage finished.
+	stageStart := scheduledAt
+
+	for _, cs := range pod.Status.InitContainerStatuses {
+		switch {
+		case cs.State.Terminated != nil && cs.State.Terminated.ExitCode == 0:
+			stageStart = cs.State.Terminated.FinishedAt.Time
+			continue
+		case cs.State.Waiting != nil && cs.State.Waiting.Reason == k8sContainerReasonContainerCreating:
+			return deploymentStageImagePull, stageStart, true
+		case cs.State.Running != nil && cs.Name == modelDownloaderInitContainerName:
+			return deploymentStageModelDownload, cs.State.Running.StartedAt.Time, true
+		}
+
+		return "", time.Time{}, false
+	}
+
+	for _, cs := range pod.Status.ContainerStatuses {
+		switch {
+		case cs.State.Waiting != nil && cs.State.Waiting.Reason == k8sContainerReasonContainerCreating:
+			return deploymentStageImagePull, stageStart, true
+		case cs.State.Running != nil && !cs.Ready:
+			if wasReadySince(pod, cs.State.Running.StartedAt.Time) {
+				return "", time.Time{}, false
+			}
+
+			return deploymentStageModelLoad, cs.State.Running.StartedAt.Time, true
+		}
+	}
+
+	return "", time.Time{}, false
+}
+
+// wasReadySince reports whether the pod was ready after the given time, i.e. its Ready
+// condition turned false again since then.
+func wasReadySince(pod *corev1.Pod, since time.Time) bool {
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodReady && cond.Status != corev1.ConditionTrue {
+			return cond.LastTransitionTime.Time.After(since)
+		}
+	}
+
+	return false
+}
+
+// checkPhaseTimeouts reports the first pod which stayed in a deployment stage longer than the
+// timeout of the stage. A pod moving on to the next stage is measured against that stage only.
+func checkPhaseTimeouts(pods []corev1.Pod, timeouts phaseTimeouts, now time.Time) (bool, string) {
+	if len(timeouts) == 0 {
+		return false, ""
+	}
+
+	sorted := make([]corev1.Pod, len(pods))
+	copy(sorted, pods)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+	for i := range sorted {
+		stage, since, ok := podDeploymentStage(&sorted[i])
+		if !ok || since.IsZero() {
+			continue
+		}
+
+		timeout, ok := timeouts[stage]
+		if !ok || now.Sub(since) < timeout {
+			continue
+		}
+
+		return true, fmt.Sprintf("%s timed out after %ds on pod '%s'", stage, int(timeout.Seconds()), sorted[i].Name)
+	}
+
+	return false, ""
+}
@@ -0,0 +1,293 @@
+package orchestrator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+
+	v1 "github.com/neutree-ai/neutree/api/v1"
+)
+
+var phaseTimeoutNow = time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func minutesAgo(m int) metav1.Time {
+	return metav1.NewTime(phaseTimeoutNow.Add(-time.Duration(m) * time.Minute))
+}
+
+func scheduledPod(name string, scheduledMinutesAgo int) corev1.Pod {
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, CreationTimestamp: minutesAgo(scheduledMinutesAgo + 1)},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodPending,
+			Conditions: []corev1.PodCondition{{
+				Type:               corev1.PodScheduled,
+				Status:             corev1.ConditionTrue,
+				LastTransitionTime: minutesAgo(scheduledMinutesAgo),
+			}},
+		},
+	}
+}
+
+func withInitContainer(pod corev1.Pod, state corev1.ContainerState) corev1.Pod {
+	pod.Status.InitContainerStatuses = append(pod.Status.InitContainerStatuses,
+		corev1.ContainerStatus{Name: modelDownloaderInitContainerName, State: state})
+	return pod
+}
+
+func withEngineContainer(pod corev1.Pod, state corev1.ContainerState) corev1.Pod {
+	pod.Status.ContainerStatuses = append(pod.Status.ContainerStatuses, corev1.ContainerStatus{Name: "vllm", State: state})
+	return pod
+}
+
+func downloadedMinutesAgo(m int) corev1.ContainerState {
+	return corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 0, FinishedAt: minutesAgo(m)}}
+}
+
+func TestCheckPhaseTimeouts(t *testing.T) {
+	creating := corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: k8sContainerReasonContainerCreating}}
+	timeouts := phaseTimeouts{
+		deploymentStageScheduling:    10 * time.Minute,
+		deploymentStageImagePull:     30 * time.Minute,
+		deploymentStageModelDownload: 60 * time.Minute,
+		deploymentStageModelLoad:     20 * time.Minute,
+	}
+
+	unschedulable := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod-a", CreationTimestamp: minutesAgo(15)},
+		Status: corev1.PodStatus{
+			Phase:      corev1.PodPending,
+			Conditions: []corev1.PodCondition{{Type: corev1.PodScheduled, Status: corev1.ConditionFalse, Reason: "Unschedulable"}},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		pods      []corev1.Pod
+		timeouts  phaseTimeouts
+		expectMsg string
+	}{
+		{
+			name:      "scheduling timed out",
+			pods:      []corev1.Pod{unschedulable},
+			timeouts:  timeouts,
+			expectMsg: "scheduling timed out after 600s on pod 'pod-a'",
+		},
+		{
+			name:      "downloader image pull timed out",
+			pods:      []corev1.Pod{withInitContainer(scheduledPod("pod-a", 45), creating)},
+			timeouts:  timeouts,
+			expectMsg: "image pull timed out after 1800s on pod 'pod-a'",
+		},
+		{
+			name: "model download timed out",
+			pods: []corev1.Pod{withInitContainer(scheduledPod("pod-a", 90), corev1.ContainerState{
+				Running: &corev1.ContainerStateRunning{StartedAt: minutesAgo(75)},
+			})},
+			timeouts:  timeouts,
+			expectMsg: "model download timed out after 3600s on pod 'pod-a'",
+		},
+		{
+			name: "engine image pull is measured from the end of the download",
+			pods: []corev1.Pod{withEngineContainer(
+				withInitContainer(scheduledPod("pod-a", 120), downloadedMinutesAgo(35)), creating)},
+			timeouts:  timeouts,
+			expectMsg: "image pull timed out after 1800s on pod 'pod-a'",
+		},
+		{
+			name: "model load timed out",
+			pods: []corev1.Pod{withEngineContainer(
+				withInitContainer(scheduledPod("pod-a", 120), downloadedMinutesAgo(60)), corev1.ContainerState{
+					Running: &corev1.ContainerStateRunning{StartedAt: minutesAgo(25)},
+				})},
+			timeouts:  timeouts,
+			expectMsg: "model load timed out after 1200s on pod 'pod-a'",
+		},
+		{
+			name: "pods which were ready are not loading the model",
+			pods: []corev1.Pod{func() corev1.Pod {
+				pod := withEngineContainer(scheduledPod("pod-a", 120), corev1.ContainerState{
+					Running: &corev1.ContainerStateRunning{StartedAt: minutesAgo(100)},
+				})
+				pod.Status.Conditions = append(pod.Status.Conditions, corev1.PodCondition{
+					Type:               corev1.PodReady,
+					Status:             corev1.ConditionFalse,
+					LastTransitionTime: minutesAgo(30),
+				})
+				return pod
+			}()},
+			timeouts: timeouts,
+		},
+		{
+			name: "pods which were never ready are loading the model",
+			pods: []corev1.Pod{func() corev1.Pod {
+				pod := withEngineContainer(scheduledPod("pod-a", 120), corev1.ContainerState{
+					Running: &corev1.ContainerStateRunning{StartedAt: minutesAgo(100)},
+				})
+				pod.Status.Conditions = append(pod.Status.Conditions, corev1.PodCondition{
+					Type:               corev1.PodReady,
+					Status:             corev1.ConditionFalse,
+					LastTransitionTime: minutesAgo(110),
+				})
+				return pod
+			}()},
+			timeouts:  timeouts,
+			expectMsg: "model load timed out after 1200s on pod 'pod-a'",
+		},
+		{
+			name: "progressing to the next stage clears a slow stage",
+			// scheduling and the download took longer than their timeouts, the model load did not yet.
+			pods: []corev1.Pod{withEngineContainer(
+				withInitContainer(scheduledPod("pod-a", 120), downloadedMinutesAgo(5)), corev1.ContainerState{
+					Running: &corev1.ContainerStateRunning{StartedAt: minutesAgo(4)},
+				})},
+			timeouts: timeouts,
+		},
+		{
+			name: "ready pods have no stage",
+			pods: []corev1.Pod{func() corev1.Pod {
+				pod := withEngineContainer(scheduledPod("pod-a", 120), corev1.ContainerState{
+					Running: &corev1.ContainerStateRunning{StartedAt: minutesAgo(100)},
+				})
+				pod.Status.ContainerStatuses[0].Ready = true
+				return pod
+			}()},
+			timeouts: timeouts,
+		},
+		{
+			name:     "stages without timeout are not bounded",
+			pods:     []corev1.Pod{unschedulable},
+			timeouts: phaseTimeouts{deploymentStageModelLoad: time.Minute},
+		},
+		{
+			name: "only the first stuck pod is reported",
+			pods: []corev1.Pod{
+				withInitContainer(scheduledPod("pod-b", 45), creating),
+				func() corev1.Pod { p := unschedulable; p.Name = "pod-c"; return p }(),
+				withInitContainer(scheduledPod("pod-a", 5), creating),
+			},
+			timeouts:  timeouts,
+			expectMsg: "image pull timed out after 1800s on pod 'pod-b'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			timedOut, msg := checkPhaseTimeouts(tt.pods, tt.timeouts, phaseTimeoutNow)
+			assert.Equal(t, tt.expectMsg != "", timedOut)
+			assert.Equal(t, tt.expectMsg, msg)
+		})
+	}
+}
+
+func TestGetPhaseTimeouts(t *testing.T) {
+	tests := []struct {
+		name        string
+		options     map[string]interface{}
+		expect      phaseTimeouts
+		expectError string
+	}{
+		{
+			name:   "not configured",
+			expect: phaseTimeouts{},
+		},
+		{
+			name: "configured stages",
+			options: map[string]interface{}{"phase_timeouts": map[string]interface{}{
+				"scheduling_seconds":     float64(600),
+				"model_download_seconds": 7200,
+				"model_load_seconds":     nil,
+			}},
+			expect: phaseTimeouts{
+				deploymentStageScheduling:    10 * time.Minute,
+				deploymentStageModelDownload: 2 * time.Hour,
+			},
+		},
+		{
+			name:        "not an object",
+			options:     map[string]interface{}{"phase_timeouts": 600},
+			expectError: "deployment_options.phase_timeouts must be an object",
+		},
+		{
+			name:        "unknown stage",
+			options:     map[string]interface{}{"phase_timeouts": map[string]interface{}{"warmup_seconds": 60}},
+			expectError: "unknown deployment_options.phase_timeouts.warmup_seconds",
+		},
+		{
+			name:        "non positive timeout",
+			options:     map[string]interface{}{"phase_timeouts": map[string]interface{}{"image_pull_seconds": 0}},
+			expectError: "deployment_options.phase_timeouts.image_pull_seconds must be a positive integer",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			timeouts, err := getPhaseTimeouts(&v1.Endpoint{Spec: &v1.EndpointSpec{DeploymentOptions: tt.options}})
+			if tt.expectError != "" {
+				assert.EqualError(t, err, tt.expectError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expect, timeouts)
+		})
+	}
+}
+
+func TestKubernetesOrchestrator_getEndpointStatsPhaseTimeout(t *testing.T) {
+	endpoint := &v1.Endpoint{
+		Metadata: &v1.Metadata{Workspace: "production", Name: "chat-model"},
+		Spec: &v1.EndpointSpec{
+			Replicas: v1.ReplicaSpec{Num: pointer.Int(1)},
+			DeploymentOptions: map[string]interface{}{
+				"phase_timeouts": map[string]interface{}{"model_download_seconds": 1800},
+			},
+		},
+	}
+	cluster := &v1.Cluster{Spec: &v1.ClusterSpec{}}
+
+	downloadingPod := func(startedAt time.Time) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "pod-downloading",
+				Namespace: "test-namespace",
+				Labels:    map[string]string{"app": "inference", "endpoint": "chat-model"},
+			},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodPending,
+				InitContainerStatuses: []corev1.ContainerStatus{{
+					Name:  modelDownloaderInitContainerName,
+					State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{StartedAt: metav1.NewTime(startedAt)}},
+				}},
+			},
+		}
+	}
+
+	t.Run("download exceeding its timeout fails the endpoint", func(t *testing.T) {
+		fakeClient := NewFakeK8sClient(t).WithDeployment(endpoint.Metadata.Name, 1, 0, 1)
+		require.NoError(t, fakeClient.Create(context.Background(), downloadingPod(time.Now().Add(-time.Hour))))
+
+		o := &kubernetesOrchestrator{}
+
+		status, err := o.getEndpointStats(fakeClient, "test-namespace", cluster, endpoint)
+		require.NoError(t, err)
+		assert.Equal(t, v1.EndpointPhaseFAILED, status.Phase)
+		assert.Equal(t, "Endpoint failed: model download timed out after 1800s on pod 'pod-downloading'", status.ErrorMessage)
+	})
+
+	t.Run("download within its timeout keeps downloading", func(t *testing.T) {
+		fakeClient := NewFakeK8sClient(t).WithDeployment(endpoint.Metadata.Name, 1, 0, 1)
+		require.NoError(t, fakeClient.Create(context.Background(), downloadingPod(time.Now().Add(-time.Minute))))
+
+		o := &kubernetesOrchestrator{}
+
+		status, err := o.getEndpointStats(fakeClient, "test-namespace", cluster, endpoint)
+		require.NoError(t, err)
+		assert.Equal(t, v1.EndpointPhaseMODELDOWNLOADING, status.Phase)
+	})
+}
@@ -0,0 +1,195 @@
+package orchestrator
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+
+	v1 "github.com/neutree-ai/neutree/api/v1"
+	"github.com/neutree-ai/neutree/internal/ray/dashboard"
+)
+
+const (
+	rayPlacementGroupStrategyPack         = "PACK"
+	rayPlacementGroupStrategySpread       = "SPREAD"
+	rayPlacementGroupStrategyStrictSpread = "STRICT_SPREAD"
+)
+
+var rayPlacementGroupStrategies = []string{
+	rayPlacementGroupStrategyPack,
+	rayPlacementGroupStrategySpread,
+	rayPlacementGroupStrategyStrictPack,
+	rayPlacementGroupStrategyStrictSpread,
+}
+
+// replicaBundle returns the Ray resources a single replica of the endpoint reserves.
+func replicaBundle(resource *v1.RayResourceSpec) map[string]float64 {
+	bundle := map[string]float64{}
+
+	for name, amount := range map[string]float64{
+		"CPU":    resource.NumCPUs,
+		"GPU":    resource.NumGPUs,
+		"memory": resource.Memory,
+	} {
+		if amount > 0 {
+			bundle[name] = amount
+		}
+	}
+
+	for name, amount := range resource.Resources {
+		if amount > 0 {
+			bundle[name] = amount
+		}
+	}
+
+	return bundle
+}
+
+// fitsBundle reports whether the resources fit into the capacity.
+func fitsBundle(resources, capacity map[string]float64) bool {
+	for name, amount := range resources {
+		if capacity[name] < amount {
+			return false
+		}
+	}
+
+	return true
+}
+
+func sumBundles(bundles []map[string]float64, times int) map[string]float64 {
+	sum := map[string]float64{}
+
+	for _, bundle := range bundles {
+		for name, amount := range bundle {
+			sum[name] += amount * float64(times)
+		}
+	}
+
+	return sum
+}
+
+// validatePlacementGroup checks that the named placement group exists and can hold every
+// replica, or that a placement group of the bundles can be created on the cluster nodes.
+func validatePlacementGroup(svc dashboard.DashboardService, opts *placementGroupOptions,
+	resource *v1.RayResourceSpec, replicas int) error {
+	replica := replicaBundle(resource)
+
+	if opts.Name != "" {
+		pg, err := svc.GetPlacementGroup(opts.Name)
+		if err != nil {
+			return err
+		}
+
+		if pg == nil {
+			return errors.Errorf("placement group %s not found", opts.Name)
+		}
+
+		if pg.State != dashboard.PlacementGroupStateCreated {
+			return errors.Errorf("placement group %s is %s, not %s", opts.Name, pg.State, dashboard.PlacementGroupStateCreated)
+		}
+
+		reserved := make([]map[string]float64, 0, len(pg.Bundles))
+		for _, bundle := range pg.Bundles {
+			reserved = append(reserved, bundle.UnitResources)
+		}
+
+		if !fitsBundle(sumBundles([]map[string]float64{replica}, replicas), sumBundles(reserved, 1)) {
+			return errors.Errorf("placement group %s can not hold %d replicas of the endpoint", opts.Name, replicas)
+		}
+
+		return nil
+	}
+
+	// Ray serve schedules the replica actor into the first bundle.
+	if !fitsBundle(replica, opts.Bundles[0]) {
+		return errors.New("the first placement group bundle must hold the resources of a replica")
+	}
+
+	nodes, err := svc.ListNodes()
+	if err != nil {
+		return errors.Wrap(err, "failed to list cluster nodes")
+	}
+
+	capacities := make([]map[string]float64, 0, len(nodes))
+
+	for _, node := range nodes {
+		if node.Raylet.State == "ALIVE" {
+			capacities = append(capacities, node.Raylet.Resources)
+		}
+	}
+
+	if !placementGroupFits(opts.Bundles, opts.Strategy, capacities) {
+		return errors.Errorf("placement group bundles with strategy %s do not fit the cluster nodes", opts.Strategy)
+	}
+
+	return nil
+}
+
+// placementGroupFits reports whether a placement group of the bundles can be created on nodes
+// with the capacities, ignoring the resources other workloads already use.
+func placementGroupFits(bundles []map[string]float64, strategy string, capacities []map[string]float64) bool {
+	switch strategy {
+	case rayPlacementGroupStrategyStrictPack:
+		total := sumBundles(bundles, 1)
+
+		for _, capacity := range capacities {
+			if fitsBundle(total, capacity) {
+				return true
+			}
+		}
+
+		return false
+	case rayPlacementGroupStrategyStrictSpread:
+		// place the largest bundles first, each on its own node.
+		sorted := make([]map[string]float64, len(bundles))
+		copy(sorted, bundles)
+		sort.SliceStable(sorted, func(i, j int) bool { return sorted[i]["GPU"] > sorted[j]["GPU"] })
+
+		used := make([]bool, len(capacities))
+
+		for _, bundle := range sorted {
+			placed := false
+
+			for i, capacity := range capacities {
+				if !used[i] && fitsBundle(bundle, capacity) {
+					used[i], placed = true, true
+					break
+				}
+			}
+
+			if !placed {
+				return false
+			}
+		}
+
+		return true
+	default:
+		// PACK and SPREAD are best effort, bundles may share or spread over any nodes.
+		remaining := make([]map[string]float64, 0, len(capacities))
+		for _, capacity := range capacities {
+			remaining = append(remaining, sumBundles([]map[string]float64{capacity}, 1))
+		}
+
+		for _, bundle := range bundles {
+			placed := false
+
+			for _, capacity := range remaining {
+				if fitsBundle(bundle, capacity) {
+					for name, amount := range bundle {
+						capacity[name] -= amount
+					}
+
+					placed = true
+
+					break
+				}
+			}
+
+			if !placed {
+				return false
+			}
+		}
+
+		return true
+	}
+}
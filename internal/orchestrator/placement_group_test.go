@@ -0,0 +1,282 @@
+package orchestrator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/utils/pointer"
+
+	v1 "github.com/neutree-ai/neutree/api/v1"
+	acceleratormocks "github.com/neutree-ai/neutree/internal/accelerator/mocks"
+	"github.com/neutree-ai/neutree/internal/accelerator/plugin"
+	"github.com/neutree-ai/neutree/internal/ray/dashboard"
+	dashboardmocks "github.com/neutree-ai/neutree/internal/ray/dashboard/mocks"
+)
+
+func TestGetPlacementGroupOptions(t *testing.T) {
+	tests := []struct {
+		name        string
+		option      interface{}
+		expect      *placementGroupOptions
+		expectError string
+	}{
+		{
+			name: "not configured",
+		},
+		{
+			name:   "named placement group",
+			option: map[string]interface{}{"name": "tp-gang"},
+			expect: &placementGroupOptions{Name: "tp-gang"},
+		},
+		{
+			name: "bundles default to the pack strategy",
+			option: map[string]interface{}{"bundles": []interface{}{
+				map[string]interface{}{"CPU": float64(1), "GPU": 4},
+				map[string]interface{}{"GPU": float64(4)},
+			}},
+			expect: &placementGroupOptions{
+				Bundles:  []map[string]float64{{"CPU": 1, "GPU": 4}, {"GPU": 4}},
+				Strategy: "PACK",
+			},
+		},
+		{
+			name: "bundles with strategy",
+			option: map[string]interface{}{
+				"bundles":  []interface{}{map[string]interface{}{"CPU": 1, "GPU": 8}},
+				"strategy": "STRICT_PACK",
+			},
+			expect: &placementGroupOptions{Bundles: []map[string]float64{{"CPU": 1, "GPU": 8}}, Strategy: "STRICT_PACK"},
+		},
+		{
+			name:        "not an object",
+			option:      "tp-gang",
+			expectError: "deployment_options.placement_group must be an object",
+		},
+		{
+			name:        "name and bundles",
+			option:      map[string]interface{}{"name": "tp-gang", "bundles": []interface{}{map[string]interface{}{"GPU": 1}}},
+			expectError: "deployment_options.placement_group.name and bundles are mutually exclusive",
+		},
+		{
+			name:        "neither name nor bundles",
+			option:      map[string]interface{}{"strategy": "PACK"},
+			expectError: "deployment_options.placement_group requires a name or bundles",
+		},
+		{
+			name:        "strategy of a named placement group",
+			option:      map[string]interface{}{"name": "tp-gang", "strategy": "PACK"},
+			expectError: "deployment_options.placement_group.strategy only applies to bundles",
+		},
+		{
+			name:        "unknown strategy",
+			option:      map[string]interface{}{"bundles": []interface{}{map[string]interface{}{"GPU": 1}}, "strategy": "FILL"},
+			expectError: "deployment_options.placement_group.strategy must be one of PACK, SPREAD, STRICT_PACK, STRICT_SPREAD",
+		},
+		{
+			name:        "empty bundle",
+			option:      map[string]interface{}{"bundles": []interface{}{map[string]interface{}{}}},
+			expectError: "deployment_options.placement_group.bundles[0]: must be a non-empty object of resource amounts",
+		},
+		{
+			name:        "negative resource",
+			option:      map[string]interface{}{"bundles": []interface{}{map[string]interface{}{"GPU": -1}}},
+			expectError: "deployment_options.placement_group.bundles[0]: GPU must be a non-negative number",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endpoint := &v1.Endpoint{Spec: &v1.EndpointSpec{DeploymentOptions: map[string]interface{}{}}}
+			if tt.option != nil {
+				endpoint.Spec.DeploymentOptions["placement_group"] = tt.option
+			}
+
+			opts, err := getPlacementGroupOptions(endpoint)
+			if tt.expectError != "" {
+				assert.EqualError(t, err, tt.expectError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expect, opts)
+		})
+	}
+}
+
+func TestEndpointToApplication_PlacementGroup(t *testing.T) {
+	nvidiaGPU := string(v1.AcceleratorTypeNVIDIAGPU)
+
+	tests := []struct {
+		name          string
+		option        map[string]interface{}
+		expectBackend map[string]interface{}
+	}{
+		{
+			name:          "named placement group",
+			option:        map[string]interface{}{"name": "tp-gang"},
+			expectBackend: map[string]interface{}{"placement_group_name": "tp-gang"},
+		},
+		{
+			name: "new placement group per replica",
+			option: map[string]interface{}{
+				"bundles":  []interface{}{map[string]interface{}{"CPU": 8, "GPU": 4}},
+				"strategy": "STRICT_PACK",
+			},
+			expectBackend: map[string]interface{}{
+				"placement_group_bundles":  []map[string]float64{{"CPU": 8, "GPU": 4}},
+				"placement_group_strategy": "STRICT_PACK",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endpoint := &v1.Endpoint{
+				Metadata: &v1.Metadata{Workspace: "default", Name: "tp-ep"},
+				Spec: &v1.EndpointSpec{
+					Engine: &v1.EndpointEngineSpec{Engine: "vllm", Version: "v0.12.0"},
+					Model:  &v1.ModelSpec{Name: "test-model", Task: v1.TextGenerationModelTask},
+					Resources: &v1.ResourceSpec{
+						CPU:         pointer.String("8"),
+						GPU:         pointer.String("4"),
+						Accelerator: map[string]string{v1.AcceleratorTypeKey: nvidiaGPU},
+					},
+					Replicas: v1.ReplicaSpec{Num: intPtr(1)},
+					DeploymentOptions: map[string]interface{}{
+						"placement_group": tt.option,
+						// the explicit placement group takes precedence.
+						"topology_aware_placement": true,
+					},
+				},
+			}
+			cluster := &v1.Cluster{
+				Spec:   &v1.ClusterSpec{Version: "v1.0.0"},
+				Status: &v1.ClusterStatus{AcceleratorType: &nvidiaGPU},
+			}
+
+			mgr := acceleratormocks.NewMockManager(t)
+			mgr.EXPECT().GetConverter(nvidiaGPU).Return(plugin.NewGPUConverter(), true)
+
+			app, err := EndpointToApplication(endpoint, cluster, &v1.ModelRegistry{Spec: &v1.ModelRegistrySpec{Type: v1.BentoMLModelRegistryType}},
+				nil, nil, mgr)
+			require.NoError(t, err)
+
+			backend := app.Args["deployment_options"].(map[string]interface{})["backend"].(map[string]interface{})
+			for _, key := range []string{"placement_group_name", "placement_group_bundles", "placement_group_strategy"} {
+				if expect, ok := tt.expectBackend[key]; ok {
+					assert.Equal(t, expect, backend[key])
+				} else {
+					assert.NotContains(t, backend, key)
+				}
+			}
+		})
+	}
+}
+
+func TestValidatePlacementGroup(t *testing.T) {
+	replica := &v1.RayResourceSpec{NumCPUs: 1, NumGPUs: 4}
+	gpuNode := func(gpus float64) v1.NodeSummary {
+		return v1.NodeSummary{Raylet: v1.Raylet{State: "ALIVE", Resources: map[string]float64{"CPU": 32, "GPU": gpus}}}
+	}
+	gang := func(state string, bundles ...map[string]float64) *dashboard.PlacementGroup {
+		pg := &dashboard.PlacementGroup{Name: "tp-gang", State: state}
+		for _, b := range bundles {
+			pg.Bundles = append(pg.Bundles, dashboard.PlacementGroupBundle{UnitResources: b})
+		}
+
+		return pg
+	}
+
+	tests := []struct {
+		name           string
+		opts           *placementGroupOptions
+		replicas       int
+		placementGroup *dashboard.PlacementGroup
+		nodes          []v1.NodeSummary
+		expectError    string
+	}{
+		{
+			name:           "named placement group holding every replica",
+			opts:           &placementGroupOptions{Name: "tp-gang"},
+			replicas:       2,
+			placementGroup: gang("CREATED", map[string]float64{"CPU": 1, "GPU": 4}, map[string]float64{"CPU": 1, "GPU": 4}),
+		},
+		{
+			name:        "named placement group not found",
+			opts:        &placementGroupOptions{Name: "tp-gang"},
+			replicas:    1,
+			expectError: "placement group tp-gang not found",
+		},
+		{
+			name:           "named placement group still pending",
+			opts:           &placementGroupOptions{Name: "tp-gang"},
+			replicas:       1,
+			placementGroup: gang("PENDING", map[string]float64{"CPU": 1, "GPU": 4}),
+			expectError:    "placement group tp-gang is PENDING, not CREATED",
+		},
+		{
+			name:           "named placement group too small for the replicas",
+			opts:           &placementGroupOptions{Name: "tp-gang"},
+			replicas:       2,
+			placementGroup: gang("CREATED", map[string]float64{"CPU": 2, "GPU": 4}),
+			expectError:    "placement group tp-gang can not hold 2 replicas of the endpoint",
+		},
+		{
+			name:     "strict pack bundles fitting one node",
+			opts:     &placementGroupOptions{Bundles: []map[string]float64{{"CPU": 1, "GPU": 4}, {"GPU": 4}}, Strategy: "STRICT_PACK"},
+			replicas: 1,
+			nodes:    []v1.NodeSummary{gpuNode(4), gpuNode(8)},
+		},
+		{
+			name:        "strict pack bundles exceeding every node",
+			opts:        &placementGroupOptions{Bundles: []map[string]float64{{"CPU": 1, "GPU": 4}, {"GPU": 4}}, Strategy: "STRICT_PACK"},
+			replicas:    1,
+			nodes:       []v1.NodeSummary{gpuNode(4), gpuNode(4)},
+			expectError: "placement group bundles with strategy STRICT_PACK do not fit the cluster nodes",
+		},
+		{
+			name:     "pack bundles spreading over nodes",
+			opts:     &placementGroupOptions{Bundles: []map[string]float64{{"CPU": 1, "GPU": 4}, {"GPU": 4}}, Strategy: "PACK"},
+			replicas: 1,
+			nodes:    []v1.NodeSummary{gpuNode(4), gpuNode(4)},
+		},
+		{
+			name: "strict spread bundles on too few nodes",
+			opts: &placementGroupOptions{
+				Bundles:  []map[string]float64{{"CPU": 1, "GPU": 4}, {"GPU": 2}, {"GPU": 2}},
+				Strategy: "STRICT_SPREAD",
+			},
+			replicas:    1,
+			nodes:       []v1.NodeSummary{gpuNode(8), gpuNode(8)},
+			expectError: "placement group bundles with strategy STRICT_SPREAD do not fit the cluster nodes",
+		},
+		{
+			name:        "first bundle smaller than a replica",
+			opts:        &placementGroupOptions{Bundles: []map[string]float64{{"CPU": 1, "GPU": 2}, {"GPU": 2}}, Strategy: "PACK"},
+			replicas:    1,
+			expectError: "the first placement group bundle must hold the resources of a replica",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := dashboardmocks.NewMockDashboardService(t)
+			if tt.opts.Name != "" {
+				svc.EXPECT().GetPlacementGroup(tt.opts.Name).Return(tt.placementGroup, nil)
+			}
+
+			if tt.nodes != nil {
+				svc.EXPECT().ListNodes().Return(tt.nodes, nil)
+			}
+
+			err := validatePlacementGroup(svc, tt.opts, replica, tt.replicas)
+			if tt.expectError != "" {
+				assert.EqualError(t, err, tt.expectError)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,242 @@
+package orchestrator
+
+import (
+	"maps"
+	"time"
+
+	"github.com/pkg/errors"
+
+	v1 "github.com/neutree-ai/neutree/api/v1"
+	"github.com/neutree-ai/neutree/internal/ray/dashboard"
+	"github.com/neutree-ai/neutree/internal/util"
+)
+
+const (
+	// canaryArg holds the traffic split of the stable application while a canary runs, the
+	// Controller sends canaryArg.weight percent of the requests to the Backend of the canary.
+	canaryArg = "canary"
+	// canaryStartedAtArg records when the canary application was deployed. It is not read by
+	// the serve applications.
+	canaryStartedAtArg = "canary_started_at"
+)
+
+// EndpointToCanaryServeApplicationName returns the serve application name of the canary of an
+// endpoint update. Canaries are only rolled out for single-model endpoints, so the name can not
+// collide with the per-model applications of a multi-model endpoint.
+func EndpointToCanaryServeApplicationName(endpoint *v1.Endpoint) string {
+	return EndpointToServeApplicationName(endpoint) + "_canary"
+}
+
+// endpointCanaryRoutePrefix is the route prefix of the canary application, which also allows to
+// call the canary directly, e.g. to check it before it takes a share of the requests.
+func endpointCanaryRoutePrefix(endpoint *v1.Endpoint) string {
+	return "/" + endpoint.Metadata.Workspace + "/" + endpoint.Metadata.Name + "/_canary"
+}
+
+// validateEndpointCanary validates spec.canary of the endpoint, and the BlueGreen upgrade
+// strategy rolled out as a canary.
+func validateEndpointCanary(endpoint *v1.Endpoint) error {
+	if endpoint.Spec == nil {
+		return nil
+	}
+
+	strategy := endpoint.Spec.GetUpgradeStrategyType()
+
+	if endpoint.Spec.Canary != nil && strategy != v1.EndpointUpgradeStrategyTypeRolling {
+		return errors.Errorf("upgrade strategy %s can not be combined with canary", strategy)
+	}
+
+	if strategy == v1.EndpointUpgradeStrategyTypeBlueGreen && endpoint.Spec.IsMultiModel() {
+		return errors.New("upgrade strategy BlueGreen is not supported by multi-model endpoints")
+	}
+
+	if endpoint.Spec.Canary == nil {
+		return nil
+	}
+
+	if endpoint.Spec.IsMultiModel() {
+		return errors.New("canary is not supported by multi-model endpoints")
+	}
+
+	if endpoint.Spec.Canary.Weight < 1 || endpoint.Spec.Canary.Weight > 100 {
+		return errors.New("canary weight must be between 1 and 100")
+	}
+
+	if endpoint.Spec.Canary.DurationSeconds < 0 {
+		return errors.New("canary duration_seconds must not be negative")
+	}
+
+	return nil
+}
+
+// canaryApplications returns the serve applications rolling the desired application of the
+// endpoint out as a canary. The deployed application keeps serving as the stable application
+// and the desired one is deployed next to it as the canary. Once the canary is running, the
+// stable application sends spec.weight percent of the requests to it. After
+// spec.duration_seconds the canary is promoted without dropping requests:
+//
+//  1. the stable application sends all the requests to the canary,
+//  2. once it does, the desired application replaces the stable one, still sending all the
+//     requests to the canary,
+//  3. once the replaced stable application is running, it serves the requests itself,
+//  4. once it does, the canary is deleted.
+//
+// Endpoints not deployed yet, or whose deployed application is already the desired one and
+// serves the requests itself, get the desired application alone.
+func canaryApplications(endpoint *v1.Endpoint, spec *v1.CanarySpec, desired dashboard.RayServeApplication,
+	apps map[string]dashboard.RayServeApplicationStatus, now time.Time) ([]dashboard.RayServeApplication, error) {
+	stableStatus, ok := apps[desired.Name]
+	if !ok || stableStatus.DeployedAppConfig == nil {
+		return []dashboard.RayServeApplication{desired}, nil
+	}
+
+	stable := withoutArgs(*stableStatus.DeployedAppConfig, canaryArg)
+
+	equal, _, err := util.JsonEqual(stable, desired)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to compare stable serve application")
+	}
+
+	canaryName := EndpointToCanaryServeApplicationName(endpoint)
+
+	if equal {
+		// The canary is only deleted once the stable application serves the requests itself.
+		canaryStatus, ok := apps[canaryName]
+		if !ok || canaryStatus.DeployedAppConfig == nil {
+			return []dashboard.RayServeApplication{desired}, nil
+		}
+
+		if stableStatus.Status != dashboard.ApplicationStatusRunning {
+			return []dashboard.RayServeApplication{*stableStatus.DeployedAppConfig, *canaryStatus.DeployedAppConfig}, nil
+		}
+
+		if _, ok := stableStatus.DeployedAppConfig.Args[canaryArg]; ok {
+			return []dashboard.RayServeApplication{desired, *canaryStatus.DeployedAppConfig}, nil
+		}
+
+		return []dashboard.RayServeApplication{desired}, nil
+	}
+
+	canary := desired
+	canary.Name = canaryName
+	canary.RoutePrefix = endpointCanaryRoutePrefix(endpoint)
+	canary.Args = maps.Clone(desired.Args)
+
+	startedAt := now
+	canaryRunning := false
+
+	if canaryStatus, ok := apps[canary.Name]; ok && canaryStatus.DeployedAppConfig != nil {
+		deployed := withoutArgs(*canaryStatus.DeployedAppConfig, canaryStartedAtArg)
+
+		equal, _, err = util.JsonEqual(deployed, canary)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to compare canary serve application")
+		}
+
+		// A canary of another version of the endpoint is replaced and starts over.
+		if equal {
+			if deployedAt, ok := canaryStartedAt(*canaryStatus.DeployedAppConfig); ok {
+				startedAt = deployedAt
+			}
+
+			canaryRunning = canaryStatus.Status == dashboard.ApplicationStatusRunning
+		}
+	}
+
+	canary.Args[canaryStartedAtArg] = startedAt.UTC().Format(time.RFC3339)
+
+	// The stable application only sends requests to a canary able to serve them.
+	if !canaryRunning {
+		return []dashboard.RayServeApplication{stable, canary}, nil
+	}
+
+	duration := time.Duration(spec.DurationSeconds) * time.Second
+	if now.Sub(startedAt) < duration {
+		return []dashboard.RayServeApplication{withCanaryRoute(stable, canary.Name, spec.Weight), canary}, nil
+	}
+
+	// The stable application is only replaced once all the requests go to the canary.
+	if stableStatus.Status == dashboard.ApplicationStatusRunning &&
+		canaryRouteWeight(*stableStatus.DeployedAppConfig, canary.Name) == 100 {
+		return []dashboard.RayServeApplication{withCanaryRoute(desired, canary.Name, 100), canary}, nil
+	}
+
+	return []dashboard.RayServeApplication{withCanaryRoute(stable, canary.Name, 100), canary}, nil
+}
+
+// withCanaryRoute returns the stable application sending weight percent of the requests to
+// the canary application.
+func withCanaryRoute(stable dashboard.RayServeApplication, canaryName string, weight int) dashboard.RayServeApplication {
+	stable.Args = maps.Clone(stable.Args)
+	stable.Args[canaryArg] = map[string]interface{}{
+		"application": canaryName,
+		"weight":      weight,
+	}
+
+	return stable
+}
+
+// canaryRouteWeight returns the percentage of the requests the deployed stable application
+// sends to the canary application.
+func canaryRouteWeight(stable dashboard.RayServeApplication, canaryName string) float64 {
+	route, ok := stable.Args[canaryArg].(map[string]interface{})
+	if !ok || route["application"] != canaryName {
+		return 0
+	}
+
+	weight, err := toFloat64(route["weight"])
+	if err != nil {
+		return 0
+	}
+
+	return weight
+}
+
+// withoutArgs returns the application without the given args.
+func withoutArgs(app dashboard.RayServeApplication, keys ...string) dashboard.RayServeApplication {
+	app.Args = maps.Clone(app.Args)
+	for _, key := range keys {
+		delete(app.Args, key)
+	}
+
+	return app
+}
+
+func canaryStartedAt(app dashboard.RayServeApplication) (time.Time, bool) {
+	value, ok := app.Args[canaryStartedAtArg].(string)
+	if !ok {
+		return time.Time{}, false
+	}
+
+	startedAt, err := time.Parse(time.RFC3339, value)
+
+	return startedAt, err == nil
+}
+
+// endpointCanaryStatus returns the status of the canary application of the endpoint, nil if no
+// canary is deployed.
+func endpointCanaryStatus(endpoint *v1.Endpoint, apps map[string]dashboard.RayServeApplicationStatus) *v1.EndpointCanaryStatus {
+	appStatus, ok := apps[EndpointToCanaryServeApplicationName(endpoint)]
+	if !ok {
+		return nil
+	}
+
+	status := &v1.EndpointCanaryStatus{ErrorMessage: appStatus.Message}
+
+	switch appStatus.Status {
+	case dashboard.ApplicationStatusRunning:
+		status.Phase = v1.EndpointPhaseRUNNING
+	case dashboard.ApplicationStatusDeployFailed, dashboard.ApplicationStatusUnhealthy:
+		status.Phase = v1.EndpointPhaseFAILED
+	default:
+		status.Phase = v1.EndpointPhaseDEPLOYING
+	}
+
+	if appStatus.DeployedAppConfig != nil {
+		if startedAt, ok := canaryStartedAt(*appStatus.DeployedAppConfig); ok {
+			status.StartedAt = startedAt.Format(time.RFC3339)
+		}
+	}
+
+	return status
+}
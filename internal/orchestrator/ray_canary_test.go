@@ -0,0 +1,411 @@
+package orchestrator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.openly.dev/pointy"
+
+	v1 "github.com/neutree-ai/neutree/api/v1"
+	acceleratormocks "github.com/neutree-ai/neutree/internal/accelerator/mocks"
+	"github.com/neutree-ai/neutree/internal/accelerator/plugin"
+	"github.com/neutree-ai/neutree/internal/accelerator/resourceparser"
+	"github.com/neutree-ai/neutree/internal/ray/dashboard"
+	dashboardmocks "github.com/neutree-ai/neutree/internal/ray/dashboard/mocks"
+	"github.com/neutree-ai/neutree/internal/util"
+	storagemocks "github.com/neutree-ai/neutree/pkg/storage/mocks"
+)
+
+func newCanaryTestEndpoint() *v1.Endpoint {
+	return &v1.Endpoint{
+		Metadata: &v1.Metadata{Workspace: "production", Name: "chat-model"},
+		Spec: &v1.EndpointSpec{
+			Cluster: "test-cluster",
+			Engine:  &v1.EndpointEngineSpec{Engine: "vllm", Version: "0.5.0"},
+			Model:   &v1.ModelSpec{Registry: "test-registry", Name: "test-model"},
+			Resources: &v1.ResourceSpec{
+				CPU:         pointy.String("1.0"),
+				GPU:         pointy.String("1.0"),
+				Accelerator: make(map[string]string),
+			},
+			Replicas:          v1.ReplicaSpec{Num: pointy.Int(1)},
+			DeploymentOptions: map[string]interface{}{},
+			Variables:         map[string]interface{}{},
+			Canary:            &v1.CanarySpec{Weight: 20, DurationSeconds: 600},
+		},
+	}
+}
+
+func canaryTestApplication(name, routePrefix, version string) dashboard.RayServeApplication {
+	return dashboard.RayServeApplication{
+		Name:        name,
+		RoutePrefix: routePrefix,
+		ImportPath:  "serve.vllm.v0_5_0.app:app_builder",
+		Args:        map[string]interface{}{"model": map[string]interface{}{"version": version}},
+	}
+}
+
+func TestCanaryApplications(t *testing.T) {
+	endpoint := newCanaryTestEndpoint()
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+
+	deployed := canaryTestApplication("production_chat-model", "/production/chat-model", "v1")
+	desired := canaryTestApplication("production_chat-model", "/production/chat-model", "v2")
+	canary := canaryTestApplication("production_chat-model_canary", "/production/chat-model/_canary", "v2")
+
+	withArgs := func(app dashboard.RayServeApplication, args map[string]interface{}) dashboard.RayServeApplication {
+		app.Args = map[string]interface{}{"model": app.Args["model"]}
+		for key, value := range args {
+			app.Args[key] = value
+		}
+
+		return app
+	}
+
+	startedAt := now.Add(-5 * time.Minute)
+	startedCanary := withArgs(canary, map[string]interface{}{canaryStartedAtArg: startedAt.Format(time.RFC3339)})
+	routedStable := withArgs(deployed, map[string]interface{}{
+		canaryArg: map[string]interface{}{"application": "production_chat-model_canary", "weight": 20},
+	})
+
+	expiredCanary := withArgs(canary, map[string]interface{}{
+		canaryStartedAtArg: now.Add(-10 * time.Minute).Format(time.RFC3339),
+	})
+	switchedStable := withArgs(deployed, map[string]interface{}{
+		canaryArg: map[string]interface{}{"application": "production_chat-model_canary", "weight": 100},
+	})
+	// The deployed config read back from the dashboard holds JSON numbers.
+	deployedSwitchedStable := withArgs(deployed, map[string]interface{}{
+		canaryArg: map[string]interface{}{"application": "production_chat-model_canary", "weight": float64(100)},
+	})
+	replacedStable := withArgs(desired, map[string]interface{}{
+		canaryArg: map[string]interface{}{"application": "production_chat-model_canary", "weight": 100},
+	})
+
+	tests := []struct {
+		name   string
+		apps   map[string]dashboard.RayServeApplicationStatus
+		expect []dashboard.RayServeApplication
+	}{
+		{
+			name:   "endpoint not deployed yet",
+			apps:   map[string]dashboard.RayServeApplicationStatus{},
+			expect: []dashboard.RayServeApplication{desired},
+		},
+		{
+			name: "deployed application is up to date",
+			apps: map[string]dashboard.RayServeApplicationStatus{
+				"production_chat-model": {Status: "RUNNING", DeployedAppConfig: &desired},
+			},
+			expect: []dashboard.RayServeApplication{desired},
+		},
+		{
+			name: "update deploys the canary next to the stable application",
+			apps: map[string]dashboard.RayServeApplicationStatus{
+				"production_chat-model": {Status: "RUNNING", DeployedAppConfig: &deployed},
+			},
+			expect: []dashboard.RayServeApplication{
+				deployed,
+				withArgs(canary, map[string]interface{}{canaryStartedAtArg: now.Format(time.RFC3339)}),
+			},
+		},
+		{
+			name: "deploying canary takes no requests",
+			apps: map[string]dashboard.RayServeApplicationStatus{
+				"production_chat-model":        {Status: "RUNNING", DeployedAppConfig: &deployed},
+				"production_chat-model_canary": {Status: "DEPLOYING", DeployedAppConfig: &startedCanary},
+			},
+			expect: []dashboard.RayServeApplication{deployed, startedCanary},
+		},
+		{
+			name: "running canary takes its share of the requests",
+			apps: map[string]dashboard.RayServeApplicationStatus{
+				"production_chat-model":        {Status: "RUNNING", DeployedAppConfig: &routedStable},
+				"production_chat-model_canary": {Status: "RUNNING", DeployedAppConfig: &startedCanary},
+			},
+			expect: []dashboard.RayServeApplication{routedStable, startedCanary},
+		},
+		{
+			name: "canary of another version starts over",
+			apps: map[string]dashboard.RayServeApplicationStatus{
+				"production_chat-model": {Status: "RUNNING", DeployedAppConfig: &routedStable},
+				"production_chat-model_canary": {Status: "RUNNING", DeployedAppConfig: func() *dashboard.RayServeApplication {
+					app := withArgs(canaryTestApplication("production_chat-model_canary", "/production/chat-model/_canary", "v3"),
+						map[string]interface{}{canaryStartedAtArg: startedAt.Format(time.RFC3339)})
+					return &app
+				}()},
+			},
+			expect: []dashboard.RayServeApplication{
+				deployed,
+				withArgs(canary, map[string]interface{}{canaryStartedAtArg: now.Format(time.RFC3339)}),
+			},
+		},
+		{
+			name: "stable application sends all the requests to the canary after the duration",
+			apps: map[string]dashboard.RayServeApplicationStatus{
+				"production_chat-model":        {Status: "RUNNING", DeployedAppConfig: &routedStable},
+				"production_chat-model_canary": {Status: "RUNNING", DeployedAppConfig: &expiredCanary},
+			},
+			expect: []dashboard.RayServeApplication{switchedStable, expiredCanary},
+		},
+		{
+			name: "stable application is not replaced while switching to the canary",
+			apps: map[string]dashboard.RayServeApplicationStatus{
+				"production_chat-model":        {Status: "DEPLOYING", DeployedAppConfig: &deployedSwitchedStable},
+				"production_chat-model_canary": {Status: "RUNNING", DeployedAppConfig: &expiredCanary},
+			},
+			expect: []dashboard.RayServeApplication{switchedStable, expiredCanary},
+		},
+		{
+			name: "stable application is replaced once it sends all the requests to the canary",
+			apps: map[string]dashboard.RayServeApplicationStatus{
+				"production_chat-model":        {Status: "RUNNING", DeployedAppConfig: &deployedSwitchedStable},
+				"production_chat-model_canary": {Status: "RUNNING", DeployedAppConfig: &expiredCanary},
+			},
+			expect: []dashboard.RayServeApplication{replacedStable, expiredCanary},
+		},
+		{
+			name: "canary keeps the requests while the replaced stable application deploys",
+			apps: map[string]dashboard.RayServeApplicationStatus{
+				"production_chat-model":        {Status: "DEPLOYING", DeployedAppConfig: &replacedStable},
+				"production_chat-model_canary": {Status: "RUNNING", DeployedAppConfig: &expiredCanary},
+			},
+			expect: []dashboard.RayServeApplication{replacedStable, expiredCanary},
+		},
+		{
+			name: "running replaced stable application serves the requests itself",
+			apps: map[string]dashboard.RayServeApplicationStatus{
+				"production_chat-model":        {Status: "RUNNING", DeployedAppConfig: &replacedStable},
+				"production_chat-model_canary": {Status: "RUNNING", DeployedAppConfig: &expiredCanary},
+			},
+			expect: []dashboard.RayServeApplication{desired, expiredCanary},
+		},
+		{
+			name: "canary is deleted once the stable application serves the requests",
+			apps: map[string]dashboard.RayServeApplicationStatus{
+				"production_chat-model":        {Status: "RUNNING", DeployedAppConfig: &desired},
+				"production_chat-model_canary": {Status: "RUNNING", DeployedAppConfig: &expiredCanary},
+			},
+			expect: []dashboard.RayServeApplication{desired},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apps, err := canaryApplications(endpoint, endpoint.Spec.Canary, desired, tt.apps, now)
+			require.NoError(t, err)
+
+			equal, diff, err := util.JsonEqual(tt.expect, apps)
+			require.NoError(t, err)
+			assert.True(t, equal, diff)
+		})
+	}
+}
+
+func TestCanaryApplications_BlueGreen(t *testing.T) {
+	endpoint := newCanaryTestEndpoint()
+	endpoint.Spec.Canary = nil
+	endpoint.Spec.UpgradeStrategy = &v1.EndpointUpgradeStrategy{Type: v1.EndpointUpgradeStrategyTypeBlueGreen}
+
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+
+	blue := canaryTestApplication("production_chat-model", "/production/chat-model", "v1")
+	desired := canaryTestApplication("production_chat-model", "/production/chat-model", "v2")
+	green := canaryTestApplication("production_chat-model_canary", "/production/chat-model/_canary", "v2")
+	green.Args[canaryStartedAtArg] = now.Add(-time.Minute).Format(time.RFC3339)
+
+	apps, err := canaryApplications(endpoint, endpointRolloutCanary(endpoint), desired,
+		map[string]dashboard.RayServeApplicationStatus{
+			"production_chat-model":        {Status: "RUNNING", DeployedAppConfig: &blue},
+			"production_chat-model_canary": {Status: "RUNNING", DeployedAppConfig: &green},
+		}, now)
+	require.NoError(t, err)
+
+	// Blue keeps running the old version and sends all the requests to the running green.
+	require.Len(t, apps, 2)
+	assert.Equal(t, "v1", apps[0].Args["model"].(map[string]interface{})["version"])
+	assert.Equal(t, 100.0, canaryRouteWeight(apps[0], "production_chat-model_canary"))
+	assert.Equal(t, green, apps[1])
+}
+
+func TestRayOrchestrator_createOrUpdateEndpoint_Canary(t *testing.T) {
+	endpoint := newCanaryTestEndpoint()
+
+	mockDashboard := dashboardmocks.NewMockDashboardService(t)
+	mockStorage := storagemocks.NewMockStorage(t)
+
+	mockAcceleratorMgr := acceleratormocks.NewMockManager(t)
+	mockAcceleratorMgr.EXPECT().GetEngineContainerRunOptions(mock.Anything).Return([]string{"--runtime=nvidia", "--gpus all"}, nil).Maybe()
+	mockAcceleratorMgr.EXPECT().GetAllConverters().Return(map[string]plugin.ResourceConverter{}).Maybe()
+	mockAcceleratorMgr.EXPECT().GetAllParsers().Return(map[string]resourceparser.ResourceParser{}).Maybe()
+
+	o, ctx := newTestRayOrchestratorCtx(mockStorage, mockDashboard, endpoint, mockAcceleratorMgr)
+
+	deployed := canaryTestApplication("production_chat-model", "/production/chat-model", "v1")
+
+	mockDashboard.On("GetServeApplications").Return(&dashboard.RayServeApplicationsResponse{
+		Applications: map[string]dashboard.RayServeApplicationStatus{
+			"production_chat-model": {Status: "RUNNING", DeployedAppConfig: &deployed},
+			"other_endpoint": {
+				Status:            "RUNNING",
+				DeployedAppConfig: &dashboard.RayServeApplication{Name: "other_endpoint"},
+			},
+		},
+	}, nil)
+
+	mockDashboard.On("UpdateServeApplications", mock.MatchedBy(func(req dashboard.RayServeApplicationsRequest) bool {
+		apps := map[string]dashboard.RayServeApplication{}
+		for _, app := range req.Applications {
+			apps[app.Name] = app
+		}
+
+		stable, canary := apps["production_chat-model"], apps["production_chat-model_canary"]
+
+		// The stable application keeps serving the deployed version until the canary runs.
+		return len(apps) == 3 &&
+			stable.Args["model"].(map[string]interface{})["version"] == "v1" &&
+			stable.Args[canaryArg] == nil &&
+			canary.RoutePrefix == "/production/chat-model/_canary" &&
+			canary.Args[canaryStartedAtArg] != nil
+	})).Return(nil)
+
+	assert.NoError(t, o.createOrUpdate(ctx))
+	mockDashboard.AssertExpectations(t)
+}
+
+func TestRayOrchestrator_deleteEndpoint_Canary(t *testing.T) {
+	endpoint := newCanaryTestEndpoint()
+
+	mockDashboard := dashboardmocks.NewMockDashboardService(t)
+	mockStorage := storagemocks.NewMockStorage(t)
+
+	mockDashboard.On("GetServeApplications").Return(&dashboard.RayServeApplicationsResponse{
+		Applications: map[string]dashboard.RayServeApplicationStatus{
+			"production_chat-model": {
+				Status:            "RUNNING",
+				DeployedAppConfig: &dashboard.RayServeApplication{Name: "production_chat-model"},
+			},
+			"production_chat-model_canary": {
+				Status:            "RUNNING",
+				DeployedAppConfig: &dashboard.RayServeApplication{Name: "production_chat-model_canary"},
+			},
+			"production_chat-model-2": {
+				Status:            "RUNNING",
+				DeployedAppConfig: &dashboard.RayServeApplication{Name: "production_chat-model-2"},
+			},
+		},
+	}, nil)
+
+	mockDashboard.On("UpdateServeApplications", mock.MatchedBy(func(req dashboard.RayServeApplicationsRequest) bool {
+		return len(req.Applications) == 1 && req.Applications[0].Name == "production_chat-model-2"
+	})).Return(nil)
+
+	o, ctx := newTestRayOrchestratorCtx(mockStorage, mockDashboard, endpoint, acceleratormocks.NewMockManager(t))
+
+	assert.NoError(t, o.deleteEndpoint(ctx))
+	mockDashboard.AssertExpectations(t)
+}
+
+func TestValidateEndpointCanary(t *testing.T) {
+	tests := []struct {
+		name        string
+		canary      *v1.CanarySpec
+		strategy    v1.EndpointUpgradeStrategyType
+		models      []*v1.ModelSpec
+		expectError string
+	}{
+		{name: "not configured"},
+		{name: "valid", canary: &v1.CanarySpec{Weight: 10, DurationSeconds: 300}},
+		{name: "all requests", canary: &v1.CanarySpec{Weight: 100}},
+		{name: "no weight", canary: &v1.CanarySpec{}, expectError: "canary weight must be between 1 and 100"},
+		{name: "weight above 100", canary: &v1.CanarySpec{Weight: 101}, expectError: "canary weight must be between 1 and 100"},
+		{
+			name:        "negative duration",
+			canary:      &v1.CanarySpec{Weight: 10, DurationSeconds: -1},
+			expectError: "canary duration_seconds must not be negative",
+		},
+		{
+			name:        "multi-model endpoint",
+			canary:      &v1.CanarySpec{Weight: 10},
+			models:      []*v1.ModelSpec{{Name: "second-model"}},
+			expectError: "canary is not supported by multi-model endpoints",
+		},
+		{
+			name:     "rolling upgrade strategy",
+			canary:   &v1.CanarySpec{Weight: 10},
+			strategy: v1.EndpointUpgradeStrategyTypeRolling,
+		},
+		{
+			name:     "blue-green upgrade strategy",
+			strategy: v1.EndpointUpgradeStrategyTypeBlueGreen,
+		},
+		{
+			name:        "canary with blue-green upgrade strategy",
+			canary:      &v1.CanarySpec{Weight: 10},
+			strategy:    v1.EndpointUpgradeStrategyTypeBlueGreen,
+			expectError: "upgrade strategy BlueGreen can not be combined with canary",
+		},
+		{
+			name:        "canary with recreate upgrade strategy",
+			canary:      &v1.CanarySpec{Weight: 10},
+			strategy:    v1.EndpointUpgradeStrategyTypeRecreate,
+			expectError: "upgrade strategy Recreate can not be combined with canary",
+		},
+		{
+			name:        "blue-green upgrade strategy of multi-model endpoint",
+			strategy:    v1.EndpointUpgradeStrategyTypeBlueGreen,
+			models:      []*v1.ModelSpec{{Name: "second-model"}},
+			expectError: "upgrade strategy BlueGreen is not supported by multi-model endpoints",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endpoint := newCanaryTestEndpoint()
+			endpoint.Spec.Canary = tt.canary
+			endpoint.Spec.Models = tt.models
+
+			if tt.strategy != "" {
+				endpoint.Spec.UpgradeStrategy = &v1.EndpointUpgradeStrategy{Type: tt.strategy}
+			}
+
+			err := validateEndpointCanary(endpoint)
+			if tt.expectError != "" {
+				assert.EqualError(t, err, tt.expectError)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestEndpointCanaryStatus(t *testing.T) {
+	endpoint := newCanaryTestEndpoint()
+
+	assert.Nil(t, endpointCanaryStatus(endpoint, map[string]dashboard.RayServeApplicationStatus{
+		"production_chat-model": {Status: "RUNNING"},
+	}))
+
+	canary := canaryTestApplication("production_chat-model_canary", "/production/chat-model/_canary", "v2")
+	canary.Args[canaryStartedAtArg] = "2026-10-15T12:00:00Z"
+
+	for status, phase := range map[string]v1.EndpointPhase{
+		"RUNNING":       v1.EndpointPhaseRUNNING,
+		"DEPLOYING":     v1.EndpointPhaseDEPLOYING,
+		"NOT_STARTED":   v1.EndpointPhaseDEPLOYING,
+		"DEPLOY_FAILED": v1.EndpointPhaseFAILED,
+		"UNHEALTHY":     v1.EndpointPhaseFAILED,
+	} {
+		assert.Equal(t, &v1.EndpointCanaryStatus{
+			Phase:        phase,
+			ErrorMessage: "replica failed",
+			StartedAt:    "2026-10-15T12:00:00Z",
+		}, endpointCanaryStatus(endpoint, map[string]dashboard.RayServeApplicationStatus{
+			"production_chat-model_canary": {Status: status, Message: "replica failed", DeployedAppConfig: &canary},
+		}), status)
+	}
+}
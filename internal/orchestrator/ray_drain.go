@@ -0,0 +1,158 @@
+package orchestrator
+
+import (
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	v1 "github.com/neutree-ai/neutree/api/v1"
+	"github.com/neutree-ai/neutree/internal/ray/dashboard"
+)
+
+// drainingRoutePrefix prefixes the route of the serve applications of a draining endpoint, so
+// the Ray Serve proxies no longer route the requests of the endpoint to them.
+const drainingRoutePrefix = "/_draining"
+
+// DrainEndpoint gracefully removes an endpoint from Ray Serve. The serve applications of the
+// endpoint are first moved off the endpoint route, which Ray Serve applies without restarting
+// the replicas: new requests are rejected by the proxies while the requests in flight finish.
+// Once the replicas of the endpoint process no request, or timeout passed since the deletion
+// of the endpoint, the endpoint is deleted as by DeleteEndpoint. Until then it returns
+// ErrEndpointDraining instead of holding the caller.
+//
+// The requests in flight are read from the Ray Serve metrics of the nodes, which lag the
+// replicas by the Ray metrics report interval. Without the metrics the drain waits out the
+// timeout.
+func (o *RayOrchestrator) DrainEndpoint(endpoint *v1.Endpoint, timeout time.Duration) error {
+	ctx, err := o.prepareOrchestratorContextForPauseDelete(endpoint)
+	if err != nil {
+		return errors.Wrapf(err, "failed to prepare context for endpoint %s", endpoint.Metadata.WorkspaceName())
+	}
+
+	found, err := o.stopEndpointRoutes(ctx)
+	if err != nil {
+		return err
+	}
+
+	if found && !endpointRequestsDone(ctx, timeout) {
+		return ErrEndpointDraining
+	}
+
+	return o.DeleteEndpoint(endpoint)
+}
+
+// stopEndpointRoutes moves the serve applications of the endpoint under drainingRoutePrefix.
+// It returns false when the endpoint has no serve application.
+func (o *RayOrchestrator) stopEndpointRoutes(ctx *OrchestratorContext) (bool, error) {
+	mu := getClusterLock(ctx.Cluster.Metadata.WorkspaceName())
+	mu.Lock()
+	defer mu.Unlock()
+
+	currentAppsResp, err := ctx.rayService.GetServeApplications()
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to get current serve applications before draining endpoint %s",
+			ctx.Endpoint.Metadata.WorkspaceName())
+	}
+
+	appsList := make([]dashboard.RayServeApplication, 0, len(currentAppsResp.Applications))
+	found := false
+	changed := false
+
+	for name, appStatus := range currentAppsResp.Applications {
+		// When the application is deleted, the deployed application configuration is empty, ignored it.
+		if appStatus.DeployedAppConfig == nil {
+			continue
+		}
+
+		app := *appStatus.DeployedAppConfig
+
+		if isEndpointServeApplication(ctx.Endpoint, name) {
+			found = true
+
+			// Already moved by an earlier attempt to drain the endpoint.
+			if !strings.HasPrefix(app.RoutePrefix, drainingRoutePrefix+"/") {
+				app.RoutePrefix = drainingRoutePrefix + app.RoutePrefix
+				changed = true
+			}
+		}
+
+		appsList = append(appsList, app)
+	}
+
+	if !changed {
+		return found, nil
+	}
+
+	ctx.logger.V(4).Info("Stopping routing new requests to endpoint")
+
+	err = ctx.rayService.UpdateServeApplications(dashboard.RayServeApplicationsRequest{Applications: appsList})
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to update serve applications for draining endpoint %s",
+			ctx.Endpoint.Metadata.WorkspaceName())
+	}
+
+	return true, nil
+}
+
+// endpointRequestsDone reports whether the replicas of the endpoint process no request, or
+// timeout passed since the deletion of the endpoint.
+func endpointRequestsDone(ctx *OrchestratorContext, timeout time.Duration) bool {
+	timedOut := drainTimedOut(ctx.Endpoint, timeout)
+
+	appsOngoing, err := ctx.rayService.GetServeOngoingRequests()
+	if err != nil {
+		if timedOut {
+			ctx.logger.Info("Timed out draining endpoint without the requests in flight, deleting it",
+				"timeout", timeout, "error", err.Error())
+
+			return true
+		}
+
+		ctx.logger.Info("Failed to get requests in flight, draining endpoint until the timeout",
+			"timeout", timeout, "error", err.Error())
+
+		return false
+	}
+
+	ongoing := endpointOngoingRequests(ctx.Endpoint, appsOngoing)
+	if ongoing == 0 {
+		return true
+	}
+
+	if timedOut {
+		ctx.logger.Info("Timed out draining endpoint, deleting it with requests in flight",
+			"ongoingRequests", ongoing, "timeout", timeout)
+
+		return true
+	}
+
+	ctx.logger.V(4).Info("Waiting for requests in flight to finish", "ongoingRequests", ongoing)
+
+	return false
+}
+
+// drainTimedOut reports whether timeout passed since the deletion of the endpoint. An endpoint
+// whose deletion time can not be read is not drained.
+func drainTimedOut(endpoint *v1.Endpoint, timeout time.Duration) bool {
+	deletedAt, err := time.Parse(time.RFC3339, endpoint.GetDeletionTimestamp())
+	if err != nil {
+		return true
+	}
+
+	return !time.Now().Before(deletedAt.Add(timeout))
+}
+
+// endpointOngoingRequests returns the requests the replicas of the endpoint are processing,
+// given the requests in flight by serve application.
+func endpointOngoingRequests(endpoint *v1.Endpoint, appsOngoing map[string]int) int {
+	ongoing := 0
+
+	for name, n := range appsOngoing {
+		if isEndpointServeApplication(endpoint, name) {
+			ongoing += n
+		}
+	}
+
+	return ongoing
+}
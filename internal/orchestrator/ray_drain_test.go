@@ -0,0 +1,180 @@
+package orchestrator
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	v1 "github.com/neutree-ai/neutree/api/v1"
+	"github.com/neutree-ai/neutree/internal/ray/dashboard"
+	dashboardmocks "github.com/neutree-ai/neutree/internal/ray/dashboard/mocks"
+	storagemocks "github.com/neutree-ai/neutree/pkg/storage/mocks"
+)
+
+func TestRayOrchestrator_DrainEndpoint(t *testing.T) {
+	cluster := v1.Cluster{
+		Metadata: &v1.Metadata{Name: "test-cluster", Workspace: "production"},
+		Spec:     &v1.ClusterSpec{Type: v1.SSHClusterType, Version: "v1.1.0"},
+		Status: &v1.ClusterStatus{
+			Phase:        v1.ClusterPhaseRunning,
+			DashboardURL: "http://127.0.0.1:8265",
+		},
+	}
+	endpoint := &v1.Endpoint{
+		Metadata: &v1.Metadata{Workspace: "production", Name: "chat-model"},
+		Spec: &v1.EndpointSpec{
+			Cluster: "test-cluster",
+			Model:   &v1.ModelSpec{Registry: "test-registry", Name: "test-model"},
+		},
+	}
+
+	appName := EndpointToServeApplicationName(endpoint)
+	otherApp := dashboard.RayServeApplication{Name: "production_other", RoutePrefix: "/production/other"}
+
+	// apps returns the serve applications with the endpoint application routed at routePrefix.
+	apps := func(routePrefix string) *dashboard.RayServeApplicationsResponse {
+		return &dashboard.RayServeApplicationsResponse{
+			Applications: map[string]dashboard.RayServeApplicationStatus{
+				appName: {
+					Status:            dashboard.ApplicationStatusRunning,
+					DeployedAppConfig: &dashboard.RayServeApplication{Name: appName, RoutePrefix: routePrefix},
+				},
+				otherApp.Name: {
+					Status:            dashboard.ApplicationStatusRunning,
+					DeployedAppConfig: &otherApp,
+				},
+			},
+		}
+	}
+
+	// ongoing returns the requests in flight with the endpoint application processing n.
+	ongoing := func(n int) map[string]int {
+		return map[string]int{appName: n, otherApp.Name: 5}
+	}
+
+	routes := func(req dashboard.RayServeApplicationsRequest) map[string]string {
+		result := map[string]string{}
+		for _, app := range req.Applications {
+			result[app.Name] = app.RoutePrefix
+		}
+
+		return result
+	}
+
+	drained := map[string]string{appName: "/_draining/production/chat-model", otherApp.Name: "/production/other"}
+	deleted := map[string]string{otherApp.Name: "/production/other"}
+
+	stopRoutes := func(mockDashboard *dashboardmocks.MockDashboardService) {
+		mockDashboard.On("GetServeApplications").Return(apps("/production/chat-model"), nil).Once()
+		mockDashboard.On("UpdateServeApplications", mock.MatchedBy(func(req dashboard.RayServeApplicationsRequest) bool {
+			return assert.ObjectsAreEqual(drained, routes(req))
+		})).Return(nil).Once()
+	}
+	deleteApps := func(mockDashboard *dashboardmocks.MockDashboardService) {
+		mockDashboard.On("GetServeApplications").Return(apps("/_draining/production/chat-model"), nil).Once()
+		mockDashboard.On("UpdateServeApplications", mock.MatchedBy(func(req dashboard.RayServeApplicationsRequest) bool {
+			return assert.ObjectsAreEqual(deleted, routes(req))
+		})).Return(nil).Once()
+	}
+
+	tests := []struct {
+		name string
+		// deletedAgo is how long ago the endpoint was deleted.
+		deletedAgo time.Duration
+		setupMock  func(*dashboardmocks.MockDashboardService)
+		expectErr  error
+	}{
+		{
+			name: "waits for requests in flight",
+			setupMock: func(mockDashboard *dashboardmocks.MockDashboardService) {
+				stopRoutes(mockDashboard)
+				mockDashboard.On("GetServeOngoingRequests").Return(ongoing(3), nil).Once()
+			},
+			expectErr: ErrEndpointDraining,
+		},
+		{
+			name: "deletes once requests in flight finished",
+			setupMock: func(mockDashboard *dashboardmocks.MockDashboardService) {
+				mockDashboard.On("GetServeApplications").Return(apps("/_draining/production/chat-model"), nil).Once()
+				mockDashboard.On("GetServeOngoingRequests").Return(ongoing(0), nil).Once()
+				deleteApps(mockDashboard)
+			},
+		},
+		{
+			name:       "deletes with requests in flight after timeout",
+			deletedAgo: 2 * time.Minute,
+			setupMock: func(mockDashboard *dashboardmocks.MockDashboardService) {
+				stopRoutes(mockDashboard)
+				mockDashboard.On("GetServeOngoingRequests").Return(ongoing(1), nil).Once()
+				deleteApps(mockDashboard)
+			},
+		},
+		{
+			name: "waits out the timeout without metrics",
+			setupMock: func(mockDashboard *dashboardmocks.MockDashboardService) {
+				mockDashboard.On("GetServeApplications").Return(apps("/_draining/production/chat-model"), nil).Once()
+				mockDashboard.On("GetServeOngoingRequests").Return(nil, errors.New("metrics unavailable")).Once()
+			},
+			expectErr: ErrEndpointDraining,
+		},
+		{
+			name:       "deletes after timeout without metrics",
+			deletedAgo: 2 * time.Minute,
+			setupMock: func(mockDashboard *dashboardmocks.MockDashboardService) {
+				mockDashboard.On("GetServeApplications").Return(apps("/_draining/production/chat-model"), nil).Once()
+				mockDashboard.On("GetServeOngoingRequests").Return(nil, errors.New("metrics unavailable")).Once()
+				deleteApps(mockDashboard)
+			},
+		},
+		{
+			name: "endpoint not deployed",
+			setupMock: func(mockDashboard *dashboardmocks.MockDashboardService) {
+				mockDashboard.On("GetServeApplications").Return(&dashboard.RayServeApplicationsResponse{
+					Applications: map[string]dashboard.RayServeApplicationStatus{},
+				}, nil).Twice()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prevFactory := dashboard.NewDashboardService
+			mockDashboard := dashboardmocks.NewMockDashboardService(t)
+			dashboard.NewDashboardService = func(string) dashboard.DashboardService { return mockDashboard }
+
+			t.Cleanup(func() {
+				dashboard.NewDashboardService = prevFactory
+			})
+
+			mockStorage := storagemocks.NewMockStorage(t)
+			mockStorage.On("ListCluster", mock.Anything).Return([]v1.Cluster{cluster}, nil)
+			tt.setupMock(mockDashboard)
+
+			o := &RayOrchestrator{cluster: &cluster, storage: mockStorage}
+
+			deleting := *endpoint
+			deleting.Metadata = &v1.Metadata{
+				Workspace:         endpoint.Metadata.Workspace,
+				Name:              endpoint.Metadata.Name,
+				DeletionTimestamp: time.Now().Add(-tt.deletedAgo).Format(time.RFC3339Nano),
+			}
+
+			assert.Equal(t, tt.expectErr, o.DrainEndpoint(&deleting, time.Minute))
+		})
+	}
+}
+
+func TestEndpointOngoingRequests(t *testing.T) {
+	endpoint := &v1.Endpoint{Metadata: &v1.Metadata{Workspace: "production", Name: "chat-model"}}
+
+	appsOngoing := map[string]int{
+		"production_chat-model":        5,
+		"production_chat-model_canary": 1,
+		"production_other":             7,
+	}
+
+	assert.Equal(t, 6, endpointOngoingRequests(endpoint, appsOngoing))
+}
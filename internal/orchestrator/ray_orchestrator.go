@@ -7,16 +7,21 @@ import (
 	"math"
 	"net/url"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 	"k8s.io/klog/v2"
 
 	v1 "github.com/neutree-ai/neutree/api/v1"
 	"github.com/neutree-ai/neutree/internal/accelerator"
+	"github.com/neutree-ai/neutree/internal/engine"
 	"github.com/neutree-ai/neutree/internal/model_registry"
 	"github.com/neutree-ai/neutree/internal/ray/dashboard"
+	"github.com/neutree-ai/neutree/internal/ray/rayserve"
+	"github.com/neutree-ai/neutree/internal/registry"
 	resourceview "github.com/neutree-ai/neutree/internal/resource"
 	"github.com/neutree-ai/neutree/internal/semver"
 	"github.com/neutree-ai/neutree/internal/util"
@@ -39,11 +44,6 @@ func isNewClusterVersion(cluster *v1.Cluster) (bool, error) {
 	return isNew, nil
 }
 
-// clusterLocks provides per-cluster mutexes to serialize Ray Serve application
-// updates (read-modify-write on PUT /api/serve/applications/) and prevent
-// concurrent workers from overwriting each other's changes.
-var clusterLocks sync.Map
-
 const (
 	modelDownloadLogTailLines = 200
 
@@ -52,6 +52,11 @@ const (
 	modelDownloadFailedMarker = "NEUTREE_MODEL_DOWNLOAD_FAILED"
 
 	legacyModelDownloadDoneMarker = "Model download completed."
+
+	// endpointNameEnv and endpointWorkspaceEnv label the per-model request metrics of the
+	// serve applications with their endpoint.
+	endpointNameEnv      = "NEUTREE_ENDPOINT_NAME"
+	endpointWorkspaceEnv = "NEUTREE_ENDPOINT_WORKSPACE"
 )
 
 type modelDownloadMarkerState int
@@ -63,9 +68,10 @@ const (
 	modelDownloadMarkerFailed
 )
 
+// getClusterLock serializes the Ray Serve application updates of a cluster, including the
+// ones of node drains warm replacing endpoint replicas.
 func getClusterLock(clusterKey string) *sync.Mutex {
-	actual, _ := clusterLocks.LoadOrStore(clusterKey, &sync.Mutex{})
-	return actual.(*sync.Mutex) //nolint:errcheck // type is guaranteed by LoadOrStore
+	return rayserve.ClusterLock(clusterKey)
 }
 
 var _ Orchestrator = &RayOrchestrator{}
@@ -75,6 +81,7 @@ type RayOrchestrator struct {
 
 	storage        storage.Storage
 	acceleratorMgr accelerator.Manager
+	imageService   registry.ImageService
 }
 
 type RayOptions struct {
@@ -86,6 +93,7 @@ func NewRayOrchestrator(opts RayOptions) *RayOrchestrator {
 		cluster:        opts.Cluster,
 		storage:        opts.Storage,
 		acceleratorMgr: opts.AcceleratorMgr,
+		imageService:   opts.ImageService,
 	}
 
 	return o
@@ -105,6 +113,11 @@ func (o *RayOrchestrator) prepareOrchestratorContext(endpoint *v1.Endpoint) (*Or
 		return nil, errors.Wrap(err, "failed to get deploy cluster")
 	}
 
+	endpoint, err = expandResourcePreset(endpoint, deployedCluster)
+	if err != nil {
+		return nil, err
+	}
+
 	engine, err := getUsedEngine(o.storage, endpoint)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get engine")
@@ -146,6 +159,10 @@ func (o *RayOrchestrator) validateDependencies(ctx *OrchestratorContext) error {
 		return errors.Errorf("deploy cluster %s is not ssh type", ctx.Cluster.Metadata.WorkspaceName())
 	}
 
+	if err := validateEndpointAcceleratorProduct(ctx.Endpoint, ctx.Cluster); err != nil {
+		return err
+	}
+
 	// validate engine status
 	if ctx.Engine.Status == nil || ctx.Engine.Status.Phase != v1.EnginePhaseCreated {
 		return errors.Errorf("engine %s not ready", ctx.Engine.Metadata.WorkspaceName())
@@ -161,7 +178,49 @@ func (o *RayOrchestrator) validateDependencies(ctx *OrchestratorContext) error {
 		return errors.Errorf("image registry %s not ready", ctx.ImageRegistry.Metadata.WorkspaceName())
 	}
 
-	return nil
+	// validate engine args against the engine version schema
+	if err := engine.ValidateEndpointEngineArgs(ctx.Endpoint, ctx.Engine); err != nil {
+		return err
+	}
+
+	if err := validateEndpointSamplingParams(ctx.Endpoint); err != nil {
+		return err
+	}
+
+	if err := validateEndpointServedModels(ctx.Endpoint); err != nil {
+		return err
+	}
+
+	if err := validateEndpointUpgradeStrategy(ctx.Endpoint); err != nil {
+		return err
+	}
+
+	if err := validateEndpointCanary(ctx.Endpoint); err != nil {
+		return err
+	}
+
+	return o.validateEndpointPlacementGroup(ctx)
+}
+
+// validateEndpointPlacementGroup checks the placement group the endpoint replicas are
+// gang scheduled into exists or can be created on the cluster.
+func (o *RayOrchestrator) validateEndpointPlacementGroup(ctx *OrchestratorContext) error {
+	placementGroup, err := getPlacementGroupOptions(ctx.Endpoint)
+	if err != nil || placementGroup == nil {
+		return err
+	}
+
+	rayResource, err := convertToRay(o.acceleratorMgr, ctx.Endpoint.Spec.Resources)
+	if err != nil {
+		return errors.Wrapf(err, "failed to convert endpoint %s resources to Ray format", ctx.Endpoint.Metadata.WorkspaceName())
+	}
+
+	replicas := 1
+	if ctx.Endpoint.Spec.Replicas.Num != nil {
+		replicas = *ctx.Endpoint.Spec.Replicas.Num
+	}
+
+	return validatePlacementGroup(ctx.rayService, placementGroup, rayResource, replicas*max(len(ctx.Endpoint.Spec.ServedModels()), 1))
 }
 
 // CreateEndpoint deploys a new endpoint using Ray Serve.
@@ -185,6 +244,10 @@ func (o *RayOrchestrator) CreateEndpoint(endpoint *v1.Endpoint) error {
 	}
 
 	if !isNew {
+		if ctx.Endpoint.Spec.IsMultiModel() {
+			return errors.Errorf("multi-model endpoints require cluster %s to be upgraded", ctx.Cluster.Metadata.WorkspaceName())
+		}
+
 		// always exec connect model to cluster, for cluster may dynamic scale, we need ensure model exists on all cluster nodes.
 		// todo: In order to reduce model connection actions, a new controller may be created in the future to uniformly manage model connections on the cluster.
 		err = o.connectSSHClusterEndpointModel(*ctx.ModelRegistry, *ctx.Endpoint, connect)
@@ -193,7 +256,18 @@ func (o *RayOrchestrator) CreateEndpoint(endpoint *v1.Endpoint) error {
 		}
 	}
 
-	return o.createOrUpdate(ctx)
+	if isNew && ctx.Endpoint.Spec.PinsEngineImageDigest() {
+		imageRef, err := sshEngineImageRef(ctx.Endpoint, ctx.Engine, ctx.ImageRegistry)
+		if err != nil {
+			return err
+		}
+
+		if _, err = resolveEngineImageDigest(o.storage, o.imageService, ctx.Endpoint, ctx.ImageRegistry, imageRef); err != nil {
+			return errors.Wrapf(err, "failed to pin engine image of endpoint %s", ctx.Endpoint.Metadata.WorkspaceName())
+		}
+	}
+
+	return tolerateDashboardOutage(ctx, o.createOrUpdate(ctx))
 }
 
 // PauseEndpoint removes the endpoint's Ray Serve application, which is how
@@ -210,6 +284,8 @@ func (o *RayOrchestrator) PauseEndpoint(endpoint *v1.Endpoint) error {
 
 	ctx.logger.V(4).Info("Pausing endpoint by deleting from Ray Serve")
 
+	// A dashboard outage is not tolerated here: the application would keep serving while
+	// the endpoint is reported paused, the error retries the pause on the next resync.
 	if err := o.deleteEndpoint(ctx); err != nil {
 		return errors.Wrapf(err, "failed to pause endpoint %s", endpoint.Metadata.WorkspaceName())
 	}
@@ -250,42 +326,87 @@ func (o *RayOrchestrator) createOrUpdate(ctx *OrchestratorContext) error {
 		return errors.Wrapf(err, "failed to get current serve applications for endpoint %s", ctx.Endpoint.Metadata.WorkspaceName())
 	}
 
-	newApp, err := EndpointToApplication(ctx.Endpoint, ctx.Cluster, ctx.ModelRegistry, ctx.Engine, ctx.ImageRegistry, o.acceleratorMgr)
+	newApps, err := EndpointToApplications(ctx.Endpoint, ctx.Cluster, ctx.ModelRegistry, ctx.Engine, ctx.ImageRegistry, o.acceleratorMgr)
 	if err != nil {
 		return errors.Wrapf(err, "failed to convert endpoint to application for endpoint %s", ctx.Endpoint.Metadata.WorkspaceName())
 	}
 
+	// Keep the replacement replicas of a node being drained until the drain completes.
+	rayserve.ApplyWarmReplacementSurge(ctx.Cluster.Metadata.WorkspaceName(), newApps)
+
+	if canary := endpointRolloutCanary(ctx.Endpoint); canary != nil && len(newApps) == 1 {
+		newApps, err = canaryApplications(ctx.Endpoint, canary, newApps[0], currentAppsResp.Applications, time.Now())
+		if err != nil {
+			return errors.Wrapf(err, "failed to plan canary of endpoint %s", ctx.Endpoint.Metadata.WorkspaceName())
+		}
+	}
+
+	recreate := ctx.Endpoint.Spec.GetUpgradeStrategyType() == v1.EndpointUpgradeStrategyTypeRecreate
+
+	desiredApps := make(map[string]dashboard.RayServeApplication, len(newApps))
+	for _, app := range newApps {
+		desiredApps[app.Name] = app
+	}
+
 	// Build the list of applications for the PUT request
-	needAppend := true
-	needUpdate := true
+	needAppend := false
+	needUpdate := false
+	deployed := make(map[string]bool, len(newApps))
 
-	updatedAppsList := make([]dashboard.RayServeApplication, 0, len(currentAppsResp.Applications)+1)
+	updatedAppsList := make([]dashboard.RayServeApplication, 0, len(currentAppsResp.Applications)+len(newApps))
 
 	for _, appStatus := range currentAppsResp.Applications {
-		if appStatus.DeployedAppConfig != nil {
-			updatedAppsList = append(updatedAppsList, *appStatus.DeployedAppConfig)
+		if appStatus.DeployedAppConfig == nil {
+			continue
+		}
 
-			if appStatus.DeployedAppConfig.Name == newApp.Name {
-				needAppend = false
+		newApp, desired := desiredApps[appStatus.DeployedAppConfig.Name]
 
-				equal, diff, err := util.JsonEqual(appStatus.DeployedAppConfig, newApp)
-				if err != nil {
-					return errors.Wrapf(err, "failed to compare serve application for endpoint %s", ctx.Endpoint.Metadata.WorkspaceName())
-				}
+		if !desired {
+			// Drop applications of models that are no longer served by the endpoint.
+			if isEndpointServeApplication(ctx.Endpoint, appStatus.DeployedAppConfig.Name) {
+				ctx.logger.Info("Serve application is no longer served by endpoint, removing", "application", appStatus.DeployedAppConfig.Name)
 
-				if equal {
-					needUpdate = false
-				} else {
-					ctx.logger.Info("Serve application need to update", "diff", diff)
+				needUpdate = true
 
-					updatedAppsList[len(updatedAppsList)-1] = newApp
-				}
+				continue
 			}
+
+			updatedAppsList = append(updatedAppsList, *appStatus.DeployedAppConfig)
+
+			continue
+		}
+
+		deployed[newApp.Name] = true
+
+		equal, diff, err := util.JsonEqual(appStatus.DeployedAppConfig, newApp)
+		if err != nil {
+			return errors.Wrapf(err, "failed to compare serve application for endpoint %s", ctx.Endpoint.Metadata.WorkspaceName())
+		}
+
+		if equal {
+			updatedAppsList = append(updatedAppsList, *appStatus.DeployedAppConfig)
+		} else if recreate {
+			// The application is deleted first and deployed again by the next reconcile, once
+			// it is gone, so its replicas are not replaced in place.
+			ctx.logger.Info("Serve application need to update, removing it to recreate", "application", newApp.Name, "diff", diff)
+
+			needUpdate = true
+		} else {
+			ctx.logger.Info("Serve application need to update", "application", newApp.Name, "diff", diff)
+
+			needUpdate = true
+
+			updatedAppsList = append(updatedAppsList, newApp)
 		}
 	}
 
-	if needAppend {
-		updatedAppsList = append(updatedAppsList, newApp)
+	for _, app := range newApps {
+		if !deployed[app.Name] {
+			needAppend = true
+
+			updatedAppsList = append(updatedAppsList, app)
+		}
 	}
 
 	if needAppend || needUpdate {
@@ -358,7 +479,7 @@ func (o *RayOrchestrator) deleteEndpoint(ctx *OrchestratorContext) error {
 	found := false
 
 	for name, appStatus := range currentAppsResp.Applications {
-		if name == EndpointToServeApplicationName(ctx.Endpoint) {
+		if isEndpointServeApplication(ctx.Endpoint, name) {
 			found = true
 			continue // Skip the endpoint to be deleted
 		}
@@ -472,14 +593,16 @@ func isBackendDeployment(deploymentName string) bool {
 	return strings.EqualFold(deploymentName, "backend")
 }
 
-func inspectRayModelDownloadLogs(svc dashboard.DashboardService, appStatus dashboard.RayServeApplicationStatus) (modelDownloadMarkerState, string, error) {
+func inspectRayModelDownloadLogs(svc dashboard.DashboardService, appStatus dashboard.RayServeApplicationStatus) (
+	modelDownloadMarkerState, string, *v1.ModelDownloadProgress, error) {
 	var (
-		firstErr         error
-		doneDetail       string
-		inProgressDetail string
-		noReplicaDetail  string
-		unknownActorSeen bool
-		unknownActorMsg  string
+		firstErr           error
+		doneDetail         string
+		inProgressDetail   string
+		inProgressProgress *v1.ModelDownloadProgress
+		noReplicaDetail    string
+		unknownActorSeen   bool
+		unknownActorMsg    string
 	)
 
 	for deploymentKey, deployment := range appStatus.Deployments {
@@ -520,6 +643,11 @@ func inspectRayModelDownloadLogs(svc dashboard.DashboardService, appStatus dashb
 
 			replicaState := modelDownloadMarkerNone
 
+			var (
+				replicaProgress  *v1.ModelDownloadProgress
+				replicaRateLimit string
+			)
+
 			for _, suffix := range []string{"out", "err"} {
 				logText, err := svc.GetActorLog(replica.ActorID, suffix, modelDownloadLogTailLines)
 				if err != nil {
@@ -530,11 +658,22 @@ func inspectRayModelDownloadLogs(svc dashboard.DashboardService, appStatus dashb
 					continue
 				}
 
+				if progress := parseModelDownloadProgress(logText); progress != nil {
+					replicaProgress = progress
+				}
+
+				if rateLimit := parseModelDownloadRateLimit(logText); rateLimit != "" {
+					replicaRateLimit = rateLimit
+				}
+
 				switch modelDownloadStateFromLog(logText) {
 				case modelDownloadMarkerFailed:
-					return modelDownloadMarkerFailed,
-						fmt.Sprintf("Deployment %s replica %s model download failed", deploymentName, replicaID),
-						nil
+					message := fmt.Sprintf("Deployment %s replica %s model download failed", deploymentName, replicaID)
+					if replicaRateLimit != "" {
+						message += ": " + modelDownloadRateLimitedMessage
+					}
+
+					return modelDownloadMarkerFailed, message, nil, nil
 				case modelDownloadMarkerDone:
 					replicaState = modelDownloadMarkerDone
 				case modelDownloadMarkerInProgress:
@@ -549,6 +688,11 @@ func inspectRayModelDownloadLogs(svc dashboard.DashboardService, appStatus dashb
 				doneDetail = fmt.Sprintf("Deployment %s replica %s model download completed", deploymentName, replicaID)
 			case modelDownloadMarkerInProgress:
 				inProgressDetail = fmt.Sprintf("Deployment %s replica %s model download in progress", deploymentName, replicaID)
+				if replicaRateLimit != "" {
+					inProgressDetail += ": " + replicaRateLimit
+				}
+
+				inProgressProgress = replicaProgress
 			case modelDownloadMarkerNone:
 				unknownActorSeen = true
 
@@ -560,22 +704,22 @@ func inspectRayModelDownloadLogs(svc dashboard.DashboardService, appStatus dashb
 	}
 
 	if inProgressDetail != "" {
-		return modelDownloadMarkerInProgress, inProgressDetail, nil
+		return modelDownloadMarkerInProgress, inProgressDetail, inProgressProgress, nil
 	}
 
 	if unknownActorSeen {
-		return modelDownloadMarkerNone, unknownActorMsg, firstErr
+		return modelDownloadMarkerNone, unknownActorMsg, nil, firstErr
 	}
 
 	if noReplicaDetail != "" {
-		return modelDownloadMarkerNone, noReplicaDetail, firstErr
+		return modelDownloadMarkerNone, noReplicaDetail, nil, firstErr
 	}
 
 	if doneDetail != "" {
-		return modelDownloadMarkerDone, doneDetail, nil
+		return modelDownloadMarkerDone, doneDetail, nil, nil
 	}
 
-	return modelDownloadMarkerNone, "", firstErr
+	return modelDownloadMarkerNone, "", nil, firstErr
 }
 
 func pendingRayModelDownloadMessage(
@@ -618,35 +762,99 @@ func setModelDownloadStatus(status *v1.EndpointStatus, completed bool, currentMo
 	status.ModelDownloadCompletedHash = &emptyHash
 }
 
+// rayStartupModelDownloadStatus is the result of inspecting backend actor logs during startup.
+type rayStartupModelDownloadStatus struct {
+	phase      v1.EndpointPhase
+	completed  bool
+	incomplete bool
+	messages   []string
+	progress   *v1.ModelDownloadProgress
+}
+
 func resolveRayStartupModelDownloadStatus(
 	svc dashboard.DashboardService,
 	appStatus dashboard.RayServeApplicationStatus,
 	phase v1.EndpointPhase,
 	modelDownloadCompleted bool,
-) (v1.EndpointPhase, bool, bool, []string) {
+) rayStartupModelDownloadStatus {
 	if phase != v1.EndpointPhaseDEPLOYING ||
 		(appStatus.Status != dashboard.ApplicationStatusDeploying && appStatus.Status != dashboard.ApplicationStatusNotStarted) {
-		return phase, modelDownloadCompleted, false, nil
+		return rayStartupModelDownloadStatus{phase: phase, completed: modelDownloadCompleted}
 	}
 
-	markerState, markerMsg, markerErr := inspectRayModelDownloadLogs(svc, appStatus)
+	markerState, markerMsg, progress, markerErr := inspectRayModelDownloadLogs(svc, appStatus)
 	if markerErr != nil {
 		klog.V(4).Info("failed to inspect Ray actor model download logs", "error", markerErr)
 	}
 
 	switch markerState {
 	case modelDownloadMarkerDone:
-		return phase, true, false, nil
+		return rayStartupModelDownloadStatus{phase: phase, completed: true}
 	case modelDownloadMarkerFailed:
-		return v1.EndpointPhaseFAILED, false, true, []string{markerMsg}
+		return rayStartupModelDownloadStatus{phase: v1.EndpointPhaseFAILED, incomplete: true, messages: []string{markerMsg}}
 	case modelDownloadMarkerInProgress:
-		return v1.EndpointPhaseMODELDOWNLOADING, false, true, []string{markerMsg}
+		return rayStartupModelDownloadStatus{
+			phase:      v1.EndpointPhaseMODELDOWNLOADING,
+			incomplete: true,
+			messages:   []string{markerMsg},
+			progress:   progress,
+		}
 	case modelDownloadMarkerNone:
-		return v1.EndpointPhaseMODELDOWNLOADING, false, true,
-			[]string{pendingRayModelDownloadMessage(appStatus, markerMsg, markerErr)}
+		return rayStartupModelDownloadStatus{
+			phase:      v1.EndpointPhaseMODELDOWNLOADING,
+			incomplete: true,
+			messages:   []string{pendingRayModelDownloadMessage(appStatus, markerMsg, markerErr)},
+		}
 	}
 
-	return phase, modelDownloadCompleted, false, nil
+	return rayStartupModelDownloadStatus{phase: phase, completed: modelDownloadCompleted}
+}
+
+// dashboardOutagePolicy returns how the endpoints of the cluster are reported while its Ray
+// dashboard is unreachable.
+func dashboardOutagePolicy(cluster *v1.Cluster) v1.DashboardOutagePolicy {
+	if cluster == nil || cluster.Spec == nil || cluster.Spec.Config == nil {
+		return v1.DashboardOutageFail
+	}
+
+	return cluster.Spec.Config.SSHConfig.DashboardOutagePolicy()
+}
+
+// tolerateDashboardOutage drops the error of an endpoint sync that could not reach the Ray
+// dashboard when the cluster tolerates dashboard outages, so the endpoint is not failed while
+// the head node restarts. The sync is retried on the next resync, and the status reported
+// meanwhile by GetEndpointStatus.
+func tolerateDashboardOutage(ctx *OrchestratorContext, err error) error {
+	if err == nil || !dashboard.IsDashboardUnreachable(err) || dashboardOutagePolicy(ctx.Cluster) == v1.DashboardOutageFail {
+		return err
+	}
+
+	ctx.logger.Info("Ray dashboard unreachable, retrying the endpoint sync on the next resync", "error", err.Error())
+
+	return nil
+}
+
+// dashboardOutageStatus returns the status reported for an endpoint whose Ray dashboard is
+// unreachable: its last known status, left as is with the last_known policy so nothing flaps,
+// or marked Unknown. Returns nil when the policy fails the endpoint or err is not a dashboard
+// outage.
+func dashboardOutageStatus(endpoint *v1.Endpoint, policy v1.DashboardOutagePolicy, err error) *v1.EndpointStatus {
+	if !dashboard.IsDashboardUnreachable(err) || policy == v1.DashboardOutageFail {
+		return nil
+	}
+
+	status := &v1.EndpointStatus{}
+	if endpoint.Status != nil {
+		*status = *endpoint.Status
+	}
+
+	// An endpoint without a last known phase has nothing to keep.
+	if policy == v1.DashboardOutageUnknown || status.Phase == "" {
+		status.Phase = v1.EndpointPhaseUNKNOWN
+		status.ErrorMessage = "Ray dashboard unreachable, the endpoint status is unknown until it is back"
+	}
+
+	return status
 }
 
 // GetEndpointStatus retrieves the status of a specific endpoint from Ray Serve.
@@ -661,11 +869,18 @@ func (o *RayOrchestrator) GetEndpointStatus(endpoint *v1.Endpoint) (*v1.Endpoint
 
 	currentAppsResp, err := dashboardService.GetServeApplications()
 	if err != nil {
+		if status := dashboardOutageStatus(endpoint, dashboardOutagePolicy(o.cluster), err); status != nil {
+			klog.Warningf("Ray dashboard of endpoint %s unreachable, reporting %s status: %v",
+				endpoint.Metadata.WorkspaceName(), status.Phase, err)
+
+			return status, nil
+		}
+
 		return nil, errors.Wrapf(err, "failed to get current serve applications for endpoint %s status", endpoint.Metadata.WorkspaceName())
 	}
 
 	isDeleting := endpoint.GetDeletionTimestamp() != ""
-	status, exists := currentAppsResp.Applications[EndpointToServeApplicationName(endpoint)]
+	status, exists, complete := endpointServeApplicationStatus(endpoint, currentAppsResp.Applications)
 
 	if isDeleting {
 		if !exists {
@@ -677,7 +892,7 @@ func (o *RayOrchestrator) GetEndpointStatus(endpoint *v1.Endpoint) (*v1.Endpoint
 
 		return &v1.EndpointStatus{
 			Phase:        v1.EndpointPhaseDELETING,
-			ErrorMessage: "Endpoint deleting in progress: waiting for Ray Serve to delete the application",
+			ErrorMessage: "Endpoint deleting in progress: waiting for the requests in flight and for Ray Serve to delete the application",
 		}, nil
 	}
 
@@ -696,7 +911,7 @@ func (o *RayOrchestrator) GetEndpointStatus(endpoint *v1.Endpoint) (*v1.Endpoint
 		}, nil
 	}
 
-	if !exists {
+	if !complete {
 		return &v1.EndpointStatus{
 			Phase:        v1.EndpointPhaseDEPLOYING,
 			ErrorMessage: "Endpoint deploying in progress: Endpoint not found in Ray Serve applications",
@@ -747,6 +962,8 @@ func (o *RayOrchestrator) GetEndpointStatus(endpoint *v1.Endpoint) (*v1.Endpoint
 			Phase:        phase,
 			ErrorMessage: "",
 			Resources:    resources,
+			Canary:       endpointCanaryStatus(endpoint, currentAppsResp.Applications),
+			Replicas:     rayReplicaStatuses(status),
 		}
 		if currentModelHash != "" {
 			setModelDownloadStatus(endpointStatus, true, currentModelHash)
@@ -755,10 +972,11 @@ func (o *RayOrchestrator) GetEndpointStatus(endpoint *v1.Endpoint) (*v1.Endpoint
 		return endpointStatus, nil
 	}
 
-	var modelDownloadMessages []string
-	phase, modelDownloadCompleted, modelDownloadIncomplete, modelDownloadMessages =
-		resolveRayStartupModelDownloadStatus(dashboardService, status, phase, modelDownloadCompleted)
-	errorMessages = append(errorMessages, modelDownloadMessages...)
+	startupStatus := resolveRayStartupModelDownloadStatus(dashboardService, status, phase, modelDownloadCompleted)
+	phase = startupStatus.phase
+	modelDownloadCompleted = startupStatus.completed
+	modelDownloadIncomplete = startupStatus.incomplete
+	errorMessages = append(errorMessages, startupStatus.messages...)
 
 	// Merge Ray Serve error messages
 	if status.Message != "" {
@@ -786,7 +1004,7 @@ func (o *RayOrchestrator) GetEndpointStatus(endpoint *v1.Endpoint) (*v1.Endpoint
 		case v1.EndpointPhaseMODELDOWNLOADING:
 			errorMsg = "Endpoint model download in progress: " + errorMsg
 		case v1.EndpointPhaseFAILED:
-			errorMsg = "Endpoint failed: " + errorMsg
+			errorMsg = v1.EndpointWorkloadFailedMessagePrefix + errorMsg
 		}
 	}
 
@@ -794,6 +1012,12 @@ func (o *RayOrchestrator) GetEndpointStatus(endpoint *v1.Endpoint) (*v1.Endpoint
 		Phase:        phase,
 		ErrorMessage: errorMsg, // Use merged error message
 		Resources:    resources,
+		Canary:       endpointCanaryStatus(endpoint, currentAppsResp.Applications),
+		Replicas:     rayReplicaStatuses(status),
+	}
+
+	if phase == v1.EndpointPhaseMODELDOWNLOADING {
+		endpointStatus.ModelDownloadProgress = startupStatus.progress
 	}
 
 	if currentModelHash != "" {
@@ -818,6 +1042,60 @@ func (o *RayOrchestrator) buildEndpointResourceStatus(endpoint *v1.Endpoint) (*v
 	return resourceBuilder.BuildEndpointResources(context.Background(), o.cluster, endpoint)
 }
 
+// EndpointToApplications converts an endpoint to its Ray Serve applications. A multi-model
+// endpoint is deployed as one application per served model, routed under the endpoint route
+// prefix by model key and serving the model under its model name.
+func EndpointToApplications(endpoint *v1.Endpoint, deployedCluster *v1.Cluster,
+	modelRegistry *v1.ModelRegistry, engine *v1.Engine, imageRegistry *v1.ImageRegistry,
+	acceleratorMgr accelerator.Manager) ([]dashboard.RayServeApplication, error) {
+	if !endpoint.Spec.IsMultiModel() {
+		app, err := EndpointToApplication(endpoint, deployedCluster, modelRegistry, engine, imageRegistry, acceleratorMgr)
+		if err != nil {
+			return nil, err
+		}
+
+		return []dashboard.RayServeApplication{app}, nil
+	}
+
+	apps := make([]dashboard.RayServeApplication, 0, len(endpoint.Spec.Models)+1)
+
+	for _, model := range endpoint.Spec.ServedModels() {
+		app, err := EndpointToApplication(endpointForServedModel(endpoint, model), deployedCluster, modelRegistry, engine, imageRegistry, acceleratorMgr)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to convert model %s to application", model.Name)
+		}
+
+		app.Name = EndpointToServedModelApplicationName(endpoint, model)
+		app.RoutePrefix = util.EndpointServedModelRoutePrefix(endpoint, model)
+
+		// The gateway dispatches on the requested model name, so every model is served under its name.
+		if modelArgs, ok := app.Args["model"].(map[string]interface{}); ok {
+			modelArgs["serve_name"] = model.Name
+		}
+
+		apps = append(apps, app)
+	}
+
+	return apps, nil
+}
+
+// endpointForServedModel returns a copy of the endpoint that serves only the given model.
+func endpointForServedModel(endpoint *v1.Endpoint, model *v1.ModelSpec) *v1.Endpoint {
+	servedModel := *model
+	if servedModel.Registry == "" {
+		servedModel.Registry = endpoint.Spec.Model.Registry
+	}
+
+	spec := *endpoint.Spec
+	spec.Model = &servedModel
+	spec.Models = nil
+
+	servedEndpoint := *endpoint
+	servedEndpoint.Spec = &spec
+
+	return &servedEndpoint
+}
+
 // endpointToApplication converts Neutree Endpoint and ModelRegistry to a RayServeApplication.
 func EndpointToApplication(endpoint *v1.Endpoint, deployedCluster *v1.Cluster,
 	modelRegistry *v1.ModelRegistry, engine *v1.Engine, imageRegistry *v1.ImageRegistry,
@@ -855,6 +1133,15 @@ func EndpointToApplication(endpoint *v1.Endpoint, deployedCluster *v1.Cluster,
 		}
 	}
 
+	routerOpts, err := getRouterOptions(endpoint)
+	if err != nil {
+		return dashboard.RayServeApplication{}, errors.Wrapf(err, "failed to parse router options for endpoint %s", endpoint.Metadata.WorkspaceName())
+	}
+
+	if routerOpts != nil {
+		deploymentOptions[deploymentOptionRouter] = routerOpts.Args()
+	}
+
 	rayResource, err := convertToRay(acceleratorMgr, endpoint.Spec.Resources)
 	if err != nil {
 		klog.Errorf("Failed to convert endpoint %s resources to Ray format: %v", endpoint.Metadata.WorkspaceName(), err)
@@ -869,6 +1156,46 @@ func EndpointToApplication(endpoint *v1.Endpoint, deployedCluster *v1.Cluster,
 		"resources":    rayResource.Resources,
 	}
 
+	if endpoint.Spec.MaxOngoingRequests != nil {
+		if *endpoint.Spec.MaxOngoingRequests <= 0 {
+			return dashboard.RayServeApplication{}, errors.Errorf("spec.max_ongoing_requests of endpoint %s must be positive",
+				endpoint.Metadata.WorkspaceName())
+		}
+
+		backendConfig["max_ongoing_requests"] = *endpoint.Spec.MaxOngoingRequests
+	}
+
+	topologyAware, err := useTopologyAwarePlacement(endpoint, deployedCluster, rayResource.NumGPUs)
+	if err != nil {
+		return dashboard.RayServeApplication{}, errors.Wrapf(err, "failed to parse placement options for endpoint %s", endpoint.Metadata.WorkspaceName())
+	}
+
+	placementGroup, err := getPlacementGroupOptions(endpoint)
+	if err != nil {
+		return dashboard.RayServeApplication{}, errors.Wrapf(err, "failed to parse placement options for endpoint %s", endpoint.Metadata.WorkspaceName())
+	}
+
+	// an explicit placement group takes precedence over the topology-aware one.
+	switch {
+	case placementGroup != nil && placementGroup.Name != "":
+		backendConfig["placement_group_name"] = placementGroup.Name
+	case placementGroup != nil:
+		backendConfig["placement_group_bundles"] = placementGroup.Bundles
+		backendConfig["placement_group_strategy"] = placementGroup.Strategy
+	case topologyAware:
+		backendConfig["placement_group_bundles"] = rayTopologyPlacementGroupBundles(rayResource)
+		backendConfig["placement_group_strategy"] = rayPlacementGroupStrategyStrictPack
+	}
+
+	healthCheckOpts, err := getServeHealthCheckOptions(endpoint)
+	if err != nil {
+		return dashboard.RayServeApplication{}, errors.Wrapf(err, "failed to parse health check options for endpoint %s", endpoint.Metadata.WorkspaceName())
+	}
+
+	for k, v := range healthCheckOpts {
+		backendConfig[k] = v
+	}
+
 	deploymentOptions["backend"] = backendConfig
 
 	deploymentOptions["controller"] = map[string]interface{}{
@@ -885,6 +1212,51 @@ func EndpointToApplication(endpoint *v1.Endpoint, deployedCluster *v1.Cluster,
 		applicationEnv[k] = v
 	}
 
+	// Ray downloads the model inside the backend replica, so only the retry settings apply here.
+	// credentials_secret is mounted as a Secret volume and only refreshes kubernetes downloads.
+	downloaderOptions, err := getModelDownloaderOptions(endpoint)
+	if err != nil {
+		return dashboard.RayServeApplication{}, errors.Wrapf(err, "failed to parse model downloader options for endpoint %s", endpoint.Metadata.WorkspaceName())
+	}
+
+	for k, v := range downloaderOptions.Env() {
+		if _, exists := applicationEnv[k]; !exists {
+			applicationEnv[k] = v
+		}
+	}
+
+	compressionOpts, err := getCompressionOptions(endpoint)
+	if err != nil {
+		return dashboard.RayServeApplication{}, errors.Wrapf(err, "failed to parse compression options for endpoint %s", endpoint.Metadata.WorkspaceName())
+	}
+
+	if compressionOpts != nil {
+		maps.Copy(applicationEnv, compressionOpts.Env())
+	}
+
+	tracingOpts, err := getTracingOptions(endpoint)
+	if err != nil {
+		return dashboard.RayServeApplication{}, errors.Wrapf(err, "failed to parse tracing options for endpoint %s", endpoint.Metadata.WorkspaceName())
+	}
+
+	if tracingOpts != nil {
+		maps.Copy(applicationEnv, tracingOpts.Env())
+	}
+
+	accessLogOpts, err := getAccessLogOptions(endpoint)
+	if err != nil {
+		return dashboard.RayServeApplication{}, errors.Wrapf(err, "failed to parse access log options for endpoint %s", endpoint.Metadata.WorkspaceName())
+	}
+
+	if accessLogOpts != nil {
+		maps.Copy(applicationEnv, accessLogOpts.Env())
+	}
+
+	// All applications of a multi-model endpoint report their request metrics under the
+	// endpoint, so they can be broken down per model and summed back per endpoint.
+	applicationEnv[endpointNameEnv] = endpoint.Metadata.Name
+	applicationEnv[endpointWorkspaceEnv] = endpoint.Metadata.Workspace
+
 	modelArgs := map[string]interface{}{
 		"registry_type": modelRegistry.Spec.Type,
 		"name":          endpoint.Spec.Model.Name,
@@ -895,16 +1267,9 @@ func EndpointToApplication(endpoint *v1.Endpoint, deployedCluster *v1.Cluster,
 
 	modelArgs["serve_name"] = endpointModelServeName(endpoint, modelRegistry)
 
-	modelCacheRelativePath := v1.DefaultModelCacheRelativePath
-
-	modelCaches, err := util.GetClusterModelCache(*deployedCluster)
+	modelCacheName, err := getModelCacheName(endpoint, deployedCluster)
 	if err != nil {
-		return dashboard.RayServeApplication{}, errors.Wrap(err, "failed to get cluster model cache")
-	}
-
-	// TODO: Now we only use the first model cache for simplicity, In the future, we may support specific model cache.
-	if len(modelCaches) > 0 {
-		modelCacheRelativePath = modelCaches[0].Name
+		return dashboard.RayServeApplication{}, err
 	}
 
 	switch modelRegistry.Spec.Type {
@@ -922,7 +1287,7 @@ func EndpointToApplication(endpoint *v1.Endpoint, deployedCluster *v1.Cluster,
 			// bentoml model registry path: <BENTOML_HOME>/models/<model_name>/<model_version>
 			// so we need to append "models" to the path
 			modelArgs["registry_path"] = filepath.Join(nfsMountPath, "models", endpoint.Spec.Model.Name, modelRealVersion)
-			modelArgs["path"] = filepath.Join(v1.DefaultSSHClusterModelCacheMountPath, modelCacheRelativePath, endpoint.Spec.Model.Name, modelRealVersion)
+			modelArgs["path"] = util.ModelCacheModelPath(v1.DefaultSSHClusterModelCacheMountPath, deployedCluster, modelCacheName, modelRegistry.Spec.Type, endpoint.Spec.Model.Name, modelRealVersion)
 		}
 	case v1.HuggingFaceModelRegistryType:
 		applicationEnv[v1.HFEndpoint] = strings.TrimSuffix(modelRegistry.Spec.Url, "/")
@@ -937,7 +1302,7 @@ func EndpointToApplication(endpoint *v1.Endpoint, deployedCluster *v1.Cluster,
 
 		modelArgs["version"] = modelRealVersion
 		modelArgs["registry_path"] = endpoint.Spec.Model.Name
-		modelArgs["path"] = filepath.Join(v1.DefaultSSHClusterModelCacheMountPath, modelCacheRelativePath, endpoint.Spec.Model.Name, modelRealVersion)
+		modelArgs["path"] = util.ModelCacheModelPath(v1.DefaultSSHClusterModelCacheMountPath, deployedCluster, modelCacheName, modelRegistry.Spec.Type, endpoint.Spec.Model.Name, modelRealVersion)
 	}
 
 	app.Args["model"] = modelArgs
@@ -948,6 +1313,8 @@ func EndpointToApplication(endpoint *v1.Endpoint, deployedCluster *v1.Cluster,
 
 	setDefaultTensorParallelSize(endpoint, &app, rayResource.NumGPUs)
 
+	setDefaultSamplingParamsForApplication(endpoint, &app)
+
 	setEngineSpecialEnv(endpoint, deployedCluster, applicationEnv)
 
 	app.RuntimeEnv = map[string]interface{}{
@@ -975,6 +1342,11 @@ func EndpointToApplication(endpoint *v1.Endpoint, deployedCluster *v1.Cluster,
 	}
 
 	if isNewCluster {
+		modelCaches, err := util.GetClusterModelCache(*deployedCluster)
+		if err != nil {
+			return dashboard.RayServeApplication{}, errors.Wrap(err, "failed to get cluster model cache")
+		}
+
 		// Inject engine identity for metrics labeling (used by NeutreeRayStatLogger / _SanitizedRayStatLogger).
 		if endpoint.Spec.Engine != nil {
 			applicationEnv["ENGINE_NAME"] = endpoint.Spec.Engine.Engine
@@ -1012,6 +1384,23 @@ func setDefaultSGLangEnableMetricsForApplication(endpoint *v1.Endpoint, app *das
 	app.Args["engine_args"] = engineArgs
 }
 
+// setDefaultSamplingParamsForApplication passes the default sampling parameters of the endpoint
+// to engines applying them server-side. engine_args is copied as it is shared with the endpoint
+// variables.
+func setDefaultSamplingParamsForApplication(endpoint *v1.Endpoint, app *dashboard.RayServeApplication) {
+	if endpoint.Spec.DefaultSamplingParams == nil || !endpoint.Spec.EngineAppliesSamplingDefaults() {
+		return
+	}
+
+	engineArgs := map[string]interface{}{}
+	if args, ok := app.Args["engine_args"].(map[string]interface{}); ok {
+		maps.Copy(engineArgs, args)
+	}
+
+	setDefaultSamplingParamsArg(endpoint, engineArgs)
+	app.Args["engine_args"] = engineArgs
+}
+
 // setDefaultTensorParallelSize auto-sets the tensor-parallel field in
 // engine_args to GPU count when GPU > 1 and is a whole number. Skips if the
 // engine doesn't take a TP arg or if the user already configured it (in
@@ -1063,32 +1452,16 @@ func buildEngineContainerConfigs(endpoint *v1.Endpoint,
 	engine *v1.Engine, imageRegistry *v1.ImageRegistry,
 	acceleratorMgr accelerator.Manager,
 	modelCaches []v1.ModelCache, modelRegistry *v1.ModelRegistry) (baseConfig, backendConfig map[string]interface{}, err error) {
-	if endpoint == nil || endpoint.Spec == nil || endpoint.Spec.Engine == nil {
-		return nil, nil, errors.New("endpoint with engine spec is required for SSH cluster")
-	}
-
-	if engine == nil || engine.Spec == nil {
-		return nil, nil, errors.New("engine is required for SSH cluster")
-	}
-
-	// Find the matching engine version
-	var targetVersion *v1.EngineVersion
-
-	for _, ev := range engine.Spec.Versions {
-		if ev.Version == endpoint.Spec.Engine.Version {
-			targetVersion = ev
-			break
-		}
+	imageRef, err := sshEngineImageRef(endpoint, engine, imageRegistry)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	if targetVersion == nil {
-		return nil, nil, errors.Errorf("engine version %s not found in engine %s", endpoint.Spec.Engine.Version, engine.Metadata.Name)
+	if endpoint.Spec.PinsEngineImageDigest() && endpoint.Status != nil {
+		imageRef = util.PinImageDigest(imageRef, endpoint.Status.EngineImageDigest)
 	}
 
-	// Get accelerator type from endpoint resources (consistent with K8s orchestrator).
-	// Default to "cpu" when no accelerator type is specified.
 	acceleratorType := ""
-
 	if endpoint.Spec.Resources != nil {
 		acceleratorType = endpoint.Spec.Resources.GetAcceleratorType()
 	}
@@ -1097,25 +1470,6 @@ func buildEngineContainerConfigs(endpoint *v1.Endpoint,
 		acceleratorType = acceleratorTypeCPU
 	}
 
-	// Look up engine image and build full image reference
-	imagePrefix := ""
-	if imageRegistry != nil {
-		imagePrefix, err = util.GetImagePrefix(imageRegistry)
-		if err != nil {
-			return nil, nil, errors.Wrapf(err, "failed to get image prefix from registry")
-		}
-	}
-
-	// SSH clusters use GetImageForSSHAccelerator which tries "ssh_<type>" first,
-	// then falls back to generic accelerator key.
-	engineImage := targetVersion.GetImageForSSHAccelerator(acceleratorType)
-	if engineImage == nil {
-		return nil, nil, errors.Errorf("no engine image configured for accelerator %q in engine %s version %s",
-			acceleratorType, engine.Metadata.Name, endpoint.Spec.Engine.Version)
-	}
-
-	imageRef := util.BuildEngineImageRef(imagePrefix, engineImage)
-
 	// Base config: engine image + --rm only (for app_builder and Controller).
 	baseConfig = map[string]interface{}{
 		"image":       imageRef,
@@ -1193,6 +1547,64 @@ func buildEngineContainerConfigs(endpoint *v1.Endpoint,
 	return baseConfig, backendConfig, nil
 }
 
+// sshEngineImageRef returns the tagged engine image reference an endpoint runs with on SSH clusters.
+func sshEngineImageRef(endpoint *v1.Endpoint, engine *v1.Engine, imageRegistry *v1.ImageRegistry) (string, error) {
+	if endpoint == nil || endpoint.Spec == nil || endpoint.Spec.Engine == nil {
+		return "", errors.New("endpoint with engine spec is required for SSH cluster")
+	}
+
+	if engine == nil || engine.Spec == nil {
+		return "", errors.New("engine is required for SSH cluster")
+	}
+
+	// Find the matching engine version
+	var targetVersion *v1.EngineVersion
+
+	for _, ev := range engine.Spec.Versions {
+		if ev.Version == endpoint.Spec.Engine.Version {
+			targetVersion = ev
+			break
+		}
+	}
+
+	if targetVersion == nil {
+		return "", errors.Errorf("engine version %s not found in engine %s", endpoint.Spec.Engine.Version, engine.Metadata.Name)
+	}
+
+	// Get accelerator type from endpoint resources (consistent with K8s orchestrator).
+	// Default to "cpu" when no accelerator type is specified.
+	acceleratorType := ""
+
+	if endpoint.Spec.Resources != nil {
+		acceleratorType = endpoint.Spec.Resources.GetAcceleratorType()
+	}
+
+	if acceleratorType == "" {
+		acceleratorType = acceleratorTypeCPU
+	}
+
+	// Look up engine image and build full image reference
+	imagePrefix := ""
+	if imageRegistry != nil {
+		var err error
+
+		imagePrefix, err = util.GetImagePrefix(imageRegistry)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to get image prefix from registry")
+		}
+	}
+
+	// SSH clusters use GetImageForSSHAccelerator which tries "ssh_<type>" first,
+	// then falls back to generic accelerator key.
+	engineImage := targetVersion.GetImageForSSHAccelerator(acceleratorType)
+	if engineImage == nil {
+		return "", errors.Errorf("no engine image configured for accelerator %q in engine %s version %s",
+			acceleratorType, engine.Metadata.Name, endpoint.Spec.Engine.Version)
+	}
+
+	return util.BuildEngineImageRef(imagePrefix, engineImage), nil
+}
+
 func setEngineSpecialEnv(endpoint *v1.Endpoint, deployedCluster *v1.Cluster, applicationEnv map[string]string) {
 	// Old clusters (<= v1.0.0) use RAY_kill_child_processes_on_worker_exit_with_raylet_subreaper which causes
 	// parent processes to lose child exit codes, breaking vLLM's P2P check. For those clusters, skip the check.
@@ -1224,3 +1636,128 @@ func FormatServiceURL(cluster *v1.Cluster, endpoint *v1.Endpoint) (string, error
 func EndpointToServeApplicationName(endpoint *v1.Endpoint) string {
 	return fmt.Sprintf("%s_%s", endpoint.Metadata.Workspace, endpoint.Metadata.Name)
 }
+
+// EndpointToServedModelApplicationName returns the serve application name of one model of a multi-model endpoint.
+func EndpointToServedModelApplicationName(endpoint *v1.Endpoint, model *v1.ModelSpec) string {
+	return EndpointToServeApplicationName(endpoint) + "_" + util.EndpointServedModelKey(endpoint, model)
+}
+
+// isEndpointServeApplication reports whether the serve application belongs to the endpoint,
+// including the per-model applications of a multi-model endpoint. Workspace and endpoint
+// names cannot contain "_", so the prefix match is unambiguous.
+func isEndpointServeApplication(endpoint *v1.Endpoint, appName string) bool {
+	name := EndpointToServeApplicationName(endpoint)
+
+	return appName == name || strings.HasPrefix(appName, name+"_")
+}
+
+// serveApplicationStatusSeverity orders Ray Serve application statuses from healthy to failed.
+var serveApplicationStatusSeverity = map[string]int{
+	dashboard.ApplicationStatusRunning:      0,
+	dashboard.ApplicationStatusDeploying:    1,
+	dashboard.ApplicationStatusNotStarted:   2,
+	dashboard.ApplicationStatusUnhealthy:    4,
+	dashboard.ApplicationStatusDeployFailed: 5,
+}
+
+// endpointServeApplicationStatus returns the Ray Serve status of the endpoint. exists reports
+// whether any application of the endpoint is present and complete whether all expected ones are.
+//
+// The applications of a multi-model endpoint are merged into one status: the most severe
+// application status wins, messages are prefixed with the model name and deployments are
+// keyed by model key.
+func endpointServeApplicationStatus(endpoint *v1.Endpoint, apps map[string]dashboard.RayServeApplicationStatus) (
+	status dashboard.RayServeApplicationStatus, exists bool, complete bool) {
+	if !endpoint.Spec.IsMultiModel() {
+		status, exists = apps[EndpointToServeApplicationName(endpoint)]
+		return status, exists, exists
+	}
+
+	for name := range apps {
+		if isEndpointServeApplication(endpoint, name) {
+			exists = true
+			break
+		}
+	}
+
+	merged := dashboard.RayServeApplicationStatus{
+		Status:      dashboard.ApplicationStatusRunning,
+		Deployments: map[string]dashboard.Deployment{},
+	}
+
+	var messages []string
+
+	maxSeverity := 0
+
+	for _, model := range endpoint.Spec.ServedModels() {
+		appStatus, ok := apps[EndpointToServedModelApplicationName(endpoint, model)]
+		if !ok {
+			return dashboard.RayServeApplicationStatus{}, exists, false
+		}
+
+		severity, known := serveApplicationStatusSeverity[appStatus.Status]
+		if !known {
+			severity = 3
+		}
+
+		if severity > maxSeverity {
+			maxSeverity = severity
+			merged.Status = appStatus.Status
+		}
+
+		if appStatus.Message != "" {
+			messages = append(messages, fmt.Sprintf("model %s: %s", model.Name, appStatus.Message))
+		}
+
+		key := util.EndpointServedModelKey(endpoint, model)
+		for deploymentKey, deployment := range appStatus.Deployments {
+			if deployment.Name == "" {
+				deployment.Name = deploymentKey
+			}
+
+			merged.Deployments[key+"/"+deploymentKey] = deployment
+		}
+	}
+
+	merged.Message = strings.Join(messages, "; ")
+
+	return merged, exists, true
+}
+
+// rayReplicaStatuses returns the status of the replicas of the serve application, ordered by
+// deployment and replica id. Ray Serve reports errors per deployment, so the replicas of an
+// unhealthy deployment that are not running carry the message of the deployment.
+func rayReplicaStatuses(status dashboard.RayServeApplicationStatus) []v1.ReplicaStatus {
+	var replicas []v1.ReplicaStatus
+
+	for _, key := range slices.Sorted(maps.Keys(status.Deployments)) {
+		deployment := status.Deployments[key]
+
+		name := deployment.Name
+		if name == "" {
+			name = key
+		}
+
+		start := len(replicas)
+
+		for _, replica := range deployment.Replicas {
+			replicaStatus := v1.ReplicaStatus{
+				ReplicaID:  replica.ReplicaID,
+				Deployment: name,
+				State:      v1.ReplicaState(replica.State),
+			}
+
+			if replicaStatus.State != v1.ReplicaStateRunning && deployment.Status != dashboard.DeploymentStatusHealthy {
+				replicaStatus.ErrorMessage = deployment.Message
+			}
+
+			replicas = append(replicas, replicaStatus)
+		}
+
+		slices.SortFunc(replicas[start:], func(a, b v1.ReplicaStatus) int {
+			return strings.Compare(a.ReplicaID, b.ReplicaID)
+		})
+	}
+
+	return replicas
+}
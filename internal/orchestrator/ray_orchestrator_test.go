@@ -1,6 +1,7 @@
 package orchestrator
 
 import (
+	"fmt"
 	"path/filepath"
 	"testing"
 	"time"
@@ -11,6 +12,7 @@ import (
 	"go.openly.dev/pointy"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/klog/v2"
+	"k8s.io/utils/pointer"
 
 	v1 "github.com/neutree-ai/neutree/api/v1"
 	acceleratormocks "github.com/neutree-ai/neutree/internal/accelerator/mocks"
@@ -28,6 +30,8 @@ func intPtr(i int) *int { return &i }
 
 func stringPtr(v string) *string { return &v }
 
+func float64Ptr(v float64) *float64 { return &v }
+
 func endpointModelHashForTest(t *testing.T, endpoint *v1.Endpoint) string {
 	t.Helper()
 
@@ -487,6 +491,148 @@ func TestRayOrchestrator_createOrUpdateEndpoint_ApplicationNameConsistency(t *te
 	}
 }
 
+func TestRayOrchestrator_createOrUpdateEndpoint_MultiModel(t *testing.T) {
+	endpoint := &v1.Endpoint{
+		Metadata: &v1.Metadata{
+			Workspace: "production",
+			Name:      "chat-model",
+		},
+		Spec: &v1.EndpointSpec{
+			Cluster: "test-cluster",
+			Engine: &v1.EndpointEngineSpec{
+				Engine:  "vllm",
+				Version: "0.5.0",
+			},
+			Model: &v1.ModelSpec{
+				Registry: "test-registry",
+				Name:     "test-model",
+			},
+			Models: []*v1.ModelSpec{
+				{Name: "second-model"},
+			},
+			Resources: &v1.ResourceSpec{
+				CPU:         pointy.String("1.0"),
+				GPU:         pointy.String("1.0"),
+				Accelerator: make(map[string]string),
+			},
+			Replicas: v1.ReplicaSpec{
+				Num: pointy.Int(1),
+			},
+			DeploymentOptions: map[string]interface{}{},
+			Variables:         map[string]interface{}{},
+		},
+	}
+
+	mockDashboard := dashboardmocks.NewMockDashboardService(t)
+	mockStorage := storagemocks.NewMockStorage(t)
+
+	// The single-model application and a removed model are dropped, other endpoints are kept.
+	mockDashboard.On("GetServeApplications").Return(&dashboard.RayServeApplicationsResponse{
+		Applications: map[string]dashboard.RayServeApplicationStatus{
+			"production_chat-model": {
+				Status:            "RUNNING",
+				DeployedAppConfig: &dashboard.RayServeApplication{Name: "production_chat-model"},
+			},
+			"production_chat-model_removed-model": {
+				Status:            "RUNNING",
+				DeployedAppConfig: &dashboard.RayServeApplication{Name: "production_chat-model_removed-model"},
+			},
+			"production_chat-model-2": {
+				Status:            "RUNNING",
+				DeployedAppConfig: &dashboard.RayServeApplication{Name: "production_chat-model-2"},
+			},
+		},
+	}, nil)
+
+	mockDashboard.On("UpdateServeApplications", mock.MatchedBy(func(req dashboard.RayServeApplicationsRequest) bool {
+		names := map[string]string{}
+		for _, app := range req.Applications {
+			names[app.Name] = app.RoutePrefix
+		}
+
+		return len(names) == 3 &&
+			names["production_chat-model_test-model"] == "/production/chat-model/test-model" &&
+			names["production_chat-model_second-model"] == "/production/chat-model/second-model" &&
+			names["production_chat-model-2"] == ""
+	})).Return(nil)
+
+	mockAcceleratorMgr := acceleratormocks.NewMockManager(t)
+	mockAcceleratorMgr.EXPECT().GetEngineContainerRunOptions(mock.Anything).Return([]string{"--runtime=nvidia", "--gpus all"}, nil).Maybe()
+	mockAcceleratorMgr.EXPECT().GetAllConverters().Return(map[string]plugin.ResourceConverter{}).Maybe()
+	mockAcceleratorMgr.EXPECT().GetAllParsers().Return(map[string]resourceparser.ResourceParser{}).Maybe()
+
+	o, ctx := newTestRayOrchestratorCtx(mockStorage, mockDashboard, endpoint, mockAcceleratorMgr)
+
+	assert.NoError(t, o.createOrUpdate(ctx))
+	mockDashboard.AssertExpectations(t)
+}
+
+func TestRayOrchestrator_createOrUpdateEndpoint_RoutingWeights(t *testing.T) {
+	endpoint := &v1.Endpoint{
+		Metadata: &v1.Metadata{
+			Workspace: "production",
+			Name:      "chat-model",
+		},
+		Spec: &v1.EndpointSpec{
+			Cluster: "test-cluster",
+			Engine: &v1.EndpointEngineSpec{
+				Engine:  "vllm",
+				Version: "0.5.0",
+			},
+			Model: &v1.ModelSpec{
+				Registry: "test-registry",
+				Name:     "test-model",
+				Version:  "v1",
+				Weight:   intPtr(90),
+			},
+			Models: []*v1.ModelSpec{
+				{Name: "test-model", Version: "v2", Weight: intPtr(10)},
+			},
+			Resources: &v1.ResourceSpec{
+				CPU:         pointy.String("1.0"),
+				GPU:         pointy.String("1.0"),
+				Accelerator: make(map[string]string),
+			},
+			Replicas: v1.ReplicaSpec{
+				Num: pointy.Int(1),
+			},
+			DeploymentOptions: map[string]interface{}{},
+			Variables:         map[string]interface{}{},
+		},
+	}
+
+	mockDashboard := dashboardmocks.NewMockDashboardService(t)
+	mockStorage := storagemocks.NewMockStorage(t)
+
+	mockAcceleratorMgr := acceleratormocks.NewMockManager(t)
+	mockAcceleratorMgr.EXPECT().GetEngineContainerRunOptions(mock.Anything).Return([]string{"--runtime=nvidia", "--gpus all"}, nil).Maybe()
+	mockAcceleratorMgr.EXPECT().GetAllConverters().Return(map[string]plugin.ResourceConverter{}).Maybe()
+	mockAcceleratorMgr.EXPECT().GetAllParsers().Return(map[string]resourceparser.ResourceParser{}).Maybe()
+
+	o, ctx := newTestRayOrchestratorCtx(mockStorage, mockDashboard, endpoint, mockAcceleratorMgr)
+
+	deployedApps, err := EndpointToApplications(ctx.Endpoint, ctx.Cluster, ctx.ModelRegistry, ctx.Engine, ctx.ImageRegistry, o.acceleratorMgr)
+	require.NoError(t, err)
+
+	applications := map[string]dashboard.RayServeApplicationStatus{}
+	for i := range deployedApps {
+		applications[deployedApps[i].Name] = dashboard.RayServeApplicationStatus{Status: "RUNNING", DeployedAppConfig: &deployedApps[i]}
+	}
+
+	mockDashboard.On("GetServeApplications").Return(&dashboard.RayServeApplicationsResponse{Applications: applications}, nil)
+
+	modelHash := endpointModelHashForTest(t, endpoint)
+
+	// Shifting the traffic to the canary version only changes the gateway routing, the serve
+	// applications are kept as deployed.
+	endpoint.Spec.Model.Weight = intPtr(50)
+	endpoint.Spec.Models[0].Weight = intPtr(50)
+
+	assert.NoError(t, o.createOrUpdate(ctx))
+	mockDashboard.AssertNotCalled(t, "UpdateServeApplications", mock.Anything)
+	assert.Equal(t, modelHash, endpointModelHashForTest(t, endpoint))
+}
+
 func TestRayOrchestrator_deleteEndpoint(t *testing.T) {
 	endpoint := &v1.Endpoint{
 		Metadata: &v1.Metadata{
@@ -846,20 +992,854 @@ func TestEndpointToApplication_ImportPathStripsVariantSuffix(t *testing.T) {
 				},
 			}
 
-			modelRegistry := &v1.ModelRegistry{
-				Spec: &v1.ModelRegistrySpec{
-					Type: v1.HuggingFaceModelRegistryType,
-				},
+			modelRegistry := &v1.ModelRegistry{
+				Spec: &v1.ModelRegistrySpec{
+					Type: v1.HuggingFaceModelRegistryType,
+				},
+			}
+
+			app, err := EndpointToApplication(endpoint, &v1.Cluster{}, modelRegistry, nil, nil, nil)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedImportPath, app.ImportPath)
+		})
+	}
+}
+
+func TestEndpointToApplication_SchedulerAliasRoundrobinToPow2(t *testing.T) {
+	endpoint := &v1.Endpoint{
+		Metadata: &v1.Metadata{
+			Name:      "ep",
+			Workspace: "ws",
+		},
+		Spec: &v1.EndpointSpec{
+			Engine: &v1.EndpointEngineSpec{
+				Engine:  "vllm",
+				Version: "v0.8.5",
+			},
+			Model: &v1.ModelSpec{
+				Name:    "m",
+				Version: "v1",
+				Task:    "text-generation",
+			},
+			Resources: &v1.ResourceSpec{},
+			Replicas:  v1.ReplicaSpec{Num: intPtr(1)},
+			DeploymentOptions: map[string]interface{}{
+				"scheduler": map[string]interface{}{
+					"type": "roundrobin",
+				},
+			},
+			Env: map[string]string{},
+		},
+	}
+
+	cluster := &v1.Cluster{}
+	modelRegistry := &v1.ModelRegistry{
+		Spec: &v1.ModelRegistrySpec{
+			Type: v1.BentoMLModelRegistryType,
+			Url:  "",
+		},
+	}
+
+	app, err := EndpointToApplication(endpoint, cluster, modelRegistry, nil, nil, nil)
+	assert.NoError(t, err)
+
+	deploymentOptions := app.Args["deployment_options"].(map[string]interface{})
+	scheduler := deploymentOptions["scheduler"].(map[string]interface{})
+	assert.Equal(t, "pow2", scheduler["type"])
+}
+
+func TestEndpointToApplication_ModelDownloaderRetryEnv(t *testing.T) {
+	endpoint := &v1.Endpoint{
+		Metadata: &v1.Metadata{
+			Name:      "ep",
+			Workspace: "ws",
+		},
+		Spec: &v1.EndpointSpec{
+			Engine: &v1.EndpointEngineSpec{
+				Engine:  "vllm",
+				Version: "v0.8.5",
+			},
+			Model: &v1.ModelSpec{
+				Name:    "m",
+				Version: "v1",
+				Task:    "text-generation",
+			},
+			Resources: &v1.ResourceSpec{},
+			Replicas:  v1.ReplicaSpec{Num: intPtr(1)},
+			DeploymentOptions: map[string]interface{}{
+				"model_downloader": map[string]interface{}{
+					"image_pull_policy":     "IfNotPresent",
+					"retries":               float64(5),
+					"retry_backoff_seconds": float64(3),
+					"max_concurrency":       float64(2),
+				},
+			},
+			Env: map[string]string{},
+		},
+	}
+
+	cluster := &v1.Cluster{}
+	modelRegistry := &v1.ModelRegistry{
+		Spec: &v1.ModelRegistrySpec{
+			Type: v1.BentoMLModelRegistryType,
+			Url:  "",
+		},
+	}
+
+	app, err := EndpointToApplication(endpoint, cluster, modelRegistry, nil, nil, nil)
+	require.NoError(t, err)
+
+	envVars := app.RuntimeEnv["env_vars"].(map[string]string)
+	assert.Equal(t, "5", envVars[modelDownloaderRetriesEnv])
+	assert.Equal(t, "3", envVars[modelDownloaderRetryBackoffEnv])
+	assert.Equal(t, "2", envVars[modelDownloaderMaxConcurrencyEnv])
+
+	endpoint.Spec.DeploymentOptions["model_downloader"] = map[string]interface{}{"image_pull_policy": "Sometimes"}
+	_, err = EndpointToApplication(endpoint, cluster, modelRegistry, nil, nil, nil)
+	assert.Error(t, err)
+}
+
+func TestEndpointToApplication_RouterOptions(t *testing.T) {
+	endpoint := &v1.Endpoint{
+		Metadata: &v1.Metadata{
+			Name:      "ep",
+			Workspace: "ws",
+		},
+		Spec: &v1.EndpointSpec{
+			Engine: &v1.EndpointEngineSpec{
+				Engine:  "vllm",
+				Version: "v0.8.5",
+			},
+			Model: &v1.ModelSpec{
+				Name:    "m",
+				Version: "v1",
+				Task:    "text-generation",
+			},
+			Resources: &v1.ResourceSpec{},
+			Replicas:  v1.ReplicaSpec{Num: intPtr(1)},
+			DeploymentOptions: map[string]interface{}{
+				"router": map[string]interface{}{
+					"retries": float64(2),
+					"circuit_breaker": map[string]interface{}{
+						"failure_threshold": float64(3),
+					},
+				},
+			},
+			Env: map[string]string{},
+		},
+	}
+
+	cluster := &v1.Cluster{}
+	modelRegistry := &v1.ModelRegistry{
+		Spec: &v1.ModelRegistrySpec{
+			Type: v1.BentoMLModelRegistryType,
+			Url:  "",
+		},
+	}
+
+	app, err := EndpointToApplication(endpoint, cluster, modelRegistry, nil, nil, nil)
+	require.NoError(t, err)
+
+	deploymentOptions := app.Args["deployment_options"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{
+		"retries": 2,
+		"circuit_breaker": map[string]interface{}{
+			"failure_threshold": 3,
+			"open_seconds":      float64(defaultRouterCircuitOpenSeconds),
+		},
+	}, deploymentOptions["router"])
+
+	endpoint.Spec.DeploymentOptions["router"] = map[string]interface{}{
+		"deduplication": map[string]interface{}{"ttl_seconds": float64(300)},
+	}
+	app, err = EndpointToApplication(endpoint, cluster, modelRegistry, nil, nil, nil)
+	require.NoError(t, err)
+
+	deploymentOptions = app.Args["deployment_options"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{
+		"ttl_seconds": float64(300),
+		"max_entries": defaultRouterDeduplicationMaxEntries,
+	}, deploymentOptions["router"].(map[string]interface{})["deduplication"])
+	assert.NotContains(t, deploymentOptions["router"], "image_fetch")
+
+	endpoint.Spec.DeploymentOptions["router"] = map[string]interface{}{
+		"image_fetch": map[string]interface{}{},
+	}
+	app, err = EndpointToApplication(endpoint, cluster, modelRegistry, nil, nil, nil)
+	require.NoError(t, err)
+
+	deploymentOptions = app.Args["deployment_options"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{
+		"max_bytes":       defaultRouterImageFetchMaxBytes,
+		"timeout_seconds": float64(defaultRouterImageFetchTimeoutSeconds),
+	}, deploymentOptions["router"].(map[string]interface{})["image_fetch"])
+
+	endpoint.Spec.DeploymentOptions["router"] = map[string]interface{}{
+		"image_fetch": map[string]interface{}{"max_bytes": float64(1 << 20), "timeout_seconds": float64(5)},
+	}
+	app, err = EndpointToApplication(endpoint, cluster, modelRegistry, nil, nil, nil)
+	require.NoError(t, err)
+
+	deploymentOptions = app.Args["deployment_options"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{
+		"max_bytes":       1 << 20,
+		"timeout_seconds": float64(5),
+	}, deploymentOptions["router"].(map[string]interface{})["image_fetch"])
+
+	invalid := []map[string]interface{}{
+		{"retries": float64(-1)},
+		{"retries": 1.5},
+		{"request_timeout_seconds": float64(0)},
+		{"request_timeout_seconds": "60"},
+		{"circuit_breaker": "on"},
+		{"circuit_breaker": map[string]interface{}{"failure_threshold": "3"}},
+		{"circuit_breaker": map[string]interface{}{"open_seconds": float64(0)}},
+		{"deduplication": true},
+		{"deduplication": map[string]interface{}{"max_entries": float64(10)}},
+		{"deduplication": map[string]interface{}{"ttl_seconds": float64(0)}},
+		{"deduplication": map[string]interface{}{"ttl_seconds": float64(60), "max_entries": float64(0)}},
+		{"image_fetch": true},
+		{"image_fetch": map[string]interface{}{"max_bytes": float64(0)}},
+		{"image_fetch": map[string]interface{}{"max_bytes": 1.5}},
+		{"image_fetch": map[string]interface{}{"timeout_seconds": float64(-1)}},
+	}
+
+	for _, router := range invalid {
+		endpoint.Spec.DeploymentOptions["router"] = router
+		_, err = EndpointToApplication(endpoint, cluster, modelRegistry, nil, nil, nil)
+		assert.Error(t, err, "router options %v", router)
+	}
+}
+
+func TestGetRouterOptions_RequestTimeout(t *testing.T) {
+	tests := []struct {
+		name   string
+		router interface{}
+		expect map[string]interface{}
+	}{
+		{
+			name: "router not configured",
+		},
+		{
+			name:   "router configured without timeout leaves requests unbounded",
+			router: map[string]interface{}{"retries": float64(1)},
+			expect: map[string]interface{}{
+				"retries": 1,
+				"circuit_breaker": map[string]interface{}{
+					"failure_threshold": 0,
+					"open_seconds":      float64(defaultRouterCircuitOpenSeconds),
+				},
+			},
+		},
+		{
+			name:   "router configured with timeout",
+			router: map[string]interface{}{"request_timeout_seconds": float64(120)},
+			expect: map[string]interface{}{
+				"retries":                 0,
+				"request_timeout_seconds": float64(120),
+				"circuit_breaker": map[string]interface{}{
+					"failure_threshold": 0,
+					"open_seconds":      float64(defaultRouterCircuitOpenSeconds),
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endpoint := &v1.Endpoint{Spec: &v1.EndpointSpec{
+				Model:             &v1.ModelSpec{Task: v1.TextEmbeddingModelTask},
+				DeploymentOptions: map[string]interface{}{},
+			}}
+			if tt.router != nil {
+				endpoint.Spec.DeploymentOptions["router"] = tt.router
+			}
+
+			opts, err := getRouterOptions(endpoint)
+			require.NoError(t, err)
+
+			if tt.expect == nil {
+				assert.Nil(t, opts)
+				return
+			}
+
+			assert.Equal(t, tt.expect, opts.Args())
+		})
+	}
+}
+
+func TestEndpointToApplication_CompressionOptions(t *testing.T) {
+	endpoint := &v1.Endpoint{
+		Metadata: &v1.Metadata{
+			Name:      "ep",
+			Workspace: "ws",
+		},
+		Spec: &v1.EndpointSpec{
+			Engine: &v1.EndpointEngineSpec{
+				Engine:  "vllm",
+				Version: "v0.8.5",
+			},
+			Model: &v1.ModelSpec{
+				Name:    "m",
+				Version: "v1",
+				Task:    "text-embedding",
+			},
+			Resources:         &v1.ResourceSpec{},
+			Replicas:          v1.ReplicaSpec{Num: intPtr(1)},
+			DeploymentOptions: map[string]interface{}{},
+			Env:               map[string]string{},
+		},
+	}
+
+	cluster := &v1.Cluster{}
+	modelRegistry := &v1.ModelRegistry{
+		Spec: &v1.ModelRegistrySpec{
+			Type: v1.BentoMLModelRegistryType,
+			Url:  "",
+		},
+	}
+
+	// compression is disabled and the router passes requests through when not configured.
+	app, err := EndpointToApplication(endpoint, cluster, modelRegistry, nil, nil, nil)
+	require.NoError(t, err)
+
+	envVars := app.RuntimeEnv["env_vars"].(map[string]string)
+	assert.NotContains(t, envVars, compressionRequestEnv)
+	assert.NotContains(t, envVars, compressionResponseEnv)
+
+	endpoint.Spec.DeploymentOptions["compression"] = map[string]interface{}{
+		"request":        true,
+		"response":       true,
+		"min_size_bytes": float64(512),
+	}
+
+	app, err = EndpointToApplication(endpoint, cluster, modelRegistry, nil, nil, nil)
+	require.NoError(t, err)
+
+	envVars = app.RuntimeEnv["env_vars"].(map[string]string)
+	assert.Equal(t, "true", envVars[compressionRequestEnv])
+	assert.Equal(t, "true", envVars[compressionResponseEnv])
+	assert.Equal(t, "512", envVars[compressionMinSizeEnv])
+
+	endpoint.Spec.DeploymentOptions["compression"] = map[string]interface{}{"response": true}
+
+	app, err = EndpointToApplication(endpoint, cluster, modelRegistry, nil, nil, nil)
+	require.NoError(t, err)
+
+	envVars = app.RuntimeEnv["env_vars"].(map[string]string)
+	assert.Equal(t, "false", envVars[compressionRequestEnv])
+	assert.Equal(t, "true", envVars[compressionResponseEnv])
+	assert.NotContains(t, envVars, compressionMinSizeEnv)
+
+	invalid := []interface{}{
+		"gzip",
+		map[string]interface{}{"request": "yes"},
+		map[string]interface{}{"min_size_bytes": float64(-1)},
+		map[string]interface{}{"min_size_bytes": 1.5},
+	}
+
+	for _, compression := range invalid {
+		endpoint.Spec.DeploymentOptions["compression"] = compression
+		_, err = EndpointToApplication(endpoint, cluster, modelRegistry, nil, nil, nil)
+		assert.Error(t, err, "compression options %v", compression)
+	}
+}
+
+func TestEndpointToApplication_TracingOptions(t *testing.T) {
+	endpoint := &v1.Endpoint{
+		Metadata: &v1.Metadata{
+			Name:      "ep",
+			Workspace: "ws",
+		},
+		Spec: &v1.EndpointSpec{
+			Engine: &v1.EndpointEngineSpec{
+				Engine:  "vllm",
+				Version: "v0.8.5",
+			},
+			Model: &v1.ModelSpec{
+				Name:    "m",
+				Version: "v1",
+				Task:    "text-generation",
+			},
+			Resources:         &v1.ResourceSpec{},
+			Replicas:          v1.ReplicaSpec{Num: intPtr(1)},
+			DeploymentOptions: map[string]interface{}{},
+			Env:               map[string]string{},
+		},
+	}
+
+	cluster := &v1.Cluster{}
+	modelRegistry := &v1.ModelRegistry{
+		Spec: &v1.ModelRegistrySpec{
+			Type: v1.BentoMLModelRegistryType,
+			Url:  "",
+		},
+	}
+
+	// the router neither propagates trace context nor exports spans when not configured.
+	app, err := EndpointToApplication(endpoint, cluster, modelRegistry, nil, nil, nil)
+	require.NoError(t, err)
+
+	envVars := app.RuntimeEnv["env_vars"].(map[string]string)
+	assert.NotContains(t, envVars, tracingOTLPEndpointEnv)
+	assert.NotContains(t, envVars, tracingSampleRatioEnv)
+
+	endpoint.Spec.DeploymentOptions["tracing"] = map[string]interface{}{
+		"otlp_endpoint": "http://otel-collector.observability:4318/",
+		"sample_ratio":  0.25,
+	}
+
+	app, err = EndpointToApplication(endpoint, cluster, modelRegistry, nil, nil, nil)
+	require.NoError(t, err)
+
+	envVars = app.RuntimeEnv["env_vars"].(map[string]string)
+	assert.Equal(t, "http://otel-collector.observability:4318", envVars[tracingOTLPEndpointEnv])
+	assert.Equal(t, "0.25", envVars[tracingSampleRatioEnv])
+
+	endpoint.Spec.DeploymentOptions["tracing"] = map[string]interface{}{"otlp_endpoint": "https://otlp.example.com"}
+
+	app, err = EndpointToApplication(endpoint, cluster, modelRegistry, nil, nil, nil)
+	require.NoError(t, err)
+
+	envVars = app.RuntimeEnv["env_vars"].(map[string]string)
+	assert.Equal(t, "https://otlp.example.com", envVars[tracingOTLPEndpointEnv])
+	assert.NotContains(t, envVars, tracingSampleRatioEnv)
+
+	invalid := map[string]interface{}{
+		"not an object":      "http://otel-collector:4318",
+		"missing endpoint":   map[string]interface{}{"sample_ratio": 0.5},
+		"not an http url":    map[string]interface{}{"otlp_endpoint": "otel-collector:4317"},
+		"ratio out of range": map[string]interface{}{"otlp_endpoint": "http://otel-collector:4318", "sample_ratio": 1.5},
+		"unknown key":        map[string]interface{}{"otlp_endpoint": "http://otel-collector:4318", "protocol": "grpc"},
+	}
+
+	for name, tracing := range invalid {
+		endpoint.Spec.DeploymentOptions["tracing"] = tracing
+		_, err = EndpointToApplication(endpoint, cluster, modelRegistry, nil, nil, nil)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestEndpointToApplication_AccessLogOptions(t *testing.T) {
+	endpoint := &v1.Endpoint{
+		Metadata: &v1.Metadata{
+			Name:      "ep",
+			Workspace: "ws",
+		},
+		Spec: &v1.EndpointSpec{
+			Engine: &v1.EndpointEngineSpec{
+				Engine:  "vllm",
+				Version: "v0.8.5",
+			},
+			Model: &v1.ModelSpec{
+				Name:    "m",
+				Version: "v1",
+				Task:    "text-generation",
+			},
+			Resources:         &v1.ResourceSpec{},
+			Replicas:          v1.ReplicaSpec{Num: intPtr(1)},
+			DeploymentOptions: map[string]interface{}{},
+			Env:               map[string]string{},
+		},
+	}
+
+	cluster := &v1.Cluster{}
+	modelRegistry := &v1.ModelRegistry{
+		Spec: &v1.ModelRegistrySpec{
+			Type: v1.BentoMLModelRegistryType,
+			Url:  "",
+		},
+	}
+
+	// the router does not log requests when not configured.
+	app, err := EndpointToApplication(endpoint, cluster, modelRegistry, nil, nil, nil)
+	require.NoError(t, err)
+
+	envVars := app.RuntimeEnv["env_vars"].(map[string]string)
+	assert.NotContains(t, envVars, accessLogSinkEnv)
+
+	endpoint.Spec.DeploymentOptions["access_log"] = map[string]interface{}{"sink": "stdout"}
+
+	app, err = EndpointToApplication(endpoint, cluster, modelRegistry, nil, nil, nil)
+	require.NoError(t, err)
+
+	envVars = app.RuntimeEnv["env_vars"].(map[string]string)
+	assert.Equal(t, "stdout", envVars[accessLogSinkEnv])
+	assert.NotContains(t, envVars, accessLogURLEnv)
+	assert.NotContains(t, envVars, accessLogFieldsEnv)
+	assert.NotContains(t, envVars, accessLogSampleRatioEnv)
+
+	endpoint.Spec.DeploymentOptions["access_log"] = map[string]interface{}{
+		"sink":         "object_store",
+		"url":          "https://logs.example.com/neutree/",
+		"fields":       []interface{}{"timestamp", "api_key", "model", "total_tokens"},
+		"sample_ratio": 0.1,
+	}
+
+	app, err = EndpointToApplication(endpoint, cluster, modelRegistry, nil, nil, nil)
+	require.NoError(t, err)
+
+	envVars = app.RuntimeEnv["env_vars"].(map[string]string)
+	assert.Equal(t, "object_store", envVars[accessLogSinkEnv])
+	assert.Equal(t, "https://logs.example.com/neutree", envVars[accessLogURLEnv])
+	assert.Equal(t, "timestamp,api_key,model,total_tokens", envVars[accessLogFieldsEnv])
+	assert.Equal(t, "0.1", envVars[accessLogSampleRatioEnv])
+
+	invalid := map[string]interface{}{
+		"not an object":          "stdout",
+		"missing sink":           map[string]interface{}{"sample_ratio": 0.5},
+		"unknown sink":           map[string]interface{}{"sink": "syslog"},
+		"object store no url":    map[string]interface{}{"sink": "object_store"},
+		"url with stdout":        map[string]interface{}{"sink": "stdout", "url": "https://logs.example.com"},
+		"not an http url":        map[string]interface{}{"sink": "object_store", "url": "s3://bucket/logs"},
+		"unknown field":          map[string]interface{}{"sink": "stdout", "fields": []interface{}{"model", "prompt"}},
+		"empty fields":           map[string]interface{}{"sink": "stdout", "fields": []interface{}{}},
+		"ratio out of range":     map[string]interface{}{"sink": "stdout", "sample_ratio": -0.1},
+		"unknown key":            map[string]interface{}{"sink": "stdout", "format": "json"},
+		"fields not a list":      map[string]interface{}{"sink": "stdout", "fields": "model"},
+		"sample ratio not float": map[string]interface{}{"sink": "stdout", "sample_ratio": "all"},
+	}
+
+	for name, accessLog := range invalid {
+		endpoint.Spec.DeploymentOptions["access_log"] = accessLog
+		_, err = EndpointToApplication(endpoint, cluster, modelRegistry, nil, nil, nil)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestModelCachePath_ConsistentAcrossOrchestrators(t *testing.T) {
+	tests := []struct {
+		name         string
+		registryType v1.ModelRegistryType
+		registryURL  string
+		modelCaches  []v1.ModelCache
+		expected     string
+	}{
+		{
+			name:         "default cache",
+			registryType: v1.HuggingFaceModelRegistryType,
+			registryURL:  "https://huggingface.co",
+			expected:     "default/qwen/Qwen3-8B/v1",
+		},
+		{
+			name:         "cache without registry path",
+			registryType: v1.HuggingFaceModelRegistryType,
+			registryURL:  "https://huggingface.co",
+			modelCaches:  []v1.ModelCache{{Name: "shared"}},
+			expected:     "shared/qwen/Qwen3-8B/v1",
+		},
+		{
+			name:         "hugging face registry path",
+			registryType: v1.HuggingFaceModelRegistryType,
+			registryURL:  "https://huggingface.co",
+			modelCaches: []v1.ModelCache{{Name: "shared", RegistryPaths: map[v1.ModelRegistryType]string{
+				v1.HuggingFaceModelRegistryType: "hf",
+				v1.BentoMLModelRegistryType:     "bentoml",
+			}}},
+			expected: "shared/hf/qwen/Qwen3-8B/v1",
+		},
+		{
+			name:         "bentoml registry path",
+			registryType: v1.BentoMLModelRegistryType,
+			registryURL:  "nfs://10.0.0.1/bentoml",
+			modelCaches: []v1.ModelCache{{Name: "shared", RegistryPaths: map[v1.ModelRegistryType]string{
+				v1.HuggingFaceModelRegistryType: "hf",
+				v1.BentoMLModelRegistryType:     "bentoml",
+			}}},
+			expected: "shared/bentoml/qwen/Qwen3-8B/v1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endpoint := &v1.Endpoint{
+				Metadata: &v1.Metadata{Name: "ep", Workspace: "ws"},
+				Spec: &v1.EndpointSpec{
+					Engine:            &v1.EndpointEngineSpec{Engine: "vllm", Version: "v0.8.5"},
+					Model:             &v1.ModelSpec{Name: "qwen/Qwen3-8B", Version: "v1", Task: "text-generation"},
+					Resources:         &v1.ResourceSpec{},
+					Replicas:          v1.ReplicaSpec{Num: intPtr(1)},
+					DeploymentOptions: map[string]interface{}{},
+					Env:               map[string]string{},
+				},
+			}
+			modelRegistry := &v1.ModelRegistry{
+				Metadata: &v1.Metadata{Name: "registry"},
+				Spec:     &v1.ModelRegistrySpec{Type: tt.registryType, Url: tt.registryURL},
+			}
+
+			rayCluster := &v1.Cluster{Spec: &v1.ClusterSpec{Type: v1.SSHClusterType, Config: &v1.ClusterConfig{ModelCaches: tt.modelCaches}}}
+
+			app, err := EndpointToApplication(endpoint, rayCluster, modelRegistry, nil, nil, nil)
+			require.NoError(t, err)
+
+			rayPath, err := filepath.Rel(v1.DefaultSSHClusterModelCacheMountPath, app.Args["model"].(map[string]interface{})["path"].(string))
+			require.NoError(t, err)
+
+			k8sCluster := &v1.Cluster{Spec: &v1.ClusterSpec{Type: v1.KubernetesClusterType, Config: &v1.ClusterConfig{ModelCaches: tt.modelCaches}}}
+			data := &DeploymentManifestVariables{Env: map[string]string{}, ModelArgs: map[string]interface{}{}}
+
+			require.NoError(t, (&kubernetesOrchestrator{}).setModelRegistryVariables(data, endpoint, k8sCluster, modelRegistry))
+
+			k8sPath, err := filepath.Rel(v1.DefaultK8sClusterModelCacheMountPath, data.ModelArgs["path"].(string))
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expected, rayPath)
+			assert.Equal(t, tt.expected, k8sPath)
+		})
+	}
+}
+
+func TestEndpointToApplication_HealthCheckOptions(t *testing.T) {
+	tests := []struct {
+		name              string
+		deploymentOptions map[string]interface{}
+		expected          map[string]interface{}
+		expectErr         string
+	}{
+		{
+			name:              "ray defaults when not configured",
+			deploymentOptions: map[string]interface{}{},
+			expected:          map[string]interface{}{},
+		},
+		{
+			name: "period and timeout are mapped to the backend deployment",
+			deploymentOptions: map[string]interface{}{
+				"health_check_period_s":  float64(5),
+				"health_check_timeout_s": 60,
+			},
+			expected: map[string]interface{}{
+				"health_check_period_s":  float64(5),
+				"health_check_timeout_s": float64(60),
+			},
+		},
+		{
+			name:              "fractional period",
+			deploymentOptions: map[string]interface{}{"health_check_period_s": 0.5},
+			expected:          map[string]interface{}{"health_check_period_s": 0.5},
+		},
+		{
+			name:              "negative period is rejected",
+			deploymentOptions: map[string]interface{}{"health_check_period_s": float64(-1)},
+			expectErr:         "deployment_options.health_check_period_s must be a non-negative number",
+		},
+		{
+			name:              "non-numeric timeout is rejected",
+			deploymentOptions: map[string]interface{}{"health_check_timeout_s": "30s"},
+			expectErr:         "deployment_options.health_check_timeout_s must be a non-negative number",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endpoint := &v1.Endpoint{
+				Metadata: &v1.Metadata{Name: "ep", Workspace: "ws"},
+				Spec: &v1.EndpointSpec{
+					Engine:            &v1.EndpointEngineSpec{Engine: "vllm", Version: "v0.8.5"},
+					Model:             &v1.ModelSpec{Name: "m", Version: "v1", Task: "text-generation"},
+					Resources:         &v1.ResourceSpec{},
+					Replicas:          v1.ReplicaSpec{Num: intPtr(1)},
+					DeploymentOptions: tt.deploymentOptions,
+				},
+			}
+			modelRegistry := &v1.ModelRegistry{Spec: &v1.ModelRegistrySpec{Type: v1.BentoMLModelRegistryType}}
+
+			app, err := EndpointToApplication(endpoint, &v1.Cluster{}, modelRegistry, nil, nil, nil)
+			if tt.expectErr != "" {
+				require.ErrorContains(t, err, tt.expectErr)
+				return
+			}
+
+			require.NoError(t, err)
+
+			backend := app.Args["deployment_options"].(map[string]interface{})["backend"].(map[string]interface{})
+			for _, key := range []string{"health_check_period_s", "health_check_timeout_s"} {
+				expected, ok := tt.expected[key]
+				if !ok {
+					assert.NotContains(t, backend, key)
+					continue
+				}
+
+				assert.Equal(t, expected, backend[key])
+			}
+		})
+	}
+}
+
+func TestEndpointToApplication_MaxOngoingRequests(t *testing.T) {
+	tests := []struct {
+		name               string
+		maxOngoingRequests *int
+		expected           interface{}
+		expectErr          string
+	}{
+		{
+			name: "ray default when not configured",
+		},
+		{
+			name:               "mapped to the backend deployment",
+			maxOngoingRequests: intPtr(16),
+			expected:           16,
+		},
+		{
+			name:               "zero is rejected",
+			maxOngoingRequests: intPtr(0),
+			expectErr:          "spec.max_ongoing_requests of endpoint ws/ep must be positive",
+		},
+		{
+			name:               "negative is rejected",
+			maxOngoingRequests: intPtr(-1),
+			expectErr:          "spec.max_ongoing_requests of endpoint ws/ep must be positive",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endpoint := &v1.Endpoint{
+				Metadata: &v1.Metadata{Name: "ep", Workspace: "ws"},
+				Spec: &v1.EndpointSpec{
+					Engine:    &v1.EndpointEngineSpec{Engine: "vllm", Version: "v0.8.5"},
+					Model:     &v1.ModelSpec{Name: "m", Version: "v1", Task: "text-generation"},
+					Resources: &v1.ResourceSpec{},
+					Replicas:  v1.ReplicaSpec{Num: intPtr(2)},
+					Variables: map[string]interface{}{
+						"engine_args": map[string]interface{}{"max-concurrency": "64"},
+					},
+					MaxOngoingRequests: tt.maxOngoingRequests,
+				},
+			}
+			modelRegistry := &v1.ModelRegistry{Spec: &v1.ModelRegistrySpec{Type: v1.BentoMLModelRegistryType}}
+
+			app, err := EndpointToApplication(endpoint, &v1.Cluster{}, modelRegistry, nil, nil, nil)
+			if tt.expectErr != "" {
+				require.EqualError(t, err, tt.expectErr)
+				return
+			}
+
+			require.NoError(t, err)
+
+			backend := app.Args["deployment_options"].(map[string]interface{})["backend"].(map[string]interface{})
+			if tt.expected == nil {
+				assert.NotContains(t, backend, "max_ongoing_requests")
+			} else {
+				assert.Equal(t, tt.expected, backend["max_ongoing_requests"])
+			}
+
+			// the engine keeps its own concurrency limit below the serve one
+			assert.Equal(t, map[string]interface{}{"max-concurrency": "64"}, app.Args["engine_args"])
+		})
+	}
+}
+
+func TestEndpointToApplication_TopologyAwarePlacement(t *testing.T) {
+	nvidiaGPU := string(v1.AcceleratorTypeNVIDIAGPU)
+	gpu := func(domain string) *v1.DeviceResource {
+		return &v1.DeviceResource{Health: true, NVLinkDomain: domain}
+	}
+	nvlinkResources := &v1.ClusterResources{
+		NodeResources: map[string]*v1.NodeResourceStatus{
+			"10.0.0.1": {Devices: []*v1.DeviceResource{gpu("0"), gpu("0"), gpu("0"), gpu("0"), gpu("1"), gpu("1")}},
+		},
+	}
+
+	tests := []struct {
+		name          string
+		gpus          string
+		topologyAware interface{}
+		resources     *v1.ClusterResources
+		wantPlacement bool
+	}{
+		{
+			name:          "tensor-parallel endpoint on cluster with NVLink topology",
+			gpus:          "4",
+			topologyAware: true,
+			resources:     nvlinkResources,
+			wantPlacement: true,
+		},
+		{
+			name:          "no topology reported falls back to default placement",
+			gpus:          "4",
+			topologyAware: true,
+		},
+		{
+			name:          "no NVLink domain large enough falls back to default placement",
+			gpus:          "8",
+			topologyAware: true,
+			resources:     nvlinkResources,
+		},
+		{
+			name:          "single GPU replica",
+			gpus:          "1",
+			topologyAware: true,
+			resources:     nvlinkResources,
+		},
+		{
+			name:      "disabled",
+			gpus:      "4",
+			resources: nvlinkResources,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endpoint := &v1.Endpoint{
+				Metadata: &v1.Metadata{Workspace: "default", Name: "tp-ep"},
+				Spec: &v1.EndpointSpec{
+					Engine: &v1.EndpointEngineSpec{Engine: "vllm", Version: "v0.12.0"},
+					Model:  &v1.ModelSpec{Name: "test-model", Task: v1.TextGenerationModelTask},
+					Resources: &v1.ResourceSpec{
+						CPU:         pointer.String("8"),
+						GPU:         pointer.String(tt.gpus),
+						Accelerator: map[string]string{v1.AcceleratorTypeKey: nvidiaGPU},
+					},
+					Replicas:          v1.ReplicaSpec{Num: intPtr(1)},
+					DeploymentOptions: map[string]interface{}{},
+				},
+			}
+			if tt.topologyAware != nil {
+				endpoint.Spec.DeploymentOptions["topology_aware_placement"] = tt.topologyAware
+			}
+
+			cluster := &v1.Cluster{
+				Spec:   &v1.ClusterSpec{Version: "v1.0.0"},
+				Status: &v1.ClusterStatus{AcceleratorType: &nvidiaGPU, ResourceInfo: tt.resources},
+			}
+
+			mgr := acceleratormocks.NewMockManager(t)
+			mgr.EXPECT().GetConverter(nvidiaGPU).Return(plugin.NewGPUConverter(), true)
+
+			app, err := EndpointToApplication(endpoint, cluster, &v1.ModelRegistry{Spec: &v1.ModelRegistrySpec{Type: v1.BentoMLModelRegistryType}},
+				nil, nil, mgr)
+			require.NoError(t, err)
+
+			backend := app.Args["deployment_options"].(map[string]interface{})["backend"].(map[string]interface{})
+			if !tt.wantPlacement {
+				assert.NotContains(t, backend, "placement_group_bundles")
+				assert.NotContains(t, backend, "placement_group_strategy")
+
+				return
 			}
 
-			app, err := EndpointToApplication(endpoint, &v1.Cluster{}, modelRegistry, nil, nil, nil)
-			assert.NoError(t, err)
-			assert.Equal(t, tt.expectedImportPath, app.ImportPath)
+			assert.Equal(t, []map[string]float64{{"CPU": 8, "GPU": 4}}, backend["placement_group_bundles"])
+			assert.Equal(t, "STRICT_PACK", backend["placement_group_strategy"])
 		})
 	}
+
+	endpoint := &v1.Endpoint{
+		Metadata: &v1.Metadata{Workspace: "default", Name: "tp-ep"},
+		Spec: &v1.EndpointSpec{
+			Engine:            &v1.EndpointEngineSpec{Engine: "vllm", Version: "v0.12.0"},
+			Model:             &v1.ModelSpec{Name: "test-model", Task: v1.TextGenerationModelTask},
+			Resources:         &v1.ResourceSpec{},
+			DeploymentOptions: map[string]interface{}{"topology_aware_placement": "yes"},
+		},
+	}
+	_, err := EndpointToApplication(endpoint, &v1.Cluster{}, &v1.ModelRegistry{Spec: &v1.ModelRegistrySpec{Type: v1.BentoMLModelRegistryType}},
+		nil, nil, nil)
+	assert.Error(t, err)
 }
 
-func TestEndpointToApplication_SchedulerAliasRoundrobinToPow2(t *testing.T) {
+func TestEndpointToApplications_MultiModel(t *testing.T) {
 	endpoint := &v1.Endpoint{
 		Metadata: &v1.Metadata{
 			Name:      "ep",
@@ -871,18 +1851,14 @@ func TestEndpointToApplication_SchedulerAliasRoundrobinToPow2(t *testing.T) {
 				Version: "v0.8.5",
 			},
 			Model: &v1.ModelSpec{
-				Name:    "m",
-				Version: "v1",
-				Task:    "text-generation",
+				Registry: "hf",
+				Name:     "Qwen/Qwen3-8B",
+				Version:  "v1",
+				Task:     "text-generation",
 			},
 			Resources: &v1.ResourceSpec{},
 			Replicas:  v1.ReplicaSpec{Num: intPtr(1)},
-			DeploymentOptions: map[string]interface{}{
-				"scheduler": map[string]interface{}{
-					"type": "roundrobin",
-				},
-			},
-			Env: map[string]string{},
+			Env:       map[string]string{},
 		},
 	}
 
@@ -890,16 +1866,158 @@ func TestEndpointToApplication_SchedulerAliasRoundrobinToPow2(t *testing.T) {
 	modelRegistry := &v1.ModelRegistry{
 		Spec: &v1.ModelRegistrySpec{
 			Type: v1.BentoMLModelRegistryType,
-			Url:  "",
 		},
 	}
 
-	app, err := EndpointToApplication(endpoint, cluster, modelRegistry, nil, nil, nil)
-	assert.NoError(t, err)
+	apps, err := EndpointToApplications(endpoint, cluster, modelRegistry, nil, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, apps, 1)
+	assert.Equal(t, "ws_ep", apps[0].Name)
+	assert.Equal(t, "/ws/ep", apps[0].RoutePrefix)
+	assert.Equal(t, "Qwen/Qwen3-8B:v1", apps[0].Args["model"].(map[string]interface{})["serve_name"])
 
-	deploymentOptions := app.Args["deployment_options"].(map[string]interface{})
-	scheduler := deploymentOptions["scheduler"].(map[string]interface{})
-	assert.Equal(t, "pow2", scheduler["type"])
+	endpoint.Spec.Models = []*v1.ModelSpec{
+		{Name: "llama3", Version: "v2", Task: "text-generation"},
+	}
+
+	apps, err = EndpointToApplications(endpoint, cluster, modelRegistry, nil, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, apps, 2)
+
+	assert.Equal(t, "ws_ep_qwen-qwen3-8b", apps[0].Name)
+	assert.Equal(t, "/ws/ep/qwen-qwen3-8b", apps[0].RoutePrefix)
+	assert.Equal(t, "Qwen/Qwen3-8B", apps[0].Args["model"].(map[string]interface{})["serve_name"])
+
+	assert.Equal(t, "ws_ep_llama3", apps[1].Name)
+	assert.Equal(t, "/ws/ep/llama3", apps[1].RoutePrefix)
+	modelArgs := apps[1].Args["model"].(map[string]interface{})
+	assert.Equal(t, "llama3", modelArgs["serve_name"])
+	assert.Equal(t, "llama3", modelArgs["name"])
+	assert.Equal(t, "v2", modelArgs["version"])
+
+	// Every model reports its request metrics under the endpoint.
+	for _, app := range apps {
+		envVars := app.RuntimeEnv["env_vars"].(map[string]string)
+		assert.Equal(t, "ep", envVars[endpointNameEnv])
+		assert.Equal(t, "ws", envVars[endpointWorkspaceEnv])
+	}
+
+	// The endpoint itself is left untouched.
+	assert.Equal(t, "Qwen/Qwen3-8B", endpoint.Spec.Model.Name)
+	assert.Empty(t, endpoint.Spec.Models[0].Registry)
+}
+
+func TestEndpointServeApplicationStatus(t *testing.T) {
+	endpoint := &v1.Endpoint{
+		Metadata: &v1.Metadata{Name: "ep", Workspace: "ws"},
+		Spec: &v1.EndpointSpec{
+			Model:  &v1.ModelSpec{Name: "model-a"},
+			Models: []*v1.ModelSpec{{Name: "model-b"}},
+		},
+	}
+
+	tests := []struct {
+		name           string
+		apps           map[string]dashboard.RayServeApplicationStatus
+		expectExists   bool
+		expectComplete bool
+		expectStatus   string
+		expectMessage  string
+	}{
+		{
+			name: "all models running",
+			apps: map[string]dashboard.RayServeApplicationStatus{
+				"ws_ep_model-a": {Status: dashboard.ApplicationStatusRunning},
+				"ws_ep_model-b": {Status: dashboard.ApplicationStatusRunning},
+			},
+			expectExists:   true,
+			expectComplete: true,
+			expectStatus:   dashboard.ApplicationStatusRunning,
+		},
+		{
+			name: "most severe status wins",
+			apps: map[string]dashboard.RayServeApplicationStatus{
+				"ws_ep_model-a": {Status: dashboard.ApplicationStatusDeployFailed, Message: "oom"},
+				"ws_ep_model-b": {Status: dashboard.ApplicationStatusDeploying},
+			},
+			expectExists:   true,
+			expectComplete: true,
+			expectStatus:   dashboard.ApplicationStatusDeployFailed,
+			expectMessage:  "model model-a: oom",
+		},
+		{
+			name: "missing model application",
+			apps: map[string]dashboard.RayServeApplicationStatus{
+				"ws_ep_model-a": {Status: dashboard.ApplicationStatusRunning},
+			},
+			expectExists:   true,
+			expectComplete: false,
+		},
+		{
+			name: "stale single-model application",
+			apps: map[string]dashboard.RayServeApplicationStatus{
+				"ws_ep": {Status: dashboard.ApplicationStatusRunning},
+			},
+			expectExists:   true,
+			expectComplete: false,
+		},
+		{
+			name: "other endpoint only",
+			apps: map[string]dashboard.RayServeApplicationStatus{
+				"ws_ep-2_model-a": {Status: dashboard.ApplicationStatusRunning},
+			},
+			expectExists:   false,
+			expectComplete: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, exists, complete := endpointServeApplicationStatus(endpoint, tt.apps)
+
+			assert.Equal(t, tt.expectExists, exists)
+			assert.Equal(t, tt.expectComplete, complete)
+
+			if tt.expectComplete {
+				assert.Equal(t, tt.expectStatus, status.Status)
+				assert.Equal(t, tt.expectMessage, status.Message)
+			}
+		})
+	}
+}
+
+func TestRayReplicaStatuses(t *testing.T) {
+	status := dashboard.RayServeApplicationStatus{
+		Deployments: map[string]dashboard.Deployment{
+			"Controller": {
+				Name:     "Controller",
+				Status:   dashboard.DeploymentStatusHealthy,
+				Replicas: []dashboard.Replica{{ReplicaID: "c1", State: "RUNNING"}},
+			},
+			"Backend": {
+				Name:    "Backend",
+				Status:  dashboard.DeploymentStatusUnhealthy,
+				Message: "The deployment failed to start 3 times in a row.",
+				Replicas: []dashboard.Replica{
+					{ReplicaID: "b2", State: "STARTING"},
+					{ReplicaID: "b1", State: "RUNNING"},
+				},
+			},
+		},
+	}
+
+	assert.Equal(t, []v1.ReplicaStatus{
+		{ReplicaID: "b1", Deployment: "Backend", State: v1.ReplicaStateRunning},
+		{
+			ReplicaID:    "b2",
+			Deployment:   "Backend",
+			State:        v1.ReplicaStateStarting,
+			ErrorMessage: "The deployment failed to start 3 times in a row.",
+		},
+		{ReplicaID: "c1", Deployment: "Controller", State: v1.ReplicaStateRunning},
+	}, rayReplicaStatuses(status))
+
+	assert.Nil(t, rayReplicaStatuses(dashboard.RayServeApplicationStatus{}))
 }
 
 func TestEndpointToApplication_ResourceNameNormalization(t *testing.T) {
@@ -1060,8 +2178,11 @@ func TestEndpointToApplication_setModelArgs(t *testing.T) {
 				"path":          filepath.Join(v1.DefaultSSHClusterModelCacheMountPath, v1.DefaultModelCacheRelativePath, "llama-2-7b", "v1.0"),
 				"registry_path": filepath.Join("/mnt", "default", "llama-endpoint", "models", "llama-2-7b", "v1.0"),
 			},
-			expectedEnvs: map[string]string{},
-			wantErr:      false,
+			expectedEnvs: map[string]string{
+				endpointNameEnv:      "llama-endpoint",
+				endpointWorkspaceEnv: "default",
+			},
+			wantErr: false,
 		},
 		{
 			name: "BentoML modelRegistry - specific version - with cluster model cache",
@@ -1114,8 +2235,11 @@ func TestEndpointToApplication_setModelArgs(t *testing.T) {
 				"path":          filepath.Join(v1.DefaultSSHClusterModelCacheMountPath, "test-cache", "llama-2-7b", "v1.0"),
 				"registry_path": filepath.Join("/mnt", "default", "llama-endpoint", "models", "llama-2-7b", "v1.0"),
 			},
-			expectedEnvs: map[string]string{},
-			wantErr:      false,
+			expectedEnvs: map[string]string{
+				endpointNameEnv:      "llama-endpoint",
+				endpointWorkspaceEnv: "default",
+			},
+			wantErr: false,
 		},
 		{
 			name: "BentoML modelRegistry - specific version - with cluster multi model cache - only use the first one",
@@ -1172,8 +2296,11 @@ func TestEndpointToApplication_setModelArgs(t *testing.T) {
 				"path":          filepath.Join(v1.DefaultSSHClusterModelCacheMountPath, "test-cache-1", "llama-2-7b", "v1.0"),
 				"registry_path": filepath.Join("/mnt", "default", "llama-endpoint", "models", "llama-2-7b", "v1.0"),
 			},
-			expectedEnvs: map[string]string{},
-			wantErr:      false,
+			expectedEnvs: map[string]string{
+				endpointNameEnv:      "llama-endpoint",
+				endpointWorkspaceEnv: "default",
+			},
+			wantErr: false,
 		},
 		{
 			name: "HuggingFace modelRegistry - specific version",
@@ -1216,8 +2343,10 @@ func TestEndpointToApplication_setModelArgs(t *testing.T) {
 				"registry_path": "llama-2-7b",
 			},
 			expectedEnvs: map[string]string{
-				v1.HFEndpoint: "https://huggingface.co",
-				v1.HFTokenEnv: "test-token",
+				endpointNameEnv:      "llama-endpoint",
+				endpointWorkspaceEnv: "default",
+				v1.HFEndpoint:        "https://huggingface.co",
+				v1.HFTokenEnv:        "test-token",
 			},
 			wantErr: false,
 		},
@@ -1261,8 +2390,11 @@ func TestEndpointToApplication_setModelArgs(t *testing.T) {
 				"path":          filepath.Join(v1.DefaultSSHClusterModelCacheMountPath, v1.DefaultModelCacheRelativePath, "qwen", "v1.0"),
 				"registry_path": filepath.Join("/mnt", "default", "sglang-endpoint", "models", "qwen", "v1.0"),
 			},
-			expectedEnvs: map[string]string{},
-			wantErr:      false,
+			expectedEnvs: map[string]string{
+				endpointNameEnv:      "sglang-endpoint",
+				endpointWorkspaceEnv: "default",
+			},
+			wantErr: false,
 		},
 		{
 			name: "HuggingFace modelRegistry - without specific version",
@@ -1304,8 +2436,10 @@ func TestEndpointToApplication_setModelArgs(t *testing.T) {
 				"registry_path": "llama-2-7b",
 			},
 			expectedEnvs: map[string]string{
-				v1.HFEndpoint: "https://huggingface.co",
-				v1.HFTokenEnv: "test-token",
+				endpointNameEnv:      "llama-endpoint",
+				endpointWorkspaceEnv: "default",
+				v1.HFEndpoint:        "https://huggingface.co",
+				v1.HFTokenEnv:        "test-token",
 			},
 			wantErr: false,
 		},
@@ -1499,6 +2633,42 @@ func TestBuildEngineContainerConfigs(t *testing.T) {
 				"--rm",
 			},
 		},
+		{
+			name: "pinned engine image is deployed by digest",
+			endpoint: &v1.Endpoint{
+				Spec: &v1.EndpointSpec{
+					Engine:    &v1.EndpointEngineSpec{Engine: "llama-cpp", Version: "v0.3.7", PinImageDigest: true},
+					Resources: &v1.ResourceSpec{},
+				},
+				Status: &v1.EndpointStatus{EngineImageDigest: "sha256:abc"},
+			},
+			engine:        cpuEngine,
+			expectedImage: "neutree/engine-llama-cpp:v0.3.7-ray2.53.0@sha256:abc",
+			expectedBaseOptions: []string{
+				"--rm",
+			},
+			expectedBackendOptions: []string{
+				"--rm",
+			},
+		},
+		{
+			name: "digest is ignored once unpinned",
+			endpoint: &v1.Endpoint{
+				Spec: &v1.EndpointSpec{
+					Engine:    &v1.EndpointEngineSpec{Engine: "llama-cpp", Version: "v0.3.7"},
+					Resources: &v1.ResourceSpec{},
+				},
+				Status: &v1.EndpointStatus{EngineImageDigest: "sha256:abc"},
+			},
+			engine:        cpuEngine,
+			expectedImage: "neutree/engine-llama-cpp:v0.3.7-ray2.53.0",
+			expectedBaseOptions: []string{
+				"--rm",
+			},
+			expectedBackendOptions: []string{
+				"--rm",
+			},
+		},
 		{
 			name:          "nil imageRegistry omits registry prefix",
 			endpoint:      gpuEndpoint("v0.12.0"),
@@ -2450,6 +3620,7 @@ func TestRayOrchestrator_GetEndpointStatus(t *testing.T) {
 		expectCurrentModelHash       bool
 		expectedModelDownloadHash    *string
 		expectModelDownloadHashEmpty bool
+		expectedDownloadProgress     *v1.ModelDownloadProgress
 		expectErrorMsg               string
 		expectError                  bool
 	}{
@@ -2916,6 +4087,46 @@ func TestRayOrchestrator_GetEndpointStatus(t *testing.T) {
 			expectErrorMsg:               "model download in progress",
 			expectError:                  false,
 		},
+		{
+			name: "return ModelDownloading with progress parsed from backend actor log",
+			inputEndpoint: func() *v1.Endpoint {
+				return newEndpoint()
+			},
+			setupMock: func(mockDashboard *dashboardmocks.MockDashboardService) {
+				mockDashboard.On("GetServeApplications").Return(&dashboard.RayServeApplicationsResponse{
+					Applications: map[string]dashboard.RayServeApplicationStatus{
+						applicationName: {
+							Status: "DEPLOYING",
+							Deployments: map[string]dashboard.Deployment{
+								"BACKEND": {
+									Name:   "BACKEND",
+									Status: "UPDATING",
+									Replicas: []dashboard.Replica{
+										{
+											ActorID:   "actor-1",
+											ReplicaID: "backend-replica-1",
+										},
+									},
+								},
+							},
+						},
+					},
+				}, nil)
+				mockDashboard.On("GetActorLog", "actor-1", "out", 200).Return(
+					"NEUTREE_MODEL_DOWNLOAD_START\n"+
+						"NEUTREE_MODEL_DOWNLOAD_PROGRESS downloaded=100 total=400\n"+
+						"NEUTREE_MODEL_DOWNLOAD_PROGRESS downloaded=300 total=400\n", nil)
+				mockDashboard.On("GetActorLog", "actor-1", "err", 200).Return("", nil)
+			},
+			expectedPhase:                v1.EndpointPhaseMODELDOWNLOADING,
+			expectModelDownloadHashEmpty: true,
+			expectedDownloadProgress: &v1.ModelDownloadProgress{
+				DownloadedBytes: 300,
+				TotalBytes:      400,
+				Percent:         float64Ptr(75),
+			},
+			expectErrorMsg: "model download in progress",
+		},
 		{
 			name: "return Deploying and mark model download completed when backend actor log contains done marker",
 			inputEndpoint: func() *v1.Endpoint {
@@ -3040,6 +4251,49 @@ func TestRayOrchestrator_GetEndpointStatus(t *testing.T) {
 			expectErrorMsg:               "model download failed",
 			expectError:                  false,
 		},
+		{
+			name: "return Failed with rate limit message when the model registry rate limited the download",
+			inputEndpoint: func() *v1.Endpoint {
+				return newEndpoint()
+			},
+			setupMock: func(mockDashboard *dashboardmocks.MockDashboardService) {
+				existingApp := &dashboard.RayServeApplication{
+					Name:        applicationName,
+					RoutePrefix: "/production/chat-model",
+					ImportPath:  "old.import.path",
+					Args:        map[string]interface{}{"old": "config"},
+				}
+
+				mockDashboard.On("GetServeApplications").Return(&dashboard.RayServeApplicationsResponse{
+					Applications: map[string]dashboard.RayServeApplicationStatus{
+						applicationName: {
+							Status:            "DEPLOYING",
+							DeployedAppConfig: existingApp,
+							Deployments: map[string]dashboard.Deployment{
+								"BACKEND": {
+									Name:   "BACKEND",
+									Status: "UPDATING",
+									Replicas: []dashboard.Replica{
+										{
+											ActorID:   "actor-1",
+											ReplicaID: "backend-replica-1",
+										},
+									},
+								},
+							},
+						},
+					},
+				}, nil)
+				mockDashboard.On("GetActorLog", "actor-1", "out", 200).
+					Return("NEUTREE_MODEL_DOWNLOAD_START\nNEUTREE_MODEL_DOWNLOAD_RATE_LIMITED retry_after=30\n"+
+						"NEUTREE_MODEL_DOWNLOAD_RATE_LIMITED\nNEUTREE_MODEL_DOWNLOAD_FAILED\n", nil)
+				mockDashboard.On("GetActorLog", "actor-1", "err", 200).Maybe().Return("", nil)
+			},
+			expectedPhase:                v1.EndpointPhaseFAILED,
+			expectModelDownloadHashEmpty: true,
+			expectErrorMsg:               "model download failed: rate limited by model registry",
+			expectError:                  false,
+		},
 		{
 			name: "return Deploying when backend actor is done even if controller actor has no download markers",
 			inputEndpoint: func() *v1.Endpoint {
@@ -3856,6 +5110,7 @@ func TestRayOrchestrator_GetEndpointStatus(t *testing.T) {
 					require.NotNil(t, status.ModelDownloadCompletedHash)
 					assert.Empty(t, *status.ModelDownloadCompletedHash)
 				}
+				assert.Equal(t, tt.expectedDownloadProgress, status.ModelDownloadProgress)
 				if tt.expectErrorMsg != "" {
 					assert.Contains(t, status.ErrorMessage, tt.expectErrorMsg)
 				}
@@ -3867,6 +5122,133 @@ func TestRayOrchestrator_GetEndpointStatus(t *testing.T) {
 	}
 }
 
+func TestRayOrchestrator_GetEndpointStatus_DashboardOutage(t *testing.T) {
+	unreachable := fmt.Errorf("%w: dial tcp 10.0.0.1:8265: connect: connection refused", dashboard.ErrDashboardUnreachable)
+	lastKnown := &v1.EndpointStatus{
+		Phase:      v1.EndpointPhaseRUNNING,
+		ServiceURL: "http://gateway/production/chat-model",
+	}
+
+	tests := []struct {
+		name           string
+		policy         v1.DashboardOutagePolicy
+		lastKnown      *v1.EndpointStatus
+		fetchErr       error
+		expectError    bool
+		expectedPhase  v1.EndpointPhase
+		expectErrorMsg string
+	}{
+		{
+			name:        "fails by default",
+			lastKnown:   lastKnown,
+			fetchErr:    unreachable,
+			expectError: true,
+		},
+		{
+			name:           "unknown keeps the rest of the last known status",
+			policy:         v1.DashboardOutageUnknown,
+			lastKnown:      lastKnown,
+			fetchErr:       unreachable,
+			expectedPhase:  v1.EndpointPhaseUNKNOWN,
+			expectErrorMsg: "Ray dashboard unreachable",
+		},
+		{
+			name:          "last known keeps the last known status",
+			policy:        v1.DashboardOutageLastKnown,
+			lastKnown:     lastKnown,
+			fetchErr:      unreachable,
+			expectedPhase: v1.EndpointPhaseRUNNING,
+		},
+		{
+			name:           "last known without status is unknown",
+			policy:         v1.DashboardOutageLastKnown,
+			fetchErr:       unreachable,
+			expectedPhase:  v1.EndpointPhaseUNKNOWN,
+			expectErrorMsg: "Ray dashboard unreachable",
+		},
+		{
+			name:        "errors of a reachable dashboard still fail",
+			policy:      v1.DashboardOutageLastKnown,
+			lastKnown:   lastKnown,
+			fetchErr:    assert.AnError,
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDashboard := dashboardmocks.NewMockDashboardService(t)
+			mockDashboard.On("GetServeApplications").Return(nil, tt.fetchErr)
+
+			dashboard.NewDashboardService = func(dashboardUrl string) dashboard.DashboardService {
+				return mockDashboard
+			}
+
+			o := &RayOrchestrator{
+				cluster: &v1.Cluster{
+					Metadata: &v1.Metadata{Name: "test-cluster"},
+					Spec: &v1.ClusterSpec{
+						Version: "v1.0.0",
+						Config: &v1.ClusterConfig{
+							SSHConfig: &v1.RaySSHProvisionClusterConfig{DashboardOutage: tt.policy},
+						},
+					},
+					Status: &v1.ClusterStatus{
+						Initialized:  true,
+						DashboardURL: "http://ray-dashboard.example.com:8265",
+					},
+				},
+			}
+
+			endpoint := &v1.Endpoint{
+				Metadata: &v1.Metadata{Workspace: "production", Name: "chat-model"},
+				Spec:     &v1.EndpointSpec{Model: &v1.ModelSpec{Name: "test-model"}},
+				Status:   tt.lastKnown,
+			}
+
+			status, err := o.GetEndpointStatus(endpoint)
+			if tt.expectError {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedPhase, status.Phase)
+
+			if tt.lastKnown != nil {
+				assert.Equal(t, tt.lastKnown.ServiceURL, status.ServiceURL)
+				// The last known status of the endpoint is copied, not modified.
+				assert.Equal(t, v1.EndpointPhaseRUNNING, tt.lastKnown.Phase)
+			}
+
+			if tt.expectErrorMsg != "" {
+				assert.Contains(t, status.ErrorMessage, tt.expectErrorMsg)
+			} else {
+				assert.Empty(t, status.ErrorMessage)
+			}
+		})
+	}
+}
+
+func TestTolerateDashboardOutage(t *testing.T) {
+	unreachable := fmt.Errorf("%w: connection refused", dashboard.ErrDashboardUnreachable)
+	newCtx := func(policy v1.DashboardOutagePolicy) *OrchestratorContext {
+		return &OrchestratorContext{
+			Cluster: &v1.Cluster{Spec: &v1.ClusterSpec{Config: &v1.ClusterConfig{
+				SSHConfig: &v1.RaySSHProvisionClusterConfig{DashboardOutage: policy},
+			}}},
+			logger: klog.Background(),
+		}
+	}
+
+	assert.ErrorIs(t, tolerateDashboardOutage(newCtx(""), unreachable), dashboard.ErrDashboardUnreachable)
+	// The sync is retried on the next resync instead of failing the endpoint.
+	assert.NoError(t, tolerateDashboardOutage(newCtx(v1.DashboardOutageUnknown), unreachable))
+	assert.NoError(t, tolerateDashboardOutage(newCtx(v1.DashboardOutageLastKnown), unreachable))
+	assert.ErrorIs(t, tolerateDashboardOutage(newCtx(v1.DashboardOutageLastKnown), assert.AnError), assert.AnError)
+	assert.NoError(t, tolerateDashboardOutage(newCtx(v1.DashboardOutageLastKnown), nil))
+}
+
 // TestRayOrchestrator_prepareOrchestratorContextForPauseDelete_ToleratesMissingDeps
 // verifies that the lite preparation does NOT fetch engine/model-registry/
 // image-registry from storage — this is what lets pause/delete on Ray
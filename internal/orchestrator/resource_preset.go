@@ -0,0 +1,64 @@
+package orchestrator
+
+import (
+	"maps"
+
+	"github.com/pkg/errors"
+
+	v1 "github.com/neutree-ai/neutree/api/v1"
+)
+
+// expandResourcePreset returns the endpoint with its resources expanded from the resource
+// preset of the deploy cluster they reference. Resource values set on the endpoint take
+// precedence over the preset ones. Endpoints without a preset are returned as is.
+func expandResourcePreset(endpoint *v1.Endpoint, cluster *v1.Cluster) (*v1.Endpoint, error) {
+	if endpoint.Spec == nil || endpoint.Spec.Resources == nil || endpoint.Spec.Resources.Preset == "" {
+		return endpoint, nil
+	}
+
+	resources := endpoint.Spec.Resources
+
+	var presets map[string]v1.ResourceSpec
+	if cluster.Spec != nil && cluster.Spec.Config != nil {
+		presets = cluster.Spec.Config.ResourcePresets
+	}
+
+	preset, ok := presets[resources.Preset]
+	if !ok {
+		return nil, errors.Errorf("resource preset %s not found in cluster %s",
+			resources.Preset, cluster.Metadata.WorkspaceName())
+	}
+
+	expanded := &v1.ResourceSpec{
+		CPU:    preset.CPU,
+		GPU:    preset.GPU,
+		Memory: preset.Memory,
+		Preset: resources.Preset,
+	}
+
+	if resources.CPU != nil {
+		expanded.CPU = resources.CPU
+	}
+
+	if resources.GPU != nil {
+		expanded.GPU = resources.GPU
+	}
+
+	if resources.Memory != nil {
+		expanded.Memory = resources.Memory
+	}
+
+	if len(preset.Accelerator) > 0 || len(resources.Accelerator) > 0 {
+		expanded.Accelerator = make(map[string]string, len(preset.Accelerator)+len(resources.Accelerator))
+		maps.Copy(expanded.Accelerator, preset.Accelerator)
+		maps.Copy(expanded.Accelerator, resources.Accelerator)
+	}
+
+	spec := *endpoint.Spec
+	spec.Resources = expanded
+
+	expandedEndpoint := *endpoint
+	expandedEndpoint.Spec = &spec
+
+	return &expandedEndpoint, nil
+}
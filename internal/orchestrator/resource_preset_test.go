@@ -0,0 +1,107 @@
+package orchestrator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.openly.dev/pointy"
+
+	v1 "github.com/neutree-ai/neutree/api/v1"
+)
+
+func TestExpandResourcePreset(t *testing.T) {
+	cluster := &v1.Cluster{
+		Metadata: &v1.Metadata{Name: "cluster-a", Workspace: "default"},
+		Spec: &v1.ClusterSpec{
+			Config: &v1.ClusterConfig{
+				ResourcePresets: map[string]v1.ResourceSpec{
+					"small": {CPU: pointy.String("2"), Memory: pointy.String("8")},
+					"large": {
+						CPU:         pointy.String("16"),
+						GPU:         pointy.String("2"),
+						Memory:      pointy.String("64"),
+						Accelerator: map[string]string{"type": "nvidia_gpu", "product": "NVIDIA-A100"},
+					},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		cluster   *v1.Cluster
+		resources *v1.ResourceSpec
+		expected  *v1.ResourceSpec
+		expectErr string
+	}{
+		{
+			name:      "resources without preset are kept",
+			cluster:   cluster,
+			resources: &v1.ResourceSpec{CPU: pointy.String("4")},
+			expected:  &v1.ResourceSpec{CPU: pointy.String("4")},
+		},
+		{
+			name:      "preset expands to concrete resources",
+			cluster:   cluster,
+			resources: &v1.ResourceSpec{Preset: "large"},
+			expected: &v1.ResourceSpec{
+				CPU:         pointy.String("16"),
+				GPU:         pointy.String("2"),
+				Memory:      pointy.String("64"),
+				Accelerator: map[string]string{"type": "nvidia_gpu", "product": "NVIDIA-A100"},
+				Preset:      "large",
+			},
+		},
+		{
+			name:    "explicit values override the preset",
+			cluster: cluster,
+			resources: &v1.ResourceSpec{
+				Preset:      "large",
+				GPU:         pointy.String("4"),
+				Memory:      pointy.String("128"),
+				Accelerator: map[string]string{"product": "NVIDIA-H100"},
+			},
+			expected: &v1.ResourceSpec{
+				CPU:         pointy.String("16"),
+				GPU:         pointy.String("4"),
+				Memory:      pointy.String("128"),
+				Accelerator: map[string]string{"type": "nvidia_gpu", "product": "NVIDIA-H100"},
+				Preset:      "large",
+			},
+		},
+		{
+			name:      "unknown preset is rejected",
+			cluster:   cluster,
+			resources: &v1.ResourceSpec{Preset: "xlarge"},
+			expectErr: "resource preset xlarge not found in cluster default/cluster-a",
+		},
+		{
+			name:      "cluster without presets rejects a preset",
+			cluster:   &v1.Cluster{Metadata: &v1.Metadata{Name: "cluster-b", Workspace: "default"}, Spec: &v1.ClusterSpec{}},
+			resources: &v1.ResourceSpec{Preset: "small"},
+			expectErr: "resource preset small not found in cluster default/cluster-b",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endpoint := &v1.Endpoint{
+				Metadata: &v1.Metadata{Name: "endpoint-a", Workspace: "default"},
+				Spec:     &v1.EndpointSpec{Cluster: "cluster-a", Resources: tt.resources},
+			}
+
+			expanded, err := expandResourcePreset(endpoint, tt.cluster)
+			if tt.expectErr != "" {
+				require.EqualError(t, err, tt.expectErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, expanded.Spec.Resources)
+			// The stored endpoint and cluster preset are left untouched.
+			assert.Same(t, tt.resources, endpoint.Spec.Resources)
+			assert.Equal(t, "NVIDIA-A100", cluster.Spec.Config.ResourcePresets["large"].Accelerator["product"])
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package orchestrator
+
+import (
+	"github.com/pkg/errors"
+
+	v1 "github.com/neutree-ai/neutree/api/v1"
+)
+
+// serviceMeshPodAnnotations are the pod annotations joining an endpoint replica to a service mesh.
+//
+// The model-downloader init container runs before the sidecar proxy is up while the outbound
+// traffic of the pod is already redirected to it, so registry downloads over http(s) bypass
+// the proxy. The engine is only started once the proxy is ready to carry its traffic.
+var serviceMeshPodAnnotations = map[v1.ServiceMeshType]map[string]string{
+	v1.ServiceMeshTypeIstio: {
+		"sidecar.istio.io/inject": "true",
+		// Kubelet probes go to the sidecar, which forwards them to the engine, so the engine
+		// health checks keep working when the mesh enforces mTLS.
+		"sidecar.istio.io/rewriteAppHTTPProbers":        "true",
+		"proxy.istio.io/config":                         `{"holdApplicationUntilProxyStarts":true}`,
+		"traffic.sidecar.istio.io/excludeOutboundPorts": "80,443",
+	},
+	v1.ServiceMeshTypeLinkerd: {
+		// The linkerd proxy admits kubelet probes on the probe paths by itself.
+		"linkerd.io/inject":                     "enabled",
+		"config.linkerd.io/proxy-await":         "enabled",
+		"config.linkerd.io/skip-outbound-ports": "80,443",
+	},
+}
+
+// endpointServiceMesh returns the service mesh the pods of the endpoint join, empty if none.
+// Endpoints join the mesh configured on their cluster unless deployment_options.service_mesh
+// is false.
+func endpointServiceMesh(endpoint *v1.Endpoint, cluster *v1.Cluster) (v1.ServiceMeshType, error) {
+	enabled, err := getServiceMesh(endpoint)
+	if err != nil {
+		return "", err
+	}
+
+	if enabled != nil && !*enabled {
+		return "", nil
+	}
+
+	var mesh *v1.ServiceMeshConfig
+	if cluster.Spec != nil && cluster.Spec.Config != nil && cluster.Spec.Config.KubernetesConfig != nil {
+		mesh = cluster.Spec.Config.KubernetesConfig.ServiceMesh
+	}
+
+	if mesh == nil || mesh.Type == "" {
+		if enabled != nil {
+			return "", errors.Errorf("deployment_options.%s requires a service mesh configured on cluster %s",
+				deploymentOptionServiceMesh, cluster.Metadata.WorkspaceName())
+		}
+
+		return "", nil
+	}
+
+	if _, ok := serviceMeshPodAnnotations[mesh.Type]; !ok {
+		return "", errors.Errorf("unsupported service mesh type %q of cluster %s, must be one of %s, %s",
+			mesh.Type, cluster.Metadata.WorkspaceName(), v1.ServiceMeshTypeIstio, v1.ServiceMeshTypeLinkerd)
+	}
+
+	return mesh.Type, nil
+}
+
+// setServiceMeshVariables adds the sidecar injection annotations of the service mesh to the
+// endpoint pods. Annotations already set, e.g. by accelerator resources, are kept.
+func (k *kubernetesOrchestrator) setServiceMeshVariables(data *DeploymentManifestVariables, endpoint *v1.Endpoint, deployedCluster *v1.Cluster) error {
+	mesh, err := endpointServiceMesh(endpoint, deployedCluster)
+	if err != nil || mesh == "" {
+		return err
+	}
+
+	data.ServiceMesh = string(mesh)
+
+	for key, value := range serviceMeshPodAnnotations[mesh] {
+		if _, exists := data.Annotations[key]; !exists {
+			data.Annotations[key] = value
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,141 @@
+package orchestrator
+
+import (
+	"context"
+	"path/filepath"
+	"strconv"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v1 "github.com/neutree-ai/neutree/api/v1"
+	"github.com/neutree-ai/neutree/internal/util"
+)
+
+const (
+	// sharedWeightsHostPath is the directory of the node shared memory the dshm volume of
+	// endpoints with shared weights is backed by, one directory per endpoint. It outlives the
+	// replicas, so replicas scheduled later on the node find the weights already loaded. It
+	// is not removed with the endpoint either, the weights stay in the node memory until the
+	// directory is removed on the node or the node reboots.
+	sharedWeightsHostPath = "/dev/shm/neutree"
+
+	// podSecurityEnforceLabel is the namespace label setting the Pod Security Standard level
+	// enforced on its pods, the baseline and restricted levels forbid hostPath volumes.
+	podSecurityEnforceLabel = "pod-security.kubernetes.io/enforce"
+
+	// sharedWeightsModelPath is where the model-downloader places shared weights, inside the
+	// dshm volume mounted at /dev/shm.
+	sharedWeightsModelPath = "/dev/shm/models"
+)
+
+// sharedWeightsEngineArgs are the engine args mapping the weights from the model files
+// instead of reading them into the memory of each replica, by engines supporting shared
+// weights.
+var sharedWeightsEngineArgs = map[string]map[string]interface{}{
+	v1.EngineNameLlamaCpp: {"use_mmap": "true"},
+}
+
+// validateSharedWeights rejects an endpoint sharing weights the cluster can not run: the
+// engine must load the weights with mmap, and the dshm hostPath volume must be allowed by the
+// Pod Security Standard enforced on the namespace of the cluster. Levels enforced cluster-wide
+// by the admission configuration are not visible here, such pods fail to be created.
+func validateSharedWeights(ctx *OrchestratorContext) error {
+	enabled, err := getSharedWeights(ctx.Endpoint)
+	if err != nil || !enabled {
+		return err
+	}
+
+	if _, ok := sharedWeightsEngineArgs[ctx.Engine.Metadata.Name]; !ok {
+		return errors.Errorf("deployment_options.%s is not supported by engine %s",
+			deploymentOptionSharedWeights, ctx.Engine.Metadata.Name)
+	}
+
+	namespace := &corev1.Namespace{}
+
+	err = ctx.ctrClient.Get(context.Background(), client.ObjectKey{Name: util.ClusterNamespace(ctx.Cluster)}, namespace)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+
+	if err != nil {
+		return errors.Wrapf(err, "failed to get namespace %s", util.ClusterNamespace(ctx.Cluster))
+	}
+
+	if level := namespace.Labels[podSecurityEnforceLabel]; level == "baseline" || level == "restricted" {
+		return errors.Errorf("deployment_options.%s mounts a hostPath volume, which the %s pod security level "+
+			"enforced on namespace %s forbids", deploymentOptionSharedWeights, level, namespace.Name)
+	}
+
+	return nil
+}
+
+// setSharedWeightsVariables moves the model weights of an endpoint with shared weights from
+// the model cache into the dshm volume and has the engine map them from there. It returns
+// whether the endpoint shares weights, which backs the dshm volume by the node shared memory.
+// Engine args set explicitly on the endpoint are kept.
+func (k *kubernetesOrchestrator) setSharedWeightsVariables(data *DeploymentManifestVariables, endpoint *v1.Endpoint,
+	engine *v1.Engine) (bool, error) {
+	enabled, err := getSharedWeights(endpoint)
+	if err != nil || !enabled {
+		return false, err
+	}
+
+	args, ok := sharedWeightsEngineArgs[engine.Metadata.Name]
+	if !ok {
+		return false, errors.Errorf("deployment_options.%s is not supported by engine %s",
+			deploymentOptionSharedWeights, engine.Metadata.Name)
+	}
+
+	// Shared memory is charged to the replica first writing it, the model-downloader of
+	// that replica must be able to hold all weights.
+	if sizeGB, ok := estimateModelSizeGB(endpoint.Spec.Model); ok && endpoint.Spec.Resources != nil {
+		if memoryGB := endpoint.Spec.Resources.GetMemoryInGB(); memoryGB > 0 && sizeGB > memoryGB {
+			return false, errors.Errorf("deployment_options.%s requires the replica memory to hold the model weights, "+
+				"%s GB estimated, %s GB requested", deploymentOptionSharedWeights,
+				strconv.FormatFloat(sizeGB, 'f', 1, 64), strconv.FormatFloat(memoryGB, 'f', -1, 64))
+		}
+	}
+
+	for key, value := range args {
+		if _, exists := data.EngineArgs[key]; !exists {
+			data.EngineArgs[key] = value
+		}
+	}
+
+	if modelPath, ok := data.ModelArgs["path"].(string); ok && modelPath != "" {
+		relativePath, err := filepath.Rel(v1.DefaultK8sClusterModelCacheMountPath, modelPath)
+		if err != nil {
+			return false, errors.Wrapf(err, "failed to relocate model path %s to shared memory", modelPath)
+		}
+
+		data.ModelArgs["path"] = filepath.Join(sharedWeightsModelPath, relativePath)
+	}
+
+	return true, nil
+}
+
+// sharedMemoryVolumeSource returns the source of the dshm volume. Replicas sharing weights
+// mount the node directory of their endpoint, a memory emptyDir can not be shared by pods.
+// The others get their own shared memory whose size is only bounded by the memory allocated
+// to the node.
+func sharedMemoryVolumeSource(data *DeploymentManifestVariables, sharedWeights bool) corev1.VolumeSource {
+	if sharedWeights {
+		hostPathType := corev1.HostPathDirectoryOrCreate
+
+		return corev1.VolumeSource{
+			HostPath: &corev1.HostPathVolumeSource{
+				Path: filepath.Join(sharedWeightsHostPath, data.Namespace, data.EndpointName),
+				Type: &hostPathType,
+			},
+		}
+	}
+
+	return corev1.VolumeSource{
+		EmptyDir: &corev1.EmptyDirVolumeSource{
+			Medium: corev1.StorageMediumMemory,
+		},
+	}
+}
@@ -0,0 +1,239 @@
+package orchestrator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	v1 "github.com/neutree-ai/neutree/api/v1"
+	"github.com/neutree-ai/neutree/internal/util"
+)
+
+func TestKubernetesOrchestrator_setSharedWeightsVariables(t *testing.T) {
+	modelPath := "/models-cache/default/qwen2-0.5b-instruct/v1"
+
+	tests := []struct {
+		name             string
+		engine           string
+		sharedWeights    interface{}
+		engineArgs       map[string]interface{}
+		memory           *string
+		expectShared     bool
+		expectEngineArgs map[string]interface{}
+		expectModelPath  string
+		expectError      string
+	}{
+		{
+			name:             "disabled by default",
+			engine:           v1.EngineNameLlamaCpp,
+			expectEngineArgs: map[string]interface{}{},
+			expectModelPath:  modelPath,
+		},
+		{
+			name:             "weights mapped from shared memory",
+			engine:           v1.EngineNameLlamaCpp,
+			sharedWeights:    true,
+			memory:           pointer.String("4"),
+			expectShared:     true,
+			expectEngineArgs: map[string]interface{}{"use_mmap": "true"},
+			expectModelPath:  "/dev/shm/models/default/qwen2-0.5b-instruct/v1",
+		},
+		{
+			name:             "explicit engine args are kept",
+			engine:           v1.EngineNameLlamaCpp,
+			sharedWeights:    true,
+			engineArgs:       map[string]interface{}{"use_mmap": "false"},
+			expectShared:     true,
+			expectEngineArgs: map[string]interface{}{"use_mmap": "false"},
+			expectModelPath:  "/dev/shm/models/default/qwen2-0.5b-instruct/v1",
+		},
+		{
+			name:          "engine without mmap support",
+			engine:        v1.EngineNameVLLM,
+			sharedWeights: true,
+			expectError:   "deployment_options.shared_weights is not supported by engine vllm",
+		},
+		{
+			name:          "weights larger than the replica memory",
+			engine:        v1.EngineNameLlamaCpp,
+			sharedWeights: true,
+			memory:        pointer.String("0.5"),
+			expectError: "deployment_options.shared_weights requires the replica memory to hold the model weights, " +
+				"1.0 GB estimated, 0.5 GB requested",
+		},
+		{
+			name:          "not a boolean",
+			engine:        v1.EngineNameLlamaCpp,
+			sharedWeights: "yes",
+			expectError:   "deployment_options.shared_weights must be a boolean",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endpoint := &v1.Endpoint{
+				Metadata: &v1.Metadata{Workspace: "default", Name: "chat"},
+				Spec: &v1.EndpointSpec{
+					Model: &v1.ModelSpec{
+						Name: "qwen2-0.5b-instruct",
+						Info: &v1.ModelInfo{ParameterCount: "0.5B"},
+					},
+					Resources:         &v1.ResourceSpec{Memory: tt.memory},
+					DeploymentOptions: map[string]interface{}{},
+				},
+			}
+			if tt.sharedWeights != nil {
+				endpoint.Spec.DeploymentOptions["shared_weights"] = tt.sharedWeights
+			}
+
+			data := newDeploymentManifestVariables()
+			data.ModelArgs["path"] = modelPath
+			for key, value := range tt.engineArgs {
+				data.EngineArgs[key] = value
+			}
+
+			shared, err := (&kubernetesOrchestrator{}).setSharedWeightsVariables(&data, endpoint,
+				&v1.Engine{Metadata: &v1.Metadata{Name: tt.engine}})
+			if tt.expectError != "" {
+				assert.EqualError(t, err, tt.expectError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectShared, shared)
+			assert.Equal(t, tt.expectEngineArgs, data.EngineArgs)
+			assert.Equal(t, tt.expectModelPath, data.ModelArgs["path"])
+		})
+	}
+}
+
+func TestValidateSharedWeights(t *testing.T) {
+	cluster := &v1.Cluster{Metadata: &v1.Metadata{Workspace: "default", Name: "cluster-a"}}
+
+	tests := []struct {
+		name          string
+		engine        string
+		sharedWeights interface{}
+		podSecurity   string
+		expectError   string
+	}{
+		{
+			name:   "disabled by default",
+			engine: v1.EngineNameVLLM,
+		},
+		{
+			name:          "privileged namespace",
+			engine:        v1.EngineNameLlamaCpp,
+			sharedWeights: true,
+			podSecurity:   "privileged",
+		},
+		{
+			name:          "engine without mmap support",
+			engine:        v1.EngineNameVLLM,
+			sharedWeights: true,
+			expectError:   "deployment_options.shared_weights is not supported by engine vllm",
+		},
+		{
+			name:          "namespace forbidding hostPath volumes",
+			engine:        v1.EngineNameLlamaCpp,
+			sharedWeights: true,
+			podSecurity:   "baseline",
+			expectError: "deployment_options.shared_weights mounts a hostPath volume, which the baseline pod security level " +
+				"enforced on namespace " + util.ClusterNamespace(cluster) + " forbids",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: util.ClusterNamespace(cluster)}}
+			if tt.podSecurity != "" {
+				namespace.Labels = map[string]string{podSecurityEnforceLabel: tt.podSecurity}
+			}
+
+			endpoint := &v1.Endpoint{Metadata: &v1.Metadata{Workspace: "default", Name: "test-endpoint"}, Spec: &v1.EndpointSpec{}}
+			if tt.sharedWeights != nil {
+				endpoint.Spec.DeploymentOptions = map[string]interface{}{"shared_weights": tt.sharedWeights}
+			}
+
+			err := validateSharedWeights(&OrchestratorContext{
+				Cluster:   cluster,
+				Endpoint:  endpoint,
+				Engine:    &v1.Engine{Metadata: &v1.Metadata{Name: tt.engine}},
+				ctrClient: fake.NewClientBuilder().WithObjects(namespace).Build(),
+			})
+			if tt.expectError != "" {
+				assert.EqualError(t, err, tt.expectError)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestBuildDeployment_SharedWeights(t *testing.T) {
+	data := newDeploymentManifestVariables()
+	data.NeutreeVersion = "v0.1.0"
+	data.Namespace = "neutree-cluster-a"
+	data.ImagePrefix = "registry.example.com"
+	data.ImageRepo = "myrepo"
+	data.ImageTag = "v1.0.0"
+	data.EndpointName = "test-endpoint"
+	data.ModelArgs = map[string]interface{}{
+		"name":       "qwen2-0.5b-instruct",
+		"task":       "text-generation",
+		"path":       "/models-cache/default/qwen2-0.5b-instruct/v1",
+		"file":       "*q8_0.gguf",
+		"serve_name": "qwen2-0.5b-instruct",
+	}
+	data.RoutingLogic = "roundrobin"
+	data.Replicas = 2
+
+	endpoint := &v1.Endpoint{
+		Metadata: &v1.Metadata{Workspace: "default", Name: "test-endpoint"},
+		Spec: &v1.EndpointSpec{
+			Model:             &v1.ModelSpec{Name: "qwen2-0.5b-instruct"},
+			DeploymentOptions: map[string]interface{}{"shared_weights": true},
+		},
+	}
+
+	k := &kubernetesOrchestrator{}
+
+	shared, err := k.setSharedWeightsVariables(&data, endpoint, &v1.Engine{Metadata: &v1.Metadata{Name: v1.EngineNameLlamaCpp}})
+	require.NoError(t, err)
+	k.addSharedMemoryVolume(&data, shared)
+
+	objs, err := buildDeploymentObjects(realEmbeddedTemplate(t, "llama-cpp-v0.3.7"), data)
+	require.NoError(t, err)
+
+	var deployment appsv1.Deployment
+
+	for _, obj := range objs.Items {
+		if obj.GetKind() == "Deployment" {
+			require.NoError(t, runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &deployment))
+		}
+	}
+
+	podSpec := deployment.Spec.Template.Spec
+	require.Len(t, podSpec.Containers, 1)
+	assert.Contains(t, podSpec.Containers[0].Args[0], "--use_mmap \"true\"")
+	assert.Contains(t, podSpec.Containers[0].Args[0], "--model $(find /dev/shm/models/default/qwen2-0.5b-instruct/v1 ")
+	assert.Contains(t, podSpec.InitContainers[0].Args[0], "--path=\"/dev/shm/models/default/qwen2-0.5b-instruct/v1\"")
+
+	hostPathType := corev1.HostPathDirectoryOrCreate
+	assert.Equal(t, []corev1.Volume{{
+		Name: "dshm",
+		VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{
+			Path: "/dev/shm/neutree/neutree-cluster-a/test-endpoint",
+			Type: &hostPathType,
+		}},
+	}}, podSpec.Volumes)
+	assert.Equal(t, []corev1.VolumeMount{{Name: "dshm", MountPath: "/dev/shm"}}, podSpec.Containers[0].VolumeMounts)
+}
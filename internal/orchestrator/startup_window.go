@@ -0,0 +1,130 @@
+package orchestrator
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	v1 "github.com/neutree-ai/neutree/api/v1"
+)
+
+const (
+	// startupProbePeriodSeconds is the startup probe period of the built-in deploy templates.
+	startupProbePeriodSeconds = 10
+	// defaultStartupSeconds is the startup window when the model size is unknown, matching the
+	// fixed failureThreshold of 120 the built-in templates used before the window was estimated.
+	defaultStartupSeconds = 1200
+
+	// The estimated startup window is startupBaseSeconds plus startupSecondsPerGB for every GB
+	// of weights, capped at maxStartupSeconds. Weights are downloaded by the model-downloader
+	// init container, so the window only covers loading them.
+	startupBaseSeconds  = 300
+	startupSecondsPerGB = 20
+	maxStartupSeconds   = 7200
+
+	defaultBytesPerParameter = 2
+)
+
+// startupTimeoutSeconds returns how long an engine replica of the endpoint may take to become ready.
+// An explicit deployment_options.startup_timeout_seconds wins; otherwise the window is estimated from
+// the model size declared in the model info, falling back to defaultStartupSeconds.
+func startupTimeoutSeconds(endpoint *v1.Endpoint) (int, error) {
+	seconds, err := getStartupTimeoutSeconds(endpoint)
+	if err != nil {
+		return 0, err
+	}
+
+	if seconds > 0 {
+		return seconds, nil
+	}
+
+	sizeGB, ok := estimateModelSizeGB(endpoint.Spec.Model)
+	if !ok {
+		return defaultStartupSeconds, nil
+	}
+
+	seconds = startupBaseSeconds + int(math.Ceil(sizeGB*startupSecondsPerGB))
+
+	return min(seconds, maxStartupSeconds), nil
+}
+
+// startupFailureThreshold converts a startup window into the startup probe failureThreshold.
+func startupFailureThreshold(seconds int) int {
+	return int(math.Ceil(float64(seconds) / startupProbePeriodSeconds))
+}
+
+// estimateModelSizeGB estimates the size of the model weights from its parameter count and quantization.
+func estimateModelSizeGB(model *v1.ModelSpec) (float64, bool) {
+	if model == nil || model.Info == nil {
+		return 0, false
+	}
+
+	params, ok := parseParameterCount(model.Info.ParameterCount)
+	if !ok {
+		return 0, false
+	}
+
+	return params * bytesPerParameter(model.Info.Quantization) / 1e9, true
+}
+
+// parseParameterCount parses parameter counts such as "7B", "72.7B", "560M" or "8x7B".
+func parseParameterCount(count string) (float64, bool) {
+	count = strings.ToUpper(strings.TrimSpace(count))
+	if count == "" {
+		return 0, false
+	}
+
+	experts := 1.0
+
+	if n, rest, found := strings.Cut(count, "X"); found {
+		e, err := strconv.ParseFloat(n, 64)
+		if err != nil || e <= 0 {
+			return 0, false
+		}
+
+		experts, count = e, rest
+	}
+
+	multiplier := 1.0
+
+	switch {
+	case strings.HasSuffix(count, "K"):
+		multiplier = 1e3
+	case strings.HasSuffix(count, "M"):
+		multiplier = 1e6
+	case strings.HasSuffix(count, "B"):
+		multiplier = 1e9
+	case strings.HasSuffix(count, "T"):
+		multiplier = 1e12
+	}
+
+	if multiplier != 1 {
+		count = count[:len(count)-1]
+	}
+
+	n, err := strconv.ParseFloat(count, 64)
+	if err != nil || n <= 0 {
+		return 0, false
+	}
+
+	return experts * n * multiplier, true
+}
+
+func bytesPerParameter(quantization string) float64 {
+	q := strings.ToLower(quantization)
+
+	switch {
+	case q == "":
+		return defaultBytesPerParameter
+	case strings.Contains(q, "32"):
+		return 4
+	case strings.Contains(q, "16"):
+		return 2
+	case strings.Contains(q, "8"):
+		return 1
+	case strings.Contains(q, "4"), strings.Contains(q, "awq"), strings.Contains(q, "gptq"):
+		return 0.5
+	default:
+		return defaultBytesPerParameter
+	}
+}
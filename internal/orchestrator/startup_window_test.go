@@ -0,0 +1,114 @@
+package orchestrator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	v1 "github.com/neutree-ai/neutree/api/v1"
+)
+
+func TestParseParameterCount(t *testing.T) {
+	tests := []struct {
+		count    string
+		expected float64
+		ok       bool
+	}{
+		{count: "7B", expected: 7e9, ok: true},
+		{count: "72.7b", expected: 72.7e9, ok: true},
+		{count: "560M", expected: 560e6, ok: true},
+		{count: "1T", expected: 1e12, ok: true},
+		{count: "8x7B", expected: 56e9, ok: true},
+		{count: "1000", expected: 1000, ok: true},
+		{count: "", ok: false},
+		{count: "large", ok: false},
+		{count: "-7B", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.count, func(t *testing.T) {
+			params, ok := parseParameterCount(tt.count)
+			assert.Equal(t, tt.ok, ok)
+			assert.InDelta(t, tt.expected, params, 1)
+		})
+	}
+}
+
+func TestStartupTimeoutSeconds(t *testing.T) {
+	endpointWith := func(info *v1.ModelInfo, options map[string]interface{}) *v1.Endpoint {
+		return &v1.Endpoint{
+			Spec: &v1.EndpointSpec{
+				Model:             &v1.ModelSpec{Name: "model", Info: info},
+				DeploymentOptions: options,
+			},
+		}
+	}
+
+	tests := []struct {
+		name      string
+		endpoint  *v1.Endpoint
+		expected  int
+		expectErr bool
+	}{
+		{
+			name:     "unknown model size",
+			endpoint: endpointWith(nil, nil),
+			expected: defaultStartupSeconds,
+		},
+		{
+			name:     "small model gets a short window",
+			endpoint: endpointWith(&v1.ModelInfo{ParameterCount: "0.5B"}, nil),
+			expected: startupBaseSeconds + 1*startupSecondsPerGB,
+		},
+		{
+			name:     "7B bf16 model",
+			endpoint: endpointWith(&v1.ModelInfo{ParameterCount: "7B", Quantization: "bf16"}, nil),
+			expected: startupBaseSeconds + 14*startupSecondsPerGB,
+		},
+		{
+			name:     "quantized model loads faster",
+			endpoint: endpointWith(&v1.ModelInfo{ParameterCount: "70B", Quantization: "fp8"}, nil),
+			expected: startupBaseSeconds + 70*startupSecondsPerGB,
+		},
+		{
+			name:     "huge model is capped",
+			endpoint: endpointWith(&v1.ModelInfo{ParameterCount: "671B", Quantization: "bf16"}, nil),
+			expected: maxStartupSeconds,
+		},
+		{
+			name: "override wins over the estimate",
+			endpoint: endpointWith(&v1.ModelInfo{ParameterCount: "671B"},
+				map[string]interface{}{deploymentOptionStartupTimeoutSeconds: float64(900)}),
+			expected: 900,
+		},
+		{
+			name:      "invalid override",
+			endpoint:  endpointWith(nil, map[string]interface{}{deploymentOptionStartupTimeoutSeconds: "1h"}),
+			expectErr: true,
+		},
+		{
+			name:      "non-positive override",
+			endpoint:  endpointWith(nil, map[string]interface{}{deploymentOptionStartupTimeoutSeconds: float64(0)}),
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seconds, err := startupTimeoutSeconds(tt.endpoint)
+			if tt.expectErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, seconds)
+		})
+	}
+}
+
+func TestStartupFailureThreshold(t *testing.T) {
+	assert.Equal(t, 120, startupFailureThreshold(defaultStartupSeconds))
+	assert.Equal(t, 31, startupFailureThreshold(305))
+}
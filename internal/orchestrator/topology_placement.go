@@ -0,0 +1,85 @@
+package orchestrator
+
+import (
+	"strconv"
+
+	"k8s.io/klog/v2"
+
+	v1 "github.com/neutree-ai/neutree/api/v1"
+	"github.com/neutree-ai/neutree/internal/accelerator/plugin"
+)
+
+const (
+	// rayPlacementGroupStrategyStrictPack places all bundles of a placement group on one node.
+	rayPlacementGroupStrategyStrictPack = "STRICT_PACK"
+
+	// hamiGPUSchedulerPolicyAnnotation selects the HAMi GPU scheduling policy of a pod.
+	hamiGPUSchedulerPolicyAnnotation = "hami.io/gpu-scheduler-policy"
+)
+
+// useTopologyAwarePlacement reports whether the GPUs of each endpoint replica should be placed
+// on NVLink-connected devices. The endpoint must enable it, a replica must span several GPUs
+// and some node of the cluster must report an NVLink domain large enough to hold all of them;
+// otherwise the default placement is kept.
+func useTopologyAwarePlacement(endpoint *v1.Endpoint, cluster *v1.Cluster, gpus float64) (bool, error) {
+	enabled, err := getTopologyAwarePlacement(endpoint)
+	if err != nil || !enabled {
+		return false, err
+	}
+
+	if gpus <= 1 {
+		return false, nil
+	}
+
+	var resources *v1.ClusterResources
+	if cluster != nil && cluster.Status != nil {
+		resources = cluster.Status.ResourceInfo
+	}
+
+	if float64(resources.LargestNVLinkDomain()) < gpus {
+		klog.V(4).Infof("No NVLink domain with %v GPUs reported, using default placement for endpoint %s",
+			gpus, endpoint.Metadata.WorkspaceName())
+
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// rayTopologyPlacementGroupBundles returns a single bundle holding all resources of a replica.
+// Combined with STRICT_PACK, Ray allocates all GPUs of the replica on one node.
+func rayTopologyPlacementGroupBundles(resource *v1.RayResourceSpec) []map[string]float64 {
+	bundle := map[string]float64{
+		"CPU": resource.NumCPUs,
+		"GPU": resource.NumGPUs,
+	}
+
+	if resource.Memory > 0 {
+		bundle["memory"] = resource.Memory
+	}
+
+	for k, v := range resource.Resources {
+		bundle[k] = v
+	}
+
+	return []map[string]float64{bundle}
+}
+
+// setTopologyAwarePlacement asks the HAMi scheduler to pick NVLink-connected GPUs for the pods
+// of the endpoint when topology-aware placement applies.
+func (k *kubernetesOrchestrator) setTopologyAwarePlacement(data *DeploymentManifestVariables, endpoint *v1.Endpoint, deployedCluster *v1.Cluster) error {
+	var gpus float64
+
+	if endpoint.Spec.Resources != nil && endpoint.Spec.Resources.GPU != nil {
+		gpus, _ = strconv.ParseFloat(*endpoint.Spec.Resources.GPU, 64) //nolint:errcheck
+	}
+
+	topologyAware, err := useTopologyAwarePlacement(endpoint, deployedCluster, gpus)
+	if err != nil || !topologyAware {
+		return err
+	}
+
+	data.Annotations[hamiGPUSchedulerPolicyAnnotation] = plugin.NvidiaGPUTopologyAwarePolicy
+
+	return nil
+}
@@ -0,0 +1,65 @@
+package orchestrator
+
+import (
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+
+	v1 "github.com/neutree-ai/neutree/api/v1"
+)
+
+// validateEndpointUpgradeStrategy validates spec.upgrade_strategy of the endpoint.
+func validateEndpointUpgradeStrategy(endpoint *v1.Endpoint) error {
+	if endpoint.Spec == nil || endpoint.Spec.UpgradeStrategy == nil {
+		return nil
+	}
+
+	switch endpoint.Spec.GetUpgradeStrategyType() {
+	case v1.EndpointUpgradeStrategyTypeRolling, v1.EndpointUpgradeStrategyTypeRecreate, v1.EndpointUpgradeStrategyTypeBlueGreen:
+		return nil
+	default:
+		return errors.Errorf("unknown upgrade strategy %s, must be one of Rolling, Recreate, BlueGreen",
+			endpoint.Spec.UpgradeStrategy.Type)
+	}
+}
+
+// validateKubernetesEndpointUpgradeStrategy validates spec.upgrade_strategy of an endpoint
+// deployed on a Kubernetes cluster. The Deployments have no traffic switch, a BlueGreen update
+// would only be a rolling update surging all the replicas, so it is rejected.
+func validateKubernetesEndpointUpgradeStrategy(endpoint *v1.Endpoint) error {
+	if err := validateEndpointUpgradeStrategy(endpoint); err != nil {
+		return err
+	}
+
+	if endpoint.Spec.GetUpgradeStrategyType() == v1.EndpointUpgradeStrategyTypeBlueGreen {
+		return errors.New("upgrade strategy BlueGreen is not supported by endpoints on Kubernetes clusters")
+	}
+
+	return nil
+}
+
+// endpointRolloutCanary returns the canary the updates of a Ray endpoint are rolled out with,
+// nil if they are applied in place. A blue-green update is a canary taking all the requests
+// once it is running, which is promoted right away.
+func endpointRolloutCanary(endpoint *v1.Endpoint) *v1.CanarySpec {
+	if endpoint.Spec.Canary != nil {
+		return endpoint.Spec.Canary
+	}
+
+	if endpoint.Spec.GetUpgradeStrategyType() == v1.EndpointUpgradeStrategyTypeBlueGreen {
+		return &v1.CanarySpec{Weight: 100}
+	}
+
+	return nil
+}
+
+// kubernetesDeploymentStrategy returns the Deployment strategy of the upgrade strategy of the
+// endpoint, nil for the rolling update of the deployment templates. The StatefulSets of the
+// endpoints with peer discovery are always rolled.
+func kubernetesDeploymentStrategy(endpoint *v1.Endpoint) *appsv1.DeploymentStrategy {
+	switch endpoint.Spec.GetUpgradeStrategyType() {
+	case v1.EndpointUpgradeStrategyTypeRecreate:
+		return &appsv1.DeploymentStrategy{Type: appsv1.RecreateDeploymentStrategyType}
+	default:
+		return nil
+	}
+}
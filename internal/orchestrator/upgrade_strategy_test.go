@@ -0,0 +1,242 @@
+package orchestrator
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+
+	v1 "github.com/neutree-ai/neutree/api/v1"
+	acceleratormocks "github.com/neutree-ai/neutree/internal/accelerator/mocks"
+	"github.com/neutree-ai/neutree/internal/accelerator/plugin"
+	"github.com/neutree-ai/neutree/internal/accelerator/resourceparser"
+	"github.com/neutree-ai/neutree/internal/ray/dashboard"
+	dashboardmocks "github.com/neutree-ai/neutree/internal/ray/dashboard/mocks"
+	storagemocks "github.com/neutree-ai/neutree/pkg/storage/mocks"
+)
+
+func TestValidateEndpointUpgradeStrategy(t *testing.T) {
+	tests := []struct {
+		name        string
+		strategy    *v1.EndpointUpgradeStrategy
+		expectError string
+	}{
+		{name: "not configured"},
+		{name: "default type", strategy: &v1.EndpointUpgradeStrategy{}},
+		{name: "rolling", strategy: &v1.EndpointUpgradeStrategy{Type: v1.EndpointUpgradeStrategyTypeRolling}},
+		{name: "recreate", strategy: &v1.EndpointUpgradeStrategy{Type: v1.EndpointUpgradeStrategyTypeRecreate}},
+		{name: "blue-green", strategy: &v1.EndpointUpgradeStrategy{Type: v1.EndpointUpgradeStrategyTypeBlueGreen}},
+		{
+			name:        "unknown type",
+			strategy:    &v1.EndpointUpgradeStrategy{Type: "Canary"},
+			expectError: "unknown upgrade strategy Canary, must be one of Rolling, Recreate, BlueGreen",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endpoint := &v1.Endpoint{Spec: &v1.EndpointSpec{UpgradeStrategy: tt.strategy}}
+
+			err := validateEndpointUpgradeStrategy(endpoint)
+			if tt.expectError != "" {
+				assert.EqualError(t, err, tt.expectError)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestValidateKubernetesEndpointUpgradeStrategy(t *testing.T) {
+	endpoint := &v1.Endpoint{Spec: &v1.EndpointSpec{}}
+	assert.NoError(t, validateKubernetesEndpointUpgradeStrategy(endpoint))
+
+	endpoint.Spec.UpgradeStrategy = &v1.EndpointUpgradeStrategy{Type: v1.EndpointUpgradeStrategyTypeRecreate}
+	assert.NoError(t, validateKubernetesEndpointUpgradeStrategy(endpoint))
+
+	endpoint.Spec.UpgradeStrategy = &v1.EndpointUpgradeStrategy{Type: v1.EndpointUpgradeStrategyTypeBlueGreen}
+	assert.EqualError(t, validateKubernetesEndpointUpgradeStrategy(endpoint),
+		"upgrade strategy BlueGreen is not supported by endpoints on Kubernetes clusters")
+
+	endpoint.Spec.UpgradeStrategy = &v1.EndpointUpgradeStrategy{Type: "Canary"}
+	assert.EqualError(t, validateKubernetesEndpointUpgradeStrategy(endpoint),
+		"unknown upgrade strategy Canary, must be one of Rolling, Recreate, BlueGreen")
+}
+
+func TestEndpointRolloutCanary(t *testing.T) {
+	endpoint := newCanaryTestEndpoint()
+	assert.Equal(t, endpoint.Spec.Canary, endpointRolloutCanary(endpoint))
+
+	endpoint.Spec.Canary = nil
+	assert.Nil(t, endpointRolloutCanary(endpoint))
+
+	endpoint.Spec.UpgradeStrategy = &v1.EndpointUpgradeStrategy{Type: v1.EndpointUpgradeStrategyTypeRecreate}
+	assert.Nil(t, endpointRolloutCanary(endpoint))
+
+	// A blue-green update switches all the requests to the new version once it is running.
+	endpoint.Spec.UpgradeStrategy = &v1.EndpointUpgradeStrategy{Type: v1.EndpointUpgradeStrategyTypeBlueGreen}
+	assert.Equal(t, &v1.CanarySpec{Weight: 100}, endpointRolloutCanary(endpoint))
+}
+
+func TestRayOrchestrator_createOrUpdateEndpoint_UpgradeStrategy(t *testing.T) {
+	deployed := canaryTestApplication("production_chat-model", "/production/chat-model", "v1")
+	other := dashboard.RayServeApplication{Name: "other_endpoint"}
+
+	running := map[string]dashboard.RayServeApplicationStatus{
+		"production_chat-model": {Status: "RUNNING", DeployedAppConfig: &deployed},
+		"other_endpoint":        {Status: "RUNNING", DeployedAppConfig: &other},
+	}
+
+	tests := []struct {
+		name     string
+		strategy v1.EndpointUpgradeStrategyType
+		apps     map[string]dashboard.RayServeApplicationStatus
+		expect   func(apps map[string]dashboard.RayServeApplication) bool
+	}{
+		{
+			name: "rolling updates the application in place",
+			apps: running,
+			expect: func(apps map[string]dashboard.RayServeApplication) bool {
+				app, ok := apps["production_chat-model"]
+				return len(apps) == 2 && ok && app.Args["model"].(map[string]interface{})["version"] != "v1"
+			},
+		},
+		{
+			name:     "recreate removes the application first",
+			strategy: v1.EndpointUpgradeStrategyTypeRecreate,
+			apps:     running,
+			expect: func(apps map[string]dashboard.RayServeApplication) bool {
+				_, ok := apps["other_endpoint"]
+				return len(apps) == 1 && ok
+			},
+		},
+		{
+			name:     "recreate deploys the removed application",
+			strategy: v1.EndpointUpgradeStrategyTypeRecreate,
+			apps: map[string]dashboard.RayServeApplicationStatus{
+				"other_endpoint": {Status: "RUNNING", DeployedAppConfig: &other},
+			},
+			expect: func(apps map[string]dashboard.RayServeApplication) bool {
+				_, ok := apps["production_chat-model"]
+				return len(apps) == 2 && ok
+			},
+		},
+		{
+			name:     "blue-green deploys the new version next to the running one",
+			strategy: v1.EndpointUpgradeStrategyTypeBlueGreen,
+			apps:     running,
+			expect: func(apps map[string]dashboard.RayServeApplication) bool {
+				stable, green := apps["production_chat-model"], apps["production_chat-model_canary"]
+				return len(apps) == 3 &&
+					stable.Args["model"].(map[string]interface{})["version"] == "v1" &&
+					green.RoutePrefix == "/production/chat-model/_canary"
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endpoint := newCanaryTestEndpoint()
+			endpoint.Spec.Canary = nil
+
+			if tt.strategy != "" {
+				endpoint.Spec.UpgradeStrategy = &v1.EndpointUpgradeStrategy{Type: tt.strategy}
+			}
+
+			mockDashboard := dashboardmocks.NewMockDashboardService(t)
+			mockStorage := storagemocks.NewMockStorage(t)
+
+			mockAcceleratorMgr := acceleratormocks.NewMockManager(t)
+			mockAcceleratorMgr.EXPECT().GetEngineContainerRunOptions(mock.Anything).Return([]string{"--runtime=nvidia", "--gpus all"}, nil).Maybe()
+			mockAcceleratorMgr.EXPECT().GetAllConverters().Return(map[string]plugin.ResourceConverter{}).Maybe()
+			mockAcceleratorMgr.EXPECT().GetAllParsers().Return(map[string]resourceparser.ResourceParser{}).Maybe()
+
+			o, ctx := newTestRayOrchestratorCtx(mockStorage, mockDashboard, endpoint, mockAcceleratorMgr)
+
+			mockDashboard.On("GetServeApplications").Return(&dashboard.RayServeApplicationsResponse{Applications: tt.apps}, nil)
+			mockDashboard.On("UpdateServeApplications", mock.MatchedBy(func(req dashboard.RayServeApplicationsRequest) bool {
+				apps := map[string]dashboard.RayServeApplication{}
+				for _, app := range req.Applications {
+					apps[app.Name] = app
+				}
+
+				return tt.expect(apps)
+			})).Return(nil)
+
+			assert.NoError(t, o.createOrUpdate(ctx))
+			mockDashboard.AssertExpectations(t)
+		})
+	}
+}
+
+func TestBuildDeployment_UpgradeStrategy(t *testing.T) {
+	rolling := appsv1.DeploymentStrategy{
+		Type: appsv1.RollingUpdateDeploymentStrategyType,
+		RollingUpdate: &appsv1.RollingUpdateDeployment{
+			MaxUnavailable: ptr.To(intstr.FromInt32(1)),
+			MaxSurge:       ptr.To(intstr.FromInt32(0)),
+		},
+	}
+
+	tests := []struct {
+		strategy *v1.EndpointUpgradeStrategy
+		expect   appsv1.DeploymentStrategy
+	}{
+		{
+			expect: rolling,
+		},
+		{
+			strategy: &v1.EndpointUpgradeStrategy{Type: v1.EndpointUpgradeStrategyTypeRolling},
+			expect:   rolling,
+		},
+		{
+			strategy: &v1.EndpointUpgradeStrategy{Type: v1.EndpointUpgradeStrategyTypeRecreate},
+			expect:   appsv1.DeploymentStrategy{Type: appsv1.RecreateDeploymentStrategyType},
+		},
+	}
+
+	for _, templateKey := range []string{"vllm-v0.11.2", "vllm-v0.17.1", "vllm-v0.24.0", "sglang-v0.5.10", "llama-cpp-v0.3.7"} {
+		for _, tt := range tests {
+			t.Run(fmt.Sprintf("%s/%s", templateKey, (&v1.EndpointSpec{UpgradeStrategy: tt.strategy}).GetUpgradeStrategyType()), func(t *testing.T) {
+				data := newDeploymentManifestVariables()
+				data.NeutreeVersion = "v0.1.0"
+				data.Namespace = "default"
+				data.ImagePrefix = "registry.example.com"
+				data.ImageRepo = "myrepo"
+				data.ImageTag = "v1.0.0"
+				data.EndpointName = "test-endpoint"
+				data.ModelArgs = map[string]interface{}{
+					"name":       "gpt-4",
+					"task":       "text-generation",
+					"path":       "/mnt/models/gpt-4",
+					"serve_name": "gpt-4",
+				}
+				data.RoutingLogic = "roundrobin"
+				data.Replicas = 1
+				data.DeploymentStrategy = kubernetesDeploymentStrategy(&v1.Endpoint{
+					Spec: &v1.EndpointSpec{UpgradeStrategy: tt.strategy},
+				})
+
+				objs, err := buildDeploymentObjects(realEmbeddedTemplate(t, templateKey), data)
+				require.NoError(t, err)
+
+				var deployment appsv1.Deployment
+
+				for _, obj := range objs.Items {
+					if obj.GetKind() == "Deployment" {
+						require.NoError(t, runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &deployment))
+					}
+				}
+
+				assert.Equal(t, tt.expect, deployment.Spec.Strategy)
+			})
+		}
+	}
+}
@@ -1,9 +1,14 @@
 package orchestrator
 
 import (
+	"encoding/json"
 	"fmt"
 	"maps"
+	"math"
+	"slices"
+	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/pkg/errors"
 	"k8s.io/klog/v2"
@@ -12,11 +17,113 @@ import (
 	"github.com/neutree-ai/neutree/internal/accelerator"
 	"github.com/neutree-ai/neutree/internal/accelerator/plugin"
 	"github.com/neutree-ai/neutree/internal/model_registry"
+	"github.com/neutree-ai/neutree/internal/util"
 	"github.com/neutree-ai/neutree/pkg/storage"
 )
 
 const acceleratorTypeCPU = "cpu"
 
+// modelDownloadProgressMarker prefixes the machine-readable progress lines printed by
+// neutree.downloader, e.g. "NEUTREE_MODEL_DOWNLOAD_PROGRESS downloaded=512 total=1024".
+const modelDownloadProgressMarker = "NEUTREE_MODEL_DOWNLOAD_PROGRESS"
+
+// modelDownloadRateLimitedMarker is printed by neutree.downloader when the model registry rate
+// limits the download, with the seconds it waits before retrying, e.g.
+// "NEUTREE_MODEL_DOWNLOAD_RATE_LIMITED retry_after=30". It has no retry_after once the
+// downloader gives up.
+const modelDownloadRateLimitedMarker = "NEUTREE_MODEL_DOWNLOAD_RATE_LIMITED"
+
+// modelDownloadRateLimitedMessage tells a download held back by the model registry apart from
+// other download failures in the endpoint status.
+const modelDownloadRateLimitedMessage = "rate limited by model registry"
+
+// parseModelDownloadProgress returns the latest download progress found in logText,
+// or nil when no progress line is present.
+func parseModelDownloadProgress(logText string) *v1.ModelDownloadProgress {
+	lines := strings.Split(logText, "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		idx := strings.Index(lines[i], modelDownloadProgressMarker)
+		if idx < 0 {
+			continue
+		}
+
+		var (
+			downloaded, total int64
+			parsed            bool
+		)
+
+		for _, field := range strings.Fields(lines[i][idx+len(modelDownloadProgressMarker):]) {
+			key, value, ok := strings.Cut(field, "=")
+			if !ok {
+				continue
+			}
+
+			n, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || n < 0 {
+				continue
+			}
+
+			switch key {
+			case "downloaded":
+				downloaded = n
+				parsed = true
+			case "total":
+				total = n
+			}
+		}
+
+		if !parsed {
+			continue
+		}
+
+		progress := &v1.ModelDownloadProgress{
+			DownloadedBytes: downloaded,
+			TotalBytes:      total,
+		}
+
+		if total > 0 {
+			percent := math.Round(math.Min(100, float64(downloaded)*100/float64(total))*10) / 10
+			progress.Percent = &percent
+		}
+
+		return progress
+	}
+
+	return nil
+}
+
+// parseModelDownloadRateLimit returns why the model download is held back when the last thing
+// neutree.downloader reported in logText is a rate limit of the model registry, e.g. "rate
+// limited by model registry, retrying in 30s". It is empty when the download progressed since.
+func parseModelDownloadRateLimit(logText string) string {
+	lines := strings.Split(logText, "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		if strings.Contains(lines[i], modelDownloadProgressMarker) {
+			return ""
+		}
+
+		idx := strings.Index(lines[i], modelDownloadRateLimitedMarker)
+		if idx < 0 {
+			continue
+		}
+
+		for _, field := range strings.Fields(lines[i][idx+len(modelDownloadRateLimitedMarker):]) {
+			value, ok := strings.CutPrefix(field, "retry_after=")
+			if !ok {
+				continue
+			}
+
+			if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+				return fmt.Sprintf("%s, retrying in %ds", modelDownloadRateLimitedMessage, seconds)
+			}
+		}
+
+		return modelDownloadRateLimitedMessage
+	}
+
+	return ""
+}
+
 // engineTPArgKey returns the underscore-form engine_args key the engine
 // uses for tensor parallel size. vLLM uses `tensor_parallel_size`; SGLang's
 // ServerArgs dataclass field is `tp_size`. Returns "" when the engine
@@ -64,7 +171,7 @@ func getEndpointDeployCluster(s storage.Storage, endpoint *v1.Endpoint) (*v1.Clu
 		})
 	}
 
-	clusterList, err := s.ListCluster(storage.ListOption{Filters: clusterFilter})
+	clusterList, err := s.ListCluster(storage.ListOption{Filters: clusterFilter, IncludeDeleted: true, IncludeArchived: true})
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to list cluster")
 	}
@@ -90,6 +197,7 @@ func getUsedEngine(s storage.Storage, endpoint *v1.Endpoint) (*v1.Engine, error)
 				Value:    strconv.Quote(endpoint.Metadata.Workspace),
 			},
 		},
+		IncludeDeleted: true,
 	})
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to list engine")
@@ -120,19 +228,24 @@ func getUsedEngine(s storage.Storage, endpoint *v1.Endpoint) (*v1.Engine, error)
 }
 
 func getEndpointModelRegistry(s storage.Storage, endpoint *v1.Endpoint) (*v1.ModelRegistry, error) {
+	return getModelRegistry(s, endpoint.Metadata.Workspace, endpoint.Spec.Model.Registry)
+}
+
+func getModelRegistry(s storage.Storage, workspace, name string) (*v1.ModelRegistry, error) {
 	modelRegistry, err := s.ListModelRegistry(storage.ListOption{
 		Filters: []storage.Filter{
 			{
 				Column:   "metadata->name",
 				Operator: "eq",
-				Value:    strconv.Quote(endpoint.Spec.Model.Registry),
+				Value:    strconv.Quote(name),
 			},
 			{
 				Column:   "metadata->workspace",
 				Operator: "eq",
-				Value:    strconv.Quote(endpoint.Metadata.Workspace),
+				Value:    strconv.Quote(workspace),
 			},
 		},
+		IncludeDeleted: true,
 	})
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to list model registry")
@@ -162,13 +275,13 @@ func getUsedImageRegistries(cluster *v1.Cluster, s storage.Storage) (*v1.ImageRe
 		})
 	}
 
-	imageRegistryList, err := s.ListImageRegistry(storage.ListOption{Filters: imageRegistryFilter})
+	imageRegistryList, err := s.ListImageRegistry(storage.ListOption{Filters: imageRegistryFilter, IncludeDeleted: true})
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to list image registry")
 	}
 
 	if len(imageRegistryList) == 0 {
-		return nil, storage.ErrResourceNotFound
+		return nil, errors.Wrapf(storage.ErrResourceNotFound, "image registry %s is missing", cluster.Spec.ImageRegistry)
 	}
 
 	return &imageRegistryList[0], nil
@@ -341,6 +454,8 @@ func convertToKubernetes(acceleratorMgr accelerator.Manager, spec *v1.ResourceSp
 		if res.Env != nil {
 			maps.Copy(result.Env, res.Env)
 		}
+
+		result.Tolerations = append(result.Tolerations, res.Tolerations...)
 	}
 
 	// Add custom resources
@@ -436,3 +551,158 @@ func IsEndpointPaused(endpoint *v1.Endpoint) bool {
 
 	return false
 }
+
+// validateEndpointAcceleratorProduct rejects an endpoint pinned to an accelerator product the
+// deploy cluster has no allocatable accelerators of, its replicas could never be scheduled.
+// The product is requested as a Ray custom resource on ssh clusters and as a node selector on
+// kubernetes clusters, both by the converter of the accelerator type, so a product without a
+// type is rejected too. Clusters not reporting their resources yet are not checked.
+func validateEndpointAcceleratorProduct(endpoint *v1.Endpoint, cluster *v1.Cluster) error {
+	if endpoint == nil || endpoint.Spec == nil || endpoint.Spec.Resources == nil {
+		return nil
+	}
+
+	product := endpoint.Spec.Resources.GetAcceleratorProduct()
+	if product == "" {
+		return nil
+	}
+
+	acceleratorType := endpoint.Spec.Resources.GetAcceleratorType()
+	if acceleratorType == "" || acceleratorType == acceleratorTypeCPU {
+		return errors.Errorf("accelerator product %q requires an accelerator type", product)
+	}
+
+	if cluster == nil || cluster.Status == nil || cluster.Status.ResourceInfo == nil ||
+		cluster.Status.ResourceInfo.Allocatable == nil {
+		return nil
+	}
+
+	products := cluster.Status.ResourceInfo.GetProductModels(acceleratorType)
+	if slices.Contains(products, product) {
+		return nil
+	}
+
+	sort.Strings(products)
+
+	return errors.Errorf("accelerator product %q is not allocatable in deploy cluster %s, %s products: %v",
+		product, cluster.Metadata.WorkspaceName(), acceleratorType, products)
+}
+
+// validateEndpointSamplingParams validates the default sampling parameters of the endpoint
+// against the ranges the OpenAI API accepts.
+func validateEndpointSamplingParams(endpoint *v1.Endpoint) error {
+	if endpoint == nil || endpoint.Spec == nil || endpoint.Spec.DefaultSamplingParams == nil {
+		return nil
+	}
+
+	params := endpoint.Spec.DefaultSamplingParams
+
+	if params.Temperature != nil && (*params.Temperature < 0 || *params.Temperature > 2) {
+		return errors.New("default_sampling_params.temperature must be between 0 and 2")
+	}
+
+	if params.TopP != nil && (*params.TopP < 0 || *params.TopP > 1) {
+		return errors.New("default_sampling_params.top_p must be between 0 and 1")
+	}
+
+	if params.MaxTokens != nil && *params.MaxTokens < 1 {
+		return errors.New("default_sampling_params.max_tokens must be positive")
+	}
+
+	return nil
+}
+
+// vllmGenerationConfigArgKey is the vLLM engine arg overriding the generation config of the
+// model, which holds the sampling parameters of the requests omitting them.
+const vllmGenerationConfigArgKey = "override_generation_config"
+
+// setDefaultSamplingParamsArg maps the default sampling parameters of the endpoint to the
+// server-side defaults of its engine, unless engine_args already overrides them. Shared by the
+// Ray and Kubernetes orchestrators, the gateway applies them for the other engines.
+func setDefaultSamplingParamsArg(endpoint *v1.Endpoint, engineArgs map[string]interface{}) {
+	if endpoint.Spec.DefaultSamplingParams == nil || !endpoint.Spec.EngineAppliesSamplingDefaults() {
+		return
+	}
+
+	if _, ok := engineArgs[vllmGenerationConfigArgKey]; ok {
+		return
+	}
+
+	if _, ok := engineArgs[strings.ReplaceAll(vllmGenerationConfigArgKey, "_", "-")]; ok {
+		return
+	}
+
+	params := endpoint.Spec.DefaultSamplingParams
+	config := map[string]interface{}{}
+
+	if params.Temperature != nil {
+		config["temperature"] = *params.Temperature
+	}
+
+	if params.TopP != nil {
+		config["top_p"] = *params.TopP
+	}
+
+	// vLLM takes the max_tokens default from max_new_tokens of the generation config.
+	if params.MaxTokens != nil {
+		config["max_new_tokens"] = *params.MaxTokens
+	}
+
+	if len(config) == 0 {
+		return
+	}
+
+	// The value is JSON-encoded, as vLLM parses it from the command line of the Kubernetes
+	// templates, the Ray serve application decodes it into the engine args.
+	// Numbers only, marshaling does not fail.
+	encoded, _ := json.Marshal(config) //nolint:errcheck
+	engineArgs[vllmGenerationConfigArgKey] = string(encoded)
+}
+
+// validateEndpointServedModels validates the models of a multi-model endpoint. Every model must
+// have a name with a distinct serve key and use the registry of the primary model. Versions of a
+// model may be served side by side as long as each has a distinct version, and routing weights
+// must not be negative.
+func validateEndpointServedModels(endpoint *v1.Endpoint) error {
+	if endpoint == nil || !endpoint.Spec.IsMultiModel() {
+		return nil
+	}
+
+	if endpoint.Spec.Model == nil {
+		return errors.New("multi-model endpoint requires spec.model")
+	}
+
+	seen := map[string]string{}
+
+	for _, model := range endpoint.Spec.ServedModels() {
+		if model == nil || model.Name == "" {
+			return errors.New("every model of a multi-model endpoint must have a name")
+		}
+
+		if model.Registry != "" && model.Registry != endpoint.Spec.Model.Registry {
+			return errors.Errorf("model %s uses registry %s, but all models of a multi-model endpoint must use registry %s",
+				model.Name, model.Registry, endpoint.Spec.Model.Registry)
+		}
+
+		key := util.EndpointServedModelKey(endpoint, model)
+		if key == "" {
+			return errors.Errorf("model name %q is not valid for a multi-model endpoint", model.Name)
+		}
+
+		if other, ok := seen[key]; ok {
+			if other == model.Name {
+				return errors.Errorf("versions of model %s served by a multi-model endpoint must be distinct", model.Name)
+			}
+
+			return errors.Errorf("models %s and %s of a multi-model endpoint conflict, model names must be distinct", other, model.Name)
+		}
+
+		if model.Weight != nil && *model.Weight < 0 {
+			return errors.Errorf("weight of model %s must not be negative", model.Name)
+		}
+
+		seen[key] = model.Name
+	}
+
+	return nil
+}
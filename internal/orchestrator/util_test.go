@@ -7,6 +7,7 @@ import (
 	"github.com/neutree-ai/neutree/internal/accelerator/plugin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.openly.dev/pointy"
 
 	acceleratormocks "github.com/neutree-ai/neutree/internal/accelerator/mocks"
 	"github.com/neutree-ai/neutree/internal/model_registry"
@@ -447,3 +448,337 @@ func TestGetDeployedModelRealVersion_ModelRegistry(t *testing.T) {
 		})
 	}
 }
+
+func TestParseModelDownloadProgress(t *testing.T) {
+	percent := func(v float64) *float64 { return &v }
+
+	tests := []struct {
+		name     string
+		logText  string
+		expected *v1.ModelDownloadProgress
+	}{
+		{
+			name:     "no progress line",
+			logText:  "NEUTREE_MODEL_DOWNLOAD_START\nsomething else\n",
+			expected: nil,
+		},
+		{
+			name:    "latest progress line wins",
+			logText: "NEUTREE_MODEL_DOWNLOAD_PROGRESS downloaded=10 total=100\nNEUTREE_MODEL_DOWNLOAD_PROGRESS downloaded=55 total=100\n",
+			expected: &v1.ModelDownloadProgress{
+				DownloadedBytes: 55,
+				TotalBytes:      100,
+				Percent:         percent(55),
+			},
+		},
+		{
+			name:    "unknown total has no percent",
+			logText: "2024-01-01 INFO NEUTREE_MODEL_DOWNLOAD_PROGRESS downloaded=2048 total=0",
+			expected: &v1.ModelDownloadProgress{
+				DownloadedBytes: 2048,
+			},
+		},
+		{
+			name:    "percent is capped and rounded",
+			logText: "NEUTREE_MODEL_DOWNLOAD_PROGRESS downloaded=2 total=3\n",
+			expected: &v1.ModelDownloadProgress{
+				DownloadedBytes: 2,
+				TotalBytes:      3,
+				Percent:         percent(66.7),
+			},
+		},
+		{
+			name:    "malformed line is skipped",
+			logText: "NEUTREE_MODEL_DOWNLOAD_PROGRESS downloaded=1 total=4\nNEUTREE_MODEL_DOWNLOAD_PROGRESS downloaded=abc\n",
+			expected: &v1.ModelDownloadProgress{
+				DownloadedBytes: 1,
+				TotalBytes:      4,
+				Percent:         percent(25),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, parseModelDownloadProgress(tt.logText))
+		})
+	}
+}
+
+func TestParseModelDownloadRateLimit(t *testing.T) {
+	tests := []struct {
+		name     string
+		logText  string
+		expected string
+	}{
+		{
+			name:    "not rate limited",
+			logText: "NEUTREE_MODEL_DOWNLOAD_START\nNEUTREE_MODEL_DOWNLOAD_PROGRESS downloaded=10 total=100\n",
+		},
+		{
+			name:     "waiting for the registry",
+			logText:  "NEUTREE_MODEL_DOWNLOAD_START\nNEUTREE_MODEL_DOWNLOAD_RATE_LIMITED retry_after=30\nRate limited by model registry (retry 1/5), retrying in 30.0s\n",
+			expected: "rate limited by model registry, retrying in 30s",
+		},
+		{
+			name:    "download progressed after the rate limit",
+			logText: "NEUTREE_MODEL_DOWNLOAD_RATE_LIMITED retry_after=30\nNEUTREE_MODEL_DOWNLOAD_PROGRESS downloaded=10 total=100\n",
+		},
+		{
+			name:     "download gave up",
+			logText:  "NEUTREE_MODEL_DOWNLOAD_RATE_LIMITED retry_after=30\nNEUTREE_MODEL_DOWNLOAD_RATE_LIMITED\nNEUTREE_MODEL_DOWNLOAD_FAILED\n",
+			expected: "rate limited by model registry",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, parseModelDownloadRateLimit(tt.logText))
+		})
+	}
+}
+
+func TestValidateEndpointSamplingParams(t *testing.T) {
+	tests := []struct {
+		name        string
+		params      *v1.SamplingParams
+		expectError string
+	}{
+		{name: "no defaults"},
+		{
+			name:   "valid defaults",
+			params: &v1.SamplingParams{Temperature: pointy.Float64(0), TopP: pointy.Float64(1), MaxTokens: pointy.Int(1)},
+		},
+		{
+			name:        "temperature out of range",
+			params:      &v1.SamplingParams{Temperature: pointy.Float64(2.5)},
+			expectError: "temperature must be between 0 and 2",
+		},
+		{
+			name:        "top_p out of range",
+			params:      &v1.SamplingParams{TopP: pointy.Float64(-0.1)},
+			expectError: "top_p must be between 0 and 1",
+		},
+		{
+			name:        "max_tokens not positive",
+			params:      &v1.SamplingParams{MaxTokens: pointy.Int(0)},
+			expectError: "max_tokens must be positive",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateEndpointSamplingParams(&v1.Endpoint{Spec: &v1.EndpointSpec{DefaultSamplingParams: tt.params}})
+			if tt.expectError == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.ErrorContains(t, err, tt.expectError)
+		})
+	}
+}
+
+func TestSetDefaultSamplingParamsArg(t *testing.T) {
+	params := &v1.SamplingParams{Temperature: pointy.Float64(0.2), MaxTokens: pointy.Int(512)}
+
+	tests := []struct {
+		name       string
+		engine     string
+		engineArgs map[string]interface{}
+		expected   map[string]interface{}
+	}{
+		{
+			name:       "vllm applies the defaults",
+			engine:     v1.EngineNameVLLM,
+			engineArgs: map[string]interface{}{},
+			expected: map[string]interface{}{
+				"override_generation_config": `{"max_new_tokens":512,"temperature":0.2}`,
+			},
+		},
+		{
+			name:       "user generation config wins",
+			engine:     v1.EngineNameVLLM,
+			engineArgs: map[string]interface{}{"override-generation-config": `{"temperature": 1}`},
+			expected:   map[string]interface{}{"override-generation-config": `{"temperature": 1}`},
+		},
+		{
+			name:       "left to the gateway for other engines",
+			engine:     v1.EngineNameSGLang,
+			engineArgs: map[string]interface{}{},
+			expected:   map[string]interface{}{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endpoint := &v1.Endpoint{Spec: &v1.EndpointSpec{
+				Engine:                &v1.EndpointEngineSpec{Engine: tt.engine},
+				DefaultSamplingParams: params,
+			}}
+
+			setDefaultSamplingParamsArg(endpoint, tt.engineArgs)
+			assert.Equal(t, tt.expected, tt.engineArgs)
+		})
+	}
+}
+
+func TestValidateEndpointServedModels(t *testing.T) {
+	newEndpoint := func(models ...*v1.ModelSpec) *v1.Endpoint {
+		return &v1.Endpoint{
+			Metadata: &v1.Metadata{Name: "ep", Workspace: "ws"},
+			Spec: &v1.EndpointSpec{
+				Model:  &v1.ModelSpec{Registry: "hf", Name: "Qwen/Qwen3-8B"},
+				Models: models,
+			},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		endpoint    *v1.Endpoint
+		expectError string
+	}{
+		{
+			name:     "single model endpoint",
+			endpoint: newEndpoint(),
+		},
+		{
+			name:     "models inherit primary registry",
+			endpoint: newEndpoint(&v1.ModelSpec{Name: "llama3"}, &v1.ModelSpec{Registry: "hf", Name: "mistral"}),
+		},
+		{
+			name:        "model without name",
+			endpoint:    newEndpoint(&v1.ModelSpec{}),
+			expectError: "must have a name",
+		},
+		{
+			name:        "model from another registry",
+			endpoint:    newEndpoint(&v1.ModelSpec{Registry: "bentoml", Name: "llama3"}),
+			expectError: "must use registry hf",
+		},
+		{
+			name:        "conflicting model names",
+			endpoint:    newEndpoint(&v1.ModelSpec{Name: "qwen/qwen3-8b"}),
+			expectError: "model names must be distinct",
+		},
+		{
+			name:     "versions of the primary model",
+			endpoint: newEndpoint(&v1.ModelSpec{Name: "Qwen/Qwen3-8B", Version: "v2"}),
+		},
+		{
+			name:        "versions of a model without distinct versions",
+			endpoint:    newEndpoint(&v1.ModelSpec{Name: "llama3", Version: "v1"}, &v1.ModelSpec{Name: "llama3", Version: "v1"}),
+			expectError: "versions of model llama3 served by a multi-model endpoint must be distinct",
+		},
+		{
+			name:     "weighted versions of a model",
+			endpoint: newEndpoint(&v1.ModelSpec{Name: "llama3", Version: "v1", Weight: intPtr(90)}, &v1.ModelSpec{Name: "llama3", Version: "v2", Weight: intPtr(10)}),
+		},
+		{
+			name:        "negative weight",
+			endpoint:    newEndpoint(&v1.ModelSpec{Name: "llama3", Version: "v2", Weight: intPtr(-1)}),
+			expectError: "weight of model llama3 must not be negative",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateEndpointServedModels(tt.endpoint)
+			if tt.expectError == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expectError)
+		})
+	}
+}
+
+func TestValidateEndpointAcceleratorProduct(t *testing.T) {
+	newEndpoint := func(accelerator map[string]string) *v1.Endpoint {
+		return &v1.Endpoint{
+			Metadata: &v1.Metadata{Name: "ep", Workspace: "ws"},
+			Spec: &v1.EndpointSpec{
+				Resources: &v1.ResourceSpec{GPU: pointy.String("1"), Accelerator: accelerator},
+			},
+		}
+	}
+
+	newCluster := func(resources *v1.ClusterResources) *v1.Cluster {
+		return &v1.Cluster{
+			Metadata: &v1.Metadata{Name: "cluster", Workspace: "ws"},
+			Status:   &v1.ClusterStatus{ResourceInfo: resources},
+		}
+	}
+
+	mixedGPUs := &v1.ClusterResources{
+		ResourceStatus: v1.ResourceStatus{
+			Allocatable: &v1.ResourceInfo{
+				AcceleratorGroups: map[v1.AcceleratorType]*v1.AcceleratorGroup{
+					v1.AcceleratorTypeNVIDIAGPU: {
+						Quantity: 12,
+						ProductGroups: map[v1.AcceleratorProduct]float64{
+							"NVIDIA_L20": 8,
+							"NVIDIA_A10": 4,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		endpoint    *v1.Endpoint
+		cluster     *v1.Cluster
+		expectError string
+	}{
+		{
+			name:     "no product",
+			endpoint: newEndpoint(map[string]string{v1.AcceleratorTypeKey: "nvidia_gpu"}),
+			cluster:  newCluster(mixedGPUs),
+		},
+		{
+			name:     "allocatable product",
+			endpoint: newEndpoint(map[string]string{v1.AcceleratorTypeKey: "nvidia_gpu", v1.AcceleratorProductKey: "NVIDIA_L20"}),
+			cluster:  newCluster(mixedGPUs),
+		},
+		{
+			name:        "product not in the cluster",
+			endpoint:    newEndpoint(map[string]string{v1.AcceleratorTypeKey: "nvidia_gpu", v1.AcceleratorProductKey: "NVIDIA_H100"}),
+			cluster:     newCluster(mixedGPUs),
+			expectError: `accelerator product "NVIDIA_H100" is not allocatable in deploy cluster ws/cluster, nvidia_gpu products: [NVIDIA_A10 NVIDIA_L20]`,
+		},
+		{
+			name:        "product of another accelerator type",
+			endpoint:    newEndpoint(map[string]string{v1.AcceleratorTypeKey: "amd_gpu", v1.AcceleratorProductKey: "NVIDIA_L20"}),
+			cluster:     newCluster(mixedGPUs),
+			expectError: `accelerator product "NVIDIA_L20" is not allocatable in deploy cluster ws/cluster, amd_gpu products: []`,
+		},
+		{
+			name:        "product without accelerator type",
+			endpoint:    newEndpoint(map[string]string{v1.AcceleratorProductKey: "NVIDIA_L20"}),
+			cluster:     newCluster(mixedGPUs),
+			expectError: `accelerator product "NVIDIA_L20" requires an accelerator type`,
+		},
+		{
+			name:     "cluster not reporting its resources yet",
+			endpoint: newEndpoint(map[string]string{v1.AcceleratorTypeKey: "nvidia_gpu", v1.AcceleratorProductKey: "NVIDIA_H100"}),
+			cluster:  newCluster(nil),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateEndpointAcceleratorProduct(tt.endpoint, tt.cluster)
+			if tt.expectError == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.EqualError(t, err, tt.expectError)
+		})
+	}
+}
@@ -3,6 +3,7 @@ package dashboard
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -20,9 +21,24 @@ type DashboardService interface {
 	GetServeApplications() (*RayServeApplicationsResponse, error)
 	UpdateServeApplications(appsReq RayServeApplicationsRequest) error
 	GetActorLog(actorID, suffix string, lines int) (string, error)
+	// GetServeOngoingRequests returns the requests the serve replicas are processing, by application.
+	GetServeOngoingRequests() (map[string]int, error)
 
 	// State API: actor listing with filters
 	ListActors(filters []ActorFilter, detail bool, limit int) (*ActorsResponse, error)
+
+	// State API: named placement group lookup
+	GetPlacementGroup(name string) (*PlacementGroup, error)
+}
+
+// ErrDashboardUnreachable is returned when the Ray dashboard cannot be reached or its gateway
+// reports it unavailable, e.g. while the head node restarts.
+var ErrDashboardUnreachable = errors.New("ray dashboard unreachable")
+
+// IsDashboardUnreachable reports whether err is caused by the Ray dashboard being unreachable,
+// as opposed to the dashboard rejecting the request.
+func IsDashboardUnreachable(err error) bool {
+	return errors.Is(err, ErrDashboardUnreachable)
 }
 
 type Client struct {
@@ -68,11 +84,15 @@ func (c *Client) doRequest(method, path string, body, result interface{}) error
 
 	resp, err := c.client.Do(req)
 	if err != nil {
-		return err
+		return fmt.Errorf("%w: %w", ErrDashboardUnreachable, err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return fmt.Errorf("%w: API request failed: %s", ErrDashboardUnreachable, resp.Status)
+	default:
 		return fmt.Errorf("API request failed: %s", resp.Status)
 	}
 
@@ -0,0 +1,44 @@
+package dashboard
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_GetServeApplications_DashboardUnreachable(t *testing.T) {
+	tests := []struct {
+		name            string
+		status          int
+		stopServer      bool
+		wantUnreachable bool
+	}{
+		{name: "connection refused", stopServer: true, wantUnreachable: true},
+		{name: "head node restarting behind a proxy", status: http.StatusServiceUnavailable, wantUnreachable: true},
+		{name: "request rejected", status: http.StatusInternalServerError, wantUnreachable: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(tt.status)
+			}))
+			if tt.stopServer {
+				srv.Close()
+			} else {
+				defer srv.Close()
+			}
+
+			c := &Client{dashboardURL: srv.URL, client: &http.Client{}}
+
+			resp, err := c.GetServeApplications()
+
+			require.Error(t, err)
+			assert.Nil(t, resp)
+			assert.Equal(t, tt.wantUnreachable, IsDashboardUnreachable(err))
+		})
+	}
+}
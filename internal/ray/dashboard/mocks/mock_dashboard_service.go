@@ -192,6 +192,64 @@ func (_c *MockDashboardService_GetClusterStatus_Call) RunAndReturn(run func() (v
 	return _c
 }
 
+// GetPlacementGroup provides a mock function with given fields: name
+func (_m *MockDashboardService) GetPlacementGroup(name string) (*dashboard.PlacementGroup, error) {
+	ret := _m.Called(name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPlacementGroup")
+	}
+
+	var r0 *dashboard.PlacementGroup
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (*dashboard.PlacementGroup, error)); ok {
+		return rf(name)
+	}
+	if rf, ok := ret.Get(0).(func(string) *dashboard.PlacementGroup); ok {
+		r0 = rf(name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dashboard.PlacementGroup)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDashboardService_GetPlacementGroup_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetPlacementGroup'
+type MockDashboardService_GetPlacementGroup_Call struct {
+	*mock.Call
+}
+
+// GetPlacementGroup is a helper method to define mock.On call
+//   - name string
+func (_e *MockDashboardService_Expecter) GetPlacementGroup(name interface{}) *MockDashboardService_GetPlacementGroup_Call {
+	return &MockDashboardService_GetPlacementGroup_Call{Call: _e.mock.On("GetPlacementGroup", name)}
+}
+
+func (_c *MockDashboardService_GetPlacementGroup_Call) Run(run func(name string)) *MockDashboardService_GetPlacementGroup_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *MockDashboardService_GetPlacementGroup_Call) Return(_a0 *dashboard.PlacementGroup, _a1 error) *MockDashboardService_GetPlacementGroup_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDashboardService_GetPlacementGroup_Call) RunAndReturn(run func(string) (*dashboard.PlacementGroup, error)) *MockDashboardService_GetPlacementGroup_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetServeApplications provides a mock function with no fields
 func (_m *MockDashboardService) GetServeApplications() (*dashboard.RayServeApplicationsResponse, error) {
 	ret := _m.Called()
@@ -249,6 +307,63 @@ func (_c *MockDashboardService_GetServeApplications_Call) RunAndReturn(run func(
 	return _c
 }
 
+// GetServeOngoingRequests provides a mock function with no fields
+func (_m *MockDashboardService) GetServeOngoingRequests() (map[string]int, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetServeOngoingRequests")
+	}
+
+	var r0 map[string]int
+	var r1 error
+	if rf, ok := ret.Get(0).(func() (map[string]int, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() map[string]int); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string]int)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDashboardService_GetServeOngoingRequests_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetServeOngoingRequests'
+type MockDashboardService_GetServeOngoingRequests_Call struct {
+	*mock.Call
+}
+
+// GetServeOngoingRequests is a helper method to define mock.On call
+func (_e *MockDashboardService_Expecter) GetServeOngoingRequests() *MockDashboardService_GetServeOngoingRequests_Call {
+	return &MockDashboardService_GetServeOngoingRequests_Call{Call: _e.mock.On("GetServeOngoingRequests")}
+}
+
+func (_c *MockDashboardService_GetServeOngoingRequests_Call) Run(run func()) *MockDashboardService_GetServeOngoingRequests_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockDashboardService_GetServeOngoingRequests_Call) Return(_a0 map[string]int, _a1 error) *MockDashboardService_GetServeOngoingRequests_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDashboardService_GetServeOngoingRequests_Call) RunAndReturn(run func() (map[string]int, error)) *MockDashboardService_GetServeOngoingRequests_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // ListActors provides a mock function with given fields: filters, detail, limit
 func (_m *MockDashboardService) ListActors(filters []dashboard.ActorFilter, detail bool, limit int) (*dashboard.ActorsResponse, error) {
 	ret := _m.Called(filters, detail, limit)
@@ -0,0 +1,68 @@
+package dashboard
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/pkg/errors"
+)
+
+// PlacementGroupStateCreated is the state of a placement group whose bundles are all reserved.
+const PlacementGroupStateCreated = "CREATED"
+
+// PlacementGroupsResponse is the envelope returned by GET /api/v0/placement_groups.
+type PlacementGroupsResponse struct {
+	Result bool                        `json:"result"`
+	Msg    string                      `json:"msg"`
+	Data   PlacementGroupsResponseData `json:"data"`
+}
+
+type PlacementGroupsResponseData struct {
+	Result PlacementGroupsListResult `json:"result"`
+}
+
+type PlacementGroupsListResult struct {
+	Total  int              `json:"total"`
+	Result []PlacementGroup `json:"result"`
+}
+
+// PlacementGroup mirrors fields exposed by Ray's state API for placement groups.
+// Bundles only populate when detail=true is sent in the request.
+type PlacementGroup struct {
+	PlacementGroupID string                 `json:"placement_group_id"`
+	Name             string                 `json:"name"`
+	State            string                 `json:"state"`
+	Strategy         string                 `json:"strategy"`
+	Bundles          []PlacementGroupBundle `json:"bundles,omitempty"`
+}
+
+type PlacementGroupBundle struct {
+	BundleID      string             `json:"bundle_id"`
+	NodeID        string             `json:"node_id"`
+	UnitResources map[string]float64 `json:"unit_resources"`
+}
+
+// GetPlacementGroup returns the named placement group with its bundles, or nil if no
+// placement group has the name.
+func (c *Client) GetPlacementGroup(name string) (*PlacementGroup, error) {
+	q := url.Values{}
+	q.Add("filter_keys", "name")
+	q.Add("filter_predicates", "=")
+	q.Add("filter_values", name)
+	q.Set("detail", "true")
+
+	var resp PlacementGroupsResponse
+	if err := c.doRequest(http.MethodGet, "/api/v0/placement_groups?"+q.Encode(), nil, &resp); err != nil {
+		return nil, errors.Wrapf(err, "failed to get placement group %s", name)
+	}
+
+	// a removed placement group is kept in the state API until it is garbage collected.
+	for i := range resp.Data.Result.Result {
+		pg := &resp.Data.Result.Result[i]
+		if pg.Name == name && pg.State != "REMOVED" {
+			return pg, nil
+		}
+	}
+
+	return nil, nil
+}
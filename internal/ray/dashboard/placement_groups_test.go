@@ -0,0 +1,60 @@
+package dashboard
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_GetPlacementGroup(t *testing.T) {
+	body := `{
+		"result": true,
+		"msg": "",
+		"data": {
+			"result": {
+				"total": 2,
+				"result": [
+					{"placement_group_id": "old", "name": "tp-gang", "state": "REMOVED", "strategy": "PACK"},
+					{
+						"placement_group_id": "abc123",
+						"name": "tp-gang",
+						"state": "CREATED",
+						"strategy": "STRICT_PACK",
+						"bundles": [{"bundle_id": "b0", "node_id": "node-1", "unit_resources": {"CPU": 1, "GPU": 4}}]
+					}
+				]
+			}
+		}
+	}`
+
+	var capturedQuery map[string][]string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v0/placement_groups", r.URL.Path)
+		capturedQuery = r.URL.Query()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	c := &Client{dashboardURL: srv.URL, client: &http.Client{}}
+
+	pg, err := c.GetPlacementGroup("tp-gang")
+	require.NoError(t, err)
+	require.NotNil(t, pg)
+
+	assert.Equal(t, []string{"name"}, capturedQuery["filter_keys"])
+	assert.Equal(t, []string{"tp-gang"}, capturedQuery["filter_values"])
+	assert.Equal(t, []string{"true"}, capturedQuery["detail"])
+
+	assert.Equal(t, "abc123", pg.PlacementGroupID)
+	assert.Equal(t, "CREATED", pg.State)
+	assert.Equal(t, map[string]float64{"CPU": 1, "GPU": 4}, pg.Bundles[0].UnitResources)
+
+	pg, err = c.GetPlacementGroup("other")
+	require.NoError(t, err)
+	assert.Nil(t, pg)
+}
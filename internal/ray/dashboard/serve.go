@@ -53,6 +53,7 @@ type Replica struct {
 	ActorID     string `json:"actor_id"`
 	LogFilePath string `json:"log_file_path"`
 	ReplicaID   string `json:"replica_id"`
+	State       string `json:"state,omitempty"`
 }
 
 type ProxyStatus struct {
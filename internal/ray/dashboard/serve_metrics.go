@@ -0,0 +1,88 @@
+package dashboard
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/prometheus/common/expfmt"
+
+	v1 "github.com/neutree-ai/neutree/api/v1"
+)
+
+// serveReplicaProcessingQueriesMetric is the gauge of the requests a Ray Serve replica is
+// processing, labeled with the application and deployment of the replica.
+const serveReplicaProcessingQueriesMetric = "ray_serve_replica_processing_queries"
+
+// GetServeOngoingRequests returns the requests the Ray Serve replicas are processing, by
+// application. The Ray Serve API does not report them, so they are read from the metrics
+// every alive node exports on v1.RayletMetricsPort. The metrics are reported periodically,
+// they lag the replicas by the Ray metrics report interval.
+func (c *Client) GetServeOngoingRequests() (map[string]int, error) {
+	nodes, err := c.ListNodes()
+	if err != nil {
+		return nil, err
+	}
+
+	ongoing := map[string]int{}
+
+	for _, node := range nodes {
+		if node.Raylet.State != v1.AliveNodeState {
+			continue
+		}
+
+		metricsURL := "http://" + net.JoinHostPort(node.IP, strconv.Itoa(v1.RayletMetricsPort)) + "/metrics"
+
+		if err := c.addNodeServeOngoingRequests(metricsURL, ongoing); err != nil {
+			return nil, fmt.Errorf("get serve metrics of node %s: %w", node.IP, err)
+		}
+	}
+
+	return ongoing, nil
+}
+
+func (c *Client) addNodeServeOngoingRequests(metricsURL string, ongoing map[string]int) error {
+	resp, err := c.client.Get(metricsURL)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("metrics request failed: %s", resp.Status)
+	}
+
+	return addServeOngoingRequests(resp.Body, ongoing)
+}
+
+// addServeOngoingRequests adds the requests processed by the replicas in the Prometheus text
+// exposition read from r to ongoing, by application.
+func addServeOngoingRequests(r io.Reader, ongoing map[string]int) error {
+	var parser expfmt.TextParser
+
+	families, err := parser.TextToMetricFamilies(r)
+	if err != nil {
+		return err
+	}
+
+	family, ok := families[serveReplicaProcessingQueriesMetric]
+	if !ok {
+		return nil
+	}
+
+	for _, metric := range family.GetMetric() {
+		application := ""
+
+		for _, label := range metric.GetLabel() {
+			if label.GetName() == "application" {
+				application = label.GetValue()
+			}
+		}
+
+		ongoing[application] += int(metric.GetGauge().GetValue())
+	}
+
+	return nil
+}
@@ -0,0 +1,33 @@
+package dashboard
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddServeOngoingRequests(t *testing.T) {
+	metrics := `# HELP ray_serve_replica_processing_queries The current number of queries being processed.
+# TYPE ray_serve_replica_processing_queries gauge
+ray_serve_replica_processing_queries{application="production_chat-model",deployment="Controller",replica="a"} 3.0
+ray_serve_replica_processing_queries{application="production_chat-model",deployment="Backend",replica="b"} 2.0
+ray_serve_replica_processing_queries{application="production_other",deployment="Backend",replica="c"} 0.0
+# HELP ray_serve_num_http_requests_total The number of HTTP requests processed.
+# TYPE ray_serve_num_http_requests_total counter
+ray_serve_num_http_requests_total{application="production_chat-model"} 42.0
+`
+
+	ongoing := map[string]int{"production_chat-model": 1}
+
+	require.NoError(t, addServeOngoingRequests(strings.NewReader(metrics), ongoing))
+	assert.Equal(t, map[string]int{"production_chat-model": 6, "production_other": 0}, ongoing)
+}
+
+func TestAddServeOngoingRequests_NoServeReplica(t *testing.T) {
+	ongoing := map[string]int{}
+
+	require.NoError(t, addServeOngoingRequests(strings.NewReader("ray_node_cpu_count 8\n"), ongoing))
+	assert.Empty(t, ongoing)
+}
@@ -0,0 +1,307 @@
+package rayserve
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+
+	"github.com/neutree-ai/neutree/internal/ray/dashboard"
+)
+
+const (
+	ReplicaStateRunning = "RUNNING"
+
+	backendDeploymentName = "backend"
+)
+
+// WarmReplacementPollInterval is how often the replacement replicas are checked for readiness.
+var WarmReplacementPollInterval = 5 * time.Second
+
+// clusterLocks provides per-cluster mutexes to serialize Ray Serve application
+// updates (read-modify-write on PUT /api/serve/applications/) and prevent
+// concurrent workers from overwriting each other's changes.
+var clusterLocks sync.Map
+
+func ClusterLock(clusterKey string) *sync.Mutex {
+	actual, _ := clusterLocks.LoadOrStore(clusterKey, &sync.Mutex{})
+	return actual.(*sync.Mutex) //nolint:errcheck // type is guaranteed by LoadOrStore
+}
+
+// warmReplacements records, per cluster and serve application, the extra backend replicas
+// started to replace the ones of draining nodes. The endpoint orchestrator keeps them in the
+// applications it applies, so an endpoint reconcile does not scale them down mid drain.
+var (
+	warmReplacementsMu sync.Mutex
+	warmReplacements   = map[string]map[string]int{}
+)
+
+func addWarmReplacementSurge(clusterKey string, surge map[string]int, sign int) {
+	warmReplacementsMu.Lock()
+	defer warmReplacementsMu.Unlock()
+
+	apps := warmReplacements[clusterKey]
+	if apps == nil {
+		apps = map[string]int{}
+		warmReplacements[clusterKey] = apps
+	}
+
+	for app, n := range surge {
+		apps[app] += sign * n
+		if apps[app] <= 0 {
+			delete(apps, app)
+		}
+	}
+
+	if len(apps) == 0 {
+		delete(warmReplacements, clusterKey)
+	}
+}
+
+// WarmReplacementSurge returns the extra backend replicas of the application while a node of
+// the cluster is drained.
+func WarmReplacementSurge(clusterKey, appName string) int {
+	warmReplacementsMu.Lock()
+	defer warmReplacementsMu.Unlock()
+
+	return warmReplacements[clusterKey][appName]
+}
+
+// ApplyWarmReplacementSurge adds the warm replacement surge to the backend replicas of the
+// applications about to be applied to the cluster.
+func ApplyWarmReplacementSurge(clusterKey string, apps []dashboard.RayServeApplication) {
+	for i := range apps {
+		surge := WarmReplacementSurge(clusterKey, apps[i].Name)
+		if surge == 0 {
+			continue
+		}
+
+		if n, ok := BackendNumReplicas(&apps[i]); ok {
+			setBackendNumReplicas(&apps[i], n+surge)
+		}
+	}
+}
+
+func backendConfig(app *dashboard.RayServeApplication) map[string]interface{} {
+	deploymentOptions, _ := app.Args["deployment_options"].(map[string]interface{})
+	backend, _ := deploymentOptions["backend"].(map[string]interface{})
+
+	return backend
+}
+
+// BackendNumReplicas returns the fixed number of backend replicas of the application, false if
+// the application is autoscaled.
+func BackendNumReplicas(app *dashboard.RayServeApplication) (int, bool) {
+	switch n := backendConfig(app)["num_replicas"].(type) {
+	case int:
+		return n, true
+	case *int:
+		if n == nil {
+			return 0, false
+		}
+
+		return *n, true
+	case int64:
+		return int(n), true
+	case float64:
+		return int(n), true
+	default:
+		return 0, false
+	}
+}
+
+func setBackendNumReplicas(app *dashboard.RayServeApplication, n int) {
+	backendConfig(app)["num_replicas"] = n
+}
+
+func backendReplicas(appStatus dashboard.RayServeApplicationStatus) []dashboard.Replica {
+	var replicas []dashboard.Replica
+
+	for key, deployment := range appStatus.Deployments {
+		name := deployment.Name
+		if name == "" {
+			name = key
+		}
+
+		if strings.EqualFold(name, backendDeploymentName) {
+			replicas = append(replicas, deployment.Replicas...)
+		}
+	}
+
+	return replicas
+}
+
+// WarmReplaceNodeReplicas scales up every serve application with backend replicas on the node by
+// the number of those replicas and waits until as many replicas as before run on other nodes, so
+// draining the node keeps the serving capacity. The returned release removes the surge once the
+// node is drained, it must be called even if the wait failed.
+func WarmReplaceNodeReplicas(ctx context.Context, svc dashboard.DashboardService, clusterKey, nodeID string,
+	timeout time.Duration) (func() error, error) {
+	surge, targets, err := surgeNodeReplicas(svc, clusterKey, nodeID)
+	if err != nil {
+		return func() error { return nil }, err
+	}
+
+	release := func() error {
+		return releaseWarmReplacement(svc, clusterKey, surge)
+	}
+
+	if len(surge) == 0 {
+		return release, nil
+	}
+
+	klog.Infof("Warm replacing replicas of node %s in cluster %s: %v", nodeID, clusterKey, surge)
+
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(WarmReplacementPollInterval)
+	defer ticker.Stop()
+
+	for {
+		ready, err := replacementsReady(svc, nodeID, targets)
+		if err != nil {
+			klog.Warningf("failed to check replacement replicas of node %s: %v", nodeID, err)
+		}
+
+		if ready {
+			return release, nil
+		}
+
+		select {
+		case <-waitCtx.Done():
+			return release, errors.Errorf("replacement replicas of node %s are not running after %s", nodeID, timeout)
+		case <-ticker.C:
+		}
+	}
+}
+
+// surgeNodeReplicas adds a backend replica for every one running on the node, returning the added
+// replicas and the replicas each application needs on other nodes.
+func surgeNodeReplicas(svc dashboard.DashboardService, clusterKey, nodeID string) (map[string]int, map[string]int, error) {
+	mu := ClusterLock(clusterKey)
+	mu.Lock()
+	defer mu.Unlock()
+
+	appsResp, err := svc.GetServeApplications()
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to get serve applications")
+	}
+
+	surge := map[string]int{}
+	targets := map[string]int{}
+	apps := make([]dashboard.RayServeApplication, 0, len(appsResp.Applications))
+
+	for _, name := range SortedServeApplicationNames(appsResp) {
+		appStatus := appsResp.Applications[name]
+		if appStatus.DeployedAppConfig == nil {
+			continue
+		}
+
+		app := *appStatus.DeployedAppConfig
+
+		onNode := 0
+
+		for _, replica := range backendReplicas(appStatus) {
+			if replica.NodeID == nodeID {
+				onNode++
+			}
+		}
+
+		n, ok := BackendNumReplicas(&app)
+		if onNode > 0 && ok {
+			setBackendNumReplicas(&app, n+onNode)
+
+			surge[app.Name] = onNode
+			targets[app.Name] = n
+		}
+
+		apps = append(apps, app)
+	}
+
+	if len(surge) == 0 {
+		return nil, nil, nil
+	}
+
+	err = svc.UpdateServeApplications(dashboard.RayServeApplicationsRequest{Applications: apps})
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to scale up serve applications")
+	}
+
+	addWarmReplacementSurge(clusterKey, surge, 1)
+
+	return surge, targets, nil
+}
+
+func replacementsReady(svc dashboard.DashboardService, nodeID string, targets map[string]int) (bool, error) {
+	appsResp, err := svc.GetServeApplications()
+	if err != nil {
+		return false, errors.Wrap(err, "failed to get serve applications")
+	}
+
+	for name, target := range targets {
+		appStatus, ok := appsResp.Applications[name]
+		if !ok {
+			// the application was removed meanwhile, nothing to replace.
+			continue
+		}
+
+		running := 0
+
+		for _, replica := range backendReplicas(appStatus) {
+			if replica.NodeID != nodeID && replica.State == ReplicaStateRunning {
+				running++
+			}
+		}
+
+		if running < target {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+func releaseWarmReplacement(svc dashboard.DashboardService, clusterKey string, surge map[string]int) error {
+	if len(surge) == 0 {
+		return nil
+	}
+
+	mu := ClusterLock(clusterKey)
+	mu.Lock()
+	defer mu.Unlock()
+
+	addWarmReplacementSurge(clusterKey, surge, -1)
+
+	appsResp, err := svc.GetServeApplications()
+	if err != nil {
+		return errors.Wrap(err, "failed to get serve applications")
+	}
+
+	apps := make([]dashboard.RayServeApplication, 0, len(appsResp.Applications))
+
+	for _, name := range SortedServeApplicationNames(appsResp) {
+		appStatus := appsResp.Applications[name]
+		if appStatus.DeployedAppConfig == nil {
+			continue
+		}
+
+		app := *appStatus.DeployedAppConfig
+
+		if n, ok := BackendNumReplicas(&app); ok && surge[app.Name] > 0 {
+			setBackendNumReplicas(&app, max(n-surge[app.Name], 0))
+		}
+
+		apps = append(apps, app)
+	}
+
+	err = svc.UpdateServeApplications(dashboard.RayServeApplicationsRequest{Applications: apps})
+	if err != nil {
+		return errors.Wrap(err, "failed to scale down serve applications")
+	}
+
+	return nil
+}
@@ -0,0 +1,182 @@
+package rayserve
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neutree-ai/neutree/internal/ray/dashboard"
+	dashboardmocks "github.com/neutree-ai/neutree/internal/ray/dashboard/mocks"
+)
+
+func newServeApp(name string, numReplicas int) *dashboard.RayServeApplication {
+	return &dashboard.RayServeApplication{
+		Name:        name,
+		RoutePrefix: "/" + name,
+		ImportPath:  "serve.vllm.app:app_builder",
+		Args: map[string]interface{}{
+			"deployment_options": map[string]interface{}{
+				"backend":    map[string]interface{}{"num_replicas": float64(numReplicas)},
+				"controller": map[string]interface{}{"num_replicas": float64(1)},
+			},
+		},
+	}
+}
+
+func newServeAppStatus(app *dashboard.RayServeApplication, replicas ...dashboard.Replica) dashboard.RayServeApplicationStatus {
+	return dashboard.RayServeApplicationStatus{
+		Status:            dashboard.ApplicationStatusRunning,
+		DeployedAppConfig: app,
+		Deployments: map[string]dashboard.Deployment{
+			"Backend":    {Name: "Backend", Replicas: replicas},
+			"Controller": {Name: "Controller", Replicas: []dashboard.Replica{{NodeID: "node-1", State: ReplicaStateRunning}}},
+		},
+	}
+}
+
+func running(nodeID string) dashboard.Replica {
+	return dashboard.Replica{NodeID: nodeID, State: ReplicaStateRunning}
+}
+
+func numReplicasOf(t *testing.T, req dashboard.RayServeApplicationsRequest, name string) int {
+	for i := range req.Applications {
+		if req.Applications[i].Name == name {
+			n, ok := BackendNumReplicas(&req.Applications[i])
+			require.True(t, ok)
+
+			return n
+		}
+	}
+
+	t.Fatalf("application %s not in request", name)
+
+	return 0
+}
+
+func TestWarmReplaceNodeReplicas(t *testing.T) {
+	WarmReplacementPollInterval = time.Millisecond
+
+	t.Cleanup(func() { WarmReplacementPollInterval = 5 * time.Second })
+
+	tests := []struct {
+		name string
+		// polled are the backend replicas of app "chat" observed while waiting for the replacement.
+		polled    [][]dashboard.Replica
+		timeout   time.Duration
+		expectErr bool
+	}{
+		{
+			name: "waits for the replacement replica before returning",
+			polled: [][]dashboard.Replica{
+				{running("node-1"), running("node-2"), {NodeID: "node-3", State: "STARTING"}},
+				{running("node-1"), running("node-2"), running("node-3")},
+			},
+			timeout: time.Minute,
+		},
+		{
+			name: "replacement not running in time",
+			polled: [][]dashboard.Replica{
+				{running("node-1"), running("node-2"), {NodeID: "node-3", State: "STARTING"}},
+			},
+			timeout:   20 * time.Millisecond,
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := dashboardmocks.NewMockDashboardService(t)
+
+			// chat has a replica on the drained node-1, embed has none.
+			responses := []map[string]dashboard.RayServeApplicationStatus{{
+				"chat":  newServeAppStatus(newServeApp("chat", 2), running("node-1"), running("node-2")),
+				"embed": newServeAppStatus(newServeApp("embed", 1), running("node-2")),
+			}}
+			for _, replicas := range tt.polled {
+				responses = append(responses, map[string]dashboard.RayServeApplicationStatus{
+					"chat": newServeAppStatus(newServeApp("chat", 3), replicas...),
+				})
+			}
+
+			// the last response is repeated until the wait times out.
+			svc.EXPECT().GetServeApplications().RunAndReturn(func() (*dashboard.RayServeApplicationsResponse, error) {
+				apps := responses[0]
+				if len(responses) > 1 {
+					responses = responses[1:]
+				}
+
+				return &dashboard.RayServeApplicationsResponse{Applications: apps}, nil
+			})
+			svc.EXPECT().UpdateServeApplications(mock.Anything).Run(func(req dashboard.RayServeApplicationsRequest) {
+				assert.Equal(t, 3, numReplicasOf(t, req, "chat"))
+				assert.Equal(t, 1, numReplicasOf(t, req, "embed"))
+			}).Return(nil).Once()
+
+			release, err := WarmReplaceNodeReplicas(context.Background(), svc, "default/ssh", "node-1", tt.timeout)
+			if tt.expectErr {
+				assert.ErrorContains(t, err, "replacement replicas of node node-1 are not running")
+			} else {
+				require.NoError(t, err)
+				assert.Len(t, responses, 1, "all polled states should be observed")
+			}
+
+			// the endpoint orchestrator keeps the replacement until the node is drained.
+			assert.Equal(t, 1, WarmReplacementSurge("default/ssh", "chat"))
+			assert.Equal(t, 0, WarmReplacementSurge("default/ssh", "embed"))
+
+			// the drained node replica is gone, the surge is removed.
+			responses = []map[string]dashboard.RayServeApplicationStatus{{
+				"chat":  newServeAppStatus(newServeApp("chat", 3), running("node-2"), running("node-3")),
+				"embed": newServeAppStatus(newServeApp("embed", 1), running("node-2")),
+			}}
+			svc.EXPECT().UpdateServeApplications(mock.Anything).Run(func(req dashboard.RayServeApplicationsRequest) {
+				assert.Equal(t, 2, numReplicasOf(t, req, "chat"))
+				assert.Equal(t, 1, numReplicasOf(t, req, "embed"))
+			}).Return(nil).Once()
+
+			require.NoError(t, release())
+			assert.Equal(t, 0, WarmReplacementSurge("default/ssh", "chat"))
+		})
+	}
+}
+
+func TestWarmReplaceNodeReplicas_NoReplicasOnNode(t *testing.T) {
+	svc := dashboardmocks.NewMockDashboardService(t)
+	svc.EXPECT().GetServeApplications().Return(&dashboard.RayServeApplicationsResponse{
+		Applications: map[string]dashboard.RayServeApplicationStatus{
+			"chat": newServeAppStatus(newServeApp("chat", 1), running("node-2")),
+		},
+	}, nil).Once()
+
+	release, err := WarmReplaceNodeReplicas(context.Background(), svc, "default/ssh", "node-1", time.Minute)
+	require.NoError(t, err)
+	require.NoError(t, release())
+}
+
+func TestApplyWarmReplacementSurge(t *testing.T) {
+	addWarmReplacementSurge("default/surge", map[string]int{"chat": 2}, 1)
+	t.Cleanup(func() { addWarmReplacementSurge("default/surge", map[string]int{"chat": 2}, -1) })
+
+	num := 1
+	chat := newServeApp("chat", 0)
+	chat.Args["deployment_options"].(map[string]interface{})["backend"] = map[string]interface{}{"num_replicas": &num}
+	autoscaled := newServeApp("auto", 0)
+	delete(autoscaled.Args["deployment_options"].(map[string]interface{})["backend"].(map[string]interface{}), "num_replicas")
+
+	apps := []dashboard.RayServeApplication{*chat, *newServeApp("embed", 1), *autoscaled}
+	ApplyWarmReplacementSurge("default/surge", apps)
+
+	n, ok := BackendNumReplicas(&apps[0])
+	assert.True(t, ok)
+	assert.Equal(t, 3, n)
+
+	n, _ = BackendNumReplicas(&apps[1])
+	assert.Equal(t, 1, n)
+
+	_, ok = BackendNumReplicas(&apps[2])
+	assert.False(t, ok)
+}
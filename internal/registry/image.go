@@ -18,6 +18,8 @@ type ImageService interface {
 	ListImageTags(imageRepo string, auth authn.Authenticator) ([]string, error)
 	// GetImageLabels returns the labels from an image's config.
 	GetImageLabels(image string, auth authn.Authenticator) (map[string]string, error)
+	// GetImageDigest resolves the image reference to the digest of its manifest, e.g. "sha256:...".
+	GetImageDigest(image string, auth authn.Authenticator) (string, error)
 }
 
 type imageService struct {
@@ -100,6 +102,20 @@ func (svc *imageService) GetImageLabels(image string, auth authn.Authenticator)
 	return cfg.Config.Labels, nil
 }
 
+func (svc *imageService) GetImageDigest(image string, auth authn.Authenticator) (string, error) {
+	ref, err := name.ParseReference(image)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to parse image "+image)
+	}
+
+	desc, err := remote.Head(ref, remote.WithAuth(auth), remote.WithTransport(svc.transport))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to request image "+image)
+	}
+
+	return desc.Digest.String(), nil
+}
+
 func (svc *imageService) ListImageTags(imageRepo string, auth authn.Authenticator) ([]string, error) {
 	repo, err := name.NewRepository(imageRepo)
 	if err != nil {
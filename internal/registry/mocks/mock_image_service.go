@@ -134,6 +134,63 @@ func (_c *MockImageService_CheckPullPermission_Call) RunAndReturn(run func(strin
 	return _c
 }
 
+// GetImageDigest provides a mock function with given fields: image, auth
+func (_m *MockImageService) GetImageDigest(image string, auth authn.Authenticator) (string, error) {
+	ret := _m.Called(image, auth)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetImageDigest")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, authn.Authenticator) (string, error)); ok {
+		return rf(image, auth)
+	}
+	if rf, ok := ret.Get(0).(func(string, authn.Authenticator) string); ok {
+		r0 = rf(image, auth)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, authn.Authenticator) error); ok {
+		r1 = rf(image, auth)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockImageService_GetImageDigest_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetImageDigest'
+type MockImageService_GetImageDigest_Call struct {
+	*mock.Call
+}
+
+// GetImageDigest is a helper method to define mock.On call
+//   - image string
+//   - auth authn.Authenticator
+func (_e *MockImageService_Expecter) GetImageDigest(image interface{}, auth interface{}) *MockImageService_GetImageDigest_Call {
+	return &MockImageService_GetImageDigest_Call{Call: _e.mock.On("GetImageDigest", image, auth)}
+}
+
+func (_c *MockImageService_GetImageDigest_Call) Run(run func(image string, auth authn.Authenticator)) *MockImageService_GetImageDigest_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(authn.Authenticator))
+	})
+	return _c
+}
+
+func (_c *MockImageService_GetImageDigest_Call) Return(_a0 string, _a1 error) *MockImageService_GetImageDigest_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockImageService_GetImageDigest_Call) RunAndReturn(run func(string, authn.Authenticator) (string, error)) *MockImageService_GetImageDigest_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetImageLabels provides a mock function with given fields: image, auth
 func (_m *MockImageService) GetImageLabels(image string, auth authn.Authenticator) (map[string]string, error) {
 	ret := _m.Called(image, auth)
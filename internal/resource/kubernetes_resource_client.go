@@ -22,11 +22,12 @@ import (
 const kubernetesDefaultDeviceCoreUnits = int64(100)
 
 type kubernetesNeutreeDeviceAnnotation struct {
-	ID          string `json:"id,omitempty"`
-	UUID        string `json:"uuid,omitempty"`
-	MinorNumber *int   `json:"minor_number,omitempty"`
-	MemoryMiB   int64  `json:"memory_mib,omitempty"`
-	Healthy     bool   `json:"healthy,omitempty"`
+	ID           string `json:"id,omitempty"`
+	UUID         string `json:"uuid,omitempty"`
+	MinorNumber  *int   `json:"minor_number,omitempty"`
+	MemoryMiB    int64  `json:"memory_mib,omitempty"`
+	Healthy      bool   `json:"healthy,omitempty"`
+	NVLinkDomain string `json:"nvlink_domain,omitempty"`
 }
 
 type kubernetesNeutreeDeviceOrder struct {
@@ -40,12 +41,13 @@ type kubernetesNeutreeDeviceUsage struct {
 }
 
 type kubernetesNeutreeDeviceResource struct {
-	uuid        string
-	memoryMiB   int64
-	coreUnits   int64
-	healthy     bool
-	minorNumber *int
-	order       *int
+	uuid         string
+	memoryMiB    int64
+	coreUnits    int64
+	healthy      bool
+	minorNumber  *int
+	order        *int
+	nvlinkDomain string
 }
 
 type kubernetesNeutreeAcceleratorResources struct {
@@ -67,7 +69,7 @@ func NewK8sResourceClient(ctrClient client.Client, parsers map[string]resourcepa
 	}
 }
 
-func (c *K8sResourceClient) ListNodes(ctx context.Context, _ *v1.Cluster) ([]ResourceNode, error) {
+func (c *K8sResourceClient) ListNodes(ctx context.Context, cluster *v1.Cluster) ([]ResourceNode, error) {
 	nodeList := &corev1.NodeList{}
 	if err := c.client.List(ctx, nodeList); err != nil {
 		return nil, fmt.Errorf("failed to list k8s nodes: %w", err)
@@ -99,7 +101,7 @@ func (c *K8sResourceClient) ListNodes(ctx context.Context, _ *v1.Cluster) ([]Res
 		nodesByID[node.Name] = &nodeResourceInfo{
 			allocatableResource: node.Status.Allocatable.DeepCopy(),
 			availableResource:   node.Status.Allocatable.DeepCopy(),
-			labels:              node.Labels,
+			labels:              withGPUDevicePluginLabel(node.Labels, cluster),
 			annotations:         node.Annotations,
 		}
 	}
@@ -222,6 +224,28 @@ func (c *K8sResourceClient) ListEndpointInstances(
 	return instances, nil
 }
 
+// withGPUDevicePluginLabel adds the GPU device plugin mode of the cluster config to the labels
+// of a node, for the resource parsers to read its GPUs under the resource name of that mode.
+func withGPUDevicePluginLabel(labels map[string]string, cluster *v1.Cluster) map[string]string {
+	if cluster == nil || cluster.Spec == nil {
+		return labels
+	}
+
+	kubernetesConfig, err := util.ParseKubernetesClusterConfig(cluster)
+	if err != nil || kubernetesConfig.GPUDevicePlugin == "" {
+		return labels
+	}
+
+	withPlugin := make(map[string]string, len(labels)+1)
+	for key, value := range labels {
+		withPlugin[key] = value
+	}
+
+	withPlugin[v1.GPUDevicePluginLabelKey] = string(kubernetesConfig.GPUDevicePlugin)
+
+	return withPlugin
+}
+
 func kubernetesPodResourceContext(pod corev1.Pod) resourceparser.KubernetesPodResourceContext {
 	totalRequested, totalLimits := resourceutil.PodRequestsAndLimits(&pod)
 
@@ -434,12 +458,13 @@ func parseKubernetesNeutreeDeviceResources(
 
 		order := orders[uuid]
 		result = append(result, kubernetesNeutreeDeviceResource{
-			uuid:        uuid,
-			memoryMiB:   device.MemoryMiB,
-			coreUnits:   kubernetesDefaultDeviceCoreUnits,
-			healthy:     device.Healthy,
-			minorNumber: order.MinorNumber,
-			order:       order.Order,
+			uuid:         uuid,
+			memoryMiB:    device.MemoryMiB,
+			coreUnits:    kubernetesDefaultDeviceCoreUnits,
+			healthy:      device.Healthy,
+			minorNumber:  order.MinorNumber,
+			order:        order.Order,
+			nvlinkDomain: device.NVLinkDomain,
 		})
 	}
 
@@ -503,13 +528,14 @@ func buildKubernetesNeutreeAcceleratorResources(
 		}
 
 		deviceResources = append(deviceResources, &v1.DeviceResource{
-			UUID:        device.uuid,
-			Product:     string(product),
-			Health:      device.healthy,
-			MinorNumber: device.minorNumber,
-			Order:       device.order,
-			Allocatable: allocatable,
-			Available:   available,
+			UUID:         device.uuid,
+			Product:      string(product),
+			Health:       device.healthy,
+			MinorNumber:  device.minorNumber,
+			Order:        device.order,
+			Allocatable:  allocatable,
+			Available:    available,
+			NVLinkDomain: device.nvlinkDomain,
 		})
 
 		if !device.healthy {
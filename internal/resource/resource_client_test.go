@@ -349,8 +349,8 @@ func TestK8sResourceClientListNodesUsesNeutreeDeviceAnnotations(t *testing.T) {
 			},
 			Annotations: map[string]string{
 				resourceparser.NeutreeAcceleratorDevicesAnnotation: `[
-					{"uuid":"GPU-1","product_model":"Annotation-T4","memory_mib":15360,"healthy":true,"minor_number":3},
-					{"uuid":"GPU-2","product_model":"Annotation-T4","memory_mib":15360,"healthy":true,"minor_number":0}
+					{"uuid":"GPU-1","product_model":"Annotation-T4","memory_mib":15360,"healthy":true,"minor_number":3,"nvlink_domain":"nvlink-0"},
+					{"uuid":"GPU-2","product_model":"Annotation-T4","memory_mib":15360,"healthy":true,"minor_number":0,"nvlink_domain":"nvlink-0"}
 				]`,
 			},
 		},
@@ -401,6 +401,8 @@ func TestK8sResourceClientListNodesUsesNeutreeDeviceAnnotations(t *testing.T) {
 	require.Equal(t, 1, *nodes[0].Status.Devices[0].Order)
 	require.NotNil(t, nodes[0].Status.Devices[1].Order)
 	require.Equal(t, 0, *nodes[0].Status.Devices[1].Order)
+	require.Equal(t, "nvlink-0", nodes[0].Status.Devices[0].NVLinkDomain)
+	require.Equal(t, "nvlink-0", nodes[0].Status.Devices[1].NVLinkDomain)
 	allocatable := nodes[0].Status.Allocatable.AcceleratorGroups[v1.AcceleratorTypeNVIDIAGPU]
 	require.Equal(t, float64(2), allocatable.Quantity)
 	require.Equal(t, float64(30720), allocatable.Products["Tesla-T4"].Virtualization.MemoryMiB)
@@ -600,6 +602,49 @@ func TestK8sResourceClientListNodesUsesStandardParserWithoutNeutreeDeviceAnnotat
 		nodes[0].AcceleratorMetadata[v1.AcceleratorTypeNVIDIAGPU].Products["NVIDIA_A100"].MemoryTotalMiB)
 }
 
+func TestK8sResourceClientListNodesUsesGPUDevicePluginOfClusterConfig(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "gpu-node",
+			Labels: map[string]string{
+				plugin.NvidiaGPUKubernetesNodeSelectorKey: "NVIDIA_A100",
+			},
+		},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:                       k8sresource.MustParse("8"),
+				plugin.NvidiaGPUKubernetesResource:       k8sresource.MustParse("2"),
+				plugin.NvidiaGPUSharedKubernetesResource: k8sresource.MustParse("8"),
+			},
+		},
+	}
+
+	ctrClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(node).
+		Build()
+	client := resourceview.NewK8sResourceClient(ctrClient, map[string]resourceparser.ResourceParser{
+		string(v1.AcceleratorTypeNVIDIAGPU): &plugin.GPUResourceParser{},
+	})
+
+	cluster := &v1.Cluster{Spec: &v1.ClusterSpec{Config: &v1.ClusterConfig{
+		KubernetesConfig: &v1.KubernetesClusterConfig{GPUDevicePlugin: v1.GPUDevicePluginMPS},
+	}}}
+
+	nodes, err := client.ListNodes(context.Background(), cluster)
+
+	require.NoError(t, err)
+	require.Len(t, nodes, 1)
+
+	allocatable := nodes[0].Status.Allocatable.AcceleratorGroups[v1.AcceleratorTypeNVIDIAGPU]
+	require.Equal(t, float64(8), allocatable.Quantity)
+	require.Equal(t, float64(8), allocatable.ProductGroups["NVIDIA_A100"])
+	require.Empty(t, node.Labels[v1.GPUDevicePluginLabelKey])
+}
+
 func TestK8sResourceClientListNodesSubtractsGPURequestsWhenGPUCountLabelExists(t *testing.T) {
 	scheme := runtime.NewScheme()
 	require.NoError(t, corev1.AddToScheme(scheme))
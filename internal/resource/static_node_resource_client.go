@@ -235,13 +235,14 @@ func resourceNodeFromStaticNodeDeviceSnapshot(node *v1.StaticNode, base *Resourc
 		}
 
 		nodeStatus.Devices = append(nodeStatus.Devices, &v1.DeviceResource{
-			UUID:        device.UUID,
-			Product:     baseProduct,
-			Health:      device.Healthy,
-			MinorNumber: ordersByUUID[device.UUID].MinorNumber,
-			Order:       ordersByUUID[device.UUID].Order,
-			Allocatable: allocatablePool,
-			Available:   availablePool,
+			UUID:         device.UUID,
+			Product:      baseProduct,
+			Health:       device.Healthy,
+			MinorNumber:  ordersByUUID[device.UUID].MinorNumber,
+			Order:        ordersByUUID[device.UUID].Order,
+			Allocatable:  allocatablePool,
+			Available:    availablePool,
+			NVLinkDomain: device.NVLinkDomain,
 		})
 
 		addStaticNodeAcceleratorMetadata(metadata, acceleratorType, baseProduct, device.MemoryMiB)
@@ -55,6 +55,9 @@ func RegisterAuthRoutes(group *gin.RouterGroup, middlewares []gin.HandlerFunc, d
 	authGroup.POST("/logout", handleAuthProxy(deps))   // signOut
 	authGroup.GET("/authorize", handleAuthProxy(deps)) // OAuth authorize
 	authGroup.GET("/callback", handleAuthProxy(deps))  // OAuth callback
+
+	// API key validation of the auth sidecars of the endpoints, authenticated by the key itself
+	authGroup.GET("/validate", handleValidateAPIKey(deps))
 }
 
 func handleCreateUser(deps *Dependencies) gin.HandlerFunc {
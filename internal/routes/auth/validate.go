@@ -0,0 +1,74 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+
+	v1 "github.com/neutree-ai/neutree/api/v1"
+	"github.com/neutree-ai/neutree/internal/middleware"
+	"github.com/neutree-ai/neutree/pkg/storage"
+)
+
+// handleValidateAPIKey answers 204 No Content if the Authorization header of the request holds
+// an API key neutree issued and did not revoke, and 401 Unauthorized otherwise. It is the
+// validate_url of the auth sidecars of the endpoints on clusters where neutree does not front
+// the endpoint traffic.
+func handleValidateAPIKey(deps *Dependencies) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		err := validateAPIKey(deps, c.GetHeader("Authorization"))
+		if err == nil {
+			c.Status(http.StatusNoContent)
+			return
+		}
+
+		if !errors.Is(err, errInvalidAPIKey) {
+			klog.Errorf("Failed to validate API key: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to validate API key"})
+
+			return
+		}
+
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	}
+}
+
+var errInvalidAPIKey = errors.New("invalid API key")
+
+// validateAPIKey checks the API key of an Authorization header against the key stored for it,
+// so deleted, disabled and regenerated keys are rejected like the gateway does.
+func validateAPIKey(deps *Dependencies, authHeader string) error {
+	key := strings.TrimPrefix(authHeader, "Bearer ")
+	if key == "" {
+		return errors.Wrap(errInvalidAPIKey, "Authorization header is required")
+	}
+
+	parsed, err := middleware.ParseAPIKey(key, deps.AuthConfig)
+	if err != nil {
+		return errors.Wrap(errInvalidAPIKey, err.Error())
+	}
+
+	apiKey, err := deps.Storage.GetApiKey(*parsed.KeyID)
+	if err != nil {
+		if errors.Is(err, storage.ErrResourceNotFound) {
+			return errors.Wrap(errInvalidAPIKey, "API key not found")
+		}
+
+		return errors.Wrap(err, "failed to get API key")
+	}
+
+	if apiKey.GetDeletionTimestamp() != "" || apiKey.Status == nil || apiKey.Status.Phase != v1.ApiKeyPhaseCREATED ||
+		subtle.ConstantTimeCompare([]byte(apiKey.Status.SkValue), []byte(key)) != 1 {
+		return errors.Wrap(errInvalidAPIKey, "API key was revoked")
+	}
+
+	if apiKey.Spec != nil && apiKey.Spec.Limits != nil && apiKey.Spec.Limits.Disabled {
+		return errors.Wrap(errInvalidAPIKey, "API key is disabled")
+	}
+
+	return nil
+}
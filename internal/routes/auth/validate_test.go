@@ -0,0 +1,154 @@
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	v1 "github.com/neutree-ai/neutree/api/v1"
+	"github.com/neutree-ai/neutree/internal/middleware"
+	"github.com/neutree-ai/neutree/pkg/storage"
+	storagemocks "github.com/neutree-ai/neutree/pkg/storage/mocks"
+)
+
+const (
+	testJwtSecret = "0123456789abcdef0123456789abcdef"
+	testUserID    = "11111111-2222-3333-4444-555555555555"
+	testKeyID     = "66666666-7777-8888-9999-aaaaaaaaaaaa"
+)
+
+// newTestAPIKey issues a self-contained API key the way api.create_api_key does.
+func newTestAPIKey(t *testing.T) string {
+	payload, err := hex.DecodeString(strings.ReplaceAll(testUserID+testKeyID, "-", "") + "0000000000000000")
+	require.NoError(t, err)
+
+	// PKCS#7 padding of the 40 bytes payload to 3 AES blocks.
+	for len(payload) < 48 {
+		payload = append(payload, 8)
+	}
+
+	block, err := aes.NewCipher([]byte(testJwtSecret))
+	require.NoError(t, err)
+
+	encrypted := make([]byte, len(payload))
+	cipher.NewCBCEncrypter(block, make([]byte, aes.BlockSize)).CryptBlocks(encrypted, payload)
+
+	h := hmac.New(sha256.New, []byte(testJwtSecret))
+	h.Write(encrypted)
+
+	return "sk_" + base64.RawURLEncoding.EncodeToString(append(encrypted, h.Sum(nil)[:16]...))
+}
+
+func TestHandleValidateAPIKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	key := newTestAPIKey(t)
+
+	storedKey := func(mutate func(apiKey *v1.ApiKey)) *v1.ApiKey {
+		apiKey := &v1.ApiKey{
+			ID:       testKeyID,
+			Metadata: &v1.Metadata{Name: "key"},
+			Spec:     &v1.ApiKeySpec{},
+			Status:   &v1.ApiKeyStatus{Phase: v1.ApiKeyPhaseCREATED, SkValue: key},
+		}
+		if mutate != nil {
+			mutate(apiKey)
+		}
+
+		return apiKey
+	}
+
+	tests := []struct {
+		name       string
+		header     string
+		stored     *v1.ApiKey
+		storeErr   error
+		expectCode int
+	}{
+		{
+			name:       "bearer API key",
+			header:     "Bearer " + key,
+			stored:     storedKey(nil),
+			expectCode: http.StatusNoContent,
+		},
+		{
+			name:       "bare API key",
+			header:     key,
+			stored:     storedKey(nil),
+			expectCode: http.StatusNoContent,
+		},
+		{
+			name:       "missing Authorization header",
+			expectCode: http.StatusUnauthorized,
+		},
+		{
+			name:       "forged API key",
+			header:     "Bearer " + key[:len(key)-4] + "AAAA",
+			expectCode: http.StatusUnauthorized,
+		},
+		{
+			name:       "deleted API key",
+			header:     "Bearer " + key,
+			storeErr:   storage.ErrResourceNotFound,
+			expectCode: http.StatusUnauthorized,
+		},
+		{
+			name:   "regenerated API key",
+			header: "Bearer " + key,
+			stored: storedKey(func(apiKey *v1.ApiKey) {
+				apiKey.Status.SkValue = "sk_other"
+			}),
+			expectCode: http.StatusUnauthorized,
+		},
+		{
+			name:   "disabled API key",
+			header: "Bearer " + key,
+			stored: storedKey(func(apiKey *v1.ApiKey) {
+				apiKey.Spec.Limits = &v1.ApiKeyLimits{Disabled: true}
+			}),
+			expectCode: http.StatusUnauthorized,
+		},
+		{
+			name:       "storage failure",
+			header:     "Bearer " + key,
+			storeErr:   assert.AnError,
+			expectCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStorage := storagemocks.NewMockStorage(t)
+			if tt.stored != nil || tt.storeErr != nil {
+				mockStorage.On("GetApiKey", testKeyID).Return(tt.stored, tt.storeErr)
+			}
+
+			router := gin.New()
+			RegisterAuthRoutes(router.Group("/api/v1"), nil, &Dependencies{
+				AuthConfig: middleware.AuthConfig{JwtSecret: testJwtSecret},
+				Storage:    mockStorage,
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/auth/validate", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectCode, w.Code, w.Body.String())
+		})
+	}
+}
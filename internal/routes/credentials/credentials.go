@@ -12,6 +12,7 @@ import (
 type Dependencies struct {
 	Storage          storage.Storage
 	StorageAccessURL string
+	StorageSchema    string
 }
 
 // RegisterCredentialsRoutes registers credentials retrieval routes
@@ -30,6 +31,7 @@ func RegisterCredentialsRoutes(group *gin.RouterGroup, middlewares []gin.Handler
 	proxyDeps := &proxies.Dependencies{
 		Storage:          deps.Storage,
 		StorageAccessURL: deps.StorageAccessURL,
+		StorageSchema:    deps.StorageSchema,
 	}
 
 	// Cluster credentials (kubeconfig, SSH keys, etc.)
@@ -56,7 +58,7 @@ func RegisterCredentialsRoutes(group *gin.RouterGroup, middlewares []gin.Handler
 
 func handleResourceCredentials(deps *proxies.Dependencies, tabelName string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		proxyHandler := proxies.CreateProxyHandler(deps.StorageAccessURL, tabelName, proxies.CreatePostgrestAuthModifier(c))
+		proxyHandler := proxies.CreateProxyHandler(deps.StorageAccessURL, tabelName, proxies.CreatePostgrestAuthModifier(c, deps.StorageSchema))
 		proxyHandler(c)
 	}
 }
@@ -44,6 +44,9 @@ type AITrace struct {
 	// BodyTruncated marks a record whose bodies exceeded the ingestion cap and
 	// were cut off by Vector; the stored bodies are a prefix of the originals.
 	BodyTruncated bool `json:"body_truncated,omitempty"`
+	// BodySampledOut marks a successful request whose bodies were not captured
+	// because of the endpoint's request logging sample rate.
+	BodySampledOut bool `json:"body_sampled_out,omitempty"`
 	// BodyIncomplete marks a chunked record for which some body chunks could
 	// not be read back (partial ingestion loss); the returned bodies hold the
 	// longest decodable prefix.
@@ -106,6 +109,39 @@ type AITraceKeyStatsResponse struct {
 	Keys        []AITraceKeyStat `json:"keys"`
 }
 
+// AITraceSizeDistribution summarises the sizes of the requests or responses
+// reporting them: how many did, their average and percentiles.
+type AITraceSizeDistribution struct {
+	Count int64   `json:"count"`
+	Avg   float64 `json:"avg"`
+	P50   float64 `json:"p50"`
+	P90   float64 `json:"p90"`
+	P99   float64 `json:"p99"`
+}
+
+// AITraceEndpointStat is one endpoint's request/response size distributions
+// over the requested window, alongside its request count and average latency,
+// for capacity planning. Byte sizes are recorded for every request; token
+// distributions are nil when the engine of the endpoint reported no usage.
+type AITraceEndpointStat struct {
+	EndpointType     string                   `json:"endpoint_type"`
+	EndpointName     string                   `json:"endpoint_name"`
+	Requests         int64                    `json:"requests"`
+	AvgDurationMs    float64                  `json:"avg_duration_ms"`
+	RequestBytes     AITraceSizeDistribution  `json:"request_bytes"`
+	ResponseBytes    AITraceSizeDistribution  `json:"response_bytes"`
+	PromptTokens     *AITraceSizeDistribution `json:"prompt_tokens,omitempty"`
+	CompletionTokens *AITraceSizeDistribution `json:"completion_tokens,omitempty"`
+}
+
+// AITraceEndpointStatsResponse is the wire format for
+// GET /api/v1/ai-traces/:workspace/endpoint-stats — per-endpoint request and
+// response size distributions over a trailing window (default 24h).
+type AITraceEndpointStatsResponse struct {
+	WindowHours int                   `json:"window_hours"`
+	Endpoints   []AITraceEndpointStat `json:"endpoints"`
+}
+
 // RegisterAITraceRoutes mounts the AI inference trace endpoints.
 func RegisterAITraceRoutes(group *gin.RouterGroup, middlewares []gin.HandlerFunc, deps *Dependencies) {
 	traces := group.Group("/ai-traces/:workspace")
@@ -115,6 +151,7 @@ func RegisterAITraceRoutes(group *gin.RouterGroup, middlewares []gin.HandlerFunc
 	traces.GET("", handleListAITraces(deps))
 	traces.GET("/stats", handleAITraceStats(deps))
 	traces.GET("/key-stats", handleAITraceKeyStats(deps))
+	traces.GET("/endpoint-stats", handleAITraceEndpointStats(deps))
 	traces.GET("/:request_id", handleGetAITrace(deps))
 }
 
@@ -605,10 +642,25 @@ func statsWindow(c *gin.Context) (startDay, endDay time.Time, ok bool) {
 	return startDay, endDay, true
 }
 
-// maxKeyStatsWindowHours bounds the trailing window the key-stats endpoint will
-// aggregate over (30 days), keeping a single LogsQL scan bounded.
+// maxKeyStatsWindowHours bounds the trailing window the key-stats and
+// endpoint-stats endpoints will aggregate over (30 days), keeping a single
+// LogsQL scan bounded.
 const maxKeyStatsWindowHours = 720
 
+// statsWindowHours parses ?window_hours= for the trailing-window stats
+// endpoints, falling back to 24h when absent or out of range.
+func statsWindowHours(c *gin.Context) int {
+	windowHours := 24
+
+	if v := c.Query("window_hours"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 && n <= maxKeyStatsWindowHours {
+			windowHours = n
+		}
+	}
+
+	return windowHours
+}
+
 // handleAITraceKeyStats returns per-API-key aggregates (request count, tokens,
 // success count, average latency) over a trailing window — default 24h. A single
 // `stats by (api_key_id)` LogsQL scan covers every key the caller may read, so
@@ -621,14 +673,7 @@ func handleAITraceKeyStats(deps *Dependencies) gin.HandlerFunc {
 			return
 		}
 
-		windowHours := 24
-
-		if v := c.Query("window_hours"); v != "" {
-			if n, err := strconv.Atoi(v); err == nil && n > 0 && n <= maxKeyStatsWindowHours {
-				windowHours = n
-			}
-		}
-
+		windowHours := statsWindowHours(c)
 		since := time.Now().UTC().Add(-time.Duration(windowHours) * time.Hour)
 
 		keys, err := store.KeyStats(traceScopeClause(c), since)
@@ -647,3 +692,33 @@ func handleAITraceKeyStats(deps *Dependencies) gin.HandlerFunc {
 		})
 	}
 }
+
+// handleAITraceEndpointStats returns per-endpoint request/response size
+// distributions (bytes, and tokens where the engine reports them) with the
+// request count and average latency, over a trailing window — default 24h.
+func handleAITraceEndpointStats(deps *Dependencies) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		store := requireTraceStore(c, deps)
+		if store == nil {
+			return
+		}
+
+		windowHours := statsWindowHours(c)
+		since := time.Now().UTC().Add(-time.Duration(windowHours) * time.Hour)
+
+		endpoints, err := store.EndpointStats(traceScopeClause(c), since)
+		if err != nil {
+			klog.Errorf("ai-trace: endpoint-stats: %v", err)
+			c.JSON(http.StatusBadGateway, gin.H{
+				"error": "failed to query trace store",
+			})
+
+			return
+		}
+
+		c.JSON(http.StatusOK, AITraceEndpointStatsResponse{
+			WindowHours: windowHours,
+			Endpoints:   endpoints,
+		})
+	}
+}
@@ -495,10 +495,11 @@ func TestAITraceHandlers_StoreNotConfigured(t *testing.T) {
 	deps := &Dependencies{}
 
 	handlers := map[string]gin.HandlerFunc{
-		"list":      handleListAITraces(deps),
-		"stats":     handleAITraceStats(deps),
-		"get":       handleGetAITrace(deps),
-		"key-stats": handleAITraceKeyStats(deps),
+		"list":           handleListAITraces(deps),
+		"stats":          handleAITraceStats(deps),
+		"get":            handleGetAITrace(deps),
+		"key-stats":      handleAITraceKeyStats(deps),
+		"endpoint-stats": handleAITraceEndpointStats(deps),
 	}
 
 	for name, h := range handlers {
@@ -578,6 +579,61 @@ func TestHandleAITraceKeyStats_DecodesNDJSON(t *testing.T) {
 	assert.Equal(t, int64(52), resp.Keys[1].Requests)
 }
 
+func TestHandleAITraceEndpointStats_DecodesNDJSON(t *testing.T) {
+	// One row per endpoint; "llama" runs on an engine reporting no token usage,
+	// so its token counts are zero and its token distributions are omitted.
+	var gotQuery string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotQuery = r.URL.Query().Get("query")
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		_, _ = w.Write([]byte(
+			`{"endpoint_type":"endpoint","endpoint_name":"qwen","requests":"120","avg_duration_ms":"812.5",` +
+				`"request_size_count":"120","request_size_avg":"2048","request_size_p50":"1900","request_size_p90":"4000","request_size_p99":"8100",` +
+				`"response_size_count":"120","response_size_avg":"1024","response_size_p50":"900","response_size_p90":"2000","response_size_p99":"3000",` +
+				`"prompt_tokens_count":"118","prompt_tokens_avg":"512","prompt_tokens_p50":"480","prompt_tokens_p90":"1000","prompt_tokens_p99":"2000",` +
+				`"completion_tokens_count":"118","completion_tokens_avg":"256","completion_tokens_p50":"200","completion_tokens_p90":"500","completion_tokens_p99":"900"}` + "\n" +
+				`{"endpoint_type":"endpoint","endpoint_name":"llama","requests":"4","avg_duration_ms":"100",` +
+				`"request_size_count":"4","request_size_avg":"300","request_size_p50":"300","request_size_p90":"320","request_size_p99":"330",` +
+				`"response_size_count":"4","response_size_avg":"600","response_size_p50":"600","response_size_p90":"610","response_size_p99":"620",` +
+				`"prompt_tokens_count":"0","prompt_tokens_avg":"NaN","completion_tokens_count":"0","completion_tokens_avg":"NaN"}` + "\n" +
+				`{"endpoint_type":"","endpoint_name":"","requests":"2"}` + "\n"))
+	}))
+	defer server.Close()
+
+	deps := &Dependencies{AITraceStoreURL: server.URL, HTTPClient: &util.DefaultHTTPClient{}}
+	c, w := traceCtx("user-1", "ws1")
+	c.Request = httptest.NewRequest("GET", "/?window_hours=168", nil)
+
+	handleAITraceEndpointStats(deps)(c)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, gotQuery, "| stats by (endpoint_type, endpoint_name) count() requests")
+	assert.Contains(t, gotQuery, "quantile(0.99, completion_tokens) completion_tokens_p99")
+
+	var resp AITraceEndpointStatsResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, 168, resp.WindowHours)
+	require.Len(t, resp.Endpoints, 2) // unattributed row dropped
+
+	qwen := resp.Endpoints[0]
+	assert.Equal(t, "qwen", qwen.EndpointName)
+	assert.Equal(t, int64(120), qwen.Requests)
+	assert.InDelta(t, 812.5, qwen.AvgDurationMs, 0.01)
+	assert.Equal(t, AITraceSizeDistribution{Count: 120, Avg: 2048, P50: 1900, P90: 4000, P99: 8100}, qwen.RequestBytes)
+	assert.Equal(t, AITraceSizeDistribution{Count: 120, Avg: 1024, P50: 900, P90: 2000, P99: 3000}, qwen.ResponseBytes)
+	require.NotNil(t, qwen.PromptTokens)
+	assert.Equal(t, AITraceSizeDistribution{Count: 118, Avg: 512, P50: 480, P90: 1000, P99: 2000}, *qwen.PromptTokens)
+	require.NotNil(t, qwen.CompletionTokens)
+	assert.InDelta(t, 900, qwen.CompletionTokens.P99, 0.01)
+
+	llama := resp.Endpoints[1]
+	assert.Equal(t, "llama", llama.EndpointName)
+	assert.Equal(t, int64(4), llama.RequestBytes.Count)
+	assert.Nil(t, llama.PromptTokens)
+	assert.Nil(t, llama.CompletionTokens)
+}
+
 func TestHandleListAITraces_IncludeBodyClampsLimit(t *testing.T) {
 	// Body-carrying pages are clamped to includeBodyMaxLimit; metadata-only
 	// pages keep the requested size.
@@ -0,0 +1,261 @@
+package logs
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	utilexec "k8s.io/client-go/util/exec"
+	"k8s.io/klog/v2"
+
+	v1 "github.com/neutree-ai/neutree/api/v1"
+	"github.com/neutree-ai/neutree/internal/middleware"
+	"github.com/neutree-ai/neutree/internal/util"
+)
+
+const (
+	// permEndpointExec is only held by admin by default; a debug role is
+	// created by assigning it (see migrations 086/087).
+	permEndpointExec = "endpoint:exec"
+	// execTimeout bounds a single exec so a hung command cannot hold the request open.
+	execTimeout = 5 * time.Minute
+	// maxExecOutputBytes caps the stdout and stderr returned for an exec.
+	maxExecOutputBytes = 1 << 20
+)
+
+// ExecRequest is a command to run inside an endpoint replica.
+type ExecRequest struct {
+	Command []string `json:"command"`
+	Stdin   string   `json:"stdin,omitempty"`
+	TTY     bool     `json:"tty,omitempty"`
+}
+
+// ExecResponse is the result of a command run inside an endpoint replica.
+type ExecResponse struct {
+	Stdout    string `json:"stdout"`
+	Stderr    string `json:"stderr"`
+	ExitCode  int    `json:"exit_code"`
+	Truncated bool   `json:"truncated,omitempty"`
+}
+
+// RegisterEndpointExecRoutes registers the debug exec route for endpoint replicas.
+func RegisterEndpointExecRoutes(group *gin.RouterGroup, middlewares []gin.HandlerFunc, deps *Dependencies) {
+	execGroup := group.Group("/endpoints/:workspace/:name")
+	execGroup.Use(middlewares...)
+	execGroup.Use(middleware.RequireWorkspacePermission(permEndpointExec, middleware.PermissionDependencies{
+		Storage: deps.Storage,
+	}))
+
+	execGroup.POST("/exec/:replica_id", handleEndpointExec(deps))
+}
+
+// handleEndpointExec runs a command in an endpoint replica. Every exec is
+// audit-logged before it runs; an exec that cannot be recorded is refused.
+func handleEndpointExec(deps *Dependencies) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		workspace := c.Param("workspace")
+		name := c.Param("name")
+		replicaID := c.Param("replica_id")
+
+		if workspace == "" || name == "" || replicaID == "" {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "workspace, name, and replica_id are required",
+			})
+
+			return
+		}
+
+		var req ExecRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": fmt.Sprintf("invalid request body: %v", err),
+			})
+
+			return
+		}
+
+		if len(req.Command) == 0 || strings.TrimSpace(req.Command[0]) == "" {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "command is required",
+			})
+
+			return
+		}
+
+		endpoint, cluster, err := getEndpointAndCluster(deps, workspace, name)
+		if err != nil {
+			klog.Errorf("Failed to get endpoint and cluster: %v", err)
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+
+			return
+		}
+
+		if cluster.Spec.Type != v1.KubernetesClusterType {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": fmt.Sprintf("exec is not supported for cluster type: %s", cluster.Spec.Type),
+			})
+
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), execTimeout)
+		defer cancel()
+
+		namespace := util.ClusterNamespace(cluster)
+
+		pod, err := deps.K8sClient.GetPod(ctx, cluster, namespace, replicaID)
+		if err != nil || !isEndpointPod(pod, endpoint) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": fmt.Sprintf("replica %s not found", replicaID),
+			})
+
+			return
+		}
+
+		container, ok := engineContainerName(pod, endpoint)
+		if !ok {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": fmt.Sprintf("no engine container found in pod %s", replicaID),
+			})
+
+			return
+		}
+
+		userID := c.GetString("user_id")
+
+		if err := recordExecAudit(deps, userID, endpoint, replicaID, &req); err != nil {
+			klog.Errorf("Failed to record exec audit log for user %s: %v", userID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to record audit log",
+			})
+
+			return
+		}
+
+		klog.Infof("User %s exec in endpoint %s replica %s: %q", userID, endpoint.Key(), replicaID, req.Command)
+
+		resp, err := execInPod(ctx, deps.K8sClient, cluster, namespace, pod.Name, container, &req)
+		if err != nil {
+			klog.Errorf("Failed to exec in endpoint %s replica %s: %v", endpoint.Key(), replicaID, err)
+			c.JSON(http.StatusBadGateway, gin.H{
+				"error": fmt.Sprintf("failed to exec: %v", err),
+			})
+
+			return
+		}
+
+		c.JSON(http.StatusOK, resp)
+	}
+}
+
+// isEndpointPod guards against exec into pods of other endpoints sharing the cluster namespace.
+func isEndpointPod(pod *corev1.Pod, endpoint *v1.Endpoint) bool {
+	return pod != nil &&
+		pod.Labels["workspace"] == endpoint.Metadata.Workspace &&
+		pod.Labels["endpoint"] == endpoint.Metadata.Name
+}
+
+// engineContainerName returns the container of the pod running the endpoint engine. The engine
+// container is named after the engine, the auth sidecar or containers injected into the pod,
+// e.g. by a service mesh, may come before it.
+func engineContainerName(pod *corev1.Pod, endpoint *v1.Endpoint) (string, bool) {
+	if endpoint.Spec == nil || endpoint.Spec.Engine == nil {
+		return "", false
+	}
+
+	for _, container := range pod.Spec.Containers {
+		if container.Name == endpoint.Spec.Engine.Engine {
+			return container.Name, true
+		}
+	}
+
+	return "", false
+}
+
+func recordExecAudit(deps *Dependencies, userID string, endpoint *v1.Endpoint, replicaID string, req *ExecRequest) error {
+	return deps.Storage.CallDatabaseFunctionAsUser(userID, "record_audit_log", map[string]interface{}{
+		"p_action":        permEndpointExec,
+		"p_workspace":     endpoint.Metadata.Workspace,
+		"p_resource_kind": "Endpoint",
+		"p_resource_name": endpoint.Metadata.Name,
+		"p_details": map[string]interface{}{
+			"replica_id": replicaID,
+			"command":    req.Command,
+			"stdin":      req.Stdin != "",
+			"tty":        req.TTY,
+		},
+	}, nil)
+}
+
+func execInPod(ctx context.Context, k8sClient util.K8sClient, cluster *v1.Cluster, namespace, podName, container string,
+	req *ExecRequest) (*ExecResponse, error) {
+	stdout := &cappedBuffer{limit: maxExecOutputBytes}
+	stderr := &cappedBuffer{limit: maxExecOutputBytes}
+
+	opts := &corev1.PodExecOptions{
+		Container: container,
+		Command:   req.Command,
+		Stdin:     req.Stdin != "",
+		Stdout:    true,
+		// A TTY merges stderr into stdout.
+		Stderr: !req.TTY,
+		TTY:    req.TTY,
+	}
+
+	var stdin io.Reader
+	if opts.Stdin {
+		stdin = strings.NewReader(req.Stdin)
+	}
+
+	var errOut io.Writer
+	if opts.Stderr {
+		errOut = stderr
+	}
+
+	resp := &ExecResponse{}
+
+	if err := k8sClient.ExecInPod(ctx, cluster, namespace, podName, opts, stdin, stdout, errOut); err != nil {
+		var exitErr utilexec.ExitError
+		if !errors.As(err, &exitErr) || !exitErr.Exited() {
+			return nil, err
+		}
+
+		resp.ExitCode = exitErr.ExitStatus()
+	}
+
+	resp.Stdout = stdout.String()
+	resp.Stderr = stderr.String()
+	resp.Truncated = stdout.truncated || stderr.truncated
+
+	return resp, nil
+}
+
+// cappedBuffer keeps the first limit bytes written and discards the rest.
+type cappedBuffer struct {
+	bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if remaining := b.limit - b.Len(); remaining < len(p) {
+		b.truncated = true
+
+		if remaining > 0 {
+			b.Buffer.Write(p[:remaining])
+		}
+
+		return len(p), nil
+	}
+
+	return b.Buffer.Write(p)
+}
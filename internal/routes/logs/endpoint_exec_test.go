@@ -0,0 +1,183 @@
+package logs
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilexec "k8s.io/client-go/util/exec"
+
+	v1 "github.com/neutree-ai/neutree/api/v1"
+	utilmocks "github.com/neutree-ai/neutree/internal/util/mocks"
+	"github.com/neutree-ai/neutree/pkg/storage/mocks"
+)
+
+func setupExecRouter(deps *Dependencies) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	RegisterEndpointExecRoutes(router.Group("/api/v1"), []gin.HandlerFunc{
+		func(c *gin.Context) {
+			c.Set("user_id", "user-123")
+			c.Next()
+		},
+	}, deps)
+
+	return router
+}
+
+func mockExecPermission(s *mocks.MockStorage, granted bool) {
+	s.On("CallDatabaseFunction", "has_permission", mock.MatchedBy(func(params map[string]interface{}) bool {
+		return params["user_uuid"] == "user-123" &&
+			params["required_permission"] == permEndpointExec &&
+			params["workspace"] == "default"
+	}), mock.Anything).Run(func(args mock.Arguments) {
+		*args.Get(2).(*bool) = granted
+	}).Return(nil)
+}
+
+func mockExecEndpoint(s *mocks.MockStorage) {
+	s.On("GetEndpointByName", "default", mock.Anything).Return(&v1.Endpoint{
+		Metadata: &v1.Metadata{Name: "chat", Workspace: "default"},
+		Spec: &v1.EndpointSpec{
+			Cluster: "k8s",
+			Engine:  &v1.EndpointEngineSpec{Engine: "vllm"},
+		},
+	}, nil)
+	s.On("GetClusterByName", "default", "k8s").Return(&v1.Cluster{
+		Metadata: &v1.Metadata{Name: "k8s", Workspace: "default"},
+		Spec:     &v1.ClusterSpec{Type: v1.KubernetesClusterType},
+	}, nil)
+}
+
+func endpointPod(name, endpoint string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{"workspace": "default", "endpoint": endpoint, "app": "inference"},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "auth-proxy"}, {Name: "vllm"}},
+		},
+	}
+}
+
+func postExec(router *gin.Engine, replicaID string, body interface{}) *httptest.ResponseRecorder {
+	payload, _ := json.Marshal(body)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/endpoints/default/chat/exec/"+replicaID, bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	return w
+}
+
+func TestEndpointExec_RequiresExecPermission(t *testing.T) {
+	mockStorage := mocks.NewMockStorage(t)
+	mockK8sClient := utilmocks.NewMockK8sClient(t)
+	router := setupExecRouter(&Dependencies{Storage: mockStorage, K8sClient: mockK8sClient})
+
+	mockExecPermission(mockStorage, false)
+
+	w := postExec(router, "chat-pod-0", ExecRequest{Command: []string{"sh"}})
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), permEndpointExec)
+	mockStorage.AssertNotCalled(t, "CallDatabaseFunctionAsUser", mock.Anything, "record_audit_log", mock.Anything, mock.Anything)
+	mockK8sClient.AssertNotCalled(t, "ExecInPod", mock.Anything, mock.Anything, mock.Anything, mock.Anything,
+		mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestEndpointExec_RecordsAuditLog(t *testing.T) {
+	mockStorage := mocks.NewMockStorage(t)
+	mockK8sClient := utilmocks.NewMockK8sClient(t)
+	router := setupExecRouter(&Dependencies{Storage: mockStorage, K8sClient: mockK8sClient})
+
+	mockExecPermission(mockStorage, true)
+	mockExecEndpoint(mockStorage)
+
+	var audit map[string]interface{}
+
+	mockStorage.On("CallDatabaseFunctionAsUser", "user-123", "record_audit_log", mock.Anything, nil).Run(func(args mock.Arguments) {
+		audit = args.Get(2).(map[string]interface{})
+	}).Return(nil).Once()
+
+	mockK8sClient.On("GetPod", mock.Anything, mock.Anything, mock.Anything, "chat-pod-0").
+		Return(endpointPod("chat-pod-0", "chat"), nil)
+	mockK8sClient.On("ExecInPod", mock.Anything, mock.Anything, mock.Anything, "chat-pod-0",
+		mock.MatchedBy(func(opts *corev1.PodExecOptions) bool {
+			return opts.Container == "vllm" && opts.Stdin && !opts.TTY
+		}), mock.Anything, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			in, _ := io.ReadAll(args.Get(5).(io.Reader))
+			_, _ = args.Get(6).(io.Writer).Write(in)
+		}).Return(nil)
+
+	w := postExec(router, "chat-pod-0", ExecRequest{Command: []string{"cat"}, Stdin: "hello"})
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var resp ExecResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "hello", resp.Stdout)
+	assert.Equal(t, 0, resp.ExitCode)
+
+	require.NotNil(t, audit)
+	assert.NotContains(t, audit, "p_user_id")
+	assert.Equal(t, permEndpointExec, audit["p_action"])
+	assert.Equal(t, "default", audit["p_workspace"])
+	assert.Equal(t, "chat", audit["p_resource_name"])
+	assert.Equal(t, "chat-pod-0", audit["p_details"].(map[string]interface{})["replica_id"])
+	assert.Equal(t, []string{"cat"}, audit["p_details"].(map[string]interface{})["command"])
+}
+
+func TestEndpointExec_ExitCode(t *testing.T) {
+	mockStorage := mocks.NewMockStorage(t)
+	mockK8sClient := utilmocks.NewMockK8sClient(t)
+	router := setupExecRouter(&Dependencies{Storage: mockStorage, K8sClient: mockK8sClient})
+
+	mockExecPermission(mockStorage, true)
+	mockExecEndpoint(mockStorage)
+	mockStorage.On("CallDatabaseFunctionAsUser", "user-123", "record_audit_log", mock.Anything, nil).Return(nil)
+
+	mockK8sClient.On("GetPod", mock.Anything, mock.Anything, mock.Anything, "chat-pod-0").
+		Return(endpointPod("chat-pod-0", "chat"), nil)
+	mockK8sClient.On("ExecInPod", mock.Anything, mock.Anything, mock.Anything, "chat-pod-0",
+		mock.Anything, nil, mock.Anything, mock.Anything).
+		Return(utilexec.CodeExitError{Err: io.EOF, Code: 3})
+
+	w := postExec(router, "chat-pod-0", ExecRequest{Command: []string{"false"}})
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var resp ExecResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, 3, resp.ExitCode)
+}
+
+func TestEndpointExec_RejectsForeignReplica(t *testing.T) {
+	mockStorage := mocks.NewMockStorage(t)
+	mockK8sClient := utilmocks.NewMockK8sClient(t)
+	router := setupExecRouter(&Dependencies{Storage: mockStorage, K8sClient: mockK8sClient})
+
+	mockExecPermission(mockStorage, true)
+	mockExecEndpoint(mockStorage)
+
+	mockK8sClient.On("GetPod", mock.Anything, mock.Anything, mock.Anything, "other-pod-0").
+		Return(endpointPod("other-pod-0", "other"), nil)
+
+	w := postExec(router, "other-pod-0", ExecRequest{Command: []string{"sh"}})
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockStorage.AssertNotCalled(t, "CallDatabaseFunctionAsUser", mock.Anything, "record_audit_log", mock.Anything, mock.Anything)
+}
@@ -3,6 +3,7 @@ package logs
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -242,56 +243,25 @@ func handleGetLogs(deps *Dependencies) gin.HandlerFunc {
 
 // getEndpointAndCluster retrieves the endpoint and its cluster
 func getEndpointAndCluster(deps *Dependencies, workspace, name string) (*v1.Endpoint, *v1.Cluster, error) {
-	// Get endpoint
-	endpoints, err := deps.Storage.ListEndpoint(storage.ListOption{
-		Filters: []storage.Filter{
-			{
-				Column:   "metadata->name",
-				Operator: "eq",
-				Value:    strconv.Quote(name),
-			},
-			{
-				Column:   "metadata->workspace",
-				Operator: "eq",
-				Value:    strconv.Quote(workspace),
-			},
-		},
-	})
+	endpoint, err := deps.Storage.GetEndpointByName(workspace, name)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to list endpoints: %v", err)
-	}
+		if errors.Is(err, storage.ErrResourceNotFound) {
+			return nil, nil, fmt.Errorf("endpoint not found")
+		}
 
-	if len(endpoints) == 0 {
-		return nil, nil, fmt.Errorf("endpoint not found")
+		return nil, nil, fmt.Errorf("failed to get endpoint: %v", err)
 	}
 
-	endpoint := &endpoints[0]
-
-	// Get cluster
-	clusters, err := deps.Storage.ListCluster(storage.ListOption{
-		Filters: []storage.Filter{
-			{
-				Column:   "metadata->name",
-				Operator: "eq",
-				Value:    strconv.Quote(endpoint.Spec.Cluster),
-			},
-			{
-				Column:   "metadata->workspace",
-				Operator: "eq",
-				Value:    strconv.Quote(endpoint.Metadata.Workspace),
-			},
-		},
-	})
-
+	cluster, err := deps.Storage.GetClusterByName(endpoint.Metadata.Workspace, endpoint.Spec.Cluster)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to list clusters: %v", err)
-	}
+		if errors.Is(err, storage.ErrResourceNotFound) {
+			return nil, nil, fmt.Errorf("cluster not found")
+		}
 
-	if len(clusters) == 0 {
-		return nil, nil, fmt.Errorf("cluster not found")
+		return nil, nil, fmt.Errorf("failed to get cluster: %v", err)
 	}
 
-	return endpoint, &clusters[0], nil
+	return endpoint, cluster, nil
 }
 
 // getRayLogSources gets log sources from Ray Dashboard API
@@ -20,6 +20,7 @@ import (
 	dashboardmocks "github.com/neutree-ai/neutree/internal/ray/dashboard/mocks"
 	"github.com/neutree-ai/neutree/internal/util"
 	utilmocks "github.com/neutree-ai/neutree/internal/util/mocks"
+	"github.com/neutree-ai/neutree/pkg/storage"
 	"github.com/neutree-ai/neutree/pkg/storage/mocks"
 )
 
@@ -107,7 +108,7 @@ func TestHandleGetLogSources_EndpointNotFound(t *testing.T) {
 	mockStorage := new(mocks.MockStorage)
 	deps := setupTestDeps(mockStorage)
 
-	mockStorage.On("ListEndpoint", mock.Anything).Return([]v1.Endpoint{}, nil)
+	mockStorage.On("GetEndpointByName", mock.Anything, mock.Anything).Return(nil, storage.ErrResourceNotFound)
 
 	c, w := createLogsMockContext("GET", "/api/v1/endpoints/default/non-existent/log-sources")
 
@@ -123,7 +124,7 @@ func TestHandleGetLogSources_StorageError(t *testing.T) {
 	deps := setupTestDeps(mockStorage)
 
 	mockError := errors.New("storage error")
-	mockStorage.On("ListEndpoint", mock.Anything).Return([]v1.Endpoint{}, mockError)
+	mockStorage.On("GetEndpointByName", mock.Anything, mock.Anything).Return(nil, mockError)
 
 	c, w := createLogsMockContext("GET", "/api/v1/endpoints/default/test-endpoint/log-sources")
 
@@ -134,7 +135,7 @@ func TestHandleGetLogSources_StorageError(t *testing.T) {
 	var response map[string]string
 	err := json.Unmarshal(w.Body.Bytes(), &response)
 	assert.NoError(t, err)
-	assert.Contains(t, response["error"], "failed to list endpoints")
+	assert.Contains(t, response["error"], "failed to get endpoint")
 
 	mockStorage.AssertExpectations(t)
 }
@@ -163,8 +164,8 @@ func TestHandleGetLogSources_UnsupportedClusterType(t *testing.T) {
 		},
 	}
 
-	mockStorage.On("ListEndpoint", mock.Anything).Return([]v1.Endpoint{endpoint}, nil)
-	mockStorage.On("ListCluster", mock.Anything).Return([]v1.Cluster{cluster}, nil)
+	mockStorage.On("GetEndpointByName", mock.Anything, mock.Anything).Return(&endpoint, nil)
+	mockStorage.On("GetClusterByName", mock.Anything, mock.Anything).Return(&cluster, nil)
 
 	c, w := createLogsMockContext("GET", "/api/v1/endpoints/default/test-endpoint/log-sources")
 
@@ -251,8 +252,8 @@ func TestHandleGetLogs_HEAD_Request(t *testing.T) {
 		},
 	}
 
-	mockStorage.On("ListEndpoint", mock.Anything).Return([]v1.Endpoint{endpoint}, nil)
-	mockStorage.On("ListCluster", mock.Anything).Return([]v1.Cluster{cluster}, nil)
+	mockStorage.On("GetEndpointByName", mock.Anything, mock.Anything).Return(&endpoint, nil)
+	mockStorage.On("GetClusterByName", mock.Anything, mock.Anything).Return(&cluster, nil)
 
 	c, w := createLogsMockContext("HEAD", "/api/v1/endpoints/default/test-endpoint/logs/pod-123/logs")
 
@@ -292,8 +293,8 @@ func TestHandleGetLogs_DownloadParameter(t *testing.T) {
 		},
 	}
 
-	mockStorage.On("ListEndpoint", mock.Anything).Return([]v1.Endpoint{endpoint}, nil)
-	mockStorage.On("ListCluster", mock.Anything).Return([]v1.Cluster{cluster}, nil)
+	mockStorage.On("GetEndpointByName", mock.Anything, mock.Anything).Return(&endpoint, nil)
+	mockStorage.On("GetClusterByName", mock.Anything, mock.Anything).Return(&cluster, nil)
 
 	c, w := createLogsMockContext("HEAD", "/api/v1/endpoints/default/test-endpoint/logs/pod-123/logs")
 	// Set query parameter properly
@@ -364,8 +365,8 @@ func TestHandleGetLogs_LinesParameter(t *testing.T) {
 				},
 			}
 
-			mockStorage.On("ListEndpoint", mock.Anything).Return([]v1.Endpoint{endpoint}, nil)
-			mockStorage.On("ListCluster", mock.Anything).Return([]v1.Cluster{cluster}, nil)
+			mockStorage.On("GetEndpointByName", mock.Anything, mock.Anything).Return(&endpoint, nil)
+			mockStorage.On("GetClusterByName", mock.Anything, mock.Anything).Return(&cluster, nil)
 
 			c, w := createLogsMockContext("HEAD", "/api/v1/endpoints/default/test-endpoint/logs/pod-123/logs")
 			// Set query parameter properly
@@ -436,8 +437,8 @@ func TestGetEndpointAndCluster(t *testing.T) {
 			},
 		}
 
-		mockStorage.On("ListEndpoint", mock.Anything).Return([]v1.Endpoint{endpoint}, nil)
-		mockStorage.On("ListCluster", mock.Anything).Return([]v1.Cluster{cluster}, nil)
+		mockStorage.On("GetEndpointByName", "default", "test-endpoint").Return(&endpoint, nil)
+		mockStorage.On("GetClusterByName", "default", "test-cluster").Return(&cluster, nil)
 
 		resultEndpoint, resultCluster, err := getEndpointAndCluster(deps, "default", "test-endpoint")
 
@@ -454,7 +455,7 @@ func TestGetEndpointAndCluster(t *testing.T) {
 		mockStorage := new(mocks.MockStorage)
 		deps := setupTestDeps(mockStorage)
 
-		mockStorage.On("ListEndpoint", mock.Anything).Return([]v1.Endpoint{}, nil)
+		mockStorage.On("GetEndpointByName", mock.Anything, mock.Anything).Return(nil, storage.ErrResourceNotFound)
 
 		_, _, err := getEndpointAndCluster(deps, "default", "non-existent")
 
@@ -478,8 +479,8 @@ func TestGetEndpointAndCluster(t *testing.T) {
 			},
 		}
 
-		mockStorage.On("ListEndpoint", mock.Anything).Return([]v1.Endpoint{endpoint}, nil)
-		mockStorage.On("ListCluster", mock.Anything).Return([]v1.Cluster{}, nil)
+		mockStorage.On("GetEndpointByName", mock.Anything, mock.Anything).Return(&endpoint, nil)
+		mockStorage.On("GetClusterByName", mock.Anything, mock.Anything).Return(nil, storage.ErrResourceNotFound)
 
 		_, _, err := getEndpointAndCluster(deps, "default", "test-endpoint")
 
@@ -976,8 +977,8 @@ func TestHandleGetLogSources_SSHCluster_Success(t *testing.T) {
 		},
 	}
 
-	mockStorage.On("ListEndpoint", mock.Anything).Return([]v1.Endpoint{endpoint}, nil)
-	mockStorage.On("ListCluster", mock.Anything).Return([]v1.Cluster{cluster}, nil)
+	mockStorage.On("GetEndpointByName", mock.Anything, mock.Anything).Return(&endpoint, nil)
+	mockStorage.On("GetClusterByName", mock.Anything, mock.Anything).Return(&cluster, nil)
 
 	// Mock successful HTTP response
 	responseBody := `{
@@ -1052,8 +1053,8 @@ func TestHandleGetLogs_SSHCluster_Success(t *testing.T) {
 		},
 	}
 
-	mockStorage.On("ListEndpoint", mock.Anything).Return([]v1.Endpoint{endpoint}, nil)
-	mockStorage.On("ListCluster", mock.Anything).Return([]v1.Cluster{cluster}, nil)
+	mockStorage.On("GetEndpointByName", mock.Anything, mock.Anything).Return(&endpoint, nil)
+	mockStorage.On("GetClusterByName", mock.Anything, mock.Anything).Return(&cluster, nil)
 
 	// Mock applications API response
 	appsResponse := `{
@@ -1131,8 +1132,8 @@ func TestHandleGetLogs_K8sCluster_Success(t *testing.T) {
 		},
 	}
 
-	mockStorage.On("ListEndpoint", mock.Anything).Return([]v1.Endpoint{endpoint}, nil)
-	mockStorage.On("ListCluster", mock.Anything).Return([]v1.Cluster{cluster}, nil)
+	mockStorage.On("GetEndpointByName", mock.Anything, mock.Anything).Return(&endpoint, nil)
+	mockStorage.On("GetClusterByName", mock.Anything, mock.Anything).Return(&cluster, nil)
 
 	// Mock successful pod get
 	pod := &corev1.Pod{
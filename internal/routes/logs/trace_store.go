@@ -43,14 +43,16 @@ func newTraceStore(deps *Dependencies) *traceStore {
 }
 
 // listProjection is the LogsQL `fields` projection for the list query: every
-// metadata column the list view renders — including the body_truncated flag,
-// so truncated traces are recognizable without fetching bodies — while
+// metadata column the list view renders — including the body_truncated and
+// body_sampled_out flags, so truncated or sampled-out traces are recognizable
+// without fetching bodies — while
 // deliberately excluding the large request_body / response_body fields so
 // list responses stay small.
 const listProjection = "_time, request_id, workspace, endpoint_type, " +
 	"endpoint_name, api_key_id, request_uri, request_model, response_model, " +
 	"response_status, prompt_tokens, completion_tokens, total_tokens, " +
-	"finish_reason, stream, user_agent, duration_ms, body_truncated"
+	"finish_reason, stream, user_agent, duration_ms, body_truncated, " +
+	"body_sampled_out"
 
 // fullProjection extends listProjection with the large request/response body
 // columns plus the chunked-body metadata needed to reassemble oversized
@@ -432,6 +434,36 @@ func (s *traceStore) KeyStats(scope string, since time.Time) ([]AITraceKeyStat,
 	return s.queryKeyStats(query, params)
 }
 
+// sizeStatsFields are the trace fields EndpointStats aggregates into size
+// distributions.
+var sizeStatsFields = []string{"request_size", "response_size", "prompt_tokens", "completion_tokens"}
+
+// EndpointStats returns per-endpoint request/response size distributions,
+// request count and average latency since the given instant.
+func (s *traceStore) EndpointStats(scope string, since time.Time) ([]AITraceEndpointStat, error) {
+	// count(field) counts the records carrying the field, so engines that do
+	// not report token usage yield a zero token count rather than skewing the
+	// percentiles; avg/quantile only consider numeric values.
+	aggregates := []string{"count() requests", "avg(duration_ms) avg_duration_ms"}
+	for _, field := range sizeStatsFields {
+		aggregates = append(aggregates,
+			fmt.Sprintf("count(%[1]s) %[1]s_count", field),
+			fmt.Sprintf("avg(%[1]s) %[1]s_avg", field),
+			fmt.Sprintf("quantile(0.5, %[1]s) %[1]s_p50", field),
+			fmt.Sprintf("quantile(0.9, %[1]s) %[1]s_p90", field),
+			fmt.Sprintf("quantile(0.99, %[1]s) %[1]s_p99", field),
+		)
+	}
+
+	query := fmt.Sprintf("%s | stats by (endpoint_type, endpoint_name) %s",
+		s.baseQuery(scope), strings.Join(aggregates, ", "))
+
+	params := url.Values{}
+	params.Set("start", since.Format(time.RFC3339))
+
+	return s.queryEndpointStats(query, params)
+}
+
 // select runs a LogsQL query against VictoriaLogs and returns the raw NDJSON
 // response body; the caller must Close it.
 func (s *traceStore) selectQuery(query string, params url.Values) (*http.Response, error) {
@@ -589,6 +621,72 @@ func (s *traceStore) queryKeyStats(query string, params url.Values) ([]AITraceKe
 	return out, nil
 }
 
+// queryEndpointStats runs the per-endpoint `stats by (endpoint_type,
+// endpoint_name)` aggregation and decodes the NDJSON result rows.
+func (s *traceStore) queryEndpointStats(query string, params url.Values) ([]AITraceEndpointStat, error) {
+	resp, err := s.selectQuery(query, params)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	out := make([]AITraceEndpointStat, 0, 16)
+	scanner := bufio.NewScanner(resp.Body)
+
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var r map[string]string
+		if err := json.Unmarshal(line, &r); err != nil {
+			continue
+		}
+
+		// Traffic not attributed to an endpoint (e.g. a malformed request URI)
+		// cannot be sized against any endpoint.
+		if strings.TrimSpace(r["endpoint_name"]) == "" {
+			continue
+		}
+
+		distribution := func(field string) AITraceSizeDistribution {
+			return AITraceSizeDistribution{
+				Count: parseIntLoose(r[field+"_count"]),
+				Avg:   parseFloatLoose(r[field+"_avg"]),
+				P50:   parseFloatLoose(r[field+"_p50"]),
+				P90:   parseFloatLoose(r[field+"_p90"]),
+				P99:   parseFloatLoose(r[field+"_p99"]),
+			}
+		}
+		tokenDistribution := func(field string) *AITraceSizeDistribution {
+			d := distribution(field)
+			if d.Count == 0 {
+				return nil
+			}
+
+			return &d
+		}
+
+		out = append(out, AITraceEndpointStat{
+			EndpointType:     r["endpoint_type"],
+			EndpointName:     r["endpoint_name"],
+			Requests:         parseIntLoose(r["requests"]),
+			AvgDurationMs:    parseFloatLoose(r["avg_duration_ms"]),
+			RequestBytes:     distribution("request_size"),
+			ResponseBytes:    distribution("response_size"),
+			PromptTokens:     tokenDistribution("prompt_tokens"),
+			CompletionTokens: tokenDistribution("completion_tokens"),
+		})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("scan victorialogs response: %w", err)
+	}
+
+	return out, nil
+}
+
 // parseIntLoose parses a VL numeric result (which may be int- or float-
 // formatted) into an int64, truncating any fractional part. Returns 0 on error.
 // Integer-formatted values parse as base-10 int64 first so large counts
@@ -640,6 +738,7 @@ type vlRecord struct {
 	ResponseBody     string `json:"response_body"`
 	BodyChunked      string `json:"body_chunked"`
 	BodyTruncated    string `json:"body_truncated"`
+	BodySampledOut   string `json:"body_sampled_out"`
 	RequestChunks    string `json:"request_chunks"`
 	ResponseChunks   string `json:"response_chunks"`
 }
@@ -651,22 +750,23 @@ func decodeVLRecord(line []byte) (AITrace, bool) {
 	}
 
 	t := AITrace{
-		RequestID:     r.RequestID,
-		Time:          r.Time,
-		Workspace:     r.Workspace,
-		EndpointType:  r.EndpointType,
-		EndpointName:  r.EndpointName,
-		APIKeyID:      r.APIKeyID,
-		RequestURI:    r.RequestURI,
-		RequestModel:  r.RequestModel,
-		ResponseModel: r.ResponseModel,
-		FinishReason:  r.FinishReason,
-		Stream:        r.IsStream == stringTrue,
-		UserAgent:     r.UserAgent,
-		RequestBody:   r.RequestBody,
-		ResponseBody:  r.ResponseBody,
-		BodyTruncated: r.BodyTruncated == stringTrue,
-		bodyChunked:   r.BodyChunked == stringTrue,
+		RequestID:      r.RequestID,
+		Time:           r.Time,
+		Workspace:      r.Workspace,
+		EndpointType:   r.EndpointType,
+		EndpointName:   r.EndpointName,
+		APIKeyID:       r.APIKeyID,
+		RequestURI:     r.RequestURI,
+		RequestModel:   r.RequestModel,
+		ResponseModel:  r.ResponseModel,
+		FinishReason:   r.FinishReason,
+		Stream:         r.IsStream == stringTrue,
+		UserAgent:      r.UserAgent,
+		RequestBody:    r.RequestBody,
+		ResponseBody:   r.ResponseBody,
+		BodyTruncated:  r.BodyTruncated == stringTrue,
+		BodySampledOut: r.BodySampledOut == stringTrue,
+		bodyChunked:    r.BodyChunked == stringTrue,
 	}
 
 	if n, err := strconv.Atoi(r.RequestChunks); err == nil {
@@ -213,9 +213,10 @@ func TestList_IncludeBodyReassemblesInOneBatch(t *testing.T) {
 func TestList_WithoutBodySkipsChunkFetch(t *testing.T) {
 	// Metadata-only pages never trigger chunk fetches, even when the page
 	// contains chunked records (their chunk metadata is not even projected) —
-	// but the body_truncated flag IS projected, so truncated traces stay
-	// recognizable in the list view.
-	page := `{"request_id":"req-a","workspace":"ws1","response_status":"200","body_truncated":"true"}` + "\n"
+	// but the body_truncated and body_sampled_out flags ARE projected, so
+	// truncated or sampled-out traces stay recognizable in the list view.
+	page := `{"request_id":"req-a","workspace":"ws1","response_status":"200","body_truncated":"true"}` + "\n" +
+		`{"request_id":"req-b","workspace":"ws1","response_status":"200","body_sampled_out":"true"}` + "\n"
 
 	fake := &fakeVL{responses: []string{page}}
 	server := fake.server(t)
@@ -226,11 +227,14 @@ func TestList_WithoutBodySkipsChunkFetch(t *testing.T) {
 
 	items, err := store.List(`workspace:="ws1"`, traceFilters{}, 50, false, timeWindow{})
 	require.NoError(t, err)
-	require.Len(t, items, 1)
+	require.Len(t, items, 2)
 	assert.True(t, items[0].BodyTruncated)
+	assert.False(t, items[0].BodySampledOut)
+	assert.True(t, items[1].BodySampledOut)
 	require.Len(t, fake.queries, 1)
 	assert.NotContains(t, fake.queries[0], "body_chunked")
 	assert.Contains(t, fake.queries[0], "body_truncated")
+	assert.Contains(t, fake.queries[0], "body_sampled_out")
 }
 
 func TestAssembleBody(t *testing.T) {
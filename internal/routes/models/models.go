@@ -574,6 +574,7 @@ func validateModelDeletion(deps *Dependencies, workspace, registryName, modelNam
 				Value:    modelName,
 			},
 		},
+		IncludeDeleted: true,
 	})
 	if err != nil {
 		return 0, fmt.Errorf("failed to list endpoints: %w", err)
@@ -4,14 +4,15 @@ import (
 	"github.com/gin-gonic/gin"
 
 	v1 "github.com/neutree-ai/neutree/api/v1"
+	"github.com/neutree-ai/neutree/pkg/storage"
 )
 
 func RegisterAPIKeyRoutes(group *gin.RouterGroup, middlewares []gin.HandlerFunc, deps *Dependencies) {
 	proxyGroup := group.Group("/api_keys")
 	proxyGroup.Use(middlewares...)
 
-	handler := CreateStructProxyHandler[v1.ApiKey](deps, "api_keys")
+	handler := CreateStructProxyHandler[v1.ApiKey](deps, storage.API_KEY_TABLE)
 
 	proxyGroup.GET("", handler)
-	proxyGroup.PATCH("", handler)
+	proxyGroup.PATCH("", validateExpectedVersion(deps.Storage, storage.API_KEY_TABLE), handler)
 }
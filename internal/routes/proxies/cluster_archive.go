@@ -0,0 +1,90 @@
+package proxies
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/klog/v2"
+
+	"github.com/neutree-ai/neutree/internal/middleware"
+	"github.com/neutree-ai/neutree/pkg/storage"
+)
+
+// clusterArchiveRequest is the request body for the archive and restore cluster endpoints.
+type clusterArchiveRequest struct {
+	Workspace string `json:"workspace"`
+	Name      string `json:"name" binding:"required"`
+}
+
+// handleArchiveCluster archives a cluster instead of deleting it, it is kept for audit and
+// recovery but no longer reconciled. Clusters still referenced by endpoints are refused.
+func handleArchiveCluster(deps *Dependencies) gin.HandlerFunc {
+	return handleClusterArchival(deps, "archive", deps.Storage.ArchiveCluster)
+}
+
+// handleRestoreCluster restores an archived cluster, the cluster controller reconciles it again.
+func handleRestoreCluster(deps *Dependencies) gin.HandlerFunc {
+	return handleClusterArchival(deps, "restore", deps.Storage.RestoreCluster)
+}
+
+func handleClusterArchival(deps *Dependencies, action string, apply func(id string) error) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req clusterArchiveRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body: " + err.Error()})
+			return
+		}
+
+		if req.Workspace == "" {
+			req.Workspace = defaultWorkspace
+		}
+
+		userID := c.GetString("user_id")
+		if userID == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+			return
+		}
+
+		hasPermission, err := middleware.CheckWorkspacePermission(deps.Storage, userID, req.Workspace, "cluster:update")
+		if err != nil {
+			klog.Errorf("Failed to check permission cluster:update for user %s: %v", userID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check permissions"})
+
+			return
+		}
+
+		if !hasPermission {
+			c.JSON(http.StatusForbidden, gin.H{"error": "insufficient permissions", "required": "cluster:update"})
+			return
+		}
+
+		cluster, err := deps.Storage.GetClusterByName(req.Workspace, req.Name)
+		if err != nil && !errors.Is(err, storage.ErrResourceNotFound) {
+			klog.Errorf("Failed to get cluster %s/%s: %v", req.Workspace, req.Name, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get cluster"})
+
+			return
+		}
+
+		if err != nil || cluster.GetDeletionTimestamp() != "" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "cluster not found"})
+			return
+		}
+
+		if err = apply(strconv.Itoa(cluster.ID)); err != nil {
+			if errors.Is(err, storage.ErrResourceInUse) {
+				c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+				return
+			}
+
+			klog.Errorf("Failed to %s cluster %s/%s: %v", action, req.Workspace, req.Name, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to " + action + " cluster"})
+
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,128 @@
+package proxies
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	v1 "github.com/neutree-ai/neutree/api/v1"
+	"github.com/neutree-ai/neutree/pkg/storage"
+	storageMocks "github.com/neutree-ai/neutree/pkg/storage/mocks"
+)
+
+func TestHandleClusterArchival(t *testing.T) {
+	cluster := &v1.Cluster{ID: 4, Metadata: &v1.Metadata{Workspace: "default", Name: "cluster"}}
+	deletedCluster := &v1.Cluster{ID: 4, Metadata: &v1.Metadata{Workspace: "default", Name: "cluster", DeletionTimestamp: "2026-10-01T00:00:00Z"}}
+
+	tests := []struct {
+		name         string
+		path         string
+		body         string
+		allowed      bool
+		cluster      *v1.Cluster
+		applyMethod  string
+		applyErr     error
+		expectedCode int
+	}{
+		{
+			name:         "archives cluster",
+			path:         "/clusters/archive",
+			body:         `{"workspace":"default","name":"cluster"}`,
+			allowed:      true,
+			cluster:      cluster,
+			applyMethod:  "ArchiveCluster",
+			expectedCode: http.StatusNoContent,
+		},
+		{
+			name:         "restores cluster",
+			path:         "/clusters/restore",
+			body:         `{"name":"cluster"}`,
+			allowed:      true,
+			cluster:      cluster,
+			applyMethod:  "RestoreCluster",
+			expectedCode: http.StatusNoContent,
+		},
+		{
+			name:         "cluster referenced by endpoints",
+			path:         "/clusters/archive",
+			body:         `{"name":"cluster"}`,
+			allowed:      true,
+			cluster:      cluster,
+			applyMethod:  "ArchiveCluster",
+			applyErr:     errors.Wrap(storage.ErrResourceInUse, "cluster default/cluster is still referenced by 1 endpoint(s)"),
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:         "cluster being deleted",
+			path:         "/clusters/restore",
+			body:         `{"name":"cluster"}`,
+			allowed:      true,
+			cluster:      deletedCluster,
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "cluster not found",
+			path:         "/clusters/archive",
+			body:         `{"name":"cluster"}`,
+			allowed:      true,
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "insufficient permissions",
+			path:         "/clusters/archive",
+			body:         `{"name":"cluster"}`,
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "missing name",
+			path:         "/clusters/archive",
+			body:         `{}`,
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStorage := storageMocks.NewMockStorage(t)
+			mockStorage.On("CallDatabaseFunction", "has_permission", mock.MatchedBy(func(params map[string]interface{}) bool {
+				return params["required_permission"] == "cluster:update" && params["workspace"] == "default"
+			}), mock.Anything).Run(func(args mock.Arguments) {
+				*args.Get(2).(*bool) = tt.allowed
+			}).Return(nil).Maybe()
+
+			if tt.cluster != nil {
+				mockStorage.On("GetClusterByName", "default", "cluster").Return(tt.cluster, nil).Maybe()
+			} else {
+				mockStorage.On("GetClusterByName", "default", "cluster").Return(nil, storage.ErrResourceNotFound).Maybe()
+			}
+
+			if tt.applyMethod != "" {
+				mockStorage.On(tt.applyMethod, "4").Return(tt.applyErr).Once()
+			}
+
+			gin.SetMode(gin.TestMode)
+
+			deps := &Dependencies{Storage: mockStorage}
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				c.Set("user_id", "user-123")
+				c.Next()
+			})
+			router.POST("/clusters/archive", handleArchiveCluster(deps))
+			router.POST("/clusters/restore", handleRestoreCluster(deps))
+
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			request.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(recorder, request)
+
+			assert.Equal(t, tt.expectedCode, recorder.Code)
+		})
+	}
+}
@@ -107,6 +107,194 @@ func validateClusterAcceleratorVirtualization(s storage.Storage) gin.HandlerFunc
 	}
 }
 
+// validateClusterSpecLimits rejects cluster payloads with out-of-range tuning values.
+func validateClusterSpecLimits() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodPost && c.Request.Method != http.MethodPatch {
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, invalidClusterPayloadError(err))
+			c.Abort()
+
+			return
+		}
+
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		if len(bytes.TrimSpace(body)) == 0 {
+			c.Next()
+			return
+		}
+
+		for _, validate := range []func([]byte) *validationError{
+			validateClusterReconcileIntervalBody,
+			validateClusterMaintenanceBody,
+			validateClusterNodeProvisionParallelismBody,
+			validateClusterDashboardOutageBody,
+			validateClusterModelCacheRegistryPathsBody,
+			validateClusterModelCacheNamesBody,
+		} {
+			if validationErr := validate(body); validationErr != nil {
+				c.JSON(http.StatusBadRequest, validationErr)
+				c.Abort()
+
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
+
+func validateClusterReconcileIntervalBody(body []byte) *validationError {
+	var cluster v1.Cluster
+	if err := json.Unmarshal(body, &cluster); err != nil {
+		return invalidClusterPayloadError(err)
+	}
+
+	if cluster.Spec == nil || cluster.Spec.ReconcileIntervalSeconds == nil {
+		return nil
+	}
+
+	if *cluster.Spec.ReconcileIntervalSeconds < v1.MinClusterReconcileIntervalSeconds {
+		return &validationError{
+			Code:    "10209",
+			Message: "invalid cluster payload",
+			Hint: fmt.Sprintf("spec.reconcile_interval_seconds must be at least %d, got %d",
+				v1.MinClusterReconcileIntervalSeconds, *cluster.Spec.ReconcileIntervalSeconds),
+		}
+	}
+
+	return nil
+}
+
+func validateClusterMaintenanceBody(body []byte) *validationError {
+	var cluster v1.Cluster
+	if err := json.Unmarshal(body, &cluster); err != nil {
+		return invalidClusterPayloadError(err)
+	}
+
+	if cluster.Spec == nil || cluster.Spec.Maintenance == nil {
+		return nil
+	}
+
+	for i, window := range cluster.Spec.Maintenance.Windows {
+		start, end, err := window.Parse()
+		if err != nil {
+			return &validationError{
+				Code:    "10209",
+				Message: "invalid cluster payload",
+				Hint:    fmt.Sprintf("spec.maintenance.windows[%d] must use RFC 3339 times: %v", i, err),
+			}
+		}
+
+		if !end.After(start) {
+			return &validationError{
+				Code:    "10209",
+				Message: "invalid cluster payload",
+				Hint:    fmt.Sprintf("spec.maintenance.windows[%d] must end after it starts", i),
+			}
+		}
+	}
+
+	return nil
+}
+
+func validateClusterNodeProvisionParallelismBody(body []byte) *validationError {
+	var cluster v1.Cluster
+	if err := json.Unmarshal(body, &cluster); err != nil {
+		return invalidClusterPayloadError(err)
+	}
+
+	if cluster.Spec == nil || cluster.Spec.Config == nil || cluster.Spec.Config.SSHConfig == nil ||
+		cluster.Spec.Config.SSHConfig.NodeProvisionParallelism == nil {
+		return nil
+	}
+
+	if *cluster.Spec.Config.SSHConfig.NodeProvisionParallelism < 1 {
+		return &validationError{
+			Code:    "10209",
+			Message: "invalid cluster payload",
+			Hint: fmt.Sprintf("spec.config.ssh_config.node_provision_parallelism must be at least 1, got %d",
+				*cluster.Spec.Config.SSHConfig.NodeProvisionParallelism),
+		}
+	}
+
+	return nil
+}
+
+func validateClusterDashboardOutageBody(body []byte) *validationError {
+	var cluster v1.Cluster
+	if err := json.Unmarshal(body, &cluster); err != nil {
+		return invalidClusterPayloadError(err)
+	}
+
+	if cluster.Spec == nil || cluster.Spec.Config == nil || cluster.Spec.Config.SSHConfig == nil {
+		return nil
+	}
+
+	switch cluster.Spec.Config.SSHConfig.DashboardOutage {
+	case "", v1.DashboardOutageFail, v1.DashboardOutageUnknown, v1.DashboardOutageLastKnown:
+		return nil
+	}
+
+	return &validationError{
+		Code:    "10209",
+		Message: "invalid cluster payload",
+		Hint: fmt.Sprintf("spec.config.ssh_config.dashboard_outage must be one of %s, %s or %s, got %q",
+			v1.DashboardOutageFail, v1.DashboardOutageUnknown, v1.DashboardOutageLastKnown,
+			cluster.Spec.Config.SSHConfig.DashboardOutage),
+	}
+}
+
+func validateClusterModelCacheRegistryPathsBody(body []byte) *validationError {
+	var cluster v1.Cluster
+	if err := json.Unmarshal(body, &cluster); err != nil {
+		return invalidClusterPayloadError(err)
+	}
+
+	if cluster.Spec == nil || cluster.Spec.Config == nil {
+		return nil
+	}
+
+	for i, cache := range cluster.Spec.Config.ModelCaches {
+		if err := clustervalidation.ValidateModelCacheRegistryPaths(cache); err != nil {
+			return &validationError{
+				Code:    "10209",
+				Message: "invalid cluster payload",
+				Hint:    fmt.Sprintf("spec.config.model_caches[%d].registry_paths: %s", i, err.Error()),
+			}
+		}
+	}
+
+	return nil
+}
+
+func validateClusterModelCacheNamesBody(body []byte) *validationError {
+	var cluster v1.Cluster
+	if err := json.Unmarshal(body, &cluster); err != nil {
+		return invalidClusterPayloadError(err)
+	}
+
+	if cluster.Spec == nil || cluster.Spec.Config == nil {
+		return nil
+	}
+
+	if err := clustervalidation.ValidateModelCacheNames(cluster.Spec.Config.ModelCaches); err != nil {
+		return &validationError{
+			Code:    "10209",
+			Message: "invalid cluster payload",
+			Hint:    fmt.Sprintf("spec.config.model_caches: %s", err.Error()),
+		}
+	}
+
+	return nil
+}
+
 func validateClusterVersionUpdate(s storage.Storage) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		if c.Request.Method != http.MethodPatch {
@@ -229,7 +417,7 @@ func resolveClusterForVersionUpdate(
 		}
 	}
 
-	clusters, err := s.ListCluster(storage.ListOption{Filters: filters})
+	clusters, err := s.ListCluster(storage.ListOption{Filters: filters, IncludeDeleted: true})
 	if err != nil {
 		return nil, &validationError{
 			Code:    "10212",
@@ -375,7 +563,7 @@ func validateClusterAcceleratorVirtualizationDisable(
 		return validationErr
 	}
 
-	endpoints, err := s.ListEndpoint(storage.ListOption{Filters: clusterEndpointReferenceFilters(workspace, name)})
+	endpoints, err := s.ListEndpoint(storage.ListOption{Filters: clusterEndpointReferenceFilters(workspace, name), IncludeDeleted: true})
 	if err != nil {
 		return &validationError{
 			Code:    "10209",
@@ -443,7 +631,7 @@ func resolveClusterIdentityForAcceleratorVirtualizationDisable(
 }
 
 func resolveClusterIdentityFromPatchFilters(s storage.Storage, filters []storage.Filter) (string, string, *validationError) {
-	clusters, err := s.ListCluster(storage.ListOption{Filters: filters})
+	clusters, err := s.ListCluster(storage.ListOption{Filters: filters, IncludeDeleted: true})
 	if err != nil {
 		return "", "", &validationError{
 			Code:    "10209",
@@ -559,8 +747,12 @@ func RegisterClusterRoutes(group *gin.RouterGroup, middlewares []gin.HandlerFunc
 	handler := CreateStructProxyHandler[v1.Cluster](deps, storage.CLUSTERS_TABLE)
 	acceleratorVirtualizationValidation := validateClusterAcceleratorVirtualization(deps.Storage)
 	versionUpdateValidation := validateClusterVersionUpdate(deps.Storage)
+	specLimitsValidation := validateClusterSpecLimits()
 
 	proxyGroup.GET("", handler)
-	proxyGroup.POST("", acceleratorVirtualizationValidation, handler)
-	proxyGroup.PATCH("", deletionValidation, versionUpdateValidation, acceleratorVirtualizationValidation, handler)
+	proxyGroup.POST("", acceleratorVirtualizationValidation, specLimitsValidation, handler)
+	proxyGroup.PATCH("", validateExpectedVersion(deps.Storage, storage.CLUSTERS_TABLE), deletionValidation, versionUpdateValidation, acceleratorVirtualizationValidation, specLimitsValidation, handler)
+
+	proxyGroup.POST("/archive", handleArchiveCluster(deps))
+	proxyGroup.POST("/restore", handleRestoreCluster(deps))
 }
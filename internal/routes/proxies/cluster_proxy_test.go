@@ -281,6 +281,256 @@ func TestValidateClusterAcceleratorVirtualizationBody(t *testing.T) {
 	})
 }
 
+func TestValidateClusterReconcileIntervalBody(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		expectErr bool
+	}{
+		{
+			name: "allows cluster without interval override",
+			body: `{"spec": {"type": "ssh"}}`,
+		},
+		{
+			name: "allows interval at the minimum",
+			body: `{"spec": {"type": "ssh", "reconcile_interval_seconds": 5}}`,
+		},
+		{
+			name:      "rejects interval below the minimum",
+			body:      `{"spec": {"type": "ssh", "reconcile_interval_seconds": 1}}`,
+			expectErr: true,
+		},
+		{
+			name:      "rejects invalid payload",
+			body:      `{"spec": {"reconcile_interval_seconds": "fast"}}`,
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateClusterReconcileIntervalBody([]byte(tt.body))
+			if !tt.expectErr {
+				assert.Nil(t, err)
+				return
+			}
+
+			if assert.NotNil(t, err) {
+				assert.Equal(t, "10209", err.Code)
+			}
+		})
+	}
+}
+
+func TestValidateClusterMaintenanceBody(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		expectErr bool
+	}{
+		{
+			name: "allows cluster without maintenance",
+			body: `{"spec": {"type": "ssh"}}`,
+		},
+		{
+			name: "allows paused cluster",
+			body: `{"spec": {"type": "ssh", "maintenance": {"paused": true}}}`,
+		},
+		{
+			name: "allows window",
+			body: `{"spec": {"maintenance": {"windows": [{"start": "2026-01-10T02:00:00Z", "end": "2026-01-10T04:00:00+01:00"}]}}}`,
+		},
+		{
+			name:      "rejects window with invalid time",
+			body:      `{"spec": {"maintenance": {"windows": [{"start": "tonight", "end": "2026-01-10T04:00:00Z"}]}}}`,
+			expectErr: true,
+		},
+		{
+			name:      "rejects window ending before it starts",
+			body:      `{"spec": {"maintenance": {"windows": [{"start": "2026-01-10T04:00:00Z", "end": "2026-01-10T02:00:00Z"}]}}}`,
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateClusterMaintenanceBody([]byte(tt.body))
+			if !tt.expectErr {
+				assert.Nil(t, err)
+				return
+			}
+
+			if assert.NotNil(t, err) {
+				assert.Equal(t, "10209", err.Code)
+			}
+		})
+	}
+}
+
+func TestValidateClusterNodeProvisionParallelismBody(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		expectErr bool
+	}{
+		{
+			name: "allows ssh cluster without parallelism",
+			body: `{"spec": {"type": "ssh", "config": {"ssh_config": {"provider": {"head_ip": "10.0.0.1"}}}}}`,
+		},
+		{
+			name: "allows positive parallelism",
+			body: `{"spec": {"type": "ssh", "config": {"ssh_config": {"node_provision_parallelism": 4}}}}`,
+		},
+		{
+			name:      "rejects zero parallelism",
+			body:      `{"spec": {"type": "ssh", "config": {"ssh_config": {"node_provision_parallelism": 0}}}}`,
+			expectErr: true,
+		},
+		{
+			name:      "rejects invalid payload",
+			body:      `{"spec": {"config": {"ssh_config": {"node_provision_parallelism": "many"}}}}`,
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateClusterNodeProvisionParallelismBody([]byte(tt.body))
+			if !tt.expectErr {
+				assert.Nil(t, err)
+				return
+			}
+
+			if assert.NotNil(t, err) {
+				assert.Equal(t, "10209", err.Code)
+			}
+		})
+	}
+}
+
+func TestValidateClusterDashboardOutageBody(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		expectErr bool
+	}{
+		{
+			name: "allows ssh cluster without policy",
+			body: `{"spec": {"type": "ssh", "config": {"ssh_config": {"provider": {"head_ip": "10.0.0.1"}}}}}`,
+		},
+		{
+			name: "allows last known policy",
+			body: `{"spec": {"type": "ssh", "config": {"ssh_config": {"dashboard_outage": "last_known"}}}}`,
+		},
+		{
+			name:      "rejects unknown policy",
+			body:      `{"spec": {"type": "ssh", "config": {"ssh_config": {"dashboard_outage": "ignore"}}}}`,
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateClusterDashboardOutageBody([]byte(tt.body))
+			if !tt.expectErr {
+				assert.Nil(t, err)
+				return
+			}
+
+			if assert.NotNil(t, err) {
+				assert.Equal(t, "10209", err.Code)
+			}
+		})
+	}
+}
+
+func TestValidateClusterModelCacheNamesBody(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		expectErr bool
+	}{
+		{
+			name: "allows single unnamed cache",
+			body: `{"spec": {"type": "kubernetes", "config": {"model_caches": [{"host_path": {"path": "/data"}}]}}}`,
+		},
+		{
+			name: "allows caches with distinct names",
+			body: `{"spec": {"type": "kubernetes", "config": {"model_caches": [{"name": "team-a"}, {"name": "team-b"}]}}}`,
+		},
+		{
+			name:      "rejects unnamed cache among several",
+			body:      `{"spec": {"type": "kubernetes", "config": {"model_caches": [{"name": "team-a"}, {}]}}}`,
+			expectErr: true,
+		},
+		{
+			name:      "rejects duplicate names",
+			body:      `{"spec": {"type": "kubernetes", "config": {"model_caches": [{"name": "team-a"}, {"name": "team-a"}]}}}`,
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateClusterModelCacheNamesBody([]byte(tt.body))
+			if !tt.expectErr {
+				assert.Nil(t, err)
+				return
+			}
+
+			if assert.NotNil(t, err) {
+				assert.Equal(t, "10209", err.Code)
+			}
+		})
+	}
+}
+
+func TestValidateClusterModelCacheRegistryPathsBody(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		expectErr bool
+	}{
+		{
+			name: "allows cache without registry paths",
+			body: `{"spec": {"type": "kubernetes", "config": {"model_caches": [{"name": "shared"}]}}}`,
+		},
+		{
+			name: "allows registry paths",
+			body: `{"spec": {"type": "ssh", "config": {"model_caches": [{"name": "shared", "registry_paths": {"hugging-face": "hf", "bentoml": "bento/models"}}]}}}`,
+		},
+		{
+			name:      "rejects unknown registry type",
+			body:      `{"spec": {"type": "ssh", "config": {"model_caches": [{"name": "shared", "registry_paths": {"s3": "s3"}}]}}}`,
+			expectErr: true,
+		},
+		{
+			name:      "rejects path leaving the cache",
+			body:      `{"spec": {"type": "kubernetes", "config": {"model_caches": [{"name": "shared", "registry_paths": {"hugging-face": "../hf"}}]}}}`,
+			expectErr: true,
+		},
+		{
+			name:      "rejects absolute path",
+			body:      `{"spec": {"type": "kubernetes", "config": {"model_caches": [{"name": "shared", "registry_paths": {"hugging-face": "/hf"}}]}}}`,
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateClusterModelCacheRegistryPathsBody([]byte(tt.body))
+			if !tt.expectErr {
+				assert.Nil(t, err)
+				return
+			}
+
+			if assert.NotNil(t, err) {
+				assert.Equal(t, "10209", err.Code)
+			}
+		})
+	}
+}
+
 func TestValidateClusterAcceleratorVirtualizationDisable(t *testing.T) {
 	vGPUEndpoint := v1.Endpoint{
 		Spec: &v1.EndpointSpec{
@@ -311,7 +561,7 @@ func TestValidateClusterAcceleratorVirtualizationDisable(t *testing.T) {
 		}
 		expectedEndpointFilters := clusterEndpointReferenceFilters("default", "gpu-cluster")
 
-		mockStorage.On("ListEndpoint", storage.ListOption{Filters: expectedEndpointFilters}).
+		mockStorage.On("ListEndpoint", storage.ListOption{Filters: expectedEndpointFilters, IncludeDeleted: true}).
 			Return([]v1.Endpoint{vGPUEndpoint}, nil)
 
 		err := validateClusterAcceleratorVirtualizationDisable(mockStorage, cluster, nil)
@@ -333,7 +583,7 @@ func TestValidateClusterAcceleratorVirtualizationDisable(t *testing.T) {
 		}
 		expectedEndpointFilters := clusterEndpointReferenceFilters("default", "gpu-cluster")
 
-		mockStorage.On("ListEndpoint", storage.ListOption{Filters: expectedEndpointFilters}).
+		mockStorage.On("ListEndpoint", storage.ListOption{Filters: expectedEndpointFilters, IncludeDeleted: true}).
 			Return([]v1.Endpoint{nonVGPUEndpoint}, nil)
 
 		err := validateClusterAcceleratorVirtualizationDisable(mockStorage, cluster, nil)
@@ -360,7 +610,7 @@ func TestValidateClusterAcceleratorVirtualizationDisable(t *testing.T) {
 		})).Return([]v1.Cluster{
 			{Metadata: &v1.Metadata{Workspace: "default", Name: "gpu-cluster"}},
 		}, nil)
-		mockStorage.On("ListEndpoint", storage.ListOption{Filters: expectedEndpointFilters}).
+		mockStorage.On("ListEndpoint", storage.ListOption{Filters: expectedEndpointFilters, IncludeDeleted: true}).
 			Return([]v1.Endpoint{vGPUEndpoint}, nil)
 
 		err := validateClusterAcceleratorVirtualizationDisable(mockStorage, clusterPatch, query)
@@ -409,7 +659,7 @@ func TestValidateClusterAcceleratorVirtualizationDisable(t *testing.T) {
 		}
 		expectedEndpointFilters := clusterEndpointReferenceFilters("default", "gpu-cluster")
 
-		mockStorage.On("ListEndpoint", storage.ListOption{Filters: expectedEndpointFilters}).
+		mockStorage.On("ListEndpoint", storage.ListOption{Filters: expectedEndpointFilters, IncludeDeleted: true}).
 			Return(nil, errors.New("database error"))
 
 		err := validateClusterAcceleratorVirtualizationDisable(mockStorage, cluster, nil)
@@ -428,7 +678,7 @@ func TestValidateClusterAcceleratorVirtualizationDisable(t *testing.T) {
 		}
 		expectedEndpointFilters := clusterEndpointReferenceFilters("default", "gpu-cluster")
 
-		mockStorage.On("ListEndpoint", storage.ListOption{Filters: expectedEndpointFilters}).
+		mockStorage.On("ListEndpoint", storage.ListOption{Filters: expectedEndpointFilters, IncludeDeleted: true}).
 			Return([]v1.Endpoint{vGPUEndpoint}, nil)
 
 		err := validateClusterAcceleratorVirtualizationDisable(mockStorage, cluster, nil)
@@ -447,7 +697,8 @@ func TestValidateClusterAcceleratorVirtualizationMiddleware(t *testing.T) {
 	t.Run("rejects disable patch before proxy handler when vGPU endpoint references cluster", func(t *testing.T) {
 		mockStorage := storageMocks.NewMockStorage(t)
 		mockStorage.On("ListEndpoint", storage.ListOption{
-			Filters: clusterEndpointReferenceFilters("default", "gpu-cluster"),
+			Filters:        clusterEndpointReferenceFilters("default", "gpu-cluster"),
+			IncludeDeleted: true,
 		}).Return([]v1.Endpoint{
 			{
 				Spec: &v1.EndpointSpec{
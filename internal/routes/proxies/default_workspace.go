@@ -0,0 +1,184 @@
+package proxies
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+
+	v1 "github.com/neutree-ai/neutree/api/v1"
+	"github.com/neutree-ai/neutree/internal/middleware"
+	"github.com/neutree-ai/neutree/pkg/storage"
+)
+
+// DefaultWorkspaceName is the workspace endpoints are created in when the request does not name one.
+const DefaultWorkspaceName = "default"
+
+// defaultWorkspaceProvisioner creates the default workspace the first time a resource is created in it.
+// Only the workspace record is stored here, the workspace controller then finishes its provisioning.
+type defaultWorkspaceProvisioner struct {
+	storage storage.Storage
+	name    string
+
+	mu          sync.Mutex
+	provisioned bool
+}
+
+func newDefaultWorkspaceProvisioner(s storage.Storage, name string) *defaultWorkspaceProvisioner {
+	if name == "" {
+		name = DefaultWorkspaceName
+	}
+
+	return &defaultWorkspaceProvisioner{
+		storage: s,
+		name:    name,
+	}
+}
+
+// middleware fills in the default workspace for POST requests without one and provisions it
+// if it does not exist yet. Provisioning requires the workspace:create permission, requests of
+// users without it are passed through unchanged and rejected by the storage RBAC as before.
+func (p *defaultWorkspaceProvisioner) middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodPost {
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, &validationError{
+				Code:    "10214",
+				Message: "invalid endpoint payload",
+				Hint:    err.Error(),
+			})
+			c.Abort()
+
+			return
+		}
+
+		workspace, body := p.defaultRequestWorkspace(body)
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		c.Request.ContentLength = int64(len(body))
+
+		if workspace != p.name {
+			c.Next()
+			return
+		}
+
+		if err := p.ensure(c.GetString("user_id")); err != nil {
+			klog.Errorf("Failed to provision default workspace %s: %v", p.name, err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to provision default workspace",
+			})
+			c.Abort()
+
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// defaultRequestWorkspace returns the workspace of a single-object request body, setting it to
+// the default workspace when the body does not name one.
+func (p *defaultWorkspaceProvisioner) defaultRequestWorkspace(body []byte) (string, []byte) {
+	var object map[string]interface{}
+	if err := json.Unmarshal(body, &object); err != nil || object == nil {
+		return "", body
+	}
+
+	metadata, ok := object["metadata"].(map[string]interface{})
+	if !ok {
+		return "", body
+	}
+
+	if workspace, ok := metadata["workspace"].(string); ok && workspace != "" {
+		return workspace, body
+	}
+
+	metadata["workspace"] = p.name
+
+	defaulted, err := json.Marshal(object)
+	if err != nil {
+		return "", body
+	}
+
+	return p.name, defaulted
+}
+
+// ensure creates the default workspace unless it already exists. It is safe to call concurrently
+// and from several API replicas: a concurrent insert is caught by the unique workspace name.
+func (p *defaultWorkspaceProvisioner) ensure(userID string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.provisioned {
+		return nil
+	}
+
+	exists, err := p.exists()
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		p.provisioned = true
+		return nil
+	}
+
+	if userID == "" {
+		return nil
+	}
+
+	allowed, err := middleware.CheckWorkspacePermission(p.storage, userID, "", "workspace:create")
+	if err != nil {
+		return errors.Wrap(err, "failed to check workspace:create permission")
+	}
+
+	if !allowed {
+		klog.V(4).Infof("User %s may not create workspaces, skipping provisioning of default workspace %s", userID, p.name)
+		return nil
+	}
+
+	klog.Infof("Provisioning default workspace %s", p.name)
+
+	createErr := p.storage.CreateWorkspace(&v1.Workspace{
+		APIVersion: "v1",
+		Kind:       "Workspace",
+		Metadata:   &v1.Metadata{Name: p.name},
+	})
+	if createErr != nil {
+		// Another API replica may have created it in the meantime.
+		if exists, err := p.exists(); err != nil || !exists {
+			return errors.Wrapf(createErr, "failed to create workspace %s", p.name)
+		}
+	}
+
+	p.provisioned = true
+
+	return nil
+}
+
+func (p *defaultWorkspaceProvisioner) exists() (bool, error) {
+	workspaces, err := p.storage.ListWorkspace(storage.ListOption{
+		Filters: []storage.Filter{
+			{
+				Column:   "metadata->name",
+				Operator: "eq",
+				Value:    strconv.Quote(p.name),
+			},
+		},
+	})
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to list workspace %s", p.name)
+	}
+
+	return len(workspaces) > 0, nil
+}
@@ -0,0 +1,133 @@
+package proxies
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	v1 "github.com/neutree-ai/neutree/api/v1"
+	storageMocks "github.com/neutree-ai/neutree/pkg/storage/mocks"
+)
+
+func mockWorkspaceCreatePermission(mockStorage *storageMocks.MockStorage, allowed bool) {
+	mockStorage.On("CallDatabaseFunction", "has_permission", mock.MatchedBy(func(params map[string]interface{}) bool {
+		return params["user_uuid"] == "user-123" &&
+			params["required_permission"] == "workspace:create" &&
+			params["workspace"] == nil
+	}), mock.Anything).Run(func(args mock.Arguments) {
+		result := args.Get(2).(*bool)
+		*result = allowed
+	}).Return(nil)
+}
+
+func runDefaultWorkspaceProvisioning(t *testing.T, p *defaultWorkspaceProvisioner, body string) (*httptest.ResponseRecorder, *v1.Endpoint) {
+	gin.SetMode(gin.TestMode)
+
+	var received *v1.Endpoint
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", "user-123")
+		c.Next()
+	})
+	router.POST("/endpoints", p.middleware(), func(c *gin.Context) {
+		data, err := io.ReadAll(c.Request.Body)
+		require.NoError(t, err)
+
+		received = &v1.Endpoint{}
+		require.NoError(t, json.Unmarshal(data, received))
+		c.Status(http.StatusCreated)
+	})
+
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPost, "/endpoints", strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(recorder, request)
+
+	return recorder, received
+}
+
+func TestDefaultWorkspaceProvisioning_CreatedOnceOnFirstEndpoint(t *testing.T) {
+	mockStorage := storageMocks.NewMockStorage(t)
+	mockStorage.On("ListWorkspace", mock.Anything).Return([]v1.Workspace{}, nil).Once()
+	mockWorkspaceCreatePermission(mockStorage, true)
+	mockStorage.On("CreateWorkspace", mock.MatchedBy(func(workspace *v1.Workspace) bool {
+		return workspace.Metadata.Name == "team" && workspace.Kind == "Workspace"
+	})).Return(nil).Once()
+
+	p := newDefaultWorkspaceProvisioner(mockStorage, "team")
+
+	recorder, endpoint := runDefaultWorkspaceProvisioning(t, p, `{"metadata":{"name":"ep-1"},"spec":{}}`)
+	assert.Equal(t, http.StatusCreated, recorder.Code)
+	assert.Equal(t, "team", endpoint.Metadata.Workspace)
+
+	// The provisioned workspace is reused by later endpoints without touching storage again.
+	recorder, endpoint = runDefaultWorkspaceProvisioning(t, p, `{"metadata":{"name":"ep-2","workspace":"team"},"spec":{}}`)
+	assert.Equal(t, http.StatusCreated, recorder.Code)
+	assert.Equal(t, "team", endpoint.Metadata.Workspace)
+
+	mockStorage.AssertNumberOfCalls(t, "CreateWorkspace", 1)
+}
+
+func TestDefaultWorkspaceProvisioning_ExistingWorkspaceIsReused(t *testing.T) {
+	mockStorage := storageMocks.NewMockStorage(t)
+	mockStorage.On("ListWorkspace", mock.Anything).Return([]v1.Workspace{
+		{Metadata: &v1.Metadata{Name: DefaultWorkspaceName}},
+	}, nil).Once()
+
+	p := newDefaultWorkspaceProvisioner(mockStorage, "")
+
+	for _, name := range []string{"ep-1", "ep-2"} {
+		recorder, endpoint := runDefaultWorkspaceProvisioning(t, p, `{"metadata":{"name":"`+name+`"}}`)
+		assert.Equal(t, http.StatusCreated, recorder.Code)
+		assert.Equal(t, DefaultWorkspaceName, endpoint.Metadata.Workspace)
+	}
+
+	mockStorage.AssertNotCalled(t, "CreateWorkspace", mock.Anything)
+}
+
+func TestDefaultWorkspaceProvisioning_RequiresWorkspaceCreatePermission(t *testing.T) {
+	mockStorage := storageMocks.NewMockStorage(t)
+	mockStorage.On("ListWorkspace", mock.Anything).Return([]v1.Workspace{}, nil)
+	mockWorkspaceCreatePermission(mockStorage, false)
+
+	p := newDefaultWorkspaceProvisioner(mockStorage, DefaultWorkspaceName)
+
+	recorder, endpoint := runDefaultWorkspaceProvisioning(t, p, `{"metadata":{"name":"ep-1","workspace":"default"}}`)
+	assert.Equal(t, http.StatusCreated, recorder.Code)
+	assert.Equal(t, DefaultWorkspaceName, endpoint.Metadata.Workspace)
+	mockStorage.AssertNotCalled(t, "CreateWorkspace", mock.Anything)
+}
+
+func TestDefaultWorkspaceProvisioning_ConcurrentCreate(t *testing.T) {
+	mockStorage := storageMocks.NewMockStorage(t)
+	mockStorage.On("ListWorkspace", mock.Anything).Return([]v1.Workspace{}, nil).Once()
+	mockWorkspaceCreatePermission(mockStorage, true)
+	mockStorage.On("CreateWorkspace", mock.Anything).Return(assert.AnError).Once()
+	mockStorage.On("ListWorkspace", mock.Anything).Return([]v1.Workspace{
+		{Metadata: &v1.Metadata{Name: DefaultWorkspaceName}},
+	}, nil).Once()
+
+	p := newDefaultWorkspaceProvisioner(mockStorage, DefaultWorkspaceName)
+
+	recorder, _ := runDefaultWorkspaceProvisioning(t, p, `{"metadata":{"name":"ep-1"}}`)
+	assert.Equal(t, http.StatusCreated, recorder.Code)
+}
+
+func TestDefaultWorkspaceProvisioning_OtherWorkspaceUntouched(t *testing.T) {
+	mockStorage := storageMocks.NewMockStorage(t)
+
+	p := newDefaultWorkspaceProvisioner(mockStorage, DefaultWorkspaceName)
+
+	recorder, endpoint := runDefaultWorkspaceProvisioning(t, p, `{"metadata":{"name":"ep-1","workspace":"prod"}}`)
+	assert.Equal(t, http.StatusCreated, recorder.Code)
+	assert.Equal(t, "prod", endpoint.Metadata.Workspace)
+}
@@ -0,0 +1,108 @@
+package proxies
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	v1 "github.com/neutree-ai/neutree/api/v1"
+)
+
+// defaultEndpointRequestTimeout sets deployment_options.router.request_timeout_seconds of the
+// endpoints created without one to the default of their model task. It only applies to new
+// endpoints: an upsert may replace an existing endpoint, whose requests are left as bounded
+// as they were, and PATCH requests are passed through unchanged.
+func defaultEndpointRequestTimeout() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodPost || strings.Contains(c.Request.Header.Get("Prefer"), "resolution=") {
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, invalidEndpointPayloadError(err))
+			c.Abort()
+
+			return
+		}
+
+		body = defaultRequestTimeout(body)
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		c.Request.ContentLength = int64(len(body))
+
+		c.Next()
+	}
+}
+
+// defaultRequestTimeout returns the request body with the default request timeout set on each
+// endpoint that does not configure one. Bodies it cannot parse are returned as they are and
+// left to the later validation.
+func defaultRequestTimeout(body []byte) []byte {
+	var payload interface{}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return body
+	}
+
+	objects, ok := payload.([]interface{})
+	if !ok {
+		objects = []interface{}{payload}
+	}
+
+	defaulted := false
+
+	for _, object := range objects {
+		if endpoint, ok := object.(map[string]interface{}); ok && defaultEndpointSpecRequestTimeout(endpoint) {
+			defaulted = true
+		}
+	}
+
+	if !defaulted {
+		return body
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return body
+	}
+
+	return data
+}
+
+func defaultEndpointSpecRequestTimeout(endpoint map[string]interface{}) bool {
+	spec, ok := endpoint["spec"].(map[string]interface{})
+	if !ok {
+		return false
+	}
+
+	task := ""
+	if model, ok := spec["model"].(map[string]interface{}); ok {
+		task, _ = model["task"].(string)
+	}
+
+	if spec["deployment_options"] == nil {
+		spec["deployment_options"] = map[string]interface{}{}
+	}
+
+	deploymentOptions, ok := spec["deployment_options"].(map[string]interface{})
+	if !ok {
+		return false
+	}
+
+	if deploymentOptions["router"] == nil {
+		deploymentOptions["router"] = map[string]interface{}{}
+	}
+
+	router, ok := deploymentOptions["router"].(map[string]interface{})
+	if !ok || router["request_timeout_seconds"] != nil {
+		return false
+	}
+
+	router["request_timeout_seconds"] = v1.DefaultRequestTimeoutSeconds(task)
+
+	return true
+}
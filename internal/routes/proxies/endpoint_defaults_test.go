@@ -0,0 +1,112 @@
+package proxies
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultEndpointRequestTimeout(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		prefer string
+		body   string
+		expect interface{}
+	}{
+		{
+			name:   "text generation endpoint",
+			method: http.MethodPost,
+			body:   `{"metadata":{"name":"e"},"spec":{"model":{"task":"text-generation"}}}`,
+			expect: float64(600),
+		},
+		{
+			name:   "text embedding endpoint with router options",
+			method: http.MethodPost,
+			body:   `{"spec":{"model":{"task":"text-embedding"},"deployment_options":{"router":{"retries":2}}}}`,
+			expect: float64(30),
+		},
+		{
+			name:   "endpoint without task",
+			method: http.MethodPost,
+			body:   `{"spec":{"deployment_options":{}}}`,
+			expect: float64(300),
+		},
+		{
+			name:   "endpoint configuring its timeout",
+			method: http.MethodPost,
+			body:   `{"spec":{"model":{"task":"text-generation"},"deployment_options":{"router":{"request_timeout_seconds":60}}}}`,
+			expect: float64(60),
+		},
+		{
+			name:   "upsert of an existing endpoint",
+			method: http.MethodPost,
+			prefer: "resolution=merge-duplicates",
+			body:   `{"spec":{"model":{"task":"text-generation"}}}`,
+		},
+		{
+			name:   "update of an existing endpoint",
+			method: http.MethodPatch,
+			body:   `{"spec":{"model":{"task":"text-generation"}}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+
+			var received map[string]interface{}
+
+			router := gin.New()
+			router.Handle(tt.method, "/endpoints", defaultEndpointRequestTimeout(), func(c *gin.Context) {
+				data, err := io.ReadAll(c.Request.Body)
+				require.NoError(t, err)
+				require.NoError(t, json.Unmarshal(data, &received))
+				c.Status(http.StatusCreated)
+			})
+
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(tt.method, "/endpoints", strings.NewReader(tt.body))
+			request.Header.Set("Content-Type", "application/json")
+
+			if tt.prefer != "" {
+				request.Header.Set("Prefer", tt.prefer)
+			}
+
+			router.ServeHTTP(recorder, request)
+			require.Equal(t, http.StatusCreated, recorder.Code)
+
+			spec := received["spec"].(map[string]interface{})
+			deploymentOptions, _ := spec["deployment_options"].(map[string]interface{})
+			routerOptions, _ := deploymentOptions["router"].(map[string]interface{})
+			assert.Equal(t, tt.expect, routerOptions["request_timeout_seconds"])
+		})
+	}
+}
+
+func TestDefaultRequestTimeout_BulkInsert(t *testing.T) {
+	body := defaultRequestTimeout([]byte(`[{"spec":{"model":{"task":"text-rerank"}}},{"spec":{"model":{"task":"text-generation"}}}]`))
+
+	var endpoints []struct {
+		Spec struct {
+			DeploymentOptions struct {
+				Router struct {
+					RequestTimeoutSeconds float64 `json:"request_timeout_seconds"`
+				} `json:"router"`
+			} `json:"deployment_options"`
+		} `json:"spec"`
+	}
+	require.NoError(t, json.Unmarshal(body, &endpoints))
+	require.Len(t, endpoints, 2)
+	assert.Equal(t, float64(30), endpoints[0].Spec.DeploymentOptions.Router.RequestTimeoutSeconds)
+	assert.Equal(t, float64(600), endpoints[1].Spec.DeploymentOptions.Router.RequestTimeoutSeconds)
+
+	assert.Equal(t, "not json", string(defaultRequestTimeout([]byte("not json"))))
+}
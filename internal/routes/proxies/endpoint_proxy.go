@@ -1,9 +1,15 @@
 package proxies
 
 import (
+	"errors"
+	"net/http"
+	"strconv"
+
 	"github.com/gin-gonic/gin"
+	"k8s.io/klog/v2"
 
 	v1 "github.com/neutree-ai/neutree/api/v1"
+	"github.com/neutree-ai/neutree/internal/middleware"
 	"github.com/neutree-ai/neutree/pkg/storage"
 )
 
@@ -14,15 +20,107 @@ import (
 // Disallowed methods:
 //   - PUT: Not supported (use PATCH for updates)
 //   - DELETE: Use deletion timestamp pattern instead
+//
+// A PATCH with an If-Match header holding the resource_version the client read the endpoint
+// at fails with 409 Conflict if the endpoint spec was changed since, as for the other resources.
 func RegisterEndpointRoutes(group *gin.RouterGroup, middlewares []gin.HandlerFunc, deps *Dependencies) {
 	proxyGroup := group.Group("/endpoints")
 	proxyGroup.Use(middlewares...)
 
 	handler := CreateStructProxyHandler[v1.Endpoint](deps, storage.ENDPOINT_TABLE)
 	vgpuValidation := validateEndpointVGPU(deps.Storage)
+	engineArgsValidation := validateEndpointEngineArgs(deps.Storage)
+	expectedVersion := validateExpectedVersion(deps.Storage, storage.ENDPOINT_TABLE)
+	workspaceProvisioning := newDefaultWorkspaceProvisioner(deps.Storage, deps.DefaultWorkspace).middleware()
+	requestTimeoutDefaulting := defaultEndpointRequestTimeout()
 
 	// Only register allowed methods
 	proxyGroup.GET("", handler)
-	proxyGroup.POST("", vgpuValidation, handler)
-	proxyGroup.PATCH("", vgpuValidation, handler)
+	proxyGroup.POST("", workspaceProvisioning, requestTimeoutDefaulting, vgpuValidation, engineArgsValidation, handler)
+	proxyGroup.PATCH("", expectedVersion, vgpuValidation, engineArgsValidation, handler)
+
+	// Refresh the pinned engine image digest
+	proxyGroup.POST("/refresh_image_digest", handleRefreshImageDigest(deps))
+}
+
+// refreshImageDigestRequest is the request body for the refresh image digest endpoint.
+type refreshImageDigestRequest struct {
+	Workspace string `json:"workspace"`
+	Name      string `json:"name" binding:"required"`
+}
+
+// refreshImageDigestResponse is the response body for the refresh image digest endpoint.
+type refreshImageDigestResponse struct {
+	PreviousDigest string `json:"previous_digest,omitempty"`
+}
+
+// handleRefreshImageDigest clears the engine image digest a digest-pinned endpoint is pinned to.
+// The endpoint controller then resolves the image tag again and redeploys by the new digest.
+func handleRefreshImageDigest(deps *Dependencies) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req refreshImageDigestRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body: " + err.Error()})
+			return
+		}
+
+		if req.Workspace == "" {
+			req.Workspace = defaultWorkspace
+		}
+
+		userID := c.GetString("user_id")
+		if userID == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+			return
+		}
+
+		hasPermission, err := middleware.CheckWorkspacePermission(deps.Storage, userID, req.Workspace, "endpoint:update")
+		if err != nil {
+			klog.Errorf("Failed to check permission endpoint:update for user %s: %v", userID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check permissions"})
+
+			return
+		}
+
+		if !hasPermission {
+			c.JSON(http.StatusForbidden, gin.H{"error": "insufficient permissions", "required": "endpoint:update"})
+			return
+		}
+
+		endpoint, err := deps.Storage.GetEndpointByName(req.Workspace, req.Name)
+		if err != nil && !errors.Is(err, storage.ErrResourceNotFound) {
+			klog.Errorf("Failed to get endpoint %s/%s: %v", req.Workspace, req.Name, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get endpoint"})
+
+			return
+		}
+
+		// endpoints being deleted are not redeployed.
+		if err != nil || endpoint.GetDeletionTimestamp() != "" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "endpoint not found"})
+			return
+		}
+
+		if !endpoint.Spec.PinsEngineImageDigest() {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "endpoint does not pin its engine image digest"})
+			return
+		}
+
+		if endpoint.Status == nil || endpoint.Status.EngineImageDigest == "" {
+			c.JSON(http.StatusOK, refreshImageDigestResponse{})
+			return
+		}
+
+		status := *endpoint.Status
+		status.EngineImageDigest = ""
+
+		if err = deps.Storage.UpdateEndpoint(strconv.Itoa(endpoint.ID), &v1.Endpoint{Status: &status}); err != nil {
+			klog.Errorf("Failed to clear engine image digest of endpoint %s/%s: %v", req.Workspace, req.Name, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to refresh engine image digest"})
+
+			return
+		}
+
+		c.JSON(http.StatusOK, refreshImageDigestResponse{PreviousDigest: endpoint.Status.EngineImageDigest})
+	}
 }
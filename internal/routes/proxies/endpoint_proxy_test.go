@@ -0,0 +1,112 @@
+package proxies
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	v1 "github.com/neutree-ai/neutree/api/v1"
+	"github.com/neutree-ai/neutree/pkg/storage"
+	storageMocks "github.com/neutree-ai/neutree/pkg/storage/mocks"
+)
+
+func TestHandleRefreshImageDigest(t *testing.T) {
+	pinnedEndpoint := func(pin bool, digest string) *v1.Endpoint {
+		return &v1.Endpoint{
+			ID:       3,
+			Metadata: &v1.Metadata{Workspace: "default", Name: "ep"},
+			Spec: &v1.EndpointSpec{
+				Engine: &v1.EndpointEngineSpec{Engine: "vllm", Version: "v0.11.2", PinImageDigest: pin},
+			},
+			Status: &v1.EndpointStatus{Phase: v1.EndpointPhaseRUNNING, EngineImageDigest: digest},
+		}
+	}
+
+	tests := []struct {
+		name          string
+		body          string
+		allowed       bool
+		endpoint      *v1.Endpoint
+		expectCleared bool
+		expectedCode  int
+	}{
+		{
+			name:          "clears pinned digest",
+			body:          `{"workspace":"default","name":"ep"}`,
+			allowed:       true,
+			endpoint:      pinnedEndpoint(true, "sha256:abc"),
+			expectCleared: true,
+			expectedCode:  http.StatusOK,
+		},
+		{
+			name:         "endpoint not pinned",
+			body:         `{"name":"ep"}`,
+			allowed:      true,
+			endpoint:     pinnedEndpoint(false, ""),
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "endpoint not found",
+			body:         `{"name":"ep"}`,
+			allowed:      true,
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "insufficient permissions",
+			body:         `{"name":"ep"}`,
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "missing name",
+			body:         `{}`,
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStorage := storageMocks.NewMockStorage(t)
+			mockStorage.On("CallDatabaseFunction", "has_permission", mock.MatchedBy(func(params map[string]interface{}) bool {
+				return params["required_permission"] == "endpoint:update" && params["workspace"] == "default"
+			}), mock.Anything).Run(func(args mock.Arguments) {
+				*args.Get(2).(*bool) = tt.allowed
+			}).Return(nil).Maybe()
+			if tt.endpoint != nil {
+				mockStorage.On("GetEndpointByName", "default", "ep").Return(tt.endpoint, nil).Maybe()
+			} else {
+				mockStorage.On("GetEndpointByName", "default", "ep").Return(nil, storage.ErrResourceNotFound).Maybe()
+			}
+
+			if tt.expectCleared {
+				mockStorage.On("UpdateEndpoint", "3", mock.MatchedBy(func(e *v1.Endpoint) bool {
+					return e.Status.EngineImageDigest == "" && e.Status.Phase == v1.EndpointPhaseRUNNING
+				})).Return(nil).Once()
+			}
+
+			gin.SetMode(gin.TestMode)
+
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				c.Set("user_id", "user-123")
+				c.Next()
+			})
+			router.POST("/endpoints/refresh_image_digest", handleRefreshImageDigest(&Dependencies{Storage: mockStorage}))
+
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodPost, "/endpoints/refresh_image_digest", strings.NewReader(tt.body))
+			request.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(recorder, request)
+
+			assert.Equal(t, tt.expectedCode, recorder.Code)
+
+			if tt.expectCleared {
+				assert.JSONEq(t, `{"previous_digest":"sha256:abc"}`, recorder.Body.String())
+			}
+		})
+	}
+}
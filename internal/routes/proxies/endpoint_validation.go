@@ -3,6 +3,7 @@ package proxies
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -12,6 +13,7 @@ import (
 	"github.com/gin-gonic/gin"
 
 	v1 "github.com/neutree-ai/neutree/api/v1"
+	"github.com/neutree-ai/neutree/internal/engine"
 	"github.com/neutree-ai/neutree/pkg/storage"
 )
 
@@ -58,6 +60,117 @@ func validateEndpointVGPU(store storage.Storage) gin.HandlerFunc {
 	}
 }
 
+// validateEndpointEngineArgs rejects an endpoint created or updated with engine_args the
+// values schema of its engine version does not accept, instead of failing its deploy. A
+// PATCH is validated against the engine and engine_args of the stored endpoint it does not
+// change. Endpoints whose engine does not exist yet are left to the deploy to report.
+func validateEndpointEngineArgs(store storage.Storage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodPost && c.Request.Method != http.MethodPatch {
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, invalidEndpointPayloadError(err))
+			c.Abort()
+
+			return
+		}
+
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		if len(bytes.TrimSpace(body)) == 0 {
+			c.Next()
+			return
+		}
+
+		if validationErr := validateEndpointEngineArgsRequest(store, c.Request.Method, c.Request.URL.Query(), body); validationErr != nil {
+			c.JSON(validationErrStatus(validationErr), validationErr)
+			c.Abort()
+
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func validateEndpointEngineArgsRequest(
+	store storage.Storage,
+	method string,
+	queryParams url.Values,
+	body []byte,
+) *validationError {
+	endpoint, validationErr := parseEndpointBody(body)
+	if validationErr != nil {
+		return validationErr
+	}
+
+	if endpoint.Spec == nil || (endpoint.Spec.Engine == nil && endpoint.Spec.Variables == nil) {
+		return nil
+	}
+
+	if method == http.MethodPatch {
+		filters := queryParamsToFilters(queryParams)
+		if len(filters) == 0 {
+			return nil
+		}
+
+		existing, err := store.ListEndpoint(storage.ListOption{Filters: filters})
+		if err != nil {
+			return endpointEngineArgsLookupError("failed to look up the endpoint to validate engine_args")
+		}
+
+		if len(existing) != 1 || existing[0].Spec == nil {
+			return nil
+		}
+
+		spec := *endpoint.Spec
+		if spec.Engine == nil {
+			spec.Engine = existing[0].Spec.Engine
+		}
+
+		if spec.Variables == nil {
+			spec.Variables = existing[0].Spec.Variables
+		}
+
+		endpoint = &v1.Endpoint{Metadata: existing[0].Metadata, Spec: &spec}
+	}
+
+	if endpoint.Spec.Engine == nil || endpoint.Spec.Engine.Engine == "" {
+		return nil
+	}
+
+	if _, ok := endpoint.Spec.Variables["engine_args"].(map[string]interface{}); !ok {
+		return nil
+	}
+
+	workspace := defaultWorkspace
+	if endpoint.Metadata != nil && endpoint.Metadata.Workspace != "" {
+		workspace = endpoint.Metadata.Workspace
+	}
+
+	engines, err := store.ListEngine(storage.ListOption{Filters: []storage.Filter{
+		{Column: "metadata->name", Operator: "eq", Value: strconv.Quote(endpoint.Spec.Engine.Engine)},
+		{Column: "metadata->workspace", Operator: "eq", Value: strconv.Quote(workspace)},
+	}})
+	if err != nil {
+		return endpointEngineArgsLookupError("failed to look up engine " + endpoint.Spec.Engine.Engine + " to validate engine_args")
+	}
+
+	if len(engines) == 0 {
+		return nil
+	}
+
+	if err := engine.ValidateEndpointEngineArgs(endpoint, &engines[0]); err != nil {
+		return endpointEngineArgsError(err.Error())
+	}
+
+	return nil
+}
+
 func validateEndpointVGPURequest(
 	store storage.Storage,
 	method string,
@@ -177,7 +290,7 @@ func resolveEndpointPatch(
 		return nil, endpointVGPUTargetError("endpoint lookup filters are required for vGPU resource PATCH")
 	}
 
-	endpoints, err := store.ListEndpoint(storage.ListOption{Filters: filters})
+	endpoints, err := store.ListEndpoint(storage.ListOption{Filters: filters, IncludeDeleted: true})
 	if err != nil {
 		return nil, endpointVGPULookupError("failed to look up endpoint for vGPU resource PATCH")
 	}
@@ -208,22 +321,17 @@ func resolveEndpointVGPUCluster(store storage.Storage, endpoint *v1.Endpoint) (*
 		workspace = endpoint.Metadata.Workspace
 	}
 
-	clusters, err := store.ListCluster(storage.ListOption{
-		Filters: endpointClusterLookupFilters(clusterName, workspace),
-	})
-	if err != nil {
+	cluster, err := store.GetClusterByName(workspace, clusterName)
+	if err != nil && !errors.Is(err, storage.ErrResourceNotFound) {
 		return nil, endpointVGPULookupError("failed to look up cluster for endpoint accelerator virtualization")
 	}
 
-	if len(clusters) == 0 {
+	// endpoints can not be deployed to archived clusters.
+	if err != nil || cluster.IsArchived() {
 		return nil, endpointVGPUTargetError(fmt.Sprintf("cluster %s/%s not found", workspace, clusterName))
 	}
 
-	if len(clusters) > 1 {
-		return nil, endpointVGPUTargetError(fmt.Sprintf("multiple clusters matched %s/%s", workspace, clusterName))
-	}
-
-	return &clusters[0], nil
+	return cluster, nil
 }
 
 func mergeEndpointPatch(existing *v1.Endpoint, patch *v1.Endpoint) v1.Endpoint {
@@ -379,13 +487,6 @@ func endpointPatchMayAffectVGPUValidation(endpoint *v1.Endpoint) bool {
 		endpoint.Spec.Replicas.Num != nil
 }
 
-func endpointClusterLookupFilters(cluster, workspace string) []storage.Filter {
-	return []storage.Filter{
-		{Column: "metadata->name", Operator: "eq", Value: strconv.Quote(cluster)},
-		{Column: "metadata->workspace", Operator: "eq", Value: strconv.Quote(workspace)},
-	}
-}
-
 func validateEndpointVGPUCluster(cluster *v1.Cluster) *validationError {
 	if cluster == nil || cluster.Spec == nil || !cluster.Spec.AcceleratorVirtualizationEnabled() {
 		return endpointVGPUNotReadyError(cluster, "cluster accelerator virtualization is not enabled")
@@ -632,6 +733,21 @@ func endpointVGPULookupError(hint string) *validationError {
 	return err
 }
 
+func endpointEngineArgsError(hint string) *validationError {
+	return &validationError{
+		Code:    "10227",
+		Message: "invalid endpoint engine_args",
+		Hint:    hint,
+	}
+}
+
+func endpointEngineArgsLookupError(hint string) *validationError {
+	err := endpointEngineArgsError(hint)
+	err.HTTPStatus = http.StatusServiceUnavailable
+
+	return err
+}
+
 func validationErrStatus(err *validationError) int {
 	if err != nil && err.HTTPStatus != 0 {
 		return err.HTTPStatus
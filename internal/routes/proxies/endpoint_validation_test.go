@@ -13,6 +13,7 @@ import (
 	"github.com/neutree-ai/neutree/pkg/storage"
 	storagemocks "github.com/neutree-ai/neutree/pkg/storage/mocks"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func TestValidateEndpointVGPUResourceShape(t *testing.T) {
@@ -1134,7 +1135,6 @@ type fakeClusterStorage struct {
 	endpointListCalls  int
 	listError          error
 	endpointListError  error
-	listOption         storage.ListOption
 	endpointListOption storage.ListOption
 }
 
@@ -1154,15 +1154,20 @@ func (s *fakeClusterStorage) GetCluster(id string) (*v1.Cluster, error) {
 	return nil, nil
 }
 
-func (s *fakeClusterStorage) ListCluster(option storage.ListOption) ([]v1.Cluster, error) {
+func (s *fakeClusterStorage) GetClusterByName(workspace, name string) (*v1.Cluster, error) {
 	s.listCalls++
-	s.listOption = option
 
 	if s.listError != nil {
 		return nil, s.listError
 	}
 
-	return s.clusters, nil
+	for i := range s.clusters {
+		if s.clusters[i].Metadata != nil && s.clusters[i].Metadata.Workspace == workspace && s.clusters[i].Metadata.Name == name {
+			return &s.clusters[i], nil
+		}
+	}
+
+	return nil, storage.ErrResourceNotFound
 }
 
 func (s *fakeClusterStorage) CreateEndpoint(data *v1.Endpoint) error {
@@ -1173,7 +1178,7 @@ func (s *fakeClusterStorage) DeleteEndpoint(id string) error {
 	return nil
 }
 
-func (s *fakeClusterStorage) UpdateEndpoint(id string, data *v1.Endpoint) error {
+func (s *fakeClusterStorage) UpdateEndpoint(id string, data *v1.Endpoint, opts ...storage.UpdateOption) error {
 	return nil
 }
 
@@ -1191,3 +1196,132 @@ func (s *fakeClusterStorage) ListEndpoint(option storage.ListOption) ([]v1.Endpo
 
 	return s.endpoints, nil
 }
+
+func TestEndpointEngineArgsValidation(t *testing.T) {
+	vllm := v1.Engine{
+		Metadata: &v1.Metadata{Name: "vllm", Workspace: "team-a"},
+		Spec: &v1.EngineSpec{Versions: []*v1.EngineVersion{{
+			Version: "v0.17.1",
+			ValuesSchema: map[string]interface{}{
+				"properties": map[string]interface{}{
+					"max_model_len": map[string]interface{}{"type": "integer"},
+				},
+				"additionalProperties": false,
+			},
+		}}},
+	}
+
+	stored := v1.Endpoint{
+		Metadata: &v1.Metadata{Name: "endpoint", Workspace: "team-a"},
+		Spec: &v1.EndpointSpec{
+			Engine:    &v1.EndpointEngineSpec{Engine: "vllm", Version: "v0.17.1"},
+			Variables: map[string]interface{}{"engine_args": map[string]interface{}{"max_model_len": float64(4096)}},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		method       string
+		path         string
+		body         string
+		setupMock    func(s *storagemocks.MockStorage)
+		expectStatus int
+		expectHint   string
+	}{
+		{
+			name:   "valid engine args on create",
+			method: http.MethodPost,
+			path:   "/endpoints",
+			body: `{"metadata": {"name": "endpoint", "workspace": "team-a"}, "spec": {"engine": {"engine": "vllm", "version": "v0.17.1"},
+				"variables": {"engine_args": {"max-model-len": 4096}}}}`,
+			setupMock: func(s *storagemocks.MockStorage) {
+				s.On("ListEngine", mock.Anything).Return([]v1.Engine{vllm}, nil)
+			},
+			expectStatus: http.StatusNoContent,
+		},
+		{
+			name:   "misspelled engine arg on create",
+			method: http.MethodPost,
+			path:   "/endpoints",
+			body: `{"metadata": {"name": "endpoint", "workspace": "team-a"}, "spec": {"engine": {"engine": "vllm", "version": "v0.17.1"},
+				"variables": {"engine_args": {"max_model_lenght": 4096}}}}`,
+			setupMock: func(s *storagemocks.MockStorage) {
+				s.On("ListEngine", mock.Anything).Return([]v1.Engine{vllm}, nil)
+			},
+			expectStatus: http.StatusBadRequest,
+			expectHint:   `did you mean "max_model_len"`,
+		},
+		{
+			name:   "engine not created yet",
+			method: http.MethodPost,
+			path:   "/endpoints",
+			body: `{"metadata": {"name": "endpoint", "workspace": "team-a"}, "spec": {"engine": {"engine": "vllm", "version": "v0.17.1"},
+				"variables": {"engine_args": {"max_model_lenght": 4096}}}}`,
+			setupMock: func(s *storagemocks.MockStorage) {
+				s.On("ListEngine", mock.Anything).Return([]v1.Engine{}, nil)
+			},
+			expectStatus: http.StatusNoContent,
+		},
+		{
+			name:         "no engine args",
+			method:       http.MethodPost,
+			path:         "/endpoints",
+			body:         `{"metadata": {"name": "endpoint"}, "spec": {"engine": {"engine": "vllm", "version": "v0.17.1"}}}`,
+			expectStatus: http.StatusNoContent,
+		},
+		{
+			name:   "patch validated against the engine of the stored endpoint",
+			method: http.MethodPatch,
+			path:   "/endpoints?metadata->>name=eq.endpoint&metadata->>workspace=eq.team-a",
+			body:   `{"spec": {"variables": {"engine_args": {"max_model_lenght": 4096}}}}`,
+			setupMock: func(s *storagemocks.MockStorage) {
+				s.On("ListEndpoint", mock.Anything).Return([]v1.Endpoint{stored}, nil)
+				s.On("ListEngine", mock.Anything).Return([]v1.Engine{vllm}, nil)
+			},
+			expectStatus: http.StatusBadRequest,
+			expectHint:   `did you mean "max_model_len"`,
+		},
+		{
+			name:   "engine lookup failure",
+			method: http.MethodPatch,
+			path:   "/endpoints?metadata->>name=eq.endpoint&metadata->>workspace=eq.team-a",
+			body:   `{"spec": {"engine": {"engine": "vllm", "version": "v0.17.1"}}}`,
+			setupMock: func(s *storagemocks.MockStorage) {
+				s.On("ListEndpoint", mock.Anything).Return([]v1.Endpoint{stored}, nil)
+				s.On("ListEngine", mock.Anything).Return(nil, errors.New("connection refused"))
+			},
+			expectStatus: http.StatusServiceUnavailable,
+			expectHint:   "failed to look up engine vllm to validate engine_args",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+
+			store := storagemocks.NewMockStorage(t)
+			if tt.setupMock != nil {
+				tt.setupMock(store)
+			}
+
+			router := gin.New()
+			router.Handle(tt.method, "/endpoints", validateEndpointEngineArgs(store), func(c *gin.Context) {
+				c.Status(http.StatusNoContent)
+			})
+
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			request.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(recorder, request)
+
+			assert.Equal(t, tt.expectStatus, recorder.Code)
+
+			if tt.expectHint != "" {
+				var response validationError
+				assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				assert.Equal(t, "10227", response.Code)
+				assert.Contains(t, response.Hint, tt.expectHint)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package proxies
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/neutree-ai/neutree/pkg/storage"
+)
+
+// validateExpectedVersion rejects a PATCH whose If-Match header holds a resource version the
+// stored resource no longer has, i.e. another writer changed its spec since the client read
+// it. The client has to read the resource again and reapply its change. The resource version
+// is only bumped by spec changes, so the status writes of the controllers do not conflict.
+// A matching PATCH only updates the resource at that version, and fails with 409 Conflict if
+// a write racing the check changed it, instead of reporting an update of nothing.
+func validateExpectedVersion(store storage.Storage, tableName string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ifMatch := parseIfMatch(c.GetHeader("If-Match"))
+		if c.Request.Method != http.MethodPatch || ifMatch == "" {
+			c.Next()
+			return
+		}
+
+		version, err := strconv.ParseInt(ifMatch, 10, 64)
+		if err != nil {
+			abortInvalidIfMatch(c, "If-Match requires the resource_version the resource was read at")
+			return
+		}
+
+		query := c.Request.URL.Query()
+
+		filters := queryParamsToFilters(query)
+		if len(filters) == 0 {
+			abortInvalidIfMatch(c, "If-Match requires filters selecting the resource to update")
+			return
+		}
+
+		var resources []struct {
+			ResourceVersion int64 `json:"resource_version"`
+		}
+
+		if err := store.GenericQuery(tableName, storage.ResourceVersionColumn, filters, &resources); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to look up resource version: " + err.Error()})
+			c.Abort()
+
+			return
+		}
+
+		if len(resources) > 1 {
+			abortInvalidIfMatch(c, "If-Match requires filters selecting a single resource")
+			return
+		}
+
+		if len(resources) == 1 && resources[0].ResourceVersion != version {
+			abortVersionConflict(c, fmt.Sprintf("the resource is at version %d since version %d, read it again and reapply the change",
+				resources[0].ResourceVersion, version))
+
+			return
+		}
+
+		query.Set(storage.ResourceVersionColumn, "eq."+ifMatch)
+		c.Request.URL.RawQuery = query.Encode()
+
+		// The updated rows are returned to tell an update of nothing apart, they are only
+		// passed on to the client if it asked for them.
+		prefer := c.GetHeader("Prefer")
+		wantsRepresentation := strings.Contains(prefer, "return=representation")
+
+		if !wantsRepresentation {
+			c.Request.Header.Set("Prefer", strings.TrimPrefix(prefer+",return=representation", ","))
+		}
+
+		writer := c.Writer
+		capture := &responseCapture{ResponseWriter: writer, body: &bytes.Buffer{}, statusCode: http.StatusOK}
+		c.Writer = capture
+
+		c.Next()
+
+		c.Writer = writer
+
+		if capture.statusCode < 200 || capture.statusCode >= 300 {
+			c.Data(capture.statusCode, writer.Header().Get("Content-Type"), capture.body.Bytes())
+			return
+		}
+
+		var rows []json.RawMessage
+		if err := json.Unmarshal(capture.body.Bytes(), &rows); err == nil && len(rows) == 0 {
+			writer.Header().Del("Content-Length")
+			abortVersionConflict(c, fmt.Sprintf("the resource is no longer at version %d, read it again and reapply the change", version))
+
+			return
+		}
+
+		writer.Header().Del("Content-Length")
+
+		if !wantsRepresentation {
+			c.Status(http.StatusNoContent)
+			return
+		}
+
+		c.Data(capture.statusCode, writer.Header().Get("Content-Type"), capture.body.Bytes())
+	}
+}
+
+func abortInvalidIfMatch(c *gin.Context, hint string) {
+	c.JSON(http.StatusBadRequest, &validationError{
+		Code:    "10225",
+		Message: "invalid If-Match precondition",
+		Hint:    hint,
+	})
+	c.Abort()
+}
+
+func abortVersionConflict(c *gin.Context, hint string) {
+	c.JSON(http.StatusConflict, &validationError{
+		Code:    "10226",
+		Message: storage.ErrConflict.Error(),
+		Hint:    hint,
+	})
+	c.Abort()
+}
+
+// parseIfMatch returns the resource version of an If-Match header, given as an entity tag
+// (quoted, possibly weak) or as the bare resource_version. Any version (*) is no precondition.
+func parseIfMatch(header string) string {
+	version := strings.TrimSpace(header)
+	if version == "*" {
+		return ""
+	}
+
+	version = strings.TrimPrefix(version, "W/")
+
+	return strings.Trim(version, `"`)
+}
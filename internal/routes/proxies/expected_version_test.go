@@ -0,0 +1,160 @@
+package proxies
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neutree-ai/neutree/pkg/storage"
+	storageMocks "github.com/neutree-ai/neutree/pkg/storage/mocks"
+)
+
+func TestValidateExpectedVersion(t *testing.T) {
+	tests := []struct {
+		name          string
+		query         string
+		ifMatch       string
+		prefer        string
+		stored        []int64
+		updated       string
+		expectStatus  int
+		expectCode    string
+		expectVersion string
+		expectBody    string
+	}{
+		{
+			name:         "no precondition",
+			query:        "id=eq.1",
+			updated:      `[]`,
+			expectStatus: http.StatusOK,
+			expectBody:   `[]`,
+		},
+		{
+			name:         "any version",
+			query:        "id=eq.1",
+			ifMatch:      "*",
+			updated:      `[]`,
+			expectStatus: http.StatusOK,
+			expectBody:   `[]`,
+		},
+		{
+			name:          "version matches",
+			query:         "id=eq.1",
+			ifMatch:       `"3"`,
+			stored:        []int64{3},
+			updated:       `[{"id":1,"resource_version":4}]`,
+			expectStatus:  http.StatusNoContent,
+			expectVersion: "eq.3",
+		},
+		{
+			name:          "version matches with the updated rows asked for",
+			query:         "id=eq.1",
+			ifMatch:       `W/"3"`,
+			prefer:        "return=representation",
+			stored:        []int64{3},
+			updated:       `[{"id":1,"resource_version":4}]`,
+			expectStatus:  http.StatusOK,
+			expectVersion: "eq.3",
+			expectBody:    `[{"id":1,"resource_version":4}]`,
+		},
+		{
+			name:          "version changed by a racing write",
+			query:         "id=eq.1",
+			ifMatch:       "3",
+			stored:        []int64{3},
+			updated:       `[]`,
+			expectStatus:  http.StatusConflict,
+			expectCode:    "10226",
+			expectVersion: "eq.3",
+		},
+		{
+			name:         "version mismatches",
+			query:        "id=eq.1",
+			ifMatch:      "2",
+			stored:       []int64{3},
+			expectStatus: http.StatusConflict,
+			expectCode:   "10226",
+		},
+		{
+			name:         "not a resource version",
+			query:        "id=eq.1",
+			ifMatch:      "2026-10-15T08:00:00+00:00",
+			expectStatus: http.StatusBadRequest,
+			expectCode:   "10225",
+		},
+		{
+			name:         "no filters",
+			ifMatch:      "3",
+			expectStatus: http.StatusBadRequest,
+			expectCode:   "10225",
+		},
+		{
+			name:         "several resources",
+			query:        "metadata->>workspace=eq.default",
+			ifMatch:      "3",
+			stored:       []int64{3, 3},
+			expectStatus: http.StatusBadRequest,
+			expectCode:   "10225",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStorage := storageMocks.NewMockStorage(t)
+			mockStorage.On("GenericQuery", storage.ENDPOINT_TABLE, "resource_version", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				rows := make([]map[string]interface{}, 0, len(tt.stored))
+				for _, version := range tt.stored {
+					rows = append(rows, map[string]interface{}{"resource_version": version})
+				}
+
+				raw, err := json.Marshal(rows)
+				require.NoError(t, err)
+				require.NoError(t, json.Unmarshal(raw, args.Get(3)))
+			}).Return(nil).Maybe()
+
+			var proxiedVersion, proxiedPrefer string
+
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.PATCH("/endpoints", validateExpectedVersion(mockStorage, storage.ENDPOINT_TABLE), func(c *gin.Context) {
+				proxiedVersion = c.Request.URL.Query().Get(storage.ResourceVersionColumn)
+				proxiedPrefer = c.GetHeader("Prefer")
+				c.Data(http.StatusOK, "application/json", []byte(tt.updated))
+			})
+
+			req := httptest.NewRequest(http.MethodPatch, "/endpoints?"+tt.query, strings.NewReader(`{"spec":{}}`))
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+
+			if tt.prefer != "" {
+				req.Header.Set("Prefer", tt.prefer)
+			}
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectStatus, w.Code)
+			assert.Equal(t, tt.expectVersion, proxiedVersion)
+
+			if tt.expectVersion != "" {
+				assert.Contains(t, proxiedPrefer, "return=representation")
+			}
+
+			if tt.expectCode != "" {
+				var resp validationError
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+				assert.Equal(t, tt.expectCode, resp.Code)
+			} else {
+				assert.Equal(t, tt.expectBody, w.Body.String())
+			}
+		})
+	}
+}
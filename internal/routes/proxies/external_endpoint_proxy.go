@@ -3,6 +3,7 @@ package proxies
 import (
 	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -35,7 +36,7 @@ func RegisterExternalEndpointRoutes(group *gin.RouterGroup, middlewares []gin.Ha
 	// Only register allowed methods
 	proxyGroup.GET("", handler)
 	proxyGroup.POST("", handler)
-	proxyGroup.PATCH("", handler)
+	proxyGroup.PATCH("", validateExpectedVersion(deps.Storage, storage.EXTERNAL_ENDPOINT_TABLE), handler)
 
 	// Test connectivity endpoint
 	proxyGroup.POST("/test_connectivity", handleTestConnectivity(deps))
@@ -195,37 +196,26 @@ func resolveModelsURL(deps *Dependencies, req *testConnectivityRequest) (string,
 
 	endpointName := *req.EndpointRef
 
-	endpoints, err := deps.Storage.ListEndpoint(storage.ListOption{
-		Filters: []storage.Filter{
-			{Column: "metadata->name", Operator: "eq", Value: strconv.Quote(endpointName)},
-			{Column: "metadata->workspace", Operator: "eq", Value: strconv.Quote(workspace)},
-		},
-	})
-	if err != nil {
+	ep, err := deps.Storage.GetEndpointByName(workspace, endpointName)
+	if err != nil && !errors.Is(err, storage.ErrResourceNotFound) {
 		return "", fmt.Errorf("failed to look up endpoint %s: %w", endpointName, err)
 	}
 
-	if len(endpoints) == 0 {
+	// endpoints being deleted no longer serve requests.
+	if err != nil || ep.GetDeletionTimestamp() != "" {
 		return "", fmt.Errorf("endpoint %s not found in workspace %s", endpointName, workspace)
 	}
 
-	ep := &endpoints[0]
-
-	clusters, err := deps.Storage.ListCluster(storage.ListOption{
-		Filters: []storage.Filter{
-			{Column: "metadata->name", Operator: "eq", Value: strconv.Quote(ep.Spec.Cluster)},
-			{Column: "metadata->workspace", Operator: "eq", Value: strconv.Quote(workspace)},
-		},
-	})
+	cluster, err := deps.Storage.GetClusterByName(workspace, ep.Spec.Cluster)
 	if err != nil {
-		return "", fmt.Errorf("failed to look up cluster %s: %w", ep.Spec.Cluster, err)
-	}
+		if errors.Is(err, storage.ErrResourceNotFound) {
+			return "", fmt.Errorf("cluster %s not found for endpoint %s", ep.Spec.Cluster, endpointName)
+		}
 
-	if len(clusters) == 0 {
-		return "", fmt.Errorf("cluster %s not found for endpoint %s", ep.Spec.Cluster, endpointName)
+		return "", fmt.Errorf("failed to look up cluster %s: %w", ep.Spec.Cluster, err)
 	}
 
-	scheme, host, port, err := util.GetClusterServeAddress(&clusters[0])
+	scheme, host, port, err := util.GetClusterServeAddress(cluster)
 	if err != nil {
 		return "", fmt.Errorf("failed to get cluster serve address: %w", err)
 	}
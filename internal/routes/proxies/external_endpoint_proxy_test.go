@@ -199,28 +199,20 @@ func TestHandleTestConnectivityEndpointRef(t *testing.T) {
 	mockStorage := new(storageMocks.MockStorage)
 	deps := &Dependencies{Storage: mockStorage}
 
-	// Mock ListEndpoint
-	mockStorage.On("ListEndpoint", mock.MatchedBy(func(opt storage.ListOption) bool {
-		return len(opt.Filters) == 2
-	})).Return([]v1.Endpoint{
-		{
-			Spec: &v1.EndpointSpec{
-				Cluster: "my-cluster",
-			},
+	// Mock GetEndpointByName
+	mockStorage.On("GetEndpointByName", "test-ws", "my-endpoint").Return(&v1.Endpoint{
+		Spec: &v1.EndpointSpec{
+			Cluster: "my-cluster",
 		},
 	}, nil)
 
-	// Mock ListCluster — return dashboard URL pointing at mock server
-	mockStorage.On("ListCluster", mock.MatchedBy(func(opt storage.ListOption) bool {
-		return len(opt.Filters) == 2
-	})).Return([]v1.Cluster{
-		{
-			Spec: &v1.ClusterSpec{
-				Type: v1.KubernetesClusterType,
-			},
-			Status: &v1.ClusterStatus{
-				DashboardURL: dashboardURL,
-			},
+	// Mock GetClusterByName — return dashboard URL pointing at mock server
+	mockStorage.On("GetClusterByName", "test-ws", "my-cluster").Return(&v1.Cluster{
+		Spec: &v1.ClusterSpec{
+			Type: v1.KubernetesClusterType,
+		},
+		Status: &v1.ClusterStatus{
+			DashboardURL: dashboardURL,
 		},
 	}, nil)
 
@@ -258,7 +250,7 @@ func TestHandleTestConnectivityEndpointRefNotFound(t *testing.T) {
 	mockStorage := new(storageMocks.MockStorage)
 	deps := &Dependencies{Storage: mockStorage}
 
-	mockStorage.On("ListEndpoint", mock.Anything).Return([]v1.Endpoint{}, nil)
+	mockStorage.On("GetEndpointByName", "default", "nonexistent").Return(nil, storage.ErrResourceNotFound)
 
 	epRef := "nonexistent"
 	ws := "default"
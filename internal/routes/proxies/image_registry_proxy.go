@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 
@@ -17,21 +18,29 @@ import (
 
 func validateImageRegistryDeletion(s storage.Storage) middleware.DeletionValidatorFunc {
 	return func(workspace, name string) error {
-		fmt.Println("validateImageRegistryDeletion", workspace, name)
-
-		count, err := s.Count(storage.CLUSTERS_TABLE, []storage.Filter{
-			{Column: "metadata->>workspace", Operator: "eq", Value: workspace},
-			{Column: "spec->>image_registry", Operator: "eq", Value: name},
+		clusters, err := s.ListCluster(storage.ListOption{
+			Filters: []storage.Filter{
+				{Column: "metadata->>workspace", Operator: "eq", Value: workspace},
+				{Column: "spec->>image_registry", Operator: "eq", Value: name},
+			},
+			IncludeDeleted:  true,
+			IncludeArchived: true,
 		})
 		if err != nil {
-			return fmt.Errorf("failed to count clusters: %w", err)
+			return fmt.Errorf("failed to list clusters: %w", err)
 		}
 
-		if count > 0 {
+		if len(clusters) > 0 {
+			names := make([]string, 0, len(clusters))
+			for _, cluster := range clusters {
+				names = append(names, cluster.Metadata.Name)
+			}
+
 			return &middleware.DeletionError{
 				Code:    "10127",
 				Message: fmt.Sprintf("cannot delete image_registry '%s/%s'", workspace, name),
-				Hint:    fmt.Sprintf("%d cluster(s) still reference this image registry", count),
+				Hint: fmt.Sprintf("%d cluster(s) still reference this image registry: %s, delete them first or force delete the image registry",
+					len(clusters), strings.Join(names, ", ")),
 			}
 		}
 
@@ -84,10 +93,11 @@ func RegisterImageRegistryRoutes(group *gin.RouterGroup, middlewares []gin.Handl
 	deletionValidation := middleware.DeletionValidation(
 		storage.IMAGE_REGISTRY_TABLE,
 		validateImageRegistryDeletion(deps.Storage),
+		middleware.AllowForceDeletion(),
 	)
 	handler := CreateStructProxyHandler[v1.ImageRegistry](deps, storage.IMAGE_REGISTRY_TABLE)
 
 	proxyGroup.GET("", handler)
 	proxyGroup.POST("", validateImageRegistryURL(), handler)
-	proxyGroup.PATCH("", deletionValidation, validateImageRegistryURL(), handler)
+	proxyGroup.PATCH("", validateExpectedVersion(deps.Storage, storage.IMAGE_REGISTRY_TABLE), deletionValidation, validateImageRegistryURL(), handler)
 }
@@ -13,6 +13,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 
+	v1 "github.com/neutree-ai/neutree/api/v1"
 	"github.com/neutree-ai/neutree/internal/middleware"
 	"github.com/neutree-ai/neutree/pkg/storage"
 	storageMocks "github.com/neutree-ai/neutree/pkg/storage/mocks"
@@ -168,7 +169,7 @@ func TestValidateImageRegistryDeletion(t *testing.T) {
 		name         string
 		workspace    string
 		registryName string
-		clusterCount int
+		clusters     []string
 		queryError   error
 		expectError  bool
 		expectedCode string
@@ -178,7 +179,7 @@ func TestValidateImageRegistryDeletion(t *testing.T) {
 			name:         "no dependencies - deletion allowed",
 			workspace:    "default",
 			registryName: "my-registry",
-			clusterCount: 0,
+			clusters:     nil,
 			queryError:   nil,
 			expectError:  false,
 		},
@@ -186,17 +187,17 @@ func TestValidateImageRegistryDeletion(t *testing.T) {
 			name:         "has dependencies - deletion blocked",
 			workspace:    "default",
 			registryName: "my-registry",
-			clusterCount: 3,
+			clusters:     []string{"ray-a", "ray-b", "k8s-c"},
 			queryError:   nil,
 			expectError:  true,
 			expectedCode: "10127",
-			expectedHint: "3 cluster(s) still reference this image registry",
+			expectedHint: "3 cluster(s) still reference this image registry: ray-a, ray-b, k8s-c",
 		},
 		{
 			name:         "query error",
 			workspace:    "default",
 			registryName: "my-registry",
-			clusterCount: 0,
+			clusters:     nil,
 			queryError:   errors.New("database error"),
 			expectError:  true,
 		},
@@ -206,13 +207,19 @@ func TestValidateImageRegistryDeletion(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			mockStorage := storageMocks.NewMockStorage(t)
 
-			mockStorage.On("Count",
-				storage.CLUSTERS_TABLE,
-				[]storage.Filter{
+			clusters := make([]v1.Cluster, 0, len(tt.clusters))
+			for _, name := range tt.clusters {
+				clusters = append(clusters, v1.Cluster{Metadata: &v1.Metadata{Name: name, Workspace: tt.workspace}})
+			}
+
+			mockStorage.On("ListCluster", storage.ListOption{
+				Filters: []storage.Filter{
 					{Column: "metadata->>workspace", Operator: "eq", Value: tt.workspace},
 					{Column: "spec->>image_registry", Operator: "eq", Value: tt.registryName},
 				},
-			).Return(tt.clusterCount, tt.queryError)
+				IncludeDeleted:  true,
+				IncludeArchived: true,
+			}).Return(clusters, tt.queryError)
 
 			validator := validateImageRegistryDeletion(mockStorage)
 			err := validator(tt.workspace, tt.registryName)
@@ -236,3 +243,65 @@ func TestValidateImageRegistryDeletion(t *testing.T) {
 		})
 	}
 }
+
+func TestRegisterImageRegistryRoutesDeletion(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		annotations    string
+		expectedStatus int
+		expectForward  bool
+	}{
+		{
+			name:           "referenced image registry deletion is blocked",
+			annotations:    `{}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "force deletion skips the dependents check",
+			annotations:    `{"neutree.ai/force-delete":"true"}`,
+			expectedStatus: http.StatusNoContent,
+			expectForward:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var upstreamCalled atomic.Bool
+			upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				upstreamCalled.Store(true)
+				w.WriteHeader(http.StatusNoContent)
+			}))
+			defer upstream.Close()
+
+			storageMock := storageMocks.NewMockStorage(t)
+			if !tt.expectForward {
+				storageMock.On("ListCluster", mock.Anything).Return([]v1.Cluster{
+					{Metadata: &v1.Metadata{Name: "ray-a", Workspace: "default"}},
+				}, nil)
+			}
+
+			router := gin.New()
+			RegisterImageRegistryRoutes(router.Group("/api/v1"), nil, &Dependencies{
+				Storage:          storageMock,
+				StorageAccessURL: upstream.URL,
+			})
+
+			body := strings.NewReader(`{"metadata":{"name":"my-registry","workspace":"default",` +
+				`"deletion_timestamp":"2025-12-29T06:09:38.917Z","annotations":` + tt.annotations + `}}`)
+			req := httptest.NewRequest(http.MethodPatch, "/api/v1/image_registries?id=eq.118", body)
+			req.Header.Set("Content-Type", "application/json")
+
+			recorder := newCloseNotifyRecorder()
+			router.ServeHTTP(recorder, req)
+
+			assert.Equal(t, tt.expectedStatus, recorder.ResponseRecorder.Code)
+			assert.Equal(t, tt.expectForward, upstreamCalled.Load())
+
+			if !tt.expectForward {
+				assert.Contains(t, recorder.ResponseRecorder.Body.String(), "ray-a")
+			}
+		})
+	}
+}
@@ -10,6 +10,7 @@ import (
 
 	v1 "github.com/neutree-ai/neutree/api/v1"
 	"github.com/neutree-ai/neutree/internal/recipe"
+	"github.com/neutree-ai/neutree/pkg/storage"
 )
 
 // RegisterModelCatalogRoutes registers model catalog routes
@@ -35,7 +36,7 @@ func RegisterModelCatalogRoutes(group *gin.RouterGroup, middlewares []gin.Handle
 	// Only register allowed methods
 	proxyGroup.GET("", handler)
 	proxyGroup.POST("", recipeValidation, handler)
-	proxyGroup.PATCH("", recipeValidation, handler)
+	proxyGroup.PATCH("", validateExpectedVersion(deps.Storage, storage.MODEL_CATALOG_TABLE), recipeValidation, handler)
 }
 
 // validateModelCatalogRecipe rejects structurally invalid recipe catalogs at
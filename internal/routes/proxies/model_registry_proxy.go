@@ -44,5 +44,5 @@ func RegisterModelRegistryRoutes(group *gin.RouterGroup, middlewares []gin.Handl
 
 	proxyGroup.GET("", handler)
 	proxyGroup.POST("", handler)
-	proxyGroup.PATCH("", deletionValidation, handler)
+	proxyGroup.PATCH("", validateExpectedVersion(deps.Storage, storage.MODEL_REGISTRY_TABLE), deletionValidation, handler)
 }
@@ -2,12 +2,12 @@ package proxies
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/httputil"
 	"net/url"
-	"strconv"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -22,9 +22,12 @@ import (
 type Dependencies struct {
 	Storage          storage.Storage
 	StorageAccessURL string
+	StorageSchema    string
 	AuthEndpoint     string
 	AuthConfig       middleware.AuthConfig
 	ImageService     registry.ImageService
+	// DefaultWorkspace is provisioned on the first endpoint created in it.
+	DefaultWorkspace string
 }
 
 func CreateProxyHandler(targetURL string, path string, modifyRequest func(*http.Request)) gin.HandlerFunc {
@@ -108,22 +111,9 @@ func handleServeProxy(deps *Dependencies) gin.HandlerFunc {
 			return
 		}
 
-		endpoints, err := deps.Storage.ListEndpoint(storage.ListOption{
-			Filters: []storage.Filter{
-				{
-					Column:   "metadata->name",
-					Operator: "eq",
-					Value:    strconv.Quote(name),
-				},
-				{
-					Column:   "metadata->workspace",
-					Operator: "eq",
-					Value:    strconv.Quote(workspace),
-				},
-			},
-		})
-		if err != nil {
-			errS := fmt.Sprintf("Failed to list endpoints: %v", err)
+		endpoint, err := deps.Storage.GetEndpointByName(workspace, name)
+		if err != nil && !errors.Is(err, storage.ErrResourceNotFound) {
+			errS := fmt.Sprintf("Failed to get endpoint: %v", err)
 			klog.Errorf(errS)
 
 			c.JSON(http.StatusBadRequest, gin.H{
@@ -133,7 +123,8 @@ func handleServeProxy(deps *Dependencies) gin.HandlerFunc {
 			return
 		}
 
-		if len(endpoints) == 0 {
+		// endpoints being deleted no longer serve requests.
+		if err != nil || endpoint.GetDeletionTimestamp() != "" {
 			c.JSON(http.StatusNotFound, gin.H{
 				"error": "endpoint not found",
 			})
@@ -142,40 +133,26 @@ func handleServeProxy(deps *Dependencies) gin.HandlerFunc {
 		}
 
 		// use internal serve access url
-		clusters, err := deps.Storage.ListCluster(storage.ListOption{
-			Filters: []storage.Filter{
-				{
-					Column:   "metadata->name",
-					Operator: "eq",
-					Value:    strconv.Quote(endpoints[0].Spec.Cluster),
-				},
-				{
-					Column:   "metadata->workspace",
-					Operator: "eq",
-					Value:    strconv.Quote(endpoints[0].Metadata.Workspace),
-				},
-			},
-		})
-
+		cluster, err := deps.Storage.GetClusterByName(endpoint.Metadata.Workspace, endpoint.Spec.Cluster)
 		if err != nil {
-			errS := fmt.Sprintf("Failed to list clusters: %v", err)
-			klog.Errorf(errS)
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": errS,
-			})
+			if errors.Is(err, storage.ErrResourceNotFound) {
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error": "endpoint relate cluster not found",
+				})
 
-			return
-		}
+				return
+			}
 
-		if len(clusters) == 0 {
+			errS := fmt.Sprintf("Failed to get cluster: %v", err)
+			klog.Errorf(errS)
 			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "endpoint relate cluster not found",
+				"error": errS,
 			})
 
 			return
 		}
 
-		if clusters[0].Status.DashboardURL == "" {
+		if cluster.Status.DashboardURL == "" {
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error": "cluster dashboard_url not found",
 			})
@@ -183,7 +160,7 @@ func handleServeProxy(deps *Dependencies) gin.HandlerFunc {
 			return
 		}
 
-		scheme, host, port, err := util.GetClusterServeAddress(&clusters[0])
+		scheme, host, port, err := util.GetClusterServeAddress(cluster)
 		if err != nil {
 			errS := fmt.Sprintf("Failed to get cluster serve address: %v", err)
 			klog.Errorf(errS)
@@ -194,7 +171,7 @@ func handleServeProxy(deps *Dependencies) gin.HandlerFunc {
 			return
 		}
 
-		serviceURL := fmt.Sprintf("%s://%s:%d/%s/%s", scheme, host, port, workspace, endpoints[0].Metadata.Name)
+		serviceURL := fmt.Sprintf("%s://%s:%d/%s/%s", scheme, host, port, workspace, endpoint.Metadata.Name)
 
 		path := c.Param("path")
 		if path != "" && path[0] == '/' {
@@ -252,23 +229,9 @@ func handleRayDashboardProxy(deps *Dependencies) gin.HandlerFunc {
 			return
 		}
 
-		clusters, err := deps.Storage.ListCluster(storage.ListOption{
-			Filters: []storage.Filter{
-				{
-					Column:   "metadata->name",
-					Operator: "eq",
-					Value:    strconv.Quote(name),
-				},
-				{
-					Column:   "metadata->workspace",
-					Operator: "eq",
-					Value:    strconv.Quote(workspace),
-				},
-			},
-		})
-
-		if err != nil {
-			errS := fmt.Sprintf("Failed to list clusters: %v", err)
+		cluster, err := deps.Storage.GetClusterByName(workspace, name)
+		if err != nil && !errors.Is(err, storage.ErrResourceNotFound) {
+			errS := fmt.Sprintf("Failed to get cluster: %v", err)
 			klog.Errorf(errS)
 
 			c.JSON(http.StatusBadRequest, gin.H{
@@ -278,7 +241,8 @@ func handleRayDashboardProxy(deps *Dependencies) gin.HandlerFunc {
 			return
 		}
 
-		if len(clusters) == 0 {
+		// clusters being deleted or archived are not proxied to.
+		if err != nil || cluster.GetDeletionTimestamp() != "" || cluster.IsArchived() {
 			c.JSON(http.StatusNotFound, gin.H{
 				"error": "cluster not found",
 			})
@@ -286,7 +250,7 @@ func handleRayDashboardProxy(deps *Dependencies) gin.HandlerFunc {
 			return
 		}
 
-		dashboardURL := clusters[0].Status.DashboardURL
+		dashboardURL := cluster.Status.DashboardURL
 		if dashboardURL == "" {
 			c.JSON(http.StatusBadRequest, gin.H{
 				"error": "dashboard_url not found",
@@ -305,11 +269,18 @@ func handleRayDashboardProxy(deps *Dependencies) gin.HandlerFunc {
 	}
 }
 
-func CreatePostgrestAuthModifier(c *gin.Context) func(*http.Request) {
+// CreatePostgrestAuthModifier authorizes the proxied PostgREST request with the PostgREST token
+// of the caller and sends it to schema, PostgREST uses its default schema when schema is empty.
+func CreatePostgrestAuthModifier(c *gin.Context, schema string) func(*http.Request) {
 	return func(req *http.Request) {
 		if postgrestToken, exists := middleware.GetPostgrestToken(c); exists && postgrestToken != "" {
 			req.Header.Set("Authorization", "Bearer "+postgrestToken)
 		}
+
+		if schema != "" {
+			req.Header.Set("Accept-Profile", schema)
+			req.Header.Set("Content-Profile", schema)
+		}
 	}
 }
 
@@ -322,7 +293,7 @@ func handlePostgrestRPCProxy(deps *Dependencies) gin.HandlerFunc {
 
 		path = "rpc/" + path
 
-		proxyHandler := CreateProxyHandler(deps.StorageAccessURL, path, CreatePostgrestAuthModifier(c))
+		proxyHandler := CreateProxyHandler(deps.StorageAccessURL, path, CreatePostgrestAuthModifier(c, deps.StorageSchema))
 		proxyHandler(c)
 	}
 }
@@ -358,23 +329,9 @@ func handleKubernetesProxy(deps *Dependencies) gin.HandlerFunc {
 		}
 
 		// Query Kubernetes cluster
-		clusters, err := deps.Storage.ListCluster(storage.ListOption{
-			Filters: []storage.Filter{
-				{
-					Column:   "metadata->name",
-					Operator: "eq",
-					Value:    strconv.Quote(name),
-				},
-				{
-					Column:   "metadata->workspace",
-					Operator: "eq",
-					Value:    strconv.Quote(workspace),
-				},
-			},
-		})
-
-		if err != nil {
-			errS := fmt.Sprintf("Failed to list clusters: %v", err)
+		cluster, err := deps.Storage.GetClusterByName(workspace, name)
+		if err != nil && !errors.Is(err, storage.ErrResourceNotFound) {
+			errS := fmt.Sprintf("Failed to get cluster: %v", err)
 			klog.Errorf(errS)
 
 			c.JSON(http.StatusBadRequest, gin.H{
@@ -384,7 +341,8 @@ func handleKubernetesProxy(deps *Dependencies) gin.HandlerFunc {
 			return
 		}
 
-		if len(clusters) == 0 {
+		// clusters being deleted or archived are not proxied to.
+		if err != nil || cluster.GetDeletionTimestamp() != "" || cluster.IsArchived() {
 			c.JSON(http.StatusNotFound, gin.H{
 				"error": "cluster not found",
 			})
@@ -392,9 +350,7 @@ func handleKubernetesProxy(deps *Dependencies) gin.HandlerFunc {
 			return
 		}
 
-		cluster := clusters[0]
-
-		kubeconfig, err := util.GetKubeConfigFromCluster(&cluster)
+		kubeconfig, err := util.GetKubeConfigFromCluster(cluster)
 		if err != nil {
 			errS := fmt.Sprintf("Failed to get kubeconfig: %v", err)
 			klog.Errorf(errS)
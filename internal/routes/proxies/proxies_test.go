@@ -13,6 +13,7 @@ import (
 	"github.com/stretchr/testify/mock"
 
 	v1 "github.com/neutree-ai/neutree/api/v1"
+	"github.com/neutree-ai/neutree/pkg/storage"
 	"github.com/neutree-ai/neutree/pkg/storage/mocks"
 )
 
@@ -131,7 +132,7 @@ func TestHandleServeProxy_EndpointNotFound(t *testing.T) {
 	}
 
 	// Configure mock behaviors - return empty result
-	mockStorage.On("ListEndpoint", mock.Anything).Return([]v1.Endpoint{}, nil)
+	mockStorage.On("GetEndpointByName", mock.Anything, mock.Anything).Return(nil, storage.ErrResourceNotFound)
 
 	// Create test context
 	c, w := createMockContext("GET", "/api/v1/serve-proxy/default/non-existent-endpoint/", "")
@@ -163,7 +164,7 @@ func TestHandleServeProxy_StorageError(t *testing.T) {
 
 	// Configure mock behaviors - return error
 	mockError := errors.New("storage error")
-	mockStorage.On("ListEndpoint", mock.Anything).Return([]v1.Endpoint{}, mockError)
+	mockStorage.On("GetEndpointByName", mock.Anything, mock.Anything).Return(nil, mockError)
 
 	// Create test context
 	c, w := createMockContext("GET", "/api/v1/serve-proxy/default/test-endpoint", "")
@@ -178,7 +179,7 @@ func TestHandleServeProxy_StorageError(t *testing.T) {
 	var response map[string]string
 	err := json.Unmarshal(w.Body.Bytes(), &response)
 	assert.NoError(t, err)
-	assert.Contains(t, response["error"], "Failed to list endpoints")
+	assert.Contains(t, response["error"], "Failed to get endpoint")
 
 	mockStorage.AssertExpectations(t)
 }
@@ -207,10 +208,10 @@ func TestHandleServeProxy_MissingClusterDashboardURL(t *testing.T) {
 	}
 
 	// Configure mock behaviors
-	mockStorage.On("ListEndpoint", mock.Anything).Return([]v1.Endpoint{endpoint}, nil)
-	mockStorage.On("ListCluster", mock.Anything).Return([]v1.Cluster{{
+	mockStorage.On("GetEndpointByName", mock.Anything, mock.Anything).Return(&endpoint, nil)
+	mockStorage.On("GetClusterByName", mock.Anything, mock.Anything).Return(&v1.Cluster{
 		Status: &v1.ClusterStatus{},
-	}}, nil)
+	}, nil)
 
 	// Create test context
 	c, w := createMockContext("GET", "/api/v1/serve-proxy/default/test-endpoint", "")
@@ -267,7 +268,7 @@ func TestHandleRayDashboardProxy_ClusterNotFound(t *testing.T) {
 	}
 
 	// Configure mock behaviors - return empty result
-	mockStorage.On("ListCluster", mock.Anything).Return([]v1.Cluster{}, nil)
+	mockStorage.On("GetClusterByName", mock.Anything, mock.Anything).Return(nil, storage.ErrResourceNotFound)
 
 	// Create test context
 	c, w := createMockContext("GET", "/api/v1/ray-dashboard-proxy/default/non-existent-cluster", "")
@@ -299,7 +300,7 @@ func TestHandleRayDashboardProxy_StorageError(t *testing.T) {
 
 	// Configure mock behaviors - return error
 	mockError := errors.New("storage error")
-	mockStorage.On("ListCluster", mock.Anything).Return([]v1.Cluster{}, mockError)
+	mockStorage.On("GetClusterByName", mock.Anything, mock.Anything).Return(nil, mockError)
 
 	// Create test context
 	c, w := createMockContext("GET", "/api/v1/ray-dashboard-proxy/default/test-cluster", "")
@@ -314,7 +315,7 @@ func TestHandleRayDashboardProxy_StorageError(t *testing.T) {
 	var response map[string]string
 	err := json.Unmarshal(w.Body.Bytes(), &response)
 	assert.NoError(t, err)
-	assert.Contains(t, response["error"], "Failed to list clusters")
+	assert.Contains(t, response["error"], "Failed to get cluster")
 
 	mockStorage.AssertExpectations(t)
 }
@@ -337,7 +338,7 @@ func TestHandleRayDashboardProxy_MissingDashboardURL(t *testing.T) {
 	}
 
 	// Configure mock behaviors
-	mockStorage.On("ListCluster", mock.Anything).Return([]v1.Cluster{cluster}, nil)
+	mockStorage.On("GetClusterByName", mock.Anything, mock.Anything).Return(&cluster, nil)
 
 	// Create test context
 	c, w := createMockContext("GET", "/api/v1/ray-dashboard-proxy/default/test-cluster", "")
@@ -371,7 +372,7 @@ func TestCreatePostgrestAuthModifier(t *testing.T) {
 		req.Header.Set("Authorization", "sk_original_api_key")
 
 		// Apply the modifier
-		modifier := CreatePostgrestAuthModifier(c)
+		modifier := CreatePostgrestAuthModifier(c, "")
 		modifier(req)
 
 		// Verify the Authorization header was replaced
@@ -388,7 +389,7 @@ func TestCreatePostgrestAuthModifier(t *testing.T) {
 		req.Header.Set("Authorization", originalAuth)
 
 		// Apply the modifier
-		modifier := CreatePostgrestAuthModifier(c)
+		modifier := CreatePostgrestAuthModifier(c, "")
 		modifier(req)
 
 		// Verify the Authorization header was not modified
@@ -406,13 +407,40 @@ func TestCreatePostgrestAuthModifier(t *testing.T) {
 		req.Header.Set("Authorization", originalAuth)
 
 		// Apply the modifier
-		modifier := CreatePostgrestAuthModifier(c)
+		modifier := CreatePostgrestAuthModifier(c, "")
 		modifier(req)
 
 		// Empty postgrest_token means GetPostgrestToken returns false,
 		// so Authorization should not be modified
 		assert.Equal(t, originalAuth, req.Header.Get("Authorization"))
 	})
+
+	t.Run("With storage schema", func(t *testing.T) {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Set("postgrest_token", "test-postgrest-token-123")
+
+		req := httptest.NewRequest("PATCH", "/test", nil)
+		req.Header.Set("Accept-Profile", "api")
+
+		modifier := CreatePostgrestAuthModifier(c, "tenant_a")
+		modifier(req)
+
+		assert.Equal(t, "Bearer test-postgrest-token-123", req.Header.Get("Authorization"))
+		assert.Equal(t, "tenant_a", req.Header.Get("Accept-Profile"))
+		assert.Equal(t, "tenant_a", req.Header.Get("Content-Profile"))
+	})
+
+	t.Run("Without storage schema", func(t *testing.T) {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+
+		req := httptest.NewRequest("GET", "/test", nil)
+
+		modifier := CreatePostgrestAuthModifier(c, "")
+		modifier(req)
+
+		assert.Empty(t, req.Header.Get("Accept-Profile"))
+		assert.Empty(t, req.Header.Get("Content-Profile"))
+	})
 }
 
 func TestCreateProxyHandlerWithTransport(t *testing.T) {
@@ -485,7 +513,7 @@ func TestHandleKubernetesProxy_ClusterNotFound(t *testing.T) {
 		Storage: mockStorage,
 	}
 
-	mockStorage.On("ListCluster", mock.Anything).Return([]v1.Cluster{}, nil)
+	mockStorage.On("GetClusterByName", mock.Anything, mock.Anything).Return(nil, storage.ErrResourceNotFound)
 
 	c, w := createMockContext("GET", "/api/v1/k8s-proxy/default/non-existent-cluster", "")
 	handlerFunc := handleKubernetesProxy(deps)
@@ -508,7 +536,7 @@ func TestHandleKubernetesProxy_StorageError(t *testing.T) {
 	}
 
 	mockError := errors.New("storage error")
-	mockStorage.On("ListCluster", mock.Anything).Return([]v1.Cluster{}, mockError)
+	mockStorage.On("GetClusterByName", mock.Anything, mock.Anything).Return(nil, mockError)
 
 	c, w := createMockContext("GET", "/api/v1/k8s-proxy/default/test-cluster", "")
 	handlerFunc := handleKubernetesProxy(deps)
@@ -519,7 +547,7 @@ func TestHandleKubernetesProxy_StorageError(t *testing.T) {
 	var response map[string]string
 	err := json.Unmarshal(w.Body.Bytes(), &response)
 	assert.NoError(t, err)
-	assert.Contains(t, response["error"], "Failed to list clusters")
+	assert.Contains(t, response["error"], "Failed to get cluster")
 
 	mockStorage.AssertExpectations(t)
 }
@@ -542,7 +570,7 @@ func TestHandleKubernetesProxy_InvalidKubeconfig(t *testing.T) {
 		Storage: mockStorage,
 	}
 
-	mockStorage.On("ListCluster", mock.Anything).Return([]v1.Cluster{cluster}, nil)
+	mockStorage.On("GetClusterByName", mock.Anything, mock.Anything).Return(&cluster, nil)
 
 	c, w := createMockContext("GET", "/api/v1/k8s-proxy/default/test-cluster/api/v1/namespaces", "")
 	handlerFunc := handleKubernetesProxy(deps)
@@ -154,6 +154,9 @@ func (w *responseCapture) WriteString(s string) (int, error) {
 	return w.body.WriteString(s)
 }
 
+// Flush keeps the captured response from being sent, e.g. by the reverse proxy.
+func (w *responseCapture) Flush() {}
+
 // structTagConfig holds everything the resource proxy derives from a resource
 // struct's tags in a single reflection pass.
 type structTagConfig struct {
@@ -645,7 +648,7 @@ func CreateStructProxyHandler[T any](deps *Dependencies, tableName string) gin.H
 		}
 
 		// Create proxy handler
-		proxyHandler := CreateProxyHandler(deps.StorageAccessURL, tableName, CreatePostgrestAuthModifier(c))
+		proxyHandler := CreateProxyHandler(deps.StorageAccessURL, tableName, CreatePostgrestAuthModifier(c, deps.StorageSchema))
 
 		// If no field filtering is needed, use proxy directly
 		if len(excludeFields) == 0 {
@@ -722,7 +725,7 @@ func handlePatchWithBackfill(
 		c.Request.ContentLength = int64(len(bodyBytes))
 		c.Request.Header.Set("Content-Length", fmt.Sprintf("%d", len(bodyBytes)))
 
-		proxyHandler := CreateProxyHandler(deps.StorageAccessURL, tableName, CreatePostgrestAuthModifier(c))
+		proxyHandler := CreateProxyHandler(deps.StorageAccessURL, tableName, CreatePostgrestAuthModifier(c, deps.StorageSchema))
 		proxyHandler(c)
 
 		return
@@ -760,6 +763,6 @@ func handlePatchWithBackfill(
 	c.Request.Header.Set("Content-Length", fmt.Sprintf("%d", len(modifiedBodyBytes)))
 
 	// Forward to PostgREST
-	proxyHandler := CreateProxyHandler(deps.StorageAccessURL, tableName, CreatePostgrestAuthModifier(c))
+	proxyHandler := CreateProxyHandler(deps.StorageAccessURL, tableName, CreatePostgrestAuthModifier(c, deps.StorageSchema))
 	proxyHandler(c)
 }
@@ -64,5 +64,5 @@ func RegisterRoleRoutes(group *gin.RouterGroup, middlewares []gin.HandlerFunc, d
 
 	proxyGroup.GET("", handler)
 	proxyGroup.POST("", handler)
-	proxyGroup.PATCH("", deletionValidation, handler)
+	proxyGroup.PATCH("", validateExpectedVersion(deps.Storage, storage.ROLE_TABLE), deletionValidation, handler)
 }
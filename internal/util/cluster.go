@@ -4,14 +4,17 @@ import (
 	"encoding/base64"
 	"net/http"
 	"net/url"
+	"path"
 	"strconv"
 
 	"github.com/pkg/errors"
 	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
 	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
@@ -26,8 +29,10 @@ var (
 	_      = rayv1.AddToScheme(scheme)
 	_      = admissionregistrationv1.AddToScheme(scheme)
 	_      = appsv1.AddToScheme(scheme)
+	_      = batchv1.AddToScheme(scheme)
 	_      = corev1.AddToScheme(scheme)
 	_      = rbacv1.AddToScheme(scheme)
+	_      = schedulingv1.AddToScheme(scheme)
 )
 
 func GetClusterModelCache(c v1.Cluster) ([]v1.ModelCache, error) {
@@ -43,6 +48,47 @@ func GetClusterModelCache(c v1.Cluster) ([]v1.ModelCache, error) {
 	return c.Spec.Config.ModelCaches, nil
 }
 
+// FindModelCache returns the model cache of the cluster with the given name, the first model
+// cache of the cluster if name is empty. It returns nil if the cluster has no such model cache.
+func FindModelCache(c *v1.Cluster, name string) *v1.ModelCache {
+	modelCaches, _ := GetClusterModelCache(*c) // nolint: errcheck
+	if len(modelCaches) == 0 {
+		return nil
+	}
+
+	if name == "" {
+		return &modelCaches[0]
+	}
+
+	for i := range modelCaches {
+		if modelCaches[i].Name == name {
+			return &modelCaches[i]
+		}
+	}
+
+	return nil
+}
+
+// ModelCacheRelativePath returns the directory, relative to the model cache mount path, models
+// of the registry type are cached in. The model cache is selected by FindModelCache, the
+// default directory is used if the cluster has none.
+func ModelCacheRelativePath(c *v1.Cluster, cacheName string, registryType v1.ModelRegistryType) string {
+	modelCache := FindModelCache(c, cacheName)
+	if modelCache == nil {
+		return v1.DefaultModelCacheRelativePath
+	}
+
+	return path.Join(modelCache.Name, modelCache.RegistryPaths[registryType])
+}
+
+// ModelCacheModelPath returns the path a model version of the registry type is cached at in
+// the engine containers of the cluster, <mount path>/<cache>/<registry path>/<model>/<version>.
+// Both orchestrators resolve model paths with it, only the mount path of the model caches
+// differs between them.
+func ModelCacheModelPath(mountPath string, c *v1.Cluster, cacheName string, registryType v1.ModelRegistryType, model, version string) string {
+	return path.Join(mountPath, ModelCacheRelativePath(c, cacheName, registryType), model, version)
+}
+
 func ParseSSHClusterConfig(cluster *v1.Cluster) (*v1.RaySSHProvisionClusterConfig, error) {
 	if cluster.Spec.Config == nil || cluster.Spec.Config.SSHConfig == nil {
 		return nil, errors.New("ssh cluster config is empty")
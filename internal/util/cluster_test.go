@@ -4,8 +4,11 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	rbacv1 "k8s.io/api/rbac/v1"
+
+	v1 "github.com/neutree-ai/neutree/api/v1"
 )
 
 func makeKubeConfig(clusterConfig, userConfig string) string {
@@ -131,3 +134,61 @@ func TestKubernetesClientSchemeRegistersRBAC(t *testing.T) {
 	require.NotEmpty(t, kinds)
 	require.Equal(t, "Role", kinds[0].Kind)
 }
+
+func TestModelCacheModelPath(t *testing.T) {
+	registryPaths := map[v1.ModelRegistryType]string{v1.HuggingFaceModelRegistryType: "hf"}
+
+	tests := []struct {
+		name         string
+		modelCaches  []v1.ModelCache
+		cacheName    string
+		registryType v1.ModelRegistryType
+		expected     string
+	}{
+		{
+			name:         "no model cache",
+			registryType: v1.HuggingFaceModelRegistryType,
+			expected:     "/models-cache/default/qwen/Qwen3-8B/main",
+		},
+		{
+			name:         "first model cache",
+			modelCaches:  []v1.ModelCache{{Name: "first"}, {Name: "second", RegistryPaths: registryPaths}},
+			registryType: v1.HuggingFaceModelRegistryType,
+			expected:     "/models-cache/first/qwen/Qwen3-8B/main",
+		},
+		{
+			name:         "registry path of the registry type",
+			modelCaches:  []v1.ModelCache{{Name: "shared", RegistryPaths: registryPaths}},
+			registryType: v1.HuggingFaceModelRegistryType,
+			expected:     "/models-cache/shared/hf/qwen/Qwen3-8B/main",
+		},
+		{
+			name:         "model cache selected by name",
+			modelCaches:  []v1.ModelCache{{Name: "team-a"}, {Name: "team-b", RegistryPaths: registryPaths}},
+			cacheName:    "team-b",
+			registryType: v1.HuggingFaceModelRegistryType,
+			expected:     "/models-cache/team-b/hf/qwen/Qwen3-8B/main",
+		},
+		{
+			name:         "unknown model cache name",
+			modelCaches:  []v1.ModelCache{{Name: "team-a"}},
+			cacheName:    "team-b",
+			registryType: v1.HuggingFaceModelRegistryType,
+			expected:     "/models-cache/default/qwen/Qwen3-8B/main",
+		},
+		{
+			name:         "registry type without registry path",
+			modelCaches:  []v1.ModelCache{{Name: "shared", RegistryPaths: registryPaths}},
+			registryType: v1.BentoMLModelRegistryType,
+			expected:     "/models-cache/shared/qwen/Qwen3-8B/main",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster := &v1.Cluster{Spec: &v1.ClusterSpec{Config: &v1.ClusterConfig{ModelCaches: tt.modelCaches}}}
+
+			assert.Equal(t, tt.expected, ModelCacheModelPath(v1.DefaultK8sClusterModelCacheMountPath, cluster, tt.cacheName, tt.registryType, "qwen/Qwen3-8B", "main"))
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package util
+
+import (
+	"fmt"
+	"strings"
+
+	v1 "github.com/neutree-ai/neutree/api/v1"
+)
+
+// EndpointServedModelKey returns the path-safe key of a model served by a multi-model endpoint.
+// It is derived from the model name, e.g. "Qwen/Qwen3-8B" becomes "qwen-qwen3-8b". Versions of
+// a model the endpoint serves side by side are told apart by their version, e.g. "qwen-qwen3-8b-v2".
+func EndpointServedModelKey(endpoint *v1.Endpoint, model *v1.ModelSpec) string {
+	if model == nil {
+		return ""
+	}
+
+	key := pathSafeKey(model.Name)
+
+	if version := pathSafeKey(model.Version); version != "" && endpointServesModelVersions(endpoint, model) {
+		key += "-" + version
+	}
+
+	return key
+}
+
+// endpointServesModelVersions reports whether the endpoint serves another model with the name of model.
+func endpointServesModelVersions(endpoint *v1.Endpoint, model *v1.ModelSpec) bool {
+	if endpoint == nil {
+		return false
+	}
+
+	for _, other := range endpoint.Spec.ServedModels() {
+		if other != model && other != nil && other.Name == model.Name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func pathSafeKey(s string) string {
+	key := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r - 'A' + 'a'
+		default:
+			return '-'
+		}
+	}, s)
+
+	return strings.Trim(key, "-")
+}
+
+// EndpointServedModelRoutePrefix returns the serve route prefix of one model of a multi-model endpoint.
+func EndpointServedModelRoutePrefix(endpoint *v1.Endpoint, model *v1.ModelSpec) string {
+	return fmt.Sprintf("/%s/%s/%s", endpoint.Metadata.Workspace, endpoint.Metadata.Name, EndpointServedModelKey(endpoint, model))
+}
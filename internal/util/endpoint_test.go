@@ -0,0 +1,46 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	v1 "github.com/neutree-ai/neutree/api/v1"
+)
+
+func TestEndpointServedModelKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		model    *v1.ModelSpec
+		expected string
+	}{
+		{name: "nil model", model: nil, expected: ""},
+		{name: "simple name", model: &v1.ModelSpec{Name: "llama3"}, expected: "llama3"},
+		{name: "huggingface repo", model: &v1.ModelSpec{Name: "Qwen/Qwen3-8B"}, expected: "qwen-qwen3-8b"},
+		{name: "trim separators", model: &v1.ModelSpec{Name: "_model.v1_"}, expected: "model-v1"},
+		{name: "version of a model served alone", model: &v1.ModelSpec{Name: "llama3", Version: "v2"}, expected: "llama3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, EndpointServedModelKey(nil, tt.model))
+		})
+	}
+}
+
+func TestEndpointServedModelKey_Versions(t *testing.T) {
+	primary := &v1.ModelSpec{Name: "Qwen/Qwen3-8B", Version: "v1"}
+	canary := &v1.ModelSpec{Name: "Qwen/Qwen3-8B", Version: "V2.0"}
+	other := &v1.ModelSpec{Name: "llama3", Version: "v1"}
+	endpoint := &v1.Endpoint{Spec: &v1.EndpointSpec{Model: primary, Models: []*v1.ModelSpec{canary, other}}}
+
+	assert.Equal(t, "qwen-qwen3-8b-v1", EndpointServedModelKey(endpoint, primary))
+	assert.Equal(t, "qwen-qwen3-8b-v2-0", EndpointServedModelKey(endpoint, canary))
+	assert.Equal(t, "llama3", EndpointServedModelKey(endpoint, other))
+}
+
+func TestEndpointServedModelRoutePrefix(t *testing.T) {
+	endpoint := &v1.Endpoint{Metadata: &v1.Metadata{Workspace: "default", Name: "chat"}}
+
+	assert.Equal(t, "/default/chat/qwen-qwen3-8b", EndpointServedModelRoutePrefix(endpoint, &v1.ModelSpec{Name: "Qwen/Qwen3-8B"}))
+}
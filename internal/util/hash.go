@@ -18,7 +18,18 @@ func ComputeEndpointModelHash(endpoint *v1.Endpoint) (string, error) {
 		return "", nil
 	}
 
-	modelJSON, err := json.Marshal(endpoint.Spec.Model)
+	var models interface{} = withoutRoutingWeight(endpoint.Spec.Model)
+
+	if endpoint.Spec.IsMultiModel() {
+		servedModels := endpoint.Spec.ServedModels()
+		for i, model := range servedModels {
+			servedModels[i] = withoutRoutingWeight(model)
+		}
+
+		models = servedModels
+	}
+
+	modelJSON, err := json.Marshal(models)
 	if err != nil {
 		return "", err
 	}
@@ -27,3 +38,16 @@ func ComputeEndpointModelHash(endpoint *v1.Endpoint) (string, error) {
 
 	return fmt.Sprintf("%x", hash), nil
 }
+
+// withoutRoutingWeight returns a copy of the model without its routing weight, which only
+// changes the routing of the endpoint and not the models it downloads.
+func withoutRoutingWeight(model *v1.ModelSpec) *v1.ModelSpec {
+	if model == nil || model.Weight == nil {
+		return model
+	}
+
+	m := *model
+	m.Weight = nil
+
+	return &m
+}
@@ -44,6 +44,25 @@ func BuildEngineImageRef(imagePrefix string, engineImage *v1.EngineImage) string
 	return RewriteImageRef(imagePrefix, imageName+":"+tag)
 }
 
+// PinImageDigest pins the image reference to the given manifest digest. The tag is kept for
+// readability, container runtimes pull the image by digest.
+//
+// Examples:
+//
+//	PinImageDigest("registry.io/neutree/vllm:v0.11.2", "sha256:abc") → "registry.io/neutree/vllm:v0.11.2@sha256:abc"
+//	PinImageDigest("registry.io/neutree/vllm:v0.11.2", "")           → "registry.io/neutree/vllm:v0.11.2"
+func PinImageDigest(image, digest string) string {
+	if image == "" || digest == "" {
+		return image
+	}
+
+	if i := strings.Index(image, "@"); i >= 0 {
+		image = image[:i]
+	}
+
+	return image + "@" + digest
+}
+
 // RewriteImageRef rewrites image into imagePrefix while preserving the image
 // repository path and removing any source registry host. Docker Hub prefixes
 // leave image references unchanged.
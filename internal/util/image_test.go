@@ -101,6 +101,39 @@ func TestBuildEngineImageRef(t *testing.T) {
 	}
 }
 
+func TestPinImageDigest(t *testing.T) {
+	tests := []struct {
+		name     string
+		image    string
+		digest   string
+		expected string
+	}{
+		{
+			name:     "pin tagged image",
+			image:    "registry.io/neutree/vllm:v0.11.2",
+			digest:   "sha256:abc",
+			expected: "registry.io/neutree/vllm:v0.11.2@sha256:abc",
+		},
+		{
+			name:     "replace existing digest",
+			image:    "registry.io/neutree/vllm:v0.11.2@sha256:old",
+			digest:   "sha256:new",
+			expected: "registry.io/neutree/vllm:v0.11.2@sha256:new",
+		},
+		{
+			name:     "no digest leaves image unchanged",
+			image:    "registry.io/neutree/vllm:v0.11.2",
+			expected: "registry.io/neutree/vllm:v0.11.2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, PinImageDigest(tt.image, tt.digest))
+		})
+	}
+}
+
 func TestRewriteImageRef(t *testing.T) {
 	tests := []struct {
 		name        string
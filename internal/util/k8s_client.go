@@ -4,8 +4,13 @@ import (
 	"context"
 	"io"
 
+	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/remotecommand"
 
 	v1 "github.com/neutree-ai/neutree/api/v1"
 )
@@ -14,6 +19,8 @@ import (
 type K8sClient interface {
 	GetPod(ctx context.Context, cluster *v1.Cluster, namespace, name string) (*corev1.Pod, error)
 	GetPodLogs(ctx context.Context, cluster *v1.Cluster, namespace, podName string, opts *corev1.PodLogOptions) (io.ReadCloser, error)
+	ExecInPod(ctx context.Context, cluster *v1.Cluster, namespace, podName string, opts *corev1.PodExecOptions,
+		stdin io.Reader, stdout, stderr io.Writer) error
 }
 
 // DefaultK8sClient is the default implementation using real Kubernetes clientset
@@ -40,3 +47,42 @@ func (c *DefaultK8sClient) GetPodLogs(ctx context.Context, cluster *v1.Cluster,
 
 	return req.Stream(ctx)
 }
+
+// ExecInPod runs a command in a pod container, streaming its output to stdout and stderr.
+// A command exiting non-zero is reported as a k8s.io/client-go/util/exec.ExitError.
+func (c *DefaultK8sClient) ExecInPod(ctx context.Context, cluster *v1.Cluster, namespace, podName string, opts *corev1.PodExecOptions,
+	stdin io.Reader, stdout, stderr io.Writer) error {
+	kubeconfig, err := GetKubeConfigFromCluster(cluster)
+	if err != nil {
+		return errors.Wrap(err, "failed to get kubeconfig from cluster")
+	}
+
+	restConfig, err := clientcmd.RESTConfigFromKubeConfig([]byte(kubeconfig))
+	if err != nil {
+		return errors.Wrap(err, "failed to create REST config")
+	}
+
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return errors.Wrap(err, "failed to create kubernetes clientset")
+	}
+
+	req := clientset.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Name(podName).
+		Namespace(namespace).
+		SubResource("exec").
+		VersionedParams(opts, clientgoscheme.ParameterCodec)
+
+	executor, err := remotecommand.NewSPDYExecutor(restConfig, "POST", req.URL())
+	if err != nil {
+		return errors.Wrap(err, "failed to create SPDY executor")
+	}
+
+	return executor.StreamWithContext(ctx, remotecommand.StreamOptions{
+		Stdin:  stdin,
+		Stdout: stdout,
+		Stderr: stderr,
+		Tty:    opts.TTY,
+	})
+}
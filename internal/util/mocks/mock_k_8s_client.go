@@ -27,6 +27,59 @@ func (_m *MockK8sClient) EXPECT() *MockK8sClient_Expecter {
 	return &MockK8sClient_Expecter{mock: &_m.Mock}
 }
 
+// ExecInPod provides a mock function with given fields: ctx, cluster, namespace, podName, opts, stdin, stdout, stderr
+func (_m *MockK8sClient) ExecInPod(ctx context.Context, cluster *v1.Cluster, namespace string, podName string, opts *corev1.PodExecOptions, stdin io.Reader, stdout io.Writer, stderr io.Writer) error {
+	ret := _m.Called(ctx, cluster, namespace, podName, opts, stdin, stdout, stderr)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExecInPod")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *v1.Cluster, string, string, *corev1.PodExecOptions, io.Reader, io.Writer, io.Writer) error); ok {
+		r0 = rf(ctx, cluster, namespace, podName, opts, stdin, stdout, stderr)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockK8sClient_ExecInPod_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ExecInPod'
+type MockK8sClient_ExecInPod_Call struct {
+	*mock.Call
+}
+
+// ExecInPod is a helper method to define mock.On call
+//   - ctx context.Context
+//   - cluster *v1.Cluster
+//   - namespace string
+//   - podName string
+//   - opts *corev1.PodExecOptions
+//   - stdin io.Reader
+//   - stdout io.Writer
+//   - stderr io.Writer
+func (_e *MockK8sClient_Expecter) ExecInPod(ctx interface{}, cluster interface{}, namespace interface{}, podName interface{}, opts interface{}, stdin interface{}, stdout interface{}, stderr interface{}) *MockK8sClient_ExecInPod_Call {
+	return &MockK8sClient_ExecInPod_Call{Call: _e.mock.On("ExecInPod", ctx, cluster, namespace, podName, opts, stdin, stdout, stderr)}
+}
+
+func (_c *MockK8sClient_ExecInPod_Call) Run(run func(ctx context.Context, cluster *v1.Cluster, namespace string, podName string, opts *corev1.PodExecOptions, stdin io.Reader, stdout io.Writer, stderr io.Writer)) *MockK8sClient_ExecInPod_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*v1.Cluster), args[2].(string), args[3].(string), args[4].(*corev1.PodExecOptions), args[5].(io.Reader), args[6].(io.Writer), args[7].(io.Writer))
+	})
+	return _c
+}
+
+func (_c *MockK8sClient_ExecInPod_Call) Return(_a0 error) *MockK8sClient_ExecInPod_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockK8sClient_ExecInPod_Call) RunAndReturn(run func(context.Context, *v1.Cluster, string, string, *corev1.PodExecOptions, io.Reader, io.Writer, io.Writer) error) *MockK8sClient_ExecInPod_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetPod provides a mock function with given fields: ctx, cluster, namespace, name
 func (_m *MockK8sClient) GetPod(ctx context.Context, cluster *v1.Cluster, namespace string, name string) (*corev1.Pod, error) {
 	ret := _m.Called(ctx, cluster, namespace, name)
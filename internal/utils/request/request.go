@@ -7,6 +7,8 @@ import (
 	"io"
 
 	"github.com/gin-gonic/gin"
+
+	v1 "github.com/neutree-ai/neutree/api/v1"
 )
 
 // BodyContext holds parsed request body information
@@ -61,6 +63,21 @@ func IsSoftDeleteRequest(requestBody map[string]interface{}) bool {
 	return false
 }
 
+// IsForceDeleteRequest checks if a soft delete request sets the force delete annotation
+func IsForceDeleteRequest(requestBody map[string]interface{}) bool {
+	metadata, ok := requestBody["metadata"].(map[string]interface{})
+	if !ok {
+		return false
+	}
+
+	annotations, ok := metadata["annotations"].(map[string]interface{})
+	if !ok {
+		return false
+	}
+
+	return annotations[v1.ForceDeleteAnnotationKey] == v1.ForceDeleteAnnotationValue
+}
+
 // ExtractFilterValue extracts value from PostgREST filter format (e.g., "eq.value" -> "value")
 func ExtractFilterValue(filter string) string {
 	if filter == "" {
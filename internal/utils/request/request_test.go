@@ -169,6 +169,63 @@ func TestIsSoftDeleteRequest(t *testing.T) {
 	}
 }
 
+func TestIsForceDeleteRequest(t *testing.T) {
+	tests := []struct {
+		name        string
+		requestBody map[string]interface{}
+		expected    bool
+	}{
+		{
+			name: "force delete annotation set",
+			requestBody: map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"name":               "test-resource",
+					"deletion_timestamp": "2025-12-29T06:09:38.917Z",
+					"annotations": map[string]interface{}{
+						"neutree.ai/force-delete": "true",
+					},
+				},
+			},
+			expected: true,
+		},
+		{
+			name: "other annotations only",
+			requestBody: map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"name":               "test-resource",
+					"deletion_timestamp": "2025-12-29T06:09:38.917Z",
+					"annotations": map[string]interface{}{
+						"owner": "team-a",
+					},
+				},
+			},
+			expected: false,
+		},
+		{
+			name: "no annotations",
+			requestBody: map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"name":               "test-resource",
+					"deletion_timestamp": "2025-12-29T06:09:38.917Z",
+				},
+			},
+			expected: false,
+		},
+		{
+			name:        "empty request body",
+			requestBody: map[string]interface{}{},
+			expected:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := IsForceDeleteRequest(tt.requestBody)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
 func TestExtractFilterValue(t *testing.T) {
 	tests := []struct {
 		name     string
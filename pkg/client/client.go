@@ -16,6 +16,7 @@ type Client struct {
 	// Service endpoints
 	Models          *ModelsService
 	Engines         *EnginesService
+	Clusters        *ClustersService
 	ImageRegistries *ImageRegistriesService
 	ModelRegistries *ModelRegistriesService
 	Generic         *GenericService
@@ -101,6 +102,7 @@ func NewClient(baseURL string, options ...ClientOption) *Client {
 	// Initialize services
 	client.Models = NewModelsService(client)
 	client.Engines = NewEnginesService(client)
+	client.Clusters = NewClustersService(client)
 	client.ImageRegistries = NewImageRegistriesService(client)
 	client.ModelRegistries = NewModelRegistriesService(client)
 	client.Traces = NewTracesService(client)
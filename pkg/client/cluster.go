@@ -0,0 +1,65 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// ClustersService handles the cluster operations beyond their CRUD, i.e. archiving and
+// restoring clusters.
+type ClustersService struct {
+	client *Client
+}
+
+// NewClustersService creates a new clusters service
+func NewClustersService(client *Client) *ClustersService {
+	return &ClustersService{client: client}
+}
+
+type clusterArchiveRequest struct {
+	Workspace string `json:"workspace"`
+	Name      string `json:"name"`
+}
+
+// Archive archives a cluster, it is kept for audit and recovery but no longer reconciled.
+// Clusters still referenced by endpoints can not be archived.
+func (s *ClustersService) Archive(workspace, name string) error {
+	return s.post("archive", workspace, name)
+}
+
+// Restore restores an archived cluster.
+func (s *ClustersService) Restore(workspace, name string) error {
+	return s.post("restore", workspace, name)
+}
+
+func (s *ClustersService) post(action, workspace, name string) error {
+	body, err := json.Marshal(clusterArchiveRequest{Workspace: workspace, Name: name})
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("%s/api/v1/clusters/%s", s.client.baseURL, action)
+
+	req, err := http.NewRequest("POST", url, bytes.NewBuffer(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("server returned non-204 status: %d, body: %s", resp.StatusCode, string(bodyBytes))
+	}
+
+	return nil
+}
@@ -0,0 +1,44 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClustersArchiveAndRestore(t *testing.T) {
+	var paths []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+
+		var body clusterArchiveRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		require.Equal(t, clusterArchiveRequest{Workspace: "default", Name: "cluster"}, body)
+
+		paths = append(paths, r.URL.Path)
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	c := NewClient(server.URL)
+
+	require.NoError(t, c.Clusters.Archive("default", "cluster"))
+	require.NoError(t, c.Clusters.Restore("default", "cluster"))
+	require.Equal(t, []string{"/api/v1/clusters/archive", "/api/v1/clusters/restore"}, paths)
+}
+
+func TestClustersArchiveReturnsServerError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+		_, _ = w.Write([]byte(`{"error":"cluster default/cluster is still referenced by 1 endpoint(s)"}`))
+	}))
+	defer server.Close()
+
+	err := NewClient(server.URL).Clusters.Archive("default", "cluster")
+	require.ErrorContains(t, err, "still referenced by 1 endpoint(s)")
+}
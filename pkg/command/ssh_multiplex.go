@@ -0,0 +1,353 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+const (
+	// sshMasterOpenTimeout bounds the authentication of a master connection, a master which
+	// did not create its control socket by then is stopped.
+	sshMasterOpenTimeout  = 30 * time.Second
+	sshMasterExitTimeout  = 10 * time.Second
+	sshMasterPollInterval = 50 * time.Millisecond
+)
+
+// sshOptionsWithArgument are the ssh options taking an argument, see ssh(1).
+var sshOptionsWithArgument = map[string]bool{
+	"-B": true, "-b": true, "-c": true, "-D": true, "-E": true, "-e": true, "-F": true, "-I": true,
+	"-i": true, "-J": true, "-L": true, "-l": true, "-m": true, "-O": true, "-o": true, "-p": true,
+	"-Q": true, "-R": true, "-S": true, "-W": true, "-w": true,
+}
+
+// sshForwardOptions are left to the commands, the master connection only authenticates.
+var sshForwardOptions = map[string]bool{"-D": true, "-L": true, "-R": true, "-W": true}
+
+// SSHMultiplexExecutor runs the ssh commands of the wrapped Executor through one persistent
+// master connection per destination, user, port and identity, so that a node running many
+// commands is only authenticated once. Other commands, and ssh commands choosing their own
+// control socket, are run as they are.
+//
+// The masters run in the foreground as child processes of the executor, which waits on them,
+// so no exited master is left unreaped. They are stopped by Close, or when the context given
+// to NewSSHMultiplexExecutor is done. A master which exited is opened again by the next
+// command, and a command whose master cannot be opened connects on its own.
+type SSHMultiplexExecutor struct {
+	executor   Executor
+	controlDir string
+	// masterCommand returns the command of a master connection with the ssh arguments.
+	masterCommand func(args []string) *exec.Cmd
+
+	mu      sync.Mutex
+	masters map[string]*sshMaster
+	closed  bool
+	stop    context.CancelFunc
+}
+
+type sshMaster struct {
+	mu          sync.Mutex
+	controlPath string
+	destination string
+	options     []string
+	// process is the running master, nil if it was not opened.
+	process *sshMasterProcess
+}
+
+type sshMasterProcess struct {
+	cmd *exec.Cmd
+	// output is the ssh output, read once done is closed.
+	output bytes.Buffer
+	// done is closed once the process exited and was waited on.
+	done chan struct{}
+	err  error
+}
+
+func (p *sshMasterProcess) exited() bool {
+	select {
+	case <-p.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// stop terminates the process, killing it if it does not exit within timeout.
+func (p *sshMasterProcess) stop(timeout time.Duration) {
+	if p.exited() {
+		return
+	}
+
+	_ = p.cmd.Process.Signal(syscall.SIGTERM) //nolint:errcheck
+
+	select {
+	case <-p.done:
+	case <-time.After(timeout):
+		_ = p.cmd.Process.Kill() //nolint:errcheck
+
+		<-p.done
+	}
+}
+
+var _ Executor = &SSHMultiplexExecutor{}
+
+// NewSSHMultiplexExecutor wraps executor, the control sockets are kept in a temporary
+// directory removed by Close.
+func NewSSHMultiplexExecutor(ctx context.Context, executor Executor) (*SSHMultiplexExecutor, error) {
+	controlDir, err := os.MkdirTemp("", "neutree-ssh-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create ssh control directory: %w", err)
+	}
+
+	ctx, stop := context.WithCancel(ctx)
+
+	e := &SSHMultiplexExecutor{
+		executor:      executor,
+		controlDir:    controlDir,
+		masterCommand: sshMasterCommand,
+		masters:       map[string]*sshMaster{},
+		stop:          stop,
+	}
+
+	go func() {
+		<-ctx.Done()
+
+		if err := e.Close(); err != nil {
+			klog.Warningf("Failed to close ssh master connections: %v", err)
+		}
+	}()
+
+	return e, nil
+}
+
+func (e *SSHMultiplexExecutor) Execute(ctx context.Context, name string, args []string) ([]byte, error) {
+	if name != "ssh" {
+		return e.executor.Execute(ctx, name, args)
+	}
+
+	master := e.master(args)
+	if master == nil {
+		return e.executor.Execute(ctx, name, args)
+	}
+
+	if err := e.open(ctx, master); err != nil {
+		klog.V(4).Infof("Failed to open ssh master connection to %s, connecting directly: %v", master.destination, err)
+		return e.executor.Execute(ctx, name, args)
+	}
+
+	// ControlMaster=no makes ssh connect on its own if the master exited meanwhile.
+	muxArgs := make([]string, 0, len(args)+4)
+	muxArgs = append(muxArgs, "-o", "ControlMaster=no", "-o", "ControlPath="+master.controlPath)
+	muxArgs = append(muxArgs, args...)
+
+	return e.executor.Execute(ctx, name, muxArgs)
+}
+
+func (e *SSHMultiplexExecutor) ExecuteWithTimeout(ctx context.Context, timeout time.Duration, name string, args []string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	return e.Execute(ctx, name, args)
+}
+
+// Close closes the master connections and removes the control sockets. Commands run after
+// Close connect on their own.
+func (e *SSHMultiplexExecutor) Close() error {
+	e.mu.Lock()
+	if e.closed {
+		e.mu.Unlock()
+		return nil
+	}
+
+	e.closed = true
+	masters := e.masters
+	e.masters = map[string]*sshMaster{}
+	e.mu.Unlock()
+
+	e.stop()
+
+	for _, master := range masters {
+		master.mu.Lock()
+
+		if master.process != nil {
+			master.process.stop(sshMasterExitTimeout)
+			master.process = nil
+		}
+
+		master.mu.Unlock()
+	}
+
+	if err := os.RemoveAll(e.controlDir); err != nil {
+		return fmt.Errorf("failed to remove ssh control directory: %w", err)
+	}
+
+	return nil
+}
+
+// master returns the master connection of the destination of the ssh arguments, or nil if
+// the command is not multiplexed.
+func (e *SSHMultiplexExecutor) master(args []string) *sshMaster {
+	var (
+		destination, user, port, identity string
+		options                           []string
+	)
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			destination = arg
+			break
+		}
+
+		if !sshOptionsWithArgument[arg] {
+			// Flags such as -N or -T only concern the command.
+			continue
+		}
+
+		if i+1 >= len(args) {
+			return nil
+		}
+
+		value := args[i+1]
+		i++
+
+		switch arg {
+		case "-S", "-O":
+			return nil
+		case "-o":
+			option := strings.ToLower(value)
+			if strings.HasPrefix(option, "controlmaster") || strings.HasPrefix(option, "controlpath") ||
+				strings.HasPrefix(option, "controlpersist") {
+				return nil
+			}
+		case "-l":
+			user = value
+		case "-p":
+			port = value
+		case "-i":
+			identity = value
+		}
+
+		if !sshForwardOptions[arg] {
+			options = append(options, arg, value)
+		}
+	}
+
+	if destination == "" {
+		return nil
+	}
+
+	sum := sha256.Sum256([]byte(strings.Join([]string{destination, user, port, identity}, "\x00")))
+	key := hex.EncodeToString(sum[:])
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.closed {
+		return nil
+	}
+
+	master, ok := e.masters[key]
+	if !ok {
+		// Unix socket paths are short, so the socket is named by a prefix of the key.
+		master = &sshMaster{
+			controlPath: filepath.Join(e.controlDir, key[:16]),
+			destination: destination,
+			options:     options,
+		}
+		e.masters[key] = master
+	}
+
+	return master
+}
+
+// open opens the master connection unless it is still open. The master is authenticated once
+// it created its control socket.
+func (e *SSHMultiplexExecutor) open(ctx context.Context, master *sshMaster) error {
+	master.mu.Lock()
+	defer master.mu.Unlock()
+
+	if master.process != nil && !master.process.exited() {
+		return nil
+	}
+
+	master.process = nil
+
+	// Without -f and ControlPersist the master stays in the foreground, ssh would otherwise
+	// fork it into a background process the executor does not wait on.
+	args := make([]string, 0, len(master.options)+6)
+	args = append(args,
+		"-o", "ControlMaster=yes",
+		"-o", "ControlPath="+master.controlPath,
+		"-N",
+	)
+	args = append(args, master.options...)
+	args = append(args, master.destination)
+
+	// A master which was killed leaves its socket behind, and would not listen on it.
+	if err := os.Remove(master.controlPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove ssh control socket: %w", err)
+	}
+
+	cmd := e.masterCommand(args)
+	process := &sshMasterProcess{cmd: cmd, done: make(chan struct{})}
+	cmd.Stdout = &process.output
+	cmd.Stderr = &process.output
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start ssh master connection to %s: %w", master.destination, err)
+	}
+
+	go func() {
+		process.err = cmd.Wait()
+		close(process.done)
+	}()
+
+	timeout := time.NewTimer(sshMasterOpenTimeout)
+	defer timeout.Stop()
+
+	ticker := time.NewTicker(sshMasterPollInterval)
+	defer ticker.Stop()
+
+	for !fileExists(master.controlPath) {
+		select {
+		case <-process.done:
+			if output := strings.TrimSpace(process.output.String()); output != "" {
+				return fmt.Errorf("%w: %s", process.err, output)
+			}
+
+			return fmt.Errorf("ssh master exited: %w", process.err)
+		case <-ctx.Done():
+			process.stop(sshMasterExitTimeout)
+			return ctx.Err()
+		case <-timeout.C:
+			process.stop(sshMasterExitTimeout)
+			return fmt.Errorf("ssh master did not authenticate within %s", sshMasterOpenTimeout)
+		case <-ticker.C:
+		}
+	}
+
+	master.process = process
+
+	return nil
+}
+
+func sshMasterCommand(args []string) *exec.Cmd {
+	return exec.Command("ssh", args...)
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,235 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSSH records the commands, and the masters it starts as processes creating their control
+// socket and running until they are stopped.
+type fakeSSH struct {
+	mu       sync.Mutex
+	commands [][]string
+	masters  []*exec.Cmd
+	// failMasters makes the masters exit without creating their control socket.
+	failMasters bool
+}
+
+func (f *fakeSSH) Execute(ctx context.Context, name string, args []string) ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.commands = append(f.commands, append([]string{name}, args...))
+
+	return []byte("ok"), nil
+}
+
+func (f *fakeSSH) masterCommand(args []string) *exec.Cmd {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.commands = append(f.commands, append([]string{"ssh"}, args...))
+
+	cmd := exec.Command("sh", "-c", `touch "$0" && exec sleep 60`, strings.TrimPrefix(args[3], "ControlPath="))
+	if f.failMasters {
+		cmd = exec.Command("sh", "-c", "echo mux_listener_setup: cannot listen >&2; exit 255")
+	}
+
+	f.masters = append(f.masters, cmd)
+
+	return cmd
+}
+
+func (f *fakeSSH) master(i int) *exec.Cmd {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.masters[i]
+}
+
+func (f *fakeSSH) ExecuteWithTimeout(ctx context.Context, timeout time.Duration, name string, args []string) ([]byte, error) {
+	return f.Execute(ctx, name, args)
+}
+
+func (f *fakeSSH) recorded() [][]string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([][]string(nil), f.commands...)
+}
+
+func sshArgs(destination string, cmd string) []string {
+	return []string{"-L", "8265:localhost:8265", "-o", "StrictHostKeyChecking=no", "-i", "/tmp/key", destination, cmd}
+}
+
+func newTestSSHMultiplexExecutor(t *testing.T, ctx context.Context, fake *fakeSSH) *SSHMultiplexExecutor {
+	e, err := NewSSHMultiplexExecutor(ctx, fake)
+	require.NoError(t, err)
+
+	e.masterCommand = fake.masterCommand
+
+	return e
+}
+
+// exited reports whether the process of cmd exited and was waited on, i.e. is no zombie.
+func exited(cmd *exec.Cmd) bool {
+	return errors.Is(cmd.Process.Signal(syscall.Signal(0)), os.ErrProcessDone)
+}
+
+// runningMasters returns the number of masters e considers running.
+func runningMasters(e *SSHMultiplexExecutor) int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	running := 0
+
+	for _, master := range e.masters {
+		master.mu.Lock()
+
+		if master.process != nil && !master.process.exited() {
+			running++
+		}
+
+		master.mu.Unlock()
+	}
+
+	return running
+}
+
+func TestSSHMultiplexExecutor_ReusesMasterPerDestination(t *testing.T) {
+	fake := &fakeSSH{}
+	e := newTestSSHMultiplexExecutor(t, context.Background(), fake)
+
+	defer e.Close() //nolint:errcheck
+
+	for _, destination := range []string{"root@10.0.0.1", "root@10.0.0.1", "root@10.0.0.2"} {
+		output, err := e.Execute(context.Background(), "ssh", sshArgs(destination, "uptime"))
+		require.NoError(t, err)
+		assert.Equal(t, "ok", string(output))
+	}
+
+	commands := fake.recorded()
+	require.Len(t, commands, 5)
+
+	// The master only authenticates, the forwards are left to the commands.
+	master := commands[0]
+	controlPath := strings.TrimPrefix(master[4], "ControlPath=")
+	assert.Equal(t, []string{"ssh", "-o", "ControlMaster=yes", "-o", "ControlPath=" + controlPath,
+		"-N", "-o", "StrictHostKeyChecking=no", "-i", "/tmp/key", "root@10.0.0.1"}, master)
+	assert.Equal(t, append([]string{"ssh", "-o", "ControlMaster=no", "-o", "ControlPath=" + controlPath},
+		sshArgs("root@10.0.0.1", "uptime")...), commands[1])
+	assert.Equal(t, commands[1], commands[2])
+
+	// Another destination has its own master.
+	assert.Contains(t, commands[3], "ControlMaster=yes")
+	assert.NotContains(t, commands[3], "ControlPath="+controlPath)
+	assert.Contains(t, commands[4], "root@10.0.0.2")
+}
+
+func TestSSHMultiplexExecutor_ReopensExitedMaster(t *testing.T) {
+	fake := &fakeSSH{}
+	e := newTestSSHMultiplexExecutor(t, context.Background(), fake)
+
+	defer e.Close() //nolint:errcheck
+
+	_, err := e.Execute(context.Background(), "ssh", sshArgs("root@10.0.0.1", "uptime"))
+	require.NoError(t, err)
+
+	// A killed master leaves its socket behind.
+	require.NoError(t, fake.master(0).Process.Kill())
+	assert.Eventually(t, func() bool { return runningMasters(e) == 0 }, time.Second, time.Millisecond)
+
+	_, err = e.Execute(context.Background(), "ssh", sshArgs("root@10.0.0.1", "uptime"))
+	require.NoError(t, err)
+
+	commands := fake.recorded()
+	require.Len(t, commands, 4)
+	assert.Contains(t, commands[2], "ControlMaster=yes")
+	assert.True(t, exited(fake.master(0)))
+	assert.Equal(t, 1, runningMasters(e))
+}
+
+func TestSSHMultiplexExecutor_RunsOtherCommandsAsTheyAre(t *testing.T) {
+	fake := &fakeSSH{}
+	e := newTestSSHMultiplexExecutor(t, context.Background(), fake)
+
+	defer e.Close() //nolint:errcheck
+
+	commands := [][]string{
+		{"ray", "up", "cluster.yaml"},
+		{"ssh", "-o", "ControlMaster=auto", "-o", "ControlPath=/tmp/%C", "root@10.0.0.1", "uptime"},
+		{"ssh", "-S", "/tmp/socket", "root@10.0.0.1", "uptime"},
+		{"ssh", "-o"},
+	}
+
+	for _, command := range commands {
+		_, err := e.Execute(context.Background(), command[0], command[1:])
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, commands, fake.recorded())
+}
+
+func TestSSHMultiplexExecutor_ConnectsDirectlyWhenMasterFails(t *testing.T) {
+	fake := &fakeSSH{failMasters: true}
+	e := newTestSSHMultiplexExecutor(t, context.Background(), fake)
+
+	defer e.Close() //nolint:errcheck
+
+	output, err := e.Execute(context.Background(), "ssh", sshArgs("root@10.0.0.1", "uptime"))
+	require.NoError(t, err)
+	assert.Equal(t, "ok", string(output))
+
+	commands := fake.recorded()
+	require.Len(t, commands, 2)
+	assert.Equal(t, append([]string{"ssh"}, sshArgs("root@10.0.0.1", "uptime")...), commands[1])
+}
+
+func TestSSHMultiplexExecutor_Close(t *testing.T) {
+	fake := &fakeSSH{}
+	e := newTestSSHMultiplexExecutor(t, context.Background(), fake)
+
+	_, err := e.Execute(context.Background(), "ssh", sshArgs("root@10.0.0.1", "uptime"))
+	require.NoError(t, err)
+
+	require.NoError(t, e.Close())
+	require.NoError(t, e.Close())
+
+	// The master is stopped and waited on.
+	assert.True(t, exited(fake.master(0)))
+	assert.Len(t, fake.recorded(), 2)
+	assert.NoDirExists(t, e.controlDir)
+
+	// Commands after Close connect on their own.
+	_, err = e.Execute(context.Background(), "ssh", sshArgs("root@10.0.0.1", "uptime"))
+	require.NoError(t, err)
+	assert.Equal(t, append([]string{"ssh"}, sshArgs("root@10.0.0.1", "uptime")...), fake.recorded()[2])
+}
+
+func TestSSHMultiplexExecutor_ClosesOnContextDone(t *testing.T) {
+	fake := &fakeSSH{}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	e := newTestSSHMultiplexExecutor(t, ctx, fake)
+
+	_, err := e.Execute(context.Background(), "ssh", sshArgs("root@10.0.0.1", "uptime"))
+	require.NoError(t, err)
+
+	cancel()
+
+	assert.Eventually(t, func() bool { return exited(fake.master(0)) }, time.Second, time.Millisecond)
+	assert.Eventually(t, func() bool {
+		_, err := os.Stat(e.controlDir)
+		return os.IsNotExist(err)
+	}, time.Second, time.Millisecond)
+}
@@ -0,0 +1,185 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	v1 "github.com/neutree-ai/neutree/api/v1"
+)
+
+// cachedStorage serves ListEndpoint and ListCluster from memory for ttl, so the controllers
+// and routes listing them on every reconcile share one PostgREST request. Concurrent identical
+// lists wait for the same request. A create, update or delete of endpoints or clusters through
+// the storage drops the lists of that kind; writes by other processes are seen once the lists
+// expire.
+//
+// The lists are kept as JSON and decoded on every call, so callers can modify the returned
+// resources.
+type cachedStorage struct {
+	Storage
+
+	ttl time.Duration
+	now func() time.Time
+
+	mu sync.Mutex
+	// entries holds the lists by kind, generation of the kind and list option.
+	entries map[string]cacheEntry
+	// generations of the kinds, bumped by every write so lists read before it are not cached.
+	generations map[string]uint64
+	group       singleflight.Group
+}
+
+type cacheEntry struct {
+	data   []byte
+	expiry time.Time
+}
+
+func newCachedStorage(s Storage, ttl time.Duration) *cachedStorage {
+	return &cachedStorage{
+		Storage:     s,
+		ttl:         ttl,
+		now:         time.Now,
+		entries:     map[string]cacheEntry{},
+		generations: map[string]uint64{},
+	}
+}
+
+func cachedList[T any](c *cachedStorage, kind string, option ListOption, list func(ListOption) ([]T, error)) ([]T, error) {
+	optionKey, err := json.Marshal(option)
+	if err != nil {
+		return list(option)
+	}
+
+	c.mu.Lock()
+	generation := c.generations[kind]
+	key := fmt.Sprintf("%s/%d/%s", kind, generation, optionKey)
+	entry, ok := c.entries[key]
+	c.mu.Unlock()
+
+	data := entry.data
+
+	if !ok || !c.now().Before(entry.expiry) {
+		v, err, _ := c.group.Do(key, func() (interface{}, error) {
+			items, err := list(option)
+			if err != nil {
+				return nil, err
+			}
+
+			data, err := json.Marshal(items)
+			if err != nil {
+				return nil, err
+			}
+
+			c.store(kind, generation, key, data)
+
+			return data, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		data = v.([]byte) //nolint:errcheck
+	}
+
+	var items []T
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+func (c *cachedStorage) store(kind string, generation uint64, key string, data []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Written meanwhile, the list may miss the write.
+	if c.generations[kind] != generation {
+		return
+	}
+
+	now := c.now()
+	for k, entry := range c.entries {
+		if !now.Before(entry.expiry) {
+			delete(c.entries, k)
+		}
+	}
+
+	c.entries[key] = cacheEntry{data: data, expiry: now.Add(c.ttl)}
+}
+
+func (c *cachedStorage) invalidate(kind string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generations[kind]++
+
+	for key := range c.entries {
+		if strings.HasPrefix(key, kind+"/") {
+			delete(c.entries, key)
+		}
+	}
+}
+
+func (c *cachedStorage) ListEndpoint(option ListOption) ([]v1.Endpoint, error) {
+	return cachedList(c, ENDPOINT_TABLE, option, c.Storage.ListEndpoint)
+}
+
+func (c *cachedStorage) CreateEndpoint(data *v1.Endpoint) error {
+	defer c.invalidate(ENDPOINT_TABLE)
+	return c.Storage.CreateEndpoint(data)
+}
+
+func (c *cachedStorage) DeleteEndpoint(id string) error {
+	defer c.invalidate(ENDPOINT_TABLE)
+	return c.Storage.DeleteEndpoint(id)
+}
+
+func (c *cachedStorage) UpdateEndpoint(id string, data *v1.Endpoint, opts ...UpdateOption) error {
+	defer c.invalidate(ENDPOINT_TABLE)
+	return c.Storage.UpdateEndpoint(id, data, opts...)
+}
+
+func (c *cachedStorage) ListCluster(option ListOption) ([]v1.Cluster, error) {
+	return cachedList(c, CLUSTERS_TABLE, option, c.Storage.ListCluster)
+}
+
+func (c *cachedStorage) CreateCluster(data *v1.Cluster) error {
+	defer c.invalidate(CLUSTERS_TABLE)
+	return c.Storage.CreateCluster(data)
+}
+
+func (c *cachedStorage) DeleteCluster(id string) error {
+	defer c.invalidate(CLUSTERS_TABLE)
+	return c.Storage.DeleteCluster(id)
+}
+
+func (c *cachedStorage) ArchiveCluster(id string) error {
+	defer c.invalidate(CLUSTERS_TABLE)
+	return c.Storage.ArchiveCluster(id)
+}
+
+func (c *cachedStorage) RestoreCluster(id string) error {
+	defer c.invalidate(CLUSTERS_TABLE)
+	return c.Storage.RestoreCluster(id)
+}
+
+func (c *cachedStorage) UpdateCluster(id string, data *v1.Cluster) error {
+	defer c.invalidate(CLUSTERS_TABLE)
+	return c.Storage.UpdateCluster(id, data)
+}
+
+func (c *cachedStorage) GenericCreate(table string, data interface{}, option CreateOption) error {
+	defer c.invalidate(table)
+	return c.Storage.GenericCreate(table, data, option)
+}
+
+func (c *cachedStorage) GenericUpdate(table string, id string, data interface{}, option UpdateOption) error {
+	defer c.invalidate(table)
+	return c.Storage.GenericUpdate(table, id, data, option)
+}
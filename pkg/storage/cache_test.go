@@ -0,0 +1,174 @@
+package storage
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	v1 "github.com/neutree-ai/neutree/api/v1"
+)
+
+// countingStorage counts the endpoint and cluster lists reaching the storage.
+type countingStorage struct {
+	Storage
+
+	endpointLists atomic.Int32
+	clusterLists  atomic.Int32
+	// release, when set, blocks the endpoint lists until it is closed.
+	release chan struct{}
+}
+
+func (s *countingStorage) ListEndpoint(option ListOption) ([]v1.Endpoint, error) {
+	s.endpointLists.Add(1)
+
+	if s.release != nil {
+		<-s.release
+	}
+
+	return []v1.Endpoint{{
+		Metadata: &v1.Metadata{Name: "chat-model"},
+		Status:   &v1.EndpointStatus{Phase: v1.EndpointPhaseRUNNING},
+	}}, nil
+}
+
+func (s *countingStorage) UpdateEndpoint(id string, data *v1.Endpoint, opts ...UpdateOption) error {
+	return nil
+}
+
+func (s *countingStorage) ListCluster(option ListOption) ([]v1.Cluster, error) {
+	s.clusterLists.Add(1)
+	return []v1.Cluster{{Metadata: &v1.Metadata{Name: "cluster"}}}, nil
+}
+
+func (s *countingStorage) GenericUpdate(table string, id string, data interface{}, option UpdateOption) error {
+	return nil
+}
+
+func TestCachedStorage_ServesListsUntilExpiry(t *testing.T) {
+	backend := &countingStorage{}
+	s := newCachedStorage(backend, time.Second)
+
+	now := time.Now()
+	s.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		endpoints, err := s.ListEndpoint(ListOption{})
+		require.NoError(t, err)
+		require.Len(t, endpoints, 1)
+		assert.Equal(t, "chat-model", endpoints[0].Metadata.Name)
+	}
+
+	assert.Equal(t, int32(1), backend.endpointLists.Load())
+
+	// Other filters are listed on their own.
+	_, err := s.ListEndpoint(ListOption{Filters: []Filter{{Column: "metadata->>name", Operator: "eq", Value: "chat-model"}}})
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), backend.endpointLists.Load())
+
+	now = now.Add(time.Second)
+
+	_, err = s.ListEndpoint(ListOption{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), backend.endpointLists.Load())
+}
+
+func TestCachedStorage_ReturnsCopies(t *testing.T) {
+	s := newCachedStorage(&countingStorage{}, time.Minute)
+
+	endpoints, err := s.ListEndpoint(ListOption{})
+	require.NoError(t, err)
+
+	endpoints[0].Status.Phase = v1.EndpointPhaseFAILED
+
+	endpoints, err = s.ListEndpoint(ListOption{})
+	require.NoError(t, err)
+	assert.Equal(t, v1.EndpointPhaseRUNNING, endpoints[0].Status.Phase)
+}
+
+func TestCachedStorage_InvalidatesOnWrite(t *testing.T) {
+	backend := &countingStorage{}
+	s := newCachedStorage(backend, time.Minute)
+
+	list := func() {
+		_, err := s.ListEndpoint(ListOption{})
+		require.NoError(t, err)
+		_, err = s.ListCluster(ListOption{})
+		require.NoError(t, err)
+	}
+
+	list()
+	require.NoError(t, s.UpdateEndpoint("1", &v1.Endpoint{}))
+	list()
+
+	// Only the lists of the written kind are dropped.
+	assert.Equal(t, int32(2), backend.endpointLists.Load())
+	assert.Equal(t, int32(1), backend.clusterLists.Load())
+
+	require.NoError(t, s.GenericUpdate(CLUSTERS_TABLE, "1", map[string]interface{}{}, UpdateOption{}))
+	list()
+
+	assert.Equal(t, int32(2), backend.endpointLists.Load())
+	assert.Equal(t, int32(2), backend.clusterLists.Load())
+}
+
+func TestCachedStorage_DedupesConcurrentLists(t *testing.T) {
+	backend := &countingStorage{release: make(chan struct{})}
+	s := newCachedStorage(backend, time.Minute)
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			endpoints, err := s.ListEndpoint(ListOption{})
+			assert.NoError(t, err)
+			assert.Len(t, endpoints, 1)
+		}()
+	}
+
+	assert.Eventually(t, func() bool { return backend.endpointLists.Load() == 1 }, time.Second, time.Millisecond)
+	close(backend.release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), backend.endpointLists.Load())
+}
+
+func TestCachedStorage_DoesNotCacheListsReadBeforeWrite(t *testing.T) {
+	backend := &countingStorage{release: make(chan struct{})}
+	s := newCachedStorage(backend, time.Minute)
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		_, err := s.ListEndpoint(ListOption{})
+		assert.NoError(t, err)
+	}()
+
+	assert.Eventually(t, func() bool { return backend.endpointLists.Load() == 1 }, time.Second, time.Millisecond)
+	require.NoError(t, s.UpdateEndpoint("1", &v1.Endpoint{}))
+	close(backend.release)
+	<-done
+
+	_, err := s.ListEndpoint(ListOption{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), backend.endpointLists.Load())
+}
+
+func TestNew_CacheTTL(t *testing.T) {
+	s, err := New(Options{AccessURL: "http://127.0.0.1:1", Scheme: "public", JwtSecret: "test-secret"})
+	require.NoError(t, err)
+	assert.IsType(t, &postgrestStorage{}, s)
+
+	s, err = New(Options{AccessURL: "http://127.0.0.1:1", Scheme: "public", JwtSecret: "test-secret", CacheTTL: time.Second})
+	require.NoError(t, err)
+	assert.IsType(t, &cachedStorage{}, s)
+}
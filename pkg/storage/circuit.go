@@ -0,0 +1,146 @@
+package storage
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+)
+
+// ErrCircuitOpen is returned without sending the request while the storage circuit is open,
+// i.e. after PostgREST kept failing. The requests are sent again once a probe succeeds.
+var ErrCircuitOpen = errors.New("storage circuit open, postgrest keeps failing")
+
+// CircuitBreakerPolicy configures when the requests to PostgREST stop being sent. After
+// FailureThreshold consecutive requests failed with a network error or a 5xx response, the
+// circuit opens and every request fails with ErrCircuitOpen for OpenDuration, so the
+// controllers sharing the storage back off together rather than keep a struggling database
+// busy. A single request is then sent as a probe: its success closes the circuit, its
+// failure opens it for another OpenDuration.
+type CircuitBreakerPolicy struct {
+	// FailureThreshold is the number of consecutive failed requests opening the circuit, the
+	// circuit breaker is disabled when 0.
+	FailureThreshold int
+	// OpenDuration is how long the circuit stays open before a probe is sent.
+	OpenDuration time.Duration
+}
+
+// DefaultCircuitBreakerPolicy returns the circuit breaker policy of the neutree components.
+func DefaultCircuitBreakerPolicy() CircuitBreakerPolicy {
+	return CircuitBreakerPolicy{
+		FailureThreshold: 10,
+		OpenDuration:     30 * time.Second,
+	}
+}
+
+func (p CircuitBreakerPolicy) enabled() bool {
+	return p.FailureThreshold > 0
+}
+
+// circuitTransport sends the requests through next unless the circuit is open.
+type circuitTransport struct {
+	policy CircuitBreakerPolicy
+	next   http.RoundTripper
+	now    func() time.Time
+
+	mu       sync.Mutex
+	failures int
+	// openedAt is the time the circuit opened, zero while it is closed.
+	openedAt time.Time
+	// probing is set while the probe of the open circuit is sent.
+	probing bool
+}
+
+func newCircuitTransport(policy CircuitBreakerPolicy, next http.RoundTripper) *circuitTransport {
+	return &circuitTransport{policy: policy, next: next, now: time.Now}
+}
+
+func (t *circuitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	probe, err := t.allow()
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	// Requests canceled by the caller say nothing about PostgREST.
+	if err != nil && req.Context().Err() != nil {
+		if probe {
+			t.endProbe()
+		}
+
+		return resp, err
+	}
+
+	t.record(probe, shouldRetry(resp, err))
+
+	return resp, err
+}
+
+// allow returns whether the request may be sent and whether it is the probe of the open circuit.
+func (t *circuitTransport) allow() (bool, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.openedAt.IsZero() {
+		return false, nil
+	}
+
+	if t.probing {
+		return false, ErrCircuitOpen
+	}
+
+	if wait := t.policy.OpenDuration - t.now().Sub(t.openedAt); wait > 0 {
+		return false, errors.Wrapf(ErrCircuitOpen, "retry in %s", wait.Round(time.Second))
+	}
+
+	t.probing = true
+
+	return true, nil
+}
+
+func (t *circuitTransport) endProbe() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.probing = false
+}
+
+func (t *circuitTransport) record(probe, failed bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if probe {
+		t.probing = false
+	}
+
+	open := !t.openedAt.IsZero()
+
+	if !failed {
+		// Only the probe closes the circuit, not requests sent before it opened.
+		if !open || probe {
+			if open {
+				klog.Info("Storage circuit closed, postgrest recovered")
+			}
+
+			t.failures = 0
+			t.openedAt = time.Time{}
+		}
+
+		return
+	}
+
+	if open && !probe {
+		return
+	}
+
+	t.failures++
+	if probe || t.failures >= t.policy.FailureThreshold {
+		if !open {
+			klog.Warningf("Storage circuit opened after %d consecutive failed postgrest requests", t.failures)
+		}
+
+		t.openedAt = t.now()
+	}
+}
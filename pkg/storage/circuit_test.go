@@ -0,0 +1,114 @@
+package storage
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	var (
+		called  atomic.Int32
+		healthy atomic.Bool
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`{"message":"unavailable"}`))
+
+			return
+		}
+
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer srv.Close()
+
+	postgrestClient, err := newPostgrestClient(Options{
+		AccessURL:      srv.URL,
+		Scheme:         "public",
+		JwtSecret:      "test-secret",
+		CircuitBreaker: CircuitBreakerPolicy{FailureThreshold: 3, OpenDuration: time.Minute},
+	})
+	require.NoError(t, err)
+
+	circuit, ok := postgrestClient.Transport.Parent.(*circuitTransport)
+	require.True(t, ok)
+
+	now := time.Now()
+	circuit.now = func() time.Time { return now }
+
+	s := &postgrestStorage{postgrestClient: postgrestClient}
+	list := func() error {
+		_, err := s.ListCluster(ListOption{})
+		return err
+	}
+
+	// Repeated failures open the circuit.
+	for i := 0; i < 3; i++ {
+		err := list()
+		require.Error(t, err)
+		assert.False(t, errors.Is(err, ErrCircuitOpen))
+	}
+
+	// Requests fail fast while the circuit is open, writes too.
+	err = list()
+	require.ErrorIs(t, err, ErrCircuitOpen)
+	require.ErrorIs(t, s.DeleteCluster("1"), ErrCircuitOpen)
+	assert.Equal(t, int32(3), called.Load())
+
+	// A failed probe opens the circuit again.
+	now = now.Add(time.Minute)
+	require.Error(t, list())
+	assert.Equal(t, int32(4), called.Load())
+	require.ErrorIs(t, list(), ErrCircuitOpen)
+	assert.Equal(t, int32(4), called.Load())
+
+	// A successful probe closes it.
+	healthy.Store(true)
+	now = now.Add(time.Minute)
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, list())
+	}
+
+	assert.Equal(t, int32(7), called.Load())
+}
+
+func TestCircuitBreaker_OnlyProbeClosesCircuit(t *testing.T) {
+	next := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	})
+	circuit := newCircuitTransport(CircuitBreakerPolicy{FailureThreshold: 1, OpenDuration: time.Minute}, next)
+
+	circuit.record(false, true)
+	// A request sent before the circuit opened succeeds afterwards.
+	circuit.record(false, false)
+
+	req := httptest.NewRequest(http.MethodGet, "http://postgrest/clusters", nil)
+	_, err := circuit.RoundTrip(req)
+	require.ErrorIs(t, err, ErrCircuitOpen)
+}
+
+func TestCircuitBreaker_DisabledByDefault(t *testing.T) {
+	postgrestClient, err := newPostgrestClient(Options{AccessURL: "http://127.0.0.1:1", Scheme: "public", JwtSecret: "test-secret"})
+	require.NoError(t, err)
+
+	_, ok := postgrestClient.Transport.Parent.(*circuitTransport)
+	assert.False(t, ok)
+}
+
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
@@ -22,6 +22,52 @@ func (_m *MockStorage) EXPECT() *MockStorage_Expecter {
 	return &MockStorage_Expecter{mock: &_m.Mock}
 }
 
+// ArchiveCluster provides a mock function with given fields: id
+func (_m *MockStorage) ArchiveCluster(id string) error {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ArchiveCluster")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockStorage_ArchiveCluster_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ArchiveCluster'
+type MockStorage_ArchiveCluster_Call struct {
+	*mock.Call
+}
+
+// ArchiveCluster is a helper method to define mock.On call
+//   - id string
+func (_e *MockStorage_Expecter) ArchiveCluster(id interface{}) *MockStorage_ArchiveCluster_Call {
+	return &MockStorage_ArchiveCluster_Call{Call: _e.mock.On("ArchiveCluster", id)}
+}
+
+func (_c *MockStorage_ArchiveCluster_Call) Run(run func(id string)) *MockStorage_ArchiveCluster_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *MockStorage_ArchiveCluster_Call) Return(_a0 error) *MockStorage_ArchiveCluster_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockStorage_ArchiveCluster_Call) RunAndReturn(run func(string) error) *MockStorage_ArchiveCluster_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // CallDatabaseFunction provides a mock function with given fields: name, params, result
 func (_m *MockStorage) CallDatabaseFunction(name string, params map[string]interface{}, result interface{}) error {
 	ret := _m.Called(name, params, result)
@@ -70,6 +116,55 @@ func (_c *MockStorage_CallDatabaseFunction_Call) RunAndReturn(run func(string, m
 	return _c
 }
 
+// CallDatabaseFunctionAsUser provides a mock function with given fields: userID, name, params, result
+func (_m *MockStorage) CallDatabaseFunctionAsUser(userID string, name string, params map[string]interface{}, result interface{}) error {
+	ret := _m.Called(userID, name, params, result)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CallDatabaseFunctionAsUser")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, map[string]interface{}, interface{}) error); ok {
+		r0 = rf(userID, name, params, result)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockStorage_CallDatabaseFunctionAsUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CallDatabaseFunctionAsUser'
+type MockStorage_CallDatabaseFunctionAsUser_Call struct {
+	*mock.Call
+}
+
+// CallDatabaseFunctionAsUser is a helper method to define mock.On call
+//   - userID string
+//   - name string
+//   - params map[string]interface{}
+//   - result interface{}
+func (_e *MockStorage_Expecter) CallDatabaseFunctionAsUser(userID interface{}, name interface{}, params interface{}, result interface{}) *MockStorage_CallDatabaseFunctionAsUser_Call {
+	return &MockStorage_CallDatabaseFunctionAsUser_Call{Call: _e.mock.On("CallDatabaseFunctionAsUser", userID, name, params, result)}
+}
+
+func (_c *MockStorage_CallDatabaseFunctionAsUser_Call) Run(run func(userID string, name string, params map[string]interface{}, result interface{})) *MockStorage_CallDatabaseFunctionAsUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string), args[2].(map[string]interface{}), args[3].(interface{}))
+	})
+	return _c
+}
+
+func (_c *MockStorage_CallDatabaseFunctionAsUser_Call) Return(_a0 error) *MockStorage_CallDatabaseFunctionAsUser_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockStorage_CallDatabaseFunctionAsUser_Call) RunAndReturn(run func(string, string, map[string]interface{}, interface{}) error) *MockStorage_CallDatabaseFunctionAsUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Count provides a mock function with given fields: table, filters
 func (_m *MockStorage) Count(table string, filters []storage.Filter) (int, error) {
 	ret := _m.Called(table, filters)
@@ -1415,6 +1510,54 @@ func (_c *MockStorage_DeleteWorkspace_Call) RunAndReturn(run func(string) error)
 	return _c
 }
 
+// GenericCreate provides a mock function with given fields: table, data, option
+func (_m *MockStorage) GenericCreate(table string, data interface{}, option storage.CreateOption) error {
+	ret := _m.Called(table, data, option)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GenericCreate")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, interface{}, storage.CreateOption) error); ok {
+		r0 = rf(table, data, option)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockStorage_GenericCreate_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GenericCreate'
+type MockStorage_GenericCreate_Call struct {
+	*mock.Call
+}
+
+// GenericCreate is a helper method to define mock.On call
+//   - table string
+//   - data interface{}
+//   - option storage.CreateOption
+func (_e *MockStorage_Expecter) GenericCreate(table interface{}, data interface{}, option interface{}) *MockStorage_GenericCreate_Call {
+	return &MockStorage_GenericCreate_Call{Call: _e.mock.On("GenericCreate", table, data, option)}
+}
+
+func (_c *MockStorage_GenericCreate_Call) Run(run func(table string, data interface{}, option storage.CreateOption)) *MockStorage_GenericCreate_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(interface{}), args[2].(storage.CreateOption))
+	})
+	return _c
+}
+
+func (_c *MockStorage_GenericCreate_Call) Return(_a0 error) *MockStorage_GenericCreate_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockStorage_GenericCreate_Call) RunAndReturn(run func(string, interface{}, storage.CreateOption) error) *MockStorage_GenericCreate_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GenericQuery provides a mock function with given fields: table, selectFields, filters, result
 func (_m *MockStorage) GenericQuery(table string, selectFields string, filters []storage.Filter, result interface{}) error {
 	ret := _m.Called(table, selectFields, filters, result)
@@ -1464,6 +1607,55 @@ func (_c *MockStorage_GenericQuery_Call) RunAndReturn(run func(string, string, [
 	return _c
 }
 
+// GenericUpdate provides a mock function with given fields: table, id, data, option
+func (_m *MockStorage) GenericUpdate(table string, id string, data interface{}, option storage.UpdateOption) error {
+	ret := _m.Called(table, id, data, option)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GenericUpdate")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, interface{}, storage.UpdateOption) error); ok {
+		r0 = rf(table, id, data, option)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockStorage_GenericUpdate_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GenericUpdate'
+type MockStorage_GenericUpdate_Call struct {
+	*mock.Call
+}
+
+// GenericUpdate is a helper method to define mock.On call
+//   - table string
+//   - id string
+//   - data interface{}
+//   - option storage.UpdateOption
+func (_e *MockStorage_Expecter) GenericUpdate(table interface{}, id interface{}, data interface{}, option interface{}) *MockStorage_GenericUpdate_Call {
+	return &MockStorage_GenericUpdate_Call{Call: _e.mock.On("GenericUpdate", table, id, data, option)}
+}
+
+func (_c *MockStorage_GenericUpdate_Call) Run(run func(table string, id string, data interface{}, option storage.UpdateOption)) *MockStorage_GenericUpdate_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string), args[2].(interface{}), args[3].(storage.UpdateOption))
+	})
+	return _c
+}
+
+func (_c *MockStorage_GenericUpdate_Call) Return(_a0 error) *MockStorage_GenericUpdate_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockStorage_GenericUpdate_Call) RunAndReturn(run func(string, string, interface{}, storage.UpdateOption) error) *MockStorage_GenericUpdate_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetApiKey provides a mock function with given fields: id
 func (_m *MockStorage) GetApiKey(id string) (*v1.ApiKey, error) {
 	ret := _m.Called(id)
@@ -1580,6 +1772,65 @@ func (_c *MockStorage_GetCluster_Call) RunAndReturn(run func(string) (*v1.Cluste
 	return _c
 }
 
+// GetClusterByName provides a mock function with given fields: workspace, name
+func (_m *MockStorage) GetClusterByName(workspace string, name string) (*v1.Cluster, error) {
+	ret := _m.Called(workspace, name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetClusterByName")
+	}
+
+	var r0 *v1.Cluster
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string) (*v1.Cluster, error)); ok {
+		return rf(workspace, name)
+	}
+	if rf, ok := ret.Get(0).(func(string, string) *v1.Cluster); ok {
+		r0 = rf(workspace, name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*v1.Cluster)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(workspace, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockStorage_GetClusterByName_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetClusterByName'
+type MockStorage_GetClusterByName_Call struct {
+	*mock.Call
+}
+
+// GetClusterByName is a helper method to define mock.On call
+//   - workspace string
+//   - name string
+func (_e *MockStorage_Expecter) GetClusterByName(workspace interface{}, name interface{}) *MockStorage_GetClusterByName_Call {
+	return &MockStorage_GetClusterByName_Call{Call: _e.mock.On("GetClusterByName", workspace, name)}
+}
+
+func (_c *MockStorage_GetClusterByName_Call) Run(run func(workspace string, name string)) *MockStorage_GetClusterByName_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockStorage_GetClusterByName_Call) Return(_a0 *v1.Cluster, _a1 error) *MockStorage_GetClusterByName_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockStorage_GetClusterByName_Call) RunAndReturn(run func(string, string) (*v1.Cluster, error)) *MockStorage_GetClusterByName_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetEndpoint provides a mock function with given fields: id
 func (_m *MockStorage) GetEndpoint(id string) (*v1.Endpoint, error) {
 	ret := _m.Called(id)
@@ -1638,6 +1889,65 @@ func (_c *MockStorage_GetEndpoint_Call) RunAndReturn(run func(string) (*v1.Endpo
 	return _c
 }
 
+// GetEndpointByName provides a mock function with given fields: workspace, name
+func (_m *MockStorage) GetEndpointByName(workspace string, name string) (*v1.Endpoint, error) {
+	ret := _m.Called(workspace, name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetEndpointByName")
+	}
+
+	var r0 *v1.Endpoint
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string) (*v1.Endpoint, error)); ok {
+		return rf(workspace, name)
+	}
+	if rf, ok := ret.Get(0).(func(string, string) *v1.Endpoint); ok {
+		r0 = rf(workspace, name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*v1.Endpoint)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(workspace, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockStorage_GetEndpointByName_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetEndpointByName'
+type MockStorage_GetEndpointByName_Call struct {
+	*mock.Call
+}
+
+// GetEndpointByName is a helper method to define mock.On call
+//   - workspace string
+//   - name string
+func (_e *MockStorage_Expecter) GetEndpointByName(workspace interface{}, name interface{}) *MockStorage_GetEndpointByName_Call {
+	return &MockStorage_GetEndpointByName_Call{Call: _e.mock.On("GetEndpointByName", workspace, name)}
+}
+
+func (_c *MockStorage_GetEndpointByName_Call) Run(run func(workspace string, name string)) *MockStorage_GetEndpointByName_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockStorage_GetEndpointByName_Call) Return(_a0 *v1.Endpoint, _a1 error) *MockStorage_GetEndpointByName_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockStorage_GetEndpointByName_Call) RunAndReturn(run func(string, string) (*v1.Endpoint, error)) *MockStorage_GetEndpointByName_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetEngine provides a mock function with given fields: id
 func (_m *MockStorage) GetEngine(id string) (*v1.Engine, error) {
 	ret := _m.Called(id)
@@ -2972,6 +3282,52 @@ func (_c *MockStorage_ListWorkspace_Call) RunAndReturn(run func(storage.ListOpti
 	return _c
 }
 
+// RestoreCluster provides a mock function with given fields: id
+func (_m *MockStorage) RestoreCluster(id string) error {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RestoreCluster")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockStorage_RestoreCluster_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RestoreCluster'
+type MockStorage_RestoreCluster_Call struct {
+	*mock.Call
+}
+
+// RestoreCluster is a helper method to define mock.On call
+//   - id string
+func (_e *MockStorage_Expecter) RestoreCluster(id interface{}) *MockStorage_RestoreCluster_Call {
+	return &MockStorage_RestoreCluster_Call{Call: _e.mock.On("RestoreCluster", id)}
+}
+
+func (_c *MockStorage_RestoreCluster_Call) Run(run func(id string)) *MockStorage_RestoreCluster_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *MockStorage_RestoreCluster_Call) Return(_a0 error) *MockStorage_RestoreCluster_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockStorage_RestoreCluster_Call) RunAndReturn(run func(string) error) *MockStorage_RestoreCluster_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // UpdateApiKey provides a mock function with given fields: id, data
 func (_m *MockStorage) UpdateApiKey(id string, data *v1.ApiKey) error {
 	ret := _m.Called(id, data)
@@ -3066,17 +3422,24 @@ func (_c *MockStorage_UpdateCluster_Call) RunAndReturn(run func(string, *v1.Clus
 	return _c
 }
 
-// UpdateEndpoint provides a mock function with given fields: id, data
-func (_m *MockStorage) UpdateEndpoint(id string, data *v1.Endpoint) error {
-	ret := _m.Called(id, data)
+// UpdateEndpoint provides a mock function with given fields: id, data, opts
+func (_m *MockStorage) UpdateEndpoint(id string, data *v1.Endpoint, opts ...storage.UpdateOption) error {
+	_va := make([]interface{}, len(opts))
+	for _i := range opts {
+		_va[_i] = opts[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, id, data)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
 
 	if len(ret) == 0 {
 		panic("no return value specified for UpdateEndpoint")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(string, *v1.Endpoint) error); ok {
-		r0 = rf(id, data)
+	if rf, ok := ret.Get(0).(func(string, *v1.Endpoint, ...storage.UpdateOption) error); ok {
+		r0 = rf(id, data, opts...)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -3092,13 +3455,21 @@ type MockStorage_UpdateEndpoint_Call struct {
 // UpdateEndpoint is a helper method to define mock.On call
 //   - id string
 //   - data *v1.Endpoint
-func (_e *MockStorage_Expecter) UpdateEndpoint(id interface{}, data interface{}) *MockStorage_UpdateEndpoint_Call {
-	return &MockStorage_UpdateEndpoint_Call{Call: _e.mock.On("UpdateEndpoint", id, data)}
+//   - opts ...storage.UpdateOption
+func (_e *MockStorage_Expecter) UpdateEndpoint(id interface{}, data interface{}, opts ...interface{}) *MockStorage_UpdateEndpoint_Call {
+	return &MockStorage_UpdateEndpoint_Call{Call: _e.mock.On("UpdateEndpoint",
+		append([]interface{}{id, data}, opts...)...)}
 }
 
-func (_c *MockStorage_UpdateEndpoint_Call) Run(run func(id string, data *v1.Endpoint)) *MockStorage_UpdateEndpoint_Call {
+func (_c *MockStorage_UpdateEndpoint_Call) Run(run func(id string, data *v1.Endpoint, opts ...storage.UpdateOption)) *MockStorage_UpdateEndpoint_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string), args[1].(*v1.Endpoint))
+		variadicArgs := make([]storage.UpdateOption, len(args)-2)
+		for i, a := range args[2:] {
+			if a != nil {
+				variadicArgs[i] = a.(storage.UpdateOption)
+			}
+		}
+		run(args[0].(string), args[1].(*v1.Endpoint), variadicArgs...)
 	})
 	return _c
 }
@@ -3108,7 +3479,7 @@ func (_c *MockStorage_UpdateEndpoint_Call) Return(_a0 error) *MockStorage_Update
 	return _c
 }
 
-func (_c *MockStorage_UpdateEndpoint_Call) RunAndReturn(run func(string, *v1.Endpoint) error) *MockStorage_UpdateEndpoint_Call {
+func (_c *MockStorage_UpdateEndpoint_Call) RunAndReturn(run func(string, *v1.Endpoint, ...storage.UpdateOption) error) *MockStorage_UpdateEndpoint_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -2,7 +2,10 @@ package storage
 
 import (
 	"encoding/json"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	postgrest "github.com/supabase-community/postgrest-go"
@@ -16,6 +19,8 @@ var _ Storage = (*postgrestStorage)(nil)
 
 type postgrestStorage struct {
 	postgrestClient *postgrest.Client
+	// options are used to call PostgREST with another token than the service token.
+	options Options
 }
 
 func (s *postgrestStorage) genericList(table string, response interface{}, option ListOption) error {
@@ -30,6 +35,84 @@ func (s *postgrestStorage) genericList(table string, response interface{}, optio
 	return parseResponse(response, responseContent)
 }
 
+// defaultCreateOption is how the typed Create methods create rows: a row conflicting with an
+// existing one is merged into it, and the data is populated with the created row.
+var defaultCreateOption = CreateOption{Upsert: true, Returning: true}
+
+func (s *postgrestStorage) genericCreate(table string, data interface{}, option CreateOption) error {
+	returning := "minimal"
+	if option.Returning {
+		returning = "representation"
+	}
+
+	responseContent, _, err := s.postgrestClient.From(table).Insert(data, option.Upsert, option.OnConflict, returning, "").Execute()
+	if err != nil {
+		return err
+	}
+
+	if !option.Returning || len(responseContent) == 0 {
+		return nil
+	}
+
+	var rows []json.RawMessage
+	if err = parseResponse(&rows, responseContent); err != nil {
+		return err
+	}
+
+	// an ignored duplicate returns no row
+	if len(rows) == 0 {
+		return nil
+	}
+
+	return parseResponse(data, rows[0])
+}
+
+func (s *postgrestStorage) genericUpdate(table string, id string, data interface{}, option UpdateOption) error {
+	if option.ExpectedVersion == 0 {
+		_, _, err := s.postgrestClient.From(table).Update(data, "", "").Filter("id", "eq", id).Execute()
+		return err
+	}
+
+	responseContent, _, err := s.postgrestClient.From(table).Update(data, "representation", "").
+		Filter("id", "eq", id).
+		Filter(ResourceVersionColumn, "eq", strconv.FormatInt(option.ExpectedVersion, 10)).
+		Execute()
+	if err != nil {
+		return err
+	}
+
+	var rows []json.RawMessage
+	if err = parseResponse(&rows, responseContent); err != nil {
+		return err
+	}
+
+	if len(rows) > 0 {
+		return nil
+	}
+
+	// nothing matched, either the row is gone or it changed since the expected version
+	count, err := s.Count(table, []Filter{{Column: "id", Operator: "eq", Value: id}})
+	if err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return ErrResourceNotFound
+	}
+
+	return ErrConflict
+}
+
+// GenericUpdate updates a row of any table, only at the expected version if the option has one
+func (s *postgrestStorage) GenericUpdate(table string, id string, data interface{}, option UpdateOption) error {
+	return s.genericUpdate(table, id, data, option)
+}
+
+// GenericCreate creates a row in any table as the option asks
+func (s *postgrestStorage) GenericCreate(table string, data interface{}, option CreateOption) error {
+	return s.genericCreate(table, data, option)
+}
+
 // GenericQuery performs a generic query on any table with custom select fields
 func (s *postgrestStorage) GenericQuery(table string, selectFields string, filters []Filter, result interface{}) error {
 	builder := s.postgrestClient.From(table).Select(selectFields, "", false)
@@ -78,7 +161,7 @@ func (s *postgrestStorage) CreateImageRegistry(data *v1.ImageRegistry) error {
 		err error
 	)
 
-	if _, _, err = s.postgrestClient.From(IMAGE_REGISTRY_TABLE).Insert(data, true, "", "", "").Execute(); err != nil {
+	if err = s.genericCreate(IMAGE_REGISTRY_TABLE, data, defaultCreateOption); err != nil {
 		return err
 	}
 
@@ -143,7 +226,7 @@ func (s *postgrestStorage) CreateModelRegistry(data *v1.ModelRegistry) error {
 		err error
 	)
 
-	if _, _, err = s.postgrestClient.From(MODEL_REGISTRY_TABLE).Insert(data, true, "", "", "").Execute(); err != nil {
+	if err = s.genericCreate(MODEL_REGISTRY_TABLE, data, defaultCreateOption); err != nil {
 		return err
 	}
 
@@ -197,6 +280,15 @@ func (s *postgrestStorage) GetModelRegistry(id string) (*v1.ModelRegistry, error
 }
 
 func (s *postgrestStorage) ListCluster(option ListOption) ([]v1.Cluster, error) {
+	if !option.IncludeArchived {
+		// copied so the filters of the caller are not appended to.
+		option.Filters = append(slices.Clone(option.Filters), Filter{
+			Column:   "status->>archive_timestamp",
+			Operator: "is",
+			Value:    "null",
+		})
+	}
+
 	var response []v1.Cluster
 	err := s.genericList(CLUSTERS_TABLE, &response, option)
 
@@ -208,7 +300,7 @@ func (s *postgrestStorage) CreateCluster(data *v1.Cluster) error {
 		err error
 	)
 
-	if _, _, err = s.postgrestClient.From(CLUSTERS_TABLE).Insert(data, true, "", "", "").Execute(); err != nil {
+	if err = s.genericCreate(CLUSTERS_TABLE, data, defaultCreateOption); err != nil {
 		return err
 	}
 
@@ -227,6 +319,53 @@ func (s *postgrestStorage) DeleteCluster(id string) error {
 	return nil
 }
 
+func (s *postgrestStorage) ArchiveCluster(id string) error {
+	cluster, err := s.GetCluster(id)
+	if err != nil {
+		return err
+	}
+
+	if cluster.IsArchived() {
+		return nil
+	}
+
+	// endpoints being deleted still need the cluster to tear down their workload.
+	endpoints, err := s.ListEndpoint(ListOption{
+		Filters: []Filter{
+			{Column: "metadata->>workspace", Operator: "eq", Value: cluster.Metadata.Workspace},
+			{Column: "spec->>cluster", Operator: "eq", Value: cluster.Metadata.Name},
+		},
+		IncludeDeleted: true,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to list endpoints of cluster %s", cluster.Metadata.WorkspaceName())
+	}
+
+	if len(endpoints) > 0 {
+		return errors.Wrapf(ErrResourceInUse, "cluster %s is still referenced by %d endpoint(s)",
+			cluster.Metadata.WorkspaceName(), len(endpoints))
+	}
+
+	// only the archive timestamp and the phase are written, see archive_cluster.
+	return s.CallDatabaseFunction("archive_cluster", map[string]interface{}{
+		"p_id":                cluster.ID,
+		"p_archive_timestamp": time.Now().Format(time.RFC3339Nano),
+	}, nil)
+}
+
+func (s *postgrestStorage) RestoreCluster(id string) error {
+	cluster, err := s.GetCluster(id)
+	if err != nil {
+		return err
+	}
+
+	if !cluster.IsArchived() {
+		return nil
+	}
+
+	return s.CallDatabaseFunction("restore_cluster", map[string]interface{}{"p_id": cluster.ID}, nil)
+}
+
 func (s *postgrestStorage) UpdateCluster(id string, data *v1.Cluster) error {
 	var (
 		err error
@@ -261,6 +400,19 @@ func (s *postgrestStorage) GetCluster(id string) (*v1.Cluster, error) {
 	return &response[0], nil
 }
 
+func (s *postgrestStorage) GetClusterByName(workspace, name string) (*v1.Cluster, error) {
+	var response []v1.Cluster
+	if err := s.genericList(CLUSTERS_TABLE, &response, nameListOption(workspace, name)); err != nil {
+		return nil, err
+	}
+
+	if len(response) == 0 {
+		return nil, ErrResourceNotFound
+	}
+
+	return &response[0], nil
+}
+
 func parseResponse(response interface{}, responseContent []byte) error {
 	if err := json.Unmarshal(responseContent, response); err != nil {
 		return errors.Wrapf(err, "failed to parse response: %v Raw response: %s", err, string(responseContent))
@@ -274,7 +426,7 @@ func (s *postgrestStorage) CreateRole(data *v1.Role) error {
 		err error
 	)
 
-	if _, _, err = s.postgrestClient.From(ROLE_TABLE).Insert(data, true, "", "", "").Execute(); err != nil {
+	if err = s.genericCreate(ROLE_TABLE, data, defaultCreateOption); err != nil {
 		return err
 	}
 
@@ -335,7 +487,7 @@ func (s *postgrestStorage) ListStaticNodeCluster(option ListOption) ([]v1.Static
 }
 
 func (s *postgrestStorage) CreateStaticNodeCluster(data *v1.StaticNodeCluster) error {
-	_, _, err := s.postgrestClient.From(STATIC_NODE_CLUSTER_TABLE).Insert(data, true, "", "", "").Execute()
+	err := s.genericCreate(STATIC_NODE_CLUSTER_TABLE, data, defaultCreateOption)
 
 	return err
 }
@@ -360,7 +512,7 @@ func (s *postgrestStorage) ListStaticNode(option ListOption) ([]v1.StaticNode, e
 }
 
 func (s *postgrestStorage) CreateStaticNode(data *v1.StaticNode) error {
-	_, _, err := s.postgrestClient.From(STATIC_NODE_TABLE).Insert(data, true, "", "", "").Execute()
+	err := s.genericCreate(STATIC_NODE_TABLE, data, defaultCreateOption)
 
 	return err
 }
@@ -389,7 +541,7 @@ func (s *postgrestStorage) CreateRoleAssignment(data *v1.RoleAssignment) error {
 		err error
 	)
 
-	if _, _, err = s.postgrestClient.From(ROLE_ASSIGNMENT_TABLE).Insert(data, true, "", "", "").Execute(); err != nil {
+	if err = s.genericCreate(ROLE_ASSIGNMENT_TABLE, data, defaultCreateOption); err != nil {
 		return err
 	}
 
@@ -454,7 +606,7 @@ func (s *postgrestStorage) CreateWorkspace(data *v1.Workspace) error {
 		err error
 	)
 
-	if _, _, err = s.postgrestClient.From(WORKSPACE_TABLE).Insert(data, true, "", "", "").Execute(); err != nil {
+	if err = s.genericCreate(WORKSPACE_TABLE, data, defaultCreateOption); err != nil {
 		return err
 	}
 
@@ -519,7 +671,7 @@ func (s *postgrestStorage) CreateApiKey(data *v1.ApiKey) error {
 		err error
 	)
 
-	if _, _, err = s.postgrestClient.From(API_KEY_TABLE).Insert(data, true, "", "", "").Execute(); err != nil {
+	if err = s.genericCreate(API_KEY_TABLE, data, defaultCreateOption); err != nil {
 		return err
 	}
 
@@ -585,7 +737,7 @@ func (s *postgrestStorage) CreateEngine(data *v1.Engine) error {
 	)
 
 	// Assuming the table name is "engines"
-	if _, _, err = s.postgrestClient.From(ENGINE_TABLE).Insert(data, true, "", "", "").Execute(); err != nil {
+	if err = s.genericCreate(ENGINE_TABLE, data, defaultCreateOption); err != nil {
 		return err
 	}
 
@@ -654,7 +806,7 @@ func (s *postgrestStorage) CreateEndpoint(data *v1.Endpoint) error {
 		err error
 	)
 
-	if _, _, err = s.postgrestClient.From(ENDPOINT_TABLE).Insert(data, true, "", "", "").Execute(); err != nil {
+	if err = s.genericCreate(ENDPOINT_TABLE, data, defaultCreateOption); err != nil {
 		return err
 	}
 
@@ -673,16 +825,13 @@ func (s *postgrestStorage) DeleteEndpoint(id string) error {
 	return nil
 }
 
-func (s *postgrestStorage) UpdateEndpoint(id string, data *v1.Endpoint) error {
-	var (
-		err error
-	)
-
-	if _, _, err = s.postgrestClient.From(ENDPOINT_TABLE).Update(data, "", "").Filter("id", "eq", id).Execute(); err != nil {
-		return err
+func (s *postgrestStorage) UpdateEndpoint(id string, data *v1.Endpoint, opts ...UpdateOption) error {
+	var option UpdateOption
+	if len(opts) > 0 {
+		option = opts[0]
 	}
 
-	return nil
+	return s.genericUpdate(ENDPOINT_TABLE, id, data, option)
 }
 
 func (s *postgrestStorage) GetEndpoint(id string) (*v1.Endpoint, error) {
@@ -707,6 +856,19 @@ func (s *postgrestStorage) GetEndpoint(id string) (*v1.Endpoint, error) {
 	return &response[0], nil
 }
 
+func (s *postgrestStorage) GetEndpointByName(workspace, name string) (*v1.Endpoint, error) {
+	var response []v1.Endpoint
+	if err := s.genericList(ENDPOINT_TABLE, &response, nameListOption(workspace, name)); err != nil {
+		return nil, err
+	}
+
+	if len(response) == 0 {
+		return nil, ErrResourceNotFound
+	}
+
+	return &response[0], nil
+}
+
 func (s *postgrestStorage) ListEndpoint(option ListOption) ([]v1.Endpoint, error) {
 	var response []v1.Endpoint
 	err := s.genericList(ENDPOINT_TABLE, &response, option)
@@ -715,7 +877,32 @@ func (s *postgrestStorage) ListEndpoint(option ListOption) ([]v1.Endpoint, error
 }
 
 func (s *postgrestStorage) CallDatabaseFunction(method string, params map[string]interface{}, result interface{}) error {
-	resultString, err := s.postgrestClient.RpcWithError(method, "", params)
+	return callDatabaseFunction(s.postgrestClient, method, params, result)
+}
+
+func (s *postgrestStorage) CallDatabaseFunctionAsUser(userID string, method string, params map[string]interface{}, result interface{}) error {
+	if userID == "" {
+		return errors.Errorf("rpc %s requires a user", method)
+	}
+
+	token, err := createUserServiceToken(s.options.JwtSecret, userID)
+	if err != nil {
+		return err
+	}
+
+	client := postgrest.NewClient(s.options.AccessURL, s.options.Scheme, nil).SetAuthToken(token)
+	if client.ClientError != nil {
+		return errors.Wrapf(client.ClientError, "rpc %s failed", method)
+	}
+
+	// Share the retries and the circuit of the service client.
+	client.Transport.Parent = s.postgrestClient.Transport.Parent
+
+	return callDatabaseFunction(client, method, params, result)
+}
+
+func callDatabaseFunction(client *postgrest.Client, method string, params map[string]interface{}, result interface{}) error {
+	resultString, err := client.RpcWithError(method, "", params)
 	if err != nil {
 		return errors.Wrapf(err, "rpc %s failed", method)
 	}
@@ -736,7 +923,7 @@ func (s *postgrestStorage) CreateModelCatalog(data *v1.ModelCatalog) error {
 		err error
 	)
 
-	if _, _, err = s.postgrestClient.From(MODEL_CATALOG_TABLE).Insert(data, true, "", "", "").Execute(); err != nil {
+	if err = s.genericCreate(MODEL_CATALOG_TABLE, data, defaultCreateOption); err != nil {
 		return err
 	}
 
@@ -912,7 +1099,7 @@ func (s *postgrestStorage) CreateUserProfile(data *v1.UserProfile) error {
 		err error
 	)
 
-	if _, _, err = s.postgrestClient.From(USER_PROFILE_TABLE).Insert(data, true, "", "", "").Execute(); err != nil {
+	if err = s.genericCreate(USER_PROFILE_TABLE, data, defaultCreateOption); err != nil {
 		return err
 	}
 
@@ -978,7 +1165,7 @@ func (s *postgrestStorage) CreateExternalEndpoint(data *v1.ExternalEndpoint) err
 		err error
 	)
 
-	if _, _, err = s.postgrestClient.From(EXTERNAL_ENDPOINT_TABLE).Insert(data, true, "", "", "").Execute(); err != nil {
+	if err = s.genericCreate(EXTERNAL_ENDPOINT_TABLE, data, defaultCreateOption); err != nil {
 		return err
 	}
 
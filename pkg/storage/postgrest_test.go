@@ -1,12 +1,20 @@
 package storage
 
 import (
+	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"sync/atomic"
 	"testing"
 
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	v1 "github.com/neutree-ai/neutree/api/v1"
+	"github.com/neutree-ai/neutree/pkg/scheme"
 )
 
 // NEU-447 regression tests.
@@ -111,3 +119,482 @@ func TestCallDatabaseFunction_FailureDoesNotPoisonListQueries(t *testing.T) {
 	require.Equal(t, int32(1), atomic.LoadInt32(&listCalls),
 		"the List must reach the server, not short-circuit on a stale error")
 }
+
+func TestCallDatabaseFunctionAsUser_UserIsTheTokenSubject(t *testing.T) {
+	var claims jwt.MapClaims
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rpc/record_audit_log" {
+			http.NotFound(w, r)
+			return
+		}
+
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		_, err := jwt.ParseWithClaims(token, &claims, func(*jwt.Token) (interface{}, error) {
+			return []byte("test-secret"), nil
+		})
+		assert.NoError(t, err)
+
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`1`))
+	}))
+	defer server.Close()
+
+	s := newTestStorage(t, server.URL)
+
+	var id int64
+	require.NoError(t, s.CallDatabaseFunctionAsUser("user-123", "record_audit_log", map[string]interface{}{}, &id))
+	assert.Equal(t, int64(1), id)
+	assert.Equal(t, "user-123", claims["sub"])
+	assert.Equal(t, "service_role", claims["role"])
+
+	// The calls of the service itself keep the service token.
+	claims = nil
+	require.NoError(t, s.CallDatabaseFunction("record_audit_log", map[string]interface{}{}, nil))
+	assert.NotContains(t, claims, "sub")
+
+	require.Error(t, s.CallDatabaseFunctionAsUser("", "record_audit_log", map[string]interface{}{}, nil))
+}
+
+func TestCreateEndpoint_PopulatesCreatedRow(t *testing.T) {
+	var prefer string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		prefer = r.Header.Get("Prefer")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`[{"id":7,"metadata":{"name":"chat","workspace":"default",` +
+			`"creation_timestamp":"2025-01-01T00:00:00Z"},"spec":{"cluster":"cluster-a"}}]`))
+	}))
+	defer server.Close()
+
+	s := newTestStorage(t, server.URL)
+
+	endpoint := &v1.Endpoint{
+		Metadata: &v1.Metadata{Name: "chat", Workspace: "default"},
+		Spec:     &v1.EndpointSpec{Cluster: "cluster-a"},
+	}
+	require.NoError(t, s.CreateEndpoint(endpoint))
+
+	assert.Equal(t, "resolution=merge-duplicates,return=representation", prefer)
+	assert.Equal(t, 7, endpoint.ID)
+	assert.Equal(t, "2025-01-01T00:00:00Z", endpoint.Metadata.CreationTimestamp)
+}
+
+func TestGenericCreate(t *testing.T) {
+	tests := []struct {
+		name         string
+		option       CreateOption
+		response     string
+		expectPrefer string
+		expectQuery  string
+		expectID     int
+	}{
+		{
+			name:         "insert",
+			expectPrefer: "return=minimal",
+		},
+		{
+			name:         "insert returning the created row",
+			option:       CreateOption{Returning: true},
+			response:     `[{"id":3,"metadata":{"name":"chat"}}]`,
+			expectPrefer: "return=representation",
+			expectID:     3,
+		},
+		{
+			name:         "upsert on a unique column",
+			option:       CreateOption{Upsert: true, OnConflict: "name", Returning: true},
+			response:     `[{"id":5,"metadata":{"name":"chat"}}]`,
+			expectPrefer: "resolution=merge-duplicates,return=representation",
+			expectQuery:  "on_conflict=name",
+			expectID:     5,
+		},
+		{
+			name:         "conflict columns without upsert are ignored",
+			option:       CreateOption{OnConflict: "name"},
+			expectPrefer: "return=minimal",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var prefer, query string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				prefer = r.Header.Get("Prefer")
+				query = r.URL.RawQuery
+				w.WriteHeader(http.StatusCreated)
+				_, _ = w.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			s := newTestStorage(t, server.URL)
+
+			endpoint := &v1.Endpoint{Metadata: &v1.Metadata{Name: "chat"}}
+			require.NoError(t, s.GenericCreate(ENDPOINT_TABLE, endpoint, tt.option))
+
+			assert.Equal(t, tt.expectPrefer, prefer)
+			assert.Equal(t, tt.expectQuery, query)
+			assert.Equal(t, tt.expectID, endpoint.ID)
+		})
+	}
+}
+
+// newSoftDeleteServer serves a live and a soft-deleted endpoint, filtering out the latter when
+// the request filters on the deletion timestamp as PostgREST would.
+func newSoftDeleteServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rows := `[{"id":1,"metadata":{"name":"live"}},{"id":2,"metadata":{"name":"deleting","deletion_timestamp":"2026-10-01T00:00:00Z"}}]`
+		if r.URL.Query().Get("metadata->>deletion_timestamp") == "is.null" {
+			rows = `[{"id":1,"metadata":{"name":"live"}}]`
+		}
+
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(rows))
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestListEndpoint_SoftDeleted(t *testing.T) {
+	tests := []struct {
+		name        string
+		option      ListOption
+		expectNames []string
+	}{
+		{
+			name:        "soft-deleted endpoints excluded by default",
+			option:      ListOption{Filters: []Filter{{Column: "metadata->>workspace", Operator: "eq", Value: "default"}}},
+			expectNames: []string{"live"},
+		},
+		{
+			name:        "soft-deleted endpoints included",
+			option:      ListOption{IncludeDeleted: true},
+			expectNames: []string{"live", "deleting"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestStorage(t, newSoftDeleteServer(t).URL)
+
+			endpoints, err := s.ListEndpoint(tt.option)
+			require.NoError(t, err)
+
+			names := make([]string, 0, len(endpoints))
+			for _, endpoint := range endpoints {
+				names = append(names, endpoint.Metadata.Name)
+			}
+
+			assert.Equal(t, tt.expectNames, names)
+		})
+	}
+}
+
+func TestObjectStorageList_SoftDeleted(t *testing.T) {
+	s := scheme.NewScheme()
+	require.NoError(t, v1.AddToScheme(s))
+
+	objStorage, err := NewObjectStorage(Options{
+		AccessURL: newSoftDeleteServer(t).URL,
+		Scheme:    "public",
+		JwtSecret: "test-secret",
+	}, s)
+	require.NoError(t, err)
+
+	list := &v1.EndpointList{Kind: "EndpointList"}
+	require.NoError(t, objStorage.List(list, ListOption{}))
+	assert.Len(t, list.Items, 1)
+
+	require.NoError(t, objStorage.List(list, ListOption{IncludeDeleted: true}))
+	assert.Len(t, list.Items, 2)
+}
+
+// newVersionedServer serves the endpoint with ID 1 at resource version 2.
+func newVersionedServer(t *testing.T, updated *int32) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		exists := query.Get("id") == "eq.1"
+
+		switch r.Method {
+		case http.MethodPatch:
+			if !exists {
+				_, _ = w.Write([]byte(`[]`))
+				return
+			}
+
+			if version := query.Get("resource_version"); version != "" && version != "eq.2" {
+				_, _ = w.Write([]byte(`[]`))
+				return
+			}
+
+			atomic.AddInt32(updated, 1)
+			_, _ = w.Write([]byte(`[{"id":1,"metadata":{"name":"chat"},"resource_version":3}]`))
+		case http.MethodGet:
+			count := 0
+			if exists {
+				count = 1
+			}
+
+			_, _ = fmt.Fprintf(w, `[{"count":%d}]`, count)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestUpdateEndpoint_ExpectedVersion(t *testing.T) {
+	tests := []struct {
+		name          string
+		id            string
+		opts          []UpdateOption
+		expectError   error
+		expectUpdated int32
+	}{
+		{
+			name:          "no expected version",
+			id:            "1",
+			expectUpdated: 1,
+		},
+		{
+			name:          "version matches",
+			id:            "1",
+			opts:          []UpdateOption{{ExpectedVersion: 2}},
+			expectUpdated: 1,
+		},
+		{
+			name:        "version mismatches",
+			id:          "1",
+			opts:        []UpdateOption{{ExpectedVersion: 1}},
+			expectError: ErrConflict,
+		},
+		{
+			name:        "endpoint not found",
+			id:          "2",
+			opts:        []UpdateOption{{ExpectedVersion: 2}},
+			expectError: ErrResourceNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var updated int32
+
+			s := newTestStorage(t, newVersionedServer(t, &updated).URL)
+
+			err := s.UpdateEndpoint(tt.id, &v1.Endpoint{Metadata: &v1.Metadata{Name: "chat"}}, tt.opts...)
+			if tt.expectError != nil {
+				assert.ErrorIs(t, err, tt.expectError)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Equal(t, tt.expectUpdated, atomic.LoadInt32(&updated))
+		})
+	}
+}
+
+func TestGenericUpdate_ExpectedVersion(t *testing.T) {
+	var updated int32
+
+	s := newTestStorage(t, newVersionedServer(t, &updated).URL)
+	cluster := &v1.Cluster{Metadata: &v1.Metadata{Name: "cluster-a"}}
+
+	require.NoError(t, s.GenericUpdate(CLUSTERS_TABLE, "1", cluster, UpdateOption{ExpectedVersion: 2}))
+	assert.ErrorIs(t, s.GenericUpdate(CLUSTERS_TABLE, "1", cluster, UpdateOption{ExpectedVersion: 1}), ErrConflict)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&updated))
+}
+
+func TestGetByName(t *testing.T) {
+	var queries []string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.Path+"?"+r.URL.RawQuery)
+		w.Header().Set("Content-Type", "application/json")
+
+		name := strings.TrimPrefix(r.URL.Query().Get("metadata->name"), "eq.")
+		if name == `"missing"` {
+			_, _ = w.Write([]byte(`[]`))
+			return
+		}
+
+		_, _ = fmt.Fprintf(w, `[{"id":1,"metadata":{"name":%s,"workspace":"default","deletion_timestamp":"2026-01-01T00:00:00Z"}}]`, name)
+	}))
+	defer srv.Close()
+
+	s := newTestStorage(t, srv.URL)
+
+	endpoint, err := s.GetEndpointByName("default", "chat")
+	require.NoError(t, err)
+	assert.Equal(t, "chat", endpoint.Metadata.Name)
+
+	cluster, err := s.GetClusterByName("default", "k8s")
+	require.NoError(t, err)
+	assert.Equal(t, "k8s", cluster.Metadata.Name)
+
+	_, err = s.GetEndpointByName("default", "missing")
+	assert.ErrorIs(t, err, ErrResourceNotFound)
+
+	// The rows are filtered by PostgREST, soft-deleted ones included.
+	require.Len(t, queries, 3)
+	assert.Contains(t, queries[0], "/endpoints?")
+	assert.Contains(t, queries[1], "/clusters?")
+
+	for _, query := range queries {
+		assert.Contains(t, query, "metadata-%3Eworkspace=eq.%22default%22")
+		assert.NotContains(t, query, "deletion_timestamp")
+	}
+}
+
+func TestListCluster_Archived(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rows := `[{"id":1,"metadata":{"name":"live"}},{"id":2,"metadata":{"name":"archived"},"status":{"phase":"Archived","archive_timestamp":"2026-10-01T00:00:00Z"}}]`
+		if r.URL.Query().Get("status->>archive_timestamp") == "is.null" {
+			rows = `[{"id":1,"metadata":{"name":"live"}}]`
+		}
+
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(rows))
+	}))
+	defer server.Close()
+
+	s := newTestStorage(t, server.URL)
+
+	filters := []Filter{{Column: "metadata->>workspace", Operator: "eq", Value: "default"}}
+
+	clusters, err := s.ListCluster(ListOption{Filters: filters})
+	require.NoError(t, err)
+	require.Len(t, clusters, 1)
+	assert.Equal(t, "live", clusters[0].Metadata.Name)
+	assert.Len(t, filters, 1, "the filters of the caller are left as they are")
+
+	clusters, err = s.ListCluster(ListOption{Filters: filters, IncludeArchived: true})
+	require.NoError(t, err)
+	assert.Len(t, clusters, 2)
+}
+
+func TestArchiveCluster(t *testing.T) {
+	tests := []struct {
+		name         string
+		row          string
+		endpoints    string
+		expectUpdate bool
+		expectInUse  bool
+	}{
+		{
+			name:         "running cluster",
+			row:          `{"id":1,"metadata":{"name":"cluster","workspace":"default"},"status":{"phase":"Running","ready_nodes":2}}`,
+			endpoints:    `[]`,
+			expectUpdate: true,
+		},
+		{
+			name: "already archived cluster",
+			row:  `{"id":1,"metadata":{"name":"cluster","workspace":"default"},"status":{"phase":"Archived","archive_timestamp":"2026-10-01T00:00:00Z"}}`,
+		},
+		{
+			name:        "cluster referenced by endpoints",
+			row:         `{"id":1,"metadata":{"name":"cluster","workspace":"default"},"status":{"phase":"Running"}}`,
+			endpoints:   `[{"id":7,"metadata":{"name":"chat","workspace":"default"},"spec":{"cluster":"cluster"}}]`,
+			expectInUse: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var params map[string]interface{}
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case strings.HasSuffix(r.URL.Path, "/"+ENDPOINT_TABLE):
+					assert.Equal(t, "eq.default", r.URL.Query().Get("metadata->>workspace"))
+					assert.Equal(t, "eq.cluster", r.URL.Query().Get("spec->>cluster"))
+					_, _ = w.Write([]byte(tt.endpoints))
+				case strings.HasSuffix(r.URL.Path, "/rpc/archive_cluster"):
+					assert.Equal(t, http.MethodPost, r.Method)
+					assert.NoError(t, json.NewDecoder(r.Body).Decode(&params))
+					w.WriteHeader(http.StatusNoContent)
+				case r.Method == http.MethodGet:
+					assert.Equal(t, "eq.1", r.URL.Query().Get("id"))
+					_, _ = w.Write([]byte("[" + tt.row + "]"))
+				default:
+					t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+				}
+			}))
+			defer server.Close()
+
+			s := newTestStorage(t, server.URL)
+
+			err := s.ArchiveCluster("1")
+			if tt.expectInUse {
+				assert.ErrorIs(t, err, ErrResourceInUse)
+				assert.Nil(t, params)
+
+				return
+			}
+
+			require.NoError(t, err)
+
+			if !tt.expectUpdate {
+				assert.Nil(t, params)
+				return
+			}
+
+			// only the archive timestamp and the phase are written, not the whole status.
+			require.NotNil(t, params)
+			assert.Equal(t, float64(1), params["p_id"])
+			assert.NotEmpty(t, params["p_archive_timestamp"])
+		})
+	}
+}
+
+func TestRestoreCluster(t *testing.T) {
+	tests := []struct {
+		name          string
+		row           string
+		expectRestore bool
+	}{
+		{
+			name:          "archived cluster",
+			row:           `{"id":1,"metadata":{"name":"cluster","workspace":"default"},"status":{"phase":"Archived","archive_timestamp":"2026-10-01T00:00:00Z"}}`,
+			expectRestore: true,
+		},
+		{
+			name: "cluster not archived",
+			row:  `{"id":1,"metadata":{"name":"cluster","workspace":"default"},"status":{"phase":"Running"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var params map[string]interface{}
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case strings.HasSuffix(r.URL.Path, "/rpc/restore_cluster"):
+					assert.NoError(t, json.NewDecoder(r.Body).Decode(&params))
+					w.WriteHeader(http.StatusNoContent)
+				case r.Method == http.MethodGet:
+					assert.Equal(t, "eq.1", r.URL.Query().Get("id"))
+					_, _ = w.Write([]byte("[" + tt.row + "]"))
+				default:
+					t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+				}
+			}))
+			defer server.Close()
+
+			s := newTestStorage(t, server.URL)
+
+			require.NoError(t, s.RestoreCluster("1"))
+
+			if !tt.expectRestore {
+				assert.Nil(t, params)
+				return
+			}
+
+			assert.Equal(t, map[string]interface{}{"p_id": float64(1)}, params)
+		})
+	}
+}
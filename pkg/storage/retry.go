@@ -0,0 +1,144 @@
+package storage
+
+import (
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// RetryPolicy configures how the idempotent requests to PostgREST (GET, HEAD) are retried when
+// they fail with a network error or a 5xx response, e.g. while PostgREST restarts. Writes and
+// 4xx responses are never retried.
+type RetryPolicy struct {
+	// MaxAttempts is the number of times a request is sent, retries are disabled below 2.
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry, doubled before each next one.
+	BaseDelay time.Duration
+	// Jitter is the fraction of each delay added at random, from 0 to 1.
+	Jitter float64
+	// Budget is the number of retries allowed per request, e.g. 0.2 allows one retry for every
+	// five requests, so a sustained outage is not hit with MaxAttempts times the requests. A
+	// burst of up to retryBudgetBurst retries is allowed. Retries are not limited when 0.
+	Budget float64
+}
+
+// retryBudgetBurst is the number of retries a retry budget holds at most.
+const retryBudgetBurst = 10
+
+// DefaultRetryPolicy returns the retry policy of the neutree components.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 4,
+		BaseDelay:   200 * time.Millisecond,
+		Jitter:      0.2,
+		Budget:      0.2,
+	}
+}
+
+func (p RetryPolicy) enabled() bool {
+	return p.MaxAttempts > 1
+}
+
+// delay returns the delay before the given retry, starting at 1.
+func (p RetryPolicy) delay(retry int) time.Duration {
+	d := p.BaseDelay << (retry - 1)
+	if p.Jitter > 0 {
+		d += time.Duration(rand.Float64() * p.Jitter * float64(d)) //nolint:gosec
+	}
+
+	return d
+}
+
+// retryBudget is a token bucket earning policy.Budget retries with every request.
+type retryBudget struct {
+	mu     sync.Mutex
+	tokens float64
+}
+
+func newRetryBudget() *retryBudget {
+	return &retryBudget{tokens: retryBudgetBurst}
+}
+
+func (b *retryBudget) deposit(ratio float64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.tokens = min(b.tokens+ratio, retryBudgetBurst)
+}
+
+func (b *retryBudget) withdraw() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.tokens < 1 {
+		return false
+	}
+
+	b.tokens--
+
+	return true
+}
+
+// retryTransport retries the idempotent requests sent through next following policy.
+type retryTransport struct {
+	policy RetryPolicy
+	next   http.RoundTripper
+	// budget limits the retries when policy.Budget is set.
+	budget *retryBudget
+}
+
+func newRetryTransport(policy RetryPolicy, next http.RoundTripper) *retryTransport {
+	t := &retryTransport{policy: policy, next: next}
+	if policy.Budget > 0 {
+		t.budget = newRetryBudget()
+	}
+
+	return t
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return t.next.RoundTrip(req)
+	}
+
+	if t.budget != nil {
+		t.budget.deposit(t.policy.Budget)
+	}
+
+	for attempt := 1; ; attempt++ {
+		resp, err := t.next.RoundTrip(req)
+		if attempt >= t.policy.MaxAttempts || !shouldRetry(resp, err) {
+			return resp, err
+		}
+
+		if t.budget != nil && !t.budget.withdraw() {
+			klog.V(4).Infof("Storage retry budget exhausted, not retrying %s %s", req.Method, req.URL.Path)
+			return resp, err
+		}
+
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			_ = resp.Body.Close()
+		}
+
+		timer := time.NewTimer(t.policy.delay(attempt))
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func shouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+
+	return resp.StatusCode >= http.StatusInternalServerError
+}
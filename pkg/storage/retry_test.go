@@ -0,0 +1,141 @@
+package storage
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStorage_RetriesIdempotentRequests(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       []int
+		call         func(s Storage) error
+		expectErr    bool
+		expectCalled int32
+	}{
+		{
+			name:   "list retried on 5xx",
+			status: []int{http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusOK},
+			call: func(s Storage) error {
+				_, err := s.ListCluster(ListOption{})
+				return err
+			},
+			expectCalled: 3,
+		},
+		{
+			name:   "list fails after max attempts",
+			status: []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable},
+			call: func(s Storage) error {
+				_, err := s.ListCluster(ListOption{})
+				return err
+			},
+			expectErr:    true,
+			expectCalled: 3,
+		},
+		{
+			name:   "list not retried on 4xx",
+			status: []int{http.StatusBadRequest},
+			call: func(s Storage) error {
+				_, err := s.ListCluster(ListOption{})
+				return err
+			},
+			expectErr:    true,
+			expectCalled: 1,
+		},
+		{
+			name:   "delete not retried on 5xx",
+			status: []int{http.StatusServiceUnavailable},
+			call: func(s Storage) error {
+				return s.DeleteCluster("1")
+			},
+			expectErr:    true,
+			expectCalled: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var called atomic.Int32
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := called.Add(1)
+				w.Header().Set("Content-Type", "application/json")
+
+				status := tt.status[min(int(n), len(tt.status))-1]
+				w.WriteHeader(status)
+
+				if status == http.StatusOK {
+					_, _ = w.Write([]byte(`[]`))
+					return
+				}
+
+				_, _ = w.Write([]byte(`{"message":"unavailable"}`))
+			}))
+			defer srv.Close()
+
+			s, err := New(Options{
+				AccessURL: srv.URL,
+				Scheme:    "public",
+				JwtSecret: "test-secret",
+				Retry:     RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond},
+			})
+			require.NoError(t, err)
+
+			err = tt.call(s)
+			if tt.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Equal(t, tt.expectCalled, called.Load())
+		})
+	}
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	p := RetryPolicy{MaxAttempts: 4, BaseDelay: 100 * time.Millisecond}
+	assert.Equal(t, 100*time.Millisecond, p.delay(1))
+	assert.Equal(t, 200*time.Millisecond, p.delay(2))
+	assert.Equal(t, 400*time.Millisecond, p.delay(3))
+
+	p.Jitter = 0.5
+	for i := 0; i < 10; i++ {
+		d := p.delay(2)
+		assert.GreaterOrEqual(t, d, 200*time.Millisecond)
+		assert.LessOrEqual(t, d, 300*time.Millisecond)
+	}
+}
+
+func TestRetryBudget(t *testing.T) {
+	var called atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	s, err := New(Options{
+		AccessURL: srv.URL,
+		Scheme:    "public",
+		JwtSecret: "test-secret",
+		Retry:     RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, Budget: 0.5},
+	})
+	require.NoError(t, err)
+
+	// The burst is spent by the first lists, the next lists earn a retry every other list.
+	for i := 0; i < 20; i++ {
+		_, err := s.ListCluster(ListOption{})
+		require.Error(t, err)
+	}
+
+	// 20 lists and 19 of the 40 retries they failed with, the last half retry is left.
+	assert.Equal(t, int32(20+19), called.Load())
+}
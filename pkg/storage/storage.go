@@ -1,9 +1,15 @@
 package storage
 
 import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/pkg/errors"
 	"github.com/supabase-community/postgrest-go"
+	"k8s.io/klog/v2"
 
 	"github.com/neutree-ai/neutree/pkg/scheme"
 
@@ -12,8 +18,18 @@ import (
 
 var (
 	ErrResourceNotFound = errors.New("resource not found")
+	// ErrSchemaNotFound means PostgREST does not serve the neutree tables from the configured schema.
+	ErrSchemaNotFound = errors.New("storage schema not found")
+	// ErrConflict means the stored row changed since the version an update expected, the
+	// writer has to read it again before updating it.
+	ErrConflict = errors.New("resource version conflict")
+	// ErrResourceInUse means the resource is still referenced by other resources.
+	ErrResourceInUse = errors.New("resource in use")
 )
 
+// DefaultSchema is the PostgREST schema the neutree tables are created in.
+const DefaultSchema = "api"
+
 const (
 	ENDPOINT_TABLE            = "endpoints"
 	ENGINE_TABLE              = "engines"
@@ -62,11 +78,20 @@ type ClusterStorage interface {
 	CreateCluster(data *v1.Cluster) error
 	// DeleteCluster deletes a cluster by its ID.
 	DeleteCluster(id string) error
+	// ArchiveCluster archives a cluster by its ID instead of deleting it: the row is kept with
+	// an archive timestamp and the Archived phase in its status. ErrResourceInUse is returned
+	// while endpoints still reference the cluster.
+	ArchiveCluster(id string) error
+	// RestoreCluster restores an archived cluster by its ID, it is reconciled again.
+	RestoreCluster(id string) error
 	// UpdateCluster updates an existing cluster in the database.
 	UpdateCluster(id string, data *v1.Cluster) error
 	// GetCluster retrieves a cluster by its ID.
 	GetCluster(id string) (*v1.Cluster, error)
-	// ListCluster retrieves a list of clusters with optional filters.
+	// GetClusterByName retrieves a cluster by its workspace and name, soft-deleted or not.
+	GetClusterByName(workspace, name string) (*v1.Cluster, error)
+	// ListCluster retrieves a list of clusters with optional filters, archived clusters are
+	// only listed with IncludeArchived.
 	ListCluster(option ListOption) ([]v1.Cluster, error)
 }
 
@@ -140,10 +165,13 @@ type EndpointStorage interface {
 	CreateEndpoint(data *v1.Endpoint) error
 	// DeleteEndpoint deletes a endpoint by its ID.
 	DeleteEndpoint(id string) error
-	// UpdateEndpoint updates an existing endpoint in the database.
-	UpdateEndpoint(id string, data *v1.Endpoint) error
+	// UpdateEndpoint updates an existing endpoint in the database. With an expected version,
+	// it fails with ErrConflict if the stored endpoint changed since.
+	UpdateEndpoint(id string, data *v1.Endpoint, opts ...UpdateOption) error
 	// GetEndpoint retrieves a endpoint by its ID.
 	GetEndpoint(id string) (*v1.Endpoint, error)
+	// GetEndpointByName retrieves an endpoint by its workspace and name, soft-deleted or not.
+	GetEndpointByName(workspace, name string) (*v1.Endpoint, error)
 	// ListEndpoint retrieves a list of endpoint with optional filters.
 	ListEndpoint(option ListOption) ([]v1.Endpoint, error)
 }
@@ -224,6 +252,19 @@ type Storage interface {
 	// CallDatabaseFunction calls a database function with the given name and parameters.
 	CallDatabaseFunction(name string, params map[string]interface{}, result interface{}) error
 
+	// CallDatabaseFunctionAsUser calls a database function on behalf of a user. The call has the
+	// service role with the user as the JWT subject, so the function reads the user from
+	// auth.uid() instead of trusting a parameter of the caller.
+	CallDatabaseFunctionAsUser(userID string, name string, params map[string]interface{}, result interface{}) error
+
+	// GenericCreate creates a row in any table. The option chooses how a row conflicting
+	// with an existing one is handled and whether data is populated with the created row.
+	GenericCreate(table string, data interface{}, option CreateOption) error
+
+	// GenericUpdate updates a row of any table by its ID. With an expected version, it fails
+	// with ErrConflict if the stored row changed since.
+	GenericUpdate(table string, id string, data interface{}, option UpdateOption) error
+
 	// GenericQuery performs a generic query on any table with custom select fields
 	// Use this for internal operations that need service_role permissions
 	GenericQuery(table string, selectFields string, filters []Filter, result interface{}) error
@@ -234,8 +275,17 @@ type Storage interface {
 
 type Options struct {
 	AccessURL string
+	// Scheme is the PostgREST schema used for all requests, sent as Accept-Profile/Content-Profile.
 	Scheme    string
 	JwtSecret string
+	// Retry is the retry policy of the idempotent requests, they are not retried by default.
+	Retry RetryPolicy
+	// CircuitBreaker stops sending requests while PostgREST keeps failing, it is disabled by
+	// default.
+	CircuitBreaker CircuitBreakerPolicy
+	// CacheTTL is how long the endpoint and cluster lists are served from memory, they are
+	// not cached when 0.
+	CacheTTL time.Duration
 }
 
 func CreateServiceToken(jwtSecret string) (*string, error) {
@@ -251,7 +301,22 @@ func CreateServiceToken(jwtSecret string) (*string, error) {
 	return &jwtAutoToken, nil
 }
 
-func New(o Options) (Storage, error) {
+// createUserServiceToken returns a service role token with the user as subject.
+func createUserServiceToken(jwtSecret, userID string) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"role": "service_role",
+		"sub":  userID,
+	})
+
+	tokenString, err := token.SignedString([]byte(jwtSecret))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to generate jwt token")
+	}
+
+	return tokenString, nil
+}
+
+func newPostgrestClient(o Options) (*postgrest.Client, error) {
 	jwtAutoToken, err := CreateServiceToken(o.JwtSecret)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to init storage")
@@ -262,8 +327,34 @@ func New(o Options) (Storage, error) {
 		return nil, errors.Wrap(postgrestClient.ClientError, "failed to init storage")
 	}
 
+	var transport http.RoundTripper = http.DefaultTransport
+	if o.Retry.enabled() {
+		transport = newRetryTransport(o.Retry, transport)
+	}
+
+	// The circuit sees a request once its retries are over.
+	if o.CircuitBreaker.enabled() {
+		transport = newCircuitTransport(o.CircuitBreaker, transport)
+	}
+
+	postgrestClient.Transport.Parent = transport
+
+	return postgrestClient, nil
+}
+
+func New(o Options) (Storage, error) {
+	postgrestClient, err := newPostgrestClient(o)
+	if err != nil {
+		return nil, err
+	}
+
 	s := &postgrestStorage{
 		postgrestClient: postgrestClient,
+		options:         o,
+	}
+
+	if o.CacheTTL > 0 {
+		return newCachedStorage(s, o.CacheTTL), nil
 	}
 
 	return s, nil
@@ -276,9 +367,64 @@ type Filter struct {
 }
 type ListOption struct {
 	Filters []Filter
+	// IncludeDeleted also lists the soft-deleted rows, i.e. the ones with a deletion timestamp,
+	// which are left out by default. Controllers finishing the deletion of a resource and the
+	// lookups of resources that are still in use while being deleted set it.
+	IncludeDeleted bool
+	// IncludeArchived also lists the archived clusters, which are left out by default. It only
+	// applies to ListCluster.
+	IncludeArchived bool
+}
+
+// CreateOption controls how a row is created, it is sent as the PostgREST Prefer header.
+type CreateOption struct {
+	// Upsert merges the row into the existing row it conflicts with instead of failing
+	// (resolution=merge-duplicates).
+	Upsert bool
+	// OnConflict lists the comma separated unique columns an upsert conflicts on, the
+	// primary key by default.
+	OnConflict string
+	// Returning populates the data with the created row, including the values generated by
+	// the database such as the ID and timestamps (return=representation).
+	Returning bool
+}
+
+// ResourceVersionColumn is the version of the spec of a resource row, bumped by the database
+// on every spec change but not on status writes.
+const ResourceVersionColumn = "resource_version"
+
+// UpdateOption controls how a row is updated.
+type UpdateOption struct {
+	// ExpectedVersion is the resource version the writer read the row at. The row is only
+	// updated if it still has it, so concurrent writers do not clobber each other. Zero
+	// updates the row whatever its version.
+	ExpectedVersion int64
+}
+
+// nameListOption lists the row of the given workspace and name, soft-deleted or not.
+func nameListOption(workspace, name string) ListOption {
+	return ListOption{
+		Filters: []Filter{
+			{
+				Column:   "metadata->name",
+				Operator: "eq",
+				Value:    strconv.Quote(name),
+			},
+			{
+				Column:   "metadata->workspace",
+				Operator: "eq",
+				Value:    strconv.Quote(workspace),
+			},
+		},
+		IncludeDeleted: true,
+	}
 }
 
 func applyListOption(builder *postgrest.FilterBuilder, option ListOption) {
+	if !option.IncludeDeleted {
+		builder.Filter("metadata->>deletion_timestamp", "is", "null")
+	}
+
 	for _, filter := range option.Filters {
 		builder.Filter(filter.Column, filter.Operator, filter.Value)
 	}
@@ -301,14 +447,9 @@ type ObjectStorage interface {
 }
 
 func NewObjectStorage(o Options, s *scheme.Scheme) (ObjectStorage, error) {
-	jwtAutoToken, err := CreateServiceToken(o.JwtSecret)
+	postgrestClient, err := newPostgrestClient(o)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to init storage")
-	}
-
-	postgrestClient := postgrest.NewClient(o.AccessURL, o.Scheme, nil).SetAuthToken(*jwtAutoToken)
-	if postgrestClient.ClientError != nil {
-		return nil, errors.Wrap(postgrestClient.ClientError, "failed to init storage")
+		return nil, err
 	}
 
 	return &postgrestObjectStorage{
@@ -316,3 +457,45 @@ func NewObjectStorage(o Options, s *scheme.Scheme) (ObjectStorage, error) {
 		scheme:          s,
 	}, nil
 }
+
+// ValidateSchema checks that PostgREST exposes the configured schema and that it holds the
+// neutree tables. It returns ErrSchemaNotFound for a misconfigured schema, other errors
+// (e.g. PostgREST not reachable yet) are returned as is.
+func ValidateSchema(o Options) error {
+	postgrestClient, err := newPostgrestClient(o)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = postgrestClient.From(WORKSPACE_TABLE).Select("id", "", false).Limit(1, "").Execute()
+	if err == nil {
+		return nil
+	}
+
+	// PGRST106: schema not exposed, PGRST205/42P01: table missing in the schema.
+	for _, code := range []string{"PGRST106", "PGRST205", "42P01"} {
+		if strings.Contains(err.Error(), "("+code+")") {
+			return errors.Wrapf(ErrSchemaNotFound, "schema %q: %v", o.Scheme, err)
+		}
+	}
+
+	return errors.Wrapf(err, "failed to validate schema %q", o.Scheme)
+}
+
+// Validate fails if PostgREST does not serve the neutree tables from the configured schema, so
+// a misconfigured service does not start. An unreachable PostgREST is only logged, it may still
+// be starting.
+func (o Options) Validate() error {
+	err := ValidateSchema(o)
+	if err == nil {
+		return nil
+	}
+
+	if errors.Is(err, ErrSchemaNotFound) {
+		return errors.Wrap(err, "invalid storage schema")
+	}
+
+	klog.Warningf("Skip validating storage schema %s: %v", o.Scheme, err)
+
+	return nil
+}
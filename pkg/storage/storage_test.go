@@ -0,0 +1,162 @@
+package storage
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew_CustomSchema(t *testing.T) {
+	var paths, acceptProfiles, contentProfiles []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		acceptProfiles = append(acceptProfiles, r.Header.Get("Accept-Profile"))
+		contentProfiles = append(contentProfiles, r.Header.Get("Content-Profile"))
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	s, err := New(Options{
+		AccessURL: server.URL,
+		Scheme:    "tenant_a",
+		JwtSecret: "test-secret",
+	})
+	require.NoError(t, err)
+
+	_, err = s.ListWorkspace(ListOption{})
+	require.NoError(t, err)
+	require.NoError(t, s.CallDatabaseFunction("sync_api_key_usage", map[string]interface{}{}, nil))
+
+	assert.Equal(t, []string{"/" + WORKSPACE_TABLE, "/rpc/sync_api_key_usage"}, paths)
+	assert.Equal(t, []string{"tenant_a", "tenant_a"}, acceptProfiles)
+	assert.Equal(t, []string{"tenant_a", "tenant_a"}, contentProfiles)
+}
+
+func TestValidateSchema(t *testing.T) {
+	tests := []struct {
+		name            string
+		status          int
+		body            string
+		wantErr         bool
+		wantNotFound    bool
+		closedPostgREST bool
+	}{
+		{
+			name:   "schema exists",
+			status: http.StatusOK,
+			body:   `[]`,
+		},
+		{
+			name:         "schema not exposed",
+			status:       http.StatusNotAcceptable,
+			body:         `{"code":"PGRST106","message":"The schema must be one of the following: api"}`,
+			wantErr:      true,
+			wantNotFound: true,
+		},
+		{
+			name:         "tables missing in schema",
+			status:       http.StatusNotFound,
+			body:         `{"code":"PGRST205","message":"Could not find the table 'tenant_a.workspaces' in the schema cache"}`,
+			wantErr:      true,
+			wantNotFound: true,
+		},
+		{
+			name:    "other postgrest error",
+			status:  http.StatusInternalServerError,
+			body:    `{"code":"XX000","message":"internal error"}`,
+			wantErr: true,
+		},
+		{
+			name:            "postgrest unreachable",
+			closedPostgREST: true,
+			wantErr:         true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var acceptProfile string
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				acceptProfile = r.Header.Get("Accept-Profile")
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			if tt.closedPostgREST {
+				server.Close()
+			} else {
+				defer server.Close()
+			}
+
+			err := ValidateSchema(Options{
+				AccessURL: server.URL,
+				Scheme:    "tenant_a",
+				JwtSecret: "test-secret",
+			})
+
+			if !tt.wantErr {
+				assert.NoError(t, err)
+				assert.Equal(t, "tenant_a", acceptProfile)
+
+				return
+			}
+
+			assert.Error(t, err)
+			assert.Equal(t, tt.wantNotFound, errors.Is(err, ErrSchemaNotFound))
+		})
+	}
+}
+
+func TestOptionsValidate(t *testing.T) {
+	tests := []struct {
+		name            string
+		status          int
+		body            string
+		closedPostgREST bool
+		wantErr         bool
+	}{
+		{
+			name:   "schema exists",
+			status: http.StatusOK,
+			body:   `[]`,
+		},
+		{
+			name:    "schema not exposed",
+			status:  http.StatusNotAcceptable,
+			body:    `{"code":"PGRST106","message":"The schema must be one of the following: api"}`,
+			wantErr: true,
+		},
+		{
+			name:            "postgrest unreachable",
+			closedPostgREST: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			if tt.closedPostgREST {
+				server.Close()
+			} else {
+				defer server.Close()
+			}
+
+			err := Options{AccessURL: server.URL, Scheme: "tenant_a", JwtSecret: "test-secret"}.Validate()
+
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrSchemaNotFound)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
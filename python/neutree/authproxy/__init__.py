@@ -0,0 +1,15 @@
+"""neutree.authproxy
+
+Auth sidecar of the endpoints on Kubernetes clusters where neutree does not front
+the endpoint traffic. It authenticates the requests with the API key validation
+endpoint of neutree before they reach the engine.
+Run as module: python -m neutree.authproxy
+"""
+
+from .proxy import AuthProxyConfig, KeyValidator, serve
+
+__all__ = [
+    "AuthProxyConfig",
+    "KeyValidator",
+    "serve",
+]
@@ -0,0 +1,20 @@
+"""CLI entrypoint of the auth sidecar, configured by the NEUTREE_AUTH_* environment variables."""
+import logging
+import sys
+
+from .proxy import AuthProxyConfig, serve
+
+
+def main():
+    logging.basicConfig(level=logging.INFO, format="%(asctime)s %(levelname)s %(message)s")
+    try:
+        config = AuthProxyConfig.from_env()
+    except ValueError as e:
+        logging.error("invalid auth sidecar config: %s", e)
+        return 2
+    serve(config)
+    return 0
+
+
+if __name__ == "__main__":
+    sys.exit(main())
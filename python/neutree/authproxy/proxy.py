@@ -0,0 +1,229 @@
+"""Reverse proxy forwarding the requests with a valid API key to the engine.
+
+Each request is authenticated by sending a GET request with its Authorization
+header to NEUTREE_AUTH_VALIDATE_URL, the request is forwarded to
+NEUTREE_AUTH_UPSTREAM when the validation answers with a 2xx status and rejected
+with 401 otherwise. Validation results are cached for NEUTREE_AUTH_CACHE_TTL
+seconds, so a client streaming many requests does not validate each of them.
+The paths of NEUTREE_AUTH_PUBLIC_PATHS, the engine health check and metrics,
+are forwarded without authentication for the kubelet probes and the metrics
+scraper. Responses are streamed, so server-sent events reach the client as the
+engine sends them.
+"""
+import hashlib
+import http.client
+import logging
+import os
+import threading
+import time
+import urllib.error
+import urllib.parse
+import urllib.request
+from dataclasses import dataclass, field
+from http.server import BaseHTTPRequestHandler, ThreadingHTTPServer
+from typing import Callable, Dict, Optional, Tuple
+
+logger = logging.getLogger(__name__)
+
+DEFAULT_LISTEN_PORT = 8000
+DEFAULT_PUBLIC_PATHS = ("/health", "/metrics")
+DEFAULT_CACHE_TTL_SECONDS = 30.0
+VALIDATE_TIMEOUT_SECONDS = 10.0
+STREAM_CHUNK_SIZE = 64 * 1024
+
+# Hop-by-hop headers are not forwarded, see RFC 9110 section 7.6.1.
+HOP_BY_HOP_HEADERS = frozenset((
+    "connection", "keep-alive", "proxy-authenticate", "proxy-authorization",
+    "te", "trailer", "transfer-encoding", "upgrade",
+))
+
+
+class ValidationUnavailable(Exception):
+    """The validation endpoint could not tell whether the key is valid."""
+
+
+@dataclass
+class AuthProxyConfig:
+    upstream: str
+    validate_url: str
+    listen_port: int = DEFAULT_LISTEN_PORT
+    public_paths: Tuple[str, ...] = DEFAULT_PUBLIC_PATHS
+    cache_ttl: float = DEFAULT_CACHE_TTL_SECONDS
+
+    @classmethod
+    def from_env(cls, env: Optional[Dict[str, str]] = None) -> "AuthProxyConfig":
+        env = os.environ if env is None else env
+        upstream = env.get("NEUTREE_AUTH_UPSTREAM", "").strip()
+        validate_url = env.get("NEUTREE_AUTH_VALIDATE_URL", "").strip()
+        if not upstream:
+            raise ValueError("NEUTREE_AUTH_UPSTREAM is required")
+        if not validate_url:
+            raise ValueError("NEUTREE_AUTH_VALIDATE_URL is required")
+
+        public_paths = DEFAULT_PUBLIC_PATHS
+        if env.get("NEUTREE_AUTH_PUBLIC_PATHS"):
+            public_paths = tuple(p.strip() for p in env["NEUTREE_AUTH_PUBLIC_PATHS"].split(",") if p.strip())
+
+        return cls(
+            upstream=upstream,
+            validate_url=validate_url,
+            listen_port=int(env.get("NEUTREE_AUTH_LISTEN_PORT") or DEFAULT_LISTEN_PORT),
+            public_paths=public_paths,
+            cache_ttl=float(env.get("NEUTREE_AUTH_CACHE_TTL") or DEFAULT_CACHE_TTL_SECONDS),
+        )
+
+
+@dataclass
+class KeyValidator:
+    """Validates Authorization headers with the validation endpoint, caching the results.
+
+    Only the answers of the validation endpoint are cached, a validation endpoint that
+    can not be reached rejects the requests until it answers again.
+    """
+    validate_url: str
+    cache_ttl: float = DEFAULT_CACHE_TTL_SECONDS
+    clock: Callable[[], float] = time.monotonic
+    _cache: Dict[str, Tuple[bool, float]] = field(default_factory=dict)
+    _lock: threading.Lock = field(default_factory=threading.Lock)
+
+    def valid(self, authorization: str) -> bool:
+        if not authorization:
+            return False
+
+        # The cache is keyed by a digest, so it holds no API keys.
+        key = hashlib.sha256(authorization.encode()).hexdigest()
+        now = self.clock()
+        with self._lock:
+            cached = self._cache.get(key)
+            if cached is not None and cached[1] > now:
+                return cached[0]
+
+        valid = self._validate(authorization)
+        with self._lock:
+            self._cache[key] = (valid, now + self.cache_ttl)
+            # Expired entries are dropped as the cache grows, keys are not revalidated.
+            if len(self._cache) > 10000:
+                self._cache = {k: v for k, v in self._cache.items() if v[1] > now}
+        return valid
+
+    def _validate(self, authorization: str) -> bool:
+        req = urllib.request.Request(self.validate_url, method="GET", headers={"Authorization": authorization})
+        try:
+            with urllib.request.urlopen(req, timeout=VALIDATE_TIMEOUT_SECONDS) as resp:
+                return 200 <= resp.status < 300
+        except urllib.error.HTTPError as e:
+            if e.code in (401, 403):
+                return False
+            raise ValidationUnavailable(f"validation endpoint answered {e.code}") from e
+        except (urllib.error.URLError, OSError) as e:
+            raise ValidationUnavailable(str(e)) from e
+
+
+def make_handler(config: AuthProxyConfig, validator: KeyValidator):
+    upstream = urllib.parse.urlsplit(config.upstream)
+    public_paths = frozenset(config.public_paths)
+
+    class AuthProxyHandler(BaseHTTPRequestHandler):
+        protocol_version = "HTTP/1.1"
+
+        def log_message(self, format, *args):  # noqa: A002
+            logger.debug("%s " + format, self.address_string(), *args)
+
+        def _reject(self, status: int, message: str):
+            body = ('{"error": "%s"}' % message).encode()
+            self.send_response(status)
+            self.send_header("Content-Type", "application/json")
+            self.send_header("Content-Length", str(len(body)))
+            # The request body is not read, the connection can not serve another request.
+            self.send_header("Connection", "close")
+            self.close_connection = True
+            self.end_headers()
+            self.wfile.write(body)
+
+        def _read_body(self) -> Optional[bytes]:
+            if self.headers.get("Transfer-Encoding", "").lower() == "chunked":
+                chunks = []
+                while True:
+                    size = int(self.rfile.readline().split(b";")[0].strip(), 16)
+                    if size == 0:
+                        # Trailers end with an empty line.
+                        while self.rfile.readline() not in (b"\r\n", b"\n", b""):
+                            pass
+                        return b"".join(chunks)
+                    chunks.append(self.rfile.read(size))
+                    self.rfile.readline()
+            length = int(self.headers.get("Content-Length") or 0)
+            return self.rfile.read(length) if length else None
+
+        def _proxy(self):
+            path = urllib.parse.urlsplit(self.path).path
+            if path not in public_paths:
+                try:
+                    valid = validator.valid(self.headers.get("Authorization", ""))
+                except ValidationUnavailable as e:
+                    logger.warning("failed to validate API key: %s", e)
+                    self._reject(503, "API key validation unavailable")
+                    return
+                if not valid:
+                    self._reject(401, "invalid API key")
+                    return
+
+            body = self._read_body()
+            headers = {k: v for k, v in self.headers.items()
+                       if k.lower() not in HOP_BY_HOP_HEADERS and k.lower() not in ("host", "content-length")}
+            headers["Host"] = upstream.netloc
+            if body is not None:
+                headers["Content-Length"] = str(len(body))
+
+            conn = http.client.HTTPConnection(upstream.hostname, upstream.port or 80)
+            try:
+                try:
+                    conn.request(self.command, self.path, body=body, headers=headers)
+                    resp = conn.getresponse()
+                except OSError as e:
+                    logger.warning("failed to reach the engine: %s", e)
+                    self._reject(502, "engine unavailable")
+                    return
+
+                self.send_response(resp.status, resp.reason)
+                length = resp.getheader("Content-Length")
+                for k, v in resp.getheaders():
+                    if k.lower() not in HOP_BY_HOP_HEADERS:
+                        self.send_header(k, v)
+                chunked = length is None and self.command != "HEAD" and resp.status not in (204, 304)
+                if chunked:
+                    self.send_header("Transfer-Encoding", "chunked")
+                self.end_headers()
+
+                while True:
+                    data = resp.read1(STREAM_CHUNK_SIZE)
+                    if not data:
+                        break
+                    if chunked:
+                        self.wfile.write(b"%x\r\n%s\r\n" % (len(data), data))
+                    else:
+                        self.wfile.write(data)
+                    self.wfile.flush()
+                if chunked:
+                    self.wfile.write(b"0\r\n\r\n")
+                    self.wfile.flush()
+            finally:
+                conn.close()
+
+        do_GET = do_POST = do_PUT = do_PATCH = do_DELETE = do_HEAD = do_OPTIONS = _proxy
+
+    return AuthProxyHandler
+
+
+def new_server(config: AuthProxyConfig, validator: Optional[KeyValidator] = None,
+               host: str = "0.0.0.0") -> ThreadingHTTPServer:
+    validator = validator or KeyValidator(config.validate_url, config.cache_ttl)
+    server = ThreadingHTTPServer((host, config.listen_port), make_handler(config, validator))
+    server.daemon_threads = True
+    return server
+
+
+def serve(config: AuthProxyConfig):
+    server = new_server(config)
+    logger.info("auth sidecar listening on port %d, forwarding to %s", config.listen_port, config.upstream)
+    server.serve_forever()
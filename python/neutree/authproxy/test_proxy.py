@@ -0,0 +1,142 @@
+"""Tests for the auth sidecar reverse proxy."""
+
+import http.client
+import threading
+import unittest
+from http.server import BaseHTTPRequestHandler, ThreadingHTTPServer
+
+from neutree.authproxy.proxy import AuthProxyConfig, KeyValidator, ValidationUnavailable, new_server
+
+VALID_KEY = "Bearer sk_valid"
+
+
+def start(server):
+    thread = threading.Thread(target=server.serve_forever, daemon=True)
+    thread.start()
+    return server
+
+
+class ValidateHandler(BaseHTTPRequestHandler):
+    calls = []
+
+    def log_message(self, *args):
+        pass
+
+    def do_GET(self):
+        ValidateHandler.calls.append(self.headers.get("Authorization"))
+        status = 204 if self.headers.get("Authorization") == VALID_KEY else 401
+        self.send_response(status)
+        self.send_header("Content-Length", "0")
+        self.end_headers()
+
+
+class EngineHandler(BaseHTTPRequestHandler):
+    protocol_version = "HTTP/1.1"
+
+    def log_message(self, *args):
+        pass
+
+    def do_GET(self):
+        body = b"ok"
+        self.send_response(200)
+        self.send_header("Content-Length", str(len(body)))
+        self.end_headers()
+        self.wfile.write(body)
+
+    def do_POST(self):
+        # Streams the request body back as server-sent events without a Content-Length.
+        body = self.rfile.read(int(self.headers["Content-Length"]))
+        self.send_response(200)
+        self.send_header("Content-Type", "text/event-stream")
+        self.send_header("Connection", "close")
+        self.end_headers()
+        for part in (b"data: ", body, b"\n\n"):
+            self.wfile.write(part)
+            self.wfile.flush()
+
+
+class AuthProxyTest(unittest.TestCase):
+    @classmethod
+    def setUpClass(cls):
+        cls.validate = start(ThreadingHTTPServer(("127.0.0.1", 0), ValidateHandler))
+        cls.engine = start(ThreadingHTTPServer(("127.0.0.1", 0), EngineHandler))
+        config = AuthProxyConfig(
+            upstream=f"http://127.0.0.1:{cls.engine.server_port}",
+            validate_url=f"http://127.0.0.1:{cls.validate.server_port}/api/v1/auth/validate",
+            listen_port=0,
+        )
+        cls.proxy = start(new_server(config, host="127.0.0.1"))
+
+    @classmethod
+    def tearDownClass(cls):
+        for server in (cls.proxy, cls.engine, cls.validate):
+            server.shutdown()
+            server.server_close()
+
+    def setUp(self):
+        ValidateHandler.calls.clear()
+
+    def request(self, method, path, headers=None, body=None):
+        conn = http.client.HTTPConnection("127.0.0.1", self.proxy.server_port, timeout=5)
+        try:
+            conn.request(method, path, body=body, headers=headers or {})
+            resp = conn.getresponse()
+            return resp.status, resp.read()
+        finally:
+            conn.close()
+
+    def test_rejects_missing_and_invalid_keys(self):
+        self.assertEqual(self.request("GET", "/v1/models")[0], 401)
+        self.assertEqual(self.request("GET", "/v1/models", {"Authorization": "Bearer sk_invalid"})[0], 401)
+
+    def test_forwards_valid_keys(self):
+        self.assertEqual(self.request("GET", "/v1/models", {"Authorization": VALID_KEY}), (200, b"ok"))
+
+    def test_streams_chunked_responses(self):
+        status, body = self.request("POST", "/v1/chat/completions", {"Authorization": VALID_KEY}, b'{"n": 1}')
+        self.assertEqual((status, body), (200, b'data: {"n": 1}\n\n'))
+
+    def test_public_paths_skip_validation(self):
+        self.assertEqual(self.request("GET", "/health"), (200, b"ok"))
+        self.assertEqual(self.request("GET", "/metrics"), (200, b"ok"))
+        self.assertEqual(ValidateHandler.calls, [])
+
+
+class KeyValidatorTest(unittest.TestCase):
+    def test_caches_results_until_ttl(self):
+        now = [0.0]
+        calls = []
+
+        validator = KeyValidator("http://validate", cache_ttl=30, clock=lambda: now[0])
+        validator._validate = lambda authorization: calls.append(authorization) or authorization == VALID_KEY
+
+        self.assertTrue(validator.valid(VALID_KEY))
+        self.assertTrue(validator.valid(VALID_KEY))
+        self.assertFalse(validator.valid("Bearer sk_invalid"))
+        self.assertEqual(len(calls), 2)
+
+        now[0] = 31
+        self.assertTrue(validator.valid(VALID_KEY))
+        self.assertEqual(len(calls), 3)
+
+    def test_unreachable_validation_is_not_cached(self):
+        validator = KeyValidator("http://127.0.0.1:1/validate")
+        with self.assertRaises(ValidationUnavailable):
+            validator.valid(VALID_KEY)
+        self.assertEqual(validator._cache, {})
+
+    def test_config_from_env(self):
+        config = AuthProxyConfig.from_env({
+            "NEUTREE_AUTH_UPSTREAM": "http://127.0.0.1:8001",
+            "NEUTREE_AUTH_VALIDATE_URL": "http://neutree-api/api/v1/auth/validate",
+            "NEUTREE_AUTH_LISTEN_PORT": "8000",
+            "NEUTREE_AUTH_PUBLIC_PATHS": "/ping, /metrics",
+        })
+        self.assertEqual(config.listen_port, 8000)
+        self.assertEqual(config.public_paths, ("/ping", "/metrics"))
+        with self.assertRaises(ValueError):
+            AuthProxyConfig.from_env({"NEUTREE_AUTH_UPSTREAM": "http://127.0.0.1:8001"})
+
+
+if __name__ == "__main__":
+    unittest.main()
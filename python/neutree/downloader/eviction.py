@@ -0,0 +1,184 @@
+"""Model cache eviction.
+
+Run by the cluster reconcile as a Job mounting the model cache:
+
+    python3 -m neutree.downloader.eviction --path=/models-cache/<cache> \
+        --policy=size_capped --max-size=536870912000 --protect=<model name> ...
+
+Every directory holding a ``.neutree`` marker directory is a cached model. Once
+the cache is over max size, unprotected models are evicted least recently used
+first: the ``lru`` policy evicts all of them idle for longer than max idle, the
+``size_capped`` policy evicts until the cache fits in max size again. Without
+max size the cache size is unlimited: the ``lru`` policy evicts the idle models
+whatever the cache size, the ``size_capped`` policy evicts nothing.
+
+Models referenced by an endpoint (``--protect``), models still downloading and
+models used within MIN_IDLE_SECONDS are never evicted.
+"""
+import argparse
+import os
+import shutil
+import sys
+import time
+from dataclasses import dataclass
+from typing import Iterable, List, Optional
+
+from .utils import DOWNLOAD_COMPLETE_FILE, DOWNLOAD_SLOTS_DIR, LAST_USED_FILE
+
+POLICY_LRU = "lru"
+POLICY_SIZE_CAPPED = "size_capped"
+
+MARKER_DIR = ".neutree"
+
+# A model used this recently may belong to an endpoint created after the protected
+# models were listed, so it is kept regardless of the policy.
+MIN_IDLE_SECONDS = 600
+
+
+@dataclass
+class CachedModel:
+    # path relative to the cache root, i.e. <model name>/<version>.
+    path: str
+    size: int
+    last_used: float
+    downloading: bool = False
+
+
+def _dir_size(path: str) -> int:
+    size = 0
+    for root, _, files in os.walk(path):
+        for f in files:
+            try:
+                size += os.lstat(os.path.join(root, f)).st_size
+            except OSError:
+                continue
+    return size
+
+
+def _last_used(path: str) -> float:
+    for marker in (LAST_USED_FILE, DOWNLOAD_COMPLETE_FILE, MARKER_DIR):
+        try:
+            return os.stat(os.path.join(path, marker)).st_mtime
+        except OSError:
+            continue
+    return 0.0
+
+
+def _is_downloading(path: str) -> bool:
+    """Whether a download slot of the model is held by a running download."""
+    import fcntl
+
+    slots_dir = os.path.join(path, DOWNLOAD_SLOTS_DIR)
+    if not os.path.isdir(slots_dir):
+        return False
+
+    for name in os.listdir(slots_dir):
+        with open(os.path.join(slots_dir, name), "a") as fd:
+            try:
+                fcntl.flock(fd.fileno(), fcntl.LOCK_EX | fcntl.LOCK_NB)
+            except (IOError, OSError):
+                return True
+            fcntl.flock(fd.fileno(), fcntl.LOCK_UN)
+    return False
+
+
+def scan_cache(root: str) -> List[CachedModel]:
+    """List the cached models under root."""
+    models = []
+    for current, dirs, _ in os.walk(root):
+        if MARKER_DIR not in dirs:
+            continue
+
+        # the model directory is not walked any further.
+        dirs[:] = []
+        models.append(CachedModel(
+            path=os.path.relpath(current, root),
+            size=_dir_size(current),
+            last_used=_last_used(current),
+            downloading=_is_downloading(current),
+        ))
+    return models
+
+
+def is_protected(path: str, protected: Iterable[str]) -> bool:
+    """Whether the model path belongs to, or contains, a protected model."""
+    for p in protected:
+        p = p.strip("/")
+        if not p:
+            continue
+        if path == p or path.startswith(p + "/") or p.startswith(path + "/"):
+            return True
+    return False
+
+
+def select_evictions(models: List[CachedModel], protected: Iterable[str], policy: str,
+                     max_size: Optional[int], max_idle: float, now: float,
+                     min_idle: float = MIN_IDLE_SECONDS) -> List[CachedModel]:
+    """Select the models to evict, least recently used first. max_size None is unlimited."""
+    if policy not in (POLICY_LRU, POLICY_SIZE_CAPPED):
+        raise ValueError(f"unknown eviction policy {policy}")
+
+    total = sum(m.size for m in models)
+    if max_size is None and policy == POLICY_SIZE_CAPPED:
+        return []
+    if max_size is not None and total <= max_size:
+        return []
+
+    protected = list(protected)
+    candidates = sorted(
+        (m for m in models
+         if not m.downloading and not is_protected(m.path, protected) and now - m.last_used >= min_idle),
+        key=lambda m: m.last_used,
+    )
+
+    evictions = []
+    for m in candidates:
+        if policy == POLICY_SIZE_CAPPED and total <= max_size:
+            break
+        if policy == POLICY_LRU and now - m.last_used < max_idle:
+            # candidates are sorted, the remaining ones were used even later.
+            break
+        evictions.append(m)
+        total -= m.size
+    return evictions
+
+
+def evict(root: str, models: List[CachedModel]) -> None:
+    for m in models:
+        path = os.path.join(root, m.path)
+        print(f"Evicting model {m.path} ({m.size} bytes, last used at {time.ctime(m.last_used)})", flush=True)
+        shutil.rmtree(path, ignore_errors=True)
+
+        # remove the model name directories left empty.
+        parent = os.path.dirname(path)
+        while os.path.abspath(parent) != os.path.abspath(root):
+            try:
+                os.rmdir(parent)
+            except OSError:
+                break
+            parent = os.path.dirname(parent)
+
+
+def _build_parser():
+    p = argparse.ArgumentParser(prog="neutree.downloader.eviction")
+    p.add_argument("--path", required=True, help="model cache root")
+    p.add_argument("--policy", required=True, choices=[POLICY_LRU, POLICY_SIZE_CAPPED])
+    p.add_argument("--max-size", type=int, default=None, help="cache size in bytes eviction starts at, unlimited if not set")
+    p.add_argument("--max-idle", type=float, default=0, help="seconds the lru policy keeps an unused model")
+    p.add_argument("--protect", action="append", default=[], help="model path referenced by an endpoint")
+    return p
+
+
+def main(argv: Optional[List[str]] = None):
+    args = _build_parser().parse_args(argv if argv is not None else sys.argv[1:])
+
+    models = scan_cache(args.path)
+    evictions = select_evictions(models, args.protect, args.policy, args.max_size, args.max_idle, time.time())
+
+    total = sum(m.size for m in models)
+    print(f"Model cache {args.path} holds {len(models)} models of {total} bytes, evicting {len(evictions)}", flush=True)
+    evict(args.path, evictions)
+
+
+if __name__ == "__main__":
+    main()
@@ -0,0 +1,190 @@
+"""Download slots taken from Kubernetes coordination.k8s.io Leases.
+
+The orchestrator deploys one Lease per download allowed at once, named after
+NEUTREE_DL_LEASE and the slot index, and lets the replicas of the endpoint get
+and update them. A replica holds a Lease while it downloads, renewing it, and
+frees it when done. The Lease of a replica killed mid-download is taken over
+once it expires, so the limit holds across replicas and restarts.
+"""
+import datetime
+import json
+import os
+import socket
+import ssl
+import threading
+import urllib.error
+import urllib.request
+from typing import Any, Dict, Optional
+
+from .utils import is_download_complete
+
+SERVICE_ACCOUNT_DIR = "/var/run/secrets/kubernetes.io/serviceaccount"
+DEFAULT_LEASE_DURATION_SECONDS = 30
+DOWNLOAD_LEASE_POLL_SECONDS = 2.0
+
+
+class LeaseConflict(Exception):
+    """The Lease changed since it was read, e.g. another replica took it first."""
+
+
+class KubernetesLeaseClient:
+    """Minimal client of the Leases of the pod namespace, authenticated with the
+    service account of the pod, so the downloader needs no Kubernetes client library.
+    """
+    def __init__(self, namespace: Optional[str] = None, server: Optional[str] = None,
+                 service_account_dir: str = SERVICE_ACCOUNT_DIR):
+        self.service_account_dir = service_account_dir
+        if namespace is None:
+            with open(os.path.join(service_account_dir, "namespace")) as f:
+                namespace = f.read().strip()
+        self.namespace = namespace
+        if server is None:
+            host = os.environ["KUBERNETES_SERVICE_HOST"]
+            port = os.environ.get("KUBERNETES_SERVICE_PORT", "443")
+            server = f"https://[{host}]:{port}" if ":" in host else f"https://{host}:{port}"
+        self.server = server
+        self.context = ssl.create_default_context(cafile=os.path.join(service_account_dir, "ca.crt"))
+
+    def _url(self, name: str) -> str:
+        return f"{self.server}/apis/coordination.k8s.io/v1/namespaces/{self.namespace}/leases/{name}"
+
+    def _request(self, method: str, name: str, body: Optional[Dict[str, Any]] = None) -> Dict[str, Any]:
+        # The token is read on every request as the kubelet rotates it.
+        with open(os.path.join(self.service_account_dir, "token")) as f:
+            token = f.read().strip()
+        data = json.dumps(body).encode() if body is not None else None
+        req = urllib.request.Request(self._url(name), data=data, method=method, headers={
+            "Authorization": f"Bearer {token}",
+            "Content-Type": "application/json",
+            "Accept": "application/json",
+        })
+        try:
+            with urllib.request.urlopen(req, context=self.context, timeout=10) as resp:
+                return json.loads(resp.read())
+        except urllib.error.HTTPError as e:
+            if e.code == 409:
+                raise LeaseConflict(f"lease {name} was updated concurrently") from e
+            raise
+
+    def get(self, name: str) -> Dict[str, Any]:
+        return self._request("GET", name)
+
+    def update(self, name: str, lease: Dict[str, Any]) -> Dict[str, Any]:
+        """Replace the Lease, failing with LeaseConflict when it changed since it was read."""
+        return self._request("PUT", name, lease)
+
+
+def _now() -> datetime.datetime:
+    return datetime.datetime.now(datetime.timezone.utc)
+
+
+def _format_time(t: datetime.datetime) -> str:
+    return t.strftime("%Y-%m-%dT%H:%M:%S.%fZ")
+
+
+def _parse_time(value: str) -> datetime.datetime:
+    return datetime.datetime.fromisoformat(value.replace("Z", "+00:00"))
+
+
+def lease_expired(lease: Dict[str, Any], now: datetime.datetime) -> bool:
+    """Return True when nobody holds the Lease, or its holder stopped renewing it."""
+    spec = lease.get("spec") or {}
+    if not spec.get("holderIdentity"):
+        return True
+    renewed = spec.get("renewTime") or spec.get("acquireTime")
+    if not renewed:
+        return True
+    duration = spec.get("leaseDurationSeconds") or DEFAULT_LEASE_DURATION_SECONDS
+    return _parse_time(renewed) + datetime.timedelta(seconds=duration) < now
+
+
+class DownloadLease:
+    """Cluster-wide download semaphore backed by `limit` Kubernetes Leases.
+
+    Behaves as DownloadSlot: at most `limit` holders download at once, and a
+    waiter proceeds without a Lease (acquired is False) once another holder has
+    populated the cache in dest.
+    """
+    def __init__(self, dest: str, limit: int, prefix: str, *,
+                 client: Optional[KubernetesLeaseClient] = None, holder: Optional[str] = None):
+        self.dest = dest
+        self.limit = limit
+        self.prefix = prefix
+        self.client = client
+        # Pods are named by their hostname, a restarted init container takes its own Lease back.
+        self.holder = holder or os.environ.get("HOSTNAME") or socket.gethostname()
+        self.name = None
+        self.lease = None
+        self.acquired = False
+        self._stop = threading.Event()
+        self._renewer = None
+
+    def _try_acquire(self) -> bool:
+        now = _now()
+        for i in range(self.limit):
+            name = f"{self.prefix}-{i}"
+            lease = self.client.get(name)
+            spec = lease.setdefault("spec", {})
+            if spec.get("holderIdentity") != self.holder and not lease_expired(lease, now):
+                continue
+            spec["holderIdentity"] = self.holder
+            spec["acquireTime"] = _format_time(now)
+            spec["renewTime"] = _format_time(now)
+            try:
+                self.lease = self.client.update(name, lease)
+            except LeaseConflict:
+                continue
+            self.name = name
+            return True
+        return False
+
+    def _renew(self) -> None:
+        duration = (self.lease.get("spec") or {}).get("leaseDurationSeconds") or DEFAULT_LEASE_DURATION_SECONDS
+        while not self._stop.wait(duration / 3):
+            self.lease["spec"]["renewTime"] = _format_time(_now())
+            try:
+                self.lease = self.client.update(self.name, self.lease)
+            except LeaseConflict:
+                print(f"Download lease {self.name} was taken over, the download continues without it", flush=True)
+                self.acquired = False
+                return
+            except Exception as e:
+                # Retried on the next renewal, the Lease only expires after missing a few of them.
+                print(f"Failed to renew download lease {self.name}: {e}", flush=True)
+
+    def __enter__(self):
+        if self.client is None:
+            self.client = KubernetesLeaseClient()
+        waiting = False
+        while True:
+            if self._try_acquire():
+                self.acquired = True
+                self._renewer = threading.Thread(target=self._renew, daemon=True)
+                self._renewer.start()
+                return self
+            if is_download_complete(self.dest):
+                return self
+            if not waiting:
+                print(f"All {self.limit} download leases are held, waiting for the model cache to be populated", flush=True)
+                waiting = True
+            self._stop.wait(DOWNLOAD_LEASE_POLL_SECONDS)
+
+    def __exit__(self, exc_type, exc_val, exc_tb):
+        if self._renewer:
+            self._stop.set()
+            self._renewer.join()
+            self._renewer = None
+        if self.acquired:
+            spec = self.lease.setdefault("spec", {})
+            if spec.get("holderIdentity") == self.holder:
+                spec["holderIdentity"] = None
+                spec["acquireTime"] = None
+                spec["renewTime"] = None
+                try:
+                    self.client.update(self.name, self.lease)
+                except Exception as e:
+                    # The Lease expires on its own, only the waiters are delayed.
+                    print(f"Failed to release download lease {self.name}: {e}", flush=True)
+        self.lease = None
+        self.name = None
+        self.acquired = False
@@ -11,6 +11,14 @@ DEFAULT_PROGRESS_INTERVAL = 30.0
 MIN_PROGRESS_INTERVAL = 1.0
 PROGRESS_INTERVAL_ENV = "NEUTREE_DL_PROGRESS_INTERVAL"
 
+# Machine-readable progress line parsed by the orchestrator from container/actor logs.
+MODEL_DOWNLOAD_PROGRESS_MARKER = "NEUTREE_MODEL_DOWNLOAD_PROGRESS"
+
+
+def format_progress_marker(downloaded: int, total: Optional[int] = None) -> str:
+    """Format a progress marker line: NEUTREE_MODEL_DOWNLOAD_PROGRESS downloaded=<bytes> total=<bytes>."""
+    return f"{MODEL_DOWNLOAD_PROGRESS_MARKER} downloaded={max(0, int(downloaded))} total={max(0, int(total or 0))}"
+
 
 def is_interactive() -> bool:
     """Return true when either stdout or stderr is attached to a terminal."""
@@ -147,6 +155,8 @@ class ProgressReporter:
         if self._stop.is_set():
             return
 
+        print(format_progress_marker(size, self.total_size), flush=True)
+
         if self.total_size:
             pct = min(100.0, (size / self.total_size) * 100.0)
             self.logger.info(
@@ -0,0 +1,151 @@
+"""Tests for the model cache eviction."""
+
+import contextlib
+import fcntl
+import hashlib
+import io
+import os
+import shutil
+import sys
+import tempfile
+import types
+import unittest
+
+_fake_sha = types.ModuleType("huggingface_hub.utils.sha")
+_fake_sha.git_hash = lambda data: ""
+_fake_sha.sha_fileobj = lambda stream, bufsize=0: hashlib.sha256(stream.read()).digest()
+sys.modules.setdefault("huggingface_hub", types.ModuleType("huggingface_hub"))
+sys.modules.setdefault("huggingface_hub.utils", types.ModuleType("huggingface_hub.utils"))
+sys.modules.setdefault("huggingface_hub.utils.sha", _fake_sha)
+_fake_hf_api = types.ModuleType("huggingface_hub.hf_api")
+_fake_hf_api.RepoFile = type("RepoFile", (), {})
+sys.modules.setdefault("huggingface_hub.hf_api", _fake_hf_api)
+
+from neutree.downloader import eviction  # noqa: E402
+from neutree.downloader import utils as downloader_utils  # noqa: E402
+from neutree.downloader.eviction import CachedModel, select_evictions  # noqa: E402
+
+NOW = 1_000_000.0
+HOUR = 3600.0
+
+
+def model(path, size, idle_hours, downloading=False):
+    return CachedModel(path=path, size=size, last_used=NOW - idle_hours * HOUR, downloading=downloading)
+
+
+class TestSelectEvictions(unittest.TestCase):
+    def setUp(self):
+        self.models = [
+            model("qwen/Qwen3-8B/main", 40, 1),
+            model("llama/Llama-3-8B/main", 30, 48),
+            model("mistral/Mistral-7B/v1", 20, 72),
+            model("bge-m3/latest", 10, 24),
+        ]
+
+    def paths(self, evictions):
+        return [m.path for m in evictions]
+
+    def test_nothing_evicted_under_max_size(self):
+        self.assertEqual(select_evictions(self.models, [], "size_capped", 100, 0, NOW), [])
+        self.assertEqual(select_evictions(self.models, [], "lru", 100, 0, NOW), [])
+
+    def test_size_capped_evicts_least_recently_used_until_under_max_size(self):
+        evictions = select_evictions(self.models, [], "size_capped", 60, 0, NOW)
+
+        self.assertEqual(self.paths(evictions), ["mistral/Mistral-7B/v1", "llama/Llama-3-8B/main"])
+
+    def test_lru_evicts_models_idle_for_longer_than_max_idle(self):
+        evictions = select_evictions(self.models, [], "lru", 90, 36 * HOUR, NOW)
+
+        # the cache fits after the first eviction, lru still evicts every idle model.
+        self.assertEqual(self.paths(evictions), ["mistral/Mistral-7B/v1", "llama/Llama-3-8B/main"])
+
+    def test_unlimited_max_size(self):
+        self.assertEqual(select_evictions(self.models, [], "size_capped", None, 0, NOW), [])
+
+        evictions = select_evictions(self.models, [], "lru", None, 36 * HOUR, NOW)
+
+        self.assertEqual(self.paths(evictions), ["mistral/Mistral-7B/v1", "llama/Llama-3-8B/main"])
+
+    def test_models_in_use_are_never_evicted(self):
+        protected = ["mistral/Mistral-7B", "llama/Llama-3-8B/main"]
+
+        evictions = select_evictions(self.models, protected, "size_capped", 10, 0, NOW)
+
+        # the cache stays over max size rather than evicting a model in use.
+        self.assertEqual(self.paths(evictions), ["bge-m3/latest", "qwen/Qwen3-8B/main"])
+
+    def test_downloading_and_recently_used_models_are_never_evicted(self):
+        models = self.models + [model("new/model/main", 50, 0.05), model("partial/model/main", 50, 96, downloading=True)]
+
+        evictions = select_evictions(models, [], "size_capped", 0, 0, NOW)
+
+        self.assertEqual(self.paths(evictions), [
+            "mistral/Mistral-7B/v1", "llama/Llama-3-8B/main", "bge-m3/latest", "qwen/Qwen3-8B/main"])
+
+    def test_unknown_policy(self):
+        with self.assertRaises(ValueError):
+            select_evictions(self.models, [], "fifo", 10, 0, NOW)
+
+
+class TestIsProtected(unittest.TestCase):
+    def test_is_protected(self):
+        self.assertTrue(eviction.is_protected("qwen/Qwen3-8B/main", ["qwen/Qwen3-8B"]))
+        self.assertTrue(eviction.is_protected("qwen/Qwen3-8B/main", ["/qwen/Qwen3-8B/main/"]))
+        self.assertTrue(eviction.is_protected("qwen", ["qwen/Qwen3-8B"]))
+        self.assertFalse(eviction.is_protected("qwen/Qwen3-8B-Instruct/main", ["qwen/Qwen3-8B"]))
+        self.assertFalse(eviction.is_protected("qwen/Qwen3-8B/main", [""]))
+
+
+class TestEvictionMain(unittest.TestCase):
+    def setUp(self):
+        self.root = tempfile.mkdtemp()
+        self.addCleanup(shutil.rmtree, self.root, True)
+
+    def add_model(self, path, size, idle_hours):
+        dest = os.path.join(self.root, path)
+        os.makedirs(dest)
+        with open(os.path.join(dest, "model.safetensors"), "wb") as f:
+            f.write(b"x" * size)
+        downloader_utils.mark_model_used(dest)
+        used = NOW - idle_hours * HOUR
+        os.utime(os.path.join(dest, downloader_utils.LAST_USED_FILE), (used, used))
+        return dest
+
+    def test_scan_cache(self):
+        self.add_model("org/a/main", 10, 1)
+        self.add_model("b/v1", 20, 2)
+        os.makedirs(os.path.join(self.root, "not-a-model"))
+
+        models = sorted(eviction.scan_cache(self.root), key=lambda m: m.path)
+
+        self.assertEqual([(m.path, m.last_used) for m in models], [("b/v1", NOW - 2 * HOUR), ("org/a/main", NOW - HOUR)])
+        self.assertGreaterEqual(models[0].size, 20)
+        self.assertFalse(models[0].downloading)
+
+    def test_scan_cache_detects_running_downloads(self):
+        dest = self.add_model("org/a/main", 10, 1)
+        slots_dir = os.path.join(dest, downloader_utils.DOWNLOAD_SLOTS_DIR)
+        os.makedirs(slots_dir)
+        with open(os.path.join(slots_dir, "slot-0.lock"), "w") as fd:
+            fcntl.flock(fd.fileno(), fcntl.LOCK_EX | fcntl.LOCK_NB)
+
+            self.assertTrue(eviction.scan_cache(self.root)[0].downloading)
+
+        self.assertFalse(eviction.scan_cache(self.root)[0].downloading)
+
+    def test_main_evicts_unprotected_models(self):
+        self.add_model("org/a/main", 100, 48)
+        self.add_model("org/b/main", 100, 72)
+        self.add_model("c/v1", 100, 96)
+
+        with contextlib.redirect_stdout(io.StringIO()):
+            eviction.main(["--path", self.root, "--policy", "size_capped", "--max-size", "300", "--protect", "c"])
+
+        self.assertTrue(os.path.isdir(os.path.join(self.root, "c", "v1")))
+        self.assertTrue(os.path.isdir(os.path.join(self.root, "org", "a", "main")))
+        self.assertFalse(os.path.exists(os.path.join(self.root, "org", "b")))
+
+
+if __name__ == "__main__":
+    unittest.main()
@@ -0,0 +1,167 @@
+"""Tests for the download slots taken from Kubernetes Leases."""
+
+import contextlib
+import copy
+import datetime
+import hashlib
+import io
+import sys
+import tempfile
+import threading
+import time
+import types
+import unittest
+from unittest import mock
+
+_fake_sha = types.ModuleType("huggingface_hub.utils.sha")
+_fake_sha.git_hash = lambda data: ""
+_fake_sha.sha_fileobj = lambda stream, bufsize=0: hashlib.sha256(stream.read()).digest()
+sys.modules.setdefault("huggingface_hub", types.ModuleType("huggingface_hub"))
+sys.modules.setdefault("huggingface_hub.utils", types.ModuleType("huggingface_hub.utils"))
+sys.modules.setdefault("huggingface_hub.utils.sha", _fake_sha)
+
+from neutree.downloader import download_with_markers  # noqa: E402
+from neutree.downloader import lease as downloader_lease  # noqa: E402
+from neutree.downloader import utils as downloader_utils  # noqa: E402
+
+
+class FakeLeaseClient:
+    """In-memory Leases rejecting updates of a stale resourceVersion, as the API server does."""
+
+    def __init__(self, names, duration=30):
+        self.lock = threading.Lock()
+        self.leases = {
+            name: {"metadata": {"name": name, "resourceVersion": "1"},
+                   "spec": {"leaseDurationSeconds": duration}}
+            for name in names
+        }
+
+    def get(self, name):
+        with self.lock:
+            return copy.deepcopy(self.leases[name])
+
+    def update(self, name, lease):
+        with self.lock:
+            current = self.leases[name]
+            if lease["metadata"]["resourceVersion"] != current["metadata"]["resourceVersion"]:
+                raise downloader_lease.LeaseConflict(name)
+            updated = copy.deepcopy(lease)
+            updated["metadata"]["resourceVersion"] = str(int(current["metadata"]["resourceVersion"]) + 1)
+            self.leases[name] = updated
+            return copy.deepcopy(updated)
+
+    def holders(self):
+        with self.lock:
+            return {name: lease["spec"].get("holderIdentity") for name, lease in self.leases.items()}
+
+
+class ConcurrencyTrackingDownloader:
+    def __init__(self, duration=0.2):
+        self.duration = duration
+        self.active = 0
+        self.max_active = 0
+        self.calls = 0
+        self.lock = threading.Lock()
+
+    def download(self, source, dest, **kwargs):
+        with self.lock:
+            self.calls += 1
+            self.active += 1
+            self.max_active = max(self.max_active, self.active)
+        time.sleep(self.duration)
+        with self.lock:
+            self.active -= 1
+
+
+class TestDownloadLease(unittest.TestCase):
+    def setUp(self):
+        self.client = FakeLeaseClient(["chat-model-download-0", "chat-model-download-1"])
+        p = mock.patch.object(downloader_lease, "DOWNLOAD_LEASE_POLL_SECONDS", 0.01)
+        p.start()
+        self.addCleanup(p.stop)
+
+    def download(self, downloader, holder):
+        # Every replica downloads into a cache of its own, only the Leases are shared.
+        with downloader_lease.DownloadLease(tempfile.mkdtemp(), 2, "chat-model-download",
+                                            client=self.client, holder=holder):
+            downloader.download("source", "dest")
+
+    def test_download_concurrency_is_bounded_by_leases(self):
+        downloader = ConcurrencyTrackingDownloader()
+
+        with contextlib.redirect_stdout(io.StringIO()):
+            threads = [threading.Thread(target=self.download, args=(downloader, f"chat-{i}")) for i in range(5)]
+            for thread in threads:
+                thread.start()
+            for thread in threads:
+                thread.join(timeout=10)
+
+        self.assertEqual(downloader.calls, 5)
+        self.assertEqual(downloader.max_active, 2)
+
+    def test_lease_is_released_on_completion(self):
+        with downloader_lease.DownloadLease(tempfile.mkdtemp(), 2, "chat-model-download",
+                                            client=self.client, holder="chat-0") as slot:
+            self.assertTrue(slot.acquired)
+            self.assertEqual(self.client.holders()["chat-model-download-0"], "chat-0")
+
+        self.assertEqual(self.client.holders(), {"chat-model-download-0": None, "chat-model-download-1": None})
+
+    def test_lease_is_released_on_failure(self):
+        with self.assertRaises(RuntimeError):
+            with downloader_lease.DownloadLease(tempfile.mkdtemp(), 2, "chat-model-download",
+                                                client=self.client, holder="chat-0"):
+                raise RuntimeError("download failed")
+
+        self.assertEqual(self.client.holders(), {"chat-model-download-0": None, "chat-model-download-1": None})
+
+    def test_expired_lease_is_taken_over(self):
+        stale = datetime.datetime.now(datetime.timezone.utc) - datetime.timedelta(minutes=5)
+        for name in self.client.leases:
+            self.client.leases[name]["spec"].update(
+                holderIdentity=f"killed-{name}", renewTime=downloader_lease._format_time(stale))
+
+        with downloader_lease.DownloadLease(tempfile.mkdtemp(), 2, "chat-model-download",
+                                            client=self.client, holder="chat-0") as slot:
+            self.assertTrue(slot.acquired)
+            self.assertEqual(slot.name, "chat-model-download-0")
+
+    def test_waiter_proceeds_when_lease_frees(self):
+        now = downloader_lease._format_time(datetime.datetime.now(datetime.timezone.utc))
+        for name in self.client.leases:
+            self.client.leases[name]["spec"].update(holderIdentity=f"other-{name}", renewTime=now)
+        downloader = ConcurrencyTrackingDownloader(duration=0)
+        output = io.StringIO()
+
+        with contextlib.redirect_stdout(output):
+            thread = threading.Thread(target=self.download, args=(downloader, "chat-0"))
+            thread.start()
+            time.sleep(0.1)
+            self.assertEqual(downloader.calls, 0)
+
+            self.client.leases["chat-model-download-1"]["spec"]["holderIdentity"] = None
+            thread.join(timeout=5)
+
+        self.assertFalse(thread.is_alive())
+        self.assertEqual(downloader.calls, 1)
+        self.assertIn("All 2 download leases are held", output.getvalue())
+
+    def test_download_with_markers_uses_leases(self):
+        env = {"NEUTREE_DL_MAX_CONCURRENCY": "2", "NEUTREE_DL_LEASE": "chat-model-download", "HOSTNAME": "chat-0"}
+        seen = []
+
+        class Downloader:
+            def download(inner, *args, **kwargs):
+                seen.append(self.client.holders()["chat-model-download-0"])
+
+        with mock.patch.dict("os.environ", env), \
+                mock.patch.object(downloader_lease, "KubernetesLeaseClient", return_value=self.client), \
+                contextlib.redirect_stdout(io.StringIO()):
+            download_with_markers(Downloader(), "source", tempfile.mkdtemp())
+
+        self.assertEqual(seen, ["chat-0"])
+        self.assertEqual(self.client.holders()["chat-model-download-0"], None)
+
+
+if __name__ == "__main__":
+    unittest.main()
@@ -1,9 +1,16 @@
 """Tests for model download marker logging."""
 
 import contextlib
+import datetime
+import email.utils
 import hashlib
+import fcntl
 import io
+import os
 import sys
+import tempfile
+import threading
+import time
 import types
 import unittest
 from unittest import mock
@@ -20,20 +27,41 @@ sys.modules.setdefault("huggingface_hub.hf_api", _fake_hf_api)
 
 from neutree.downloader import download_with_markers  # noqa: E402
 from neutree.downloader import __main__ as downloader_main  # noqa: E402
+from neutree.downloader import utils as downloader_utils  # noqa: E402
 from neutree.downloader.entity import DownloadRequest  # noqa: E402
 
 
 class FakeDownloader:
-    def __init__(self, error=None):
+    def __init__(self, error=None, errors=None):
         self.error = error
+        self.errors = list(errors or [])
         self.calls = []
 
     def download(self, *args, **kwargs):
         self.calls.append((args, kwargs))
+        if self.errors:
+            raise self.errors.pop(0)
         if self.error:
             raise self.error
 
 
+class AuthExpiredError(Exception):
+    """Mimics an HTTP client error carrying the response of the registry."""
+
+    def __init__(self, status_code):
+        super().__init__(f"{status_code} Client Error")
+        self.response = types.SimpleNamespace(status_code=status_code)
+
+
+class RateLimitedError(Exception):
+    """Mimics an HTTP 429 error of the registry, optionally asking to retry after a while."""
+
+    def __init__(self, retry_after=None):
+        super().__init__("429 Client Error: Too Many Requests")
+        headers = {} if retry_after is None else {"Retry-After": retry_after}
+        self.response = types.SimpleNamespace(status=429, headers=headers)
+
+
 class TestDownloadMarkers(unittest.TestCase):
     def test_download_with_markers_prints_start_and_done(self):
         downloader = FakeDownloader()
@@ -79,6 +107,161 @@ class TestDownloadMarkers(unittest.TestCase):
             ["NEUTREE_MODEL_DOWNLOAD_START", "NEUTREE_MODEL_DOWNLOAD_FAILED"],
         )
 
+    def test_download_with_markers_does_not_retry_permanent_errors(self):
+        downloader = FakeDownloader(RuntimeError("download failed"))
+
+        with mock.patch("neutree.downloader.utils.time.sleep") as mock_sleep, \
+                self.assertRaises(RuntimeError), \
+                contextlib.redirect_stdout(io.StringIO()):
+            download_with_markers(downloader, "source", "/dest", retries=3)
+
+        self.assertEqual(len(downloader.calls), 1)
+        mock_sleep.assert_not_called()
+
+    def test_download_with_markers_retries_transient_errors(self):
+        downloader = FakeDownloader(errors=[ConnectionError("reset"), TimeoutError("timed out")])
+        output = io.StringIO()
+
+        with mock.patch.dict("os.environ", {"NEUTREE_DL_RETRY_BACKOFF": "1"}), \
+                mock.patch("neutree.downloader.utils.time.sleep") as mock_sleep, \
+                contextlib.redirect_stdout(output):
+            download_with_markers(downloader, "source", "/dest", retries=3)
+
+        self.assertEqual(len(downloader.calls), 3)
+        self.assertEqual([c.args[0] for c in mock_sleep.call_args_list], [1.0, 2.0])
+        lines = output.getvalue().splitlines()
+        self.assertEqual(lines[0], "NEUTREE_MODEL_DOWNLOAD_START")
+        self.assertEqual(lines[-1], "NEUTREE_MODEL_DOWNLOAD_DONE")
+        self.assertNotIn("NEUTREE_MODEL_DOWNLOAD_FAILED", lines)
+
+    def test_download_with_markers_fails_after_retries_exhausted(self):
+        downloader = FakeDownloader(ConnectionError("reset"))
+        output = io.StringIO()
+
+        with mock.patch("neutree.downloader.utils.time.sleep") as mock_sleep, \
+                self.assertRaises(ConnectionError), \
+                contextlib.redirect_stdout(output):
+            download_with_markers(downloader, "source", "/dest", retries=2)
+
+        self.assertEqual(len(downloader.calls), 3)
+        self.assertEqual(mock_sleep.call_count, 2)
+        lines = output.getvalue().splitlines()
+        self.assertEqual(lines.count("NEUTREE_MODEL_DOWNLOAD_START"), 1)
+        self.assertEqual(lines[-1], "NEUTREE_MODEL_DOWNLOAD_FAILED")
+
+    def test_download_with_markers_refreshes_expired_credential_and_resumes(self):
+        downloader = FakeDownloader(errors=[AuthExpiredError(401)])
+        output = io.StringIO()
+
+        with tempfile.TemporaryDirectory() as tmp:
+            token_file = os.path.join(tmp, "token")
+            with open(token_file, "w") as f:
+                f.write("fresh\n")
+
+            with mock.patch.dict("os.environ", {"NEUTREE_DL_TOKEN_FILE": token_file,
+                                                "NEUTREE_DL_RETRY_BACKOFF": "1"}), \
+                    mock.patch("neutree.downloader.utils.time.sleep") as mock_sleep, \
+                    contextlib.redirect_stdout(output):
+                download_with_markers(downloader, "source", "/dest",
+                                      credentials={"token": "expired"}, retries=0)
+
+        self.assertEqual([kwargs["credentials"] for _, kwargs in downloader.calls],
+                         [{"token": "expired"}, {"token": "fresh"}])
+        self.assertEqual([c.args[0] for c in mock_sleep.call_args_list], [1.0])
+        lines = output.getvalue().splitlines()
+        self.assertEqual(lines[-1], "NEUTREE_MODEL_DOWNLOAD_DONE")
+        self.assertNotIn("NEUTREE_MODEL_DOWNLOAD_FAILED", lines)
+
+    def test_download_with_markers_fails_after_auth_refreshes_exhausted(self):
+        downloader = FakeDownloader(AuthExpiredError(403))
+        output = io.StringIO()
+
+        with tempfile.TemporaryDirectory() as tmp:
+            token_file = os.path.join(tmp, "token")
+            with open(token_file, "w") as f:
+                f.write("still-expired")
+
+            with mock.patch.dict("os.environ", {"NEUTREE_DL_TOKEN_FILE": token_file,
+                                                "NEUTREE_DL_AUTH_REFRESH_RETRIES": "2"}), \
+                    mock.patch("neutree.downloader.utils.time.sleep"), \
+                    self.assertRaises(AuthExpiredError), \
+                    contextlib.redirect_stdout(output):
+                download_with_markers(downloader, "source", "/dest", retries=3)
+
+        self.assertEqual(len(downloader.calls), 3)
+        self.assertEqual(output.getvalue().splitlines()[-1], "NEUTREE_MODEL_DOWNLOAD_FAILED")
+
+    def test_download_with_markers_does_not_refresh_without_token_file(self):
+        downloader = FakeDownloader(AuthExpiredError(401))
+
+        with mock.patch.dict("os.environ", {"NEUTREE_DL_AUTH_REFRESH_RETRIES": "3"}), \
+                mock.patch("neutree.downloader.utils.time.sleep") as mock_sleep, \
+                self.assertRaises(AuthExpiredError), \
+                contextlib.redirect_stdout(io.StringIO()):
+            os.environ.pop("NEUTREE_DL_TOKEN_FILE", None)
+            download_with_markers(downloader, "source", "/dest", retries=3)
+
+        self.assertEqual(len(downloader.calls), 1)
+        mock_sleep.assert_not_called()
+
+    def test_download_with_markers_waits_retry_after_when_rate_limited(self):
+        downloader = FakeDownloader(errors=[RateLimitedError("30"), RateLimitedError()])
+        output = io.StringIO()
+
+        with mock.patch.dict("os.environ", {"NEUTREE_DL_RETRY_BACKOFF": "1"}), \
+                mock.patch("neutree.downloader.utils.time.sleep") as mock_sleep, \
+                contextlib.redirect_stdout(output):
+            download_with_markers(downloader, "source", "/dest", retries=0)
+
+        self.assertEqual(len(downloader.calls), 3)
+        # Retry-After first, then the exponential backoff of the second rate limit.
+        self.assertEqual([c.args[0] for c in mock_sleep.call_args_list], [30.0, 2.0])
+        lines = output.getvalue().splitlines()
+        self.assertIn("NEUTREE_MODEL_DOWNLOAD_RATE_LIMITED retry_after=30", lines)
+        self.assertIn("NEUTREE_MODEL_DOWNLOAD_RATE_LIMITED retry_after=2", lines)
+        self.assertEqual(lines[-1], "NEUTREE_MODEL_DOWNLOAD_DONE")
+
+    def test_download_with_markers_reports_rate_limit_when_giving_up(self):
+        downloader = FakeDownloader(RateLimitedError("5"))
+        output = io.StringIO()
+
+        with mock.patch.dict("os.environ", {"NEUTREE_DL_RATE_LIMIT_RETRIES": "2"}), \
+                mock.patch("neutree.downloader.utils.time.sleep"), \
+                self.assertRaises(RateLimitedError), \
+                contextlib.redirect_stdout(output):
+            download_with_markers(downloader, "source", "/dest", retries=3)
+
+        self.assertEqual(len(downloader.calls), 3)
+        self.assertEqual(output.getvalue().splitlines()[-2:],
+                         ["NEUTREE_MODEL_DOWNLOAD_RATE_LIMITED", "NEUTREE_MODEL_DOWNLOAD_FAILED"])
+
+    def test_retry_after_seconds(self):
+        now = datetime.datetime.now(datetime.timezone.utc)
+
+        self.assertIsNone(downloader_utils.retry_after_seconds(RateLimitedError()))
+        self.assertEqual(downloader_utils.retry_after_seconds(RateLimitedError("12")), 12.0)
+        self.assertEqual(downloader_utils.retry_after_seconds(RateLimitedError("86400")),
+                         downloader_utils.MAX_RETRY_AFTER_SECONDS)
+        self.assertIsNone(downloader_utils.retry_after_seconds(RateLimitedError("soon")))
+
+        retry_at = email.utils.format_datetime(now + datetime.timedelta(seconds=60), usegmt=True)
+        self.assertAlmostEqual(downloader_utils.retry_after_seconds(RateLimitedError(retry_at)), 60, delta=2)
+
+        retry_at = email.utils.format_datetime(now - datetime.timedelta(seconds=60), usegmt=True)
+        self.assertEqual(downloader_utils.retry_after_seconds(RateLimitedError(retry_at)), 0.0)
+
+    def test_build_request_reads_token_file(self):
+        with tempfile.TemporaryDirectory() as tmp:
+            token_file = os.path.join(tmp, "token")
+            with open(token_file, "w") as f:
+                f.write("from-file\n")
+
+            with mock.patch.dict("os.environ", {"NEUTREE_DL_TOKEN_FILE": token_file, "HF_TOKEN": "from-env"}):
+                _, request = downloader_utils.build_request_from_model_args(
+                    {"name": "model", "registry_type": "hugging-face"})
+
+        self.assertEqual(request.credentials, {"token": "from-file"})
+
     def test_cli_main_uses_download_with_markers(self):
         downloader = FakeDownloader()
         request = DownloadRequest(
@@ -114,5 +297,108 @@ class TestDownloadMarkers(unittest.TestCase):
         )
 
 
+class ConcurrencyTrackingDownloader:
+    """Tracks how many downloads fetch into an unpopulated cache at once.
+
+    Downloads starting after the cache is populated only verify it, so they are
+    not counted as active.
+    """
+    def __init__(self, duration=0.2):
+        self.duration = duration
+        self.active = 0
+        self.max_active = 0
+        self.calls = 0
+        self.lock = threading.Lock()
+
+    def download(self, source, dest, **kwargs):
+        fetching = not downloader_utils.is_download_complete(dest)
+        with self.lock:
+            self.calls += 1
+            if fetching:
+                self.active += 1
+                self.max_active = max(self.max_active, self.active)
+        if not fetching:
+            return
+        time.sleep(self.duration)
+        with self.lock:
+            self.active -= 1
+
+
+class TestDownloadConcurrency(unittest.TestCase):
+    def setUp(self):
+        self.dest = tempfile.mkdtemp()
+        patches = [
+            mock.patch.dict("os.environ", {"NEUTREE_DL_MAX_CONCURRENCY": "2"}),
+            mock.patch.object(downloader_utils, "DOWNLOAD_SLOT_POLL_SECONDS", 0.01),
+        ]
+        for p in patches:
+            p.start()
+            self.addCleanup(p.stop)
+
+    def hold_slots(self, count):
+        slots_dir = os.path.join(self.dest, downloader_utils.DOWNLOAD_SLOTS_DIR)
+        os.makedirs(slots_dir, exist_ok=True)
+        fds = []
+        for i in range(count):
+            fd = open(os.path.join(slots_dir, f"slot-{i}.lock"), "w")
+            fcntl.flock(fd.fileno(), fcntl.LOCK_EX | fcntl.LOCK_NB)
+            fds.append(fd)
+        return fds
+
+    def start_download(self, downloader):
+        thread = threading.Thread(
+            target=download_with_markers, args=(downloader, "source", self.dest))
+        thread.start()
+        return thread
+
+    def test_download_concurrency_is_bounded(self):
+        downloader = ConcurrencyTrackingDownloader()
+
+        with contextlib.redirect_stdout(io.StringIO()):
+            threads = [self.start_download(downloader) for _ in range(5)]
+            for thread in threads:
+                thread.join(timeout=10)
+
+        self.assertEqual(downloader.calls, 5)
+        self.assertEqual(downloader.max_active, 2)
+        self.assertTrue(downloader_utils.is_download_complete(self.dest))
+
+    def test_waiter_proceeds_when_slot_frees(self):
+        fds = self.hold_slots(2)
+        downloader = FakeDownloader()
+
+        with contextlib.redirect_stdout(io.StringIO()):
+            thread = self.start_download(downloader)
+            time.sleep(0.1)
+            self.assertEqual(downloader.calls, [])
+
+            fds[0].close()
+            thread.join(timeout=5)
+        fds[1].close()
+
+        self.assertFalse(thread.is_alive())
+        self.assertEqual(len(downloader.calls), 1)
+
+    def test_waiter_proceeds_when_cache_is_populated(self):
+        fds = self.hold_slots(2)
+        downloader = FakeDownloader()
+        output = io.StringIO()
+
+        with contextlib.redirect_stdout(output):
+            thread = self.start_download(downloader)
+            time.sleep(0.1)
+            self.assertEqual(downloader.calls, [])
+
+            downloader_utils.mark_download_complete(self.dest)
+            thread.join(timeout=5)
+        for fd in fds:
+            fd.close()
+
+        self.assertFalse(thread.is_alive())
+        self.assertEqual(len(downloader.calls), 1)
+        self.assertIn("waiting for the model cache to be populated", output.getvalue())
+        self.assertEqual(output.getvalue().splitlines()[-1], "NEUTREE_MODEL_DOWNLOAD_DONE")
+
+
 if __name__ == "__main__":
     unittest.main()
@@ -1,6 +1,8 @@
 """Tests for non-TTY downloader progress reporting."""
 
+import contextlib
 import hashlib
+import io
 import os
 import shutil
 import sys
@@ -21,6 +23,7 @@ sys.modules.setdefault("huggingface_hub.hf_api", _fake_hf_api)
 
 from neutree.downloader.progress import (  # noqa: E402
     ProgressReporter,
+    format_progress_marker,
     format_size,
     get_dir_size,
     is_interactive,
@@ -56,6 +59,11 @@ class TestProgressHelpers(unittest.TestCase):
         self.assertEqual(format_size(1536), "1.5 KiB")
         self.assertEqual(format_size(1024 * 1024), "1.0 MiB")
 
+    def test_format_progress_marker(self):
+        self.assertEqual(format_progress_marker(512, 1024), "NEUTREE_MODEL_DOWNLOAD_PROGRESS downloaded=512 total=1024")
+        self.assertEqual(format_progress_marker(512), "NEUTREE_MODEL_DOWNLOAD_PROGRESS downloaded=512 total=0")
+        self.assertEqual(format_progress_marker(-1, None), "NEUTREE_MODEL_DOWNLOAD_PROGRESS downloaded=0 total=0")
+
     def test_get_dir_size_handles_files_directories_and_missing_paths(self):
         os.makedirs(os.path.join(self.tmpdir, "nested"))
         with open(os.path.join(self.tmpdir, "a.bin"), "wb") as f:
@@ -127,6 +135,21 @@ class TestProgressReporter(unittest.TestCase):
         self.assertTrue(any("Test download completed: 7 B downloaded" in message for message in messages))
         self.assertFalse(any("15 B downloaded" in message for message in messages))
 
+    def test_reporter_prints_progress_marker(self):
+        output = io.StringIO()
+
+        with contextlib.redirect_stdout(output):
+            with ProgressReporter(
+                    self.tmpdir,
+                    self.logger,
+                    label="Test download",
+                    total_size=7,
+                    interactive=False):
+                with open(os.path.join(self.tmpdir, "new.bin"), "wb") as f:
+                    f.write(b"content")
+
+        self.assertIn("NEUTREE_MODEL_DOWNLOAD_PROGRESS downloaded=0 total=7", output.getvalue().splitlines())
+
     def test_log_progress_suppressed_after_stop_requested(self):
         reporter = ProgressReporter(
             self.tmpdir,
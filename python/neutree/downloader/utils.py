@@ -2,6 +2,8 @@ import os
 import shutil
 import json
 import datetime
+import email.utils
+import math
 import time
 from typing import Callable, Optional
 from typing import Dict, Any, Tuple
@@ -59,6 +61,250 @@ def env_bool(key: str, default: bool) -> bool:
 MODEL_DOWNLOAD_START_MARKER = "NEUTREE_MODEL_DOWNLOAD_START"
 MODEL_DOWNLOAD_DONE_MARKER = "NEUTREE_MODEL_DOWNLOAD_DONE"
 MODEL_DOWNLOAD_FAILED_MARKER = "NEUTREE_MODEL_DOWNLOAD_FAILED"
+# Printed while the model registry rate limits the download, with the seconds waited before
+# retrying ("NEUTREE_MODEL_DOWNLOAD_RATE_LIMITED retry_after=30"), and without them once
+# the downloader gives up. The control plane reports it in the endpoint status.
+MODEL_DOWNLOAD_RATE_LIMITED_MARKER = "NEUTREE_MODEL_DOWNLOAD_RATE_LIMITED"
+
+
+DEFAULT_RETRY_BACKOFF_SECONDS = 2.0
+MAX_RETRY_BACKOFF_SECONDS = 60.0
+
+DEFAULT_AUTH_REFRESH_RETRIES = 3
+AUTH_EXPIRED_STATUS_CODES = (401, 403)
+
+DEFAULT_RATE_LIMIT_RETRIES = 5
+RATE_LIMITED_STATUS_CODE = 429
+# Upper bound of a Retry-After wait, so a misbehaving registry can not park the download for hours.
+MAX_RETRY_AFTER_SECONDS = 900.0
+
+# Download slots and the completion marker live in the destination, so they are
+# shared by every replica mounting the same model cache.
+DOWNLOAD_SLOTS_DIR = os.path.join(".neutree", "download-slots")
+DOWNLOAD_COMPLETE_FILE = os.path.join(".neutree", "download.complete")
+# Touched on every successful download, the model cache eviction evicts the least recently used models first.
+LAST_USED_FILE = os.path.join(".neutree", "last-used")
+DOWNLOAD_SLOT_POLL_SECONDS = 1.0
+
+
+def is_transient_download_error(exc: BaseException) -> bool:
+    """Return True when exc looks like a network blip worth retrying in-process.
+
+    Builtin connection/timeout errors are matched by type. Errors raised by HTTP
+    client libraries (requests, urllib3, httpx) do not share a common base, so
+    they are matched by class name instead of importing every library here.
+    """
+    if isinstance(exc, (ConnectionError, TimeoutError)):
+        return True
+    name = type(exc).__name__
+    return any(s in name for s in ("Timeout", "ConnectionError", "ChunkedEncodingError", "ProtocolError"))
+
+
+def is_auth_expired_error(exc: BaseException) -> bool:
+    """Return True when exc is the registry rejecting the credential, e.g. a signed URL
+    or STS token that expired while a long download was running.
+
+    HTTP client libraries (requests, urllib3, httpx) expose the response on the
+    exception with either status_code or status.
+    """
+    return _response_status(exc) in AUTH_EXPIRED_STATUS_CODES
+
+
+def _response_status(exc: BaseException) -> Optional[int]:
+    response = getattr(exc, "response", None)
+    return getattr(response, "status_code", None) or getattr(response, "status", None)
+
+
+def is_rate_limited_error(exc: BaseException) -> bool:
+    """Return True when exc is the registry rate limiting the download (HTTP 429)."""
+    return _response_status(exc) == RATE_LIMITED_STATUS_CODE
+
+
+def retry_after_seconds(exc: BaseException) -> Optional[float]:
+    """Return the wait the registry asks for in the Retry-After header of the response of exc,
+    capped at MAX_RETRY_AFTER_SECONDS, or None when it asks for none.
+
+    Retry-After holds either a number of seconds or an HTTP date.
+    """
+    headers = getattr(getattr(exc, "response", None), "headers", None) or {}
+    value = headers.get("Retry-After") or headers.get("retry-after")
+    if value is None:
+        return None
+    value = str(value).strip()
+    try:
+        seconds = float(value)
+    except ValueError:
+        try:
+            retry_at = email.utils.parsedate_to_datetime(value)
+        except (TypeError, ValueError):
+            return None
+        if retry_at.tzinfo is None:
+            retry_at = retry_at.replace(tzinfo=datetime.timezone.utc)
+        seconds = (retry_at - datetime.datetime.now(datetime.timezone.utc)).total_seconds()
+    return min(max(0.0, seconds), MAX_RETRY_AFTER_SECONDS)
+
+
+def rate_limit_retries() -> int:
+    """Return how many times a download rate limited by the registry is retried.
+
+    The limit is read from NEUTREE_DL_RATE_LIMIT_RETRIES.
+    """
+    try:
+        return max(0, int(os.environ.get("NEUTREE_DL_RATE_LIMIT_RETRIES", DEFAULT_RATE_LIMIT_RETRIES)))
+    except ValueError:
+        return DEFAULT_RATE_LIMIT_RETRIES
+
+
+def read_credentials() -> Optional[Dict[str, str]]:
+    """Return the registry credentials, or None when the registry needs none.
+
+    NEUTREE_DL_TOKEN_FILE is preferred over the NEUTREE_DL_TOKEN/HF_TOKEN env: the file
+    is read again on every call, so a credential rotated in it (e.g. a mounted Kubernetes
+    Secret) is picked up without restarting the downloader.
+    """
+    token_file = os.environ.get("NEUTREE_DL_TOKEN_FILE")
+    if token_file:
+        try:
+            with open(token_file) as f:
+                token = f.read().strip()
+        except OSError as e:
+            print(f"Failed to read registry credential from {token_file}: {e}", flush=True)
+            token = ""
+        if token:
+            return {"token": token}
+
+    token = os.environ.get("NEUTREE_DL_TOKEN") or os.environ.get("HF_TOKEN")
+    if token:
+        return {"token": token}
+    return None
+
+
+def auth_refresh_retries() -> int:
+    """Return how many times an expired credential is refreshed during one download.
+
+    Credentials are only refreshable from NEUTREE_DL_TOKEN_FILE, without it an expired
+    credential fails the download. The limit is read from NEUTREE_DL_AUTH_REFRESH_RETRIES.
+    """
+    if not os.environ.get("NEUTREE_DL_TOKEN_FILE"):
+        return 0
+    try:
+        return max(0, int(os.environ.get("NEUTREE_DL_AUTH_REFRESH_RETRIES", DEFAULT_AUTH_REFRESH_RETRIES)))
+    except ValueError:
+        return DEFAULT_AUTH_REFRESH_RETRIES
+
+
+def retry_backoff_seconds(attempt: int) -> float:
+    """Exponential backoff for the given retry attempt (1-based), capped at MAX_RETRY_BACKOFF_SECONDS.
+
+    The base delay is read from NEUTREE_DL_RETRY_BACKOFF.
+    """
+    base = DEFAULT_RETRY_BACKOFF_SECONDS
+    if os.environ.get("NEUTREE_DL_RETRY_BACKOFF"):
+        try:
+            base = max(0.0, float(os.environ.get("NEUTREE_DL_RETRY_BACKOFF")))
+        except ValueError:
+            base = DEFAULT_RETRY_BACKOFF_SECONDS
+    return min(base * (2 ** (attempt - 1)), MAX_RETRY_BACKOFF_SECONDS)
+
+
+def download_max_concurrency() -> Optional[int]:
+    """Return the NEUTREE_DL_MAX_CONCURRENCY limit, or None when downloads are unbounded."""
+    raw = os.environ.get("NEUTREE_DL_MAX_CONCURRENCY")
+    if not raw:
+        return None
+    try:
+        limit = int(raw)
+    except ValueError:
+        return None
+    return limit if limit > 0 else None
+
+
+def is_download_complete(dest: str) -> bool:
+    return os.path.exists(os.path.join(dest, DOWNLOAD_COMPLETE_FILE))
+
+
+def mark_model_used(dest: str) -> None:
+    """Record the model in dest as used now, best-effort as it only orders the eviction."""
+    if not os.path.isdir(dest):
+        return
+    marker = os.path.join(dest, LAST_USED_FILE)
+    try:
+        ensure_dir(os.path.dirname(marker))
+        with open(marker, "w") as f:
+            f.write(datetime.datetime.now(datetime.timezone.utc).isoformat())
+    except OSError as e:
+        print(f"Failed to record model usage in {dest}: {e}", flush=True)
+
+
+def mark_download_complete(dest: str) -> None:
+    marker = os.path.join(dest, DOWNLOAD_COMPLETE_FILE)
+    ensure_dir(os.path.dirname(marker))
+    with open(marker, "w") as f:
+        f.write(datetime.datetime.now(datetime.timezone.utc).isoformat())
+
+
+class DownloadSlot:
+    """Cluster-wide download semaphore backed by `limit` slot lock files in dest.
+
+    At most `limit` holders download into dest at once. A waiter proceeds as soon
+    as a slot frees, or without a slot (acquired is False) once another holder has
+    populated the cache, in which case its download only verifies the cached files.
+    """
+    def __init__(self, dest: str, limit: int):
+        self.dest = dest
+        self.limit = limit
+        self.lockfd = None
+        self.acquired = False
+
+    def _try_acquire(self) -> bool:
+        import fcntl
+
+        slots_dir = os.path.join(self.dest, DOWNLOAD_SLOTS_DIR)
+        ensure_dir(slots_dir)
+        for i in range(self.limit):
+            fd = open(os.path.join(slots_dir, f"slot-{i}.lock"), 'w')
+            try:
+                fcntl.flock(fd.fileno(), fcntl.LOCK_EX | fcntl.LOCK_NB)
+            except (IOError, OSError):
+                fd.close()
+                continue
+            self.lockfd = fd
+            return True
+        return False
+
+    def __enter__(self):
+        waiting = False
+        while True:
+            if self._try_acquire():
+                self.acquired = True
+                return self
+            if is_download_complete(self.dest):
+                return self
+            if not waiting:
+                print(f"All {self.limit} download slots are in use, waiting for the model cache to be populated", flush=True)
+                waiting = True
+            time.sleep(DOWNLOAD_SLOT_POLL_SECONDS)
+
+    def __exit__(self, exc_type, exc_val, exc_tb):
+        import fcntl
+
+        if self.lockfd:
+            try:
+                fcntl.flock(self.lockfd.fileno(), fcntl.LOCK_UN)
+            except:
+                pass
+            self.lockfd.close()
+            self.lockfd = None
+        self.acquired = False
+
+
+def download_slot(dest: str, limit: int):
+    """Return the download semaphore configured for the replica, see download_with_markers."""
+    prefix = os.environ.get("NEUTREE_DL_LEASE")
+    if prefix:
+        from .lease import DownloadLease
+        return DownloadLease(dest, limit, prefix)
+    return DownloadSlot(dest, limit)
 
 
 def download_with_markers(downloader: Any, source: str, dest: str, *,
@@ -66,18 +312,87 @@ def download_with_markers(downloader: Any, source: str, dest: str, *,
                           recursive: bool = True, overwrite: bool = False,
                           retries: int = 3, timeout: Optional[float] = None,
                           metadata: Optional[Dict[str, Any]] = None) -> None:
+    """Run downloader.download wrapped in start/done/failed markers.
+
+    Transient failures are retried up to `retries` times with exponential
+    backoff, so a short network blip does not fail the whole container.
+    A credential expiring mid-download is refreshed from NEUTREE_DL_TOKEN_FILE
+    and the download resumes, see auth_refresh_retries. A download rate
+    limited by the registry waits as long as Retry-After asks, or backs off
+    exponentially, see rate_limit_retries.
+    Markers are printed once per call regardless of the number of attempts.
+
+    When NEUTREE_DL_MAX_CONCURRENCY is set, the download holds a DownloadSlot
+    so only that many replicas sharing the model cache download at once. With
+    NEUTREE_DL_LEASE set it holds a DownloadLease instead, bounding the downloads
+    of all replicas of the endpoint, see lease.py.
+    """
     print(MODEL_DOWNLOAD_START_MARKER, flush=True)
-    try:
-        downloader.download(source, dest, credentials=credentials,
-                            recursive=recursive, overwrite=overwrite,
-                            retries=retries, timeout=timeout, metadata=metadata)
-    except Exception:
-        print(MODEL_DOWNLOAD_FAILED_MARKER, flush=True)
-        raise
-
+    limit = download_max_concurrency()
+    if limit is None:
+        _download_with_retries(downloader, source, dest, credentials=credentials,
+                               recursive=recursive, overwrite=overwrite,
+                               retries=retries, timeout=timeout, metadata=metadata)
+    else:
+        with download_slot(dest, limit):
+            _download_with_retries(downloader, source, dest, credentials=credentials,
+                                   recursive=recursive, overwrite=overwrite,
+                                   retries=retries, timeout=timeout, metadata=metadata)
+            mark_download_complete(dest)
+
+    mark_model_used(dest)
     print(MODEL_DOWNLOAD_DONE_MARKER, flush=True)
 
 
+def _download_with_retries(downloader: Any, source: str, dest: str, *,
+                           credentials: Optional[Dict[str, str]], recursive: bool,
+                           overwrite: bool, retries: int, timeout: Optional[float],
+                           metadata: Optional[Dict[str, Any]]) -> None:
+    attempt = 0
+    refreshes = 0
+    max_refreshes = auth_refresh_retries()
+    rate_limited = 0
+    max_rate_limited = rate_limit_retries()
+    while True:
+        try:
+            downloader.download(source, dest, credentials=credentials,
+                                recursive=recursive, overwrite=overwrite,
+                                retries=retries, timeout=timeout, metadata=metadata)
+            break
+        except Exception as e:
+            # Backends resume from the files already in dest, so a refreshed credential
+            # only fetches what the expired one did not.
+            if refreshes < max_refreshes and is_auth_expired_error(e):
+                refreshes += 1
+                delay = retry_backoff_seconds(refreshes)
+                print(f"Registry credential expired (refresh {refreshes}/{max_refreshes}), "
+                      f"resuming in {delay:.1f}s: {e}", flush=True)
+                time.sleep(delay)
+                credentials = read_credentials() or credentials
+                continue
+            if is_rate_limited_error(e):
+                if rate_limited >= max_rate_limited:
+                    print(MODEL_DOWNLOAD_RATE_LIMITED_MARKER, flush=True)
+                    print(MODEL_DOWNLOAD_FAILED_MARKER, flush=True)
+                    raise
+                rate_limited += 1
+                delay = retry_after_seconds(e)
+                if delay is None:
+                    delay = retry_backoff_seconds(rate_limited)
+                print(f"{MODEL_DOWNLOAD_RATE_LIMITED_MARKER} retry_after={math.ceil(delay)}", flush=True)
+                print(f"Rate limited by model registry (retry {rate_limited}/{max_rate_limited}), "
+                      f"retrying in {delay:.1f}s: {e}", flush=True)
+                time.sleep(delay)
+                continue
+            attempt += 1
+            if attempt > retries or not is_transient_download_error(e):
+                print(MODEL_DOWNLOAD_FAILED_MARKER, flush=True)
+                raise
+            delay = retry_backoff_seconds(attempt)
+            print(f"Transient download error (attempt {attempt}/{retries}), retrying in {delay:.1f}s: {e}", flush=True)
+            time.sleep(delay)
+
+
 def build_request_from_model_args(model_args: Dict[str, Any]) -> Tuple[str, DownloadRequest]:
     """Convert high-level model_args + environment into (backend, DownloadRequest).
 
@@ -85,8 +400,10 @@ def build_request_from_model_args(model_args: Dict[str, Any]) -> Tuple[str, Down
     - backend: NEUTREE_DL_BACKEND env > model_args.registry-type heuristics > resource scheme heuristics > 'nfs'
     - source: model_args.path or model_args.name
     - dest: NEUTREE_DL_DEST or NEUTREE_DL_CACHE_DIR or '/models'
-    - credentials: model_args.credentials (dict) or token from NEUTREE_DL_TOKEN/NEUTREE_HF_TOKEN
+    - credentials: token from NEUTREE_DL_TOKEN_FILE or NEUTREE_DL_TOKEN/HF_TOKEN, see read_credentials
     - recursive/overwrite/retries/timeout read from env or defaults
+    - retry backoff (NEUTREE_DL_RETRY_BACKOFF) and rate limit retries (NEUTREE_DL_RATE_LIMIT_RETRIES)
+      are read by download_with_markers
     """
     backend = os.environ.get("NEUTREE_DL_BACKEND")
     if not backend:
@@ -108,10 +425,7 @@ def build_request_from_model_args(model_args: Dict[str, Any]) -> Tuple[str, Down
     source = model_args.get("registry_path") or model_args.get("name") or ""
     dest = model_args.get("path") or "/models-cache"
 
-    credentials = None
-    token = os.environ.get("NEUTREE_DL_TOKEN") or os.environ.get("HF_TOKEN")
-    if token and token != "":
-        credentials = {"token": token}
+    credentials = read_credentials()
 
     recursive = env_bool("NEUTREE_DL_RECURSIVE", True)
     overwrite = env_bool("NEUTREE_DL_OVERWRITE", False)